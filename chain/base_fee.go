@@ -0,0 +1,35 @@
+package chain
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// nextBaseFee calculates the base fee of a block with the provided block number, created as a child of the chain's
+// current head. If the base fee is dynamic, it is adjusted from the head's as EIP-1559 specifies, and then once for
+// each block skipped in between, which are treated as empty. Otherwise, the configured initial base fee is used.
+// Returns the base fee for the block.
+func (t *TestChain) nextBaseFee(blockNumber uint64) *big.Int {
+	baseFeeConfig := t.testChainConfig.BaseFeeConfig
+	parent := t.Head().Header
+	if !baseFeeConfig.Dynamic || parent.Number.Sign() == 0 || parent.BaseFee == nil {
+		return new(big.Int).SetUint64(baseFeeConfig.InitialBaseFee)
+	}
+
+	// Adjust the base fee from our head's, and then for each empty block we skipped. The base fee of empty blocks
+	// falls by an eighth each block, until it is too small to fall any further, so we stop once it stops changing.
+	baseFee := misc.CalcBaseFee(t.chainConfig, parent)
+	emptyParent := &types.Header{GasLimit: parent.GasLimit, GasUsed: 0}
+	for number := parent.Number.Uint64() + 1; number < blockNumber; number++ {
+		emptyParent.Number = new(big.Int).SetUint64(number)
+		emptyParent.BaseFee = baseFee
+		nextBaseFee := misc.CalcBaseFee(t.chainConfig, emptyParent)
+		if nextBaseFee.Cmp(baseFee) == 0 {
+			break
+		}
+		baseFee = nextBaseFee
+	}
+	return baseFee
+}
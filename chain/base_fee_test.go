@@ -0,0 +1,109 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestChainFixedBaseFee ensures every block of a TestChain uses the configured initial base fee by default, including
+// blocks created after a block number jump.
+func TestChainFixedBaseFee(t *testing.T) {
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChainConfig.BaseFeeConfig.InitialBaseFee = 7
+	chain, err := NewTestChain(core.GenesisAlloc{}, testChainConfig)
+	assert.NoError(t, err)
+
+	for i := uint64(1); i <= 3; i++ {
+		block, err := chain.PendingBlockCreateWithParameters(chain.HeadBlockNumber()+i, chain.Head().Header.Time+i, nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, big.NewInt(7), block.Header.BaseFee)
+		assert.NoError(t, chain.PendingBlockCommit())
+	}
+}
+
+// TestChainDynamicBaseFee ensures a TestChain with a dynamic base fee adjusts it as EIP-1559 specifies, treating
+// skipped blocks as empty, that EIP-1559 transactions observe the base fee and their effective gas price, and that
+// transactions whose fee cap does not cover the base fee are rejected. It also ensures a clone derives the same blocks.
+func TestChainDynamicBaseFee(t *testing.T) {
+	// Create a contract which stores block.basefee in slot 0 and tx.gasprice in slot 1.
+	contract := common.HexToAddress("0x1234")
+	contractCode := hexutil.MustDecode("0x" +
+		"48600055" + // BASEFEE PUSH1 0 SSTORE
+		"3a600155" + // GASPRICE PUSH1 1 SSTORE
+		"00", // STOP
+	)
+
+	// Create our chain with a dynamic base fee and a small block gas limit, so a single call exceeds its gas target.
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := core.GenesisAlloc{
+		sender:   {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		contract: {Balance: big.NewInt(0), Code: contractCode},
+	}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChainConfig.BaseFeeConfig.Dynamic = true
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+	chain.BlockGasLimit = 100_000
+
+	// The first block after genesis uses our initial base fee. An EIP-1559 transaction in it pays the base fee plus
+	// its tip, which the contract observes.
+	block, err := chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	initialBaseFee := big.NewInt(params.InitialBaseFee)
+	assert.EqualValues(t, initialBaseFee, block.Header.BaseFee)
+	tip := big.NewInt(3)
+	gasPrice := new(big.Int).Add(initialBaseFee, tip)
+	feeCap := new(big.Int).Mul(initialBaseFee, big.NewInt(2))
+	balanceBefore := chain.State().GetBalance(sender)
+	msg := types.NewMessage(sender, &contract, 0, big.NewInt(0), chain.BlockGasLimit, gasPrice, feeCap, tip, nil, nil, false)
+	assert.NoError(t, chain.PendingBlockAddTx(&msg))
+	assert.NoError(t, chain.PendingBlockCommit())
+	assert.EqualValues(t, common.BigToHash(initialBaseFee), chain.State().GetState(contract, common.Hash{}))
+	assert.EqualValues(t, common.BigToHash(gasPrice), chain.State().GetState(contract, common.BigToHash(big.NewInt(1))))
+	gasUsed := chain.Head().Header.GasUsed
+	assert.Greater(t, gasUsed, chain.BlockGasLimit/2)
+	gasCost := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice)
+	assert.EqualValues(t, new(big.Int).Sub(balanceBefore, gasCost), chain.State().GetBalance(sender))
+
+	// As our previous block exceeded its gas target, the base fee should rise.
+	block, err = chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	assert.EqualValues(t, misc.CalcBaseFee(chain.chainConfig, chain.Head().Header), block.Header.BaseFee)
+	assert.Greater(t, block.Header.BaseFee.Cmp(initialBaseFee), 0)
+	assert.NoError(t, chain.PendingBlockCommit())
+
+	// After jumping several blocks, the base fee should fall by an eighth for our empty previous block and for each
+	// block skipped.
+	expectedBaseFee := new(big.Int).Set(chain.Head().Header.BaseFee)
+	for i := 0; i < 4; i++ {
+		expectedBaseFee.Sub(expectedBaseFee, new(big.Int).Div(expectedBaseFee, big.NewInt(8)))
+	}
+	block, err = chain.PendingBlockCreateWithParameters(chain.HeadBlockNumber()+4, chain.Head().Header.Time+4, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, expectedBaseFee, block.Header.BaseFee)
+
+	// A transaction whose fee cap does not cover the base fee should be rejected.
+	lowFeeCap := new(big.Int).Sub(block.Header.BaseFee, big.NewInt(1))
+	lowFeeCapMsg := types.NewMessage(sender, &contract, 1, big.NewInt(0), chain.BlockGasLimit, lowFeeCap, lowFeeCap, big.NewInt(0), nil, nil, false)
+	assert.ErrorIs(t, chain.PendingBlockAddTx(&lowFeeCapMsg), core.ErrFeeCapTooLow)
+	assert.NoError(t, chain.PendingBlockCommit())
+
+	// A clone should derive the same base fees, and thus the same blocks.
+	clonedChain, err := chain.Clone(nil)
+	assert.NoError(t, err)
+	verifyChain(t, chain)
+	verifyChain(t, clonedChain)
+	assert.EqualValues(t, chain.Head().Header.BaseFee, clonedChain.Head().Header.BaseFee)
+	assert.EqualValues(t, chain.Head().Hash, clonedChain.Head().Hash)
+}
@@ -10,6 +10,12 @@ import (
 
 // newTestChainBlockContext obtains a new vm.BlockContext that is tailored to provide data from a TestChain.
 func newTestChainBlockContext(testChain *TestChain, header *types.Header) vm.BlockContext {
+	// The base fee is taken from the header of the block executed in, so contracts observe the base fee transactions
+	// in it pay.
+	baseFee := new(big.Int)
+	if header.BaseFee != nil {
+		baseFee.Set(header.BaseFee)
+	}
 	return vm.BlockContext{
 		CanTransfer: core.CanTransfer,
 		Transfer:    core.Transfer,
@@ -23,7 +29,7 @@ func newTestChainBlockContext(testChain *TestChain, header *types.Header) vm.Blo
 		BlockNumber: new(big.Int).Set(header.Number),
 		Time:        header.Time,
 		Difficulty:  new(big.Int).Set(header.Difficulty),
-		BaseFee:     new(big.Int).Set(testChain.Head().Header.BaseFee),
+		BaseFee:     baseFee,
 		GasLimit:    header.GasLimit,
 		Random:      &header.MixDigest,
 	}
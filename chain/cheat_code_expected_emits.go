@@ -0,0 +1,120 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	coreTypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// cheatCodeExpectedEmit describes an event emission expected through cheat codes. The expected event is described by
+// a template log which the call frame setting the expectation emits, and the next call it makes must emit a log
+// matching it.
+type cheatCodeExpectedEmit struct {
+	// checkTopics describes whether each of the (up to three) indexed topics following the event signature must match.
+	checkTopics [3]bool
+
+	// checkData describes whether the non-indexed data of the event must match.
+	checkData bool
+
+	// emitter describes the address which must emit the event, or nil if any address may emit it.
+	emitter *common.Address
+
+	// template describes the template log the expected event is matched against. This is nil until the template log
+	// is emitted.
+	template *coreTypes.Log
+}
+
+// matches checks whether the provided log meets the expectation.
+// Returns true if the log matches the template log under the expectation's checks.
+func (e *cheatCodeExpectedEmit) matches(log *coreTypes.Log) bool {
+	// Verify the emitter, topic count and event signature match.
+	if e.emitter != nil && *e.emitter != log.Address {
+		return false
+	}
+	if len(log.Topics) != len(e.template.Topics) {
+		return false
+	}
+	if len(log.Topics) > 0 && log.Topics[0] != e.template.Topics[0] {
+		return false
+	}
+
+	// Verify any topics and data we are checking match.
+	for i := 1; i < len(log.Topics) && i <= len(e.checkTopics); i++ {
+		if e.checkTopics[i-1] && log.Topics[i] != e.template.Topics[i] {
+			return false
+		}
+	}
+	return !e.checkData || bytes.Equal(log.Data, e.template.Data)
+}
+
+// describe obtains a printable description of the expected event, marking any parts which are not checked.
+func (e *cheatCodeExpectedEmit) describe() string {
+	// If we never captured a template, there is nothing to describe.
+	if e.template == nil {
+		return "<no event was emitted as a template>"
+	}
+
+	// Describe our emitter, topics and data.
+	emitter := "<any>"
+	if e.emitter != nil {
+		emitter = e.emitter.String()
+	}
+	topics := make([]string, len(e.template.Topics))
+	for i, topic := range e.template.Topics {
+		topics[i] = topic.String()
+		if i > 0 && i <= len(e.checkTopics) && !e.checkTopics[i-1] {
+			topics[i] += " (unchecked)"
+		}
+	}
+	data := "0x" + hex.EncodeToString(e.template.Data)
+	if !e.checkData {
+		data += " (unchecked)"
+	}
+	return fmt.Sprintf("emitter=%v, topics=[%v], data=%v", emitter, strings.Join(topics, ", "), data)
+}
+
+// describeLog obtains a printable description of the provided log.
+func describeLog(log *coreTypes.Log) string {
+	topics := make([]string, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = topic.String()
+	}
+	return fmt.Sprintf("emitter=%v, topics=[%v], data=0x%v", log.Address.String(), strings.Join(topics, ", "), hex.EncodeToString(log.Data))
+}
+
+// matchExpectedEmits matches the provided expected events, in order, against the provided emitted logs. Logs which
+// do not match the next expected event are skipped.
+// Returns printable descriptions of each expected event which was not met, alongside the logs which were emitted.
+func matchExpectedEmits(expectedEmits []*cheatCodeExpectedEmit, emittedLogs []*coreTypes.Log, reason string) []string {
+	// Advance through our expectations in order as each is met.
+	metCount := 0
+	for _, log := range emittedLogs {
+		if metCount < len(expectedEmits) && expectedEmits[metCount].matches(log) {
+			metCount++
+		}
+	}
+	if metCount == len(expectedEmits) {
+		return nil
+	}
+
+	// Describe the logs which were emitted, so any unmet expectation can be compared against them.
+	emitted := "  emitted: <none>"
+	if len(emittedLogs) > 0 {
+		emittedDescriptions := make([]string, len(emittedLogs))
+		for i, log := range emittedLogs {
+			emittedDescriptions[i] = fmt.Sprintf("  emitted[%d]: %v", i, describeLog(log))
+		}
+		emitted = strings.Join(emittedDescriptions, "\n")
+	}
+
+	// Describe each unmet expectation.
+	failures := make([]string, 0, len(expectedEmits)-metCount)
+	for _, expectedEmit := range expectedEmits[metCount:] {
+		failures = append(failures, fmt.Sprintf("expected event was not emitted (%v):\n  expected:   %v\n%v", reason, expectedEmit.describe(), emitted))
+	}
+	return failures
+}
@@ -0,0 +1,68 @@
+package chain
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestChainExpectedEmits ensures event emissions expected through cheat codes are reported as unmet only when the next
+// call made does not emit a matching event.
+func TestChainExpectedEmits(t *testing.T) {
+	// Obtain our cheat code contract address and the selector for expectEmit().
+	cheatCodeAddress := common.HexToAddress("0x7109709ECfa91a80626fF3989D68f67F5b1DD12D")
+	expectEmitSelector := crypto.Keccak256([]byte("expectEmit()"))[:4]
+
+	// Create a target contract which emits an event with a topic of 1, and a caller contract which expects an event
+	// with the topic provided in its call data, then calls the target.
+	target := common.HexToAddress("0x1234")
+	caller := common.HexToAddress("0x5678")
+	targetCode := hexutil.MustDecode("0x600160006000a100") // PUSH1 1 PUSH1 0 PUSH1 0 LOG1 STOP
+	callerCode := hexutil.MustDecode("0x" +
+		"63" + hex.EncodeToString(expectEmitSelector) + "60e01b600052" + // PUSH4 selector PUSH1 0xe0 SHL PUSH1 0 MSTORE
+		"6000600060046000600073" + cheatCodeAddress.Hex()[2:] + "5af150" + // PUSH1 0 PUSH1 0 PUSH1 4 PUSH1 0 PUSH1 0 PUSH20 cheats GAS CALL POP
+		"60003560006000a1" + // PUSH1 0 CALLDATALOAD PUSH1 0 PUSH1 0 LOG1
+		"60006000600060006000611234" + "5af15000", // PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 PUSH2 0x1234 GAS CALL POP STOP
+	)
+
+	// Create our chain with a funded sender.
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := core.GenesisAlloc{
+		sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		target: {Balance: big.NewInt(0), Code: targetCode},
+		caller: {Balance: big.NewInt(0), Code: callerCode},
+	}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+
+	// callCaller calls our caller contract with the provided expected topic in a new block.
+	// Returns the unmet event expectations.
+	callCaller := func(topic int64) []string {
+		_, err := chain.PendingBlockCreate()
+		assert.NoError(t, err)
+		msg := types.NewMessage(sender, &caller, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), common.BigToHash(big.NewInt(topic)).Bytes(), nil, false)
+		err = chain.PendingBlockAddTx(msg)
+		assert.NoError(t, err)
+		err = chain.PendingBlockCommit()
+		assert.NoError(t, err)
+		messageResults := chain.Head().MessageResults[0]
+		assert.EqualValues(t, types.ReceiptStatusSuccessful, messageResults.Receipt.Status)
+		return messageResults.ExpectedEmitFailures
+	}
+
+	// An expectation the target meets should not be reported, while one it does not meet should be.
+	assert.Empty(t, callCaller(1))
+	failures := callCaller(2)
+	assert.Len(t, failures, 1)
+	assert.Contains(t, failures[0], "expected event was not emitted")
+}
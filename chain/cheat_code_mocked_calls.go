@@ -0,0 +1,104 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// mockedCallCodePrefix describes the bytecode which leads the code of a mocked call stub, following the PUSH4
+// instruction which pushes the length of the mocked return data. When executed, it copies the return data which
+// follows it in the code into memory and returns it.
+var mockedCallCodePrefix = []byte{
+	byte(vm.DUP1),                              // [size, size]
+	byte(vm.PUSH1), byte(mockedCallCodeLength), // [offset, size, size]
+	byte(vm.PUSH1), 0x00, // [0, offset, size, size]
+	byte(vm.CODECOPY),    // [size]
+	byte(vm.PUSH1), 0x00, // [0, size]
+	byte(vm.RETURN),
+}
+
+// mockedCallCodeLength describes the length of a mocked call stub's code, excluding the return data it carries.
+const mockedCallCodeLength = 5 + 9
+
+// cheatCodeMockedCall describes a call mocked through cheat codes, which returns the provided data rather than
+// executing the target's code.
+type cheatCodeMockedCall struct {
+	// calldata describes the call data the mock matches. Calls whose data begins with it are mocked.
+	calldata []byte
+
+	// value describes the call value the mock matches, or nil if it matches any call value.
+	value *big.Int
+
+	// returnData describes the data returned to the caller of a mocked call.
+	returnData []byte
+}
+
+// cheatCodeMockedCalls describes the calls mocked through cheat codes, keyed by target address. The set is never
+// modified in place, so a previous set can be restored when the chain reverts.
+type cheatCodeMockedCalls map[common.Address][]*cheatCodeMockedCall
+
+// with returns a copy of the mocked calls with the provided mock added for the provided target address, replacing
+// any existing mock which matches the same call data and call value.
+func (m cheatCodeMockedCalls) with(target common.Address, mockedCall *cheatCodeMockedCall) cheatCodeMockedCalls {
+	// Copy our mocked calls.
+	updated := make(cheatCodeMockedCalls, len(m)+1)
+	for address, mockedCalls := range m {
+		updated[address] = mockedCalls
+	}
+
+	// Add our mock for the target, dropping any mock it replaces.
+	targetMockedCalls := make([]*cheatCodeMockedCall, 0, len(m[target])+1)
+	for _, existing := range m[target] {
+		sameValue := (existing.value == nil && mockedCall.value == nil) ||
+			(existing.value != nil && mockedCall.value != nil && existing.value.Cmp(mockedCall.value) == 0)
+		if !sameValue || !bytes.Equal(existing.calldata, mockedCall.calldata) {
+			targetMockedCalls = append(targetMockedCalls, existing)
+		}
+	}
+	updated[target] = append(targetMockedCalls, mockedCall)
+	return updated
+}
+
+// match obtains the mock for a call to the provided target address with the provided call data and call value. If
+// several mocks match, the one matching the longest call data is preferred, followed by one matching the call value.
+// Returns the matching mock, or nil if the call is not mocked.
+func (m cheatCodeMockedCalls) match(target common.Address, calldata []byte, value *big.Int) *cheatCodeMockedCall {
+	var matched *cheatCodeMockedCall
+	for _, mockedCall := range m[target] {
+		// Verify the mock matches our call.
+		if !bytes.HasPrefix(calldata, mockedCall.calldata) || (mockedCall.value != nil && mockedCall.value.Cmp(value) != 0) {
+			continue
+		}
+
+		// Prefer the most specific mock.
+		if matched == nil || len(mockedCall.calldata) > len(matched.calldata) ||
+			(len(mockedCall.calldata) == len(matched.calldata) && matched.value == nil && mockedCall.value != nil) {
+			matched = mockedCall
+		}
+	}
+	return matched
+}
+
+// newMockedCallCode creates the code of a mocked call stub, which returns the provided data when executed.
+func newMockedCallCode(returnData []byte) []byte {
+	code := make([]byte, mockedCallCodeLength+len(returnData))
+	code[0] = byte(vm.PUSH4)
+	binary.BigEndian.PutUint32(code[1:5], uint32(len(returnData)))
+	copy(code[5:], mockedCallCodePrefix)
+	copy(code[mockedCallCodeLength:], returnData)
+	return code
+}
+
+// IsMockedCallCode indicates whether the provided code is a stub executed in place of a target's code for a call
+// mocked through cheat codes.
+// Returns true if the code is a mocked call stub.
+func IsMockedCallCode(code []byte) bool {
+	if len(code) < mockedCallCodeLength || code[0] != byte(vm.PUSH4) || !bytes.Equal(code[5:mockedCallCodeLength], mockedCallCodePrefix) {
+		return false
+	}
+	return uint64(len(code)-mockedCallCodeLength) == uint64(binary.BigEndian.Uint32(code[1:5]))
+}
@@ -0,0 +1,120 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMockedCallCode ensures mocked call stubs are recognized as such, and other code is not.
+func TestMockedCallCode(t *testing.T) {
+	for _, returnData := range [][]byte{nil, {0x01}, make([]byte, 100)} {
+		assert.True(t, IsMockedCallCode(newMockedCallCode(returnData)))
+	}
+	mockedCallCode := newMockedCallCode([]byte{0x01, 0x02})
+	assert.False(t, IsMockedCallCode(mockedCallCode[:len(mockedCallCode)-1]))
+	assert.False(t, IsMockedCallCode(append(mockedCallCode, 0x03)))
+	assert.False(t, IsMockedCallCode(hexutil.MustDecode("0x602a60005260206000f3")))
+	assert.False(t, IsMockedCallCode(nil))
+}
+
+// TestChainMockedCalls ensures calls mocked through cheat codes return the mocked data for matching calls, persist
+// across transactions, and are removed when the chain reverts the transactions which registered them.
+func TestChainMockedCalls(t *testing.T) {
+	// Create a target contract which returns 42, and a proxy contract which calls the target with its own call data
+	// and call value, returning the target's return data.
+	target := common.HexToAddress("0x1234")
+	targetCode := hexutil.MustDecode("0x602a60005260206000f3") // PUSH1 42 PUSH1 0 MSTORE PUSH1 0x20 PUSH1 0 RETURN
+	proxy := common.HexToAddress("0x5678")
+	proxyCode := hexutil.MustDecode("0x" +
+		"3660006000" + "37" + // CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY
+		"60006000366000346112345a" + "f1" + "50" + // PUSH1 0 PUSH1 0 CALLDATASIZE PUSH1 0 CALLVALUE PUSH2 0x1234 GAS CALL POP
+		"3d60006000" + "3e" + // RETURNDATASIZE PUSH1 0 PUSH1 0 RETURNDATACOPY
+		"3d6000f3", // RETURNDATASIZE PUSH1 0 RETURN
+	)
+
+	// Create our chain with a funded sender.
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := core.GenesisAlloc{
+		sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		target: {Balance: big.NewInt(0), Code: targetCode},
+		proxy:  {Balance: big.NewInt(0), Code: proxyCode},
+	}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+
+	// Obtain our cheat code contract.
+	cheatCodeAddress := common.HexToAddress("0x7109709ECfa91a80626fF3989D68f67F5b1DD12D")
+	cheatCodeContract := chain.CheatCodeContracts()[cheatCodeAddress]
+	assert.NotNil(t, cheatCodeContract)
+
+	// callProxy calls our proxy contract with the provided call value and data, returning the result as an integer.
+	callProxy := func(value int64, data string) uint64 {
+		msg := types.NewMessage(sender, &proxy, chain.State().GetNonce(sender), big.NewInt(value), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), hexutil.MustDecode(data), nil, true)
+		result, err := chain.CallContract(msg, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+		return new(big.Int).SetBytes(result.ReturnData).Uint64()
+	}
+
+	// cheatCodeMessage creates a message calling the provided cheat code method with the provided arguments.
+	cheatCodeMessage := func(methodSig string, args ...any) core.Message {
+		data, err := cheatCodeContract.Abi().Pack(methodSig, args...)
+		assert.NoError(t, err)
+		return types.NewMessage(sender, &cheatCodeAddress, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), data, nil, false)
+	}
+
+	// callCheatCodeInBlock calls the provided cheat code method with the provided arguments in a new block.
+	callCheatCodeInBlock := func(methodSig string, args ...any) {
+		_, err := chain.PendingBlockCreate()
+		assert.NoError(t, err)
+		err = chain.PendingBlockAddTx(cheatCodeMessage(methodSig, args...))
+		assert.NoError(t, err)
+		err = chain.PendingBlockCommit()
+		assert.NoError(t, err)
+		assert.EqualValues(t, types.ReceiptStatusSuccessful, chain.Head().MessageResults[0].Receipt.Status)
+	}
+
+	// Calls should not be mocked until a mock is registered.
+	assert.EqualValues(t, 42, callProxy(0, "0xaabbccdd"))
+	callCheatCodeInBlock("mockCall(address,bytes,bytes)", target, hexutil.MustDecode("0xaabbccdd"), common.BigToHash(big.NewInt(7)).Bytes())
+
+	// Calls with matching call data should be mocked, without changing the target's code.
+	assert.EqualValues(t, 7, callProxy(0, "0xaabbccdd"))
+	assert.EqualValues(t, 7, callProxy(0, "0xaabbccdd00"))
+	assert.EqualValues(t, 42, callProxy(0, "0x11223344"))
+	assert.EqualValues(t, targetCode, chain.State().GetCode(target))
+
+	// Mocks matching the call value should be preferred.
+	callCheatCodeInBlock("mockCall(address,uint256,bytes,bytes)", target, big.NewInt(5), hexutil.MustDecode("0xaabbccdd"), common.BigToHash(big.NewInt(9)).Bytes())
+	assert.EqualValues(t, 9, callProxy(5, "0xaabbccdd"))
+	assert.EqualValues(t, 7, callProxy(0, "0xaabbccdd"))
+
+	// Mocks registered in calls which are not committed should not persist.
+	result, err := chain.CallContract(cheatCodeMessage("mockCall(address,bytes,bytes)", target, hexutil.MustDecode("0x11223344"), common.BigToHash(big.NewInt(1)).Bytes()), nil)
+	assert.NoError(t, err)
+	assert.NoError(t, result.Err)
+	assert.EqualValues(t, 42, callProxy(0, "0x11223344"))
+
+	// Reverting the chain should remove the mocks registered in the reverted blocks.
+	err = chain.RevertToBlockNumber(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, callProxy(5, "0xaabbccdd"))
+	err = chain.RevertToBlockNumber(0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 42, callProxy(0, "0xaabbccdd"))
+
+	// Clearing mocked calls should remove all mocks.
+	callCheatCodeInBlock("mockCall(address,bytes,bytes)", target, []byte{}, common.BigToHash(big.NewInt(3)).Bytes())
+	assert.EqualValues(t, 3, callProxy(0, "0x11223344"))
+	callCheatCodeInBlock("clearMockedCalls()")
+	assert.EqualValues(t, 42, callProxy(0, "0x11223344"))
+}
@@ -1,8 +1,12 @@
 package chain
 
 import (
+	"bytes"
+
 	"github.com/crytic/medusa/chain/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	coreTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"math/big"
 )
@@ -25,10 +29,78 @@ type cheatCodeTracer struct {
 
 	// results stores the tracer output after a transaction has concluded.
 	results *cheatCodeTracerResults
+
+	// snapshots describes the state snapshots taken through cheat codes which can still be reverted to, keyed by
+	// snapshot ID. Snapshots persist across transactions, but are removed when the chain reverts the transaction
+	// which took them.
+	snapshots map[uint64]*cheatCodeStateSnapshot
+
+	// transactionsStarted describes the number of transactions started, identifying the current transaction.
+	transactionsStarted uint64
+
+	// callFramesEntered describes the number of call frames entered in the current transaction.
+	callFramesEntered uint64
+
+	// stateChanges describes the accounts, and the storage slots of each, which may have changed since the oldest
+	// snapshot was taken. Reverting to a snapshot taken in a previous transaction restores them from its state.
+	stateChanges map[common.Address]map[common.Hash]struct{}
+
+	// nextSnapshotId describes the ID to assign to the next snapshot taken. IDs are never reused, so a stale ID can
+	// never refer to a newer snapshot.
+	nextSnapshotId uint64
+
+	// mockedCalls describes the calls mocked through cheat codes. Mocks persist across transactions, but are removed
+	// when the chain reverts the transaction which registered them.
+	mockedCalls cheatCodeMockedCalls
+
+	// pendingMockedCallRestore describes a function which restores the code of the target of the last mocked call,
+	// or nil if there is none pending. It is executed once the call frame which made the mocked call resumes.
+	pendingMockedCallRestore func()
+	// pendingMockedCallDepth describes the call depth of the call frame which made the last mocked call.
+	pendingMockedCallDepth uint64
+}
+
+// cheatCodeStateSnapshot describes a state snapshot taken through cheat codes, which the EVM state and block context
+// can be reverted to.
+type cheatCodeStateSnapshot struct {
+	// transaction describes the transaction the snapshot was taken in, as counted by the tracer.
+	transaction uint64
+
+	// stateRevision describes the state journal revision ID to revert the state to, within the transaction the
+	// snapshot was taken in.
+	stateRevision int
+
+	// state describes a copy of the state at the time of the snapshot, which the state is restored from when
+	// reverting to the snapshot in a later transaction, as its journal revision no longer exists then.
+	state vm.StateDB
+	// forkState describes the wrapper of state which fetches remote state into it if the chain is forking a remote
+	// chain, or nil otherwise.
+	forkState *forkStateDB
+
+	// callFramesEntered describes the number of call frames entered in the transaction at the time of the snapshot.
+	// Reverting the state discards the journal revisions of any call frame entered after the snapshot, so the
+	// snapshot cannot be reverted to while such a call frame is still executing.
+	callFramesEntered uint64
+
+	// time describes the block timestamp at the time of the snapshot.
+	time uint64
+	// blockNumber describes the block number at the time of the snapshot.
+	blockNumber *big.Int
+	// baseFee describes the block base fee at the time of the snapshot.
+	baseFee *big.Int
+	// difficulty describes the block difficulty at the time of the snapshot.
+	difficulty *big.Int
+	// random describes the block randomness at the time of the snapshot.
+	random *common.Hash
+	// coinbase describes the block coinbase at the time of the snapshot.
+	coinbase common.Address
 }
 
 // cheatCodeTracerCallFrame represents per-call-frame data traced by a cheatCodeTracer.
 type cheatCodeTracerCallFrame struct {
+	// enteredIndex describes the index of this call frame among all call frames entered in the transaction.
+	enteredIndex uint64
+
 	// onNextFrameEnterHooks describes hooks which will be executed the next time this call frame executes a call,
 	// creating "the next call frame".
 	// The hooks are executed as a queue on entry.
@@ -52,6 +124,20 @@ type cheatCodeTracerCallFrame struct {
 	// The hooks are executed as a stack (to support revert operations).
 	onChainRevertRestoreHooks types.GenericHookFuncs
 
+	// pendingExpectedEmit describes an event expectation set by this call frame through cheat codes, which is awaiting
+	// the template log this call frame emits next. This is nil if there is none.
+	pendingExpectedEmit *cheatCodeExpectedEmit
+	// expectedEmits describes the event expectations set by this call frame, which the next call it makes must meet.
+	expectedEmits []*cheatCodeExpectedEmit
+	// expectedEmitsToMeet describes the event expectations this call frame must meet before it exits, which were set
+	// by the call frame which entered it.
+	expectedEmitsToMeet []*cheatCodeExpectedEmit
+	// logCountOnEnter describes the number of logs emitted in the transaction before this call frame was entered.
+	logCountOnEnter int
+	// expectedEmitFailures describes unmet event expectations within this call frame. These are propagated up the
+	// call stack only if this call frame does not revert, as with any other state.
+	expectedEmitFailures []string
+
 	// vmPc describes the current call frame's program counter.
 	vmPc uint64
 	// vmOp describes the current call frame's last instruction executed.
@@ -67,11 +153,18 @@ type cheatCodeTracerCallFrame struct {
 type cheatCodeTracerResults struct {
 	// onChainRevertHooks describes hooks which are to be executed when the chain reverts.
 	onChainRevertHooks types.GenericHookFuncs
+
+	// expectedEmitFailures describes unmet event expectations set through cheat codes.
+	expectedEmitFailures []string
 }
 
 // newCheatCodeTracer creates a cheatCodeTracer and returns it.
 func newCheatCodeTracer() *cheatCodeTracer {
-	tracer := &cheatCodeTracer{}
+	tracer := &cheatCodeTracer{
+		snapshots:    make(map[uint64]*cheatCodeStateSnapshot),
+		stateChanges: make(map[common.Address]map[common.Hash]struct{}),
+		mockedCalls:  make(cheatCodeMockedCalls),
+	}
 	return tracer
 }
 
@@ -104,7 +197,16 @@ func (t *cheatCodeTracer) CaptureTxStart(gasLimit uint64) {
 	t.callDepth = 0
 	t.callFrames = make([]*cheatCodeTracerCallFrame, 0)
 	t.results = &cheatCodeTracerResults{
-		onChainRevertHooks: nil,
+		onChainRevertHooks:   nil,
+		expectedEmitFailures: nil,
+	}
+
+	// Snapshots taken in a previous transaction can still be reverted to. If there are none, we no longer need to
+	// track the state changes since they were taken.
+	t.transactionsStarted++
+	t.callFramesEntered = 0
+	if len(t.snapshots) == 0 {
+		t.stateChanges = make(map[common.Address]map[common.Hash]struct{})
 	}
 }
 
@@ -118,22 +220,38 @@ func (t *cheatCodeTracer) CaptureStart(env *vm.EVM, from common.Address, to comm
 	// Store our evm reference
 	t.evm = env
 
+	// The transaction pays for gas from its sender, and pays fees to the coinbase.
+	t.recordStateChange(from, nil)
+	t.recordStateChange(to, nil)
+	t.recordStateChange(env.Context.Coinbase, nil)
+
 	// Create our call frame struct to track data for this initial entry call frame.
-	callFrameData := &cheatCodeTracerCallFrame{}
+	callFrameData := &cheatCodeTracerCallFrame{
+		enteredIndex: t.callFramesEntered,
+	}
+	t.callFramesEntered++
 	t.callFrames = append(t.callFrames, callFrameData)
 }
 
 // CaptureEnd is called after a call to finalize tracing completes for the top of a call frame, as defined by vm.EVMLogger.
 func (t *cheatCodeTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	// If this call frame made a mocked call which did not enter the target (e.g. due to insufficient balance),
+	// restore the target's code now.
+	t.restoreMockedCallCode()
+
 	// Execute all current call frame exit hooks
 	exitingCallFrame := t.callFrames[t.callDepth]
 	exitingCallFrame.onFrameExitRestoreHooks.Execute(false, true)
 	exitingCallFrame.onTopFrameExitRestoreHooks.Execute(false, true)
 
+	// Check any event expectations this call frame set which were never met by a call.
+	t.checkUnusedExpectedEmits(exitingCallFrame)
+
 	// If we didn't encounter an error in this call frame, we push our upward propagating revert events up one frame.
 	if err == nil {
-		// Store these revert hooks in our results.
+		// Store these revert hooks and unmet event expectations in our results.
 		t.results.onChainRevertHooks = append(t.results.onChainRevertHooks, exitingCallFrame.onChainRevertRestoreHooks...)
+		t.results.expectedEmitFailures = append(t.results.expectedEmitFailures, exitingCallFrame.expectedEmitFailures...)
 	} else {
 		// We hit an error, so a revert occurred before this tx was committed.
 		exitingCallFrame.onChainRevertRestoreHooks.Execute(false, true)
@@ -150,30 +268,63 @@ func (t *cheatCodeTracer) CaptureEnter(typ vm.OpCode, from common.Address, to co
 
 	// Increase our call depth now that we're entering a new call frame.
 	t.callDepth++
+	t.recordStateChange(from, nil)
+	t.recordStateChange(to, nil)
 
 	// Create our call frame struct to track data for this initial entry call frame.
 	// We forward our "next frame hooks" to this frame, then clear them from the previous frame.
 	callFrameData := &cheatCodeTracerCallFrame{
+		enteredIndex:            t.callFramesEntered,
 		onFrameExitRestoreHooks: previousCallFrame.onNextFrameExitRestoreHooks,
 	}
+	t.callFramesEntered++
 	previousCallFrame.onNextFrameExitRestoreHooks = nil
 	t.callFrames = append(t.callFrames, callFrameData)
 
+	// If the previous call frame set event expectations, this call must meet them. Calls to cheat code contracts
+	// are not subject to expectations.
+	if len(previousCallFrame.expectedEmits) > 0 && !t.isCheatCodeContract(to) {
+		callFrameData.expectedEmitsToMeet = previousCallFrame.expectedEmits
+		callFrameData.logCountOnEnter = len(t.evm.StateDB.(LogsStateDB).Logs())
+		previousCallFrame.expectedEmits = nil
+	}
+
 	// Note: We do not execute events for "next frame enter" here, as we do not yet have scope information.
 	// Those events are executed when the first EVM instruction is executed in the new scope.
 }
 
 // CaptureExit is called upon exiting of the call frame, as defined by vm.EVMLogger.
 func (t *cheatCodeTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	// If this call frame made a mocked call which did not enter the target (e.g. due to insufficient balance),
+	// restore the target's code now.
+	t.restoreMockedCallCode()
+
 	// Execute all current call frame exit hooks
 	exitingCallFrame := t.callFrames[t.callDepth]
 	exitingCallFrame.onFrameExitRestoreHooks.Execute(false, true)
 	parentCallFrame := t.callFrames[t.callDepth-1]
 
+	// Check the event expectations this call frame had to meet. If any are unmet, the failure belongs to the parent
+	// call frame which set them, so it is not discarded if this call frame reverted.
+	if len(exitingCallFrame.expectedEmitsToMeet) > 0 {
+		var emittedLogs []*coreTypes.Log
+		reason := "call reverted"
+		if err == nil {
+			emittedLogs = t.evm.StateDB.(LogsStateDB).Logs()[exitingCallFrame.logCountOnEnter:]
+			reason = "call completed"
+		}
+		failures := matchExpectedEmits(exitingCallFrame.expectedEmitsToMeet, emittedLogs, reason)
+		parentCallFrame.expectedEmitFailures = append(parentCallFrame.expectedEmitFailures, failures...)
+	}
+
+	// Check any event expectations this call frame set which were never met by a call.
+	t.checkUnusedExpectedEmits(exitingCallFrame)
+
 	// If we didn't encounter an error in this call frame, we push our upward propagating revert events up one frame.
 	if err == nil {
 		parentCallFrame.onTopFrameExitRestoreHooks = append(parentCallFrame.onTopFrameExitRestoreHooks, exitingCallFrame.onTopFrameExitRestoreHooks...)
 		parentCallFrame.onChainRevertRestoreHooks = append(parentCallFrame.onChainRevertRestoreHooks, exitingCallFrame.onChainRevertRestoreHooks...)
+		parentCallFrame.expectedEmitFailures = append(parentCallFrame.expectedEmitFailures, exitingCallFrame.expectedEmitFailures...)
 	} else {
 		// We hit an error, so a revert occurred before this tx was committed.
 		exitingCallFrame.onChainRevertRestoreHooks.Execute(false, true)
@@ -200,6 +351,227 @@ func (t *cheatCodeTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64
 	if t.callDepth > 0 {
 		t.callFrames[t.callDepth-1].onNextFrameEnterHooks.Execute(true, true)
 	}
+
+	// If this call frame resumed after making a mocked call, restore the target's code. Then check if we are about
+	// to make a mocked call.
+	t.restoreMockedCallCode()
+	t.captureMockedCall(op, scope)
+
+	// If this call frame is awaiting a template log for an event expectation, capture it.
+	t.captureExpectedEmitTemplate(op, scope)
+
+	// If we are about to write to storage, record the slot written.
+	if op == vm.SSTORE && len(scope.Stack.Data()) >= 1 {
+		slot := common.Hash(scope.Stack.Back(0).Bytes32())
+		t.recordStateChange(scope.Contract.Address(), &slot)
+	}
+}
+
+// recordStateChange records that the account at the provided address, and the provided storage slot of it if it is
+// not nil, may have changed, so they can be restored when reverting to a snapshot taken in a previous transaction.
+func (t *cheatCodeTracer) recordStateChange(address common.Address, slot *common.Hash) {
+	slots, ok := t.stateChanges[address]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		t.stateChanges[address] = slots
+	}
+	if slot != nil {
+		slots[*slot] = struct{}{}
+	}
+}
+
+// copyState obtains a copy of the state the EVM is currently executing over, for a snapshot to be restored from. If
+// the chain is forking a remote chain, the copy is wrapped to fetch missing state from the remote chain, and the
+// wrapper is returned alongside it.
+// Returns the copied state, or nil if the state cannot be copied.
+func (t *cheatCodeTracer) copyState() (vm.StateDB, *forkStateDB) {
+	switch stateDB := t.evm.StateDB.(type) {
+	case *state.StateDB:
+		return t.chain.evmStateDB(stateDB.Copy())
+	case *forkStateDB:
+		return t.chain.evmStateDB(stateDB.StateDB.Copy())
+	default:
+		return nil, nil
+	}
+}
+
+// restoreSnapshotState restores the state the EVM is executing over to that of the provided snapshot, which was taken
+// in a previous transaction, by restoring each account and storage slot which may have changed since. The changes are
+// journaled as any other, so they are undone if the current call frame reverts.
+// Note: The storage of accounts which self-destructed since the snapshot was taken is only restored for the slots
+// written since.
+// Returns an error if remote state could not be fetched while restoring it.
+func (t *cheatCodeTracer) restoreSnapshotState(snapshot *cheatCodeStateSnapshot) error {
+	stateDB := t.evm.StateDB
+	for address, slots := range t.stateChanges {
+		// Restore our account's balance, nonce and code.
+		balanceDiff := new(big.Int).Sub(snapshot.state.GetBalance(address), stateDB.GetBalance(address))
+		if balanceDiff.Sign() != 0 {
+			stateDB.AddBalance(address, balanceDiff)
+		}
+		if nonce := snapshot.state.GetNonce(address); nonce != stateDB.GetNonce(address) {
+			stateDB.SetNonce(address, nonce)
+		}
+		if codeHash := snapshot.state.GetCodeHash(address); codeHash != stateDB.GetCodeHash(address) {
+			stateDB.SetCode(address, snapshot.state.GetCode(address))
+		}
+
+		// Restore the storage slots written.
+		for slot := range slots {
+			if value := snapshot.state.GetState(address, slot); value != stateDB.GetState(address, slot) {
+				stateDB.SetState(address, slot, value)
+			}
+		}
+	}
+	if snapshot.forkState != nil && snapshot.forkState.Error() != nil {
+		return snapshot.forkState.Error()
+	}
+	return nil
+}
+
+// isCheatCodeContract checks whether the provided address is that of a cheat code contract installed in the chain.
+// Returns true if the address is a cheat code contract.
+func (t *cheatCodeTracer) isCheatCodeContract(address common.Address) bool {
+	_, ok := t.chain.vmConfigExtensions.AdditionalPrecompiles[address].(*CheatCodeContract)
+	return ok
+}
+
+// captureExpectedEmitTemplate checks whether the provided operation emits a log from a call frame awaiting a template
+// log for an event expectation. If so, the log about to be emitted is captured as the template, and the expectation
+// is queued for the next call the call frame makes.
+func (t *cheatCodeTracer) captureExpectedEmitTemplate(op vm.OpCode, scope *vm.ScopeContext) {
+	// If this call frame is not awaiting a template, or this is not a log operation, there is nothing to do.
+	currentCallFrame := t.CurrentCallFrame()
+	if currentCallFrame.pendingExpectedEmit == nil || op < vm.LOG0 || op > vm.LOG4 {
+		return
+	}
+
+	// Obtain the topics and data location for the log from the stack.
+	topicCount := int(op - vm.LOG0)
+	stack := scope.Stack
+	if len(stack.Data()) < 2+topicCount {
+		return
+	}
+	topics := make([]common.Hash, topicCount)
+	for i := 0; i < topicCount; i++ {
+		topics[i] = common.Hash(stack.Back(2 + i).Bytes32())
+	}
+	dataOffset, dataSize := stack.Back(0), stack.Back(1)
+	if !dataSize.IsUint64() || (!dataSize.IsZero() && !dataOffset.IsUint64()) {
+		return
+	}
+
+	// Read the data from memory. The tracer is invoked before memory is expanded, so any data beyond the current
+	// memory size is zero.
+	data := make([]byte, dataSize.Uint64())
+	memory := scope.Memory.Data()
+	if dataOffset.IsUint64() && dataOffset.Uint64() < uint64(len(memory)) {
+		copy(data, memory[dataOffset.Uint64():])
+	}
+
+	// Record our template and queue our expectation.
+	expectedEmit := currentCallFrame.pendingExpectedEmit
+	expectedEmit.template = &coreTypes.Log{
+		Address: scope.Contract.Address(),
+		Topics:  topics,
+		Data:    data,
+	}
+	currentCallFrame.expectedEmits = append(currentCallFrame.expectedEmits, expectedEmit)
+	currentCallFrame.pendingExpectedEmit = nil
+}
+
+// checkUnusedExpectedEmits records failures in the provided exiting call frame for any event expectations it set
+// which no call was made to meet.
+func (t *cheatCodeTracer) checkUnusedExpectedEmits(exitingCallFrame *cheatCodeTracerCallFrame) {
+	unusedExpectedEmits := exitingCallFrame.expectedEmits
+	if exitingCallFrame.pendingExpectedEmit != nil {
+		unusedExpectedEmits = append(unusedExpectedEmits, exitingCallFrame.pendingExpectedEmit)
+	}
+	failures := matchExpectedEmits(unusedExpectedEmits, nil, "no call was made after it was expected")
+	exitingCallFrame.expectedEmitFailures = append(exitingCallFrame.expectedEmitFailures, failures...)
+	exitingCallFrame.expectedEmits = nil
+	exitingCallFrame.pendingExpectedEmit = nil
+}
+
+// setMockedCalls sets the calls mocked through cheat codes. The previous mocked calls are restored if this code
+// path reverts, or the chain reverts the transaction making this change.
+func (t *cheatCodeTracer) setMockedCalls(mockedCalls cheatCodeMockedCalls) {
+	original := t.mockedCalls
+	t.mockedCalls = mockedCalls
+	t.CurrentCallFrame().onChainRevertRestoreHooks.Push(func() {
+		t.mockedCalls = original
+	})
+}
+
+// restoreMockedCallCode executes any pending function restoring the code of the target of a mocked call, if the
+// current call frame is the one which made the mocked call.
+func (t *cheatCodeTracer) restoreMockedCallCode() {
+	if t.pendingMockedCallRestore != nil && t.pendingMockedCallDepth == t.callDepth {
+		restore := t.pendingMockedCallRestore
+		t.pendingMockedCallRestore = nil
+		restore()
+	}
+}
+
+// captureMockedCall checks whether the provided operation is a call which matches a call mocked through cheat codes.
+// If so, the target's code is temporarily replaced with a stub which returns the mocked data, and restored once the
+// stub begins executing (the EVM will already have loaded the code it executes), as well as once the calling frame
+// resumes in case the stub's execution was reverted.
+func (t *cheatCodeTracer) captureMockedCall(op vm.OpCode, scope *vm.ScopeContext) {
+	// If we have no mocked calls, or this is not a call, there is nothing to do.
+	if len(t.mockedCalls) == 0 || (op != vm.CALL && op != vm.CALLCODE && op != vm.DELEGATECALL && op != vm.STATICCALL) {
+		return
+	}
+
+	// Obtain our call target, value, and the stack index of the call data location.
+	stack := scope.Stack
+	if len(stack.Data()) < 6 {
+		return
+	}
+	target := common.Address(stack.Back(1).Bytes20())
+	value := new(big.Int)
+	calldataIndex := 2
+	if op == vm.CALL || op == vm.CALLCODE {
+		if len(stack.Data()) < 7 {
+			return
+		}
+		value = stack.Back(2).ToBig()
+		calldataIndex = 3
+	} else if op == vm.DELEGATECALL {
+		value = scope.Contract.Value()
+	}
+
+	// Read our call data from memory. The tracer is invoked before memory is expanded, so any data beyond the
+	// current memory size is zero.
+	calldataOffset, calldataSize := stack.Back(calldataIndex), stack.Back(calldataIndex+1)
+	if !calldataSize.IsUint64() || (!calldataSize.IsZero() && !calldataOffset.IsUint64()) {
+		return
+	}
+	calldata := make([]byte, calldataSize.Uint64())
+	memory := scope.Memory.Data()
+	if calldataOffset.IsUint64() && calldataOffset.Uint64() < uint64(len(memory)) {
+		copy(calldata, memory[calldataOffset.Uint64():])
+	}
+
+	// If this call is not mocked, there is nothing to do.
+	mockedCall := t.mockedCalls.match(target, calldata, value)
+	if mockedCall == nil {
+		return
+	}
+
+	// Replace the target's code with our stub, and restore it once the stub begins executing, or this call frame
+	// resumes.
+	stateDB := t.evm.StateDB
+	originalCode := stateDB.GetCode(target)
+	stateDB.SetCode(target, newMockedCallCode(mockedCall.returnData))
+	restore := func() {
+		if !bytes.Equal(stateDB.GetCode(target), originalCode) {
+			stateDB.SetCode(target, originalCode)
+		}
+	}
+	t.CurrentCallFrame().onNextFrameEnterHooks.Push(restore)
+	t.pendingMockedCallRestore = restore
+	t.pendingMockedCallDepth = t.callDepth
 }
 
 // CaptureFault records an execution fault, as defined by vm.EVMLogger.
@@ -211,6 +583,7 @@ func (t *cheatCodeTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64
 // tracer is used during transaction execution (block creation), the results can later be queried from the block.
 // This method will only be called on the added tracer if it implements the extended TestChainTracer interface.
 func (t *cheatCodeTracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
-	// Add our revert operations we collected for this transaction.
+	// Add our revert operations and unmet event expectations we collected for this transaction.
 	results.OnRevertHookFuncs = append(results.OnRevertHookFuncs, t.results.onChainRevertHooks...)
+	results.ExpectedEmitFailures = append(results.ExpectedEmitFailures, t.results.expectedEmitFailures...)
 }
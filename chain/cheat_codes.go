@@ -1,9 +1,11 @@
 package chain
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
 	"fmt"
+	"github.com/crytic/medusa/logging"
 	"github.com/crytic/medusa/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -12,8 +14,13 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// defaultFFITimeout describes the maximum amount of time a command executed by the ffi cheat code may take, if no
+// timeout is provided in the chain configuration.
+const defaultFFITimeout = 60 * time.Second
+
 // getCheatCodeProviders obtains a cheatCodeTracer (used to power cheat code analysis) and associated CheatCodeContract
 // objects linked to the tracer (providing on-chain callable methods as an entry point). These objects are attached to
 // the TestChain to enable cheat code functionality.
@@ -68,6 +75,10 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	if err != nil {
 		return nil, err
 	}
+	typeUint256Slice, err := abi.NewType("uint256[]", "", nil)
+	if err != nil {
+		return nil, err
+	}
 	typeStringSlice, err := abi.NewType("string[]", "", nil)
 	if err != nil {
 		return nil, err
@@ -85,9 +96,16 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	contract.addMethod(
 		"warp", abi.Arguments{{Type: typeUint64}}, abi.Arguments{},
 		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Block timestamps must advance from the parent block, so we cannot warp to or before it.
+			timestamp := inputs[0].(uint64)
+			parentTimestamp := tracer.chain.Head().Header.Time
+			if timestamp <= parentTimestamp {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("warp: timestamp must exceed the parent block timestamp of %d", parentTimestamp)))
+			}
+
 			// Maintain our changes until the transaction exits.
 			original := tracer.evm.Context.Time
-			tracer.evm.Context.Time = inputs[0].(uint64)
+			tracer.evm.Context.Time = timestamp
 			tracer.CurrentCallFrame().onTopFrameExitRestoreHooks.Push(func() {
 				tracer.evm.Context.Time = original
 			})
@@ -99,9 +117,20 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	contract.addMethod(
 		"roll", abi.Arguments{{Type: typeUint256}}, abi.Arguments{},
 		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Block numbers are represented as 64-bit integers and must advance from the parent block, so we cannot
+			// roll beyond that range, or to or before the parent block.
+			blockNumber := inputs[0].(*big.Int)
+			parentBlockNumber := tracer.chain.HeadBlockNumber()
+			if !blockNumber.IsUint64() {
+				return nil, cheatCodeRevertData([]byte("roll: block number exceeds the maximum of 2^64-1"))
+			}
+			if blockNumber.Uint64() <= parentBlockNumber {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("roll: block number must exceed the parent block number of %d", parentBlockNumber)))
+			}
+
 			// Maintain our changes until the transaction exits.
 			original := new(big.Int).Set(tracer.evm.Context.BlockNumber)
-			tracer.evm.Context.BlockNumber.Set(inputs[0].(*big.Int))
+			tracer.evm.Context.BlockNumber.Set(blockNumber)
 			tracer.CurrentCallFrame().onTopFrameExitRestoreHooks.Push(func() {
 				tracer.evm.Context.BlockNumber.Set(original)
 			})
@@ -123,7 +152,7 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 		},
 	)
 
-	// Difficulty: Sets VM block number
+	// Difficulty: Sets VM block difficulty
 	contract.addMethod(
 		"difficulty", abi.Arguments{{Type: typeUint256}}, abi.Arguments{},
 		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
@@ -151,6 +180,21 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 		},
 	)
 
+	// Prevrandao: Sets the block's random value, which block.prevrandao (and block.difficulty since the merge) returns.
+	contract.addMethod(
+		"prevrandao", abi.Arguments{{Type: typeBytes32}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Maintain our changes until the transaction exits.
+			random := common.Hash(inputs[0].([32]byte))
+			originalRandom := tracer.evm.Context.Random
+			tracer.evm.Context.Random = &random
+			tracer.CurrentCallFrame().onTopFrameExitRestoreHooks.Push(func() {
+				tracer.evm.Context.Random = originalRandom
+			})
+			return nil, nil
+		},
+	)
+
 	// ChainId: Sets VM chain ID
 	contract.addMethod(
 		"chainId", abi.Arguments{{Type: typeUint256}}, abi.Arguments{},
@@ -174,6 +218,7 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 			slot := inputs[1].([32]byte)
 			value := inputs[2].([32]byte)
 			tracer.evm.StateDB.SetState(account, slot, value)
+			tracer.recordStateChange(account, (*common.Hash)(&slot))
 			return nil, nil
 		},
 	)
@@ -195,7 +240,13 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
 			account := inputs[0].(common.Address)
 			code := inputs[1].([]byte)
+			previousCode := tracer.evm.StateDB.GetCode(account)
 			tracer.evm.StateDB.SetCode(account, code)
+			tracer.recordStateChange(account, nil)
+
+			// Record the change as a contract deployment, so the etched code can be matched to a known contract
+			// definition.
+			tracer.chain.deploymentsTracer.captureCodeChange(tracer.evm, account, previousCode, code)
 			return nil, nil
 		},
 	)
@@ -209,6 +260,7 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 			originalBalance := tracer.evm.StateDB.GetBalance(account)
 			diff := new(big.Int).Sub(newBalance, originalBalance)
 			tracer.evm.StateDB.AddBalance(account, diff)
+			tracer.recordStateChange(account, nil)
 			return nil, nil
 		},
 	)
@@ -230,6 +282,165 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 			account := inputs[0].(common.Address)
 			nonce := inputs[1].(uint64)
 			tracer.evm.StateDB.SetNonce(account, nonce)
+			tracer.recordStateChange(account, nil)
+			return nil, nil
+		},
+	)
+
+	// Snapshot: Takes a snapshot of the current state and block context, returning an ID which can be reverted to.
+	contract.addMethod(
+		"snapshot", abi.Arguments{}, abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Record our state revision and block context.
+			snapshotId := tracer.nextSnapshotId
+			tracer.nextSnapshotId++
+			snapshotState, snapshotForkState := tracer.copyState()
+			tracer.snapshots[snapshotId] = &cheatCodeStateSnapshot{
+				transaction:       tracer.transactionsStarted,
+				stateRevision:     tracer.evm.StateDB.Snapshot(),
+				state:             snapshotState,
+				forkState:         snapshotForkState,
+				callFramesEntered: tracer.callFramesEntered,
+				time:              tracer.evm.Context.Time,
+				blockNumber:       new(big.Int).Set(tracer.evm.Context.BlockNumber),
+				baseFee:           new(big.Int).Set(tracer.evm.Context.BaseFee),
+				difficulty:        new(big.Int).Set(tracer.evm.Context.Difficulty),
+				random:            tracer.evm.Context.Random,
+				coinbase:          tracer.evm.Context.Coinbase,
+			}
+
+			// If this code path reverts, or the chain reverts the transaction, the state no longer exists, so the
+			// snapshot is invalidated.
+			tracer.CurrentCallFrame().onChainRevertRestoreHooks.Push(func() {
+				delete(tracer.snapshots, snapshotId)
+			})
+			return []any{new(big.Int).SetUint64(snapshotId)}, nil
+		},
+	)
+
+	// RevertTo: Reverts the state and block context to a snapshot previously taken.
+	contract.addMethod(
+		"revertTo", abi.Arguments{{Type: typeUint256}}, abi.Arguments{{Type: typeBool}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Verify the snapshot is one we can revert to.
+			snapshotIdInput := inputs[0].(*big.Int)
+			if !snapshotIdInput.IsUint64() {
+				return nil, cheatCodeRevertData([]byte("revertTo: invalid snapshot ID"))
+			}
+			snapshotId := snapshotIdInput.Uint64()
+			snapshot, ok := tracer.snapshots[snapshotId]
+			if !ok {
+				return nil, cheatCodeRevertData([]byte("revertTo: invalid snapshot ID"))
+			}
+
+			if snapshot.transaction == tracer.transactionsStarted {
+				// Verify no calling frame was entered after the snapshot, as reverting would discard the state revision
+				// it reverts to if it fails.
+				for _, callFrame := range tracer.callFrames[:tracer.callDepth] {
+					if callFrame.enteredIndex >= snapshot.callFramesEntered {
+						return nil, cheatCodeRevertData([]byte("revertTo: cannot revert to a snapshot taken before the current call was entered"))
+					}
+				}
+
+				// Revert our state, then take a new revision, as reverting to a revision discards it too, to allow
+				// reverting to this snapshot again.
+				tracer.evm.StateDB.RevertToSnapshot(snapshot.stateRevision)
+				snapshot.stateRevision = tracer.evm.StateDB.Snapshot()
+			} else {
+				// The snapshot was taken in a previous transaction, so its state revision no longer exists. Instead, we
+				// restore the state which changed since from the state it copied.
+				if snapshot.state == nil {
+					return nil, cheatCodeRevertData([]byte("revertTo: the state of the snapshot could not be recorded"))
+				}
+				if err := tracer.restoreSnapshotState(snapshot); err != nil {
+					return nil, cheatCodeRevertData([]byte(fmt.Sprintf("revertTo: could not fetch forked chain state: %v", err)))
+				}
+			}
+
+			// Any snapshot taken after this one refers to a discarded state, so it is invalidated.
+			for otherSnapshotId := range tracer.snapshots {
+				if otherSnapshotId > snapshotId {
+					delete(tracer.snapshots, otherSnapshotId)
+				}
+			}
+
+			// Revert our block context. Any restore hooks previously registered by other cheat codes still restore
+			// their original values when the transaction exits.
+			tracer.evm.Context.Time = snapshot.time
+			tracer.evm.Context.BlockNumber.Set(snapshot.blockNumber)
+			tracer.evm.Context.BaseFee.Set(snapshot.baseFee)
+			tracer.evm.Context.Difficulty.Set(snapshot.difficulty)
+			tracer.evm.Context.Random = snapshot.random
+			tracer.evm.Context.Coinbase = snapshot.coinbase
+			return []any{true}, nil
+		},
+	)
+
+	// MockCall: Mocks calls to an address with call data beginning with the provided data, returning the provided
+	// return data instead of executing the address's code.
+	contract.addMethod(
+		"mockCall", abi.Arguments{{Type: typeAddress}, {Type: typeBytes}, {Type: typeBytes}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.setMockedCalls(tracer.mockedCalls.with(inputs[0].(common.Address), &cheatCodeMockedCall{
+				calldata:   inputs[1].([]byte),
+				value:      nil,
+				returnData: inputs[2].([]byte),
+			}))
+			return nil, nil
+		},
+	)
+
+	// MockCall: Mocks calls to an address with the provided call value and call data beginning with the provided
+	// data, returning the provided return data instead of executing the address's code.
+	contract.addMethod(
+		"mockCall", abi.Arguments{{Type: typeAddress}, {Type: typeUint256}, {Type: typeBytes}, {Type: typeBytes}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.setMockedCalls(tracer.mockedCalls.with(inputs[0].(common.Address), &cheatCodeMockedCall{
+				calldata:   inputs[2].([]byte),
+				value:      new(big.Int).Set(inputs[1].(*big.Int)),
+				returnData: inputs[3].([]byte),
+			}))
+			return nil, nil
+		},
+	)
+
+	// ClearMockedCalls: Removes all mocked calls.
+	contract.addMethod(
+		"clearMockedCalls", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.setMockedCalls(make(cheatCodeMockedCalls))
+			return nil, nil
+		},
+	)
+
+	// ExpectEmit: Expects the next call made by the caller to emit an event matching the next event the caller emits,
+	// checking the indexed topics and data as specified.
+	expectEmit := func(tracer *cheatCodeTracer, checkTopics [3]bool, checkData bool, emitter *common.Address) {
+		tracer.PreviousCallFrame().pendingExpectedEmit = &cheatCodeExpectedEmit{
+			checkTopics: checkTopics,
+			checkData:   checkData,
+			emitter:     emitter,
+		}
+	}
+	contract.addMethod(
+		"expectEmit", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			expectEmit(tracer, [3]bool{true, true, true}, true, nil)
+			return nil, nil
+		},
+	)
+	contract.addMethod(
+		"expectEmit", abi.Arguments{{Type: typeBool}, {Type: typeBool}, {Type: typeBool}, {Type: typeBool}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			expectEmit(tracer, [3]bool{inputs[0].(bool), inputs[1].(bool), inputs[2].(bool)}, inputs[3].(bool), nil)
+			return nil, nil
+		},
+	)
+	contract.addMethod(
+		"expectEmit", abi.Arguments{{Type: typeBool}, {Type: typeBool}, {Type: typeBool}, {Type: typeBool}, {Type: typeAddress}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			emitter := inputs[4].(common.Address)
+			expectEmit(tracer, [3]bool{inputs[0].(bool), inputs[1].(bool), inputs[2].(bool)}, inputs[3].(bool), &emitter)
 			return nil, nil
 		},
 	)
@@ -315,11 +526,24 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 				args = cmdAndInputs[1:]
 			}
 
-			// Create our command
-			cmd := exec.Command(command, args...)
-
-			// Execute it and grab the output
-			stdout, _, combined, err := utils.RunCommandWithOutputAndError(cmd)
+			// Create our command, which is killed if it does not complete within our timeout.
+			timeout := time.Duration(tracer.chain.testChainConfig.CheatCodeConfig.FFITimeout) * time.Second
+			if timeout == 0 {
+				timeout = defaultFFITimeout
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			cmd := exec.CommandContext(ctx, command, args...)
+
+			// Execute it and grab the output. Any errors output by the command are logged for debugging.
+			stdout, stderr, combined, err := utils.RunCommandWithOutputAndError(cmd)
+			if len(stderr) > 0 {
+				logging.GlobalLogger.Debug().Str("command", command).Str("stderr", string(stderr)).
+					Msgf("ffi command %v output to stderr: %v", command, string(stderr))
+			}
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("ffi: cmd timed out after %v", timeout)))
+			}
 			if err != nil {
 				errorMsg := fmt.Sprintf("ffi: cmd failed with the following error: %v\nOutput: %v", err, string(combined))
 				return nil, cheatCodeRevertData([]byte(errorMsg))
@@ -340,8 +564,11 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	// addr: Compute the address for a given private key
 	contract.addMethod("addr", abi.Arguments{{Type: typeUint256}}, abi.Arguments{{Type: typeAddress}},
 		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
-			// Using TOECDSAUnsafe b/c the private key is guaranteed to be of length 256 bits, at most
-			privateKey := crypto.ToECDSAUnsafe(inputs[0].(*big.Int).Bytes())
+			// The private key is at most 256 bits, but it must also be a valid secp256k1 scalar.
+			privateKey, err := crypto.ToECDSA(common.LeftPadBytes(inputs[0].(*big.Int).Bytes(), 32))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("addr: invalid private key"))
+			}
 
 			// Get ECDSA public key
 			publicKey := privateKey.Public().(*ecdsa.PublicKey)
@@ -357,8 +584,11 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	contract.addMethod("sign", abi.Arguments{{Type: typeUint256}, {Type: typeBytes32}},
 		abi.Arguments{{Type: typeUint8}, {Type: typeBytes32}, {Type: typeBytes32}},
 		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
-			// Using TOECDSAUnsafe b/c the private key is guaranteed to be of length 256 bits, at most
-			privateKey := crypto.ToECDSAUnsafe(inputs[0].(*big.Int).Bytes())
+			// The private key is at most 256 bits, but it must also be a valid secp256k1 scalar.
+			privateKey, err := crypto.ToECDSA(common.LeftPadBytes(inputs[0].(*big.Int).Bytes(), 32))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("sign: invalid private key"))
+			}
 			digest := inputs[1].([32]byte)
 
 			// Sign digest
@@ -380,6 +610,18 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 		},
 	)
 
+	// signerKeys: Obtains the private keys the fuzzer uses to produce signatures, so contracts can sign as the
+	// accounts they control. Configuring sender addresses as these accounts allows signing as the fuzzing senders.
+	contract.addMethod("signerKeys", abi.Arguments{}, abi.Arguments{{Type: typeUint256Slice}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			signerKeys := make([]*big.Int, len(tracer.chain.SignerKeys))
+			for i, signerKey := range tracer.chain.SignerKeys {
+				signerKeys[i] = new(big.Int).Set(signerKey.D)
+			}
+			return []any{signerKeys}, nil
+		},
+	)
+
 	// toString(address): Convert address to string
 	contract.addMethod("toString", abi.Arguments{{Type: typeAddress}}, abi.Arguments{{Type: typeString}},
 		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
@@ -502,6 +744,12 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 		},
 	)
 
+	// Add our environment variable cheat codes.
+	err = addEnvironmentCheatCodes(contract)
+	if err != nil {
+		return nil, err
+	}
+
 	// Return our precompile contract information.
 	return contract, nil
 }
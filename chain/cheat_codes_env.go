@@ -0,0 +1,265 @@
+package chain
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// cheatCodeEnvType describes a type which environment variables can be read as through cheat codes.
+type cheatCodeEnvType struct {
+	// name describes the name used for the type in the cheat code method reading it (e.g. "Uint" for envUint).
+	name string
+
+	// abiType describes the ABI type environment variables are read as.
+	abiType string
+
+	// parse parses an environment variable value as the type.
+	// Returns the parsed value, or an error if the value is malformed.
+	parse func(value string) (any, error)
+}
+
+// cheatCodeEnvTypes describes all types which environment variables can be read as through cheat codes.
+var cheatCodeEnvTypes = []cheatCodeEnvType{
+	{name: "Bool", abiType: "bool", parse: parseEnvBool},
+	{name: "Uint", abiType: "uint256", parse: parseEnvUint},
+	{name: "Int", abiType: "int256", parse: parseEnvInt},
+	{name: "Address", abiType: "address", parse: parseEnvAddress},
+	{name: "Bytes32", abiType: "bytes32", parse: parseEnvBytes32},
+	{name: "String", abiType: "string", parse: func(value string) (any, error) { return value, nil }},
+	{name: "Bytes", abiType: "bytes", parse: parseEnvBytes},
+}
+
+// parseEnvBool parses an environment variable value as a bool.
+// Returns the parsed value, or an error if the value is malformed.
+func parseEnvBool(value string) (any, error) {
+	switch strings.ToLower(value) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return nil, fmt.Errorf("expected 'true' or 'false'")
+}
+
+// parseEnvInteger parses an environment variable value as a decimal or "0x" prefixed hexadecimal integer.
+// Returns the parsed value, or an error if the value is malformed.
+func parseEnvInteger(value string) (*big.Int, error) {
+	// Extract any sign, so we can determine the base of the remaining value.
+	negative := strings.HasPrefix(value, "-")
+	unsigned := strings.TrimPrefix(strings.TrimPrefix(value, "-"), "+")
+
+	// Parse the value in the base indicated by its prefix.
+	base := 10
+	if strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X") {
+		base = 16
+		unsigned = unsigned[2:]
+	}
+	n, ok := new(big.Int).SetString(unsigned, base)
+	if !ok || strings.HasPrefix(unsigned, "-") || strings.HasPrefix(unsigned, "+") {
+		return nil, fmt.Errorf("expected a decimal or hexadecimal integer")
+	}
+	if negative {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// parseEnvUint parses an environment variable value as a uint256.
+// Returns the parsed value, or an error if the value is malformed.
+func parseEnvUint(value string) (any, error) {
+	n, err := parseEnvInteger(value)
+	if err != nil {
+		return nil, err
+	}
+	if n.Sign() < 0 || n.BitLen() > 256 {
+		return nil, fmt.Errorf("value is out of range of uint256")
+	}
+	return n, nil
+}
+
+// parseEnvInt parses an environment variable value as an int256.
+// Returns the parsed value, or an error if the value is malformed.
+func parseEnvInt(value string) (any, error) {
+	n, err := parseEnvInteger(value)
+	if err != nil {
+		return nil, err
+	}
+	if n.Cmp(abi.MaxInt256) > 0 || n.Cmp(new(big.Int).Neg(new(big.Int).Add(abi.MaxInt256, big.NewInt(1)))) < 0 {
+		return nil, fmt.Errorf("value is out of range of int256")
+	}
+	return n, nil
+}
+
+// parseEnvAddress parses an environment variable value as an address.
+// Returns the parsed value, or an error if the value is malformed.
+func parseEnvAddress(value string) (any, error) {
+	return utils.HexStringToAddress(value)
+}
+
+// parseEnvBytes32 parses an environment variable value as a "0x" prefixed hex string of up to 32 bytes, which is
+// right-padded with zeros.
+// Returns the parsed value, or an error if the value is malformed.
+func parseEnvBytes32(value string) (any, error) {
+	b, err := hexutil.Decode(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 32 {
+		return nil, fmt.Errorf("value exceeds 32 bytes")
+	}
+	var b32 [32]byte
+	copy(b32[:], b)
+	return b32, nil
+}
+
+// parseEnvBytes parses an environment variable value as a "0x" prefixed hex string.
+// Returns the parsed value, or an error if the value is malformed.
+func parseEnvBytes(value string) (any, error) {
+	return hexutil.Decode(value)
+}
+
+// addEnvironmentCheatCodes adds cheat code methods to the provided contract which read environment variables of the
+// medusa process, as each type in cheatCodeEnvTypes, as well as delimited arrays of each type. If environment access
+// is disabled in the chain configuration, reading a variable reverts, while reading it with a default value returns
+// the default.
+// Returns an error if one occurs.
+func addEnvironmentCheatCodes(contract *CheatCodeContract) error {
+	typeString, err := abi.NewType("string", "", nil)
+	if err != nil {
+		return err
+	}
+
+	for _, envType := range cheatCodeEnvTypes {
+		// Capture our type for use in our method handlers.
+		envType := envType
+		abiType, err := abi.NewType(envType.abiType, "", nil)
+		if err != nil {
+			return err
+		}
+		abiArrayType, err := abi.NewType(envType.abiType+"[]", "", nil)
+		if err != nil {
+			return err
+		}
+		methodName := "env" + envType.name
+
+		// lookupEnv obtains the value of the provided environment variable, or a revert describing why it could not
+		// be obtained.
+		lookupEnv := func(tracer *cheatCodeTracer, key string) (string, bool, *cheatCodeRawReturnData) {
+			if !tracer.chain.testChainConfig.CheatCodeConfig.EnvironmentAccessEnabled {
+				return "", false, cheatCodeRevertData([]byte(fmt.Sprintf("%v: environment access is not enabled in the chain configuration", methodName)))
+			}
+			value, ok := os.LookupEnv(key)
+			return value, ok, nil
+		}
+
+		// parseValue parses the provided environment variable value as our type.
+		parseValue := func(key string, value string) (any, *cheatCodeRawReturnData) {
+			parsed, err := envType.parse(value)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("%v: failed to parse environment variable \"%v\" as %v: %v", methodName, key, envType.abiType, err)))
+			}
+			return parsed, nil
+		}
+
+		// parseArray parses the provided environment variable value as an array of our type, split by the provided
+		// delimiter.
+		parseArray := func(key string, value string, delimiter string) (any, *cheatCodeRawReturnData) {
+			array := reflect.MakeSlice(abiArrayType.GetType(), 0, 0)
+			if value == "" {
+				return array.Interface(), nil
+			}
+			for _, element := range strings.Split(value, delimiter) {
+				parsed, revert := parseValue(key, strings.TrimSpace(element))
+				if revert != nil {
+					return nil, revert
+				}
+				array = reflect.Append(array, reflect.ValueOf(parsed))
+			}
+			return array.Interface(), nil
+		}
+
+		// env<Type>(string): Reads an environment variable as our type.
+		contract.addMethod(
+			methodName, abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: abiType}},
+			func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+				key := inputs[0].(string)
+				value, ok, revert := lookupEnv(tracer, key)
+				if revert != nil {
+					return nil, revert
+				}
+				if !ok {
+					return nil, cheatCodeRevertData([]byte(fmt.Sprintf("%v: environment variable \"%v\" not found", methodName, key)))
+				}
+				parsed, revert := parseValue(key, value)
+				if revert != nil {
+					return nil, revert
+				}
+				return []any{parsed}, nil
+			},
+		)
+
+		// env<Type>(string,string): Reads an environment variable as a delimited array of our type.
+		contract.addMethod(
+			methodName, abi.Arguments{{Type: typeString}, {Type: typeString}}, abi.Arguments{{Type: abiArrayType}},
+			func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+				key := inputs[0].(string)
+				value, ok, revert := lookupEnv(tracer, key)
+				if revert != nil {
+					return nil, revert
+				}
+				if !ok {
+					return nil, cheatCodeRevertData([]byte(fmt.Sprintf("%v: environment variable \"%v\" not found", methodName, key)))
+				}
+				parsed, revert := parseArray(key, value, inputs[1].(string))
+				if revert != nil {
+					return nil, revert
+				}
+				return []any{parsed}, nil
+			},
+		)
+
+		// envOr(string,<type>): Reads an environment variable as our type, or returns the provided default if it is
+		// not set or environment access is disabled.
+		contract.addMethod(
+			"envOr", abi.Arguments{{Type: typeString}, {Type: abiType}}, abi.Arguments{{Type: abiType}},
+			func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+				key := inputs[0].(string)
+				value, ok, revert := lookupEnv(tracer, key)
+				if revert != nil || !ok {
+					return []any{inputs[1]}, nil
+				}
+				parsed, revert := parseValue(key, value)
+				if revert != nil {
+					return nil, revert
+				}
+				return []any{parsed}, nil
+			},
+		)
+
+		// envOr(string,string,<type>[]): Reads an environment variable as a delimited array of our type, or returns
+		// the provided default if it is not set or environment access is disabled.
+		contract.addMethod(
+			"envOr", abi.Arguments{{Type: typeString}, {Type: typeString}, {Type: abiArrayType}}, abi.Arguments{{Type: abiArrayType}},
+			func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+				key := inputs[0].(string)
+				value, ok, revert := lookupEnv(tracer, key)
+				if revert != nil || !ok {
+					return []any{inputs[2]}, nil
+				}
+				parsed, revert := parseArray(key, value, inputs[1].(string))
+				if revert != nil {
+					return nil, revert
+				}
+				return []any{parsed}, nil
+			},
+		)
+	}
+	return nil
+}
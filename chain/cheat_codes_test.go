@@ -0,0 +1,265 @@
+package chain
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestChainEtchDeploymentEvents ensures code etched through cheat codes in transactions is reported as a contract
+// deployment, replacing any previous code, and that the events are inverted when the chain reverts.
+func TestChainEtchDeploymentEvents(t *testing.T) {
+	// Create our chain with a funded sender and a target with existing code.
+	sender := common.HexToAddress("0x0707")
+	target := common.HexToAddress("0x1234")
+	genesisAlloc := core.GenesisAlloc{
+		sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		target: {Balance: big.NewInt(0), Code: []byte{0x00}},
+	}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+
+	// Track the deployments added and removed for our target.
+	var added, removed [][]byte
+	chain.Events.ContractDeploymentAddedEventEmitter.Subscribe(func(event ContractDeploymentsAddedEvent) error {
+		if event.Contract.Address == target {
+			added = append(added, event.Contract.RuntimeBytecode)
+		}
+		return nil
+	})
+	chain.Events.ContractDeploymentRemovedEventEmitter.Subscribe(func(event ContractDeploymentsRemovedEvent) error {
+		if event.Contract.Address == target {
+			removed = append(removed, event.Contract.RuntimeBytecode)
+		}
+		return nil
+	})
+
+	// Create a message which etches new code at our target.
+	cheatCodeAddress := common.HexToAddress("0x7109709ECfa91a80626fF3989D68f67F5b1DD12D")
+	etchedCode := []byte{0x60, 0x00, 0x00}
+	data, err := chain.CheatCodeContracts()[cheatCodeAddress].Abi().Pack("etch(address,bytes)", target, etchedCode)
+	assert.NoError(t, err)
+	msg := types.NewMessage(sender, &cheatCodeAddress, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), data, nil, false)
+
+	// Etching in a call should not report any deployment changes.
+	_, err = chain.CallContract(msg, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+
+	// Etching in a transaction should report the previous code removed and the etched code added.
+	_, err = chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	err = chain.PendingBlockAddTx(msg)
+	assert.NoError(t, err)
+	err = chain.PendingBlockCommit()
+	assert.NoError(t, err)
+	assert.EqualValues(t, etchedCode, chain.State().GetCode(target))
+	assert.EqualValues(t, [][]byte{etchedCode}, added)
+	assert.EqualValues(t, [][]byte{{0x00}}, removed)
+
+	// Reverting the chain should invert these events.
+	err = chain.RevertToBlockNumber(0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, [][]byte{etchedCode, {0x00}}, added)
+	assert.EqualValues(t, [][]byte{{0x00}, etchedCode}, removed)
+}
+
+// TestChainEnvironmentCheatCodes ensures environment variables are read and parsed through cheat codes, reverting
+// with the variable name if they are missing or malformed, and that defaults are used if environment access is
+// disabled.
+func TestChainEnvironmentCheatCodes(t *testing.T) {
+	// Set our environment variables.
+	t.Setenv("MEDUSA_TEST_UINT", "0x2a")
+	t.Setenv("MEDUSA_TEST_INTS", "-1, 2,3")
+	t.Setenv("MEDUSA_TEST_ADDRESS", "0x1234")
+	t.Setenv("MEDUSA_TEST_MALFORMED", "not a number")
+
+	// Create our chain with a funded sender.
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := core.GenesisAlloc{
+		sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+	}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+
+	// callCheatCode calls the provided cheat code method with the provided arguments.
+	// Returns the unpacked return values, or nil and the revert reason if the call reverted.
+	cheatCodeAddress := common.HexToAddress("0x7109709ECfa91a80626fF3989D68f67F5b1DD12D")
+	cheatCodeAbi := chain.CheatCodeContracts()[cheatCodeAddress].Abi()
+	callCheatCode := func(methodSig string, args ...any) ([]any, string) {
+		data, err := cheatCodeAbi.Pack(methodSig, args...)
+		assert.NoError(t, err)
+		msg := types.NewMessage(sender, &cheatCodeAddress, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), data, nil, true)
+		result, err := chain.CallContract(msg, nil)
+		assert.NoError(t, err)
+		if result.Err != nil {
+			return nil, string(result.ReturnData)
+		}
+		values, err := cheatCodeAbi.Methods[methodSig].Outputs.Unpack(result.ReturnData)
+		assert.NoError(t, err)
+		return values, ""
+	}
+
+	// Verify variables are read and parsed.
+	values, _ := callCheatCode("envUint(string)", "MEDUSA_TEST_UINT")
+	assert.EqualValues(t, []any{big.NewInt(42)}, values)
+	values, _ = callCheatCode("envInt(string,string)", "MEDUSA_TEST_INTS", ",")
+	assert.EqualValues(t, []any{[]*big.Int{big.NewInt(-1), big.NewInt(2), big.NewInt(3)}}, values)
+	values, _ = callCheatCode("envAddress(string)", "MEDUSA_TEST_ADDRESS")
+	assert.EqualValues(t, []any{common.HexToAddress("0x1234")}, values)
+	values, _ = callCheatCode("envOr(string,uint256)", "MEDUSA_TEST_MISSING", big.NewInt(7))
+	assert.EqualValues(t, []any{big.NewInt(7)}, values)
+
+	// Verify missing and malformed variables revert with the variable name.
+	_, reason := callCheatCode("envUint(string)", "MEDUSA_TEST_MISSING")
+	assert.Contains(t, reason, "MEDUSA_TEST_MISSING")
+	_, reason = callCheatCode("envUint(string)", "MEDUSA_TEST_MALFORMED")
+	assert.Contains(t, reason, "MEDUSA_TEST_MALFORMED")
+	_, reason = callCheatCode("envOr(string,uint256)", "MEDUSA_TEST_MALFORMED", big.NewInt(7))
+	assert.Contains(t, reason, "MEDUSA_TEST_MALFORMED")
+
+	// Verify disabling environment access causes reads to revert, and defaults to be used.
+	chain.testChainConfig.CheatCodeConfig.EnvironmentAccessEnabled = false
+	_, reason = callCheatCode("envUint(string)", "MEDUSA_TEST_UINT")
+	assert.Contains(t, reason, "environment access is not enabled")
+	values, _ = callCheatCode("envOr(string,uint256)", "MEDUSA_TEST_UINT", big.NewInt(7))
+	assert.EqualValues(t, []any{big.NewInt(7)}, values)
+}
+
+// TestChainFFICheatCode ensures the ffi cheat code only executes commands when enabled, returning their output, and
+// reverts if they exit with a non-zero exit code or exceed the configured timeout.
+func TestChainFFICheatCode(t *testing.T) {
+	// These commands are only available on unix platforms.
+	if utils.IsWindowsEnvironment() {
+		t.Skip("ffi test commands are not available on windows")
+	}
+
+	// Create our chain with a funded sender.
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := core.GenesisAlloc{
+		sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+	}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChainConfig.CheatCodeConfig.FFITimeout = 1
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+
+	// callFFI calls the ffi cheat code with the provided command and arguments.
+	// Returns the output of the command, or nil and the revert reason if the call reverted.
+	cheatCodeAddress := common.HexToAddress("0x7109709ECfa91a80626fF3989D68f67F5b1DD12D")
+	cheatCodeAbi := chain.CheatCodeContracts()[cheatCodeAddress].Abi()
+	callFFI := func(cmdAndInputs ...string) ([]byte, string) {
+		data, err := cheatCodeAbi.Pack("ffi(string[])", cmdAndInputs)
+		assert.NoError(t, err)
+		msg := types.NewMessage(sender, &cheatCodeAddress, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), data, nil, true)
+		result, err := chain.CallContract(msg, nil)
+		assert.NoError(t, err)
+		if result.Err != nil {
+			return nil, string(result.ReturnData)
+		}
+		values, err := cheatCodeAbi.Methods["ffi(string[])"].Outputs.Unpack(result.ReturnData)
+		assert.NoError(t, err)
+		return values[0].([]byte), ""
+	}
+
+	// Verify ffi reverts while disabled.
+	_, reason := callFFI("echo", "-n", "0x1234")
+	assert.Contains(t, reason, "ffi is not enabled")
+	chain.testChainConfig.CheatCodeConfig.EnableFFI = true
+
+	// Verify command output is returned, and failing or slow commands revert.
+	output, _ := callFFI("echo", "-n", "0x1234")
+	assert.EqualValues(t, []byte{0x12, 0x34}, output)
+	_, reason = callFFI("false")
+	assert.Contains(t, reason, "ffi: cmd failed")
+	_, reason = callFFI("sleep", "5")
+	assert.Contains(t, reason, "ffi: cmd timed out")
+
+	// Verify output to stderr is logged at the debug level through our logger, along with the command.
+	originalLogger := logging.GlobalLogger
+	defer func() { logging.GlobalLogger = originalLogger }()
+	var logOutput strings.Builder
+	logging.GlobalLogger = logging.NewLogger(logging.LogFormatJSON, &logOutput)
+	logging.GlobalLogger.SetDebug(true)
+	output, _ = callFFI("sh", "-c", "echo -n 0x1234; echo ffi diagnostics >&2")
+	assert.EqualValues(t, []byte{0x12, 0x34}, output)
+	assert.Contains(t, logOutput.String(), `"command":"sh"`)
+	assert.Contains(t, logOutput.String(), "ffi diagnostics")
+}
+
+// TestChainSnapshotCheatCodesAcrossTransactions ensures snapshots taken through cheat codes can be reverted to in
+// later transactions, restoring the state changed since, and that they are invalidated once the chain reverts the
+// block they were taken in.
+func TestChainSnapshotCheatCodesAcrossTransactions(t *testing.T) {
+	// Create our chain with a funded sender and a target with existing code.
+	sender := common.HexToAddress("0x0707")
+	target := common.HexToAddress("0x1234")
+	genesisAlloc := core.GenesisAlloc{
+		sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		target: {Balance: big.NewInt(0), Code: []byte{0x00}},
+	}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+
+	// sendCheatCode sends a transaction calling the provided cheat code method with the provided arguments, in a
+	// block of its own.
+	// Returns the execution result of the transaction.
+	cheatCodeAddress := common.HexToAddress("0x7109709ECfa91a80626fF3989D68f67F5b1DD12D")
+	cheatCodeAbi := chain.CheatCodeContracts()[cheatCodeAddress].Abi()
+	sendCheatCode := func(methodSig string, args ...any) *core.ExecutionResult {
+		data, err := cheatCodeAbi.Pack(methodSig, args...)
+		assert.NoError(t, err)
+		msg := types.NewMessage(sender, &cheatCodeAddress, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), data, nil, false)
+		block, err := chain.PendingBlockCreate()
+		assert.NoError(t, err)
+		err = chain.PendingBlockAddTx(msg)
+		assert.NoError(t, err)
+		err = chain.PendingBlockCommit()
+		assert.NoError(t, err)
+		return block.MessageResults[0].ExecutionResult
+	}
+
+	// Take a snapshot, then change our target's storage and balance in later transactions.
+	result := sendCheatCode("snapshot()")
+	assert.NoError(t, result.Err)
+	snapshotId := new(big.Int).SetBytes(result.ReturnData)
+	slot, value := common.HexToHash("0x01"), common.HexToHash("0x02")
+	assert.NoError(t, sendCheatCode("store(address,bytes32,bytes32)", target, slot, value).Err)
+	assert.NoError(t, sendCheatCode("deal(address,uint256)", target, big.NewInt(5)).Err)
+	assert.EqualValues(t, value, chain.State().GetState(target, slot))
+	assert.EqualValues(t, big.NewInt(5), chain.State().GetBalance(target))
+
+	// Reverting to the snapshot in a later transaction should restore the state changed since.
+	assert.NoError(t, sendCheatCode("revertTo(uint256)", snapshotId).Err)
+	assert.EqualValues(t, common.Hash{}, chain.State().GetState(target, slot))
+	assert.EqualValues(t, 0, chain.State().GetBalance(target).Sign())
+
+	// Reverting the chain to a block after the snapshot was taken should keep it, while reverting past the block it
+	// was taken in should invalidate it.
+	err = chain.RevertToBlockNumber(2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, value, chain.State().GetState(target, slot))
+	assert.NoError(t, sendCheatCode("revertTo(uint256)", snapshotId).Err)
+	assert.EqualValues(t, common.Hash{}, chain.State().GetState(target, slot))
+	err = chain.RevertToBlockNumber(0)
+	assert.NoError(t, err)
+	result = sendCheatCode("revertTo(uint256)", snapshotId)
+	assert.Error(t, result.Err)
+	assert.Contains(t, string(result.ReturnData), "invalid snapshot ID")
+}
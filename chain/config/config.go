@@ -13,6 +13,49 @@ type TestChainConfig struct {
 
 	// CheatCodeConfig indicates the configuration for EVM cheat codes to use.
 	CheatCodeConfig CheatCodeConfig `json:"cheatCodes"`
+
+	// ConsoleLogEnabled indicates whether the console contract should be installed in the chain, so messages
+	// contracts log through console.log are captured, logged at the debug level and shown in execution traces. Capture
+	// may be disabled to save the cost of decoding messages during long campaigns.
+	ConsoleLogEnabled bool `json:"consoleLogEnabled"`
+
+	// ForkConfig indicates the configuration for forking the state of a remote chain.
+	ForkConfig ForkConfig `json:"forkConfig"`
+
+	// BaseFeeConfig indicates how the base fee of each block produced by the chain is determined.
+	BaseFeeConfig BaseFeeConfig `json:"baseFee"`
+}
+
+// BaseFeeConfig describes how the base fee of each block produced by a test chain is determined. The base fee is
+// exposed to contracts through block.basefee, and is paid (and burned) by EIP-1559 transactions.
+type BaseFeeConfig struct {
+	// Dynamic indicates whether the base fee of each block should be adjusted from that of its parent as EIP-1559
+	// specifies, rising when its parent used more gas than its gas target and falling when it used less. Blocks
+	// skipped between two blocks are treated as empty. If false, every block uses InitialBaseFee.
+	Dynamic bool `json:"dynamic"`
+
+	// InitialBaseFee describes the base fee, in wei, of blocks whose parent is the genesis block, or of every block
+	// if Dynamic is false.
+	InitialBaseFee uint64 `json:"initialBaseFee"`
+}
+
+// ForkConfig describes configuration options used to fuzz against the state of a remote chain. When enabled, any
+// accounts, code and storage which are missing from the test chain are lazily fetched from an RPC endpoint. Accounts
+// created or modified in the test chain shadow the remote state.
+type ForkConfig struct {
+	// ForkModeEnabled indicates whether the test chain should fetch missing state from a remote chain.
+	ForkModeEnabled bool `json:"forkModeEnabled"`
+
+	// RpcUrl describes the URL of the RPC endpoint to fetch remote state from.
+	RpcUrl string `json:"rpcUrl"`
+
+	// RpcBlock describes the block number of the remote chain to fetch state at. If zero, the latest block at the
+	// time the test chain is first created is used.
+	RpcBlock uint64 `json:"rpcBlock"`
+
+	// CacheDirectory describes the directory in which state fetched from the remote chain is cached, so repeated
+	// fuzzing campaigns do not need to fetch it again. If empty, fetched state is only cached in memory.
+	CacheDirectory string `json:"cacheDirectory"`
 }
 
 // CheatCodeConfig describes any configuration options related to the use of vm extensions (a.k.a. cheat codes)
@@ -23,6 +66,14 @@ type CheatCodeConfig struct {
 	// EnableFFI describes whether the FFI cheat code should be enabled. Enablement allows for arbitrary code execution
 	// on the tester's machine
 	EnableFFI bool `json:"enableFFI"`
+
+	// FFITimeout describes the maximum amount of time, in seconds, a command executed by the FFI cheat code may take
+	// before it is killed and the cheat code reverts. If zero, a default timeout is used.
+	FFITimeout uint64 `json:"ffiTimeout"`
+
+	// EnvironmentAccessEnabled describes whether the environment variable cheat codes may read the environment of the
+	// medusa process. If disabled, reading an environment variable reverts, unless a default value is provided.
+	EnvironmentAccessEnabled bool `json:"environmentAccessEnabled"`
 }
 
 // GetVMConfigExtensions derives a vm.ConfigExtensions from the provided TestChainConfig.
@@ -1,5 +1,7 @@
 package config
 
+import "github.com/ethereum/go-ethereum/params"
+
 // DefaultTestChainConfig obtains a default configuration for a chain.TestChain.
 // Returns a TestChainConfig populated with default values.
 func DefaultTestChainConfig() (*TestChainConfig, error) {
@@ -7,8 +9,21 @@ func DefaultTestChainConfig() (*TestChainConfig, error) {
 	config := &TestChainConfig{
 		CodeSizeCheckDisabled: true,
 		CheatCodeConfig: CheatCodeConfig{
-			CheatCodesEnabled: true,
-			EnableFFI:         false,
+			CheatCodesEnabled:        true,
+			EnableFFI:                false,
+			FFITimeout:               60,
+			EnvironmentAccessEnabled: true,
+		},
+		ConsoleLogEnabled: true,
+		ForkConfig: ForkConfig{
+			ForkModeEnabled: false,
+			RpcUrl:          "",
+			RpcBlock:        0,
+			CacheDirectory:  "",
+		},
+		BaseFeeConfig: BaseFeeConfig{
+			Dynamic:        false,
+			InitialBaseFee: params.InitialBaseFee,
 		},
 	}
 
@@ -0,0 +1,212 @@
+package chain
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/crytic/medusa/logging"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/exp/slices"
+)
+
+// ConsoleLogContractAddress describes the address of the console contract which Hardhat's and Foundry's console.log
+// libraries send their log payloads to.
+var ConsoleLogContractAddress = common.HexToAddress("0x000000000000000000636F6e736F6c652e6c6f67")
+
+// consoleLogMultiArgumentTypes describes the argument types which console.log overloads with two to four arguments
+// accept, in any combination.
+var consoleLogMultiArgumentTypes = []string{"uint256", "string", "bool", "address"}
+
+// getConsoleLogContract obtains a CheatCodeContract which implements the console contract, decoding the payloads of
+// every console.log overload and logging them at the debug level, tagged with the address of the contract which
+// logged them.
+// Returns the precompiled contract, or an error if one occurs.
+func getConsoleLogContract(tracer *cheatCodeTracer) (*CheatCodeContract, error) {
+	contract := newCheatCodeContract(tracer, ConsoleLogContractAddress, "console")
+
+	// Every overload shares the same handler, which simply logs its formatted arguments.
+	handler := func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+		// Formatting arguments is only worth it if debug events are logged at all.
+		event := logging.GlobalLogger.Debug()
+		if !event.Enabled() {
+			return nil, nil
+		}
+
+		// The logging contract is the one which made the call to us, or the transaction sender if it called us
+		// directly.
+		caller := tracer.evm.TxContext.Origin
+		if callerFrame := tracer.PreviousCallFrame(); callerFrame != nil && callerFrame.vmScope != nil {
+			caller = callerFrame.vmScope.Contract.Address()
+		}
+		event.Str("contract", caller.String()).Msgf("[console.log] %v: %v", caller.String(), FormatConsoleLogArgs(inputs))
+		return nil, nil
+	}
+
+	// log() and the single argument overloads, each of which also has a type-specific name (e.g. logUint).
+	signatures := []string{"log()"}
+	singleArgumentTypes := []string{"uint256", "int256", "string", "bool", "address", "bytes"}
+	for i := 1; i <= 32; i++ {
+		singleArgumentTypes = append(singleArgumentTypes, fmt.Sprintf("bytes%d", i))
+	}
+	for _, argumentType := range singleArgumentTypes {
+		typedName := strings.TrimSuffix(argumentType, "256")
+		typedName = "log" + strings.ToUpper(typedName[:1]) + typedName[1:]
+		signatures = append(signatures, fmt.Sprintf("log(%v)", argumentType), fmt.Sprintf("%v(%v)", typedName, argumentType))
+	}
+
+	// Overloads with two to four arguments of common types, along with an integer following a string.
+	signatures = append(signatures, "log(string,int256)")
+	argumentTypeCombinations := [][]string{{}}
+	for argumentCount := 1; argumentCount <= 4; argumentCount++ {
+		var nextCombinations [][]string
+		for _, combination := range argumentTypeCombinations {
+			for _, argumentType := range consoleLogMultiArgumentTypes {
+				nextCombinations = append(nextCombinations, append(slices.Clone(combination), argumentType))
+			}
+		}
+		argumentTypeCombinations = nextCombinations
+		if argumentCount >= 2 {
+			for _, combination := range argumentTypeCombinations {
+				signatures = append(signatures, fmt.Sprintf("log(%v)", strings.Join(combination, ",")))
+			}
+		}
+	}
+
+	// Add each overload. Older versions of the console libraries computed the selectors of integer overloads from
+	// the "uint" and "int" type aliases, so we accept those selectors too.
+	for _, signature := range signatures {
+		err := contract.addConsoleLogMethod(signature, signature, handler)
+		if err != nil {
+			return nil, err
+		}
+		if legacySignature := consoleLogLegacySignature(signature); legacySignature != signature {
+			err = contract.addConsoleLogMethod(signature, legacySignature, handler)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return contract, nil
+}
+
+// consoleLogLegacySignature obtains the signature older console libraries computed selectors for the console.log
+// overload with the provided signature from, with "uint256" and "int256" written as "uint" and "int".
+// Returns the legacy signature, which is the provided signature if it has no integer arguments.
+func consoleLogLegacySignature(signature string) string {
+	return strings.ReplaceAll(signature, "int256", "int")
+}
+
+// addConsoleLogMethod adds a console.log overload with the provided signature to the contract, which is called
+// through the selector of the provided selector signature.
+// Returns an error if the signature could not be parsed.
+func (c *CheatCodeContract) addConsoleLogMethod(signature string, selectorSignature string, handler cheatCodeMethodHandler) error {
+	// Parse our method name and argument types from the signature.
+	name, argumentTypesString, _ := strings.Cut(strings.TrimSuffix(signature, ")"), "(")
+	inputs := abi.Arguments{}
+	if argumentTypesString != "" {
+		for _, argumentTypeString := range strings.Split(argumentTypesString, ",") {
+			argumentType, err := abi.NewType(argumentTypeString, "", nil)
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, abi.Argument{Type: argumentType})
+		}
+	}
+
+	// If we are adding the overload under a legacy selector, override the selector and signature the method is
+	// resolved by. Otherwise, add it as any other method.
+	if selectorSignature == signature {
+		c.addMethod(name, inputs, abi.Arguments{}, handler)
+		return nil
+	}
+	method := abi.NewMethod(name, name, abi.Function, "external", false, false, inputs, abi.Arguments{})
+	method.Sig = selectorSignature
+	method.ID = crypto.Keccak256([]byte(selectorSignature))[:4]
+	c.methodInfo[binary.LittleEndian.Uint32(method.ID)] = &cheatCodeMethod{
+		method:  method,
+		handler: handler,
+	}
+	c.abi.Methods[method.Sig] = method
+	return nil
+}
+
+// FormatConsoleLogArgs formats the provided unpacked console.log arguments into the message they log. As with the
+// console.log implementations of other tools, if the first argument is a string, it may contain format specifiers
+// (%s, %d, %i, %o) which are substituted by the following arguments. Any remaining arguments are appended, separated
+// by spaces.
+// Returns the formatted message.
+func FormatConsoleLogArgs(args []any) string {
+	// If we have no format string, simply join our arguments.
+	formattedArgs := make([]string, 0, len(args))
+	format, ok := "", false
+	if len(args) > 0 {
+		format, ok = args[0].(string)
+	}
+	if !ok {
+		for _, arg := range args {
+			formattedArgs = append(formattedArgs, formatConsoleLogArg(arg))
+		}
+		return strings.Join(formattedArgs, " ")
+	}
+
+	// Otherwise, substitute our format specifiers with the arguments following the format string.
+	var message strings.Builder
+	remainingArgs := args[1:]
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 >= len(format) {
+			message.WriteByte(format[i])
+			continue
+		}
+		switch format[i+1] {
+		case '%':
+			message.WriteByte('%')
+			i++
+		case 's', 'd', 'i', 'o':
+			if len(remainingArgs) == 0 {
+				message.WriteByte(format[i])
+				continue
+			}
+			message.WriteString(formatConsoleLogArg(remainingArgs[0]))
+			remainingArgs = remainingArgs[1:]
+			i++
+		default:
+			message.WriteByte(format[i])
+		}
+	}
+	formattedArgs = append(formattedArgs, message.String())
+	for _, arg := range remainingArgs {
+		formattedArgs = append(formattedArgs, formatConsoleLogArg(arg))
+	}
+	return strings.Join(formattedArgs, " ")
+}
+
+// formatConsoleLogArg formats a single unpacked console.log argument. Integers are formatted in decimal, addresses
+// are checksummed, and byte values are hex-encoded with a "0x" prefix.
+// Returns the formatted argument.
+func formatConsoleLogArg(arg any) string {
+	switch value := arg.(type) {
+	case *big.Int:
+		return value.String()
+	case common.Address:
+		return value.String()
+	case []byte:
+		return "0x" + hex.EncodeToString(value)
+	case string:
+		return value
+	}
+
+	// Fixed-size byte values (bytes1 through bytes32) are unpacked as byte arrays of varying length.
+	reflectedValue := reflect.ValueOf(arg)
+	if reflectedValue.Kind() == reflect.Array && reflectedValue.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, reflectedValue.Len())
+		reflect.Copy(reflect.ValueOf(b), reflectedValue)
+		return "0x" + hex.EncodeToString(b)
+	}
+	return fmt.Sprintf("%v", arg)
+}
@@ -0,0 +1,99 @@
+package chain
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/crytic/medusa/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFormatConsoleLogArgs ensures console.log arguments are formatted by type, with format specifiers in a leading
+// string substituted by the following arguments.
+func TestFormatConsoleLogArgs(t *testing.T) {
+	address := common.HexToAddress("0x00000000000000000000000000000000000000aB")
+	assert.EqualValues(t, "", FormatConsoleLogArgs(nil))
+	assert.EqualValues(t, "7 true "+address.String(), FormatConsoleLogArgs([]any{big.NewInt(7), true, address}))
+	assert.EqualValues(t, "0x0102 0x0a00", FormatConsoleLogArgs([]any{[]byte{1, 2}, [2]byte{10, 0}}))
+	assert.EqualValues(t, "balance of "+address.String()+": -3 100%", FormatConsoleLogArgs([]any{"balance of %s: %d 100%%", address, big.NewInt(-3)}))
+	assert.EqualValues(t, "value=%d", FormatConsoleLogArgs([]any{"value=%d"}))
+	assert.EqualValues(t, "a 1 false", FormatConsoleLogArgs([]any{"a", big.NewInt(1), false}))
+}
+
+// TestChainConsoleLog ensures messages contracts log through console.log are logged at the debug level, tagged with
+// the logging contract, for both current and legacy overload selectors, and that the console contract is only
+// installed when console.log capture is enabled.
+func TestChainConsoleLog(t *testing.T) {
+	// Capture debug events from the global logger.
+	originalLogger := logging.GlobalLogger
+	defer func() { logging.GlobalLogger = originalLogger }()
+	var output strings.Builder
+	logging.GlobalLogger = logging.NewLogger(logging.LogFormatJSON, &output)
+	logging.GlobalLogger.SetDebug(true)
+
+	// Create our chain with a funded sender and a contract which forwards its call data to the console contract:
+	// calldatacopy(0, 0, calldatasize()); staticcall(gas(), console, 0, calldatasize(), 0, 0)
+	sender := common.HexToAddress("0x0707")
+	logger := common.HexToAddress("0x1234")
+	loggerCode := append([]byte{0x36, 0x60, 0x00, 0x60, 0x00, 0x37, 0x60, 0x00, 0x60, 0x00, 0x36, 0x60, 0x00, 0x73}, ConsoleLogContractAddress.Bytes()...)
+	loggerCode = append(loggerCode, 0x5a, 0xfa, 0x00)
+	genesisAlloc := core.GenesisAlloc{
+		sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		logger: {Balance: big.NewInt(0), Code: loggerCode},
+	}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChainConfig.CheatCodeConfig.CheatCodesEnabled = false
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+	consoleLogContract, ok := chain.CheatCodeContracts()[ConsoleLogContractAddress]
+	assert.True(t, ok)
+
+	// Log a formatted message through the current selector, and an integer through the legacy "log(uint)" selector.
+	formattedData, err := consoleLogContract.Abi().Pack("log(string,uint256)", "value: %d", big.NewInt(42))
+	assert.NoError(t, err)
+	uintArgs, err := consoleLogContract.Abi().Methods["log(uint256)"].Inputs.Pack(big.NewInt(7))
+	assert.NoError(t, err)
+	legacyData := append(crypto.Keccak256([]byte("log(uint)"))[:4], uintArgs...)
+	for _, data := range [][]byte{formattedData, legacyData} {
+		msg := types.NewMessage(sender, &logger, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), data, nil, false)
+		_, err = chain.CallContract(msg, nil)
+		assert.NoError(t, err)
+	}
+
+	// Both messages should have been logged at the debug level, tagged with our logging contract.
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Len(t, lines, 2)
+	var messages []string
+	for _, line := range lines {
+		var event map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		assert.EqualValues(t, "debug", event["level"])
+		assert.EqualValues(t, logger.String(), event["contract"])
+		messages = append(messages, event["message"].(string))
+	}
+	assert.EqualValues(t, []string{"[console.log] " + logger.String() + ": value: 42", "[console.log] " + logger.String() + ": 7"}, messages)
+
+	// If debug events are disabled, nothing should be logged.
+	output.Reset()
+	logging.GlobalLogger.SetDebug(false)
+	msg := types.NewMessage(sender, &logger, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), formattedData, nil, false)
+	_, err = chain.CallContract(msg, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, output.String())
+
+	// If console.log capture is disabled, the console contract should not be installed at all.
+	testChainConfig.ConsoleLogEnabled = false
+	chain, err = NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+	_, ok = chain.CheatCodeContracts()[ConsoleLogContractAddress]
+	assert.False(t, ok)
+	assert.Zero(t, chain.State().GetCodeSize(ConsoleLogContractAddress))
+}
@@ -0,0 +1,248 @@
+package chain
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// forkStateMarkerAddress describes the address of an account whose storage is used by forkStateDB to record which
+// accounts and storage slots are known locally, and must not be fetched from the remote chain again. Recording this in
+// the state itself ensures it is reverted alongside the state (e.g. on a snapshot revert or chain revert).
+// The address is the ASCII encoding of "medusa-fork".
+var forkStateMarkerAddress = common.HexToAddress("0x6d65647573612d666f726b")
+
+const (
+	// forkAccountStatusUnknown indicates an account has not yet been resolved from local or remote state.
+	forkAccountStatusUnknown byte = iota
+	// forkAccountStatusRemote indicates an account was fetched from the remote chain, and any of its storage slots not
+	// yet known locally must be fetched from the remote chain too.
+	forkAccountStatusRemote
+	// forkAccountStatusLocal indicates an account exists locally (e.g. it was created or deployed in the test chain),
+	// and shadows any remote state entirely.
+	forkAccountStatusLocal
+)
+
+// forkStateDB wraps a state.StateDB for use in an EVM, lazily fetching any accounts, code and storage slots which
+// are missing locally from a ForkStateSource, and writing them into the underlying state.StateDB when first accessed.
+// Accounts which already exist locally when first accessed, or which are created locally, shadow the remote state.
+// Methods which are not overridden operate on the underlying state.StateDB directly.
+type forkStateDB struct {
+	// StateDB describes the underlying state which fetched state is written to.
+	*state.StateDB
+
+	// source describes the provider of the remote chain state.
+	source ForkStateSource
+
+	// err describes the first error encountered while fetching remote state, as the vm.StateDB interface does not
+	// allow them to be returned.
+	err error
+}
+
+// newForkStateDB creates a forkStateDB which wraps the provided state and fetches missing state from the provided
+// source.
+func newForkStateDB(stateDB *state.StateDB, source ForkStateSource) *forkStateDB {
+	return &forkStateDB{
+		StateDB: stateDB,
+		source:  source,
+	}
+}
+
+// Error returns the first error encountered while fetching remote state, or nil if none occurred.
+func (s *forkStateDB) Error() error {
+	return s.err
+}
+
+// recordError records the provided error if no previous error was recorded.
+func (s *forkStateDB) recordError(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// accountMarkerKey obtains the storage slot of the forkStateMarkerAddress account used to record the status of the
+// provided account.
+func accountMarkerKey(address common.Address) common.Hash {
+	return crypto.Keccak256Hash(address.Bytes())
+}
+
+// storageMarkerKey obtains the storage slot of the forkStateMarkerAddress account used to record whether the provided
+// storage slot of the provided account is known locally.
+func storageMarkerKey(address common.Address, slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(address.Bytes(), slot.Bytes())
+}
+
+// resolveAccount ensures the account at the provided address is known locally, fetching it from the remote chain if
+// it does not exist locally and was not resolved previously.
+// Returns the status of the account.
+func (s *forkStateDB) resolveAccount(address common.Address) byte {
+	// Our marker account is purely local.
+	if address == forkStateMarkerAddress {
+		return forkAccountStatusLocal
+	}
+
+	// If we resolved this account previously, return its status.
+	markerKey := accountMarkerKey(address)
+	marker := s.StateDB.GetState(forkStateMarkerAddress, markerKey)
+	if marker != (common.Hash{}) {
+		return marker[common.HashLength-1]
+	}
+
+	// If the account exists locally, it shadows the remote account. Otherwise, we fetch the remote account.
+	status := forkAccountStatusLocal
+	if !s.StateDB.Exist(address) {
+		account, err := s.source.Account(address)
+		if err != nil {
+			// We do not record a status, so we try to fetch the account again upon the next access.
+			s.recordError(err)
+			return forkAccountStatusUnknown
+		}
+
+		// Write the account to our local state if it is not empty.
+		if account.Balance.Sign() != 0 || account.Nonce != 0 || len(account.Code) > 0 {
+			s.StateDB.SetBalance(address, account.Balance)
+			s.StateDB.SetNonce(address, account.Nonce)
+			s.StateDB.SetCode(address, account.Code)
+		}
+
+		// Only contracts have storage, so we only need to fetch storage for accounts with code.
+		if len(account.Code) > 0 {
+			status = forkAccountStatusRemote
+		}
+	}
+
+	// Record the status of our account.
+	s.StateDB.SetState(forkStateMarkerAddress, markerKey, common.BigToHash(new(big.Int).SetUint64(uint64(status))))
+	return status
+}
+
+// resolveStorage ensures the provided storage slot of the account at the provided address is known locally, fetching
+// it from the remote chain if the account was fetched from the remote chain and the slot was not resolved previously.
+// Returns the fetched value and a boolean indicating whether the slot was fetched by this call.
+func (s *forkStateDB) resolveStorage(address common.Address, slot common.Hash) (common.Hash, bool) {
+	// Storage of local accounts shadows remote storage entirely.
+	if s.resolveAccount(address) != forkAccountStatusRemote {
+		return common.Hash{}, false
+	}
+
+	// If we resolved this slot previously, there is nothing to fetch.
+	markerKey := storageMarkerKey(address, slot)
+	if s.StateDB.GetState(forkStateMarkerAddress, markerKey) != (common.Hash{}) {
+		return common.Hash{}, false
+	}
+
+	// Fetch the slot and write it to our local state.
+	value, err := s.source.Storage(address, slot)
+	if err != nil {
+		s.recordError(err)
+		return common.Hash{}, false
+	}
+	s.StateDB.SetState(address, slot, value)
+	s.StateDB.SetState(forkStateMarkerAddress, markerKey, common.BigToHash(big.NewInt(1)))
+	return value, true
+}
+
+// CreateAccount creates a new account at the provided address, preserving the balance of any existing local or remote
+// account. The account then shadows the remote account entirely.
+func (s *forkStateDB) CreateAccount(address common.Address) {
+	s.resolveAccount(address)
+	s.StateDB.CreateAccount(address)
+	s.StateDB.SetState(forkStateMarkerAddress, accountMarkerKey(address), common.BigToHash(new(big.Int).SetUint64(uint64(forkAccountStatusLocal))))
+}
+
+// SubBalance subtracts the provided amount from the balance of the account at the provided address.
+func (s *forkStateDB) SubBalance(address common.Address, amount *big.Int) {
+	s.resolveAccount(address)
+	s.StateDB.SubBalance(address, amount)
+}
+
+// AddBalance adds the provided amount to the balance of the account at the provided address.
+func (s *forkStateDB) AddBalance(address common.Address, amount *big.Int) {
+	s.resolveAccount(address)
+	s.StateDB.AddBalance(address, amount)
+}
+
+// GetBalance returns the balance of the account at the provided address.
+func (s *forkStateDB) GetBalance(address common.Address) *big.Int {
+	s.resolveAccount(address)
+	return s.StateDB.GetBalance(address)
+}
+
+// GetNonce returns the nonce of the account at the provided address.
+func (s *forkStateDB) GetNonce(address common.Address) uint64 {
+	s.resolveAccount(address)
+	return s.StateDB.GetNonce(address)
+}
+
+// SetNonce sets the nonce of the account at the provided address.
+func (s *forkStateDB) SetNonce(address common.Address, nonce uint64) {
+	s.resolveAccount(address)
+	s.StateDB.SetNonce(address, nonce)
+}
+
+// GetCodeHash returns the code hash of the account at the provided address.
+func (s *forkStateDB) GetCodeHash(address common.Address) common.Hash {
+	s.resolveAccount(address)
+	return s.StateDB.GetCodeHash(address)
+}
+
+// GetCode returns the code of the account at the provided address.
+func (s *forkStateDB) GetCode(address common.Address) []byte {
+	s.resolveAccount(address)
+	return s.StateDB.GetCode(address)
+}
+
+// SetCode sets the code of the account at the provided address.
+func (s *forkStateDB) SetCode(address common.Address, code []byte) {
+	s.resolveAccount(address)
+	s.StateDB.SetCode(address, code)
+}
+
+// GetCodeSize returns the size of the code of the account at the provided address.
+func (s *forkStateDB) GetCodeSize(address common.Address) int {
+	s.resolveAccount(address)
+	return s.StateDB.GetCodeSize(address)
+}
+
+// GetCommittedState returns the value of the provided storage slot of the account at the provided address, as of
+// the start of the current transaction.
+func (s *forkStateDB) GetCommittedState(address common.Address, slot common.Hash) common.Hash {
+	// TODO: A slot fetched earlier in the current transaction (e.g. by GetState) is considered dirty by the
+	//  underlying state, so the committed value returned is zero, which may cause slight gas discrepancies.
+	if value, fetched := s.resolveStorage(address, slot); fetched {
+		return value
+	}
+	return s.StateDB.GetCommittedState(address, slot)
+}
+
+// GetState returns the current value of the provided storage slot of the account at the provided address.
+func (s *forkStateDB) GetState(address common.Address, slot common.Hash) common.Hash {
+	s.resolveStorage(address, slot)
+	return s.StateDB.GetState(address, slot)
+}
+
+// SetState sets the value of the provided storage slot of the account at the provided address.
+func (s *forkStateDB) SetState(address common.Address, slot common.Hash, value common.Hash) {
+	s.resolveStorage(address, slot)
+	s.StateDB.SetState(address, slot, value)
+}
+
+// Suicide marks the account at the provided address as self-destructed.
+func (s *forkStateDB) Suicide(address common.Address) bool {
+	s.resolveAccount(address)
+	return s.StateDB.Suicide(address)
+}
+
+// Exist reports whether the account at the provided address exists, locally or remotely.
+func (s *forkStateDB) Exist(address common.Address) bool {
+	s.resolveAccount(address)
+	return s.StateDB.Exist(address)
+}
+
+// Empty reports whether the account at the provided address is empty, as defined by EIP-161.
+func (s *forkStateDB) Empty(address common.Address) bool {
+	s.resolveAccount(address)
+	return s.StateDB.Empty(address)
+}
@@ -0,0 +1,314 @@
+package chain
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// forkRpcRequestTimeout describes the maximum amount of time a single request to a fork RPC endpoint may take.
+const forkRpcRequestTimeout = 30 * time.Second
+
+// ForkAccount describes the state of an account on a remote chain, excluding its storage.
+type ForkAccount struct {
+	// Balance describes the balance of the account.
+	Balance *big.Int
+
+	// Nonce describes the nonce of the account.
+	Nonce uint64
+
+	// Code describes the runtime bytecode of the account.
+	Code []byte
+}
+
+// ForkStateSource describes a provider of the state of a remote chain at a given block, which a TestChain can lazily
+// fetch state from when it is missing locally. Implementations must be safe for concurrent use.
+type ForkStateSource interface {
+	// BlockTimestamp returns the timestamp of the remote block state is fetched at.
+	BlockTimestamp() uint64
+
+	// Account fetches the state of the account at the provided address.
+	// Returns the account state, or an error if one occurs.
+	Account(address common.Address) (*ForkAccount, error)
+
+	// Storage fetches the value of the provided storage slot of the account at the provided address.
+	// Returns the storage value, or an error if one occurs.
+	Storage(address common.Address, slot common.Hash) (common.Hash, error)
+}
+
+// forkStateSources caches every ForkStateSource created by getForkStateSource, so that chains created with the same
+// fork configuration (e.g. clones used by each worker) share a single RPC client and cache.
+var forkStateSources = make(map[config.ForkConfig]ForkStateSource)
+
+// forkStateSourcesLock provides thread synchronization to prevent concurrent access errors into forkStateSources.
+var forkStateSourcesLock sync.Mutex
+
+// getForkStateSource obtains the ForkStateSource for the provided fork configuration, creating one if it does not
+// yet exist.
+// Returns the ForkStateSource, or an error if one occurs.
+func getForkStateSource(forkConfig config.ForkConfig) (ForkStateSource, error) {
+	forkStateSourcesLock.Lock()
+	defer forkStateSourcesLock.Unlock()
+
+	// If we already created a source for this config, return it.
+	if source, ok := forkStateSources[forkConfig]; ok {
+		return source, nil
+	}
+
+	// Otherwise create a new one.
+	source, err := newRpcForkStateSource(forkConfig)
+	if err != nil {
+		return nil, err
+	}
+	forkStateSources[forkConfig] = source
+	return source, nil
+}
+
+// rpcForkStateSourceCacheEntry describes a single line of the disk cache kept by a rpcForkStateSource. Each entry
+// records either an account, or a storage slot.
+type rpcForkStateSourceCacheEntry struct {
+	// Address describes the address of the account the entry relates to.
+	Address common.Address `json:"address"`
+
+	// Slot describes the storage slot of the entry, if it is a storage entry.
+	Slot *common.Hash `json:"slot,omitempty"`
+
+	// Value describes the value of the storage slot, if it is a storage entry.
+	Value *common.Hash `json:"value,omitempty"`
+
+	// Balance describes the balance of the account, if it is an account entry.
+	Balance *hexutil.Big `json:"balance,omitempty"`
+
+	// Nonce describes the nonce of the account, if it is an account entry.
+	Nonce *hexutil.Uint64 `json:"nonce,omitempty"`
+
+	// Code describes the runtime bytecode of the account, if it is an account entry.
+	Code hexutil.Bytes `json:"code,omitempty"`
+}
+
+// rpcStorageKey describes the key used to cache a storage slot of an account in a rpcForkStateSource.
+type rpcStorageKey struct {
+	// address describes the address of the account.
+	address common.Address
+
+	// slot describes the storage slot of the account.
+	slot common.Hash
+}
+
+// rpcForkStateSource is a ForkStateSource which fetches state from an RPC endpoint, caching it in memory and
+// optionally on disk.
+type rpcForkStateSource struct {
+	// client describes the RPC client used to fetch state.
+	client *ethclient.Client
+
+	// blockNumber describes the block number state is fetched at.
+	blockNumber *big.Int
+
+	// blockTimestamp describes the timestamp of the block state is fetched at.
+	blockTimestamp uint64
+
+	// accounts describes the accounts which were fetched so far.
+	accounts map[common.Address]*ForkAccount
+
+	// storage describes the storage slots which were fetched so far.
+	storage map[rpcStorageKey]common.Hash
+
+	// cacheFile describes the file fetched state is appended to, or nil if disk caching is disabled.
+	cacheFile *os.File
+
+	// lock provides thread synchronization to prevent concurrent access errors into the caches.
+	lock sync.Mutex
+}
+
+// newRpcForkStateSource creates a rpcForkStateSource for the provided fork configuration, loading any state cached
+// on disk by previous campaigns forking the same chain and block.
+// Returns the rpcForkStateSource, or an error if one occurs.
+func newRpcForkStateSource(forkConfig config.ForkConfig) (*rpcForkStateSource, error) {
+	// Connect to our RPC endpoint.
+	client, err := ethclient.Dial(forkConfig.RpcUrl)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to fork RPC endpoint '%v': %v", forkConfig.RpcUrl, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), forkRpcRequestTimeout)
+	defer cancel()
+
+	// Resolve the block we will fork at. If none was provided, we use the latest.
+	var blockNumber *big.Int
+	if forkConfig.RpcBlock != 0 {
+		blockNumber = new(big.Int).SetUint64(forkConfig.RpcBlock)
+	}
+	header, err := client.HeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch fork block header from RPC endpoint: %v", err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch chain ID from fork RPC endpoint: %v", err)
+	}
+
+	// Create our source.
+	source := &rpcForkStateSource{
+		client:         client,
+		blockNumber:    header.Number,
+		blockTimestamp: header.Time,
+		accounts:       make(map[common.Address]*ForkAccount),
+		storage:        make(map[rpcStorageKey]common.Hash),
+	}
+
+	// If we have a cache directory, load any cached state for this chain and block, and open the cache to append to.
+	if forkConfig.CacheDirectory != "" {
+		err = utils.MakeDirectory(forkConfig.CacheDirectory)
+		if err != nil {
+			return nil, err
+		}
+		cachePath := filepath.Join(forkConfig.CacheDirectory, fmt.Sprintf("fork_%v_%v.jsonl", chainID, header.Number))
+		err = source.loadCache(cachePath)
+		if err != nil {
+			return nil, err
+		}
+		source.cacheFile, err = os.OpenFile(cachePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("could not open fork state cache '%v': %v", cachePath, err)
+		}
+	}
+	return source, nil
+}
+
+// loadCache loads all state cached in the file at the provided path into memory. A missing cache file is not an
+// error.
+// Returns an error if one occurs.
+func (s *rpcForkStateSource) loadCache(cachePath string) error {
+	file, err := os.Open(cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	// Parse each entry. An entry which failed to parse (e.g. a partially written final line) is skipped, as it will
+	// simply be fetched again.
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var entry rpcForkStateSourceCacheEntry
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		if entry.Slot != nil && entry.Value != nil {
+			s.storage[rpcStorageKey{address: entry.Address, slot: *entry.Slot}] = *entry.Value
+		} else if entry.Balance != nil && entry.Nonce != nil {
+			s.accounts[entry.Address] = &ForkAccount{
+				Balance: entry.Balance.ToInt(),
+				Nonce:   uint64(*entry.Nonce),
+				Code:    entry.Code,
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// appendCache appends the provided entry to the disk cache, if disk caching is enabled. This expects the lock to
+// be held by the caller.
+// Returns an error if one occurs.
+func (s *rpcForkStateSource) appendCache(entry *rpcForkStateSourceCacheEntry) error {
+	if s.cacheFile == nil {
+		return nil
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.cacheFile.Write(append(b, '\n'))
+	if err != nil {
+		return fmt.Errorf("An error occurred while writing fork state cache to disk: %v\n", err)
+	}
+	return nil
+}
+
+// BlockTimestamp returns the timestamp of the remote block state is fetched at.
+func (s *rpcForkStateSource) BlockTimestamp() uint64 {
+	return s.blockTimestamp
+}
+
+// Account fetches the state of the account at the provided address, from the cache if possible.
+// Returns the account state, or an error if one occurs.
+func (s *rpcForkStateSource) Account(address common.Address) (*ForkAccount, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// If we fetched this account already, return it.
+	if account, ok := s.accounts[address]; ok {
+		return account, nil
+	}
+
+	// Otherwise fetch it from our RPC endpoint.
+	ctx, cancel := context.WithTimeout(context.Background(), forkRpcRequestTimeout)
+	defer cancel()
+	balance, err := s.client.BalanceAt(ctx, address, s.blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch balance of %v from fork RPC endpoint: %v", address, err)
+	}
+	nonce, err := s.client.NonceAt(ctx, address, s.blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch nonce of %v from fork RPC endpoint: %v", address, err)
+	}
+	code, err := s.client.CodeAt(ctx, address, s.blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch code of %v from fork RPC endpoint: %v", address, err)
+	}
+
+	// Cache it and return it.
+	account := &ForkAccount{Balance: balance, Nonce: nonce, Code: code}
+	s.accounts[address] = account
+	return account, s.appendCache(&rpcForkStateSourceCacheEntry{
+		Address: address,
+		Balance: (*hexutil.Big)(balance),
+		Nonce:   (*hexutil.Uint64)(&nonce),
+		Code:    code,
+	})
+}
+
+// Storage fetches the value of the provided storage slot of the account at the provided address, from the cache if
+// possible.
+// Returns the storage value, or an error if one occurs.
+func (s *rpcForkStateSource) Storage(address common.Address, slot common.Hash) (common.Hash, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// If we fetched this slot already, return it.
+	key := rpcStorageKey{address: address, slot: slot}
+	if value, ok := s.storage[key]; ok {
+		return value, nil
+	}
+
+	// Otherwise fetch it from our RPC endpoint.
+	ctx, cancel := context.WithTimeout(context.Background(), forkRpcRequestTimeout)
+	defer cancel()
+	b, err := s.client.StorageAt(ctx, address, slot, s.blockNumber)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("could not fetch storage of %v from fork RPC endpoint: %v", address, err)
+	}
+
+	// Cache it and return it.
+	value := common.BytesToHash(b)
+	s.storage[key] = value
+	return value, s.appendCache(&rpcForkStateSourceCacheEntry{
+		Address: address,
+		Slot:    &slot,
+		Value:   &value,
+	})
+}
@@ -0,0 +1,183 @@
+package chain
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// testForkStateSource is a ForkStateSource which serves state from memory and counts fetches, for use in testing.
+type testForkStateSource struct {
+	// accounts describes the remote accounts served by this source.
+	accounts map[common.Address]*ForkAccount
+
+	// storage describes the remote storage served by this source.
+	storage map[common.Address]map[common.Hash]common.Hash
+
+	// fetches describes the number of times state was fetched from this source.
+	fetches int
+
+	// lock provides thread synchronization to prevent concurrent access errors into fetches.
+	lock sync.Mutex
+}
+
+// BlockTimestamp returns the timestamp of the remote block state is fetched at.
+func (s *testForkStateSource) BlockTimestamp() uint64 {
+	return 1_700_000_000
+}
+
+// Account fetches the state of the account at the provided address.
+func (s *testForkStateSource) Account(address common.Address) (*ForkAccount, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.fetches++
+	if account, ok := s.accounts[address]; ok {
+		return account, nil
+	}
+	return &ForkAccount{Balance: big.NewInt(0)}, nil
+}
+
+// Storage fetches the value of the provided storage slot of the account at the provided address.
+func (s *testForkStateSource) Storage(address common.Address, slot common.Hash) (common.Hash, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.fetches++
+	return s.storage[address][slot], nil
+}
+
+// TestChainForkState creates a TestChain which forks state from a remote chain, and ensures remote state is fetched
+// when missing locally, is shadowed by local changes, and is consistent across snapshot reverts, chain reverts and
+// chain clones.
+func TestChainForkState(t *testing.T) {
+	// Create a remote contract which returns storage slot 0 when called without data, and otherwise sets storage
+	// slot 0 to the first word of the call data.
+	remoteContract := common.HexToAddress("0x1234")
+	remoteAccount := common.HexToAddress("0x5678")
+	remoteCode := hexutil.MustDecode("0x" +
+		"36600f57" + // CALLDATASIZE PUSH1 0x0f JUMPI
+		"60005460005260206000f3" + // PUSH1 0 SLOAD PUSH1 0 MSTORE PUSH1 0x20 PUSH1 0 RETURN
+		"5b600035600055" + "00", // JUMPDEST PUSH1 0 CALLDATALOAD PUSH1 0 SSTORE STOP
+	)
+	source := &testForkStateSource{
+		accounts: map[common.Address]*ForkAccount{
+			remoteContract: {Balance: big.NewInt(5), Nonce: 1, Code: remoteCode},
+			remoteAccount:  {Balance: big.NewInt(1000), Nonce: 3},
+		},
+		storage: map[common.Address]map[common.Hash]common.Hash{
+			remoteContract: {
+				common.BigToHash(big.NewInt(0)): common.BigToHash(big.NewInt(42)),
+				common.BigToHash(big.NewInt(1)): common.BigToHash(big.NewInt(7)),
+			},
+		},
+	}
+
+	// Create our chain with a funded sender.
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := core.GenesisAlloc{sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)}}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	chain, err := newTestChain(genesisAlloc, testChainConfig, source)
+	assert.NoError(t, err)
+	assert.EqualValues(t, source.BlockTimestamp(), chain.Head().Header.Time)
+
+	// callRemoteContract calls our remote contract to obtain storage slot 0.
+	callRemoteContract := func() uint64 {
+		msg := types.NewMessage(sender, &remoteContract, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), nil, nil, true)
+		result, err := chain.CallContract(msg, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, result.Err)
+		return new(big.Int).SetBytes(result.ReturnData).Uint64()
+	}
+
+	// Remote storage should be fetched when missing locally.
+	assert.EqualValues(t, 42, callRemoteContract())
+
+	// Set storage slot 0 to zero in a transaction. The local value should now shadow the remote value.
+	_, err = chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	msg := types.NewMessage(sender, &remoteContract, chain.State().GetNonce(sender), big.NewInt(0), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), make([]byte, 32), nil, false)
+	err = chain.PendingBlockAddTx(msg)
+	assert.NoError(t, err)
+	err = chain.PendingBlockCommit()
+	assert.NoError(t, err)
+	assert.EqualValues(t, types.ReceiptStatusSuccessful, chain.Head().MessageResults[0].Receipt.Status)
+	assert.EqualValues(t, 0, callRemoteContract())
+
+	// The remote contract's account should have been written to local state.
+	assert.EqualValues(t, big.NewInt(5), chain.State().GetBalance(remoteContract))
+	assert.EqualValues(t, remoteCode, chain.State().GetCode(remoteContract))
+
+	// A clone of the chain should reach the same state.
+	clonedChain, err := chain.Clone(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, chain.Head().Hash, clonedChain.Head().Hash)
+
+	// Reverting the chain should revert to the remote value.
+	err = chain.RevertToBlockNumber(0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 42, callRemoteContract())
+
+	// Remote accounts should be fetched, and state written over a snapshot should revert to remote state.
+	forkState := newForkStateDB(chain.State(), source)
+	assert.EqualValues(t, big.NewInt(1000), forkState.GetBalance(remoteAccount))
+	assert.EqualValues(t, 3, forkState.GetNonce(remoteAccount))
+	snapshot := forkState.Snapshot()
+	slot := common.BigToHash(big.NewInt(1))
+	forkState.SetState(remoteContract, slot, common.Hash{})
+	assert.EqualValues(t, common.Hash{}, forkState.GetState(remoteContract, slot))
+	forkState.RevertToSnapshot(snapshot)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(7)), forkState.GetState(remoteContract, slot))
+
+	// Resolved state should not be fetched again.
+	fetches := source.fetches
+	forkState.GetBalance(remoteAccount)
+	forkState.GetState(remoteContract, slot)
+	assert.EqualValues(t, fetches, source.fetches)
+
+	// Accounts created locally should shadow remote accounts.
+	forkState.CreateAccount(remoteContract)
+	assert.EqualValues(t, common.Hash{}, forkState.GetState(remoteContract, common.BigToHash(big.NewInt(0))))
+	assert.NoError(t, forkState.Error())
+}
+
+// TestRpcForkStateSourceCache ensures state fetched by a rpcForkStateSource and appended to its disk cache is loaded
+// again by a later source, so it does not need to be fetched again.
+func TestRpcForkStateSourceCache(t *testing.T) {
+	// Create a source which writes to a cache file, and append an account and storage slot to it.
+	cachePath := filepath.Join(t.TempDir(), "fork_1_100.jsonl")
+	cacheFile, err := os.OpenFile(cachePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	source := &rpcForkStateSource{cacheFile: cacheFile}
+	address, slot, value := common.HexToAddress("0x1234"), common.BigToHash(big.NewInt(1)), common.BigToHash(big.NewInt(2))
+	nonce := uint64(3)
+	assert.NoError(t, source.appendCache(&rpcForkStateSourceCacheEntry{Address: address, Balance: (*hexutil.Big)(big.NewInt(4)), Nonce: (*hexutil.Uint64)(&nonce), Code: []byte{0x00}}))
+	assert.NoError(t, source.appendCache(&rpcForkStateSourceCacheEntry{Address: address, Slot: &slot, Value: &value}))
+	assert.NoError(t, cacheFile.Close())
+
+	// Load the cache into a new source, and verify its contents are served without an RPC client.
+	source = &rpcForkStateSource{
+		accounts: make(map[common.Address]*ForkAccount),
+		storage:  make(map[rpcStorageKey]common.Hash),
+	}
+	assert.NoError(t, source.loadCache(cachePath))
+	account, err := source.Account(address)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, account.Balance.Uint64())
+	assert.EqualValues(t, nonce, account.Nonce)
+	assert.EqualValues(t, []byte{0x00}, account.Code)
+	storageValue, err := source.Storage(address, slot)
+	assert.NoError(t, err)
+	assert.EqualValues(t, value, storageValue)
+
+	// A missing cache should not be an error.
+	assert.NoError(t, source.loadCache(filepath.Join(t.TempDir(), "missing.jsonl")))
+}
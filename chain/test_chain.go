@@ -1,25 +1,31 @@
 package chain
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"github.com/crytic/medusa/chain/config"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"golang.org/x/exp/maps"
 	"math/big"
+	"reflect"
 	"sort"
 
+	"github.com/VictoriaMetrics/fastcache"
 	chainTypes "github.com/crytic/medusa/chain/types"
 	"github.com/crytic/medusa/chain/vendored"
 	"github.com/crytic/medusa/utils"
+	"github.com/crytic/medusa/utils/reflectionutils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
-	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
 )
@@ -39,6 +45,10 @@ type TestChain struct {
 	// Transactions which push the block gas usage beyond this limit will not be added to a block without error.
 	BlockGasLimit uint64
 
+	// SignerKeys defines the private keys exposed to contracts through cheat codes, so they can produce signatures
+	// for the accounts the keys control.
+	SignerKeys []*ecdsa.PrivateKey
+
 	// testChainConfig represents the configuration used by this TestChain.
 	testChainConfig *config.TestChainConfig
 
@@ -64,8 +74,9 @@ type TestChain struct {
 	// This is constructed over the kvstore.
 	db ethdb.Database
 
-	// keyValueStore represents the underlying key-value store used to construct the db.
-	keyValueStore *memorydb.Database
+	// keyValueStore represents the underlying key-value store used to construct the db. It tracks its size, so the
+	// memory consumed by the chain's state can be measured.
+	keyValueStore *sizeTrackingKeyValueStore
 
 	// callTracerRouter forwards vm.EVMLogger and TestChainTracer calls to any instances added to it. This
 	// router is used for non-state changing calls.
@@ -75,6 +86,14 @@ type TestChain struct {
 	// router is used for transaction execution when constructing blocks.
 	transactionTracerRouter *TestChainTracerRouter
 
+	// deploymentsTracer describes the internal tracer which captures contract deployment changes made by transactions
+	// executed on this chain, to power its contract deployment related events.
+	deploymentsTracer *testChainDeploymentsTracer
+
+	// forkSource describes the provider of remote chain state which missing state is fetched from, or nil if the
+	// chain is not forking a remote chain.
+	forkSource ForkStateSource
+
 	// Events defines the event system for the TestChain.
 	Events TestChainEvents
 }
@@ -83,6 +102,30 @@ type TestChain struct {
 // This creates a test chain with a test chain configuration and the provided genesis allocation and config.
 // If a nil config is provided, a default one is used.
 func NewTestChain(genesisAlloc core.GenesisAlloc, testChainConfig *config.TestChainConfig) (*TestChain, error) {
+	// Use a default config if we were not provided one
+	var err error
+	if testChainConfig == nil {
+		testChainConfig, err = config.DefaultTestChainConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// If we are forking a remote chain, obtain the source to fetch its state from.
+	var forkSource ForkStateSource
+	if testChainConfig.ForkConfig.ForkModeEnabled {
+		forkSource, err = getForkStateSource(testChainConfig.ForkConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newTestChain(genesisAlloc, testChainConfig, forkSource)
+}
+
+// newTestChain creates a simulated Ethereum backend used for testing, or returns an error if one occurred.
+// This creates a test chain with the provided genesis allocation and test chain configuration. If a fork state
+// source is provided, missing state is fetched from it.
+func newTestChain(genesisAlloc core.GenesisAlloc, testChainConfig *config.TestChainConfig, forkSource ForkStateSource) (*TestChain, error) {
 	// Copy our chain config, so it is not shared across chains.
 	chainConfig, err := utils.CopyChainConfig(params.TestChainConfig)
 	if err != nil {
@@ -108,11 +151,14 @@ func NewTestChain(genesisAlloc core.GenesisAlloc, testChainConfig *config.TestCh
 		BaseFee:    big.NewInt(0),
 	}
 
-	// Use a default config if we were not provided one
-	if testChainConfig == nil {
-		testChainConfig, err = config.DefaultTestChainConfig()
-		if err != nil {
-			return nil, err
+	// If we are forking a remote chain, start from the timestamp of the forked block, so contracts see a consistent
+	// time. We also add the account used to record which remote state was fetched, with a nonce so it is not
+	// considered empty and removed.
+	if forkSource != nil {
+		genesisDefinition.Timestamp = forkSource.BlockTimestamp()
+		genesisDefinition.Alloc[forkStateMarkerAddress] = core.GenesisAccount{
+			Balance: big.NewInt(0),
+			Nonce:   1,
 		}
 	}
 
@@ -139,8 +185,22 @@ func NewTestChain(genesisAlloc core.GenesisAlloc, testChainConfig *config.TestCh
 		}
 	}
 
+	// If console.log capture is enabled, install the console contract in the same way. It relies on the cheat code
+	// tracer to determine which contract logged a message, so the tracer is attached whenever either is enabled.
+	if testChainConfig.ConsoleLogEnabled {
+		consoleLogContract, err := getConsoleLogContract(cheatTracer)
+		if err != nil {
+			return nil, err
+		}
+		genesisDefinition.Alloc[consoleLogContract.address] = core.GenesisAccount{
+			Balance: big.NewInt(0),
+			Code:    []byte{0xFF},
+		}
+		vmConfigExtensions.AdditionalPrecompiles[consoleLogContract.address] = consoleLogContract
+	}
+
 	// Create an in-memory database
-	keyValueStore := memorydb.New()
+	keyValueStore := newSizeTrackingKeyValueStore()
 	db := rawdb.NewDatabase(keyValueStore)
 
 	// Commit our genesis definition to get a genesis block.
@@ -173,11 +233,13 @@ func NewTestChain(genesisAlloc core.GenesisAlloc, testChainConfig *config.TestCh
 		testChainConfig:         testChainConfig,
 		chainConfig:             genesisDefinition.Config,
 		vmConfigExtensions:      vmConfigExtensions,
+		deploymentsTracer:       newTestChainDeploymentsTracer(),
+		forkSource:              forkSource,
 	}
 
 	// Add our internal tracers to this chain.
-	chain.AddTracer(newTestChainDeploymentsTracer(), true, false)
-	if testChainConfig.CheatCodeConfig.CheatCodesEnabled {
+	chain.AddTracer(chain.deploymentsTracer, true, false)
+	if testChainConfig.CheatCodeConfig.CheatCodesEnabled || testChainConfig.ConsoleLogEnabled {
 		chain.AddTracer(cheatTracer, true, true)
 		cheatTracer.bindToChain(chain)
 	}
@@ -197,8 +259,8 @@ func NewTestChain(genesisAlloc core.GenesisAlloc, testChainConfig *config.TestCh
 // step between chain creation, and copying of all blocks, allowing for tracers to be added.
 // Returns the new chain, or an error if one occurred.
 func (t *TestChain) Clone(onCreateFunc func(chain *TestChain) error) (*TestChain, error) {
-	// Create a new chain with the same genesis definition and config
-	targetChain, err := NewTestChain(t.genesisDefinition.Alloc, t.testChainConfig)
+	// Create a new chain with the same genesis definition, config, and fork state source
+	targetChain, err := newTestChain(t.genesisDefinition.Alloc, t.testChainConfig, t.forkSource)
 	if err != nil {
 		return nil, err
 	}
@@ -237,8 +299,9 @@ func (t *TestChain) Clone(onCreateFunc func(chain *TestChain) error) (*TestChain
 		}
 	}
 
-	// Set our final block gas limit
+	// Set our final block gas limit and signer keys
 	targetChain.BlockGasLimit = t.BlockGasLimit
+	targetChain.SignerKeys = t.SignerKeys
 
 	// Verify our state
 	if targetChain.Head().Hash != t.Head().Hash {
@@ -249,6 +312,28 @@ func (t *TestChain) Clone(onCreateFunc func(chain *TestChain) error) (*TestChain
 	return targetChain, nil
 }
 
+// DatabaseSize returns the approximate amount of memory consumed by the chain's database, in bytes. This is the sum of
+// the lengths of all keys and values committed to it, and grows as state changes are committed in new blocks.
+func (t *TestChain) DatabaseSize() uint64 {
+	return t.keyValueStore.Size()
+}
+
+// Close releases the resources held by the TestChain, such as its database and the cache of trie nodes used to access
+// its state. The state database's clean node cache allocates its memory outside the Go heap, so it is not reclaimed
+// when the chain is garbage collected and must be released here. The chain must not be used after it is closed.
+// Returns an error if one occurs.
+func (t *TestChain) Close() error {
+	// Reset the clean node cache of our trie database, releasing its memory for reuse.
+	cleansField := reflect.ValueOf(t.stateDatabase.TrieDB()).Elem().FieldByName("cleans")
+	if cleans, ok := reflectionutils.GetField(cleansField).(*fastcache.Cache); ok && cleans != nil {
+		cleans.Reset()
+	}
+
+	// Release the state and database.
+	t.state = nil
+	return t.db.Close()
+}
+
 // AddTracer adds a given vm.EVMLogger or TestChainTracer to the TestChain. If directed, the tracer will be attached
 // for transactions and/or non-state changing calls made via CallContract.
 func (t *TestChain) AddTracer(tracer vm.EVMLogger, txs bool, calls bool) {
@@ -466,6 +551,44 @@ func (t *TestChain) StateAfterBlockNumber(blockNumber uint64) (*state.StateDB, e
 	return t.StateFromRoot(root)
 }
 
+// StateHash calculates a hash of the current state of the accounts at the provided addresses: their balance, nonce,
+// code hash and storage root. Unlike the world state root, it ignores every other account, so two call sequences may
+// be found to reach the same state of the accounts of interest (e.g. deployed contracts) even if the accounts sending
+// their calls were charged differently for gas. The order of the addresses provided does not matter.
+// Returns the state hash, or an error if one occurs.
+func (t *TestChain) StateHash(addresses []common.Address) (common.Hash, error) {
+	// Sort our addresses, so the order they were provided in does not matter.
+	sortedAddresses := make([]common.Address, len(addresses))
+	copy(sortedAddresses, addresses)
+	sort.Slice(sortedAddresses, func(i, j int) bool {
+		return bytes.Compare(sortedAddresses[i][:], sortedAddresses[j][:]) < 0
+	})
+
+	// Hash the state of each account which exists.
+	hashProvider := crypto.NewKeccakState()
+	var nonce [8]byte
+	for _, address := range sortedAddresses {
+		if !t.state.Exist(address) {
+			continue
+		}
+		storageRoot := types.EmptyRootHash
+		storageTrie, err := t.state.StorageTrie(address)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if storageTrie != nil {
+			storageRoot = storageTrie.Hash()
+		}
+		binary.BigEndian.PutUint64(nonce[:], t.state.GetNonce(address))
+		hashProvider.Write(address[:])
+		hashProvider.Write(common.BigToHash(t.state.GetBalance(address)).Bytes())
+		hashProvider.Write(nonce[:])
+		hashProvider.Write(t.state.GetCodeHash(address).Bytes())
+		hashProvider.Write(storageRoot[:])
+	}
+	return common.BytesToHash(hashProvider.Sum(nil)), nil
+}
+
 // RevertToBlockNumber sets the head of the chain to the block specified by the provided block number and reloads
 // the state from the underlying database.
 func (t *TestChain) RevertToBlockNumber(blockNumber uint64) error {
@@ -551,7 +674,8 @@ func (t *TestChain) CallContract(msg core.Message, state *state.StateDB, additio
 	extendedTracerRouter.AddTracers(additionalTracers...)
 
 	// Create our EVM instance.
-	evm := vm.NewEVM(blockContext, txContext, state, t.chainConfig, vm.Config{
+	evmState, forkState := t.evmStateDB(state)
+	evm := vm.NewEVM(blockContext, txContext, evmState, t.chainConfig, vm.Config{
 		Debug:            true,
 		Tracer:           extendedTracerRouter,
 		NoBaseFee:        true,
@@ -567,9 +691,40 @@ func (t *TestChain) CallContract(msg core.Message, state *state.StateDB, additio
 	// Revert to our state snapshot to undo any changes.
 	state.RevertToSnapshot(snapshot)
 
+	// As the call is never committed to the chain, execute any revert hooks tracers registered during it (e.g. to
+	// undo cheat code changes which persist across transactions).
+	callResults := &chainTypes.MessageResults{AdditionalResults: make(map[string]any, 0)}
+	extendedTracerRouter.CaptureTxEndSetAdditionalResults(callResults)
+	callResults.OnRevertHookFuncs.Execute(false, true)
+
+	// If we failed to fetch any remote state, our result may be incorrect.
+	if err == nil && forkState != nil && forkState.Error() != nil {
+		return nil, fmt.Errorf("could not fetch forked chain state: %v", forkState.Error())
+	}
 	return res, err
 }
 
+// LogsStateDB describes a vm.StateDB which also exposes the logs emitted so far in the current transaction. Every
+// vm.StateDB a TestChain executes an EVM over implements it.
+type LogsStateDB interface {
+	vm.StateDB
+
+	// Logs returns the logs emitted so far in the current transaction.
+	Logs() []*types.Log
+}
+
+// evmStateDB obtains the vm.StateDB an EVM should execute over, for the provided state. If the chain is forking a
+// remote chain, this wraps the provided state to fetch missing state from the remote chain, and the wrapper is
+// returned alongside it so any errors fetching remote state may be checked. Otherwise, the provided state is returned
+// alongside a nil wrapper.
+func (t *TestChain) evmStateDB(stateDB *state.StateDB) (vm.StateDB, *forkStateDB) {
+	if t.forkSource == nil {
+		return stateDB, nil
+	}
+	forkState := newForkStateDB(stateDB, t.forkSource)
+	return forkState, forkState
+}
+
 // PendingBlock describes the current pending block which is being constructed and awaiting commitment to the chain.
 // This may be nil if no pending block was created.
 func (t *TestChain) PendingBlock() *chainTypes.Block {
@@ -632,7 +787,7 @@ func (t *TestChain) PendingBlockCreateWithParameters(blockNumber uint64, blockTi
 	// - TODO: Difficulty should be revisited/checked.
 	// - GasUsed is aggregated for each transaction in the block (for now zero).
 	// - Mix digest is only useful for randomness, so we just fake randomness by using the previous block hash.
-	// - TODO: BaseFee should be revisited/checked.
+	// - BaseFee is fixed or adjusted from the parent block's, as configured.
 	header := &types.Header{
 		ParentHash:  parentBlockHash,
 		UncleHash:   types.EmptyUncleHash,
@@ -649,7 +804,7 @@ func (t *TestChain) PendingBlockCreateWithParameters(blockNumber uint64, blockTi
 		Extra:       []byte{},
 		MixDigest:   parentBlockHash,
 		Nonce:       types.BlockNonce{},
-		BaseFee:     big.NewInt(params.InitialBaseFee),
+		BaseFee:     t.nextBaseFee(blockNumber),
 	}
 
 	// Create a new block for our test node
@@ -691,7 +846,8 @@ func (t *TestChain) PendingBlockAddTx(message core.Message) error {
 	blockContext := newTestChainBlockContext(t, t.pendingBlock.Header)
 
 	// Create our EVM instance.
-	evm := vm.NewEVM(blockContext, core.NewEVMTxContext(message), t.state, t.chainConfig, vm.Config{
+	evmState, forkState := t.evmStateDB(t.state)
+	evm := vm.NewEVM(blockContext, core.NewEVMTxContext(message), evmState, t.chainConfig, vm.Config{
 		Debug:            true,
 		Tracer:           t.transactionTracerRouter,
 		NoBaseFee:        true,
@@ -702,10 +858,13 @@ func (t *TestChain) PendingBlockAddTx(message core.Message) error {
 	var usedGas uint64
 	t.state.SetTxContext(tx.Hash(), len(t.pendingBlock.Messages))
 	receipt, executionResult, err := vendored.EVMApplyTransaction(message, t.chainConfig, &t.pendingBlock.Header.Coinbase, gasPool, t.state, t.pendingBlock.Header.Number, t.pendingBlock.Hash, tx, &usedGas, evm)
+	if err == nil && forkState != nil && forkState.Error() != nil {
+		err = fmt.Errorf("could not fetch forked chain state: %v", forkState.Error())
+	}
 	if err != nil {
 		// If we encountered an error, reset our state, as we couldn't add the tx.
 		t.state, _ = state.New(t.pendingBlock.Header.Root, t.stateDatabase, nil)
-		return fmt.Errorf("test chain state write error when adding tx to pending block: %v", err)
+		return fmt.Errorf("test chain state write error when adding tx to pending block: %w", err)
 	}
 
 	// Create our message result
@@ -0,0 +1,90 @@
+package chain
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+// sizeTrackingKeyValueStore wraps an in-memory key-value store, tracking the approximate amount of memory consumed by
+// the keys and values it stores. This allows the size of a TestChain's database to be measured without iterating it.
+type sizeTrackingKeyValueStore struct {
+	// Database describes the underlying in-memory key-value store.
+	*memorydb.Database
+
+	// size describes the sum of the lengths of all keys and values currently stored.
+	size uint64
+
+	// sizeLock provides thread-synchronization to avoid race conditions when updating size.
+	sizeLock sync.Mutex
+}
+
+// newSizeTrackingKeyValueStore creates a new, empty in-memory key-value store which tracks its size.
+func newSizeTrackingKeyValueStore() *sizeTrackingKeyValueStore {
+	return &sizeTrackingKeyValueStore{
+		Database: memorydb.New(),
+	}
+}
+
+// Size returns the sum of the lengths of all keys and values currently stored, in bytes.
+func (s *sizeTrackingKeyValueStore) Size() uint64 {
+	s.sizeLock.Lock()
+	defer s.sizeLock.Unlock()
+	return s.size
+}
+
+// Put inserts the given value into the key-value store, updating its size.
+func (s *sizeTrackingKeyValueStore) Put(key []byte, value []byte) error {
+	s.sizeLock.Lock()
+	defer s.sizeLock.Unlock()
+	if existing, err := s.Database.Get(key); err == nil {
+		s.size -= uint64(len(key) + len(existing))
+	}
+	if err := s.Database.Put(key, value); err != nil {
+		return err
+	}
+	s.size += uint64(len(key) + len(value))
+	return nil
+}
+
+// Delete removes the key from the key-value store, updating its size.
+func (s *sizeTrackingKeyValueStore) Delete(key []byte) error {
+	s.sizeLock.Lock()
+	defer s.sizeLock.Unlock()
+	existing, err := s.Database.Get(key)
+	if err != nil {
+		return s.Database.Delete(key)
+	}
+	if err = s.Database.Delete(key); err != nil {
+		return err
+	}
+	s.size -= uint64(len(key) + len(existing))
+	return nil
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to the key-value store until a final write is
+// called, at which point its size is updated.
+func (s *sizeTrackingKeyValueStore) NewBatch() ethdb.Batch {
+	return &sizeTrackingBatch{Batch: s.Database.NewBatch(), store: s}
+}
+
+// NewBatchWithSize creates a write-only key-value store batch with a pre-allocated buffer, which updates the size of
+// the key-value store when written.
+func (s *sizeTrackingKeyValueStore) NewBatchWithSize(size int) ethdb.Batch {
+	return &sizeTrackingBatch{Batch: s.Database.NewBatchWithSize(size), store: s}
+}
+
+// sizeTrackingBatch wraps a batch of a sizeTrackingKeyValueStore, so writing it updates the size of the store.
+type sizeTrackingBatch struct {
+	// Batch describes the underlying batch buffering changes.
+	ethdb.Batch
+
+	// store describes the key-value store the batch is written to.
+	store *sizeTrackingKeyValueStore
+}
+
+// Write flushes any accumulated data to the key-value store, updating its size.
+func (b *sizeTrackingBatch) Write() error {
+	return b.Batch.Replay(b.store)
+}
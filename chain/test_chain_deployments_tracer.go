@@ -165,6 +165,43 @@ func (t *testChainDeploymentsTracer) CaptureState(pc uint64, op vm.OpCode, gas,
 	}
 }
 
+// captureCodeChange records a change to the code of the provided address which was made directly, rather than through
+// contract creation or destruction (e.g. by a cheat code), in the current call frame. If the provided EVM is not the
+// one being traced by this tracer, the change is not part of a transaction and is ignored.
+func (t *testChainDeploymentsTracer) captureCodeChange(evm *vm.EVM, address common.Address, previousCode []byte, newCode []byte) {
+	// If we are not tracing this execution, there is nothing to record.
+	if evm != t.evm || len(t.pendingCallFrames) == 0 {
+		return
+	}
+
+	// Record the removal of any previous code, followed by the addition of the new code.
+	callFrameData := t.pendingCallFrames[t.callDepth]
+	if len(previousCode) > 0 {
+		callFrameData.results = append(callFrameData.results, types.DeployedContractBytecodeChange{
+			Contract: &types.DeployedContractBytecode{
+				Address:         address,
+				InitBytecode:    nil,
+				RuntimeBytecode: previousCode,
+			},
+			Creation:       false,
+			SelfDestructed: false,
+			Destroyed:      true,
+		})
+	}
+	if len(newCode) > 0 {
+		callFrameData.results = append(callFrameData.results, types.DeployedContractBytecodeChange{
+			Contract: &types.DeployedContractBytecode{
+				Address:         address,
+				InitBytecode:    nil,
+				RuntimeBytecode: newCode,
+			},
+			Creation:       true,
+			SelfDestructed: false,
+			Destroyed:      false,
+		})
+	}
+}
+
 // CaptureFault records an execution fault, as defined by vm.EVMLogger.
 func (t *testChainDeploymentsTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
 
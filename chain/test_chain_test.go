@@ -147,6 +147,40 @@ func TestChainReverting(t *testing.T) {
 	}
 }
 
+// TestChainDatabaseSize creates a TestChain and commits blocks which change state, ensuring the approximate size
+// of its database grows with each, that a clone has the same size, and that chains can be closed.
+func TestChainDatabaseSize(t *testing.T) {
+	// Obtain our chain and senders
+	chain, senders := createChain(t)
+	previousSize := chain.DatabaseSize()
+	assert.Greater(t, previousSize, uint64(0))
+
+	// Commit blocks which transfer value to new accounts, so each adds state to our database.
+	for i := 0; i < 5; i++ {
+		recipient := common.BigToAddress(big.NewInt(int64(0x1000 + i)))
+		msg := types.NewMessage(senders[0], &recipient, chain.State().GetNonce(senders[0]), big.NewInt(1), chain.BlockGasLimit, big.NewInt(1), big.NewInt(0), big.NewInt(0), nil, nil, false)
+		_, err := chain.PendingBlockCreate()
+		assert.NoError(t, err)
+		err = chain.PendingBlockAddTx(&msg)
+		assert.NoError(t, err)
+		err = chain.PendingBlockCommit()
+		assert.NoError(t, err)
+
+		assert.Greater(t, chain.DatabaseSize(), previousSize)
+		previousSize = chain.DatabaseSize()
+	}
+
+	// A clone replays the same blocks, so it should have committed the same state.
+	clonedChain, err := chain.Clone(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, chain.DatabaseSize(), clonedChain.DatabaseSize())
+
+	// Closing either chain should not affect the other.
+	assert.NoError(t, clonedChain.Close())
+	verifyChain(t, chain)
+	assert.NoError(t, chain.Close())
+}
+
 // TestChainBlockNumberJumping creates a TestChain and creates blocks with block numbers which jumped (are
 // non-consecutive) to ensure the chain appropriately spoofs intermediate blocks.
 func TestChainBlockNumberJumping(t *testing.T) {
@@ -24,6 +24,10 @@ type MessageResults struct {
 	// ContractDeploymentChanges describes changes made to deployed contracts, such as creation and destruction.
 	ContractDeploymentChanges []DeployedContractBytecodeChange
 
+	// ExpectedEmitFailures describes event emissions expected through cheat codes which were not met during the
+	// execution of this transaction, as printable descriptions of the expected and emitted events.
+	ExpectedEmitFailures []string
+
 	// AdditionalResults represents results of arbitrary types which can be stored by any part of the application,
 	// such as a tracers.
 	AdditionalResults map[string]any
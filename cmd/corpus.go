@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crytic/medusa/fuzzing"
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+// corpusCmd represents the command provider for corpus maintenance
+var corpusCmd = &cobra.Command{
+	Use:   "corpus",
+	Short: "Maintains the corpus of a project",
+	Long:  `Maintains the corpus of call sequences collected by fuzzing campaigns of a project`,
+}
+
+// corpusVerifyCmd represents the command provider for corpus verification
+var corpusVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Replays the corpus to verify each call sequence",
+	Long: `Replays every call sequence in the corpus against freshly deployed contracts, without mutating them, and ` +
+		`reports whether each is valid, stale (it references a contract or method which no longer exists), or ` +
+		`violates a property or assertion test`,
+	Args: cmdValidateCorpusArgs,
+	RunE: cmdRunCorpusVerify,
+}
+
+// corpusMinimizeCmd represents the command provider for corpus minimization
+var corpusMinimizeCmd = &cobra.Command{
+	Use:   "minimize",
+	Short: "Removes call sequences which do not contribute coverage from the corpus",
+	Long: `Replays every call sequence in the corpus against freshly deployed contracts to measure its coverage, then ` +
+		`keeps a minimal subset of call sequences which preserves the total coverage of the corpus, moving the rest ` +
+		`to its "pruned" directory`,
+	Args: cmdValidateCorpusArgs,
+	RunE: cmdRunCorpusMinimize,
+}
+
+// corpusImportCmd represents the command provider for importing another fuzzer's corpus
+var corpusImportCmd = &cobra.Command{
+	Use:   "import <directory>",
+	Short: "Imports call sequences from another fuzzer's corpus",
+	Long: `Imports call sequences from another fuzzer's corpus or reproducer files in the provided directory into the ` +
+		`corpus, mapping their senders and targets onto the project configuration and validating each by replaying it`,
+	Args: cobra.ExactArgs(1),
+	RunE: cmdRunCorpusImport,
+}
+
+// corpusStatsCmd represents the command provider for corpus statistics
+var corpusStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarizes the origins and lineage of call sequences in the corpus",
+	Long: `Summarizes the metadata recorded for each call sequence in the corpus, reporting how call sequences were ` +
+		`produced (generated, mutated, spliced, shrunk or imported), which workers produced them, and how deeply ` +
+		`they descend from one another`,
+	Args: cmdValidateCorpusArgs,
+	RunE: cmdRunCorpusStats,
+}
+
+// cmdValidateCorpusArgs makes sure that there are no positional arguments provided to a corpus subcommand
+func cmdValidateCorpusArgs(cmd *cobra.Command, args []string) error {
+	// Make sure we have no positional args
+	if err := cobra.NoArgs(cmd, args); err != nil {
+		return fmt.Errorf("corpus %v does not accept any positional arguments, only flags and their associated values", cmd.Name())
+	}
+	return nil
+}
+
+func init() {
+	// Add all the flags allowed for the corpus commands
+	err := addCorpusVerifyFlags()
+	if err != nil {
+		panic(err)
+	}
+	err = addCorpusMinimizeFlags()
+	if err != nil {
+		panic(err)
+	}
+	err = addCorpusImportFlags()
+	if err != nil {
+		panic(err)
+	}
+	err = addCorpusStatsFlags()
+	if err != nil {
+		panic(err)
+	}
+
+	// Add the corpus command and its subcommands to the root command
+	corpusCmd.AddCommand(corpusVerifyCmd, corpusMinimizeCmd, corpusImportCmd, corpusStatsCmd)
+	rootCmd.AddCommand(corpusCmd)
+}
+
+// cmdRunCorpusVerify executes the CLI corpus verify command, reading the project configuration as described by
+// readProjectConfig, then replaying the corpus and reporting the status of every call sequence in it.
+func cmdRunCorpusVerify(cmd *cobra.Command, args []string) error {
+	// Obtain our flags describing how to verify the corpus and report results
+	fix, err := cmd.Flags().GetBool("fix")
+	if err != nil {
+		return err
+	}
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported output format '%v', expected 'text' or 'json'", format)
+	}
+
+	// Create our fuzzer, which compiles and deploys our contracts, then verify the corpus with it
+	fuzzer, err := newCorpusFuzzer(cmd)
+	if err != nil {
+		return err
+	}
+	verifications, err := fuzzer.VerifyCorpus(fix)
+	if err != nil {
+		return err
+	}
+
+	// Report our results in the requested format
+	if format == "json" {
+		b, err := json.MarshalIndent(verifications, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	statusCounts := make(map[corpus.CallSequenceVerificationStatus]int)
+	for _, verification := range verifications {
+		statusCounts[verification.Status]++
+		switch verification.Status {
+		case corpus.CallSequenceVerificationStatusStale:
+			fmt.Printf("[%v] %v: %v\n", verification.Status, verification.FilePath, verification.Error)
+		case corpus.CallSequenceVerificationStatusViolated:
+			fmt.Printf("[%v] %v: %v\n", verification.Status, verification.FilePath, strings.Join(verification.ViolatedTests, ", "))
+		default:
+			fmt.Printf("[%v] %v\n", verification.Status, verification.FilePath)
+		}
+	}
+	fmt.Printf("Verified %d call sequences: %d valid, %d stale, %d violated\n", len(verifications),
+		statusCounts[corpus.CallSequenceVerificationStatusValid], statusCounts[corpus.CallSequenceVerificationStatusStale],
+		statusCounts[corpus.CallSequenceVerificationStatusViolated])
+	if fix && statusCounts[corpus.CallSequenceVerificationStatusStale] > 0 {
+		fmt.Printf("Removed %d stale call sequences from the corpus\n", statusCounts[corpus.CallSequenceVerificationStatusStale])
+	}
+	return nil
+}
+
+// cmdRunCorpusMinimize executes the CLI corpus minimize command, reading the project configuration as described by
+// readProjectConfig, then minimizing the corpus and reporting its size before and after.
+func cmdRunCorpusMinimize(cmd *cobra.Command, args []string) error {
+	// Create our fuzzer, which compiles and deploys our contracts, then minimize the corpus with it
+	fuzzer, err := newCorpusFuzzer(cmd)
+	if err != nil {
+		return err
+	}
+	minimization, err := fuzzer.MinimizeCorpus()
+	if err != nil {
+		return err
+	}
+
+	// Report our results
+	fmt.Printf("Minimized corpus preserving %d covered locations\n", minimization.CoveredLocationCount)
+	fmt.Printf("  call sequences: %d -> %d\n", minimization.OriginalCallSequenceCount, minimization.MinimizedCallSequenceCount)
+	fmt.Printf("  calls: %d -> %d\n", minimization.OriginalCallCount, minimization.MinimizedCallCount)
+	fmt.Printf("  replay time: %v -> %v (estimated)\n", minimization.OriginalReplayDuration.Round(time.Millisecond), minimization.MinimizedReplayDuration.Round(time.Millisecond))
+	return nil
+}
+
+// cmdRunCorpusImport executes the CLI corpus import command, reading the project configuration as described by
+// readProjectConfig, then importing the call sequences in the provided directory into the corpus.
+func cmdRunCorpusImport(cmd *cobra.Command, args []string) error {
+	// Obtain the format of the corpus we are importing
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+
+	// Resolve our import directory before we change our working directory
+	directory, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Create our fuzzer, which compiles and deploys our contracts, then import the corpus with it
+	fuzzer, err := newCorpusFuzzer(cmd)
+	if err != nil {
+		return err
+	}
+	callSequenceImport, err := fuzzer.ImportCorpus(directory, format)
+	if err != nil {
+		return err
+	}
+
+	// Report our results
+	fmt.Printf("Imported %d of %d call sequences (%d skipped, %d duplicates, %d calls skipped)\n", callSequenceImport.ImportedCallSequenceCount,
+		callSequenceImport.FileCount, callSequenceImport.SkippedCallSequenceCount, callSequenceImport.DuplicateCallSequenceCount,
+		callSequenceImport.SkippedCallCount)
+	return nil
+}
+
+// cmdRunCorpusStats executes the CLI corpus stats command, reading the project configuration as described by
+// readProjectConfig, then summarizing the metadata of the call sequences in the corpus.
+func cmdRunCorpusStats(cmd *cobra.Command, args []string) error {
+	// Obtain the format to report our statistics in
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported output format '%v', expected 'text' or 'json'", format)
+	}
+
+	// Read our corpus. Summarizing its metadata does not require compiling or deploying any contracts.
+	projectConfig, err := readCorpusProjectConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if projectConfig.Fuzzing.CorpusDirectory == "" {
+		return fmt.Errorf("corpus stats requires a corpus directory to be configured")
+	}
+	c, err := corpus.NewCorpus(projectConfig.Fuzzing.CorpusDirectory)
+	if err != nil {
+		return err
+	}
+	statistics := c.Statistics()
+
+	// Report our results in the requested format
+	if format == "json" {
+		b, err := json.MarshalIndent(statistics, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	fmt.Printf("Corpus contains %d call sequences (%d without metadata)\n", statistics.CallSequenceCount, statistics.MissingMetadataCount)
+	fmt.Printf("Origins:\n")
+	origins := []corpus.CallSequenceOrigin{
+		corpus.CallSequenceOriginGeneration, corpus.CallSequenceOriginMutation, corpus.CallSequenceOriginSplice,
+		corpus.CallSequenceOriginShrink, corpus.CallSequenceOriginImport, corpus.CallSequenceOriginUnknown,
+	}
+	for _, origin := range origins {
+		originName := string(origin)
+		if origin == corpus.CallSequenceOriginUnknown {
+			originName = "unknown"
+		}
+		fmt.Printf("  %v: %d\n", originName, statistics.OriginCounts[origin])
+	}
+	fmt.Printf("Workers:\n")
+	workerIndexes := maps.Keys(statistics.WorkerCounts)
+	sort.Ints(workerIndexes)
+	for _, workerIndex := range workerIndexes {
+		fmt.Printf("  worker %d: %d\n", workerIndex, statistics.WorkerCounts[workerIndex])
+	}
+	fmt.Printf("Lineage depths:\n")
+	depths := maps.Keys(statistics.LineageDepthCounts)
+	sort.Ints(depths)
+	for _, depth := range depths {
+		fmt.Printf("  depth %d: %d\n", depth, statistics.LineageDepthCounts[depth])
+	}
+	fmt.Printf("Call sequences with a pruned parent: %d\n", statistics.MissingParentCount)
+	fmt.Printf("Most call sequences derived from a single call sequence: %d\n", statistics.MaxChildCount)
+	if statistics.EarliestCreatedAt != nil {
+		fmt.Printf("Created between %v and %v\n", statistics.EarliestCreatedAt.Format(time.RFC3339), statistics.LatestCreatedAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// readCorpusProjectConfig reads the project configuration for a corpus subcommand as described by readProjectConfig,
+// updating it with the command's flags, then changes the working directory to that of the project configuration file,
+// as paths in the configuration are relative to it.
+// Returns the project configuration, or an error if one occurs.
+func readCorpusProjectConfig(cmd *cobra.Command) (*config.ProjectConfig, error) {
+	// Read our project configuration
+	projectConfig, configPath, err := readProjectConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the project configuration given whatever flags were set using the CLI
+	err = updateProjectConfigWithCorpusFlags(cmd, projectConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// Change our working directory to the parent directory of the project configuration file, as paths in the
+	// configuration are relative to it.
+	err = os.Chdir(filepath.Dir(configPath))
+	if err != nil {
+		return nil, err
+	}
+	return projectConfig, nil
+}
+
+// newCorpusFuzzer reads the project configuration for a corpus subcommand as described by readCorpusProjectConfig,
+// then creates a fuzzer for it, compiling its contracts.
+// Returns the fuzzer, or an error if one occurs.
+func newCorpusFuzzer(cmd *cobra.Command) (*fuzzing.Fuzzer, error) {
+	projectConfig, err := readCorpusProjectConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return fuzzing.NewFuzzer(*projectConfig)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/spf13/cobra"
+)
+
+// addCorpusFlags adds the flags shared by the corpus subcommands to the provided command
+func addCorpusFlags(cmd *cobra.Command) {
+	// Prevent alphabetical sorting of usage message
+	cmd.Flags().SortFlags = false
+
+	// Config file
+	cmd.Flags().String("config", "", "path to config file")
+
+	// Target
+	cmd.Flags().String("target", "", TargetFlagDescription)
+
+	// Corpus directory
+	cmd.Flags().String("corpus-dir", "", "directory path for corpus items (unless a config file is provided, the default is used)")
+}
+
+// addCorpusVerifyFlags adds the various flags for the corpus verify command
+func addCorpusVerifyFlags() error {
+	addCorpusFlags(corpusVerifyCmd)
+
+	// Pruning stale call sequences
+	corpusVerifyCmd.Flags().Bool("fix", false, "remove call sequences which can no longer be replayed from the corpus")
+
+	// Output format
+	corpusVerifyCmd.Flags().String("format", "text", "output format for the verification results (\"text\" or \"json\")")
+
+	return nil
+}
+
+// addCorpusMinimizeFlags adds the various flags for the corpus minimize command
+func addCorpusMinimizeFlags() error {
+	addCorpusFlags(corpusMinimizeCmd)
+	return nil
+}
+
+// addCorpusImportFlags adds the various flags for the corpus import command
+func addCorpusImportFlags() error {
+	addCorpusFlags(corpusImportCmd)
+
+	// Import format
+	corpusImportCmd.Flags().String("format", "echidna", "format of the corpus being imported (\"echidna\")")
+
+	return nil
+}
+
+// addCorpusStatsFlags adds the various flags for the corpus stats command
+func addCorpusStatsFlags() error {
+	addCorpusFlags(corpusStatsCmd)
+
+	// Output format
+	corpusStatsCmd.Flags().String("format", "text", "output format for the corpus statistics (\"text\" or \"json\")")
+
+	return nil
+}
+
+// updateProjectConfigWithCorpusFlags will update the given projectConfig with any CLI arguments that were provided to
+// a corpus subcommand
+func updateProjectConfigWithCorpusFlags(cmd *cobra.Command, projectConfig *config.ProjectConfig) error {
+	var err error
+
+	// If --target was used
+	if cmd.Flags().Changed("target") {
+		newTarget, err := cmd.Flags().GetString("target")
+		if err != nil {
+			return err
+		}
+
+		err = projectConfig.Compilation.SetTarget(newTarget)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update corpus directory
+	if cmd.Flags().Changed("corpus-dir") {
+		projectConfig.Fuzzing.CorpusDirectory, err = cmd.Flags().GetString("corpus-dir")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/logging/colors"
+	"github.com/spf13/cobra"
+)
+
+// coverageCmd represents the command provider for coverage inspection
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "Inspects the coverage achieved by fuzzing campaigns",
+	Long:  `Inspects the coverage snapshots written to the corpus directory by fuzzing campaigns`,
+}
+
+// coverageDiffCmd represents the command provider for comparing coverage snapshots
+var coverageDiffCmd = &cobra.Command{
+	Use:   "diff <old> <new>",
+	Short: "Compares the coverage of two coverage snapshots",
+	Long: `Compares two coverage snapshots (as written to "coverage/coverage.cov" in the corpus directory), reporting ` +
+		`the source lines and branch edges of each contract which were newly covered, lost, or remain covered`,
+	Args: cobra.ExactArgs(2),
+	RunE: cmdRunCoverageDiff,
+}
+
+func init() {
+	// Add all the flags allowed for the coverage commands
+	err := addCoverageDiffFlags()
+	if err != nil {
+		panic(err)
+	}
+
+	// Add the coverage command and its subcommands to the root command
+	coverageCmd.AddCommand(coverageDiffCmd)
+	rootCmd.AddCommand(coverageCmd)
+}
+
+// cmdRunCoverageDiff executes the CLI coverage diff command, reading the provided old and new coverage snapshots and
+// reporting the differences in their coverage.
+func cmdRunCoverageDiff(cmd *cobra.Command, args []string) error {
+	// Obtain the format to report our differences in
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported output format '%v', expected 'text' or 'json'", format)
+	}
+
+	// Read our snapshots and compare them
+	oldSnapshot, err := coverage.ReadCoverageSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	newSnapshot, err := coverage.ReadCoverageSnapshot(args[1])
+	if err != nil {
+		return err
+	}
+	diff := coverage.DiffCoverageSnapshots(oldSnapshot, newSnapshot)
+
+	// Report our results in the requested format
+	if format == "json" {
+		b, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	for _, contractDiff := range diff.Contracts {
+		fmt.Printf("%v:%v\n", contractDiff.SourcePath, contractDiff.Name)
+		fmt.Printf("  lines: %v\n", formatCoverageDiffCounts(contractDiff.Lines))
+		fmt.Printf("  edges: %v\n", formatCoverageDiffCounts(contractDiff.Edges))
+		for _, filePath := range contractDiff.ChangedFiles {
+			fmt.Printf("  %v\n", colors.Colorize(fmt.Sprintf("warning: %v changed between snapshots, so its line numbers may not correspond", filePath), colors.Yellow))
+		}
+		for _, line := range contractDiff.NewlyCoveredLines {
+			fmt.Printf("  %v\n", colors.Colorize(fmt.Sprintf("+ %v:%d", line.Path, line.Line), colors.Green))
+		}
+		for _, line := range contractDiff.LostLines {
+			fmt.Printf("  %v\n", colors.Colorize(fmt.Sprintf("- %v:%d", line.Path, line.Line), colors.Red))
+		}
+		for _, edge := range contractDiff.NewlyCoveredEdges {
+			fmt.Printf("  %v\n", colors.Colorize(fmt.Sprintf("+ %v", formatSourceEdgeLocation(edge)), colors.Green))
+		}
+		for _, edge := range contractDiff.LostEdges {
+			fmt.Printf("  %v\n", colors.Colorize(fmt.Sprintf("- %v", formatSourceEdgeLocation(edge)), colors.Red))
+		}
+	}
+	fmt.Printf("Total lines: %v\n", formatCoverageDiffCounts(diff.Lines))
+	fmt.Printf("Total edges: %v\n", formatCoverageDiffCounts(diff.Edges))
+	return nil
+}
+
+// formatCoverageDiffCounts formats the provided counts for the text output of the coverage diff command, coloring
+// newly covered counts green and lost counts red.
+func formatCoverageDiffCounts(counts coverage.CoverageDiffCounts) string {
+	return fmt.Sprintf("%v, %v, %d unchanged",
+		colors.Colorize(fmt.Sprintf("%d newly covered", counts.NewlyCovered), colors.Green),
+		colors.Colorize(fmt.Sprintf("%d lost", counts.Lost), colors.Red),
+		counts.Unchanged)
+}
+
+// formatSourceEdgeLocation formats the provided branch edge for the text output of the coverage diff command.
+func formatSourceEdgeLocation(edge coverage.SourceEdgeLocation) string {
+	outcome := "not taken"
+	if edge.Taken {
+		outcome = "taken"
+	}
+	return fmt.Sprintf("%v:%d branch %d (%v)", edge.Path, edge.Line, edge.Branch, outcome)
+}
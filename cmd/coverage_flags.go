@@ -0,0 +1,12 @@
+package cmd
+
+// addCoverageDiffFlags adds the various flags for the coverage diff command
+func addCoverageDiffFlags() error {
+	// Prevent alphabetical sorting of usage message
+	coverageDiffCmd.Flags().SortFlags = false
+
+	// Output format
+	coverageDiffCmd.Flags().String("format", "text", "output format for the coverage differences (\"text\" or \"json\")")
+
+	return nil
+}
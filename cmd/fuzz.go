@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/crytic/medusa/fuzzing"
 	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/crytic/medusa/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -40,26 +42,104 @@ func init() {
 	rootCmd.AddCommand(fuzzCmd)
 }
 
-// cmdRunFuzz executes the CLI fuzz command and navigates through the following possibilities:
+// cmdRunFuzz executes the CLI fuzz command, reading the project configuration as described by readProjectConfig.
+func cmdRunFuzz(cmd *cobra.Command, args []string) error {
+	// Read our project configuration
+	projectConfig, configPath, err := readProjectConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Update the project configuration given whatever flags were set using the CLI
+	err = updateProjectConfigWithFuzzFlags(cmd, projectConfig)
+	if err != nil {
+		return err
+	}
+
+	// Change our working directory to the parent directory of the project configuration file
+	// This is important as when we compile for a given platform, the paths may be relative to wherever the
+	// configuration is supplied from. Providing a file path explicitly is optional anyways, so we _should_
+	// be in the config directory when running this.
+	err = os.Chdir(filepath.Dir(configPath))
+	if err != nil {
+		return err
+	}
+
+	// Determine whether we watch our compilation target for changes.
+	watch, err := cmd.Flags().GetBool("watch")
+	if err != nil {
+		return err
+	}
+
+	// Create our fuzzing
+	fuzzer, err := fuzzing.NewFuzzer(*projectConfig)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Stop our fuzzing gracefully on the first keyboard interrupt, letting workers finish shrinking and writing our
+	// corpus and reports. If another interrupt is received before we finish, exit immediately.
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go func() {
+		<-c
+		logging.GlobalLogger.Info().Msg("Stopping the fuzzer gracefully, finishing any shrinking in progress and writing results (interrupt again to exit immediately) ...")
+		fuzzer.Stop()
+		cancel()
+		<-c
+		logging.GlobalLogger.Warn().Msg("Interrupted again, exiting immediately")
+		os.Exit(1)
+	}()
+
+	// Print a function coverage summary whenever one is requested by a signal (SIGUSR1, where supported)
+	summaryRequests := make(chan os.Signal, 1)
+	notifyFunctionCoverageSummarySignal(summaryRequests)
+	defer signal.Stop(summaryRequests)
+	go func() {
+		for range summaryRequests {
+			fuzzer.PrintFunctionCoverageSummary()
+		}
+	}()
+
+	// Start the fuzzing process with our cancellable context. In watch mode, we keep fuzzing across recompilations
+	// of our target until we are interrupted.
+	if watch {
+		err = fuzzer.Watch(ctx)
+	} else {
+		err = fuzzer.StartWithContext(ctx)
+	}
+
+	// A campaign stopped because its coverage stagnated only exits successfully if configured to. In watch mode, we
+	// only exit once interrupted.
+	if err == nil && !watch && fuzzer.StopReason() == fuzzing.StopReasonNoNewCoverage && !projectConfig.Fuzzing.StagnationIsSuccess {
+		err = fmt.Errorf("fuzzing stopped as no new coverage was found for %v", projectConfig.Fuzzing.StopAfterNoNewCoverage)
+	}
+	return err
+}
+
+// readProjectConfig reads the project configuration for a command and navigates through the following possibilities:
 // #1: We will search for either a custom config file (via --config) or the default (medusa.json).
 // If we find it, read it. If we can't read it, throw an error.
 // #2: If a custom file was provided (--config was used), and we can't find the file, throw an error.
 // #3: If medusa.json can't be found, use the default project configuration.
-func cmdRunFuzz(cmd *cobra.Command, args []string) error {
+// Returns the project configuration and the path it was expected at, or an error if one occurs.
+func readProjectConfig(cmd *cobra.Command) (*config.ProjectConfig, string, error) {
 	var projectConfig *config.ProjectConfig
 
 	// Check to see if --config flag was used and store the value of --config flag
 	configFlagUsed := cmd.Flags().Changed("config")
 	configPath, err := cmd.Flags().GetString("config")
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
 	// If --config was not used, look for `medusa.json` in the current work directory
 	if !configFlagUsed {
 		workingDirectory, err := os.Getwd()
 		if err != nil {
-			return err
+			return nil, "", err
 		}
 		configPath = filepath.Join(workingDirectory, DefaultProjectConfigFilename)
 	}
@@ -72,13 +152,13 @@ func cmdRunFuzz(cmd *cobra.Command, args []string) error {
 		// Try to read the configuration file and throw an error if something goes wrong
 		projectConfig, err = config.ReadProjectConfigFromFile(configPath)
 		if err != nil {
-			return err
+			return nil, "", err
 		}
 	}
 
 	// Possibility #2: If the --config flag was used, and we couldn't find the file, we'll throw an error
 	if configFlagUsed && existenceError != nil {
-		return existenceError
+		return nil, "", existenceError
 	}
 
 	// Possibility #3: --config flag was not used and medusa.json was not found, so use the default project config
@@ -88,41 +168,8 @@ func cmdRunFuzz(cmd *cobra.Command, args []string) error {
 
 		projectConfig, err = config.GetDefaultProjectConfig(DefaultCompilationPlatform)
 		if err != nil {
-			return err
+			return nil, "", err
 		}
 	}
-
-	// Update the project configuration given whatever flags were set using the CLI
-	err = updateProjectConfigWithFuzzFlags(cmd, projectConfig)
-	if err != nil {
-		return err
-	}
-
-	// Change our working directory to the parent directory of the project configuration file
-	// This is important as when we compile for a given platform, the paths may be relative to wherever the
-	// configuration is supplied from. Providing a file path explicitly is optional anyways, so we _should_
-	// be in the config directory when running this.
-	err = os.Chdir(filepath.Dir(configPath))
-	if err != nil {
-		return err
-	}
-
-	// Create our fuzzing
-	fuzzer, err := fuzzing.NewFuzzer(*projectConfig)
-	if err != nil {
-		return err
-	}
-
-	// Stop our fuzzing on keyboard interrupts
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	go func() {
-		<-c
-		fuzzer.Stop()
-	}()
-
-	// Start the fuzzing process with our cancellable context.
-	err = fuzzer.Start()
-
-	return err
+	return projectConfig, configPath, nil
 }
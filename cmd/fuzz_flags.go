@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/crytic/medusa/compilation"
 	"github.com/crytic/medusa/fuzzing/config"
 	"github.com/spf13/cobra"
 )
@@ -36,6 +37,22 @@ func addFuzzFlags() error {
 	fuzzCmd.Flags().Uint64("test-limit", 0,
 		fmt.Sprintf("number of transactions to test before exiting (unless a config file is provided, default is %d). 0 means that test limit is not enforced", defaultConfig.Fuzzing.TestLimit))
 
+	// Call limit
+	fuzzCmd.Flags().Uint64("call-limit", 0,
+		fmt.Sprintf("number of calls to test before exiting (unless a config file is provided, default is %d). 0 means that call limit is not enforced", defaultConfig.Fuzzing.CallLimit))
+
+	// Sequence limit
+	fuzzCmd.Flags().Uint64("sequence-limit", 0,
+		fmt.Sprintf("number of call sequences to test before exiting (unless a config file is provided, default is %d). 0 means that sequence limit is not enforced", defaultConfig.Fuzzing.SequenceLimit))
+
+	// Stop after no new coverage
+	fuzzCmd.Flags().String("stop-after-no-new-coverage", "",
+		"duration (e.g. \"30m\") without new coverage after which to exit (unless a config file is provided, default is to not stop when coverage stagnates)")
+
+	// Seed
+	fuzzCmd.Flags().Int64("seed", 0,
+		"seed used to derive all random values in the campaign (unless a config file is provided, default is derived from the current time)")
+
 	// Tx sequence length
 	fuzzCmd.Flags().Int("seq-len", 0,
 		fmt.Sprintf("maximum transactions to run in sequence (unless a config file is provided, default is %d)", defaultConfig.Fuzzing.CallSequenceLength))
@@ -49,6 +66,22 @@ func addFuzzFlags() error {
 	fuzzCmd.Flags().String("corpus-dir", "",
 		fmt.Sprintf("directory path for corpus items (unless a config file is provided, default is %q)", defaultConfig.Fuzzing.CorpusDirectory))
 
+	// Resume
+	fuzzCmd.Flags().Bool("resume", false,
+		"resume the campaign from the checkpoint in the corpus directory, restoring its test case failures, optimization values, metrics and random seed")
+
+	// Watch mode
+	fuzzCmd.Flags().Bool("watch", false,
+		"watch the compilation target's sources, recompiling them on change and restarting the campaign against the recompiled contracts with its corpus and learned values")
+
+	// Status screen
+	fuzzCmd.Flags().Bool("tui", false,
+		"show the campaign on an interactive status screen, with logs in a pane below it (ignored if stdout is not a terminal)")
+
+	// Compilation cache
+	fuzzCmd.Flags().Bool("no-cache", false,
+		fmt.Sprintf("compile the targets even if their sources and compilation settings did not change since the compilations cached in %q", compilation.CompilationCachePath))
+
 	// Senders
 	fuzzCmd.Flags().StringSlice("senders", []string{},
 		"account address(es) used to send state-changing txns")
@@ -64,6 +97,18 @@ func addFuzzFlags() error {
 	// Trace all
 	fuzzCmd.Flags().Bool("trace-all", false,
 		fmt.Sprintf("print the execution trace for every element in a shrunken call sequence instead of only the last element (unless a config file is provided, default is %t)", defaultConfig.Fuzzing.Testing.TraceAll))
+
+	// Trace verbosity
+	fuzzCmd.Flags().Uint8("trace-verbosity", 0,
+		fmt.Sprintf("level of detail in execution traces, where 1 additionally records storage writes (unless a config file is provided, default is %d)", defaultConfig.Fuzzing.Testing.TraceVerbosity))
+
+	// Fork RPC URL
+	fuzzCmd.Flags().String("fork-url", "",
+		"RPC endpoint to fetch state from, enabling fork mode to fuzz against the state of a remote chain")
+
+	// Fork block number
+	fuzzCmd.Flags().Uint64("fork-block", 0,
+		"block number of the remote chain to fork state at (unless a config file is provided, default is the latest block)")
 	return nil
 }
 
@@ -85,6 +130,17 @@ func updateProjectConfigWithFuzzFlags(cmd *cobra.Command, projectConfig *config.
 		}
 	}
 
+	// Disable the compilation cache if --no-cache was used
+	if cmd.Flags().Changed("no-cache") {
+		noCache, err := cmd.Flags().GetBool("no-cache")
+		if err != nil {
+			return err
+		}
+		if noCache {
+			projectConfig.Compilation.DisableCache = true
+		}
+	}
+
 	// Update number of workers
 	if cmd.Flags().Changed("workers") {
 		projectConfig.Fuzzing.Workers, err = cmd.Flags().GetInt("workers")
@@ -109,6 +165,39 @@ func updateProjectConfigWithFuzzFlags(cmd *cobra.Command, projectConfig *config.
 		}
 	}
 
+	// Update call limit
+	if cmd.Flags().Changed("call-limit") {
+		projectConfig.Fuzzing.CallLimit, err = cmd.Flags().GetUint64("call-limit")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update sequence limit
+	if cmd.Flags().Changed("sequence-limit") {
+		projectConfig.Fuzzing.SequenceLimit, err = cmd.Flags().GetUint64("sequence-limit")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update the duration without new coverage to stop after
+	if cmd.Flags().Changed("stop-after-no-new-coverage") {
+		projectConfig.Fuzzing.StopAfterNoNewCoverage, err = cmd.Flags().GetString("stop-after-no-new-coverage")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update seed
+	if cmd.Flags().Changed("seed") {
+		seed, err := cmd.Flags().GetInt64("seed")
+		if err != nil {
+			return err
+		}
+		projectConfig.Fuzzing.Seed = &seed
+	}
+
 	// Update sequence length
 	if cmd.Flags().Changed("seq-len") {
 		projectConfig.Fuzzing.CallSequenceLength, err = cmd.Flags().GetInt("seq-len")
@@ -133,6 +222,14 @@ func updateProjectConfigWithFuzzFlags(cmd *cobra.Command, projectConfig *config.
 		}
 	}
 
+	// Update whether we resume from a checkpoint
+	if cmd.Flags().Changed("resume") {
+		projectConfig.Fuzzing.Resume, err = cmd.Flags().GetBool("resume")
+		if err != nil {
+			return err
+		}
+	}
+
 	// Update senders
 	if cmd.Flags().Changed("senders") {
 		projectConfig.Fuzzing.SenderAddresses, err = cmd.Flags().GetStringSlice("senders")
@@ -164,5 +261,39 @@ func updateProjectConfigWithFuzzFlags(cmd *cobra.Command, projectConfig *config.
 			return err
 		}
 	}
+
+	// Update the trace verbosity
+	if cmd.Flags().Changed("trace-verbosity") {
+		traceVerbosity, err := cmd.Flags().GetUint8("trace-verbosity")
+		if err != nil {
+			return err
+		}
+		projectConfig.Fuzzing.Testing.TraceVerbosity = config.TraceVerbosity(traceVerbosity)
+	}
+
+	// Update status screen enablement
+	if cmd.Flags().Changed("tui") {
+		projectConfig.Logging.TUI, err = cmd.Flags().GetBool("tui")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update the fork RPC URL, enabling fork mode
+	if cmd.Flags().Changed("fork-url") {
+		projectConfig.Fuzzing.TestChainConfig.ForkConfig.RpcUrl, err = cmd.Flags().GetString("fork-url")
+		if err != nil {
+			return err
+		}
+		projectConfig.Fuzzing.TestChainConfig.ForkConfig.ForkModeEnabled = projectConfig.Fuzzing.TestChainConfig.ForkConfig.RpcUrl != ""
+	}
+
+	// Update the fork block number
+	if cmd.Flags().Changed("fork-block") {
+		projectConfig.Fuzzing.TestChainConfig.ForkConfig.RpcBlock, err = cmd.Flags().GetUint64("fork-block")
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyFunctionCoverageSummarySignal relays the signal which requests a function coverage summary (SIGUSR1) to the
+// provided channel.
+func notifyFunctionCoverageSummarySignal(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGUSR1)
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package cmd
+
+import "os"
+
+// notifyFunctionCoverageSummarySignal does nothing, as there is no signal to request a function coverage summary on
+// Windows.
+func notifyFunctionCoverageSummarySignal(c chan<- os.Signal) {
+}
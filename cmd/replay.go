@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crytic/medusa/fuzzing"
+	"github.com/crytic/medusa/logging/colors"
+	"github.com/spf13/cobra"
+)
+
+// replayCmd represents the command provider for replaying failures
+var replayCmd = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Replays a failed test from its failure file",
+	Long: `Compiles and deploys the project's contracts, then replays the call sequence described by a failure file ` +
+		`(as written to the failures directory when a test fails), printing its execution trace. Exits with an error ` +
+		`if the failure still reproduces`,
+	Args: cobra.ExactArgs(1),
+	RunE: cmdRunReplay,
+}
+
+func init() {
+	// Add all the flags allowed for the replay command
+	err := addReplayFlags()
+	if err != nil {
+		panic(err)
+	}
+
+	// Add the replay command to the root command
+	rootCmd.AddCommand(replayCmd)
+}
+
+// cmdRunReplay executes the CLI replay command, reading the project configuration as described by readProjectConfig,
+// then replaying the provided failure file and reporting whether the failure still reproduces.
+func cmdRunReplay(cmd *cobra.Command, args []string) error {
+	// Resolve our failure file before we change our working directory
+	filePath, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Read our project configuration and update it with our flags
+	projectConfig, configPath, err := readProjectConfig(cmd)
+	if err != nil {
+		return err
+	}
+	err = updateProjectConfigWithReplayFlags(cmd, projectConfig)
+	if err != nil {
+		return err
+	}
+
+	// Change our working directory to the parent directory of the project configuration file, as paths in the
+	// configuration are relative to it.
+	err = os.Chdir(filepath.Dir(configPath))
+	if err != nil {
+		return err
+	}
+
+	// Create our fuzzer, which compiles our contracts, then replay our failure with it
+	fuzzer, err := fuzzing.NewFuzzer(*projectConfig)
+	if err != nil {
+		return err
+	}
+	replay, err := fuzzer.ReplayFailureFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	// Report our results
+	for _, mismatch := range replay.DeploymentMismatches {
+		fmt.Println(colors.Colorize(fmt.Sprintf("warning: the deployment differs from the one the failure was found with: %v", mismatch), colors.Yellow))
+	}
+	fmt.Printf("Replayed %d call(s) of the call sequence which failed %v:\n", len(replay.CallSequence), replay.Failure.TestName)
+	fmt.Println(replay.CallSequence.String())
+	if !replay.Reproduced {
+		if len(replay.ViolatedTests) > 0 {
+			fmt.Println(colors.Colorize(fmt.Sprintf("warning: the call sequence violated other tests: %v", strings.Join(replay.ViolatedTests, ", ")), colors.Yellow))
+		}
+		fmt.Println(colors.Colorize(fmt.Sprintf("%v no longer reproduces", replay.Failure.TestName), colors.Green))
+		return nil
+	}
+	return fmt.Errorf("%v still reproduces\n", replay.Failure.TestName)
+}
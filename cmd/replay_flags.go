@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/spf13/cobra"
+)
+
+// addReplayFlags adds the various flags for the replay command
+func addReplayFlags() error {
+	// Prevent alphabetical sorting of usage message
+	replayCmd.Flags().SortFlags = false
+
+	// Config file
+	replayCmd.Flags().String("config", "", "path to config file")
+
+	// Target
+	replayCmd.Flags().String("target", "", TargetFlagDescription)
+
+	return nil
+}
+
+// updateProjectConfigWithReplayFlags will update the given projectConfig with any CLI arguments that were provided to
+// the replay command
+func updateProjectConfigWithReplayFlags(cmd *cobra.Command, projectConfig *config.ProjectConfig) error {
+	// If --target was used
+	if cmd.Flags().Changed("target") {
+		newTarget, err := cmd.Flags().GetString("target")
+		if err != nil {
+			return err
+		}
+
+		err = projectConfig.Compilation.SetTarget(newTarget)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/crytic/medusa/logging/colors"
 	"github.com/spf13/cobra"
 )
 
@@ -8,10 +11,40 @@ const version = "0.1.0"
 
 // rootCmd represents the root CLI command object which all other commands stem from.
 var rootCmd = &cobra.Command{
-	Use:     "medusa",
-	Version: version,
-	Short:   "A Solidity smart contract fuzzing harness",
-	Long:    "medusa is a solidity smart contract fuzzing harness",
+	Use:               "medusa",
+	Version:           version,
+	Short:             "A Solidity smart contract fuzzing harness",
+	Long:              "medusa is a solidity smart contract fuzzing harness",
+	PersistentPreRunE: cmdSetColorMode,
+}
+
+func init() {
+	// Colors apply to every command
+	rootCmd.PersistentFlags().String("color", string(colors.ModeAuto), "when to color output (\"auto\", \"always\" or \"never\"); \"auto\" colors output only if it is a terminal and NO_COLOR is not set")
+	rootCmd.PersistentFlags().Bool("no-color", false, "disable colored output (equivalent to --color=never)")
+}
+
+// cmdSetColorMode sets when output is colored, given the --color and --no-color flags.
+func cmdSetColorMode(cmd *cobra.Command, args []string) error {
+	colorMode, err := cmd.Flags().GetString("color")
+	if err != nil {
+		return err
+	}
+	if !colors.Mode(colorMode).IsValid() {
+		return fmt.Errorf("unsupported color mode '%v', expected 'auto', 'always' or 'never'", colorMode)
+	}
+	noColor, err := cmd.Flags().GetBool("no-color")
+	if err != nil {
+		return err
+	}
+	if noColor {
+		if cmd.Flags().Changed("color") && colors.Mode(colorMode) != colors.ModeNever {
+			return fmt.Errorf("--no-color cannot be used with --color=%v", colorMode)
+		}
+		colorMode = string(colors.ModeNever)
+	}
+	colors.SetMode(colors.Mode(colorMode))
+	return nil
 }
 
 // Execute provides an exportable function to invoke the CLI.
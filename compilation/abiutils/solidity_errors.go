@@ -23,6 +23,37 @@ const (
 	PanicCodeCallUninitializedVariable     = 0x51
 )
 
+// GetSolidityPanicCodeDescription obtains a human-readable description of the provided `Panic(uint)` error code.
+// Returns the description, or a generic description if the panic code is not known.
+func GetSolidityPanicCodeDescription(panicCode *big.Int) string {
+	if !panicCode.IsUint64() {
+		return "unknown panic"
+	}
+	switch panicCode.Uint64() {
+	case PanicCodeCompilerInserted:
+		return "generic compiler inserted panic"
+	case PanicCodeAssertFailed:
+		return "assertion failed"
+	case PanicCodeArithmeticUnderOverflow:
+		return "arithmetic underflow or overflow"
+	case PanicCodeDivideByZero:
+		return "division or modulo by zero"
+	case PanicCodeEnumTypeConversionOutOfBounds:
+		return "conversion of out-of-bounds value to enum type"
+	case PanicCodeIncorrectStorageAccess:
+		return "access to incorrectly encoded storage byte array"
+	case PanicCodePopEmptyArray:
+		return "pop on an empty array"
+	case PanicCodeOutOfBoundsArrayAccess:
+		return "out-of-bounds array access"
+	case PanicCodeAllocateTooMuchMemory:
+		return "allocation of too much memory or too large an array"
+	case PanicCodeCallUninitializedVariable:
+		return "call to an uninitialized internal function"
+	}
+	return "unknown panic"
+}
+
 // GetSolidityPanicCode obtains a panic code from a VM error and return data, if possible.
 // A flag is provided indicating whether assertion failures in older Solidity compilations will be also mapped onto
 // newer Solidity panic code.
@@ -105,3 +136,41 @@ func GetSolidityCustomRevertError(contractAbi *abi.ABI, returnError error, retur
 	}
 	return nil, nil
 }
+
+// SolidityCustomErrors maps the 4-byte selectors of custom Solidity errors to their ABI error definitions, so reverts
+// can be decoded regardless of which contract's ABI declared the error.
+type SolidityCustomErrors map[[4]byte]*abi.Error
+
+// Add adds every custom error definition in the provided ABI.
+func (e SolidityCustomErrors) Add(contractAbi *abi.ABI) {
+	for _, abiError := range contractAbi.Errors {
+		// Make a local copy to avoid taking a pointer of a loop variable.
+		abiError := abiError
+		var selector [4]byte
+		copy(selector[:], abiError.ID.Bytes()[:4])
+		e[selector] = &abiError
+	}
+}
+
+// GetSolidityCustomRevertError obtains a custom Solidity error returned, if one was and could be resolved from the
+// known errors.
+// Returns the ABI error definition as well as its unpacked values. Or returns nil outputs if a custom error was not
+// emitted, or could not be resolved.
+func (e SolidityCustomErrors) GetSolidityCustomRevertError(returnError error, returnData []byte) (*abi.Error, []any) {
+	if returnError != vm.ErrExecutionReverted || len(returnData) < 4 {
+		return nil, nil
+	}
+
+	// Look up the error by the data's leading selector value, and unpack its arguments.
+	var selector [4]byte
+	copy(selector[:], returnData[:4])
+	matchedCustomError, ok := e[selector]
+	if !ok {
+		return nil, nil
+	}
+	unpackedCustomErrorArgs, err := matchedCustomError.Inputs.Unpack(returnData[4:])
+	if err != nil {
+		return nil, nil
+	}
+	return matchedCustomError, unpackedCustomErrorArgs
+}
@@ -0,0 +1,159 @@
+package compilation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/crytic/medusa/compilation/platforms"
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/utils"
+)
+
+// CompilationCachePath describes the path of the file compilations are cached in, relative to the project directory.
+var CompilationCachePath = filepath.Join(".medusa", "compilation_cache.json")
+
+// compilationCacheFormatVersion describes the version of the format compilations are cached in. It should be
+// incremented whenever the format changes, or compilations are parsed differently, so caches written previously are
+// invalidated.
+const compilationCacheFormatVersion = 2
+
+// compilationCacheSourceExtensions describes the extensions of the source files which affect a compilation.
+var compilationCacheSourceExtensions = []string{".sol", ".vy", ".vyi"}
+
+// compilationCacheConfigFileNames describes the names of the files which configure how a compilation framework
+// compiles sources, such as the remappings and compiler settings they describe.
+var compilationCacheConfigFileNames = []string{
+	"foundry.toml", "remappings.txt", "hardhat.config.js", "hardhat.config.ts", "truffle-config.js", "truffle.js",
+}
+
+// compilationCache describes the JSON representation of a cached compilation.
+type compilationCache struct {
+	// FormatVersion describes the version of the format the compilation was cached in.
+	FormatVersion int `json:"formatVersion"`
+
+	// Key describes the hash of every input of the compilation.
+	Key string `json:"key"`
+
+	// Compilations describes the compilations produced.
+	Compilations []types.Compilation `json:"compilations"`
+
+	// Output describes the output of the compilation platform.
+	Output string `json:"output"`
+}
+
+// compilationCacheKey computes a hash of every input of a compilation with the provided platform config: the
+// platform and its configuration (including any remappings and compiler settings), and the contents of every source
+// and framework configuration file under its target. If the target is a file, every file in its directory is hashed,
+// so changes to the files it imports are observed. Hidden and node_modules directories are skipped.
+// Returns the hex-encoded hash, or an error if one occurs.
+func compilationCacheKey(platformConfig platforms.PlatformConfig) (string, error) {
+	platformConfigBytes, err := json.Marshal(platformConfig)
+	if err != nil {
+		return "", err
+	}
+
+	// Determine the files to hash.
+	target := platformConfig.GetTarget()
+	if target == "" {
+		target = "."
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		target = filepath.Dir(target)
+	}
+	paths := make([]string, 0)
+	err = filepath.WalkDir(target, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != target && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		for _, extension := range compilationCacheSourceExtensions {
+			if filepath.Ext(path) == extension {
+				paths = append(paths, path)
+				return nil
+			}
+		}
+		for _, name := range compilationCacheConfigFileNames {
+			if entry.Name() == name {
+				paths = append(paths, path)
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(paths)
+
+	// Hash our inputs, prefixing each with its length, so the boundaries between them are unambiguous.
+	hash := sha256.New()
+	write := func(b []byte) {
+		fmt.Fprintf(hash, "%d:", len(b))
+		hash.Write(b)
+	}
+	write([]byte(platformConfig.Platform()))
+	write(platformConfigBytes)
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		write([]byte(filepath.ToSlash(path)))
+		write(b)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// readCompilationCache reads the compilations cached at the provided path, if they were cached in the current format
+// with the provided key.
+// Returns the compilations and the output of the compilation platform, and a boolean indicating whether they were
+// read.
+func readCompilationCache(path string, key string) ([]types.Compilation, string, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", false
+	}
+	var cache compilationCache
+	err = json.Unmarshal(b, &cache)
+	if err != nil || cache.FormatVersion != compilationCacheFormatVersion || cache.Key != key {
+		return nil, "", false
+	}
+	return cache.Compilations, cache.Output, true
+}
+
+// writeCompilationCache caches the provided compilations and compilation platform output at the provided path, with
+// the provided key, replacing any compilations cached previously.
+// Returns an error if one occurs.
+func writeCompilationCache(path string, key string, compilations []types.Compilation, output string) error {
+	b, err := json.Marshal(compilationCache{
+		FormatVersion: compilationCacheFormatVersion,
+		Key:           key,
+		Compilations:  compilations,
+		Output:        output,
+	})
+	if err != nil {
+		return err
+	}
+	err = utils.MakeDirectory(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(path, b, 0644)
+}
@@ -0,0 +1,128 @@
+package compilation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crytic/medusa/compilation/platforms"
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/utils"
+	"github.com/crytic/medusa/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompiledContractJSON tests that a compiled contract is preserved when it is serialized to, and deserialized
+// from JSON, including its ABI.
+func TestCompiledContractJSON(t *testing.T) {
+	contractAbi, err := types.ParseABIFromInterface(`[
+		{"type": "constructor", "stateMutability": "payable", "inputs": [{"name": "owner", "type": "address"}]},
+		{"type": "fallback", "stateMutability": "nonpayable"},
+		{"type": "receive", "stateMutability": "payable"},
+		{"type": "function", "name": "transfer", "stateMutability": "nonpayable",
+			"inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}],
+			"outputs": [{"name": "", "type": "bool"}]},
+		{"type": "function", "name": "transfer", "stateMutability": "nonpayable",
+			"inputs": [{"name": "to", "type": "address"}], "outputs": []},
+		{"type": "function", "name": "submit", "stateMutability": "view",
+			"inputs": [{"name": "orders", "type": "tuple[2][]", "internalType": "struct Exchange.Order[2][]", "components": [
+				{"name": "maker", "type": "address"},
+				{"name": "fills", "type": "tuple[]", "internalType": "struct Exchange.Fill[]", "components": [
+					{"name": "amount", "type": "uint128"}, {"name": "data", "type": "bytes"}]}]}],
+			"outputs": [{"name": "ids", "type": "bytes32[]"}]},
+		{"type": "event", "name": "Transfer", "anonymous": false, "inputs": [
+			{"name": "from", "type": "address", "indexed": true}, {"name": "amount", "type": "uint256", "indexed": false}]},
+		{"type": "error", "name": "InsufficientBalance", "inputs": [{"name": "needed", "type": "uint256"}]}
+	]`)
+	assert.NoError(t, err)
+	contract := types.CompiledContract{
+		Abi:                   *contractAbi,
+		InitBytecode:          []byte{0x60, 0x80, 0x60, 0x40},
+		RuntimeBytecode:       []byte{0x60, 0x80},
+		SrcMapsInit:           "1:2:0:-",
+		SrcMapsRuntime:        "3:4:0:i",
+		MethodInputSizeLimits: map[string][]int{"a9059cbb": {0, 32}},
+		InitLinkReferences:    types.LinkReferences{"contracts/Library.sol:Library": {1}},
+	}
+
+	// Serialize and deserialize our contract, ensuring it is unchanged.
+	b, err := json.Marshal(contract)
+	assert.NoError(t, err)
+	var deserializedContract types.CompiledContract
+	err = json.Unmarshal(b, &deserializedContract)
+	assert.NoError(t, err)
+	assert.EqualValues(t, contract, deserializedContract)
+}
+
+// TestCompilationCache tests that compilations are cached, and only recompiled when the sources or settings of the
+// compilation target change, or compilation caching is disabled.
+func TestCompilationCache(t *testing.T) {
+	// Our fake vyper compiler is a shell script, so this test cannot run on Windows.
+	if utils.IsWindowsEnvironment() {
+		t.Skip("the fake vyper compiler used by this test requires a POSIX shell")
+	}
+
+	// Copy our testdata over to our testing directory
+	vyperDirectory := testutils.CopyToTestDirectory(t, "platforms/testdata/vyper/basic_project/")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, vyperDirectory, func() {
+		// Create a fake vyper compiler which prints the output of a previous compilation, counting its invocations.
+		commandPath := filepath.Join(t.TempDir(), "vyper")
+		script := "#!/bin/sh\necho invoked >> '" + commandPath + ".log'\ncat '" + filepath.Join(vyperDirectory, "combined_json.json") + "'\n"
+		assert.NoError(t, os.WriteFile(commandPath, []byte(script), 0755))
+		invocations := func() int {
+			b, _ := os.ReadFile(commandPath + ".log")
+			return len(b) / len("invoked\n")
+		}
+
+		vyperConfig := platforms.NewVyperCompilationConfig("contracts")
+		vyperConfig.Command = commandPath
+		compilationConfig, err := NewCompilationConfigFromPlatformConfig(vyperConfig)
+		assert.NoError(t, err)
+
+		// Compiling twice should only invoke our compiler once, with the same compilations produced by both.
+		compilations, _, err := compilationConfig.Compile()
+		assert.NoError(t, err)
+		cachedCompilations, _, err := compilationConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, invocations())
+		assert.EqualValues(t, compilations, cachedCompilations)
+
+		// Changing a source should invalidate our cache.
+		sourcePath := filepath.Join("contracts", "Token.vy")
+		source, err := os.ReadFile(sourcePath)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(sourcePath, append(source, []byte("\n# changed\n")...), 0644))
+		_, _, err = compilationConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, invocations())
+
+		// Changing our settings should invalidate our cache.
+		vyperConfig.Args = []string{"--optimize", "gas"}
+		assert.NoError(t, compilationConfig.SetPlatformConfig(vyperConfig))
+		_, _, err = compilationConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, invocations())
+
+		// A cache written in another format should not be used.
+		b, err := os.ReadFile(CompilationCachePath)
+		assert.NoError(t, err)
+		var cache compilationCache
+		assert.NoError(t, json.Unmarshal(b, &cache))
+		cache.FormatVersion = compilationCacheFormatVersion - 1
+		b, err = json.Marshal(cache)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(CompilationCachePath, b, 0644))
+		_, _, err = compilationConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 4, invocations())
+
+		// Disabling our cache should always invoke our compiler.
+		compilationConfig.DisableCache = true
+		_, _, err = compilationConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 5, invocations())
+	})
+}
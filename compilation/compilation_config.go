@@ -7,6 +7,7 @@ import (
 
 	"github.com/crytic/medusa/compilation/platforms"
 	"github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/logging"
 )
 
 // CompilationConfig describes the configuration options used to compile a smart contract
@@ -18,6 +19,11 @@ type CompilationConfig struct {
 
 	// PlatformConfig describes the Platform-specific configuration needed to compile.
 	PlatformConfig *json.RawMessage `json:"platformConfig"`
+
+	// DisableCache describes whether compilations should not be cached in the CompilationCachePath of the project.
+	// When caching is enabled, the platform is only invoked if its configuration or the contents of the source files
+	// under its target changed since the cached compilations were produced.
+	DisableCache bool `json:"disableCache"`
 }
 
 // NewCompilationConfig returns a CompilationConfig with default values for a given platform identifier.
@@ -63,8 +69,33 @@ func (c *CompilationConfig) Compile() ([]types.Compilation, string, error) {
 		return nil, "", err
 	}
 
-	// Compile using our solc configs
-	return platformConfig.Compile()
+	// If caching is disabled, compile using our platform configs.
+	if c.DisableCache {
+		return platformConfig.Compile()
+	}
+
+	// Otherwise, load our cached compilations if none of their inputs changed. If we cannot hash our inputs, we compile
+	// without caching.
+	cacheKey, err := compilationCacheKey(platformConfig)
+	if err != nil {
+		logging.GlobalLogger.Warn().Err(err).Msgf("Failed to check the compilation cache, compiling without it: %v", err)
+		return platformConfig.Compile()
+	}
+	if compilations, output, ok := readCompilationCache(CompilationCachePath, cacheKey); ok {
+		logging.GlobalLogger.Info().Msgf("Loaded compilations from %v, as the compilation target did not change", CompilationCachePath)
+		return compilations, output, nil
+	}
+
+	// Compile using our platform configs, caching the result.
+	compilations, output, err := platformConfig.Compile()
+	if err != nil {
+		return nil, output, err
+	}
+	err = writeCompilationCache(CompilationCachePath, cacheKey, compilations, output)
+	if err != nil {
+		logging.GlobalLogger.Warn().Err(err).Msgf("Failed to write the compilation cache: %v", err)
+	}
+	return compilations, output, nil
 }
 
 // GetPlatformConfig will return the de-serialized version of platforms.PlatformConfig for a given CompilationConfig
@@ -1,7 +1,6 @@
 package platforms
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -210,23 +209,25 @@ func (c *CryticCompilationConfig) Compile() ([]types.Compilation, string, error)
 				return nil, "", fmt.Errorf("unable to parse ABI for contract '%s'\n", contractName)
 			}
 
-			// Decode our init and runtime bytecode
-			initBytecode, err := hex.DecodeString(strings.TrimPrefix(contract.Bin, "0x"))
+			// Decode our init and runtime bytecode, which may contain placeholders for the addresses of libraries
+			initBytecode, initLinkReferences, err := types.DecodeLinkableBytecode(contract.Bin)
 			if err != nil {
 				return nil, "", fmt.Errorf("unable to parse init bytecode for contract '%s'\n", contractName)
 			}
-			runtimeBytecode, err := hex.DecodeString(strings.TrimPrefix(contract.BinRuntime, "0x"))
+			runtimeBytecode, runtimeLinkReferences, err := types.DecodeLinkableBytecode(contract.BinRuntime)
 			if err != nil {
 				return nil, "", fmt.Errorf("unable to parse runtime bytecode for contract '%s'\n", contractName)
 			}
 
 			// Add contract details
 			compilation.Sources[sourcePath].Contracts[contractName] = types.CompiledContract{
-				Abi:             *contractAbi,
-				InitBytecode:    initBytecode,
-				RuntimeBytecode: runtimeBytecode,
-				SrcMapsInit:     contract.SrcMap,
-				SrcMapsRuntime:  contract.SrcMapRuntime,
+				Abi:                   *contractAbi,
+				InitBytecode:          initBytecode,
+				RuntimeBytecode:       runtimeBytecode,
+				SrcMapsInit:           contract.SrcMap,
+				SrcMapsRuntime:        contract.SrcMapRuntime,
+				InitLinkReferences:    initLinkReferences,
+				RuntimeLinkReferences: runtimeLinkReferences,
 			}
 		}
 
@@ -0,0 +1,219 @@
+package platforms
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/crytic/medusa/compilation/types"
+)
+
+// FoundryCompilationConfig represents the various configuration options that can be provided by the user
+// while using the `foundry` platform
+type FoundryCompilationConfig struct {
+	// Target is the root directory of the Foundry project, which contains its foundry.toml
+	Target string `json:"target"`
+
+	// Profile is the foundry.toml profile to compile with. If empty, the profile set by the FOUNDRY_PROFILE environment
+	// variable is used, or the default profile if it is not set.
+	Profile string `json:"profile"`
+
+	// OutDirectory is the directory, relative to the Target, which build artifacts are written to. If empty, the `out`
+	// setting of the profile in foundry.toml is used, or `out` if it is not set.
+	OutDirectory string `json:"outDirectory"`
+
+	// Remappings are additional import remappings provided to `forge build`, on top of those the project defines
+	Remappings []string `json:"remappings"`
+
+	// LibPaths are additional library directories provided to `forge build`, on top of those the project defines
+	LibPaths []string `json:"libPaths"`
+
+	// Force indicates whether the project is compiled in full, ignoring forge's cache, so no stale build-info files
+	// remain from previous builds
+	Force bool `json:"force"`
+
+	// SkipBuild indicates whether `forge build` should not be invoked, and build-info files from a previous
+	// `forge build --build-info` read instead
+	SkipBuild bool `json:"skipBuild"`
+
+	// Args are additional arguments that can be provided to `forge build`
+	Args []string `json:"args"`
+}
+
+// Platform returns the platform type
+func (f *FoundryCompilationConfig) Platform() string {
+	return "foundry"
+}
+
+// GetTarget returns the target for compilation
+func (f *FoundryCompilationConfig) GetTarget() string {
+	return f.Target
+}
+
+// SetTarget sets the new target for compilation
+func (f *FoundryCompilationConfig) SetTarget(newTarget string) {
+	f.Target = newTarget
+}
+
+// NewFoundryCompilationConfig returns the default configuration options while using `foundry`
+func NewFoundryCompilationConfig(target string) *FoundryCompilationConfig {
+	return &FoundryCompilationConfig{
+		Target:       target,
+		Profile:      "",
+		OutDirectory: "",
+		Remappings:   []string{},
+		LibPaths:     []string{},
+		Force:        true,
+		SkipBuild:    false,
+		Args:         []string{},
+	}
+}
+
+// validateArgs ensures that the additional arguments provided to `forge build` do not contain the `--out` argument, as
+// the FoundryCompilationConfig.OutDirectory option is equivalent to it, and we must know where artifacts are written.
+func (f *FoundryCompilationConfig) validateArgs() error {
+	for _, arg := range f.Args {
+		if arg == "--out" || arg == "-o" || strings.HasPrefix(arg, "--out=") {
+			return fmt.Errorf("do not specify `%s` as an argument, use the outDirectory config variable instead", arg)
+		}
+	}
+	return nil
+}
+
+// getArgs returns the arguments to be provided to `forge build` during compilation.
+func (f *FoundryCompilationConfig) getArgs() []string {
+	// We always write build-info files, as they hold the ASTs and source maps of every compiled source.
+	args := []string{"build", "--build-info"}
+	if f.Force {
+		args = append(args, "--force")
+	}
+	if f.OutDirectory != "" {
+		args = append(args, "--out", f.OutDirectory)
+	}
+	for _, remapping := range f.Remappings {
+		args = append(args, "--remappings", remapping)
+	}
+	for _, libPath := range f.LibPaths {
+		args = append(args, "--lib-paths", libPath)
+	}
+	return append(args, f.Args...)
+}
+
+// getProfile returns the foundry.toml profile the project is compiled with.
+func (f *FoundryCompilationConfig) getProfile() string {
+	if f.Profile != "" {
+		return f.Profile
+	}
+	if profile := os.Getenv("FOUNDRY_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// Compile uses the FoundryCompilationConfig provided to compile a given target with `forge build`, parse the
+// build-info files it writes, and then create a list of types.Compilation.
+func (f *FoundryCompilationConfig) Compile() ([]types.Compilation, string, error) {
+	// Validate args to make sure --out is not specified
+	err := f.validateArgs()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Run forge to compile our project, unless we were asked to read the artifacts of a previous build.
+	var out []byte
+	if !f.SkipBuild {
+		cmd := exec.Command("forge", f.getArgs()...)
+		cmd.Dir = f.Target
+		if f.Profile != "" {
+			cmd.Env = append(os.Environ(), "FOUNDRY_PROFILE="+f.Profile)
+		}
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			return nil, "", fmt.Errorf("error while executing forge:\nOUTPUT:\n%s\nERROR: %s\n", string(out), err.Error())
+		}
+	}
+
+	// Resolve the directory forge wrote our artifacts to.
+	outDirectory := f.OutDirectory
+	if outDirectory == "" {
+		outDirectory, err = readFoundryOutDirectory(f.Target, f.getProfile())
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if !filepath.IsAbs(outDirectory) {
+		outDirectory = filepath.Join(f.Target, outDirectory)
+	}
+
+	// Parse a compilation from every build-info file forge wrote.
+	compilations, err := readSolcBuildInfoCompilations(filepath.Join(outDirectory, "build-info"), f.Target)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not read forge's build artifacts, ensure the project is built with `forge build --build-info`: %v", err)
+	}
+	return compilations, string(out), nil
+}
+
+// readFoundryOutDirectory reads the directory the provided profile of the Foundry project in the provided directory
+// writes build artifacts to, from the FOUNDRY_OUT environment variable, or the `out` setting of the profile (or the
+// default profile) in the project's foundry.toml. Only the subset of TOML used to define this setting is supported.
+// Returns the out directory, which is `out` if it is not set, or an error if one occurs.
+func readFoundryOutDirectory(projectDirectory string, profile string) (string, error) {
+	// Environment variables take precedence over foundry.toml, as they do for forge itself.
+	if outDirectory := os.Getenv("FOUNDRY_OUT"); outDirectory != "" {
+		return outDirectory, nil
+	}
+
+	// Read our foundry.toml. Without one, forge uses its defaults.
+	file, err := os.Open(filepath.Join(projectDirectory, "foundry.toml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "out", nil
+		}
+		return "", err
+	}
+	defer file.Close()
+
+	// Find the out setting of our profile and the default profile, which our profile inherits settings from.
+	var section, profileOut, defaultOut string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			if end := strings.Index(line, "]"); end != -1 {
+				section = strings.TrimSpace(line[1:end])
+			}
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) != "out" {
+			continue
+		}
+
+		// Strip any trailing comment and the quotes around our value.
+		value = strings.TrimSpace(value)
+		if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+			quote := value[:1]
+			if end := strings.Index(value[1:], quote); end != -1 {
+				value = value[1 : end+1]
+			}
+		}
+		if section == "profile."+profile {
+			profileOut = value
+		} else if section == "profile.default" {
+			defaultOut = value
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return "", err
+	}
+
+	if profileOut != "" {
+		return profileOut, nil
+	} else if defaultOut != "" {
+		return defaultOut, nil
+	}
+	return "out", nil
+}
@@ -0,0 +1,105 @@
+package platforms
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFoundryCompilationAbsolutePath tests compilation of a Foundry project with an absolute project path.
+func TestFoundryCompilationAbsolutePath(t *testing.T) {
+	// Copy our testdata over to our testing directory
+	foundryDirectory := testutils.CopyToTestDirectory(t, "testdata/foundry/basic_project/")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, foundryDirectory, func() {
+		// Create a foundry provider
+		foundryConfig := NewFoundryCompilationConfig(foundryDirectory)
+
+		// Compile the project and ensure we obtained a compilation for each solc version it uses
+		compilations, _, err := foundryConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, len(compilations))
+	})
+}
+
+// TestFoundryCompilationBuildInfo tests parsing the build-info files of a previously built Foundry project, ensuring
+// a compilation is produced for each solc version, with the contracts, bytecode, source maps and ASTs of its sources.
+func TestFoundryCompilationBuildInfo(t *testing.T) {
+	// Copy our testdata over to our testing directory
+	foundryDirectory := testutils.CopyToTestDirectory(t, "testdata/foundry/basic_project/")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, foundryDirectory, func() {
+		// Read the artifacts of the previous build, rather than invoking forge.
+		foundryConfig := NewFoundryCompilationConfig(foundryDirectory)
+		foundryConfig.SkipBuild = true
+		compilations, _, err := foundryConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, len(compilations))
+
+		// Each compilation should describe one source, resolved relative to the project, and its contract.
+		expectedContracts := map[string]string{
+			filepath.Join(foundryDirectory, "src", "FirstContract.sol"):  "FirstContract",
+			filepath.Join(foundryDirectory, "src", "SecondContract.sol"): "SecondContract",
+		}
+		for _, compilation := range compilations {
+			assert.EqualValues(t, 1, len(compilation.Sources))
+			sourcePath, ok := compilation.GetSourcePath(0)
+			assert.True(t, ok)
+			contractName, ok := expectedContracts[sourcePath]
+			assert.True(t, ok)
+
+			contract, ok := compilation.Sources[sourcePath].Contracts[contractName]
+			assert.True(t, ok)
+			assert.EqualValues(t, 1, len(contract.Abi.Methods))
+			assert.NotEmpty(t, contract.InitBytecode)
+			assert.NotEmpty(t, contract.RuntimeBytecode)
+			for _, sourceMap := range []string{contract.SrcMapsInit, contract.SrcMapsRuntime} {
+				parsedSourceMap, err := types.ParseSourceMap(sourceMap)
+				assert.NoError(t, err)
+				assert.NotEmpty(t, parsedSourceMap)
+			}
+
+			// The function of each method should be resolvable from the AST.
+			for _, method := range contract.Abi.Methods {
+				functionSourceRanges := compilation.GetFunctionSourceRanges(sourcePath, contractName)
+				_, ok = functionSourceRanges[hex.EncodeToString(method.ID)]
+				assert.True(t, ok)
+			}
+		}
+
+		// Artifacts of another profile should be read from the directory it writes them to.
+		foundryConfig.Profile = "ci"
+		_, _, err = foundryConfig.Compile()
+		assert.Error(t, err)
+	})
+}
+
+// TestFoundryOutDirectory tests resolving the directory a Foundry project writes build artifacts to, for each profile
+// it defines.
+func TestFoundryOutDirectory(t *testing.T) {
+	foundryDirectory := testutils.CopyToTestDirectory(t, "testdata/foundry/basic_project/")
+
+	// Profiles without an out setting inherit that of the default profile.
+	for profile, expected := range map[string]string{"default": "out", "ci": "out-ci", "unknown": "out"} {
+		outDirectory, err := readFoundryOutDirectory(foundryDirectory, profile)
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, outDirectory)
+	}
+
+	// Projects without a foundry.toml use forge's default.
+	outDirectory, err := readFoundryOutDirectory(t.TempDir(), "default")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "out", outDirectory)
+
+	// The FOUNDRY_OUT environment variable takes precedence.
+	t.Setenv("FOUNDRY_OUT", "artifacts")
+	outDirectory, err = readFoundryOutDirectory(foundryDirectory, "ci")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "artifacts", outDirectory)
+}
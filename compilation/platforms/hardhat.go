@@ -0,0 +1,127 @@
+package platforms
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/crytic/medusa/compilation/types"
+)
+
+// HardhatCompilationConfig represents the various configuration options that can be provided by the user
+// while using the `hardhat` platform
+type HardhatCompilationConfig struct {
+	// Target is the root directory of the Hardhat project
+	Target string `json:"target"`
+
+	// ConfigFile is the path of the Hardhat config file to compile with, relative to the Target. If empty, Hardhat
+	// finds the config file of the project itself.
+	ConfigFile string `json:"configFile"`
+
+	// UseNpx indicates whether Hardhat is invoked through `npx`
+	UseNpx bool `json:"useNpx"`
+
+	// Command is the command used to invoke Hardhat. If empty, `hardhat` is used.
+	Command string `json:"command"`
+
+	// ArtifactsDirectory is the directory, relative to the Target, which Hardhat writes build artifacts to. If empty,
+	// Hardhat's default of `artifacts` is used. This must match the `paths.artifacts` setting of the Hardhat config.
+	ArtifactsDirectory string `json:"artifactsDirectory"`
+
+	// Force indicates whether the project is compiled in full, ignoring Hardhat's cache
+	Force bool `json:"force"`
+
+	// SkipBuild indicates whether Hardhat should not be invoked, and build-info files from a previous compilation
+	// read instead
+	SkipBuild bool `json:"skipBuild"`
+
+	// Args are additional arguments that can be provided to `hardhat compile`
+	Args []string `json:"args"`
+}
+
+// Platform returns the platform type
+func (h *HardhatCompilationConfig) Platform() string {
+	return "hardhat"
+}
+
+// GetTarget returns the target for compilation
+func (h *HardhatCompilationConfig) GetTarget() string {
+	return h.Target
+}
+
+// SetTarget sets the new target for compilation
+func (h *HardhatCompilationConfig) SetTarget(newTarget string) {
+	h.Target = newTarget
+}
+
+// NewHardhatCompilationConfig returns the default configuration options while using `hardhat`
+func NewHardhatCompilationConfig(target string) *HardhatCompilationConfig {
+	return &HardhatCompilationConfig{
+		Target:             target,
+		ConfigFile:         "",
+		UseNpx:             true,
+		Command:            "",
+		ArtifactsDirectory: "",
+		Force:              true,
+		SkipBuild:          false,
+		Args:               []string{},
+	}
+}
+
+// getArgs returns the arguments to be provided to Hardhat during compilation.
+func (h *HardhatCompilationConfig) getArgs() []string {
+	// Determine the base command to use.
+	var baseCommandStr = "hardhat"
+	if h.Command != "" {
+		baseCommandStr = h.Command
+	}
+
+	// Build-info files are always written by `hardhat compile`, so we need no arguments to obtain them.
+	args := []string{baseCommandStr}
+	if h.ConfigFile != "" {
+		args = append(args, "--config", h.ConfigFile)
+	}
+	args = append(args, "compile")
+	if h.Force {
+		args = append(args, "--force")
+	}
+	return append(args, h.Args...)
+}
+
+// Compile uses the HardhatCompilationConfig provided to compile a given target with `hardhat compile`, parse the
+// build-info files it writes, and then create a list of types.Compilation.
+func (h *HardhatCompilationConfig) Compile() ([]types.Compilation, string, error) {
+	// Run Hardhat to compile our project, unless we were asked to read the artifacts of a previous compilation.
+	var out []byte
+	if !h.SkipBuild {
+		var cmd *exec.Cmd
+		if args := h.getArgs(); h.UseNpx {
+			cmd = exec.Command("npx", args...)
+		} else {
+			cmd = exec.Command(args[0], args[1:]...)
+		}
+		cmd.Dir = h.Target
+		var err error
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			return nil, "", fmt.Errorf("error while executing hardhat:\nOUTPUT:\n%s\nERROR: %s\n", string(out), err.Error())
+		}
+	}
+
+	// Resolve the directory Hardhat wrote our artifacts to.
+	artifactsDirectory := h.ArtifactsDirectory
+	if artifactsDirectory == "" {
+		artifactsDirectory = "artifacts"
+	}
+	if !filepath.IsAbs(artifactsDirectory) {
+		artifactsDirectory = filepath.Join(h.Target, artifactsDirectory)
+	}
+
+	// Parse a compilation from every build-info file Hardhat wrote. Projects using several solc versions produce a
+	// build-info file for each.
+	compilations, err := readSolcBuildInfoCompilations(filepath.Join(artifactsDirectory, "build-info"), h.Target)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not read hardhat's build artifacts: %v", err)
+	}
+	return compilations, string(out), nil
+}
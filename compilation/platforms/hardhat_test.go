@@ -0,0 +1,80 @@
+package platforms
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/crytic/medusa/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHardhatCompilationAbsolutePath tests compilation of a hardhat project with an absolute project path.
+func TestHardhatCompilationAbsolutePath(t *testing.T) {
+	// Copy our testdata over to our testing directory
+	hardhatDirectory := testutils.CopyToTestDirectory(t, "testdata/hardhat/basic_project/")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, hardhatDirectory, func() {
+		// Create a hardhat provider
+		hardhatConfig := NewHardhatCompilationConfig(hardhatDirectory)
+
+		// Compile the project and ensure we obtained a compilation for each solc version it uses
+		compilations, _, err := hardhatConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, len(compilations))
+	})
+}
+
+// TestHardhatCompilationBuildInfo tests parsing the build-info files of a previously compiled hardhat project which
+// uses several solc versions and defines contracts with identical names in different files.
+func TestHardhatCompilationBuildInfo(t *testing.T) {
+	// Copy our testdata over to our testing directory
+	hardhatDirectory := testutils.CopyToTestDirectory(t, "testdata/hardhat/build_info_project/")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, hardhatDirectory, func() {
+		// Read the artifacts of the previous compilation, rather than invoking hardhat.
+		hardhatConfig := NewHardhatCompilationConfig(hardhatDirectory)
+		hardhatConfig.SkipBuild = true
+		compilations, _, err := hardhatConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, len(compilations))
+
+		// Our first compilation should hold both contracts named Token, each with its own methods, and resolve the
+		// sources of its source maps.
+		tokenMethods := map[string]string{
+			filepath.Join(hardhatDirectory, "contracts", "Token.sol"):           "setX",
+			filepath.Join(hardhatDirectory, "contracts", "legacy", "Token.sol"): "setA",
+		}
+		assert.EqualValues(t, 2, len(compilations[0].Sources))
+		for sourcePath, methodName := range tokenMethods {
+			contract, ok := compilations[0].Sources[sourcePath].Contracts["Token"]
+			assert.True(t, ok)
+			_, ok = contract.Abi.Methods[methodName]
+			assert.True(t, ok)
+			assert.NotEmpty(t, contract.InitBytecode)
+			assert.NotEmpty(t, contract.RuntimeBytecode)
+			assert.NotEmpty(t, contract.SrcMapsRuntime)
+		}
+		for sourceUnitID := 0; sourceUnitID < 2; sourceUnitID++ {
+			sourcePath, ok := compilations[0].GetSourcePath(sourceUnitID)
+			assert.True(t, ok)
+			assert.Contains(t, tokenMethods, sourcePath)
+		}
+
+		// Our second compilation should hold the contract compiled with the other solc version.
+		_, ok := compilations[1].Sources[filepath.Join(hardhatDirectory, "contracts", "Vault.sol")].Contracts["Vault"]
+		assert.True(t, ok)
+	})
+}
+
+// TestHardhatCompilationArgs tests the arguments hardhat is invoked with, including a custom config file.
+func TestHardhatCompilationArgs(t *testing.T) {
+	hardhatConfig := NewHardhatCompilationConfig(".")
+	assert.EqualValues(t, []string{"hardhat", "compile", "--force"}, hardhatConfig.getArgs())
+
+	hardhatConfig.ConfigFile = "hardhat.ci.config.js"
+	hardhatConfig.Force = false
+	hardhatConfig.Args = []string{"--quiet"}
+	assert.EqualValues(t, []string{"hardhat", "--config", "hardhat.ci.config.js", "compile", "--quiet"}, hardhatConfig.getArgs())
+}
@@ -1,30 +1,74 @@
 package platforms
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"os/exec"
-	"regexp"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver"
 	"github.com/crytic/medusa/compilation/types"
 	"github.com/crytic/medusa/utils"
-	"github.com/ethereum/go-ethereum/common/compiler"
 )
 
+// SolcCompilationConfig represents the various configuration options that can be provided by the user
+// while using the `solc` platform
 type SolcCompilationConfig struct {
+	// Target is a Solidity source file, or a directory whose Solidity source files are compiled
 	Target string `json:"target"`
+
+	// Sources are additional Solidity source files, or directories of them, which are compiled alongside the Target
+	Sources []string `json:"sources"`
+
+	// CompilerVersion is the solc version every source is compiled with. If empty, each source is compiled with a
+	// version which satisfies the version pragmas of the source and everything it imports.
+	CompilerVersion string `json:"compilerVersion"`
+
+	// UseSystemSolc determines whether the `solc` binary installed on the system is used when its version is
+	// suitable, rather than a downloaded solc binary
+	UseSystemSolc bool `json:"useSystemSolc"`
+
+	// Remappings are import remappings, of the form `[context:]prefix=target`, provided to solc
+	Remappings []string `json:"remappings"`
+
+	// OptimizerEnabled determines whether the solc optimizer is enabled
+	OptimizerEnabled bool `json:"optimizerEnabled"`
+
+	// OptimizerRuns is the number of times the deployed code is expected to run, which the optimizer tunes for
+	OptimizerRuns int `json:"optimizerRuns"`
+
+	// EvmVersion is the EVM version to compile for. If empty, the compiler's default is used.
+	EvmVersion string `json:"evmVersion"`
+
+	// ViaIR determines whether code is generated through solc's IR pipeline
+	ViaIR bool `json:"viaIR"`
+
+	// CacheDirectory is the directory downloaded solc binaries and compilation outputs are cached in. If empty, a
+	// directory within the user's cache directory is used.
+	CacheDirectory string `json:"cacheDirectory"`
 }
 
+// NewSolcCompilationConfig returns the default configuration options while using `solc`
 func NewSolcCompilationConfig(target string) *SolcCompilationConfig {
 	return &SolcCompilationConfig{
-		Target: target,
+		Target:           target,
+		Sources:          []string{},
+		CompilerVersion:  "",
+		UseSystemSolc:    true,
+		Remappings:       []string{},
+		OptimizerEnabled: false,
+		OptimizerRuns:    200,
+		EvmVersion:       "",
+		ViaIR:            false,
+		CacheDirectory:   "",
 	}
 }
 
+// Platform returns the platform type
 func (s *SolcCompilationConfig) Platform() string {
 	return "solc"
 }
@@ -39,133 +83,290 @@ func (s *SolcCompilationConfig) SetTarget(newTarget string) {
 	s.Target = newTarget
 }
 
+// GetSystemSolcVersion obtains the version of the `solc` binary installed on the system.
+// Returns the version, or an error if it could not be obtained.
 func GetSystemSolcVersion() (*semver.Version, error) {
-	// Run solc --version to obtain our compiler version.
-	out, err := exec.Command("solc", "--version").CombinedOutput()
+	return getSolcVersion("solc")
+}
+
+// getCacheDirectory returns the directory downloaded solc binaries and compilation outputs are cached in.
+func (s *SolcCompilationConfig) getCacheDirectory() string {
+	if s.CacheDirectory != "" {
+		return s.CacheDirectory
+	}
+	cacheDirectory, err := os.UserCacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("error while executing solc:\nOUTPUT:\n%s\nERROR: %s\n", string(out), err.Error())
+		cacheDirectory = os.TempDir()
 	}
+	return filepath.Join(cacheDirectory, "medusa", "solc")
+}
 
-	// Parse the compiler version out of the output
-	exp := regexp.MustCompile(`\d+\.\d+\.\d+`)
-	versionStr := exp.FindString(string(out))
-	if versionStr == "" {
-		return nil, errors.New("could not parse solc version using 'solc --version'")
+// getSourceUnitNames returns the source unit names of the Solidity source files to compile, which are the Target and
+// Sources themselves if they are files, or every Solidity source file within them (outside of hidden and node_modules
+// directories) if they are directories. Names are sorted and de-duplicated.
+func (s *SolcCompilationConfig) getSourceUnitNames() ([]string, error) {
+	names := make(map[string]bool)
+	for _, sourcePath := range append([]string{s.Target}, s.Sources...) {
+		info, err := os.Stat(sourcePath)
+		if err != nil {
+			return nil, err
+		}
+		sourceFiles := []string{sourcePath}
+		if info.IsDir() {
+			sourceFiles = nil
+			root := sourcePath
+			err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if info.IsDir() {
+					if path != root && (strings.HasPrefix(info.Name(), ".") || info.Name() == "node_modules") {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if filepath.Ext(path) == ".sol" {
+					sourceFiles = append(sourceFiles, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, sourceFile := range sourceFiles {
+			name, err := solcSourceUnitName(sourceFile)
+			if err != nil {
+				return nil, err
+			}
+			names[name] = true
+		}
 	}
 
-	// Parse our semver string and return it
-	return semver.NewVersion(versionStr)
-}
-
-// SetSolcOutputOptions determines what outputOptions should be provided to solc given a semver.Version
-func (s *SolcCompilationConfig) SetSolcOutputOptions(v *semver.Version) string {
-	// useCompactFormat will add the compact-format output option
-	// if version is 0.4.12-0.4.26 or 0.5.0-0.5.17 or 0.6.0-0.6.12 or 0.7.0-0.7.6 or 0.8.0-0.8.9
-	useCompactFormat := (v.Major() == 0 && v.Minor() == 4 && v.Patch() >= 12 && v.Patch() <= 26) ||
-		(v.Major() == 0 && v.Minor() == 5 && v.Patch() <= 17) ||
-		(v.Major() == 0 && v.Minor() == 6 && v.Patch() <= 12) ||
-		(v.Major() == 0 && v.Minor() == 7 && v.Patch() <= 6) ||
-		(v.Major() == 0 && v.Minor() == 8 && v.Patch() <= 9)
-
-	// if version is 0.3.0-0.3.6 or 0.4.0-0.4.11 no 'hashes' outputOption
-	if (v.Major() == 0 && v.Minor() == 4 && v.Patch() <= 11) || (v.Major() == 0 && v.Minor() == 3 && v.Patch() <= 6) {
-		return "abi,ast,bin,bin-runtime,srcmap,srcmap-runtime,userdoc,devdoc"
-	} else if useCompactFormat {
-		// Both 'hashes' and 'compact-format' are allowed as outputOptions
-		return "abi,ast,bin,bin-runtime,srcmap,srcmap-runtime,userdoc,devdoc,hashes,compact-format"
-	} else {
-		// Can't use 'compact-format' but 'hashes' is allowed as outputOption
-		return "abi,ast,bin,bin-runtime,srcmap,srcmap-runtime,userdoc,devdoc,hashes"
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
 	}
-}
-func (s *SolcCompilationConfig) Compile() ([]types.Compilation, string, error) {
-	// Obtain our solc version string
-	v, err := GetSystemSolcVersion()
-	if err != nil {
-		return nil, "", err
+	sort.Strings(sortedNames)
+	if len(sortedNames) == 0 {
+		return nil, fmt.Errorf("no Solidity source files were found in target '%s'", s.Target)
 	}
+	return sortedNames, nil
+}
 
-	// Determine which compiler options we need.
-	outputOptions := s.SetSolcOutputOptions(v)
+// solcCompilationGroup describes a group of Solidity sources which are compiled together, by a single solc invocation.
+type solcCompilationGroup struct {
+	// version describes the solc version the sources are compiled with.
+	version *semver.Version
 
-	// Create our command
-	cmd := exec.Command("solc", s.Target, "--combined-json", outputOptions)
-	cmdStdout, cmdStderr, cmdCombined, err := utils.RunCommandWithOutputAndError(cmd)
-	if err != nil {
-		return nil, "", fmt.Errorf("error while executing solc:\n%s\n\nCommand Output:\n%s\n", err.Error(), string(cmdCombined))
-	}
+	// names describes the source unit names of the sources to compile, excluding the sources they import.
+	names []string
+}
 
-	// Our compilation succeeded, load the JSON
-	var results map[string]any
-	err = json.Unmarshal(cmdStdout, &results)
-	if err != nil {
-		return nil, "", err
+// groupSourceUnits groups the sources with the provided names by the solc version they are compiled with, selecting
+// for each a version which satisfies the version pragmas of the source and every source it imports. The configured
+// CompilerVersion is used if one is set. Otherwise, the system solc is preferred, followed by the newest cached
+// version, followed by the newest version available for download.
+// Returns the groups, ordered by version, or an error if no suitable version exists for a source.
+func (s *SolcCompilationConfig) groupSourceUnits(sourceUnits map[string]*solcSourceUnit, names []string, versionManager *solcVersionManager, systemVersion *semver.Version) ([]solcCompilationGroup, error) {
+	// Determine the versions we may select from, in our order of preference. Versions available for download are only
+	// fetched if no other version is suitable.
+	var candidates []*semver.Version
+	if s.CompilerVersion != "" {
+		version, err := semver.NewVersion(s.CompilerVersion)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse solc compiler version '%s': %v", s.CompilerVersion, err)
+		}
+		candidates = append(candidates, version)
+	} else {
+		if systemVersion != nil {
+			candidates = append(candidates, systemVersion)
+		}
+		installedVersions, err := versionManager.installedVersions()
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, installedVersions...)
 	}
+	fetchedAvailableVersions := s.CompilerVersion != ""
 
-	// Create a compilation unit out of this.
-	compilation := types.NewCompilation()
-
-	// Parse our sources from solc output
-	if sources, ok := results["sources"]; ok {
-		if sourcesMap, ok := sources.(map[string]any); ok {
-			for name, source := range sourcesMap {
-				// Treat our source as a key-value lookup
-				sourceDict, sourceCorrectType := source.(map[string]any)
-				if !sourceCorrectType {
-					return nil, "", fmt.Errorf("could not parse compiled source artifact because it could not be casted to a dictionary")
+	groups := make([]solcCompilationGroup, 0)
+	for _, name := range names {
+		// Collect the constraints of every source compiled alongside this one.
+		var constraints []solcVersionConstraint
+		for _, closureName := range solcSourceUnitClosure(sourceUnits, name) {
+			constraints = append(constraints, sourceUnits[closureName].versionConstraints...)
+		}
+		satisfies := func(version *semver.Version) bool {
+			for _, constraint := range constraints {
+				if !constraint.check(version) {
+					return false
 				}
+			}
+			return true
+		}
 
-				// Try to obtain our AST key
-				ast, hasAST := sourceDict["AST"]
-				if !hasAST {
-					return nil, "", fmt.Errorf("could not parse AST from sources, AST field could not be found")
+		// Select a version, fetching the versions available for download if none of our candidates are suitable.
+		var selected *semver.Version
+		for selected == nil {
+			for _, candidate := range candidates {
+				if satisfies(candidate) {
+					selected = candidate
+					break
 				}
+			}
+			if selected != nil || fetchedAvailableVersions {
+				break
+			}
+			availableVersions, err := versionManager.availableVersions()
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, availableVersions...)
+			fetchedAvailableVersions = true
+		}
+		if selected == nil {
+			if s.CompilerVersion != "" {
+				return nil, fmt.Errorf("solc version %v does not satisfy the version pragmas of Solidity source '%s' and its imports", s.CompilerVersion, name)
+			}
+			return nil, fmt.Errorf("no solc version satisfies the version pragmas of Solidity source '%s' and its imports", name)
+		}
 
-				// Construct our compiled source object
-				compilation.Sources[name] = types.CompiledSource{
-					Ast:       ast,
-					Contracts: make(map[string]types.CompiledContract),
-				}
+		// Add the source to the group for its version.
+		index := -1
+		for i, group := range groups {
+			if group.version.Equal(selected) {
+				index = i
+				break
 			}
 		}
+		if index < 0 {
+			groups = append(groups, solcCompilationGroup{version: selected})
+			index = len(groups) - 1
+		}
+		groups[index].names = append(groups[index].names, name)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].version.LessThan(groups[j].version)
+	})
+	return groups, nil
+}
+
+// standardJsonInput constructs the standard JSON input which compiles the sources with the provided names, along with
+// every source they import, from the provided sources.
+func (s *SolcCompilationConfig) standardJsonInput(sourceUnits map[string]*solcSourceUnit, names []string) solcStandardJsonInput {
+	input := solcStandardJsonInput{
+		Language: "Solidity",
+		Sources:  make(map[string]solcStandardJsonInputSource),
 	}
+	for _, name := range names {
+		for _, closureName := range solcSourceUnitClosure(sourceUnits, name) {
+			input.Sources[closureName] = solcStandardJsonInputSource{Content: sourceUnits[closureName].content}
+		}
+	}
+	input.Settings.Remappings = s.Remappings
+	input.Settings.Optimizer.Enabled = s.OptimizerEnabled
+	input.Settings.Optimizer.Runs = s.OptimizerRuns
+	input.Settings.EvmVersion = s.EvmVersion
+	input.Settings.ViaIR = s.ViaIR
+	input.Settings.OutputSelection = solcStandardJsonOutputSelection
+	return input
+}
 
-	// Parse our contracts from solc output
-	contracts, err := compiler.ParseCombinedJSON(cmdStdout, "solc", v.String(), v.String(), "")
+// Compile uses the SolcCompilationConfig provided to compile the Solidity sources it describes, grouped by the solc
+// version each is compiled with. Outputs are cached by a hash of the solc version and standard JSON input, so sources
+// are not recompiled unless they or the compilation settings change.
+// Returns the compilations, the compiler warnings, or an error if one occurs.
+func (s *SolcCompilationConfig) Compile() ([]types.Compilation, string, error) {
+	// Read our sources, along with everything they import.
+	names, err := s.getSourceUnitNames()
+	if err != nil {
+		return nil, "", err
+	}
+	remappings := make([]solcImportRemapping, 0, len(s.Remappings))
+	for _, remapping := range s.Remappings {
+		parsedRemapping, err := parseSolcImportRemapping(remapping)
+		if err != nil {
+			return nil, "", err
+		}
+		remappings = append(remappings, parsedRemapping)
+	}
+	sourceUnits, err := readSolcSourceUnits(names, remappings)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Group our sources by the solc version they are compiled with.
+	cacheDirectory := s.getCacheDirectory()
+	versionManager := newSolcVersionManager(filepath.Join(cacheDirectory, "bin"))
+	var systemVersion *semver.Version
+	if s.UseSystemSolc {
+		// If solc is not installed on the system, we use downloaded binaries instead.
+		systemVersion, _ = GetSystemSolcVersion()
+	}
+	groups, err := s.groupSourceUnits(sourceUnits, names, versionManager, systemVersion)
 	if err != nil {
 		return nil, "", err
 	}
-	for name, contract := range contracts {
-		// Split our name which should be of form "filename:contractname"
-		nameSplit := strings.Split(name, ":")
-		sourcePath := strings.Join(nameSplit[0:len(nameSplit)-1], ":")
-		contractName := nameSplit[len(nameSplit)-1]
 
-		// Convert the abi structure to our parsed abi type
-		contractAbi, err := types.ParseABIFromInterface(contract.Info.AbiDefinition)
+	compilations := make([]types.Compilation, 0, len(groups))
+	var warnings strings.Builder
+	for _, group := range groups {
+		// Construct our input, and check whether we have already compiled it.
+		input, err := json.Marshal(s.standardJsonInput(sourceUnits, group.names))
 		if err != nil {
-			continue
+			return nil, "", err
 		}
+		hash := sha256.Sum256(append([]byte(group.version.String()+"\n"), input...))
+		outputPath := filepath.Join(cacheDirectory, "output", hex.EncodeToString(hash[:])+".json")
+		outputBytes, err := os.ReadFile(outputPath)
+		cached := err == nil
 
-		// Decode our init and runtime bytecode
-		initBytecode, err := hex.DecodeString(strings.TrimPrefix(contract.Code, "0x"))
+		// If we have not, compile it with the binary for our version.
+		if !cached {
+			solcPath := "solc"
+			if systemVersion == nil || !systemVersion.Equal(group.version) {
+				solcPath, err = versionManager.install(group.version)
+				if err != nil {
+					return nil, "", err
+				}
+			}
+			outputBytes, err = runSolcStandardJson(solcPath, input)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		// Parse our output, reporting any errors solc encountered.
+		var output solcStandardJsonOutput
+		err = json.Unmarshal(outputBytes, &output)
 		if err != nil {
-			return nil, "", fmt.Errorf("unable to parse init bytecode for contract '%s'\n", contractName)
+			return nil, "", fmt.Errorf("could not parse the output of solc version %v: %v", group.version, err)
 		}
-		runtimeBytecode, err := hex.DecodeString(strings.TrimPrefix(contract.RuntimeCode, "0x"))
+		var errorMessages strings.Builder
+		for _, outputError := range output.Errors {
+			if outputError.Severity == "error" {
+				errorMessages.WriteString(outputError.FormattedMessage + "\n")
+			} else {
+				warnings.WriteString(outputError.FormattedMessage + "\n")
+			}
+		}
+		if errorMessages.Len() > 0 {
+			return nil, "", fmt.Errorf("error while executing solc version %v:\n%s", group.version, errorMessages.String())
+		}
+		compilation, err := output.compilation("")
 		if err != nil {
-			return nil, "", fmt.Errorf("unable to parse runtime bytecode for contract '%s'\n", contractName)
+			return nil, "", err
 		}
+		compilations = append(compilations, *compilation)
 
-		// Construct our compiled contract
-		compilation.Sources[sourcePath].Contracts[contractName] = types.CompiledContract{
-			Abi:             *contractAbi,
-			InitBytecode:    initBytecode,
-			RuntimeBytecode: runtimeBytecode,
-			SrcMapsInit:     contract.Info.SrcMap.(string),
-			SrcMapsRuntime:  contract.Info.SrcMapRuntime,
+		// Cache the output of successful compilations. Failing to do so only costs us a recompilation.
+		if !cached {
+			if err = utils.MakeDirectory(filepath.Dir(outputPath)); err == nil {
+				_ = utils.WriteFileAtomic(outputPath, outputBytes, 0644)
+			}
 		}
 	}
-
-	return []types.Compilation{*compilation}, string(cmdStderr), nil
+	return compilations, warnings.String(), nil
 }
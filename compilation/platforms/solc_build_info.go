@@ -0,0 +1,237 @@
+package platforms
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/crytic/medusa/compilation/types"
+)
+
+// solcStandardJsonBytecode describes the bytecode of a contract, as output by solc's standard JSON interface.
+type solcStandardJsonBytecode struct {
+	// Object describes the hex-encoded bytecode.
+	Object string `json:"object"`
+
+	// SourceMap describes the source mappings of the bytecode.
+	SourceMap string `json:"sourceMap"`
+
+	// LinkReferences describes the locations of placeholders for the addresses of the libraries the bytecode
+	// references, keyed by the source path, then name, of each library.
+	LinkReferences map[string]map[string][]struct {
+		// Start describes the byte offset of the placeholder within the bytecode.
+		Start int `json:"start"`
+
+		// Length describes the byte length of the placeholder.
+		Length int `json:"length"`
+	} `json:"linkReferences"`
+
+	// ImmutableReferences describes the regions of deployed bytecode which the values of immutable variables are
+	// substituted into, keyed by the AST ID of each variable.
+	ImmutableReferences map[string][]struct {
+		// Start describes the byte offset of the region within the bytecode.
+		Start int `json:"start"`
+
+		// Length describes the byte length of the region.
+		Length int `json:"length"`
+	} `json:"immutableReferences"`
+}
+
+// immutableRegions obtains the regions of the bytecode which the values of immutable variables are substituted into,
+// ordered by offset.
+func (b *solcStandardJsonBytecode) immutableRegions() []types.BytecodeRegion {
+	var regions []types.BytecodeRegion
+	for _, references := range b.ImmutableReferences {
+		for _, reference := range references {
+			regions = append(regions, types.BytecodeRegion{Offset: reference.Start, Length: reference.Length})
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].Offset < regions[j].Offset
+	})
+	return regions
+}
+
+// decode decodes the bytecode, and the locations of the libraries it references. Libraries are referenced by their
+// fully qualified name, using the provided function to resolve their source path.
+// Returns the decoded bytecode and its link references, or an error if one occurs.
+func (b *solcStandardJsonBytecode) decode(resolveSourcePath func(string) string) ([]byte, types.LinkReferences, error) {
+	bytecode, linkReferences, err := types.DecodeLinkableBytecode(b.Object)
+	if err != nil || len(b.LinkReferences) == 0 {
+		return bytecode, linkReferences, err
+	}
+
+	// The link references describe which library each placeholder refers to, so we reference libraries by name
+	// rather than by placeholder.
+	linkReferences = make(types.LinkReferences)
+	for sourcePath, libraries := range b.LinkReferences {
+		for libraryName, references := range libraries {
+			fullyQualifiedName := resolveSourcePath(sourcePath) + ":" + libraryName
+			for _, reference := range references {
+				linkReferences[fullyQualifiedName] = append(linkReferences[fullyQualifiedName], reference.Start)
+			}
+		}
+	}
+	return bytecode, linkReferences, nil
+}
+
+// solcStandardJsonContract describes a compiled contract, as output by solc's standard JSON interface.
+type solcStandardJsonContract struct {
+	// Abi describes the application binary interface of the contract.
+	Abi any `json:"abi"`
+
+	// Evm describes the EVM-related outputs of the contract.
+	Evm struct {
+		// Bytecode describes the bytecode used to deploy the contract.
+		Bytecode solcStandardJsonBytecode `json:"bytecode"`
+
+		// DeployedBytecode describes the bytecode of the contract once deployed.
+		DeployedBytecode solcStandardJsonBytecode `json:"deployedBytecode"`
+	} `json:"evm"`
+}
+
+// solcStandardJsonSource describes a compiled source file, as output by solc's standard JSON interface.
+type solcStandardJsonSource struct {
+	// Ast describes the abstract syntax tree of the source file.
+	Ast any `json:"ast"`
+}
+
+// solcStandardJsonOutput describes the output of solc's standard JSON interface.
+type solcStandardJsonOutput struct {
+	// Sources describes each compiled source file, keyed by source path.
+	Sources map[string]solcStandardJsonSource `json:"sources"`
+
+	// Contracts describes each compiled contract, keyed by source path, then contract name.
+	Contracts map[string]map[string]solcStandardJsonContract `json:"contracts"`
+
+	// Errors describes the errors and warnings solc encountered while compiling.
+	Errors []solcStandardJsonError `json:"errors"`
+}
+
+// solcStandardJsonError describes an error or warning, as output by solc's standard JSON interface.
+type solcStandardJsonError struct {
+	// Severity describes the severity of the error, which is "error", "warning" or "info".
+	Severity string `json:"severity"`
+
+	// FormattedMessage describes the error message, formatted with its source location.
+	FormattedMessage string `json:"formattedMessage"`
+}
+
+// solcBuildInfo describes a build-info file written by development frameworks such as Foundry and Hardhat, which
+// records the input and output of a single solc invocation.
+type solcBuildInfo struct {
+	// Output describes the output of the solc invocation.
+	Output solcStandardJsonOutput `json:"output"`
+}
+
+// compilation converts the solc standard JSON output into a types.Compilation. If a source directory is provided,
+// source paths are made relative to the current working directory by joining them to it, so the source files can be
+// read when mapping coverage to them.
+// Returns the compilation, or an error if one occurs.
+func (o *solcStandardJsonOutput) compilation(sourceDirectory string) (*types.Compilation, error) {
+	// resolveSourcePath resolves the path of a source file as recorded by solc.
+	resolveSourcePath := func(sourcePath string) string {
+		if sourceDirectory == "" || filepath.IsAbs(sourcePath) {
+			return sourcePath
+		}
+		return filepath.Join(sourceDirectory, sourcePath)
+	}
+
+	// Loop through all sources and parse them into our types.
+	compilation := types.NewCompilation()
+	for sourcePath, source := range o.Sources {
+		compilation.Sources[resolveSourcePath(sourcePath)] = types.CompiledSource{
+			Ast:       source.Ast,
+			Contracts: make(map[string]types.CompiledContract),
+		}
+	}
+
+	// Loop through all contracts and parse them into our types.
+	for sourcePath, sourceContracts := range o.Contracts {
+		// Ensure a source exists for this, or create one if it did not exist in the "sources" key of the output.
+		sourcePath = resolveSourcePath(sourcePath)
+		if _, ok := compilation.Sources[sourcePath]; !ok {
+			compilation.Sources[sourcePath] = types.CompiledSource{
+				Ast:       nil,
+				Contracts: make(map[string]types.CompiledContract),
+			}
+		}
+
+		for contractName, contract := range sourceContracts {
+			// Parse the ABI
+			contractAbi, err := types.ParseABIFromInterface(contract.Abi)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse ABI for contract '%s'\n", contractName)
+			}
+
+			// Decode our init and runtime bytecode. Bytecode which references libraries which were not linked
+			// contains placeholders for their addresses, which are linked once the libraries are deployed.
+			initBytecode, initLinkReferences, err := contract.Evm.Bytecode.decode(resolveSourcePath)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse init bytecode for contract '%s'\n", contractName)
+			}
+			runtimeBytecode, runtimeLinkReferences, err := contract.Evm.DeployedBytecode.decode(resolveSourcePath)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse runtime bytecode for contract '%s'\n", contractName)
+			}
+
+			// Add contract details
+			compilation.Sources[sourcePath].Contracts[contractName] = types.CompiledContract{
+				Abi:                        *contractAbi,
+				InitBytecode:               initBytecode,
+				RuntimeBytecode:            runtimeBytecode,
+				SrcMapsInit:                contract.Evm.Bytecode.SourceMap,
+				SrcMapsRuntime:             contract.Evm.DeployedBytecode.SourceMap,
+				InitLinkReferences:         initLinkReferences,
+				RuntimeLinkReferences:      runtimeLinkReferences,
+				RuntimeImmutableReferences: contract.Evm.DeployedBytecode.immutableRegions(),
+			}
+		}
+	}
+	return compilation, nil
+}
+
+// readSolcBuildInfoCompilations reads every build-info file in the provided directory, creating one compilation for
+// each, so projects compiled with several solc versions produce a compilation for every version. Build-info files
+// which do not record solc's output (e.g. as it is written to a separate file) are skipped. Source paths are
+// resolved as described by solcStandardJsonOutput.compilation.
+// Returns the compilations, or an error if one occurs.
+func readSolcBuildInfoCompilations(buildInfoDirectory string, sourceDirectory string) ([]types.Compilation, error) {
+	// Find the build-info files in the directory, sorting them so compilations are always returned in the same order.
+	matches, err := filepath.Glob(filepath.Join(buildInfoDirectory, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no build-info files were found in '%s'", buildInfoDirectory)
+	}
+	sort.Strings(matches)
+
+	// Parse a compilation from each build-info file.
+	compilations := make([]types.Compilation, 0, len(matches))
+	for _, match := range matches {
+		b, err := os.ReadFile(match)
+		if err != nil {
+			return nil, fmt.Errorf("could not read build-info file at path '%s', error: %v", match, err)
+		}
+		var buildInfo solcBuildInfo
+		err = json.Unmarshal(b, &buildInfo)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse build-info file at path '%s', error: %v", match, err)
+		}
+		if len(buildInfo.Output.Sources) == 0 && len(buildInfo.Output.Contracts) == 0 {
+			continue
+		}
+		compilation, err := buildInfo.Output.compilation(sourceDirectory)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse build-info file at path '%s', error: %v", match, err)
+		}
+		compilations = append(compilations, *compilation)
+	}
+	if len(compilations) == 0 {
+		return nil, fmt.Errorf("no build-info files in '%s' recorded the output of a compilation", buildInfoDirectory)
+	}
+	return compilations, nil
+}
@@ -0,0 +1,239 @@
+package platforms
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/crytic/medusa/utils"
+)
+
+// solcStandardJsonInput describes the input provided to solc's standard JSON interface.
+type solcStandardJsonInput struct {
+	// Language describes the language of the sources.
+	Language string `json:"language"`
+
+	// Sources describes the content of each source, keyed by source unit name.
+	Sources map[string]solcStandardJsonInputSource `json:"sources"`
+
+	// Settings describes the settings to compile the sources with.
+	Settings solcStandardJsonInputSettings `json:"settings"`
+}
+
+// solcStandardJsonInputSource describes a source provided to solc's standard JSON interface.
+type solcStandardJsonInputSource struct {
+	// Content describes the content of the source.
+	Content string `json:"content"`
+}
+
+// solcStandardJsonInputSettings describes the settings provided to solc's standard JSON interface.
+type solcStandardJsonInputSettings struct {
+	// Remappings describes the import remappings to apply.
+	Remappings []string `json:"remappings"`
+
+	// Optimizer describes the optimizer settings.
+	Optimizer struct {
+		// Enabled describes whether the optimizer is enabled.
+		Enabled bool `json:"enabled"`
+
+		// Runs describes how many times the deployed code is expected to run, which the optimizer tunes for.
+		Runs int `json:"runs"`
+	} `json:"optimizer"`
+
+	// EvmVersion describes the EVM version to target, or the compiler's default if empty.
+	EvmVersion string `json:"evmVersion,omitempty"`
+
+	// ViaIR describes whether code is generated through the IR pipeline.
+	ViaIR bool `json:"viaIR,omitempty"`
+
+	// OutputSelection describes the outputs to produce for each source and contract.
+	OutputSelection map[string]map[string][]string `json:"outputSelection"`
+}
+
+// solcStandardJsonOutputSelection describes the outputs medusa requests from solc's standard JSON interface.
+var solcStandardJsonOutputSelection = map[string]map[string][]string{
+	"*": {
+		"*": {
+			"abi",
+			"evm.bytecode.object", "evm.bytecode.sourceMap", "evm.bytecode.linkReferences",
+			"evm.deployedBytecode.object", "evm.deployedBytecode.sourceMap", "evm.deployedBytecode.linkReferences",
+			"evm.deployedBytecode.immutableReferences",
+		},
+		"": {"ast"},
+	},
+}
+
+// solcImportRemapping describes an import remapping, of the form `[context:]prefix=target`, which replaces the prefix
+// of direct imports within sources whose name starts with the context.
+type solcImportRemapping struct {
+	// context describes the prefix of the names of the sources the remapping applies to.
+	context string
+
+	// prefix describes the prefix of the import paths which are remapped.
+	prefix string
+
+	// target describes what the prefix is replaced with.
+	target string
+}
+
+// parseSolcImportRemapping parses an import remapping of the form `[context:]prefix=target`.
+// Returns the remapping, or an error if it is malformed.
+func parseSolcImportRemapping(remapping string) (solcImportRemapping, error) {
+	contextAndPrefix, target, found := strings.Cut(remapping, "=")
+	if !found || contextAndPrefix == "" {
+		return solcImportRemapping{}, fmt.Errorf("import remapping '%s' must be of the form [context:]prefix=target", remapping)
+	}
+	context, prefix, found := strings.Cut(contextAndPrefix, ":")
+	if !found {
+		context, prefix = "", contextAndPrefix
+	}
+	return solcImportRemapping{context: context, prefix: prefix, target: target}, nil
+}
+
+// solcSourceUnit describes a Solidity source, as it is provided to solc.
+type solcSourceUnit struct {
+	// content describes the content of the source.
+	content string
+
+	// versionConstraints describes the constraints of the version pragmas of the source.
+	versionConstraints []solcVersionConstraint
+
+	// imports describes the source unit names of the sources the source imports.
+	imports []string
+}
+
+// solcCommentRegex matches comments in Solidity source code.
+var solcCommentRegex = regexp.MustCompile(`(?s)/\*.*?\*/|//[^\n]*`)
+
+// solcPragmaRegex matches a version pragma in Solidity source code, capturing its version expression.
+var solcPragmaRegex = regexp.MustCompile(`\bpragma\s+solidity\s+([^;]+);`)
+
+// solcImportRegex matches an import directive in Solidity source code, capturing the path it imports.
+var solcImportRegex = regexp.MustCompile(`\bimport\s+(?:[^;"']*?\bfrom\s+)?["']([^"']+)["']`)
+
+// solcSourceUnitName obtains the source unit name of the source file at the provided path, which is its path
+// relative to the current working directory (or its absolute path, if it does not reside within it), using forward
+// slashes, as solc names sources relative to its base path.
+// Returns the source unit name, or an error if one occurs.
+func solcSourceUnitName(sourcePath string) (string, error) {
+	absolutePath, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return "", err
+	}
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	relativePath, err := filepath.Rel(workingDirectory, absolutePath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(absolutePath), nil
+	}
+	return filepath.ToSlash(relativePath), nil
+}
+
+// resolveSolcImport resolves the source unit name of the provided import path, imported by the source with the
+// provided source unit name, as solc does. Relative imports are resolved against the importing source, while import
+// remappings are applied to direct imports, with the longest matching context and prefix taking precedence.
+// Returns the source unit name of the imported source.
+func resolveSolcImport(importer string, importPath string, remappings []solcImportRemapping) string {
+	if strings.HasPrefix(importPath, "./") || strings.HasPrefix(importPath, "../") {
+		return path.Clean(path.Join(path.Dir(importer), importPath))
+	}
+
+	var selected *solcImportRemapping
+	for i, remapping := range remappings {
+		if !strings.HasPrefix(importer, remapping.context) || !strings.HasPrefix(importPath, remapping.prefix) {
+			continue
+		}
+		if selected == nil || len(remapping.context) > len(selected.context) ||
+			(len(remapping.context) == len(selected.context) && len(remapping.prefix) >= len(selected.prefix)) {
+			selected = &remappings[i]
+		}
+	}
+	if selected != nil {
+		return selected.target + strings.TrimPrefix(importPath, selected.prefix)
+	}
+	return importPath
+}
+
+// readSolcSourceUnits reads the sources with the provided source unit names, along with every source they import,
+// directly or indirectly, resolving imports with the provided import remappings. Sources are read from the path their
+// source unit name describes.
+// Returns the sources keyed by source unit name, or an error if one could not be read.
+func readSolcSourceUnits(names []string, remappings []solcImportRemapping) (map[string]*solcSourceUnit, error) {
+	sourceUnits := make(map[string]*solcSourceUnit)
+	pending := append([]string{}, names...)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if _, ok := sourceUnits[name]; ok {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.FromSlash(name))
+		if err != nil {
+			return nil, fmt.Errorf("could not read Solidity source '%s': %v", name, err)
+		}
+		sourceUnit := &solcSourceUnit{content: string(b)}
+		code := solcCommentRegex.ReplaceAllString(sourceUnit.content, "")
+		for _, match := range solcPragmaRegex.FindAllStringSubmatch(code, -1) {
+			constraint, err := parseSolcVersionConstraint(match[1])
+			if err != nil {
+				return nil, fmt.Errorf("could not parse the version pragma of Solidity source '%s': %v", name, err)
+			}
+			sourceUnit.versionConstraints = append(sourceUnit.versionConstraints, constraint)
+		}
+		for _, match := range solcImportRegex.FindAllStringSubmatch(code, -1) {
+			importName := resolveSolcImport(name, match[1], remappings)
+			sourceUnit.imports = append(sourceUnit.imports, importName)
+			pending = append(pending, importName)
+		}
+		sourceUnits[name] = sourceUnit
+	}
+	return sourceUnits, nil
+}
+
+// solcSourceUnitClosure obtains the source unit names of the source with the provided name and every source it
+// imports, directly or indirectly, from the provided sources.
+// Returns the source unit names, in sorted order.
+func solcSourceUnitClosure(sourceUnits map[string]*solcSourceUnit, name string) []string {
+	visited := map[string]bool{name: true}
+	pending := []string{name}
+	for len(pending) > 0 {
+		sourceUnit := sourceUnits[pending[0]]
+		pending = pending[1:]
+		if sourceUnit == nil {
+			continue
+		}
+		for _, importName := range sourceUnit.imports {
+			if !visited[importName] {
+				visited[importName] = true
+				pending = append(pending, importName)
+			}
+		}
+	}
+	closure := make([]string, 0, len(visited))
+	for visitedName := range visited {
+		closure = append(closure, visitedName)
+	}
+	sort.Strings(closure)
+	return closure
+}
+
+// runSolcStandardJson runs the solc binary at the provided path with the provided standard JSON input.
+// Returns the standard JSON output, or an error if solc could not be run.
+func runSolcStandardJson(solcPath string, input []byte) ([]byte, error) {
+	cmd := exec.Command(solcPath, "--standard-json")
+	cmd.Stdin = bytes.NewReader(input)
+	cmdStdout, _, cmdCombined, err := utils.RunCommandWithOutputAndError(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing solc:\n%s\n\nCommand Output:\n%s\n", err.Error(), string(cmdCombined))
+	}
+	return cmdStdout, nil
+}
@@ -1,10 +1,14 @@
 package platforms
 
 import (
-	"github.com/crytic/medusa/utils/testutils"
-	"github.com/stretchr/testify/assert"
+	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/Masterminds/semver"
+	"github.com/crytic/medusa/utils"
+	"github.com/crytic/medusa/utils/testutils"
+	"github.com/stretchr/testify/assert"
 )
 
 // TestSolcVersion ensures that a version of solc could be obtained and is installed
@@ -68,3 +72,144 @@ func TestFailedSolcCompilation(t *testing.T) {
 		assert.True(t, len(compilations) == 0)
 	})
 }
+
+// TestSolcVersionConstraint tests that version pragmas are parsed with the semantics solc applies to them.
+func TestSolcVersionConstraint(t *testing.T) {
+	cases := []struct {
+		expression string
+		allowed    []string
+		disallowed []string
+	}{
+		{"^0.8.0", []string{"0.8.0", "0.8.19"}, []string{"0.7.6", "0.9.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"~0.6.2", []string{"0.6.2", "0.6.12"}, []string{"0.6.1", "0.7.0"}},
+		{"0.7.6", []string{"0.7.6"}, []string{"0.7.5", "0.8.0"}},
+		{">=0.6.0 <0.8.0", []string{"0.6.0", "0.7.6"}, []string{"0.5.17", "0.8.0"}},
+		{">= 0.5.0 < 0.6.0 || ^0.8.1", []string{"0.5.3", "0.8.1"}, []string{"0.6.0", "0.8.0"}},
+		{"0.8", []string{"0.8.0"}, []string{"0.8.1"}},
+	}
+	for _, c := range cases {
+		constraint, err := parseSolcVersionConstraint(c.expression)
+		assert.NoError(t, err)
+		for _, version := range c.allowed {
+			assert.True(t, constraint.check(semver.MustParse(version)), "%v should satisfy '%v'", version, c.expression)
+		}
+		for _, version := range c.disallowed {
+			assert.False(t, constraint.check(semver.MustParse(version)), "%v should not satisfy '%v'", version, c.expression)
+		}
+	}
+
+	// Malformed expressions should not be parsed.
+	for _, expression := range []string{"", "^", "0.8.x", ">=0.8.0 ||"} {
+		_, err := parseSolcVersionConstraint(expression)
+		assert.Error(t, err, "'%v' should not be parsed", expression)
+	}
+}
+
+// TestSolcSourceUnitGrouping tests that sources requiring different solc versions are grouped by the version they are
+// compiled with, resolving the remapped imports they share.
+func TestSolcSourceUnitGrouping(t *testing.T) {
+	// Copy our testdata over to our testing directory
+	projectDirectory := testutils.CopyToTestDirectory(t, "testdata/solc/multi_version_project/")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, projectDirectory, func() {
+		solc := NewSolcCompilationConfig("contracts")
+		solc.Remappings = []string{"shared/=lib/shared/"}
+
+		// Both contracts should import the library through the remapping.
+		names, err := solc.getSourceUnitNames()
+		assert.NoError(t, err)
+		assert.EqualValues(t, []string{"contracts/LegacyContract.sol", "contracts/ModernContract.sol"}, names)
+		remapping, err := parseSolcImportRemapping(solc.Remappings[0])
+		assert.NoError(t, err)
+		sourceUnits, err := readSolcSourceUnits(names, []solcImportRemapping{remapping})
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, len(sourceUnits))
+		assert.EqualValues(t, []string{"lib/shared/SharedMath.sol"}, sourceUnits["contracts/LegacyContract.sol"].imports)
+		assert.EqualValues(t, []string{"lib/shared/SharedMath.sol"}, sourceUnits["contracts/ModernContract.sol"].imports)
+
+		// Simulate cached solc binaries, the newest of which should be preferred where suitable.
+		versionManager := newSolcVersionManager(t.TempDir())
+		for _, version := range []string{"0.7.6", "0.8.10", "0.8.4"} {
+			assert.NoError(t, os.WriteFile(versionManager.binaryPath(semver.MustParse(version)), []byte{}, 0755))
+		}
+		groups, err := solc.groupSourceUnits(sourceUnits, names, versionManager, nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, len(groups))
+		assert.EqualValues(t, "0.7.6", groups[0].version.String())
+		assert.EqualValues(t, []string{"contracts/LegacyContract.sol"}, groups[0].names)
+		assert.EqualValues(t, "0.8.10", groups[1].version.String())
+		assert.EqualValues(t, []string{"contracts/ModernContract.sol"}, groups[1].names)
+
+		// The system solc should be preferred where suitable.
+		groups, err = solc.groupSourceUnits(sourceUnits, names, versionManager, semver.MustParse("0.8.4"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, "0.8.4", groups[1].version.String())
+
+		// A configured compiler version which does not satisfy every source should fail.
+		solc.CompilerVersion = "0.8.10"
+		_, err = solc.groupSourceUnits(sourceUnits, names, versionManager, nil)
+		assert.Error(t, err)
+	})
+}
+
+// TestSolcStandardJsonCompilationCache tests that sources are compiled with a cached solc binary of the version they
+// require, and that the output is cached so unchanged sources are not recompiled.
+func TestSolcStandardJsonCompilationCache(t *testing.T) {
+	// Our fake solc binary is a shell script, so this test cannot run on Windows.
+	if utils.IsWindowsEnvironment() {
+		t.Skip("the fake solc binary used by this test requires a POSIX shell")
+	}
+
+	// Copy our testdata over to our testing directory
+	projectDirectory := testutils.CopyToTestDirectory(t, "testdata/solc/multi_version_project/")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, projectDirectory, func() {
+		solc := NewSolcCompilationConfig("contracts/ModernContract.sol")
+		solc.Remappings = []string{"shared/=lib/shared/"}
+		solc.CompilerVersion = "0.8.10"
+		solc.UseSystemSolc = false
+		solc.CacheDirectory = t.TempDir()
+
+		// Create a fake solc binary which records its input and returns a canned output.
+		output := `{
+			"sources": {"contracts/ModernContract.sol": {"id": 0, "ast": {"nodeType": "SourceUnit", "src": "0:0:0"}}},
+			"contracts": {"contracts/ModernContract.sol": {"ModernContract": {
+				"abi": [{"type": "function", "name": "double", "stateMutability": "pure",
+					"inputs": [{"name": "x", "type": "uint256"}], "outputs": [{"name": "", "type": "uint256"}]}],
+				"evm": {"bytecode": {"object": "6080604052", "sourceMap": "1:2:0"},
+					"deployedBytecode": {"object": "60806040", "sourceMap": "1:2:0"}}
+			}}},
+			"errors": [{"severity": "warning", "formattedMessage": "Warning: canned warning"}]
+		}`
+		inputPath := filepath.Join(t.TempDir(), "input.json")
+		binaryPath := newSolcVersionManager(filepath.Join(solc.CacheDirectory, "bin")).binaryPath(semver.MustParse("0.8.10"))
+		script := "#!/bin/sh\ncat > '" + inputPath + "'\ncat <<'EOF'\n" + output + "\nEOF\n"
+		assert.NoError(t, utils.MakeDirectory(filepath.Dir(binaryPath)))
+		assert.NoError(t, os.WriteFile(binaryPath, []byte(script), 0755))
+
+		// Compile our contract, which should be provided to solc alongside its remapped import.
+		compilations, warnings, err := solc.Compile()
+		assert.NoError(t, err)
+		assert.Contains(t, warnings, "canned warning")
+		assert.EqualValues(t, 1, len(compilations))
+		contract, ok := compilations[0].Sources["contracts/ModernContract.sol"].Contracts["ModernContract"]
+		assert.True(t, ok)
+		assert.EqualValues(t, []byte{0x60, 0x80, 0x60, 0x40, 0x52}, contract.InitBytecode)
+		input, err := os.ReadFile(inputPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(input), `"lib/shared/SharedMath.sol"`)
+		assert.Contains(t, string(input), `"shared/=lib/shared/"`)
+
+		// Without our binary, the cached output should still be used by our compiler version, as nothing changed.
+		assert.NoError(t, os.Remove(binaryPath))
+		compilations, _, err = solc.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(compilations))
+		entries, err := os.ReadDir(filepath.Join(solc.CacheDirectory, "output"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(entries))
+	})
+}
@@ -0,0 +1,340 @@
+package platforms
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/crytic/medusa/utils"
+)
+
+// solcBinariesURL describes the URL of the repository which official solc binaries are downloaded from.
+const solcBinariesURL = "https://binaries.soliditylang.org"
+
+// solcDownloadTimeout describes the maximum time a request to the solc binaries repository may take.
+const solcDownloadTimeout = 5 * time.Minute
+
+// solcVersionComparator describes a single comparison a solc version must satisfy, as part of a version pragma.
+type solcVersionComparator struct {
+	// operator describes the comparison operator, which is one of "=", "<", "<=", ">" or ">=".
+	operator string
+
+	// version describes the version compared against.
+	version *semver.Version
+}
+
+// check indicates whether the provided version satisfies the comparison.
+func (c solcVersionComparator) check(version *semver.Version) bool {
+	comparison := version.Compare(c.version)
+	switch c.operator {
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	case ">":
+		return comparison > 0
+	case ">=":
+		return comparison >= 0
+	default:
+		return comparison == 0
+	}
+}
+
+// solcVersionConstraint describes the solc versions allowed by a version pragma (e.g. `pragma solidity ^0.8.0;`), as
+// a list of alternatives, each of which is a list of comparisons a version must all satisfy.
+type solcVersionConstraint [][]solcVersionComparator
+
+// solcVersionOperatorRegex matches an operator within a version pragma, along with any whitespace following it.
+var solcVersionOperatorRegex = regexp.MustCompile(`(\^|~|>=|<=|>|<|=)\s+`)
+
+// solcVersionComparatorRegex matches a single comparison within a version pragma, capturing its operator and version.
+var solcVersionComparatorRegex = regexp.MustCompile(`^(\^|~|>=|<=|>|<|=)?\s*v?(\d+(?:\.\d+){0,2})$`)
+
+// parseSolcVersionConstraint parses the version expression of a version pragma, using the semantics solc applies to
+// it, which differ from those of other semantic versioning libraries for versions below 1.0.0 (e.g. `^0.8.0` does not
+// allow 0.9.0).
+// Returns the parsed constraint, or an error if the expression is malformed.
+func parseSolcVersionConstraint(expression string) (solcVersionConstraint, error) {
+	var constraint solcVersionConstraint
+	for _, alternative := range strings.Split(expression, "||") {
+		// Operators may be separated from their versions by whitespace, so we join them before splitting comparisons.
+		alternative = solcVersionOperatorRegex.ReplaceAllString(strings.TrimSpace(alternative), "$1")
+		comparators := make([]solcVersionComparator, 0)
+		for _, field := range strings.Fields(alternative) {
+			match := solcVersionComparatorRegex.FindStringSubmatch(field)
+			if match == nil {
+				return nil, fmt.Errorf("could not parse solc version constraint '%s'", expression)
+			}
+			version, err := semver.NewVersion(match[2])
+			if err != nil {
+				return nil, fmt.Errorf("could not parse solc version constraint '%s': %v", expression, err)
+			}
+
+			switch match[1] {
+			case "^":
+				// Caret ranges allow changes which do not modify the left-most non-zero version component.
+				var upperBound semver.Version
+				if version.Major() > 0 {
+					upperBound = version.IncMajor()
+				} else if version.Minor() > 0 {
+					upperBound = version.IncMinor()
+				} else {
+					upperBound = version.IncPatch()
+				}
+				comparators = append(comparators, solcVersionComparator{">=", version}, solcVersionComparator{"<", &upperBound})
+			case "~":
+				// Tilde ranges allow patch-level changes.
+				upperBound := version.IncMinor()
+				comparators = append(comparators, solcVersionComparator{">=", version}, solcVersionComparator{"<", &upperBound})
+			case "":
+				comparators = append(comparators, solcVersionComparator{"=", version})
+			default:
+				comparators = append(comparators, solcVersionComparator{match[1], version})
+			}
+		}
+		if len(comparators) == 0 {
+			return nil, fmt.Errorf("could not parse solc version constraint '%s'", expression)
+		}
+		constraint = append(constraint, comparators)
+	}
+	return constraint, nil
+}
+
+// check indicates whether the provided version satisfies the constraint.
+func (c solcVersionConstraint) check(version *semver.Version) bool {
+	for _, comparators := range c {
+		satisfied := true
+		for _, comparator := range comparators {
+			if !comparator.check(version) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+// getSolcVersion obtains the version of the solc binary at the provided path.
+// Returns the version, or an error if it could not be obtained.
+func getSolcVersion(solcPath string) (*semver.Version, error) {
+	// Run solc --version to obtain our compiler version.
+	out, err := exec.Command(solcPath, "--version").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error while executing solc:\nOUTPUT:\n%s\nERROR: %s\n", string(out), err.Error())
+	}
+
+	// Parse the compiler version out of the output
+	exp := regexp.MustCompile(`\d+\.\d+\.\d+`)
+	versionStr := exp.FindString(string(out))
+	if versionStr == "" {
+		return nil, errors.New("could not parse solc version using 'solc --version'")
+	}
+
+	// Parse our semver string and return it
+	return semver.NewVersion(versionStr)
+}
+
+// solcBinaryList describes the list of solc binaries the solc binaries repository provides for a platform.
+type solcBinaryList struct {
+	// Builds describes each binary available for the platform.
+	Builds []struct {
+		// Path describes the path of the binary, relative to the platform's directory in the repository.
+		Path string `json:"path"`
+
+		// Version describes the version of the binary.
+		Version string `json:"version"`
+
+		// Sha256 describes the hex-encoded SHA-256 hash of the binary, with a "0x" prefix.
+		Sha256 string `json:"sha256"`
+	} `json:"builds"`
+}
+
+// solcVersionManager downloads and caches official solc binaries, so sources can be compiled with the versions they
+// require, without installing them manually.
+type solcVersionManager struct {
+	// directory describes the directory solc binaries are cached in.
+	directory string
+
+	// binaryList describes the binaries available from the solc binaries repository, or nil if they have not been
+	// fetched yet.
+	binaryList *solcBinaryList
+}
+
+// newSolcVersionManager creates a solcVersionManager which caches solc binaries in the provided directory.
+func newSolcVersionManager(directory string) *solcVersionManager {
+	return &solcVersionManager{
+		directory: directory,
+	}
+}
+
+// solcBinaryPlatform obtains the name of the current platform, as used by the solc binaries repository.
+// Returns the name of the platform, or an error if the repository does not provide binaries for it.
+func solcBinaryPlatform() (string, error) {
+	switch {
+	case utils.IsWindowsEnvironment():
+		return "windows-amd64", nil
+	case utils.IsMacOSEnvironment():
+		// macOS binaries run on both architectures.
+		return "macosx-amd64", nil
+	case runtime.GOOS == "linux" && runtime.GOARCH == "amd64":
+		return "linux-amd64", nil
+	}
+	return "", fmt.Errorf("solc binaries are not provided for %s-%s, install solc and use it instead", runtime.GOOS, runtime.GOARCH)
+}
+
+// binaryPath obtains the path a solc binary of the provided version is cached at.
+func (m *solcVersionManager) binaryPath(version *semver.Version) string {
+	binaryPath := filepath.Join(m.directory, "solc-"+version.String())
+	if utils.IsWindowsEnvironment() {
+		binaryPath += ".exe"
+	}
+	return binaryPath
+}
+
+// installedVersions obtains the versions of the solc binaries which are cached, from newest to oldest.
+// Returns the cached versions, or an error if they could not be read.
+func (m *solcVersionManager) installedVersions() ([]*semver.Version, error) {
+	entries, err := os.ReadDir(m.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	versions := make([]*semver.Version, 0)
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".exe")
+		if entry.IsDir() || !strings.HasPrefix(name, "solc-") {
+			continue
+		}
+		if version, err := semver.NewVersion(strings.TrimPrefix(name, "solc-")); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+	return versions, nil
+}
+
+// fetchBinaryList fetches the list of binaries the solc binaries repository provides for the current platform, if it
+// was not already fetched.
+// Returns the binary list, or an error if one occurs.
+func (m *solcVersionManager) fetchBinaryList() (*solcBinaryList, error) {
+	if m.binaryList != nil {
+		return m.binaryList, nil
+	}
+	platform, err := solcBinaryPlatform()
+	if err != nil {
+		return nil, err
+	}
+	b, err := downloadSolcBinariesFile(platform + "/list.json")
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch the list of solc releases: %v", err)
+	}
+	var binaryList solcBinaryList
+	err = json.Unmarshal(b, &binaryList)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the list of solc releases: %v", err)
+	}
+	m.binaryList = &binaryList
+	return m.binaryList, nil
+}
+
+// availableVersions obtains the versions of solc which can be downloaded, from newest to oldest. Prereleases are not
+// included.
+// Returns the available versions, or an error if they could not be fetched.
+func (m *solcVersionManager) availableVersions() ([]*semver.Version, error) {
+	binaryList, err := m.fetchBinaryList()
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]*semver.Version, 0, len(binaryList.Builds))
+	for _, build := range binaryList.Builds {
+		if version, err := semver.NewVersion(build.Version); err == nil && version.Prerelease() == "" {
+			versions = append(versions, version)
+		}
+	}
+	sort.Sort(sort.Reverse(semver.Collection(versions)))
+	return versions, nil
+}
+
+// install obtains the path of a solc binary of the provided version, downloading it to the cache if it is not already
+// cached. Downloaded binaries are verified against the hash published alongside them.
+// Returns the path of the binary, or an error if one occurs.
+func (m *solcVersionManager) install(version *semver.Version) (string, error) {
+	binaryPath := m.binaryPath(version)
+	if _, err := os.Stat(binaryPath); err == nil {
+		return binaryPath, nil
+	}
+
+	// Find the binary for our version.
+	binaryList, err := m.fetchBinaryList()
+	if err != nil {
+		return "", err
+	}
+	index := -1
+	for i, build := range binaryList.Builds {
+		if buildVersion, err := semver.NewVersion(build.Version); err == nil && buildVersion.Equal(version) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return "", fmt.Errorf("solc version %v is not available for download", version)
+	}
+	build := binaryList.Builds[index]
+
+	// Download and verify the binary.
+	platform, err := solcBinaryPlatform()
+	if err != nil {
+		return "", err
+	}
+	b, err := downloadSolcBinariesFile(platform + "/" + build.Path)
+	if err != nil {
+		return "", fmt.Errorf("could not download solc version %v: %v", version, err)
+	}
+	hash := sha256.Sum256(b)
+	if !strings.EqualFold(hex.EncodeToString(hash[:]), strings.TrimPrefix(build.Sha256, "0x")) {
+		return "", fmt.Errorf("the downloaded binary for solc version %v did not match its published hash", version)
+	}
+
+	// Write the binary atomically, so an interrupted write is never mistaken for a cached binary.
+	err = utils.MakeDirectory(m.directory)
+	if err == nil {
+		err = utils.WriteFileAtomic(binaryPath, b, 0755)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not cache solc version %v: %v", version, err)
+	}
+	return binaryPath, nil
+}
+
+// downloadSolcBinariesFile downloads the file at the provided path within the solc binaries repository.
+// Returns the contents of the file, or an error if one occurs.
+func downloadSolcBinariesFile(path string) ([]byte, error) {
+	client := http.Client{Timeout: solcDownloadTimeout}
+	response, err := client.Get(solcBinariesURL + "/" + path)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("request for '%s' returned status %s", path, response.Status)
+	}
+	return io.ReadAll(response.Body)
+}
@@ -0,0 +1,9 @@
+pragma solidity ^0.8.10;
+
+contract FirstContract {
+    uint x;
+
+    function setX(uint value) public {
+        x = value + 3;
+    }
+}
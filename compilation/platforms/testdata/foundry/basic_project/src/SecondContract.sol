@@ -0,0 +1,9 @@
+pragma solidity ^0.7.1;
+
+contract SecondContract {
+    uint a;
+
+    function setA(uint value) public {
+        a = value + 3;
+    }
+}
@@ -0,0 +1,9 @@
+pragma solidity ^0.7.1;
+
+contract Vault {
+    uint b;
+
+    function setB(uint value) public {
+        b = value + 3;
+    }
+}
@@ -0,0 +1,9 @@
+pragma solidity ^0.8.10;
+
+contract Token {
+    uint a;
+
+    function setA(uint value) public {
+        a = value + 3;
+    }
+}
@@ -0,0 +1,10 @@
+// This contract requires solc 0.7.6, and imports the same library as ModernContract.
+pragma solidity =0.7.6;
+
+import "shared/SharedMath.sol";
+
+contract LegacyContract {
+    function triple(uint256 x) public pure returns (uint256) {
+        return SharedMath.add(SharedMath.add(x, x), x);
+    }
+}
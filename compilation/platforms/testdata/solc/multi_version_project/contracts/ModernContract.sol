@@ -0,0 +1,10 @@
+// This contract requires solc 0.8, and imports a library through a remapping.
+pragma solidity ^0.8.0;
+
+import { SharedMath } from "shared/SharedMath.sol";
+
+contract ModernContract {
+    function double(uint256 x) public pure returns (uint256) {
+        return SharedMath.add(x, x);
+    }
+}
@@ -0,0 +1,8 @@
+// This library can be compiled by both solc 0.7 and 0.8.
+pragma solidity >=0.7.0 <0.9.0;
+
+library SharedMath {
+    function add(uint256 a, uint256 b) internal pure returns (uint256) {
+        return a + b;
+    }
+}
@@ -0,0 +1,21 @@
+# @version ^0.3.10
+
+MAX_NAME_LENGTH: constant(uint256) = 32
+
+name: public(String[MAX_NAME_LENGTH])
+data: public(Bytes[64])
+values: public(DynArray[uint256, 8])
+x: public(uint256)
+
+@external
+def setName(newName: String[MAX_NAME_LENGTH]):
+    self.name = newName
+
+@external
+def setData(newData: Bytes[64], newValues: DynArray[uint256, 8] = []):
+    self.data = newData
+    self.values = newValues
+
+@external
+def setX(newX: uint256):
+    self.x = newX
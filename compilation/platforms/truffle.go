@@ -1,13 +1,11 @@
 package platforms
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 
 	"github.com/crytic/medusa/compilation/types"
 )
@@ -117,23 +115,25 @@ func (s *TruffleCompilationConfig) Compile() ([]types.Compilation, string, error
 			}
 		}
 
-		// Decode our init and runtime bytecode
-		initBytecode, err := hex.DecodeString(strings.TrimPrefix(compiledJson.Bytecode, "0x"))
+		// Decode our init and runtime bytecode, which may contain placeholders for the addresses of libraries
+		initBytecode, initLinkReferences, err := types.DecodeLinkableBytecode(compiledJson.Bytecode)
 		if err != nil {
 			return nil, "", fmt.Errorf("unable to parse init bytecode for contract '%s'\n", compiledJson.ContractName)
 		}
-		runtimeBytecode, err := hex.DecodeString(strings.TrimPrefix(compiledJson.DeployedBytecode, "0x"))
+		runtimeBytecode, runtimeLinkReferences, err := types.DecodeLinkableBytecode(compiledJson.DeployedBytecode)
 		if err != nil {
 			return nil, "", fmt.Errorf("unable to parse runtime bytecode for contract '%s'\n", compiledJson.ContractName)
 		}
 
 		// Add our contract to the source
 		compilation.Sources[compiledJson.SourcePath].Contracts[compiledJson.ContractName] = types.CompiledContract{
-			Abi:             *contractAbi,
-			InitBytecode:    initBytecode,
-			RuntimeBytecode: runtimeBytecode,
-			SrcMapsInit:     compiledJson.SourceMap,
-			SrcMapsRuntime:  compiledJson.DeployedSourceMap,
+			Abi:                   *contractAbi,
+			InitBytecode:          initBytecode,
+			RuntimeBytecode:       runtimeBytecode,
+			SrcMapsInit:           compiledJson.SourceMap,
+			SrcMapsRuntime:        compiledJson.DeployedSourceMap,
+			InitLinkReferences:    initLinkReferences,
+			RuntimeLinkReferences: runtimeLinkReferences,
 		}
 	}
 
@@ -0,0 +1,377 @@
+package platforms
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// VyperCompilationConfig represents the various configuration options that can be provided by the user
+// while using the `vyper` platform
+type VyperCompilationConfig struct {
+	// Target is a Vyper source file, or a directory whose Vyper source files are compiled
+	Target string `json:"target"`
+
+	// Command is the command used to invoke the Vyper compiler. If empty, `vyper` is used.
+	Command string `json:"command"`
+
+	// Args are additional arguments that can be provided to the Vyper compiler
+	Args []string `json:"args"`
+}
+
+// Platform returns the platform type
+func (v *VyperCompilationConfig) Platform() string {
+	return "vyper"
+}
+
+// GetTarget returns the target for compilation
+func (v *VyperCompilationConfig) GetTarget() string {
+	return v.Target
+}
+
+// SetTarget sets the new target for compilation
+func (v *VyperCompilationConfig) SetTarget(newTarget string) {
+	v.Target = newTarget
+}
+
+// NewVyperCompilationConfig returns the default configuration options while using `vyper`
+func NewVyperCompilationConfig(target string) *VyperCompilationConfig {
+	return &VyperCompilationConfig{
+		Target:  target,
+		Command: "",
+		Args:    []string{},
+	}
+}
+
+// getSourceFiles returns the paths of the Vyper source files to compile, which is the Target itself if it is a file,
+// or every Vyper source file within it (outside of hidden directories) if it is a directory. Paths are sorted, so
+// source unit IDs are assigned deterministically.
+func (v *VyperCompilationConfig) getSourceFiles() ([]string, error) {
+	info, err := os.Stat(v.Target)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{v.Target}, nil
+	}
+
+	var sourceFiles []string
+	err = filepath.Walk(v.Target, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != v.Target && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".vy" {
+			sourceFiles = append(sourceFiles, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(sourceFiles) == 0 {
+		return nil, fmt.Errorf("no Vyper source files were found in '%s'", v.Target)
+	}
+	sort.Strings(sourceFiles)
+	return sourceFiles, nil
+}
+
+// Compile uses the VyperCompilationConfig provided to compile a given target with `vyper -f combined_json`, and
+// then create a list of types.Compilation.
+func (v *VyperCompilationConfig) Compile() ([]types.Compilation, string, error) {
+	sourceFiles, err := v.getSourceFiles()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Determine the base command to use.
+	var baseCommandStr = "vyper"
+	if v.Command != "" {
+		baseCommandStr = v.Command
+	}
+
+	// Compile every source file in a single invocation, so all contracts share one compilation.
+	args := append([]string{"-f", "combined_json"}, v.Args...)
+	cmd := exec.Command(baseCommandStr, append(args, sourceFiles...)...)
+	cmdStdout, cmdStderr, cmdCombined, err := utils.RunCommandWithOutputAndError(cmd)
+	if err != nil {
+		return nil, "", fmt.Errorf("error while executing vyper:\n%s\n\nCommand Output:\n%s\n", err.Error(), string(cmdCombined))
+	}
+
+	compilation, err := parseVyperCombinedJson(cmdStdout)
+	if err != nil {
+		return nil, "", err
+	}
+	return []types.Compilation{*compilation}, string(cmdStderr), nil
+}
+
+// vyperCombinedJsonContract describes a compiled contract, as output by `vyper -f combined_json`.
+type vyperCombinedJsonContract struct {
+	// Abi describes the application binary interface of the contract.
+	Abi any `json:"abi"`
+
+	// Bytecode describes the hex-encoded bytecode used to deploy the contract.
+	Bytecode string `json:"bytecode"`
+
+	// BytecodeRuntime describes the hex-encoded bytecode of the contract once deployed.
+	BytecodeRuntime string `json:"bytecode_runtime"`
+
+	// SourceMap describes the source mappings of the runtime bytecode.
+	SourceMap struct {
+		// PcPosMap maps the program counter of each instruction to the source range it was compiled from, as a
+		// [line, column, end line, end column] list, where lines start at one and columns at zero. Instructions which
+		// were not compiled from a source range map to null.
+		PcPosMap map[string][]int `json:"pc_pos_map"`
+
+		// PcJumpMap maps the program counter of each jump instruction to its jump type, as they are described by
+		// solc's source mappings.
+		PcJumpMap map[string]string `json:"pc_jump_map"`
+	} `json:"source_map"`
+}
+
+// parseVyperCombinedJson parses the output of `vyper -f combined_json` into a types.Compilation. Vyper contracts are
+// named after their source file. Vyper does not output an AST in the form other platforms provide, so each source is
+// given a minimal AST which describes only its source unit ID, and Vyper's source maps are converted into solc's
+// format to be used with them. If a source map cannot be converted, the contract is given none, so coverage is only
+// recorded for its bytecode.
+// Returns the compilation, or an error if one occurs.
+func parseVyperCombinedJson(output []byte) (*types.Compilation, error) {
+	// Parse our output. Each source path maps to its contract, alongside a version key describing the compiler.
+	var results map[string]json.RawMessage
+	err := json.Unmarshal(output, &results)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse vyper output: %v", err)
+	}
+	sourcePaths := make([]string, 0, len(results))
+	for sourcePath := range results {
+		if sourcePath != "version" {
+			sourcePaths = append(sourcePaths, sourcePath)
+		}
+	}
+	sort.Strings(sourcePaths)
+
+	compilation := types.NewCompilation()
+	for sourceUnitID, sourcePath := range sourcePaths {
+		var contract vyperCombinedJsonContract
+		err = json.Unmarshal(results[sourcePath], &contract)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse vyper output for source '%s': %v", sourcePath, err)
+		}
+		contractName := strings.TrimSuffix(filepath.Base(sourcePath), filepath.Ext(sourcePath))
+
+		// Parse the ABI
+		contractAbi, err := types.ParseABIFromInterface(contract.Abi)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse ABI for contract '%s'\n", contractName)
+		}
+
+		// Decode our init and runtime bytecode
+		initBytecode, err := hex.DecodeString(strings.TrimPrefix(contract.Bytecode, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse init bytecode for contract '%s'\n", contractName)
+		}
+		runtimeBytecode, err := hex.DecodeString(strings.TrimPrefix(contract.BytecodeRuntime, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse runtime bytecode for contract '%s'\n", contractName)
+		}
+
+		// Our source maps and the bounds of method input parameters are resolved from the source code. If it cannot
+		// be read, we go without them.
+		source, _ := os.ReadFile(sourcePath)
+		var srcMapsRuntime string
+		var methodInputSizeLimits map[string][]int
+		if source != nil {
+			srcMapsRuntime = convertVyperSourceMap(runtimeBytecode, contract.SourceMap.PcPosMap, contract.SourceMap.PcJumpMap, source, sourceUnitID)
+			methodInputSizeLimits = getVyperMethodInputSizeLimits(string(source), contractAbi)
+		}
+
+		compilation.Sources[sourcePath] = types.CompiledSource{
+			Ast: map[string]any{
+				"nodeType": "Module",
+				"src":      fmt.Sprintf("0:%d:%d", len(source), sourceUnitID),
+			},
+			Contracts: map[string]types.CompiledContract{
+				contractName: {
+					Abi:                   *contractAbi,
+					InitBytecode:          initBytecode,
+					RuntimeBytecode:       runtimeBytecode,
+					SrcMapsInit:           "",
+					SrcMapsRuntime:        srcMapsRuntime,
+					MethodInputSizeLimits: methodInputSizeLimits,
+				},
+			},
+		}
+	}
+	return compilation, nil
+}
+
+// convertVyperSourceMap converts the source map Vyper outputs for the provided runtime bytecode, which maps program
+// counters to line and column ranges, into solc's source map format, which maps the index of each instruction to a
+// byte range in the source with the provided source unit ID. Instructions without a known source range are mapped to
+// a negative source unit ID.
+// Returns the source map, or an empty string if Vyper provided none.
+func convertVyperSourceMap(bytecode []byte, pcPosMap map[string][]int, pcJumpMap map[string]string, source []byte, sourceUnitID int) string {
+	if len(pcPosMap) == 0 {
+		return ""
+	}
+
+	// Determine the byte offset at which each line starts, so we can convert lines and columns to byte offsets.
+	lineOffsets := []int{0}
+	for i, b := range source {
+		if b == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+	toOffset := func(line int, column int) int {
+		if line < 1 || line > len(lineOffsets) {
+			return -1
+		}
+		return lineOffsets[line-1] + column
+	}
+
+	// Create an element for every instruction. We do not compress the source map, as it is parsed into the same
+	// elements either way.
+	instructionPCs := types.GetInstructionPCs(bytecode)
+	elements := make([]string, len(instructionPCs))
+	for i, pc := range instructionPCs {
+		pcStr := strconv.FormatUint(pc, 10)
+		jumpType, ok := pcJumpMap[pcStr]
+		if !ok {
+			jumpType = "-"
+		}
+
+		offset, length, elementSourceUnitID := -1, -1, -1
+		if pos := pcPosMap[pcStr]; len(pos) == 4 {
+			start, end := toOffset(pos[0], pos[1]), toOffset(pos[2], pos[3])
+			if start >= 0 && end >= start {
+				offset, length, elementSourceUnitID = start, end-start, sourceUnitID
+			}
+		}
+		elements[i] = fmt.Sprintf("%d:%d:%d:%s", offset, length, elementSourceUnitID, jumpType)
+	}
+	return strings.Join(elements, ";")
+}
+
+// vyperFunctionDefinitionRegex matches the start of a function definition in Vyper source code, capturing the name of
+// the function. Functions declared by interfaces are indented, so they are not matched.
+var vyperFunctionDefinitionRegex = regexp.MustCompile(`(?m)^def\s+(\w+)\s*\(`)
+
+// vyperConstantDefinitionRegex matches the definition of an integer constant in Vyper source code, capturing its name
+// and value.
+var vyperConstantDefinitionRegex = regexp.MustCompile(`(?m)^(\w+)\s*:\s*constant\(\w+\)\s*=\s*(\d+)\s*$`)
+
+// vyperBoundedTypeRegex matches the Vyper types whose size is bounded, capturing the bound, which is the last
+// argument of the type.
+var vyperBoundedTypeRegex = regexp.MustCompile(`^(?:Bytes|String|DynArray)\s*\[(?:.*,)?\s*(\w+)\s*\]$`)
+
+// getVyperMethodInputSizeLimits resolves the bounded input parameters of the methods in the provided ABI from the
+// function definitions in the provided Vyper source code, as Bytes[N], String[N] and DynArray[T, N] parameters are
+// encoded as unbounded bytes, string and dynamic array types in the ABI, and values exceeding their bounds are
+// rejected.
+// Returns a mapping of hex-encoded method selectors (without a "0x" prefix) to a slice containing an entry for each
+// input parameter of the method, which is the bound of the parameter, or zero if it is not bounded.
+func getVyperMethodInputSizeLimits(source string, contractAbi *abi.ABI) map[string][]int {
+	// Collect integer constants, as they may be used as bounds.
+	constants := make(map[string]int)
+	for _, match := range vyperConstantDefinitionRegex.FindAllStringSubmatch(source, -1) {
+		if value, err := strconv.Atoi(match[2]); err == nil {
+			constants[match[1]] = value
+		}
+	}
+
+	// Resolve the bound of each parameter of each function definition.
+	functionLimits := make(map[string][]int)
+	for _, match := range vyperFunctionDefinitionRegex.FindAllStringSubmatchIndex(source, -1) {
+		name := source[match[2]:match[3]]
+		var limits []int
+		for _, parameter := range splitVyperParameters(source[match[1]:]) {
+			// Strip the default value and name of the parameter, leaving its type.
+			parameter, _, _ = strings.Cut(parameter, "=")
+			_, parameterType, _ := strings.Cut(parameter, ":")
+			limit := 0
+			if boundMatch := vyperBoundedTypeRegex.FindStringSubmatch(strings.TrimSpace(parameterType)); boundMatch != nil {
+				if value, err := strconv.Atoi(boundMatch[1]); err == nil {
+					limit = value
+				} else {
+					limit = constants[boundMatch[1]]
+				}
+			}
+			limits = append(limits, limit)
+		}
+		functionLimits[name] = limits
+	}
+
+	// Map each method to the bounds of its parameters. Functions with default parameter values produce a method for
+	// each number of parameters they can be called with, so methods may only use some of the parameters.
+	results := make(map[string][]int)
+	for _, method := range contractAbi.Methods {
+		limits, ok := functionLimits[method.RawName]
+		if !ok || len(limits) < len(method.Inputs) {
+			continue
+		}
+		limits = limits[:len(method.Inputs)]
+		for _, limit := range limits {
+			if limit > 0 {
+				results[hex.EncodeToString(method.ID)] = limits
+				break
+			}
+		}
+	}
+	return results
+}
+
+// splitVyperParameters splits the parameter list of a Vyper function definition, starting after its opening
+// parenthesis, into its parameters, ignoring commas within nested brackets, parentheses and string literals.
+// Returns the parameters, which may contain surrounding whitespace.
+func splitVyperParameters(source string) []string {
+	var parameters []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case ')':
+			if depth == 0 {
+				if parameter := strings.TrimSpace(source[start:i]); parameter != "" {
+					parameters = append(parameters, parameter)
+				}
+				return parameters
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				parameters = append(parameters, strings.TrimSpace(source[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return parameters
+}
@@ -0,0 +1,75 @@
+package platforms
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVyperCompilationAbsolutePath tests compilation of a Vyper project with an absolute project path.
+func TestVyperCompilationAbsolutePath(t *testing.T) {
+	// Copy our testdata over to our testing directory
+	vyperDirectory := testutils.CopyToTestDirectory(t, "testdata/vyper/basic_project/")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, vyperDirectory, func() {
+		// Create a vyper provider
+		vyperConfig := NewVyperCompilationConfig(vyperDirectory)
+
+		// Compile the project and ensure we obtained a single compilation for its contracts
+		compilations, _, err := vyperConfig.Compile()
+		assert.NoError(t, err)
+		assert.EqualValues(t, 1, len(compilations))
+	})
+}
+
+// TestVyperCompilationCombinedJson tests parsing the output of `vyper -f combined_json`, ensuring the contract, its
+// source map and the bounds of its method input parameters are resolved.
+func TestVyperCompilationCombinedJson(t *testing.T) {
+	// Copy our testdata over to our testing directory
+	vyperDirectory := testutils.CopyToTestDirectory(t, "testdata/vyper/basic_project/")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, vyperDirectory, func() {
+		// Parse the output of a previous compilation, rather than invoking vyper.
+		output, err := os.ReadFile("combined_json.json")
+		assert.NoError(t, err)
+		compilation, err := parseVyperCombinedJson(output)
+		assert.NoError(t, err)
+
+		// Our contract should be named after its source file, which its source unit ID should resolve to.
+		sourcePath := filepath.Join("contracts", "Token.vy")
+		contract, ok := compilation.Sources[sourcePath].Contracts["Token"]
+		assert.True(t, ok)
+		assert.EqualValues(t, 8, len(contract.Abi.Methods))
+		assert.NotEmpty(t, contract.InitBytecode)
+		assert.NotEmpty(t, contract.RuntimeBytecode)
+		resolvedSourcePath, ok := compilation.GetSourcePath(0)
+		assert.True(t, ok)
+		assert.EqualValues(t, sourcePath, resolvedSourcePath)
+
+		// The source map should have an element for each instruction, mapping it to the byte range of the statement
+		// it was compiled from.
+		sourceMap, err := types.ParseSourceMap(contract.SrcMapsRuntime)
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(types.GetInstructionPCs(contract.RuntimeBytecode)), len(sourceMap))
+		assert.EqualValues(t, -1, sourceMap[0].SourceUnitID)
+		assert.EqualValues(t, types.SourceMapElement{Offset: 241, Length: 19, SourceUnitID: 0, JumpType: "-"}, sourceMap[1])
+
+		// Bytes, String and DynArray parameters should be bounded, including those with default values, and with
+		// bounds defined by constants.
+		expectedLimits := map[string][]int{
+			"setName":  {32},
+			"setData":  {64},
+			"setData0": {64, 8},
+		}
+		for methodName, method := range contract.Abi.Methods {
+			assert.EqualValues(t, expectedLimits[methodName], contract.MethodInputSizeLimits[hex.EncodeToString(method.ID)], methodName)
+		}
+	})
+}
@@ -18,6 +18,9 @@ func init() {
 		func() platforms.PlatformConfig { return platforms.NewSolcCompilationConfig("contract.sol") },
 		func() platforms.PlatformConfig { return platforms.NewTruffleCompilationConfig(".") },
 		func() platforms.PlatformConfig { return platforms.NewCryticCompilationConfig(".") },
+		func() platforms.PlatformConfig { return platforms.NewFoundryCompilationConfig(".") },
+		func() platforms.PlatformConfig { return platforms.NewHardhatCompilationConfig(".") },
+		func() platforms.PlatformConfig { return platforms.NewVyperCompilationConfig(".") },
 	}
 
 	// Initialize our platform config generator.
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/fxamacker/cbor"
+)
+
+// BytecodeRegion describes a contiguous region of bytecode.
+type BytecodeRegion struct {
+	// Offset describes the byte offset at which the region starts.
+	Offset int
+
+	// Length describes the byte length of the region.
+	Length int
+}
+
+// StripContractMetadata removes the CBOR-encoded ContractMetadata trailer the Solidity and Vyper compilers append to
+// runtime bytecode, which is followed by its big-endian length, as a 2-byte integer.
+// Returns the bytecode without its metadata trailer, or the provided bytecode if it has none.
+func StripContractMetadata(bytecode []byte) []byte {
+	if len(bytecode) < 2 {
+		return bytecode
+	}
+	metadataLength := int(bytecode[len(bytecode)-2])<<8 | int(bytecode[len(bytecode)-1])
+	metadataOffset := len(bytecode) - 2 - metadataLength
+	if metadataLength == 0 || metadataOffset < 0 {
+		return bytecode
+	}
+	var metadata map[string]any
+	if err := cbor.Unmarshal(bytecode[metadataOffset:len(bytecode)-2], &metadata); err != nil {
+		return bytecode
+	}
+	return bytecode[:metadataOffset]
+}
+
+// zeroPush32Regions determines the regions of the provided bytecode which are the zero operands of PUSH32
+// instructions. Compilers emit these as placeholders for the values of immutable variables, which are substituted
+// into the bytecode when the contract is deployed.
+// Returns the regions of each zero PUSH32 operand.
+func zeroPush32Regions(bytecode []byte) []BytecodeRegion {
+	regions := make([]BytecodeRegion, 0)
+	zeroOperand := make([]byte, 32)
+	for pc := 0; pc < len(bytecode); pc++ {
+		op := vm.OpCode(bytecode[pc])
+		if !op.IsPush() {
+			continue
+		}
+		operandLength := int(op - vm.PUSH1 + 1)
+		if op == vm.PUSH32 && pc+1+operandLength <= len(bytecode) && bytes.Equal(bytecode[pc+1:pc+1+operandLength], zeroOperand) {
+			regions = append(regions, BytecodeRegion{Offset: pc + 1, Length: operandLength})
+		}
+		pc += operandLength
+	}
+	return regions
+}
+
+// IsRuntimeMatchIgnoringMetadata returns a boolean indicating whether the provided runtime bytecode of a deployed
+// contract is a match to this compiled contract definition, when their metadata trailers, and the values of immutable
+// variables substituted into the deployed bytecode, are ignored. The regions holding immutable values are described
+// by RuntimeImmutableReferences if the compilation platform provides them, or are otherwise assumed to be the zero
+// operands of PUSH32 instructions in the definition's bytecode. This matches contracts whose metadata differs from the
+// definition's, such as those compiled without metadata, which IsMatch cannot match.
+func (c *CompiledContract) IsRuntimeMatchIgnoringMetadata(runtimeBytecode []byte) bool {
+	// Strip the metadata of both, which must leave code of the same length.
+	definitionCode := StripContractMetadata(c.RuntimeBytecode)
+	deployedCode := StripContractMetadata(runtimeBytecode)
+	if len(definitionCode) == 0 || len(definitionCode) != len(deployedCode) {
+		return false
+	}
+
+	// Mask the regions holding immutable values, then compare the remaining code.
+	immutableRegions := c.RuntimeImmutableReferences
+	if len(immutableRegions) == 0 {
+		immutableRegions = zeroPush32Regions(definitionCode)
+	}
+	maskedDeployedCode := append([]byte{}, deployedCode...)
+	for _, region := range immutableRegions {
+		if region.Offset < 0 || region.Offset+region.Length > len(definitionCode) {
+			return false
+		}
+		copy(maskedDeployedCode[region.Offset:region.Offset+region.Length], definitionCode[region.Offset:region.Offset+region.Length])
+	}
+	return bytes.Equal(maskedDeployedCode, definitionCode)
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testMetadataTrailer creates a CBOR-encoded metadata trailer (as appended to runtime bytecode by solc), followed by
+// its length, with an IPFS hash filled with the provided byte.
+func testMetadataTrailer(hashByte byte) []byte {
+	metadata := append([]byte{0xa2, 0x64, 'i', 'p', 'f', 's', 0x58, 0x22}, bytes.Repeat([]byte{hashByte}, 34)...)
+	metadata = append(metadata, 0x64, 's', 'o', 'l', 'c', 0x43, 0x00, 0x08, 0x13)
+	return append(metadata, 0x00, byte(len(metadata)))
+}
+
+// testRuntimeCode creates runtime bytecode which pushes the provided immutable value with PUSH32, then stores the
+// provided value, followed by a metadata trailer with an IPFS hash filled with the provided byte.
+func testRuntimeCode(immutableValue byte, storedValue byte, hashByte byte) []byte {
+	code := []byte{0x60, 0x80, 0x60, 0x40, 0x52, 0x7f}
+	code = append(code, bytes.Repeat([]byte{immutableValue}, 32)...)
+	code = append(code, 0x60, storedValue, 0x55, 0x00)
+	return append(code, testMetadataTrailer(hashByte)...)
+}
+
+// TestStripContractMetadata tests that metadata trailers are stripped from runtime bytecode, and bytecode without
+// one is left unchanged.
+func TestStripContractMetadata(t *testing.T) {
+	code := []byte{0x60, 0x80, 0x60, 0x40, 0x52, 0x00}
+	assert.EqualValues(t, code, StripContractMetadata(append(append([]byte{}, code...), testMetadataTrailer(1)...)))
+	assert.EqualValues(t, code, StripContractMetadata(code))
+	assert.EqualValues(t, []byte{0x00, 0x02}, StripContractMetadata([]byte{0x00, 0x02}))
+	assert.Empty(t, StripContractMetadata(nil))
+}
+
+// TestIsRuntimeMatchIgnoringMetadata tests that deployed runtime bytecode is matched to a contract definition when it
+// only differs by its metadata and the values of immutable variables, but not otherwise.
+func TestIsRuntimeMatchIgnoringMetadata(t *testing.T) {
+	// Our definition has a zero PUSH32 operand as a placeholder for an immutable value.
+	contract := CompiledContract{RuntimeBytecode: testRuntimeCode(0, 1, 1)}
+
+	// Deployments whose metadata and immutable values differ should only be matched when they are ignored.
+	deployedCode := testRuntimeCode(7, 1, 2)
+	assert.False(t, contract.IsMatch(nil, deployedCode))
+	assert.True(t, contract.IsRuntimeMatchIgnoringMetadata(deployedCode))
+	assert.True(t, contract.IsRuntimeMatchIgnoringMetadata(StripContractMetadata(deployedCode)))
+
+	// Deployments whose code otherwise differs should not be matched.
+	assert.False(t, contract.IsRuntimeMatchIgnoringMetadata(testRuntimeCode(7, 2, 1)))
+	assert.False(t, contract.IsRuntimeMatchIgnoringMetadata(append([]byte{0x00}, deployedCode...)))
+	assert.False(t, contract.IsRuntimeMatchIgnoringMetadata(nil))
+
+	// If immutable references are provided, only the regions they describe should be ignored, rather than every zero
+	// PUSH32 operand.
+	contract.RuntimeImmutableReferences = []BytecodeRegion{{Offset: 6, Length: 32}}
+	assert.True(t, contract.IsRuntimeMatchIgnoringMetadata(deployedCode))
+	contract.RuntimeImmutableReferences = []BytecodeRegion{{Offset: 6, Length: 16}}
+	assert.False(t, contract.IsRuntimeMatchIgnoringMetadata(deployedCode))
+	contract.RuntimeImmutableReferences = []BytecodeRegion{{Offset: 60, Length: 32}}
+	assert.False(t, contract.IsRuntimeMatchIgnoringMetadata(deployedCode))
+
+	// Definitions without runtime bytecode (e.g. interfaces) should never be matched.
+	assert.False(t, (&CompiledContract{}).IsRuntimeMatchIgnoringMetadata(deployedCode))
+}
@@ -0,0 +1,184 @@
+package types
+
+import "strings"
+
+// EnumBounds describes the enums within the type of a method input parameter, mirroring the structure of the
+// parameter's abi.Type. Enums are encoded as uint8 in the ABI, so this information is otherwise lost once a contract
+// ABI is parsed. A nil *EnumBounds indicates the type contains no enums.
+type EnumBounds struct {
+	// MemberCount describes the number of members of the enum, if the type is an enum, or zero otherwise.
+	MemberCount int
+
+	// Elem describes the enums within the element type, if the type is an array, or nil otherwise.
+	Elem *EnumBounds
+
+	// TupleElems describes the enums within the type of each field, if the type is a struct, or nil otherwise.
+	TupleElems []*EnumBounds
+}
+
+// GetMethodInputEnumBounds resolves the enums within the input parameters for the methods of the contract with the
+// provided name, defined in the source at the provided path, including enums nested within arrays or structs.
+// Returns a mapping of hex-encoded method selectors (without a "0x" prefix) to a slice containing an entry for
+// each input parameter of the method. Each entry describes the enums within the type of the parameter, or is nil if
+// the parameter contains no enums.
+func (c *Compilation) GetMethodInputEnumBounds(sourcePath string, contractName string) map[string][]*EnumBounds {
+	// Collect the member counts for every enum definition and every struct definition across all sources, as a
+	// contract may refer to an enum or struct defined in an imported source. We also collect every contract
+	// definition, as a contract may inherit methods from base contracts defined in other sources.
+	enumMemberCounts := make(map[float64]int)
+	structDefinitions := make(map[float64]map[string]any)
+	contractDefinitions := make(map[float64]map[string]any)
+	var targetContract map[string]any
+	for currentSourcePath, source := range c.Sources {
+		walkCompilationAstNodes(source.Ast, func(node map[string]any) {
+			id, obtainedId := node["id"].(float64)
+			nodeType, obtainedNodeType := node["nodeType"].(string)
+			if !obtainedId || !obtainedNodeType {
+				return // fail silently to continue walking
+			}
+
+			if strings.EqualFold(nodeType, "EnumDefinition") {
+				if members, obtainedMembers := node["members"].([]any); obtainedMembers {
+					enumMemberCounts[id] = len(members)
+				}
+			} else if strings.EqualFold(nodeType, "StructDefinition") {
+				structDefinitions[id] = node
+			} else if strings.EqualFold(nodeType, "ContractDefinition") {
+				contractDefinitions[id] = node
+				if name, _ := node["name"].(string); currentSourcePath == sourcePath && name == contractName {
+					targetContract = node
+				}
+			}
+		})
+	}
+
+	// If we could not find our contract definition, we cannot resolve anything.
+	if targetContract == nil {
+		return nil
+	}
+
+	// Obtain the linearized inheritance order for our contract, starting with the most derived (the contract itself).
+	// If it isn't provided, we only consider the contract's own definitions.
+	linearizedBaseContracts, obtainedBaseContracts := targetContract["linearizedBaseContracts"].([]any)
+	if !obtainedBaseContracts {
+		linearizedBaseContracts = []any{targetContract["id"]}
+	}
+
+	// Resolve the enum parameters of each function, where definitions in more derived contracts take precedence.
+	results := make(map[string][]*EnumBounds)
+	for _, baseContractId := range linearizedBaseContracts {
+		id, ok := baseContractId.(float64)
+		if !ok {
+			continue
+		}
+		contractDefinition, ok := contractDefinitions[id]
+		if !ok {
+			continue
+		}
+
+		// Walk the contract's function definitions.
+		walkCompilationAstNodes(contractDefinition["nodes"], func(node map[string]any) {
+			nodeType, _ := node["nodeType"].(string)
+			if !strings.EqualFold(nodeType, "FunctionDefinition") {
+				return
+			}
+
+			// Older compiler versions do not provide function selectors in the AST, in which case we can't map the
+			// function to an ABI method.
+			selector, obtainedSelector := node["functionSelector"].(string)
+			if !obtainedSelector {
+				return
+			}
+			if _, exists := results[selector]; exists {
+				return
+			}
+
+			// Obtain our function parameters.
+			parameterList, _ := node["parameters"].(map[string]any)
+			parameters, _ := parameterList["parameters"].([]any)
+
+			// Determine the enums within each parameter.
+			bounds := make([]*EnumBounds, len(parameters))
+			hasEnum := false
+			for i, parameter := range parameters {
+				parameterNode, _ := parameter.(map[string]any)
+				typeName, _ := parameterNode["typeName"].(map[string]any)
+				bounds[i] = resolveEnumBounds(typeName, enumMemberCounts, structDefinitions)
+				hasEnum = hasEnum || bounds[i] != nil
+			}
+
+			// Only record functions that have parameters containing enums.
+			if hasEnum {
+				results[selector] = bounds
+			}
+		})
+	}
+	return results
+}
+
+// resolveEnumBounds resolves the enums within the type described by the provided AST type name node, walking the
+// base type of arrays and the member types of structs, using the provided enum member counts and struct definitions,
+// keyed by the AST node ID of their definitions.
+// Returns the enums within the type, or nil if it contains none.
+func resolveEnumBounds(typeName map[string]any, enumMemberCounts map[float64]int, structDefinitions map[float64]map[string]any) *EnumBounds {
+	// Arrays contain enums if their base type does.
+	if nodeType, _ := typeName["nodeType"].(string); strings.EqualFold(nodeType, "ArrayTypeName") {
+		baseType, _ := typeName["baseType"].(map[string]any)
+		if elem := resolveEnumBounds(baseType, enumMemberCounts, structDefinitions); elem != nil {
+			return &EnumBounds{Elem: elem}
+		}
+		return nil
+	}
+
+	// Otherwise, the type must reference an enum or struct definition to contain enums.
+	referencedDeclaration, ok := typeName["referencedDeclaration"].(float64)
+	if !ok {
+		return nil
+	}
+	if memberCount, ok := enumMemberCounts[referencedDeclaration]; ok {
+		return &EnumBounds{MemberCount: memberCount}
+	}
+	structDefinition, ok := structDefinitions[referencedDeclaration]
+	if !ok {
+		return nil
+	}
+
+	// Structs contain enums if any of their members do.
+	members, _ := structDefinition["members"].([]any)
+	tupleElems := make([]*EnumBounds, len(members))
+	hasEnum := false
+	for i, member := range members {
+		memberNode, _ := member.(map[string]any)
+		memberTypeName, _ := memberNode["typeName"].(map[string]any)
+		tupleElems[i] = resolveEnumBounds(memberTypeName, enumMemberCounts, structDefinitions)
+		hasEnum = hasEnum || tupleElems[i] != nil
+	}
+	if hasEnum {
+		return &EnumBounds{TupleElems: tupleElems}
+	}
+	return nil
+}
+
+// walkCompilationAstNodes walks/iterates across an AST for each node, calling the provided walk function with each
+// discovered node as an argument.
+func walkCompilationAstNodes(ast any, walkFunc func(node map[string]any)) {
+	// Try to parse our node as different types and walk all children.
+	if d, ok := ast.(map[string]any); ok {
+		// If this dictionary contains keys 'id' and 'nodeType', we can assume it's an AST node
+		_, hasId := d["id"]
+		_, hasNodeType := d["nodeType"]
+		if hasId && hasNodeType {
+			walkFunc(d)
+		}
+
+		// Walk all keys of the dictionary.
+		for _, v := range d {
+			walkCompilationAstNodes(v, walkFunc)
+		}
+	} else if slice, ok := ast.([]any); ok {
+		// Walk all elements of a slice.
+		for _, elem := range slice {
+			walkCompilationAstNodes(elem, walkFunc)
+		}
+	}
+}
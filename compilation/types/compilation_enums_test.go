@@ -0,0 +1,67 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetMethodInputEnumBounds ensures the enums within method input parameters are resolved from the AST, including
+// enums nested within arrays and structs.
+func TestGetMethodInputEnumBounds(t *testing.T) {
+	// Define an AST akin to the following source:
+	//   contract TestContract {
+	//       enum Color { Red, Green, Blue }
+	//       struct Paint { uint8 amount; Color color; }
+	//       function setColor(Color c) public {}
+	//       function setColors(Color[] calldata c, uint8 notAnEnum) public {}
+	//       function setPaints(Paint[2] calldata p) public {}
+	//       function setAmount(uint8 amount) public {}
+	//   }
+	astJSON := `{
+		"id": 1, "nodeType": "SourceUnit", "nodes": [{
+			"id": 2, "nodeType": "ContractDefinition", "name": "TestContract", "linearizedBaseContracts": [2], "nodes": [
+				{"id": 3, "nodeType": "EnumDefinition", "name": "Color", "members": [
+					{"id": 4, "nodeType": "EnumValue", "name": "Red"},
+					{"id": 5, "nodeType": "EnumValue", "name": "Green"},
+					{"id": 6, "nodeType": "EnumValue", "name": "Blue"}
+				]},
+				{"id": 7, "nodeType": "StructDefinition", "name": "Paint", "members": [
+					{"id": 8, "nodeType": "VariableDeclaration", "typeName": {"id": 9, "nodeType": "ElementaryTypeName", "name": "uint8"}},
+					{"id": 10, "nodeType": "VariableDeclaration", "typeName": {"id": 11, "nodeType": "UserDefinedTypeName", "referencedDeclaration": 3}}
+				]},
+				{"id": 12, "nodeType": "FunctionDefinition", "name": "setColor", "functionSelector": "aaaaaaaa", "parameters": {"id": 13, "nodeType": "ParameterList", "parameters": [
+					{"id": 14, "nodeType": "VariableDeclaration", "typeName": {"id": 15, "nodeType": "UserDefinedTypeName", "referencedDeclaration": 3}}
+				]}},
+				{"id": 16, "nodeType": "FunctionDefinition", "name": "setColors", "functionSelector": "bbbbbbbb", "parameters": {"id": 17, "nodeType": "ParameterList", "parameters": [
+					{"id": 18, "nodeType": "VariableDeclaration", "typeName": {"id": 19, "nodeType": "ArrayTypeName", "baseType": {"id": 20, "nodeType": "UserDefinedTypeName", "referencedDeclaration": 3}}},
+					{"id": 21, "nodeType": "VariableDeclaration", "typeName": {"id": 22, "nodeType": "ElementaryTypeName", "name": "uint8"}}
+				]}},
+				{"id": 23, "nodeType": "FunctionDefinition", "name": "setPaints", "functionSelector": "cccccccc", "parameters": {"id": 24, "nodeType": "ParameterList", "parameters": [
+					{"id": 25, "nodeType": "VariableDeclaration", "typeName": {"id": 26, "nodeType": "ArrayTypeName", "baseType": {"id": 27, "nodeType": "UserDefinedTypeName", "referencedDeclaration": 7}}}
+				]}},
+				{"id": 28, "nodeType": "FunctionDefinition", "name": "setAmount", "functionSelector": "dddddddd", "parameters": {"id": 29, "nodeType": "ParameterList", "parameters": [
+					{"id": 30, "nodeType": "VariableDeclaration", "typeName": {"id": 31, "nodeType": "ElementaryTypeName", "name": "uint8"}}
+				]}}
+			]
+		}]
+	}`
+	var ast any
+	err := json.Unmarshal([]byte(astJSON), &ast)
+	assert.NoError(t, err)
+	compilation := NewCompilation()
+	compilation.Sources["TestContract.sol"] = CompiledSource{Ast: ast}
+
+	// Verify the enums within each method's parameters were resolved, and methods without enums were omitted.
+	enumBounds := compilation.GetMethodInputEnumBounds("TestContract.sol", "TestContract")
+	colorBounds := &EnumBounds{MemberCount: 3}
+	assert.EqualValues(t, map[string][]*EnumBounds{
+		"aaaaaaaa": {colorBounds},
+		"bbbbbbbb": {{Elem: colorBounds}, nil},
+		"cccccccc": {{Elem: &EnumBounds{TupleElems: []*EnumBounds{nil, colorBounds}}}},
+	}, enumBounds)
+
+	// Nothing is resolved for a contract which does not exist.
+	assert.Nil(t, compilation.GetMethodInputEnumBounds("TestContract.sol", "MissingContract"))
+}
@@ -0,0 +1,86 @@
+package types
+
+import "strings"
+
+// GetFunctionSourceRanges resolves the source ranges of the function definitions of the contract with the provided
+// name, defined in the source at the provided path, including those it inherits from base contracts.
+// Returns a mapping of hex-encoded method selectors (without a "0x" prefix) to the source range of the function
+// definition which implements the method. Methods without a function definition (e.g. public state variable getters)
+// are omitted.
+func (c *Compilation) GetFunctionSourceRanges(sourcePath string, contractName string) map[string]SourceRange {
+	// Collect every contract definition, as a contract may inherit functions from base contracts defined in other
+	// sources.
+	contractDefinitions := make(map[float64]map[string]any)
+	var targetContract map[string]any
+	for currentSourcePath, source := range c.Sources {
+		walkCompilationAstNodes(source.Ast, func(node map[string]any) {
+			id, obtainedId := node["id"].(float64)
+			nodeType, _ := node["nodeType"].(string)
+			if !obtainedId || !strings.EqualFold(nodeType, "ContractDefinition") {
+				return // fail silently to continue walking
+			}
+			contractDefinitions[id] = node
+			if name, _ := node["name"].(string); currentSourcePath == sourcePath && name == contractName {
+				targetContract = node
+			}
+		})
+	}
+
+	// If we could not find our contract definition, we cannot resolve anything.
+	if targetContract == nil {
+		return nil
+	}
+
+	// Obtain the linearized inheritance order for our contract, starting with the most derived (the contract itself).
+	// If it isn't provided, we only consider the contract's own definitions.
+	linearizedBaseContracts, obtainedBaseContracts := targetContract["linearizedBaseContracts"].([]any)
+	if !obtainedBaseContracts {
+		linearizedBaseContracts = []any{targetContract["id"]}
+	}
+
+	// Resolve the function definition of each method, where definitions in more derived contracts take precedence.
+	results := make(map[string]SourceRange)
+	for _, baseContractId := range linearizedBaseContracts {
+		id, ok := baseContractId.(float64)
+		if !ok {
+			continue
+		}
+		contractDefinition, ok := contractDefinitions[id]
+		if !ok {
+			continue
+		}
+		walkCompilationAstNodes(contractDefinition["nodes"], func(node map[string]any) {
+			nodeType, _ := node["nodeType"].(string)
+			if !strings.EqualFold(nodeType, "FunctionDefinition") {
+				return
+			}
+
+			// Older compiler versions do not provide function selectors in the AST, in which case we can't map the
+			// function to an ABI method. Functions without an implementation do not compile to any instructions.
+			selector, obtainedSelector := node["functionSelector"].(string)
+			if implemented, ok := node["implemented"].(bool); !obtainedSelector || (ok && !implemented) {
+				return
+			}
+			if _, exists := results[selector]; exists {
+				return
+			}
+			src, _ := node["src"].(string)
+			if sourceRange, err := parseSourceRange(src); err == nil {
+				results[selector] = sourceRange
+			}
+		})
+	}
+	return results
+}
+
+// GetSourcePath resolves the path of the source file which source maps and source ranges reference with the provided
+// source unit identifier.
+// Returns the source path, and a boolean indicating whether it was found.
+func (c *Compilation) GetSourcePath(sourceUnitID int) (string, bool) {
+	for sourcePath, source := range c.Sources {
+		if id, err := GetSourceUnitID(source.Ast); err == nil && id == sourceUnitID {
+			return sourcePath, true
+		}
+	}
+	return "", false
+}
@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/crytic/medusa/logging"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 	"golang.org/x/exp/slices"
-	"strings"
 )
 
 // CompiledContract represents a single contract unit from a smart contract compilation.
@@ -28,6 +33,49 @@ type CompiledContract struct {
 
 	// SrcMapsRuntime describes the source mappings to associate source file and bytecode segments in RuntimeBytecode.
 	SrcMapsRuntime string
+
+	// MethodInputSizeLimits maps hex-encoded method selectors (without a "0x" prefix) to the maximum size of each of
+	// the method's input parameters, or zero if the size of the parameter is not bounded. The size of a bytes or string
+	// parameter is its length, while the size of a dynamic array parameter is its element count. This is populated by
+	// compilation platforms whose languages declare bounds which the ABI does not describe, such as Vyper.
+	MethodInputSizeLimits map[string][]int
+
+	// InitLinkReferences describes the locations in InitBytecode at which the addresses of the libraries the contract
+	// references must be substituted before it is deployed.
+	InitLinkReferences LinkReferences
+
+	// RuntimeLinkReferences describes the locations in RuntimeBytecode at which the addresses of the libraries the
+	// contract references are substituted once it is deployed.
+	RuntimeLinkReferences LinkReferences
+
+	// RuntimeImmutableReferences describes the regions of RuntimeBytecode which the values of immutable variables are
+	// substituted into when the contract is deployed. This is only populated by compilation platforms which provide
+	// them.
+	RuntimeImmutableReferences []BytecodeRegion
+}
+
+// LibraryReferences returns the references to every library the contract's bytecode must be linked with, in sorted
+// order. References are described by LinkReferences.
+func (c *CompiledContract) LibraryReferences() []string {
+	references := c.InitLinkReferences.References()
+	for _, reference := range c.RuntimeLinkReferences.References() {
+		if !slices.Contains(references, reference) {
+			references = append(references, reference)
+		}
+	}
+	sort.Strings(references)
+	return references
+}
+
+// LinkLibrary substitutes the provided address of the library with the provided reference into the contract's init
+// and runtime bytecode, so it can be deployed, and matched to deployments of it.
+// Returns an error if the bytecode could not be linked.
+func (c *CompiledContract) LinkLibrary(reference string, address common.Address) error {
+	err := c.InitLinkReferences.Link(c.InitBytecode, reference, address)
+	if err == nil {
+		err = c.RuntimeLinkReferences.Link(c.RuntimeBytecode, reference, address)
+	}
+	return err
 }
 
 // IsMatch returns a boolean indicating whether provided contract bytecode is a match to this compiled contract
@@ -85,33 +133,89 @@ func (c *CompiledContract) IsMatch(initBytecode []byte, runtimeBytecode []byte)
 	return false
 }
 
-// ParseABIFromInterface parses a generic object into an abi.ABI and returns it, or an error if one occurs.
-func ParseABIFromInterface(i any) (*abi.ABI, error) {
-	var (
-		result abi.ABI
-		err    error
-	)
+// fixedPointTypePattern matches ABI type strings of fixed point types (e.g. "fixed128x18" or "ufixed64x10[]"), or
+// arrays of them.
+var fixedPointTypePattern = regexp.MustCompile(`^u?fixed(\d+x\d+)?(\[\d*\])*$`)
 
+// usesFixedPointType indicates whether any of the provided JSON ABI arguments, or their tuple components, are of a
+// fixed point type.
+func usesFixedPointType(arguments []abiArgumentJSON) bool {
+	for _, argument := range arguments {
+		if fixedPointTypePattern.MatchString(argument.Type) || usesFixedPointType(argument.Components) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseABIFromInterface parses a generic object into an abi.ABI and returns it, or an error if one occurs. Our
+// go-ethereum fork cannot parse or pack fixed point types, so ABI entries using them are skipped with a warning,
+// rather than failing to parse the whole ABI.
+func ParseABIFromInterface(i any) (*abi.ABI, error) {
 	// If it's a string, just parse it. Otherwise, we assume it's an interface and serialize it into a string.
+	var b []byte
 	if s, ok := i.(string); ok {
-		result, err = abi.JSON(strings.NewReader(s))
-		if err != nil {
-			return nil, err
-		}
+		b = []byte(s)
 	} else {
-		var b []byte
+		var err error
 		b, err = json.Marshal(i)
 		if err != nil {
 			return nil, err
 		}
-		result, err = abi.JSON(strings.NewReader(string(b)))
-		if err != nil {
-			return nil, err
-		}
+	}
+
+	// Remove entries which use fixed point types before parsing.
+	b, skippedEntries, err := removeFixedPointABIEntries(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(skippedEntries) > 0 {
+		logging.GlobalLogger.Warn().Strs("entries", skippedEntries).
+			Msgf("Skipping ABI entries which use fixed point types, as they are unsupported: %v", strings.Join(skippedEntries, ", "))
+	}
+
+	result, err := abi.JSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
 	}
 	return &result, nil
 }
 
+// removeFixedPointABIEntries removes the entries of the provided JSON ABI whose inputs or outputs use fixed point
+// types. If the provided data is not a JSON array, it is returned unchanged, so abi.JSON can report the error.
+// Returns the JSON ABI without those entries, descriptions of the entries removed (e.g. "function deposit"), or an
+// error if one occurs.
+func removeFixedPointABIEntries(b []byte) ([]byte, []string, error) {
+	var rawEntries []json.RawMessage
+	if err := json.Unmarshal(b, &rawEntries); err != nil {
+		return b, nil, nil
+	}
+
+	keptEntries := make([]json.RawMessage, 0, len(rawEntries))
+	skippedEntries := make([]string, 0)
+	for _, rawEntry := range rawEntries {
+		var entry abiEntryJSON
+		if err := json.Unmarshal(rawEntry, &entry); err != nil {
+			return nil, nil, err
+		}
+		if usesFixedPointType(entry.Inputs) || usesFixedPointType(entry.Outputs) {
+			skippedEntries = append(skippedEntries, strings.TrimSpace(entry.Type+" "+entry.Name))
+			continue
+		}
+		keptEntries = append(keptEntries, rawEntry)
+	}
+
+	// If nothing was removed, return the original data.
+	if len(skippedEntries) == 0 {
+		return b, nil, nil
+	}
+	b, err := json.Marshal(keptEntries)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, skippedEntries, nil
+}
+
 // GetDeploymentMessageData is a helper method used create contract deployment message data for the given contract.
 // This data can be set in transaction/message structs "data" field to indicate the packed init bytecode and constructor
 // argument data to use.
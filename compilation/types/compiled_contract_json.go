@@ -0,0 +1,194 @@
+package types
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// compiledContractJSON describes the JSON representation of a CompiledContract. The ABI is stored in the standard
+// JSON ABI format, as abi.ABI can only be unmarshalled from it.
+type compiledContractJSON struct {
+	Abi                        json.RawMessage  `json:"abi"`
+	InitBytecode               []byte           `json:"initBytecode"`
+	RuntimeBytecode            []byte           `json:"runtimeBytecode"`
+	SrcMapsInit                string           `json:"srcMapsInit"`
+	SrcMapsRuntime             string           `json:"srcMapsRuntime"`
+	MethodInputSizeLimits      map[string][]int `json:"methodInputSizeLimits,omitempty"`
+	InitLinkReferences         LinkReferences   `json:"initLinkReferences,omitempty"`
+	RuntimeLinkReferences      LinkReferences   `json:"runtimeLinkReferences,omitempty"`
+	RuntimeImmutableReferences []BytecodeRegion `json:"runtimeImmutableReferences,omitempty"`
+}
+
+// abiEntryJSON describes an entry of a JSON ABI, which describes a single function, event or error.
+type abiEntryJSON struct {
+	Type            string            `json:"type"`
+	Name            string            `json:"name,omitempty"`
+	Inputs          []abiArgumentJSON `json:"inputs"`
+	Outputs         []abiArgumentJSON `json:"outputs"`
+	StateMutability string            `json:"stateMutability,omitempty"`
+	Constant        bool              `json:"constant,omitempty"`
+	Payable         bool              `json:"payable,omitempty"`
+	Anonymous       bool              `json:"anonymous,omitempty"`
+}
+
+// abiArgumentJSON describes an argument of an entry of a JSON ABI, or a component of a tuple argument.
+type abiArgumentJSON struct {
+	Name         string            `json:"name"`
+	Type         string            `json:"type"`
+	InternalType string            `json:"internalType,omitempty"`
+	Components   []abiArgumentJSON `json:"components,omitempty"`
+	Indexed      bool              `json:"indexed,omitempty"`
+}
+
+// newAbiArgumentJSON converts an argument with the provided name and type to its JSON ABI representation.
+func newAbiArgumentJSON(name string, argumentType *abi.Type, indexed bool) abiArgumentJSON {
+	// Find the element type of any arrays, as tuples are described by a "tuple" type with their components, followed
+	// by the array suffixes.
+	elementType := argumentType
+	for (elementType.T == abi.SliceTy || elementType.T == abi.ArrayTy) && elementType.Elem != nil {
+		elementType = elementType.Elem
+	}
+	argument := abiArgumentJSON{Name: name, Type: argumentType.String(), Indexed: indexed}
+	if elementType.T == abi.TupleTy {
+		arraySuffix := argumentType.String()[len(elementType.String()):]
+		argument.Type = "tuple" + arraySuffix
+		if elementType.TupleRawName != "" {
+			argument.InternalType = "struct " + elementType.TupleRawName + arraySuffix
+		}
+		argument.Components = make([]abiArgumentJSON, len(elementType.TupleElems))
+		for i, componentType := range elementType.TupleElems {
+			argument.Components[i] = newAbiArgumentJSON(elementType.TupleRawNames[i], componentType, false)
+		}
+	}
+	return argument
+}
+
+// newAbiArgumentsJSON converts the provided arguments to their JSON ABI representation. Nil arguments are converted
+// to nil, so they remain nil when they are parsed again.
+func newAbiArgumentsJSON(arguments abi.Arguments) []abiArgumentJSON {
+	if arguments == nil {
+		return nil
+	}
+	argumentsJSON := make([]abiArgumentJSON, len(arguments))
+	for i, argument := range arguments {
+		argumentsJSON[i] = newAbiArgumentJSON(argument.Name, &argument.Type, argument.Indexed)
+	}
+	return argumentsJSON
+}
+
+// newAbiMethodJSON converts the provided method of the provided type to its JSON ABI representation.
+func newAbiMethodJSON(entryType string, method abi.Method) abiEntryJSON {
+	return abiEntryJSON{
+		Type:            entryType,
+		Name:            method.RawName,
+		Inputs:          newAbiArgumentsJSON(method.Inputs),
+		Outputs:         newAbiArgumentsJSON(method.Outputs),
+		StateMutability: method.StateMutability,
+		Constant:        method.Constant,
+		Payable:         method.Payable,
+	}
+}
+
+// marshalABI converts the provided ABI to the standard JSON ABI format, so it can be parsed by abi.JSON. Entries are
+// ordered by name, so overloaded methods and events are assigned the same names when they are parsed again.
+// Returns the JSON ABI, or an error if one occurs.
+func marshalABI(contractAbi abi.ABI) ([]byte, error) {
+	entries := make([]abiEntryJSON, 0)
+	constructor := contractAbi.Constructor
+	if constructor.StateMutability != "" || constructor.Payable || len(constructor.Inputs) > 0 {
+		entries = append(entries, newAbiMethodJSON("constructor", constructor))
+	}
+	if contractAbi.HasFallback() {
+		entries = append(entries, newAbiMethodJSON("fallback", contractAbi.Fallback))
+	}
+	if contractAbi.HasReceive() {
+		entries = append(entries, newAbiMethodJSON("receive", contractAbi.Receive))
+	}
+
+	methodNames := make([]string, 0, len(contractAbi.Methods))
+	for name := range contractAbi.Methods {
+		methodNames = append(methodNames, name)
+	}
+	sort.Strings(methodNames)
+	for _, name := range methodNames {
+		entries = append(entries, newAbiMethodJSON("function", contractAbi.Methods[name]))
+	}
+
+	eventNames := make([]string, 0, len(contractAbi.Events))
+	for name := range contractAbi.Events {
+		eventNames = append(eventNames, name)
+	}
+	sort.Strings(eventNames)
+	for _, name := range eventNames {
+		event := contractAbi.Events[name]
+		entries = append(entries, abiEntryJSON{
+			Type:      "event",
+			Name:      event.RawName,
+			Inputs:    newAbiArgumentsJSON(event.Inputs),
+			Anonymous: event.Anonymous,
+		})
+	}
+
+	errorNames := make([]string, 0, len(contractAbi.Errors))
+	for name := range contractAbi.Errors {
+		errorNames = append(errorNames, name)
+	}
+	sort.Strings(errorNames)
+	for _, name := range errorNames {
+		entries = append(entries, abiEntryJSON{
+			Type:   "error",
+			Name:   contractAbi.Errors[name].Name,
+			Inputs: newAbiArgumentsJSON(contractAbi.Errors[name].Inputs),
+		})
+	}
+	return json.Marshal(entries)
+}
+
+// MarshalJSON provides custom JSON marshalling for the CompiledContract, storing its ABI in the standard JSON ABI
+// format.
+// Returns the JSON encoded data, or an error if one occurs.
+func (c CompiledContract) MarshalJSON() ([]byte, error) {
+	abiJSON, err := marshalABI(c.Abi)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(compiledContractJSON{
+		Abi:                        abiJSON,
+		InitBytecode:               c.InitBytecode,
+		RuntimeBytecode:            c.RuntimeBytecode,
+		SrcMapsInit:                c.SrcMapsInit,
+		SrcMapsRuntime:             c.SrcMapsRuntime,
+		MethodInputSizeLimits:      c.MethodInputSizeLimits,
+		InitLinkReferences:         c.InitLinkReferences,
+		RuntimeLinkReferences:      c.RuntimeLinkReferences,
+		RuntimeImmutableReferences: c.RuntimeImmutableReferences,
+	})
+}
+
+// UnmarshalJSON provides custom JSON unmarshalling for the CompiledContract.
+// Returns an error if one occurs.
+func (c *CompiledContract) UnmarshalJSON(b []byte) error {
+	var contractJSON compiledContractJSON
+	err := json.Unmarshal(b, &contractJSON)
+	if err != nil {
+		return err
+	}
+	contractAbi, err := ParseABIFromInterface(string(contractJSON.Abi))
+	if err != nil {
+		return err
+	}
+	*c = CompiledContract{
+		Abi:                        *contractAbi,
+		InitBytecode:               contractJSON.InitBytecode,
+		RuntimeBytecode:            contractJSON.RuntimeBytecode,
+		SrcMapsInit:                contractJSON.SrcMapsInit,
+		SrcMapsRuntime:             contractJSON.SrcMapsRuntime,
+		MethodInputSizeLimits:      contractJSON.MethodInputSizeLimits,
+		InitLinkReferences:         contractJSON.InitLinkReferences,
+		RuntimeLinkReferences:      contractJSON.RuntimeLinkReferences,
+		RuntimeImmutableReferences: contractJSON.RuntimeImmutableReferences,
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseABIFromInterfaceFixedPointTypes ensures ABI entries using fixed point types, which our go-ethereum fork
+// cannot parse, are skipped without failing to parse the rest of the ABI.
+func TestParseABIFromInterfaceFixedPointTypes(t *testing.T) {
+	abiJSON := `[
+		{"type": "function", "name": "deposit", "inputs": [{"name": "amount", "type": "ufixed128x18"}], "outputs": [], "stateMutability": "nonpayable"},
+		{"type": "function", "name": "rates", "inputs": [], "outputs": [{"name": "", "type": "tuple[]", "components": [{"name": "rate", "type": "fixed"}]}], "stateMutability": "view"},
+		{"type": "function", "name": "withdraw", "inputs": [{"name": "amount", "type": "uint256"}], "outputs": [], "stateMutability": "nonpayable"},
+		{"type": "event", "name": "Rate", "inputs": [{"name": "rate", "type": "fixed64x10[2]", "indexed": false}], "anonymous": false}
+	]`
+
+	// Parse the ABI from a string, and from a generic JSON value.
+	for _, input := range []any{abiJSON, []any{map[string]any{"type": "function", "name": "deposit", "inputs": []any{map[string]any{"name": "amount", "type": "fixed"}}, "outputs": []any{}}}} {
+		contractAbi, err := ParseABIFromInterface(input)
+		assert.NoError(t, err)
+		assert.NotContains(t, contractAbi.Methods, "deposit")
+		assert.NotContains(t, contractAbi.Methods, "rates")
+		assert.Empty(t, contractAbi.Events)
+	}
+
+	// Entries without fixed point types should remain.
+	contractAbi, err := ParseABIFromInterface(abiJSON)
+	assert.NoError(t, err)
+	assert.Contains(t, contractAbi.Methods, "withdraw")
+	assert.Len(t, contractAbi.Methods, 1)
+}
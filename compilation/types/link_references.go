@@ -0,0 +1,97 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// libraryPlaceholderLength describes the length of a placeholder for a library address in hex-encoded bytecode, which
+// is the length of a hex-encoded address.
+const libraryPlaceholderLength = common.AddressLength * 2
+
+// LinkReferences describes the locations in bytecode at which the addresses of libraries must be substituted before
+// it is deployed. It maps a reference to each library to the byte offsets of its address within the bytecode. A
+// reference is either the fully qualified name of the library (of the form "<source path>:<library name>"), or the
+// placeholder the compiler left in the bytecode in place of its address, if the compilation artifacts do not
+// describe which library it refers to.
+type LinkReferences map[string][]int
+
+// DecodeLinkableBytecode decodes the provided hex-encoded bytecode, which may contain placeholders for the addresses of
+// libraries it references (e.g. "__$<hash>$__"), which are decoded as zero addresses.
+// Returns the decoded bytecode and the locations of the placeholders within it, keyed by placeholder, or an error if
+// the bytecode could not be decoded.
+func DecodeLinkableBytecode(bytecode string) ([]byte, LinkReferences, error) {
+	// Replace every placeholder with a zero address, recording where it was. Placeholders are the only underscores
+	// which can appear in hex-encoded bytecode.
+	bytecode = strings.TrimPrefix(bytecode, "0x")
+	var linkReferences LinkReferences
+	for {
+		index := strings.Index(bytecode, "__")
+		if index == -1 {
+			break
+		}
+		if index%2 != 0 || index+libraryPlaceholderLength > len(bytecode) {
+			return nil, nil, fmt.Errorf("bytecode contains a malformed library placeholder at offset %d", index/2)
+		}
+		if linkReferences == nil {
+			linkReferences = make(LinkReferences)
+		}
+		placeholder := bytecode[index : index+libraryPlaceholderLength]
+		linkReferences[placeholder] = append(linkReferences[placeholder], index/2)
+		bytecode = bytecode[:index] + strings.Repeat("0", libraryPlaceholderLength) + bytecode[index+libraryPlaceholderLength:]
+	}
+
+	decoded, err := hex.DecodeString(bytecode)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decoded, linkReferences, nil
+}
+
+// IsLinkReferenceTo indicates whether the provided link reference refers to the library with the provided name,
+// defined in the source at the provided path. A reference refers to the library if it is its fully qualified name, or
+// the placeholder the compiler leaves for it, in either the current ("__$<hash>$__") or legacy ("__<name>___") form.
+func IsLinkReferenceTo(reference string, sourcePath string, libraryName string) bool {
+	fullyQualifiedName := sourcePath + ":" + libraryName
+	if reference == fullyQualifiedName {
+		return true
+	}
+
+	// The current placeholder form contains a prefix of the hash of the fully qualified name.
+	hash := hex.EncodeToString(crypto.Keccak256([]byte(fullyQualifiedName)))
+	if reference == "__$"+hash[:libraryPlaceholderLength-6]+"$__" {
+		return true
+	}
+
+	// The legacy placeholder form contains the fully qualified name, truncated or padded with underscores.
+	legacyPlaceholder := "__" + fullyQualifiedName + strings.Repeat("_", libraryPlaceholderLength)
+	return reference == legacyPlaceholder[:libraryPlaceholderLength]
+}
+
+// References returns the references to every library in the LinkReferences, in sorted order.
+func (l LinkReferences) References() []string {
+	references := make([]string, 0, len(l))
+	for reference := range l {
+		references = append(references, reference)
+	}
+	sort.Strings(references)
+	return references
+}
+
+// Link substitutes the provided address of the library with the provided reference into the provided bytecode, at
+// every location the library is referenced.
+// Returns an error if a location lies outside the bytecode.
+func (l LinkReferences) Link(bytecode []byte, reference string, address common.Address) error {
+	for _, offset := range l[reference] {
+		if offset < 0 || offset+common.AddressLength > len(bytecode) {
+			return fmt.Errorf("the reference to library '%s' at offset %d lies outside the bytecode", reference, offset)
+		}
+		copy(bytecode[offset:], address.Bytes())
+	}
+	return nil
+}
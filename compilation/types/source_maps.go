@@ -0,0 +1,168 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Reference: Source mapping format
+// https://docs.soliditylang.org/en/latest/internals/source_mappings.html
+
+// SourceMap describes a list of elements which associate each instruction in compiled bytecode with a range in the
+// source code it was compiled from. Elements are ordered by instruction index, not by program counter.
+type SourceMap []SourceMapElement
+
+// SourceMapElement describes the source range an instruction in compiled bytecode was compiled from.
+type SourceMapElement struct {
+	// Offset describes the byte offset of the source range within the source file.
+	Offset int
+
+	// Length describes the byte length of the source range.
+	Length int
+
+	// SourceUnitID describes the identifier of the source file which contains the source range. This is
+	// negative for instructions which the compiler generated without associating them with a source file.
+	SourceUnitID int
+
+	// JumpType describes whether a jump instruction goes into a function ("i"), returns from a function ("o"), or
+	// is a regular jump as part of a loop or conditional ("-").
+	JumpType string
+
+	// ModifierDepth describes the depth of modifiers the instruction was executed within.
+	ModifierDepth int
+}
+
+// ParseSourceMap parses a compressed source map string as output by the compiler. Each element of a compressed
+// source map only specifies the fields which changed from the previous element.
+// Returns the parsed SourceMap, or an error if one occurs.
+func ParseSourceMap(sourceMap string) (SourceMap, error) {
+	// An empty source map has no elements.
+	if sourceMap == "" {
+		return SourceMap{}, nil
+	}
+
+	// Parse each element, starting with the fields of the previous element.
+	elements := strings.Split(sourceMap, ";")
+	parsed := make(SourceMap, 0, len(elements))
+	current := SourceMapElement{SourceUnitID: -1, JumpType: "-"}
+	for i, element := range elements {
+		for fieldIndex, field := range strings.Split(element, ":") {
+			if field == "" {
+				continue
+			}
+
+			// The jump type is the only non-integer field.
+			if fieldIndex == 3 {
+				current.JumpType = field
+				continue
+			}
+			value, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse source map element %d: %v", i, err)
+			}
+			switch fieldIndex {
+			case 0:
+				current.Offset = value
+			case 1:
+				current.Length = value
+			case 2:
+				current.SourceUnitID = value
+			case 4:
+				current.ModifierDepth = value
+			}
+		}
+		parsed = append(parsed, current)
+	}
+	return parsed, nil
+}
+
+// GetInstructionPCs obtains the program counter of each instruction in the provided bytecode, such that the index
+// of an instruction in the returned slice is the index of its SourceMap element.
+// Returns the program counters of each instruction in the bytecode.
+func GetInstructionPCs(bytecode []byte) []uint64 {
+	pcs := make([]uint64, 0, len(bytecode))
+	for pc := 0; pc < len(bytecode); pc++ {
+		pcs = append(pcs, uint64(pc))
+
+		// Skip over the data pushed by push instructions, as it is not executed.
+		op := vm.OpCode(bytecode[pc])
+		if op >= vm.PUSH1 && op <= vm.PUSH32 {
+			pc += int(op-vm.PUSH1) + 1
+		}
+	}
+	return pcs
+}
+
+// ElementAtPC obtains the element of the source map describing the instruction at the provided program counter in the
+// provided bytecode, which the source map was generated for.
+// Returns the source map element, or nil if no instruction begins at the program counter, or the source map does not
+// describe it.
+func (s SourceMap) ElementAtPC(bytecode []byte, pc uint64) *SourceMapElement {
+	instructionPCs := GetInstructionPCs(bytecode)
+	i := sort.Search(len(instructionPCs), func(i int) bool {
+		return instructionPCs[i] >= pc
+	})
+	if i >= len(instructionPCs) || instructionPCs[i] != pc || i >= len(s) {
+		return nil
+	}
+	return &s[i]
+}
+
+// SourceRange describes a range of a source file, as referenced by the "src" field of AST nodes.
+type SourceRange struct {
+	// Offset describes the byte offset of the range within the source file.
+	Offset int
+
+	// Length describes the byte length of the range.
+	Length int
+
+	// SourceUnitID describes the identifier of the source file which contains the range.
+	SourceUnitID int
+}
+
+// Contains indicates whether the provided source map element lies within the source range.
+func (r SourceRange) Contains(element SourceMapElement) bool {
+	return element.SourceUnitID == r.SourceUnitID && element.Offset >= r.Offset && element.Offset+element.Length <= r.Offset+r.Length
+}
+
+// parseSourceRange parses a source range of the form offset:length:sourceUnitID, as provided by the "src" field of
+// AST nodes.
+// Returns the parsed SourceRange, or an error if it is malformed.
+func parseSourceRange(src string) (SourceRange, error) {
+	srcFields := strings.Split(src, ":")
+	if len(srcFields) != 3 {
+		return SourceRange{}, fmt.Errorf("source range '%v' is malformed", src)
+	}
+	var values [3]int
+	for i, field := range srcFields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return SourceRange{}, fmt.Errorf("source range '%v' is malformed", src)
+		}
+		values[i] = value
+	}
+	return SourceRange{Offset: values[0], Length: values[1], SourceUnitID: values[2]}, nil
+}
+
+// GetSourceUnitID obtains the identifier source maps use to reference a source file, from its compiled AST.
+// Returns the source unit identifier, or an error if the AST does not describe it.
+func GetSourceUnitID(ast any) (int, error) {
+	// The source range of the root AST node references the source unit.
+	astDict, ok := ast.(map[string]any)
+	if !ok {
+		return 0, fmt.Errorf("could not obtain source unit ID because the AST is not a dictionary")
+	}
+	src, ok := astDict["src"].(string)
+	if !ok {
+		return 0, fmt.Errorf("could not obtain source unit ID because the AST does not describe its source range")
+	}
+	sourceRange, err := parseSourceRange(src)
+	if err != nil {
+		return 0, fmt.Errorf("could not obtain source unit ID: %v", err)
+	}
+	return sourceRange.SourceUnitID, nil
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSourceMapElementAtPC ensures source map elements are resolved by the program counter of their instruction,
+// skipping over the data of push instructions.
+func TestSourceMapElementAtPC(t *testing.T) {
+	// PUSH1 0x01, PUSH2 0x0203, ADD, STOP
+	bytecode := []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH2), 0x02, 0x03, byte(vm.ADD), byte(vm.STOP)}
+	sourceMap, err := ParseSourceMap("0:10:0:-;12:4;20:2:1:i")
+	assert.NoError(t, err)
+
+	element := sourceMap.ElementAtPC(bytecode, 2)
+	if assert.NotNil(t, element) {
+		assert.EqualValues(t, SourceMapElement{Offset: 12, Length: 4, SourceUnitID: 0, JumpType: "-"}, *element)
+	}
+	element = sourceMap.ElementAtPC(bytecode, 5)
+	if assert.NotNil(t, element) {
+		assert.EqualValues(t, 1, element.SourceUnitID)
+	}
+
+	// Program counters within push data, or of instructions the source map does not describe, have no element.
+	assert.Nil(t, sourceMap.ElementAtPC(bytecode, 3))
+	assert.Nil(t, sourceMap.ElementAtPC(bytecode, 6))
+	assert.Nil(t, sourceMap.ElementAtPC(bytecode, 100))
+}
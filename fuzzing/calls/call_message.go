@@ -3,6 +3,7 @@ package calls
 import (
 	"fmt"
 	"github.com/crytic/medusa/chain"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	coreTypes "github.com/ethereum/go-ethereum/core/types"
@@ -15,6 +16,19 @@ import (
 //go:generate go get github.com/fjl/gencodec
 //go:generate go run github.com/fjl/gencodec -type CallMessage -field-override callMessageMarshaling -out gen_call_message_json.go
 
+// AgentExecuteMethodSignature describes the signature of the method agent contracts must implement to forward calls
+// routed through them. It is called with the target, call data and value of the forwarded call.
+const AgentExecuteMethodSignature = "execute(address,bytes,uint256)"
+
+// agentExecuteMethod describes the agent contract method with the AgentExecuteMethodSignature.
+var agentExecuteMethod = func() abi.Method {
+	addressType, _ := abi.NewType("address", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	inputs := abi.Arguments{{Name: "target", Type: addressType}, {Name: "data", Type: bytesType}, {Name: "value", Type: uint256Type}}
+	return abi.NewMethod("execute", "execute", abi.Function, "payable", false, true, inputs, nil)
+}()
+
 // CallMessage implements Ethereum's coreTypes.Message, used to apply EVM/state updates.
 type CallMessage struct {
 	// MsgFrom represents a core.Message's from parameter (sender), indicating who sent a transaction/message to the
@@ -38,12 +52,14 @@ type CallMessage struct {
 	// of the message.
 	MsgGasPrice *big.Int `json:"gasPrice"`
 
-	// MsgGasFeeCap represents the maximum fee to enforce for gas related costs (related to 1559 transaction executed).
-	// The use of nil here indicates that the gas price oracle should be relied on instead.
+	// MsgGasFeeCap represents the maximum fee per gas (maxFeePerGas) the sender is willing to pay for an EIP-1559
+	// transaction, including the base fee. If non-zero, the message is executed as an EIP-1559 transaction, and
+	// MsgGasPrice is set to its effective gas price when it is executed (see SetEffectiveGasPrice). If zero, it is
+	// executed as a legacy transaction which does not pay a base fee.
 	MsgGasFeeCap *big.Int `json:"gasFeeCap"`
 
-	// MsgGasTipCap represents the fee cap to use for 1559 transaction. The use of nil here indicates that the gas price
-	// oracle should be relied on instead.
+	// MsgGasTipCap represents the maximum priority fee per gas (maxPriorityFeePerGas) the sender is willing to pay
+	// the block's coinbase for an EIP-1559 transaction, on top of the base fee.
 	MsgGasTipCap *big.Int `json:"gasTipCap"`
 
 	// MsgData represents the underlying message data to be sent to the receiver. If the receiver is a smart contract,
@@ -55,6 +71,11 @@ type CallMessage struct {
 	// contract, this will likely house your call parameters and other serialized data. This overrides MsgData if it is
 	// set, allowing Data to be sourced from method ABI input arguments instead.
 	MsgDataAbiValues *CallMessageDataAbiValues `json:"dataAbiValues,omitempty"`
+
+	// MsgAgent represents the address of an agent contract the message is routed through, or nil if it is sent
+	// directly to MsgTo. A routed message is sent to the agent, which forwards the call data and value to MsgTo
+	// through its AgentExecuteMethodSignature method, so that MsgTo is called by the agent rather than MsgFrom.
+	MsgAgent *common.Address `json:"agent,omitempty"`
 }
 
 // callMessageMarshaling is a structure that overrides field types during JSON marshaling. It allows CallMessage to
@@ -119,10 +140,34 @@ func (m *CallMessage) FillFromTestChainProperties(chain *chain.TestChain) {
 		m.MsgGasPrice = big.NewInt(1)
 	}
 
-	// Setting fee and tip cap to zero alongside the NoBaseFee for the vm.Config will bypass base fee validation.
-	// TODO: Set this appropriately for newer transaction types.
-	m.MsgGasFeeCap = big.NewInt(0)
-	m.MsgGasTipCap = big.NewInt(0)
+	// If fee and tip caps were not provided, we set them to zero, which alongside the NoBaseFee for the vm.Config will
+	// bypass base fee validation, executing the message as a legacy transaction.
+	if m.MsgGasFeeCap == nil {
+		m.MsgGasFeeCap = big.NewInt(0)
+	}
+	if m.MsgGasTipCap == nil {
+		m.MsgGasTipCap = big.NewInt(0)
+	}
+}
+
+// IsDynamicFee indicates whether the message is executed as an EIP-1559 (dynamic fee) transaction, as it specifies a
+// non-zero gas fee cap.
+func (m *CallMessage) IsDynamicFee() bool {
+	return m.MsgGasFeeCap != nil && m.MsgGasFeeCap.Sign() > 0
+}
+
+// SetEffectiveGasPrice sets the gas price of an EIP-1559 message to the price it pays for each unit of gas in a block
+// with the provided base fee: the base fee plus its tip cap, limited to its fee cap. This is the gas price observed by
+// contracts through tx.gasprice. Legacy messages are left unchanged.
+func (m *CallMessage) SetEffectiveGasPrice(baseFee *big.Int) {
+	if !m.IsDynamicFee() || baseFee == nil {
+		return
+	}
+	gasPrice := new(big.Int).Add(baseFee, m.MsgGasTipCap)
+	if gasPrice.Cmp(m.MsgGasFeeCap) > 0 {
+		gasPrice.Set(m.MsgGasFeeCap)
+	}
+	m.MsgGasPrice = gasPrice
 }
 
 func (m *CallMessage) From() common.Address { return m.MsgFrom }
@@ -149,6 +194,36 @@ func (m *CallMessage) Data() []byte {
 func (m *CallMessage) AccessList() coreTypes.AccessList { return nil }
 func (m *CallMessage) IsFake() bool                     { return true }
 
+// ExecutableMessage obtains the message to execute in order to perform this call. If the message is routed through an
+// agent contract, this is a message to the agent which forwards the call. Otherwise, it is the message itself.
+// The returned message should not be modified, as it may be the message itself.
+func (m *CallMessage) ExecutableMessage() *CallMessage {
+	if m.MsgAgent == nil || m.MsgTo == nil {
+		return m
+	}
+
+	// Pack our call into a call to the agent's execute method.
+	value := m.MsgValue
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	args, err := agentExecuteMethod.Inputs.Pack(*m.MsgTo, m.Data(), value)
+	if err != nil {
+		panic(fmt.Errorf("error while packing call message routed through agent: %v", err))
+	}
+	return &CallMessage{
+		MsgFrom:      m.MsgFrom,
+		MsgTo:        m.MsgAgent,
+		MsgNonce:     m.MsgNonce,
+		MsgValue:     m.MsgValue,
+		MsgGas:       m.MsgGas,
+		MsgGasPrice:  m.MsgGasPrice,
+		MsgGasFeeCap: m.MsgGasFeeCap,
+		MsgGasTipCap: m.MsgGasTipCap,
+		MsgData:      append(slices.Clone(agentExecuteMethod.ID), args...),
+	}
+}
+
 // Clone creates a copy of the given message and its underlying components, or an error if one occurs.
 func (m *CallMessage) Clone() (*CallMessage, error) {
 	// Clone our underlying ABI values data if we have any.
@@ -169,6 +244,7 @@ func (m *CallMessage) Clone() (*CallMessage, error) {
 		MsgGasTipCap:     new(big.Int).Set(m.MsgGasTipCap),
 		MsgData:          slices.Clone(m.MsgData),
 		MsgDataAbiValues: clonedAbiValues,
+		MsgAgent:         m.MsgAgent, // this value should be read-only, so we re-use it rather than cloning.
 	}
 	return clone, nil
 }
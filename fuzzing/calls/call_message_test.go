@@ -0,0 +1,68 @@
+package calls
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCallMessageExecutableMessage ensures calls routed through an agent contract are executed as a call to the agent
+// which forwards the original call data and value, while other calls are executed as they are.
+func TestCallMessageExecutableMessage(t *testing.T) {
+	sender := common.HexToAddress("0x10000")
+	target := common.HexToAddress("0x20000")
+	agent := common.HexToAddress("0x30000")
+	call := NewCallMessage(sender, &target, 3, big.NewInt(7), 100_000, big.NewInt(1), nil, nil, []byte{0x01, 0x02, 0x03, 0x04})
+
+	// Calls which are not routed through an agent are executed directly.
+	assert.Same(t, call, call.ExecutableMessage())
+
+	// Calls routed through an agent are sent to it, with the same sender, nonce, value and gas.
+	call.MsgAgent = &agent
+	routed := call.ExecutableMessage()
+	assert.EqualValues(t, agent, *routed.To())
+	assert.EqualValues(t, sender, routed.From())
+	assert.EqualValues(t, 3, routed.Nonce())
+	assert.EqualValues(t, big.NewInt(7), routed.Value())
+	assert.EqualValues(t, 100_000, routed.Gas())
+
+	// The agent is called with the original target, call data and value.
+	assert.EqualValues(t, agentExecuteMethod.ID, routed.Data()[:4])
+	args, err := agentExecuteMethod.Inputs.Unpack(routed.Data()[4:])
+	assert.NoError(t, err)
+	assert.EqualValues(t, []any{target, []byte{0x01, 0x02, 0x03, 0x04}, big.NewInt(7)}, args)
+
+	// The original call is left unchanged.
+	assert.EqualValues(t, target, *call.To())
+	assert.EqualValues(t, []byte{0x01, 0x02, 0x03, 0x04}, call.Data())
+}
+
+// TestCallMessageSetEffectiveGasPrice ensures EIP-1559 calls are priced at the base fee plus their tip cap, limited
+// to their fee cap, and are executed as dynamic fee transactions, while legacy calls keep their gas price.
+func TestCallMessageSetEffectiveGasPrice(t *testing.T) {
+	sender := common.HexToAddress("0x10000")
+	target := common.HexToAddress("0x20000")
+
+	// Legacy calls are left unchanged.
+	legacyCall := NewCallMessage(sender, &target, 0, big.NewInt(0), 100_000, big.NewInt(1), big.NewInt(0), big.NewInt(0), nil)
+	assert.False(t, legacyCall.IsDynamicFee())
+	legacyCall.SetEffectiveGasPrice(big.NewInt(1000))
+	assert.EqualValues(t, big.NewInt(1), legacyCall.MsgGasPrice)
+	assert.EqualValues(t, types.LegacyTxType, utils.MessageToTransaction(legacyCall).Type())
+
+	// EIP-1559 calls pay the base fee plus their tip, unless this exceeds their fee cap.
+	dynamicFeeCall := NewCallMessage(sender, &target, 0, big.NewInt(0), 100_000, nil, big.NewInt(1050), big.NewInt(20), nil)
+	assert.True(t, dynamicFeeCall.IsDynamicFee())
+	dynamicFeeCall.SetEffectiveGasPrice(big.NewInt(1000))
+	assert.EqualValues(t, big.NewInt(1020), dynamicFeeCall.MsgGasPrice)
+	dynamicFeeCall.SetEffectiveGasPrice(big.NewInt(1040))
+	assert.EqualValues(t, big.NewInt(1050), dynamicFeeCall.MsgGasPrice)
+	tx := utils.MessageToTransaction(dynamicFeeCall)
+	assert.EqualValues(t, types.DynamicFeeTxType, tx.Type())
+	assert.EqualValues(t, big.NewInt(1050), tx.GasFeeCap())
+	assert.EqualValues(t, big.NewInt(20), tx.GasTipCap())
+}
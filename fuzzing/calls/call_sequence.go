@@ -22,11 +22,12 @@ type CallSequence []*CallSequenceElement
 
 // AttachExecutionTraces takes a given chain which executed the call sequence, and a list of contract definitions,
 // and it replays each call of the sequence with an execution tracer attached to it, it then sets each
-// CallSequenceElement.ExecutionTrace to the resulting trace. Returns an error if one occurred.
-func (cs CallSequence) AttachExecutionTraces(chain *chain.TestChain, contractDefinitions fuzzingTypes.Contracts) error {
+// CallSequenceElement.ExecutionTrace to the resulting trace. If traceStorageWrites is true, the traces also record
+// storage writes. Returns an error if one occurred.
+func (cs CallSequence) AttachExecutionTraces(chain *chain.TestChain, contractDefinitions fuzzingTypes.Contracts, traceStorageWrites bool) error {
 	// For each call sequence element, attach an execution trace.
 	for _, cse := range cs {
-		err := cse.AttachExecutionTrace(chain, contractDefinitions)
+		err := cse.AttachExecutionTrace(chain, contractDefinitions, traceStorageWrites)
 		if err != nil {
 			return err
 		}
@@ -109,7 +110,7 @@ func (cs CallSequence) Hash() (common.Hash, error) {
 
 			// Try to obtain a hash for the message/call. If this fails, we will replace it in the deferred panic
 			// recovery.
-			messageHashData = utils.MessageToTransaction(cse.Call).Hash().Bytes()
+			messageHashData = utils.MessageToTransaction(cse.Call.ExecutableMessage()).Hash().Bytes()
 		}()
 
 		// Hash the message hash data.
@@ -154,6 +155,10 @@ type CallSequenceElement struct {
 
 	// ExecutionTrace represents a verbose execution trace collected. Nil if an execution trace was not collected.
 	ExecutionTrace *executiontracer.ExecutionTrace `json:"-"`
+
+	// SenderRole describes the role of the sender of the Call, which is displayed alongside it. Empty if the sender
+	// has no role.
+	SenderRole string `json:"-"`
 }
 
 // NewCallSequenceElement returns a new CallSequenceElement struct to track a single call made within a CallSequence.
@@ -185,6 +190,7 @@ func (cse *CallSequenceElement) Clone() (*CallSequenceElement, error) {
 		BlockTimestampDelay: cse.BlockTimestampDelay,
 		ChainReference:      cse.ChainReference,
 		ExecutionTrace:      cse.ExecutionTrace,
+		SenderRole:          cse.SenderRole,
 	}
 	return clone, nil
 }
@@ -232,6 +238,17 @@ func (cse *CallSequenceElement) String() string {
 		blockTimeStr = strconv.FormatUint(cse.ChainReference.Block.Header.Time, 10)
 	}
 
+	// If the sender has a role, display it alongside the sender.
+	senderStr := cse.Call.From().String()
+	if cse.SenderRole != "" {
+		senderStr = fmt.Sprintf("%s (%s)", senderStr, cse.SenderRole)
+	}
+
+	// If the call was routed through an agent contract, display the agent which made the call.
+	if cse.Call.MsgAgent != nil {
+		senderStr = fmt.Sprintf("%s via agent %s", senderStr, cse.Call.MsgAgent.String())
+	}
+
 	// Return a formatted string representing this element.
 	return fmt.Sprintf(
 		"%s.%s(%s) (block=%s, time=%s, gas=%d, gasprice=%s, value=%s, sender=%s)",
@@ -243,15 +260,15 @@ func (cse *CallSequenceElement) String() string {
 		cse.Call.Gas(),
 		cse.Call.GasPrice().String(),
 		cse.Call.Value().String(),
-		cse.Call.From(),
+		senderStr,
 	)
 }
 
 // AttachExecutionTrace takes a given chain which executed the call sequence element, and a list of contract definitions,
 // and it replays the call with an execution tracer attached to it, it then sets CallSequenceElement.ExecutionTrace to
-// the resulting trace.
+// the resulting trace. If traceStorageWrites is true, the trace also records storage writes.
 // Returns an error if one occurred.
-func (cse *CallSequenceElement) AttachExecutionTrace(chain *chain.TestChain, contractDefinitions fuzzingTypes.Contracts) error {
+func (cse *CallSequenceElement) AttachExecutionTrace(chain *chain.TestChain, contractDefinitions fuzzingTypes.Contracts, traceStorageWrites bool) error {
 	// Verify the element has been executed before.
 	if cse.ChainReference == nil {
 		return fmt.Errorf("failed to resolve execution trace as the chain reference is nil, indicating the call sequence element has never been executed")
@@ -264,7 +281,7 @@ func (cse *CallSequenceElement) AttachExecutionTrace(chain *chain.TestChain, con
 	}
 
 	// Perform our call with the given trace
-	_, cse.ExecutionTrace, err = executiontracer.CallWithExecutionTrace(chain, contractDefinitions, cse.Call, state)
+	_, cse.ExecutionTrace, err = executiontracer.CallWithExecutionTrace(chain, contractDefinitions, cse.Call.ExecutableMessage(), state, traceStorageWrites)
 	if err != nil {
 		return fmt.Errorf("failed to resolve execution trace due to error replaying the call: %v", err)
 	}
@@ -0,0 +1,85 @@
+package calls
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// canonicalHashDomain describes the prefix hashed before any call sequence elements by CanonicalHash. It identifies
+// the normalization used, and must change if the normalization ever does.
+const canonicalHashDomain = "medusa-call-sequence-canonical-v1"
+
+// CanonicalHash calculates a hash of the call sequence which identifies it by the behavior it describes, rather than
+// by its serialized form, such that call sequences which send the same calls from the same senders with the same
+// delays produce the same hash. The normalization is fixed, so hashes remain stable across versions of the fuzzer.
+//
+// The hash is the Keccak256 hash of the canonicalHashDomain, followed by the following fields for each element:
+//   - the 20 byte sender address
+//   - a single byte, 1 if the call has a target, followed by the 20 byte target address, or 0 if it deploys a contract
+//   - if the call is routed through an agent contract, a single byte 2, followed by the 20 byte agent address
+//   - the 32 byte big-endian value sent
+//   - the 8 byte big-endian block number delay
+//   - the 8 byte big-endian block timestamp delay
+//   - the 8 byte big-endian length of the call data, followed by the call data (with ABI values packed)
+//
+// Nonces, gas limits and gas prices are excluded, as they are refilled from the chain when a call sequence is
+// executed, as are the blocks a call sequence was executed in.
+// Returns the calculated hash, or an error if the call data of an element could not be obtained (e.g. its ABI values
+// have not been resolved to a method).
+func (cs CallSequence) CanonicalHash() (common.Hash, error) {
+	hashProvider := crypto.NewKeccakState()
+	hashProvider.Write([]byte(canonicalHashDomain))
+
+	var temp [8]byte
+	for i, cse := range cs {
+		if cse == nil || cse.Call == nil {
+			return common.Hash{}, fmt.Errorf("could not calculate canonical hash, call sequence element %d has no call", i)
+		}
+
+		// Hash our sender and target.
+		hashProvider.Write(cse.Call.MsgFrom.Bytes())
+		if cse.Call.MsgTo != nil {
+			hashProvider.Write([]byte{1})
+			hashProvider.Write(cse.Call.MsgTo.Bytes())
+		} else {
+			hashProvider.Write([]byte{0})
+		}
+
+		// Hash the agent contract the call is routed through, if any.
+		if cse.Call.MsgAgent != nil {
+			hashProvider.Write([]byte{2})
+			hashProvider.Write(cse.Call.MsgAgent.Bytes())
+		}
+
+		// Hash our value.
+		value := cse.Call.MsgValue
+		if value == nil {
+			value = big.NewInt(0)
+		}
+		hashProvider.Write(common.BigToHash(value).Bytes())
+
+		// Hash our delays.
+		binary.BigEndian.PutUint64(temp[:], cse.BlockNumberDelay)
+		hashProvider.Write(temp[:])
+		binary.BigEndian.PutUint64(temp[:], cse.BlockTimestampDelay)
+		hashProvider.Write(temp[:])
+
+		// Hash our call data, packing any ABI values.
+		data := cse.Call.MsgData
+		if cse.Call.MsgDataAbiValues != nil {
+			var err error
+			data, err = cse.Call.MsgDataAbiValues.Pack()
+			if err != nil {
+				return common.Hash{}, fmt.Errorf("could not calculate canonical hash, call sequence element %d could not be packed: %v", i, err)
+			}
+		}
+		binary.BigEndian.PutUint64(temp[:], uint64(len(data)))
+		hashProvider.Write(temp[:])
+		hashProvider.Write(data)
+	}
+	return common.BytesToHash(hashProvider.Sum(nil)), nil
+}
@@ -0,0 +1,66 @@
+package calls
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCallSequenceCanonicalHash ensures canonical hashes ignore volatile fields such as nonces, gas and chain
+// references, distinguish the fields which describe the behavior of a call sequence, and remain stable across versions.
+func TestCallSequenceCanonicalHash(t *testing.T) {
+	// Create a method and a call sequence calling it with ABI values.
+	uint256Type, err := abi.NewType("uint256", "", nil)
+	assert.NoError(t, err)
+	method := abi.NewMethod("set", "set", abi.Function, "", false, false, abi.Arguments{{Name: "x", Type: uint256Type}}, nil)
+	sender := common.HexToAddress("0x10000")
+	target := common.HexToAddress("0x20000")
+	newCallSequence := func(nonce uint64, gas uint64, x int64, blockNumberDelay uint64) CallSequence {
+		call := NewCallMessageWithAbiValueData(sender, &target, nonce, big.NewInt(5), gas, big.NewInt(1), big.NewInt(1), big.NewInt(1), &CallMessageDataAbiValues{
+			Method:      &method,
+			InputValues: []any{big.NewInt(x)},
+		})
+		return CallSequence{NewCallSequenceElement(nil, call, blockNumberDelay, 10)}
+	}
+	hash, err := newCallSequence(0, 30000000, 7, 1).CanonicalHash()
+	assert.NoError(t, err)
+
+	// The hash must be stable across versions, so it is fixed here. If this changes, corpus deduplication and lineage
+	// break for existing corpora, so the canonical hash domain must be changed with it.
+	assert.EqualValues(t, common.HexToHash("0x3aec327218def80852e44e1b28de00de9ffb4fa3335d1bef262a80d99db2ec96"), hash)
+
+	// Nonces and gas should not affect the hash.
+	otherHash, err := newCallSequence(9, 12345, 7, 1).CanonicalHash()
+	assert.NoError(t, err)
+	assert.EqualValues(t, hash, otherHash)
+
+	// Raw call data equal to the packed ABI values should produce the same hash.
+	rawSequence := newCallSequence(0, 30000000, 7, 1)
+	rawSequence[0].Call.MsgData = rawSequence[0].Call.Data()
+	rawSequence[0].Call.MsgDataAbiValues = nil
+	otherHash, err = rawSequence.CanonicalHash()
+	assert.NoError(t, err)
+	assert.EqualValues(t, hash, otherHash)
+
+	// Arguments, delays and senders should affect the hash.
+	otherHash, err = newCallSequence(0, 30000000, 8, 1).CanonicalHash()
+	assert.NoError(t, err)
+	assert.NotEqualValues(t, hash, otherHash)
+	otherHash, err = newCallSequence(0, 30000000, 7, 2).CanonicalHash()
+	assert.NoError(t, err)
+	assert.NotEqualValues(t, hash, otherHash)
+	otherSenderSequence := newCallSequence(0, 30000000, 7, 1)
+	otherSenderSequence[0].Call.MsgFrom = common.HexToAddress("0x30000")
+	otherHash, err = otherSenderSequence.CanonicalHash()
+	assert.NoError(t, err)
+	assert.NotEqualValues(t, hash, otherHash)
+
+	// ABI values which were not resolved to a method cannot be hashed.
+	unresolvedSequence := newCallSequence(0, 30000000, 7, 1)
+	unresolvedSequence[0].Call.MsgDataAbiValues.Method = nil
+	_, err = unresolvedSequence.CanonicalHash()
+	assert.Error(t, err)
+}
@@ -1,8 +1,11 @@
 package calls
 
 import (
+	"errors"
 	"fmt"
+
 	"github.com/crytic/medusa/chain"
+	"github.com/ethereum/go-ethereum/core"
 )
 
 // ExecuteCallSequenceFetchElementFunc describes a function that is called to obtain the next call sequence element to
@@ -21,6 +24,10 @@ type ExecuteCallSequenceExecutionCheckFunc func(currentExecutedSequence CallSequ
 // A "fetch next call" function is provided to fetch the next element to execute.
 // A "post element executed check" function is provided to check whether execution should stop after each element is
 // executed.
+// Calls whose sender cannot afford the value they send (and the gas they may use), whose gas limit does not cover
+// their intrinsic gas, or whose EIP-1559 fee cap does not cover the base fee of the block they are executed in, are
+// rejected by the chain before execution. They are skipped rather than treated as an error, and are not included in
+// the executed call sequence.
 // Returns the call sequence which was executed and an error if one occurs.
 func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc ExecuteCallSequenceFetchElementFunc, executionCheckFunc ExecuteCallSequenceExecutionCheckFunc) (CallSequence, error) {
 	// If there is no fetch element function provided, throw an error
@@ -51,6 +58,7 @@ func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc Exe
 		// block gas limit, which we handle by committing the pending block without this tx, and creating a new pending
 		// block that is empty to try adding this tx there instead.
 		// If we encounter an error on an empty block, we throw the error as there is nothing more we can do.
+		skipped := false
 		for {
 			// If we have a pending block, but we intend to delay this call from the last, we commit that block.
 			if chain.PendingBlock() != nil && callSequenceElement.BlockNumberDelay > 0 {
@@ -83,9 +91,18 @@ func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc Exe
 				}
 			}
 
-			// Try to add our transaction to this block.
-			err = chain.PendingBlockAddTx(callSequenceElement.Call)
+			// Try to add our transaction to this block, at the gas price it pays given the block's base fee.
+			callSequenceElement.Call.SetEffectiveGasPrice(chain.PendingBlock().Header.BaseFee)
+			err = chain.PendingBlockAddTx(callSequenceElement.Call.ExecutableMessage())
 			if err != nil {
+				// If the sender could not afford to send this tx, it does not provide the gas it requires before
+				// execution, or its fee cap does not cover the block's base fee, the block would not accept it, so we
+				// skip it.
+				if errors.Is(err, core.ErrInsufficientFunds) || errors.Is(err, core.ErrInsufficientFundsForTransfer) || errors.Is(err, core.ErrIntrinsicGas) || errors.Is(err, core.ErrFeeCapTooLow) {
+					skipped = true
+					break
+				}
+
 				// If we encountered a block gas limit error, this tx is too expensive to fit in this block.
 				// If there are other transactions in the block, this makes sense. The block is "full".
 				// In that case, we commit the pending block without this tx, and create a new pending block to add
@@ -133,6 +150,11 @@ func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc Exe
 			break
 		}
 
+		// If this tx was skipped, we move onto the next element without checking it, as it was never executed.
+		if skipped {
+			continue
+		}
+
 		// If post-execution check requested we break execution, break out of our "execute next call sequence loop"
 		if execCheckFuncRequestedBreak {
 			break
@@ -151,7 +173,8 @@ func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc Exe
 
 // ExecuteCallSequence executes a provided CallSequence on the provided chain.
 // It returns the slice of the call sequence which was tested, and an error if one occurred.
-// If no error occurred, it can be expected that the returned call sequence contains all elements originally provided.
+// If no error occurred, it can be expected that the returned call sequence contains all elements originally provided,
+// other than those skipped because the chain rejected them before execution.
 func ExecuteCallSequence(chain *chain.TestChain, callSequence CallSequence) (CallSequence, error) {
 	// Execute our sequence with a simple fetch operation provided to obtain each element.
 	fetchElementFunc := func(currentIndex int) (*CallSequenceElement, error) {
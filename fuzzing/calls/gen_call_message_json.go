@@ -25,6 +25,7 @@ func (c CallMessage) MarshalJSON() ([]byte, error) {
 		MsgGasTipCap     *hexutil.Big              `json:"gasTipCap"`
 		MsgData          hexutil.Bytes             `json:"data,omitempty"`
 		MsgDataAbiValues *CallMessageDataAbiValues `json:"dataAbiValues,omitempty"`
+		MsgAgent         *common.Address           `json:"agent,omitempty"`
 	}
 	var enc CallMessage
 	enc.MsgFrom = c.MsgFrom
@@ -37,6 +38,7 @@ func (c CallMessage) MarshalJSON() ([]byte, error) {
 	enc.MsgGasTipCap = (*hexutil.Big)(c.MsgGasTipCap)
 	enc.MsgData = c.MsgData
 	enc.MsgDataAbiValues = c.MsgDataAbiValues
+	enc.MsgAgent = c.MsgAgent
 	return json.Marshal(&enc)
 }
 
@@ -53,6 +55,7 @@ func (c *CallMessage) UnmarshalJSON(input []byte) error {
 		MsgGasTipCap     *hexutil.Big              `json:"gasTipCap"`
 		MsgData          *hexutil.Bytes            `json:"data,omitempty"`
 		MsgDataAbiValues *CallMessageDataAbiValues `json:"dataAbiValues,omitempty"`
+		MsgAgent         *common.Address           `json:"agent,omitempty"`
 	}
 	var dec CallMessage
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -88,5 +91,8 @@ func (c *CallMessage) UnmarshalJSON(input []byte) error {
 	if dec.MsgDataAbiValues != nil {
 		c.MsgDataAbiValues = dec.MsgDataAbiValues
 	}
+	if dec.MsgAgent != nil {
+		c.MsgAgent = dec.MsgAgent
+	}
 	return nil
 }
@@ -0,0 +1,147 @@
+package comparisontracer
+
+import (
+	"math/big"
+
+	"github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// comparisonTracerResultsKey describes the key to use when storing tracer results in call message results, or when
+// querying them.
+const comparisonTracerResultsKey = "ComparisonTracerResults"
+
+// maxOperandsPerTransaction describes the maximum amount of unique operands a ComparisonTracer records for a single
+// transaction, to bound the overhead of transactions which execute many comparisons (e.g. loops).
+const maxOperandsPerTransaction = 256
+
+// ComparisonOperand describes an operand of a comparison instruction executed during a transaction.
+type ComparisonOperand struct {
+	// Value describes the 256-bit word which was compared.
+	Value *uint256.Int
+
+	// Signed describes whether the operand was compared as a signed integer (SLT or SGT).
+	Signed bool
+}
+
+// GetComparisonTracerResults obtains the comparison operands stored by a ComparisonTracer from message results. This
+// is nil if no operands were recorded by a tracer (e.g. ComparisonTracer was not attached during this message
+// execution).
+func GetComparisonTracerResults(messageResults *types.MessageResults) []ComparisonOperand {
+	// Try to obtain the results the tracer should've stored.
+	if genericResult, ok := messageResults.AdditionalResults[comparisonTracerResultsKey]; ok {
+		if castedResult, ok := genericResult.([]ComparisonOperand); ok {
+			return castedResult
+		}
+	}
+
+	// If we could not obtain them, return nil.
+	return nil
+}
+
+// RemoveComparisonTracerResults removes the comparison operands stored by a ComparisonTracer from message results.
+func RemoveComparisonTracerResults(messageResults *types.MessageResults) {
+	delete(messageResults.AdditionalResults, comparisonTracerResultsKey)
+}
+
+// comparisonOperandKey describes the comparable form of a ComparisonOperand, used to de-duplicate operands.
+type comparisonOperandKey struct {
+	// Value describes the 256-bit word which was compared, as a fixed size array.
+	Value [32]byte
+
+	// Signed describes whether the operand was compared as a signed integer.
+	Signed bool
+}
+
+// ComparisonTracer implements vm.EVMLogger to collect the operands of comparison instructions (EQ, LT, GT, SLT and
+// SGT) executed during a transaction. Values a contract compares its inputs against, such as magic values computed
+// at runtime, can then be provided as inputs in later calls.
+type ComparisonTracer struct {
+	// operands describes the unique operands recorded for the current transaction, in the order they were recorded.
+	operands []ComparisonOperand
+
+	// operandSet describes the operands recorded for the current transaction, used to de-duplicate them.
+	operandSet map[comparisonOperandKey]struct{}
+}
+
+// NewComparisonTracer returns a new ComparisonTracer.
+func NewComparisonTracer() *ComparisonTracer {
+	tracer := &ComparisonTracer{}
+	tracer.CaptureTxStart(0)
+	return tracer
+}
+
+// CaptureTxStart is called upon the start of transaction execution, as defined by vm.EVMLogger.
+func (t *ComparisonTracer) CaptureTxStart(gasLimit uint64) {
+	// Reset our capture state
+	t.operands = make([]ComparisonOperand, 0)
+	t.operandSet = make(map[comparisonOperandKey]struct{})
+}
+
+// CaptureTxEnd is called upon the end of transaction execution, as defined by vm.EVMLogger.
+func (t *ComparisonTracer) CaptureTxEnd(restGas uint64) {
+}
+
+// CaptureStart initializes the tracing operation for the top of a call frame, as defined by vm.EVMLogger.
+func (t *ComparisonTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureEnd is called after a call to finalize tracing completes for the top of a call frame, as defined by vm.EVMLogger.
+func (t *ComparisonTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureEnter is called upon entering of the call frame, as defined by vm.EVMLogger.
+func (t *ComparisonTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit is called upon exiting of the call frame, as defined by vm.EVMLogger.
+func (t *ComparisonTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureState records data from an EVM state update, as defined by vm.EVMLogger.
+func (t *ComparisonTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, vmErr error) {
+	// We only record the operands of comparison instructions, while we have not yet recorded too many.
+	var signed bool
+	switch op {
+	case vm.EQ, vm.LT, vm.GT:
+		signed = false
+	case vm.SLT, vm.SGT:
+		signed = true
+	default:
+		return
+	}
+	if len(t.operands) >= maxOperandsPerTransaction || len(scope.Stack.Data()) < 2 {
+		return
+	}
+
+	// Record both operands, as we cannot tell which of them is the constant being compared against. Comparisons of
+	// equal values or zero tell us nothing new, so they are skipped.
+	x, y := scope.Stack.Back(0), scope.Stack.Back(1)
+	if x.Eq(y) {
+		return
+	}
+	for _, operand := range []*uint256.Int{x, y} {
+		if operand.IsZero() {
+			continue
+		}
+		key := comparisonOperandKey{Value: operand.Bytes32(), Signed: signed}
+		if _, exists := t.operandSet[key]; !exists {
+			t.operandSet[key] = struct{}{}
+			t.operands = append(t.operands, ComparisonOperand{Value: new(uint256.Int).Set(operand), Signed: signed})
+		}
+	}
+}
+
+// CaptureFault records an execution fault, as defined by vm.EVMLogger.
+func (t *ComparisonTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureTxEndSetAdditionalResults can be used to set additional results captured from execution tracing. If this
+// tracer is used during transaction execution (block creation), the results can later be queried from the block.
+// This method will only be called on the added tracer if it implements the extended TestChainTracer interface.
+func (t *ComparisonTracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
+	// Store our tracer results.
+	results.AdditionalResults[comparisonTracerResultsKey] = t.operands
+}
@@ -0,0 +1,41 @@
+package comparisontracer
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestComparisonTracerOperands ensures the comparison tracer records the unique, non-zero operands of comparisons of
+// differing values, noting whether they were compared as signed integers.
+func TestComparisonTracerOperands(t *testing.T) {
+	negativeTwo := new(uint256.Int).Neg(uint256.NewInt(2))
+	negativeTwoBytes := negativeTwo.Bytes32()
+	code := []byte{
+		byte(vm.PUSH1), 0x05, byte(vm.PUSH1), 0x07, byte(vm.EQ), byte(vm.POP), // 7 == 5
+		byte(vm.PUSH1), 0x05, byte(vm.PUSH1), 0x07, byte(vm.GT), byte(vm.POP), // 7 > 5, already recorded
+		byte(vm.PUSH1), 0x03, byte(vm.PUSH1), 0x03, byte(vm.EQ), byte(vm.POP), // 3 == 3, equal values are skipped
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x09, byte(vm.LT), byte(vm.POP), // 9 < 0, zero is skipped
+		byte(vm.PUSH1), 0x05, byte(vm.PUSH32), // -2 s< 5
+	}
+	code = append(code, negativeTwoBytes[:]...)
+	code = append(code, byte(vm.SLT), byte(vm.POP), byte(vm.STOP))
+
+	tracer := NewComparisonTracer()
+	_, _, err := runtime.Execute(code, nil, &runtime.Config{EVMConfig: vm.Config{Debug: true, Tracer: tracer}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []ComparisonOperand{
+		{Value: uint256.NewInt(7), Signed: false},
+		{Value: uint256.NewInt(5), Signed: false},
+		{Value: uint256.NewInt(9), Signed: false},
+		{Value: negativeTwo, Signed: true},
+		{Value: uint256.NewInt(5), Signed: true},
+	}, tracer.operands)
+
+	// Starting a new transaction should reset the recorded operands.
+	tracer.CaptureTxStart(0)
+	assert.Empty(t, tracer.operands)
+}
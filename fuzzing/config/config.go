@@ -1,14 +1,25 @@
 package config
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/crytic/medusa/chain/config"
+	"math/big"
+	"net"
+	"net/url"
 	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/crytic/medusa/compilation"
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/logging"
 	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 type ProjectConfig struct {
@@ -17,6 +28,83 @@ type ProjectConfig struct {
 
 	// Compilation describes the configuration used to compile the underlying project.
 	Compilation *compilation.CompilationConfig `json:"compilation"`
+
+	// Metrics describes the configuration used to expose live metrics of fuzzing campaigns.
+	Metrics MetricsConfig `json:"metrics"`
+
+	// Logging describes the configuration used to log the events of fuzzing campaigns.
+	Logging LoggingConfig `json:"logging"`
+
+	// Notifications describes the configuration used to notify webhooks of tests failed in fuzzing campaigns.
+	Notifications NotificationsConfig `json:"notifications"`
+}
+
+// NotificationsConfig describes the configuration options used to notify webhooks of test cases a fuzzing.Fuzzer
+// failed, so long-running campaigns need not be watched.
+type NotificationsConfig struct {
+	// WebhookURLs describes the URLs notifications are POSTed to each time a test case fails, once its call sequence
+	// was shrunk. If empty, no notifications are sent.
+	WebhookURLs []string `json:"webhookUrls"`
+
+	// Format describes the format of the notifications: "json" for a JSON payload describing the failed test case,
+	// its call sequence and the campaign, or "slack" for a Slack-compatible payload holding only a message.
+	Format NotificationFormat `json:"format"`
+
+	// Template describes a Go text/template the message of a notification is rendered from, given the JSON payload's
+	// fields (e.g. {{.TestCase}} or {{.Campaign.Seed}}). If empty, a default message is used.
+	Template string `json:"template"`
+
+	// Timeout describes the maximum amount of time, in seconds, a single attempt to deliver a notification may take.
+	Timeout uint64 `json:"timeout"`
+
+	// Retries describes the amount of times delivering a notification to a webhook is retried if an attempt fails.
+	Retries uint64 `json:"retries"`
+}
+
+// NotificationFormat describes the format notifications are sent to webhooks in.
+type NotificationFormat string
+
+const (
+	// NotificationFormatJSON indicates notifications are sent as a JSON payload describing the failed test case, its
+	// call sequence and the campaign, along with a rendered message.
+	NotificationFormatJSON NotificationFormat = "json"
+
+	// NotificationFormatSlack indicates notifications are sent as a Slack-compatible payload holding only a rendered
+	// message.
+	NotificationFormatSlack NotificationFormat = "slack"
+)
+
+// IsValid indicates whether the NotificationFormat is one of the known notification formats.
+func (f NotificationFormat) IsValid() bool {
+	return f == NotificationFormatJSON || f == NotificationFormatSlack
+}
+
+// LoggingConfig describes the configuration options used to log the events of a fuzzing.Fuzzer.
+type LoggingConfig struct {
+	// Format describes the format events are logged to standard output in: "text" for plain messages, or "json" for
+	// one JSON object per event, holding its level, timestamp, message and structured fields.
+	Format logging.LogFormat `json:"format"`
+
+	// Debug describes whether debug events, such as the messages contracts log through console.log, should be logged.
+	Debug bool `json:"debug"`
+
+	// TUI describes whether campaigns should be shown on an interactive status screen rather than as scrolling log
+	// output, with logs shown in a pane of the screen. If standard output is not a terminal, logs are written as usual.
+	TUI bool `json:"tui"`
+}
+
+// MetricsConfig describes the configuration options used to expose live metrics of a fuzzing.Fuzzer over HTTP, and
+// to report metrics which indicate a broken harness.
+type MetricsConfig struct {
+	// Address describes the TCP address (e.g. "localhost:9090") the metrics server listens on while a fuzzing
+	// campaign runs, serving Prometheus metrics at "/metrics" and a JSON status at "/status". If empty, no metrics
+	// server is started.
+	Address string `json:"address"`
+
+	// RevertRateWarningThreshold describes the fraction of calls to a method (between 0 and 1) which must revert for
+	// a warning to be logged periodically and when the campaign ends, listing the method's most common revert
+	// reasons. If zero, no such warnings are logged.
+	RevertRateWarningThreshold float64 `json:"revertRateWarningThreshold"`
 }
 
 // FuzzingConfig describes the configuration options used by the fuzzing.Fuzzer.
@@ -28,6 +116,11 @@ type FuzzingConfig struct {
 	// so that memory from its underlying chain is freed.
 	WorkerResetLimit int `json:"workerResetLimit"`
 
+	// WorkerMemoryLimit describes the approximate size, in megabytes, the database of a worker's underlying chain may
+	// grow to before the worker is destroyed and recreated, in addition to the WorkerResetLimit. Providing zero will
+	// result in no memory limit.
+	WorkerMemoryLimit int `json:"workerMemoryLimit"`
+
 	// Timeout describes a time in seconds for which the fuzzing operation should run. Providing negative or zero value
 	// will result in no timeout.
 	Timeout int `json:"timeout"`
@@ -36,6 +129,27 @@ type FuzzingConfig struct {
 	// must be non-negative. A zero value indicates the test limit should not be enforced.
 	TestLimit uint64 `json:"testLimit"`
 
+	// CallLimit describes a threshold for the number of calls to test, after which the campaign stops. A zero value
+	// indicates no call limit should be enforced. If TestLimit is also set, the campaign stops once either is reached.
+	CallLimit uint64 `json:"callLimit"`
+
+	// SequenceLimit describes a threshold for the number of call sequences to test, after which the campaign stops.
+	// A zero value indicates no sequence limit should be enforced.
+	SequenceLimit uint64 `json:"sequenceLimit"`
+
+	// StopAfterNoNewCoverage describes a duration (e.g. "30m") after which the campaign stops if coverage has not
+	// increased for its entirety. An empty string indicates the campaign should not stop when coverage stagnates.
+	StopAfterNoNewCoverage string `json:"stopAfterNoNewCoverage"`
+
+	// StagnationIsSuccess describes whether a campaign stopped by StopAfterNoNewCoverage should be considered to have
+	// completed successfully. If false, the fuzz command exits with an error when coverage stagnates.
+	StagnationIsSuccess bool `json:"stagnationIsSuccess"`
+
+	// Seed describes the seed used to derive all random providers in a fuzzing campaign. If nil, a seed is derived
+	// from the current time. Running a campaign again with the same seed and a single worker reproduces the same
+	// generated call sequences.
+	Seed *int64 `json:"seed"`
+
 	// CallSequenceLength describes the maximum length a transaction sequence can be generated as.
 	CallSequenceLength int `json:"callSequenceLength"`
 
@@ -43,15 +157,119 @@ type FuzzingConfig struct {
 	// the in-memory corpus will be used, but not flush to disk.
 	CorpusDirectory string `json:"corpusDirectory"`
 
+	// CheckpointInterval describes the interval, in seconds, at which a checkpoint of the campaign state beyond the
+	// corpus (test case states, optimization values, cumulative metrics and the random seed) is written to the corpus
+	// directory. A checkpoint is also written when the campaign ends. A zero value disables periodic checkpoints. No
+	// checkpoints are written if the corpus directory is empty.
+	CheckpointInterval int `json:"checkpointInterval"`
+
+	// Resume describes whether the campaign should resume from the checkpoint in the corpus directory, restoring its
+	// test case failures, optimization values, cumulative metrics and random seed. The contracts must compile to the
+	// same bytecode as when the checkpoint was written.
+	Resume bool `json:"resume"`
+
 	// CoverageEnabled describes whether to use coverage-guided fuzzing
 	CoverageEnabled bool `json:"coverageEnabled"`
 
-	// DeploymentOrder determines the order in which the contracts should be deployed
+	// CoverageFeedback describes the coverage signal which determines whether a call sequence achieved new coverage
+	// and should be added to the corpus: "pc" for instructions executed, "edge" for branch edges (each outcome of a
+	// JUMPI) taken, or "both". Instruction coverage is always recorded for reporting.
+	CoverageFeedback coverage.CoverageFeedback `json:"coverageFeedback"`
+
+	// CoverageHitCounts describes whether coverage should record how many times each instruction (or edge) was hit
+	// by a call, bucketed as 1, 2, 3, 4-7, 8-15, 16-31, 32-127 and 128+ times, such that a call hitting one in a new
+	// hit count bucket is considered to have achieved new coverage.
+	CoverageHitCounts bool `json:"coverageHitCounts"`
+
+	// CoverageReports describes the formats of the coverage reports written to the "coverage" folder of the corpus
+	// directory at the end of a campaign: any of "html", "lcov" and "snapshot" (a CoverageSnapshot which can be compared
+	// with that of another campaign using "medusa coverage diff"). No reports are written if the corpus directory is
+	// empty.
+	CoverageReports []coverage.ReportFormat `json:"coverageReports"`
+
+	// CoverageReportIncludePaths describes source path patterns of which a source file must match at least one to be
+	// included in coverage reports. If empty, every source file is included unless it is excluded. A pattern without
+	// a slash (e.g. "node_modules") matches any directory or file name in a source path.
+	CoverageReportIncludePaths []string `json:"coverageReportIncludePaths"`
+
+	// CoverageReportExcludePaths describes source path patterns of which a source file must match none to be
+	// included in coverage reports (e.g. "node_modules" or "lib" to exclude dependencies).
+	CoverageReportExcludePaths []string `json:"coverageReportExcludePaths"`
+
+	// CorpusPowerScheduleEnabled describes whether corpus call sequences should be selected for mutation with a bias
+	// towards those which reach coverage that few other corpus call sequences reach.
+	CorpusPowerScheduleEnabled bool `json:"corpusPowerScheduleEnabled"`
+
+	// CorpusCompression describes whether call sequences written to the corpus directory should be gzip compressed.
+	// Both compressed and uncompressed call sequences are read from the corpus directory regardless.
+	CorpusCompression bool `json:"corpusCompression"`
+
+	// CorpusForceFullReplay describes whether every corpus call sequence should be replayed on startup to measure
+	// coverage, rather than loading the coverage maps persisted in the corpus directory when the compiled contracts
+	// have not changed.
+	CorpusForceFullReplay bool `json:"corpusForceFullReplay"`
+
+	// CorpusStateDeduplication describes whether a call sequence which reaches the same state of the deployed
+	// contracts as a call sequence added to the corpus earlier in the campaign, while adding no more than
+	// CorpusStateDeduplicationMaxCoverageDelta newly covered locations, should replace it if it is shorter, or be
+	// discarded otherwise.
+	CorpusStateDeduplication bool `json:"corpusStateDeduplication"`
+
+	// CorpusStateDeduplicationMaxCoverageDelta describes the maximum amount of newly covered locations a call
+	// sequence may add to still be deduplicated against the corpus by the state it reaches, if
+	// CorpusStateDeduplication is enabled.
+	CorpusStateDeduplicationMaxCoverageDelta int `json:"corpusStateDeduplicationMaxCoverageDelta"`
+
+	// DeploymentOrder determines the order in which the contracts should be deployed. If a SetupContract is provided,
+	// it instead determines which of the contracts deployed during setup should be tested.
 	DeploymentOrder []string `json:"deploymentOrder"`
 
+	// SetupContract describes the name of a contract whose constructor (and setUp method, if it has one) deploys and
+	// configures the contracts to test, rather than deploying the contracts in DeploymentOrder directly.
+	SetupContract string `json:"setupContract"`
+
 	// Constructor arguments for contracts deployment. It is available only in init mode
 	ConstructorArgs map[string]map[string]any `json:"constructorArgs"`
 
+	// FuzzedConstructorArgs describes, for each contract name, the names of constructor arguments whose values are
+	// generated by the fuzzer at the start of each fuzzing campaign, rather than provided through ConstructorArgs.
+	FuzzedConstructorArgs map[string][]string `json:"fuzzedConstructorArgs"`
+
+	// ReplayFuzzedConstructorArgs describes whether the values generated for FuzzedConstructorArgs in the previous
+	// fuzzing campaign, which are recorded in the corpus directory, should be used again rather than generating new
+	// ones, so the corpus is replayed against contracts deployed the same way. Arguments without a recorded value are
+	// still generated.
+	ReplayFuzzedConstructorArgs bool `json:"replayFuzzedConstructorArgs"`
+
+	// Create2Deployments describes, for each contract name in the DeploymentOrder, a salt and factory with which the
+	// contract is deployed through CREATE2, rather than by the deployer directly, so it is deployed at the address it
+	// is deployed at on other chains.
+	Create2Deployments map[string]Create2DeploymentConfig `json:"create2Deployments"`
+
+	// ProxyImplementations describes, for proxies whose implementation is not stored in their EIP-1967 implementation
+	// slot, the name of the contract definition of their implementation, keyed by the name or address of the proxy.
+	// The methods of a proxy's implementation are called through the proxy, whether it was configured here or
+	// resolved from its implementation slot.
+	ProxyImplementations map[string]string `json:"proxyImplementations"`
+
+	// IncludeFunctionSignatures describes the only contract methods the fuzzer should call, each as a method signature
+	// (e.g. "transfer(address,uint256)"), a contract-qualified method signature (e.g. "Token.transfer(address,uint256)")
+	// or a regular expression matching contract-qualified method signatures (e.g. "Token\.set.*"). This cannot be
+	// used together with ExcludeFunctionSignatures.
+	IncludeFunctionSignatures []string `json:"includeFunctionSignatures"`
+
+	// ExcludeFunctionSignatures describes contract methods the fuzzer should not call, in the same form as
+	// IncludeFunctionSignatures. This cannot be used together with IncludeFunctionSignatures.
+	ExcludeFunctionSignatures []string `json:"excludeFunctionSignatures"`
+
+	// MethodSelection describes how the method targeted by each new call generated by the fuzzer is selected.
+	MethodSelection MethodSelection `json:"methodSelection"`
+
+	// MethodWeights describes the relative likelihood of methods being targeted by new calls, keyed by method signature
+	// (e.g. "transfer(address,uint256)") or contract-qualified method signature (e.g. "Token.transfer(address,uint256)").
+	// Methods which are not listed have a weight of 1. This is ignored if methods are selected uniformly.
+	MethodWeights map[string]uint64 `json:"methodWeights"`
+
 	// DeployerAddress describe the account address to be used to deploy contracts.
 	DeployerAddress string `json:"deployerAddress"`
 
@@ -59,6 +277,30 @@ type FuzzingConfig struct {
 	// campaigns.
 	SenderAddresses []string `json:"senderAddresses"`
 
+	// Senders describes additional account addresses to be used to send state-changing txs (calls) in fuzzing
+	// campaigns, along with their starting balance, nonce and role.
+	Senders []SenderConfig `json:"senders"`
+
+	// MethodRoles describes, for each method filter entry (in the same form as IncludeFunctionSignatures), the roles of
+	// the senders which may call the methods it matches. Methods matched by no entry may be called by any sender.
+	MethodRoles map[string][]string `json:"methodRoles"`
+
+	// AgentContracts describes the names of contracts which are deployed to act as senders. A fraction of the calls the
+	// fuzzer generates are routed through an agent, which calls the target itself, so callbacks made to the sender
+	// (e.g. token hooks) reach the agent. Agents must implement an execute(address,bytes,uint256) method which
+	// forwards the provided call data and value to the provided target, and reverts with its revert data if it fails.
+	// Agents not in the DeploymentOrder are deployed after it, by the deployer.
+	AgentContracts []string `json:"agentContracts"`
+
+	// AgentCallProbability describes the probability that a call generated by the fuzzer is routed through one of the
+	// AgentContracts, rather than sent directly by one of the senders.
+	AgentCallProbability float64 `json:"agentCallProbability"`
+
+	// SignerPrivateKeys describe a set of hex-encoded private keys used to produce valid ECDSA signatures for
+	// signature arguments of fuzzed calls. The addresses of these keys are used as address arguments in fuzzing
+	// campaigns, so they may be registered as authorized signers.
+	SignerPrivateKeys []string `json:"signerPrivateKeys"`
+
 	// MaxBlockNumberDelay describes the maximum distance in block numbers the fuzzer will use when generating blocks
 	// compared to the previous.
 	MaxBlockNumberDelay uint64 `json:"blockNumberDelayMax"`
@@ -67,6 +309,10 @@ type FuzzingConfig struct {
 	// compared to the previous.
 	MaxBlockTimestampDelay uint64 `json:"blockTimestampDelayMax"`
 
+	// BlockDelayWeights describes the relative likelihood of each kind of delay the fuzzer will use between the blocks
+	// of generated calls.
+	BlockDelayWeights BlockDelayWeightsConfig `json:"blockDelayWeights"`
+
 	// BlockGasLimit describes the maximum amount of gas that can be used in a block by transactions. This defines
 	// limits for how many transactions can be included per block.
 	BlockGasLimit uint64 `json:"blockGasLimit"`
@@ -74,6 +320,30 @@ type FuzzingConfig struct {
 	// TransactionGasLimit describes the maximum amount of gas that will be used by the fuzzer generated transactions.
 	TransactionGasLimit uint64 `json:"transactionGasLimit"`
 
+	// FuzzTransactionGasLimits describes whether the fuzzer should vary the gas limit of each call it generates,
+	// drawing from interesting values (such as the cost of a plain transfer, or the block gas limit) and mutations of
+	// them, rather than always using the TransactionGasLimit.
+	FuzzTransactionGasLimits bool `json:"fuzzTransactionGasLimits"`
+
+	// NonPayableValueProbability describes the probability that the fuzzer sends ether with a call to a non-payable
+	// method, to test that the method reverts. Calls to non-payable methods otherwise send no ether.
+	NonPayableValueProbability float64 `json:"nonPayableValueProbability"`
+
+	// CopyCallArgumentProbability describes the probability that the fuzzer copies the value of one argument of a call
+	// into another argument of a compatible type within the same call (e.g. so `transferFrom(from, to, amount)` is
+	// called with `from == to`). A probability of zero disables copying.
+	CopyCallArgumentProbability float64 `json:"copyCallArgumentProbability"`
+
+	// TransactionFees describes the fees the fuzzer's generated calls pay for the gas they use.
+	TransactionFees TransactionFeesConfig `json:"transactionFees"`
+
+	// StorageGrowth describes the configuration used to record how much contract storage and code the fuzzer's call
+	// sequences create, to report the sequences causing the largest state growth.
+	StorageGrowth StorageGrowthConfig `json:"storageGrowth"`
+
+	// ValueSetSeeding describes the configuration used to seed the fuzzer's base value set from compiled contracts.
+	ValueSetSeeding ValueSetSeedingConfig `json:"valueSetSeeding"`
+
 	// Testing describes the configuration used for different testing strategies.
 	Testing TestingConfig `json:"testing"`
 
@@ -81,11 +351,206 @@ type FuzzingConfig struct {
 	TestChainConfig config.TestChainConfig `json:"chainConfig"`
 }
 
+// NoNewCoverageStopDuration parses the duration described by StopAfterNoNewCoverage.
+// Returns the duration, or zero if none was provided, or an error if the duration is malformed or negative.
+func (c FuzzingConfig) NoNewCoverageStopDuration() (time.Duration, error) {
+	if c.StopAfterNoNewCoverage == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(c.StopAfterNoNewCoverage)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("project configuration must specify a valid, non-negative duration to stop after no new coverage is found: '%s'", c.StopAfterNoNewCoverage)
+	}
+	return duration, nil
+}
+
+// SenderConfig describes an account address used to send state-changing txs (calls) in fuzzing campaigns.
+type SenderConfig struct {
+	// Address describes the account address of the sender.
+	Address string `json:"address"`
+
+	// Balance describes the starting balance of the sender in wei, as a decimal or "0x"-prefixed hexadecimal string.
+	// If empty, the sender is funded like those in SenderAddresses.
+	Balance string `json:"balance"`
+
+	// Nonce describes the starting nonce of the sender.
+	Nonce uint64 `json:"nonce"`
+
+	// Role describes an optional label for the sender (e.g. "attacker" or "admin"), which MethodRoles may restrict
+	// methods to, and which is shown next to the sender in failed test reports.
+	Role string `json:"role"`
+}
+
+// BalanceValue parses the starting balance of the sender.
+// Returns the balance, or nil if none was provided, or an error if the balance is malformed.
+func (s SenderConfig) BalanceValue() (*big.Int, error) {
+	if s.Balance == "" {
+		return nil, nil
+	}
+	balance, ok := new(big.Int).SetString(s.Balance, 0)
+	if !ok || balance.Sign() < 0 {
+		return nil, fmt.Errorf("invalid balance '%s' for sender %s", s.Balance, s.Address)
+	}
+	return balance, nil
+}
+
+// DeterministicDeploymentProxyAddress describes the address of the deterministic deployment proxy, a CREATE2 factory
+// deployed at the same address on most chains. It is used to deploy contracts in Create2Deployments which do not
+// specify a factory.
+const DeterministicDeploymentProxyAddress = "0x4e59b44847b379578588920cA78FbF26c0B4956C"
+
+// Create2DeploymentConfig describes how a contract is deployed through CREATE2 during chain setup. The factory is sent
+// the salt followed by the contract's init bytecode (with its constructor arguments) as call data, which it must
+// deploy through CREATE2 with the provided salt, as the deterministic deployment proxy does.
+type Create2DeploymentConfig struct {
+	// Salt describes the hex-encoded CREATE2 salt of up to 32 bytes, which is left-padded with zeros.
+	Salt string `json:"salt"`
+
+	// Factory describes the name of a contract deployed earlier in the DeploymentOrder, or the address of a contract,
+	// which deploys the contract. If empty, the deterministic deployment proxy is used, which is added to the chain
+	// if it does not exist.
+	Factory string `json:"factory"`
+}
+
+// SaltValue parses the CREATE2 salt of the deployment.
+// Returns the salt, or an error if the salt is malformed.
+func (c Create2DeploymentConfig) SaltValue() (common.Hash, error) {
+	trimmedSalt := strings.TrimPrefix(c.Salt, "0x")
+	if len(trimmedSalt)%2 != 0 {
+		trimmedSalt = "0" + trimmedSalt
+	}
+	b, err := hex.DecodeString(trimmedSalt)
+	if err != nil || len(b) > common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid CREATE2 salt '%s'", c.Salt)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// UsesDeterministicDeploymentProxy indicates whether any contract in Create2Deployments is deployed by the
+// deterministic deployment proxy, as it specifies no factory.
+func (c FuzzingConfig) UsesDeterministicDeploymentProxy() bool {
+	for _, deployment := range c.Create2Deployments {
+		if deployment.Factory == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ValueSetSeedingConfig describes the configuration options used to seed the fuzzer's base value set with constants
+// extracted from compiled contract bytecode (e.g. PUSH instruction operands).
+type ValueSetSeedingConfig struct {
+	// BytecodeIntegers describes whether integer constants found in contract bytecode should be added to the value set.
+	BytecodeIntegers bool `json:"bytecodeIntegers"`
+
+	// BytecodeAddresses describes whether 20-byte constants found in contract bytecode should be added to the value
+	// set as addresses. This may be disabled to avoid polluting the address pool (e.g. when forking).
+	BytecodeAddresses bool `json:"bytecodeAddresses"`
+
+	// BytecodeBytes describes whether 32-byte constants found in contract bytecode should be added to the value set
+	// as byte sequences.
+	BytecodeBytes bool `json:"bytecodeBytes"`
+
+	// RuntimeValues describes whether values decoded from the return data and event logs of fuzzed calls should be
+	// added to a worker's value set, so they may be used as arguments in later calls.
+	RuntimeValues bool `json:"runtimeValues"`
+
+	// ComparisonValues describes whether the operands of comparison instructions (EQ, LT, GT, SLT, SGT) executed
+	// during fuzzed calls should be added to a worker's value set. This helps open checks against values computed at
+	// runtime, which cannot be found in contract bytecode, at the cost of tracing every executed instruction.
+	ComparisonValues bool `json:"comparisonValues"`
+
+	// MaxRuntimeValues describes the maximum amount of values learned at runtime which a worker will retain in its
+	// value set. Once exceeded, the oldest learned values are evicted first.
+	MaxRuntimeValues int `json:"maxRuntimeValues"`
+
+	// PersistValueSet describes whether the value set learned during a fuzzing campaign should be written to the
+	// corpus directory on exit, and merged into the base value set when a later campaign starts. This has no effect
+	// if no corpus directory is set.
+	PersistValueSet bool `json:"persistValueSet"`
+
+	// MaxPersistedValues describes the maximum amount of values of each type which are written to, and loaded from,
+	// the persisted value set. Once exceeded, the least recently learned values are evicted first.
+	MaxPersistedValues int `json:"maxPersistedValues"`
+}
+
+// TransactionFeesConfig describes the fees the fuzzer's generated calls pay for the gas they use. By default, calls are
+// sent as legacy transactions which do not pay a base fee, so gas is effectively free. The base fee of each block is
+// determined by the TestChainConfig.
+type TransactionFeesConfig struct {
+	// DynamicFeeTransactions describes whether generated calls should be sent as EIP-1559 (type 2) transactions, which
+	// pay the base fee of the block they are included in, alongside a priority fee. The fee caps of each call are drawn
+	// from the ranges below and recorded with it in the corpus. Calls whose fee cap does not cover the base fee of the
+	// block they would be included in are not executed.
+	DynamicFeeTransactions bool `json:"dynamicFeeTransactions"`
+
+	// MinMaxFeePerGas and MaxMaxFeePerGas describe the inclusive range, in wei, the maxFeePerGas of each generated
+	// EIP-1559 transaction is drawn from.
+	MinMaxFeePerGas uint64 `json:"minMaxFeePerGas"`
+	MaxMaxFeePerGas uint64 `json:"maxMaxFeePerGas"`
+
+	// MinMaxPriorityFeePerGas and MaxMaxPriorityFeePerGas describe the inclusive range, in wei, the
+	// maxPriorityFeePerGas of each generated EIP-1559 transaction is drawn from. It is capped to the transaction's
+	// maxFeePerGas.
+	MinMaxPriorityFeePerGas uint64 `json:"minMaxPriorityFeePerGas"`
+	MaxMaxPriorityFeePerGas uint64 `json:"maxMaxPriorityFeePerGas"`
+}
+
+// StorageGrowthConfig describes the configuration used to record the storage slots each call sequence writes to each
+// contract, and the code size of the contracts it creates. This reveals unbounded state growth (e.g. a denial of
+// service through state bloat) which the campaign's tests do not check for.
+type StorageGrowthConfig struct {
+	// Enabled describes whether storage growth should be recorded. When the campaign ends, the contracts whose storage
+	// grew the most and the call sequences which grew it the most are reported, and those call sequences are stored
+	// in the corpus directory.
+	Enabled bool `json:"enabled"`
+
+	// TopSequences describes the amount of call sequences which created the most new storage slots to report and
+	// store.
+	TopSequences int `json:"topSequences"`
+}
+
+// BlockDelayWeightsConfig describes the relative likelihood of each kind of delay the fuzzer will use between the block
+// of a generated call and the block of the call before it. Timestamp delays are capped to the FuzzingConfig
+// MaxBlockTimestampDelay, and block number delays (which are derived from them) to the MaxBlockNumberDelay.
+type BlockDelayWeightsConfig struct {
+	// None describes the weight of including a call in the same block as the call before it.
+	None uint64 `json:"none"`
+
+	// One describes the weight of advancing the block number and timestamp by one.
+	One uint64 `json:"one"`
+
+	// Minutes describes the weight of advancing the block timestamp by at least a minute, but less than an hour.
+	Minutes uint64 `json:"minutes"`
+
+	// Hours describes the weight of advancing the block timestamp by at least an hour, but less than a day.
+	Hours uint64 `json:"hours"`
+
+	// Days describes the weight of advancing the block timestamp by at least a day, but less than thirty days.
+	Days uint64 `json:"days"`
+
+	// Max describes the weight of advancing the block number and timestamp by their maximum configured delays.
+	Max uint64 `json:"max"`
+
+	// Random describes the weight of advancing the block number and timestamp by arbitrary amounts within their
+	// maximum configured delays.
+	Random uint64 `json:"random"`
+}
+
+// Total returns the sum of every weight in the BlockDelayWeightsConfig.
+func (w BlockDelayWeightsConfig) Total() uint64 {
+	return w.None + w.One + w.Minutes + w.Hours + w.Days + w.Max + w.Random
+}
+
 // TestingConfig describes the configuration options used for testing
 type TestingConfig struct {
 	// StopOnFailedTest describes whether the fuzzing.Fuzzer should stop after detecting the first failed test.
 	StopOnFailedTest bool `json:"stopOnFailedTest"`
 
+	// FailedTestBehavior describes how a failed test is treated for the remainder of the fuzzing campaign, if the
+	// fuzzing.Fuzzer does not stop on failed tests.
+	FailedTestBehavior FailedTestBehavior `json:"failedTestBehavior"`
+
 	// StopOnFailedContractMatching describes whether the fuzzing.Fuzzer should stop after failing to match bytecode
 	// to determine which contract a deployed contract is.
 	StopOnFailedContractMatching bool `json:"stopOnFailedContractMatching"`
@@ -94,16 +559,128 @@ type TestingConfig struct {
 	// than just the contracts specified in the project configuration's deployment order.
 	TestAllContracts bool `json:"testAllContracts"`
 
+	// TreatOOGAsFailure describes whether calls which fail because they ran out of gas should be reported as test
+	// failures. By default, such calls are not reported as assertion failures, nor as failures of never-revert tests
+	// when the fuzzer gave them less than the transaction gas limit.
+	TreatOOGAsFailure bool `json:"treatOOGAsFailure"`
+
 	// TraceAll describes whether a trace should be attached to each element of a finalized shrunken call sequence,
 	// e.g. when a call sequence triggers a test failure. Test providers may attach execution traces by default,
 	// even if this option is not enabled.
 	TraceAll bool `json:"traceAll"`
 
+	// TraceVerbosity describes the level of detail recorded in execution traces attached to call sequences. At
+	// TraceVerbosityCalls, traces describe calls, their decoded arguments and results, events and reverts. At
+	// TraceVerbosityStorageWrites, storage slots written by each call frame are described as well.
+	TraceVerbosity TraceVerbosity `json:"traceVerbosity"`
+
+	// ShrinkCallArguments describes whether, once calls have been removed from a call sequence being shrunk, the ABI
+	// argument values of the remaining calls should be simplified (e.g. integers towards zero, bytes and strings
+	// towards shorter lengths) while the sequence continues to satisfy the shrink request.
+	ShrinkCallArguments bool `json:"shrinkCallArguments"`
+
+	// ShrinkLimit describes the maximum number of candidate call sequences which should be executed while shrinking
+	// a call sequence. Once reached, the smallest call sequence found so far is reported. Zero indicates no limit.
+	ShrinkLimit uint64 `json:"shrinkLimit"`
+
+	// ShrinkTimeout describes a time in seconds for which shrinking a call sequence should run. Once elapsed, the
+	// smallest call sequence found so far is reported. Providing negative or zero value will result in no timeout.
+	ShrinkTimeout int `json:"shrinkTimeout"`
+
+	// StopShrinkTimeout describes a time in seconds for which shrinking a call sequence may continue once the campaign
+	// is stopped gracefully (e.g. by a keyboard interrupt). Once elapsed, the smallest call sequence found so far is
+	// reported. Zero indicates shrinking should stop as soon as the campaign does.
+	StopShrinkTimeout int `json:"stopShrinkTimeout"`
+
+	// GenerateFoundryReproducers describes whether a self-contained Foundry test reproducing each failed test should
+	// be written to a "reproducers" directory, within the corpus directory if one is set, or else the working
+	// directory.
+	GenerateFoundryReproducers bool `json:"generateFoundryReproducers"`
+
+	// FailuresDirectory describes the directory a failure file is written to for each failed test, describing the
+	// shrunken call sequence which failed it and the deployment it failed against, so the failure can be replayed
+	// with "medusa replay". If empty, no failure files are written.
+	FailuresDirectory string `json:"failuresDirectory"`
+
+	// ResultOutputs describes the files test case results should be written to once a campaign stops, including when
+	// it is stopped by a timeout or interruption.
+	ResultOutputs []TestResultOutputConfig `json:"resultOutputs"`
+
 	// AssertionTesting describes the configuration used for assertion testing.
 	AssertionTesting AssertionTestingConfig `json:"assertionTesting"`
 
 	// PropertyTesting describes the configuration used for property testing.
 	PropertyTesting PropertyTestConfig `json:"propertyTesting"`
+
+	// OptimizationTesting describes the configuration used for optimization testing.
+	OptimizationTesting OptimizationTestingConfig `json:"optimizationTesting"`
+
+	// DifferentialTesting describes the configuration used for differential testing.
+	DifferentialTesting DifferentialTestingConfig `json:"differentialTesting"`
+
+	// NeverRevertTesting describes the configuration used for never revert testing.
+	NeverRevertTesting NeverRevertTestingConfig `json:"neverRevertTesting"`
+}
+
+// TraceVerbosity describes the level of detail recorded in execution traces.
+type TraceVerbosity uint8
+
+const (
+	// TraceVerbosityCalls indicates execution traces describe calls, events and reverts.
+	TraceVerbosityCalls TraceVerbosity = iota
+
+	// TraceVerbosityStorageWrites indicates execution traces additionally describe storage writes (SSTORE).
+	TraceVerbosityStorageWrites
+)
+
+// MethodSelection describes how the method targeted by each new call generated by the fuzzer is selected.
+type MethodSelection string
+
+const (
+	// MethodSelectionUniform indicates every method is equally likely to be selected, regardless of configured weights.
+	MethodSelectionUniform MethodSelection = "uniform"
+
+	// MethodSelectionWeighted indicates methods are selected with a likelihood proportional to their configured weights.
+	MethodSelectionWeighted MethodSelection = "weighted"
+
+	// MethodSelectionAdaptive indicates methods are selected with a likelihood proportional to their configured weights,
+	// boosted for methods whose calls recently increased coverage, and reduced for methods whose calls almost always
+	// revert.
+	MethodSelectionAdaptive MethodSelection = "adaptive"
+)
+
+// FailedTestBehavior describes how a failed test is treated for the remainder of a fuzzing campaign.
+type FailedTestBehavior string
+
+const (
+	// FailedTestBehaviorDisable indicates a test is no longer tested once it fails, while other tests keep running.
+	FailedTestBehaviorDisable FailedTestBehavior = "disable"
+
+	// FailedTestBehaviorDeduplicate indicates a test is still tested once it fails, so additional failures can be
+	// found. A failure is only reported if its shrunken call sequence differs from those of previous failures.
+	FailedTestBehaviorDeduplicate FailedTestBehavior = "deduplicate"
+)
+
+// TestResultOutputFormat describes a file format test case results can be written in.
+type TestResultOutputFormat string
+
+const (
+	// TestResultOutputFormatJUnit indicates a JUnit XML report, in which each test is a test case, as consumed by most
+	// CI test summaries.
+	TestResultOutputFormatJUnit TestResultOutputFormat = "junit"
+
+	// TestResultOutputFormatSARIF indicates a SARIF log, in which each failed test is a result located at the
+	// function which defines the test, as consumed by code scanning tools.
+	TestResultOutputFormatSARIF TestResultOutputFormat = "sarif"
+)
+
+// TestResultOutputConfig describes a file test case results should be written to.
+type TestResultOutputConfig struct {
+	// Format describes the format the results should be written in.
+	Format TestResultOutputFormat `json:"format"`
+
+	// Path describes the path of the file the results should be written to.
+	Path string `json:"path"`
 }
 
 // AssertionTestingConfig describes the configuration options used for assertion testing
@@ -113,6 +690,60 @@ type AssertionTestingConfig struct {
 
 	// TestViewMethods dictates whether constant/pure/view methods should be tested.
 	TestViewMethods bool `json:"testViewMethods"`
+
+	// ExpectRevertPrefixes dictates what method name prefixes will determine if a contract method is expected to
+	// revert. If the remainder of the method name begins with the name of a custom error in the contract's ABI, the
+	// method is expected to revert with that error specifically.
+	ExpectRevertPrefixes []string `json:"expectRevertPrefixes"`
+
+	// PanicCodeConfig describes which Solidity panic codes are treated as assertion failures when a tested method
+	// reverts with them.
+	PanicCodeConfig PanicCodeConfig `json:"panicCodeConfig"`
+
+	// FailOnSwallowedPanics describes whether a panic code the PanicCodeConfig treats as a failure should fail the
+	// test of a method when it is raised by an inner call the method made and caught (e.g. by a try/catch statement),
+	// rather than propagated, even if the call to the method succeeds.
+	FailOnSwallowedPanics bool `json:"failOnSwallowedPanics"`
+}
+
+// PanicCodeConfig describes whether each Solidity panic code (raised through the `Panic(uint256)` error) is treated
+// as an assertion failure during assertion testing.
+type PanicCodeConfig struct {
+	// FailOnCompilerInsertedPanic describes whether a generic compiler inserted panic (0x00) is a failure.
+	FailOnCompilerInsertedPanic bool `json:"failOnCompilerInsertedPanic"`
+
+	// FailOnAssertion describes whether a failed `assert(...)` statement (0x01) is a failure.
+	FailOnAssertion bool `json:"failOnAssertion"`
+
+	// FailOnArithmeticUnderflow describes whether an arithmetic underflow or overflow outside an unchecked block
+	// (0x11) is a failure.
+	FailOnArithmeticUnderflow bool `json:"failOnArithmeticUnderflow"`
+
+	// FailOnDivideByZero describes whether a division or modulo by zero (0x12) is a failure.
+	FailOnDivideByZero bool `json:"failOnDivideByZero"`
+
+	// FailOnEnumTypeConversionOutOfBounds describes whether converting a value out of an enum's range (0x21) is a
+	// failure.
+	FailOnEnumTypeConversionOutOfBounds bool `json:"failOnEnumTypeConversionOutOfBounds"`
+
+	// FailOnIncorrectStorageAccess describes whether accessing an incorrectly encoded storage byte array (0x22) is a
+	// failure.
+	FailOnIncorrectStorageAccess bool `json:"failOnIncorrectStorageAccess"`
+
+	// FailOnPopEmptyArray describes whether calling `.pop()` on an empty array (0x31) is a failure.
+	FailOnPopEmptyArray bool `json:"failOnPopEmptyArray"`
+
+	// FailOnOutOfBoundsArrayAccess describes whether accessing an array or slice at an out of bounds index (0x32) is
+	// a failure.
+	FailOnOutOfBoundsArrayAccess bool `json:"failOnOutOfBoundsArrayAccess"`
+
+	// FailOnAllocateTooMuchMemory describes whether allocating too much memory or creating too large an array (0x41)
+	// is a failure.
+	FailOnAllocateTooMuchMemory bool `json:"failOnAllocateTooMuchMemory"`
+
+	// FailOnCallUninitializedVariable describes whether calling a zero-initialized internal function pointer (0x51)
+	// is a failure.
+	FailOnCallUninitializedVariable bool `json:"failOnCallUninitializedVariable"`
 }
 
 // PropertyTestConfig describes the configuration options used for property testing
@@ -122,6 +753,66 @@ type PropertyTestConfig struct {
 
 	// TestPrefixes dictates what method name prefixes will determine if a contract method is a property test.
 	TestPrefixes []string `json:"testPrefixes"`
+
+	// InvariantPatterns dictates what regular expressions will determine if a contract method is a property test,
+	// regardless of its prefix (e.g. "^invariant_" for Foundry invariant tests). A method matching any of them is
+	// tested in the same way as one matching TestPrefixes.
+	InvariantPatterns []string `json:"invariantPatterns"`
+}
+
+// OptimizationTestingConfig describes the configuration options used for optimization testing
+type OptimizationTestingConfig struct {
+	// Enabled describes whether testing is enabled.
+	Enabled bool `json:"enabled"`
+
+	// TestPrefixes dictates what method name prefixes will determine if a contract method is an optimization test
+	// whose return value should be maximized.
+	TestPrefixes []string `json:"testPrefixes"`
+
+	// MinimizeTestPrefixes dictates what method name prefixes will determine if a contract method is an optimization
+	// test whose return value should be minimized. These take precedence over TestPrefixes, so a method matching both
+	// is minimized.
+	MinimizeTestPrefixes []string `json:"minimizeTestPrefixes"`
+
+	// GasTargets describes the contract methods whose gas consumption should be maximized, each in the form
+	// "ContractName.methodName" or "ContractName.methodSignature" (e.g. "Token.transfer(address,uint256)"). The gas
+	// measured is that used for execution by the last call in a call sequence, when it targets the method.
+	GasTargets []string `json:"gasTargets"`
+}
+
+// DifferentialTestingConfig describes the configuration options used for differential testing
+type DifferentialTestingConfig struct {
+	// Enabled describes whether testing is enabled.
+	Enabled bool `json:"enabled"`
+
+	// ContractPairs describes the pairs of contracts whose behavior should be compared. Every call made to the subject
+	// contract of a pair is also made to its reference contract, and the test fails if their return values or revert
+	// statuses differ.
+	ContractPairs []DifferentialContractPair `json:"contractPairs"`
+}
+
+// DifferentialContractPair describes two contracts with identical ABIs which are expected to behave identically.
+type DifferentialContractPair struct {
+	// Subject describes the name of the contract under test (e.g. an optimized implementation).
+	Subject string `json:"subject"`
+
+	// Reference describes the name of the contract the subject is compared against (e.g. a reference
+	// implementation).
+	Reference string `json:"reference"`
+}
+
+// NeverRevertTestingConfig describes the configuration options used for never revert testing
+type NeverRevertTestingConfig struct {
+	// Enabled describes whether testing is enabled.
+	Enabled bool `json:"enabled"`
+
+	// TestPrefixes dictates what method name prefixes will determine if a contract method must never revert.
+	TestPrefixes []string `json:"testPrefixes"`
+
+	// Methods describes the signatures of methods which must never revert (e.g. "previewWithdraw(uint256)"). A
+	// signature may be qualified by a contract name (e.g. "Vault.previewWithdraw(uint256)") to only test the method
+	// of that contract.
+	Methods []string `json:"methods"`
 }
 
 // ReadProjectConfigFromFile reads a JSON-serialized ProjectConfig from a provided file path.
@@ -183,6 +874,27 @@ func (p *ProjectConfig) Validate() error {
 		return errors.New("project configuration must specify a positive number for the worker reset limit")
 	}
 
+	// Verify the worker memory limit is not negative
+	if p.Fuzzing.WorkerMemoryLimit < 0 {
+		return errors.New("project configuration must specify a non-negative number for the worker memory limit")
+	}
+
+	// Verify the runtime value bound is a positive number if runtime or comparison values are enabled
+	valueSetSeeding := p.Fuzzing.ValueSetSeeding
+	if (valueSetSeeding.RuntimeValues || valueSetSeeding.ComparisonValues) && valueSetSeeding.MaxRuntimeValues <= 0 {
+		return errors.New("project configuration must specify a positive number for the max runtime values if runtime or comparison values are enabled")
+	}
+
+	// Verify the persisted value bound is a positive number if the value set is persisted
+	if valueSetSeeding.PersistValueSet && valueSetSeeding.MaxPersistedValues <= 0 {
+		return errors.New("project configuration must specify a positive number for the max persisted values if the value set is persisted")
+	}
+
+	// Verify we have a kind of block delay to select
+	if p.Fuzzing.BlockDelayWeights.Total() == 0 {
+		return errors.New("project configuration must specify at least one non-zero block delay weight")
+	}
+
 	// Verify gas limits are appropriate
 	if p.Fuzzing.BlockGasLimit < p.Fuzzing.TransactionGasLimit {
 		return errors.New("project configuration must specify a block gas limit which is not less than the transaction gas limit")
@@ -191,22 +903,263 @@ func (p *ProjectConfig) Validate() error {
 		return errors.New("project configuration must specify a block and transaction gas limit which is non-zero")
 	}
 
+	// Verify the stagnation duration is valid, and that we measure the coverage it depends on
+	stagnationDuration, err := p.Fuzzing.NoNewCoverageStopDuration()
+	if err != nil {
+		return err
+	}
+	if stagnationDuration > 0 && !p.Fuzzing.CoverageEnabled {
+		return errors.New("project configuration must enable coverage to stop after no new coverage is found")
+	}
+
+	// Verify the time shrinking may continue for once the campaign stops is non-negative
+	if p.Fuzzing.Testing.StopShrinkTimeout < 0 {
+		return errors.New("project configuration must specify a non-negative stop shrink timeout")
+	}
+
+	// Verify checkpoints can be written and resumed from
+	if p.Fuzzing.CheckpointInterval < 0 {
+		return errors.New("project configuration must specify a non-negative checkpoint interval")
+	}
+	if p.Fuzzing.Resume && p.Fuzzing.CorpusDirectory == "" {
+		return errors.New("project configuration must specify a corpus directory to resume a campaign from its checkpoint")
+	}
+
+	// Verify the coverage delta below which call sequences are deduplicated by state is valid
+	if p.Fuzzing.CorpusStateDeduplicationMaxCoverageDelta < 0 {
+		return errors.New("project configuration must specify a non-negative max coverage delta for corpus state deduplication")
+	}
+
+	// Verify the fee ranges of EIP-1559 transactions are valid
+	transactionFees := p.Fuzzing.TransactionFees
+	if transactionFees.DynamicFeeTransactions {
+		if transactionFees.MinMaxFeePerGas == 0 || transactionFees.MinMaxFeePerGas > transactionFees.MaxMaxFeePerGas {
+			return errors.New("project configuration must specify a non-empty range of positive max fees per gas for dynamic fee transactions")
+		}
+		if transactionFees.MinMaxPriorityFeePerGas > transactionFees.MaxMaxPriorityFeePerGas {
+			return errors.New("project configuration must specify a non-empty range of max priority fees per gas for dynamic fee transactions")
+		}
+	}
+
+	// Verify the amount of call sequences storage growth is reported for is valid
+	if p.Fuzzing.StorageGrowth.Enabled && p.Fuzzing.StorageGrowth.TopSequences < 0 {
+		return errors.New("project configuration must specify a non-negative amount of top storage growth sequences")
+	}
+
+	// Verify the probability of sending ether to non-payable methods is valid
+	if p.Fuzzing.NonPayableValueProbability < 0 || p.Fuzzing.NonPayableValueProbability > 1 {
+		return errors.New("project configuration must specify a non-payable value probability between 0 and 1")
+	}
+
+	// Verify the probability of copying values between call arguments is valid
+	if p.Fuzzing.CopyCallArgumentProbability < 0 || p.Fuzzing.CopyCallArgumentProbability > 1 {
+		return errors.New("project configuration must specify a copy call argument probability between 0 and 1")
+	}
+
 	// Verify that senders are well-formed addresses
 	if _, err := utils.HexStringsToAddresses(p.Fuzzing.SenderAddresses); err != nil {
 		return errors.New("project configuration must specify only well-formed sender address(es)")
 	}
 
+	// Verify that configured senders are well-formed and distinct, and that method roles are assigned to senders
+	senderAddresses := make(map[common.Address]bool)
+	for _, sender := range p.Fuzzing.SenderAddresses {
+		senderAddresses[common.HexToAddress(sender)] = true
+	}
+	senderRoles := make(map[string]bool)
+	for _, sender := range p.Fuzzing.Senders {
+		address, err := utils.HexStringToAddress(sender.Address)
+		if err != nil {
+			return fmt.Errorf("project configuration must specify only well-formed sender addresses, got '%s'", sender.Address)
+		}
+		if senderAddresses[address] {
+			return fmt.Errorf("project configuration must not specify sender %s more than once", sender.Address)
+		}
+		senderAddresses[address] = true
+		if _, err := sender.BalanceValue(); err != nil {
+			return fmt.Errorf("project configuration must specify well-formed sender balances: %v", err)
+		}
+		if sender.Role != "" {
+			senderRoles[sender.Role] = true
+		}
+	}
+	for entry, roles := range p.Fuzzing.MethodRoles {
+		for _, role := range roles {
+			if !senderRoles[role] {
+				return fmt.Errorf("project configuration allows role '%s' to call methods matching '%s', but no sender has this role", role, entry)
+			}
+		}
+	}
+
+	// Verify the probability of routing calls through agents is valid
+	if p.Fuzzing.AgentCallProbability < 0 || p.Fuzzing.AgentCallProbability > 1 {
+		return errors.New("project configuration must specify an agent call probability between 0 and 1")
+	}
+
+	// Verify that signer private keys are well-formed
+	if _, err := utils.HexStringsToPrivateKeys(p.Fuzzing.SignerPrivateKeys); err != nil {
+		return errors.New("project configuration must specify only well-formed signer private key(s)")
+	}
+
+	// Verify the method selection is a known strategy
+	methodSelection := p.Fuzzing.MethodSelection
+	if methodSelection != MethodSelectionUniform && methodSelection != MethodSelectionWeighted && methodSelection != MethodSelectionAdaptive {
+		return fmt.Errorf("project configuration must specify a method selection of %q, %q or %q, got %q", MethodSelectionUniform, MethodSelectionWeighted, MethodSelectionAdaptive, methodSelection)
+	}
+
+	// Verify that CREATE2 deployments have well-formed salts, and are not their own factory
+	for contractName, deployment := range p.Fuzzing.Create2Deployments {
+		if _, err := deployment.SaltValue(); err != nil {
+			return fmt.Errorf("project configuration must specify a well-formed CREATE2 salt for contract %v: %v", contractName, err)
+		}
+		if deployment.Factory == contractName {
+			return fmt.Errorf("project configuration must not specify contract %v as its own CREATE2 factory", contractName)
+		}
+	}
+
+	// Verify that proxy implementations name the contracts which implement them
+	for proxy, implementationName := range p.Fuzzing.ProxyImplementations {
+		if proxy == "" || implementationName == "" {
+			return errors.New("project configuration must specify a proxy and implementation contract for each proxy implementation")
+		}
+	}
+
+	// Verify that function signatures are either included or excluded, but not both
+	if len(p.Fuzzing.IncludeFunctionSignatures) > 0 && len(p.Fuzzing.ExcludeFunctionSignatures) > 0 {
+		return errors.New("project configuration must not specify both included and excluded function signatures")
+	}
+
+	// Verify that an RPC endpoint is provided if fork mode is enabled
+	if p.Fuzzing.TestChainConfig.ForkConfig.ForkModeEnabled && p.Fuzzing.TestChainConfig.ForkConfig.RpcUrl == "" {
+		return errors.New("project configuration must specify an RPC URL if fork mode is enabled")
+	}
+
 	// Verify that deployer is a well-formed address
 	if _, err := utils.HexStringToAddress(p.Fuzzing.DeployerAddress); err != nil {
 		return errors.New("project configuration must specify only a well-formed deployer address")
 	}
 
+	// Verify the coverage feedback is a known signal
+	if !p.Fuzzing.CoverageFeedback.IsValid() {
+		return fmt.Errorf("project configuration must specify a coverage feedback of %q, %q or %q", coverage.CoverageFeedbackPC, coverage.CoverageFeedbackEdge, coverage.CoverageFeedbackBoth)
+	}
+
+	// Verify the coverage report formats are known
+	for _, reportFormat := range p.Fuzzing.CoverageReports {
+		if !reportFormat.IsValid() {
+			return fmt.Errorf("project configuration must specify coverage report formats of %q, %q or %q, got %q", coverage.ReportFormatHTML, coverage.ReportFormatLCOV, coverage.ReportFormatSnapshot, reportFormat)
+		}
+	}
+
+	// Verify the trace verbosity is a known level
+	if p.Fuzzing.Testing.TraceVerbosity > TraceVerbosityStorageWrites {
+		return fmt.Errorf("project configuration must specify a trace verbosity no greater than %d", TraceVerbosityStorageWrites)
+	}
+
+	// Verify the failed test behavior is a known behavior
+	failedTestBehavior := p.Fuzzing.Testing.FailedTestBehavior
+	if failedTestBehavior != FailedTestBehaviorDisable && failedTestBehavior != FailedTestBehaviorDeduplicate {
+		return fmt.Errorf("project configuration must specify a failed test behavior of %q or %q, got %q", FailedTestBehaviorDisable, FailedTestBehaviorDeduplicate, failedTestBehavior)
+	}
+
+	// Verify the test result outputs are known formats with paths to write them to
+	for _, resultOutput := range p.Fuzzing.Testing.ResultOutputs {
+		if resultOutput.Format != TestResultOutputFormatJUnit && resultOutput.Format != TestResultOutputFormatSARIF {
+			return fmt.Errorf("project configuration must specify test result output formats of %q or %q, got %q", TestResultOutputFormatJUnit, TestResultOutputFormatSARIF, resultOutput.Format)
+		}
+		if resultOutput.Path == "" {
+			return fmt.Errorf("project configuration must specify a path for the %q test result output", resultOutput.Format)
+		}
+	}
+
 	// Verify property testing fields.
 	if p.Fuzzing.Testing.PropertyTesting.Enabled {
-		// Test prefixes must be supplied if property testing is enabled.
-		if len(p.Fuzzing.Testing.PropertyTesting.TestPrefixes) == 0 {
-			return errors.New("project configuration must specify test name prefixes if property testing is enabled")
+		// Test prefixes or invariant patterns must be supplied if property testing is enabled.
+		if len(p.Fuzzing.Testing.PropertyTesting.TestPrefixes) == 0 && len(p.Fuzzing.Testing.PropertyTesting.InvariantPatterns) == 0 {
+			return errors.New("project configuration must specify test name prefixes or invariant patterns if property testing is enabled")
+		}
+	}
+
+	// Verify the invariant patterns are valid regular expressions
+	for _, pattern := range p.Fuzzing.Testing.PropertyTesting.InvariantPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("project configuration must specify invariant patterns which are valid regular expressions, got %q: %v", pattern, err)
+		}
+	}
+
+	// Verify optimization testing fields.
+	if p.Fuzzing.Testing.OptimizationTesting.Enabled {
+		// Test prefixes or gas targets must be supplied if optimization testing is enabled.
+		optimizationTesting := p.Fuzzing.Testing.OptimizationTesting
+		if len(optimizationTesting.TestPrefixes) == 0 && len(optimizationTesting.MinimizeTestPrefixes) == 0 && len(optimizationTesting.GasTargets) == 0 {
+			return errors.New("project configuration must specify test name prefixes or gas targets if optimization testing is enabled")
+		}
+		for _, gasTarget := range optimizationTesting.GasTargets {
+			contractName, methodName, found := strings.Cut(gasTarget, ".")
+			if !found || contractName == "" || methodName == "" {
+				return fmt.Errorf("project configuration specifies gas target '%s', which is not of the form 'ContractName.methodName'", gasTarget)
+			}
+		}
+	}
+
+	// Verify differential testing fields.
+	if p.Fuzzing.Testing.DifferentialTesting.Enabled {
+		// Contract pairs must be supplied if differential testing is enabled.
+		if len(p.Fuzzing.Testing.DifferentialTesting.ContractPairs) == 0 {
+			return errors.New("project configuration must specify contract pairs if differential testing is enabled")
 		}
+		for _, pair := range p.Fuzzing.Testing.DifferentialTesting.ContractPairs {
+			if pair.Subject == "" || pair.Reference == "" {
+				return errors.New("project configuration must specify a subject and reference contract for each differential testing contract pair")
+			}
+			if pair.Subject == pair.Reference {
+				return fmt.Errorf("project configuration must specify different subject and reference contracts for differential testing, got %q for both", pair.Subject)
+			}
+		}
+	}
+
+	// Verify never revert testing fields.
+	if p.Fuzzing.Testing.NeverRevertTesting.Enabled {
+		// Test prefixes or methods must be supplied if never revert testing is enabled.
+		if len(p.Fuzzing.Testing.NeverRevertTesting.TestPrefixes) == 0 && len(p.Fuzzing.Testing.NeverRevertTesting.Methods) == 0 {
+			return errors.New("project configuration must specify test name prefixes or methods if never revert testing is enabled")
+		}
+	}
+
+	// Verify the log format is known
+	if !p.Logging.Format.IsValid() {
+		return fmt.Errorf("project configuration must specify a log format of %q or %q", logging.LogFormatText, logging.LogFormatJSON)
+	}
+
+	// Verify the notification webhooks and their settings are well-formed, if any are provided
+	if len(p.Notifications.WebhookURLs) > 0 {
+		for _, webhookURL := range p.Notifications.WebhookURLs {
+			parsedURL, err := url.Parse(webhookURL)
+			if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+				return fmt.Errorf("project configuration must specify notification webhook URLs with an http or https scheme and a host, got %q", webhookURL)
+			}
+		}
+		if !p.Notifications.Format.IsValid() {
+			return fmt.Errorf("project configuration must specify a notification format of %q or %q", NotificationFormatJSON, NotificationFormatSlack)
+		}
+		if _, err := template.New("notification").Parse(p.Notifications.Template); err != nil {
+			return fmt.Errorf("project configuration must specify a valid notification template: %v", err)
+		}
+		if p.Notifications.Timeout == 0 {
+			return errors.New("project configuration must specify a notification timeout greater than zero")
+		}
+	}
+
+	// Verify the metrics server address is well-formed, if one is provided
+	if p.Metrics.Address != "" {
+		if _, _, err := net.SplitHostPort(p.Metrics.Address); err != nil {
+			return fmt.Errorf("project configuration must specify a metrics address of the form host:port: %v", err)
+		}
+	}
+
+	// Verify the revert rate warning threshold is a fraction of calls
+	if p.Metrics.RevertRateWarningThreshold < 0 || p.Metrics.RevertRateWarningThreshold > 1 {
+		return errors.New("project configuration must specify a revert rate warning threshold between 0 and 1")
 	}
 	return nil
 }
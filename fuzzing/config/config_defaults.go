@@ -3,6 +3,8 @@ package config
 import (
 	testChainConfig "github.com/crytic/medusa/chain/config"
 	"github.com/crytic/medusa/compilation"
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/logging"
 )
 
 // GetDefaultProjectConfig obtains a default configuration for a project. It populates a default compilation config
@@ -31,44 +33,169 @@ func GetDefaultProjectConfig(platform string) (*ProjectConfig, error) {
 	// Create a project configuration
 	projectConfig := &ProjectConfig{
 		Fuzzing: FuzzingConfig{
-			Workers:            10,
-			WorkerResetLimit:   50,
-			Timeout:            0,
-			TestLimit:          0,
-			CallSequenceLength: 100,
-			DeploymentOrder:    []string{},
-			ConstructorArgs:    map[string]map[string]any{},
-			CorpusDirectory:    "",
-			CoverageEnabled:    true,
+			Workers:                                  10,
+			WorkerResetLimit:                         50,
+			WorkerMemoryLimit:                        0,
+			Timeout:                                  0,
+			TestLimit:                                0,
+			CallLimit:                                0,
+			SequenceLimit:                            0,
+			StopAfterNoNewCoverage:                   "",
+			StagnationIsSuccess:                      true,
+			CallSequenceLength:                       100,
+			DeploymentOrder:                          []string{},
+			SetupContract:                            "",
+			ConstructorArgs:                          map[string]map[string]any{},
+			FuzzedConstructorArgs:                    map[string][]string{},
+			ReplayFuzzedConstructorArgs:              false,
+			Create2Deployments:                       map[string]Create2DeploymentConfig{},
+			ProxyImplementations:                     map[string]string{},
+			IncludeFunctionSignatures:                []string{},
+			ExcludeFunctionSignatures:                []string{},
+			MethodSelection:                          MethodSelectionWeighted,
+			MethodWeights:                            map[string]uint64{},
+			CorpusDirectory:                          "",
+			CheckpointInterval:                       60,
+			Resume:                                   false,
+			CoverageEnabled:                          true,
+			CoverageFeedback:                         coverage.CoverageFeedbackPC,
+			CoverageHitCounts:                        false,
+			CoverageReports:                          []coverage.ReportFormat{coverage.ReportFormatHTML, coverage.ReportFormatLCOV, coverage.ReportFormatSnapshot},
+			CoverageReportIncludePaths:               []string{},
+			CoverageReportExcludePaths:               []string{},
+			CorpusPowerScheduleEnabled:               false,
+			CorpusCompression:                        false,
+			CorpusForceFullReplay:                    false,
+			CorpusStateDeduplication:                 false,
+			CorpusStateDeduplicationMaxCoverageDelta: 4,
 			SenderAddresses: []string{
 				"0x10000",
 				"0x20000",
 				"0x30000",
 			},
+			Senders:              []SenderConfig{},
+			MethodRoles:          map[string][]string{},
+			AgentContracts:       []string{},
+			AgentCallProbability: 0.25,
+			SignerPrivateKeys: []string{
+				"0x1",
+				"0x2",
+				"0x3",
+			},
 			DeployerAddress:        "0x30000",
 			MaxBlockNumberDelay:    60480,
 			MaxBlockTimestampDelay: 604800,
-			BlockGasLimit:          125_000_000,
-			TransactionGasLimit:    12_500_000,
+			BlockDelayWeights: BlockDelayWeightsConfig{
+				None:    20,
+				One:     20,
+				Minutes: 15,
+				Hours:   15,
+				Days:    15,
+				Max:     5,
+				Random:  10,
+			},
+			BlockGasLimit:               125_000_000,
+			TransactionGasLimit:         12_500_000,
+			FuzzTransactionGasLimits:    false,
+			NonPayableValueProbability:  0.01,
+			CopyCallArgumentProbability: 0.1,
+			TransactionFees: TransactionFeesConfig{
+				DynamicFeeTransactions:  false,
+				MinMaxFeePerGas:         1_000_000_000,
+				MaxMaxFeePerGas:         100_000_000_000,
+				MinMaxPriorityFeePerGas: 0,
+				MaxMaxPriorityFeePerGas: 2_000_000_000,
+			},
+			StorageGrowth: StorageGrowthConfig{
+				Enabled:      false,
+				TopSequences: 5,
+			},
+			ValueSetSeeding: ValueSetSeedingConfig{
+				BytecodeIntegers:   true,
+				BytecodeAddresses:  true,
+				BytecodeBytes:      true,
+				RuntimeValues:      true,
+				ComparisonValues:   false,
+				MaxRuntimeValues:   1000,
+				PersistValueSet:    true,
+				MaxPersistedValues: 10000,
+			},
 			Testing: TestingConfig{
 				StopOnFailedTest:             true,
+				FailedTestBehavior:           FailedTestBehaviorDisable,
 				StopOnFailedContractMatching: true,
 				TestAllContracts:             false,
+				TreatOOGAsFailure:            false,
 				TraceAll:                     false,
+				TraceVerbosity:               TraceVerbosityCalls,
+				ShrinkCallArguments:          true,
+				ShrinkLimit:                  5000,
+				ShrinkTimeout:                0,
+				StopShrinkTimeout:            10,
+				GenerateFoundryReproducers:   false,
+				FailuresDirectory:            ".medusa/failures",
+				ResultOutputs:                []TestResultOutputConfig{},
 				AssertionTesting: AssertionTestingConfig{
 					Enabled:         false,
 					TestViewMethods: false,
+					ExpectRevertPrefixes: []string{
+						"medusa_revert_",
+					},
+					PanicCodeConfig: PanicCodeConfig{
+						FailOnAssertion: true,
+					},
+					FailOnSwallowedPanics: false,
 				},
 				PropertyTesting: PropertyTestConfig{
 					Enabled: true,
 					TestPrefixes: []string{
 						"fuzz_",
 					},
+					InvariantPatterns: []string{
+						"^invariant_",
+					},
+				},
+				OptimizationTesting: OptimizationTestingConfig{
+					Enabled: false,
+					TestPrefixes: []string{
+						"optimize_",
+					},
+					MinimizeTestPrefixes: []string{
+						"optimize_min_",
+					},
+					GasTargets: []string{},
+				},
+				DifferentialTesting: DifferentialTestingConfig{
+					Enabled:       false,
+					ContractPairs: []DifferentialContractPair{},
+				},
+				NeverRevertTesting: NeverRevertTestingConfig{
+					Enabled: false,
+					TestPrefixes: []string{
+						"neverRevert_",
+					},
+					Methods: []string{},
 				},
 			},
 			TestChainConfig: *chainConfig,
 		},
 		Compilation: compilationConfig,
+		Metrics: MetricsConfig{
+			Address:                    "",
+			RevertRateWarningThreshold: 0.9,
+		},
+		Logging: LoggingConfig{
+			Format: logging.LogFormatText,
+			Debug:  false,
+			TUI:    false,
+		},
+		Notifications: NotificationsConfig{
+			WebhookURLs: []string{},
+			Format:      NotificationFormatJSON,
+			Template:    "",
+			Timeout:     10,
+			Retries:     3,
+		},
 	}
 
 	// Return the project configuration
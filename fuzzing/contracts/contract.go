@@ -1,7 +1,11 @@
 package contracts
 
 import (
+	"encoding/hex"
+
+	"github.com/crytic/medusa/compilation/abiutils"
 	"github.com/crytic/medusa/compilation/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 )
 
 // Contracts describes an array of contracts
@@ -18,10 +22,31 @@ func (c Contracts) MatchBytecode(initBytecode []byte, runtimeBytecode []byte) *C
 		}
 	}
 
+	// If no definition matched exactly, try matching runtime bytecode while ignoring metadata and immutable values.
+	// We only do so once every definition failed to match exactly, so definitions whose code only differs by their
+	// metadata are not mistaken for one another.
+	if len(runtimeBytecode) > 0 {
+		for i := 0; i < len(c); i++ {
+			if c[i].CompiledContract().IsRuntimeMatchIgnoringMetadata(runtimeBytecode) {
+				return c[i]
+			}
+		}
+	}
+
 	// If we found no definition, return nil.
 	return nil
 }
 
+// CustomErrors collects the custom Solidity error definitions of every contract in the current list of contracts.
+// Returns a mapping of error selectors to error definitions.
+func (c Contracts) CustomErrors() abiutils.SolidityCustomErrors {
+	customErrors := make(abiutils.SolidityCustomErrors)
+	for _, contract := range c {
+		customErrors.Add(&contract.CompiledContract().Abi)
+	}
+	return customErrors
+}
+
 // Contract describes a compiled smart contract.
 type Contract struct {
 	// name represents the name of the contract.
@@ -32,14 +57,19 @@ type Contract struct {
 
 	// compiledContract describes the compiled contract data.
 	compiledContract *types.CompiledContract
+
+	// methodInputEnumBounds maps hex-encoded method selectors to the enums within each of the method's input
+	// parameters (or nil if the parameter contains no enums).
+	methodInputEnumBounds map[string][]*types.EnumBounds
 }
 
 // NewContract returns a new Contract instance with the provided information.
-func NewContract(name string, sourcePath string, compiledContract *types.CompiledContract) *Contract {
+func NewContract(name string, sourcePath string, compiledContract *types.CompiledContract, methodInputEnumBounds map[string][]*types.EnumBounds) *Contract {
 	return &Contract{
-		name:             name,
-		sourcePath:       sourcePath,
-		compiledContract: compiledContract,
+		name:                  name,
+		sourcePath:            sourcePath,
+		compiledContract:      compiledContract,
+		methodInputEnumBounds: methodInputEnumBounds,
 	}
 }
 
@@ -57,3 +87,25 @@ func (c *Contract) SourcePath() string {
 func (c *Contract) CompiledContract() *types.CompiledContract {
 	return c.compiledContract
 }
+
+// MethodInputEnumBounds returns the enums within each input parameter of the provided method, as described by
+// types.EnumBounds, where parameters which contain no enums have nil bounds. If the method has no known parameters
+// containing enums, nil is returned.
+func (c *Contract) MethodInputEnumBounds(method *abi.Method) []*types.EnumBounds {
+	bounds := c.methodInputEnumBounds[hex.EncodeToString(method.ID)]
+	if len(bounds) != len(method.Inputs) {
+		return nil
+	}
+	return bounds
+}
+
+// MethodInputSizeLimits returns the maximum size of each input parameter of the provided method, as described by
+// types.CompiledContract.MethodInputSizeLimits, where parameters which are not bounded have a limit of zero. If the
+// method has no known bounded parameters, nil is returned.
+func (c *Contract) MethodInputSizeLimits(method *abi.Method) []int {
+	limits := c.compiledContract.MethodInputSizeLimits[hex.EncodeToString(method.ID)]
+	if len(limits) != len(method.Inputs) {
+		return nil
+	}
+	return limits
+}
@@ -0,0 +1,78 @@
+package contracts
+
+import (
+	"strings"
+
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// EIP1967ImplementationSlot describes the storage slot EIP-1967 proxies store the address of their implementation
+// in: bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1).
+var EIP1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+// ProxyImplementationResolver resolves the contract definitions of the implementations proxies delegate calls to,
+// so their methods can be called through the proxy.
+type ProxyImplementationResolver struct {
+	// contractDefinitions describes the contract definitions implementations are resolved from.
+	contractDefinitions Contracts
+
+	// implementationsByName describes the names of the implementation contracts configured for proxies, keyed by the
+	// name of the proxy contract.
+	implementationsByName map[string]string
+
+	// implementationsByAddress describes the names of the implementation contracts configured for proxies, keyed by
+	// the address of the proxy.
+	implementationsByAddress map[common.Address]string
+}
+
+// NewProxyImplementationResolver creates a ProxyImplementationResolver which resolves implementations from the
+// provided contract definitions. The provided configured implementations describe the names of the implementation
+// contracts of proxies which are not resolved from their EIP-1967 implementation slot, keyed by the name or
+// "0x"-prefixed address of the proxy.
+func NewProxyImplementationResolver(contractDefinitions Contracts, configuredImplementations map[string]string) *ProxyImplementationResolver {
+	resolver := &ProxyImplementationResolver{
+		contractDefinitions:      contractDefinitions,
+		implementationsByName:    make(map[string]string),
+		implementationsByAddress: make(map[common.Address]string),
+	}
+	for proxy, implementationName := range configuredImplementations {
+		if strings.HasPrefix(proxy, "0x") {
+			if address, err := utils.HexStringToAddress(proxy); err == nil {
+				resolver.implementationsByAddress[address] = implementationName
+				continue
+			}
+		}
+		resolver.implementationsByName[proxy] = implementationName
+	}
+	return resolver
+}
+
+// Resolve resolves the contract definition of the implementation the proxy at the provided address delegates calls
+// to. Implementations configured for the proxy are preferred. Otherwise, the address stored in the proxy's EIP-1967
+// implementation slot in the provided state is resolved through the provided deployed contract definitions.
+// Returns the implementation's contract definition, or nil if the contract is not a proxy, or its implementation
+// could not be resolved.
+func (r *ProxyImplementationResolver) Resolve(stateDB vm.StateDB, proxyAddress common.Address, proxyContract *Contract, deployedContracts map[common.Address]*Contract) *Contract {
+	// If an implementation was configured for our proxy, look it up by name.
+	implementationName, configured := r.implementationsByAddress[proxyAddress]
+	if !configured && proxyContract != nil {
+		implementationName, configured = r.implementationsByName[proxyContract.Name()]
+	}
+	if configured {
+		for _, contract := range r.contractDefinitions {
+			if contract.Name() == implementationName {
+				return contract
+			}
+		}
+		return nil
+	}
+
+	// Otherwise, resolve the implementation stored in our proxy's EIP-1967 implementation slot.
+	implementationAddress := common.BytesToAddress(stateDB.GetState(proxyAddress, EIP1967ImplementationSlot).Bytes())
+	if implementationAddress == (common.Address{}) || implementationAddress == proxyAddress {
+		return nil
+	}
+	return deployedContracts[implementationAddress]
+}
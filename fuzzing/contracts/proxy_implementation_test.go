@@ -0,0 +1,54 @@
+package contracts
+
+import (
+	"testing"
+
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestProxyImplementationResolver ensures the implementations of proxies are resolved from their EIP-1967
+// implementation slot, or from configured implementations, which are preferred, and re-resolved once the proxy is
+// upgraded.
+func TestProxyImplementationResolver(t *testing.T) {
+	proxy := NewContract("Proxy", "src/Proxy.sol", &types.CompiledContract{}, nil)
+	implementation := NewContract("Implementation", "src/Implementation.sol", &types.CompiledContract{}, nil)
+	upgradedImplementation := NewContract("UpgradedImplementation", "src/Implementation.sol", &types.CompiledContract{}, nil)
+	proxyAddress := common.HexToAddress("0x1000")
+	implementationAddress := common.HexToAddress("0x2000")
+	upgradedImplementationAddress := common.HexToAddress("0x3000")
+	deployedContracts := map[common.Address]*Contract{
+		proxyAddress:                  proxy,
+		implementationAddress:         implementation,
+		upgradedImplementationAddress: upgradedImplementation,
+	}
+	contractDefinitions := Contracts{proxy, implementation, upgradedImplementation}
+
+	stateDB, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	assert.NoError(t, err)
+
+	// Contracts with no implementation in their implementation slot are not proxies.
+	resolver := NewProxyImplementationResolver(contractDefinitions, nil)
+	assert.Nil(t, resolver.Resolve(stateDB, proxyAddress, proxy, deployedContracts))
+
+	// Once an implementation is stored, it should be resolved, and re-resolved once it is upgraded.
+	stateDB.SetState(proxyAddress, EIP1967ImplementationSlot, implementationAddress.Hash())
+	assert.Same(t, implementation, resolver.Resolve(stateDB, proxyAddress, proxy, deployedContracts))
+	stateDB.SetState(proxyAddress, EIP1967ImplementationSlot, upgradedImplementationAddress.Hash())
+	assert.Same(t, upgradedImplementation, resolver.Resolve(stateDB, proxyAddress, proxy, deployedContracts))
+
+	// Implementations which are not deployed contracts we know of cannot be resolved.
+	stateDB.SetState(proxyAddress, EIP1967ImplementationSlot, common.HexToAddress("0x4000").Hash())
+	assert.Nil(t, resolver.Resolve(stateDB, proxyAddress, proxy, deployedContracts))
+
+	// Configured implementations are preferred, whether configured by the name or address of the proxy.
+	resolver = NewProxyImplementationResolver(contractDefinitions, map[string]string{"Proxy": "Implementation"})
+	assert.Same(t, implementation, resolver.Resolve(stateDB, proxyAddress, proxy, deployedContracts))
+	resolver = NewProxyImplementationResolver(contractDefinitions, map[string]string{proxyAddress.String(): "UpgradedImplementation"})
+	assert.Same(t, upgradedImplementation, resolver.Resolve(stateDB, proxyAddress, proxy, deployedContracts))
+	resolver = NewProxyImplementationResolver(contractDefinitions, map[string]string{"Proxy": "Missing"})
+	assert.Nil(t, resolver.Resolve(stateDB, proxyAddress, proxy, deployedContracts))
+}
@@ -1,7 +1,6 @@
 package corpus
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/crytic/medusa/chain"
@@ -11,14 +10,28 @@ import (
 	"github.com/crytic/medusa/utils/randomutils"
 	"github.com/ethereum/go-ethereum/common"
 	"math/big"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/logging"
 	"github.com/google/uuid"
 )
 
+// corpusVersion describes the version of the corpus directory layout and artifact formats written by this version of
+// the fuzzer. It should be incremented whenever a change is made which older versions cannot read. Corpus directories
+// without a version file predate versioning and are treated as version zero.
+const corpusVersion = 2
+
+// corpusVersionInfo describes the contents of the version file stored in the corpus directory.
+type corpusVersionInfo struct {
+	// Version describes the corpusVersion the corpus directory was last written with.
+	Version int `json:"version"`
+}
+
 // Corpus describes an archive of fuzzer-generated artifacts used to further fuzzing efforts. These artifacts are
 // reusable across fuzzer runs. Changes to the fuzzer/chain configuration or definitions within smart contracts
 // may create incompatibilities with corpus items.
@@ -33,18 +46,128 @@ type Corpus struct {
 	// to the fuzzer.
 	callSequences []*corpusFile[calls.CallSequence]
 
+	// optimizationCallSequences describes the call sequences read from the OptimizationCallSequencesDirectory, which
+	// reached the best values of optimization tests in previous runs.
+	optimizationCallSequences []*corpusFile[calls.CallSequence]
+
+	// resumedCallSequences describes the call sequences restored from the checkpoint of a previous campaign, such as
+	// those which failed tests, which are replayed on Initialize without being written to the corpus directory.
+	resumedCallSequences []*corpusFile[calls.CallSequence]
+
 	// unexecutedCallSequences defines the callSequences which have not yet been executed by the fuzzer. As each item
 	// is selected for execution by the fuzzer on startup, it is removed. This way, all call sequences loaded from disk
 	// are executed to check for test failures.
-	unexecutedCallSequences []calls.CallSequence
+	unexecutedCallSequences []*corpusFile[calls.CallSequence]
 
 	// weightedCallSequenceChooser is a provider that allows for weighted random selection of callSequences. If a
 	// call sequence was not found to be compatible with this run, it is not added to the chooser.
-	weightedCallSequenceChooser *randomutils.WeightedRandomChooser[calls.CallSequence]
+	weightedCallSequenceChooser *randomutils.WeightedRandomChooser[*corpusFile[calls.CallSequence]]
+
+	// callSequenceChoices describes every choice added to the weightedCallSequenceChooser, in the order they were
+	// added.
+	callSequenceChoices []*randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]]
+
+	// powerScheduleEnabled describes whether call sequence weights are determined by the rarity of the coverage each
+	// call sequence reached, rather than by the weights provided when adding them.
+	powerScheduleEnabled bool
+
+	// powerScheduleEntries describes the call sequences tracked by the power schedule if it is enabled, keyed by their
+	// choice in the weightedCallSequenceChooser.
+	powerScheduleEntries map[*randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]]]*powerScheduleEntry
+
+	// coverageLocationHitCounts describes how many call sequences tracked by the power schedule reached each coverage
+	// location.
+	coverageLocationHitCounts map[coverage.CoverageLocation]uint64
+
+	// coverageLocationEntries describes the call sequences tracked by the power schedule which reached each coverage
+	// location, so only their weights need to be updated when its hit count changes.
+	coverageLocationEntries map[coverage.CoverageLocation]map[*powerScheduleEntry]struct{}
+
+	// mutationHistories describes the mutation history of each corpus entry in the weightedCallSequenceChooser which
+	// can still be chosen.
+	mutationHistories map[*corpusFile[calls.CallSequence]]*CallSequenceMutationHistory
+
+	// mutationHistoriesLock provides thread synchronization to prevent concurrent access errors into
+	// mutationHistories.
+	mutationHistoriesLock sync.Mutex
+
+	// randomProvider describes the provider used to select random call sequences from the corpus. If nil, a provider
+	// seeded from the current time is used.
+	randomProvider *rand.Rand
+
+	// coverageFeedback describes the coverage signal which determines whether a call sequence achieved new coverage
+	// and should be added to the corpus.
+	coverageFeedback coverage.CoverageFeedback
+
+	// hitCountsEnabled describes whether coverage records the hit count bucket of each location within a call, such
+	// that hitting a location in a new hit count bucket is considered new coverage.
+	hitCountsEnabled bool
+
+	// proxyImplementations describes the names of the implementation contracts configured for proxies, keyed by the
+	// name or address of the proxy, used to resolve calls made to implementation methods through proxies.
+	proxyImplementations map[string]string
+
+	// replayWorkers describes the amount of call sequences replayed in parallel to measure coverage when the corpus is
+	// initialized.
+	replayWorkers int
+
+	// compressionEnabled describes whether call sequence files written to the corpus directory are gzip compressed.
+	compressionEnabled bool
+
+	// callSequenceHashes describes the canonical hashes of the call sequences known to be in the corpus, so that
+	// equivalent call sequences discovered by different workers are only added once.
+	callSequenceHashes map[common.Hash]struct{}
+
+	// duplicateCallSequenceCount describes the count of call sequences which were not added to the corpus as an
+	// equivalent call sequence was already in it.
+	duplicateCallSequenceCount uint64
+
+	// stateDeduplicationEnabled describes whether call sequences adding little coverage are deduplicated by the state
+	// they reach, and stateDeduplicationMaxCoverageDelta describes the maximum amount of newly covered locations such
+	// call sequences may add.
+	stateDeduplicationEnabled          bool
+	stateDeduplicationMaxCoverageDelta int
+
+	// stateHashEntries describes the call sequences added to the corpus in the current campaign, keyed by the hash of
+	// the state they reached, if state deduplication is enabled.
+	stateHashEntries map[common.Hash]*stateHashEntry
+
+	// stateDuplicateCallSequenceCount describes the count of call sequences which were deduplicated against a call
+	// sequence in the corpus reaching the same state.
+	stateDuplicateCallSequenceCount uint64
+
+	// replacedCallSequenceCount describes the count of call sequences in the weightedCallSequenceChooser which were
+	// replaced by shorter call sequences reaching the same state, and were disabled.
+	replacedCallSequenceCount int
+
+	// replacedCallSequenceFilePaths describes the files of call sequences replaced by shorter call sequences reaching
+	// the same state, which are deleted on the next Flush.
+	replacedCallSequenceFilePaths []string
+
+	// fullReplayForced describes whether every call sequence should be replayed on startup, even if coverage maps
+	// persisted for the same compiled bytecode could be loaded instead.
+	fullReplayForced bool
+
+	// bytecodeHash describes the hash of the compiled contracts the corpus was initialized with, and the fuzzed
+	// constructor arguments they were deployed with, used to determine whether persisted coverage maps apply to them.
+	bytecodeHash common.Hash
+
+	// fuzzedConstructorArgs describes the constructor argument values generated for fuzzed constructor arguments of the
+	// contracts the corpus is initialized with, keyed by contract name and then argument name.
+	fuzzedConstructorArgs map[string]map[string]any
+
+	// pendingReplays describes the corpus files whose call sequences were loaded without being replayed, as their
+	// coverage was loaded from persisted coverage maps, keyed by the pointer to their data returned by
+	// UnexecutedCallSequence. They are added to the weightedCallSequenceChooser once the fuzzer has resolved them while
+	// executing them.
+	pendingReplays map[*calls.CallSequence]*corpusFile[calls.CallSequence]
 
 	// callSequencesLock provides thread synchronization to prevent concurrent access errors into
 	// callSequences.
 	callSequencesLock sync.Mutex
+
+	// Events describes the event system for the Corpus.
+	Events CorpusEvents
 }
 
 // corpusFile represents corpus data and its state on the filesystem.
@@ -54,22 +177,37 @@ type corpusFile[T any] struct {
 
 	// data describes an object whose data should be written to the file.
 	data T
+
+	// metadata describes the provenance of the data, or nil if it is unknown (e.g. the file predates metadata).
+	metadata *CallSequenceMetadata
 }
 
 // NewCorpus initializes a new Corpus object, reading artifacts from the provided directory. If the directory refers
 // to an empty path, artifacts will not be persistently stored.
 func NewCorpus(corpusDirectory string) (*Corpus, error) {
 	corpus := &Corpus{
-		storageDirectory:        corpusDirectory,
-		coverageMaps:            coverage.NewCoverageMaps(),
-		callSequences:           make([]*corpusFile[calls.CallSequence], 0),
-		unexecutedCallSequences: make([]calls.CallSequence, 0),
+		storageDirectory:          corpusDirectory,
+		coverageMaps:              coverage.NewCoverageMaps(),
+		callSequences:             make([]*corpusFile[calls.CallSequence], 0),
+		optimizationCallSequences: make([]*corpusFile[calls.CallSequence], 0),
+		unexecutedCallSequences:   make([]*corpusFile[calls.CallSequence], 0),
+		callSequenceHashes:        make(map[common.Hash]struct{}),
+		stateHashEntries:          make(map[common.Hash]*stateHashEntry),
 	}
 
 	// If we have a corpus directory set, parse it.
 	if corpus.storageDirectory != "" {
-		// Read all call sequences discovered in the relevant corpus directory.
-		matches, err := filepath.Glob(filepath.Join(corpus.CallSequencesDirectory(), "*.json"))
+		// Verify the corpus directory was not written by a newer version of the fuzzer, in a format we cannot read.
+		version, err := corpus.readVersion()
+		if err != nil {
+			return nil, err
+		}
+		if version > corpusVersion {
+			return nil, fmt.Errorf("corpus directory '%v' was written with corpus version %v, which is newer than the supported version %v", corpus.storageDirectory, version, corpusVersion)
+		}
+
+		// Read all call sequences discovered in the relevant corpus directory, whether compressed or not.
+		matches, err := corpus.callSequenceFilePaths()
 		if err != nil {
 			return nil, err
 		}
@@ -78,23 +216,33 @@ func NewCorpus(corpusDirectory string) (*Corpus, error) {
 			filePath := matches[i]
 
 			// Read the call sequence data.
-			b, err := os.ReadFile(filePath)
+			b, err := readCorpusFile(filePath)
 			if err != nil {
 				return nil, err
 			}
 
-			// Parse the call sequence data.
-			var seq calls.CallSequence
-			err = json.Unmarshal(b, &seq)
+			// Parse the call sequence data and its metadata, if it has any.
+			seq, metadata, err := unmarshalCallSequenceFile(b)
 			if err != nil {
 				return nil, err
 			}
 
-			// Add entry to corpus
+			// Add entry to corpus. Its canonical hash is recorded in its metadata, if it has any, as its ABI values
+			// cannot be packed to calculate it until the corpus is initialized.
 			corpus.callSequences = append(corpus.callSequences, &corpusFile[calls.CallSequence]{
 				filePath: filePath,
 				data:     seq,
+				metadata: metadata,
 			})
+			if metadata != nil {
+				corpus.callSequenceHashes[metadata.Hash] = struct{}{}
+			}
+		}
+
+		// Read the best call sequences of optimization tests from previous runs.
+		corpus.optimizationCallSequences, err = corpus.readOptimizationCallSequences()
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -113,6 +261,77 @@ func (c *Corpus) StorageDirectory() string {
 	return c.storageDirectory
 }
 
+// VersionFilePath returns the file path where the version of the corpus directory is stored. This is a file within
+// StorageDirectory. If StorageDirectory is empty, this is as well, indicating persistent storage is not enabled.
+func (c *Corpus) VersionFilePath() string {
+	if c.storageDirectory == "" {
+		return ""
+	}
+	return filepath.Join(c.StorageDirectory(), "version.json")
+}
+
+// readVersion reads the version of the corpus directory from its version file.
+// Returns the version of the corpus directory, or zero if it has no version file. Returns an error if the version
+// file could not be read or parsed.
+func (c *Corpus) readVersion() (int, error) {
+	b, err := os.ReadFile(c.VersionFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var versionInfo corpusVersionInfo
+	err = json.Unmarshal(b, &versionInfo)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse corpus version file '%v': %v", c.VersionFilePath(), err)
+	}
+	return versionInfo.Version, nil
+}
+
+// writeVersion writes the current corpusVersion to the version file in the corpus directory. The corpus directory
+// is expected to exist.
+// Returns an error if one occurs.
+func (c *Corpus) writeVersion() error {
+	jsonEncodedData, err := json.MarshalIndent(corpusVersionInfo{Version: corpusVersion}, "", " ")
+	if err != nil {
+		return err
+	}
+	err = writeCorpusFile(c.VersionFilePath(), jsonEncodedData, false)
+	if err != nil {
+		return fmt.Errorf("An error occurred while writing corpus version to disk: %v\n", err)
+	}
+	return nil
+}
+
+// SetCoverageFeedback sets the coverage signal which determines whether a call sequence achieved new coverage, and
+// should be added by AddCallSequenceIfCoverageChanged. If edge coverage is used, it is also recorded when the corpus
+// is initialized. This must be set prior to Initialize to take effect.
+func (c *Corpus) SetCoverageFeedback(coverageFeedback coverage.CoverageFeedback) {
+	c.coverageFeedback = coverageFeedback
+}
+
+// SetHitCountsEnabled sets whether coverage measured when the corpus is initialized records the hit count bucket of
+// each location, which should match the coverage measured by the fuzzer. This must be set prior to Initialize to take
+// effect.
+func (c *Corpus) SetHitCountsEnabled(enabled bool) {
+	c.hitCountsEnabled = enabled
+}
+
+// SetFuzzedConstructorArgs sets the constructor argument values generated for fuzzed constructor arguments of the
+// contracts the corpus is initialized with, keyed by contract name and then argument name, so persisted coverage maps
+// are only loaded for contracts deployed with the same arguments. This must be set prior to Initialize to take effect.
+func (c *Corpus) SetFuzzedConstructorArgs(fuzzedConstructorArgs map[string]map[string]any) {
+	c.fuzzedConstructorArgs = fuzzedConstructorArgs
+}
+
+// SetRandomProvider sets the random provider used to select random call sequences from the corpus. This must be set
+// prior to Initialize to take effect.
+func (c *Corpus) SetRandomProvider(randomProvider *rand.Rand) {
+	c.randomProvider = randomProvider
+}
+
 // CallSequencesDirectory returns the directory path where coverage increasing call sequences should be stored.
 // This is a subdirectory of StorageDirectory. If StorageDirectory is empty, this is as well, indicating persistent
 // storage will not be used.
@@ -128,184 +347,197 @@ func (c *Corpus) CallSequenceCount() int {
 	return len(c.callSequences)
 }
 
+// DuplicateCallSequenceCount returns the count of call sequences which were not added to the corpus, as an equivalent
+// call sequence (with the same canonical hash) was already in it.
+func (c *Corpus) DuplicateCallSequenceCount() uint64 {
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+	return c.duplicateCallSequenceCount
+}
+
 // ActiveCallSequenceCount returns the count of call sequences recorded in the corpus which have been validated and are
 // ready for use by RandomCallSequence.
 func (c *Corpus) ActiveCallSequenceCount() int {
 	if c.weightedCallSequenceChooser == nil {
 		return 0
 	}
-	return c.weightedCallSequenceChooser.ChoiceCount()
+	return c.weightedCallSequenceChooser.ChoiceCount() - c.replacedCallSequenceCount
 }
 
 // Initialize initializes any runtime data needed for a Corpus on startup. Call sequences are replayed on the post-setup
-// (deployment) test chain to calculate coverage, while resolving references to compiled contracts.
+// (deployment) test chain to calculate coverage, while resolving references to compiled contracts. If coverage maps
+// were persisted for the same compiled contracts (see WriteCoverageMaps), they are loaded instead, and the call
+// sequences they cover are resolved by the fuzzer as it executes them (see UnexecutedCallSequence).
 func (c *Corpus) Initialize(baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts) error {
 	// Acquire our call sequences lock during the duration of this method.
 	c.callSequencesLock.Lock()
 	defer c.callSequencesLock.Unlock()
 
 	// Initialize our call sequence structures.
-	c.weightedCallSequenceChooser = randomutils.NewWeightedRandomChooser[calls.CallSequence]()
-	c.unexecutedCallSequences = make([]calls.CallSequence, 0)
-
-	// Create new coverage maps to track total coverage and a coverage tracer to do so.
+	if c.randomProvider != nil {
+		c.weightedCallSequenceChooser = randomutils.NewWeightedRandomChooserWithRand[*corpusFile[calls.CallSequence]](c.randomProvider, &sync.Mutex{})
+	} else {
+		c.weightedCallSequenceChooser = randomutils.NewWeightedRandomChooser[*corpusFile[calls.CallSequence]]()
+	}
+	c.callSequenceChoices = make([]*randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]], 0)
+	c.mutationHistoriesLock.Lock()
+	c.mutationHistories = make(map[*corpusFile[calls.CallSequence]]*CallSequenceMutationHistory)
+	c.mutationHistoriesLock.Unlock()
+	c.unexecutedCallSequences = make([]*corpusFile[calls.CallSequence], 0)
+	c.powerScheduleEntries = make(map[*randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]]]*powerScheduleEntry)
+	c.coverageLocationHitCounts = make(map[coverage.CoverageLocation]uint64)
+	c.coverageLocationEntries = make(map[coverage.CoverageLocation]map[*powerScheduleEntry]struct{})
+	c.pendingReplays = make(map[*calls.CallSequence]*corpusFile[calls.CallSequence])
+	c.replacedCallSequenceCount = 0
+	c.stateHashEntries = make(map[common.Hash]*stateHashEntry)
+
+	// Create new coverage maps to track total coverage.
 	c.coverageMaps = coverage.NewCoverageMaps()
-	coverageTracer := coverage.NewCoverageTracer()
-
-	// Create our structure and event listeners to track deployed contracts
-	deployedContracts := make(map[common.Address]*contracts.Contract, 0)
-
-	// Clone our test chain, adding listeners for contract deployment events from genesis.
-	testChain, err := baseTestChain.Clone(func(newChain *chain.TestChain) error {
-		// After genesis, prior to adding other blocks, we attach our coverage tracer
-		newChain.AddTracer(coverageTracer, true, false)
-
-		// We also track any contract deployments, so we can resolve contract/method definitions for corpus call
-		// sequences.
-		newChain.Events.ContractDeploymentAddedEventEmitter.Subscribe(func(event chain.ContractDeploymentsAddedEvent) error {
-			matchedContract := contractDefinitions.MatchBytecode(event.Contract.InitBytecode, event.Contract.RuntimeBytecode)
-			if matchedContract != nil {
-				deployedContracts[event.Contract.Address] = matchedContract
-			}
-			return nil
-		})
-		newChain.Events.ContractDeploymentRemovedEventEmitter.Subscribe(func(event chain.ContractDeploymentsRemovedEvent) error {
-			delete(deployedContracts, event.Contract.Address)
-			return nil
-		})
-		return nil
-	})
+
+	// If we have coverage maps persisted for the same compiled contracts, load them, and queue the call sequences they
+	// cover for execution without replaying them.
+	c.bytecodeHash = ContractsBytecodeHash(contractDefinitions, c.fuzzedConstructorArgs)
+	sequencesToReplay, err := c.loadCoverageMaps()
 	if err != nil {
-		return fmt.Errorf("failed to initialize coverage maps, base test chain cloning encountered error: %v", err)
+		return fmt.Errorf("failed to initialize coverage maps from corpus: %v", err)
 	}
 
-	// Next we replay every call sequence, checking its validity on this chain and measuring coverage. If the sequence
-	// is valid, we add it to our weighted list for future random selection.
-
-	// Cache current HeadBlockNumber so that you can reset back to it after every sequence
-	baseBlockNumber := testChain.HeadBlockNumber()
-
-	// Loop for each sequence
-	for _, sequenceFileData := range c.callSequences {
-		// Unwrap the underlying sequence.
-		sequence := sequenceFileData.data
-
-		// Define a variable to track whether we should disable this sequence (if it is no longer applicable in some
-		// way).
-		sequenceInvalidError := error(nil)
-		fetchElementFunc := func(currentIndex int) (*calls.CallSequenceElement, error) {
-			// If we are at the end of our sequence, return nil indicating we should stop executing.
-			if currentIndex >= len(sequence) {
-				return nil, nil
-			}
-
-			// If we are deploying a contract and not targeting one with this call, there should be no work to do.
-			currentSequenceElement := sequence[currentIndex]
-			if currentSequenceElement.Call.MsgTo == nil {
-				return currentSequenceElement, nil
-			}
-
-			// We are calling a contract with this call, ensure we can resolve the contract call is targeting.
-			resolvedContract, resolvedContractExists := deployedContracts[*currentSequenceElement.Call.MsgTo]
-			if !resolvedContractExists {
-				sequenceInvalidError = fmt.Errorf("contract at address '%v' could not be resolved", currentSequenceElement.Call.MsgTo.String())
-				return nil, nil
-			}
-			currentSequenceElement.Contract = resolvedContract
-
-			// Next, if our sequence element uses ABI values to produce call data, our deserialized data is not yet
-			// sufficient for runtime use, until we use it to resolve runtime references.
-			callAbiValues := currentSequenceElement.Call.MsgDataAbiValues
-			if callAbiValues != nil {
-				sequenceInvalidError = callAbiValues.Resolve(currentSequenceElement.Contract.CompiledContract().Abi)
-				if sequenceInvalidError != nil {
-					return nil, nil
-				}
-			}
-			return currentSequenceElement, nil
-		}
+	// Next we replay every call sequence in parallel, checking its validity on this chain and measuring coverage. The
+	// best call sequences of optimization tests, and those restored from a checkpoint, are always replayed, so they
+	// are executed as soon as fuzzing starts.
+	sequencesToReplay = append(sequencesToReplay, c.optimizationCallSequences...)
+	sequencesToReplay = append(sequencesToReplay, c.resumedCallSequences...)
+	replayResults, err := c.replayCallSequenceFilesForCoverage(sequencesToReplay, baseTestChain, contractDefinitions)
+	if err != nil {
+		return fmt.Errorf("failed to initialize coverage maps from corpus: %v", err)
+	}
 
-		// Define actions to perform after executing each call in the sequence.
-		executionCheckFunc := func(currentlyExecutedSequence calls.CallSequence) (bool, error) {
-			// Update our coverage maps for each call executed in our sequence.
-			lastExecutedSequenceElement := currentlyExecutedSequence[len(currentlyExecutedSequence)-1]
-			covMaps := coverage.GetCoverageTracerResults(lastExecutedSequenceElement.ChainReference.MessageResults())
-			_, covErr := c.coverageMaps.Update(covMaps)
-			if covErr != nil {
-				return true, covErr
-			}
-			return false, nil
+	// If a sequence was replayed successfully, we add a weighted choice for it, for future selection, and queue it to
+	// be executed by workers, so any tests it fails are reported as they would be for any other call sequence. If it
+	// was not, we simply exclude it from our chooser and print a warning. Results are processed in the order the call
+	// sequences were read, so the corpus does not depend on the order they were replayed in.
+	for i, sequenceFileData := range sequencesToReplay {
+		if sequenceInvalidError := replayResults[i].sequenceInvalidError; sequenceInvalidError != nil {
+			logging.GlobalLogger.Warn().Str("file", sequenceFileData.filePath).Err(sequenceInvalidError).Msgf("corpus item '%v' disabled due to error when replaying it: %v", sequenceFileData.filePath, sequenceInvalidError)
+			continue
 		}
-
-		// Execute each call sequence, populating runtime data and collecting coverage data along the way.
-		_, err = calls.ExecuteCallSequenceIteratively(testChain, fetchElementFunc, executionCheckFunc)
-
-		// If we failed to replay a sequence and measure coverage due to an unexpected error, report it.
-		if err != nil {
-			return fmt.Errorf("failed to initialize coverage maps from corpus, encountered an error while executing call sequence: %v\n", err)
+		c.addCallSequenceChoice(sequenceFileData, big.NewInt(1), replayResults[i].coveredLocations)
+		c.unexecutedCallSequences = append(c.unexecutedCallSequences, sequenceFileData)
+		if seqHash, err := sequenceFileData.data.CanonicalHash(); err == nil {
+			c.callSequenceHashes[seqHash] = struct{}{}
 		}
+	}
+	return nil
+}
 
-		// If the sequence was replayed successfully, we add a weighted choice for it, for future selection. If it was
-		// not, we simply exclude it from our chooser and print a warning.
-		if sequenceInvalidError == nil {
-			c.weightedCallSequenceChooser.AddChoices(randomutils.NewWeightedRandomChoice[calls.CallSequence](sequence, big.NewInt(1)))
-			c.unexecutedCallSequences = append(c.unexecutedCallSequences, sequence)
-		} else {
-			fmt.Printf("corpus item '%v' disabled due to error when replaying it: %v\n", sequenceFileData.filePath, sequenceInvalidError)
-		}
+// addCallSequenceChoice adds a corpus entry to the weighted random chooser with the provided weight. If the power
+// schedule is enabled, the call sequence is also tracked by it, using the provided coverage locations it reached.
+// The caller must hold the call sequences lock.
+// Returns the choice added.
+func (c *Corpus) addCallSequenceChoice(entry *corpusFile[calls.CallSequence], weight *big.Int, coveredLocations []coverage.CoverageLocation) *randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]] {
+	choice := randomutils.NewWeightedRandomChoice[*corpusFile[calls.CallSequence]](entry, weight)
+	c.weightedCallSequenceChooser.AddChoices(choice)
+	c.callSequenceChoices = append(c.callSequenceChoices, choice)
+	c.mutationHistoriesLock.Lock()
+	c.mutationHistories[entry] = NewCallSequenceMutationHistory()
+	c.mutationHistoriesLock.Unlock()
+	if c.powerScheduleEnabled {
+		c.addPowerScheduleEntry(choice, coveredLocations)
+	}
+	return choice
+}
 
-		// Revert chain state to our starting point to test the next sequence.
-		err = testChain.RevertToBlockNumber(baseBlockNumber)
-		if err != nil {
-			return fmt.Errorf("failed to reset the chain while seeding coverage: %v\n", err)
-		}
+// setCallSequenceChoiceWeight sets the weight of a corpus entry in the weighted random chooser. If the weight is zero,
+// the entry can no longer be chosen, so its mutation history is discarded. The caller must hold the call sequences
+// lock.
+func (c *Corpus) setCallSequenceChoiceWeight(choice *randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]], weight *big.Int) {
+	c.weightedCallSequenceChooser.SetChoiceWeight(choice, weight)
+	if weight.Sign() == 0 {
+		c.mutationHistoriesLock.Lock()
+		delete(c.mutationHistories, choice.Data)
+		c.mutationHistoriesLock.Unlock()
 	}
-	return nil
 }
 
-// AddCallSequence adds a call sequence to the corpus and returns an error in case of an issue
-func (c *Corpus) AddCallSequence(seq calls.CallSequence, weight *big.Int, flushImmediately bool) error {
+// AddCallSequence adds a call sequence to the corpus and returns an error in case of an issue. The provided metadata
+// describes the provenance of the call sequence and may be nil. Its hash and creation time are set by the corpus.
+func (c *Corpus) AddCallSequence(seq calls.CallSequence, weight *big.Int, metadata *CallSequenceMetadata, flushImmediately bool) error {
+	return c.addCallSequence(seq, weight, metadata, flushImmediately, nil, false)
+}
+
+// addCallSequence adds a call sequence to the corpus with the provided metadata, alongside the coverage locations it
+// reached for use in the power schedule, and returns an error in case of an issue. If the metadata records the hash
+// of the state the call sequence reached, it is tracked by it, and if deduplicateByState is true, the call sequence
+// is deduplicated against the call sequence which reached the same state (see resolveStateDuplicate).
+func (c *Corpus) addCallSequence(seq calls.CallSequence, weight *big.Int, metadata *CallSequenceMetadata, flushImmediately bool, coveredLocations []coverage.CoverageLocation, deduplicateByState bool) error {
 	// Acquire a thread lock during modification of call sequence lists.
 	c.callSequencesLock.Lock()
 
-	// Check if call sequence has been added before, if so, exit without any action.
-	seqHash, err := seq.Hash()
+	// Check if an equivalent call sequence has been added before, if so, record the duplicate and exit without any
+	// other action.
+	seqHash, err := seq.CanonicalHash()
 	if err != nil {
+		c.callSequencesLock.Unlock()
 		return err
 	}
+	if _, exists := c.callSequenceHashes[seqHash]; exists {
+		c.duplicateCallSequenceCount++
+		c.callSequencesLock.Unlock()
+		return nil
+	}
 
-	// Verify no existing corpus item hash this same hash.
-	for _, existingSeq := range c.callSequences {
-		// Calculate the existing sequence hash
-		existingSeqHash, err := existingSeq.data.Hash()
-		if err != nil {
-			return err
-		}
+	// If we are deduplicating by state and a call sequence reaching the same state is at least as short, exit
+	// without any other action.
+	if deduplicateByState && metadata != nil && metadata.StateHash != nil && !c.resolveStateDuplicate(seq, *metadata.StateHash) {
+		c.callSequencesLock.Unlock()
+		return nil
+	}
+	c.callSequenceHashes[seqHash] = struct{}{}
 
-		// Verify it is unique, if it is not, we quit immediately to avoid duplicate sequences being added.
-		if bytes.Equal(existingSeqHash[:], seqHash[:]) {
-			c.callSequencesLock.Unlock()
-			return nil
-		}
+	// Record the hash and creation time of the entry in a copy of its metadata.
+	entryMetadata := &CallSequenceMetadata{}
+	if metadata != nil {
+		*entryMetadata = *metadata
 	}
+	entryMetadata.Hash = seqHash
+	entryMetadata.CreatedAt = time.Now()
 
 	// Update our sequences with the new entry.
-	c.callSequences = append(c.callSequences, &corpusFile[calls.CallSequence]{
+	sequenceFile := &corpusFile[calls.CallSequence]{
 		filePath: "",
 		data:     seq,
-	})
+		metadata: entryMetadata,
+	}
+	c.callSequences = append(c.callSequences, sequenceFile)
 
 	// If we have initialized a chooser, add our call sequence item to it.
+	var choice *randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]]
 	if c.weightedCallSequenceChooser != nil {
 		if weight == nil {
 			weight = big.NewInt(1)
 		}
-		c.weightedCallSequenceChooser.AddChoices(randomutils.NewWeightedRandomChoice[calls.CallSequence](seq, weight))
+		choice = c.addCallSequenceChoice(sequenceFile, weight, coveredLocations)
 	}
 
-	// Unlock now, as flushing will lock on its own.
+	// Track the entry by the state it reached, if it is the first to reach it.
+	if entryMetadata.StateHash != nil {
+		if _, exists := c.stateHashEntries[*entryMetadata.StateHash]; !exists {
+			c.stateHashEntries[*entryMetadata.StateHash] = &stateHashEntry{sequenceFile: sequenceFile, choice: choice}
+		}
+	}
+
+	// Unlock now, as flushing and event handlers will lock on their own.
 	c.callSequencesLock.Unlock()
 
+	// Publish an event indicating the call sequence was added.
+	err = c.Events.CallSequenceAdded.Publish(CallSequenceAddedEvent{
+		CallSequence: seq,
+		Metadata:     entryMetadata,
+	})
+	if err != nil {
+		return fmt.Errorf("error returned by an event handler when the corpus emitted an event indicating a call sequence was added: %v", err)
+	}
+
 	// Flush changes to disk if requested.
 	if flushImmediately {
 		return c.Flush()
@@ -316,12 +548,22 @@ func (c *Corpus) AddCallSequence(seq calls.CallSequence, weight *big.Int, flushI
 
 // AddCallSequenceIfCoverageChanged checks if the most recent call executed in the provided call sequence achieved
 // coverage the Corpus did not with any of its call sequences. If it did, the call sequence is added to the corpus
-// and the Corpus coverage maps are updated accordingly.
-// Returns an error if one occurs.
-func (c *Corpus) AddCallSequenceIfCoverageChanged(callSequence calls.CallSequence, weight *big.Int, flushImmediately bool) error {
+// with the provided metadata (which may be nil), recording a hash of the coverage the call reached, and the Corpus
+// coverage maps are updated accordingly.
+// Returns a boolean indicating whether coverage increased, or an error if one occurs.
+func (c *Corpus) AddCallSequenceIfCoverageChanged(callSequence calls.CallSequence, weight *big.Int, metadata *CallSequenceMetadata, flushImmediately bool) (bool, error) {
+	return c.AddCallSequenceIfCoverageChangedWithStateHash(callSequence, weight, metadata, flushImmediately, nil)
+}
+
+// AddCallSequenceIfCoverageChangedWithStateHash behaves like AddCallSequenceIfCoverageChanged, but if state
+// deduplication is enabled (see SetStateDeduplication) and stateHashFunc is non-nil, it is called to obtain a hash of
+// the state the call sequence reached, which is recorded in its metadata. If the call sequence added little coverage
+// and a call sequence added earlier in the campaign reached the same state, only the shorter of the two is kept.
+// Returns a boolean indicating whether coverage increased, or an error if one occurs.
+func (c *Corpus) AddCallSequenceIfCoverageChangedWithStateHash(callSequence calls.CallSequence, weight *big.Int, metadata *CallSequenceMetadata, flushImmediately bool, stateHashFunc func() (common.Hash, error)) (bool, error) {
 	// If we have coverage-guided fuzzing disabled or no calls in our sequence, there is nothing to do.
 	if len(callSequence) == 0 {
-		return nil
+		return false, nil
 	}
 
 	// Obtain our coverage maps for our last call.
@@ -331,70 +573,158 @@ func (c *Corpus) AddCallSequenceIfCoverageChanged(callSequence calls.CallSequenc
 
 	// If we have none, because a coverage tracer wasn't attached when processing this call, we can stop.
 	if lastMessageCoverageMaps == nil {
-		return nil
+		return false, nil
 	}
 
 	// Memory optimization: Remove them from the results now that we obtained them, to free memory later.
 	coverage.RemoveCoverageTracerResults(lastMessageResult)
 
-	// Merge the coverage maps into our total coverage maps and check if we had an update.
-	coverageUpdated, err := c.coverageMaps.Update(lastMessageCoverageMaps)
+	// If we use a power schedule, collect the locations covered by this call prior to merging it into our total
+	// coverage maps.
+	var coveredLocations []coverage.CoverageLocation
+	if c.powerScheduleEnabled {
+		coveredLocations = lastMessageCoverageMaps.CoveredLocations()
+	}
+
+	// Merge the coverage maps into our total coverage maps and check if we had an update to the coverage signal we
+	// use as feedback.
+	pcLocationsChanged, edgeLocationsChanged, err := c.coverageMaps.UpdateWithChangeCounts(lastMessageCoverageMaps)
 	if err != nil {
-		return err
+		return false, err
 	}
+	coverageUpdated := c.coverageFeedback.CoverageChanged(pcLocationsChanged > 0, edgeLocationsChanged > 0)
 	if coverageUpdated {
-		// New coverage has been found with this call sequence, so we add it to the corpus.
-		err = c.AddCallSequence(callSequence, weight, flushImmediately)
+		// New coverage has been found with this call sequence, so we add it to the corpus, recording the coverage
+		// which caused it to be added.
+		if coveredLocations == nil {
+			coveredLocations = lastMessageCoverageMaps.CoveredLocations()
+		}
+		entryMetadata := &CallSequenceMetadata{}
+		if metadata != nil {
+			*entryMetadata = *metadata
+		}
+		entryCoverageHash := coverageHash(coveredLocations)
+		entryMetadata.CoverageHash = &entryCoverageHash
+
+		// If we deduplicate by state, record the state the call sequence reached, and deduplicate it against other
+		// call sequences reaching it if it added little coverage.
+		deduplicateByState := false
+		if c.stateDeduplicationEnabled && stateHashFunc != nil {
+			stateHash, err := stateHashFunc()
+			if err != nil {
+				return true, err
+			}
+			entryMetadata.StateHash = &stateHash
+			deduplicateByState = c.coverageFeedback.CoverageDelta(pcLocationsChanged, edgeLocationsChanged) <= c.stateDeduplicationMaxCoverageDelta
+		}
+		err = c.addCallSequence(callSequence, weight, entryMetadata, flushImmediately, coveredLocations, deduplicateByState)
 		if err != nil {
-			return err
+			return true, err
 		}
 	}
-	return nil
+	return coverageUpdated, nil
 }
 
 // RandomCallSequence returns a weighted random call sequence from the Corpus, or an error if one occurs.
 func (c *Corpus) RandomCallSequence() (calls.CallSequence, error) {
+	seq, _, err := c.RandomCallSequenceWithMutationHistory()
+	return seq, err
+}
+
+// RandomCallSequenceWithMutationHistory returns a weighted random call sequence from the Corpus, alongside the
+// mutation history of the corpus entry it was cloned from. Returns an error if one occurs.
+func (c *Corpus) RandomCallSequenceWithMutationHistory() (calls.CallSequence, *CallSequenceMutationHistory, error) {
 	// If we didn't initialize a chooser, return an error
 	if c.weightedCallSequenceChooser == nil {
-		return nil, fmt.Errorf("corpus could not return a random call sequence because the corpus was not initialized")
+		return nil, nil, fmt.Errorf("corpus could not return a random call sequence because the corpus was not initialized")
 	}
 
-	// Pick a random call sequence, then clone it before returning it, so the original is untainted.
-	seq, err := c.weightedCallSequenceChooser.Choose()
-	if seq == nil || err != nil {
-		return nil, err
+	// Pick a random corpus entry.
+	entry, err := c.weightedCallSequenceChooser.Choose()
+	if entry == nil || err != nil {
+		return nil, nil, err
+	}
+
+	// Clone the call sequence before returning it, so the original is untainted.
+	clonedSeq, err := (*entry).data.Clone()
+	if err != nil {
+		return nil, nil, err
 	}
-	return seq.Clone()
+
+	// Obtain the mutation history for the chosen entry.
+	c.mutationHistoriesLock.Lock()
+	mutationHistory := c.mutationHistories[*entry]
+	c.mutationHistoriesLock.Unlock()
+	return clonedSeq, mutationHistory, nil
 }
 
 // UnexecutedCallSequence returns a call sequence loaded from disk which has not yet been returned by this method.
 // It is intended to be used by the fuzzer to run all un-executed call sequences (without mutations) to check for test
-// failures. If a call sequence is returned, it will not be returned by this method again.
-// Returns a call sequence loaded from disk which has not yet been executed, to check for test failures. If all
-// sequences in the corpus have been executed, this will return nil.
-func (c *Corpus) UnexecutedCallSequence() *calls.CallSequence {
+// failures. If a call sequence is returned, it will not be returned by this method again, unless it was pending
+// resolution and is returned to the corpus through ResolvePendingCallSequence.
+// Returns a call sequence loaded from disk which has not yet been executed, to check for test failures, and a boolean
+// indicating whether it is pending resolution. If all sequences in the corpus have been executed, this will return
+// nil. Call sequences pending resolution were loaded along with persisted coverage maps without being replayed, so
+// their references to deployed contracts and their methods must be resolved as they are executed, after which their
+// outcome must be reported through ResolvePendingCallSequence.
+func (c *Corpus) UnexecutedCallSequence() (*calls.CallSequence, bool) {
 	// Prior to thread locking, if we have no un-executed call sequences, quit.
 	// This is a speed optimization, as thread locking on a central component affects performance.
 	if len(c.unexecutedCallSequences) == 0 {
-		return nil
+		return nil, false
 	}
 
-	// Acquire a thread lock for the duration of this method.
+	// Acquire a thread lock while obtaining an item.
 	c.callSequencesLock.Lock()
 	defer c.callSequencesLock.Unlock()
 
 	// Check that we have an item now that the thread is locked. This must be performed again as an item could've
 	// been removed between time of check (the prior exit condition) and time of use (thread locked operations).
 	if len(c.unexecutedCallSequences) == 0 {
-		return nil
+		return nil, false
 	}
 
 	// Otherwise obtain the first item and remove it from the slice.
-	firstSequence := c.unexecutedCallSequences[0]
+	firstSequenceFile := c.unexecutedCallSequences[0]
 	c.unexecutedCallSequences = c.unexecutedCallSequences[1:]
+	_, pending := c.pendingReplays[&firstSequenceFile.data]
+	return &firstSequenceFile.data, pending
+}
+
+// ResolvePendingCallSequence reports the outcome of executing a call sequence pending resolution, as returned by
+// UnexecutedCallSequence. If every element of the call sequence was resolved, it is added to the corpus for
+// selection. If one could not be resolved, as described by the provided error, the call sequence is disabled, as it
+// is no longer applicable. Otherwise, if execution stopped before every element was resolved (e.g. a test failed), it
+// is returned to the corpus to be executed again.
+func (c *Corpus) ResolvePendingCallSequence(seq *calls.CallSequence, resolved bool, sequenceInvalidError error) {
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+
+	// If the call sequence is not pending resolution, there is nothing to do.
+	sequenceFile, ok := c.pendingReplays[seq]
+	if !ok {
+		return
+	}
+
+	// If the call sequence could not be resolved, it is no longer applicable, so we disable it.
+	if sequenceInvalidError != nil {
+		delete(c.pendingReplays, seq)
+		logging.GlobalLogger.Warn().Str("file", sequenceFile.filePath).Err(sequenceInvalidError).Msgf("corpus item '%v' disabled due to error when replaying it: %v", sequenceFile.filePath, sequenceInvalidError)
+		return
+	}
+
+	// If it was not fully resolved, we execute it again later.
+	if !resolved {
+		c.unexecutedCallSequences = append(c.unexecutedCallSequences, sequenceFile)
+		return
+	}
 
-	// Return the first sequence
-	return &firstSequence
+	// Otherwise, add it for future selection. Its coverage is already included in our coverage maps.
+	delete(c.pendingReplays, seq)
+	c.addCallSequenceChoice(sequenceFile, big.NewInt(1), nil)
+	if seqHash, err := sequenceFile.data.CanonicalHash(); err == nil {
+		c.callSequenceHashes[seqHash] = struct{}{}
+	}
 }
 
 // Flush writes corpus changes to disk. Returns an error if one occurs.
@@ -417,26 +747,38 @@ func (c *Corpus) Flush() error {
 	if err != nil {
 		return err
 	}
+	err = c.writeVersion()
+	if err != nil {
+		return err
+	}
 
 	// Write all call sequences to disk
 	// TODO: This can be optimized by storing/indexing unwritten sequences separately and only iterating over those.
 	for _, sequenceFile := range c.callSequences {
 		if sequenceFile.filePath == "" {
 			// Determine the file path to write this to.
-			sequenceFile.filePath = filepath.Join(c.CallSequencesDirectory(), uuid.New().String()+".json")
+			extension := ".json"
+			if c.compressionEnabled {
+				extension = compressedCallSequenceFileExtension
+			}
+			filePath := filepath.Join(c.CallSequencesDirectory(), uuid.New().String()+extension)
 
-			// Marshal the call sequence
-			jsonEncodedData, err := json.MarshalIndent(sequenceFile.data, "", " ")
+			// Marshal the call sequence alongside its metadata.
+			jsonEncodedData, err := marshalCallSequenceFile(sequenceFile.data, sequenceFile.metadata)
 			if err != nil {
 				return err
 			}
 
 			// Write the JSON encoded data.
-			err = os.WriteFile(sequenceFile.filePath, jsonEncodedData, os.ModePerm)
+			err = writeCorpusFile(filePath, jsonEncodedData, c.compressionEnabled)
 			if err != nil {
 				return fmt.Errorf("An error occurred while writing call sequence to disk: %v\n", err)
 			}
+			sequenceFile.filePath = filePath
 		}
 	}
-	return nil
+
+	// Remove the files of call sequences replaced by shorter ones, now that the call sequences replacing them were
+	// written.
+	return c.removeReplacedCallSequenceFiles()
 }
@@ -0,0 +1,55 @@
+package corpus
+
+import (
+	"path/filepath"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/utils"
+)
+
+// CheckpointFilePath returns the file path where the checkpoint of the fuzzing campaign using the corpus is stored,
+// which describes campaign state beyond the corpus itself. This is a file within StorageDirectory. If
+// StorageDirectory is empty, this is as well, indicating persistent storage is not enabled.
+func (c *Corpus) CheckpointFilePath() string {
+	if c.storageDirectory == "" {
+		return ""
+	}
+	return filepath.Join(c.StorageDirectory(), "checkpoint.json")
+}
+
+// ReadCheckpoint reads the checkpoint data stored at the CheckpointFilePath.
+// Returns the checkpoint data, or an error if one occurs (e.g. one satisfying os.IsNotExist if no checkpoint was
+// written).
+func (c *Corpus) ReadCheckpoint() ([]byte, error) {
+	return readCorpusFile(c.CheckpointFilePath())
+}
+
+// WriteCheckpoint stores the provided checkpoint data at the CheckpointFilePath, replacing any stored previously. The
+// file is replaced atomically, so an interrupted write leaves the previous checkpoint intact. If StorageDirectory is
+// empty, this does nothing.
+// Returns an error if one occurs.
+func (c *Corpus) WriteCheckpoint(b []byte) error {
+	if c.storageDirectory == "" {
+		return nil
+	}
+	err := utils.MakeDirectory(c.storageDirectory)
+	if err != nil {
+		return err
+	}
+	return writeCorpusFile(c.CheckpointFilePath(), b, false)
+}
+
+// AddResumedCallSequences adds call sequences restored from a checkpoint to be replayed when the corpus is
+// initialized, so they are the first call sequences executed by the fuzzer, alongside the rest of the corpus. They are
+// not written to the corpus directory, and are reported by the CheckpointFilePath if they cannot be replayed. This must
+// be called prior to Initialize to take effect.
+func (c *Corpus) AddResumedCallSequences(sequences ...calls.CallSequence) {
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+	for _, sequence := range sequences {
+		c.resumedCallSequences = append(c.resumedCallSequences, &corpusFile[calls.CallSequence]{
+			filePath: c.CheckpointFilePath(),
+			data:     sequence,
+		})
+	}
+}
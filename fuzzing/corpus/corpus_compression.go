@@ -0,0 +1,70 @@
+package corpus
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crytic/medusa/utils"
+)
+
+// compressedCallSequenceFileExtension describes the file extension of gzip compressed corpus call sequence files.
+const compressedCallSequenceFileExtension = ".json.gz"
+
+// SetCompressionEnabled sets whether call sequences written to the corpus directory should be gzip compressed. Call
+// sequence files are read regardless of whether they are compressed, so existing corpora remain usable either way.
+func (c *Corpus) SetCompressionEnabled(enabled bool) {
+	c.compressionEnabled = enabled
+}
+
+// callSequenceFilePaths obtains the paths of every call sequence file in the call sequences directory, whether
+// compressed or not.
+// Returns the call sequence file paths, or an error if one occurs.
+func (c *Corpus) callSequenceFilePaths() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(c.CallSequencesDirectory(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	compressedMatches, err := filepath.Glob(filepath.Join(c.CallSequencesDirectory(), "*"+compressedCallSequenceFileExtension))
+	if err != nil {
+		return nil, err
+	}
+	return append(matches, compressedMatches...), nil
+}
+
+// readCorpusFile reads the file at the provided path, decompressing it if it is gzip compressed.
+// Returns the (decompressed) file data, or an error if one occurs.
+func readCorpusFile(filePath string) ([]byte, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil || !strings.HasSuffix(filePath, ".gz") {
+		return b, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// writeCorpusFile writes the provided data to the file at the provided path, gzip compressing it if requested. The
+// file is written atomically, so it is never left partially written (e.g. if the fuzzer is interrupted).
+// Returns an error if one occurs.
+func writeCorpusFile(filePath string, data []byte, compress bool) error {
+	if compress {
+		var buffer bytes.Buffer
+		writer := gzip.NewWriter(&buffer)
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		data = buffer.Bytes()
+	}
+	return utils.WriteFileAtomic(filePath, data, 0644)
+}
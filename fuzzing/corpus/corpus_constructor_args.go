@@ -0,0 +1,78 @@
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/crytic/medusa/utils"
+)
+
+// ConstructorArgsFilePath returns the file path where the constructor argument values generated for fuzzed constructor
+// arguments in the last fuzzing campaign should be stored. This is a file within StorageDirectory. If
+// StorageDirectory is empty, this is as well, indicating persistent storage is not enabled.
+func (c *Corpus) ConstructorArgsFilePath() string {
+	if c.storageDirectory == "" {
+		return ""
+	}
+	return filepath.Join(c.StorageDirectory(), "constructor_args.json")
+}
+
+// LoadConstructorArgs reads the constructor argument values stored in the corpus directory, if any were stored.
+// Returns the constructor argument values keyed by contract name and then argument name, in the form they would be
+// provided through the config, or nil if none were stored. Returns an error if stored values exist but could not be
+// read or parsed.
+func (c *Corpus) LoadConstructorArgs() (map[string]map[string]any, error) {
+	// If persistent storage is disabled or no constructor arguments were stored yet, there is nothing to load.
+	filePath := c.ConstructorArgsFilePath()
+	if filePath == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Parse our constructor arguments.
+	var constructorArgs map[string]map[string]any
+	err = json.Unmarshal(b, &constructorArgs)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse constructor arguments stored at '%v': %v", filePath, err)
+	}
+	return constructorArgs, nil
+}
+
+// FlushConstructorArgs writes the provided constructor argument values, keyed by contract name and then argument
+// name, to the corpus directory, replacing any previously stored values.
+// Returns an error if one occurs.
+func (c *Corpus) FlushConstructorArgs(constructorArgs map[string]map[string]any) error {
+	// If our corpus directory is empty, it indicates we do not want to write corpus artifacts to persistent storage.
+	if c.storageDirectory == "" {
+		return nil
+	}
+
+	// Ensure the corpus directory exists and is versioned.
+	err := utils.MakeDirectory(c.storageDirectory)
+	if err != nil {
+		return err
+	}
+	err = c.writeVersion()
+	if err != nil {
+		return err
+	}
+
+	// Marshal the constructor arguments and write them to disk.
+	jsonEncodedData, err := json.MarshalIndent(constructorArgs, "", " ")
+	if err != nil {
+		return err
+	}
+	err = writeCorpusFile(c.ConstructorArgsFilePath(), jsonEncodedData, false)
+	if err != nil {
+		return fmt.Errorf("An error occurred while writing constructor arguments to disk: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package corpus
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// persistedCoverageMaps describes the contents of the coverage maps file stored in the corpus directory.
+type persistedCoverageMaps struct {
+	// BytecodeHash describes the hash of the compiled contracts the coverage maps were measured against.
+	BytecodeHash common.Hash `json:"bytecodeHash"`
+
+	// CallSequenceFiles describes the names of the call sequence files whose coverage is included in CoverageMaps.
+	CallSequenceFiles []string `json:"callSequenceFiles"`
+
+	// CoverageMaps describes the total coverage achieved by the call sequences in CallSequenceFiles.
+	CoverageMaps *coverage.CoverageMaps `json:"coverageMaps"`
+}
+
+// SetFullReplayForced sets whether every call sequence should be replayed when the corpus is initialized, rather than
+// loading coverage maps persisted for the same compiled contracts. This must be set prior to Initialize to take effect.
+func (c *Corpus) SetFullReplayForced(forced bool) {
+	c.fullReplayForced = forced
+}
+
+// CoverageMapsFilePath returns the file path where the total coverage maps of the corpus are persisted. This is a file
+// within StorageDirectory. If StorageDirectory is empty, this is as well, indicating persistent storage is not enabled.
+func (c *Corpus) CoverageMapsFilePath() string {
+	if c.storageDirectory == "" {
+		return ""
+	}
+	return filepath.Join(c.StorageDirectory(), "coverage_maps.json")
+}
+
+// ContractsBytecodeHash calculates a hash of the init and runtime bytecode of the provided compiled contracts, which
+// is independent of their order. If constructor argument values generated for fuzzed constructor arguments are
+// provided, keyed by contract name and then argument name, they are hashed too, as contracts deployed with other
+// arguments may reach other coverage.
+// Returns the calculated hash.
+func ContractsBytecodeHash(contractDefinitions contracts.Contracts, fuzzedConstructorArgs map[string]map[string]any) common.Hash {
+	// Sort our contracts, so the order they were compiled in does not matter.
+	sortedContracts := make(contracts.Contracts, len(contractDefinitions))
+	copy(sortedContracts, contractDefinitions)
+	sort.SliceStable(sortedContracts, func(i, j int) bool {
+		if sortedContracts[i].SourcePath() != sortedContracts[j].SourcePath() {
+			return sortedContracts[i].SourcePath() < sortedContracts[j].SourcePath()
+		}
+		return sortedContracts[i].Name() < sortedContracts[j].Name()
+	})
+
+	// Hash each contract's name and bytecode, prefixing each with its length so the boundaries are unambiguous.
+	hashProvider := crypto.NewKeccakState()
+	var temp [8]byte
+	writeField := func(data []byte) {
+		binary.BigEndian.PutUint64(temp[:], uint64(len(data)))
+		hashProvider.Write(temp[:])
+		hashProvider.Write(data)
+	}
+	for _, contract := range sortedContracts {
+		writeField([]byte(contract.Name()))
+		writeField(contract.CompiledContract().InitBytecode)
+		writeField(contract.CompiledContract().RuntimeBytecode)
+	}
+
+	// Hash our constructor arguments, if any were generated. Map keys are sorted when encoding, so their order does
+	// not matter.
+	if len(fuzzedConstructorArgs) > 0 {
+		encodedArgs, err := json.Marshal(fuzzedConstructorArgs)
+		if err == nil {
+			writeField(encodedArgs)
+		}
+	}
+	return common.BytesToHash(hashProvider.Sum(nil))
+}
+
+// loadCoverageMaps loads the coverage maps persisted in the corpus directory, if they were measured against the
+// compiled contracts the corpus is being initialized with and every call sequence file they cover still exists. The
+// call sequences they cover are queued for execution without being replayed, pending resolution by the fuzzer.
+// This is skipped if full replays are forced or the power schedule is enabled, as it requires the coverage of each
+// call sequence. The caller must hold the call sequences lock.
+// Returns the call sequences which must still be replayed, or an error if one occurs.
+func (c *Corpus) loadCoverageMaps() ([]*corpusFile[calls.CallSequence], error) {
+	// If we cannot use persisted coverage maps, every call sequence must be replayed.
+	if c.storageDirectory == "" || c.fullReplayForced || c.powerScheduleEnabled {
+		return c.callSequences, nil
+	}
+
+	// Read our coverage maps. If they do not exist or have been invalidated, every call sequence must be replayed.
+	b, err := readCorpusFile(c.CoverageMapsFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c.callSequences, nil
+		}
+		return nil, err
+	}
+	var persisted persistedCoverageMaps
+	err = json.Unmarshal(b, &persisted)
+	if err != nil {
+		logging.GlobalLogger.Warn().Str("file", c.CoverageMapsFilePath()).Err(err).Msgf("corpus coverage maps '%v' could not be parsed, replaying the corpus: %v", c.CoverageMapsFilePath(), err)
+		return c.callSequences, nil
+	}
+	if persisted.BytecodeHash != c.bytecodeHash || persisted.CoverageMaps == nil {
+		logging.GlobalLogger.Info().Str("file", c.CoverageMapsFilePath()).Msgf("corpus coverage maps '%v' were measured against different contracts, replaying the corpus", c.CoverageMapsFilePath())
+		return c.callSequences, nil
+	}
+
+	// Determine which call sequences are covered by our coverage maps. If any call sequence they cover was removed,
+	// they may include coverage the corpus no longer reaches, so every call sequence must be replayed.
+	coveredFiles := make(map[string]struct{}, len(persisted.CallSequenceFiles))
+	for _, fileName := range persisted.CallSequenceFiles {
+		coveredFiles[fileName] = struct{}{}
+	}
+	pendingFiles := make([]*corpusFile[calls.CallSequence], 0, len(coveredFiles))
+	sequencesToReplay := make([]*corpusFile[calls.CallSequence], 0)
+	for _, sequenceFile := range c.callSequences {
+		if _, covered := coveredFiles[filepath.Base(sequenceFile.filePath)]; covered {
+			pendingFiles = append(pendingFiles, sequenceFile)
+		} else {
+			sequencesToReplay = append(sequencesToReplay, sequenceFile)
+		}
+	}
+	if len(pendingFiles) != len(coveredFiles) {
+		logging.GlobalLogger.Info().Str("file", c.CoverageMapsFilePath()).Msgf("corpus coverage maps '%v' cover call sequences which no longer exist, replaying the corpus", c.CoverageMapsFilePath())
+		return c.callSequences, nil
+	}
+
+	// Use our loaded coverage maps, and queue the call sequences they cover to be executed by the fuzzer without
+	// replaying them. They are resolved as the fuzzer executes them, and only then added for selection.
+	c.coverageMaps = persisted.CoverageMaps
+	for _, sequenceFile := range pendingFiles {
+		c.pendingReplays[&sequenceFile.data] = sequenceFile
+		c.unexecutedCallSequences = append(c.unexecutedCallSequences, sequenceFile)
+	}
+	return sequencesToReplay, nil
+}
+
+// WriteCoverageMaps writes the total coverage maps of the corpus to the corpus directory, keyed by a hash of the
+// compiled contracts the corpus was initialized with, so that the corpus can be initialized from them later without
+// replaying every call sequence. This should be called after Flush, as only call sequences written to disk are
+// recorded as covered. If the corpus was not initialized, this does nothing.
+// Returns an error if one occurs.
+func (c *Corpus) WriteCoverageMaps() error {
+	// If our corpus directory is empty, it indicates we do not want to write corpus artifacts to persistent storage.
+	if c.storageDirectory == "" || c.weightedCallSequenceChooser == nil {
+		return nil
+	}
+
+	// Lock while collecting the call sequences covered, to avoid concurrent access issues.
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+
+	// Record every call sequence written to disk as covered.
+	persisted := persistedCoverageMaps{
+		BytecodeHash:      c.bytecodeHash,
+		CallSequenceFiles: make([]string, 0, len(c.callSequences)),
+		CoverageMaps:      c.coverageMaps,
+	}
+	for _, sequenceFile := range c.callSequences {
+		if sequenceFile.filePath != "" {
+			persisted.CallSequenceFiles = append(persisted.CallSequenceFiles, filepath.Base(sequenceFile.filePath))
+		}
+	}
+
+	// Write our coverage maps.
+	jsonEncodedData, err := json.Marshal(persisted)
+	if err != nil {
+		return err
+	}
+	err = utils.MakeDirectory(c.storageDirectory)
+	if err != nil {
+		return err
+	}
+	err = writeCorpusFile(c.CoverageMapsFilePath(), jsonEncodedData, false)
+	if err != nil {
+		return fmt.Errorf("An error occurred while writing corpus coverage maps to disk: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package corpus
+
+import (
+	"github.com/crytic/medusa/events"
+	"github.com/crytic/medusa/fuzzing/calls"
+)
+
+// CorpusEvents defines event emitters for a Corpus.
+type CorpusEvents struct {
+	// CallSequenceAdded emits events when a call sequence is added to the corpus. Call sequences equivalent to one
+	// added before are not added again, so no event is emitted for them, nor for call sequences read from disk when
+	// the corpus is initialized.
+	CallSequenceAdded events.EventEmitter[CallSequenceAddedEvent]
+}
+
+// CallSequenceAddedEvent describes an event where a call sequence was added to a Corpus.
+type CallSequenceAddedEvent struct {
+	// CallSequence describes the call sequence which was added.
+	CallSequence calls.CallSequence
+
+	// Metadata describes the provenance of the call sequence, as recorded in the corpus.
+	Metadata *CallSequenceMetadata
+}
@@ -0,0 +1,615 @@
+package corpus
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/valuegeneration"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/utils/reflectionutils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// echidnaTransaction describes a transaction in an Echidna corpus or reproducer file, which each contain a JSON array
+// of them.
+type echidnaTransaction struct {
+	// Call describes the call made by the transaction, tagged by its kind (e.g. "SolCall" or "NoCall").
+	Call echidnaTaggedValue `json:"call"`
+
+	// Src describes the sender of the transaction.
+	Src string `json:"src"`
+
+	// Dst describes the address the transaction targets.
+	Dst string `json:"dst"`
+
+	// Gas describes the gas limit of the transaction.
+	Gas json.RawMessage `json:"gas"`
+
+	// Value describes the value sent with the transaction.
+	Value json.RawMessage `json:"value"`
+
+	// Delay describes the time and block number delay to apply prior to the transaction, in that order.
+	Delay []json.RawMessage `json:"delay"`
+}
+
+// echidnaTaggedValue describes a value encoded by Echidna as a JSON object with a tag describing its kind, and the
+// contents of that kind.
+type echidnaTaggedValue struct {
+	// Tag describes the kind of the value (e.g. "AbiUInt").
+	Tag string `json:"tag"`
+
+	// Contents describes the contents of the value, whose format depends on Tag.
+	Contents json.RawMessage `json:"contents"`
+}
+
+// CallSequenceImport describes the outcome of importing call sequences from another fuzzer's corpus.
+type CallSequenceImport struct {
+	// FileCount describes the count of files call sequences were read from.
+	FileCount int `json:"fileCount"`
+
+	// ImportedCallSequenceCount describes the count of call sequences added to the corpus.
+	ImportedCallSequenceCount int `json:"importedCallSequenceCount"`
+
+	// SkippedCallSequenceCount describes the count of call sequences which could not be imported.
+	SkippedCallSequenceCount int `json:"skippedCallSequenceCount"`
+
+	// DuplicateCallSequenceCount describes the count of call sequences which were not imported as an equivalent call
+	// sequence was already in the corpus.
+	DuplicateCallSequenceCount int `json:"duplicateCallSequenceCount"`
+
+	// SkippedCallCount describes the count of calls which could not be mapped onto the deployed contracts and were
+	// omitted from the call sequences they were part of.
+	SkippedCallCount int `json:"skippedCallCount"`
+}
+
+// echidnaImportTarget describes a contract deployed on the test chain, which Echidna calls may be mapped onto.
+type echidnaImportTarget struct {
+	// Address describes the address the contract is deployed at.
+	Address common.Address
+
+	// Contract describes the compiled contract which was deployed.
+	Contract *contracts.Contract
+}
+
+// echidnaCallSequenceImporter converts Echidna transactions into call sequences targeting the contracts deployed on a
+// test chain.
+type echidnaCallSequenceImporter struct {
+	// deployedContracts describes the contracts deployed on the test chain, in the order they were deployed.
+	deployedContracts []*echidnaImportTarget
+
+	// senders describes the sender addresses calls may be made from.
+	senders []common.Address
+
+	// senderMapping maps each Echidna sender address to the sender address it was mapped onto.
+	senderMapping map[common.Address]common.Address
+
+	// targetMapping maps each Echidna target address to the address of the deployed contract it was mapped onto.
+	targetMapping map[common.Address]common.Address
+
+	// blockGasLimit describes the gas limit of blocks on the test chain, which call gas limits are capped to.
+	blockGasLimit uint64
+}
+
+// ImportEchidnaCallSequences reads call sequences from the Echidna corpus or reproducer files (JSON arrays of Echidna
+// transactions) found within the provided directory and its subdirectories, and adds them to the corpus. Echidna
+// senders are mapped onto the provided sender addresses, and Echidna target addresses onto contracts deployed on the
+// provided post-setup (deployment) test chain which define the method called. Each call sequence is validated by
+// replaying it on the chain before it is added. Calls which cannot be mapped are skipped with a warning, as are call
+// sequences which could not be read or replayed. Imported call sequences are written when the corpus is flushed.
+// Returns the outcome of the import, or an error if one occurs.
+func (c *Corpus) ImportEchidnaCallSequences(baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts, directory string, senders []common.Address) (*CallSequenceImport, error) {
+	// Acquire our call sequences lock during the duration of this method.
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+
+	// Find every file in our directory which may contain call sequences.
+	filePaths := make([]string, 0)
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		extension := strings.ToLower(filepath.Ext(path))
+		if !entry.IsDir() && (extension == ".txt" || extension == ".json") {
+			filePaths = append(filePaths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Echidna corpus directory '%v': %v", directory, err)
+	}
+
+	// Clone our test chain to validate call sequences on, determining which contracts are deployed on it.
+	importer := &echidnaCallSequenceImporter{
+		deployedContracts: make([]*echidnaImportTarget, 0),
+		senders:           senders,
+		senderMapping:     make(map[common.Address]common.Address),
+		targetMapping:     make(map[common.Address]common.Address),
+		blockGasLimit:     baseTestChain.BlockGasLimit,
+	}
+	testChain, err := baseTestChain.Clone(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import Echidna corpus, base test chain cloning encountered error: %v", err)
+	}
+	defer testChain.Close()
+	testChain.BlockGasLimit = baseTestChain.BlockGasLimit
+	for _, block := range testChain.CommittedBlocks() {
+		for _, messageResults := range block.MessageResults {
+			for _, deploymentChange := range messageResults.ContractDeploymentChanges {
+				if deploymentChange.Creation {
+					matchedContract := contractDefinitions.MatchBytecode(deploymentChange.Contract.InitBytecode, deploymentChange.Contract.RuntimeBytecode)
+					if matchedContract != nil {
+						importer.deployedContracts = append(importer.deployedContracts, &echidnaImportTarget{
+							Address:  deploymentChange.Contract.Address,
+							Contract: matchedContract,
+						})
+					}
+				} else if deploymentChange.Destroyed {
+					for i, deployedContract := range importer.deployedContracts {
+						if deployedContract.Address == deploymentChange.Contract.Address {
+							importer.deployedContracts = append(importer.deployedContracts[:i], importer.deployedContracts[i+1:]...)
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	baseBlockNumber := testChain.HeadBlockNumber()
+
+	// Import the call sequence in each file.
+	result := &CallSequenceImport{FileCount: len(filePaths)}
+	for _, filePath := range filePaths {
+		// Read and convert our call sequence.
+		b, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		var transactions []echidnaTransaction
+		err = json.Unmarshal(b, &transactions)
+		if err != nil {
+			logging.GlobalLogger.Warn().Str("file", filePath).Err(err).Msgf("Echidna corpus item '%v' skipped as it could not be parsed: %v", filePath, err)
+			result.SkippedCallSequenceCount++
+			continue
+		}
+		sequence, skippedCallWarnings := importer.convertCallSequence(transactions)
+		for _, warning := range skippedCallWarnings {
+			logging.GlobalLogger.Warn().Str("file", filePath).Msgf("Echidna corpus item '%v': %v", filePath, warning)
+		}
+		result.SkippedCallCount += len(skippedCallWarnings)
+		if len(sequence) == 0 {
+			logging.GlobalLogger.Warn().Str("file", filePath).Msgf("Echidna corpus item '%v' skipped as none of its calls could be imported", filePath)
+			result.SkippedCallSequenceCount++
+			continue
+		}
+
+		// Validate our call sequence by replaying it, filling in the nonce of each call as we go.
+		fetchElementFunc := func(currentIndex int) (*calls.CallSequenceElement, error) {
+			if currentIndex >= len(sequence) {
+				return nil, nil
+			}
+			sequence[currentIndex].Call.FillFromTestChainProperties(testChain)
+			return sequence[currentIndex], nil
+		}
+		_, replayErr := calls.ExecuteCallSequenceIteratively(testChain, fetchElementFunc, nil)
+		err = testChain.RevertToBlockNumber(baseBlockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reset the chain while importing Echidna corpus: %v\n", err)
+		}
+		if replayErr != nil {
+			logging.GlobalLogger.Warn().Str("file", filePath).Err(replayErr).Msgf("Echidna corpus item '%v' skipped due to error when replaying it: %v", filePath, replayErr)
+			result.SkippedCallSequenceCount++
+			continue
+		}
+
+		// Add our call sequence to the corpus, to be written when it is next flushed, unless it is already in it.
+		sequenceHash, err := sequence.CanonicalHash()
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := c.callSequenceHashes[sequenceHash]; exists {
+			result.DuplicateCallSequenceCount++
+			continue
+		}
+		c.callSequenceHashes[sequenceHash] = struct{}{}
+		c.callSequences = append(c.callSequences, &corpusFile[calls.CallSequence]{
+			filePath: "",
+			data:     sequence,
+			metadata: &CallSequenceMetadata{
+				Hash:      sequenceHash,
+				CreatedAt: time.Now(),
+				Origin:    CallSequenceOriginImport,
+			},
+		})
+		result.ImportedCallSequenceCount++
+	}
+	return result, nil
+}
+
+// convertCallSequence converts the provided Echidna transactions into a call sequence. Delays of transactions which
+// make no call, or whose call cannot be mapped, are carried over to the next call.
+// Returns the converted call sequence, and warnings describing each call which was skipped.
+func (i *echidnaCallSequenceImporter) convertCallSequence(transactions []echidnaTransaction) (calls.CallSequence, []string) {
+	sequence := make(calls.CallSequence, 0, len(transactions))
+	warnings := make([]string, 0)
+	blockNumberDelay, blockTimestampDelay := uint64(0), uint64(0)
+	for index, transaction := range transactions {
+		// Accumulate our delays, so they still apply if this transaction makes no call.
+		if len(transaction.Delay) == 2 {
+			timeDelay, err := parseEchidnaInteger(transaction.Delay[0])
+			if err == nil && timeDelay.IsUint64() {
+				blockTimestampDelay += timeDelay.Uint64()
+			}
+			numberDelay, err := parseEchidnaInteger(transaction.Delay[1])
+			if err == nil && numberDelay.IsUint64() {
+				blockNumberDelay += numberDelay.Uint64()
+			}
+		}
+		if transaction.Call.Tag == "NoCall" {
+			continue
+		}
+
+		// Convert our call, skipping it if we cannot.
+		element, err := i.convertCall(transaction)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("call %d skipped: %v", index, err))
+			continue
+		}
+		element.BlockNumberDelay, element.BlockTimestampDelay = blockNumberDelay, blockTimestampDelay
+		blockNumberDelay, blockTimestampDelay = 0, 0
+		sequence = append(sequence, element)
+	}
+	return sequence, warnings
+}
+
+// convertCall converts the provided Echidna transaction into a call sequence element, mapping its sender and target
+// onto our sender addresses and deployed contracts.
+// Returns the converted call sequence element, or an error if the transaction could not be mapped.
+func (i *echidnaCallSequenceImporter) convertCall(transaction echidnaTransaction) (*calls.CallSequenceElement, error) {
+	// Parse our sender, target and call value.
+	if len(i.senders) == 0 {
+		return nil, fmt.Errorf("no sender addresses are configured")
+	}
+	if !common.IsHexAddress(transaction.Src) || !common.IsHexAddress(transaction.Dst) {
+		return nil, fmt.Errorf("invalid sender or target address")
+	}
+	sender := i.mapSender(common.HexToAddress(transaction.Src))
+	value := big.NewInt(0)
+	if len(transaction.Value) > 0 {
+		parsedValue, err := parseEchidnaInteger(transaction.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid call value: %v", err)
+		}
+		value = parsedValue
+	}
+	gasLimit := uint64(0)
+	if len(transaction.Gas) > 0 {
+		parsedGasLimit, err := parseEchidnaInteger(transaction.Gas)
+		if err == nil && parsedGasLimit.IsUint64() {
+			gasLimit = parsedGasLimit.Uint64()
+		}
+	}
+	if gasLimit > i.blockGasLimit {
+		gasLimit = i.blockGasLimit
+	}
+	echidnaTarget := common.HexToAddress(transaction.Dst)
+
+	switch transaction.Call.Tag {
+	case "SolCall":
+		// Parse our method name and arguments.
+		var contents []json.RawMessage
+		err := json.Unmarshal(transaction.Call.Contents, &contents)
+		if err != nil || len(contents) != 2 {
+			return nil, fmt.Errorf("invalid call contents")
+		}
+		var methodName string
+		var arguments []echidnaTaggedValue
+		if json.Unmarshal(contents[0], &methodName) != nil || json.Unmarshal(contents[1], &arguments) != nil {
+			return nil, fmt.Errorf("invalid call contents")
+		}
+
+		// Map our target onto a deployed contract defining the method, and convert our arguments for it.
+		target, method := i.mapTarget(echidnaTarget, methodName, len(arguments))
+		if target == nil {
+			return nil, fmt.Errorf("no deployed contract defines a method '%v' with %d arguments", methodName, len(arguments))
+		}
+		inputValues := make([]any, len(arguments))
+		for argumentIndex, argument := range arguments {
+			inputValues[argumentIndex], err = i.convertAbiValue(&method.Inputs[argumentIndex].Type, argument)
+			if err != nil {
+				return nil, fmt.Errorf("argument %d of '%v' could not be converted: %v", argumentIndex, methodName, err)
+			}
+		}
+		msg := calls.NewCallMessageWithAbiValueData(sender, &target.Address, 0, value, gasLimit, nil, nil, nil, &calls.CallMessageDataAbiValues{
+			Method:      method,
+			InputValues: inputValues,
+		})
+		return calls.NewCallSequenceElement(target.Contract, msg, 0, 0), nil
+	case "SolCalldata":
+		// Map our target onto a deployed contract, calling it with the raw call data.
+		data, err := parseEchidnaBytes(transaction.Call.Contents)
+		if err != nil {
+			return nil, fmt.Errorf("invalid call data: %v", err)
+		}
+		target := i.mapTargetAddress(echidnaTarget)
+		if target == nil {
+			return nil, fmt.Errorf("target address '%v' does not refer to a deployed contract", echidnaTarget.String())
+		}
+		msg := calls.NewCallMessage(sender, &target.Address, 0, value, gasLimit, nil, nil, nil, data)
+		return calls.NewCallSequenceElement(target.Contract, msg, 0, 0), nil
+	default:
+		return nil, fmt.Errorf("calls of kind '%v' are not supported", transaction.Call.Tag)
+	}
+}
+
+// mapSender maps the provided Echidna sender onto one of our sender addresses. Senders which are already one of our
+// senders are kept, while others are assigned our senders in turn, consistently across all call sequences.
+// Returns the mapped sender.
+func (i *echidnaCallSequenceImporter) mapSender(sender common.Address) common.Address {
+	for _, s := range i.senders {
+		if s == sender {
+			return sender
+		}
+	}
+	if mapped, ok := i.senderMapping[sender]; ok {
+		return mapped
+	}
+	mapped := i.senders[len(i.senderMapping)%len(i.senders)]
+	i.senderMapping[sender] = mapped
+	return mapped
+}
+
+// mapTarget maps the provided Echidna target address onto a deployed contract which defines a method with the
+// provided name and argument count. The contract the target was previously mapped onto is preferred, followed by a
+// contract deployed at the target address, followed by the first deployed contract defining the method.
+// Returns the deployed contract and its method, or nil values if no deployed contract defines the method.
+func (i *echidnaCallSequenceImporter) mapTarget(target common.Address, methodName string, argumentCount int) (*echidnaImportTarget, *abi.Method) {
+	// findMethod finds the method with our name and argument count in the provided deployed contract.
+	findMethod := func(deployedContract *echidnaImportTarget) *abi.Method {
+		for _, method := range deployedContract.Contract.CompiledContract().Abi.Methods {
+			if method.RawName == methodName && len(method.Inputs) == argumentCount {
+				method := method
+				return &method
+			}
+		}
+		return nil
+	}
+
+	// Check the contracts the target was previously mapped onto, or which is deployed at the target address first.
+	preferredAddresses := []common.Address{target}
+	if mapped, ok := i.targetMapping[target]; ok {
+		preferredAddresses = []common.Address{mapped, target}
+	}
+	for _, address := range preferredAddresses {
+		for _, deployedContract := range i.deployedContracts {
+			if deployedContract.Address == address {
+				if method := findMethod(deployedContract); method != nil {
+					return deployedContract, method
+				}
+			}
+		}
+	}
+
+	// Otherwise map the target onto the first deployed contract defining the method.
+	for _, deployedContract := range i.deployedContracts {
+		if method := findMethod(deployedContract); method != nil {
+			i.targetMapping[target] = deployedContract.Address
+			return deployedContract, method
+		}
+	}
+	return nil, nil
+}
+
+// mapTargetAddress maps the provided Echidna target address onto a deployed contract, preferring the contract the
+// target was previously mapped onto, followed by a contract deployed at the target address.
+// Returns the deployed contract, or nil if the target could not be mapped.
+func (i *echidnaCallSequenceImporter) mapTargetAddress(target common.Address) *echidnaImportTarget {
+	address := target
+	if mapped, ok := i.targetMapping[target]; ok {
+		address = mapped
+	}
+	for _, deployedContract := range i.deployedContracts {
+		if deployedContract.Address == address {
+			return deployedContract
+		}
+	}
+	return nil
+}
+
+// convertAbiValue converts the provided Echidna ABI value into a value of the provided ABI type. Addresses Echidna
+// used as senders or targets are mapped onto the addresses they were mapped to.
+// Returns the converted value, or an error if one occurs.
+func (i *echidnaCallSequenceImporter) convertAbiValue(inputType *abi.Type, value echidnaTaggedValue) (any, error) {
+	switch value.Tag {
+	case "AbiUInt", "AbiInt":
+		if inputType.T != abi.UintTy && inputType.T != abi.IntTy {
+			return nil, fmt.Errorf("expected a value of type %v, found an integer", inputType)
+		}
+		var contents []json.RawMessage
+		err := json.Unmarshal(value.Contents, &contents)
+		if err != nil || len(contents) != 2 {
+			return nil, fmt.Errorf("invalid integer value")
+		}
+		n, err := parseEchidnaInteger(contents[1])
+		if err != nil {
+			return nil, err
+		}
+		return valuegeneration.IntegerToAbiValue(n, inputType), nil
+	case "AbiAddress":
+		if inputType.T != abi.AddressTy {
+			return nil, fmt.Errorf("expected a value of type %v, found an address", inputType)
+		}
+		var address string
+		err := json.Unmarshal(value.Contents, &address)
+		if err != nil || !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid address value")
+		}
+		if mapped, ok := i.senderMapping[common.HexToAddress(address)]; ok {
+			return mapped, nil
+		}
+		if mapped, ok := i.targetMapping[common.HexToAddress(address)]; ok {
+			return mapped, nil
+		}
+		return common.HexToAddress(address), nil
+	case "AbiBool":
+		if inputType.T != abi.BoolTy {
+			return nil, fmt.Errorf("expected a value of type %v, found a bool", inputType)
+		}
+		var b bool
+		err := json.Unmarshal(value.Contents, &b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bool value")
+		}
+		return b, nil
+	case "AbiBytes":
+		if inputType.T != abi.FixedBytesTy {
+			return nil, fmt.Errorf("expected a value of type %v, found fixed bytes", inputType)
+		}
+		var contents []json.RawMessage
+		err := json.Unmarshal(value.Contents, &contents)
+		if err != nil || len(contents) != 2 {
+			return nil, fmt.Errorf("invalid fixed bytes value")
+		}
+		b, err := parseEchidnaBytes(contents[1])
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > inputType.Size {
+			return nil, fmt.Errorf("fixed bytes value exceeds %d bytes", inputType.Size)
+		}
+		fixedBytes := reflect.Indirect(reflect.New(inputType.GetType()))
+		reflect.Copy(fixedBytes, reflect.ValueOf(b))
+		return fixedBytes.Interface(), nil
+	case "AbiBytesDynamic", "AbiString":
+		b, err := parseEchidnaBytes(value.Contents)
+		if err != nil {
+			return nil, err
+		}
+		if inputType.T == abi.BytesTy {
+			return b, nil
+		} else if inputType.T == abi.StringTy {
+			return string(b), nil
+		}
+		return nil, fmt.Errorf("expected a value of type %v, found bytes", inputType)
+	case "AbiArray", "AbiArrayDynamic":
+		// Obtain our elements, which are the last item of our contents, following the array length (for static
+		// arrays) and element type.
+		var contents []json.RawMessage
+		err := json.Unmarshal(value.Contents, &contents)
+		if err != nil || len(contents) == 0 {
+			return nil, fmt.Errorf("invalid array value")
+		}
+		var elements []echidnaTaggedValue
+		err = json.Unmarshal(contents[len(contents)-1], &elements)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array value")
+		}
+
+		// Convert each element into our array or slice.
+		var array reflect.Value
+		if inputType.T == abi.ArrayTy {
+			if len(elements) != inputType.Size {
+				return nil, fmt.Errorf("expected %d array elements, found %d", inputType.Size, len(elements))
+			}
+			array = reflect.Indirect(reflect.New(inputType.GetType()))
+		} else if inputType.T == abi.SliceTy {
+			array = reflect.MakeSlice(inputType.GetType(), len(elements), len(elements))
+		} else {
+			return nil, fmt.Errorf("expected a value of type %v, found an array", inputType)
+		}
+		for elementIndex, element := range elements {
+			convertedElement, err := i.convertAbiValue(inputType.Elem, element)
+			if err != nil {
+				return nil, err
+			}
+			array.Index(elementIndex).Set(reflect.ValueOf(convertedElement))
+		}
+		return array.Interface(), nil
+	case "AbiTuple":
+		if inputType.T != abi.TupleTy {
+			return nil, fmt.Errorf("expected a value of type %v, found a tuple", inputType)
+		}
+		var elements []echidnaTaggedValue
+		err := json.Unmarshal(value.Contents, &elements)
+		if err != nil || len(elements) != len(inputType.TupleElems) {
+			return nil, fmt.Errorf("invalid tuple value")
+		}
+		tuple := reflect.Indirect(reflect.New(inputType.GetType()))
+		for elementIndex, element := range elements {
+			convertedElement, err := i.convertAbiValue(inputType.TupleElems[elementIndex], element)
+			if err != nil {
+				return nil, err
+			}
+			reflectionutils.SetField(tuple.Field(elementIndex), convertedElement)
+		}
+		return tuple.Interface(), nil
+	case "AbiFunction":
+		if inputType.T != abi.FunctionTy {
+			return nil, fmt.Errorf("expected a value of type %v, found a function", inputType)
+		}
+		b, err := parseEchidnaBytes(value.Contents)
+		if err != nil || len(b) != 24 {
+			return nil, fmt.Errorf("invalid function value")
+		}
+		var function [24]byte
+		copy(function[:], b)
+		return function, nil
+	default:
+		return nil, fmt.Errorf("values of kind '%v' are not supported", value.Tag)
+	}
+}
+
+// parseEchidnaInteger parses an integer encoded by Echidna, either as a JSON number, or a string holding a decimal or
+// "0x" prefixed hexadecimal integer.
+// Returns the parsed integer, or an error if one occurs.
+func parseEchidnaInteger(raw json.RawMessage) (*big.Int, error) {
+	text := string(raw)
+	var str string
+	if json.Unmarshal(raw, &str) == nil {
+		text = str
+	}
+	n, ok := new(big.Int).SetString(strings.TrimSpace(text), 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer value '%v'", text)
+	}
+	return n, nil
+}
+
+// parseEchidnaBytes parses a byte string encoded by Echidna, either as a JSON array of byte values, a "0x" prefixed
+// hex string, or a string holding the bytes themselves.
+// Returns the parsed bytes, or an error if one occurs.
+func parseEchidnaBytes(raw json.RawMessage) ([]byte, error) {
+	// Note: we parse arrays as integers, as byte slices are parsed from base64 strings.
+	var numbers []int
+	if json.Unmarshal(raw, &numbers) == nil {
+		b := make([]byte, len(numbers))
+		for i, number := range numbers {
+			if number < 0 || number > 255 {
+				return nil, fmt.Errorf("invalid byte value %d", number)
+			}
+			b[i] = byte(number)
+		}
+		return b, nil
+	}
+	var str string
+	if json.Unmarshal(raw, &str) != nil {
+		return nil, fmt.Errorf("invalid bytes value")
+	}
+	if strings.HasPrefix(str, "0x") {
+		if decoded, err := hex.DecodeString(str[2:]); err == nil {
+			return decoded, nil
+		}
+	}
+	return []byte(str), nil
+}
@@ -0,0 +1,138 @@
+package corpus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// CallSequenceOrigin describes how a corpus call sequence was produced.
+type CallSequenceOrigin string
+
+const (
+	// CallSequenceOriginUnknown describes a call sequence whose origin was not recorded, such as one read from a
+	// corpus file which predates corpus metadata.
+	CallSequenceOriginUnknown CallSequenceOrigin = ""
+	// CallSequenceOriginGeneration describes a call sequence which was newly generated.
+	CallSequenceOriginGeneration CallSequenceOrigin = "generation"
+	// CallSequenceOriginMutation describes a call sequence derived from a single corpus call sequence, whether its
+	// calls were mutated or not.
+	CallSequenceOriginMutation CallSequenceOrigin = "mutation"
+	// CallSequenceOriginSplice describes a call sequence derived by splicing or interleaving two corpus call
+	// sequences.
+	CallSequenceOriginSplice CallSequenceOrigin = "splice"
+	// CallSequenceOriginShrink describes a call sequence produced while shrinking a call sequence.
+	CallSequenceOriginShrink CallSequenceOrigin = "shrink"
+	// CallSequenceOriginImport describes a call sequence imported from another fuzzer's corpus.
+	CallSequenceOriginImport CallSequenceOrigin = "import"
+)
+
+// CallSequenceMetadata describes the provenance of a corpus call sequence, written alongside it in its corpus file.
+type CallSequenceMetadata struct {
+	// Hash describes the canonical hash of the call sequence (see calls.CallSequence.CanonicalHash), so that other
+	// entries can refer to it as their parent, and duplicates of it can be identified before it is replayed.
+	Hash common.Hash `json:"hash"`
+
+	// CreatedAt describes the time the call sequence was added to the corpus.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// WorkerIndex describes the index of the worker which produced the call sequence, or nil if it was not produced
+	// by a worker.
+	WorkerIndex *int `json:"workerIndex,omitempty"`
+
+	// Origin describes how the call sequence was produced.
+	Origin CallSequenceOrigin `json:"origin"`
+
+	// ParentHash describes the hash of the corpus call sequence this call sequence was derived from, or nil if it was
+	// not derived from one. Spliced call sequences refer to the call sequence their head was taken from.
+	ParentHash *common.Hash `json:"parentHash,omitempty"`
+
+	// CoverageHash describes a hash of the coverage reached by the call which caused the call sequence to be added
+	// to the corpus, or nil if it was not added for increasing coverage.
+	CoverageHash *common.Hash `json:"coverageHash,omitempty"`
+
+	// StateHash describes a hash of the state of the deployed contracts after the call sequence was executed, or nil
+	// if it was not recorded, as corpus state deduplication was disabled.
+	StateHash *common.Hash `json:"stateHash,omitempty"`
+
+	// ConstructorArgs describes the constructor argument values the fuzzer generated for contracts deployed in the
+	// campaign which produced the call sequence, keyed by contract name and then argument name, or nil if none were
+	// generated. The call sequence may only reproduce its behavior if contracts are deployed with these values.
+	ConstructorArgs map[string]map[string]any `json:"constructorArgs,omitempty"`
+}
+
+// callSequenceFileContents describes the contents of a corpus call sequence file.
+type callSequenceFileContents struct {
+	// Metadata describes the provenance of the call sequence.
+	Metadata *CallSequenceMetadata `json:"metadata"`
+
+	// CallSequence describes the call sequence itself.
+	CallSequence calls.CallSequence `json:"callSequence"`
+}
+
+// marshalCallSequenceFile encodes the provided call sequence and its metadata as the contents of a corpus file.
+// Returns the encoded file contents, or an error if one occurs.
+func marshalCallSequenceFile(callSequence calls.CallSequence, metadata *CallSequenceMetadata) ([]byte, error) {
+	return json.MarshalIndent(callSequenceFileContents{
+		Metadata:     metadata,
+		CallSequence: callSequence,
+	}, "", " ")
+}
+
+// unmarshalCallSequenceFile decodes a call sequence and its metadata from the contents of a corpus file. Files which
+// predate corpus metadata, containing only a call sequence, are accepted and yield nil metadata.
+// Returns the decoded call sequence and its metadata, or an error if one occurs.
+func unmarshalCallSequenceFile(b []byte) (calls.CallSequence, *CallSequenceMetadata, error) {
+	// Files which predate metadata contain only an array of call sequence elements.
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		var callSequence calls.CallSequence
+		err := json.Unmarshal(b, &callSequence)
+		return callSequence, nil, err
+	}
+
+	var contents callSequenceFileContents
+	err := json.Unmarshal(b, &contents)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contents.CallSequence, contents.Metadata, nil
+}
+
+// coverageHash calculates a hash over the provided coverage locations, independent of their order.
+// Returns the calculated hash.
+func coverageHash(locations []coverage.CoverageLocation) common.Hash {
+	// Sort a copy of our locations, so the hash does not depend on map iteration order.
+	sortedLocations := make([]coverage.CoverageLocation, len(locations))
+	copy(sortedLocations, locations)
+	sort.Slice(sortedLocations, func(i, j int) bool {
+		if c := bytes.Compare(sortedLocations[i].CodeHash[:], sortedLocations[j].CodeHash[:]); c != 0 {
+			return c < 0
+		}
+		if sortedLocations[i].Init != sortedLocations[j].Init {
+			return !sortedLocations[i].Init
+		}
+		return sortedLocations[i].PC < sortedLocations[j].PC
+	})
+
+	// Hash each location.
+	hashProvider := crypto.NewKeccakState()
+	var temp [8]byte
+	for _, location := range sortedLocations {
+		hashProvider.Write(location.CodeHash[:])
+		if location.Init {
+			hashProvider.Write([]byte{1})
+		} else {
+			hashProvider.Write([]byte{0})
+		}
+		binary.LittleEndian.PutUint64(temp[:], uint64(location.PC))
+		hashProvider.Write(temp[:])
+	}
+	return common.BytesToHash(hashProvider.Sum(nil))
+}
@@ -0,0 +1,165 @@
+package corpus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CorpusMinimization describes the outcome of minimizing a corpus.
+type CorpusMinimization struct {
+	// OriginalCallSequenceCount describes the count of call sequences in the corpus prior to minimization.
+	OriginalCallSequenceCount int `json:"originalCallSequenceCount"`
+
+	// MinimizedCallSequenceCount describes the count of call sequences kept in the corpus after minimization.
+	MinimizedCallSequenceCount int `json:"minimizedCallSequenceCount"`
+
+	// OriginalCallCount describes the total count of calls across call sequences prior to minimization.
+	OriginalCallCount int `json:"originalCallCount"`
+
+	// MinimizedCallCount describes the total count of calls across call sequences kept after minimization.
+	MinimizedCallCount int `json:"minimizedCallCount"`
+
+	// OriginalReplayDuration describes how long it took to replay the corpus prior to minimization.
+	OriginalReplayDuration time.Duration `json:"originalReplayDuration"`
+
+	// MinimizedReplayDuration describes an estimate of how long it will take to replay the minimized corpus, assuming
+	// each call takes as long to replay as the average call prior to minimization.
+	MinimizedReplayDuration time.Duration `json:"minimizedReplayDuration"`
+
+	// CoveredLocationCount describes the count of unique coverage locations reached by the corpus, which is preserved
+	// by minimization.
+	CoveredLocationCount int `json:"coveredLocationCount"`
+}
+
+// PrunedDirectory returns the directory path where call sequences removed from the corpus by Minimize are moved to.
+// This is a subdirectory of StorageDirectory. If StorageDirectory is empty, this is as well, indicating persistent
+// storage will not be used.
+func (c *Corpus) PrunedDirectory() string {
+	if c.storageDirectory == "" {
+		return ""
+	}
+	return filepath.Join(c.StorageDirectory(), "pruned")
+}
+
+// Minimize replays every call sequence in the corpus on the provided post-setup (deployment) test chain to measure the
+// coverage each reaches, then selects a minimal subset of call sequences which preserves the total coverage of the
+// corpus. Call sequences are selected greedily, each time choosing the one reaching the most coverage not yet reached by
+// those selected, preferring shorter call sequences on ties. Call sequences which were not selected (including those
+// which could not be replayed) are moved to PrunedDirectory rather than deleted. This should be called prior to
+// Initialize.
+// Returns the outcome of minimizing the corpus, or an error if one occurs.
+func (c *Corpus) Minimize(baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts) (*CorpusMinimization, error) {
+	// Acquire our call sequences lock during the duration of this method.
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+
+	// We can only move call sequences if we are persistently storing our corpus.
+	if c.storageDirectory == "" {
+		return nil, fmt.Errorf("corpus could not be minimized as no corpus directory was configured")
+	}
+
+	// Replay our sequences, recording the unique coverage locations reached by each. Sequences which could not be
+	// replayed are treated as reaching no coverage.
+	coverageTracer := coverage.NewCoverageTracer()
+	sequenceCoveredLocations := make([]map[coverage.CoverageLocation]struct{}, 0, len(c.callSequences))
+	currentCoveredLocations := make(map[coverage.CoverageLocation]struct{})
+	chainSetupFunc := func(newChain *chain.TestChain) {
+		newChain.AddTracer(coverageTracer, true, false)
+	}
+	checkFunc := func(_ map[common.Address]*contracts.Contract, currentlyExecutedSequence calls.CallSequence) (bool, error) {
+		lastExecutedSequenceElement := currentlyExecutedSequence[len(currentlyExecutedSequence)-1]
+		covMaps := coverage.GetCoverageTracerResults(lastExecutedSequenceElement.ChainReference.MessageResults())
+		if covMaps != nil {
+			for _, location := range covMaps.CoveredLocations() {
+				currentCoveredLocations[location] = struct{}{}
+			}
+		}
+		return false, nil
+	}
+	resultFunc := func(sequenceFile *corpusFile[calls.CallSequence], sequenceInvalidError error) error {
+		if sequenceInvalidError != nil {
+			currentCoveredLocations = make(map[coverage.CoverageLocation]struct{})
+		}
+		sequenceCoveredLocations = append(sequenceCoveredLocations, currentCoveredLocations)
+		currentCoveredLocations = make(map[coverage.CoverageLocation]struct{})
+		return nil
+	}
+	replayStartTime := time.Now()
+	err := c.replayCallSequences(baseTestChain, contractDefinitions, chainSetupFunc, checkFunc, resultFunc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to minimize corpus: %v", err)
+	}
+	replayDuration := time.Since(replayStartTime)
+
+	// Greedily select call sequences until no remaining sequence reaches additional coverage.
+	selected := make([]bool, len(c.callSequences))
+	coveredLocations := make(map[coverage.CoverageLocation]struct{})
+	for {
+		bestIndex, bestGain := -1, 0
+		for i, locations := range sequenceCoveredLocations {
+			if selected[i] {
+				continue
+			}
+			gain := 0
+			for location := range locations {
+				if _, covered := coveredLocations[location]; !covered {
+					gain++
+				}
+			}
+			if gain > bestGain || (gain == bestGain && gain > 0 && len(c.callSequences[i].data) < len(c.callSequences[bestIndex].data)) {
+				bestIndex, bestGain = i, gain
+			}
+		}
+		if bestIndex < 0 {
+			break
+		}
+		selected[bestIndex] = true
+		for location := range sequenceCoveredLocations[bestIndex] {
+			coveredLocations[location] = struct{}{}
+		}
+	}
+
+	// Move every call sequence we did not select to our pruned directory, keeping those we did.
+	minimization := &CorpusMinimization{
+		OriginalCallSequenceCount: len(c.callSequences),
+		OriginalReplayDuration:    replayDuration,
+		CoveredLocationCount:      len(coveredLocations),
+	}
+	keptCallSequences := make([]*corpusFile[calls.CallSequence], 0)
+	for i, sequenceFile := range c.callSequences {
+		minimization.OriginalCallCount += len(sequenceFile.data)
+		if selected[i] {
+			keptCallSequences = append(keptCallSequences, sequenceFile)
+			minimization.MinimizedCallCount += len(sequenceFile.data)
+			continue
+		}
+		if sequenceFile.filePath == "" {
+			continue
+		}
+		err = utils.MakeDirectory(c.PrunedDirectory())
+		if err != nil {
+			return nil, err
+		}
+		err = os.Rename(sequenceFile.filePath, filepath.Join(c.PrunedDirectory(), filepath.Base(sequenceFile.filePath)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to move corpus file '%v' to the pruned directory: %v", sequenceFile.filePath, err)
+		}
+	}
+	c.callSequences = keptCallSequences
+	minimization.MinimizedCallSequenceCount = len(keptCallSequences)
+
+	// Estimate our replay time after minimization from the average time taken per call.
+	if minimization.OriginalCallCount > 0 {
+		minimization.MinimizedReplayDuration = time.Duration(int64(replayDuration) * int64(minimization.MinimizedCallCount) / int64(minimization.OriginalCallCount))
+	}
+	return minimization, nil
+}
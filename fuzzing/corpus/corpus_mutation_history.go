@@ -0,0 +1,85 @@
+package corpus
+
+import (
+	"sync"
+)
+
+// mutationHistoryDecayFactor describes the factor every score in a CallSequenceMutationHistory is multiplied by each
+// time it decays, so arguments which stop producing new coverage are gradually explored less.
+const mutationHistoryDecayFactor = 0.95
+
+// mutationHistoryMinimumScore describes the score below which an argument's score is discarded from a
+// CallSequenceMutationHistory as it decays, to keep the history small.
+const mutationHistoryMinimumScore = 0.01
+
+// mutationHistoryKey describes an ABI argument of a call within a corpus call sequence.
+type mutationHistoryKey struct {
+	// elementIndex describes the index of the call within the corpus call sequence.
+	elementIndex int
+
+	// argumentIndex describes the index of the ABI argument within the call's input values.
+	argumentIndex int
+}
+
+// CallSequenceMutationHistory tracks which arguments of the calls in a corpus call sequence produced new coverage
+// when mutated, so that they may be mutated more often in later iterations. Scores decay over time. A history is
+// shared between all workers and is safe for concurrent use.
+type CallSequenceMutationHistory struct {
+	// scores describes the current score of each argument which produced new coverage when mutated.
+	scores map[mutationHistoryKey]float64
+
+	// productiveMutationCount describes the total number of times a mutated argument produced new coverage.
+	productiveMutationCount uint64
+
+	// lock provides thread synchronization to prevent concurrent access errors into scores.
+	lock sync.Mutex
+}
+
+// NewCallSequenceMutationHistory creates a new, empty CallSequenceMutationHistory.
+func NewCallSequenceMutationHistory() *CallSequenceMutationHistory {
+	return &CallSequenceMutationHistory{
+		scores: make(map[mutationHistoryKey]float64),
+	}
+}
+
+// RecordProductiveMutation records that mutating the argument at the provided argument index of the call at the
+// provided element index produced new coverage, increasing its score.
+func (h *CallSequenceMutationHistory) RecordProductiveMutation(elementIndex int, argumentIndex int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.scores[mutationHistoryKey{elementIndex: elementIndex, argumentIndex: argumentIndex}] += 1.0
+	h.productiveMutationCount++
+}
+
+// ArgumentScore returns the current score of the argument at the provided argument index of the call at the provided
+// element index. An argument which never produced new coverage when mutated has a score of zero.
+func (h *CallSequenceMutationHistory) ArgumentScore(elementIndex int, argumentIndex int) float64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.scores[mutationHistoryKey{elementIndex: elementIndex, argumentIndex: argumentIndex}]
+}
+
+// ProductiveMutationCount returns the total number of times a mutated argument was recorded as producing new coverage.
+func (h *CallSequenceMutationHistory) ProductiveMutationCount() uint64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.productiveMutationCount
+}
+
+// Decay reduces the score of every argument in the history, discarding scores which become negligible.
+func (h *CallSequenceMutationHistory) Decay() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for key, score := range h.scores {
+		score *= mutationHistoryDecayFactor
+		if score < mutationHistoryMinimumScore {
+			delete(h.scores, key)
+		} else {
+			h.scores[key] = score
+		}
+	}
+}
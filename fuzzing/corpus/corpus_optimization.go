@@ -0,0 +1,97 @@
+package corpus
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/utils"
+)
+
+// OptimizationCallSequencesDirectory returns the directory path where the call sequence reaching the best value of
+// each optimization test is stored. This is a subdirectory of StorageDirectory. If StorageDirectory is empty, this is
+// as well, indicating persistent storage will not be used.
+func (c *Corpus) OptimizationCallSequencesDirectory() string {
+	if c.storageDirectory == "" {
+		return ""
+	}
+	return filepath.Join(c.StorageDirectory(), "optimization_sequences")
+}
+
+// optimizationCallSequenceFilePath obtains the path of the file the best call sequence for the optimization test with
+// the provided identifier is stored at.
+func (c *Corpus) optimizationCallSequenceFilePath(testID string) string {
+	return filepath.Join(c.OptimizationCallSequencesDirectory(), testID+".json")
+}
+
+// readOptimizationCallSequences reads every call sequence stored in the OptimizationCallSequencesDirectory. These are
+// replayed with the rest of the corpus on Initialize, so the first sequences workers execute reach the best values
+// found by previous runs.
+// Returns the corpus files read, or an error if one occurs.
+func (c *Corpus) readOptimizationCallSequences() ([]*corpusFile[calls.CallSequence], error) {
+	matches, err := filepath.Glob(filepath.Join(c.OptimizationCallSequencesDirectory(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sequenceFiles := make([]*corpusFile[calls.CallSequence], 0, len(matches))
+	for _, filePath := range matches {
+		b, err := readCorpusFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		seq, metadata, err := unmarshalCallSequenceFile(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse optimization call sequence '%v': %v", filePath, err)
+		}
+		sequenceFiles = append(sequenceFiles, &corpusFile[calls.CallSequence]{
+			filePath: filePath,
+			data:     seq,
+			metadata: metadata,
+		})
+	}
+	return sequenceFiles, nil
+}
+
+// WriteOptimizationCallSequence stores the provided call sequence as the one reaching the best value of the
+// optimization test with the provided identifier, replacing any stored previously. The provided metadata describes
+// the provenance of the call sequence and may be nil. If StorageDirectory is empty, this does nothing.
+// Returns an error if one occurs.
+func (c *Corpus) WriteOptimizationCallSequence(testID string, seq calls.CallSequence, metadata *CallSequenceMetadata) error {
+	if c.storageDirectory == "" {
+		return nil
+	}
+
+	// Record the hash and creation time of the call sequence in a copy of its metadata.
+	seqHash, err := seq.CanonicalHash()
+	if err != nil {
+		return err
+	}
+	fileMetadata := &CallSequenceMetadata{}
+	if metadata != nil {
+		*fileMetadata = *metadata
+	}
+	fileMetadata.Hash = seqHash
+	fileMetadata.CreatedAt = time.Now()
+	b, err := marshalCallSequenceFile(seq, fileMetadata)
+	if err != nil {
+		return err
+	}
+
+	// Lock while writing, so concurrent improvements to the same test do not race on its file.
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+	err = utils.MakeDirectory(c.storageDirectory)
+	if err != nil {
+		return err
+	}
+	err = utils.MakeDirectory(c.OptimizationCallSequencesDirectory())
+	if err != nil {
+		return err
+	}
+	err = c.writeVersion()
+	if err != nil {
+		return err
+	}
+	return writeCorpusFile(c.optimizationCallSequenceFilePath(testID), b, false)
+}
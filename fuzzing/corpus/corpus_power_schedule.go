@@ -0,0 +1,132 @@
+package corpus
+
+import (
+	"math/big"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/utils/randomutils"
+)
+
+// powerScheduleWeightScale describes the scale applied to a call sequence's rarity score to obtain its integer weight
+// in the corpus' weighted random chooser.
+const powerScheduleWeightScale = 1000
+
+// powerScheduleEntry describes a call sequence tracked by the corpus power schedule, alongside the coverage locations
+// used to determine its rarity.
+type powerScheduleEntry struct {
+	// choice describes the weighted choice for the call sequence in the corpus' weighted random chooser.
+	choice *randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]]
+
+	// coveredLocations describes the unique coverage locations the call sequence was found to reach.
+	coveredLocations []coverage.CoverageLocation
+}
+
+// addPowerScheduleEntry registers a call sequence choice with the power schedule, recording the coverage locations
+// it reached and updating the hit counts for each. As only the hit counts of these locations change, only the weights
+// of the call sequences reaching them are updated. The caller must hold the call sequences lock.
+func (c *Corpus) addPowerScheduleEntry(choice *randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]], coveredLocations []coverage.CoverageLocation) {
+	// De-duplicate the coverage locations provided, as they may be collected across multiple calls.
+	entry := &powerScheduleEntry{
+		choice:           choice,
+		coveredLocations: make([]coverage.CoverageLocation, 0, len(coveredLocations)),
+	}
+	seenLocations := make(map[coverage.CoverageLocation]struct{}, len(coveredLocations))
+	for _, location := range coveredLocations {
+		if _, seen := seenLocations[location]; !seen {
+			seenLocations[location] = struct{}{}
+			entry.coveredLocations = append(entry.coveredLocations, location)
+		}
+	}
+
+	// Add our entry to the power schedule, and update the weights of every entry which shares a location with it.
+	c.powerScheduleEntries[choice] = entry
+	affectedEntries := map[*powerScheduleEntry]struct{}{entry: {}}
+	for _, location := range entry.coveredLocations {
+		c.coverageLocationHitCounts[location]++
+		locationEntries, exists := c.coverageLocationEntries[location]
+		if !exists {
+			locationEntries = make(map[*powerScheduleEntry]struct{})
+			c.coverageLocationEntries[location] = locationEntries
+		}
+		locationEntries[entry] = struct{}{}
+		for locationEntry := range locationEntries {
+			affectedEntries[locationEntry] = struct{}{}
+		}
+	}
+	c.updatePowerScheduleWeights(affectedEntries)
+}
+
+// removePowerScheduleEntry stops tracking the provided call sequence choice in the power schedule, if it was tracked,
+// updating the hit counts of the coverage locations it reached and the weights of the call sequences which share them.
+// The caller must hold the call sequences lock.
+func (c *Corpus) removePowerScheduleEntry(choice *randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]]) {
+	entry, exists := c.powerScheduleEntries[choice]
+	if !exists {
+		return
+	}
+	delete(c.powerScheduleEntries, choice)
+
+	// Remove the entry from each location it reached, and update the weights of the entries remaining there.
+	affectedEntries := make(map[*powerScheduleEntry]struct{})
+	for _, location := range entry.coveredLocations {
+		c.coverageLocationHitCounts[location]--
+		locationEntries := c.coverageLocationEntries[location]
+		delete(locationEntries, entry)
+		if len(locationEntries) == 0 {
+			delete(c.coverageLocationHitCounts, location)
+			delete(c.coverageLocationEntries, location)
+			continue
+		}
+		for locationEntry := range locationEntries {
+			affectedEntries[locationEntry] = struct{}{}
+		}
+	}
+	c.updatePowerScheduleWeights(affectedEntries)
+}
+
+// updatePowerScheduleWeights recalculates the weight of each provided call sequence tracked by the power schedule. A
+// call sequence's rarity score is the sum of the inverse hit counts of every coverage location it reached, such that
+// sequences reaching locations few other sequences reach are selected for mutation more often. The caller must hold
+// the call sequences lock.
+func (c *Corpus) updatePowerScheduleWeights(entries map[*powerScheduleEntry]struct{}) {
+	for entry := range entries {
+		// Calculate the rarity score for this entry.
+		rarityScore := 0.0
+		for _, location := range entry.coveredLocations {
+			rarityScore += 1.0 / float64(c.coverageLocationHitCounts[location])
+		}
+
+		// Update the weight, ensuring it is non-zero.
+		weight := big.NewInt(int64(rarityScore*powerScheduleWeightScale) + 1)
+		c.setCallSequenceChoiceWeight(entry.choice, weight)
+	}
+}
+
+// PowerScheduleEnabled indicates whether the corpus uses a power schedule to weight call sequence selection by the
+// rarity of the coverage each sequence reached.
+func (c *Corpus) PowerScheduleEnabled() bool {
+	return c.powerScheduleEnabled
+}
+
+// SetPowerScheduleEnabled sets whether the corpus should use a power schedule to weight call sequence selection by
+// the rarity of the coverage each sequence reached. When enabled, weights provided when adding call sequences are
+// ignored. This must be set prior to calling Initialize.
+func (c *Corpus) SetPowerScheduleEnabled(enabled bool) {
+	c.powerScheduleEnabled = enabled
+}
+
+// CallSequenceWeights returns the weights of each active call sequence in the corpus' weighted random chooser, in the
+// order they were added. This is used to inspect the selection bias applied by the corpus.
+func (c *Corpus) CallSequenceWeights() []*big.Int {
+	// Acquire our call sequences lock during the duration of this method.
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+
+	// Collect the weights of each choice.
+	weights := make([]*big.Int, len(c.callSequenceChoices))
+	for i, choice := range c.callSequenceChoices {
+		weights[i] = choice.Weight()
+	}
+	return weights
+}
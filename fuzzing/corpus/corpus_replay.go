@@ -0,0 +1,562 @@
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/logging"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// corpusReplayProgressInterval describes how often progress is logged while the corpus is replayed to measure its
+// coverage.
+const corpusReplayProgressInterval = 5 * time.Second
+
+// callSequenceReplayCheckFunc describes a function called after each call is executed while replaying a corpus call
+// sequence. It is given the contracts deployed on the chain, keyed by address, and the call sequence executed to this
+// point.
+// Returns a boolean indicating if the replay of the sequence should stop, or an error if one occurs.
+type callSequenceReplayCheckFunc func(deployedContracts map[common.Address]*contracts.Contract, executedSequence calls.CallSequence) (bool, error)
+
+// callSequenceReplayResultFunc describes a function called after a corpus call sequence was replayed, prior to the
+// chain being reverted. It is given the corpus file of the sequence, and an error describing why the sequence could
+// not be replayed, or nil if it replayed successfully.
+// Returns an error if one occurs.
+type callSequenceReplayResultFunc func(sequenceFile *corpusFile[calls.CallSequence], sequenceInvalidError error) error
+
+// replayTestChain describes a clone of a post-setup (deployment) test chain used to replay corpus call sequences,
+// alongside the compiled contracts deployed on it.
+type replayTestChain struct {
+	// testChain describes the cloned test chain call sequences are replayed on.
+	testChain *chain.TestChain
+
+	// deployedContracts describes the contracts deployed on testChain which were matched to compiled contracts,
+	// keyed by address.
+	deployedContracts map[common.Address]*contracts.Contract
+
+	// proxyResolver resolves the implementations of deployed proxy contracts, so calls made to implementation
+	// methods through proxies can be resolved.
+	proxyResolver *contracts.ProxyImplementationResolver
+
+	// baseBlockNumber describes the block number the chain is reverted to after each call sequence is replayed.
+	baseBlockNumber uint64
+}
+
+// newReplayTestChain clones the provided post-setup (deployment) test chain to replay call sequences on, tracking
+// deployments of the provided compiled contracts, and resolving the implementations of proxies with the provided
+// configured proxy implementations. The provided chain setup function is called on the clone after genesis, and may
+// be nil.
+// Returns the replay test chain, or an error if one occurs.
+func newReplayTestChain(baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts, proxyImplementations map[string]string, chainSetupFunc func(testChain *chain.TestChain)) (*replayTestChain, error) {
+	// Create our structure and event listeners to track deployed contracts
+	deployedContracts := make(map[common.Address]*contracts.Contract, 0)
+
+	// Clone our test chain, adding listeners for contract deployment events from genesis.
+	testChain, err := baseTestChain.Clone(func(newChain *chain.TestChain) error {
+		// After genesis, prior to adding other blocks, we allow the caller to attach any tracers.
+		if chainSetupFunc != nil {
+			chainSetupFunc(newChain)
+		}
+
+		// We also track any contract deployments, so we can resolve contract/method definitions for corpus call
+		// sequences.
+		newChain.Events.ContractDeploymentAddedEventEmitter.Subscribe(func(event chain.ContractDeploymentsAddedEvent) error {
+			matchedContract := contractDefinitions.MatchBytecode(event.Contract.InitBytecode, event.Contract.RuntimeBytecode)
+			if matchedContract != nil {
+				deployedContracts[event.Contract.Address] = matchedContract
+			}
+			return nil
+		})
+		newChain.Events.ContractDeploymentRemovedEventEmitter.Subscribe(func(event chain.ContractDeploymentsRemovedEvent) error {
+			delete(deployedContracts, event.Contract.Address)
+			return nil
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay corpus, base test chain cloning encountered error: %v", err)
+	}
+
+	// Cache current HeadBlockNumber so that you can reset back to it after every sequence
+	return &replayTestChain{
+		testChain:         testChain,
+		deployedContracts: deployedContracts,
+		proxyResolver:     contracts.NewProxyImplementationResolver(contractDefinitions, proxyImplementations),
+		baseBlockNumber:   testChain.HeadBlockNumber(),
+	}, nil
+}
+
+// replayCallSequence replays the provided corpus call sequence on the replay test chain, resolving references to
+// the compiled contracts deployed on it, then reverts the chain. The check function is called after each call and
+// may be nil, while the result function is called after the sequence, prior to the chain being reverted.
+// Returns an error if one occurs.
+func (r *replayTestChain) replayCallSequence(sequenceFileData *corpusFile[calls.CallSequence], checkFunc callSequenceReplayCheckFunc, resultFunc callSequenceReplayResultFunc) error {
+	// Unwrap the underlying sequence.
+	sequence := sequenceFileData.data
+
+	// Define a variable to track whether we should disable this sequence (if it is no longer applicable in some
+	// way).
+	sequenceInvalidError := error(nil)
+	fetchElementFunc := func(currentIndex int) (*calls.CallSequenceElement, error) {
+		// If we are at the end of our sequence, return nil indicating we should stop executing.
+		if currentIndex >= len(sequence) {
+			return nil, nil
+		}
+
+		// If we are deploying a contract and not targeting one with this call, there should be no work to do.
+		currentSequenceElement := sequence[currentIndex]
+		if currentSequenceElement.Call.MsgTo == nil {
+			return currentSequenceElement, nil
+		}
+
+		// We are calling a contract with this call, ensure we can resolve the contract call is targeting.
+		resolvedContract, resolvedContractExists := r.deployedContracts[*currentSequenceElement.Call.MsgTo]
+		if !resolvedContractExists {
+			sequenceInvalidError = fmt.Errorf("contract at address '%v' could not be resolved", currentSequenceElement.Call.MsgTo.String())
+			return nil, nil
+		}
+		currentSequenceElement.Contract = resolvedContract
+
+		// Next, if our sequence element uses ABI values to produce call data, our deserialized data is not yet
+		// sufficient for runtime use, until we use it to resolve runtime references.
+		// If the method is not declared by the contract, it may be declared by the implementation of a proxy, which it
+		// was called through.
+		callAbiValues := currentSequenceElement.Call.MsgDataAbiValues
+		if callAbiValues != nil {
+			sequenceInvalidError = callAbiValues.Resolve(currentSequenceElement.Contract.CompiledContract().Abi)
+			if sequenceInvalidError != nil {
+				implementation := r.proxyResolver.Resolve(r.testChain.State(), *currentSequenceElement.Call.MsgTo, resolvedContract, r.deployedContracts)
+				if implementation == nil || callAbiValues.Resolve(implementation.CompiledContract().Abi) != nil {
+					return nil, nil
+				}
+				currentSequenceElement.Contract = implementation
+				sequenceInvalidError = nil
+			}
+		}
+		return currentSequenceElement, nil
+	}
+
+	// Define actions to perform after executing each call in the sequence.
+	var executionCheckFunc calls.ExecuteCallSequenceExecutionCheckFunc
+	if checkFunc != nil {
+		executionCheckFunc = func(currentlyExecutedSequence calls.CallSequence) (bool, error) {
+			return checkFunc(r.deployedContracts, currentlyExecutedSequence)
+		}
+	}
+
+	// Execute each call sequence, populating runtime data along the way.
+	_, err := calls.ExecuteCallSequenceIteratively(r.testChain, fetchElementFunc, executionCheckFunc)
+
+	// If we failed to replay a sequence due to an unexpected error, report it.
+	if err != nil {
+		return fmt.Errorf("failed to replay corpus, encountered an error while executing call sequence: %v\n", err)
+	}
+
+	// Report the outcome of replaying the sequence.
+	err = resultFunc(sequenceFileData, sequenceInvalidError)
+	if err != nil {
+		return err
+	}
+
+	// Revert chain state to our starting point to test the next sequence.
+	err = r.testChain.RevertToBlockNumber(r.baseBlockNumber)
+	if err != nil {
+		return fmt.Errorf("failed to reset the chain while replaying corpus: %v\n", err)
+	}
+	return nil
+}
+
+// replayCallSequences replays every call sequence in the corpus on a clone of the provided post-setup (deployment)
+// test chain, resolving references to the provided compiled contracts and reverting the chain after each sequence.
+// The provided chain setup function is called on the clone after genesis, the check function after each call, and
+// the result function after each sequence. The check function may be nil.
+// Returns an error if one occurs.
+func (c *Corpus) replayCallSequences(baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts, chainSetupFunc func(testChain *chain.TestChain), checkFunc callSequenceReplayCheckFunc, resultFunc callSequenceReplayResultFunc) error {
+	return c.replayCallSequenceFiles(c.callSequences, baseTestChain, contractDefinitions, chainSetupFunc, checkFunc, resultFunc)
+}
+
+// replayCallSequenceFiles behaves as replayCallSequences, but replays only the provided corpus call sequences.
+// Returns an error if one occurs.
+func (c *Corpus) replayCallSequenceFiles(sequenceFiles []*corpusFile[calls.CallSequence], baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts, chainSetupFunc func(testChain *chain.TestChain), checkFunc callSequenceReplayCheckFunc, resultFunc callSequenceReplayResultFunc) error {
+	// If there is nothing to replay, we can avoid cloning the chain.
+	if len(sequenceFiles) == 0 {
+		return nil
+	}
+
+	replayChain, err := newReplayTestChain(baseTestChain, contractDefinitions, c.proxyImplementations, chainSetupFunc)
+	if err != nil {
+		return err
+	}
+	defer replayChain.testChain.Close()
+	for _, sequenceFileData := range sequenceFiles {
+		err = replayChain.replayCallSequence(sequenceFileData, checkFunc, resultFunc)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// corpusReplayResult describes the outcome of replaying a single corpus call sequence to measure its coverage.
+type corpusReplayResult struct {
+	// coveredLocations describes the coverage locations reached by the call sequence, if the power schedule is
+	// enabled.
+	coveredLocations []coverage.CoverageLocation
+
+	// sequenceInvalidError describes why the call sequence could not be replayed, or nil if it replayed successfully.
+	sequenceInvalidError error
+}
+
+// SetProxyImplementations sets the names of the implementation contracts configured for proxies, keyed by the name or
+// address of the proxy, so calls made to implementation methods through proxies can be resolved when call sequences
+// are replayed. Proxies which store their implementation in their EIP-1967 implementation slot are resolved
+// regardless. This must be set prior to Initialize to take effect.
+func (c *Corpus) SetProxyImplementations(proxyImplementations map[string]string) {
+	c.proxyImplementations = proxyImplementations
+}
+
+// SetReplayWorkers sets the amount of call sequences replayed in parallel to measure coverage when the corpus is
+// initialized, each on its own clone of the base test chain. Values below one replay call sequences one at a time.
+// This must be set prior to Initialize to take effect.
+func (c *Corpus) SetReplayWorkers(workers int) {
+	c.replayWorkers = workers
+}
+
+// replayCallSequenceFilesForCoverage replays the provided corpus call sequences on clones of the provided post-setup
+// (deployment) test chain, resolving references to the provided compiled contracts and merging the coverage reached
+// by each call into the corpus coverage maps. Call sequences are partitioned across the corpus' replay workers, each
+// replaying on its own chain, while progress is logged every corpusReplayProgressInterval. As coverage merging is
+// order-independent, the coverage measured does not depend on the order call sequences are replayed in.
+// Returns the outcome of replaying each call sequence, in the order provided, or an error if one occurs.
+func (c *Corpus) replayCallSequenceFilesForCoverage(sequenceFiles []*corpusFile[calls.CallSequence], baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts) ([]corpusReplayResult, error) {
+	// If there is nothing to replay, we can avoid cloning the chain.
+	results := make([]corpusReplayResult, len(sequenceFiles))
+	if len(sequenceFiles) == 0 {
+		return results, nil
+	}
+	workers := c.replayWorkers
+	if workers < 1 {
+		workers = 1
+	} else if workers > len(sequenceFiles) {
+		workers = len(sequenceFiles)
+	}
+
+	// Report our progress periodically until replay completes.
+	var replayed, stale uint64
+	replayDone, progressStopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(progressStopped)
+		ticker := time.NewTicker(corpusReplayProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-replayDone:
+				return
+			case <-ticker.C:
+			}
+			replayedCount, staleCount := atomic.LoadUint64(&replayed), atomic.LoadUint64(&stale)
+			logging.GlobalLogger.Info().Uint64("replayed", replayedCount).Int("total", len(sequenceFiles)).Uint64("stale", staleCount).Msgf("Replaying corpus, replayed %d/%d, %d stale", replayedCount, len(sequenceFiles), staleCount)
+		}
+	}()
+
+	// Each worker replays the next call sequence no worker has taken yet, until none remain or a worker encountered
+	// an error.
+	var nextIndex, failed uint64
+	var replayErr error
+	var replayErrLock sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.replayCallSequenceFilesForCoverageWorker(sequenceFiles, baseTestChain, contractDefinitions, func() (int, bool) {
+				index := atomic.AddUint64(&nextIndex, 1) - 1
+				return int(index), index < uint64(len(sequenceFiles)) && atomic.LoadUint64(&failed) == 0
+			}, func(index int, result corpusReplayResult) {
+				results[index] = result
+				if result.sequenceInvalidError != nil {
+					atomic.AddUint64(&stale, 1)
+				}
+				atomic.AddUint64(&replayed, 1)
+			})
+			if err != nil {
+				atomic.StoreUint64(&failed, 1)
+				replayErrLock.Lock()
+				if replayErr == nil {
+					replayErr = err
+				}
+				replayErrLock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	close(replayDone)
+	<-progressStopped
+	if replayErr != nil {
+		return nil, replayErr
+	}
+	logging.GlobalLogger.Info().Int("replayed", len(sequenceFiles)).Uint64("stale", stale).Int("workers", workers).Msgf("Replayed %d corpus call sequences using %d worker(s), %d stale", len(sequenceFiles), workers, stale)
+	return results, nil
+}
+
+// replayCallSequenceFilesForCoverageWorker replays corpus call sequences for replayCallSequenceFilesForCoverage on its
+// own clone of the provided post-setup (deployment) test chain, with its own coverage tracer. The provided next
+// function obtains the index of the next call sequence to replay, and whether one remains, while the result function
+// records the outcome of replaying the call sequence at an index.
+// Returns an error if one occurs.
+func (c *Corpus) replayCallSequenceFilesForCoverageWorker(sequenceFiles []*corpusFile[calls.CallSequence], baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts, nextFunc func() (int, bool), resultFunc func(index int, result corpusReplayResult)) error {
+	// Create our coverage tracer, attaching it to our chain after genesis, prior to adding other blocks. Tracers
+	// track the state of the transaction being executed, so each chain requires its own.
+	coverageTracer := coverage.NewCoverageTracer()
+	coverageTracer.SetEdgeCoverageEnabled(c.coverageFeedback.UsesEdgeCoverage())
+	coverageTracer.SetHitCountsEnabled(c.hitCountsEnabled)
+	replayChain, err := newReplayTestChain(baseTestChain, contractDefinitions, c.proxyImplementations, func(newChain *chain.TestChain) {
+		newChain.AddTracer(coverageTracer, true, false)
+	})
+	if err != nil {
+		return err
+	}
+	defer replayChain.testChain.Close()
+
+	// Define a variable to track the coverage locations reached by the sequence being replayed, for use in the power
+	// schedule.
+	var sequenceCoveredLocations []coverage.CoverageLocation
+
+	// Update our coverage maps for each call executed in our sequence.
+	checkFunc := func(_ map[common.Address]*contracts.Contract, currentlyExecutedSequence calls.CallSequence) (bool, error) {
+		lastExecutedSequenceElement := currentlyExecutedSequence[len(currentlyExecutedSequence)-1]
+		covMaps := coverage.GetCoverageTracerResults(lastExecutedSequenceElement.ChainReference.MessageResults())
+		if c.powerScheduleEnabled && covMaps != nil {
+			sequenceCoveredLocations = append(sequenceCoveredLocations, covMaps.CoveredLocations()...)
+		}
+		_, covErr := c.coverageMaps.Update(covMaps)
+		if covErr != nil {
+			return true, covErr
+		}
+		return false, nil
+	}
+
+	// Replay call sequences until none remain.
+	for index, ok := nextFunc(); ok; index, ok = nextFunc() {
+		sequenceCoveredLocations = make([]coverage.CoverageLocation, 0)
+		err = replayChain.replayCallSequence(sequenceFiles[index], checkFunc, func(_ *corpusFile[calls.CallSequence], sequenceInvalidError error) error {
+			resultFunc(index, corpusReplayResult{coveredLocations: sequenceCoveredLocations, sequenceInvalidError: sequenceInvalidError})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CallSequenceVerificationStatus describes the outcome of verifying a corpus call sequence.
+type CallSequenceVerificationStatus string
+
+const (
+	// CallSequenceVerificationStatusValid indicates the call sequence replayed without issue.
+	CallSequenceVerificationStatusValid CallSequenceVerificationStatus = "valid"
+
+	// CallSequenceVerificationStatusStale indicates the call sequence could not be replayed, as it references a
+	// contract or method which no longer exists.
+	CallSequenceVerificationStatusStale CallSequenceVerificationStatus = "stale"
+
+	// CallSequenceVerificationStatusViolated indicates the call sequence replayed, but violated a test.
+	CallSequenceVerificationStatusViolated CallSequenceVerificationStatus = "violated"
+)
+
+// CallSequenceVerification describes the outcome of verifying a corpus call sequence.
+type CallSequenceVerification struct {
+	// FilePath describes the path of the corpus file the call sequence was read from.
+	FilePath string `json:"filePath"`
+
+	// Status describes the outcome of verifying the call sequence.
+	Status CallSequenceVerificationStatus `json:"status"`
+
+	// Error describes why a stale call sequence could not be replayed.
+	Error string `json:"error,omitempty"`
+
+	// ViolatedTests describes the tests the call sequence violated.
+	ViolatedTests []string `json:"violatedTests,omitempty"`
+}
+
+// CallSequenceVerificationTestFunc describes a function called after each call is executed while verifying a corpus
+// call sequence, which tests whether the chain state violates any tests. It is given the chain, the contracts
+// deployed on it keyed by address, and the call sequence executed to this point.
+// Returns the names of any tests which were violated, or an error if one occurs.
+type CallSequenceVerificationTestFunc func(testChain *chain.TestChain, deployedContracts map[common.Address]*contracts.Contract, executedSequence calls.CallSequence) ([]string, error)
+
+// VerifyCallSequences replays every call sequence in the corpus on the provided post-setup (deployment) test chain,
+// without mutating them, to determine whether each is still valid for the provided compiled contracts. The provided
+// test function is called after each call, and replay of a sequence stops at the first call which violates a test.
+// Returns the verification outcome of every call sequence in the corpus, or an error if one occurs.
+func (c *Corpus) VerifyCallSequences(baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts, testFunc CallSequenceVerificationTestFunc) ([]CallSequenceVerification, error) {
+	// Acquire our call sequences lock during the duration of this method.
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+
+	// Track the chain we replay on, so the test function can query it, and the tests each sequence violated.
+	var testChain *chain.TestChain
+	var violatedTests []string
+	checkFunc := func(deployedContracts map[common.Address]*contracts.Contract, executedSequence calls.CallSequence) (bool, error) {
+		if testFunc == nil {
+			return false, nil
+		}
+		var err error
+		violatedTests, err = testFunc(testChain, deployedContracts, executedSequence)
+		return len(violatedTests) > 0 || err != nil, err
+	}
+
+	// Replay our sequences, recording the outcome of each.
+	verifications := make([]CallSequenceVerification, 0, len(c.callSequences))
+	resultFunc := func(sequenceFile *corpusFile[calls.CallSequence], sequenceInvalidError error) error {
+		verification := CallSequenceVerification{
+			FilePath: sequenceFile.filePath,
+			Status:   CallSequenceVerificationStatusValid,
+		}
+		if sequenceInvalidError != nil {
+			verification.Status = CallSequenceVerificationStatusStale
+			verification.Error = sequenceInvalidError.Error()
+		} else if len(violatedTests) > 0 {
+			verification.Status = CallSequenceVerificationStatusViolated
+			verification.ViolatedTests = violatedTests
+		}
+		verifications = append(verifications, verification)
+		violatedTests = nil
+		return nil
+	}
+	chainSetupFunc := func(newChain *chain.TestChain) {
+		testChain = newChain
+	}
+	err := c.replayCallSequences(baseTestChain, contractDefinitions, chainSetupFunc, checkFunc, resultFunc)
+	if err != nil {
+		return nil, err
+	}
+	return verifications, nil
+}
+
+// CallSequenceReplay describes the outcome of replaying a single call sequence with ReplayCallSequence.
+type CallSequenceReplay struct {
+	// ExecutedCallSequence describes the calls of the call sequence which were executed, up to and including the
+	// first call which violated a test.
+	ExecutedCallSequence calls.CallSequence
+
+	// InvalidError describes why the call sequence could not be replayed (e.g. as it references a contract or method
+	// which no longer exists), or nil if it replayed successfully.
+	InvalidError error
+
+	// ViolatedTests describes the tests the call sequence violated.
+	ViolatedTests []string
+}
+
+// ReplayCallSequence replays the provided call sequence, as read from disk, on a clone of the provided post-setup
+// (deployment) test chain, resolving references to the provided compiled contracts, and to implementations of proxies
+// with the provided configured proxy implementations. The provided test function is called after each call, and
+// replay stops at the first call which violates a test. It may be nil.
+// Returns the outcome of replaying the call sequence, or an error if one occurs.
+func ReplayCallSequence(baseTestChain *chain.TestChain, contractDefinitions contracts.Contracts, proxyImplementations map[string]string, callSequence calls.CallSequence, testFunc CallSequenceVerificationTestFunc) (*CallSequenceReplay, error) {
+	// Track the chain we replay on, so the test function can query it.
+	var testChain *chain.TestChain
+	replayChain, err := newReplayTestChain(baseTestChain, contractDefinitions, proxyImplementations, func(newChain *chain.TestChain) {
+		testChain = newChain
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer replayChain.testChain.Close()
+
+	// Replay our sequence, recording the calls executed and any tests they violated.
+	replay := &CallSequenceReplay{}
+	checkFunc := func(deployedContracts map[common.Address]*contracts.Contract, executedSequence calls.CallSequence) (bool, error) {
+		replay.ExecutedCallSequence = executedSequence
+		if testFunc == nil {
+			return false, nil
+		}
+		var err error
+		replay.ViolatedTests, err = testFunc(testChain, deployedContracts, executedSequence)
+		return len(replay.ViolatedTests) > 0 || err != nil, err
+	}
+	resultFunc := func(_ *corpusFile[calls.CallSequence], sequenceInvalidError error) error {
+		replay.InvalidError = sequenceInvalidError
+		return nil
+	}
+	err = replayChain.replayCallSequence(&corpusFile[calls.CallSequence]{data: callSequence}, checkFunc, resultFunc)
+	if err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// RemoveCallSequences removes the call sequences read from the provided corpus file paths from the corpus, deleting
+// their files. This should be called prior to Initialize.
+// Returns an error if one occurs.
+func (c *Corpus) RemoveCallSequences(filePaths []string) error {
+	// Acquire our call sequences lock during the duration of this method.
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+
+	// Determine which file paths to remove.
+	removedFilePaths := make(map[string]bool, len(filePaths))
+	for _, filePath := range filePaths {
+		removedFilePaths[filePath] = true
+	}
+
+	// Remove each matching call sequence, deleting its file.
+	remainingCallSequences := make([]*corpusFile[calls.CallSequence], 0, len(c.callSequences))
+	for _, sequenceFile := range c.callSequences {
+		if sequenceFile.filePath == "" || !removedFilePaths[sequenceFile.filePath] {
+			remainingCallSequences = append(remainingCallSequences, sequenceFile)
+			continue
+		}
+		err := os.Remove(sequenceFile.filePath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove corpus file '%v': %v", sequenceFile.filePath, err)
+		}
+	}
+	c.callSequences = remainingCallSequences
+	return nil
+}
+
+// DetachCallSequences re-encodes the ABI values of every call in the corpus as though they were read from disk,
+// detaching them from the compiled contracts they were resolved against when the corpus was last initialized. This
+// should be called prior to Initialize when the corpus is initialized again for recompiled contracts, so its call
+// sequences are resolved against them, and those which no longer apply to them are disabled as stale.
+// Returns an error if one occurs.
+func (c *Corpus) DetachCallSequences() error {
+	// Acquire our call sequences lock during the duration of this method.
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+
+	for _, sequenceFiles := range [][]*corpusFile[calls.CallSequence]{c.callSequences, c.optimizationCallSequences, c.resumedCallSequences} {
+		for _, sequenceFile := range sequenceFiles {
+			for _, element := range sequenceFile.data {
+				// The contract each call targets is resolved again when it is replayed. Calls whose ABI values were
+				// never resolved (e.g. as their sequence was stale) are still encoded, so they are left as they are.
+				element.Contract = nil
+				callAbiValues := element.Call.MsgDataAbiValues
+				if callAbiValues == nil || callAbiValues.Method == nil {
+					continue
+				}
+				b, err := json.Marshal(callAbiValues)
+				if err != nil {
+					return fmt.Errorf("failed to detach corpus call sequence: %v", err)
+				}
+				detachedAbiValues := &calls.CallMessageDataAbiValues{}
+				err = json.Unmarshal(b, detachedAbiValues)
+				if err != nil {
+					return fmt.Errorf("failed to detach corpus call sequence: %v", err)
+				}
+				element.Call.MsgDataAbiValues = detachedAbiValues
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package corpus
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/utils/randomutils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// stateHashEntry describes a call sequence added to the corpus in the current campaign, tracked by the hash of the
+// state it reached, so call sequences reaching the same state can be deduplicated against it.
+type stateHashEntry struct {
+	// sequenceFile describes the corpus file of the call sequence.
+	sequenceFile *corpusFile[calls.CallSequence]
+
+	// choice describes the weighted random choice for the call sequence, or nil if the corpus was not initialized
+	// when it was added.
+	choice *randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]]
+}
+
+// SetStateDeduplication sets whether call sequences which add no more than maxCoverageDelta newly covered locations
+// to the corpus should be deduplicated by the state they reach, as provided to
+// AddCallSequenceIfCoverageChangedWithStateHash. Such a call sequence replaces the call sequence added earlier in the
+// campaign which reached the same state if it is shorter, or is discarded otherwise. This must be set prior to
+// calling Initialize.
+func (c *Corpus) SetStateDeduplication(enabled bool, maxCoverageDelta int) {
+	c.stateDeduplicationEnabled = enabled
+	c.stateDeduplicationMaxCoverageDelta = maxCoverageDelta
+}
+
+// StateDuplicateCallSequenceCount returns the count of call sequences which reached the same state as a call sequence
+// already in the corpus while adding little coverage, and either replaced it, being shorter, or were discarded.
+func (c *Corpus) StateDuplicateCallSequenceCount() uint64 {
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+	return c.stateDuplicateCallSequenceCount
+}
+
+// resolveStateDuplicate determines whether the provided call sequence, which reached the state with the provided
+// hash, should be added to the corpus. If a call sequence which reached the same state was added earlier in the
+// campaign, the provided one is only added if it is shorter, in which case the earlier one is removed. The caller
+// must hold the call sequences lock.
+// Returns a boolean indicating whether the call sequence should be added.
+func (c *Corpus) resolveStateDuplicate(seq calls.CallSequence, stateHash common.Hash) bool {
+	existingEntry, exists := c.stateHashEntries[stateHash]
+	if !exists {
+		return true
+	}
+	c.stateDuplicateCallSequenceCount++
+	if len(seq) >= len(existingEntry.sequenceFile.data) {
+		return false
+	}
+	c.removeStateHashEntry(stateHash, existingEntry)
+	return true
+}
+
+// removeStateHashEntry removes the call sequence of the provided entry from the corpus, disabling its choice so it is
+// no longer selected. Its file is only deleted on the next Flush, once the call sequence replacing it was written, so
+// the state it reached is not lost from the corpus directory if the fuzzer exits in between. The caller must hold the
+// call sequences lock.
+func (c *Corpus) removeStateHashEntry(stateHash common.Hash, entry *stateHashEntry) {
+	delete(c.stateHashEntries, stateHash)
+
+	// Remove the call sequence from our corpus.
+	for i, sequenceFile := range c.callSequences {
+		if sequenceFile == entry.sequenceFile {
+			c.callSequences = append(c.callSequences[:i], c.callSequences[i+1:]...)
+			break
+		}
+	}
+	if entry.sequenceFile.filePath != "" {
+		c.replacedCallSequenceFilePaths = append(c.replacedCallSequenceFilePaths, entry.sequenceFile.filePath)
+	}
+
+	// If it was added to our chooser, disable it, and stop tracking it in the power schedule.
+	if entry.choice == nil {
+		return
+	}
+	c.setCallSequenceChoiceWeight(entry.choice, big.NewInt(0))
+	c.replacedCallSequenceCount++
+	c.removePowerScheduleEntry(entry.choice)
+}
+
+// removeReplacedCallSequenceFiles deletes the files of the call sequences which were replaced by shorter call
+// sequences reaching the same state. The caller must hold the call sequences lock, and should only call this once the
+// call sequences replacing them were written.
+// Returns an error if one occurs.
+func (c *Corpus) removeReplacedCallSequenceFiles() error {
+	for len(c.replacedCallSequenceFilePaths) > 0 {
+		filePath := c.replacedCallSequenceFilePaths[0]
+		err := os.Remove(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove corpus file '%v': %v", filePath, err)
+		}
+		c.replacedCallSequenceFilePaths = c.replacedCallSequenceFilePaths[1:]
+	}
+	return nil
+}
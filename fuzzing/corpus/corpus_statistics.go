@@ -0,0 +1,119 @@
+package corpus
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CorpusStatistics summarizes the provenance of the call sequences in a corpus, as recorded in their metadata.
+type CorpusStatistics struct {
+	// CallSequenceCount describes the count of call sequences in the corpus.
+	CallSequenceCount int `json:"callSequenceCount"`
+
+	// MissingMetadataCount describes the count of call sequences without metadata, which predate it.
+	MissingMetadataCount int `json:"missingMetadataCount"`
+
+	// OriginCounts describes the count of call sequences with each origin. Call sequences without metadata are
+	// counted under CallSequenceOriginUnknown.
+	OriginCounts map[CallSequenceOrigin]int `json:"originCounts"`
+
+	// WorkerCounts describes the count of call sequences produced by each worker, keyed by worker index.
+	WorkerCounts map[int]int `json:"workerCounts"`
+
+	// LineageDepthCounts describes the count of call sequences at each lineage depth, where call sequences without
+	// a parent in the corpus have a depth of zero, and those derived from one have a depth one greater than it.
+	LineageDepthCounts map[int]int `json:"lineageDepthCounts"`
+
+	// MissingParentCount describes the count of call sequences whose parent is no longer in the corpus (e.g. it was
+	// pruned).
+	MissingParentCount int `json:"missingParentCount"`
+
+	// MaxChildCount describes the largest count of call sequences derived from a single call sequence in the corpus.
+	MaxChildCount int `json:"maxChildCount"`
+
+	// EarliestCreatedAt describes the creation time of the oldest call sequence with metadata, or nil if none have it.
+	EarliestCreatedAt *time.Time `json:"earliestCreatedAt,omitempty"`
+
+	// LatestCreatedAt describes the creation time of the newest call sequence with metadata, or nil if none have it.
+	LatestCreatedAt *time.Time `json:"latestCreatedAt,omitempty"`
+}
+
+// Statistics summarizes the origins and lineage of the call sequences in the corpus from their metadata. This does
+// not require the corpus to be initialized.
+// Returns the statistics describing the corpus.
+func (c *Corpus) Statistics() *CorpusStatistics {
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+
+	statistics := &CorpusStatistics{
+		CallSequenceCount:  len(c.callSequences),
+		OriginCounts:       make(map[CallSequenceOrigin]int),
+		WorkerCounts:       make(map[int]int),
+		LineageDepthCounts: make(map[int]int),
+	}
+
+	// Index our entries by hash, so we can resolve their parents.
+	metadataByHash := make(map[common.Hash]*CallSequenceMetadata)
+	for _, sequenceFile := range c.callSequences {
+		if sequenceFile.metadata != nil {
+			metadataByHash[sequenceFile.metadata.Hash] = sequenceFile.metadata
+		}
+	}
+
+	// lineageDepth determines the lineage depth of the provided entry by following its parents, stopping if a cycle
+	// is encountered.
+	lineageDepth := func(metadata *CallSequenceMetadata) int {
+		depth := 0
+		visited := map[common.Hash]bool{metadata.Hash: true}
+		for metadata.ParentHash != nil && !visited[*metadata.ParentHash] {
+			parent, ok := metadataByHash[*metadata.ParentHash]
+			if !ok {
+				break
+			}
+			visited[parent.Hash] = true
+			metadata = parent
+			depth++
+		}
+		return depth
+	}
+
+	// Summarize each entry.
+	childCounts := make(map[common.Hash]int)
+	for _, sequenceFile := range c.callSequences {
+		metadata := sequenceFile.metadata
+		if metadata == nil {
+			statistics.MissingMetadataCount++
+			statistics.OriginCounts[CallSequenceOriginUnknown]++
+			statistics.LineageDepthCounts[0]++
+			continue
+		}
+
+		statistics.OriginCounts[metadata.Origin]++
+		if metadata.WorkerIndex != nil {
+			statistics.WorkerCounts[*metadata.WorkerIndex]++
+		}
+		statistics.LineageDepthCounts[lineageDepth(metadata)]++
+		if metadata.ParentHash != nil {
+			if _, ok := metadataByHash[*metadata.ParentHash]; ok {
+				childCounts[*metadata.ParentHash]++
+			} else {
+				statistics.MissingParentCount++
+			}
+		}
+
+		createdAt := metadata.CreatedAt
+		if statistics.EarliestCreatedAt == nil || createdAt.Before(*statistics.EarliestCreatedAt) {
+			statistics.EarliestCreatedAt = &createdAt
+		}
+		if statistics.LatestCreatedAt == nil || createdAt.After(*statistics.LatestCreatedAt) {
+			statistics.LatestCreatedAt = &createdAt
+		}
+	}
+	for _, childCount := range childCounts {
+		if childCount > statistics.MaxChildCount {
+			statistics.MaxChildCount = childCount
+		}
+	}
+	return statistics
+}
@@ -0,0 +1,77 @@
+package corpus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/utils"
+)
+
+// StorageGrowthCallSequencesDirectory returns the directory path where the call sequences which created the most new
+// contract storage slots in a campaign are stored. This is a subdirectory of StorageDirectory. If StorageDirectory is
+// empty, this is as well, indicating persistent storage will not be used.
+func (c *Corpus) StorageGrowthCallSequencesDirectory() string {
+	if c.storageDirectory == "" {
+		return ""
+	}
+	return filepath.Join(c.StorageDirectory(), "storage_growth_sequences")
+}
+
+// WriteStorageGrowthCallSequences stores the provided call sequences as those which created the most new contract
+// storage slots, in descending order, replacing any stored previously. Each is stored in a file named by its rank
+// (e.g. "1.json"), so they are not replayed as corpus call sequences. If StorageDirectory is empty, this does nothing.
+// Returns an error if one occurs.
+func (c *Corpus) WriteStorageGrowthCallSequences(sequences []calls.CallSequence) error {
+	if c.storageDirectory == "" {
+		return nil
+	}
+
+	// Lock while writing, so the directory is not written to concurrently.
+	c.callSequencesLock.Lock()
+	defer c.callSequencesLock.Unlock()
+	err := utils.MakeDirectory(c.storageDirectory)
+	if err != nil {
+		return err
+	}
+	err = utils.MakeDirectory(c.StorageGrowthCallSequencesDirectory())
+	if err != nil {
+		return err
+	}
+	err = c.writeVersion()
+	if err != nil {
+		return err
+	}
+
+	// Remove the call sequences stored by a previous campaign, as they may rank differently from ours.
+	matches, err := filepath.Glob(filepath.Join(c.StorageGrowthCallSequencesDirectory(), "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, filePath := range matches {
+		err = os.Remove(filePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Write each call sequence, recording its hash and creation time in its metadata.
+	for i, seq := range sequences {
+		seqHash, err := seq.CanonicalHash()
+		if err != nil {
+			return err
+		}
+		b, err := marshalCallSequenceFile(seq, &CallSequenceMetadata{Hash: seqHash, CreatedAt: time.Now()})
+		if err != nil {
+			return err
+		}
+		filePath := filepath.Join(c.StorageGrowthCallSequencesDirectory(), fmt.Sprintf("%d.json", i+1))
+		err = writeCorpusFile(filePath, b, false)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
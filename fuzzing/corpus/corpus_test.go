@@ -2,13 +2,26 @@ package corpus
 
 import (
 	"encoding/json"
+	"errors"
+	"github.com/crytic/medusa/chain"
+	chainConfig "github.com/crytic/medusa/chain/config"
+	compilationTypes "github.com/crytic/medusa/compilation/types"
 	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/fuzzing/valuegeneration"
+	"github.com/crytic/medusa/utils/randomutils"
 	"github.com/crytic/medusa/utils/testutils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/stretchr/testify/assert"
 	"math/big"
 	"math/rand"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -23,7 +36,7 @@ func getMockSimpleCorpus(minSequences int, maxSequences, minBlocks int, maxBlock
 	// Add the requested number of entries.
 	numSequences := minSequences + (rand.Int() % (maxSequences - minSequences))
 	for i := 0; i < numSequences; i++ {
-		err := corpus.AddCallSequence(getMockCallSequence(minBlocks+(rand.Int()%(maxBlocks-minBlocks))), nil, false)
+		err := corpus.AddCallSequence(getMockCallSequence(minBlocks+(rand.Int()%(maxBlocks-minBlocks))), nil, nil, false)
 		if err != nil {
 			return nil, err
 		}
@@ -104,6 +117,14 @@ func TestCorpusReadWrite(t *testing.T) {
 		assert.NoError(t, err)
 		assert.EqualValues(t, corpus.CallSequenceCount(), len(matches), "Did not find numEntries matches")
 
+		// Ensure every file was written atomically, leaving no temporary files behind
+		for _, directory := range []string{corpus.storageDirectory, corpus.CallSequencesDirectory()} {
+			matches, err = filepath.Glob(filepath.Join(directory, ".tmp-*"))
+			assert.NoError(t, err)
+			assert.Empty(t, matches)
+		}
+		assert.FileExists(t, corpus.VersionFilePath())
+
 		// Wipe corpus clean so that you can now read it in from disk
 		corpus, err = NewCorpus("corpus")
 		assert.NoError(t, err)
@@ -114,6 +135,941 @@ func TestCorpusReadWrite(t *testing.T) {
 	})
 }
 
+// TestCorpusMetadata ensures call sequence metadata is written and read back alongside each call sequence, that files
+// which predate metadata are still read, and that corpus statistics summarize the metadata recorded.
+func TestCorpusMetadata(t *testing.T) {
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		// Add a generated call sequence, and one mutated from it, to a corpus.
+		corpus, err := NewCorpus("corpus")
+		assert.NoError(t, err)
+		workerIndex := 3
+		parentSequence := getMockCallSequence(2)
+		err = corpus.AddCallSequence(parentSequence, nil, &CallSequenceMetadata{WorkerIndex: &workerIndex, Origin: CallSequenceOriginGeneration}, false)
+		assert.NoError(t, err)
+		parentHash, err := parentSequence.CanonicalHash()
+		assert.NoError(t, err)
+		err = corpus.AddCallSequence(getMockCallSequence(3), nil, &CallSequenceMetadata{WorkerIndex: &workerIndex, Origin: CallSequenceOriginMutation, ParentHash: &parentHash}, false)
+		assert.NoError(t, err)
+
+		// Write a call sequence file which predates metadata, then write our corpus to disk and read it back.
+		err = corpus.Flush()
+		assert.NoError(t, err)
+		legacyData, err := json.Marshal(getMockCallSequence(1))
+		assert.NoError(t, err)
+		err = os.WriteFile(filepath.Join(corpus.CallSequencesDirectory(), "legacy.json"), legacyData, os.ModePerm)
+		assert.NoError(t, err)
+		corpus, err = NewCorpus(corpus.storageDirectory)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, corpus.CallSequenceCount())
+
+		// Ensure our metadata was preserved, with the hash of our parent recorded.
+		var parentMetadata *CallSequenceMetadata
+		for _, sequenceFile := range corpus.callSequences {
+			if sequenceFile.metadata != nil && sequenceFile.metadata.Hash == parentHash {
+				parentMetadata = sequenceFile.metadata
+			}
+		}
+		if assert.NotNil(t, parentMetadata) {
+			assert.EqualValues(t, CallSequenceOriginGeneration, parentMetadata.Origin)
+			assert.EqualValues(t, workerIndex, *parentMetadata.WorkerIndex)
+			assert.False(t, parentMetadata.CreatedAt.IsZero())
+		}
+
+		// Ensure our statistics describe every call sequence.
+		statistics := corpus.Statistics()
+		assert.EqualValues(t, 3, statistics.CallSequenceCount)
+		assert.EqualValues(t, 1, statistics.MissingMetadataCount)
+		assert.EqualValues(t, map[CallSequenceOrigin]int{
+			CallSequenceOriginGeneration: 1,
+			CallSequenceOriginMutation:   1,
+			CallSequenceOriginUnknown:    1,
+		}, statistics.OriginCounts)
+		assert.EqualValues(t, map[int]int{workerIndex: 2}, statistics.WorkerCounts)
+		assert.EqualValues(t, map[int]int{0: 2, 1: 1}, statistics.LineageDepthCounts)
+		assert.EqualValues(t, 0, statistics.MissingParentCount)
+		assert.EqualValues(t, 1, statistics.MaxChildCount)
+	})
+}
+
+// TestCorpusDeduplicateCallSequences ensures call sequences which only differ in volatile fields (such as nonces and
+// gas) are added to the corpus once, with the duplicates counted.
+func TestCorpusDeduplicateCallSequences(t *testing.T) {
+	corpus, err := NewCorpus("")
+	assert.NoError(t, err)
+
+	// Add a call sequence, then a clone of it with different nonces and gas limits.
+	sequence := getMockCallSequence(3)
+	err = corpus.AddCallSequence(sequence, nil, nil, false)
+	assert.NoError(t, err)
+	duplicateSequence := make(calls.CallSequence, len(sequence))
+	for i, element := range sequence {
+		duplicateCall := *element.Call
+		duplicateCall.MsgNonce++
+		duplicateCall.MsgGas++
+		duplicateSequence[i] = calls.NewCallSequenceElement(nil, &duplicateCall, element.BlockNumberDelay, element.BlockTimestampDelay)
+	}
+	err = corpus.AddCallSequence(duplicateSequence, nil, nil, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, corpus.CallSequenceCount())
+	assert.EqualValues(t, 1, corpus.DuplicateCallSequenceCount())
+
+	// A call sequence with a different delay is not a duplicate.
+	duplicateSequence[0].BlockNumberDelay++
+	err = corpus.AddCallSequence(duplicateSequence, nil, nil, false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, corpus.CallSequenceCount())
+	assert.EqualValues(t, 1, corpus.DuplicateCallSequenceCount())
+}
+
+// TestCorpusCallSequenceAddedEvents ensures an event is published for each call sequence added to the corpus, with
+// the metadata recorded for it, but not for duplicates.
+func TestCorpusCallSequenceAddedEvents(t *testing.T) {
+	corpus, err := NewCorpus("")
+	assert.NoError(t, err)
+	var addedEvents []CallSequenceAddedEvent
+	corpus.Events.CallSequenceAdded.Subscribe(func(event CallSequenceAddedEvent) error {
+		addedEvents = append(addedEvents, event)
+		return nil
+	})
+
+	// Add a call sequence twice, then another one.
+	sequence := getMockCallSequence(2)
+	assert.NoError(t, corpus.AddCallSequence(sequence, nil, &CallSequenceMetadata{Origin: CallSequenceOriginShrink}, false))
+	assert.NoError(t, corpus.AddCallSequence(sequence, nil, nil, false))
+	assert.NoError(t, corpus.AddCallSequence(getMockCallSequence(3), nil, nil, false))
+
+	// Only the distinct call sequences should have been published, with their hashes recorded.
+	if assert.Len(t, addedEvents, 2) {
+		assert.Len(t, addedEvents[0].CallSequence, 2)
+		assert.EqualValues(t, CallSequenceOriginShrink, addedEvents[0].Metadata.Origin)
+		sequenceHash, err := sequence.CanonicalHash()
+		assert.NoError(t, err)
+		assert.EqualValues(t, sequenceHash, addedEvents[0].Metadata.Hash)
+		assert.Len(t, addedEvents[1].CallSequence, 3)
+	}
+
+	// An error returned by a handler should be returned to the caller.
+	corpus.Events.CallSequenceAdded.Subscribe(func(event CallSequenceAddedEvent) error {
+		return errors.New("handler failed")
+	})
+	assert.ErrorContains(t, corpus.AddCallSequence(getMockCallSequence(4), nil, nil, false), "handler failed")
+}
+
+// TestCorpusCompression ensures compressed call sequences are written atomically and read back alongside any
+// uncompressed call sequences in the same corpus.
+func TestCorpusCompression(t *testing.T) {
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		// Write a corpus without compression, then add more call sequences with compression enabled.
+		corpus, err := getMockSimpleCorpus(5, 10, 1, 3)
+		assert.NoError(t, err)
+		err = corpus.Flush()
+		assert.NoError(t, err)
+		uncompressedCount := corpus.CallSequenceCount()
+		corpus.SetCompressionEnabled(true)
+		for i := 0; i < 3; i++ {
+			err = corpus.AddCallSequence(getMockCallSequence(2), nil, nil, false)
+			assert.NoError(t, err)
+		}
+		err = corpus.Flush()
+		assert.NoError(t, err)
+
+		// Ensure our call sequences were written in each format, without leaving any temporary files behind.
+		matches, err := filepath.Glob(filepath.Join(corpus.CallSequencesDirectory(), "*.json"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, uncompressedCount, len(matches))
+		matches, err = filepath.Glob(filepath.Join(corpus.CallSequencesDirectory(), "*.json.gz"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, len(matches))
+		entries, err := os.ReadDir(corpus.CallSequencesDirectory())
+		assert.NoError(t, err)
+		assert.EqualValues(t, uncompressedCount+3, len(entries))
+
+		// Read the corpus back, ensuring every call sequence was read intact.
+		expectedSequences := make(map[common.Hash]calls.CallSequence)
+		for _, sequenceFile := range corpus.callSequences {
+			expectedSequences[sequenceFile.metadata.Hash] = sequenceFile.data
+		}
+		corpus, err = NewCorpus(corpus.storageDirectory)
+		assert.NoError(t, err)
+		assert.EqualValues(t, uncompressedCount+3, corpus.CallSequenceCount())
+		for _, sequenceFile := range corpus.callSequences {
+			testCorpusCallSequencesEqual(t, expectedSequences[sequenceFile.metadata.Hash], sequenceFile.data)
+		}
+	})
+}
+
+// TestCorpusOptimizationCallSequences ensures the best call sequence of each optimization test is stored in its own
+// file, replaced when a better one is written, and read back separately from other corpus call sequences.
+func TestCorpusOptimizationCallSequences(t *testing.T) {
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		corpus, err := NewCorpus("corpus")
+		assert.NoError(t, err)
+
+		// Write two call sequences for one test, then one for another.
+		bestSequence := getMockCallSequence(2)
+		err = corpus.WriteOptimizationCallSequence("OPTIMIZATION-TestContract-optimize-a()", getMockCallSequence(4), nil)
+		assert.NoError(t, err)
+		err = corpus.WriteOptimizationCallSequence("OPTIMIZATION-TestContract-optimize-a()", bestSequence, nil)
+		assert.NoError(t, err)
+		err = corpus.WriteOptimizationCallSequence("OPTIMIZATION-TestContract-optimize-min-b()", getMockCallSequence(3), nil)
+		assert.NoError(t, err)
+
+		// Only the latest call sequence of each test should be stored.
+		entries, err := os.ReadDir(corpus.OptimizationCallSequencesDirectory())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, len(entries))
+
+		// Read the corpus back, ensuring the call sequences are not treated as coverage call sequences.
+		corpus, err = NewCorpus(corpus.storageDirectory)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, corpus.CallSequenceCount())
+		assert.EqualValues(t, 2, len(corpus.optimizationCallSequences))
+		for _, sequenceFile := range corpus.optimizationCallSequences {
+			if filepath.Base(sequenceFile.filePath) == "OPTIMIZATION-TestContract-optimize-a().json" {
+				testCorpusCallSequencesEqual(t, bestSequence, sequenceFile.data)
+			}
+		}
+	})
+}
+
+// TestCorpusStorageGrowthCallSequences ensures the call sequences which grew storage the most are stored by rank,
+// replacing those stored previously, and are not read back as corpus call sequences.
+func TestCorpusStorageGrowthCallSequences(t *testing.T) {
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		corpus, err := NewCorpus("corpus")
+		assert.NoError(t, err)
+
+		// Write three call sequences, then two others.
+		err = corpus.WriteStorageGrowthCallSequences([]calls.CallSequence{getMockCallSequence(1), getMockCallSequence(2), getMockCallSequence(3)})
+		assert.NoError(t, err)
+		bestSequence := getMockCallSequence(4)
+		err = corpus.WriteStorageGrowthCallSequences([]calls.CallSequence{bestSequence, getMockCallSequence(5)})
+		assert.NoError(t, err)
+
+		// Only the latest call sequences should be stored, by rank.
+		entries, err := os.ReadDir(corpus.StorageGrowthCallSequencesDirectory())
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, len(entries))
+		b, err := readCorpusFile(filepath.Join(corpus.StorageGrowthCallSequencesDirectory(), "1.json"))
+		assert.NoError(t, err)
+		seq, _, err := unmarshalCallSequenceFile(b)
+		assert.NoError(t, err)
+		testCorpusCallSequencesEqual(t, bestSequence, seq)
+
+		// Read the corpus back, ensuring the call sequences are not treated as coverage call sequences.
+		corpus, err = NewCorpus(corpus.storageDirectory)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, corpus.CallSequenceCount())
+	})
+}
+
+// BenchmarkCorpusLoad measures the time taken to read a corpus of 10,000 call sequences from disk, with and without
+// compression.
+func BenchmarkCorpusLoad(b *testing.B) {
+	for _, compressionEnabled := range []bool{false, true} {
+		name := "uncompressed"
+		if compressionEnabled {
+			name = "compressed"
+		}
+		b.Run(name, func(b *testing.B) {
+			// Write our corpus to disk.
+			corpus, err := NewCorpus(filepath.Join(b.TempDir(), "corpus"))
+			assert.NoError(b, err)
+			corpus.SetCompressionEnabled(compressionEnabled)
+			for i := 0; i < 10000; i++ {
+				err = corpus.AddCallSequence(getMockCallSequence(5), nil, nil, false)
+				assert.NoError(b, err)
+			}
+			err = corpus.Flush()
+			assert.NoError(b, err)
+
+			// Measure reading it back.
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err = NewCorpus(corpus.StorageDirectory())
+				assert.NoError(b, err)
+			}
+		})
+	}
+}
+
+// TestCorpusVerifyCallSequences ensures call sequences which cannot be replayed on a chain are reported as stale, and
+// are deleted from disk when removed.
+func TestCorpusVerifyCallSequences(t *testing.T) {
+	// Create a mock corpus, whose call sequences target contracts which do not exist.
+	corpus, err := getMockSimpleCorpus(5, 10, 1, 3)
+	assert.NoError(t, err)
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		// Write to disk, then read the corpus back so its call sequences have file paths.
+		err := corpus.Flush()
+		assert.NoError(t, err)
+		corpus, err = NewCorpus(corpus.storageDirectory)
+		assert.NoError(t, err)
+
+		// Verify every call sequence on an empty chain, expecting each to be stale.
+		testChainConfig, err := chainConfig.DefaultTestChainConfig()
+		assert.NoError(t, err)
+		testChain, err := chain.NewTestChain(core.GenesisAlloc{}, testChainConfig)
+		assert.NoError(t, err)
+		verifications, err := corpus.VerifyCallSequences(testChain, nil, nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, corpus.CallSequenceCount(), len(verifications))
+		staleFilePaths := make([]string, 0)
+		for _, verification := range verifications {
+			assert.EqualValues(t, CallSequenceVerificationStatusStale, verification.Status)
+			assert.NotEmpty(t, verification.Error)
+			staleFilePaths = append(staleFilePaths, verification.FilePath)
+		}
+
+		// Remove our stale call sequences and ensure their files were deleted.
+		err = corpus.RemoveCallSequences(staleFilePaths)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, corpus.CallSequenceCount())
+		matches, err := filepath.Glob(filepath.Join(corpus.CallSequencesDirectory(), "*.json"))
+		assert.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+}
+
+// TestCorpusCoverageMapsPersistence ensures coverage maps persisted alongside the corpus are loaded in place of
+// replaying it when the compiled contracts are unchanged, that the call sequences they cover are queued for execution
+// pending resolution, and that the corpus is otherwise replayed in full.
+func TestCorpusCoverageMapsPersistence(t *testing.T) {
+	// Create a mock corpus, whose call sequences target contracts which do not exist.
+	corpus, err := getMockSimpleCorpus(5, 10, 1, 3)
+	assert.NoError(t, err)
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		// Write to disk, then read the corpus back so its call sequences have file paths.
+		err := corpus.Flush()
+		assert.NoError(t, err)
+		storageDirectory := corpus.storageDirectory
+		sequenceCount := corpus.CallSequenceCount()
+
+		// Create an empty chain and compiled contracts to initialize the corpus with.
+		testChainConfig, err := chainConfig.DefaultTestChainConfig()
+		assert.NoError(t, err)
+		testChain, err := chain.NewTestChain(core.GenesisAlloc{}, testChainConfig)
+		assert.NoError(t, err)
+		contractDefinitions := contracts.Contracts{
+			contracts.NewContract("Target", "", &compilationTypes.CompiledContract{RuntimeBytecode: []byte{0x00}}, nil),
+		}
+		initializeCorpus := func(contractDefinitions contracts.Contracts, forceFullReplay bool) *Corpus {
+			corpus, err := NewCorpus(storageDirectory)
+			assert.NoError(t, err)
+			corpus.SetFullReplayForced(forceFullReplay)
+			err = corpus.Initialize(testChain, contractDefinitions)
+			assert.NoError(t, err)
+			return corpus
+		}
+
+		// Without persisted coverage maps, every call sequence is replayed, and found to be stale.
+		corpus = initializeCorpus(contractDefinitions, false)
+		assert.EqualValues(t, 0, corpus.ActiveCallSequenceCount())
+
+		// Record some coverage, then persist it.
+		_, err = corpus.CoverageMaps().SetCoveredAt(common.HexToAddress("0x1234"), common.HexToHash("0x5678"), false, 10, 3)
+		assert.NoError(t, err)
+		err = corpus.WriteCoverageMaps()
+		assert.NoError(t, err)
+		persistedCoverageMaps := corpus.CoverageMaps()
+
+		// With the same contracts, the coverage maps are loaded and no call sequence is replayed. Instead, they are
+		// queued for execution pending resolution, and are not active until they are resolved.
+		corpus = initializeCorpus(contractDefinitions, false)
+		assert.True(t, corpus.CoverageMaps().Equals(persistedCoverageMaps))
+		assert.True(t, persistedCoverageMaps.Equals(corpus.CoverageMaps()))
+		assert.EqualValues(t, 0, corpus.ActiveCallSequenceCount())
+		pendingSequences := make([]*calls.CallSequence, 0)
+		for {
+			sequence, pendingResolution := corpus.UnexecutedCallSequence()
+			if sequence == nil {
+				break
+			}
+			assert.True(t, pendingResolution)
+			pendingSequences = append(pendingSequences, sequence)
+		}
+		assert.Len(t, pendingSequences, sequenceCount)
+
+		// Call sequences which could not be executed in full are queued again, those which could not be resolved
+		// are disabled, and those which were resolved become active.
+		corpus.ResolvePendingCallSequence(pendingSequences[0], false, nil)
+		corpus.ResolvePendingCallSequence(pendingSequences[1], false, errors.New("contract could not be resolved"))
+		corpus.ResolvePendingCallSequence(pendingSequences[2], true, nil)
+		assert.EqualValues(t, 1, corpus.ActiveCallSequenceCount())
+		sequence, pendingResolution := corpus.UnexecutedCallSequence()
+		assert.True(t, pendingResolution)
+		assert.Same(t, pendingSequences[0], sequence)
+		sequence, _ = corpus.UnexecutedCallSequence()
+		assert.Nil(t, sequence)
+
+		// Forcing a full replay, or changing the contracts, replays every call sequence.
+		corpus = initializeCorpus(contractDefinitions, true)
+		assert.EqualValues(t, 0, corpus.ActiveCallSequenceCount())
+		assert.Empty(t, corpus.CoverageMaps().CoveredLocations())
+		changedContractDefinitions := contracts.Contracts{
+			contracts.NewContract("Target", "", &compilationTypes.CompiledContract{RuntimeBytecode: []byte{0x01}}, nil),
+		}
+		corpus = initializeCorpus(changedContractDefinitions, false)
+		assert.EqualValues(t, 0, corpus.ActiveCallSequenceCount())
+		assert.Empty(t, corpus.CoverageMaps().CoveredLocations())
+	})
+}
+
+// TestCorpusHitCountCoverage ensures that when hit counts are recorded, calls which execute a loop a number of times
+// in a new hit count bucket are added to the corpus, while they are not when only locations hit are recorded.
+func TestCorpusHitCountCoverage(t *testing.T) {
+	// Define a contract which loops as many times as the first byte of its call data.
+	loopBytecode := []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0xF8, byte(vm.SHR), // 0: n = calldata[0]
+		byte(vm.JUMPDEST),                                                    // 6: loop start
+		byte(vm.DUP1), byte(vm.ISZERO), byte(vm.PUSH1), 0x13, byte(vm.JUMPI), // 7: exit the loop if n == 0
+		byte(vm.PUSH1), 0x01, byte(vm.SWAP1), byte(vm.SUB), // 12: n = n - 1
+		byte(vm.PUSH1), 0x06, byte(vm.JUMP), // 16: jump to loop start
+		byte(vm.JUMPDEST), byte(vm.STOP), // 19: loop end
+	}
+	sender := common.HexToAddress("0x10000")
+	contractAddress := common.HexToAddress("0x20000")
+
+	// countCorpusGrowth calls the contract with each of the provided loop counts, returning the count of calls which
+	// were added to the corpus.
+	countCorpusGrowth := func(hitCountsEnabled bool, loopCounts []byte) int {
+		// Create our chain with the contract deployed, recording coverage.
+		testChainConfig, err := chainConfig.DefaultTestChainConfig()
+		assert.NoError(t, err)
+		testChain, err := chain.NewTestChain(core.GenesisAlloc{
+			sender:          {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+			contractAddress: {Code: loopBytecode, Balance: big.NewInt(0)},
+		}, testChainConfig)
+		assert.NoError(t, err)
+		coverageTracer := coverage.NewCoverageTracer()
+		coverageTracer.SetHitCountsEnabled(hitCountsEnabled)
+		testChain.AddTracer(coverageTracer, true, false)
+
+		// Execute each call, adding it to the corpus if it achieved new coverage.
+		corpus, err := NewCorpus("")
+		assert.NoError(t, err)
+		for _, loopCount := range loopCounts {
+			call := calls.NewCallMessage(sender, &contractAddress, 0, big.NewInt(0), 0, nil, nil, nil, []byte{loopCount})
+			call.FillFromTestChainProperties(testChain)
+			sequence := calls.CallSequence{calls.NewCallSequenceElement(nil, call, 1, 1)}
+			_, err = calls.ExecuteCallSequence(testChain, sequence)
+			assert.NoError(t, err)
+			_, err = corpus.AddCallSequenceIfCoverageChanged(sequence, big.NewInt(1), nil, false)
+			assert.NoError(t, err)
+		}
+		return corpus.CallSequenceCount()
+	}
+
+	// Without hit counts, only the first call reaches new coverage. With them, each call which executes the loop a
+	// number of times in a new hit count bucket does as well.
+	loopCounts := []byte{1, 1, 2, 3, 4, 5, 8, 100}
+	assert.EqualValues(t, 1, countCorpusGrowth(false, loopCounts))
+	assert.EqualValues(t, 6, countCorpusGrowth(true, loopCounts))
+}
+
+// TestCorpusStateDeduplication ensures that when state deduplication is enabled, a call sequence adding little
+// coverage which reaches the same state as a call sequence in the corpus replaces it if it is shorter, deleting its
+// file once the replacement was written, and is discarded otherwise.
+func TestCorpusStateDeduplication(t *testing.T) {
+	// Define a contract which stores the first byte of its call data, then jumps to the destination in its second.
+	bytecode := []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0xF8, byte(vm.SHR), // 0: v = calldata[0]
+		byte(vm.PUSH1), 0x00, byte(vm.SSTORE), // 6: storage[0] = v
+		byte(vm.PUSH1), 0x01, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0xF8, byte(vm.SHR), byte(vm.JUMP), // 9: jump calldata[1]
+		byte(vm.JUMPDEST), byte(vm.STOP), // 16
+		byte(vm.JUMPDEST), byte(vm.STOP), // 18
+		byte(vm.JUMPDEST), byte(vm.STOP), // 20
+		byte(vm.JUMPDEST), byte(vm.STOP), // 22
+	}
+	sender := common.HexToAddress("0x10000")
+	contractAddress := common.HexToAddress("0x20000")
+
+	// Create our chain with the contract deployed, recording coverage.
+	testChainConfig, err := chainConfig.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChain, err := chain.NewTestChain(core.GenesisAlloc{
+		sender:          {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		contractAddress: {Code: bytecode, Balance: big.NewInt(0)},
+	}, testChainConfig)
+	assert.NoError(t, err)
+	testChain.AddTracer(coverage.NewCoverageTracer(), true, false)
+	stateHashFunc := func() (common.Hash, error) {
+		return testChain.StateHash([]common.Address{contractAddress})
+	}
+
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		corpus, err := NewCorpus("corpus")
+		assert.NoError(t, err)
+		corpus.SetStateDeduplication(true, 4)
+
+		// addCallSequence executes a call sequence with calls of the provided call data from the genesis state,
+		// adding it to the corpus if its last call achieved new coverage, then returns the corpus call sequences.
+		addCallSequence := func(callData ...[]byte) calls.CallSequence {
+			sequence := make(calls.CallSequence, 0, len(callData))
+			for _, data := range callData {
+				call := calls.NewCallMessage(sender, &contractAddress, 0, big.NewInt(0), 0, nil, nil, nil, data)
+				call.FillFromTestChainProperties(testChain)
+				sequence = append(sequence, calls.NewCallSequenceElement(nil, call, 1, 1))
+			}
+			_, err := calls.ExecuteCallSequence(testChain, sequence)
+			assert.NoError(t, err)
+			coverageIncreased, err := corpus.AddCallSequenceIfCoverageChangedWithStateHash(sequence, big.NewInt(1), nil, true, stateHashFunc)
+			assert.NoError(t, err)
+			assert.True(t, coverageIncreased)
+			assert.NoError(t, testChain.RevertToBlockNumber(0))
+
+			corpusSequences := make(calls.CallSequence, 0)
+			for _, sequenceFile := range corpus.callSequences {
+				corpusSequences = append(corpusSequences, sequenceFile.data...)
+			}
+			return corpusSequences
+		}
+
+		// The first call sequence reaches a lot of new coverage, so it is added.
+		first := addCallSequence([]byte{1, 16})
+		assert.Len(t, first, 1)
+		assert.NotNil(t, corpus.callSequences[0].metadata.StateHash)
+
+		// A longer call sequence reaching the same state with little new coverage is discarded.
+		assert.Len(t, addCallSequence([]byte{2, 16}, []byte{1, 18}), 1)
+		assert.EqualValues(t, 1, corpus.StateDuplicateCallSequenceCount())
+
+		// A call sequence reaching a new state is added, even with little new coverage.
+		assert.Len(t, addCallSequence([]byte{5, 16}, []byte{3, 20}), 3)
+		matches, err := corpus.callSequenceFilePaths()
+		assert.NoError(t, err)
+		assert.Len(t, matches, 2)
+
+		// A shorter call sequence reaching the same state with little new coverage replaces it, and its file.
+		corpusSequences := addCallSequence([]byte{3, 22})
+		assert.Len(t, corpusSequences, 2)
+		assert.EqualValues(t, []byte{3, 22}, corpusSequences[1].Call.MsgData)
+		assert.EqualValues(t, 2, corpus.StateDuplicateCallSequenceCount())
+		matches, err = corpus.callSequenceFilePaths()
+		assert.NoError(t, err)
+		assert.Len(t, matches, 2)
+
+		// The corpus directory contains the call sequences which remain.
+		corpus, err = NewCorpus("corpus")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, corpus.CallSequenceCount())
+		for _, sequenceFile := range corpus.callSequences {
+			assert.Len(t, sequenceFile.data, 1)
+		}
+	})
+}
+
+// TestCorpusPowerScheduleWeights ensures the power schedule weights call sequences by the rarity of the coverage they
+// reached, and that weights remain accurate as call sequences are added and removed, although only the call sequences
+// sharing coverage locations with them are updated.
+func TestCorpusPowerScheduleWeights(t *testing.T) {
+	corpus, err := NewCorpus("")
+	assert.NoError(t, err)
+	corpus.SetPowerScheduleEnabled(true)
+	corpus.weightedCallSequenceChooser = randomutils.NewWeightedRandomChooser[*corpusFile[calls.CallSequence]]()
+	corpus.mutationHistories = make(map[*corpusFile[calls.CallSequence]]*CallSequenceMutationHistory)
+	corpus.powerScheduleEntries = make(map[*randomutils.WeightedRandomChoice[*corpusFile[calls.CallSequence]]]*powerScheduleEntry)
+	corpus.coverageLocationHitCounts = make(map[coverage.CoverageLocation]uint64)
+	corpus.coverageLocationEntries = make(map[coverage.CoverageLocation]map[*powerScheduleEntry]struct{})
+
+	// Define our coverage locations and a function to obtain the expected weight from the hit counts of each.
+	locations := make([]coverage.CoverageLocation, 4)
+	for i := range locations {
+		locations[i] = coverage.CoverageLocation{PC: i}
+	}
+	expectedWeight := func(hitCounts ...int) *big.Int {
+		rarityScore := 0.0
+		for _, hitCount := range hitCounts {
+			rarityScore += 1.0 / float64(hitCount)
+		}
+		return big.NewInt(int64(rarityScore*powerScheduleWeightScale) + 1)
+	}
+
+	// Add call sequences which share some of their coverage locations, and ensure the weights of each are updated.
+	first := corpus.addCallSequenceChoice(&corpusFile[calls.CallSequence]{data: getMockCallSequence(1)}, big.NewInt(1), []coverage.CoverageLocation{locations[0], locations[1], locations[0]})
+	assert.EqualValues(t, expectedWeight(1, 1), first.Weight())
+	second := corpus.addCallSequenceChoice(&corpusFile[calls.CallSequence]{data: getMockCallSequence(1)}, big.NewInt(1), []coverage.CoverageLocation{locations[1], locations[2]})
+	third := corpus.addCallSequenceChoice(&corpusFile[calls.CallSequence]{data: getMockCallSequence(1)}, big.NewInt(1), []coverage.CoverageLocation{locations[3]})
+	assert.EqualValues(t, expectedWeight(1, 2), first.Weight())
+	assert.EqualValues(t, expectedWeight(2, 1), second.Weight())
+	assert.EqualValues(t, expectedWeight(1), third.Weight())
+
+	assert.Len(t, corpus.mutationHistories, 3)
+
+	// Removing a call sequence should update the weights of the call sequences which shared its locations, and
+	// disabling it should discard its mutation history.
+	corpus.setCallSequenceChoiceWeight(second, big.NewInt(0))
+	corpus.removePowerScheduleEntry(second)
+	assert.NotContains(t, corpus.mutationHistories, second.Data)
+	assert.Len(t, corpus.mutationHistories, 2)
+	assert.EqualValues(t, expectedWeight(1, 1), first.Weight())
+	assert.EqualValues(t, expectedWeight(1), third.Weight())
+	assert.NotContains(t, corpus.coverageLocationHitCounts, locations[2])
+	assert.Len(t, corpus.powerScheduleEntries, 2)
+}
+
+// TestCorpusParallelReplay ensures a corpus replayed across several workers when it is initialized measures the same
+// coverage, and queues the same call sequences for execution in the same order, as one replayed by a single worker.
+func TestCorpusParallelReplay(t *testing.T) {
+	// Define a contract which loops as many times as the first byte of its call data, and init bytecode which deploys
+	// it.
+	runtimeBytecode := []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 0xF8, byte(vm.SHR), // 0: n = calldata[0]
+		byte(vm.JUMPDEST),                                                    // 6: loop start
+		byte(vm.DUP1), byte(vm.ISZERO), byte(vm.PUSH1), 0x13, byte(vm.JUMPI), // 7: exit the loop if n == 0
+		byte(vm.PUSH1), 0x01, byte(vm.SWAP1), byte(vm.SUB), // 12: n = n - 1
+		byte(vm.PUSH1), 0x06, byte(vm.JUMP), // 16: jump to loop start
+		byte(vm.JUMPDEST), byte(vm.STOP), // 19: loop end
+	}
+	initBytecode := append([]byte{
+		byte(vm.PUSH1), byte(len(runtimeBytecode)), byte(vm.PUSH1), 0x0C, byte(vm.PUSH1), 0x00, byte(vm.CODECOPY),
+		byte(vm.PUSH1), byte(len(runtimeBytecode)), byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+	}, runtimeBytecode...)
+	contractDefinitions := contracts.Contracts{
+		contracts.NewContract("Target", "", &compilationTypes.CompiledContract{InitBytecode: initBytecode, RuntimeBytecode: runtimeBytecode}, nil),
+	}
+
+	// Create our chain with the contract deployed.
+	sender := common.HexToAddress("0x10000")
+	testChainConfig, err := chainConfig.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChain, err := chain.NewTestChain(core.GenesisAlloc{
+		sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+	}, testChainConfig)
+	assert.NoError(t, err)
+	deployment := calls.NewCallMessage(sender, nil, 0, big.NewInt(0), 0, nil, nil, nil, initBytecode)
+	deployment.FillFromTestChainProperties(testChain)
+	executedDeployment, err := calls.ExecuteCallSequence(testChain, calls.CallSequence{calls.NewCallSequenceElement(nil, deployment, 1, 1)})
+	assert.NoError(t, err)
+	contractAddress := executedDeployment[0].ChainReference.MessageResults().Receipt.ContractAddress
+
+	// initializeCorpus initializes a corpus with call sequences which loop a varying number of times, along with
+	// some which target a contract which does not exist, replaying them with the provided count of workers.
+	initializeCorpus := func(replayWorkers int) *Corpus {
+		corpus, err := NewCorpus("")
+		assert.NoError(t, err)
+		corpus.SetReplayWorkers(replayWorkers)
+		corpus.SetCoverageFeedback(coverage.CoverageFeedbackBoth)
+		corpus.SetHitCountsEnabled(true)
+		for i := 0; i < 40; i++ {
+			target := contractAddress
+			if i%7 == 0 {
+				target = common.HexToAddress("0xDEAD")
+			}
+			sequence := make(calls.CallSequence, 0)
+			for j := 0; j <= i%3; j++ {
+				call := calls.NewCallMessage(sender, &target, 0, big.NewInt(0), 0, nil, nil, nil, []byte{byte(i*3 + j)})
+				call.FillFromTestChainProperties(testChain)
+				sequence = append(sequence, calls.NewCallSequenceElement(nil, call, 1, 1))
+			}
+			err = corpus.AddCallSequence(sequence, nil, nil, false)
+			assert.NoError(t, err)
+		}
+		err = corpus.Initialize(testChain, contractDefinitions)
+		assert.NoError(t, err)
+		return corpus
+	}
+
+	// Both corpora should have measured the same coverage, disabled the same stale call sequences, and queued the rest
+	// for execution in the order they were added.
+	serialCorpus, parallelCorpus := initializeCorpus(1), initializeCorpus(8)
+	assert.NotEmpty(t, serialCorpus.CoverageMaps().CoveredLocations())
+	assert.True(t, serialCorpus.CoverageMaps().Equals(parallelCorpus.CoverageMaps()))
+	assert.True(t, parallelCorpus.CoverageMaps().Equals(serialCorpus.CoverageMaps()))
+	assert.EqualValues(t, 34, serialCorpus.ActiveCallSequenceCount())
+	assert.EqualValues(t, serialCorpus.ActiveCallSequenceCount(), parallelCorpus.ActiveCallSequenceCount())
+	for {
+		serialSequence, _ := serialCorpus.UnexecutedCallSequence()
+		parallelSequence, _ := parallelCorpus.UnexecutedCallSequence()
+		if serialSequence == nil || parallelSequence == nil {
+			assert.Nil(t, serialSequence)
+			assert.Nil(t, parallelSequence)
+			break
+		}
+		serialHash, err := serialSequence.CanonicalHash()
+		assert.NoError(t, err)
+		parallelHash, err := parallelSequence.CanonicalHash()
+		assert.NoError(t, err)
+		assert.EqualValues(t, serialHash, parallelHash)
+	}
+}
+
+// TestCorpusDetachCallSequences ensures a corpus which was initialized can be initialized again for recompiled
+// contracts once its call sequences are detached, disabling those which call methods which no longer exist.
+func TestCorpusDetachCallSequences(t *testing.T) {
+	// Define a contract which stops immediately, and init bytecode which deploys it.
+	runtimeBytecode := []byte{byte(vm.STOP)}
+	initBytecode := append([]byte{
+		byte(vm.PUSH1), byte(len(runtimeBytecode)), byte(vm.PUSH1), 0x0C, byte(vm.PUSH1), 0x00, byte(vm.CODECOPY),
+		byte(vm.PUSH1), byte(len(runtimeBytecode)), byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+	}, runtimeBytecode...)
+	contractAbi, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"set","inputs":[{"name":"x","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"}]`))
+	assert.NoError(t, err)
+	contractDefinitions := contracts.Contracts{
+		contracts.NewContract("Target", "", &compilationTypes.CompiledContract{Abi: contractAbi, InitBytecode: initBytecode, RuntimeBytecode: runtimeBytecode}, nil),
+	}
+
+	// Create our chain with the contract deployed.
+	sender := common.HexToAddress("0x10000")
+	testChainConfig, err := chainConfig.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChain, err := chain.NewTestChain(core.GenesisAlloc{
+		sender: {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+	}, testChainConfig)
+	assert.NoError(t, err)
+	deployment := calls.NewCallMessage(sender, nil, 0, big.NewInt(0), 0, nil, nil, nil, initBytecode)
+	deployment.FillFromTestChainProperties(testChain)
+	executedDeployment, err := calls.ExecuteCallSequence(testChain, calls.CallSequence{calls.NewCallSequenceElement(nil, deployment, 1, 1)})
+	assert.NoError(t, err)
+	contractAddress := executedDeployment[0].ChainReference.MessageResults().Receipt.ContractAddress
+
+	// Create a corpus with call sequences which call our method, as generated during a campaign.
+	corpus, err := NewCorpus("")
+	assert.NoError(t, err)
+	method := contractAbi.Methods["set"]
+	for i := 0; i < 5; i++ {
+		abiValues := &calls.CallMessageDataAbiValues{Method: &method, InputValues: []any{big.NewInt(int64(i))}}
+		call := calls.NewCallMessageWithAbiValueData(sender, &contractAddress, 0, big.NewInt(0), 0, nil, nil, nil, abiValues)
+		call.FillFromTestChainProperties(testChain)
+		err = corpus.AddCallSequence(calls.CallSequence{calls.NewCallSequenceElement(contractDefinitions[0], call, 1, 1)}, nil, nil, false)
+		assert.NoError(t, err)
+	}
+
+	// Once detached, the corpus should initialize for the same contracts, keeping every call sequence, any number of
+	// times.
+	for i := 0; i < 2; i++ {
+		err = corpus.DetachCallSequences()
+		assert.NoError(t, err)
+		err = corpus.Initialize(testChain, contractDefinitions)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 5, corpus.ActiveCallSequenceCount())
+	}
+
+	// If the method is removed from the contract, every call sequence should be disabled as stale without an error.
+	recompiledDefinitions := contracts.Contracts{
+		contracts.NewContract("Target", "", &compilationTypes.CompiledContract{InitBytecode: initBytecode, RuntimeBytecode: runtimeBytecode}, nil),
+	}
+	err = corpus.DetachCallSequences()
+	assert.NoError(t, err)
+	err = corpus.Initialize(testChain, recompiledDefinitions)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, corpus.ActiveCallSequenceCount())
+	assert.EqualValues(t, 5, corpus.CallSequenceCount())
+}
+
+// TestCorpusMinimize ensures call sequences which reach no coverage are moved to the pruned directory, rather than
+// deleted, when the corpus is minimized.
+func TestCorpusMinimize(t *testing.T) {
+	// Create a mock corpus, whose call sequences target contracts which do not exist.
+	corpus, err := getMockSimpleCorpus(5, 10, 1, 3)
+	assert.NoError(t, err)
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		// Write to disk, then read the corpus back so its call sequences have file paths.
+		err := corpus.Flush()
+		assert.NoError(t, err)
+		corpus, err = NewCorpus(corpus.storageDirectory)
+		assert.NoError(t, err)
+		originalCount := corpus.CallSequenceCount()
+
+		// Minimize the corpus on an empty chain, where no call sequence reaches any coverage.
+		testChainConfig, err := chainConfig.DefaultTestChainConfig()
+		assert.NoError(t, err)
+		testChain, err := chain.NewTestChain(core.GenesisAlloc{}, testChainConfig)
+		assert.NoError(t, err)
+		minimization, err := corpus.Minimize(testChain, nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, originalCount, minimization.OriginalCallSequenceCount)
+		assert.EqualValues(t, 0, minimization.MinimizedCallSequenceCount)
+		assert.EqualValues(t, 0, minimization.MinimizedCallCount)
+		assert.EqualValues(t, 0, corpus.CallSequenceCount())
+
+		// Ensure every call sequence file was moved to the pruned directory.
+		matches, err := filepath.Glob(filepath.Join(corpus.CallSequencesDirectory(), "*.json"))
+		assert.NoError(t, err)
+		assert.Empty(t, matches)
+		matches, err = filepath.Glob(filepath.Join(corpus.PrunedDirectory(), "*.json"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, originalCount, len(matches))
+	})
+}
+
+// TestCorpusConvertEchidnaCallSequence ensures Echidna transactions are converted into call sequences targeting
+// deployed contracts, carrying over delays and skipping calls which cannot be mapped, and that senders are mapped in
+// turn.
+func TestCorpusConvertEchidnaCallSequence(t *testing.T) {
+	// Create a deployed contract to map Echidna calls onto.
+	contractAbi, err := abi.JSON(strings.NewReader(`[{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[{"name":"x","type":"uint256"},{"name":"data","type":"bytes"},{"name":"flags","type":"bool[]"}],"outputs":[]}]`))
+	assert.NoError(t, err)
+	contract := contracts.NewContract("Target", "", &compilationTypes.CompiledContract{Abi: contractAbi}, nil)
+	sender := common.HexToAddress("0x10000")
+	otherSender := common.HexToAddress("0x20000")
+	target := common.HexToAddress("0x1234")
+	importer := &echidnaCallSequenceImporter{
+		deployedContracts: []*echidnaImportTarget{{Address: target, Contract: contract}},
+		senders:           []common.Address{sender, otherSender},
+		senderMapping:     make(map[common.Address]common.Address),
+		targetMapping:     make(map[common.Address]common.Address),
+		blockGasLimit:     1000000,
+	}
+
+	// Convert a sequence with a delay-only transaction, a call which cannot be mapped, and a call which can.
+	var transactions []echidnaTransaction
+	err = json.Unmarshal([]byte(`[
+		{"call":{"tag":"NoCall"},"src":"0x0000000000000000000000000000000000010000","dst":"0x0000000000000000000000000000000000000000","gas":0,"gasprice":"0x0","value":"0x0","delay":["0x5","0x1"]},
+		{"call":{"tag":"SolCall","contents":["missing",[]]},"src":"0x0000000000000000000000000000000000030000","dst":"0x00a329c0648769a73afac7f9381e08fb43dbea72","gas":12500000,"gasprice":"0x0","value":"0x0","delay":["0x2","0x1"]},
+		{"call":{"tag":"SolCall","contents":["set",[{"tag":"AbiUInt","contents":[256,"42"]},{"tag":"AbiBytesDynamic","contents":"0x0102"},{"tag":"AbiArrayDynamic","contents":[{"tag":"AbiBoolType"},[{"tag":"AbiBool","contents":true}]]}]]},"src":"0x0000000000000000000000000000000000030000","dst":"0x00a329c0648769a73afac7f9381e08fb43dbea72","gas":12500000,"gasprice":"0x0","value":"0x7","delay":["0x3","0x1"]}
+	]`), &transactions)
+	assert.NoError(t, err)
+	sequence, warnings := importer.convertCallSequence(transactions)
+	assert.EqualValues(t, 1, len(warnings))
+	assert.EqualValues(t, 1, len(sequence))
+
+	// Verify the call was mapped onto our sender and target, with its arguments, value and delays.
+	call := sequence[0].Call
+	assert.EqualValues(t, sender, call.MsgFrom)
+	assert.EqualValues(t, target, *call.MsgTo)
+	assert.EqualValues(t, big.NewInt(7), call.MsgValue)
+	assert.EqualValues(t, 1000000, call.MsgGas)
+	assert.EqualValues(t, "set", call.MsgDataAbiValues.Method.Name)
+	assert.EqualValues(t, []any{big.NewInt(42), []byte{1, 2}, []bool{true}}, call.MsgDataAbiValues.InputValues)
+	assert.EqualValues(t, 10, sequence[0].BlockTimestampDelay)
+	assert.EqualValues(t, 3, sequence[0].BlockNumberDelay)
+
+	// Senders should be assigned our senders in turn, regardless of how many targets were mapped.
+	assert.EqualValues(t, otherSender, importer.mapSender(common.HexToAddress("0x40000")))
+	assert.EqualValues(t, sender, importer.mapSender(common.HexToAddress("0x50000")))
+	assert.EqualValues(t, otherSender, importer.mapSender(common.HexToAddress("0x40000")))
+}
+
+// TestCorpusValueSetReadWrite writes a value set to the corpus directory and ensures it is read back with all of its
+// values categorized by type, that only the most recent values of each type are kept, and that unreadable or newer
+// corpus artifacts are handled.
+func TestCorpusValueSetReadWrite(t *testing.T) {
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		// Create a value set with values of every type, including a string which is not valid UTF-8.
+		valueSet := valuegeneration.NewValueSet()
+		valueSet.AddAddress(common.HexToAddress("0x1234"))
+		valueSet.AddInteger(big.NewInt(-77))
+		valueSet.AddInteger(new(big.Int).Lsh(big.NewInt(1), 255))
+		valueSet.AddString("medusa")
+		valueSet.AddString("\xff\xfe")
+		valueSet.AddBytes([]byte{0xde, 0xad, 0xbe, 0xef})
+
+		// Write it to disk.
+		corpus, err := NewCorpus("corpus")
+		assert.NoError(t, err)
+		err = corpus.FlushValueSet(valueSet, 100)
+		assert.NoError(t, err)
+
+		// Read it back into a value set with existing values, and ensure the values were merged.
+		corpus, err = NewCorpus("corpus")
+		assert.NoError(t, err)
+		loadedValueSet := valuegeneration.NewValueSet()
+		loadedValueSet.AddInteger(big.NewInt(5))
+		err = corpus.LoadValueSet(loadedValueSet, 100)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, valueSet.Addresses(), loadedValueSet.Addresses())
+		assert.ElementsMatch(t, valueSet.Strings(), loadedValueSet.Strings())
+		assert.ElementsMatch(t, valueSet.Bytes(), loadedValueSet.Bytes())
+		assert.Len(t, loadedValueSet.Integers(), 3)
+		assert.True(t, loadedValueSet.ContainsInteger(big.NewInt(-77)))
+		assert.True(t, loadedValueSet.ContainsInteger(big.NewInt(5)))
+
+		// Only the most recently added values of each type should be written, and the rest evicted on save.
+		for i := 0; i < 5; i++ {
+			valueSet.AddInteger(big.NewInt(int64(1000 + i)))
+		}
+		valueSet.AddInteger(big.NewInt(-77))
+		err = corpus.FlushValueSet(valueSet, 3)
+		assert.NoError(t, err)
+		assert.Len(t, valueSet.Integers(), 7)
+		loadedValueSet = valuegeneration.NewValueSet()
+		err = corpus.LoadValueSet(loadedValueSet, 100)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []*big.Int{big.NewInt(1003), big.NewInt(1004), big.NewInt(-77)}, loadedValueSet.Integers())
+		assert.Len(t, loadedValueSet.Addresses(), 1)
+		assert.Len(t, loadedValueSet.Strings(), 2)
+
+		// The stored order of values should be kept, so that loading enforces the same bound.
+		loadedValueSet = valuegeneration.NewValueSet()
+		err = corpus.LoadValueSet(loadedValueSet, 2)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []*big.Int{big.NewInt(1004), big.NewInt(-77)}, loadedValueSet.Integers())
+		assert.ElementsMatch(t, valueSet.Strings(), loadedValueSet.Strings())
+
+		// A value set in an unsupported format should return an error, but leave the value set unchanged.
+		err = os.WriteFile(corpus.ValueSetFilePath(), []byte(`{"version": 0, "integers": ["1"]}`), os.ModePerm)
+		assert.NoError(t, err)
+		loadedValueSet = valuegeneration.NewValueSet()
+		err = corpus.LoadValueSet(loadedValueSet, 100)
+		assert.Error(t, err)
+		assert.Len(t, loadedValueSet.Integers(), 0)
+
+		// A corpus directory written by a newer version should not be loaded.
+		err = os.WriteFile(corpus.VersionFilePath(), []byte(`{"version": 1000}`), os.ModePerm)
+		assert.NoError(t, err)
+		_, err = NewCorpus("corpus")
+		assert.Error(t, err)
+	})
+}
+
+// TestCorpusConstructorArgsReadWrite writes fuzzed constructor arguments to the corpus directory and ensures they are
+// read back, and that they are part of the hash persisted coverage maps are keyed by.
+func TestCorpusConstructorArgsReadWrite(t *testing.T) {
+	testutils.ExecuteInDirectory(t, t.TempDir(), func() {
+		// Nothing should be loaded before any constructor arguments were written.
+		corpus, err := NewCorpus("corpus")
+		assert.NoError(t, err)
+		constructorArgs, err := corpus.LoadConstructorArgs()
+		assert.NoError(t, err)
+		assert.Nil(t, constructorArgs)
+
+		// Write our constructor arguments and read them back.
+		writtenConstructorArgs := map[string]map[string]any{"Pool": {"_fee": "3000", "_token": "DeployedContract:Token"}}
+		err = corpus.FlushConstructorArgs(writtenConstructorArgs)
+		assert.NoError(t, err)
+		constructorArgs, err = corpus.LoadConstructorArgs()
+		assert.NoError(t, err)
+		assert.EqualValues(t, writtenConstructorArgs, constructorArgs)
+
+		// Contracts deployed with other constructor arguments should be hashed differently.
+		contractDefinitions := contracts.Contracts{contracts.NewContract("Pool", "", &compilationTypes.CompiledContract{}, nil)}
+		otherConstructorArgs := map[string]map[string]any{"Pool": {"_fee": "500", "_token": "DeployedContract:Token"}}
+		assert.EqualValues(t, ContractsBytecodeHash(contractDefinitions, constructorArgs), ContractsBytecodeHash(contractDefinitions, writtenConstructorArgs))
+		assert.NotEqualValues(t, ContractsBytecodeHash(contractDefinitions, constructorArgs), ContractsBytecodeHash(contractDefinitions, otherConstructorArgs))
+		assert.NotEqualValues(t, ContractsBytecodeHash(contractDefinitions, constructorArgs), ContractsBytecodeHash(contractDefinitions, nil))
+	})
+}
+
+// TestCallSequenceMutationHistory ensures that productive argument mutations increase an argument's score, and that
+// scores decay until they are discarded.
+func TestCallSequenceMutationHistory(t *testing.T) {
+	history := NewCallSequenceMutationHistory()
+
+	// Record productive mutations and ensure only the relevant arguments are scored.
+	history.RecordProductiveMutation(1, 2)
+	history.RecordProductiveMutation(1, 2)
+	history.RecordProductiveMutation(0, 0)
+	assert.EqualValues(t, 3, history.ProductiveMutationCount())
+	assert.EqualValues(t, 2.0, history.ArgumentScore(1, 2))
+	assert.EqualValues(t, 1.0, history.ArgumentScore(0, 0))
+	assert.EqualValues(t, 0.0, history.ArgumentScore(0, 2))
+
+	// Decay the history once and ensure scores were reduced, but kept their order.
+	history.Decay()
+	assert.Less(t, history.ArgumentScore(1, 2), 2.0)
+	assert.Less(t, history.ArgumentScore(0, 0), history.ArgumentScore(1, 2))
+	assert.Greater(t, history.ArgumentScore(0, 0), 0.0)
+
+	// Decay the history until all scores are discarded. The productive mutation count is unaffected.
+	for i := 0; i < 1000; i++ {
+		history.Decay()
+	}
+	assert.EqualValues(t, 0.0, history.ArgumentScore(1, 2))
+	assert.EqualValues(t, 0.0, history.ArgumentScore(0, 0))
+	assert.EqualValues(t, 3, history.ProductiveMutationCount())
+}
+
 // TestCorpusCallSequenceMarshaling ensures that a corpus entry that is round trip serialized retains its original
 // values.
 func TestCorpusCallSequenceMarshaling(t *testing.T) {
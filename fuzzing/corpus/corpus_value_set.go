@@ -0,0 +1,84 @@
+package corpus
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/crytic/medusa/fuzzing/valuegeneration"
+	"github.com/crytic/medusa/utils"
+)
+
+// ValueSetFilePath returns the file path where the value set learned across fuzzing campaigns should be stored. This
+// is a file within StorageDirectory. If StorageDirectory is empty, this is as well, indicating persistent storage is
+// not enabled.
+func (c *Corpus) ValueSetFilePath() string {
+	if c.storageDirectory == "" {
+		return ""
+	}
+	return filepath.Join(c.StorageDirectory(), "value_set.json")
+}
+
+// LoadValueSet reads the value set stored in the corpus directory, if one exists, and merges its values into the
+// provided value set. Only the maxValuesPerType most recently learned values of each type are merged.
+// Returns an error if a stored value set exists but could not be read or parsed. In this case, the provided value set
+// is left unchanged.
+func (c *Corpus) LoadValueSet(valueSet *valuegeneration.ValueSet, maxValuesPerType int) error {
+	// If persistent storage is disabled or no value set was stored yet, there is nothing to load.
+	filePath := c.ValueSetFilePath()
+	if filePath == "" {
+		return nil
+	}
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Parse the value set into a new set first, so a partially parsed file does not pollute the provided one.
+	storedValueSet := valuegeneration.NewValueSet()
+	err = json.Unmarshal(b, storedValueSet)
+	if err != nil {
+		return fmt.Errorf("could not parse value set stored at '%v': %v", filePath, err)
+	}
+	storedValueSet.EvictOldest(maxValuesPerType)
+	valueSet.Merge(storedValueSet)
+	return nil
+}
+
+// FlushValueSet writes the provided value set to the corpus directory, replacing any previously stored value set.
+// Only the maxValuesPerType most recently added values of each type are written, the rest are evicted.
+// Returns an error if one occurs.
+func (c *Corpus) FlushValueSet(valueSet *valuegeneration.ValueSet, maxValuesPerType int) error {
+	// If our corpus directory is empty, it indicates we do not want to write corpus artifacts to persistent storage.
+	if c.storageDirectory == "" {
+		return nil
+	}
+
+	// Ensure the corpus directory exists and is versioned.
+	err := utils.MakeDirectory(c.storageDirectory)
+	if err != nil {
+		return err
+	}
+	err = c.writeVersion()
+	if err != nil {
+		return err
+	}
+
+	// Evict the oldest values from a copy of the value set, so the provided one is left unchanged, then marshal it and
+	// write it to disk.
+	valueSet = valueSet.Clone()
+	valueSet.EvictOldest(maxValuesPerType)
+	jsonEncodedData, err := json.MarshalIndent(valueSet, "", " ")
+	if err != nil {
+		return err
+	}
+	err = writeCorpusFile(c.ValueSetFilePath(), jsonEncodedData, false)
+	if err != nil {
+		return fmt.Errorf("An error occurred while writing value set to disk: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package coverage
+
+// CoverageFeedback describes the coverage signal which determines whether a call sequence achieved new coverage.
+type CoverageFeedback string
+
+const (
+	// CoverageFeedbackPC indicates new coverage is achieved when an instruction (program counter) is executed for the
+	// first time.
+	CoverageFeedbackPC CoverageFeedback = "pc"
+
+	// CoverageFeedbackEdge indicates new coverage is achieved when a branch edge (a JUMPI taking or not taking its
+	// jump) is executed for the first time.
+	CoverageFeedbackEdge CoverageFeedback = "edge"
+
+	// CoverageFeedbackBoth indicates new coverage is achieved when either an instruction or a branch edge is executed
+	// for the first time.
+	CoverageFeedbackBoth CoverageFeedback = "both"
+)
+
+// IsValid indicates whether the CoverageFeedback is one of the known coverage signals.
+func (f CoverageFeedback) IsValid() bool {
+	return f == CoverageFeedbackPC || f == CoverageFeedbackEdge || f == CoverageFeedbackBoth
+}
+
+// UsesEdgeCoverage indicates whether branch edge coverage contributes to the coverage signal, and thus must be
+// recorded by a CoverageTracer.
+func (f CoverageFeedback) UsesEdgeCoverage() bool {
+	return f == CoverageFeedbackEdge || f == CoverageFeedbackBoth
+}
+
+// CoverageChanged determines whether new coverage was achieved under this coverage signal, given whether new program
+// counter and branch edge coverage were achieved (as returned by CoverageMaps.UpdateWithChanges). An empty or unknown
+// signal is treated as CoverageFeedbackPC.
+func (f CoverageFeedback) CoverageChanged(pcCoverageChanged bool, edgeCoverageChanged bool) bool {
+	switch f {
+	case CoverageFeedbackEdge:
+		return edgeCoverageChanged
+	case CoverageFeedbackBoth:
+		return pcCoverageChanged || edgeCoverageChanged
+	default:
+		return pcCoverageChanged
+	}
+}
+
+// CoverageDelta determines the amount of newly covered locations under this coverage signal, given the amount of new
+// program counter and branch edge coverage locations (as returned by CoverageMaps.UpdateWithChangeCounts). An empty
+// or unknown signal is treated as CoverageFeedbackPC.
+func (f CoverageFeedback) CoverageDelta(pcLocationsChanged int, edgeLocationsChanged int) int {
+	switch f {
+	case CoverageFeedbackEdge:
+		return edgeLocationsChanged
+	case CoverageFeedbackBoth:
+		return pcLocationsChanged + edgeLocationsChanged
+	default:
+		return pcLocationsChanged
+	}
+}
@@ -2,8 +2,10 @@ package coverage
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/slices"
 	"sync"
 )
 
@@ -27,6 +29,23 @@ type CoverageMaps struct {
 	// cachedCodeAddress and matches the cachedCodeHash, then this map is used to avoid an expensive lookup into maps.
 	cachedMap *codeCoverageData
 
+	// edgeMaps represents a structure used to track the branch edge coverage of every JUMPI instruction by a given
+	// deployed address/code hash. Each codeCoverageData holds two bytes per byte of bytecode, where the bytes at
+	// offsets 2*pc and 2*pc+1 record whether the JUMPI at pc was executed without and with taking the jump,
+	// respectively.
+	edgeMaps map[common.Address]map[common.Hash]*codeCoverageData
+
+	// cachedEdgeCodeAddress, cachedEdgeCodeHash and cachedEdgeMap serve the same purpose as cachedCodeAddress,
+	// cachedCodeHash and cachedMap, for edgeMaps.
+	cachedEdgeCodeAddress common.Address
+	cachedEdgeCodeHash    common.Hash
+	cachedEdgeMap         *codeCoverageData
+
+	// countingHits describes whether the coverage data in maps and edgeMaps holds (saturating) hit counts, rather than
+	// hit count buckets. Coverage maps only count hits while they are recorded by a CoverageTracer, which converts
+	// them to buckets with bucketHitCounts once a transaction completes.
+	countingHits bool
+
 	// updateLock is a lock to offer concurrent thread safety for map accesses.
 	updateLock sync.Mutex
 }
@@ -38,47 +57,88 @@ func NewCoverageMaps() *CoverageMaps {
 	return maps
 }
 
+// newHitCountingCoverageMaps initializes a new CoverageMaps object which counts the hits of each location recorded,
+// rather than only recording that it was hit.
+func newHitCountingCoverageMaps() *CoverageMaps {
+	maps := NewCoverageMaps()
+	maps.countingHits = true
+	return maps
+}
+
 // Reset clears the coverage state for the CoverageMaps.
 func (cm *CoverageMaps) Reset() {
 	cm.maps = make(map[common.Address]map[common.Hash]*codeCoverageData)
+	cm.edgeMaps = make(map[common.Address]map[common.Hash]*codeCoverageData)
+	cm.cachedMap = nil
+	cm.cachedEdgeMap = nil
 }
 
 // Update updates the current coverage maps with the provided ones. It returns a boolean indicating whether
-// new coverage was achieved, or an error if one was encountered.
+// new coverage (of either program counters or edges) was achieved, or an error if one was encountered. Coverage data
+// of the provided maps may be adopted rather than copied, so they should not be used after they are merged. Merging is
+// order-independent, so maps recorded concurrently may be merged in any order.
 func (cm *CoverageMaps) Update(coverageMaps *CoverageMaps) (bool, error) {
+	pcCoverageChanged, edgeCoverageChanged, err := cm.UpdateWithChanges(coverageMaps)
+	return pcCoverageChanged || edgeCoverageChanged, err
+}
+
+// UpdateWithChanges updates the current coverage maps with the provided ones. It returns booleans indicating whether
+// new program counter coverage and new edge coverage were achieved, respectively, or an error if one was encountered.
+func (cm *CoverageMaps) UpdateWithChanges(coverageMaps *CoverageMaps) (bool, bool, error) {
+	pcLocationsChanged, edgeLocationsChanged, err := cm.UpdateWithChangeCounts(coverageMaps)
+	return pcLocationsChanged > 0, edgeLocationsChanged > 0, err
+}
+
+// UpdateWithChangeCounts updates the current coverage maps with the provided ones. It returns the amount of program
+// counter and edge coverage locations which were newly covered (or, with hit counts, newly hit in a hit count bucket),
+// respectively, or an error if one was encountered.
+func (cm *CoverageMaps) UpdateWithChangeCounts(coverageMaps *CoverageMaps) (int, int, error) {
 	// If our maps provided are nil, do nothing
 	if coverageMaps == nil {
-		return false, nil
+		return 0, 0, nil
 	}
 
 	// Acquire our thread lock and defer our unlocking for when we exit this method
 	cm.updateLock.Lock()
 	defer cm.updateLock.Unlock()
 
-	// Create a boolean indicating whether we achieved new coverage
-	changed := false
+	// Merge our program counter and edge coverage.
+	pcLocationsChanged, err := mergeCodeCoverageMaps(cm.maps, coverageMaps.maps, cm.countingHits)
+	if err != nil {
+		return pcLocationsChanged, 0, err
+	}
+	edgeLocationsChanged, err := mergeCodeCoverageMaps(cm.edgeMaps, coverageMaps.edgeMaps, cm.countingHits)
+	return pcLocationsChanged, edgeLocationsChanged, err
+}
+
+// mergeCodeCoverageMaps merges the provided code coverage data lookups into the provided target lookup, summing hit
+// counts if accumulateHits is true. It returns the amount of locations which achieved new coverage, or an error if
+// one was encountered.
+func mergeCodeCoverageMaps(targetMaps map[common.Address]map[common.Hash]*codeCoverageData, mapsToMerge map[common.Address]map[common.Hash]*codeCoverageData, accumulateHits bool) (int, error) {
+	// Create a counter for the locations which achieved new coverage
+	changed := 0
 
 	// Loop for each coverage map provided
-	for codeAddressToMerge, mapsByCodeHashToMerge := range coverageMaps.maps {
+	for codeAddressToMerge, mapsByCodeHashToMerge := range mapsToMerge {
 		for codeHashToMerge, coverageMapToMerge := range mapsByCodeHashToMerge {
 			// If a coverage map lookup for this code address doesn't exist, create the mapping.
-			mapsByCodeHash, codeAddressExists := cm.maps[codeAddressToMerge]
+			mapsByCodeHash, codeAddressExists := targetMaps[codeAddressToMerge]
 			if !codeAddressExists {
 				mapsByCodeHash = make(map[common.Hash]*codeCoverageData)
-				cm.maps[codeAddressToMerge] = mapsByCodeHash
+				targetMaps[codeAddressToMerge] = mapsByCodeHash
 			}
 
 			// If a coverage map for this code hash already exists in our current mapping, update it with the one
 			// to merge. If it doesn't exist, set it to the one to merge.
 			if existingCoverageMap, codeHashExists := mapsByCodeHash[codeHashToMerge]; codeHashExists {
-				coverageMapChanged, err := existingCoverageMap.updateCodeCoverageData(coverageMapToMerge)
-				changed = changed || coverageMapChanged
+				coverageMapChanged, err := existingCoverageMap.updateCodeCoverageData(coverageMapToMerge, accumulateHits)
+				changed += coverageMapChanged
 				if err != nil {
 					return changed, err
 				}
 			} else {
 				mapsByCodeHash[codeHashToMerge] = coverageMapToMerge
-				changed = true
+				changed += coveredCount(coverageMapToMerge.initBytecodeCoverageData) + coveredCount(coverageMapToMerge.deployedBytecodeCoverageData)
 			}
 		}
 	}
@@ -87,6 +147,17 @@ func (cm *CoverageMaps) Update(coverageMaps *CoverageMaps) (bool, error) {
 	return changed, nil
 }
 
+// coveredCount returns the amount of locations covered within the provided coverage data.
+func coveredCount(coverageData []byte) int {
+	count := 0
+	for _, covered := range coverageData {
+		if covered != 0 {
+			count++
+		}
+	}
+	return count
+}
+
 // SetCoveredAt sets the coverage state of a given program counter location within a codeCoverageData.
 func (cm *CoverageMaps) SetCoveredAt(codeAddress common.Address, codeHash common.Hash, init bool, codeSize int, pc uint64) (bool, error) {
 	// If the code size is zero, do nothing
@@ -94,55 +165,192 @@ func (cm *CoverageMaps) SetCoveredAt(codeAddress common.Address, codeHash common
 		return false, nil
 	}
 
-	// Define variables used to update coverage maps and track changes.
-	var (
-		addedNewMap  bool
-		changedInMap bool
-		coverageMap  *codeCoverageData
-		err          error
-	)
+	// Try to obtain a coverage map for the given code hash from our cache, otherwise look it up and cache it for
+	// faster coverage setting next time this method is called.
+	addedNewMap := false
+	coverageMap := cm.cachedMap
+	if coverageMap == nil || cm.cachedCodeAddress != codeAddress || cm.cachedCodeHash != codeHash {
+		coverageMap, addedNewMap = getOrCreateCodeCoverageData(cm.maps, codeAddress, codeHash)
+		cm.cachedMap = coverageMap
+		cm.cachedCodeHash = codeHash
+		cm.cachedCodeAddress = codeAddress
+	}
 
-	// Try to obtain a coverage map for the given code hash from our cache
-	if cm.cachedMap != nil && cm.cachedCodeAddress == codeAddress && cm.cachedCodeHash == codeHash {
-		coverageMap = cm.cachedMap
-	} else {
-		// If a coverage map lookup for this code address doesn't exist, create the mapping.
-		coverageMapsByCodeHash, codeAddressExists := cm.maps[codeAddress]
-		if !codeAddressExists {
-			coverageMapsByCodeHash = make(map[common.Hash]*codeCoverageData)
-			cm.maps[codeAddress] = coverageMapsByCodeHash
+	// Set our coverage in the map and return our change state
+	changedInMap, err := coverageMap.setCodeCoverageDataAt(init, codeSize, pc, cm.countingHits)
+	return addedNewMap || changedInMap, err
+}
+
+// SetEdgeCoveredAt sets the branch edge coverage state of the JUMPI instruction at a given program counter location,
+// where taken indicates whether the jump was taken.
+func (cm *CoverageMaps) SetEdgeCoveredAt(codeAddress common.Address, codeHash common.Hash, init bool, codeSize int, pc uint64, taken bool) (bool, error) {
+	// If the code size is zero, do nothing
+	if codeSize == 0 {
+		return false, nil
+	}
+
+	// Try to obtain an edge coverage map for the given code hash from our cache, otherwise look it up and cache it.
+	addedNewMap := false
+	edgeMap := cm.cachedEdgeMap
+	if edgeMap == nil || cm.cachedEdgeCodeAddress != codeAddress || cm.cachedEdgeCodeHash != codeHash {
+		edgeMap, addedNewMap = getOrCreateCodeCoverageData(cm.edgeMaps, codeAddress, codeHash)
+		cm.cachedEdgeMap = edgeMap
+		cm.cachedEdgeCodeHash = codeHash
+		cm.cachedEdgeCodeAddress = codeAddress
+	}
+
+	// Each instruction has two edges, one for each outcome of the branch.
+	edgeIndex := pc * 2
+	if taken {
+		edgeIndex++
+	}
+	changedInMap, err := edgeMap.setCodeCoverageDataAt(init, codeSize*2, edgeIndex, cm.countingHits)
+	return addedNewMap || changedInMap, err
+}
+
+// getOrCreateCodeCoverageData obtains the codeCoverageData for the provided code address and code hash from the
+// provided lookup, creating it if it does not exist.
+// Returns the codeCoverageData, and a boolean indicating whether it was created.
+func getOrCreateCodeCoverageData(maps map[common.Address]map[common.Hash]*codeCoverageData, codeAddress common.Address, codeHash common.Hash) (*codeCoverageData, bool) {
+	// If a coverage map lookup for this code address doesn't exist, create the mapping.
+	coverageMapsByCodeHash, codeAddressExists := maps[codeAddress]
+	if !codeAddressExists {
+		coverageMapsByCodeHash = make(map[common.Hash]*codeCoverageData)
+		maps[codeAddress] = coverageMapsByCodeHash
+	}
+
+	// Obtain the coverage map for this code hash if it already exists. If it does not, create a new one.
+	if existingCoverageMap, codeHashExists := coverageMapsByCodeHash[codeHash]; codeHashExists {
+		return existingCoverageMap, false
+	}
+	coverageMap := &codeCoverageData{
+		initBytecodeCoverageData:     nil,
+		deployedBytecodeCoverageData: nil,
+	}
+	coverageMapsByCodeHash[codeHash] = coverageMap
+	return coverageMap, true
+}
+
+// CoverageLocation describes a program counter location within a contract's init or deployed bytecode, identified
+// by the code hash coverage was recorded under.
+type CoverageLocation struct {
+	// CodeHash describes the code hash which coverage was recorded under.
+	CodeHash common.Hash
+	// Init describes whether the location refers to init bytecode, rather than deployed bytecode.
+	Init bool
+	// PC describes the program counter offset in the bytecode.
+	PC int
+}
+
+// CoveredLocations returns every location covered within the CoverageMaps. Locations are de-duplicated across code
+// addresses which share the same code hash.
+func (cm *CoverageMaps) CoveredLocations() []CoverageLocation {
+	// Acquire our thread lock and defer our unlocking for when we exit this method
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+
+	// Collect every covered location into a set.
+	locationSet := make(map[CoverageLocation]struct{})
+	for _, mapsByCodeHash := range cm.maps {
+		for codeHash, coverageMap := range mapsByCodeHash {
+			for pc, covered := range coverageMap.initBytecodeCoverageData {
+				if covered != 0 {
+					locationSet[CoverageLocation{CodeHash: codeHash, Init: true, PC: pc}] = struct{}{}
+				}
+			}
+			for pc, covered := range coverageMap.deployedBytecodeCoverageData {
+				if covered != 0 {
+					locationSet[CoverageLocation{CodeHash: codeHash, Init: false, PC: pc}] = struct{}{}
+				}
+			}
 		}
+	}
 
-		// Obtain the coverage map for this code hash if it already exists. If it does not, create a new one.
-		if existingCoverageMap, codeHashExists := coverageMapsByCodeHash[codeHash]; codeHashExists {
-			coverageMap = existingCoverageMap
-		} else {
-			coverageMap = &codeCoverageData{
-				initBytecodeCoverageData:     nil,
-				deployedBytecodeCoverageData: nil,
+	// Convert our set into a list and return it.
+	locations := make([]CoverageLocation, 0, len(locationSet))
+	for location := range locationSet {
+		locations = append(locations, location)
+	}
+	return locations
+}
+
+// CoveredCounts returns the amount of covered instructions and covered branch edges within the CoverageMaps. Unlike
+// CoveredLocations, this does not collect the locations, so it is cheap enough for frequent sampling, but locations are
+// counted once for each code address they were covered at.
+func (cm *CoverageMaps) CoveredCounts() (int, int) {
+	// Acquire our thread lock and defer our unlocking for when we exit this method
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+
+	// Count every non-zero byte of coverage data.
+	countCovered := func(maps map[common.Address]map[common.Hash]*codeCoverageData) int {
+		count := 0
+		for _, mapsByCodeHash := range maps {
+			for _, coverageMap := range mapsByCodeHash {
+				for _, coverageData := range [][]byte{coverageMap.initBytecodeCoverageData, coverageMap.deployedBytecodeCoverageData} {
+					for _, covered := range coverageData {
+						if covered != 0 {
+							count++
+						}
+					}
+				}
 			}
-			cm.maps[codeAddress][codeHash] = coverageMap
-			addedNewMap = true
 		}
+		return count
+	}
+	return countCovered(cm.maps), countCovered(cm.edgeMaps)
+}
 
-		// Set our cached variables for faster coverage setting next time this method is called.
-		cm.cachedMap = coverageMap
-		cm.cachedCodeHash = codeHash
-		cm.cachedCodeAddress = codeAddress
+// codeHashCoverageData obtains the program counter and edge coverage data recorded for the init or deployed bytecode
+// with the provided code hash, merged across every code address it was recorded at.
+// Returns the program counter and edge coverage data, which are nil if no coverage of that kind was recorded.
+func (cm *CoverageMaps) codeHashCoverageData(codeHash common.Hash, init bool) ([]byte, []byte) {
+	// Acquire our thread lock and defer our unlocking for when we exit this method
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+
+	// Merge the coverage data for the code hash across every code address.
+	mergedCoverageData := func(maps map[common.Address]map[common.Hash]*codeCoverageData) []byte {
+		var merged []byte
+		for _, mapsByCodeHash := range maps {
+			coverageMap, ok := mapsByCodeHash[codeHash]
+			if !ok {
+				continue
+			}
+			coverageData := coverageMap.deployedBytecodeCoverageData
+			if init {
+				coverageData = coverageMap.initBytecodeCoverageData
+			}
+			if merged == nil {
+				merged = slices.Clone(coverageData)
+			} else {
+				mergeCoverageData(merged, coverageData, false)
+			}
+		}
+		return merged
 	}
+	return mergedCoverageData(cm.maps), mergedCoverageData(cm.edgeMaps)
+}
 
-	// Set our coverage in the map and return our change state
-	changedInMap, err = coverageMap.setCodeCoverageDataAt(init, codeSize, pc)
-	return addedNewMap || changedInMap, err
+// edgeCoverageRecorded indicates whether any branch edge coverage was recorded in the CoverageMaps.
+func (cm *CoverageMaps) edgeCoverageRecorded() bool {
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+	return len(cm.edgeMaps) > 0
 }
 
 // Equals checks whether two coverage maps are the same. Equality is determined if the keys and values are all the same.
 func (a *CoverageMaps) Equals(b *CoverageMaps) bool {
-	// Note: the `map` field is what is being tested for equality. Not the cached values
+	// Note: the `maps` and `edgeMaps` fields are what is being tested for equality. Not the cached values
+	return codeCoverageMapsEqual(a.maps, b.maps) && codeCoverageMapsEqual(a.edgeMaps, b.edgeMaps)
+}
 
+// codeCoverageMapsEqual checks whether every code coverage data in the first provided lookup is the same in the
+// second.
+func codeCoverageMapsEqual(aMaps map[common.Address]map[common.Hash]*codeCoverageData, bMaps map[common.Address]map[common.Hash]*codeCoverageData) bool {
 	// Iterate through all maps
-	for addr, aHashToCoverage := range a.maps {
-		bHashToCoverage, ok := b.maps[addr]
+	for addr, aHashToCoverage := range aMaps {
+		bHashToCoverage, ok := bMaps[addr]
 		// Address is not in b - we're done
 		if !ok {
 			return false
@@ -168,6 +376,85 @@ func (a *CoverageMaps) Equals(b *CoverageMaps) bool {
 	return true
 }
 
+// serializedCodeCoverageData describes the serialized form of the codeCoverageData for a code address and code hash.
+type serializedCodeCoverageData struct {
+	// CodeAddress describes the address of the code coverage was recorded for.
+	CodeAddress common.Address `json:"codeAddress"`
+	// CodeHash describes the code hash coverage was recorded under.
+	CodeHash common.Hash `json:"codeHash"`
+	// InitBytecodeCoverageData describes the coverage of the init bytecode, as in codeCoverageData.
+	InitBytecodeCoverageData []byte `json:"initBytecodeCoverageData,omitempty"`
+	// DeployedBytecodeCoverageData describes the coverage of the deployed bytecode, as in codeCoverageData.
+	DeployedBytecodeCoverageData []byte `json:"deployedBytecodeCoverageData,omitempty"`
+}
+
+// serializedCoverageMaps describes the serialized form of the CoverageMaps.
+type serializedCoverageMaps struct {
+	// Maps describes the program counter coverage recorded, as in CoverageMaps.maps.
+	Maps []serializedCodeCoverageData `json:"maps"`
+	// EdgeMaps describes the branch edge coverage recorded, as in CoverageMaps.edgeMaps.
+	EdgeMaps []serializedCodeCoverageData `json:"edgeMaps,omitempty"`
+}
+
+// MarshalJSON provides custom JSON marshalling for the CoverageMaps, so they may be persisted and loaded later.
+// Returns the JSON serialized data, or an error if one occurs.
+func (cm *CoverageMaps) MarshalJSON() ([]byte, error) {
+	// Acquire our thread lock and defer our unlocking for when we exit this method
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+
+	return json.Marshal(serializedCoverageMaps{
+		Maps:     serializeCodeCoverageMaps(cm.maps),
+		EdgeMaps: serializeCodeCoverageMaps(cm.edgeMaps),
+	})
+}
+
+// UnmarshalJSON provides custom JSON unmarshalling for CoverageMaps serialized by MarshalJSON, replacing any coverage
+// they currently hold.
+// Returns an error if one occurs.
+func (cm *CoverageMaps) UnmarshalJSON(b []byte) error {
+	var serialized serializedCoverageMaps
+	err := json.Unmarshal(b, &serialized)
+	if err != nil {
+		return err
+	}
+
+	// Acquire our thread lock and defer our unlocking for when we exit this method
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+
+	// Rebuild our maps, clearing our cache which may refer to the old ones.
+	cm.Reset()
+	deserializeCodeCoverageMaps(cm.maps, serialized.Maps)
+	deserializeCodeCoverageMaps(cm.edgeMaps, serialized.EdgeMaps)
+	return nil
+}
+
+// serializeCodeCoverageMaps converts the provided code coverage data lookup into its serialized form.
+func serializeCodeCoverageMaps(maps map[common.Address]map[common.Hash]*codeCoverageData) []serializedCodeCoverageData {
+	serialized := make([]serializedCodeCoverageData, 0)
+	for codeAddress, mapsByCodeHash := range maps {
+		for codeHash, coverageMap := range mapsByCodeHash {
+			serialized = append(serialized, serializedCodeCoverageData{
+				CodeAddress:                  codeAddress,
+				CodeHash:                     codeHash,
+				InitBytecodeCoverageData:     coverageMap.initBytecodeCoverageData,
+				DeployedBytecodeCoverageData: coverageMap.deployedBytecodeCoverageData,
+			})
+		}
+	}
+	return serialized
+}
+
+// deserializeCodeCoverageMaps adds the provided serialized code coverage data to the provided lookup.
+func deserializeCodeCoverageMaps(maps map[common.Address]map[common.Hash]*codeCoverageData, serialized []serializedCodeCoverageData) {
+	for _, data := range serialized {
+		coverageMap, _ := getOrCreateCodeCoverageData(maps, data.CodeAddress, data.CodeHash)
+		coverageMap.initBytecodeCoverageData = data.InitBytecodeCoverageData
+		coverageMap.deployedBytecodeCoverageData = data.DeployedBytecodeCoverageData
+	}
+}
+
 // codeCoverageData represents a data structure used to identify instruction execution coverage of contract byte code.
 type codeCoverageData struct {
 	// initBytecodeCoverageData represents a list of bytes for each byte of a contract's init bytecode. Non-zero values
@@ -178,26 +465,22 @@ type codeCoverageData struct {
 	deployedBytecodeCoverageData []byte
 }
 
-// updateCodeCoverageData creates updates the current coverage map with the provided one. It returns a boolean indicating whether
-// new coverage was achieved, or an error if one was encountered.
-func (cm *codeCoverageData) updateCodeCoverageData(coverageMap *codeCoverageData) (bool, error) {
+// updateCodeCoverageData creates updates the current coverage map with the provided one. Hit count buckets are merged,
+// or if accumulateHits is true, hit counts are summed. It returns the amount of locations which achieved new coverage
+// (were hit in a hit count bucket they were not before), or an error if one was encountered.
+func (cm *codeCoverageData) updateCodeCoverageData(coverageMap *codeCoverageData, accumulateHits bool) (int, error) {
 	// Define our return variable
-	changed := false
+	changed := 0
 
 	// Update our init bytecode coverage data.
 	if coverageMap.initBytecodeCoverageData != nil {
 		if cm.initBytecodeCoverageData == nil {
 			cm.initBytecodeCoverageData = coverageMap.initBytecodeCoverageData
-			changed = true
+			changed += coveredCount(coverageMap.initBytecodeCoverageData)
 		} else {
 			// Update each byte which represents a position in the bytecode which was covered. We ignore any size
 			// differences as init bytecode can have arbitrary length arguments appended.
-			for i := 0; i < len(cm.initBytecodeCoverageData) || i < len(coverageMap.initBytecodeCoverageData); i++ {
-				if cm.initBytecodeCoverageData[i] == 0 && coverageMap.initBytecodeCoverageData[i] != 0 {
-					cm.initBytecodeCoverageData[i] = 1
-					changed = true
-				}
-			}
+			changed += mergeCoverageData(cm.initBytecodeCoverageData, coverageMap.initBytecodeCoverageData, accumulateHits)
 		}
 	}
 
@@ -205,23 +488,91 @@ func (cm *codeCoverageData) updateCodeCoverageData(coverageMap *codeCoverageData
 	if coverageMap.deployedBytecodeCoverageData != nil {
 		if cm.deployedBytecodeCoverageData == nil {
 			cm.deployedBytecodeCoverageData = coverageMap.deployedBytecodeCoverageData
-			changed = true
+			changed += coveredCount(coverageMap.deployedBytecodeCoverageData)
 		} else {
 			// Update each byte which represents a position in the bytecode which was covered.
-			for i := 0; i < len(cm.deployedBytecodeCoverageData); i++ {
-				if cm.deployedBytecodeCoverageData[i] == 0 && coverageMap.deployedBytecodeCoverageData[i] != 0 {
-					cm.deployedBytecodeCoverageData[i] = 1
-					changed = true
-				}
-			}
+			changed += mergeCoverageData(cm.deployedBytecodeCoverageData, coverageMap.deployedBytecodeCoverageData, accumulateHits)
 		}
 	}
 
 	return changed, nil
 }
 
-// setCodeCoverageDataAt sets the coverage state of a given program counter location within a codeCoverageData.
-func (cm *codeCoverageData) setCodeCoverageDataAt(init bool, codeSize int, pc uint64) (bool, error) {
+// mergeCoverageData merges the provided coverage data into the target coverage data, up to the length of the shorter
+// of the two. Hit count buckets are merged, or if accumulateHits is true, hit counts are summed (saturating).
+// Returns the amount of locations which achieved new coverage.
+func mergeCoverageData(target []byte, coverageData []byte, accumulateHits bool) int {
+	changed := 0
+	for i := 0; i < len(target) && i < len(coverageData); i++ {
+		if accumulateHits {
+			if target[i] == 0 && coverageData[i] != 0 {
+				changed++
+			}
+			if sum := int(target[i]) + int(coverageData[i]); sum > 0xFF {
+				target[i] = 0xFF
+			} else {
+				target[i] = byte(sum)
+			}
+		} else if coverageData[i]&^target[i] != 0 {
+			target[i] |= coverageData[i]
+			changed++
+		}
+	}
+	return changed
+}
+
+// hitCountBucket obtains the hit count bucket for the provided hit count, as a single bit flag, such that coverage
+// data can record every hit count bucket a location was hit in. Hit counts are bucketed as 1, 2, 3, 4-7, 8-15,
+// 16-31, 32-127 and 128+, so that meaningful changes in how often a location is hit (e.g. loop iterations) are
+// distinguished without every change in hit count being considered new coverage.
+func hitCountBucket(hitCount byte) byte {
+	switch {
+	case hitCount == 0:
+		return 0
+	case hitCount <= 2:
+		return hitCount
+	case hitCount == 3:
+		return 1 << 2
+	case hitCount < 8:
+		return 1 << 3
+	case hitCount < 16:
+		return 1 << 4
+	case hitCount < 32:
+		return 1 << 5
+	case hitCount < 128:
+		return 1 << 6
+	default:
+		return 1 << 7
+	}
+}
+
+// bucketHitCounts converts the hit counts recorded by CoverageMaps which count hits into hit count buckets, after
+// which they no longer count hits. If the CoverageMaps do not count hits, this does nothing.
+func (cm *CoverageMaps) bucketHitCounts() {
+	// Acquire our thread lock and defer our unlocking for when we exit this method
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+
+	if !cm.countingHits {
+		return
+	}
+	for _, maps := range []map[common.Address]map[common.Hash]*codeCoverageData{cm.maps, cm.edgeMaps} {
+		for _, mapsByCodeHash := range maps {
+			for _, coverageMap := range mapsByCodeHash {
+				for _, coverageData := range [][]byte{coverageMap.initBytecodeCoverageData, coverageMap.deployedBytecodeCoverageData} {
+					for i, hitCount := range coverageData {
+						coverageData[i] = hitCountBucket(hitCount)
+					}
+				}
+			}
+		}
+	}
+	cm.countingHits = false
+}
+
+// setCodeCoverageDataAt sets the coverage state of a given program counter location within a codeCoverageData. If
+// countHits is true, the (saturating) hit count of the location is incremented instead.
+func (cm *codeCoverageData) setCodeCoverageDataAt(init bool, codeSize int, pc uint64, countHits bool) (bool, error) {
 	// Obtain our coverage data depending on if we're initializing/deploying a contract now. If coverage data doesn't
 	// exist, we create it.
 	var coverageData []byte
@@ -239,6 +590,12 @@ func (cm *codeCoverageData) setCodeCoverageDataAt(init bool, codeSize int, pc ui
 
 	// If our program counter is in range, determine if we achieved new coverage for the first time, and update it.
 	if pc < uint64(len(coverageData)) {
+		if countHits {
+			if coverageData[pc] < 0xFF {
+				coverageData[pc]++
+			}
+			return coverageData[pc] == 1, nil
+		}
 		if coverageData[pc] == 0 {
 			coverageData[pc] = 1
 			return true, nil
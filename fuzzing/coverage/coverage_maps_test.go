@@ -0,0 +1,122 @@
+package coverage
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRandomCoverageMaps creates coverage maps for the provided count of calls, each covering random program counters
+// and edges across a few contracts, as a corpus replay would record for each call it executes. The provided seed
+// determines the coverage recorded, so the same maps may be created again.
+// Returns the coverage maps of each call.
+func newRandomCoverageMaps(t *testing.T, seed int64, count int) []*CoverageMaps {
+	const codeSize = 64
+	randomProvider := rand.New(rand.NewSource(seed))
+	codeAddresses := []common.Address{common.HexToAddress("0x1000"), common.HexToAddress("0x2000")}
+	codeHashes := []common.Hash{common.HexToHash("0x1111"), common.HexToHash("0x2222"), common.HexToHash("0x3333")}
+
+	coverageMapsList := make([]*CoverageMaps, count)
+	for i := range coverageMapsList {
+		coverageMaps := NewCoverageMaps()
+		for j := 0; j < 1+randomProvider.Intn(8); j++ {
+			codeAddress := codeAddresses[randomProvider.Intn(len(codeAddresses))]
+			codeHash := codeHashes[randomProvider.Intn(len(codeHashes))]
+			init := randomProvider.Intn(4) == 0
+			pc := uint64(randomProvider.Intn(codeSize))
+			_, err := coverageMaps.SetCoveredAt(codeAddress, codeHash, init, codeSize, pc)
+			assert.NoError(t, err)
+			_, err = coverageMaps.SetEdgeCoveredAt(codeAddress, codeHash, init, codeSize, pc, randomProvider.Intn(2) == 0)
+			assert.NoError(t, err)
+		}
+		coverageMapsList[i] = coverageMaps
+	}
+	return coverageMapsList
+}
+
+// TestCoverageMapsMergeOrderIndependence ensures merging coverage maps produces the same coverage regardless of the
+// order they are merged in, including when they are merged concurrently, as when a corpus is replayed in parallel.
+func TestCoverageMapsMergeOrderIndependence(t *testing.T) {
+	const seed, count = 7, 50
+
+	// Merge our coverage maps in the order they were recorded.
+	expected := NewCoverageMaps()
+	for _, coverageMaps := range newRandomCoverageMaps(t, seed, count) {
+		_, err := expected.Update(coverageMaps)
+		assert.NoError(t, err)
+	}
+
+	// Merging them in any other order should produce the same coverage. Maps are adopted when merged, so we recreate
+	// them for each order.
+	for i := int64(0); i < 5; i++ {
+		coverageMapsList := newRandomCoverageMaps(t, seed, count)
+		rand.New(rand.NewSource(i)).Shuffle(len(coverageMapsList), func(a, b int) {
+			coverageMapsList[a], coverageMapsList[b] = coverageMapsList[b], coverageMapsList[a]
+		})
+		shuffled := NewCoverageMaps()
+		for _, coverageMaps := range coverageMapsList {
+			_, err := shuffled.Update(coverageMaps)
+			assert.NoError(t, err)
+		}
+		assert.True(t, expected.Equals(shuffled))
+		assert.True(t, shuffled.Equals(expected))
+	}
+
+	// Merging them concurrently should also produce the same coverage.
+	concurrent := NewCoverageMaps()
+	var wg sync.WaitGroup
+	for _, coverageMaps := range newRandomCoverageMaps(t, seed, count) {
+		wg.Add(1)
+		go func(coverageMaps *CoverageMaps) {
+			defer wg.Done()
+			_, err := concurrent.Update(coverageMaps)
+			assert.NoError(t, err)
+		}(coverageMaps)
+	}
+	wg.Wait()
+	assert.True(t, expected.Equals(concurrent))
+	assert.True(t, concurrent.Equals(expected))
+}
+
+// TestCoverageMapsUpdateWithChangeCounts ensures merging coverage maps reports the amount of locations which were
+// newly covered, both for code it had no coverage for and code it did.
+func TestCoverageMapsUpdateWithChangeCounts(t *testing.T) {
+	codeAddress := common.HexToAddress("0x1000")
+	codeHash := common.HexToHash("0x1111")
+
+	// newCoverageMaps creates coverage maps covering the provided program counters, and the edges to each of them.
+	newCoverageMaps := func(pcs ...uint64) *CoverageMaps {
+		coverageMaps := NewCoverageMaps()
+		for _, pc := range pcs {
+			_, err := coverageMaps.SetCoveredAt(codeAddress, codeHash, false, 64, pc)
+			assert.NoError(t, err)
+			_, err = coverageMaps.SetEdgeCoveredAt(codeAddress, codeHash, false, 64, pc, true)
+			assert.NoError(t, err)
+		}
+		return coverageMaps
+	}
+
+	// Merging coverage of code we had none for counts every location covered.
+	totalCoverageMaps := NewCoverageMaps()
+	pcLocationsChanged, edgeLocationsChanged, err := totalCoverageMaps.UpdateWithChangeCounts(newCoverageMaps(1, 2, 3))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, pcLocationsChanged)
+	assert.EqualValues(t, 3, edgeLocationsChanged)
+
+	// Merging coverage which partially overlaps only counts the locations not covered before.
+	pcLocationsChanged, edgeLocationsChanged, err = totalCoverageMaps.UpdateWithChangeCounts(newCoverageMaps(2, 3, 4, 5))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, pcLocationsChanged)
+	assert.EqualValues(t, 2, edgeLocationsChanged)
+	assert.EqualValues(t, 2, CoverageFeedbackPC.CoverageDelta(pcLocationsChanged, edgeLocationsChanged))
+	assert.EqualValues(t, 4, CoverageFeedbackBoth.CoverageDelta(pcLocationsChanged, edgeLocationsChanged))
+
+	// Merging coverage already covered counts nothing.
+	pcLocationsChanged, edgeLocationsChanged, err = totalCoverageMaps.UpdateWithChangeCounts(newCoverageMaps(1, 5))
+	assert.NoError(t, err)
+	assert.Zero(t, pcLocationsChanged)
+	assert.Zero(t, edgeLocationsChanged)
+}
@@ -0,0 +1,139 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// coverageSnapshotVersion describes the version of the CoverageSnapshot format written by this version. Snapshots
+// of other versions cannot be read.
+const coverageSnapshotVersion = 1
+
+// CoverageSnapshot describes the source line and branch coverage achieved by each compiled contract, in a form which
+// is stable across versions and can be compared with snapshots of other campaigns (e.g. with DiffCoverageSnapshots).
+type CoverageSnapshot struct {
+	// Version describes the version of the snapshot format.
+	Version int `json:"version"`
+
+	// Contracts describes the coverage of each compiled contract, sorted by source path and name.
+	Contracts []ContractCoverageSnapshot `json:"contracts"`
+}
+
+// ContractCoverageSnapshot describes the source line and branch coverage achieved by a single compiled contract.
+type ContractCoverageSnapshot struct {
+	// SourcePath describes the path of the source file which defines the contract.
+	SourcePath string `json:"sourcePath"`
+
+	// Name describes the name of the contract.
+	Name string `json:"name"`
+
+	// Files describes the coverage of each source file the contract's bytecode maps to, sorted by path.
+	Files []SourceFileCoverageSnapshot `json:"files"`
+}
+
+// SourceFileCoverageSnapshot describes the coverage of the lines of a single source file.
+type SourceFileCoverageSnapshot struct {
+	// Path describes the path of the source file.
+	Path string `json:"path"`
+
+	// ContentHash describes the hash of the contents of the source file, which identifies whether line numbers in
+	// different snapshots refer to the same source.
+	ContentHash common.Hash `json:"contentHash"`
+
+	// Lines describes the coverage of each line which any instruction maps to, in order.
+	Lines []LineCoverageSnapshot `json:"lines"`
+}
+
+// LineCoverageSnapshot describes the coverage of a single source line.
+type LineCoverageSnapshot struct {
+	// Line describes the line number, starting from one.
+	Line int `json:"line"`
+
+	// Covered describes whether any instruction which maps to the line was executed.
+	Covered bool `json:"covered"`
+
+	// Branches describes the edges of each conditional jump which maps to the line, if branch edge coverage was
+	// recorded.
+	Branches []BranchCoverageSnapshot `json:"branches,omitempty"`
+}
+
+// BranchCoverageSnapshot describes the coverage of both edges of a conditional jump.
+type BranchCoverageSnapshot struct {
+	// NotTaken describes whether the conditional jump was executed without taking the jump.
+	NotTaken bool `json:"notTaken"`
+
+	// Taken describes whether the conditional jump was executed and took the jump.
+	Taken bool `json:"taken"`
+}
+
+// NewCoverageSnapshot creates a CoverageSnapshot of the coverage of each contract in the provided source analysis.
+// Returns the CoverageSnapshot.
+func NewCoverageSnapshot(analysis *SourceAnalysis) *CoverageSnapshot {
+	snapshot := &CoverageSnapshot{
+		Version:   coverageSnapshotVersion,
+		Contracts: make([]ContractCoverageSnapshot, 0, len(analysis.Contracts)),
+	}
+	for _, contractAnalysis := range analysis.Contracts {
+		contractSnapshot := ContractCoverageSnapshot{
+			SourcePath: contractAnalysis.SourcePath,
+			Name:       contractAnalysis.Name,
+			Files:      make([]SourceFileCoverageSnapshot, 0, len(contractAnalysis.Files)),
+		}
+		for _, fileAnalysis := range contractAnalysis.Files {
+			fileSnapshot := SourceFileCoverageSnapshot{
+				Path:        fileAnalysis.Path,
+				ContentHash: fileAnalysis.ContentHash,
+				Lines:       make([]LineCoverageSnapshot, 0),
+			}
+			for lineIndex, line := range fileAnalysis.Lines {
+				if !line.IsActive {
+					continue
+				}
+				lineSnapshot := LineCoverageSnapshot{Line: lineIndex + 1, Covered: line.IsCovered}
+				for _, branch := range line.Branches {
+					lineSnapshot.Branches = append(lineSnapshot.Branches, BranchCoverageSnapshot{NotTaken: branch.NotTaken, Taken: branch.Taken})
+				}
+				fileSnapshot.Lines = append(fileSnapshot.Lines, lineSnapshot)
+			}
+			contractSnapshot.Files = append(contractSnapshot.Files, fileSnapshot)
+		}
+		sort.Slice(contractSnapshot.Files, func(i, j int) bool {
+			return contractSnapshot.Files[i].Path < contractSnapshot.Files[j].Path
+		})
+		snapshot.Contracts = append(snapshot.Contracts, contractSnapshot)
+	}
+	return snapshot
+}
+
+// WriteCoverageSnapshot writes the provided CoverageSnapshot to the provided file path.
+// Returns an error if one occurs.
+func WriteCoverageSnapshot(snapshot *CoverageSnapshot, filePath string) error {
+	b, err := json.MarshalIndent(snapshot, "", " ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(filePath, b, 0644)
+}
+
+// ReadCoverageSnapshot reads a CoverageSnapshot from the provided file path.
+// Returns the CoverageSnapshot, or an error if one occurs.
+func ReadCoverageSnapshot(filePath string) (*CoverageSnapshot, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot CoverageSnapshot
+	err = json.Unmarshal(b, &snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse coverage snapshot '%v': %v", filePath, err)
+	}
+	if snapshot.Version != coverageSnapshotVersion {
+		return nil, fmt.Errorf("could not read coverage snapshot '%v', as its version %d is not supported (expected %d)", filePath, snapshot.Version, coverageSnapshotVersion)
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,228 @@
+package coverage
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CoverageDiff describes the differences in coverage between two CoverageSnapshot objects.
+type CoverageDiff struct {
+	// Contracts describes the differences in coverage of each contract in either snapshot, sorted by source path and
+	// name.
+	Contracts []ContractCoverageDiff `json:"contracts"`
+
+	// Lines describes the differences in line coverage, summed across all contracts.
+	Lines CoverageDiffCounts `json:"lines"`
+
+	// Edges describes the differences in branch edge coverage, summed across all contracts.
+	Edges CoverageDiffCounts `json:"edges"`
+}
+
+// ContractCoverageDiff describes the differences in coverage of a single contract between two snapshots.
+type ContractCoverageDiff struct {
+	// SourcePath describes the path of the source file which defines the contract.
+	SourcePath string `json:"sourcePath"`
+
+	// Name describes the name of the contract.
+	Name string `json:"name"`
+
+	// Lines describes the differences in line coverage of the contract.
+	Lines CoverageDiffCounts `json:"lines"`
+
+	// Edges describes the differences in branch edge coverage of the contract.
+	Edges CoverageDiffCounts `json:"edges"`
+
+	// NewlyCoveredLines describes the lines which are only covered in the new snapshot.
+	NewlyCoveredLines []SourceLineLocation `json:"newlyCoveredLines"`
+
+	// LostLines describes the lines which are only covered in the old snapshot.
+	LostLines []SourceLineLocation `json:"lostLines"`
+
+	// NewlyCoveredEdges describes the branch edges which are only covered in the new snapshot.
+	NewlyCoveredEdges []SourceEdgeLocation `json:"newlyCoveredEdges"`
+
+	// LostEdges describes the branch edges which are only covered in the old snapshot.
+	LostEdges []SourceEdgeLocation `json:"lostEdges"`
+
+	// ChangedFiles describes the paths of source files whose contents differ between the snapshots, in which case
+	// the same line numbers may not refer to the same source.
+	ChangedFiles []string `json:"changedFiles"`
+}
+
+// CoverageDiffCounts describes the amount of locations (lines or edges) whose coverage changed between two
+// snapshots.
+type CoverageDiffCounts struct {
+	// NewlyCovered describes the amount of locations which are only covered in the new snapshot.
+	NewlyCovered int `json:"newlyCovered"`
+
+	// Lost describes the amount of locations which are only covered in the old snapshot.
+	Lost int `json:"lost"`
+
+	// Unchanged describes the amount of locations which are covered in both snapshots.
+	Unchanged int `json:"unchanged"`
+}
+
+// add adds the provided counts to these counts.
+func (c *CoverageDiffCounts) add(other CoverageDiffCounts) {
+	c.NewlyCovered += other.NewlyCovered
+	c.Lost += other.Lost
+	c.Unchanged += other.Unchanged
+}
+
+// SourceLineLocation describes a line of a source file.
+type SourceLineLocation struct {
+	// Path describes the path of the source file.
+	Path string `json:"path"`
+
+	// Line describes the line number, starting from one.
+	Line int `json:"line"`
+}
+
+// SourceEdgeLocation describes an edge of a conditional jump which maps to a line of a source file.
+type SourceEdgeLocation struct {
+	// Path describes the path of the source file.
+	Path string `json:"path"`
+
+	// Line describes the line number, starting from one.
+	Line int `json:"line"`
+
+	// Branch describes the index of the conditional jump among those which map to the line.
+	Branch int `json:"branch"`
+
+	// Taken describes whether the edge is the one which takes the jump.
+	Taken bool `json:"taken"`
+}
+
+// contractCoverageSnapshotKey identifies a contract across snapshots.
+type contractCoverageSnapshotKey struct {
+	sourcePath string
+	name       string
+}
+
+// contractCoverageLocations describes the locations a contract covered in a snapshot, and the content hashes of its
+// source files.
+type contractCoverageLocations struct {
+	lines         map[SourceLineLocation]struct{}
+	edges         map[SourceEdgeLocation]struct{}
+	contentHashes map[string]common.Hash
+}
+
+// coveredLocations obtains the locations covered by each contract in the snapshot.
+func (s *CoverageSnapshot) coveredLocations() map[contractCoverageSnapshotKey]*contractCoverageLocations {
+	results := make(map[contractCoverageSnapshotKey]*contractCoverageLocations, len(s.Contracts))
+	for _, contract := range s.Contracts {
+		locations := &contractCoverageLocations{
+			lines:         make(map[SourceLineLocation]struct{}),
+			edges:         make(map[SourceEdgeLocation]struct{}),
+			contentHashes: make(map[string]common.Hash),
+		}
+		for _, file := range contract.Files {
+			locations.contentHashes[file.Path] = file.ContentHash
+			for _, line := range file.Lines {
+				if line.Covered {
+					locations.lines[SourceLineLocation{Path: file.Path, Line: line.Line}] = struct{}{}
+				}
+				for branchIndex, branch := range line.Branches {
+					for _, edge := range []struct{ taken, covered bool }{{false, branch.NotTaken}, {true, branch.Taken}} {
+						if edge.covered {
+							locations.edges[SourceEdgeLocation{Path: file.Path, Line: line.Line, Branch: branchIndex, Taken: edge.taken}] = struct{}{}
+						}
+					}
+				}
+			}
+		}
+		results[contractCoverageSnapshotKey{sourcePath: contract.SourcePath, name: contract.Name}] = locations
+	}
+	return results
+}
+
+// diffCoveredLocations compares the provided sets of covered locations.
+// Returns the counts of differences, and the locations newly covered and lost, sorted by the provided less function.
+func diffCoveredLocations[T comparable](oldLocations map[T]struct{}, newLocations map[T]struct{}, less func(a, b T) bool) (CoverageDiffCounts, []T, []T) {
+	var counts CoverageDiffCounts
+	newlyCovered, lost := make([]T, 0), make([]T, 0)
+	for location := range newLocations {
+		if _, ok := oldLocations[location]; ok {
+			counts.Unchanged++
+		} else {
+			newlyCovered = append(newlyCovered, location)
+		}
+	}
+	for location := range oldLocations {
+		if _, ok := newLocations[location]; !ok {
+			lost = append(lost, location)
+		}
+	}
+	counts.NewlyCovered, counts.Lost = len(newlyCovered), len(lost)
+	sort.Slice(newlyCovered, func(i, j int) bool { return less(newlyCovered[i], newlyCovered[j]) })
+	sort.Slice(lost, func(i, j int) bool { return less(lost[i], lost[j]) })
+	return counts, newlyCovered, lost
+}
+
+// DiffCoverageSnapshots compares the coverage of each contract in the provided old and new snapshots. Contracts
+// which are only present in one snapshot are treated as covering nothing in the other.
+// Returns the CoverageDiff describing which lines and edges were newly covered, lost, or remain covered.
+func DiffCoverageSnapshots(oldSnapshot *CoverageSnapshot, newSnapshot *CoverageSnapshot) *CoverageDiff {
+	// Obtain the covered locations of each contract in either snapshot.
+	oldLocations, newLocations := oldSnapshot.coveredLocations(), newSnapshot.coveredLocations()
+	contractKeys := make([]contractCoverageSnapshotKey, 0, len(oldLocations)+len(newLocations))
+	for key := range oldLocations {
+		contractKeys = append(contractKeys, key)
+	}
+	for key := range newLocations {
+		if _, ok := oldLocations[key]; !ok {
+			contractKeys = append(contractKeys, key)
+		}
+	}
+	sort.Slice(contractKeys, func(i, j int) bool {
+		if contractKeys[i].sourcePath != contractKeys[j].sourcePath {
+			return contractKeys[i].sourcePath < contractKeys[j].sourcePath
+		}
+		return contractKeys[i].name < contractKeys[j].name
+	})
+
+	// Compare the locations covered by each contract.
+	emptyLocations := &contractCoverageLocations{}
+	lessLine := func(a, b SourceLineLocation) bool {
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	}
+	lessEdge := func(a, b SourceEdgeLocation) bool {
+		if a.Path != b.Path || a.Line != b.Line {
+			return lessLine(SourceLineLocation{a.Path, a.Line}, SourceLineLocation{b.Path, b.Line})
+		}
+		if a.Branch != b.Branch {
+			return a.Branch < b.Branch
+		}
+		return !a.Taken && b.Taken
+	}
+	diff := &CoverageDiff{Contracts: make([]ContractCoverageDiff, 0, len(contractKeys))}
+	for _, key := range contractKeys {
+		oldContractLocations, ok := oldLocations[key]
+		if !ok {
+			oldContractLocations = emptyLocations
+		}
+		newContractLocations, ok := newLocations[key]
+		if !ok {
+			newContractLocations = emptyLocations
+		}
+
+		contractDiff := ContractCoverageDiff{SourcePath: key.sourcePath, Name: key.name, ChangedFiles: make([]string, 0)}
+		contractDiff.Lines, contractDiff.NewlyCoveredLines, contractDiff.LostLines = diffCoveredLocations(oldContractLocations.lines, newContractLocations.lines, lessLine)
+		contractDiff.Edges, contractDiff.NewlyCoveredEdges, contractDiff.LostEdges = diffCoveredLocations(oldContractLocations.edges, newContractLocations.edges, lessEdge)
+		for filePath, contentHash := range newContractLocations.contentHashes {
+			if oldContentHash, ok := oldContractLocations.contentHashes[filePath]; ok && oldContentHash != contentHash {
+				contractDiff.ChangedFiles = append(contractDiff.ChangedFiles, filePath)
+			}
+		}
+		sort.Strings(contractDiff.ChangedFiles)
+
+		diff.Lines.add(contractDiff.Lines)
+		diff.Edges.add(contractDiff.Edges)
+		diff.Contracts = append(diff.Contracts, contractDiff)
+	}
+	return diff
+}
@@ -0,0 +1,90 @@
+package coverage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiffCoverageSnapshots ensures coverage snapshots survive being written and read, and that comparing them
+// reports lines and edges which were newly covered, lost, or remain covered, per contract and in total.
+func TestDiffCoverageSnapshots(t *testing.T) {
+	// Create an old snapshot covering lines 1 and 2 and one edge, and a new snapshot of a changed source file which
+	// loses line 2 but covers line 3 and both edges. The new snapshot also covers a contract the old one did not.
+	oldSnapshot := &CoverageSnapshot{
+		Version: coverageSnapshotVersion,
+		Contracts: []ContractCoverageSnapshot{{
+			SourcePath: "A.sol",
+			Name:       "A",
+			Files: []SourceFileCoverageSnapshot{{
+				Path:        "A.sol",
+				ContentHash: common.HexToHash("0x01"),
+				Lines: []LineCoverageSnapshot{
+					{Line: 1, Covered: true},
+					{Line: 2, Covered: true, Branches: []BranchCoverageSnapshot{{NotTaken: true}}},
+					{Line: 3},
+				},
+			}},
+		}},
+	}
+	newSnapshot := &CoverageSnapshot{
+		Version: coverageSnapshotVersion,
+		Contracts: []ContractCoverageSnapshot{
+			{
+				SourcePath: "A.sol",
+				Name:       "A",
+				Files: []SourceFileCoverageSnapshot{{
+					Path:        "A.sol",
+					ContentHash: common.HexToHash("0x02"),
+					Lines: []LineCoverageSnapshot{
+						{Line: 1, Covered: true},
+						{Line: 2, Branches: []BranchCoverageSnapshot{{NotTaken: true, Taken: true}}},
+						{Line: 3, Covered: true},
+					},
+				}},
+			},
+			{
+				SourcePath: "B.sol",
+				Name:       "B",
+				Files: []SourceFileCoverageSnapshot{{
+					Path:  "B.sol",
+					Lines: []LineCoverageSnapshot{{Line: 1, Covered: true}},
+				}},
+			},
+		},
+	}
+
+	// Write and read back our new snapshot to ensure its serialization is stable.
+	snapshotPath := filepath.Join(t.TempDir(), "coverage.cov")
+	err := WriteCoverageSnapshot(newSnapshot, snapshotPath)
+	assert.NoError(t, err)
+	readSnapshot, err := ReadCoverageSnapshot(snapshotPath)
+	assert.NoError(t, err)
+	assert.EqualValues(t, newSnapshot, readSnapshot)
+
+	// Compare our snapshots.
+	diff := DiffCoverageSnapshots(oldSnapshot, readSnapshot)
+	assert.Len(t, diff.Contracts, 2)
+	contractDiff := diff.Contracts[0]
+	assert.EqualValues(t, "A", contractDiff.Name)
+	assert.EqualValues(t, CoverageDiffCounts{NewlyCovered: 1, Lost: 1, Unchanged: 1}, contractDiff.Lines)
+	assert.EqualValues(t, CoverageDiffCounts{NewlyCovered: 1, Unchanged: 1}, contractDiff.Edges)
+	assert.EqualValues(t, []SourceLineLocation{{Path: "A.sol", Line: 3}}, contractDiff.NewlyCoveredLines)
+	assert.EqualValues(t, []SourceLineLocation{{Path: "A.sol", Line: 2}}, contractDiff.LostLines)
+	assert.EqualValues(t, []SourceEdgeLocation{{Path: "A.sol", Line: 2, Taken: true}}, contractDiff.NewlyCoveredEdges)
+	assert.Empty(t, contractDiff.LostEdges)
+	assert.EqualValues(t, []string{"A.sol"}, contractDiff.ChangedFiles)
+	assert.EqualValues(t, "B", diff.Contracts[1].Name)
+	assert.EqualValues(t, CoverageDiffCounts{NewlyCovered: 1}, diff.Contracts[1].Lines)
+	assert.EqualValues(t, CoverageDiffCounts{NewlyCovered: 2, Lost: 1, Unchanged: 1}, diff.Lines)
+	assert.EqualValues(t, CoverageDiffCounts{NewlyCovered: 1, Unchanged: 1}, diff.Edges)
+
+	// Snapshots of other versions should not be read.
+	newSnapshot.Version = coverageSnapshotVersion + 1
+	err = WriteCoverageSnapshot(newSnapshot, snapshotPath)
+	assert.NoError(t, err)
+	_, err = ReadCoverageSnapshot(snapshotPath)
+	assert.Error(t, err)
+}
@@ -32,6 +32,18 @@ func RemoveCoverageTracerResults(messageResults *types.MessageResults) {
 	delete(messageResults.AdditionalResults, coverageTracerResultsKey)
 }
 
+// resolveCoverageMapCodeHash obtains the code hash coverage is recorded under for the provided code. This is the
+// bytecode hash embedded in its contract metadata if it is available, so that coverage of a contract is recorded
+// under the same code hash regardless of immutables or constructor arguments, otherwise the provided code hash.
+func resolveCoverageMapCodeHash(code []byte, codeHash common.Hash) common.Hash {
+	if metadata := compilationTypes.ExtractContractMetadata(code); metadata != nil {
+		if metadataHash := metadata.ExtractBytecodeHash(); metadataHash != nil {
+			return common.BytesToHash(metadataHash)
+		}
+	}
+	return codeHash
+}
+
 // CoverageTracer implements vm.EVMLogger to collect information such as coverage maps
 // for fuzzing campaigns from EVM execution traces.
 type CoverageTracer struct {
@@ -44,6 +56,13 @@ type CoverageTracer struct {
 	// callDepth refers to the current EVM depth during tracing.
 	callDepth uint64
 
+	// edgeCoverageEnabled describes whether branch edge coverage is recorded in addition to program counter coverage.
+	edgeCoverageEnabled bool
+
+	// hitCountsEnabled describes whether coverage records the hit count bucket of each location within a transaction,
+	// rather than only whether it was hit.
+	hitCountsEnabled bool
+
 	// cachedCodeHashOriginal describes the code hash used to last store coverage.
 	cachedCodeHashOriginal common.Hash
 	// cachedCodeHashResolved describes the code hash used to store the last coverage map. If the contract metadata
@@ -69,11 +88,33 @@ func NewCoverageTracer() *CoverageTracer {
 	return tracer
 }
 
+// SetEdgeCoverageEnabled sets whether the tracer records the branch edge coverage of JUMPI instructions, in addition
+// to program counter coverage. This is disabled by default, as it adds overhead to tracing.
+func (t *CoverageTracer) SetEdgeCoverageEnabled(enabled bool) {
+	t.edgeCoverageEnabled = enabled
+}
+
+// SetHitCountsEnabled sets whether the tracer records how many times each location (and edge) was hit within a
+// transaction, bucketed such that hitting a location in a new hit count bucket is considered new coverage. This is
+// disabled by default, in which case coverage only records whether each location was hit.
+func (t *CoverageTracer) SetHitCountsEnabled(enabled bool) {
+	t.hitCountsEnabled = enabled
+}
+
+// newCoverageMaps creates coverage maps for the tracer to record coverage in, which count hits if hit counts are
+// enabled.
+func (t *CoverageTracer) newCoverageMaps() *CoverageMaps {
+	if t.hitCountsEnabled {
+		return newHitCountingCoverageMaps()
+	}
+	return NewCoverageMaps()
+}
+
 // CaptureTxStart is called upon the start of transaction execution, as defined by vm.EVMLogger.
 func (t *CoverageTracer) CaptureTxStart(gasLimit uint64) {
 	// Reset our capture state
 	t.callDepth = 0
-	t.coverageMaps = NewCoverageMaps()
+	t.coverageMaps = t.newCoverageMaps()
 	t.callFrameStates = make([]*coverageTracerCallFrameState, 0)
 	t.cachedCodeHashOriginal = common.Hash{}
 
@@ -90,7 +131,7 @@ func (t *CoverageTracer) CaptureStart(env *vm.EVM, from common.Address, to commo
 	// Create our state tracking struct for this frame.
 	t.callFrameStates = append(t.callFrameStates, &coverageTracerCallFrameState{
 		create:             create,
-		pendingCoverageMap: NewCoverageMaps(),
+		pendingCoverageMap: t.newCoverageMaps(),
 	})
 }
 
@@ -117,7 +158,7 @@ func (t *CoverageTracer) CaptureEnter(typ vm.OpCode, from common.Address, to com
 	// Create our state tracking struct for this frame.
 	t.callFrameStates = append(t.callFrameStates, &coverageTracerCallFrameState{
 		create:             typ == vm.CREATE || typ == vm.CREATE2,
-		pendingCoverageMap: NewCoverageMaps(),
+		pendingCoverageMap: t.newCoverageMaps(),
 	})
 }
 
@@ -152,22 +193,34 @@ func (t *CoverageTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64,
 		// code hash for performance reasons.
 		if t.cachedCodeHashOriginal != scope.Contract.CodeHash {
 			t.cachedCodeHashOriginal = scope.Contract.CodeHash
-			t.cachedCodeHashResolved = t.cachedCodeHashOriginal
-			if metadata := compilationTypes.ExtractContractMetadata(scope.Contract.Code); metadata != nil {
-				if metadataHash := metadata.ExtractBytecodeHash(); metadataHash != nil {
-					t.cachedCodeHashResolved = common.BytesToHash(metadataHash)
-				}
-			}
+			t.cachedCodeHashResolved = resolveCoverageMapCodeHash(scope.Contract.Code, t.cachedCodeHashOriginal)
+		}
+
+		// Coverage is recorded under the address of the code being executed, so code executed through a
+		// DELEGATECALL (e.g. by a proxy) is attributed to the implementation rather than the caller.
+		codeAddress := scope.Contract.Address()
+		if scope.Contract.CodeAddr != nil {
+			codeAddress = *scope.Contract.CodeAddr
 		}
 
 		// If the resolved code hash is not zero (indicating a contract deployment from which we could not extract
 		// a metadata code hash), then we record coverage for this location in our map.
 		zeroHash := common.BigToHash(big.NewInt(0))
 		if t.cachedCodeHashResolved != zeroHash {
-			_, coverageUpdateErr := callFrameState.pendingCoverageMap.SetCoveredAt(scope.Contract.Address(), t.cachedCodeHashResolved, callFrameState.create, len(scope.Contract.Code), pc)
+			_, coverageUpdateErr := callFrameState.pendingCoverageMap.SetCoveredAt(codeAddress, t.cachedCodeHashResolved, callFrameState.create, len(scope.Contract.Code), pc)
 			if coverageUpdateErr != nil {
 				panic(fmt.Sprintf("coverage tracer failed to update coverage map while tracing state: %v", coverageUpdateErr))
 			}
+
+			// If this is a conditional jump, record which edge it will take. The jump is taken if the condition,
+			// the second item on the stack, is non-zero.
+			if t.edgeCoverageEnabled && op == vm.JUMPI {
+				taken := !scope.Stack.Back(1).IsZero()
+				_, coverageUpdateErr = callFrameState.pendingCoverageMap.SetEdgeCoveredAt(codeAddress, t.cachedCodeHashResolved, callFrameState.create, len(scope.Contract.Code), pc, taken)
+				if coverageUpdateErr != nil {
+					panic(fmt.Sprintf("coverage tracer failed to update edge coverage map while tracing state: %v", coverageUpdateErr))
+				}
+			}
 		}
 	}
 }
@@ -180,6 +233,7 @@ func (t *CoverageTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64,
 // tracer is used during transaction execution (block creation), the results can later be queried from the block.
 // This method will only be called on the added tracer if it implements the extended TestChainTracer interface.
 func (t *CoverageTracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
-	// Store our tracer results.
+	// Convert any hit counts to buckets, then store our tracer results.
+	t.coverageMaps.bucketHitCounts()
 	results.AdditionalResults[coverageTracerResultsKey] = t.coverageMaps
 }
@@ -0,0 +1,156 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// loopBytecode describes bytecode which counts down from 100 to zero, executing the JUMPI at loopJumpiPC once without
+// taking its jump and 99 times taking it.
+var loopBytecode = []byte{
+	byte(vm.PUSH1), 0x64, // 0: counter = 100
+	byte(vm.JUMPDEST),    // 2: loop start
+	byte(vm.PUSH1), 0x01, // 3
+	byte(vm.SWAP1),       // 5
+	byte(vm.SUB),         // 6: counter = counter - 1
+	byte(vm.DUP1),        // 7
+	byte(vm.PUSH1), 0x02, // 8
+	byte(vm.JUMPI), // 10: jump to loop start if counter != 0
+	byte(vm.STOP),  // 11
+}
+
+// loopJumpiPC describes the program counter of the JUMPI instruction in loopBytecode.
+const loopJumpiPC = 10
+
+// executeWithCoverageTracer executes the provided bytecode with the provided tracer attached.
+// Returns the coverage maps recorded by the tracer.
+func executeWithCoverageTracer(t testing.TB, tracer *CoverageTracer, code []byte) *CoverageMaps {
+	tracer.CaptureTxStart(0)
+	_, _, err := runtime.Execute(code, nil, &runtime.Config{EVMConfig: vm.Config{Debug: true, Tracer: tracer}})
+	assert.NoError(t, err)
+	return tracer.coverageMaps
+}
+
+// TestCoverageTracerEdgeCoverage ensures the coverage tracer records each outcome of a JUMPI as a separate edge when
+// edge coverage is enabled, and that program counter coverage is recorded regardless.
+func TestCoverageTracerEdgeCoverage(t *testing.T) {
+	// Without edge coverage, only program counters are recorded.
+	tracer := NewCoverageTracer()
+	coverageMaps := executeWithCoverageTracer(t, tracer, loopBytecode)
+	assert.Len(t, coverageMaps.CoveredLocations(), 9)
+	assert.Empty(t, coverageMaps.edgeMaps)
+
+	// With edge coverage, both outcomes of our JUMPI are recorded as edges.
+	tracer.SetEdgeCoverageEnabled(true)
+	coverageMaps = executeWithCoverageTracer(t, tracer, loopBytecode)
+	assert.Len(t, coverageMaps.CoveredLocations(), 9)
+	assert.Len(t, coverageMaps.edgeMaps, 1)
+	for _, edgeMapsByCodeHash := range coverageMaps.edgeMaps {
+		assert.Len(t, edgeMapsByCodeHash, 1)
+		for _, edgeMap := range edgeMapsByCodeHash {
+			for i, covered := range edgeMap.deployedBytecodeCoverageData {
+				assert.EqualValues(t, i == loopJumpiPC*2 || i == loopJumpiPC*2+1, covered != 0)
+			}
+		}
+	}
+
+	// Merging coverage which only reaches a new edge should report an edge coverage change, but no program counter
+	// coverage change.
+	codeAddress, codeHash := common.HexToAddress("0x1234"), common.HexToHash("0x5678")
+	totalCoverageMaps := NewCoverageMaps()
+	_, err := totalCoverageMaps.SetCoveredAt(codeAddress, codeHash, false, 10, 5)
+	assert.NoError(t, err)
+	_, err = totalCoverageMaps.SetEdgeCoveredAt(codeAddress, codeHash, false, 10, 5, false)
+	assert.NoError(t, err)
+	newCoverageMaps := NewCoverageMaps()
+	_, err = newCoverageMaps.SetCoveredAt(codeAddress, codeHash, false, 10, 5)
+	assert.NoError(t, err)
+	_, err = newCoverageMaps.SetEdgeCoveredAt(codeAddress, codeHash, false, 10, 5, true)
+	assert.NoError(t, err)
+	pcCoverageChanged, edgeCoverageChanged, err := totalCoverageMaps.UpdateWithChanges(newCoverageMaps)
+	assert.NoError(t, err)
+	assert.False(t, pcCoverageChanged)
+	assert.True(t, edgeCoverageChanged)
+	assert.False(t, CoverageFeedbackPC.CoverageChanged(pcCoverageChanged, edgeCoverageChanged))
+	assert.True(t, CoverageFeedbackEdge.CoverageChanged(pcCoverageChanged, edgeCoverageChanged))
+	assert.True(t, CoverageFeedbackBoth.CoverageChanged(pcCoverageChanged, edgeCoverageChanged))
+
+	// Merging the same coverage again should report no change.
+	pcCoverageChanged, edgeCoverageChanged, err = totalCoverageMaps.UpdateWithChanges(newCoverageMaps)
+	assert.NoError(t, err)
+	assert.False(t, pcCoverageChanged)
+	assert.False(t, edgeCoverageChanged)
+}
+
+// TestCoverageTracerDelegateCall ensures the coverage tracer attributes code executed through a DELEGATECALL to the
+// address and code hash of the code executed, rather than those of the calling contract (e.g. a proxy).
+func TestCoverageTracerDelegateCall(t *testing.T) {
+	// Our implementation is our loop, which our caller executes through a DELEGATECALL.
+	implementationAddress := common.HexToAddress("0x1234")
+	callerCode := []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00,
+		byte(vm.PUSH20),
+	}
+	callerCode = append(callerCode, implementationAddress.Bytes()...)
+	callerCode = append(callerCode, byte(vm.GAS), byte(vm.DELEGATECALL), byte(vm.POP), byte(vm.STOP))
+
+	stateDB, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	assert.NoError(t, err)
+	stateDB.SetCode(implementationAddress, loopBytecode)
+	tracer := NewCoverageTracer()
+	tracer.CaptureTxStart(0)
+	_, _, err = runtime.Execute(callerCode, nil, &runtime.Config{State: stateDB, EVMConfig: vm.Config{Debug: true, Tracer: tracer}})
+	assert.NoError(t, err)
+
+	// Coverage of our loop should be recorded under our implementation, separately from our caller.
+	callerAddress := common.BytesToAddress([]byte("contract"))
+	implementationCoverage := tracer.coverageMaps.maps[implementationAddress][crypto.Keccak256Hash(loopBytecode)]
+	if assert.NotNil(t, implementationCoverage) {
+		coveredCount := 0
+		for _, covered := range implementationCoverage.deployedBytecodeCoverageData {
+			if covered != 0 {
+				coveredCount++
+			}
+		}
+		assert.EqualValues(t, 9, coveredCount)
+	}
+	assert.NotNil(t, tracer.coverageMaps.maps[callerAddress][crypto.Keccak256Hash(callerCode)])
+	assert.Len(t, tracer.coverageMaps.maps[callerAddress], 1)
+}
+
+// benchmarkCoverageTracer measures the overhead of executing loopBytecode with a coverage tracer attached, recording
+// edge coverage if requested.
+func benchmarkCoverageTracer(b *testing.B, edgeCoverageEnabled bool) {
+	tracer := NewCoverageTracer()
+	tracer.SetEdgeCoverageEnabled(edgeCoverageEnabled)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		executeWithCoverageTracer(b, tracer, loopBytecode)
+	}
+}
+
+// BenchmarkCoverageTracerPC measures the overhead of recording program counter coverage.
+func BenchmarkCoverageTracerPC(b *testing.B) {
+	benchmarkCoverageTracer(b, false)
+}
+
+// BenchmarkCoverageTracerPCAndEdge measures the overhead of recording both program counter and edge coverage.
+func BenchmarkCoverageTracerPCAndEdge(b *testing.B) {
+	benchmarkCoverageTracer(b, true)
+}
+
+// BenchmarkNoTracer measures the execution of loopBytecode without a tracer, as a baseline for the coverage tracer
+// benchmarks.
+func BenchmarkNoTracer(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _, err := runtime.Execute(loopBytecode, nil, nil)
+		assert.NoError(b, err)
+	}
+}
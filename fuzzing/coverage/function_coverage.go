@@ -0,0 +1,61 @@
+package coverage
+
+import (
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// InstructionCoverage describes how many of a set of instructions were executed.
+type InstructionCoverage struct {
+	// Covered describes the amount of instructions which were executed.
+	Covered int `json:"covered"`
+
+	// Total describes the total amount of instructions.
+	Total int `json:"total"`
+}
+
+// Percentage returns the percentage of instructions which were executed, or zero if there are no instructions.
+func (c InstructionCoverage) Percentage() float64 {
+	if c.Total == 0 {
+		return 0
+	}
+	return float64(c.Covered) * 100 / float64(c.Total)
+}
+
+// AnalyzeFunctionCoverage determines the instruction coverage of each function of the provided compiled contract's
+// runtime bytecode, given the source ranges of its function definitions (as returned by
+// types.Compilation.GetFunctionSourceRanges). An instruction belongs to a function if its source map element lies
+// within the function's source range.
+// Returns a mapping of the keys of the provided function source ranges to their instruction coverage, or an error if
+// one occurs.
+func AnalyzeFunctionCoverage(contract *types.CompiledContract, functionRanges map[string]types.SourceRange, coverageMaps *CoverageMaps) (map[string]InstructionCoverage, error) {
+	sourceMap, err := types.ParseSourceMap(contract.SrcMapsRuntime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Obtain the coverage recorded for the runtime bytecode.
+	codeHash := resolveCoverageMapCodeHash(contract.RuntimeBytecode, crypto.Keccak256Hash(contract.RuntimeBytecode))
+	pcCoverageData, _ := coverageMaps.codeHashCoverageData(codeHash, false)
+
+	// Attribute each instruction to the function which contains it.
+	results := make(map[string]InstructionCoverage, len(functionRanges))
+	for key := range functionRanges {
+		results[key] = InstructionCoverage{}
+	}
+	instructionPCs := types.GetInstructionPCs(contract.RuntimeBytecode)
+	for i := 0; i < len(sourceMap) && i < len(instructionPCs); i++ {
+		for key, functionRange := range functionRanges {
+			if !functionRange.Contains(sourceMap[i]) {
+				continue
+			}
+			functionCoverage := results[key]
+			functionCoverage.Total++
+			if pc := instructionPCs[i]; pc < uint64(len(pcCoverageData)) && pcCoverageData[pc] != 0 {
+				functionCoverage.Covered++
+			}
+			results[key] = functionCoverage
+		}
+	}
+	return results, nil
+}
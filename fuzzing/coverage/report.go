@@ -0,0 +1,77 @@
+package coverage
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/crytic/medusa/utils"
+)
+
+// ReportFormat describes a file format a coverage report can be written in.
+type ReportFormat string
+
+const (
+	// ReportFormatHTML indicates an HTML report, which renders each source file with its covered and uncovered lines
+	// highlighted.
+	ReportFormatHTML ReportFormat = "html"
+
+	// ReportFormatLCOV indicates an LCOV tracefile, which most coverage tooling can consume or merge with other
+	// reports.
+	ReportFormatLCOV ReportFormat = "lcov"
+
+	// ReportFormatSnapshot indicates a CoverageSnapshot, which can be compared with the snapshot of another campaign
+	// using the "coverage diff" command.
+	ReportFormatSnapshot ReportFormat = "snapshot"
+)
+
+// IsValid indicates whether the ReportFormat is one of the known report formats.
+func (f ReportFormat) IsValid() bool {
+	return f == ReportFormatHTML || f == ReportFormatLCOV || f == ReportFormatSnapshot
+}
+
+// fileName returns the name of the file a report of this format is written to.
+func (f ReportFormat) fileName() string {
+	switch f {
+	case ReportFormatLCOV:
+		return "lcov.info"
+	case ReportFormatSnapshot:
+		return "coverage.cov"
+	default:
+		return "coverage_report.html"
+	}
+}
+
+// WriteReports writes a coverage report of the provided source analysis to the provided directory for each of the
+// provided formats.
+// Returns the paths of the reports written, or an error if one occurs.
+func WriteReports(analysis *SourceAnalysis, directory string, formats []ReportFormat) ([]string, error) {
+	// If there are no reports to write, do nothing.
+	if len(formats) == 0 {
+		return nil, nil
+	}
+	err := utils.MakeDirectory(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write each report.
+	reportPaths := make([]string, 0, len(formats))
+	for _, format := range formats {
+		reportPath := filepath.Join(directory, format.fileName())
+		switch format {
+		case ReportFormatHTML:
+			err = WriteHTMLReport(analysis, reportPath)
+		case ReportFormatLCOV:
+			err = WriteLCOVReport(analysis, reportPath)
+		case ReportFormatSnapshot:
+			err = WriteCoverageSnapshot(NewCoverageSnapshot(analysis), reportPath)
+		default:
+			err = fmt.Errorf("unknown coverage report format '%v'", format)
+		}
+		if err != nil {
+			return reportPaths, err
+		}
+		reportPaths = append(reportPaths, reportPath)
+	}
+	return reportPaths, nil
+}
@@ -0,0 +1,76 @@
+package coverage
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/crytic/medusa/utils"
+)
+
+// htmlReportTemplate describes the template used to render HTML coverage reports from a SourceAnalysis.
+var htmlReportTemplate = template.Must(template.New("coverage_report").Funcs(template.FuncMap{
+	"percentage": func(covered int, active int) float64 {
+		if active == 0 {
+			return 0
+		}
+		return float64(covered) * 100 / float64(active)
+	},
+	"lineClass": func(line *SourceLineAnalysis) string {
+		if !line.IsActive {
+			return ""
+		}
+		if line.IsCovered {
+			return "covered"
+		}
+		return "uncovered"
+	},
+	"inc": func(i int) int {
+		return i + 1
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage Report</title>
+<style>
+body { font-family: sans-serif; }
+table.summary td, table.summary th { padding: 2px 12px; text-align: left; }
+table.source { border-collapse: collapse; font-family: monospace; white-space: pre; }
+table.source td { padding: 0 8px; }
+td.line-number { color: #888; text-align: right; }
+tr.covered { background-color: #d7f5d7; }
+tr.uncovered { background-color: #f7d4d4; }
+</style>
+</head>
+<body>
+<h1>Coverage Report</h1>
+<p>Lines covered: {{.CoveredLineCount}} / {{.ActiveLineCount}} ({{printf "%.1f" (percentage .CoveredLineCount .ActiveLineCount)}}%)</p>
+<table class="summary">
+<tr><th>File</th><th>Lines covered</th><th>Coverage</th></tr>
+{{- range $i, $file := .SortedFiles}}
+<tr><td><a href="#file-{{$i}}">{{$file.Path}}</a></td><td>{{$file.CoveredLineCount}} / {{$file.ActiveLineCount}}</td><td>{{printf "%.1f" (percentage $file.CoveredLineCount $file.ActiveLineCount)}}%</td></tr>
+{{- end}}
+</table>
+{{- range $i, $file := .SortedFiles}}
+<h2 id="file-{{$i}}">{{$file.Path}}</h2>
+<table class="source">
+{{- range $lineIndex, $line := $file.Lines}}
+<tr class="{{lineClass $line}}"><td class="line-number">{{inc $lineIndex}}</td><td>{{printf "%s" $line.Contents}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTMLReport writes an HTML report rendering the source files of the provided source analysis to the provided
+// file path, with a summary of the line coverage of each file.
+// Returns an error if one occurs.
+func WriteHTMLReport(analysis *SourceAnalysis, filePath string) error {
+	var buffer bytes.Buffer
+	err := htmlReportTemplate.Execute(&buffer, analysis)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(filePath, buffer.Bytes(), 0644)
+}
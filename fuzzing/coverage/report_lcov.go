@@ -0,0 +1,61 @@
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/crytic/medusa/utils"
+)
+
+// Reference: LCOV tracefile format
+// https://manpages.debian.org/unstable/lcov/geninfo.1.en.html#FILES
+
+// WriteLCOVReport writes an LCOV tracefile describing the provided source analysis to the provided file path. Each
+// active line is recorded with an execution count of one if it was covered, or zero otherwise. If branch edge
+// coverage was recorded, each conditional jump is recorded as a block with a branch for each of its outcomes.
+// Returns an error if one occurs.
+func WriteLCOVReport(analysis *SourceAnalysis, filePath string) error {
+	var buffer bytes.Buffer
+	buffer.WriteString("TN:\n")
+	for _, file := range analysis.SortedFiles() {
+		fmt.Fprintf(&buffer, "SF:%v\n", file.Path)
+
+		// Write the branch records of each line, if branch coverage was recorded.
+		branchesFound, branchesHit := 0, 0
+		if analysis.BranchesRecorded {
+			for lineIndex, line := range file.Lines {
+				for blockIndex, branch := range line.Branches {
+					for branchIndex, hit := range []bool{branch.NotTaken, branch.Taken} {
+						// A branch of a conditional jump which was never executed is recorded as "-".
+						taken := "-"
+						if branch.Executed {
+							taken = "0"
+							if hit {
+								taken = "1"
+								branchesHit++
+							}
+						}
+						fmt.Fprintf(&buffer, "BRDA:%d,%d,%d,%v\n", lineIndex+1, blockIndex, branchIndex, taken)
+						branchesFound++
+					}
+				}
+			}
+			fmt.Fprintf(&buffer, "BRF:%d\nBRH:%d\n", branchesFound, branchesHit)
+		}
+
+		// Write the line records of each active line.
+		for lineIndex, line := range file.Lines {
+			if !line.IsActive {
+				continue
+			}
+			executionCount := 0
+			if line.IsCovered {
+				executionCount = 1
+			}
+			fmt.Fprintf(&buffer, "DA:%d,%d\n", lineIndex+1, executionCount)
+		}
+		fmt.Fprintf(&buffer, "LF:%d\nLH:%d\n", file.ActiveLineCount(), file.CoveredLineCount())
+		buffer.WriteString("end_of_record\n")
+	}
+	return utils.WriteFileAtomic(filePath, buffer.Bytes(), 0644)
+}
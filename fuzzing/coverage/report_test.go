@@ -0,0 +1,123 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCoverageReports ensures coverage recorded by the coverage tracer is mapped to source lines through source maps,
+// and written as expected in LCOV and HTML reports.
+func TestCoverageReports(t *testing.T) {
+	// Create a source file with a line for each statement of our bytecode.
+	directory := t.TempDir()
+	sourcePath := filepath.Join(directory, "TestContract.sol")
+	err := os.WriteFile(sourcePath, []byte("a;\nb;\nc;\nd;\n"), 0644)
+	assert.NoError(t, err)
+
+	// Create bytecode which takes its only conditional jump, skipping the third line, and a compilation mapping it to
+	// our source file.
+	bytecode := []byte{
+		byte(vm.PUSH1), 0x01, // 0: line 1
+		byte(vm.PUSH1), 0x06, // 2: line 2
+		byte(vm.JUMPI),    // 4: line 2
+		byte(vm.STOP),     // 5: line 3
+		byte(vm.JUMPDEST), // 6: line 4
+		byte(vm.STOP),     // 7: line 4
+	}
+	compilation := types.NewCompilation()
+	compilation.Sources[sourcePath] = types.CompiledSource{
+		Ast: map[string]any{"src": "0:12:0"},
+		Contracts: map[string]types.CompiledContract{
+			"TestContract": {RuntimeBytecode: bytecode, SrcMapsRuntime: "0:2:0;3:2;;6:2;9:2;"},
+		},
+	}
+
+	// Record coverage of our bytecode, including edges, and analyze it.
+	tracer := NewCoverageTracer()
+	tracer.SetEdgeCoverageEnabled(true)
+	coverageMaps := executeWithCoverageTracer(t, tracer, bytecode)
+	analysis, err := AnalyzeSourceCoverage([]types.Compilation{*compilation}, coverageMaps, SourcePathFilter{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, analysis.ActiveLineCount())
+	assert.EqualValues(t, 3, analysis.CoveredLineCount())
+
+	// Verify our LCOV report.
+	reportPaths, err := WriteReports(analysis, filepath.Join(directory, "coverage"), []ReportFormat{ReportFormatLCOV, ReportFormatHTML})
+	assert.NoError(t, err)
+	assert.Len(t, reportPaths, 2)
+	lcovReport, err := os.ReadFile(reportPaths[0])
+	assert.NoError(t, err)
+	assert.EqualValues(t, "TN:\nSF:"+sourcePath+"\nBRDA:2,0,0,0\nBRDA:2,0,1,1\nBRF:2\nBRH:1\n"+
+		"DA:1,1\nDA:2,1\nDA:3,0\nDA:4,1\nLF:4\nLH:3\nend_of_record\n", string(lcovReport))
+
+	// Verify our HTML report renders our source file.
+	htmlReport, err := os.ReadFile(reportPaths[1])
+	assert.NoError(t, err)
+	assert.Contains(t, string(htmlReport), sourcePath)
+	assert.Contains(t, string(htmlReport), "Lines covered: 3 / 4")
+
+	// Filtered source files should be omitted from the analysis.
+	analysis, err = AnalyzeSourceCoverage([]types.Compilation{*compilation}, coverageMaps, SourcePathFilter{Exclude: []string{"TestContract.sol"}})
+	assert.NoError(t, err)
+	assert.Empty(t, analysis.Files)
+}
+
+// TestFunctionCoverage ensures the instructions of each function definition resolved from a contract's AST are
+// attributed to it, and their coverage is counted.
+func TestFunctionCoverage(t *testing.T) {
+	// Create bytecode which skips its fourth instruction, and a compilation with a function spanning its last three.
+	bytecode := []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x06, byte(vm.JUMPI), byte(vm.STOP), byte(vm.JUMPDEST), byte(vm.STOP)}
+	compilation := types.NewCompilation()
+	compilation.Sources["TestContract.sol"] = types.CompiledSource{
+		Ast: map[string]any{
+			"id":       float64(1),
+			"nodeType": "SourceUnit",
+			"src":      "0:12:0",
+			"nodes": []any{map[string]any{
+				"id":                      float64(2),
+				"nodeType":                "ContractDefinition",
+				"name":                    "TestContract",
+				"linearizedBaseContracts": []any{float64(2)},
+				"nodes": []any{map[string]any{
+					"id":               float64(3),
+					"nodeType":         "FunctionDefinition",
+					"functionSelector": "12345678",
+					"implemented":      true,
+					"src":              "6:5:0",
+				}},
+			}},
+		},
+		Contracts: map[string]types.CompiledContract{
+			"TestContract": {RuntimeBytecode: bytecode, SrcMapsRuntime: "0:2:0;3:2;;6:2;9:2;"},
+		},
+	}
+	functionRanges := compilation.GetFunctionSourceRanges("TestContract.sol", "TestContract")
+	assert.EqualValues(t, map[string]types.SourceRange{"12345678": {Offset: 6, Length: 5, SourceUnitID: 0}}, functionRanges)
+
+	// Record coverage of our bytecode and verify the coverage of our function.
+	coverageMaps := executeWithCoverageTracer(t, NewCoverageTracer(), bytecode)
+	contract := compilation.Sources["TestContract.sol"].Contracts["TestContract"]
+	functionCoverage, err := AnalyzeFunctionCoverage(&contract, functionRanges, coverageMaps)
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[string]InstructionCoverage{"12345678": {Covered: 2, Total: 3}}, functionCoverage)
+}
+
+// TestSourcePathFilter ensures source path filters include and exclude the expected source paths.
+func TestSourcePathFilter(t *testing.T) {
+	filter := SourcePathFilter{Exclude: []string{"node_modules", "lib/"}}
+	assert.True(t, filter.Matches("contracts/Token.sol"))
+	assert.False(t, filter.Matches("node_modules/@openzeppelin/contracts/token/ERC20.sol"))
+	assert.False(t, filter.Matches("lib/forge-std/src/Test.sol"))
+	assert.True(t, filter.Matches("contracts/lib.sol"))
+
+	filter = SourcePathFilter{Include: []string{"src/*"}, Exclude: []string{"*.t.sol"}}
+	assert.True(t, filter.Matches("src/Token.sol"))
+	assert.True(t, filter.Matches("src/tokens/Token.sol"))
+	assert.False(t, filter.Matches("src/Token.t.sol"))
+	assert.False(t, filter.Matches("test/Token.sol"))
+}
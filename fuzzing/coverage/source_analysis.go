@@ -0,0 +1,371 @@
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SourceAnalysis describes the coverage of source files, derived from the coverage of the bytecode compiled from them.
+type SourceAnalysis struct {
+	// Files describes the analysis of each source file, keyed by source path, across all contracts.
+	Files map[string]*SourceFileAnalysis
+
+	// Contracts describes the analysis of the source files of each compiled contract alone, sorted by source path
+	// and name. Contracts which no analyzed source file lines map to are omitted.
+	Contracts []*ContractSourceAnalysis
+
+	// BranchesRecorded describes whether branch edge coverage was recorded, in which case SourceLineAnalysis.Branches
+	// describes the branches of each line.
+	BranchesRecorded bool
+}
+
+// ContractSourceAnalysis describes the coverage of source files achieved by the bytecode of a single compiled contract.
+type ContractSourceAnalysis struct {
+	// SourcePath describes the path of the source file which defines the contract.
+	SourcePath string
+
+	// Name describes the name of the contract.
+	Name string
+
+	// Files describes the analysis of each source file the contract's bytecode maps to, keyed by source path. Only
+	// lines which the contract's bytecode maps to are active.
+	Files map[string]*SourceFileAnalysis
+}
+
+// SourceFileAnalysis describes the coverage of a single source file.
+type SourceFileAnalysis struct {
+	// Path describes the path of the source file.
+	Path string
+
+	// ContentHash describes the hash of the contents of the source file, which identifies whether line numbers refer
+	// to the same source.
+	ContentHash common.Hash
+
+	// Lines describes the analysis of each line of the source file, in order.
+	Lines []*SourceLineAnalysis
+}
+
+// SourceLineAnalysis describes the coverage of a single line of a source file.
+type SourceLineAnalysis struct {
+	// Start describes the byte offset of the start of the line within the source file.
+	Start int
+
+	// End describes the byte offset of the end of the line within the source file, excluding the line ending.
+	End int
+
+	// Contents describes the contents of the line, excluding the line ending.
+	Contents []byte
+
+	// IsActive describes whether any instruction in compiled bytecode maps to the line.
+	IsActive bool
+
+	// IsCovered describes whether any instruction which maps to the line was executed.
+	IsCovered bool
+
+	// Branches describes the conditional jumps which map to the line, if branch edge coverage was recorded.
+	Branches []*SourceBranchAnalysis
+}
+
+// SourceBranchAnalysis describes the coverage of both outcomes of a conditional jump (JUMPI) instruction.
+type SourceBranchAnalysis struct {
+	// Executed describes whether the conditional jump was executed at all.
+	Executed bool
+
+	// Taken describes whether the conditional jump was executed and took the jump.
+	Taken bool
+
+	// NotTaken describes whether the conditional jump was executed without taking the jump.
+	NotTaken bool
+}
+
+// SortedFiles returns the analysis of each source file, sorted by source path.
+func (s *SourceAnalysis) SortedFiles() []*SourceFileAnalysis {
+	files := make([]*SourceFileAnalysis, 0, len(s.Files))
+	for _, file := range s.Files {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
+// ActiveLineCount returns the amount of lines across all source files which any instruction maps to.
+func (s *SourceAnalysis) ActiveLineCount() int {
+	count := 0
+	for _, file := range s.Files {
+		count += file.ActiveLineCount()
+	}
+	return count
+}
+
+// CoveredLineCount returns the amount of lines across all source files which were covered.
+func (s *SourceAnalysis) CoveredLineCount() int {
+	count := 0
+	for _, file := range s.Files {
+		count += file.CoveredLineCount()
+	}
+	return count
+}
+
+// ActiveLineCount returns the amount of lines in the source file which any instruction maps to.
+func (s *SourceFileAnalysis) ActiveLineCount() int {
+	count := 0
+	for _, line := range s.Lines {
+		if line.IsActive {
+			count++
+		}
+	}
+	return count
+}
+
+// CoveredLineCount returns the amount of lines in the source file which were covered.
+func (s *SourceFileAnalysis) CoveredLineCount() int {
+	count := 0
+	for _, line := range s.Lines {
+		if line.IsCovered {
+			count++
+		}
+	}
+	return count
+}
+
+// lineAtOffset obtains the line of the source file which contains the provided byte offset.
+// Returns the line, or nil if the offset is outside the source file.
+func (s *SourceFileAnalysis) lineAtOffset(offset int) *SourceLineAnalysis {
+	// Find the first line which ends at or after our offset.
+	i := sort.Search(len(s.Lines), func(i int) bool {
+		return s.Lines[i].End >= offset
+	})
+	if i == len(s.Lines) || s.Lines[i].Start > offset {
+		return nil
+	}
+	return s.Lines[i]
+}
+
+// newSourceFileAnalysis creates a SourceFileAnalysis for the source file with the provided path and contents, in
+// which no line is active yet.
+func newSourceFileAnalysis(sourcePath string, contents []byte) *SourceFileAnalysis {
+	fileAnalysis := &SourceFileAnalysis{
+		Path:        sourcePath,
+		ContentHash: crypto.Keccak256Hash(contents),
+		Lines:       make([]*SourceLineAnalysis, 0),
+	}
+	start := 0
+	for _, line := range bytes.SplitAfter(contents, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		lineContents := bytes.TrimRight(line, "\r\n")
+		fileAnalysis.Lines = append(fileAnalysis.Lines, &SourceLineAnalysis{
+			Start:    start,
+			End:      start + len(lineContents),
+			Contents: lineContents,
+		})
+		start += len(line)
+	}
+	return fileAnalysis
+}
+
+// cloneInactive creates a copy of the SourceFileAnalysis in which no line is active yet.
+func (s *SourceFileAnalysis) cloneInactive() *SourceFileAnalysis {
+	clone := &SourceFileAnalysis{
+		Path:        s.Path,
+		ContentHash: s.ContentHash,
+		Lines:       make([]*SourceLineAnalysis, len(s.Lines)),
+	}
+	for i, line := range s.Lines {
+		clone.Lines[i] = &SourceLineAnalysis{Start: line.Start, End: line.End, Contents: line.Contents}
+	}
+	return clone
+}
+
+// SourcePathFilter describes patterns which determine which source files are included in coverage reports (e.g. to
+// exclude dependencies in node_modules or lib). A pattern without a slash matches any directory or file name in a
+// source path, while a pattern with a slash matches the source path or any of its parent directories. Patterns
+// follow the syntax of path.Match.
+type SourcePathFilter struct {
+	// Include describes patterns of which a source path must match at least one to be included. If empty, every
+	// source path is included, unless it is excluded.
+	Include []string
+
+	// Exclude describes patterns of which a source path must match none to be included.
+	Exclude []string
+}
+
+// Matches indicates whether the provided source path is included by the filter.
+func (f SourcePathFilter) Matches(sourcePath string) bool {
+	sourcePath = filepath.ToSlash(filepath.Clean(sourcePath))
+	if len(f.Include) > 0 && !sourcePathMatchesAnyPattern(sourcePath, f.Include) {
+		return false
+	}
+	return !sourcePathMatchesAnyPattern(sourcePath, f.Exclude)
+}
+
+// sourcePathMatchesAnyPattern indicates whether the provided slash separated source path matches any of the provided
+// SourcePathFilter patterns.
+func sourcePathMatchesAnyPattern(sourcePath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if !strings.Contains(pattern, "/") {
+			// Match any directory or file name in the path.
+			for _, name := range strings.Split(sourcePath, "/") {
+				if matched, _ := path.Match(pattern, name); matched {
+					return true
+				}
+			}
+			continue
+		}
+
+		// Match the path or any of its parent directories.
+		for candidate := sourcePath; candidate != "." && candidate != "/"; candidate = path.Dir(candidate) {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AnalyzeSourceCoverage maps the coverage recorded in the provided coverage maps to lines of the source files of the
+// provided compilations, using the source maps of each compiled contract. Each instruction is attributed to the line
+// its source range starts on. Source files which the provided filter does not match are omitted.
+// Returns the SourceAnalysis, or an error if one occurs.
+func AnalyzeSourceCoverage(compilations []types.Compilation, coverageMaps *CoverageMaps, filter SourcePathFilter) (*SourceAnalysis, error) {
+	analysis := &SourceAnalysis{
+		Files:            make(map[string]*SourceFileAnalysis),
+		Contracts:        make([]*ContractSourceAnalysis, 0),
+		BranchesRecorded: coverageMaps.edgeCoverageRecorded(),
+	}
+
+	for _, compilation := range compilations {
+		// Determine the source files referenced by each source unit ID in this compilation's source maps, reading
+		// the contents of those we have not yet.
+		sourcePathsBySourceUnitID := make(map[int]string)
+		for sourcePath, source := range compilation.Sources {
+			if !filter.Matches(sourcePath) {
+				continue
+			}
+			sourceUnitID, err := types.GetSourceUnitID(source.Ast)
+			if err != nil {
+				return nil, fmt.Errorf("could not analyze coverage of source file '%v': %v", sourcePath, err)
+			}
+			if _, ok := analysis.Files[sourcePath]; !ok {
+				contents, err := os.ReadFile(sourcePath)
+				if err != nil {
+					return nil, fmt.Errorf("could not analyze coverage of source file '%v': %v", sourcePath, err)
+				}
+				analysis.Files[sourcePath] = newSourceFileAnalysis(sourcePath, contents)
+			}
+			sourcePathsBySourceUnitID[sourceUnitID] = sourcePath
+		}
+
+		// Attribute the coverage of each contract's init and runtime bytecode to source lines, both across all
+		// contracts and for the contract alone.
+		for sourcePath, source := range compilation.Sources {
+			for contractName, contract := range source.Contracts {
+				contractAnalysis := &ContractSourceAnalysis{
+					SourcePath: sourcePath,
+					Name:       contractName,
+					Files:      make(map[string]*SourceFileAnalysis),
+				}
+				fileLookups := []func(int) *SourceFileAnalysis{
+					func(sourceUnitID int) *SourceFileAnalysis {
+						return analysis.Files[sourcePathsBySourceUnitID[sourceUnitID]]
+					},
+					func(sourceUnitID int) *SourceFileAnalysis {
+						filePath, ok := sourcePathsBySourceUnitID[sourceUnitID]
+						if !ok {
+							return nil
+						}
+						fileAnalysis, ok := contractAnalysis.Files[filePath]
+						if !ok {
+							fileAnalysis = analysis.Files[filePath].cloneInactive()
+							contractAnalysis.Files[filePath] = fileAnalysis
+						}
+						return fileAnalysis
+					},
+				}
+				err := analysis.analyzeBytecode(fileLookups, coverageMaps, contract.InitBytecode, contract.SrcMapsInit, true)
+				if err == nil {
+					err = analysis.analyzeBytecode(fileLookups, coverageMaps, contract.RuntimeBytecode, contract.SrcMapsRuntime, false)
+				}
+				if err != nil {
+					return nil, fmt.Errorf("could not analyze coverage of contract '%v' in '%v': %v", contractName, sourcePath, err)
+				}
+				if len(contractAnalysis.Files) > 0 {
+					analysis.Contracts = append(analysis.Contracts, contractAnalysis)
+				}
+			}
+		}
+	}
+
+	// Sort our contract analyses so they are reported in a stable order.
+	sort.Slice(analysis.Contracts, func(i, j int) bool {
+		if analysis.Contracts[i].SourcePath != analysis.Contracts[j].SourcePath {
+			return analysis.Contracts[i].SourcePath < analysis.Contracts[j].SourcePath
+		}
+		return analysis.Contracts[i].Name < analysis.Contracts[j].Name
+	})
+	return analysis, nil
+}
+
+// analyzeBytecode attributes the coverage of the provided init or runtime bytecode to the lines of the source files
+// provided by each of the provided lookups, which obtain the source file for a source unit ID (or nil if it is not
+// analyzed), using the provided source map.
+// Returns an error if one occurs.
+func (s *SourceAnalysis) analyzeBytecode(fileLookups []func(int) *SourceFileAnalysis, coverageMaps *CoverageMaps, bytecode []byte, sourceMapString string, init bool) error {
+	// If there is no bytecode (e.g. an interface or abstract contract), there is nothing to analyze.
+	if len(bytecode) == 0 {
+		return nil
+	}
+	sourceMap, err := types.ParseSourceMap(sourceMapString)
+	if err != nil {
+		return err
+	}
+
+	// Obtain the coverage recorded for this bytecode, which is recorded under the same code hash as the coverage
+	// tracer would resolve for it.
+	codeHash := resolveCoverageMapCodeHash(bytecode, crypto.Keccak256Hash(bytecode))
+	pcCoverageData, edgeCoverageData := coverageMaps.codeHashCoverageData(codeHash, init)
+
+	// Attribute each instruction to the line its source range starts on.
+	instructionPCs := types.GetInstructionPCs(bytecode)
+	for i := 0; i < len(sourceMap) && i < len(instructionPCs); i++ {
+		pc := instructionPCs[i]
+		executed := pc < uint64(len(pcCoverageData)) && pcCoverageData[pc] != 0
+		for _, fileLookup := range fileLookups {
+			fileAnalysis := fileLookup(sourceMap[i].SourceUnitID)
+			if fileAnalysis == nil {
+				continue
+			}
+			line := fileAnalysis.lineAtOffset(sourceMap[i].Offset)
+			if line == nil {
+				continue
+			}
+			line.IsActive = true
+			line.IsCovered = line.IsCovered || executed
+
+			// Record the outcomes of conditional jumps, if branch edge coverage was recorded.
+			if s.BranchesRecorded && vm.OpCode(bytecode[pc]) == vm.JUMPI {
+				branch := &SourceBranchAnalysis{Executed: executed}
+				if pc*2+1 < uint64(len(edgeCoverageData)) {
+					branch.NotTaken = edgeCoverageData[pc*2] != 0
+					branch.Taken = edgeCoverageData[pc*2+1] != 0
+				}
+				line.Branches = append(line.Branches, branch)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package create2tracer
+
+import (
+	"math/big"
+
+	"github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// create2TracerResultsKey describes the key to use when storing tracer results in call message results, or when
+// querying them.
+const create2TracerResultsKey = "Create2TracerResults"
+
+// GetCreate2TracerResults obtains the addresses of contracts created through CREATE2 stored by a Create2Tracer from
+// message results. This is nil if no addresses were recorded by a tracer (e.g. Create2Tracer was not attached during
+// this message execution).
+func GetCreate2TracerResults(messageResults *types.MessageResults) []common.Address {
+	// Try to obtain the results the tracer should've stored.
+	if genericResult, ok := messageResults.AdditionalResults[create2TracerResultsKey]; ok {
+		if castedResult, ok := genericResult.([]common.Address); ok {
+			return castedResult
+		}
+	}
+
+	// If we could not obtain them, return nil.
+	return nil
+}
+
+// RemoveCreate2TracerResults removes the addresses stored by a Create2Tracer from message results.
+func RemoveCreate2TracerResults(messageResults *types.MessageResults) {
+	delete(messageResults.AdditionalResults, create2TracerResultsKey)
+}
+
+// Create2Tracer implements vm.EVMLogger to compute the address of every contract created through a CREATE2
+// instruction executed during a transaction, from the creating contract, salt and init bytecode. Addresses are
+// recorded even if the creation fails (e.g. the constructor reverts), as CREATE2 addresses do not depend on the state
+// of the chain, so they can be provided as inputs in later calls, before the contract exists.
+type Create2Tracer struct {
+	// addresses describes the unique addresses recorded for the current transaction, in the order they were recorded.
+	addresses []common.Address
+
+	// addressSet describes the addresses recorded for the current transaction, used to de-duplicate them.
+	addressSet map[common.Address]struct{}
+}
+
+// NewCreate2Tracer returns a new Create2Tracer.
+func NewCreate2Tracer() *Create2Tracer {
+	tracer := &Create2Tracer{}
+	tracer.CaptureTxStart(0)
+	return tracer
+}
+
+// CaptureTxStart is called upon the start of transaction execution, as defined by vm.EVMLogger.
+func (t *Create2Tracer) CaptureTxStart(gasLimit uint64) {
+	// Reset our capture state
+	t.addresses = make([]common.Address, 0)
+	t.addressSet = make(map[common.Address]struct{})
+}
+
+// CaptureTxEnd is called upon the end of transaction execution, as defined by vm.EVMLogger.
+func (t *Create2Tracer) CaptureTxEnd(restGas uint64) {
+}
+
+// CaptureStart initializes the tracing operation for the top of a call frame, as defined by vm.EVMLogger.
+func (t *Create2Tracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureEnd is called after a call to finalize tracing completes for the top of a call frame, as defined by vm.EVMLogger.
+func (t *Create2Tracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureEnter is called upon entering of the call frame, as defined by vm.EVMLogger.
+func (t *Create2Tracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit is called upon exiting of the call frame, as defined by vm.EVMLogger.
+func (t *Create2Tracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureState records data from an EVM state update, as defined by vm.EVMLogger.
+func (t *Create2Tracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, vmErr error) {
+	// We only compute addresses for CREATE2 instructions whose gas was paid, so their operands are bounded.
+	if op != vm.CREATE2 || vmErr != nil || len(scope.Stack.Data()) < 4 {
+		return
+	}
+
+	// Read the init bytecode from memory. The memory it occupies may not have been expanded yet, in which case the
+	// unexpanded memory is treated as zeros, as the instruction will do.
+	offset, size, salt := scope.Stack.Back(1), scope.Stack.Back(2), scope.Stack.Back(3)
+	if !offset.IsUint64() || !size.IsUint64() {
+		return
+	}
+	initBytecode := make([]byte, size.Uint64())
+	if size.Uint64() > 0 && offset.Uint64() < uint64(scope.Memory.Len()) {
+		copy(initBytecode, scope.Memory.Data()[offset.Uint64():])
+	}
+
+	// Compute and record the address of the contract to be created.
+	address := crypto.CreateAddress2(scope.Contract.Address(), salt.Bytes32(), crypto.Keccak256(initBytecode))
+	if _, exists := t.addressSet[address]; !exists {
+		t.addressSet[address] = struct{}{}
+		t.addresses = append(t.addresses, address)
+	}
+}
+
+// CaptureFault records an execution fault, as defined by vm.EVMLogger.
+func (t *Create2Tracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureTxEndSetAdditionalResults can be used to set additional results captured from execution tracing. If this
+// tracer is used during transaction execution (block creation), the results can later be queried from the block.
+// This method will only be called on the added tracer if it implements the extended TestChainTracer interface.
+func (t *Create2Tracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
+	// Store our tracer results.
+	results.AdditionalResults[create2TracerResultsKey] = t.addresses
+}
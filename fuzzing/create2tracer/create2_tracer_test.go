@@ -0,0 +1,59 @@
+package create2tracer
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreate2TracerAddresses ensures the CREATE2 tracer records the addresses of contracts created through CREATE2,
+// including those whose creation fails, and that they match the addresses contracts are actually created at.
+func TestCreate2TracerAddresses(t *testing.T) {
+	// Init bytecode which deploys the runtime bytecode 0x01, and init bytecode which reverts.
+	deployingInitBytecode := []byte{
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.MSTORE8),
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+	}
+	revertingInitBytecode := []byte{byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.REVERT)}
+
+	// Store each init bytecode right-aligned in its own memory word, then create both with CREATE2 (value, offset, size
+	// and salt are pushed in reverse). Finally, create a contract from unexpanded memory, which reads as zeros.
+	code := append([]byte{byte(vm.PUSH10)}, deployingInitBytecode...)
+	code = append(code, byte(vm.PUSH1), 0x00, byte(vm.MSTORE))
+	code = append(code, byte(vm.PUSH5))
+	code = append(code, revertingInitBytecode...)
+	code = append(code, byte(vm.PUSH1), 0x20, byte(vm.MSTORE))
+	code = append(code,
+		byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x0a, byte(vm.PUSH1), 0x16, byte(vm.PUSH1), 0x00, byte(vm.CREATE2), byte(vm.POP),
+		byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x05, byte(vm.PUSH1), 0x3b, byte(vm.PUSH1), 0x00, byte(vm.CREATE2), byte(vm.POP),
+		byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x0a, byte(vm.PUSH1), 0x16, byte(vm.PUSH1), 0x00, byte(vm.CREATE2), byte(vm.POP),
+		byte(vm.PUSH1), 0x07, byte(vm.PUSH1), 0x04, byte(vm.PUSH1), 0x80, byte(vm.PUSH1), 0x00, byte(vm.CREATE2), byte(vm.POP),
+		byte(vm.STOP),
+	)
+
+	tracer := NewCreate2Tracer()
+	cfg := &runtime.Config{EVMConfig: vm.Config{Debug: true, Tracer: tracer}}
+	_, state, err := runtime.Execute(code, nil, cfg)
+	assert.NoError(t, err)
+
+	// The contract executing our code is the creator of each contract. Repeated addresses should only be recorded once.
+	creator := common.BytesToAddress([]byte("contract"))
+	deployedAddress := crypto.CreateAddress2(creator, common.HexToHash("0x2a"), crypto.Keccak256(deployingInitBytecode))
+	revertedAddress := crypto.CreateAddress2(creator, common.HexToHash("0x2a"), crypto.Keccak256(revertingInitBytecode))
+	zeroAddress := crypto.CreateAddress2(creator, common.HexToHash("0x07"), crypto.Keccak256(make([]byte, 4)))
+	assert.EqualValues(t, []common.Address{deployedAddress, revertedAddress, zeroAddress}, tracer.addresses)
+
+	// Our predicted address should be where the contract was actually created, while the reverted creation should not
+	// have created a contract.
+	assert.EqualValues(t, []byte{0x01}, state.GetCode(deployedAddress))
+	assert.Zero(t, state.GetNonce(revertedAddress))
+	assert.NotZero(t, state.GetNonce(zeroAddress))
+
+	// Starting a new transaction should reset the recorded addresses.
+	tracer.CaptureTxStart(0)
+	assert.Empty(t, tracer.addresses)
+}
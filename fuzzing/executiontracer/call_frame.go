@@ -4,6 +4,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"math/big"
+	"time"
 )
 
 // CallFrames represents a list of call frames recorded by the ExecutionTracer.
@@ -44,7 +45,8 @@ type CallFrame struct {
 	CodeRuntimeBytecode []byte
 
 	// Operations contains a chronological history of updates in the call frame.
-	// Potential types currently are *types.Log (events) or CallFrame (entering of a new child frame).
+	// Potential types currently are *types.Log (events), *CallFrame (entering of a new child frame) or *StorageWrite
+	// (storage slot updates, if the tracer records them).
 	Operations []any
 
 	// SelfDestructed indicates whether the call frame executed a SELFDESTRUCT operation.
@@ -67,6 +69,16 @@ type CallFrame struct {
 	// would be an example of a CallFrame where ExecutedCode would be false
 	ExecutedCode bool
 
+	// Mocked indicates whether the call frame was a call mocked through cheat codes, so that mocked return data was
+	// returned rather than the code at CodeAddress being executed.
+	Mocked bool
+
+	// FFI indicates whether the call frame executed an external command through the ffi cheat code.
+	FFI bool
+
+	// Duration describes the wall-clock time taken to execute the call frame.
+	Duration time.Duration
+
 	// ReturnError refers to any error returned by the EVM in the current call frame.
 	ReturnError error
 
@@ -103,3 +115,16 @@ func (c *CallFrame) ChildCallFrames() CallFrames {
 
 	return childCallFrames
 }
+
+// StorageWrite describes an update to a storage slot of the executing contract (an SSTORE operation), as recorded by
+// an ExecutionTracer.
+type StorageWrite struct {
+	// Slot refers to the storage slot which was written.
+	Slot common.Hash
+
+	// PreviousValue refers to the value held by the storage slot prior to the write.
+	PreviousValue common.Hash
+
+	// Value refers to the value written to the storage slot.
+	Value common.Hash
+}
@@ -3,9 +3,11 @@ package executiontracer
 import (
 	"encoding/hex"
 	"fmt"
+	"github.com/crytic/medusa/chain"
 	"github.com/crytic/medusa/compilation/abiutils"
 	"github.com/crytic/medusa/fuzzing/contracts"
 	"github.com/crytic/medusa/fuzzing/valuegeneration"
+	"github.com/crytic/medusa/logging/colors"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	coreTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -22,6 +24,10 @@ type ExecutionTrace struct {
 	// contractDefinitions represents the known contract definitions at the time of tracing. This is used to help
 	// obtain any additional information regarding execution.
 	contractDefinitions contracts.Contracts
+
+	// customErrors maps the selectors of custom errors declared in any of the contractDefinitions to their
+	// definitions. It is populated when first needed to decode a revert.
+	customErrors abiutils.SolidityCustomErrors
 }
 
 // newExecutionTrace creates and returns a new ExecutionTrace, to be used by the ExecutionTracer.
@@ -95,12 +101,21 @@ func (t *ExecutionTrace) generateCallFrameEnterString(callFrame *CallFrame) stri
 	}
 
 	// If we could not correctly obtain the unpacked arguments in a nice display string (due to not having a resolved
-	// contract or method definition, or failure to unpack), we display as raw data in the worst case.
+	// contract or method definition, or failure to unpack), we display as raw data in the worst case. For calls, the
+	// function selector is split from the remaining data, so the method can be looked up by hand.
 	if inputArgumentsDisplayText == nil {
 		temp := fmt.Sprintf("msg_data=%v", hex.EncodeToString(callFrame.InputData))
+		if !callFrame.IsContractCreation() && len(callFrame.InputData) >= 4 {
+			temp = fmt.Sprintf("selector=%v, msg_data=%v", hex.EncodeToString(callFrame.InputData[:4]), hex.EncodeToString(callFrame.InputData[4:]))
+		}
 		inputArgumentsDisplayText = &temp
 	}
 
+	// Highlight the names of the contract and method called.
+	proxyContractName = colors.Colorize(proxyContractName, colors.Cyan)
+	codeContractName = colors.Colorize(codeContractName, colors.Cyan)
+	methodName = colors.Colorize(methodName, colors.Bold)
+
 	// Generate the message we wish to output finally, using all these display string components.
 	// If we executed code, attach additional context such as the contract name, method, etc.
 	if callFrame.IsProxyCall() {
@@ -119,9 +134,25 @@ func (t *ExecutionTrace) generateCallFrameEnterString(callFrame *CallFrame) stri
 }
 
 // generateCallFrameExitString generates a footer string to print for the given call frame. It contains
-// result information about the call.
+// result information about the call. Calls which executed an external command through the ffi cheat code are flagged
+// with the time they took.
 // Returns the footer string.
 func (t *ExecutionTrace) generateCallFrameExitString(callFrame *CallFrame) string {
+	// Successful results are shown in green, while reverts and errors are shown in red.
+	resultString := t.generateCallFrameResultString(callFrame)
+	exitString := colors.Colorize(resultString, colors.Green)
+	if callFrame.ReturnError != nil {
+		exitString = colors.Colorize(resultString, colors.Red)
+	}
+	if callFrame.FFI {
+		exitString += colors.Colorize(fmt.Sprintf(" [ffi command executed (took %v)]", callFrame.Duration), colors.Yellow)
+	}
+	return exitString
+}
+
+// generateCallFrameResultString generates a string describing the result of the given call frame.
+// Returns the result string.
+func (t *ExecutionTrace) generateCallFrameResultString(callFrame *CallFrame) string {
 	// Define some strings that represent our current call frame
 	var method *abi.Method
 
@@ -156,15 +187,22 @@ func (t *ExecutionTrace) generateCallFrameExitString(callFrame *CallFrame) strin
 		outputArgumentsDisplayText = &temp
 	}
 
-	// Wrap our return message and output it at the end.
+	// Wrap our return message and output it at the end. Mocked return data is marked as such, as the code of the
+	// contract called was never executed.
 	if callFrame.ReturnError == nil {
+		if callFrame.Mocked {
+			return fmt.Sprintf("[mocked return (%v)]", *outputArgumentsDisplayText)
+		}
 		return fmt.Sprintf("[return (%v)]", *outputArgumentsDisplayText)
 	}
 
 	// Try to resolve a panic message and check if it signals a failed assertion.
 	panicCode := abiutils.GetSolidityPanicCode(callFrame.ReturnError, callFrame.ReturnData, true)
-	if panicCode != nil && panicCode.Uint64() == abiutils.PanicCodeAssertFailed {
-		return "[assertion failed]"
+	if panicCode != nil {
+		if panicCode.Uint64() == abiutils.PanicCodeAssertFailed {
+			return "[assertion failed]"
+		}
+		return fmt.Sprintf("[panic: %v (code: 0x%x)]", abiutils.GetSolidityPanicCodeDescription(panicCode), panicCode)
 	}
 
 	// Try to resolve an assertion failed panic code.
@@ -173,8 +211,15 @@ func (t *ExecutionTrace) generateCallFrameExitString(callFrame *CallFrame) strin
 		return fmt.Sprintf("[revert ('%v')]", *errorMessage)
 	}
 
-	// Try to unpack a custom Solidity error from the return values.
+	// Try to unpack a custom Solidity error from the return values. If the code contract does not declare it, the
+	// error may have been bubbled up from another contract, so we try to resolve it from any contract definition.
 	matchedCustomError, unpackedCustomErrorArgs := abiutils.GetSolidityCustomRevertError(callFrame.CodeContractAbi, callFrame.ReturnError, callFrame.ReturnData)
+	if matchedCustomError == nil {
+		if t.customErrors == nil {
+			t.customErrors = t.contractDefinitions.CustomErrors()
+		}
+		matchedCustomError, unpackedCustomErrorArgs = t.customErrors.GetSolidityCustomRevertError(callFrame.ReturnError, callFrame.ReturnData)
+	}
 	if matchedCustomError != nil {
 		customErrorArgsDisplayText, err := valuegeneration.EncodeABIArgumentsToString(matchedCustomError.Inputs, unpackedCustomErrorArgs)
 		if err == nil {
@@ -235,7 +280,35 @@ func (t *ExecutionTrace) generateEventEmittedString(callFrame *CallFrame, eventL
 	}
 
 	// Finally, add our output line with this event data to it.
-	return fmt.Sprintf("[event] %v", *eventDisplayText)
+	return colors.Colorize(fmt.Sprintf("[event] %v", *eventDisplayText), colors.Blue)
+}
+
+// generateStorageWriteString generates a string used to express a storage write, containing the slot written, along
+// with its previous and new values.
+// Returns a string representing a storage write.
+func (t *ExecutionTrace) generateStorageWriteString(storageWrite *StorageWrite) string {
+	return colors.Colorize(fmt.Sprintf("[storage write] slot=%v, previous=%v, value=%v", storageWrite.Slot.String(), storageWrite.PreviousValue.String(), storageWrite.Value.String()), colors.Magenta)
+}
+
+// generateConsoleLogString generates a string used to express a message logged through console.log, if the provided
+// call frame is a call to the console contract.
+// Returns a string representing the logged message, or nil if the call frame is not a console.log call whose message
+// could be decoded.
+func (t *ExecutionTrace) generateConsoleLogString(callFrame *CallFrame) *string {
+	// Resolve the console.log overload called and unpack its arguments.
+	if callFrame.CodeAddress != chain.ConsoleLogContractAddress || callFrame.CodeContractAbi == nil || len(callFrame.InputData) < 4 {
+		return nil
+	}
+	method, err := callFrame.CodeContractAbi.MethodById(callFrame.InputData)
+	if err != nil {
+		return nil
+	}
+	args, err := method.Inputs.Unpack(callFrame.InputData[4:])
+	if err != nil {
+		return nil
+	}
+	consoleLogString := colors.Colorize(fmt.Sprintf("[console.log] %v", chain.FormatConsoleLogArgs(args)), colors.Yellow)
+	return &consoleLogString
 }
 
 // generateStringsForCallFrame generates indented strings for a given call frame and its children.
@@ -249,7 +322,7 @@ func (t *ExecutionTrace) generateStringsForCallFrame(currentDepth int, callFrame
 
 	// If we're printing the root frame, add the overall execution trace header.
 	if currentDepth == 0 {
-		outputLines = append(outputLines, prefix+"[Execution Trace]")
+		outputLines = append(outputLines, prefix+colors.Colorize("[Execution Trace]", colors.Bold))
 	}
 
 	// Add the call frame enter header
@@ -267,19 +340,27 @@ func (t *ExecutionTrace) generateStringsForCallFrame(currentDepth int, callFrame
 		// frame.
 		for _, operation := range callFrame.Operations {
 			if childCallFrame, ok := operation.(*CallFrame); ok {
-				// If this is a call frame being entered, generate information recursively.
+				// If this is a message logged through console.log, show it inline rather than as a call. Otherwise, if
+				// this is a call frame being entered, generate information recursively.
+				if consoleLogMessage := t.generateConsoleLogString(childCallFrame); consoleLogMessage != nil {
+					outputLines = append(outputLines, prefix+*consoleLogMessage)
+					continue
+				}
 				childOutputLines := t.generateStringsForCallFrame(currentDepth+1, childCallFrame)
 				outputLines = append(outputLines, childOutputLines...)
 			} else if eventLog, ok := operation.(*coreTypes.Log); ok {
 				// If an event log was emitted, add a message for it.
 				eventMessage := prefix + t.generateEventEmittedString(callFrame, eventLog)
 				outputLines = append(outputLines, eventMessage)
+			} else if storageWrite, ok := operation.(*StorageWrite); ok {
+				// If a storage slot was written, add a message for it.
+				outputLines = append(outputLines, prefix+t.generateStorageWriteString(storageWrite))
 			}
 		}
 
 		// If we self-destructed, add a message for it before our footer.
 		if callFrame.SelfDestructed {
-			outputLines = append(outputLines, fmt.Sprintf("%v[selfdestruct]", prefix))
+			outputLines = append(outputLines, prefix+colors.Colorize("[selfdestruct]", colors.Red))
 		}
 
 		// Add the call frame exit footer
@@ -9,14 +9,16 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"golang.org/x/exp/slices"
 	"math/big"
+	"time"
 )
 
 // CallWithExecutionTrace obtains an execution trace for a given call, on the provided chain, using the state
-// provided. If a nil state is provided, the current chain state will be used.
+// provided. If a nil state is provided, the current chain state will be used. If traceStorageWrites is true, storage
+// writes are recorded in the trace.
 // Returns the ExecutionTrace for the call or an error if one occurs.
-func CallWithExecutionTrace(chain *chain.TestChain, contractDefinitions contracts.Contracts, msg core.Message, state *state.StateDB) (*core.ExecutionResult, *ExecutionTrace, error) {
+func CallWithExecutionTrace(chain *chain.TestChain, contractDefinitions contracts.Contracts, msg core.Message, state *state.StateDB, traceStorageWrites bool) (*core.ExecutionResult, *ExecutionTrace, error) {
 	// Create an execution tracer
-	executionTracer := NewExecutionTracer(contractDefinitions, chain.CheatCodeContracts())
+	executionTracer := NewExecutionTracer(contractDefinitions, chain.CheatCodeContracts(), traceStorageWrites)
 
 	// Call the contract on our chain with the provided state.
 	executionResult, err := chain.CallContract(msg, state, executionTracer)
@@ -46,12 +48,19 @@ type ExecutionTracer struct {
 	// currentCallFrame references the current call frame being traced.
 	currentCallFrame *CallFrame
 
+	// callFrameEnteredTimes describes the time each call frame in the current call stack was entered, so the time
+	// taken to execute it can be recorded upon exit.
+	callFrameEnteredTimes []time.Time
+
 	// contractDefinitions represents the contract definitions to match for execution traces.
 	contractDefinitions contracts.Contracts
 
 	// cheatCodeContracts  represents the cheat code contract definitions to match for execution traces.
 	cheatCodeContracts map[common.Address]*chain.CheatCodeContract
 
+	// traceStorageWrites describes whether storage writes (SSTORE operations) should be recorded in call frames.
+	traceStorageWrites bool
+
 	// onNextCaptureState refers to methods which should be executed the next time CaptureState executes.
 	// CaptureState is called prior to execution of an instruction. This allows actions to be performed
 	// after some state is captured, on the next state capture (e.g. detecting a log instruction, but
@@ -59,11 +68,13 @@ type ExecutionTracer struct {
 	onNextCaptureState []func()
 }
 
-// NewExecutionTracer creates a ExecutionTracer and returns it.
-func NewExecutionTracer(contractDefinitions contracts.Contracts, cheatCodeContracts map[common.Address]*chain.CheatCodeContract) *ExecutionTracer {
+// NewExecutionTracer creates a ExecutionTracer and returns it. If traceStorageWrites is true, storage writes are
+// recorded in the call frames of traces.
+func NewExecutionTracer(contractDefinitions contracts.Contracts, cheatCodeContracts map[common.Address]*chain.CheatCodeContract, traceStorageWrites bool) *ExecutionTracer {
 	tracer := &ExecutionTracer{
 		contractDefinitions: contractDefinitions,
 		cheatCodeContracts:  cheatCodeContracts,
+		traceStorageWrites:  traceStorageWrites,
 	}
 	return tracer
 }
@@ -79,6 +90,7 @@ func (t *ExecutionTracer) CaptureTxStart(gasLimit uint64) {
 	t.callDepth = 0
 	t.trace = newExecutionTrace(t.contractDefinitions)
 	t.currentCallFrame = nil
+	t.callFrameEnteredTimes = nil
 	t.onNextCaptureState = nil
 }
 
@@ -160,6 +172,9 @@ func (t *ExecutionTracer) captureEnteredCallFrame(fromAddress common.Address, to
 		ConstructorArgsData: nil,
 		ReturnData:          nil,
 		ExecutedCode:        false,
+		Mocked:              false,
+		FFI:                 false,
+		Duration:            0,
 		CallValue:           value,
 		ReturnError:         nil,
 		ParentCallFrame:     t.currentCallFrame,
@@ -170,6 +185,9 @@ func (t *ExecutionTracer) captureEnteredCallFrame(fromAddress common.Address, to
 		callFrameData.ToInitBytecode = inputData
 	}
 
+	// Record when we entered our call frame, so we can determine how long it took to execute.
+	t.callFrameEnteredTimes = append(t.callFrameEnteredTimes, time.Now())
+
 	// Set our current call frame in our trace
 	if t.trace.TopLevelCallFrame == nil {
 		t.trace.TopLevelCallFrame = callFrameData
@@ -204,6 +222,15 @@ func (t *ExecutionTracer) captureExitedCallFrame(output []byte, err error) {
 	// Set our information for this call frame
 	t.currentCallFrame.ReturnData = slices.Clone(output)
 	t.currentCallFrame.ReturnError = err
+	enteredTime := t.callFrameEnteredTimes[len(t.callFrameEnteredTimes)-1]
+	t.callFrameEnteredTimes = t.callFrameEnteredTimes[:len(t.callFrameEnteredTimes)-1]
+	t.currentCallFrame.Duration = time.Since(enteredTime)
+
+	// Flag calls to the ffi cheat code, as executing external commands can slow down fuzzing considerably.
+	if _, ok := t.cheatCodeContracts[t.currentCallFrame.CodeAddress]; ok && t.currentCallFrame.CodeContractAbi != nil {
+		method, err := t.currentCallFrame.CodeContractAbi.MethodById(t.currentCallFrame.InputData)
+		t.currentCallFrame.FFI = err == nil && method.Name == "ffi"
+	}
 
 	// We're exiting the current frame, so set our current call frame to the parent
 	t.currentCallFrame = t.currentCallFrame.ParentCallFrame
@@ -260,6 +287,7 @@ func (t *ExecutionTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64
 		if scope.Contract.CodeAddr != nil {
 			t.currentCallFrame.CodeAddress = *scope.Contract.CodeAddr
 		}
+		t.currentCallFrame.Mocked = chain.IsMockedCallCode(scope.Contract.Code)
 
 		// Mark code as having executed in this scope, so we don't set these values again (as cheat codes may affect it).
 		// We also want to know if a given call scope executed code, or simply represented a value transfer call.
@@ -271,10 +299,20 @@ func (t *ExecutionTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64
 		t.currentCallFrame.SelfDestructed = true
 	}
 
+	// If a storage write is occurring and we are recording them, capture the slot's value before and after the write.
+	if op == vm.SSTORE && t.traceStorageWrites && len(scope.Stack.Data()) >= 2 {
+		slot := common.Hash(scope.Stack.Back(0).Bytes32())
+		t.currentCallFrame.Operations = append(t.currentCallFrame.Operations, &StorageWrite{
+			Slot:          slot,
+			PreviousValue: t.evm.StateDB.GetState(scope.Contract.Address(), slot),
+			Value:         common.Hash(scope.Stack.Back(1).Bytes32()),
+		})
+	}
+
 	// If a log operation occurred, add a deferred operation to capture it.
 	if op == vm.LOG0 || op == vm.LOG1 || op == vm.LOG2 || op == vm.LOG3 || op == vm.LOG4 {
 		t.onNextCaptureState = append(t.onNextCaptureState, func() {
-			logs := t.evm.StateDB.(*state.StateDB).Logs()
+			logs := t.evm.StateDB.(chain.LogsStateDB).Logs()
 			if len(logs) > 0 {
 				t.currentCallFrame.Operations = append(t.currentCallFrame.Operations, logs[len(logs)-1])
 			}
@@ -2,9 +2,13 @@ package fuzzing
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -15,15 +19,20 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/crytic/medusa/chain"
+	chainTypes "github.com/crytic/medusa/chain/types"
 	compilationTypes "github.com/crytic/medusa/compilation/types"
 	"github.com/crytic/medusa/fuzzing/config"
 	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
 	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/crytic/medusa/fuzzing/reproducers"
 	"github.com/crytic/medusa/fuzzing/valuegeneration"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/logging/colors"
 	"github.com/crytic/medusa/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
 	"golang.org/x/exp/slices"
 )
 
@@ -33,17 +42,52 @@ type Fuzzer struct {
 	ctx context.Context
 	// ctxCancelFunc describes a function which can be used to cancel the fuzzing operations ctx tracks.
 	ctxCancelFunc context.CancelFunc
+	// forceStopCtx describes a context which is cancelled when the fuzzing run must stop immediately, rather than
+	// gracefully. When only ctx is cancelled, workers finish the call sequence they are testing, and any shrinking
+	// in progress continues for up to the configured stop shrink timeout. Cancelling forceStopCtx also cancels ctx.
+	forceStopCtx context.Context
+	// forceStopCtxCancelFunc describes a function which can be used to cancel forceStopCtx.
+	forceStopCtxCancelFunc context.CancelFunc
 
 	// config describes the project configuration which the fuzzing is targeting.
 	config config.ProjectConfig
 	// senders describes a set of account addresses used to send state changing calls in fuzzing campaigns.
 	senders []common.Address
+	// senderAccounts describes the starting balance (nil if it is not configured) and nonce of senders which were
+	// configured with them.
+	senderAccounts map[common.Address]core.GenesisAccount
+	// senderRoles describes the role of each sender which was configured with one.
+	senderRoles map[common.Address]string
+	// methodRoleFilters describes filters over the methods which only senders with certain roles may call.
+	methodRoleFilters []methodRoleFilter
 	// deployer describes an account address used to deploy contracts in fuzzing campaigns.
 	deployer common.Address
+	// signerKeys describes a set of private keys used to produce valid signatures for signature arguments of calls.
+	signerKeys []*ecdsa.PrivateKey
 	// contractDefinitions defines targets to be fuzzed once their deployment is detected.
 	contractDefinitions fuzzerTypes.Contracts
+	// libraryContracts describes the library contract definitions deployed during chain setup so the contracts
+	// referencing them could be linked.
+	libraryContracts []*fuzzerTypes.Contract
+	// unmatchedContractCodeHashes describes the hashes of the runtime bytecode of contracts deployed while fuzzing
+	// which could not be matched to any contract definition, so each is only reported once.
+	unmatchedContractCodeHashes map[common.Hash]struct{}
+	// unmatchedContractCodeHashesLock provides thread-synchronization to avoid race conditions when workers report
+	// contracts which could not be matched.
+	unmatchedContractCodeHashesLock sync.Mutex
+	// methodFilter describes the filter over contract methods the fuzzer may call, or nil if all may be called.
+	methodFilter *methodFilter
+	// compilations describes the compilations the contractDefinitions were derived from, which are used to map
+	// coverage to source files in coverage reports.
+	compilations []compilationTypes.Compilation
 	// baseValueSet represents a valuegeneration.ValueSet containing input values for our fuzz tests.
 	baseValueSet *valuegeneration.ValueSet
+	// learnedValueSet represents a valuegeneration.ValueSet containing the baseValueSet and all values learned by
+	// workers during the fuzzing campaign, which is persisted to the corpus directory on exit.
+	learnedValueSet *valuegeneration.ValueSet
+	// learnedValueSetLock provides thread-synchronization to avoid race conditions when workers merge their values
+	// into the learnedValueSet.
+	learnedValueSetLock sync.Mutex
 
 	// workers represents the work threads created by this Fuzzer when Start invokes a fuzz operation.
 	workers []*FuzzerWorker
@@ -51,17 +95,67 @@ type Fuzzer struct {
 	metrics *FuzzerMetrics
 	// corpus stores a list of transaction sequences that can be used for coverage-guided fuzzing
 	corpus *corpus.Corpus
-
+	// retainedCorpus describes the corpus of a previous campaign whose contracts were recompiled in watch mode, which
+	// the next campaign uses rather than reading the corpus directory again, or nil if there is none.
+	retainedCorpus *corpus.Corpus
+	// shrinkCandidates queues candidate call sequences produced by workers shrinking call sequences, so they may be
+	// tested by other workers between testing their own call sequences.
+	shrinkCandidates chan *shrinkCandidate
+	// reproducerDeployments describes the contract deployments performed when setting up the base test chain, which
+	// Foundry reproducers perform to deploy contracts at the same addresses, and failure files record.
+	reproducerDeployments []reproducers.FoundryDeployment
+
+	// seed describes the seed the randomProvider was created with for the current fuzzing campaign.
+	seed int64
 	// randomProvider describes the provider used to generate random values in the Fuzzer. All other random providers
 	// used by the Fuzzer's subcomponents are derived from this one.
 	randomProvider *rand.Rand
 
+	// fuzzedConstructorArgs describes the constructor argument values generated for each contract deployed in the
+	// current fuzzing campaign, keyed by contract name and then argument name.
+	fuzzedConstructorArgs map[string]map[string]any
+
+	// replayedConstructorArgs describes the constructor argument values generated for each contract in a previous
+	// fuzzing campaign, keyed by contract name and then argument name, which are used again rather than generating
+	// new ones, if configured to.
+	replayedConstructorArgs map[string]map[string]any
+
 	// testCases contains every TestCase registered with the Fuzzer.
 	testCases []TestCase
 	// testCasesLock provides thread-synchronization to avoid race conditions when accessing or updating test cases.
 	testCasesLock sync.Mutex
 	// testCasesFinished describes test cases already reported as having been finalized.
 	testCasesFinished map[string]TestCase
+	// testCaseFailures describes the hashes of the distinct call sequences each failed test case was reported to
+	// fail with, keyed by test case ID.
+	testCaseFailures map[string]map[common.Hash]struct{}
+
+	// unshrunkCallSequences describes the call sequences which failed tests and are being shrunk, keyed by the index
+	// of the worker shrinking them, so they are recorded in checkpoints until they are reported.
+	unshrunkCallSequences map[int]calls.CallSequence
+	// unshrunkCallSequencesLock provides thread-synchronization to avoid race conditions when accessing or updating
+	// unshrunkCallSequences.
+	unshrunkCallSequencesLock sync.Mutex
+
+	// stopConditions describes the coordinator which stops the campaign once it reaches a configured limit or its
+	// coverage stagnates.
+	stopConditions *stopConditionCoordinator
+	// stopReason describes the reason the current (or most recent) fuzzing campaign stopped.
+	stopReason StopReason
+	// stopReasonLock provides thread-synchronization to avoid race conditions when recording the stopReason, or the
+	// campaignStartTime and campaignStopTime.
+	stopReasonLock sync.Mutex
+	// campaignStartTime describes the time the workers of the current (or most recent) fuzzing campaign started
+	// fuzzing at.
+	campaignStartTime time.Time
+	// campaignStopTime describes the time the workers of the most recent fuzzing campaign stopped fuzzing at, or the
+	// zero time while they are fuzzing.
+	campaignStopTime time.Time
+	// generationResumed is non-nil while workers are paused from testing new call sequences, and is closed once they
+	// are resumed.
+	generationResumed chan struct{}
+	// generationPausedLock provides thread-synchronization to avoid race conditions when pausing or resuming workers.
+	generationPausedLock sync.Mutex
 
 	// Events describes the event system for the Fuzzer.
 	Events FuzzerEvents
@@ -79,31 +173,80 @@ func NewFuzzer(config config.ProjectConfig) (*Fuzzer, error) {
 		return nil, err
 	}
 
+	// Log events in the configured format from here on.
+	logging.SetGlobalLogger(config.Logging.Format, config.Logging.Debug)
+
 	// Parse the senders addresses from our account config.
 	senders, err := utils.HexStringsToAddresses(config.Fuzzing.SenderAddresses)
 	if err != nil {
 		return nil, err
 	}
 
+	// Parse any senders configured with a starting state or role, which send calls alongside our sender addresses.
+	senderAccounts := make(map[common.Address]core.GenesisAccount)
+	senderRoles := make(map[common.Address]string)
+	for _, senderConfig := range config.Fuzzing.Senders {
+		sender, err := utils.HexStringToAddress(senderConfig.Address)
+		if err != nil {
+			return nil, err
+		}
+		balance, err := senderConfig.BalanceValue()
+		if err != nil {
+			return nil, err
+		}
+		senders = append(senders, sender)
+		senderAccounts[sender] = core.GenesisAccount{Balance: balance, Nonce: senderConfig.Nonce}
+		if senderConfig.Role != "" {
+			senderRoles[sender] = senderConfig.Role
+		}
+	}
+
+	// Parse the filters over methods only senders with certain roles may call
+	methodRoleFilters, err := newMethodRoleFilters(config.Fuzzing)
+	if err != nil {
+		return nil, err
+	}
+
 	// Parse the deployer address from our account config
 	deployer, err := utils.HexStringToAddress(config.Fuzzing.DeployerAddress)
 	if err != nil {
 		return nil, err
 	}
 
+	// Parse the signer private keys from our account config
+	signerKeys, err := utils.HexStringsToPrivateKeys(config.Fuzzing.SignerPrivateKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the function signature filters from our config
+	methodFilter, err := newMethodFilter(config.Fuzzing)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create and return our fuzzing instance.
 	fuzzer := &Fuzzer{
 		config:              config,
 		senders:             senders,
+		senderAccounts:      senderAccounts,
+		senderRoles:         senderRoles,
+		methodRoleFilters:   methodRoleFilters,
 		deployer:            deployer,
+		signerKeys:          signerKeys,
+		methodFilter:        methodFilter,
 		baseValueSet:        valuegeneration.NewValueSet(),
 		contractDefinitions: make(fuzzerTypes.Contracts, 0),
 		testCases:           make([]TestCase, 0),
 		testCasesFinished:   make(map[string]TestCase),
+		testCaseFailures:    make(map[string]map[common.Hash]struct{}),
 		Hooks: FuzzerHooks{
+			NewValueGeneratorFunc:              defaultNewValueGeneratorFunc,
 			NewCallSequenceGeneratorConfigFunc: defaultNewCallSequenceGeneratorConfigFunc,
 			ChainSetupFunc:                     chainSetupFromCompilations,
 			CallSequenceTestFuncs:              make([]CallSequenceTestFunc, 0),
+			CallArgumentsModifyFuncs:           []CallArgumentsModifyFunc{callArgumentsModifyFuncCopyArgument},
+			CallMirrorFuncs:                    make([]CallMirrorFunc, 0),
 		},
 	}
 
@@ -114,20 +257,35 @@ func NewFuzzer(config config.ProjectConfig) (*Fuzzer, error) {
 		fuzzer.baseValueSet.AddAddress(sender)
 	}
 
+	// Add our signer addresses too, so they may be provided as the expected signer of a signature.
+	for _, signerKey := range fuzzer.signerKeys {
+		fuzzer.baseValueSet.AddAddress(crypto.PubkeyToAddress(signerKey.PublicKey))
+	}
+
 	// If we have a compilation config
 	if fuzzer.config.Compilation != nil {
 		// Compile the targets specified in the compilation config
-		fmt.Printf("Compiling targets (platform '%s') ...\n", fuzzer.config.Compilation.Platform)
+		logging.GlobalLogger.Info().Str("platform", fuzzer.config.Compilation.Platform).Msgf("Compiling targets (platform '%s') ...", fuzzer.config.Compilation.Platform)
 		compilations, compilationOutput, err := (*fuzzer.config.Compilation).Compile()
 		if err != nil {
 			return nil, err
 		}
-		fmt.Printf("%s", compilationOutput)
+		if compilationOutput = strings.TrimRight(compilationOutput, "\n"); compilationOutput != "" {
+			logging.GlobalLogger.Info().Msg(compilationOutput)
+		}
 
 		// Add our compilation targets
 		fuzzer.AddCompilationTargets(compilations)
 	}
 
+	// Stop the campaign once it reaches a configured limit or its coverage stagnates.
+	fuzzer.stopConditions = attachStopConditionCoordinator(fuzzer)
+
+	// Notify any configured webhooks of failed test cases.
+	if len(fuzzer.config.Notifications.WebhookURLs) > 0 {
+		attachFailureNotifier(fuzzer)
+	}
+
 	// Register any default providers if specified.
 	if fuzzer.config.Fuzzing.Testing.PropertyTesting.Enabled {
 		attachPropertyTestCaseProvider(fuzzer)
@@ -135,6 +293,15 @@ func NewFuzzer(config config.ProjectConfig) (*Fuzzer, error) {
 	if fuzzer.config.Fuzzing.Testing.AssertionTesting.Enabled {
 		attachAssertionTestCaseProvider(fuzzer)
 	}
+	if fuzzer.config.Fuzzing.Testing.OptimizationTesting.Enabled {
+		attachOptimizationTestCaseProvider(fuzzer)
+	}
+	if fuzzer.config.Fuzzing.Testing.DifferentialTesting.Enabled {
+		attachDifferentialTestCaseProvider(fuzzer)
+	}
+	if fuzzer.config.Fuzzing.Testing.NeverRevertTesting.Enabled {
+		attachNeverRevertTestCaseProvider(fuzzer)
+	}
 	return fuzzer, nil
 }
 
@@ -148,23 +315,55 @@ func (f *Fuzzer) Config() config.ProjectConfig {
 	return f.config
 }
 
+// Metrics exposes the metrics for the current fuzzing campaign. This is nil if the Fuzzer has not been started.
+func (f *Fuzzer) Metrics() *FuzzerMetrics {
+	return f.metrics
+}
+
+// Seed returns the seed used to derive all random providers in the current (or most recent) fuzzing campaign.
+func (f *Fuzzer) Seed() int64 {
+	return f.seed
+}
+
 // BaseValueSet exposes the underlying value set provided to the Fuzzer value generators to aid in generation
 // (e.g. for use in mutation operations).
 func (f *Fuzzer) BaseValueSet() *valuegeneration.ValueSet {
 	return f.baseValueSet
 }
 
+// labelSenderRoles sets the sender role of each element in the provided call sequence to the role configured for its
+// sender, if any.
+func (f *Fuzzer) labelSenderRoles(callSequence calls.CallSequence) {
+	for _, element := range callSequence {
+		if element.Call != nil {
+			element.SenderRole = f.senderRoles[element.Call.MsgFrom]
+		}
+	}
+}
+
 // SenderAddresses exposes the account addresses from which state changing fuzzed transactions will be sent by a
 // FuzzerWorker.
 func (f *Fuzzer) SenderAddresses() []common.Address {
 	return f.senders
 }
 
+// propertyTestSender obtains the account address from which property test methods are called, which is the first
+// of the configured sender addresses.
+func (f *Fuzzer) propertyTestSender() common.Address {
+	return f.senders[0]
+}
+
 // DeployerAddress exposes the account address from which contracts will be deployed by a FuzzerWorker.
 func (f *Fuzzer) DeployerAddress() common.Address {
 	return f.deployer
 }
 
+// traceStorageWrites indicates whether execution traces attached to call sequences should record storage writes, as
+// determined by the configured trace verbosity.
+func (f *Fuzzer) traceStorageWrites() bool {
+	return f.config.Fuzzing.Testing.TraceVerbosity >= config.TraceVerbosityStorageWrites
+}
+
 // TestCases exposes the underlying tests run during the fuzzing campaign.
 func (f *Fuzzer) TestCases() []TestCase {
 	return f.testCases
@@ -192,36 +391,133 @@ func (f *Fuzzer) RegisterTestCase(testCase TestCase) {
 	f.testCases = append(f.testCases, testCase)
 }
 
-// ReportTestCaseFinished is used to report a TestCase status as finalized to the Fuzzer.
+// ReportTestCaseFinished is used to report a TestCase status as finalized to the Fuzzer. If the test case failed, and
+// this is its first (or a distinct) failure, a TestCaseFailed event is published once the report is recorded.
 func (f *Fuzzer) ReportTestCaseFinished(testCase TestCase) {
+	// Record the report, then publish an event outside our lock, so handlers may query our test cases.
+	if !f.recordTestCaseFinished(testCase) {
+		return
+	}
+	err := f.Events.TestCaseFailed.Publish(FuzzerTestCaseFailedEvent{
+		Fuzzer:       f,
+		TestCase:     testCase,
+		CallSequence: testCase.CallSequence(),
+	})
+	if err != nil {
+		logging.GlobalLogger.Error().Str("testCase", testCase.Name()).Err(err).Msgf("Error returned by an event handler when the fuzzer emitted an event indicating %s failed: %v", testCase.Name(), err)
+	}
+}
+
+// recordTestCaseFinished records the report of a TestCase status as finalized, for ReportTestCaseFinished.
+// Returns a boolean indicating whether the report describes a newly failed test case, or a distinct failure of one.
+func (f *Fuzzer) recordTestCaseFinished(testCase TestCase) bool {
 	// Acquire a thread lock to avoid race conditions
 	f.testCasesLock.Lock()
 	defer f.testCasesLock.Unlock()
 
-	// If we already reported this test case as finished, stop
-	if _, alreadyExists := f.testCasesFinished[testCase.ID()]; alreadyExists {
-		return
+	// Label the senders of the calls in the test case's call sequence with their roles, so they are reported.
+	if callSequence := testCase.CallSequence(); callSequence != nil {
+		f.labelSenderRoles(*callSequence)
+	}
+
+	// If the test case failed, record the call sequence it failed with, so we can determine whether this is a
+	// distinct failure. Only failed test cases which continue to be tested can report additional failures.
+	_, alreadyExists := f.testCasesFinished[testCase.ID()]
+	distinctFailure := false
+	if testCase.Status() == TestCaseStatusFailed && (!alreadyExists || f.retestFailedTestCases()) {
+		distinctFailure = f.recordTestCaseFailure(testCase)
+	}
+
+	// If we already reported this test case as finished, stop, logging any additional distinct failure.
+	if alreadyExists {
+		if distinctFailure && f.retestFailedTestCases() {
+			f.logWriteFailureFile(testCase)
+			logging.GlobalLogger.Break()
+			f.logTestCaseResult(testCase)
+			logging.GlobalLogger.Break()
+			return true
+		}
+		return false
 	}
 
 	// Otherwise now mark the test case as finished.
 	f.testCasesFinished[testCase.ID()] = testCase
 
+	// If the config specifies, write a failure file and a Foundry test reproducing the failure.
+	if testCase.Status() == TestCaseStatusFailed {
+		f.logWriteFailureFile(testCase)
+	}
+	if testCase.Status() == TestCaseStatusFailed && f.config.Fuzzing.Testing.GenerateFoundryReproducers {
+		reproducerPath, err := f.writeFoundryReproducer(testCase)
+		if err != nil {
+			logging.GlobalLogger.Error().Str("testCase", testCase.Name()).Err(err).Msgf("Failed to write Foundry reproducer for %s: %v", testCase.Name(), err)
+		} else if reproducerPath != "" {
+			logging.GlobalLogger.Info().Str("testCase", testCase.Name()).Str("path", reproducerPath).Msgf("Wrote Foundry reproducer for %s to %s", testCase.Name(), reproducerPath)
+		}
+	}
+
 	// We only log here if we're not configured to stop on the first test failure. This is because the fuzzer prints
 	// results on exit, so we avoid duplicate messages.
 	if !f.config.Fuzzing.Testing.StopOnFailedTest {
-		fmt.Printf("\n[%s] %s\n%s\n\n", testCase.Status(), testCase.Name(), testCase.Message())
+		logging.GlobalLogger.Break()
+		f.logTestCaseResult(testCase)
+		logging.GlobalLogger.Break()
 	}
 
 	// If the config specifies, we stop after the first failed test reported.
 	if testCase.Status() == TestCaseStatusFailed && f.config.Fuzzing.Testing.StopOnFailedTest {
-		f.Stop()
+		f.stop(StopReasonFailedTest)
+	}
+	return testCase.Status() == TestCaseStatusFailed
+}
+
+// recordTestCaseFailure records the call sequence the provided failed TestCase currently describes as one of its
+// failures. The caller must hold testCasesLock.
+// Returns a boolean indicating whether the failure is distinct from those previously recorded for the test case.
+func (f *Fuzzer) recordTestCaseFailure(testCase TestCase) bool {
+	// Failures are distinguished by the hash of their shrunken call sequences. Test cases without a call sequence
+	// can only fail in one way.
+	var callSequenceHash common.Hash
+	if callSequence := testCase.CallSequence(); callSequence != nil {
+		var err error
+		callSequenceHash, err = callSequence.Hash()
+		if err != nil {
+			logging.GlobalLogger.Warn().Str("testCase", testCase.Name()).Err(err).Msgf("Failed to hash the call sequence which failed %s: %v", testCase.Name(), err)
+		}
+	}
+
+	// Record the failure, if it is distinct.
+	failures, ok := f.testCaseFailures[testCase.ID()]
+	if !ok {
+		failures = make(map[common.Hash]struct{})
+		f.testCaseFailures[testCase.ID()] = failures
 	}
+	if _, exists := failures[callSequenceHash]; exists {
+		return false
+	}
+	failures[callSequenceHash] = struct{}{}
+	return true
+}
+
+// DistinctTestCaseFailures returns the amount of distinct call sequences the provided TestCase was reported to fail
+// with. This is at most one unless the config specifies failed tests continue to be tested with deduplicated failures.
+func (f *Fuzzer) DistinctTestCaseFailures(testCase TestCase) int {
+	f.testCasesLock.Lock()
+	defer f.testCasesLock.Unlock()
+	return len(f.testCaseFailures[testCase.ID()])
+}
+
+// retestFailedTestCases indicates whether test cases should continue to be tested after they fail, to find additional
+// distinct failures.
+func (f *Fuzzer) retestFailedTestCases() bool {
+	return !f.config.Fuzzing.Testing.StopOnFailedTest && f.config.Fuzzing.Testing.FailedTestBehavior == config.FailedTestBehaviorDeduplicate
 }
 
 // AddCompilationTargets takes a compilation and updates the Fuzzer state with additional Fuzzer.ContractDefinitions
 // definitions and Fuzzer.BaseValueSet values.
 func (f *Fuzzer) AddCompilationTargets(compilations []compilationTypes.Compilation) {
 	// Loop for each contract in each compilation and deploy it to the test node.
+	f.compilations = append(f.compilations, compilations...)
 	for _, comp := range compilations {
 		for sourcePath, source := range comp.Sources {
 			// Seed our base value set from every source's AST
@@ -230,25 +526,68 @@ func (f *Fuzzer) AddCompilationTargets(compilations []compilationTypes.Compilati
 			// Loop for every contract and register it in our contract definitions
 			for contractName := range source.Contracts {
 				contract := source.Contracts[contractName]
-				contractDefinition := fuzzerTypes.NewContract(contractName, sourcePath, &contract)
+				enumBounds := comp.GetMethodInputEnumBounds(sourcePath, contractName)
+				contractDefinition := fuzzerTypes.NewContract(contractName, sourcePath, &contract, enumBounds)
 				f.contractDefinitions = append(f.contractDefinitions, contractDefinition)
+
+				// Add every method selector to our base value set, so bytes4 and function type arguments can reference
+				// them.
+				for _, method := range contract.Abi.Methods {
+					f.baseValueSet.AddSelector(method.ID)
+				}
+
+				// Seed our base value set with constants from the contract's bytecode.
+				seedingConfig := f.config.Fuzzing.ValueSetSeeding
+				for _, bytecode := range [][]byte{contract.InitBytecode, contract.RuntimeBytecode} {
+					f.baseValueSet.SeedFromBytecode(bytecode, seedingConfig.BytecodeIntegers, seedingConfig.BytecodeAddresses, seedingConfig.BytecodeBytes)
+				}
 			}
 		}
 	}
 }
 
+// reportUnmatchedContract logs a warning that the contract deployed at the provided address with the provided runtime
+// bytecode could not be matched to any contract definition, so its methods will not be called. Each distinct runtime
+// bytecode is only reported once, as contracts are deployed repeatedly while fuzzing.
+func (f *Fuzzer) reportUnmatchedContract(address common.Address, runtimeBytecode []byte) {
+	// Precompiles and accounts without code are never matched, so they are not reported.
+	if len(runtimeBytecode) == 0 {
+		return
+	}
+
+	f.unmatchedContractCodeHashesLock.Lock()
+	defer f.unmatchedContractCodeHashesLock.Unlock()
+	codeHash := crypto.Keccak256Hash(runtimeBytecode)
+	if _, reported := f.unmatchedContractCodeHashes[codeHash]; reported {
+		return
+	}
+	if f.unmatchedContractCodeHashes == nil {
+		f.unmatchedContractCodeHashes = make(map[common.Hash]struct{})
+	}
+	f.unmatchedContractCodeHashes[codeHash] = struct{}{}
+	logging.GlobalLogger.Warn().Str("address", address.String()).Str("codeHash", codeHash.String()).
+		Msgf("Could not match the bytecode of contract %v deployed while fuzzing to any contract definition, so its methods will not be called and traces will not decode calls to it", address.String())
+}
+
 // createTestChain creates a test chain with the account balance allocations specified by the config.
 func (f *Fuzzer) createTestChain() (*chain.TestChain, error) {
 	// Create our genesis allocations.
 	// NOTE: Sharing GenesisAlloc between chains will result in some accounts not being funded for some reason.
 	genesisAlloc := make(core.GenesisAlloc)
 
-	// Fund all of our sender addresses in the genesis block
-	initBalance := new(big.Int).Div(abi.MaxInt256, big.NewInt(2)) // TODO: make this configurable
+	// Fund all of our sender addresses in the genesis block, with any starting balance and nonce configured for them.
+	initBalance := new(big.Int).Div(abi.MaxInt256, big.NewInt(2))
 	for _, sender := range f.senders {
-		genesisAlloc[sender] = core.GenesisAccount{
+		account := core.GenesisAccount{
 			Balance: initBalance,
 		}
+		if senderAccount, ok := f.senderAccounts[sender]; ok {
+			account.Nonce = senderAccount.Nonce
+			if senderAccount.Balance != nil {
+				account.Balance = new(big.Int).Set(senderAccount.Balance)
+			}
+		}
+		genesisAlloc[sender] = account
 	}
 
 	// Fund our deployer address in the genesis block
@@ -256,12 +595,32 @@ func (f *Fuzzer) createTestChain() (*chain.TestChain, error) {
 		Balance: initBalance,
 	}
 
+	// If contracts are deployed through the deterministic deployment proxy, add it to the genesis block, so it exists
+	// at the address it exists at on other chains.
+	if f.config.Fuzzing.UsesDeterministicDeploymentProxy() {
+		genesisAlloc[common.HexToAddress(config.DeterministicDeploymentProxyAddress)] = core.GenesisAccount{
+			Code:    deterministicDeploymentProxyRuntimeCode,
+			Balance: big.NewInt(0),
+		}
+	}
+
+	// If we are forking a remote chain without a cache directory, cache fetched state in our corpus directory, so it
+	// persists across campaigns alongside the corpus.
+	testChainConfig := f.config.Fuzzing.TestChainConfig
+	if testChainConfig.ForkConfig.ForkModeEnabled && testChainConfig.ForkConfig.CacheDirectory == "" && f.config.Fuzzing.CorpusDirectory != "" {
+		testChainConfig.ForkConfig.CacheDirectory = filepath.Join(f.config.Fuzzing.CorpusDirectory, "fork_cache")
+	}
+
 	// Create our test chain with our basic allocations and passed medusa's chain configuration
-	testChain, err := chain.NewTestChain(genesisAlloc, &f.config.Fuzzing.TestChainConfig)
+	testChain, err := chain.NewTestChain(genesisAlloc, &testChainConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	// Set our block gas limit
+	// Set our block gas limit, and expose our signer keys to cheat codes
 	testChain.BlockGasLimit = f.config.Fuzzing.BlockGasLimit
-	return testChain, err
+	testChain.SignerKeys = f.signerKeys
+	return testChain, nil
 }
 
 // chainSetupFromCompilations is a TestChainSetupFunc which sets up the base test chain state by deploying
@@ -269,6 +628,11 @@ func (f *Fuzzer) createTestChain() (*chain.TestChain, error) {
 // definitions, as well as those added by Fuzzer.AddCompilationTargets. The contract deployment order is defined by
 // the Fuzzer.config.
 func chainSetupFromCompilations(fuzzer *Fuzzer, testChain *chain.TestChain) error {
+	// If a setup contract was provided, it performs the deployment instead.
+	if fuzzer.config.Fuzzing.SetupContract != "" {
+		return chainSetupFromSetupContract(fuzzer, testChain)
+	}
+
 	// Verify contract deployment order is not empty. If it's empty, but we only have one contract definition,
 	// we can infer the deployment order. Otherwise, we report an error.
 	if len(fuzzer.config.Fuzzing.DeploymentOrder) == 0 {
@@ -279,9 +643,20 @@ func chainSetupFromCompilations(fuzzer *Fuzzer, testChain *chain.TestChain) erro
 		}
 	}
 
+	// Deploy the libraries our contracts reference, so they can be linked.
+	deployedContractAddr, err := chainSetupDeployLibraries(fuzzer, testChain, fuzzer.config.Fuzzing.DeploymentOrder)
+	if err != nil {
+		return err
+	}
+
 	// Loop for all contracts to deploy
-	deployedContractAddr := make(map[string]common.Address)
+	fuzzer.fuzzedConstructorArgs = make(map[string]map[string]any)
 	for _, contractName := range fuzzer.config.Fuzzing.DeploymentOrder {
+		// Libraries were already deployed, so they are not deployed again.
+		if _, deployed := deployedContractAddr[contractName]; deployed {
+			continue
+		}
+
 		// Look for a contract in our compiled contract definitions that matches this one
 		found := false
 		for _, contract := range fuzzer.contractDefinitions {
@@ -289,12 +664,7 @@ func chainSetupFromCompilations(fuzzer *Fuzzer, testChain *chain.TestChain) erro
 			if contract.Name() == contractName {
 				args := make([]any, 0)
 				if len(contract.CompiledContract().Abi.Constructor.Inputs) > 0 {
-					jsonArgs, ok := fuzzer.config.Fuzzing.ConstructorArgs[contractName]
-					if !ok {
-						return fmt.Errorf("constructor arguments for contract %s not provided", contractName)
-					}
-					decoded, err := valuegeneration.DecodeJSONArgumentsFromMap(contract.CompiledContract().Abi.Constructor.Inputs,
-						jsonArgs, deployedContractAddr)
+					decoded, err := fuzzer.deploymentConstructorArgs(contract, deployedContractAddr)
 					if err != nil {
 						return err
 					}
@@ -307,38 +677,22 @@ func chainSetupFromCompilations(fuzzer *Fuzzer, testChain *chain.TestChain) erro
 					return fmt.Errorf("initial contract deployment failed for contract \"%v\", error: %v", contractName, err)
 				}
 
-				// Create a message to represent our contract deployment (we let deployments consume the whole block
-				// gas limit rather than use tx gas limit)
-				msg := calls.NewCallMessage(fuzzer.deployer, nil, 0, big.NewInt(0), fuzzer.config.Fuzzing.BlockGasLimit, nil, nil, nil, msgData)
-				msg.FillFromTestChainProperties(testChain)
-
-				// Create a new pending block we'll commit to chain
-				block, err := testChain.PendingBlockCreate()
-				if err != nil {
-					return err
-				}
-
-				// Add our transaction to the block
-				err = testChain.PendingBlockAddTx(msg)
-				if err != nil {
-					return err
-				}
-
-				// Commit the pending block to the chain, so it becomes the new head.
-				err = testChain.PendingBlockCommit()
-				if err != nil {
-					return err
-				}
-
-				// Ensure our transaction succeeded
-				if block.MessageResults[0].Receipt.Status != types.ReceiptStatusSuccessful {
-					return fmt.Errorf("contract deployment tx returned a failed status: %v", block.MessageResults[0].ExecutionResult.Err)
+				// Deploy our contract, through CREATE2 if the config specifies a salt and factory for it, and record
+				// it so the next config-specified constructor args can reference this contract by name.
+				if deployment, ok := fuzzer.config.Fuzzing.Create2Deployments[contractName]; ok {
+					address, err := chainSetupDeployCreate2(fuzzer, testChain, contractName, msgData, deployment, deployedContractAddr)
+					if err != nil {
+						return err
+					}
+					deployedContractAddr[contractName] = address
+				} else {
+					messageResults, err := chainSetupSendMessage(fuzzer, testChain, nil, msgData)
+					if err != nil {
+						return err
+					}
+					deployedContractAddr[contractName] = messageResults.Receipt.ContractAddress
 				}
 
-				// Record our deployed contract so the next config-specified constructor args can reference this
-				// contract by name.
-				deployedContractAddr[contractName] = block.MessageResults[0].Receipt.ContractAddress
-
 				// Flag that we found a matching compiled contract definition and deployed it, then exit out of this
 				// inner loop to process the next contract to deploy in the outer loop.
 				found = true
@@ -351,30 +705,276 @@ func chainSetupFromCompilations(fuzzer *Fuzzer, testChain *chain.TestChain) erro
 			return fmt.Errorf("DeploymentOrder specified a contract name which was not found in the compilation: %v\n", contractName)
 		}
 	}
-	return nil
+
+	// Deploy any agent contracts which were not in the deployment order.
+	return chainSetupDeployAgents(fuzzer, testChain, deployedContractAddr)
 }
 
-// defaultNewCallSequenceGeneratorConfigFunc is a NewCallSequenceGeneratorConfigFunc which creates a
-// CallSequenceGeneratorConfig with a default configuration. Returns the config or an error, if one occurs.
-func defaultNewCallSequenceGeneratorConfigFunc(fuzzer *Fuzzer, valueSet *valuegeneration.ValueSet, randomProvider *rand.Rand) (*CallSequenceGeneratorConfig, error) {
-	// Create the underlying value generator for the worker and its sequence generator.
+// chainSetupFromSetupContract sets up the base test chain state by deploying the setup contract named by the
+// Fuzzer.config, whose constructor (and setUp method, if it has one) deploys and configures the contracts to test.
+// Every contract created during setup which matches a compiled contract definition is discovered by the fuzzer as a
+// target. If no deployment order is provided by the Fuzzer.config, it is populated with the names of these contracts,
+// so they are all tested.
+func chainSetupFromSetupContract(fuzzer *Fuzzer, testChain *chain.TestChain) error {
+	// Look for the setup contract in our compiled contract definitions.
+	setupContractName := fuzzer.config.Fuzzing.SetupContract
+	index := slices.IndexFunc(fuzzer.contractDefinitions, func(contract *fuzzerTypes.Contract) bool {
+		return contract.Name() == setupContractName
+	})
+	if index < 0 {
+		return fmt.Errorf("setup contract was not found in the compilation: %v", setupContractName)
+	}
+	setupContract := fuzzer.contractDefinitions[index]
+
+	// Deploy the libraries our setup contract references, so it can be linked.
+	deployedLibraryAddr, err := chainSetupDeployLibraries(fuzzer, testChain, []string{setupContractName})
+	if err != nil {
+		return err
+	}
+
+	// Deploy our setup contract with any constructor arguments it requires.
+	fuzzer.fuzzedConstructorArgs = make(map[string]map[string]any)
+	args := make([]any, 0)
+	if len(setupContract.CompiledContract().Abi.Constructor.Inputs) > 0 {
+		decoded, err := fuzzer.deploymentConstructorArgs(setupContract, deployedLibraryAddr)
+		if err != nil {
+			return err
+		}
+		args = decoded
+	}
+	msgData, err := setupContract.CompiledContract().GetDeploymentMessageData(args)
+	if err != nil {
+		return fmt.Errorf("initial contract deployment failed for setup contract \"%v\", error: %v", setupContractName, err)
+	}
+	messageResults, err := chainSetupSendMessage(fuzzer, testChain, nil, msgData)
+	if err != nil {
+		return err
+	}
+	deploymentChanges := messageResults.ContractDeploymentChanges
+
+	// If our setup contract has a setUp method, call it to complete the deployment.
+	if method, ok := setupContract.CompiledContract().Abi.Methods["setUp"]; ok && len(method.Inputs) == 0 {
+		setupContractAddress := messageResults.Receipt.ContractAddress
+		messageResults, err = chainSetupSendMessage(fuzzer, testChain, &setupContractAddress, method.ID)
+		if err != nil {
+			return fmt.Errorf("setUp call to setup contract \"%v\" failed: %v", setupContractName, err)
+		}
+		deploymentChanges = append(deploymentChanges, messageResults.ContractDeploymentChanges...)
+	}
+
+	// Match every contract created during setup (which was not later destroyed) to a compiled contract definition.
+	createdContracts := make([]*chainTypes.DeployedContractBytecode, 0)
+	for _, deploymentChange := range deploymentChanges {
+		if deploymentChange.Creation {
+			createdContracts = append(createdContracts, deploymentChange.Contract)
+		} else if deploymentChange.Destroyed {
+			remainingContracts := make([]*chainTypes.DeployedContractBytecode, 0, len(createdContracts))
+			for _, createdContract := range createdContracts {
+				if createdContract.Address != deploymentChange.Contract.Address {
+					remainingContracts = append(remainingContracts, createdContract)
+				}
+			}
+			createdContracts = remainingContracts
+		}
+	}
+	createdContractNames := make([]string, 0)
+	createdContractAddr := deployedLibraryAddr
+	for _, createdContract := range createdContracts {
+		contract := fuzzer.contractDefinitions.MatchBytecode(createdContract.InitBytecode, createdContract.RuntimeBytecode)
+		if contract == nil {
+			logging.GlobalLogger.Warn().Str("address", createdContract.Address.String()).
+				Msgf("Could not match the bytecode of contract %v created during setup to any contract definition", createdContract.Address.String())
+			continue
+		}
+		if !slices.Contains(createdContractNames, contract.Name()) {
+			createdContractNames = append(createdContractNames, contract.Name())
+			createdContractAddr[contract.Name()] = createdContract.Address
+		}
+	}
+	logging.GlobalLogger.Info().Strs("contracts", createdContractNames).
+		Msgf("Setup contract %v deployed: %v", setupContractName, strings.Join(createdContractNames, ", "))
+
+	// If no deployment order was provided, every contract created during setup is tested.
+	if len(fuzzer.config.Fuzzing.DeploymentOrder) == 0 {
+		fuzzer.config.Fuzzing.DeploymentOrder = createdContractNames
+	}
+
+	// Deploy any agent contracts which were not created during setup.
+	return chainSetupDeployAgents(fuzzer, testChain, createdContractAddr)
+}
+
+// chainSetupSendMessage sends a transaction from the fuzzer's deployer with the provided message data to the
+// provided address (or a contract deployment, if the address is nil), and commits it to the test chain in its own
+// block. Deployment transactions may consume the whole block gas limit rather than the transaction gas limit.
+// Returns the message results of the transaction, or an error if one occurs or the transaction failed.
+func chainSetupSendMessage(fuzzer *Fuzzer, testChain *chain.TestChain, to *common.Address, msgData []byte) (*chainTypes.MessageResults, error) {
+	// Create a message to represent our transaction
+	msg := calls.NewCallMessage(fuzzer.deployer, to, 0, big.NewInt(0), fuzzer.config.Fuzzing.BlockGasLimit, nil, nil, nil, msgData)
+	msg.FillFromTestChainProperties(testChain)
+
+	// Create a new pending block we'll commit to chain
+	block, err := testChain.PendingBlockCreate()
+	if err != nil {
+		return nil, err
+	}
+
+	// Add our transaction to the block
+	err = testChain.PendingBlockAddTx(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit the pending block to the chain, so it becomes the new head.
+	err = testChain.PendingBlockCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure our transaction succeeded
+	if block.MessageResults[0].Receipt.Status != types.ReceiptStatusSuccessful {
+		if to == nil {
+			return nil, fmt.Errorf("contract deployment tx returned a failed status: %v", block.MessageResults[0].ExecutionResult.Err)
+		}
+		return nil, fmt.Errorf("tx returned a failed status: %v", block.MessageResults[0].ExecutionResult.Err)
+	}
+	return block.MessageResults[0], nil
+}
+
+// deploymentConstructorArgs obtains the constructor arguments to deploy the provided contract with. Arguments are
+// decoded from the config-provided constructor arguments, except for those the config specifies should be fuzzed,
+// which are generated with a value generator, or replayed from a previous campaign if configured to. Any generated
+// values are recorded, so they can be reported and replayed. Arguments may reference contracts deployed earlier in the deployment order by name (e.g. "DeployedContract:MyToken").
+// Returns the constructor arguments, or an error if one occurs.
+func (f *Fuzzer) deploymentConstructorArgs(contract *fuzzerTypes.Contract, deployedContractAddr map[string]common.Address) ([]any, error) {
+	inputs := contract.CompiledContract().Abi.Constructor.Inputs
+	fuzzedArgNames := f.config.Fuzzing.FuzzedConstructorArgs[contract.Name()]
+	configArgs, ok := f.config.Fuzzing.ConstructorArgs[contract.Name()]
+	if !ok && len(fuzzedArgNames) == 0 {
+		return nil, fmt.Errorf("constructor arguments for contract %s not provided", contract.Name())
+	}
+
+	// Copy the config-provided arguments, so generated values do not alter the config.
+	jsonArgs := make(map[string]any)
+	for name, value := range configArgs {
+		jsonArgs[name] = value
+	}
+
+	// Generate a value for every fuzzed argument. Values are encoded as they would be provided through the config, so
+	// they can be reported in the same form.
+	if len(fuzzedArgNames) > 0 {
+		// Outside a fuzzing campaign (e.g. when maintaining the corpus), no random provider is initialized, so we
+		// derive one from our seed.
+		randomProvider := f.randomProvider
+		if randomProvider == nil {
+			randomProvider = rand.New(rand.NewSource(f.seed))
+		}
+		valueGenerator, err := f.Hooks.NewValueGeneratorFunc(f, f.baseValueSet, randomutils.ForkRandomProvider(randomProvider))
+		if err != nil {
+			return nil, err
+		}
+		generatedArgs := make(map[string]any)
+		for _, name := range fuzzedArgNames {
+			index := slices.IndexFunc(inputs, func(input abi.Argument) bool { return input.Name == name })
+			if index < 0 {
+				return nil, fmt.Errorf("fuzzed constructor argument '%s' is not an argument of the constructor of contract %s", name, contract.Name())
+			}
+			if replayedValue, ok := f.replayedConstructorArgs[contract.Name()][name]; ok {
+				generatedArgs[name] = replayedValue
+				jsonArgs[name] = replayedValue
+				continue
+			}
+			value := valuegeneration.GenerateAbiValue(valueGenerator, &inputs[index].Type)
+			encoded, err := valuegeneration.EncodeJSONArgumentsToMap(inputs[index:index+1], []any{value})
+			if err != nil {
+				return nil, err
+			}
+			generatedArgs[name] = encoded[name]
+			jsonArgs[name] = encoded[name]
+		}
+		f.fuzzedConstructorArgs[contract.Name()] = generatedArgs
+		logging.GlobalLogger.Info().Str("contract", contract.Name()).Interface("constructorArgs", generatedArgs).
+			Msgf("Generated fuzzed constructor arguments for %s: %v", contract.Name(), describeJSONArgs(generatedArgs))
+	}
+
+	// Resolve any references to previously deployed contracts, so we can report a clear error if a contract has not
+	// been deployed yet. Resolved addresses are added to our value set, so they are used as inputs when fuzzing.
+	for _, referencedName := range valuegeneration.DeployedContractReferences(jsonArgs) {
+		referencedAddr, ok := deployedContractAddr[referencedName]
+		if !ok {
+			if slices.Contains(f.config.Fuzzing.DeploymentOrder, referencedName) {
+				return nil, fmt.Errorf("constructor arguments for contract %s reference contract %s, which is not deployed before it in the deployment order", contract.Name(), referencedName)
+			}
+			return nil, fmt.Errorf("constructor arguments for contract %s reference contract %s, which is not in the deployment order", contract.Name(), referencedName)
+		}
+		f.baseValueSet.AddAddress(referencedAddr)
+		if f.learnedValueSet != nil {
+			f.learnedValueSet.AddAddress(referencedAddr)
+		}
+	}
+	return valuegeneration.DecodeJSONArgumentsFromMap(inputs, jsonArgs, deployedContractAddr)
+}
+
+// loadReplayedConstructorArgs loads the fuzzed constructor argument values recorded in the provided corpus by a
+// previous fuzzing campaign, if we are configured to replay them, so contracts are deployed with them again. Recorded
+// values which cannot be read are not fatal, we simply generate new values.
+func (f *Fuzzer) loadReplayedConstructorArgs(c *corpus.Corpus) {
+	f.replayedConstructorArgs = nil
+	if !f.config.Fuzzing.ReplayFuzzedConstructorArgs {
+		return
+	}
+	replayedConstructorArgs, err := c.LoadConstructorArgs()
+	if err != nil {
+		logging.GlobalLogger.Warn().Err(err).Msgf("Ignoring recorded constructor arguments: %v", err)
+		return
+	}
+	f.replayedConstructorArgs = replayedConstructorArgs
+}
+
+// FuzzedConstructorArgs returns the constructor argument values generated for each contract deployed in the current
+// (or most recent) fuzzing campaign, keyed by contract name and then argument name, in the form they would be
+// provided through the config.
+func (f *Fuzzer) FuzzedConstructorArgs() map[string]map[string]any {
+	return f.fuzzedConstructorArgs
+}
+
+// describeJSONArgs obtains a text-based printable description of the provided JSON-encodable argument values.
+// Returns the JSON encoding of the values, or a generic description if they could not be encoded.
+func describeJSONArgs(args any) string {
+	b, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprintf("%v", args)
+	}
+	return string(b)
+}
+
+// defaultNewValueGeneratorFunc is a NewValueGeneratorFunc which creates a valuegeneration.MutatingValueGenerator with
+// a default configuration. Returns the value generator or an error, if one occurs.
+func defaultNewValueGeneratorFunc(fuzzer *Fuzzer, valueSet *valuegeneration.ValueSet, randomProvider *rand.Rand) (valuegeneration.ValueGenerator, error) {
+	// Create the value generator config for the worker and its sequence generator.
 	valueGenConfig := &valuegeneration.MutatingValueGeneratorConfig{
-		MinMutationRounds:               0,
-		MaxMutationRounds:               1,
-		GenerateRandomAddressBias:       0.5,
-		GenerateRandomIntegerBias:       0.5,
-		GenerateRandomStringBias:        0.5,
-		GenerateRandomBytesBias:         0.5,
-		MutateAddressProbability:        0.1,
-		MutateArrayStructureProbability: 0.1,
-		MutateBoolProbability:           0.1,
-		MutateBytesProbability:          0.1,
-		MutateBytesGenerateNewBias:      0.45,
-		MutateFixedBytesProbability:     0.1,
-		MutateStringProbability:         0.1,
-		MutateStringGenerateNewBias:     0.7,
-		MutateIntegerProbability:        0.1,
-		MutateIntegerGenerateNewBias:    0.5,
+		MinMutationRounds:                    0,
+		MaxMutationRounds:                    1,
+		GenerateRandomAddressBias:            0.5,
+		GenerateSpecialAddressBias:           0.1,
+		SpecialAddressZeroWeight:             10,
+		SpecialAddressPrecompileWeight:       5,
+		SpecialAddressSenderWeight:           20,
+		SpecialAddressDeployedContractWeight: 20,
+		SpecialAddressTargetContractWeight:   20,
+		GenerateRandomIntegerBias:            0.5,
+		GenerateRandomStringBias:             0.5,
+		GenerateRandomBytesBias:              0.5,
+		MutateAddressProbability:             0.1,
+		MutateArrayStructureProbability:      0.1,
+		MutateBoolProbability:                0.1,
+		MutateBytesProbability:               0.1,
+		MutateBytesGenerateNewBias:           0.45,
+		MutateFixedBytesProbability:          0.1,
+		MutateStringProbability:              0.1,
+		MutateStringGenerateNewBias:          0.7,
+		MutateIntegerProbability:             0.1,
+		MutateIntegerGenerateNewBias:         0.5,
+		GenerateValidSignatureProbability:    0.8,
 		RandomValueGeneratorConfig: &valuegeneration.RandomValueGeneratorConfig{
 			GenerateRandomArrayMinSize:  0,
 			GenerateRandomArrayMaxSize:  100,
@@ -385,6 +985,20 @@ func defaultNewCallSequenceGeneratorConfigFunc(fuzzer *Fuzzer, valueSet *valuege
 		},
 	}
 	valueGenerator := valuegeneration.NewMutatingValueGenerator(valueGenConfig, valueSet, randomProvider)
+	valueGenerator.SetSenderAddresses(fuzzer.senders)
+	valueGenerator.SetSignerKeys(fuzzer.signerKeys)
+	return valueGenerator, nil
+}
+
+// defaultNewCallSequenceGeneratorConfigFunc is a NewCallSequenceGeneratorConfigFunc which creates a
+// CallSequenceGeneratorConfig with a default configuration. The underlying value generator is created using the
+// Fuzzer's NewValueGeneratorFunc hook. Returns the config or an error, if one occurs.
+func defaultNewCallSequenceGeneratorConfigFunc(fuzzer *Fuzzer, valueSet *valuegeneration.ValueSet, randomProvider *rand.Rand) (*CallSequenceGeneratorConfig, error) {
+	// Create the underlying value generator for the worker and its sequence generator.
+	valueGenerator, err := fuzzer.Hooks.NewValueGeneratorFunc(fuzzer, valueSet, randomProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create value generator: %v", err)
+	}
 
 	// Create a sequence generator config which uses the created value generator.
 	sequenceGenConfig := &CallSequenceGeneratorConfig{
@@ -397,6 +1011,13 @@ func defaultNewCallSequenceGeneratorConfigFunc(fuzzer *Fuzzer, valueSet *valuege
 		RandomMutatedCorpusTailWeight:            10,
 		RandomMutatedSpliceAtRandomWeight:        20,
 		RandomMutatedInterleaveAtRandomWeight:    10,
+		TargetedArgumentMutationProbability:      0.5,
+		GenerateTimestampArgumentProbability:     0.5,
+		TimestampArgumentWindow:                  2_592_000,
+		DurationArgumentMax:                      31_536_000,
+		CopyCallArgumentProbability:              float32(fuzzer.config.Fuzzing.CopyCallArgumentProbability),
+		MutateBlockDelayProbability:              0.1,
+		MutateGasLimitProbability:                0.1,
 		ValueGenerator:                           valueGenerator,
 	}
 	return sequenceGenConfig, nil
@@ -421,7 +1042,7 @@ func (f *Fuzzer) spawnWorkersLoop(baseTestChain *chain.TestChain) error {
 	for i := 0; i < len(availableWorkerSlotQueue); i++ {
 		availableWorkerSlotQueue[i] = availableWorkerSlot{
 			index:          i,
-			randomProvider: randomutils.ForkRandomProvider(f.randomProvider),
+			randomProvider: rand.New(rand.NewSource(randomutils.DeriveSeed(f.seed, i))),
 		}
 	}
 
@@ -429,7 +1050,7 @@ func (f *Fuzzer) spawnWorkersLoop(baseTestChain *chain.TestChain) error {
 	working := !utils.CheckContextDone(f.ctx)
 
 	// Log that we are about to create the workers and start fuzzing
-	fmt.Printf("Creating %d workers ...\n", f.config.Fuzzing.Workers)
+	logging.GlobalLogger.Info().Int("workers", f.config.Fuzzing.Workers).Msgf("Creating %d workers ...", f.config.Fuzzing.Workers)
 	var err error
 	for err == nil && working {
 		// Send an item into our channel to queue up a spot. This will block us if we hit capacity until a worker
@@ -467,6 +1088,11 @@ func (f *Fuzzer) spawnWorkersLoop(baseTestChain *chain.TestChain) error {
 					err = workerErr
 				}
 
+				// Retain the values the worker learned, as its value set is discarded with it.
+				f.learnedValueSetLock.Lock()
+				f.learnedValueSet.Merge(worker.ValueSet())
+				f.learnedValueSetLock.Unlock()
+
 				// If we received a cancelled signal, signal our exit from the working loop.
 				if working && ctxCancelled {
 					working = false
@@ -484,13 +1110,33 @@ func (f *Fuzzer) spawnWorkersLoop(baseTestChain *chain.TestChain) error {
 				err = workerDestroyedErr
 			}
 
+			// If the worker exited after reaching a resource limit, it is about to be recreated, so publish an event
+			// indicating it was reset.
+			if err == nil && worker.resetCause != "" {
+				workerResetErr := f.Events.WorkerReset.Publish(FuzzerWorkerResetEvent{Worker: worker, Cause: worker.resetCause})
+				if workerResetErr != nil {
+					err = workerResetErr
+				}
+			}
+
+			// Close the worker's chain, releasing the memory it consumed before the worker is regenerated.
+			if worker != nil && worker.chain != nil {
+				workerChainCloseErr := worker.chain.Close()
+				if err == nil && workerChainCloseErr != nil {
+					err = workerChainCloseErr
+				}
+			}
+
 			// Unblock our channel by freeing our capacity of another item, making way for another worker.
 			<-threadReserveChannel
 		}(workerSlotInfo)
 	}
 
-	// Explicitly call cancel on our context to ensure all threads exit if we encountered an error.
-	if f.ctxCancelFunc != nil {
+	// Explicitly call cancel on our context to ensure all threads exit. If we encountered an error, we do not wait
+	// for workers to finish what they are doing.
+	if err != nil {
+		f.forceStop()
+	} else if f.ctxCancelFunc != nil {
 		f.ctxCancelFunc()
 	}
 
@@ -516,35 +1162,109 @@ func (f *Fuzzer) spawnWorkersLoop(baseTestChain *chain.TestChain) error {
 // is encountered or the fuzzing operation has completed. Its execution can be cancelled using the Stop method.
 // Returns an error if one is encountered.
 func (f *Fuzzer) Start() error {
+	return f.StartWithContext(context.Background())
+}
+
+// StartWithContext begins a fuzzing operation like Start, which is also stopped gracefully (as with the Stop method)
+// once the provided context is cancelled.
+// Returns an error if one is encountered.
+func (f *Fuzzer) StartWithContext(ctx context.Context) error {
 	// Define our variable to catch errors
 	var err error
 
-	// While we're fuzzing, we'll want to have an initialized random provider.
-	f.randomProvider = rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	// Create our running context (allows us to cancel across threads)
-	f.ctx, f.ctxCancelFunc = context.WithCancel(context.Background())
+	// Create our running context (allows us to cancel across threads), and the context which forces running
+	// operations to stop immediately.
+	f.forceStopCtx, f.forceStopCtxCancelFunc = context.WithCancel(context.Background())
+	f.ctx, f.ctxCancelFunc = context.WithCancel(ctx)
+	f.stopReasonLock.Lock()
+	f.stopReason = StopReasonNone
+	f.stopReasonLock.Unlock()
 
 	// If we set a timeout, create the timeout context now, as we're about to begin fuzzing.
 	if f.config.Fuzzing.Timeout > 0 {
-		fmt.Printf("Running with timeout of %d seconds\n", f.config.Fuzzing.Timeout)
+		logging.GlobalLogger.Info().Int("timeout", f.config.Fuzzing.Timeout).Msgf("Running with timeout of %d seconds", f.config.Fuzzing.Timeout)
 		f.ctx, f.ctxCancelFunc = context.WithTimeout(f.ctx, time.Duration(f.config.Fuzzing.Timeout)*time.Second)
 	}
 
-	// Set up the corpus
-	f.corpus, err = corpus.NewCorpus(f.config.Fuzzing.CorpusDirectory)
-	if err != nil {
-		return err
+	// Set up the corpus, carrying over the corpus of the previous campaign if our contracts were recompiled.
+	if f.retainedCorpus != nil {
+		f.corpus, f.retainedCorpus = f.retainedCorpus, nil
+	} else {
+		f.corpus, err = corpus.NewCorpus(f.config.Fuzzing.CorpusDirectory)
+		if err != nil {
+			return err
+		}
+
+		// Publish the call sequences added to our corpus as our own events. A corpus carried over from a previous
+		// campaign was already subscribed to when it was created.
+		f.corpus.Events.CallSequenceAdded.Subscribe(func(event corpus.CallSequenceAddedEvent) error {
+			return f.Events.CorpusEntryAdded.Publish(FuzzerCorpusEntryAddedEvent{
+				Fuzzer:       f,
+				CallSequence: event.CallSequence,
+				Metadata:     event.Metadata,
+			})
+		})
+	}
+	f.corpus.SetPowerScheduleEnabled(f.config.Fuzzing.CorpusPowerScheduleEnabled)
+	f.corpus.SetCompressionEnabled(f.config.Fuzzing.CorpusCompression)
+	f.corpus.SetFullReplayForced(f.config.Fuzzing.CorpusForceFullReplay)
+	f.corpus.SetStateDeduplication(f.config.Fuzzing.CorpusStateDeduplication, f.config.Fuzzing.CorpusStateDeduplicationMaxCoverageDelta)
+	f.corpus.SetCoverageFeedback(f.config.Fuzzing.CoverageFeedback)
+	f.corpus.SetHitCountsEnabled(f.config.Fuzzing.CoverageHitCounts)
+	f.corpus.SetReplayWorkers(f.config.Fuzzing.Workers)
+	f.corpus.SetProxyImplementations(f.config.Fuzzing.ProxyImplementations)
+
+	// If we are resuming a campaign, read the checkpoint it wrote, verifying we can resume from it.
+	var checkpoint *fuzzerCheckpoint
+	if f.config.Fuzzing.Resume {
+		checkpoint, err = f.readCheckpoint()
+		if err != nil {
+			return err
+		}
 	}
 
+	// While we're fuzzing, we'll want to have an initialized random provider. If we are resuming a campaign, we derive
+	// our seed from its seed. Otherwise, if no seed was configured, we derive one from the current time. Either way,
+	// we print it so the campaign can be reproduced.
+	if checkpoint != nil {
+		f.seed = checkpoint.resumedSeed()
+	} else if f.config.Fuzzing.Seed != nil {
+		f.seed = *f.config.Fuzzing.Seed
+	} else {
+		f.seed = time.Now().UnixNano()
+	}
+	f.randomProvider = rand.New(rand.NewSource(f.seed))
+	logging.GlobalLogger.Info().Int64("seed", f.seed).Msgf("Using random seed %d", f.seed)
+	f.corpus.SetRandomProvider(randomutils.ForkRandomProvider(f.randomProvider))
+
+	// Merge any value set persisted by a previous campaign into our base value set. A value set which cannot be read
+	// (e.g. one written in an older format) is not fatal, we simply learn values from scratch.
+	if f.config.Fuzzing.ValueSetSeeding.PersistValueSet {
+		err = f.corpus.LoadValueSet(f.baseValueSet, f.config.Fuzzing.ValueSetSeeding.MaxPersistedValues)
+		if err != nil {
+			logging.GlobalLogger.Warn().Err(err).Msgf("Ignoring persisted value set: %v", err)
+		}
+	}
+	f.learnedValueSet = f.baseValueSet.Clone()
+
 	// Initialize our metrics and valueGenerator.
-	f.metrics = newFuzzerMetrics(f.config.Fuzzing.Workers)
+	f.metrics = newFuzzerMetrics(f.config.Fuzzing.Workers, f.corpus, f.FunctionCoverageSummaries)
+	f.shrinkCandidates = make(chan *shrinkCandidate, f.config.Fuzzing.Workers)
 
 	// Initialize our test cases and providers
 	f.testCasesLock.Lock()
 	f.testCases = make([]TestCase, 0)
 	f.testCasesFinished = make(map[string]TestCase)
+	f.testCaseFailures = make(map[string]map[common.Hash]struct{})
 	f.testCasesLock.Unlock()
+	f.unshrunkCallSequencesLock.Lock()
+	f.unshrunkCallSequences = make(map[int]calls.CallSequence)
+	f.unshrunkCallSequencesLock.Unlock()
+
+	// If we are resuming a campaign, restore its state now that our metrics and corpus exist.
+	if checkpoint != nil {
+		f.restoreCheckpoint(checkpoint)
+	}
 
 	// Create our test chain
 	baseTestChain, err := f.createTestChain()
@@ -552,31 +1272,90 @@ func (f *Fuzzer) Start() error {
 		return err
 	}
 
-	// Set it up with our deployment/setup strategy defined by the fuzzer.
+	// Set it up with our deployment/setup strategy defined by the fuzzer, replaying the fuzzed constructor arguments of
+	// a previous campaign if we are configured to.
+	f.loadReplayedConstructorArgs(f.corpus)
 	err = f.Hooks.ChainSetupFunc(f, baseTestChain)
 	if err != nil {
 		return err
 	}
 
-	// Initialize our coverage maps by measuring the coverage we get from the corpus.
+	// Verify there are methods for the fuzzer to call, and report them.
+	err = f.reportTargetMethods(baseTestChain)
+	if err != nil {
+		return err
+	}
+
+	// If we generate Foundry reproducers or failure files, record the deployments they should perform.
+	if f.config.Fuzzing.Testing.GenerateFoundryReproducers || f.config.Fuzzing.Testing.FailuresDirectory != "" {
+		f.recordReproducerDeployments(baseTestChain)
+	}
+
+	// Initialize our coverage maps by measuring the coverage we get from the corpus, or loading the coverage maps
+	// persisted for contracts deployed with the same constructor arguments.
+	f.corpus.SetFuzzedConstructorArgs(f.fuzzedConstructorArgs)
 	err = f.corpus.Initialize(baseTestChain, f.contractDefinitions)
 	if err != nil {
 		return err
 	}
 
+	// If a metrics address was configured, serve live metrics of our campaign until it ends.
+	if f.config.Metrics.Address != "" {
+		server, err := newMetricsServer(f, f.config.Metrics.Address)
+		if err != nil {
+			return err
+		}
+		server.start()
+		defer server.close()
+		logging.GlobalLogger.Info().Str("address", server.address()).Msgf("Serving metrics at http://%v/metrics and http://%v/status", server.address(), server.address())
+	}
+
+	// If a status screen was requested, show it in place of scrolling log output, unless stdout is not a terminal.
+	var screen *statusScreen
+	if f.config.Logging.TUI {
+		if colors.StdoutIsTerminal() {
+			screen = newStatusScreen(f, os.Stdout, os.Stdin)
+			screen.start()
+			defer screen.close()
+		} else {
+			logging.GlobalLogger.Warn().Msg("Not showing the status screen, as stdout is not a terminal")
+		}
+	}
+
 	// Start our printing loop now that we're about to begin fuzzing.
 	go f.printMetricsLoop()
 
+	// Periodically checkpoint our campaign, if we have a corpus directory to write checkpoints to.
+	if f.config.Fuzzing.CorpusDirectory != "" && f.config.Fuzzing.CheckpointInterval > 0 {
+		go f.checkpointLoop()
+	}
+
 	// Publish a fuzzer starting event.
 	err = f.Events.FuzzerStarting.Publish(FuzzerStartingEvent{Fuzzer: f})
 	if err != nil {
 		return err
 	}
 
-	// Run the main worker loop
+	// Watch for our stop conditions as we begin fuzzing, then run the main worker loop.
+	f.stopConditions.start()
+	f.recordCampaignTimes(time.Now(), time.Time{})
 	err = f.spawnWorkersLoop(baseTestChain)
+	f.recordCampaignTimes(f.campaignStartTime, time.Now())
+	f.recordFinalStopReason(err)
+
+	// Close our status screen, so the results of our campaign are logged to stdout.
+	if screen != nil {
+		screen.close()
+	}
+
+	// Release our base chain, as no more workers will clone it.
+	baseTestChainCloseErr := baseTestChain.Close()
+	if err == nil {
+		err = baseTestChainCloseErr
+	}
 
 	// NOTE: After this point, we capture errors but do not return immediately, as we want to exit gracefully.
+	f.forceStopCtxCancelFunc()
 
 	// If we have coverage enabled and a corpus directory set, write the corpus. We do this even if we had a
 	// previous error, as we don't want to lose corpus entries.
@@ -585,6 +1364,39 @@ func (f *Fuzzer) Start() error {
 		if err == nil {
 			err = corpusFlushErr
 		}
+
+		// Persist our coverage maps too, so the next campaign can skip replaying the corpus if the contracts have not
+		// changed.
+		if corpusFlushErr == nil {
+			coverageMapsWriteErr := f.corpus.WriteCoverageMaps()
+			if err == nil {
+				err = coverageMapsWriteErr
+			}
+		}
+
+		// Write our coverage reports alongside the corpus.
+		coverageReportsErr := f.writeCoverageReports()
+		if err == nil {
+			err = coverageReportsErr
+		}
+	}
+
+	// If we generated constructor arguments and a corpus directory is set, record them so later campaigns can replay
+	// them.
+	if len(f.fuzzedConstructorArgs) > 0 {
+		constructorArgsFlushErr := f.corpus.FlushConstructorArgs(f.fuzzedConstructorArgs)
+		if err == nil {
+			err = constructorArgsFlushErr
+		}
+	}
+
+	// If we are persisting our value set and a corpus directory is set, write the values learned during this campaign
+	// so later campaigns do not need to learn them again.
+	if f.config.Fuzzing.ValueSetSeeding.PersistValueSet {
+		valueSetFlushErr := f.corpus.FlushValueSet(f.learnedValueSet, f.config.Fuzzing.ValueSetSeeding.MaxPersistedValues)
+		if err == nil {
+			err = valueSetFlushErr
+		}
 	}
 
 	// Publish a fuzzer stopping event.
@@ -593,33 +1405,108 @@ func (f *Fuzzer) Start() error {
 		err = fuzzerStoppingErr
 	}
 
+	// Checkpoint our campaign now that its test cases are finalized, so a later campaign may resume from it.
+	checkpointErr := f.writeCheckpoint()
+	if err == nil && checkpointErr != nil {
+		err = checkpointErr
+	}
+
+	// Write our test results to any configured outputs, even if the campaign was interrupted.
+	resultOutputsErr := f.writeTestResultOutputs()
+	if err == nil && resultOutputsErr != nil {
+		err = resultOutputsErr
+	}
+
+	// Store the call sequences which grew contract storage the most, so they may be replayed.
+	storageGrowthErr := f.writeStorageGrowthSequences()
+	if err == nil && storageGrowthErr != nil {
+		err = storageGrowthErr
+	}
+
 	// Print our results on exit.
+	f.PrintFunctionCoverageSummary()
+	f.printHighRevertRateWarnings()
+	f.printStorageGrowthReport()
 	f.printExitingResults()
 
+	// Publish a campaign finished event, as the last event of our campaign.
+	campaignFinishedErr := f.Events.CampaignFinished.Publish(FuzzerCampaignFinishedEvent{
+		Fuzzer:     f,
+		StopReason: f.StopReason(),
+		TestCases:  f.TestCases(),
+		Err:        err,
+	})
+	if err == nil && campaignFinishedErr != nil {
+		err = campaignFinishedErr
+	}
+
 	// Return any encountered error.
 	return err
 }
 
-// Stop stops a running operation invoked by the Start method. This method may return before complete operation teardown
-// occurs.
-func (f *Fuzzer) Stop() {
-	// Call the cancel function on our running context to stop all working goroutines
-	if f.ctxCancelFunc != nil {
-		f.ctxCancelFunc()
+// PauseGeneration pauses workers from testing new call sequences until ResumeGeneration is called. Workers finish
+// testing and shrinking their current call sequence before they pause. Timeouts and stop conditions continue to be
+// evaluated while workers are paused.
+func (f *Fuzzer) PauseGeneration() {
+	f.generationPausedLock.Lock()
+	defer f.generationPausedLock.Unlock()
+	if f.generationResumed == nil {
+		f.generationResumed = make(chan struct{})
+	}
+}
+
+// ResumeGeneration resumes workers paused from testing new call sequences by PauseGeneration.
+func (f *Fuzzer) ResumeGeneration() {
+	f.generationPausedLock.Lock()
+	defer f.generationPausedLock.Unlock()
+	if f.generationResumed != nil {
+		close(f.generationResumed)
+		f.generationResumed = nil
+	}
+}
+
+// GenerationPaused indicates whether workers are paused from testing new call sequences by PauseGeneration.
+func (f *Fuzzer) GenerationPaused() bool {
+	f.generationPausedLock.Lock()
+	defer f.generationPausedLock.Unlock()
+	return f.generationResumed != nil
+}
+
+// waitWhileGenerationPaused blocks while workers are paused from testing new call sequences, until they are resumed or
+// the fuzzing operation is cancelled.
+func (f *Fuzzer) waitWhileGenerationPaused() {
+	f.generationPausedLock.Lock()
+	generationResumed := f.generationResumed
+	f.generationPausedLock.Unlock()
+	if generationResumed == nil {
+		return
+	}
+	select {
+	case <-generationResumed:
+	case <-f.ctx.Done():
 	}
 }
 
+// Stop stops a running operation invoked by the Start method. Workers finish testing their current call sequence, and
+// any call sequence being shrunk continues to shrink for up to the configured stop shrink timeout, before results are
+// written. This method may return before complete operation teardown occurs.
+func (f *Fuzzer) Stop() {
+	f.stop(StopReasonInterrupted)
+}
+
 // printMetricsLoop prints metrics to the console in a loop until ctx signals a stopped operation.
 func (f *Fuzzer) printMetricsLoop() {
 	// Define our start time
 	startTime := time.Now()
 
 	// Define cached variables for our metrics to calculate deltas.
-	lastCallsTested := big.NewInt(0)
-	lastSequencesTested := big.NewInt(0)
+	lastCallsTested := f.metrics.CallsTested()
+	lastSequencesTested := f.metrics.SequencesTested()
 	lastWorkerStartupCount := big.NewInt(0)
+	lastShrinkCandidatesTested := big.NewInt(0)
 
 	lastPrintedTime := time.Time{}
+	lastRevertRateWarningTime := startTime
 	for !utils.CheckContextDone(f.ctx) {
 		// Obtain our metrics
 		callsTested := f.metrics.CallsTested()
@@ -630,35 +1517,123 @@ func (f *Fuzzer) printMetricsLoop() {
 		secondsSinceLastUpdate := time.Since(lastPrintedTime).Seconds()
 
 		// Print a metrics update
-		fmt.Printf(
-			"fuzz: elapsed: %s, call: %d (%d/sec), seq/s: %d, resets/s: %d, cov: %d\n",
-			time.Since(startTime).Round(time.Second),
-			callsTested,
-			uint64(float64(new(big.Int).Sub(callsTested, lastCallsTested).Uint64())/secondsSinceLastUpdate),
-			uint64(float64(new(big.Int).Sub(sequencesTested, lastSequencesTested).Uint64())/secondsSinceLastUpdate),
-			uint64(float64(new(big.Int).Sub(workerStartupCount, lastWorkerStartupCount).Uint64())/secondsSinceLastUpdate),
-			f.corpus.ActiveCallSequenceCount(),
-		)
+		elapsed := time.Since(startTime).Round(time.Second)
+		callsPerSecond := uint64(float64(new(big.Int).Sub(callsTested, lastCallsTested).Uint64()) / secondsSinceLastUpdate)
+		sequencesPerSecond := uint64(float64(new(big.Int).Sub(sequencesTested, lastSequencesTested).Uint64()) / secondsSinceLastUpdate)
+		resetsPerSecond := uint64(float64(new(big.Int).Sub(workerStartupCount, lastWorkerStartupCount).Uint64()) / secondsSinceLastUpdate)
+		corpusSize := f.corpus.ActiveCallSequenceCount()
+		duplicateCallSequences := f.metrics.CorpusDuplicateCallSequences()
+		targetedArgumentMutations := f.metrics.TargetedArgumentMutations()
+		productiveArgumentMutations := f.metrics.ProductiveArgumentMutations()
+		logging.GlobalLogger.Info().
+			Dur("elapsed", elapsed).
+			Uint64("calls", callsTested.Uint64()).
+			Uint64("callsOutOfGas", f.metrics.CallsOutOfGas().Uint64()).
+			Uint64("callsPerSecond", callsPerSecond).
+			Uint64("sequencesPerSecond", sequencesPerSecond).
+			Uint64("resetsPerSecond", resetsPerSecond).
+			Int("corpusSize", corpusSize).
+			Uint64("duplicateCallSequences", duplicateCallSequences).
+			Uint64("targetedArgumentMutations", targetedArgumentMutations.Uint64()).
+			Uint64("productiveArgumentMutations", productiveArgumentMutations.Uint64()).
+			Msgf(
+				"fuzz: elapsed: %s, call: %d (%d/sec), seq/s: %d, resets/s: %d, cov: %d, dup: %d, arg-mut: %d targeted, %d productive",
+				elapsed, callsTested, callsPerSecond, sequencesPerSecond, resetsPerSecond, corpusSize,
+				duplicateCallSequences, targetedArgumentMutations, productiveArgumentMutations,
+			)
+
+		// If workers tested shrink candidates since our last update, print the shrinking throughput of each worker.
+		shrinkCandidatesTested := f.metrics.ShrinkCandidatesTested()
+		if shrinkCandidatesTested.Cmp(lastShrinkCandidatesTested) > 0 {
+			workerShrinkThroughputs := make([]string, 0)
+			for workerIndex, throughput := range f.metrics.WorkerShrinkThroughputs() {
+				if throughput > 0 {
+					workerShrinkThroughputs = append(workerShrinkThroughputs, fmt.Sprintf("%d: %d/sec", workerIndex, uint64(throughput)))
+				}
+			}
+			shrinkCandidatesPerSecond := uint64(float64(new(big.Int).Sub(shrinkCandidatesTested, lastShrinkCandidatesTested).Uint64()) / secondsSinceLastUpdate)
+			logging.GlobalLogger.Info().
+				Uint64("shrinkCandidates", shrinkCandidatesTested.Uint64()).
+				Uint64("shrinkCandidatesPerSecond", shrinkCandidatesPerSecond).
+				Floats64("workerShrinkThroughputs", f.metrics.WorkerShrinkThroughputs()).
+				Msgf(
+					"shrink: candidates: %d (%d/sec), worker throughput: [%s]",
+					shrinkCandidatesTested, shrinkCandidatesPerSecond, strings.Join(workerShrinkThroughputs, ", "),
+				)
+		}
+
+		// Periodically warn about methods which revert too often, as they may indicate a broken harness.
+		if time.Since(lastRevertRateWarningTime) >= highRevertRateWarningInterval {
+			f.printHighRevertRateWarnings()
+			lastRevertRateWarningTime = time.Now()
+		}
 
 		// Update our delta tracking metrics
 		lastPrintedTime = time.Now()
+		lastShrinkCandidatesTested = shrinkCandidatesTested
 		lastCallsTested = callsTested
 		lastSequencesTested = sequencesTested
 		lastWorkerStartupCount = workerStartupCount
 
-		// If we reached our transaction threshold, halt
-		testLimit := f.config.Fuzzing.TestLimit
-		if testLimit > 0 && (!callsTested.IsUint64() || callsTested.Uint64() >= testLimit) {
-			fmt.Printf("transaction test limit reached, halting now ...\n")
-			f.Stop()
-			break
-		}
-
 		// Sleep some time between print iterations
 		time.Sleep(time.Second * 3)
 	}
 }
 
+// testCaseResultMessage obtains the message to print for a TestCase's result. For failed test cases, this includes the
+// random seed of the campaign and any fuzzed constructor arguments contracts were deployed with, so the failure can be
+// reproduced.
+func (f *Fuzzer) testCaseResultMessage(testCase TestCase) string {
+	msg := testCase.Message()
+	if testCase.Status() == TestCaseStatusFailed {
+		msg = fmt.Sprintf("%s\n[Seed] %d", strings.TrimRight(msg, "\n"), f.seed)
+		if len(f.fuzzedConstructorArgs) > 0 {
+			msg = fmt.Sprintf("%s\n[Fuzzed Constructor Arguments] %s", msg, describeJSONArgs(f.fuzzedConstructorArgs))
+		}
+	}
+	return msg
+}
+
+// logTestCaseResult logs the result of the provided TestCase. In the text log format, this is its status, name and
+// result message. In the JSON log format, the result is instead described by structured fields, including the call
+// sequence which produced it, if any.
+// If failed test cases continue to be tested, the amount of distinct failures is included for failed test cases. The
+// caller must hold testCasesLock, or call this once workers have stopped.
+func (f *Fuzzer) logTestCaseResult(testCase TestCase) {
+	name := strings.TrimSpace(testCase.Name())
+	if logging.GlobalLogger.Format() == logging.LogFormatJSON {
+		event := logging.GlobalLogger.Info()
+		if testCase.Status() == TestCaseStatusFailed {
+			event = logging.GlobalLogger.Error().Int64("seed", f.seed)
+			if len(f.fuzzedConstructorArgs) > 0 {
+				event = event.Interface("constructorArgs", f.fuzzedConstructorArgs)
+			}
+		}
+		event = event.Str("testCase", name).Str("testCaseId", testCase.ID()).Str("status", string(testCase.Status()))
+		if testCase.Status() == TestCaseStatusFailed && f.retestFailedTestCases() {
+			event = event.Int("distinctFailures", len(f.testCaseFailures[testCase.ID()]))
+		}
+		if optimizationTestCase, ok := testCase.(*OptimizationTestCase); ok && optimizationTestCase.Value() != nil {
+			event = event.Str("value", optimizationTestCase.Value().String())
+		}
+		if callSequence := testCase.CallSequence(); callSequence != nil {
+			event = event.Int("sequenceLength", len(*callSequence)).Interface("callSequence", *callSequence)
+		}
+		event.Msgf("[%s] %s", testCase.Status(), name)
+		return
+	}
+
+	if testCase.Status() == TestCaseStatusFailed && f.retestFailedTestCases() {
+		name = fmt.Sprintf("%s (%d distinct failure(s))", name, len(f.testCaseFailures[testCase.ID()]))
+	}
+	msg := strings.TrimSpace(f.testCaseResultMessage(testCase))
+	if msg != "" {
+		logging.GlobalLogger.Info().Msgf("[%s] %s\n%s", testCase.Status(), name, msg)
+	} else {
+		logging.GlobalLogger.Info().Msgf("[%s] %s", testCase.Status(), name)
+	}
+}
+
 // printExitingResults prints the TestCase results prior to the fuzzer exiting.
 func (f *Fuzzer) printExitingResults() {
 	// Define the order our test cases should be sorted by when considering status.
@@ -684,21 +1659,20 @@ func (f *Fuzzer) printExitingResults() {
 
 	// Define variables to track our final test count.
 	var (
-		testCountPassed int
-		testCountFailed int
+		testCountPassed    int
+		testCountFailed    int
+		distinctFailureSum int
 	)
 
 	// Print the results of each individual test case.
-	fmt.Printf("\n")
-	fmt.Printf("Fuzzer stopped, test results follow below ...\n")
+	logging.GlobalLogger.Break()
+	stopReason := f.StopReason()
+	logging.GlobalLogger.Info().Str("stopReason", string(stopReason)).Msgf("Fuzzer stopped as %s, test results follow below ...", stopReason.Description())
 	for _, testCase := range f.testCases {
-		// Obtain the test case message. If it is a non-empty string, we format our output for it specially.
-		// Otherwise, we exclude it.
-		msg := strings.TrimSpace(testCase.Message())
-		if msg != "" {
-			fmt.Printf("[%s] %s\n%s\n\n", testCase.Status(), strings.TrimSpace(testCase.Name()), msg)
-		} else {
-			fmt.Printf("[%s] %s\n", testCase.Status(), testCase.Name())
+		// Log the test case result. If it has a message, we separate it from the next result.
+		f.logTestCaseResult(testCase)
+		if strings.TrimSpace(f.testCaseResultMessage(testCase)) != "" {
+			logging.GlobalLogger.Break()
 		}
 
 		// Tally our pass/fail count.
@@ -706,10 +1680,20 @@ func (f *Fuzzer) printExitingResults() {
 			testCountPassed++
 		} else if testCase.Status() == TestCaseStatusFailed {
 			testCountFailed++
+			distinctFailureSum += len(f.testCaseFailures[testCase.ID()])
 		}
 	}
 
-	// Print our final tally of test statuses.
-	fmt.Printf("\n")
-	fmt.Printf("%d test(s) passed, %d test(s) failed\n", testCountPassed, testCountFailed)
+	// Print our final tally of test statuses. If failed tests continued to be tested, we include the amount of
+	// distinct failures found across them.
+	logging.GlobalLogger.Break()
+	event := logging.GlobalLogger.Info().
+		Int("passed", testCountPassed).
+		Int("failed", testCountFailed)
+	if f.retestFailedTestCases() {
+		event.Int("distinctFailures", distinctFailureSum).
+			Msgf("%d test(s) passed, %d test(s) failed with %d distinct failure(s)", testCountPassed, testCountFailed, distinctFailureSum)
+		return
+	}
+	event.Msgf("%d test(s) passed, %d test(s) failed", testCountPassed, testCountFailed)
 }
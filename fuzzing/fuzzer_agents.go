@@ -0,0 +1,95 @@
+package fuzzing
+
+import (
+	"fmt"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/slices"
+)
+
+// isAgentContract indicates whether the provided contract is one of the agent contracts named by the Fuzzer.config,
+// which calls generated by the fuzzer may be routed through.
+func (f *Fuzzer) isAgentContract(contract *fuzzerTypes.Contract) bool {
+	return slices.Contains(f.config.Fuzzing.AgentContracts, contract.Name())
+}
+
+// isAgentExecuteMethod indicates whether the provided method signature is that of the method agent contracts forward
+// calls routed through them with.
+func isAgentExecuteMethod(methodSignature string) bool {
+	return methodSignature == calls.AgentExecuteMethodSignature
+}
+
+// chainSetupDeployAgents deploys every agent contract named by the Fuzzer.config which was not already deployed during
+// chain setup, after verifying each agent is able to forward calls routed through it. The provided lookup of
+// deployed contract addresses by name is used to resolve constructor arguments and is updated with each agent.
+// Returns an error if one occurs.
+func chainSetupDeployAgents(fuzzer *Fuzzer, testChain *chain.TestChain, deployedContractAddr map[string]common.Address) error {
+	for _, agentName := range fuzzer.config.Fuzzing.AgentContracts {
+		// Look for the agent in our compiled contract definitions.
+		index := slices.IndexFunc(fuzzer.contractDefinitions, func(contract *fuzzerTypes.Contract) bool {
+			return contract.Name() == agentName
+		})
+		if index < 0 {
+			return fmt.Errorf("agent contract was not found in the compilation: %v", agentName)
+		}
+		agent := fuzzer.contractDefinitions[index]
+
+		// Verify the agent can forward calls, along with the value sent to it.
+		if !hasAgentExecuteMethod(agent) {
+			return fmt.Errorf("agent contract %v must define a payable %v method", agentName, calls.AgentExecuteMethodSignature)
+		}
+
+		// If the agent was already deployed, there is nothing more to do.
+		if _, deployed := deployedContractAddr[agentName]; deployed {
+			continue
+		}
+
+		// Deploy our agent with any constructor arguments it requires.
+		args := make([]any, 0)
+		if len(agent.CompiledContract().Abi.Constructor.Inputs) > 0 {
+			decoded, err := fuzzer.deploymentConstructorArgs(agent, deployedContractAddr)
+			if err != nil {
+				return err
+			}
+			args = decoded
+		}
+		msgData, err := agent.CompiledContract().GetDeploymentMessageData(args)
+		if err != nil {
+			return fmt.Errorf("initial contract deployment failed for agent contract \"%v\", error: %v", agentName, err)
+		}
+		messageResults, err := chainSetupSendMessage(fuzzer, testChain, nil, msgData)
+		if err != nil {
+			return err
+		}
+		deployedContractAddr[agentName] = messageResults.Receipt.ContractAddress
+	}
+	return nil
+}
+
+// hasAgentExecuteMethod indicates whether the provided contract defines a payable method with the signature of the
+// method agent contracts forward calls routed through them with.
+func hasAgentExecuteMethod(contract *fuzzerTypes.Contract) bool {
+	for _, method := range contract.CompiledContract().Abi.Methods {
+		if isAgentExecuteMethod(method.Sig) && method.IsPayable() {
+			return true
+		}
+	}
+	return false
+}
+
+// selectAgent selects an agent contract to route a call to the provided address through, with a probability of the
+// FuzzingConfig AgentCallProbability. Calls to agents themselves are never routed through an agent.
+// Returns the address of the selected agent, or nil if the call should be sent directly by its sender.
+func (fw *FuzzerWorker) selectAgent(to common.Address) *common.Address {
+	if len(fw.agents) == 0 || slices.Contains(fw.agents, to) {
+		return nil
+	}
+	if fw.randomProvider.Float64() >= fw.fuzzer.config.Fuzzing.AgentCallProbability {
+		return nil
+	}
+	agent := fw.agents[fw.randomProvider.Intn(len(fw.agents))]
+	return &agent
+}
@@ -0,0 +1,216 @@
+package fuzzing
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/config"
+)
+
+// CampaignResult describes the outcome of a fuzzing campaign, for programs embedding medusa which should not depend
+// on the workers, chains or corpus of a Fuzzer.
+type CampaignResult struct {
+	// StopReason describes the reason the campaign stopped.
+	StopReason StopReason `json:"stopReason"`
+
+	// Seed describes the seed the campaign's random provider was created with, which it may be reproduced with.
+	Seed int64 `json:"seed"`
+
+	// Elapsed describes the time the campaign spent fuzzing, excluding compilation and chain setup.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Tests describes the outcome of each test case of the campaign, in the order they were registered.
+	Tests []CampaignTestResult `json:"tests"`
+
+	// Coverage summarizes the coverage the campaign's corpus achieved.
+	Coverage CampaignCoverageSummary `json:"coverage"`
+
+	// Metrics describes the amount of work the campaign performed.
+	Metrics CampaignMetrics `json:"metrics"`
+
+	// StorageGrowthSequences describes the call sequences which populated the most new contract storage slots, most
+	// slots first, if storage growth was recorded.
+	StorageGrowthSequences []StorageGrowthSequence `json:"storageGrowthSequences,omitempty"`
+}
+
+// CampaignTestResult describes the outcome of a test case of a fuzzing campaign.
+type CampaignTestResult struct {
+	// ID describes the unique identifier of the test case.
+	ID string `json:"id"`
+
+	// Name describes the human-readable name of the test case.
+	Name string `json:"name"`
+
+	// Kind describes the kind of test, e.g. "property" or "assertion".
+	Kind string `json:"kind"`
+
+	// Contract describes the name of the contract which defines the test, if known.
+	Contract string `json:"contract,omitempty"`
+
+	// Status describes the status of the test case once the campaign stopped.
+	Status TestCaseStatus `json:"status"`
+
+	// Message describes the result message of the test case, without colors.
+	Message string `json:"message"`
+
+	// CallSequence describes the shrunken call sequence which failed the test, or, for optimization tests, which
+	// obtained its best value. This is nil if there is no such call sequence.
+	CallSequence calls.CallSequence `json:"callSequence,omitempty"`
+
+	// OptimizationValue describes the best value an optimization test obtained, or nil for other tests, or if it did
+	// not obtain a value.
+	OptimizationValue *big.Int `json:"optimizationValue,omitempty"`
+}
+
+// CampaignCoverageSummary summarizes the coverage the corpus of a fuzzing campaign achieved.
+type CampaignCoverageSummary struct {
+	// CoveredInstructions describes the amount of instructions covered by the corpus.
+	CoveredInstructions int `json:"coveredInstructions"`
+
+	// CoveredEdges describes the amount of branch edges covered by the corpus. This is zero unless edge coverage is
+	// recorded.
+	CoveredEdges int `json:"coveredEdges"`
+
+	// Functions summarizes how thoroughly each state changing method was exercised, as FunctionCoverageSummaries
+	// describes.
+	Functions []FunctionCoverageSummary `json:"functions"`
+}
+
+// CampaignMetrics describes the amount of work a fuzzing campaign performed.
+type CampaignMetrics struct {
+	// CallsTested describes the amount of calls the workers tested.
+	CallsTested uint64 `json:"callsTested"`
+
+	// CallsOutOfGas describes the amount of tested calls which failed because they ran out of gas.
+	CallsOutOfGas uint64 `json:"callsOutOfGas"`
+
+	// SequencesTested describes the amount of call sequences the workers tested.
+	SequencesTested uint64 `json:"sequencesTested"`
+
+	// ShrinkCandidatesTested describes the amount of candidate call sequences tested while shrinking.
+	ShrinkCandidatesTested uint64 `json:"shrinkCandidatesTested"`
+
+	// CorpusSize describes the amount of active call sequences in the corpus.
+	CorpusSize int `json:"corpusSize"`
+
+	// WorkerResets describes the amount of times workers were reset, for each cause of a reset.
+	WorkerResets map[WorkerResetCause]uint64 `json:"workerResets"`
+
+	// MethodRevertRates describes how often calls to each method reverted, along with their most common revert
+	// reasons, as MethodRevertRates describes.
+	MethodRevertRates []MethodRevertRate `json:"methodRevertRates"`
+
+	// StorageGrowth describes how the storage and code of each contract grew across the campaign's call sequences,
+	// if storage growth was recorded, as ContractStorageGrowth describes.
+	StorageGrowth []ContractStorageGrowth `json:"storageGrowth,omitempty"`
+}
+
+// Failed returns the results of the tests which failed in the campaign.
+func (r *CampaignResult) Failed() []CampaignTestResult {
+	failed := make([]CampaignTestResult, 0)
+	for _, test := range r.Tests {
+		if test.Status == TestCaseStatusFailed {
+			failed = append(failed, test)
+		}
+	}
+	return failed
+}
+
+// Test returns the result of the test with the provided ID, or nil if the campaign had no such test.
+func (r *CampaignResult) Test(id string) *CampaignTestResult {
+	for i := range r.Tests {
+		if r.Tests[i].ID == id {
+			return &r.Tests[i]
+		}
+	}
+	return nil
+}
+
+// RunCampaign creates a Fuzzer from the provided project configuration, compiling its targets, and runs a fuzzing
+// campaign with it until it reaches a configured limit, a test fails with StopOnFailedTest set, or the provided
+// context is cancelled. Failed tests do not cause an error to be returned, and should be read from the result.
+// Returns the result of the campaign, and an error if one occurred. If the campaign started before the error
+// occurred, its result is returned along with the error.
+func RunCampaign(ctx context.Context, projectConfig config.ProjectConfig) (*CampaignResult, error) {
+	fuzzer, err := NewFuzzer(projectConfig)
+	if err != nil {
+		return nil, err
+	}
+	err = fuzzer.StartWithContext(ctx)
+	return fuzzer.CampaignResult(), err
+}
+
+// CampaignResult obtains the result of the current (or most recent) fuzzing campaign started by Start or
+// StartWithContext. While a campaign runs, the result describes its progress so far.
+// Returns the result of the campaign, or nil if no campaign has been started.
+func (f *Fuzzer) CampaignResult() *CampaignResult {
+	// If we have not started a campaign, we have no result.
+	if f.metrics == nil || f.corpus == nil {
+		return nil
+	}
+	result := &CampaignResult{
+		StopReason: f.StopReason(),
+		Seed:       f.seed,
+		Tests:      make([]CampaignTestResult, 0),
+	}
+	f.stopReasonLock.Lock()
+	if !f.campaignStartTime.IsZero() {
+		if f.campaignStopTime.IsZero() {
+			result.Elapsed = time.Since(f.campaignStartTime)
+		} else {
+			result.Elapsed = f.campaignStopTime.Sub(f.campaignStartTime)
+		}
+	}
+	f.stopReasonLock.Unlock()
+
+	// Describe the outcome of each test, including the call sequence which failed or optimized it.
+	for _, testCaseResult := range f.testCaseResults() {
+		test := CampaignTestResult{
+			ID:       testCaseResult.testCase.ID(),
+			Name:     testCaseResult.testCase.Name(),
+			Kind:     testCaseResult.kind,
+			Contract: testCaseResult.contractName,
+			Status:   testCaseResult.testCase.Status(),
+			Message:  testCaseResult.message,
+		}
+		if callSequence := testCaseResult.testCase.CallSequence(); callSequence != nil {
+			test.CallSequence = *callSequence
+		}
+		if optimizationTestCase, ok := testCaseResult.testCase.(*OptimizationTestCase); ok {
+			test.OptimizationValue = optimizationTestCase.Value()
+		}
+		result.Tests = append(result.Tests, test)
+	}
+
+	// Summarize our coverage and metrics.
+	status := f.sampleStatus()
+	result.Coverage = CampaignCoverageSummary{
+		CoveredInstructions: status.CoveredInstructions,
+		CoveredEdges:        status.CoveredEdges,
+		Functions:           f.FunctionCoverageSummaries(),
+	}
+	result.Metrics = CampaignMetrics{
+		CallsTested:            status.CallsTested,
+		CallsOutOfGas:          status.CallsOutOfGas,
+		SequencesTested:        status.SequencesTested,
+		ShrinkCandidatesTested: f.metrics.ShrinkCandidatesTested().Uint64(),
+		CorpusSize:             status.CorpusSize,
+		WorkerResets:           status.WorkerResetsByCause,
+		MethodRevertRates:      status.MethodRevertRates,
+	}
+	if f.config.Fuzzing.StorageGrowth.Enabled {
+		result.Metrics.StorageGrowth = f.ContractStorageGrowth()
+		result.StorageGrowthSequences = f.StorageGrowthSequences()
+	}
+	return result
+}
+
+// recordCampaignTimes records the times the workers of the current fuzzing campaign started and stopped fuzzing at.
+// The zero stop time indicates they are still fuzzing.
+func (f *Fuzzer) recordCampaignTimes(startTime time.Time, stopTime time.Time) {
+	f.stopReasonLock.Lock()
+	defer f.stopReasonLock.Unlock()
+	f.campaignStartTime, f.campaignStopTime = startTime, stopTime
+}
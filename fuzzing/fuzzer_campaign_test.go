@@ -0,0 +1,80 @@
+package fuzzing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crytic/medusa/compilation"
+	"github.com/crytic/medusa/compilation/platforms"
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/crytic/medusa/utils/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRunCampaign runs a campaign programmatically through RunCampaign, as a program embedding medusa would, and
+// ensures its result describes the outcome of each test along with the campaign's coverage and metrics.
+func TestRunCampaign(t *testing.T) {
+	// Copy our fixture to our test directory, and run our campaign in it to avoid artifact pollution.
+	contractTestPath := testutils.CopyToTestDirectory(t, "testdata/contracts/assertions/assert_and_property_test.sol")
+	testutils.ExecuteInDirectory(t, contractTestPath, func() {
+		// Create a project configuration which compiles our fixture and fuzzes it for up to 10 seconds.
+		compilationConfig, err := compilation.NewCompilationConfigFromPlatformConfig(platforms.NewCryticCompilationConfig(contractTestPath))
+		assert.NoError(t, err)
+		projectConfig, err := config.GetDefaultProjectConfig("")
+		assert.NoError(t, err)
+		projectConfig.Compilation = compilationConfig
+		projectConfig.Fuzzing.Workers = 2
+		projectConfig.Fuzzing.Timeout = 10
+		projectConfig.Fuzzing.DeploymentOrder = []string{"TestContract"}
+		projectConfig.Fuzzing.Testing.AssertionTesting.Enabled = true
+		projectConfig.Fuzzing.Testing.PropertyTesting.Enabled = true
+		projectConfig.Fuzzing.Testing.StopOnFailedTest = false
+
+		// Run our campaign to completion.
+		result, err := RunCampaign(context.Background(), *projectConfig)
+		assert.NoError(t, err)
+		if !assert.NotNil(t, result) {
+			return
+		}
+
+		// Both our property and assertion tests should have failed, each with a shrunken call sequence.
+		failed := result.Failed()
+		assert.Len(t, failed, 2)
+		kinds := make(map[string]bool)
+		for _, test := range failed {
+			kinds[test.Kind] = true
+			assert.EqualValues(t, "TestContract", test.Contract)
+			assert.NotEmpty(t, test.CallSequence)
+			assert.NotEmpty(t, test.Message)
+			assert.EqualValues(t, test.Status, result.Test(test.ID).Status)
+		}
+		assert.True(t, kinds["property"])
+		assert.True(t, kinds["assertion"])
+
+		// Our campaign should report the work it performed and the coverage it achieved.
+		assert.NotEmpty(t, result.StopReason)
+		assert.Greater(t, result.Elapsed, time.Duration(0))
+		assert.LessOrEqual(t, result.Elapsed, 15*time.Second)
+		assert.Greater(t, result.Metrics.CallsTested, uint64(0))
+		assert.Greater(t, result.Metrics.SequencesTested, uint64(0))
+		assert.Greater(t, result.Coverage.CoveredInstructions, 0)
+		assert.Nil(t, result.Test("nonexistent"))
+	})
+}
+
+// TestCampaignResultFailed ensures only failed tests are returned as failed from a campaign result.
+func TestCampaignResultFailed(t *testing.T) {
+	result := &CampaignResult{Tests: []CampaignTestResult{
+		{ID: "a", Status: TestCaseStatusPassed},
+		{ID: "b", Status: TestCaseStatusFailed},
+		{ID: "c", Status: TestCaseStatusRunning},
+	}}
+	failed := result.Failed()
+	assert.Len(t, failed, 1)
+	assert.EqualValues(t, "b", failed[0].ID)
+	assert.EqualValues(t, TestCaseStatusRunning, result.Test("c").Status)
+
+	// A fuzzer which has not started a campaign should have no result.
+	assert.Nil(t, (&Fuzzer{}).CampaignResult())
+}
@@ -0,0 +1,240 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/utils/randomutils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fuzzerCheckpointVersion describes the version of the format checkpoints are written in. It must be incremented
+// whenever a change to the format prevents checkpoints written previously from being resumed from.
+const fuzzerCheckpointVersion = 1
+
+// fuzzerCheckpoint describes the state of a fuzzing campaign beyond its corpus, which is written to the corpus
+// directory so a later campaign may resume from it.
+type fuzzerCheckpoint struct {
+	// Version describes the version of the format the checkpoint was written in.
+	Version int `json:"version"`
+
+	// BytecodeHash describes the hash of the compiled contracts the campaign was fuzzing, as the call sequences in the
+	// checkpoint may only be replayed against the same contracts.
+	BytecodeHash common.Hash `json:"bytecodeHash"`
+
+	// Seed describes the seed used to derive all random providers in the campaign. A resumed campaign derives its
+	// seed from it, so it does not repeat the random choices already made.
+	Seed int64 `json:"seed"`
+
+	// Metrics describes the cumulative metrics of the campaign, including those of any campaigns it resumed from.
+	Metrics checkpointMetrics `json:"metrics"`
+
+	// TestCases describes the state of every test case registered in the campaign.
+	TestCases []checkpointTestCase `json:"testCases"`
+
+	// UnshrunkCallSequences describes call sequences which were found to fail tests, but were still being shrunk when
+	// the checkpoint was written.
+	UnshrunkCallSequences []calls.CallSequence `json:"unshrunkCallSequences"`
+}
+
+// checkpointMetrics describes the cumulative metrics of a fuzzing campaign recorded in a checkpoint.
+type checkpointMetrics struct {
+	// CallsTested describes the amount of calls the campaign executed and ran tests against.
+	CallsTested uint64 `json:"callsTested"`
+
+	// SequencesTested describes the amount of call sequences the campaign executed and ran tests against.
+	SequencesTested uint64 `json:"sequencesTested"`
+
+	// CallsOutOfGas describes the amount of calls the campaign executed which failed because they ran out of gas.
+	CallsOutOfGas uint64 `json:"callsOutOfGas"`
+}
+
+// checkpointTestCase describes the state of a single test case recorded in a checkpoint.
+type checkpointTestCase struct {
+	// ID describes the unique identifier of the test case.
+	ID string `json:"id"`
+
+	// Name describes the name of the test case.
+	Name string `json:"name"`
+
+	// Status describes the status of the test case when the checkpoint was written.
+	Status TestCaseStatus `json:"status"`
+
+	// OptimizationValue describes the best value found for an optimization test case, or nil if the test case is not
+	// one or no value was found.
+	OptimizationValue *big.Int `json:"optimizationValue,omitempty"`
+
+	// CallSequence describes the call sequence which failed the test case, or which reached the OptimizationValue
+	// of an optimization test case. It is nil if neither apply.
+	CallSequence calls.CallSequence `json:"callSequence,omitempty"`
+}
+
+// newFuzzerCheckpoint creates a checkpoint describing the current state of the Fuzzer's campaign.
+// Returns the checkpoint created.
+func (f *Fuzzer) newFuzzerCheckpoint() *fuzzerCheckpoint {
+	checkpoint := &fuzzerCheckpoint{
+		Version:      fuzzerCheckpointVersion,
+		BytecodeHash: corpus.ContractsBytecodeHash(f.contractDefinitions, nil),
+		Seed:         f.seed,
+		Metrics: checkpointMetrics{
+			CallsTested:     f.metrics.CallsTested().Uint64(),
+			SequencesTested: f.metrics.SequencesTested().Uint64(),
+			CallsOutOfGas:   f.metrics.CallsOutOfGas().Uint64(),
+		},
+		TestCases:             make([]checkpointTestCase, 0),
+		UnshrunkCallSequences: make([]calls.CallSequence, 0),
+	}
+
+	// Record the state of each test case, along with the call sequences we need to restore it.
+	for _, testCase := range f.TestCases() {
+		checkpointTestCase := checkpointTestCase{
+			ID:     testCase.ID(),
+			Name:   testCase.Name(),
+			Status: testCase.Status(),
+		}
+		if optimizationTestCase, ok := testCase.(*OptimizationTestCase); ok {
+			checkpointTestCase.OptimizationValue = optimizationTestCase.Value()
+		}
+		if callSequence := testCase.CallSequence(); callSequence != nil && (checkpointTestCase.Status == TestCaseStatusFailed || checkpointTestCase.OptimizationValue != nil) {
+			checkpointTestCase.CallSequence = *callSequence
+		}
+		checkpoint.TestCases = append(checkpoint.TestCases, checkpointTestCase)
+	}
+
+	// Record the call sequences still being shrunk, ordered by the worker shrinking them.
+	f.unshrunkCallSequencesLock.Lock()
+	workerIndexes := make([]int, 0, len(f.unshrunkCallSequences))
+	for workerIndex := range f.unshrunkCallSequences {
+		workerIndexes = append(workerIndexes, workerIndex)
+	}
+	sort.Ints(workerIndexes)
+	for _, workerIndex := range workerIndexes {
+		checkpoint.UnshrunkCallSequences = append(checkpoint.UnshrunkCallSequences, f.unshrunkCallSequences[workerIndex])
+	}
+	f.unshrunkCallSequencesLock.Unlock()
+	return checkpoint
+}
+
+// setUnshrunkCallSequence records the call sequence which failed tests and is being shrunk by the worker with the
+// provided index, so it is recorded in checkpoints until shrinking completes. A nil call sequence indicates the
+// worker is no longer shrinking one.
+func (f *Fuzzer) setUnshrunkCallSequence(workerIndex int, callSequence calls.CallSequence) {
+	f.unshrunkCallSequencesLock.Lock()
+	defer f.unshrunkCallSequencesLock.Unlock()
+	if callSequence == nil {
+		delete(f.unshrunkCallSequences, workerIndex)
+	} else {
+		f.unshrunkCallSequences[workerIndex] = callSequence
+	}
+}
+
+// writeCheckpoint writes a checkpoint describing the current state of the Fuzzer's campaign to the corpus directory.
+// If no corpus directory is configured, this does nothing.
+// Returns an error if one occurs.
+func (f *Fuzzer) writeCheckpoint() error {
+	if f.config.Fuzzing.CorpusDirectory == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(f.newFuzzerCheckpoint(), "", " ")
+	if err != nil {
+		return err
+	}
+	err = f.corpus.WriteCheckpoint(b)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint '%v': %v", f.corpus.CheckpointFilePath(), err)
+	}
+	return nil
+}
+
+// checkpointLoop writes a checkpoint of the Fuzzer's campaign every FuzzingConfig.CheckpointInterval seconds until
+// ctx signals a stopped operation. Checkpoints which cannot be written are reported, but do not stop the campaign.
+func (f *Fuzzer) checkpointLoop() {
+	ticker := time.NewTicker(time.Duration(f.config.Fuzzing.CheckpointInterval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := f.writeCheckpoint(); err != nil {
+			logging.GlobalLogger.Warn().Err(err).Msgf("%v", err)
+		}
+	}
+}
+
+// readCheckpoint reads the checkpoint in the corpus directory, verifying a campaign fuzzing the Fuzzer's compiled
+// contracts can resume from it.
+// Returns the checkpoint read, or an error if one occurs.
+func (f *Fuzzer) readCheckpoint() (*fuzzerCheckpoint, error) {
+	// Read our checkpoint.
+	checkpointPath := f.corpus.CheckpointFilePath()
+	b, err := f.corpus.ReadCheckpoint()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("cannot resume the campaign as no checkpoint was found at '%v'", checkpointPath)
+		}
+		return nil, err
+	}
+	var checkpoint fuzzerCheckpoint
+	err = json.Unmarshal(b, &checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse checkpoint '%v': %v", checkpointPath, err)
+	}
+
+	// Verify we understand the checkpoint, and that its call sequences apply to the contracts we are fuzzing.
+	if checkpoint.Version != fuzzerCheckpointVersion {
+		return nil, fmt.Errorf("cannot resume from checkpoint '%v' as it was written with checkpoint version %v, which is incompatible with the supported version %v", checkpointPath, checkpoint.Version, fuzzerCheckpointVersion)
+	}
+	if checkpoint.BytecodeHash != corpus.ContractsBytecodeHash(f.contractDefinitions, nil) {
+		return nil, fmt.Errorf("cannot resume from checkpoint '%v' as the contracts compiled to different bytecode than when it was written", checkpointPath)
+	}
+	return &checkpoint, nil
+}
+
+// restoreCheckpoint restores the state of the campaign described by the provided checkpoint. Its cumulative metrics
+// are carried over, and the call sequences which failed test cases (including those not yet shrunk), or reached the
+// best values of optimization test cases, are queued for replay on corpus initialization, so workers restore those
+// test cases first. This must be called after the metrics and corpus are created, but before the corpus is
+// initialized.
+func (f *Fuzzer) restoreCheckpoint(checkpoint *fuzzerCheckpoint) {
+	f.metrics.resumedMetrics = checkpoint.Metrics
+
+	// Queue the call sequences which restore our test cases.
+	failedTestCases, optimizationTestCases := 0, 0
+	for _, testCase := range checkpoint.TestCases {
+		if testCase.CallSequence == nil {
+			continue
+		}
+		f.corpus.AddResumedCallSequences(testCase.CallSequence)
+		if testCase.Status == TestCaseStatusFailed {
+			failedTestCases++
+		} else {
+			optimizationTestCases++
+		}
+	}
+	f.corpus.AddResumedCallSequences(checkpoint.UnshrunkCallSequences...)
+
+	logging.GlobalLogger.Info().
+		Uint64("calls", checkpoint.Metrics.CallsTested).
+		Int("failedTestCases", failedTestCases).
+		Int("unshrunkCallSequences", len(checkpoint.UnshrunkCallSequences)).
+		Int("optimizationTestCases", optimizationTestCases).
+		Msgf(
+			"Resuming campaign from checkpoint after %d calls, replaying %d failed test(s), %d unshrunk failure(s) and %d optimization value(s)",
+			checkpoint.Metrics.CallsTested, failedTestCases, len(checkpoint.UnshrunkCallSequences), optimizationTestCases,
+		)
+}
+
+// resumedSeed derives the seed of a campaign resuming from the checkpoint from the seed of the campaign which wrote
+// it, so the resumed campaign does not repeat the random choices already made.
+func (c *fuzzerCheckpoint) resumedSeed() int64 {
+	return randomutils.DeriveSeed(c.Seed, 0)
+}
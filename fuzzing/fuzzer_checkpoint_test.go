@@ -0,0 +1,64 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckpointReadWrite ensures checkpoints can be written and read back, and that checkpoints written in another
+// format version, or for other compiled contracts, cannot be resumed from.
+func TestCheckpointReadWrite(t *testing.T) {
+	// Create a fuzzer with just enough state to write checkpoints.
+	corpusDirectory := filepath.Join(t.TempDir(), "corpus")
+	c, err := corpus.NewCorpus(corpusDirectory)
+	assert.NoError(t, err)
+	f := &Fuzzer{
+		config:                config.ProjectConfig{Fuzzing: config.FuzzingConfig{CorpusDirectory: corpusDirectory}},
+		corpus:                c,
+		metrics:               newFuzzerMetrics(1, c, nil),
+		seed:                  7,
+		unshrunkCallSequences: make(map[int]calls.CallSequence),
+	}
+	f.metrics.workerMetrics[0].callsTested.add(100)
+	f.metrics.workerMetrics[0].sequencesTested.add(10)
+
+	// Resuming should fail until a checkpoint was written.
+	_, err = f.readCheckpoint()
+	assert.ErrorContains(t, err, "no checkpoint was found")
+
+	// A checkpoint we wrote should be read back, and carry over our metrics.
+	assert.NoError(t, f.writeCheckpoint())
+	checkpoint, err := f.readCheckpoint()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, checkpoint.Seed)
+	assert.NotEqualValues(t, checkpoint.Seed, checkpoint.resumedSeed())
+	assert.EqualValues(t, 100, checkpoint.Metrics.CallsTested)
+	assert.EqualValues(t, 10, checkpoint.Metrics.SequencesTested)
+
+	// Restoring the checkpoint should include its metrics in our totals.
+	f.metrics = newFuzzerMetrics(1, c, nil)
+	f.restoreCheckpoint(checkpoint)
+	f.metrics.workerMetrics[0].callsTested.add(5)
+	assert.EqualValues(t, 105, f.metrics.CallsTested().Uint64())
+
+	// Checkpoints in another format version, or for other contracts, should be refused.
+	for _, modify := range []func(*fuzzerCheckpoint){
+		func(checkpoint *fuzzerCheckpoint) { checkpoint.Version++ },
+		func(checkpoint *fuzzerCheckpoint) { checkpoint.BytecodeHash = common.HexToHash("0x01") },
+	} {
+		modified := *checkpoint
+		modify(&modified)
+		b, err := json.Marshal(modified)
+		assert.NoError(t, err)
+		assert.NoError(t, c.WriteCheckpoint(b))
+		_, err = f.readCheckpoint()
+		assert.ErrorContains(t, err, "cannot resume from checkpoint")
+	}
+}
@@ -0,0 +1,171 @@
+package fuzzing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// VerifyCorpus replays every call sequence in the configured corpus, without mutating it, against a freshly set up
+// test chain, to determine whether each call sequence still executes and whether it violates any property or
+// assertion tests. If pruneStale is true, call sequences which could no longer be replayed (e.g. as they reference a
+// contract or method which no longer exists) are deleted from the corpus.
+// Returns the verification outcome of every call sequence in the corpus, or an error if one occurs.
+func (f *Fuzzer) VerifyCorpus(pruneStale bool) ([]corpus.CallSequenceVerification, error) {
+	// Load our corpus and set up a test chain to replay it on.
+	c, baseTestChain, err := f.loadCorpusForMaintenance()
+	if err != nil {
+		return nil, err
+	}
+
+	// Replay every call sequence, testing each against our enabled tests.
+	verifications, err := c.VerifyCallSequences(baseTestChain, f.contractDefinitions, f.newCorpusVerificationTest())
+	if err != nil {
+		return nil, err
+	}
+
+	// If we were asked to, remove any stale call sequences from the corpus.
+	if pruneStale {
+		staleFilePaths := make([]string, 0)
+		for _, verification := range verifications {
+			if verification.Status == corpus.CallSequenceVerificationStatusStale {
+				staleFilePaths = append(staleFilePaths, verification.FilePath)
+			}
+		}
+		err = c.RemoveCallSequences(staleFilePaths)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return verifications, nil
+}
+
+// MinimizeCorpus replays every call sequence in the configured corpus against a freshly set up test chain, then moves
+// every call sequence which is not needed to preserve the total coverage of the corpus to its pruned directory.
+// Returns the outcome of minimizing the corpus, or an error if one occurs.
+func (f *Fuzzer) MinimizeCorpus() (*corpus.CorpusMinimization, error) {
+	// Load our corpus and set up a test chain to replay it on.
+	c, baseTestChain, err := f.loadCorpusForMaintenance()
+	if err != nil {
+		return nil, err
+	}
+	return c.Minimize(baseTestChain, f.contractDefinitions)
+}
+
+// ImportCorpus imports call sequences from another fuzzer's corpus in the provided directory into the configured
+// corpus, mapping them onto the contracts deployed on a freshly set up test chain. The only supported format is
+// "echidna". Call sequences which could not be imported are skipped with a warning.
+// Returns the outcome of the import, or an error if one occurs.
+func (f *Fuzzer) ImportCorpus(directory string, format string) (*corpus.CallSequenceImport, error) {
+	// Load our corpus and set up a test chain to replay imported call sequences on.
+	c, baseTestChain, err := f.loadCorpusForMaintenance()
+	if err != nil {
+		return nil, err
+	}
+
+	// Import our call sequences in the provided format.
+	var callSequenceImport *corpus.CallSequenceImport
+	switch format {
+	case "echidna":
+		callSequenceImport, err = c.ImportEchidnaCallSequences(baseTestChain, f.contractDefinitions, directory, f.senders)
+	default:
+		return nil, fmt.Errorf("unsupported corpus import format '%v', expected 'echidna'", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Write our imported call sequences to the corpus.
+	err = c.Flush()
+	if err != nil {
+		return nil, err
+	}
+	return callSequenceImport, nil
+}
+
+// loadCorpusForMaintenance loads the configured corpus, and creates a test chain set up with the deployment/setup
+// strategy defined by the fuzzer, which the corpus can be replayed on outside a fuzzing campaign.
+// Returns the corpus and test chain, or an error if one occurs.
+func (f *Fuzzer) loadCorpusForMaintenance() (*corpus.Corpus, *chain.TestChain, error) {
+	// Load our corpus.
+	if f.config.Fuzzing.CorpusDirectory == "" {
+		return nil, nil, fmt.Errorf("no corpus directory was configured")
+	}
+	c, err := corpus.NewCorpus(f.config.Fuzzing.CorpusDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.SetCompressionEnabled(f.config.Fuzzing.CorpusCompression)
+
+	// Create our test chain and set it up with our deployment/setup strategy defined by the fuzzer, replaying the
+	// fuzzed constructor arguments of the last campaign if we are configured to.
+	baseTestChain, err := f.createTestChain()
+	if err != nil {
+		return nil, nil, err
+	}
+	f.loadReplayedConstructorArgs(c)
+	err = f.Hooks.ChainSetupFunc(f, baseTestChain)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, baseTestChain, nil
+}
+
+// newCorpusVerificationTest creates a corpus.CallSequenceVerificationTestFunc which checks whether the last call of
+// the provided call sequence failed an assertion test, or left any property test on the provided chain failing, for
+// whichever of these tests are enabled. Tests are checked by the same test case providers, and against the same test
+// methods, as during a fuzzing campaign.
+// Returns the test function.
+func (f *Fuzzer) newCorpusVerificationTest() corpus.CallSequenceVerificationTestFunc {
+	// Create the test cases our providers would test during a fuzzing campaign, without registering them.
+	assertionTestProvider := &AssertionTestCaseProvider{fuzzer: f}
+	assertionTestProvider.resetTestCases()
+	propertyTestProvider := &PropertyTestCaseProvider{fuzzer: f}
+	propertyTestProvider.resetTestCases()
+
+	return func(testChain *chain.TestChain, deployedContracts map[common.Address]*contracts.Contract, executedSequence calls.CallSequence) ([]string, error) {
+		violatedTests := make([]string, 0)
+
+		// Check whether our last call failed an assertion test.
+		if f.config.Fuzzing.Testing.AssertionTesting.Enabled {
+			methodId, testFailed, err := assertionTestProvider.checkAssertionFailures(executedSequence)
+			if err != nil {
+				return nil, err
+			}
+			if testFailed {
+				if testCase, exists := assertionTestProvider.testCases[*methodId]; exists {
+					violatedTests = append(violatedTests, fmt.Sprintf("assertion in %v.%v", testCase.targetContract.Name(), testCase.targetMethod.Sig))
+				}
+			}
+		}
+
+		// Check whether any property test on our deployed contracts fails.
+		if f.config.Fuzzing.Testing.PropertyTesting.Enabled {
+			for address, contract := range deployedContracts {
+				for _, method := range contract.CompiledContract().Abi.Methods {
+					method := method
+					if _, exists := propertyTestProvider.testCases[contracts.GetContractMethodID(contract, &method)]; !exists {
+						continue
+					}
+					propertyTestMethod := &contracts.DeployedContractMethod{Address: address, Contract: contract, Method: method}
+					failed, _, err := propertyTestProvider.checkPropertyTestFailedOnChain(testChain, propertyTestMethod, false)
+					if err != nil {
+						return nil, err
+					}
+					if failed {
+						violatedTests = append(violatedTests, fmt.Sprintf("property %v.%v", contract.Name(), method.Sig))
+					}
+				}
+			}
+		}
+
+		// Sort our violated tests, so they are reported deterministically.
+		sort.Strings(violatedTests)
+		return violatedTests, nil
+	}
+}
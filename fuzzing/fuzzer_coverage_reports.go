@@ -0,0 +1,40 @@
+package fuzzing
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/logging"
+)
+
+// writeCoverageReports writes a coverage report of the corpus coverage maps, mapped to source lines, to the
+// "coverage" folder of the corpus directory for each configured report format. If no corpus directory is set, this
+// does nothing.
+// Returns an error if one occurs.
+func (f *Fuzzer) writeCoverageReports() error {
+	// If we have no corpus directory or no report formats, there is nothing to write.
+	if f.config.Fuzzing.CorpusDirectory == "" || len(f.config.Fuzzing.CoverageReports) == 0 {
+		return nil
+	}
+
+	// Map our coverage to source lines, omitting any source files filtered by our config.
+	filter := coverage.SourcePathFilter{
+		Include: f.config.Fuzzing.CoverageReportIncludePaths,
+		Exclude: f.config.Fuzzing.CoverageReportExcludePaths,
+	}
+	analysis, err := coverage.AnalyzeSourceCoverage(f.compilations, f.corpus.CoverageMaps(), filter)
+	if err != nil {
+		return fmt.Errorf("could not generate coverage reports: %v", err)
+	}
+
+	// Write our reports.
+	reportPaths, err := coverage.WriteReports(analysis, filepath.Join(f.config.Fuzzing.CorpusDirectory, "coverage"), f.config.Fuzzing.CoverageReports)
+	if err != nil {
+		return fmt.Errorf("could not write coverage reports: %v", err)
+	}
+	for _, reportPath := range reportPaths {
+		logging.GlobalLogger.Info().Str("path", reportPath).Msgf("coverage report written to '%v'", reportPath)
+	}
+	return nil
+}
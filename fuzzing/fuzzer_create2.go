@@ -0,0 +1,66 @@
+package fuzzing
+
+import (
+	"fmt"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// deterministicDeploymentProxyRuntimeCode describes the runtime bytecode of the deterministic deployment proxy, which
+// deploys the init bytecode following the 32-byte salt in its call data through CREATE2, and returns the address of
+// the deployed contract, or reverts if the deployment failed.
+var deterministicDeploymentProxyRuntimeCode = hexutil.MustDecode("0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe03601600081602082378035828234f58015156039578182fd5b8082525050506014600cf3")
+
+// create2DeploymentFactory resolves the address of the factory which deploys a contract through CREATE2 with the
+// provided deployment config. The factory may be the name of a contract deployed earlier in chain setup, an address,
+// or empty to use the deterministic deployment proxy.
+// Returns the address of the factory, or an error if it could not be resolved.
+func create2DeploymentFactory(deployment config.Create2DeploymentConfig, deployedContractAddr map[string]common.Address) (common.Address, error) {
+	if deployment.Factory == "" {
+		return common.HexToAddress(config.DeterministicDeploymentProxyAddress), nil
+	}
+	if address, ok := deployedContractAddr[deployment.Factory]; ok {
+		return address, nil
+	}
+	address, err := utils.HexStringToAddress(deployment.Factory)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("CREATE2 factory \"%v\" is neither a contract deployed before it nor an address", deployment.Factory)
+	}
+	return address, nil
+}
+
+// chainSetupDeployCreate2 deploys the contract with the provided name and deployment message data (its init bytecode
+// and constructor arguments) through CREATE2, by sending the salt followed by the message data to the factory named
+// by the provided deployment config. The address of the contract is predicted from the factory, salt and message
+// data, and verified to hold the deployed contract.
+// Returns the address of the deployed contract, or an error if one occurs.
+func chainSetupDeployCreate2(fuzzer *Fuzzer, testChain *chain.TestChain, contractName string, msgData []byte, deployment config.Create2DeploymentConfig, deployedContractAddr map[string]common.Address) (common.Address, error) {
+	// Resolve our factory and salt, so we can predict the address of our contract.
+	factory, err := create2DeploymentFactory(deployment, deployedContractAddr)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("initial contract deployment failed for contract \"%v\", error: %v", contractName, err)
+	}
+	salt, err := deployment.SaltValue()
+	if err != nil {
+		return common.Address{}, fmt.Errorf("initial contract deployment failed for contract \"%v\", error: %v", contractName, err)
+	}
+	address := crypto.CreateAddress2(factory, salt, crypto.Keccak256(msgData))
+
+	// Send our deployment to the factory, then verify it deployed our contract where we predicted.
+	_, err = chainSetupSendMessage(fuzzer, testChain, &factory, append(salt.Bytes(), msgData...))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("initial contract deployment failed for contract \"%v\" through CREATE2 factory %v, error: %v", contractName, factory.String(), err)
+	}
+	if testChain.State().GetCodeSize(address) == 0 {
+		return common.Address{}, fmt.Errorf("initial contract deployment failed for contract \"%v\", CREATE2 factory %v did not deploy it at its predicted address %v", contractName, factory.String(), address.String())
+	}
+	logging.GlobalLogger.Info().Str("address", address.String()).Str("factory", factory.String()).
+		Msgf("Deployed %v at %v through CREATE2 factory %v", contractName, address.String(), factory.String())
+	return address, nil
+}
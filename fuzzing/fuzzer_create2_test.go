@@ -0,0 +1,63 @@
+package fuzzing
+
+import (
+	"testing"
+
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCreate2Deployment ensures contracts deployed through CREATE2 during chain setup are deployed at their predicted
+// addresses, through the deterministic deployment proxy if no factory is specified, and that deployments which do
+// not create the contract where it was predicted are reported.
+func TestCreate2Deployment(t *testing.T) {
+	// Create a fuzzer with just enough state to create a test chain, which deploys a contract through the
+	// deterministic deployment proxy.
+	projectConfig, err := config.GetDefaultProjectConfig("")
+	assert.NoError(t, err)
+	projectConfig.Fuzzing.Create2Deployments = map[string]config.Create2DeploymentConfig{"Deployed": {Salt: "0x2a"}}
+	f := &Fuzzer{config: *projectConfig, deployer: common.HexToAddress(projectConfig.Fuzzing.DeployerAddress)}
+	testChain, err := f.createTestChain()
+	assert.NoError(t, err)
+	defer testChain.Close()
+
+	// Our init bytecode deploys the runtime bytecode 0x01.
+	msgData := []byte{
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.MSTORE8),
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.RETURN),
+	}
+	proxyAddress := common.HexToAddress(config.DeterministicDeploymentProxyAddress)
+	salt := common.HexToHash("0x2a")
+	predictedAddress := crypto.CreateAddress2(proxyAddress, salt, crypto.Keccak256(msgData))
+
+	// Our contract should be deployed at its predicted address.
+	deployedContractAddr := make(map[string]common.Address)
+	deployment := projectConfig.Fuzzing.Create2Deployments["Deployed"]
+	address, err := chainSetupDeployCreate2(f, testChain, "Deployed", msgData, deployment, deployedContractAddr)
+	assert.NoError(t, err)
+	assert.EqualValues(t, predictedAddress, address)
+	assert.EqualValues(t, []byte{0x01}, testChain.State().GetCode(address))
+
+	// Deploying it again with the same salt should fail, as the address is already in use.
+	_, err = chainSetupDeployCreate2(f, testChain, "Deployed", msgData, deployment, deployedContractAddr)
+	assert.Error(t, err)
+
+	// Factories may be referenced by the name of a contract deployed earlier, or by address.
+	deployedContractAddr["Factory"] = proxyAddress
+	address, err = chainSetupDeployCreate2(f, testChain, "Deployed", msgData, config.Create2DeploymentConfig{Salt: "0x2b", Factory: "Factory"}, deployedContractAddr)
+	assert.NoError(t, err)
+	assert.EqualValues(t, crypto.CreateAddress2(proxyAddress, common.HexToHash("0x2b"), crypto.Keccak256(msgData)), address)
+	address, err = chainSetupDeployCreate2(f, testChain, "Deployed", msgData, config.Create2DeploymentConfig{Salt: "0x2c", Factory: proxyAddress.String()}, deployedContractAddr)
+	assert.NoError(t, err)
+	assert.EqualValues(t, crypto.CreateAddress2(proxyAddress, common.HexToHash("0x2c"), crypto.Keccak256(msgData)), address)
+
+	// Factories which cannot be resolved, or which do not deploy the contract where it was predicted, should be
+	// reported.
+	_, err = chainSetupDeployCreate2(f, testChain, "Deployed", msgData, config.Create2DeploymentConfig{Salt: "0x2d", Factory: "Missing"}, deployedContractAddr)
+	assert.ErrorContains(t, err, "neither a contract deployed before it nor an address")
+	_, err = chainSetupDeployCreate2(f, testChain, "Deployed", msgData, config.Create2DeploymentConfig{Salt: "0x2d", Factory: f.deployer.String()}, deployedContractAddr)
+	assert.ErrorContains(t, err, "did not deploy it at its predicted address")
+}
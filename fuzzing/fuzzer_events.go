@@ -2,9 +2,20 @@ package fuzzing
 
 import (
 	"github.com/crytic/medusa/events"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/corpus"
 )
 
 // FuzzerEvents defines event emitters for a Fuzzer.
+//
+// Events are published synchronously, on the goroutine of the operation which caused them, so the operation does not
+// continue until every handler returned. Handlers should be subscribed before the campaign starts, as subscribing is
+// not safe for concurrent use with publishing. The campaign-level events (CoverageFound, CorpusEntryAdded,
+// TestCaseFailed and WorkerReset) are published by the goroutines of different workers, so their handlers may be
+// called concurrently and must be safe for concurrent use. Events caused by the same worker are published in the order
+// they occurred. CampaignFinished is published exactly once per campaign, after every worker exited, so no other event
+// of the campaign is published after it. An error returned by a handler stops the campaign and is returned by Start,
+// except for TestCaseFailed, whose handler errors are logged.
 type FuzzerEvents struct {
 	// FuzzerStarting emits events when the Fuzzer initialized state and is ready to about to begin the main
 	// execution loop for the fuzzing campaign.
@@ -19,6 +30,28 @@ type FuzzerEvents struct {
 	// WorkerDestroyed emits events when the Fuzzer destroys an existing FuzzerWorker during the fuzzing
 	// campaign. This can occur even if a fuzzing campaign is not stopping, if a worker has reached resource limits.
 	WorkerDestroyed events.EventEmitter[FuzzerWorkerDestroyedEvent]
+
+	// CoverageFound emits events when a call sequence executed by any FuzzerWorker increased the coverage of the
+	// campaign. It is published after the call sequence was added to the corpus, so it follows the CorpusEntryAdded
+	// event for it.
+	CoverageFound events.EventEmitter[FuzzerCoverageFoundEvent]
+
+	// CorpusEntryAdded emits events when a call sequence is added to the corpus of the campaign, whether for
+	// increasing coverage or failing a test.
+	CorpusEntryAdded events.EventEmitter[FuzzerCorpusEntryAddedEvent]
+
+	// TestCaseFailed emits events when a test case fails, once its call sequence was shrunk. If failed test cases
+	// continue to be tested with deduplicated failures, it is published for every distinct failure.
+	TestCaseFailed events.EventEmitter[FuzzerTestCaseFailedEvent]
+
+	// WorkerReset emits events when a FuzzerWorker exited after reaching a resource limit, so it is destroyed and
+	// recreated with a fresh chain. It is published after the WorkerDestroyed event for the worker, and before the
+	// WorkerCreated event for its replacement.
+	WorkerReset events.EventEmitter[FuzzerWorkerResetEvent]
+
+	// CampaignFinished emits events when a fuzzing campaign finished, after its corpus and results were written. It
+	// is the last event published for the campaign.
+	CampaignFinished events.EventEmitter[FuzzerCampaignFinishedEvent]
 }
 
 // FuzzerStartingEvent describes an event where a fuzzing.Fuzzer has initialized all state variables and is about to
@@ -48,3 +81,62 @@ type FuzzerWorkerDestroyedEvent struct {
 	// Worker represents the instance of the fuzzing.FuzzerWorker for which the event occurred.
 	Worker *FuzzerWorker
 }
+
+// FuzzerCoverageFoundEvent describes an event where a call sequence executed by a fuzzing.FuzzerWorker increased the
+// coverage of the campaign.
+type FuzzerCoverageFoundEvent struct {
+	// Worker represents the instance of the fuzzing.FuzzerWorker which executed the call sequence.
+	Worker *FuzzerWorker
+
+	// CallSequence describes the call sequence, executed up to the call which increased coverage.
+	CallSequence calls.CallSequence
+}
+
+// FuzzerCorpusEntryAddedEvent describes an event where a call sequence was added to the corpus of a fuzzing.Fuzzer.
+type FuzzerCorpusEntryAddedEvent struct {
+	// Fuzzer represents the instance of the fuzzing.Fuzzer for which the event occurred.
+	Fuzzer *Fuzzer
+
+	// CallSequence describes the call sequence which was added.
+	CallSequence calls.CallSequence
+
+	// Metadata describes the provenance of the call sequence, including the index of the worker which produced it.
+	Metadata *corpus.CallSequenceMetadata
+}
+
+// FuzzerTestCaseFailedEvent describes an event where a test case of a fuzzing.Fuzzer failed.
+type FuzzerTestCaseFailedEvent struct {
+	// Fuzzer represents the instance of the fuzzing.Fuzzer for which the event occurred.
+	Fuzzer *Fuzzer
+
+	// TestCase describes the test case which failed.
+	TestCase TestCase
+
+	// CallSequence describes the shrunken call sequence the test case failed with, or nil if the test case did not
+	// fail due to a call sequence.
+	CallSequence *calls.CallSequence
+}
+
+// FuzzerWorkerResetEvent describes an event where a fuzzing.FuzzerWorker reached a resource limit and is recreated.
+type FuzzerWorkerResetEvent struct {
+	// Worker represents the instance of the fuzzing.FuzzerWorker which was destroyed.
+	Worker *FuzzerWorker
+
+	// Cause describes the resource limit the worker reached.
+	Cause WorkerResetCause
+}
+
+// FuzzerCampaignFinishedEvent describes an event where a fuzzing campaign of a fuzzing.Fuzzer finished.
+type FuzzerCampaignFinishedEvent struct {
+	// Fuzzer represents the instance of the fuzzing.Fuzzer for which the event occurred.
+	Fuzzer *Fuzzer
+
+	// StopReason describes the reason the campaign stopped.
+	StopReason StopReason
+
+	// TestCases describes the test cases of the campaign, with their final statuses.
+	TestCases []TestCase
+
+	// Err describes the error the campaign stopped with, or nil if it did not encounter one.
+	Err error
+}
@@ -0,0 +1,55 @@
+package fuzzing
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTestCaseFailedEvents ensures a test case failed event is published once for each test case reported as failed,
+// even when failures are reported concurrently, and that handlers may query the fuzzer's test cases.
+func TestTestCaseFailedEvents(t *testing.T) {
+	// Create a fuzzer with just enough state to report test cases.
+	f := &Fuzzer{
+		config:            config.ProjectConfig{Fuzzing: config.FuzzingConfig{Testing: config.TestingConfig{StopOnFailedTest: true}}},
+		testCasesFinished: make(map[string]TestCase),
+		testCaseFailures:  make(map[string]map[common.Hash]struct{}),
+	}
+	var failedLock sync.Mutex
+	failed := make(map[string]int)
+	f.Events.TestCaseFailed.Subscribe(func(event FuzzerTestCaseFailedEvent) error {
+		assert.Len(t, event.Fuzzer.TestCases(), 10)
+		failedLock.Lock()
+		defer failedLock.Unlock()
+		failed[event.TestCase.Name()]++
+		return nil
+	})
+
+	// Register passed and failed test cases, then report each of them twice, concurrently.
+	var testCases []TestCase
+	for i := 0; i < 10; i++ {
+		status := TestCaseStatusPassed
+		if i%2 == 0 {
+			status = TestCaseStatusFailed
+		}
+		testCase := &testResultStubTestCase{name: fmt.Sprintf("test_%d", i), status: status}
+		f.RegisterTestCase(testCase)
+		testCases = append(testCases, testCase)
+	}
+	var wg sync.WaitGroup
+	for _, testCase := range append(testCases, testCases...) {
+		wg.Add(1)
+		go func(testCase TestCase) {
+			defer wg.Done()
+			f.ReportTestCaseFinished(testCase)
+		}(testCase)
+	}
+	wg.Wait()
+
+	// Only our failed test cases should have been published, once each.
+	assert.EqualValues(t, map[string]int{"test_0": 1, "test_2": 1, "test_4": 1, "test_6": 1, "test_8": 1}, failed)
+}
@@ -0,0 +1,306 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/logging/colors"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// failureFileVersion describes the version of the failure file format written by this version of medusa.
+const failureFileVersion = 1
+
+// FailureFile describes a failed test, as written to the failures directory, with enough context to replay the call
+// sequence which failed it against the same deployment.
+type FailureFile struct {
+	// Version describes the version of the failure file format.
+	Version int `json:"version"`
+
+	// TestName describes the name of the failed test.
+	TestName string `json:"testName"`
+
+	// TestID describes the ID of the failed test.
+	TestID string `json:"testId"`
+
+	// TestKind describes the kind of the failed test, e.g. "property" or "assertion".
+	TestKind string `json:"testKind"`
+
+	// Contract describes the name of the contract which defines the failed test, if known.
+	Contract string `json:"contract,omitempty"`
+
+	// Method describes the signature of the method which defines the failed test, if known.
+	Method string `json:"method,omitempty"`
+
+	// Message describes the result message of the failed test, without colors.
+	Message string `json:"message"`
+
+	// Seed describes the random seed of the campaign the test failed in.
+	Seed int64 `json:"seed"`
+
+	// CreatedAt describes when the test failed.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// ConstructorArgs describes the constructor arguments of the deployed contracts, including any generated by the
+	// fuzzer, keyed by contract name and then argument name, in the form they would be provided through the config.
+	ConstructorArgs map[string]map[string]any `json:"constructorArgs,omitempty"`
+
+	// Deployments describes the contracts deployed when setting up the chain the test failed on.
+	Deployments []FailureFileDeployment `json:"deployments"`
+
+	// CallSequence describes the shrunken call sequence which failed the test, including the block number and
+	// timestamp delays of each call.
+	CallSequence calls.CallSequence `json:"callSequence"`
+}
+
+// FailureFileDeployment describes a contract deployed when setting up the chain a test described by a FailureFile
+// failed on.
+type FailureFileDeployment struct {
+	// Contract describes the name of the deployed contract, or an empty string if it could not be matched to one.
+	Contract string `json:"contract,omitempty"`
+
+	// Address describes the address the contract was deployed at.
+	Address common.Address `json:"address"`
+
+	// Deployer describes the address which deployed the contract.
+	Deployer common.Address `json:"deployer"`
+}
+
+// ReadFailureFile reads a FailureFile from the provided file path.
+// Returns the failure file, or an error if one occurs.
+func ReadFailureFile(filePath string) (*FailureFile, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var failure FailureFile
+	err = json.Unmarshal(b, &failure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse failure file '%v': %v", filePath, err)
+	}
+	if failure.Version != failureFileVersion {
+		return nil, fmt.Errorf("failure file '%v' has unsupported version %d, expected %d", filePath, failure.Version, failureFileVersion)
+	}
+	return &failure, nil
+}
+
+// newFailureFile creates a FailureFile describing the provided failed TestCase, which failed with the provided call
+// sequence.
+// Returns the failure file.
+func (f *Fuzzer) newFailureFile(testCase TestCase, callSequence calls.CallSequence) *FailureFile {
+	failure := &FailureFile{
+		Version:      failureFileVersion,
+		TestName:     strings.TrimSpace(testCase.Name()),
+		TestID:       testCase.ID(),
+		TestKind:     testCaseKind(testCase),
+		Message:      strings.TrimSpace(colors.Strip(f.testCaseResultMessage(testCase))),
+		Seed:         f.seed,
+		CreatedAt:    time.Now(),
+		Deployments:  f.failureFileDeployments(),
+		CallSequence: callSequence,
+	}
+	switch t := testCase.(type) {
+	case *PropertyTestCase:
+		failure.Contract, failure.Method = t.targetContract.Name(), t.targetMethod.Sig
+	case *AssertionTestCase:
+		failure.Contract, failure.Method = t.targetContract.Name(), t.targetMethod.Sig
+	}
+
+	// Record the constructor arguments of our deployed contracts, overriding those provided by the config with any
+	// which were generated, so the same contracts are deployed when replaying.
+	constructorArgs := make(map[string]map[string]any)
+	for _, deployment := range failure.Deployments {
+		args := make(map[string]any)
+		for name, value := range f.config.Fuzzing.ConstructorArgs[deployment.Contract] {
+			args[name] = value
+		}
+		for name, value := range f.fuzzedConstructorArgs[deployment.Contract] {
+			args[name] = value
+		}
+		if len(args) > 0 {
+			constructorArgs[deployment.Contract] = args
+		}
+	}
+	if len(constructorArgs) > 0 {
+		failure.ConstructorArgs = constructorArgs
+	}
+	return failure
+}
+
+// failureFileDeployments obtains the contract deployments recorded when setting up the base test chain, as described
+// in failure files.
+func (f *Fuzzer) failureFileDeployments() []FailureFileDeployment {
+	deployments := make([]FailureFileDeployment, 0, len(f.reproducerDeployments))
+	for _, reproducerDeployment := range f.reproducerDeployments {
+		deployment := FailureFileDeployment{Address: reproducerDeployment.Address, Deployer: reproducerDeployment.Deployer}
+		if reproducerDeployment.Contract != nil {
+			deployment.Contract = reproducerDeployment.Contract.Name()
+		}
+		deployments = append(deployments, deployment)
+	}
+	return deployments
+}
+
+// writeFailureFile writes a FailureFile describing the failure of the provided TestCase to the failures directory.
+// The first failure of a test case is written to a file named after its ID, while later distinct failures of it are
+// suffixed with their index. Test cases which did not fail due to a call sequence are ignored. The caller must hold
+// testCasesLock.
+// Returns the path of the written failure file, or an empty string if none was written, or an error if one occurs.
+func (f *Fuzzer) writeFailureFile(testCase TestCase) (string, error) {
+	callSequence := testCase.CallSequence()
+	if callSequence == nil {
+		return "", nil
+	}
+	b, err := json.MarshalIndent(f.newFailureFile(testCase, *callSequence), "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	// Write our failure file to our failures directory, creating it and any parent directories (e.g. ".medusa").
+	err = os.MkdirAll(f.config.Fuzzing.Testing.FailuresDirectory, 0777)
+	if err != nil {
+		return "", err
+	}
+	fileName := reproducerFileNameCharacters.ReplaceAllString(testCase.ID(), "_")
+	if failureCount := len(f.testCaseFailures[testCase.ID()]); failureCount > 1 {
+		fileName = fmt.Sprintf("%s-%d", fileName, failureCount)
+	}
+	filePath := filepath.Join(f.config.Fuzzing.Testing.FailuresDirectory, fileName+".json")
+	err = os.WriteFile(filePath, b, 0644)
+	if err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// logWriteFailureFile writes a FailureFile describing the failure of the provided TestCase, as described by
+// writeFailureFile, if the config specifies a failures directory, and logs the outcome. The caller must hold
+// testCasesLock.
+func (f *Fuzzer) logWriteFailureFile(testCase TestCase) {
+	if f.config.Fuzzing.Testing.FailuresDirectory == "" {
+		return
+	}
+	failurePath, err := f.writeFailureFile(testCase)
+	if err != nil {
+		logging.GlobalLogger.Error().Str("testCase", testCase.Name()).Err(err).Msgf("Failed to write failure file for %s: %v", testCase.Name(), err)
+	} else if failurePath != "" {
+		logging.GlobalLogger.Info().Str("testCase", testCase.Name()).Str("path", failurePath).Msgf("Wrote failure file for %s to %s, replay it with \"medusa replay %s\"", testCase.Name(), failurePath, failurePath)
+	}
+}
+
+// FailureReplay describes the outcome of replaying a FailureFile with ReplayFailureFile.
+type FailureReplay struct {
+	// Failure describes the failure file which was replayed.
+	Failure *FailureFile
+
+	// CallSequence describes the calls of the failure's call sequence which were executed, with execution traces
+	// attached. Replay stops at the first call which violates a test.
+	CallSequence calls.CallSequence
+
+	// Reproduced indicates whether the call sequence failed the test described by the failure file again.
+	Reproduced bool
+
+	// ViolatedTests describes the tests the call sequence violated.
+	ViolatedTests []string
+
+	// DeploymentMismatches describes the deployments recorded by the failure file which differ from those performed
+	// when replaying it, which may prevent the failure from reproducing.
+	DeploymentMismatches []string
+}
+
+// ReplayFailureFile replays the call sequence described by the failure file at the provided path against a freshly
+// set up test chain, deploying contracts with the constructor arguments recorded by the failure file, and attaching
+// execution traces to each call. Only failures of property and assertion tests can be replayed.
+// Returns the outcome of replaying the failure, or an error if one occurs.
+func (f *Fuzzer) ReplayFailureFile(filePath string) (*FailureReplay, error) {
+	// Read our failure file, and determine the test which should fail again.
+	failure, err := ReadFailureFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var expectedViolation string
+	switch failure.TestKind {
+	case "property":
+		f.config.Fuzzing.Testing.PropertyTesting.Enabled = true
+		expectedViolation = fmt.Sprintf("property %v.%v", failure.Contract, failure.Method)
+	case "assertion":
+		f.config.Fuzzing.Testing.AssertionTesting.Enabled = true
+		expectedViolation = fmt.Sprintf("assertion in %v.%v", failure.Contract, failure.Method)
+	default:
+		return nil, fmt.Errorf("failures of %v tests cannot be replayed, only those of property and assertion tests", failure.TestKind)
+	}
+
+	// Deploy our contracts with the constructor arguments the failure was found with, rather than generating any.
+	constructorArgs := make(map[string]map[string]any)
+	for contractName, args := range f.config.Fuzzing.ConstructorArgs {
+		constructorArgs[contractName] = args
+	}
+	fuzzedConstructorArgs := make(map[string][]string)
+	for contractName, argNames := range f.config.Fuzzing.FuzzedConstructorArgs {
+		fuzzedConstructorArgs[contractName] = argNames
+	}
+	for contractName, args := range failure.ConstructorArgs {
+		constructorArgs[contractName] = args
+		delete(fuzzedConstructorArgs, contractName)
+	}
+	f.config.Fuzzing.ConstructorArgs = constructorArgs
+	f.config.Fuzzing.FuzzedConstructorArgs = fuzzedConstructorArgs
+	f.seed = failure.Seed
+
+	// Create our test chain and set it up with our deployment/setup strategy defined by the fuzzer.
+	baseTestChain, err := f.createTestChain()
+	if err != nil {
+		return nil, err
+	}
+	defer baseTestChain.Close()
+	err = f.Hooks.ChainSetupFunc(f, baseTestChain)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report any deployments which differ from those the failure was found with.
+	replay := &FailureReplay{Failure: failure, DeploymentMismatches: make([]string, 0)}
+	f.recordReproducerDeployments(baseTestChain)
+	deployments := f.failureFileDeployments()
+	for i, expectedDeployment := range failure.Deployments {
+		if i >= len(deployments) {
+			replay.DeploymentMismatches = append(replay.DeploymentMismatches, fmt.Sprintf("%v was not deployed at %v", expectedDeployment.Contract, expectedDeployment.Address.String()))
+		} else if deployments[i] != expectedDeployment {
+			replay.DeploymentMismatches = append(replay.DeploymentMismatches, fmt.Sprintf("%v was deployed at %v, rather than %v at %v", deployments[i].Contract, deployments[i].Address.String(), expectedDeployment.Contract, expectedDeployment.Address.String()))
+		}
+	}
+
+	// Replay our call sequence, attaching execution traces to each call before testing it.
+	traceStorageWrites := f.traceStorageWrites()
+	verificationTest := f.newCorpusVerificationTest()
+	callSequenceReplay, err := corpus.ReplayCallSequence(baseTestChain, f.contractDefinitions, f.config.Fuzzing.ProxyImplementations, failure.CallSequence, func(testChain *chain.TestChain, deployedContracts map[common.Address]*contracts.Contract, executedSequence calls.CallSequence) ([]string, error) {
+		err := executedSequence[len(executedSequence)-1].AttachExecutionTrace(testChain, f.contractDefinitions, traceStorageWrites)
+		if err != nil {
+			return nil, err
+		}
+		return verificationTest(testChain, deployedContracts, executedSequence)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if callSequenceReplay.InvalidError != nil {
+		return nil, fmt.Errorf("failure file '%v' could not be replayed: %v", filePath, callSequenceReplay.InvalidError)
+	}
+	replay.CallSequence = callSequenceReplay.ExecutedCallSequence
+	replay.ViolatedTests = callSequenceReplay.ViolatedTests
+	for _, violatedTest := range replay.ViolatedTests {
+		if violatedTest == expectedViolation {
+			replay.Reproduced = true
+		}
+	}
+	return replay, nil
+}
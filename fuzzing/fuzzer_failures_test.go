@@ -0,0 +1,71 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/crytic/medusa/fuzzing/reproducers"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWriteFailureFile ensures failure files are written for failed test cases with a call sequence, named after the
+// test case and the index of distinct failures, and can be read back with the context needed to replay them.
+func TestWriteFailureFile(t *testing.T) {
+	// Create a fuzzer with just enough state to write failure files.
+	failuresDirectory := filepath.Join(t.TempDir(), ".medusa", "failures")
+	f := &Fuzzer{
+		config: config.ProjectConfig{Fuzzing: config.FuzzingConfig{
+			ConstructorArgs: map[string]map[string]any{"TestContract": {"x": "1"}},
+			Testing:         config.TestingConfig{FailuresDirectory: failuresDirectory},
+		}},
+		seed:                  1337,
+		fuzzedConstructorArgs: map[string]map[string]any{"TestContract": {"y": "2"}},
+		reproducerDeployments: []reproducers.FoundryDeployment{{Address: common.HexToAddress("0x1234"), Deployer: common.HexToAddress("0x10000")}},
+		testCaseFailures:      map[string]map[common.Hash]struct{}{"Property Test: TestContract.fuzz_fails()": {{}: {}}},
+	}
+	to := common.HexToAddress("0x1234")
+	callSequence := calls.CallSequence{
+		calls.NewCallSequenceElement(nil, calls.NewCallMessage(common.HexToAddress("0x10000"), &to, 0, big.NewInt(0), 30000000, nil, nil, nil, []byte{0x01}), 2, 3),
+	}
+	testCase := &testResultStubTestCase{name: "Property Test: TestContract.fuzz_fails()", status: TestCaseStatusFailed, callSequence: &callSequence}
+
+	// Our failure file should be named after our test case, and describe it.
+	filePath, err := f.writeFailureFile(testCase)
+	assert.NoError(t, err)
+	assert.EqualValues(t, filepath.Join(failuresDirectory, "Property_Test_TestContract_fuzz_fails_.json"), filePath)
+	failure, err := ReadFailureFile(filePath)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Property Test: TestContract.fuzz_fails()", failure.TestName)
+	assert.EqualValues(t, 1337, failure.Seed)
+	assert.Contains(t, failure.Message, "[Seed] 1337")
+	assert.EqualValues(t, []FailureFileDeployment{{Address: to, Deployer: common.HexToAddress("0x10000")}}, failure.Deployments)
+	assert.Len(t, failure.CallSequence, 1)
+	assert.EqualValues(t, 2, failure.CallSequence[0].BlockNumberDelay)
+	assert.EqualValues(t, 3, failure.CallSequence[0].BlockTimestampDelay)
+
+	// Later distinct failures should be suffixed with their index.
+	f.testCaseFailures[testCase.ID()][common.HexToHash("0x01")] = struct{}{}
+	filePath, err = f.writeFailureFile(testCase)
+	assert.NoError(t, err)
+	assert.EqualValues(t, filepath.Join(failuresDirectory, "Property_Test_TestContract_fuzz_fails_-2.json"), filePath)
+
+	// Test cases which did not fail due to a call sequence should not be written.
+	filePath, err = f.writeFailureFile(&testResultStubTestCase{name: "test", status: TestCaseStatusFailed})
+	assert.NoError(t, err)
+	assert.Empty(t, filePath)
+
+	// Failure files in another format version should be refused.
+	failure.Version++
+	b, err := json.Marshal(failure)
+	assert.NoError(t, err)
+	filePath = filepath.Join(failuresDirectory, "future.json")
+	assert.NoError(t, os.WriteFile(filePath, b, 0644))
+	_, err = ReadFailureFile(filePath)
+	assert.ErrorContains(t, err, "unsupported version")
+}
@@ -0,0 +1,153 @@
+package fuzzing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/logging"
+	"golang.org/x/exp/slices"
+)
+
+// FunctionCoverageSummary describes how thoroughly a state changing method of a contract was exercised by a fuzzing
+// campaign.
+type FunctionCoverageSummary struct {
+	// ContractName describes the name of the contract the method belongs to.
+	ContractName string `json:"contract"`
+
+	// MethodSignature describes the signature of the method.
+	MethodSignature string `json:"method"`
+
+	// Calls describes the amount of fuzzed calls to the method.
+	Calls uint64 `json:"calls"`
+
+	// Reverts describes the amount of fuzzed calls to the method which reverted or otherwise failed.
+	Reverts uint64 `json:"reverts"`
+
+	// InstructionCoverage describes how many of the instructions of the method's function definition were executed.
+	// This is nil if the method has no function definition in the contract's source maps or AST.
+	InstructionCoverage *coverage.InstructionCoverage `json:"instructionCoverage"`
+}
+
+// CalledSuccessfully indicates whether any fuzzed call to the method succeeded.
+func (s FunctionCoverageSummary) CalledSuccessfully() bool {
+	return s.Calls > s.Reverts
+}
+
+// RevertRate returns the fraction of fuzzed calls to the method which reverted, or zero if it was never called.
+func (s FunctionCoverageSummary) RevertRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Reverts) / float64(s.Calls)
+}
+
+// FunctionCoverageSummaries summarizes how thoroughly each state changing method of the contracts in the deployment
+// order (and any other contract called during the campaign) was exercised: how often it was called, how often those
+// calls reverted, and how many of its instructions were covered by the corpus. Summaries are sorted by lowest
+// instruction coverage first, followed by those whose instruction coverage could not be determined.
+// Returns the function coverage summaries, or nil if no campaign has been started.
+func (f *Fuzzer) FunctionCoverageSummaries() []FunctionCoverageSummary {
+	// If we have not started a campaign, we have nothing to summarize.
+	if f.metrics == nil || f.corpus == nil {
+		return nil
+	}
+
+	// Determine the contracts to summarize.
+	methodCallMetrics := f.metrics.methodCallMetrics()
+	contractNames := make(map[string]struct{})
+	for _, contractName := range f.config.Fuzzing.DeploymentOrder {
+		contractNames[contractName] = struct{}{}
+	}
+	for key := range methodCallMetrics {
+		contractNames[key.contractName] = struct{}{}
+	}
+
+	// Summarize each state changing method of each such contract.
+	summaries := make([]FunctionCoverageSummary, 0)
+	for _, compilation := range f.compilations {
+		for sourcePath, source := range compilation.Sources {
+			for contractName := range source.Contracts {
+				if _, ok := contractNames[contractName]; !ok {
+					continue
+				}
+				contract := source.Contracts[contractName]
+
+				// Determine the instruction coverage of each function. If this fails (e.g. an unparsable source map),
+				// we still summarize the calls to each method.
+				functionCoverage, err := coverage.AnalyzeFunctionCoverage(&contract, compilation.GetFunctionSourceRanges(sourcePath, contractName), f.corpus.CoverageMaps())
+				if err != nil {
+					functionCoverage = nil
+				}
+
+				for _, method := range contract.Abi.Methods {
+					if method.IsConstant() {
+						continue
+					}
+					metrics := methodCallMetrics[methodCallMetricsKey{contractName: contractName, methodSignature: method.Sig}]
+					summary := FunctionCoverageSummary{
+						ContractName:    contractName,
+						MethodSignature: method.Sig,
+						Calls:           metrics.calls,
+						Reverts:         metrics.reverts,
+					}
+					if instructionCoverage, ok := functionCoverage[fmt.Sprintf("%x", method.ID)]; ok {
+						summary.InstructionCoverage = &instructionCoverage
+					}
+					summaries = append(summaries, summary)
+				}
+			}
+		}
+	}
+
+	// Sort our summaries by lowest coverage first, then by name.
+	sort.SliceStable(summaries, func(i, j int) bool {
+		iCoverage, jCoverage := summaries[i].InstructionCoverage, summaries[j].InstructionCoverage
+		if (iCoverage == nil) != (jCoverage == nil) {
+			return jCoverage == nil
+		}
+		if iCoverage != nil && iCoverage.Percentage() != jCoverage.Percentage() {
+			return iCoverage.Percentage() < jCoverage.Percentage()
+		}
+		if summaries[i].ContractName != summaries[j].ContractName {
+			return summaries[i].ContractName < summaries[j].ContractName
+		}
+		return summaries[i].MethodSignature < summaries[j].MethodSignature
+	})
+	return slices.Clip(summaries)
+}
+
+// PrintFunctionCoverageSummary prints a table summarizing how thoroughly each state changing method was exercised,
+// as described by FunctionCoverageSummaries. This may be called while a campaign is running.
+func (f *Fuzzer) PrintFunctionCoverageSummary() {
+	summaries := f.FunctionCoverageSummaries()
+	if len(summaries) == 0 {
+		return
+	}
+
+	// In the JSON log format, the summaries are logged as structured data rather than a table.
+	if logging.GlobalLogger.Format() == logging.LogFormatJSON {
+		logging.GlobalLogger.Info().Interface("functionCoverage", summaries).Msg("Function coverage")
+		return
+	}
+
+	var table strings.Builder
+	writer := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CONTRACT\tMETHOD\tCALLED\tCALLS\tREVERT RATE\tCOVERAGE\n")
+	for _, summary := range summaries {
+		called := "no"
+		if summary.CalledSuccessfully() {
+			called = "yes"
+		}
+		instructionCoverage := "n/a"
+		if summary.InstructionCoverage != nil {
+			instructionCoverage = fmt.Sprintf("%.1f%% (%d/%d)", summary.InstructionCoverage.Percentage(), summary.InstructionCoverage.Covered, summary.InstructionCoverage.Total)
+		}
+		fmt.Fprintf(writer, "%v\t%v\t%v\t%d\t%.1f%%\t%v\n", summary.ContractName, summary.MethodSignature, called, summary.Calls, summary.RevertRate()*100, instructionCoverage)
+	}
+	_ = writer.Flush()
+	logging.GlobalLogger.Break()
+	logging.GlobalLogger.Info().Msgf("Function coverage:\n%s", strings.TrimRight(table.String(), "\n"))
+}
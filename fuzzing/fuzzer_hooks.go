@@ -3,12 +3,21 @@ package fuzzing
 import (
 	"github.com/crytic/medusa/chain"
 	"github.com/crytic/medusa/fuzzing/calls"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
 	"github.com/crytic/medusa/fuzzing/valuegeneration"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"math/rand"
 )
 
 // FuzzerHooks defines the hooks that can be used for the Fuzzer on an API level.
 type FuzzerHooks struct {
+	// NewValueGeneratorFunc describes the function to use to set up a new valuegeneration.ValueGenerator for a new
+	// FuzzerWorker. It is called upon by the default NewCallSequenceGeneratorConfigFunc, and can be replaced to supply
+	// custom (e.g. protocol-aware) value generators without replacing the entire call sequence generator config.
+	// Note: A new instance should be provided per call, as each FuzzerWorker uses its own value set and random
+	// provider.
+	NewValueGeneratorFunc NewValueGeneratorFunc
+
 	// NewCallSequenceGeneratorConfigFunc describes the function to use to set up a new CallSequenceGeneratorConfig,
 	// defining parameters for a new FuzzerWorker's CallSequenceGenerator.
 	// Note: The value generator provided within the config must be either thread safe, or a new instance must be
@@ -21,8 +30,22 @@ type FuzzerHooks struct {
 	// CallSequenceTestFuncs describes a list of functions to be called upon by a FuzzerWorker after every call
 	// in a call sequence.
 	CallSequenceTestFuncs []CallSequenceTestFunc
+
+	// CallArgumentsModifyFuncs describes a list of functions to be called upon by a FuzzerWorker once all argument
+	// values of a generated or mutated call are known, before the call data is packed.
+	CallArgumentsModifyFuncs []CallArgumentsModifyFunc
+
+	// CallMirrorFuncs describes a list of functions to be called upon by a FuzzerWorker for every call sequence
+	// element it is about to execute while testing a call sequence, which may execute additional calls after it.
+	CallMirrorFuncs []CallMirrorFunc
 }
 
+// NewValueGeneratorFunc defines a method which is called to create a valuegeneration.ValueGenerator for a new
+// FuzzerWorker to use when generating and mutating call arguments. It is provided the worker's value set and random
+// provider, which the generator should use to remain consistent with the rest of the worker's state.
+// Returns a new ValueGenerator, or an error if one is encountered.
+type NewValueGeneratorFunc func(fuzzer *Fuzzer, valueSet *valuegeneration.ValueSet, randomProvider *rand.Rand) (valuegeneration.ValueGenerator, error)
+
 // NewCallSequenceGeneratorConfigFunc defines a method is called to create a new CallSequenceGeneratorConfig, defining
 // the parameters for the new FuzzerWorker to use when creating its CallSequenceGenerator used to power fuzzing.
 // Returns a new CallSequenceGeneratorConfig, or an error if one is encountered.
@@ -40,6 +63,23 @@ type TestChainSetupFunc func(fuzzer *Fuzzer, testChain *chain.TestChain) error
 // current call sequence from being further generated and tested.
 type CallSequenceTestFunc func(worker *FuzzerWorker, callSequence calls.CallSequence) ([]ShrinkCallSequenceRequest, error)
 
+// CallArgumentsModifyFunc defines a method called by a FuzzerWorker once all argument values of a generated or mutated
+// call to the provided method are known, before the call data is packed. The contract definition the method belongs
+// to is provided if it is known, or nil otherwise. Unlike value generator mutations, which
+// operate on a single argument, it may modify the provided values together (e.g. to relate arguments to one another).
+// Values must remain of the types expected for their respective method inputs.
+// Returns an error if one occurs.
+type CallArgumentsModifyFunc func(worker *FuzzerWorker, contract *fuzzerTypes.Contract, method *abi.Method, values []any) error
+
+// CallMirrorFunc defines a method called by a FuzzerWorker for each call sequence element it is about to execute
+// while testing a call sequence. It may provide elements to execute immediately after it (e.g. the same call sent to
+// another contract, so the states of both contracts remain in sync), and may discard the element entirely (e.g. if it
+// is a mirror recorded in a previous call sequence, which will be provided again). The nonces of provided elements are
+// updated from the chain state prior to their execution.
+// Returns the elements to execute after the provided element, a boolean indicating whether the provided element
+// should be executed, or an error if one occurs.
+type CallMirrorFunc func(worker *FuzzerWorker, element *calls.CallSequenceElement) ([]*calls.CallSequenceElement, bool, error)
+
 // ShrinkCallSequenceRequest is a structure signifying a request for a shrunken call sequence from the FuzzerWorker.
 type ShrinkCallSequenceRequest struct {
 	// VerifierFunction is a method is called upon by a FuzzerWorker to check if a shrunken call sequence satisfies
@@ -0,0 +1,148 @@
+package fuzzing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crytic/medusa/chain"
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/slices"
+)
+
+// isLibraryContract indicates whether the provided contract is a library which was deployed during chain setup so the
+// contracts referencing it could be linked. Libraries may only be called through the contracts referencing them, so
+// their methods are not called directly.
+func (f *Fuzzer) isLibraryContract(contract *fuzzerTypes.Contract) bool {
+	return slices.Contains(f.libraryContracts, contract)
+}
+
+// resolveLibrary resolves the contract definition of the library with the provided link reference, as described by
+// compilationTypes.LinkReferences.
+// Returns the library, or nil if it was not found.
+func (f *Fuzzer) resolveLibrary(reference string) *fuzzerTypes.Contract {
+	for _, contract := range f.contractDefinitions {
+		if compilationTypes.IsLinkReferenceTo(reference, contract.SourcePath(), contract.Name()) {
+			return contract
+		}
+	}
+	return nil
+}
+
+// libraryDeploymentOrder determines the libraries which must be deployed so the provided contracts can be linked,
+// including the libraries those libraries reference, ordered so every library is deployed after the libraries it
+// references.
+// Returns the libraries in the order they must be deployed, or an error if a library could not be found, or libraries
+// reference each other circularly, so they cannot be linked.
+func (f *Fuzzer) libraryDeploymentOrder(contracts []*fuzzerTypes.Contract) ([]*fuzzerTypes.Contract, error) {
+	// Visit the libraries referenced by each contract depth-first, so each library is appended to our order after those
+	// it references. We track the path of libraries being visited to report any circular references.
+	order := make([]*fuzzerTypes.Contract, 0)
+	path := make([]*fuzzerTypes.Contract, 0)
+	var visit func(contract *fuzzerTypes.Contract) error
+	visit = func(contract *fuzzerTypes.Contract) error {
+		for _, reference := range contract.CompiledContract().LibraryReferences() {
+			library := f.resolveLibrary(reference)
+			if library == nil {
+				return fmt.Errorf("contract \"%v\" references library \"%v\", which was not found in the compilation", contract.Name(), reference)
+			}
+			if slices.Contains(order, library) {
+				continue
+			}
+			if index := slices.Index(path, library); index >= 0 {
+				names := make([]string, 0, len(path)-index+1)
+				for _, pathLibrary := range append(path[index:], library) {
+					names = append(names, pathLibrary.Name())
+				}
+				return fmt.Errorf("libraries reference each other circularly and cannot be linked: %v", strings.Join(names, " -> "))
+			}
+
+			path = append(path, library)
+			err := visit(library)
+			if err != nil {
+				return err
+			}
+			path = path[:len(path)-1]
+			order = append(order, library)
+		}
+		return nil
+	}
+	for _, contract := range contracts {
+		path = append(path[:0], contract)
+		err := visit(contract)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// linkLibraries links every contract definition which references any of the provided deployed libraries with their
+// addresses, so they can be deployed and matched to their deployments.
+// Returns an error if one occurs.
+func (f *Fuzzer) linkLibraries(libraryAddresses map[*fuzzerTypes.Contract]common.Address) error {
+	for _, contract := range f.contractDefinitions {
+		for _, reference := range contract.CompiledContract().LibraryReferences() {
+			library := f.resolveLibrary(reference)
+			address, deployed := libraryAddresses[library]
+			if !deployed {
+				continue
+			}
+			err := contract.CompiledContract().LinkLibrary(reference, address)
+			if err != nil {
+				return fmt.Errorf("could not link contract \"%v\" with library \"%v\": %v", contract.Name(), library.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// chainSetupDeployLibraries deploys the libraries referenced by the contracts with the provided names and the agent
+// contracts named by the Fuzzer.config, in the order they must be deployed to link each of them, and links every
+// contract definition referencing them with their addresses.
+// Returns a lookup of the deployed library addresses by name, or an error if one occurs.
+func chainSetupDeployLibraries(fuzzer *Fuzzer, testChain *chain.TestChain, contractNames []string) (map[string]common.Address, error) {
+	// Determine the libraries we must deploy.
+	contracts := make([]*fuzzerTypes.Contract, 0)
+	for _, contract := range fuzzer.contractDefinitions {
+		if slices.Contains(contractNames, contract.Name()) || fuzzer.isAgentContract(contract) {
+			contracts = append(contracts, contract)
+		}
+	}
+	libraries, err := fuzzer.libraryDeploymentOrder(contracts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deploy each library, linking it with the libraries it references, which were deployed before it.
+	fuzzer.libraryContracts = libraries
+	libraryAddresses := make(map[*fuzzerTypes.Contract]common.Address)
+	deployedLibraryAddr := make(map[string]common.Address)
+	for _, library := range libraries {
+		err = fuzzer.linkLibraries(libraryAddresses)
+		if err != nil {
+			return nil, err
+		}
+		msgData, err := library.CompiledContract().GetDeploymentMessageData(nil)
+		if err != nil {
+			return nil, fmt.Errorf("initial contract deployment failed for library \"%v\", error: %v", library.Name(), err)
+		}
+		messageResults, err := chainSetupSendMessage(fuzzer, testChain, nil, msgData)
+		if err != nil {
+			return nil, fmt.Errorf("initial contract deployment failed for library \"%v\", error: %v", library.Name(), err)
+		}
+		libraryAddresses[library] = messageResults.Receipt.ContractAddress
+		deployedLibraryAddr[library.Name()] = messageResults.Receipt.ContractAddress
+		logging.GlobalLogger.Info().Str("address", messageResults.Receipt.ContractAddress.String()).
+			Msgf("Deployed library %v at %v", library.Name(), messageResults.Receipt.ContractAddress.String())
+	}
+
+	// Link every contract with the libraries it references.
+	err = fuzzer.linkLibraries(libraryAddresses)
+	if err != nil {
+		return nil, err
+	}
+	return deployedLibraryAddr, nil
+}
@@ -0,0 +1,89 @@
+package fuzzing
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestLibraryContract creates a contract definition in the source "src/Libraries.sol" with the provided name, whose
+// init bytecode references the libraries with the provided names in that source.
+func newTestLibraryContract(name string, libraryNames ...string) *fuzzerTypes.Contract {
+	linkReferences := make(compilationTypes.LinkReferences)
+	for i, libraryName := range libraryNames {
+		linkReferences["src/Libraries.sol:"+libraryName] = []int{i * common.AddressLength}
+	}
+	return fuzzerTypes.NewContract(name, "src/Libraries.sol", &compilationTypes.CompiledContract{
+		InitBytecode:       make([]byte, len(libraryNames)*common.AddressLength),
+		InitLinkReferences: linkReferences,
+	}, nil)
+}
+
+// TestLibraryDeploymentOrder ensures the libraries referenced by contracts are deployed after the libraries they
+// reference, and that missing libraries and circular references between libraries are reported.
+func TestLibraryDeploymentOrder(t *testing.T) {
+	libraryA := newTestLibraryContract("LibraryA")
+	libraryB := newTestLibraryContract("LibraryB", "LibraryA")
+	libraryC := newTestLibraryContract("LibraryC", "LibraryB")
+	target := newTestLibraryContract("Target", "LibraryC", "LibraryA")
+	unreferenced := newTestLibraryContract("Unreferenced")
+	fuzzer := &Fuzzer{contractDefinitions: fuzzerTypes.Contracts{target, libraryC, unreferenced, libraryB, libraryA}}
+
+	// Every library should be deployed once, after those it references.
+	order, err := fuzzer.libraryDeploymentOrder([]*fuzzerTypes.Contract{target})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*fuzzerTypes.Contract{libraryA, libraryB, libraryC}, order)
+
+	// Contracts which reference no libraries require none to be deployed.
+	order, err = fuzzer.libraryDeploymentOrder([]*fuzzerTypes.Contract{unreferenced})
+	assert.NoError(t, err)
+	assert.Empty(t, order)
+
+	// Libraries which are not in the compilation cannot be deployed.
+	missing := newTestLibraryContract("Missing", "LibraryD")
+	_, err = fuzzer.libraryDeploymentOrder([]*fuzzerTypes.Contract{missing})
+	assert.ErrorContains(t, err, "LibraryD")
+
+	// Libraries which reference each other circularly cannot be linked.
+	cyclicA := newTestLibraryContract("LibraryA", "LibraryB")
+	cyclicB := newTestLibraryContract("LibraryB", "LibraryA")
+	fuzzer.contractDefinitions = fuzzerTypes.Contracts{target, libraryC, cyclicB, cyclicA}
+	_, err = fuzzer.libraryDeploymentOrder([]*fuzzerTypes.Contract{target})
+	assert.ErrorContains(t, err, "LibraryA -> LibraryB -> LibraryA")
+}
+
+// TestLinkLibraries ensures the placeholders compilers leave for library addresses in bytecode are decoded and linked
+// with the addresses of deployed libraries, in both their current and legacy forms.
+func TestLinkLibraries(t *testing.T) {
+	// Create bytecode referencing our library with both forms of placeholder.
+	hash := hex.EncodeToString(crypto.Keccak256([]byte("src/Libraries.sol:Library")))
+	placeholders := []string{"__$" + hash[:34] + "$__", ("__src/Libraries.sol:Library" + strings.Repeat("_", 40))[:40]}
+	for _, placeholder := range placeholders {
+		bytecode, linkReferences, err := compilationTypes.DecodeLinkableBytecode("0x73" + placeholder + "00")
+		assert.NoError(t, err)
+		assert.EqualValues(t, append(append([]byte{0x73}, make([]byte, common.AddressLength)...), 0x00), bytecode)
+		assert.EqualValues(t, compilationTypes.LinkReferences{placeholder: {1}}, linkReferences)
+
+		// Link our contract with the address of our deployed library.
+		library := newTestLibraryContract("Library")
+		target := fuzzerTypes.NewContract("Target", "src/Target.sol", &compilationTypes.CompiledContract{
+			InitBytecode:       bytecode,
+			InitLinkReferences: linkReferences,
+		}, nil)
+		fuzzer := &Fuzzer{contractDefinitions: fuzzerTypes.Contracts{target, library}}
+		address := common.HexToAddress("0x1234567890123456789012345678901234567890")
+		err = fuzzer.linkLibraries(map[*fuzzerTypes.Contract]common.Address{library: address})
+		assert.NoError(t, err)
+		assert.EqualValues(t, append(append([]byte{0x73}, address.Bytes()...), 0x00), target.CompiledContract().InitBytecode)
+	}
+
+	// Placeholders must span an entire address.
+	_, _, err := compilationTypes.DecodeLinkableBytecode("0x73__$abc$__00")
+	assert.Error(t, err)
+}
@@ -0,0 +1,200 @@
+package fuzzing
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/config"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/logging"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// methodSignaturePattern matches method signatures (e.g. "transfer(address,uint256)"), optionally qualified by a
+// contract name (e.g. "Token.transfer(address,uint256)"). Method filter entries which do not match it are treated as
+// regular expressions.
+var methodSignaturePattern = regexp.MustCompile(`^(\w+\.)?\w+\(.*\)$`)
+
+// methodFilter describes a filter over the contract methods the fuzzer may call, as configured by the
+// FuzzingConfig IncludeFunctionSignatures or ExcludeFunctionSignatures options.
+type methodFilter struct {
+	// include describes whether the filter entries describe the only methods which may be called, rather than
+	// methods which may not be called.
+	include bool
+
+	// signatures describes method signatures matched by the filter, either alone or qualified by a contract name.
+	signatures []string
+
+	// patterns describes regular expressions matched by the filter against contract-qualified method signatures.
+	patterns []*regexp.Regexp
+}
+
+// newMethodFilter creates a methodFilter from the function signature filters in the provided config.
+// Returns the filter, or nil if no filters are configured, or an error if one occurs.
+func newMethodFilter(fuzzingConfig config.FuzzingConfig) (*methodFilter, error) {
+	if len(fuzzingConfig.IncludeFunctionSignatures) > 0 {
+		return newMethodFilterFromEntries(fuzzingConfig.IncludeFunctionSignatures, true)
+	}
+	if len(fuzzingConfig.ExcludeFunctionSignatures) > 0 {
+		return newMethodFilterFromEntries(fuzzingConfig.ExcludeFunctionSignatures, false)
+	}
+	return nil, nil
+}
+
+// newMethodFilterFromEntries creates a methodFilter from the provided entries, each a method signature, a
+// contract-qualified method signature, or a regular expression matching contract-qualified method signatures. If
+// include is true, the filter allows only the methods matched by its entries. Otherwise, it allows all others.
+// Returns the filter, or an error if one occurs.
+func newMethodFilterFromEntries(entries []string, include bool) (*methodFilter, error) {
+	filter := &methodFilter{include: include}
+	for _, entry := range entries {
+		if methodSignaturePattern.MatchString(entry) {
+			filter.signatures = append(filter.signatures, entry)
+			continue
+		}
+		pattern, err := regexp.Compile("^(?:" + entry + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid function signature filter '%s': %v", entry, err)
+		}
+		filter.patterns = append(filter.patterns, pattern)
+	}
+	return filter, nil
+}
+
+// allows indicates whether the fuzzer may call the provided method of the provided contract. A nil filter allows
+// every method.
+func (m *methodFilter) allows(contract *fuzzerTypes.Contract, method abi.Method) bool {
+	if m == nil {
+		return true
+	}
+	return m.matches(contract, method) == m.include
+}
+
+// matches indicates whether any entry of the filter matches the provided method of the provided contract.
+func (m *methodFilter) matches(contract *fuzzerTypes.Contract, method abi.Method) bool {
+	qualifiedSignature := contract.Name() + "." + method.Sig
+	for _, signature := range m.signatures {
+		if signature == method.Sig || signature == qualifiedSignature {
+			return true
+		}
+	}
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(qualifiedSignature) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCallableMethod indicates whether the fuzzer may call the provided method in the call sequences it generates,
+// before function signature filters are applied. Constant (pure/view) methods cannot change contract state, so they
+// are only called if assertion testing expects them to revert, as their test cases are otherwise never executed.
+func (f *Fuzzer) isCallableMethod(method abi.Method) bool {
+	if !method.IsConstant() {
+		return true
+	}
+	assertionTestingConfig := f.config.Fuzzing.Testing.AssertionTesting
+	return assertionTestingConfig.Enabled && expectRevertMethodSuffix(assertionTestingConfig, method) != nil
+}
+
+// reportTargetMethods logs the methods the fuzzer may call in contracts deployed to the provided test chain, after
+// any function signature filters are applied.
+// Returns an error if function signature filters leave no methods to call.
+func (f *Fuzzer) reportTargetMethods(testChain *chain.TestChain) error {
+	// Determine which contracts remain deployed on the chain.
+	deployedContracts := make(map[common.Address]*fuzzerTypes.Contract)
+	for _, block := range testChain.CommittedBlocks() {
+		for _, messageResults := range block.MessageResults {
+			for _, deploymentChange := range messageResults.ContractDeploymentChanges {
+				if deploymentChange.Creation {
+					contract := f.contractDefinitions.MatchBytecode(deploymentChange.Contract.InitBytecode, deploymentChange.Contract.RuntimeBytecode)
+					if contract != nil {
+						deployedContracts[deploymentChange.Contract.Address] = contract
+					}
+				} else if deploymentChange.Destroyed {
+					delete(deployedContracts, deploymentChange.Contract.Address)
+				}
+			}
+		}
+	}
+
+	// Collect the state changing methods the fuzzer may call in them.
+	methodNames := make([]string, 0)
+	for _, contract := range deployedContracts {
+		for _, method := range contract.CompiledContract().Abi.Methods {
+			methodName := contract.Name() + "." + method.Sig
+			if f.isCallableMethod(method) && f.methodFilter.allows(contract, method) && !slices.Contains(methodNames, methodName) {
+				methodNames = append(methodNames, methodName)
+			}
+		}
+	}
+	sort.Strings(methodNames)
+
+	// If our filters leave nothing to call, fuzzing would be pointless, so we fail instead.
+	if len(methodNames) == 0 && f.methodFilter != nil {
+		return fmt.Errorf("function signature filters leave no methods for the fuzzer to call")
+	}
+	logging.GlobalLogger.Info().Strs("methods", methodNames).
+		Msgf("Fuzzing %d method(s): %v", len(methodNames), strings.Join(methodNames, ", "))
+	return nil
+}
+
+// methodRoleFilter describes a filter over contract methods which only senders with certain roles may call, as
+// configured by an entry of the FuzzingConfig MethodRoles option.
+type methodRoleFilter struct {
+	// filter describes the filter matching the methods which are restricted.
+	filter *methodFilter
+
+	// roles describes the roles of the senders which may call the matched methods.
+	roles []string
+}
+
+// newMethodRoleFilters creates a methodRoleFilter for every entry of the method roles in the provided config, sorted
+// by entry, so senders are resolved deterministically.
+// Returns the filters, or an error if one occurs.
+func newMethodRoleFilters(fuzzingConfig config.FuzzingConfig) ([]methodRoleFilter, error) {
+	entries := maps.Keys(fuzzingConfig.MethodRoles)
+	sort.Strings(entries)
+	roleFilters := make([]methodRoleFilter, 0, len(entries))
+	for _, entry := range entries {
+		filter, err := newMethodFilterFromEntries([]string{entry}, true)
+		if err != nil {
+			return nil, err
+		}
+		roleFilters = append(roleFilters, methodRoleFilter{filter: filter, roles: fuzzingConfig.MethodRoles[entry]})
+	}
+	return roleFilters, nil
+}
+
+// methodSenders obtains the senders which may call the provided method of the provided contract. If any method role
+// filters match the method, only senders with one of the roles they allow may call it. Otherwise, any sender may.
+// Returns the senders which may call the method.
+func (f *Fuzzer) methodSenders(contract *fuzzerTypes.Contract, method abi.Method) []common.Address {
+	// Collect the roles allowed to call the method, if it is restricted.
+	var roles []string
+	restricted := false
+	for _, roleFilter := range f.methodRoleFilters {
+		if roleFilter.filter.matches(contract, method) {
+			roles = append(roles, roleFilter.roles...)
+			restricted = true
+		}
+	}
+	if !restricted {
+		return f.senders
+	}
+
+	// Collect the senders with any of those roles.
+	senders := make([]common.Address, 0)
+	for _, sender := range f.senders {
+		if role, ok := f.senderRoles[sender]; ok && slices.Contains(roles, role) {
+			senders = append(senders, sender)
+		}
+	}
+	return senders
+}
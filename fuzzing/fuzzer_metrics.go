@@ -1,65 +1,474 @@
 package fuzzing
 
-import "math/big"
+import (
+	"encoding/json"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 // FuzzerMetrics represents a struct tracking metrics for a Fuzzer run.
 type FuzzerMetrics struct {
 	// workerMetrics describes the metrics for each individual worker. This expands as needed and some slots may be nil
 	// while workers are initializing, as it corresponds to the indexes in Fuzzer.workers.
 	workerMetrics []fuzzerWorkerMetrics
+
+	// corpus describes the corpus used in the fuzzing campaign, from which corpus metrics are obtained.
+	corpus *corpus.Corpus
+
+	// functionCoverageSummaries obtains the function coverage summaries of the fuzzing campaign, which are derived
+	// from the method call outcomes recorded in these metrics and the coverage of the corpus.
+	functionCoverageSummaries func() []FunctionCoverageSummary
+
+	// resumedMetrics describes the cumulative metrics of the campaigns this one resumed from, which are included in
+	// the totals reported by these metrics.
+	resumedMetrics checkpointMetrics
+}
+
+// metricsCounter represents a counter which a worker increments while other goroutines (e.g. the metrics printing
+// loop or metrics server) read it, without requiring a lock.
+type metricsCounter struct {
+	// value describes the current value of the counter. It must only be accessed atomically.
+	value uint64
+}
+
+// add atomically adds the provided delta to the counter.
+func (c *metricsCounter) add(delta uint64) {
+	atomic.AddUint64(&c.value, delta)
+}
+
+// load atomically obtains the current value of the counter.
+func (c *metricsCounter) load() uint64 {
+	return atomic.LoadUint64(&c.value)
 }
 
 // fuzzerWorkerMetrics represents metrics for a single FuzzerWorker instance.
 type fuzzerWorkerMetrics struct {
 	// sequencesTested describes the amount of sequences of transactions which tests were run against.
-	sequencesTested *big.Int
+	sequencesTested *metricsCounter
 
 	// callsTested describes the amount of transactions/calls the fuzzer executed and ran tests against.
-	callsTested *big.Int
+	callsTested *metricsCounter
+
+	// callsOutOfGas describes the amount of executed calls which failed because they ran out of gas.
+	callsOutOfGas *metricsCounter
 
 	// workerStartupCount describes the amount of times the worker was generated, or re-generated for this index.
-	workerStartupCount *big.Int
+	workerStartupCount *metricsCounter
+
+	// workerResets describes the amount of times the worker was destroyed so it could be re-generated for this index,
+	// for each cause of a reset.
+	workerResets map[WorkerResetCause]*metricsCounter
+
+	// targetedArgumentMutations describes the amount of corpus calls for which a single argument was selected for
+	// mutation, using coverage feedback from previous mutations.
+	targetedArgumentMutations *metricsCounter
+
+	// productiveArgumentMutations describes the amount of mutated arguments which were attributed a coverage
+	// increase.
+	productiveArgumentMutations *metricsCounter
+
+	// shrinkCandidatesTested describes the amount of candidate call sequences the worker tested while shrinking call
+	// sequences, including those tested on behalf of other workers.
+	shrinkCandidatesTested *metricsCounter
+
+	// shrinkDuration describes the time, in nanoseconds, the worker spent testing shrink candidates.
+	shrinkDuration *metricsCounter
+
+	// methodCalls describes the outcomes of the calls the worker executed, for each method called.
+	methodCalls *methodCallMetricsTracker
+
+	// methodSelection describes the recent call outcomes and latest weight of each method, used to select the methods
+	// targeted by new calls.
+	methodSelection *methodSelectionTracker
+
+	// storageGrowth describes how the storage and code of each contract grew in the call sequences the worker tested,
+	// if storage growth is recorded.
+	storageGrowth *storageGrowthTracker
 }
 
-// newFuzzerMetrics obtains a new FuzzerMetrics struct for a given number of workers specified by workerCount.
+// methodCallMetricsKey identifies a method of a contract for which call outcomes are recorded.
+type methodCallMetricsKey struct {
+	// contractName describes the name of the contract the method belongs to.
+	contractName string
+
+	// methodSignature describes the signature of the method.
+	methodSignature string
+}
+
+// methodCallMetrics describes the outcomes of the calls to a single method.
+type methodCallMetrics struct {
+	// calls describes the amount of calls to the method.
+	calls uint64
+
+	// reverts describes the amount of calls to the method which reverted or otherwise failed.
+	reverts uint64
+
+	// revertReasons describes the amount of reverts for each distinct revert data they returned, truncated to
+	// revertReasonDataLength bytes.
+	revertReasons map[string]*uint64
+
+	// errorReasons describes the amount of calls which failed for each distinct VM error other than a revert.
+	errorReasons map[string]*uint64
+
+	// untrackedReverts describes the amount of reverts and failures whose reason was not recorded, as the method
+	// already recorded revertReasonsLimit distinct reasons.
+	untrackedReverts uint64
+}
+
+// revertReasonsLimit describes the maximum amount of distinct revert reasons recorded for each method by each worker,
+// which bounds the memory used to record them.
+const revertReasonsLimit = 16
+
+// revertReasonDataLength describes the maximum amount of bytes of revert data which identify a distinct revert
+// reason. This fits an error string of up to 256 bytes.
+const revertReasonDataLength = 4 + 32 + 32 + 256
+
+// recordRevertReason records the reason for a reverted or failed call with the provided execution result. Revert
+// data is only converted to a string when a reason is first recorded, so recording known reasons does not allocate.
+func (m *methodCallMetrics) recordRevertReason(executionResult *core.ExecutionResult) {
+	// Record reverts by the data they returned.
+	if executionResult != nil && executionResult.Err == vm.ErrExecutionReverted {
+		returnData := executionResult.ReturnData
+		if len(returnData) > revertReasonDataLength {
+			returnData = returnData[:revertReasonDataLength]
+		}
+		if count, ok := m.revertReasons[string(returnData)]; ok {
+			*count++
+		} else if len(m.revertReasons)+len(m.errorReasons) >= revertReasonsLimit {
+			m.untrackedReverts++
+		} else {
+			if m.revertReasons == nil {
+				m.revertReasons = make(map[string]*uint64)
+			}
+			count = new(uint64)
+			*count = 1
+			m.revertReasons[string(returnData)] = count
+		}
+		return
+	}
+
+	// Record other failures by their VM error.
+	reason := "no execution result"
+	if executionResult != nil && executionResult.Err != nil {
+		reason = executionResult.Err.Error()
+	}
+	if count, ok := m.errorReasons[reason]; ok {
+		*count++
+	} else if len(m.revertReasons)+len(m.errorReasons) >= revertReasonsLimit {
+		m.untrackedReverts++
+	} else {
+		if m.errorReasons == nil {
+			m.errorReasons = make(map[string]*uint64)
+		}
+		count = new(uint64)
+		*count = 1
+		m.errorReasons[reason] = count
+	}
+}
+
+// methodCallMetricsTracker records the outcomes of calls for each method called. It provides thread-synchronization,
+// as recorded outcomes are read by the Fuzzer while workers record them.
+type methodCallMetricsTracker struct {
+	// metrics describes the outcomes of the calls to each method.
+	metrics map[methodCallMetricsKey]*methodCallMetrics
+
+	// lock provides thread-synchronization to avoid race conditions when accessing metrics.
+	lock sync.Mutex
+}
+
+// recordCall records the outcome of the provided executed call sequence element, if the method it called can be
+// resolved.
+func (t *methodCallMetricsTracker) recordCall(element *calls.CallSequenceElement) {
+	// If the element was not executed or did not call a known method, there is nothing to record.
+	if element.ChainReference == nil {
+		return
+	}
+	method, err := element.Method()
+	if err != nil || method == nil {
+		return
+	}
+	executionResult := element.ChainReference.MessageResults().ExecutionResult
+	reverted := executionResult == nil || executionResult.Failed()
+
+	// Record the outcome of the call.
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	key := methodCallMetricsKey{contractName: element.Contract.Name(), methodSignature: method.Sig}
+	metrics, ok := t.metrics[key]
+	if !ok {
+		metrics = &methodCallMetrics{}
+		t.metrics[key] = metrics
+	}
+	metrics.calls++
+	if reverted {
+		metrics.reverts++
+		metrics.recordRevertReason(executionResult)
+	}
+}
+
+// newFuzzerMetrics obtains a new FuzzerMetrics struct for a given number of workers specified by workerCount, the
+// corpus used by the fuzzing campaign, and a function which obtains its function coverage summaries.
 // Returns the new FuzzerMetrics object.
-func newFuzzerMetrics(workerCount int) *FuzzerMetrics {
+func newFuzzerMetrics(workerCount int, corpus *corpus.Corpus, functionCoverageSummaries func() []FunctionCoverageSummary) *FuzzerMetrics {
 	// Create a new metrics struct and return it with as many slots as required.
 	metrics := FuzzerMetrics{
-		workerMetrics: make([]fuzzerWorkerMetrics, workerCount),
+		workerMetrics:             make([]fuzzerWorkerMetrics, workerCount),
+		corpus:                    corpus,
+		functionCoverageSummaries: functionCoverageSummaries,
 	}
 	for i := 0; i < len(metrics.workerMetrics); i++ {
-		metrics.workerMetrics[i].sequencesTested = big.NewInt(0)
-		metrics.workerMetrics[i].callsTested = big.NewInt(0)
-		metrics.workerMetrics[i].workerStartupCount = big.NewInt(0)
+		metrics.workerMetrics[i].sequencesTested = &metricsCounter{}
+		metrics.workerMetrics[i].callsTested = &metricsCounter{}
+		metrics.workerMetrics[i].callsOutOfGas = &metricsCounter{}
+		metrics.workerMetrics[i].workerStartupCount = &metricsCounter{}
+		metrics.workerMetrics[i].workerResets = map[WorkerResetCause]*metricsCounter{
+			WorkerResetCauseSequenceLimit: {},
+			WorkerResetCauseMemoryLimit:   {},
+		}
+		metrics.workerMetrics[i].targetedArgumentMutations = &metricsCounter{}
+		metrics.workerMetrics[i].productiveArgumentMutations = &metricsCounter{}
+		metrics.workerMetrics[i].shrinkCandidatesTested = &metricsCounter{}
+		metrics.workerMetrics[i].shrinkDuration = &metricsCounter{}
+		metrics.workerMetrics[i].methodCalls = &methodCallMetricsTracker{metrics: make(map[methodCallMetricsKey]*methodCallMetrics)}
+		metrics.workerMetrics[i].methodSelection = newMethodSelectionTracker()
+		metrics.workerMetrics[i].storageGrowth = newStorageGrowthTracker()
 	}
 	return &metrics
 }
 
-// SequencesTested returns the amount of sequences of transactions the fuzzer executed and ran tests against.
+// SequencesTested returns the amount of sequences of transactions the fuzzer executed and ran tests against, including
+// those of any campaigns it resumed from.
 func (m *FuzzerMetrics) SequencesTested() *big.Int {
-	sequencesTested := big.NewInt(0)
+	sequencesTested := new(big.Int).SetUint64(m.resumedMetrics.SequencesTested)
 	for _, workerMetrics := range m.workerMetrics {
-		sequencesTested.Add(sequencesTested, workerMetrics.sequencesTested)
+		sequencesTested.Add(sequencesTested, new(big.Int).SetUint64(workerMetrics.sequencesTested.load()))
 	}
 	return sequencesTested
 }
 
-// CallsTested returns the amount of transactions/calls the fuzzer executed and ran tests against.
+// CallsTested returns the amount of transactions/calls the fuzzer executed and ran tests against, including those of
+// any campaigns it resumed from.
 func (m *FuzzerMetrics) CallsTested() *big.Int {
-	transactionsTested := big.NewInt(0)
+	transactionsTested := new(big.Int).SetUint64(m.resumedMetrics.CallsTested)
 	for _, workerMetrics := range m.workerMetrics {
-		transactionsTested.Add(transactionsTested, workerMetrics.callsTested)
+		transactionsTested.Add(transactionsTested, new(big.Int).SetUint64(workerMetrics.callsTested.load()))
 	}
 	return transactionsTested
 }
 
+// CallsOutOfGas returns the amount of transactions/calls the fuzzer executed which failed because they ran out of gas.
+func (m *FuzzerMetrics) CallsOutOfGas() *big.Int {
+	callsOutOfGas := new(big.Int).SetUint64(m.resumedMetrics.CallsOutOfGas)
+	for _, workerMetrics := range m.workerMetrics {
+		callsOutOfGas.Add(callsOutOfGas, new(big.Int).SetUint64(workerMetrics.callsOutOfGas.load()))
+	}
+	return callsOutOfGas
+}
+
 // WorkerStartupCount describes the amount of times the worker was spawned for this index. Workers are periodically
 // reset.
 func (m *FuzzerMetrics) WorkerStartupCount() *big.Int {
 	workerStartupCount := big.NewInt(0)
 	for _, workerMetrics := range m.workerMetrics {
-		workerStartupCount.Add(workerStartupCount, workerMetrics.workerStartupCount)
+		workerStartupCount.Add(workerStartupCount, new(big.Int).SetUint64(workerMetrics.workerStartupCount.load()))
 	}
 	return workerStartupCount
 }
+
+// workerStartupCounts returns the amount of times a worker was spawned for each worker index.
+func (m *FuzzerMetrics) workerStartupCounts() []uint64 {
+	workerStartupCounts := make([]uint64, len(m.workerMetrics))
+	for i, workerMetrics := range m.workerMetrics {
+		workerStartupCounts[i] = workerMetrics.workerStartupCount.load()
+	}
+	return workerStartupCounts
+}
+
+// workerCallsTested returns the amount of calls tested by the worker at each index.
+func (m *FuzzerMetrics) workerCallsTested() []uint64 {
+	workerCallsTested := make([]uint64, len(m.workerMetrics))
+	for i, workerMetrics := range m.workerMetrics {
+		workerCallsTested[i] = workerMetrics.callsTested.load()
+	}
+	return workerCallsTested
+}
+
+// workerSequencesTested returns the amount of call sequences tested by the worker at each index.
+func (m *FuzzerMetrics) workerSequencesTested() []uint64 {
+	workerSequencesTested := make([]uint64, len(m.workerMetrics))
+	for i, workerMetrics := range m.workerMetrics {
+		workerSequencesTested[i] = workerMetrics.sequencesTested.load()
+	}
+	return workerSequencesTested
+}
+
+// WorkerResets returns the amount of times workers were destroyed so they could be re-generated with a fresh chain,
+// for each cause of a reset.
+func (m *FuzzerMetrics) WorkerResets() map[WorkerResetCause]*big.Int {
+	workerResets := make(map[WorkerResetCause]*big.Int)
+	for _, workerMetrics := range m.workerMetrics {
+		for cause, counter := range workerMetrics.workerResets {
+			if workerResets[cause] == nil {
+				workerResets[cause] = big.NewInt(0)
+			}
+			workerResets[cause].Add(workerResets[cause], new(big.Int).SetUint64(counter.load()))
+		}
+	}
+	return workerResets
+}
+
+// TargetedArgumentMutations returns the amount of corpus calls for which a single argument was selected for mutation,
+// using coverage feedback from previous mutations.
+func (m *FuzzerMetrics) TargetedArgumentMutations() *big.Int {
+	targetedArgumentMutations := big.NewInt(0)
+	for _, workerMetrics := range m.workerMetrics {
+		targetedArgumentMutations.Add(targetedArgumentMutations, new(big.Int).SetUint64(workerMetrics.targetedArgumentMutations.load()))
+	}
+	return targetedArgumentMutations
+}
+
+// ProductiveArgumentMutations returns the amount of mutated arguments which were attributed a coverage increase.
+func (m *FuzzerMetrics) ProductiveArgumentMutations() *big.Int {
+	productiveArgumentMutations := big.NewInt(0)
+	for _, workerMetrics := range m.workerMetrics {
+		productiveArgumentMutations.Add(productiveArgumentMutations, new(big.Int).SetUint64(workerMetrics.productiveArgumentMutations.load()))
+	}
+	return productiveArgumentMutations
+}
+
+// ShrinkCandidatesTested returns the amount of candidate call sequences tested by all workers while shrinking call
+// sequences.
+func (m *FuzzerMetrics) ShrinkCandidatesTested() *big.Int {
+	shrinkCandidatesTested := big.NewInt(0)
+	for _, workerMetrics := range m.workerMetrics {
+		shrinkCandidatesTested.Add(shrinkCandidatesTested, new(big.Int).SetUint64(workerMetrics.shrinkCandidatesTested.load()))
+	}
+	return shrinkCandidatesTested
+}
+
+// WorkerShrinkThroughputs returns the rate, in candidate call sequences per second, at which each worker tested
+// candidates while shrinking call sequences. Workers which have not tested any candidates have a rate of zero.
+func (m *FuzzerMetrics) WorkerShrinkThroughputs() []float64 {
+	throughputs := make([]float64, len(m.workerMetrics))
+	for i, workerMetrics := range m.workerMetrics {
+		if shrinkDuration := workerMetrics.shrinkDuration.load(); shrinkDuration > 0 {
+			seconds := float64(shrinkDuration) / float64(time.Second)
+			throughputs[i] = float64(workerMetrics.shrinkCandidatesTested.load()) / seconds
+		}
+	}
+	return throughputs
+}
+
+// methodCallMetrics returns the outcomes of the calls executed by all workers, for each method called.
+func (m *FuzzerMetrics) methodCallMetrics() map[methodCallMetricsKey]methodCallMetrics {
+	results := make(map[methodCallMetricsKey]methodCallMetrics)
+	for _, workerMetrics := range m.workerMetrics {
+		workerMetrics.methodCalls.lock.Lock()
+		for key, metrics := range workerMetrics.methodCalls.metrics {
+			result := results[key]
+			result.calls += metrics.calls
+			result.reverts += metrics.reverts
+			result.revertReasons = mergeRevertReasons(result.revertReasons, metrics.revertReasons)
+			result.errorReasons = mergeRevertReasons(result.errorReasons, metrics.errorReasons)
+			result.untrackedReverts += metrics.untrackedReverts
+			results[key] = result
+		}
+		workerMetrics.methodCalls.lock.Unlock()
+	}
+	return results
+}
+
+// mergeRevertReasons adds the provided revert reason counts to the provided merged revert reason counts, which are
+// created if they are nil and there are counts to add. The merged counts never share counters with the added counts.
+// Returns the merged revert reason counts.
+func mergeRevertReasons(merged map[string]*uint64, reasons map[string]*uint64) map[string]*uint64 {
+	if len(reasons) == 0 {
+		return merged
+	}
+	if merged == nil {
+		merged = make(map[string]*uint64, len(reasons))
+	}
+	for reason, count := range reasons {
+		if mergedCount, ok := merged[reason]; ok {
+			*mergedCount += *count
+		} else {
+			mergedCount = new(uint64)
+			*mergedCount = *count
+			merged[reason] = mergedCount
+		}
+	}
+	return merged
+}
+
+// CorpusDuplicateCallSequences returns the amount of call sequences discovered by workers which were not added to the
+// corpus, as an equivalent call sequence was already in it.
+func (m *FuzzerMetrics) CorpusDuplicateCallSequences() uint64 {
+	if m.corpus == nil {
+		return 0
+	}
+	return m.corpus.DuplicateCallSequenceCount()
+}
+
+// CorpusStateDuplicateCallSequences returns the amount of call sequences discovered by workers which reached the same
+// state as a call sequence in the corpus while adding little coverage, and either replaced it or were discarded, if
+// corpus state deduplication is enabled.
+func (m *FuzzerMetrics) CorpusStateDuplicateCallSequences() uint64 {
+	if m.corpus == nil {
+		return 0
+	}
+	return m.corpus.StateDuplicateCallSequenceCount()
+}
+
+// CorpusCallSequenceWeights returns the weights used to select each active corpus call sequence for mutation. If the
+// corpus power schedule is enabled, these reflect the rarity of the coverage each call sequence reached.
+func (m *FuzzerMetrics) CorpusCallSequenceWeights() []*big.Int {
+	if m.corpus == nil {
+		return nil
+	}
+	return m.corpus.CallSequenceWeights()
+}
+
+// FunctionCoverageSummaries returns a summary of how thoroughly each state changing method was exercised, as
+// described by Fuzzer.FunctionCoverageSummaries.
+func (m *FuzzerMetrics) FunctionCoverageSummaries() []FunctionCoverageSummary {
+	if m.functionCoverageSummaries == nil {
+		return nil
+	}
+	return m.functionCoverageSummaries()
+}
+
+// fuzzerMetricsJSON describes the JSON representation of FuzzerMetrics.
+type fuzzerMetricsJSON struct {
+	SequencesTested              *big.Int                      `json:"sequencesTested"`
+	CallsTested                  *big.Int                      `json:"callsTested"`
+	CallsOutOfGas                *big.Int                      `json:"callsOutOfGas"`
+	WorkerStartupCount           *big.Int                      `json:"workerStartupCount"`
+	WorkerResets                 map[WorkerResetCause]*big.Int `json:"workerResets"`
+	TargetedArgumentMutations    *big.Int                      `json:"targetedArgumentMutations"`
+	ProductiveArgumentMutations  *big.Int                      `json:"productiveArgumentMutations"`
+	ShrinkCandidatesTested       *big.Int                      `json:"shrinkCandidatesTested"`
+	CorpusDuplicateCallSequences uint64                        `json:"corpusDuplicateCallSequences"`
+	FunctionCoverage             []FunctionCoverageSummary     `json:"functionCoverage"`
+}
+
+// MarshalJSON provides a JSON representation of the metrics, summed across all workers.
+func (m *FuzzerMetrics) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fuzzerMetricsJSON{
+		SequencesTested:              m.SequencesTested(),
+		CallsTested:                  m.CallsTested(),
+		CallsOutOfGas:                m.CallsOutOfGas(),
+		WorkerStartupCount:           m.WorkerStartupCount(),
+		WorkerResets:                 m.WorkerResets(),
+		TargetedArgumentMutations:    m.TargetedArgumentMutations(),
+		ProductiveArgumentMutations:  m.ProductiveArgumentMutations(),
+		ShrinkCandidatesTested:       m.ShrinkCandidatesTested(),
+		CorpusDuplicateCallSequences: m.CorpusDuplicateCallSequences(),
+		FunctionCoverage:             m.FunctionCoverageSummaries(),
+	})
+}
@@ -0,0 +1,325 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/crytic/medusa/logging"
+)
+
+// metricsServerSampleInterval describes how often the metrics server samples the metrics of the fuzzing campaign.
+// Requests are served from the latest sample, so they never contend with workers.
+const metricsServerSampleInterval = time.Second
+
+// metricsServer serves live metrics of a fuzzing campaign over HTTP: Prometheus metrics at "/metrics" and a JSON
+// status at "/status".
+type metricsServer struct {
+	// fuzzer describes the Fuzzer whose campaign metrics are served.
+	fuzzer *Fuzzer
+
+	// listener describes the listener the server accepts connections on.
+	listener net.Listener
+
+	// server describes the HTTP server serving requests.
+	server *http.Server
+
+	// status describes the latest sample of the campaign's metrics.
+	status *fuzzerStatus
+
+	// statusLock provides thread-synchronization to avoid race conditions when accessing status.
+	statusLock sync.RWMutex
+
+	// stopSampling is closed to signal the sampling loop to exit.
+	stopSampling chan struct{}
+
+	// samplingStopped is closed once the sampling loop has exited.
+	samplingStopped chan struct{}
+}
+
+// fuzzerStatus describes a sample of the metrics of a fuzzing campaign.
+type fuzzerStatus struct {
+	// ElapsedSeconds describes the time elapsed since the campaign started, in seconds.
+	ElapsedSeconds float64 `json:"elapsedSeconds"`
+
+	// CallsTested describes the amount of calls the workers tested.
+	CallsTested uint64 `json:"callsTested"`
+
+	// CallsOutOfGas describes the amount of tested calls which failed because they ran out of gas.
+	CallsOutOfGas uint64 `json:"callsOutOfGas"`
+
+	// CallsPerSecond describes the rate at which calls were tested since the previous sample.
+	CallsPerSecond float64 `json:"callsPerSecond"`
+
+	// SequencesTested describes the amount of call sequences the workers tested.
+	SequencesTested uint64 `json:"sequencesTested"`
+
+	// SequencesPerSecond describes the rate at which call sequences were tested since the previous sample.
+	SequencesPerSecond float64 `json:"sequencesPerSecond"`
+
+	// CorpusSize describes the amount of active call sequences in the corpus.
+	CorpusSize int `json:"corpusSize"`
+
+	// CorpusDuplicateCallSequences describes the amount of call sequences which were not added to the corpus, as an
+	// equivalent call sequence was already in it.
+	CorpusDuplicateCallSequences uint64 `json:"corpusDuplicateCallSequences"`
+
+	// CorpusStateDuplicateCallSequences describes the amount of call sequences which were deduplicated against a call
+	// sequence in the corpus reaching the same state.
+	CorpusStateDuplicateCallSequences uint64 `json:"corpusStateDuplicateCallSequences"`
+
+	// CoveredInstructions describes the amount of instructions covered by the corpus.
+	CoveredInstructions int `json:"coveredInstructions"`
+
+	// CoveredEdges describes the amount of branch edges covered by the corpus. This is zero unless edge coverage is
+	// recorded.
+	CoveredEdges int `json:"coveredEdges"`
+
+	// FailedTestCases describes the amount of test cases which failed.
+	FailedTestCases int `json:"failedTestCases"`
+
+	// HeapBytes describes the amount of memory occupied by live and not yet freed heap objects.
+	HeapBytes uint64 `json:"heapBytes"`
+
+	// MemoryBytes describes the total amount of memory mapped by the Go runtime.
+	MemoryBytes uint64 `json:"memoryBytes"`
+
+	// WorkerResets describes the amount of times the worker at each index was reset, i.e. re-created after its first
+	// startup.
+	WorkerResets []uint64 `json:"workerResets"`
+
+	// WorkerResetsByCause describes the amount of times workers were reset, for each cause of a reset.
+	WorkerResetsByCause map[WorkerResetCause]uint64 `json:"workerResetsByCause"`
+
+	// MethodWeights describes the weight each method was last selected with when generating new calls, averaged
+	// across workers. This is empty if methods are selected uniformly.
+	MethodWeights []MethodWeight `json:"methodWeights"`
+
+	// MethodRevertRates describes how often calls to each method reverted, along with their most common revert
+	// reasons.
+	MethodRevertRates []MethodRevertRate `json:"methodRevertRates"`
+}
+
+// newMetricsServer creates a metricsServer for the provided Fuzzer and begins listening on the provided address. The
+// server does not serve requests until it is started.
+// Returns the metricsServer, or an error if one occurs.
+func newMetricsServer(fuzzer *Fuzzer, address string) (*metricsServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("could not start metrics server: %v", err)
+	}
+	s := &metricsServer{
+		fuzzer:          fuzzer,
+		listener:        listener,
+		status:          &fuzzerStatus{WorkerResets: []uint64{}, WorkerResetsByCause: map[WorkerResetCause]uint64{}, MethodWeights: []MethodWeight{}, MethodRevertRates: []MethodRevertRate{}},
+		stopSampling:    make(chan struct{}),
+		samplingStopped: make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/status", s.handleStatus)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return s, nil
+}
+
+// address returns the address the server is listening on.
+func (s *metricsServer) address() string {
+	return s.listener.Addr().String()
+}
+
+// start begins sampling the campaign's metrics and serving requests in the background.
+func (s *metricsServer) start() {
+	go s.sampleLoop()
+	go func() {
+		err := s.server.Serve(s.listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.GlobalLogger.Error().Err(err).Msgf("metrics server stopped: %v", err)
+		}
+	}()
+}
+
+// close stops sampling the campaign's metrics and shuts down the server, closing its listener and any open
+// connections.
+// Returns an error if one occurs.
+func (s *metricsServer) close() error {
+	select {
+	case <-s.stopSampling:
+	default:
+		close(s.stopSampling)
+	}
+	<-s.samplingStopped
+	return s.server.Close()
+}
+
+// sampleLoop samples the campaign's metrics every metricsServerSampleInterval until the server is closed.
+func (s *metricsServer) sampleLoop() {
+	defer close(s.samplingStopped)
+
+	startTime := time.Now()
+	lastSampleTime := startTime
+	lastCallsTested, lastSequencesTested := s.fuzzer.metrics.CallsTested().Uint64(), s.fuzzer.metrics.SequencesTested().Uint64()
+	ticker := time.NewTicker(metricsServerSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopSampling:
+			return
+		case <-ticker.C:
+		}
+
+		// Sample our metrics and calculate rates since our last sample.
+		status := s.fuzzer.sampleStatus()
+		now := time.Now()
+		status.ElapsedSeconds = now.Sub(startTime).Seconds()
+		if seconds := now.Sub(lastSampleTime).Seconds(); seconds > 0 {
+			status.CallsPerSecond = float64(status.CallsTested-lastCallsTested) / seconds
+			status.SequencesPerSecond = float64(status.SequencesTested-lastSequencesTested) / seconds
+		}
+		lastSampleTime, lastCallsTested, lastSequencesTested = now, status.CallsTested, status.SequencesTested
+
+		s.statusLock.Lock()
+		s.status = status
+		s.statusLock.Unlock()
+	}
+}
+
+// sampleStatus obtains the current metrics of the campaign, excluding those derived from previous samples.
+// Returns the sampled fuzzerStatus.
+func (f *Fuzzer) sampleStatus() *fuzzerStatus {
+	fuzzerMetrics := f.metrics
+	status := &fuzzerStatus{
+		CallsTested:                       fuzzerMetrics.CallsTested().Uint64(),
+		CallsOutOfGas:                     fuzzerMetrics.CallsOutOfGas().Uint64(),
+		SequencesTested:                   fuzzerMetrics.SequencesTested().Uint64(),
+		CorpusSize:                        f.corpus.ActiveCallSequenceCount(),
+		CorpusDuplicateCallSequences:      fuzzerMetrics.CorpusDuplicateCallSequences(),
+		CorpusStateDuplicateCallSequences: fuzzerMetrics.CorpusStateDuplicateCallSequences(),
+		FailedTestCases:                   len(f.TestCasesWithStatus(TestCaseStatusFailed)),
+		WorkerResets:                      make([]uint64, 0, len(fuzzerMetrics.workerMetrics)),
+		WorkerResetsByCause:               make(map[WorkerResetCause]uint64),
+		MethodWeights:                     fuzzerMetrics.MethodWeights(),
+		MethodRevertRates:                 f.MethodRevertRates(),
+	}
+	status.CoveredInstructions, status.CoveredEdges = f.corpus.CoverageMaps().CoveredCounts()
+	for _, startupCount := range fuzzerMetrics.workerStartupCounts() {
+		resets := uint64(0)
+		if startupCount > 0 {
+			resets = startupCount - 1
+		}
+		status.WorkerResets = append(status.WorkerResets, resets)
+	}
+	for cause, resets := range fuzzerMetrics.WorkerResets() {
+		status.WorkerResetsByCause[cause] = resets.Uint64()
+	}
+
+	// Read our memory usage from runtime metrics, which, unlike runtime.ReadMemStats, does not stop the world.
+	memorySamples := []metrics.Sample{
+		{Name: "/memory/classes/heap/objects:bytes"},
+		{Name: "/memory/classes/total:bytes"},
+	}
+	metrics.Read(memorySamples)
+	if memorySamples[0].Value.Kind() == metrics.KindUint64 {
+		status.HeapBytes = memorySamples[0].Value.Uint64()
+	}
+	if memorySamples[1].Value.Kind() == metrics.KindUint64 {
+		status.MemoryBytes = memorySamples[1].Value.Uint64()
+	}
+	return status
+}
+
+// latestStatus returns the latest sample of the campaign's metrics.
+func (s *metricsServer) latestStatus() *fuzzerStatus {
+	s.statusLock.RLock()
+	defer s.statusLock.RUnlock()
+	return s.status
+}
+
+// handleMetrics serves the latest sample of the campaign's metrics in the Prometheus text exposition format.
+func (s *metricsServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writePrometheusMetrics(w, s.latestStatus())
+}
+
+// handleStatus serves the latest sample of the campaign's metrics, along with the fuzzer's metrics summed across all
+// workers, as JSON.
+func (s *metricsServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	b, err := json.Marshal(struct {
+		*fuzzerStatus
+		Metrics *FuzzerMetrics `json:"metrics"`
+	}{s.latestStatus(), s.fuzzer.metrics})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// writePrometheusMetrics writes the provided fuzzerStatus to the provided writer in the Prometheus text exposition
+// format.
+func writePrometheusMetrics(w io.Writer, status *fuzzerStatus) {
+	writeMetric := func(name string, metricType string, help string, value any) {
+		fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n%v %v\n", name, help, name, metricType, name, value)
+	}
+	writeMetric("medusa_elapsed_seconds", "gauge", "Time elapsed since the fuzzing campaign started.", status.ElapsedSeconds)
+	writeMetric("medusa_calls_tested_total", "counter", "Calls tested by all workers.", status.CallsTested)
+	writeMetric("medusa_calls_out_of_gas_total", "counter", "Calls tested by all workers which ran out of gas.", status.CallsOutOfGas)
+	writeMetric("medusa_calls_per_second", "gauge", "Rate at which calls were tested in the last sample interval.", status.CallsPerSecond)
+	writeMetric("medusa_sequences_tested_total", "counter", "Call sequences tested by all workers.", status.SequencesTested)
+	writeMetric("medusa_sequences_per_second", "gauge", "Rate at which call sequences were tested in the last sample interval.", status.SequencesPerSecond)
+	writeMetric("medusa_corpus_size", "gauge", "Active call sequences in the corpus.", status.CorpusSize)
+	writeMetric("medusa_corpus_duplicate_call_sequences_total", "counter", "Call sequences not added to the corpus as an equivalent one was already in it.", status.CorpusDuplicateCallSequences)
+	writeMetric("medusa_corpus_state_duplicate_call_sequences_total", "counter", "Call sequences deduplicated against a corpus call sequence reaching the same state.", status.CorpusStateDuplicateCallSequences)
+	writeMetric("medusa_coverage_instructions", "gauge", "Instructions covered by the corpus.", status.CoveredInstructions)
+	writeMetric("medusa_coverage_edges", "gauge", "Branch edges covered by the corpus.", status.CoveredEdges)
+	writeMetric("medusa_failed_test_cases", "gauge", "Test cases which failed.", status.FailedTestCases)
+	writeMetric("medusa_memory_heap_bytes", "gauge", "Memory occupied by heap objects.", status.HeapBytes)
+	writeMetric("medusa_memory_total_bytes", "gauge", "Memory mapped by the Go runtime.", status.MemoryBytes)
+
+	// Worker resets are labeled by worker index.
+	var workerResets strings.Builder
+	for workerIndex, resets := range status.WorkerResets {
+		fmt.Fprintf(&workerResets, "medusa_worker_resets_total{worker=\"%d\"} %d\n", workerIndex, resets)
+	}
+	fmt.Fprintf(w, "# HELP medusa_worker_resets_total Times each worker was reset.\n# TYPE medusa_worker_resets_total counter\n%v", workerResets.String())
+
+	// Worker resets are also labeled by their cause, ordered by name so the output is stable.
+	causes := make([]string, 0, len(status.WorkerResetsByCause))
+	for cause := range status.WorkerResetsByCause {
+		causes = append(causes, string(cause))
+	}
+	sort.Strings(causes)
+	var workerResetsByCause strings.Builder
+	for _, cause := range causes {
+		fmt.Fprintf(&workerResetsByCause, "medusa_worker_resets_by_cause_total{cause=%q} %d\n", cause, status.WorkerResetsByCause[WorkerResetCause(cause)])
+	}
+	fmt.Fprintf(w, "# HELP medusa_worker_resets_by_cause_total Times workers were reset, by cause.\n# TYPE medusa_worker_resets_by_cause_total counter\n%v", workerResetsByCause.String())
+
+	// Method weights are labeled by contract and method, and omitted entirely if none were recorded.
+	if len(status.MethodWeights) > 0 {
+		var methodWeights strings.Builder
+		for _, methodWeight := range status.MethodWeights {
+			fmt.Fprintf(&methodWeights, "medusa_method_weight{contract=%q,method=%q} %v\n", methodWeight.Contract, methodWeight.Method, methodWeight.Weight)
+		}
+		fmt.Fprintf(w, "# HELP medusa_method_weight Weight each method was last selected with, averaged across workers.\n# TYPE medusa_method_weight gauge\n%v", methodWeights.String())
+	}
+
+	// Method calls and reverts are labeled by contract and method, and omitted entirely if no methods were called.
+	if len(status.MethodRevertRates) > 0 {
+		var methodCalls, methodReverts strings.Builder
+		for _, revertRate := range status.MethodRevertRates {
+			fmt.Fprintf(&methodCalls, "medusa_method_calls_total{contract=%q,method=%q} %d\n", revertRate.Contract, revertRate.Method, revertRate.Calls)
+			fmt.Fprintf(&methodReverts, "medusa_method_reverts_total{contract=%q,method=%q} %d\n", revertRate.Contract, revertRate.Method, revertRate.Reverts)
+		}
+		fmt.Fprintf(w, "# HELP medusa_method_calls_total Fuzzed calls to each method.\n# TYPE medusa_method_calls_total counter\n%v", methodCalls.String())
+		fmt.Fprintf(w, "# HELP medusa_method_reverts_total Fuzzed calls to each method which reverted or otherwise failed.\n# TYPE medusa_method_reverts_total counter\n%v", methodReverts.String())
+	}
+}
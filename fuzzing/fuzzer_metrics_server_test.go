@@ -0,0 +1,54 @@
+package fuzzing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetricsServerPrometheusFormat ensures sampled campaign metrics are written in the Prometheus text exposition
+// format, with worker resets labeled by worker index and by cause.
+func TestMetricsServerPrometheusFormat(t *testing.T) {
+	status := &fuzzerStatus{
+		CallsTested:     1200,
+		CallsOutOfGas:   30,
+		CallsPerSecond:  400,
+		SequencesTested: 12,
+		CorpusSize:      3,
+		CoveredEdges:    17,
+		FailedTestCases: 1,
+		WorkerResets:    []uint64{2, 0},
+		WorkerResetsByCause: map[WorkerResetCause]uint64{
+			WorkerResetCauseSequenceLimit: 1,
+			WorkerResetCauseMemoryLimit:   1,
+		},
+		MethodWeights:     []MethodWeight{{Contract: "Target", Method: "transfer(address,uint256)", Weight: 150}},
+		MethodRevertRates: []MethodRevertRate{{Contract: "Target", Method: "transfer(address,uint256)", Calls: 40, Reverts: 36}},
+	}
+	var b strings.Builder
+	writePrometheusMetrics(&b, status)
+	output := b.String()
+
+	assert.Contains(t, output, "# TYPE medusa_calls_tested_total counter\nmedusa_calls_tested_total 1200\n")
+	assert.Contains(t, output, "# TYPE medusa_calls_out_of_gas_total counter\nmedusa_calls_out_of_gas_total 30\n")
+	assert.Contains(t, output, "# TYPE medusa_calls_per_second gauge\nmedusa_calls_per_second 400\n")
+	assert.Contains(t, output, "\nmedusa_sequences_tested_total 12\n")
+	assert.Contains(t, output, "\nmedusa_corpus_size 3\n")
+	assert.Contains(t, output, "\nmedusa_coverage_edges 17\n")
+	assert.Contains(t, output, "\nmedusa_failed_test_cases 1\n")
+	assert.Contains(t, output, "medusa_worker_resets_total{worker=\"0\"} 2\nmedusa_worker_resets_total{worker=\"1\"} 0\n")
+	assert.Contains(t, output, "medusa_worker_resets_by_cause_total{cause=\"memoryLimit\"} 1\nmedusa_worker_resets_by_cause_total{cause=\"sequenceLimit\"} 1\n")
+	assert.Contains(t, output, "medusa_method_weight{contract=\"Target\",method=\"transfer(address,uint256)\"} 150\n")
+	assert.Contains(t, output, "medusa_method_calls_total{contract=\"Target\",method=\"transfer(address,uint256)\"} 40\n")
+	assert.Contains(t, output, "medusa_method_reverts_total{contract=\"Target\",method=\"transfer(address,uint256)\"} 36\n")
+
+	// Every metric should be preceded by its help and type.
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			name := strings.SplitN(strings.SplitN(line, " ", 2)[0], "{", 2)[0]
+			assert.Contains(t, output, "# TYPE "+name+" ")
+			assert.Contains(t, output, "# HELP "+name+" ")
+		}
+	}
+}
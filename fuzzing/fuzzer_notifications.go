@@ -0,0 +1,252 @@
+package fuzzing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/logging/colors"
+)
+
+// failureNotificationRetryDelay describes the delay before retrying to deliver a failure notification, which is
+// multiplied by the amount of attempts made so far.
+var failureNotificationRetryDelay = time.Second
+
+// defaultFailureNotificationTemplates describes the templates notification messages are rendered from for each
+// notification format, if no template is configured.
+var defaultFailureNotificationTemplates = map[config.NotificationFormat]string{
+	config.NotificationFormatJSON:  "[FAILED] {{.TestCase}} (target: {{.Campaign.Target}}, seed: {{.Campaign.Seed}})\n{{.Message}}",
+	config.NotificationFormatSlack: ":rotating_light: *{{.TestCase}}* failed (target: `{{.Campaign.Target}}`, seed: `{{.Campaign.Seed}}`)\n```{{.Message}}```",
+}
+
+// failureNotification describes the payload of a notification of a failed test case, as sent in the JSON
+// notification format, and as provided to notification templates.
+type failureNotification struct {
+	// Text describes the message rendered from the notification template.
+	Text string `json:"text"`
+
+	// TestCase describes the name of the failed test case.
+	TestCase string `json:"testCase"`
+
+	// TestCaseID describes the ID of the failed test case.
+	TestCaseID string `json:"testCaseId"`
+
+	// Message describes the result message of the failed test case, without colors.
+	Message string `json:"message"`
+
+	// CallSequence describes the shrunken call sequence the test case failed with, or nil if it did not fail due to
+	// a call sequence.
+	CallSequence calls.CallSequence `json:"callSequence"`
+
+	// Campaign describes the campaign the test case failed in.
+	Campaign failureNotificationCampaign `json:"campaign"`
+}
+
+// failureNotificationCampaign describes the campaign a test case described by a failureNotification failed in.
+type failureNotificationCampaign struct {
+	// Target describes the compilation target of the campaign.
+	Target string `json:"target"`
+
+	// Seed describes the random seed of the campaign, so the failure can be reproduced.
+	Seed int64 `json:"seed"`
+
+	// Workers describes the amount of workers the campaign fuzzes with.
+	Workers int `json:"workers"`
+
+	// ElapsedSeconds describes the time elapsed in the campaign when the test case failed, in seconds.
+	ElapsedSeconds float64 `json:"elapsedSeconds"`
+
+	// CallsTested describes the amount of calls tested in the campaign when the test case failed.
+	CallsTested uint64 `json:"callsTested"`
+
+	// SequencesTested describes the amount of call sequences tested in the campaign when the test case failed.
+	SequencesTested uint64 `json:"sequencesTested"`
+
+	// ConstructorArgs describes the constructor arguments the fuzzer generated for contracts deployed in the
+	// campaign, keyed by contract name and then argument name, or nil if none were generated.
+	ConstructorArgs map[string]map[string]any `json:"constructorArgs,omitempty"`
+}
+
+// failureNotifier notifies the webhooks configured for a Fuzzer each time one of its test cases fails. Notifications
+// are delivered in the background, so slow or unreachable webhooks never stall the campaign.
+type failureNotifier struct {
+	// fuzzer describes the Fuzzer whose failed test cases are notified.
+	fuzzer *Fuzzer
+
+	// config describes the notification configuration of the fuzzer.
+	config config.NotificationsConfig
+
+	// template describes the template notification messages are rendered from.
+	template *template.Template
+
+	// client describes the HTTP client notifications are delivered with, which bounds each attempt by the configured
+	// timeout.
+	client *http.Client
+
+	// campaignStartTime describes the time the current campaign started.
+	campaignStartTime time.Time
+
+	// pendingDeliveries tracks the notifications which are still being delivered, so the campaign can wait for them
+	// before it finishes.
+	pendingDeliveries sync.WaitGroup
+}
+
+// attachFailureNotifier creates a failureNotifier and subscribes it to the events of the provided Fuzzer.
+// Returns the notifier created.
+func attachFailureNotifier(fuzzer *Fuzzer) *failureNotifier {
+	// A malformed template is rejected by config validation, so we can safely ignore its error here.
+	notificationsConfig := fuzzer.config.Notifications
+	templateText := notificationsConfig.Template
+	if templateText == "" {
+		templateText = defaultFailureNotificationTemplates[config.NotificationFormatJSON]
+		if notificationsConfig.Format == config.NotificationFormatSlack {
+			templateText = defaultFailureNotificationTemplates[config.NotificationFormatSlack]
+		}
+	}
+	notificationTemplate, _ := template.New("notification").Parse(templateText)
+
+	n := &failureNotifier{
+		fuzzer:   fuzzer,
+		config:   notificationsConfig,
+		template: notificationTemplate,
+		client:   &http.Client{Timeout: time.Duration(notificationsConfig.Timeout) * time.Second},
+	}
+	fuzzer.Events.FuzzerStarting.Subscribe(n.onFuzzerStarting)
+	fuzzer.Events.TestCaseFailed.Subscribe(n.onTestCaseFailed)
+	fuzzer.Events.CampaignFinished.Subscribe(n.onCampaignFinished)
+	return n
+}
+
+// onFuzzerStarting is the event handler triggered when the Fuzzer is starting a campaign. It records the time the
+// campaign started.
+func (n *failureNotifier) onFuzzerStarting(event FuzzerStartingEvent) error {
+	n.campaignStartTime = time.Now()
+	return nil
+}
+
+// onTestCaseFailed is the event handler triggered when a test case of the Fuzzer failed. It renders a notification
+// of the failure, then delivers it to every webhook in the background.
+func (n *failureNotifier) onTestCaseFailed(event FuzzerTestCaseFailedEvent) error {
+	// Render our notification now, as the test case may change while it is delivered.
+	body, err := n.notificationBody(event)
+	if err != nil {
+		logging.GlobalLogger.Warn().Str("testCase", event.TestCase.Name()).Err(err).Msgf("Failed to create a notification of failed test %s: %v", event.TestCase.Name(), err)
+		return nil
+	}
+
+	// Deliver our notification to each webhook.
+	for _, webhookURL := range n.config.WebhookURLs {
+		n.pendingDeliveries.Add(1)
+		go func(webhookURL string) {
+			defer n.pendingDeliveries.Done()
+			err := n.deliver(webhookURL, body)
+			if err != nil {
+				logging.GlobalLogger.Warn().Str("testCase", event.TestCase.Name()).Str("webhook", webhookHost(webhookURL)).Err(err).
+					Msgf("Failed to notify webhook at %s of failed test %s: %v", webhookHost(webhookURL), event.TestCase.Name(), err)
+			}
+		}(webhookURL)
+	}
+	return nil
+}
+
+// onCampaignFinished is the event handler triggered when a campaign of the Fuzzer finished. It waits for pending
+// notifications to be delivered, which is bounded by the timeout and retries of each delivery.
+func (n *failureNotifier) onCampaignFinished(event FuzzerCampaignFinishedEvent) error {
+	n.pendingDeliveries.Wait()
+	return nil
+}
+
+// notificationBody creates the body of a notification of the failed test case described by the provided event, in
+// the configured notification format.
+// Returns the notification body, or an error if it could not be rendered.
+func (n *failureNotifier) notificationBody(event FuzzerTestCaseFailedEvent) ([]byte, error) {
+	// Describe our test case and campaign.
+	f := n.fuzzer
+	notification := failureNotification{
+		TestCase:   strings.TrimSpace(event.TestCase.Name()),
+		TestCaseID: event.TestCase.ID(),
+		Message:    strings.TrimSpace(colors.Strip(f.testCaseResultMessage(event.TestCase))),
+		Campaign: failureNotificationCampaign{
+			Seed:            f.seed,
+			Workers:         f.config.Fuzzing.Workers,
+			ElapsedSeconds:  time.Since(n.campaignStartTime).Seconds(),
+			ConstructorArgs: f.fuzzedConstructorArgs,
+		},
+	}
+	if event.CallSequence != nil {
+		notification.CallSequence = *event.CallSequence
+	}
+	if f.config.Compilation != nil {
+		if platformConfig, err := f.config.Compilation.GetPlatformConfig(); err == nil {
+			notification.Campaign.Target = platformConfig.GetTarget()
+		}
+	}
+	if f.metrics != nil {
+		notification.Campaign.CallsTested = f.metrics.CallsTested().Uint64()
+		notification.Campaign.SequencesTested = f.metrics.SequencesTested().Uint64()
+	}
+
+	// Render our message, then encode our notification in our format.
+	var text strings.Builder
+	err := n.template.Execute(&text, notification)
+	if err != nil {
+		return nil, err
+	}
+	notification.Text = text.String()
+	if n.config.Format == config.NotificationFormatSlack {
+		return json.Marshal(map[string]string{"text": notification.Text})
+	}
+	return json.Marshal(notification)
+}
+
+// deliver POSTs the provided notification body to the provided webhook URL, retrying failed attempts up to the
+// configured amount of retries.
+// Returns an error describing the last failed attempt, if every attempt failed.
+func (n *failureNotifier) deliver(webhookURL string, body []byte) error {
+	var err error
+	for attempt := uint64(0); attempt <= n.config.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * failureNotificationRetryDelay)
+		}
+		err = n.post(webhookURL, body)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// post makes a single attempt to POST the provided notification body to the provided webhook URL.
+// Returns an error if the request failed, or the webhook did not respond with a successful status.
+func (n *failureNotifier) post(webhookURL string, body []byte) error {
+	response, err := n.client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %v", response.Status)
+	}
+	return nil
+}
+
+// webhookHost obtains the host of the provided webhook URL, which is logged rather than the full URL, as webhook URLs
+// often embed secrets.
+func webhookHost(webhookURL string) string {
+	parsedURL, err := url.Parse(webhookURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	return parsedURL.Host
+}
@@ -0,0 +1,132 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// testNotificationWebhook is an HTTP server which records the notifications POSTed to it, responding with the
+// provided status codes in order, then with 200 OK.
+type testNotificationWebhook struct {
+	server   *httptest.Server
+	lock     sync.Mutex
+	statuses []int
+	bodies   [][]byte
+}
+
+// newTestNotificationWebhook creates and starts a testNotificationWebhook, which is closed when the test finishes.
+func newTestNotificationWebhook(t *testing.T, statuses ...int) *testNotificationWebhook {
+	webhook := &testNotificationWebhook{statuses: statuses}
+	webhook.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		webhook.lock.Lock()
+		defer webhook.lock.Unlock()
+		webhook.bodies = append(webhook.bodies, body)
+		if len(webhook.statuses) > 0 {
+			w.WriteHeader(webhook.statuses[0])
+			webhook.statuses = webhook.statuses[1:]
+		}
+	}))
+	t.Cleanup(webhook.server.Close)
+	return webhook
+}
+
+// testNotifyFailure creates a fuzzer with the provided notification config and reports a failed test case to its
+// failure notifier, then waits for its notifications to be delivered, as they are when the campaign finishes.
+func testNotifyFailure(t *testing.T, notificationsConfig config.NotificationsConfig) {
+	f := &Fuzzer{
+		config: config.ProjectConfig{Fuzzing: config.FuzzingConfig{Workers: 4}, Notifications: notificationsConfig},
+		seed:   1337,
+	}
+	attachFailureNotifier(f)
+	assert.NoError(t, f.Events.FuzzerStarting.Publish(FuzzerStartingEvent{Fuzzer: f}))
+	testCase := &testResultStubTestCase{name: "Property Test: TestContract.fuzz_fails()", status: TestCaseStatusFailed}
+	assert.NoError(t, f.Events.TestCaseFailed.Publish(FuzzerTestCaseFailedEvent{Fuzzer: f, TestCase: testCase}))
+	assert.NoError(t, f.Events.CampaignFinished.Publish(FuzzerCampaignFinishedEvent{Fuzzer: f}))
+}
+
+// TestFailureNotificationJSON ensures failed test cases are notified to every webhook with a JSON payload describing
+// the test case and campaign, and a message rendered from the configured template.
+func TestFailureNotificationJSON(t *testing.T) {
+	webhooks := []*testNotificationWebhook{newTestNotificationWebhook(t), newTestNotificationWebhook(t)}
+	testNotifyFailure(t, config.NotificationsConfig{
+		WebhookURLs: []string{webhooks[0].server.URL, webhooks[1].server.URL},
+		Format:      config.NotificationFormatJSON,
+		Template:    "{{.TestCase}} failed with seed {{.Campaign.Seed}}",
+		Timeout:     10,
+	})
+
+	for _, webhook := range webhooks {
+		assert.Len(t, webhook.bodies, 1)
+		var notification failureNotification
+		assert.NoError(t, json.Unmarshal(webhook.bodies[0], &notification))
+		assert.EqualValues(t, "Property Test: TestContract.fuzz_fails() failed with seed 1337", notification.Text)
+		assert.EqualValues(t, "Property Test: TestContract.fuzz_fails()", notification.TestCase)
+		assert.Contains(t, notification.Message, "[Seed] 1337")
+		assert.EqualValues(t, 1337, notification.Campaign.Seed)
+		assert.EqualValues(t, 4, notification.Campaign.Workers)
+	}
+}
+
+// TestFailureNotificationSlack ensures failed test cases are notified with a Slack-compatible payload when the Slack
+// notification format is selected.
+func TestFailureNotificationSlack(t *testing.T) {
+	webhook := newTestNotificationWebhook(t)
+	testNotifyFailure(t, config.NotificationsConfig{
+		WebhookURLs: []string{webhook.server.URL},
+		Format:      config.NotificationFormatSlack,
+		Timeout:     10,
+	})
+
+	assert.Len(t, webhook.bodies, 1)
+	var payload map[string]any
+	assert.NoError(t, json.Unmarshal(webhook.bodies[0], &payload))
+	assert.Len(t, payload, 1)
+	assert.Contains(t, payload["text"], "*Property Test: TestContract.fuzz_fails()* failed")
+}
+
+// TestFailureNotificationRetries ensures notifications which a webhook fails to accept are retried up to the
+// configured amount of retries.
+func TestFailureNotificationRetries(t *testing.T) {
+	defer func(delay time.Duration) { failureNotificationRetryDelay = delay }(failureNotificationRetryDelay)
+	failureNotificationRetryDelay = time.Millisecond
+
+	// Our notification should be delivered on its third attempt.
+	webhook := newTestNotificationWebhook(t, http.StatusInternalServerError, http.StatusBadGateway)
+	testNotifyFailure(t, config.NotificationsConfig{WebhookURLs: []string{webhook.server.URL}, Timeout: 10, Retries: 3})
+	assert.Len(t, webhook.bodies, 3)
+
+	// Our notification should be given up on once it runs out of retries.
+	webhook = newTestNotificationWebhook(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	testNotifyFailure(t, config.NotificationsConfig{WebhookURLs: []string{webhook.server.URL}, Timeout: 10, Retries: 1})
+	assert.Len(t, webhook.bodies, 2)
+}
+
+// TestFailureNotificationTimeout ensures a webhook which never responds neither blocks the worker reporting a failed
+// test case, nor stalls the campaign beyond the configured timeout.
+func TestFailureNotificationTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	f := &Fuzzer{config: config.ProjectConfig{Notifications: config.NotificationsConfig{WebhookURLs: []string{server.URL}, Timeout: 1}}}
+	attachFailureNotifier(f)
+	testCase := &testResultStubTestCase{name: "Property Test: TestContract.fuzz_fails()", status: TestCaseStatusFailed}
+
+	start := time.Now()
+	assert.NoError(t, f.Events.TestCaseFailed.Publish(FuzzerTestCaseFailedEvent{Fuzzer: f, TestCase: testCase}))
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.NoError(t, f.Events.CampaignFinished.Publish(FuzzerCampaignFinishedEvent{Fuzzer: f}))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
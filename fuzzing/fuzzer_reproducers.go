@@ -0,0 +1,112 @@
+package fuzzing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/reproducers"
+	"github.com/crytic/medusa/utils"
+)
+
+// reproducerFileNameCharacters matches characters which should not be used in reproducer file names.
+var reproducerFileNameCharacters = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// recordReproducerDeployments records the contract deployments performed on the provided base test chain, so Foundry
+// reproducers can deploy the same contracts at the same addresses.
+func (f *Fuzzer) recordReproducerDeployments(baseTestChain *chain.TestChain) {
+	f.reproducerDeployments = make([]reproducers.FoundryDeployment, 0)
+	for _, block := range baseTestChain.CommittedBlocks() {
+		for i, message := range block.Messages {
+			// We only record messages which deploy a contract.
+			messageResults := block.MessageResults[i]
+			if message.To() != nil || messageResults.Receipt == nil {
+				continue
+			}
+			deployment := reproducers.FoundryDeployment{
+				Deployer: message.From(),
+				Nonce:    message.Nonce(),
+				Address:  messageResults.Receipt.ContractAddress,
+				Data:     message.Data(),
+			}
+
+			// Match the deployed contract to a definition, so reproducers can deploy it by name.
+			for _, deploymentChange := range messageResults.ContractDeploymentChanges {
+				if deploymentChange.Creation && deploymentChange.Contract.Address == deployment.Address {
+					deployment.Contract = f.contractDefinitions.MatchBytecode(deploymentChange.Contract.InitBytecode, deploymentChange.Contract.RuntimeBytecode)
+					break
+				}
+			}
+			f.reproducerDeployments = append(f.reproducerDeployments, deployment)
+		}
+	}
+}
+
+// ReproducersDirectory obtains the directory Foundry reproducers for failed tests are written to.
+func (f *Fuzzer) ReproducersDirectory() string {
+	return filepath.Join(f.config.Fuzzing.CorpusDirectory, "reproducers")
+}
+
+// writeFoundryReproducer writes a Foundry test reproducing the failure of the provided TestCase to the reproducers
+// directory. Test cases which are not property or assertion tests are ignored.
+// Returns the path of the written reproducer, or an empty string if none was written, or an error if one occurs.
+func (f *Fuzzer) writeFoundryReproducer(testCase TestCase) (string, error) {
+	// Create a reproducer describing how the test case failed.
+	var reproducer *reproducers.FoundryReproducer
+	switch t := testCase.(type) {
+	case *PropertyTestCase:
+		reproducer = &reproducers.FoundryReproducer{
+			Name: fmt.Sprintf("%s.%s", t.targetContract.Name(), t.targetMethod.Name),
+			PropertyTest: &contracts.DeployedContractMethod{
+				Address:  t.targetAddress,
+				Contract: t.targetContract,
+				Method:   t.targetMethod,
+			},
+			PropertyTestSender: f.propertyTestSender(),
+		}
+	case *AssertionTestCase:
+		// Assertion failures are reproduced by the panic the last call reverted with. Those raised by inner calls and
+		// caught, or calls which ran out of gas, did not revert with a panic, so they cannot be asserted this way.
+		reproducer = &reproducers.FoundryReproducer{
+			Name:                      fmt.Sprintf("%s.%s", t.targetContract.Name(), t.targetMethod.Name),
+			ExpectAssertionFailure:    !t.expectRevert && len(t.expectedEmitFailures) == 0 && t.failurePanicCode != nil,
+			AssertionFailurePanicCode: t.failurePanicCode,
+			ExpectLastCallSuccess:     t.expectRevert && t.expectedRevertError == nil,
+		}
+		if t.expectRevert {
+			reproducer.UnexpectedRevertError = t.expectedRevertError
+		}
+	default:
+		return "", nil
+	}
+	if testCase.CallSequence() == nil {
+		return "", nil
+	}
+	reproducer.Deployments = f.reproducerDeployments
+	reproducer.CallSequence = *testCase.CallSequence()
+
+	// Generate our reproducer and write it to our reproducers directory.
+	source, err := reproducer.Generate()
+	if err != nil {
+		return "", err
+	}
+	if f.config.Fuzzing.CorpusDirectory != "" {
+		err = utils.MakeDirectory(f.config.Fuzzing.CorpusDirectory)
+		if err != nil {
+			return "", err
+		}
+	}
+	err = utils.MakeDirectory(f.ReproducersDirectory())
+	if err != nil {
+		return "", err
+	}
+	filePath := filepath.Join(f.ReproducersDirectory(), reproducerFileNameCharacters.ReplaceAllString(testCase.ID(), "_")+".t.sol")
+	err = os.WriteFile(filePath, []byte(source), 0644)
+	if err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
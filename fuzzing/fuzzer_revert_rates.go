@@ -0,0 +1,183 @@
+package fuzzing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/logging"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// methodRevertReasonsReported describes the maximum amount of revert reasons reported for each method. Less common
+// reasons are reported together.
+const methodRevertReasonsReported = 5
+
+// highRevertRateMinimumCalls describes the minimum amount of calls to a method before a high revert rate is warned
+// about, so methods which were barely called are not.
+const highRevertRateMinimumCalls = 100
+
+// highRevertRateWarningInterval describes how often methods with a high revert rate are warned about while a
+// campaign runs.
+const highRevertRateWarningInterval = time.Minute
+
+// MethodRevertRate describes how often fuzzed calls to a method reverted, and why.
+type MethodRevertRate struct {
+	// Contract describes the name of the contract the method belongs to.
+	Contract string `json:"contract"`
+
+	// Method describes the signature of the method.
+	Method string `json:"method"`
+
+	// Calls describes the amount of fuzzed calls to the method.
+	Calls uint64 `json:"calls"`
+
+	// Reverts describes the amount of fuzzed calls to the method which reverted or otherwise failed.
+	Reverts uint64 `json:"reverts"`
+
+	// RevertReasons describes the most common reasons calls to the method reverted, most common first.
+	RevertReasons []RevertReasonCount `json:"revertReasons"`
+}
+
+// RevertReasonCount describes how many calls to a method reverted for a reason.
+type RevertReasonCount struct {
+	// Reason describes the reason, decoded from the revert data where possible (e.g. "revert ('insufficient
+	// balance')"), or "other reasons" for the reverts of less common reasons.
+	Reason string `json:"reason"`
+
+	// Count describes the amount of reverted calls.
+	Count uint64 `json:"count"`
+}
+
+// RevertRate returns the fraction of fuzzed calls to the method which reverted, or zero if it was never called.
+func (r MethodRevertRate) RevertRate() float64 {
+	if r.Calls == 0 {
+		return 0
+	}
+	return float64(r.Reverts) / float64(r.Calls)
+}
+
+// MethodRevertRates summarizes how often fuzzed calls to each called method reverted, along with their most common
+// revert reasons, decoded where possible. The reasons recorded by each worker are merged when this is called, so
+// workers do not synchronize with each other to record them. Revert rates are sorted by highest revert rate first.
+// Returns the method revert rates, or nil if no campaign has been started.
+func (f *Fuzzer) MethodRevertRates() []MethodRevertRate {
+	// If we have not started a campaign, we have nothing to summarize.
+	if f.metrics == nil {
+		return nil
+	}
+
+	// Resolve the contracts methods belong to, so their custom errors can be decoded.
+	contractsByName := make(map[string]*fuzzerTypes.Contract)
+	for _, contract := range f.contractDefinitions {
+		contractsByName[contract.Name()] = contract
+	}
+	customErrors := f.contractDefinitions.CustomErrors()
+
+	revertRates := make([]MethodRevertRate, 0)
+	for key, metrics := range f.metrics.methodCallMetrics() {
+		// Decode our revert reasons. Distinct revert data may decode to the same reason, so we merge them.
+		reasonCounts := make(map[string]uint64)
+		for returnData, count := range metrics.revertReasons {
+			executionResult := &core.ExecutionResult{Err: vm.ErrExecutionReverted, ReturnData: []byte(returnData)}
+			reasonCounts[describeExecutionResult(contractsByName[key.contractName], customErrors, executionResult)] += *count
+		}
+		for vmError, count := range metrics.errorReasons {
+			reasonCounts[fmt.Sprintf("vm error ('%v')", vmError)] += *count
+		}
+
+		// Report our most common reasons, followed by any others.
+		reasons := make([]RevertReasonCount, 0, len(reasonCounts))
+		for reason, count := range reasonCounts {
+			reasons = append(reasons, RevertReasonCount{Reason: reason, Count: count})
+		}
+		sort.Slice(reasons, func(i, j int) bool {
+			if reasons[i].Count != reasons[j].Count {
+				return reasons[i].Count > reasons[j].Count
+			}
+			return reasons[i].Reason < reasons[j].Reason
+		})
+		otherReverts := metrics.untrackedReverts
+		if len(reasons) > methodRevertReasonsReported {
+			for _, reason := range reasons[methodRevertReasonsReported:] {
+				otherReverts += reason.Count
+			}
+			reasons = reasons[:methodRevertReasonsReported]
+		}
+		if otherReverts > 0 {
+			reasons = append(reasons, RevertReasonCount{Reason: "other reasons", Count: otherReverts})
+		}
+
+		revertRates = append(revertRates, MethodRevertRate{
+			Contract:      key.contractName,
+			Method:        key.methodSignature,
+			Calls:         metrics.calls,
+			Reverts:       metrics.reverts,
+			RevertReasons: reasons,
+		})
+	}
+
+	// Sort our revert rates by highest revert rate first, then by name.
+	sort.Slice(revertRates, func(i, j int) bool {
+		if revertRates[i].RevertRate() != revertRates[j].RevertRate() {
+			return revertRates[i].RevertRate() > revertRates[j].RevertRate()
+		}
+		if revertRates[i].Contract != revertRates[j].Contract {
+			return revertRates[i].Contract < revertRates[j].Contract
+		}
+		return revertRates[i].Method < revertRates[j].Method
+	})
+	return revertRates
+}
+
+// highRevertRates returns the revert rates of the methods whose revert rate meets the configured revert rate warning
+// threshold, among those called at least highRevertRateMinimumCalls times.
+func (f *Fuzzer) highRevertRates() []MethodRevertRate {
+	threshold := f.config.Metrics.RevertRateWarningThreshold
+	if threshold <= 0 {
+		return nil
+	}
+	highRevertRates := make([]MethodRevertRate, 0)
+	for _, revertRate := range f.MethodRevertRates() {
+		if revertRate.Calls >= highRevertRateMinimumCalls && revertRate.RevertRate() >= threshold {
+			highRevertRates = append(highRevertRates, revertRate)
+		}
+	}
+	return highRevertRates
+}
+
+// printHighRevertRateWarnings logs a warning with a table of the methods whose revert rate meets the configured
+// revert rate warning threshold, along with their most common revert reasons, as this often indicates a broken
+// harness. Nothing is logged if there are no such methods.
+func (f *Fuzzer) printHighRevertRateWarnings() {
+	highRevertRates := f.highRevertRates()
+	if len(highRevertRates) == 0 {
+		return
+	}
+
+	// In the JSON log format, the revert rates are logged as structured data rather than a table.
+	if logging.GlobalLogger.Format() == logging.LogFormatJSON {
+		logging.GlobalLogger.Warn().Interface("highRevertRates", highRevertRates).Msg("Methods with a high revert rate")
+		return
+	}
+
+	var table strings.Builder
+	writer := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CONTRACT\tMETHOD\tCALLS\tREVERT RATE\tTOP REVERT REASONS\n")
+	for _, revertRate := range highRevertRates {
+		reasons := make([]string, 0, len(revertRate.RevertReasons))
+		for _, reason := range revertRate.RevertReasons {
+			reasons = append(reasons, fmt.Sprintf("%v (%.1f%%)", reason.Reason, float64(reason.Count)/float64(revertRate.Reverts)*100))
+		}
+		fmt.Fprintf(writer, "%v\t%v\t%d\t%.1f%%\t%v\n", revertRate.Contract, revertRate.Method, revertRate.Calls, revertRate.RevertRate()*100, strings.Join(reasons, "; "))
+	}
+	_ = writer.Flush()
+	logging.GlobalLogger.Warn().Msgf(
+		"Methods with a revert rate of at least %.0f%%, which may indicate a broken harness:\n%s",
+		f.config.Metrics.RevertRateWarningThreshold*100, strings.TrimRight(table.String(), "\n"),
+	)
+}
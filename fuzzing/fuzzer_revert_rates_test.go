@@ -0,0 +1,111 @@
+package fuzzing
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+// revertReasonTestData encodes the return data of a revert with the provided error string, or a panic with the
+// provided panic code if the error string is empty.
+func revertReasonTestData(t *testing.T, errorString string, panicCode int64) []byte {
+	if errorString == "" {
+		uintType, _ := abi.NewType("uint256", "", nil)
+		data, err := abi.Arguments{{Type: uintType}}.Pack(big.NewInt(panicCode))
+		assert.NoError(t, err)
+		return append(hexutil.MustDecode("0x4e487b71"), data...)
+	}
+	stringType, _ := abi.NewType("string", "", nil)
+	data, err := abi.Arguments{{Type: stringType}}.Pack(errorString)
+	assert.NoError(t, err)
+	return append(hexutil.MustDecode("0x08c379a0"), data...)
+}
+
+// TestMethodRevertRates ensures the revert reasons recorded by each worker are merged and decoded, with the most
+// common reasons reported first, and reasons beyond the reported amount reported together.
+func TestMethodRevertRates(t *testing.T) {
+	f := &Fuzzer{metrics: newFuzzerMetrics(2, nil, nil)}
+	record := func(workerIndex int, contractName string, methodSignature string, calls uint64, executionResult *core.ExecutionResult) {
+		tracker := f.metrics.workerMetrics[workerIndex].methodCalls
+		key := methodCallMetricsKey{contractName: contractName, methodSignature: methodSignature}
+		metrics, ok := tracker.metrics[key]
+		if !ok {
+			metrics = &methodCallMetrics{}
+			tracker.metrics[key] = metrics
+		}
+		for i := uint64(0); i < calls; i++ {
+			metrics.calls++
+			if executionResult != nil {
+				metrics.reverts++
+				metrics.recordRevertReason(executionResult)
+			}
+		}
+	}
+
+	// Record reverts of a method across both workers, and a method which reverts less often.
+	insufficientBalance := &core.ExecutionResult{Err: vm.ErrExecutionReverted, ReturnData: revertReasonTestData(t, "insufficient balance", 0)}
+	record(0, "Target", "transfer(uint256)", 60, insufficientBalance)
+	record(1, "Target", "transfer(uint256)", 30, insufficientBalance)
+	record(1, "Target", "transfer(uint256)", 5, &core.ExecutionResult{Err: vm.ErrExecutionReverted, ReturnData: revertReasonTestData(t, "", 0x11)})
+	record(1, "Target", "transfer(uint256)", 3, &core.ExecutionResult{Err: vm.ErrOutOfGas})
+	record(1, "Target", "transfer(uint256)", 2, nil)
+	record(0, "Target", "deposit()", 10, &core.ExecutionResult{Err: vm.ErrExecutionReverted})
+	record(0, "Target", "deposit()", 90, nil)
+
+	revertRates := f.MethodRevertRates()
+	assert.Len(t, revertRates, 2)
+	assert.EqualValues(t, "transfer(uint256)", revertRates[0].Method)
+	assert.EqualValues(t, 100, revertRates[0].Calls)
+	assert.EqualValues(t, 98, revertRates[0].Reverts)
+	assert.EqualValues(t, []RevertReasonCount{
+		{Reason: "revert ('insufficient balance')", Count: 90},
+		{Reason: "panic: arithmetic underflow or overflow (code: 0x11)", Count: 5},
+		{Reason: "vm error ('out of gas')", Count: 3},
+	}, revertRates[0].RevertReasons)
+	assert.EqualValues(t, "deposit()", revertRates[1].Method)
+	assert.InDelta(t, 0.1, revertRates[1].RevertRate(), 0.0001)
+	assert.EqualValues(t, []RevertReasonCount{{Reason: "revert", Count: 10}}, revertRates[1].RevertReasons)
+
+	// Only the method meeting the warning threshold should be warned about.
+	f.config.Metrics.RevertRateWarningThreshold = 0.9
+	highRevertRates := f.highRevertRates()
+	assert.Len(t, highRevertRates, 1)
+	assert.EqualValues(t, "transfer(uint256)", highRevertRates[0].Method)
+	f.config.Metrics.RevertRateWarningThreshold = 0
+	assert.Empty(t, f.highRevertRates())
+}
+
+// TestMethodRevertReasonsLimit ensures the amount of distinct revert reasons recorded for a method is bounded, with
+// reverts of further reasons counted without their reason, and only the most common reasons reported.
+func TestMethodRevertReasonsLimit(t *testing.T) {
+	f := &Fuzzer{metrics: newFuzzerMetrics(1, nil, nil)}
+	metrics := &methodCallMetrics{}
+	f.metrics.workerMetrics[0].methodCalls.metrics[methodCallMetricsKey{contractName: "Target", methodSignature: "f()"}] = metrics
+	for i := 0; i < revertReasonsLimit+4; i++ {
+		executionResult := &core.ExecutionResult{Err: vm.ErrExecutionReverted, ReturnData: revertReasonTestData(t, fmt.Sprintf("reason %d", i), 0)}
+		for j := 0; j <= i; j++ {
+			metrics.calls++
+			metrics.reverts++
+			metrics.recordRevertReason(executionResult)
+		}
+	}
+	assert.Len(t, metrics.revertReasons, revertReasonsLimit)
+	assert.EqualValues(t, 17+18+19+20, metrics.untrackedReverts)
+
+	// The most common reasons recorded are reported, followed by all others.
+	reasons := f.MethodRevertRates()[0].RevertReasons
+	assert.Len(t, reasons, methodRevertReasonsReported+1)
+	assert.EqualValues(t, RevertReasonCount{Reason: "revert ('reason 15')", Count: 16}, reasons[0])
+	assert.EqualValues(t, "other reasons", reasons[methodRevertReasonsReported].Reason)
+	total := uint64(0)
+	for _, reason := range reasons {
+		total += reason.Count
+	}
+	assert.EqualValues(t, metrics.reverts, total)
+}
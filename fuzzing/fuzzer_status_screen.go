@@ -0,0 +1,560 @@
+package fuzzing
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/logging/colors"
+)
+
+// statusScreenRefreshInterval describes how often the status screen samples the metrics of the fuzzing campaign and
+// redraws itself. Metrics are pulled on this timer, so workers never report to the screen directly.
+const statusScreenRefreshInterval = 500 * time.Millisecond
+
+// statusScreenCoverageHistoryLength describes the amount of coverage samples the status screen's sparkline shows.
+const statusScreenCoverageHistoryLength = 60
+
+// statusScreenLogHistoryLength describes the amount of log lines the status screen retains. These are written to
+// standard output once the screen is closed, so they are not lost.
+const statusScreenLogHistoryLength = 1000
+
+// sparklineCharacters describes the characters used to draw sparklines, from the lowest value to the highest.
+var sparklineCharacters = []rune("▁▂▃▄▅▆▇█")
+
+// statusScreen shows an interactive status screen for the fuzzing campaign of a Fuzzer on a terminal, in place of
+// scrolling log output. Logs are instead shown in a pane at the bottom of the screen.
+type statusScreen struct {
+	// fuzzer describes the Fuzzer whose campaign is shown.
+	fuzzer *Fuzzer
+
+	// output describes the terminal the screen is drawn on.
+	output *os.File
+
+	// input describes the terminal keys are read from, or nil if keybindings are not supported.
+	input *os.File
+
+	// restoreInput restores the mode of the input terminal, once keys are no longer read from it.
+	restoreInput func()
+
+	// logs describes the log lines written while the screen is shown.
+	logs *statusScreenLogs
+
+	// previousLogger describes the logger which was replaced so logs are shown in the screen's log pane, and which is
+	// restored once the screen is closed.
+	previousLogger *logging.Logger
+
+	// startTime describes the time the screen was started at.
+	startTime time.Time
+
+	// coverageHistory describes the amount of covered instructions in recent samples, for the coverage sparkline.
+	coverageHistory []int
+
+	// lastSampleTime describes the time of the previous sample, from which rates are derived.
+	lastSampleTime time.Time
+
+	// lastWorkerCallsTested describes the amount of calls each worker had tested in the previous sample.
+	lastWorkerCallsTested []uint64
+
+	// message describes a message shown to acknowledge the last key pressed, e.g. that reports were written.
+	message string
+
+	// lock provides thread-synchronization between drawing the screen and handling keys.
+	lock sync.Mutex
+
+	// stop is closed to signal the screen to stop drawing itself.
+	stop chan struct{}
+
+	// stopped is closed once the screen stopped drawing itself.
+	stopped chan struct{}
+
+	// closeOnce ensures the screen is only closed once.
+	closeOnce sync.Once
+}
+
+// statusScreenSnapshot describes the state of a fuzzing campaign shown on a statusScreen, at the time it was sampled.
+type statusScreenSnapshot struct {
+	// status describes the sampled metrics of the campaign.
+	status *fuzzerStatus
+
+	// elapsed describes the time elapsed in the campaign.
+	elapsed time.Duration
+
+	// averageCallsPerSecond describes the rate at which calls were tested since the campaign started.
+	averageCallsPerSecond float64
+
+	// coverageHistory describes the amount of covered instructions in recent samples.
+	coverageHistory []int
+
+	// tests describes the test cases of the campaign.
+	tests []statusScreenTest
+
+	// workers describes the activity of each worker.
+	workers []statusScreenWorker
+
+	// paused indicates whether workers are paused from testing new call sequences.
+	paused bool
+
+	// keysEnabled indicates whether keybindings are supported.
+	keysEnabled bool
+
+	// message describes a message acknowledging the last key pressed.
+	message string
+
+	// logs describes the most recent log lines.
+	logs []string
+}
+
+// statusScreenTest describes a test case shown on a statusScreen.
+type statusScreenTest struct {
+	// name describes the name of the test case.
+	name string
+
+	// status describes the status of the test case.
+	status TestCaseStatus
+
+	// best describes the best value found by an optimization test, or an empty string for other test cases.
+	best string
+}
+
+// statusScreenWorker describes the activity of a worker shown on a statusScreen.
+type statusScreenWorker struct {
+	// callsPerSecond describes the rate at which the worker tested calls since the previous sample.
+	callsPerSecond float64
+
+	// sequencesTested describes the amount of call sequences the worker tested.
+	sequencesTested uint64
+
+	// resets describes the amount of times the worker was reset.
+	resets uint64
+}
+
+// newStatusScreen creates a statusScreen for the provided Fuzzer, drawn on the provided terminal. Keys are read from
+// the provided input terminal if it is not nil and can be switched to read individual key presses.
+// Returns the statusScreen, which is not shown until it is started.
+func newStatusScreen(fuzzer *Fuzzer, output *os.File, input *os.File) *statusScreen {
+	s := &statusScreen{
+		fuzzer:  fuzzer,
+		output:  output,
+		logs:    newStatusScreenLogs(statusScreenLogHistoryLength),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if input != nil {
+		if restoreInput, err := enableTerminalKeyInput(input); err == nil {
+			s.input, s.restoreInput = input, restoreInput
+		}
+	}
+	return s
+}
+
+// start shows the screen, redirecting logs to its log pane, and redraws it every statusScreenRefreshInterval until it
+// is closed.
+func (s *statusScreen) start() {
+	// Redirect our logs to our log pane, then switch to the terminal's alternate screen, hiding its cursor.
+	s.previousLogger = logging.GlobalLogger
+	logging.GlobalLogger = s.previousLogger.WithWriter(s.logs)
+	_, _ = io.WriteString(s.output, "\x1b[?1049h\x1b[?25l")
+
+	s.startTime = time.Now()
+	s.lastSampleTime = s.startTime
+	go s.drawLoop()
+	if s.input != nil {
+		go s.keyLoop()
+	}
+}
+
+// close stops drawing the screen and restores the terminal, then restores the logger and writes the log lines
+// retained while the screen was shown to it, so they are not lost.
+func (s *statusScreen) close() {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.stopped
+		if s.restoreInput != nil {
+			s.restoreInput()
+		}
+		_, _ = io.WriteString(s.output, "\x1b[?25h\x1b[?1049l")
+		logging.GlobalLogger = s.previousLogger
+		for _, line := range s.logs.lines() {
+			_, _ = io.WriteString(s.output, line+"\n")
+		}
+	})
+}
+
+// drawLoop samples the campaign and redraws the screen every statusScreenRefreshInterval until the screen is closed.
+func (s *statusScreen) drawLoop() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(statusScreenRefreshInterval)
+	defer ticker.Stop()
+	for {
+		width, height := terminalSize(s.output)
+		_, _ = io.WriteString(s.output, "\x1b[H"+strings.Join(s.snapshot().render(width, height), "\x1b[K\r\n")+"\x1b[K\x1b[J")
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// keyLoop handles keys pressed on the input terminal until the screen is closed: "p" pauses or resumes workers from
+// testing new call sequences, "r" writes coverage reports and test result outputs, and "q" stops the campaign.
+func (s *statusScreen) keyLoop() {
+	b := make([]byte, 1)
+	for {
+		n, err := s.input.Read(b)
+		if err != nil {
+			return
+		}
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+		if n == 0 {
+			continue
+		}
+		switch b[0] {
+		case 'p':
+			if s.fuzzer.GenerationPaused() {
+				s.fuzzer.ResumeGeneration()
+				s.setMessage("Resumed testing new call sequences")
+			} else {
+				s.fuzzer.PauseGeneration()
+				s.setMessage("Paused testing new call sequences, workers finish their current call sequence")
+			}
+		case 'r':
+			s.setMessage("Writing reports...")
+			s.setMessage(s.writeReports())
+		case 'q':
+			s.setMessage("Stopping...")
+			s.fuzzer.Stop()
+		}
+	}
+}
+
+// setMessage sets the message shown to acknowledge the last key pressed.
+func (s *statusScreen) setMessage(message string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.message = message
+}
+
+// writeReports writes the coverage reports and test result outputs of the campaign, as they are written once it ends.
+// Returns a message describing the outcome.
+func (s *statusScreen) writeReports() string {
+	var err error
+	if s.fuzzer.config.Fuzzing.CoverageEnabled {
+		err = s.fuzzer.writeCoverageReports()
+	}
+	if err == nil {
+		err = s.fuzzer.writeTestResultOutputs()
+	}
+	if err != nil {
+		return fmt.Sprintf("Failed to write reports: %v", err)
+	}
+	return fmt.Sprintf("Wrote reports at %v", time.Now().Format("15:04:05"))
+}
+
+// snapshot samples the state of the campaign to be shown on the screen.
+// Returns the sampled state.
+func (s *statusScreen) snapshot() *statusScreenSnapshot {
+	f := s.fuzzer
+	now := time.Now()
+	snapshot := &statusScreenSnapshot{
+		status:      &fuzzerStatus{},
+		elapsed:     now.Sub(s.startTime),
+		paused:      f.GenerationPaused(),
+		keysEnabled: s.input != nil,
+		logs:        s.logs.lines(),
+	}
+
+	// Until our campaign set up its metrics and corpus, there is nothing else to sample.
+	if f.metrics != nil && f.corpus != nil {
+		snapshot.status = f.sampleStatus()
+		sampleSeconds := now.Sub(s.lastSampleTime).Seconds()
+		if seconds := snapshot.elapsed.Seconds(); seconds > 0 {
+			snapshot.averageCallsPerSecond = float64(snapshot.status.CallsTested) / seconds
+		}
+
+		// Derive the rate at which each worker tests calls since our last sample.
+		workerCallsTested := f.metrics.workerCallsTested()
+		workerSequencesTested := f.metrics.workerSequencesTested()
+		callsPerSecond := 0.0
+		for i := range workerCallsTested {
+			worker := statusScreenWorker{sequencesTested: workerSequencesTested[i]}
+			if i < len(snapshot.status.WorkerResets) {
+				worker.resets = snapshot.status.WorkerResets[i]
+			}
+			if i < len(s.lastWorkerCallsTested) && sampleSeconds > 0 && workerCallsTested[i] >= s.lastWorkerCallsTested[i] {
+				worker.callsPerSecond = float64(workerCallsTested[i]-s.lastWorkerCallsTested[i]) / sampleSeconds
+			}
+			callsPerSecond += worker.callsPerSecond
+			snapshot.workers = append(snapshot.workers, worker)
+		}
+		snapshot.status.CallsPerSecond = callsPerSecond
+		s.lastWorkerCallsTested = workerCallsTested
+		s.lastSampleTime = now
+
+		// Record our coverage for our sparkline.
+		s.coverageHistory = append(s.coverageHistory, snapshot.status.CoveredInstructions)
+		if len(s.coverageHistory) > statusScreenCoverageHistoryLength {
+			s.coverageHistory = s.coverageHistory[len(s.coverageHistory)-statusScreenCoverageHistoryLength:]
+		}
+		snapshot.coverageHistory = s.coverageHistory
+	}
+
+	// Describe our test cases, including the best values of optimization tests.
+	f.testCasesLock.Lock()
+	testCases := append([]TestCase{}, f.testCases...)
+	f.testCasesLock.Unlock()
+	for _, testCase := range testCases {
+		test := statusScreenTest{name: strings.TrimSpace(testCase.Name()), status: testCase.Status()}
+		if optimizationTestCase, ok := testCase.(*OptimizationTestCase); ok {
+			if value := optimizationTestCase.Value(); value != nil {
+				test.best = value.String()
+			}
+		}
+		snapshot.tests = append(snapshot.tests, test)
+	}
+
+	s.lock.Lock()
+	snapshot.message = s.message
+	s.lock.Unlock()
+	return snapshot
+}
+
+// render renders the snapshot as lines of a screen of the provided width and height. The campaign summary is shown
+// first, followed by panels for test cases and workers, with the most recent logs filling the remaining lines.
+// Returns the rendered lines, each at most width characters long.
+func (snapshot *statusScreenSnapshot) render(width int, height int) []string {
+	// Summarize our campaign.
+	status := snapshot.status
+	state := colors.Colorize("running", colors.Green)
+	if snapshot.paused {
+		state = colors.Colorize("paused", colors.Yellow)
+	}
+	var open, failed, passed int
+	for _, test := range snapshot.tests {
+		switch test.status {
+		case TestCaseStatusFailed:
+			failed++
+		case TestCaseStatusPassed:
+			passed++
+		default:
+			open++
+		}
+	}
+	lines := []string{
+		fmt.Sprintf("%v | elapsed: %v | %v", colors.Colorize("medusa", colors.Bold), snapshot.elapsed.Round(time.Second), state),
+		fmt.Sprintf("calls: %d (%.0f/sec, avg %.0f/sec) | seq: %d", status.CallsTested, status.CallsPerSecond, snapshot.averageCallsPerSecond, status.SequencesTested),
+		fmt.Sprintf("coverage: %d instructions, %d edges %v", status.CoveredInstructions, status.CoveredEdges, sparkline(snapshot.coverageHistory)),
+		fmt.Sprintf("corpus: %d | mem: %d MB | tests: %d open, %v, %d passed", status.CorpusSize, status.MemoryBytes/1024/1024, open,
+			colors.Colorize(fmt.Sprintf("%d failed", failed), colors.Red), passed),
+	}
+
+	// Determine how many lines our panels may use, leaving at least a few lines for our logs.
+	footerLines := 1
+	panelLines := height - len(lines) - footerLines - 3 - 3
+	testLines := minInt(len(snapshot.tests), maxInt(panelLines-minInt(len(snapshot.workers), panelLines/2), 1))
+	workerLines := minInt(len(snapshot.workers), maxInt(panelLines-testLines, 1))
+
+	// Show our test cases, failed tests first, each with its status and any best value found.
+	tests := append([]statusScreenTest{}, snapshot.tests...)
+	statusOrder := map[TestCaseStatus]int{TestCaseStatusFailed: 0, TestCaseStatusRunning: 1, TestCaseStatusNotStarted: 2, TestCaseStatusPassed: 3}
+	sort.SliceStable(tests, func(i, j int) bool { return statusOrder[tests[i].status] < statusOrder[tests[j].status] })
+	lines = append(lines, statusScreenPanelTitle("Tests", width))
+	for i := 0; i < testLines; i++ {
+		test := tests[i]
+		line := fmt.Sprintf("[%v] %v", test.status, test.name)
+		if test.status == TestCaseStatusFailed {
+			line = fmt.Sprintf("[%v] %v", colors.Colorize(string(test.status), colors.Red), test.name)
+		} else if test.status == TestCaseStatusPassed {
+			line = fmt.Sprintf("[%v] %v", colors.Colorize(string(test.status), colors.Green), test.name)
+		}
+		if test.best != "" {
+			line += fmt.Sprintf(" (best: %v)", test.best)
+		}
+		lines = append(lines, line)
+	}
+	if testLines < len(tests) {
+		lines[len(lines)-1] = fmt.Sprintf("... and %d more", len(tests)-testLines+1)
+	}
+
+	// Show the activity of each worker.
+	lines = append(lines, statusScreenPanelTitle("Workers", width))
+	for i := 0; i < workerLines; i++ {
+		worker := snapshot.workers[i]
+		lines = append(lines, fmt.Sprintf("worker %d: %.0f calls/sec | seq: %d | resets: %d", i, worker.callsPerSecond, worker.sequencesTested, worker.resets))
+	}
+	if workerLines < len(snapshot.workers) {
+		lines[len(lines)-1] = fmt.Sprintf("... and %d more", len(snapshot.workers)-workerLines+1)
+	}
+
+	// Fill our remaining lines with our most recent logs.
+	lines = append(lines, statusScreenPanelTitle("Logs", width))
+	logLines := maxInt(height-len(lines)-footerLines, 0)
+	logs := snapshot.logs
+	if len(logs) > logLines {
+		logs = logs[len(logs)-logLines:]
+	}
+	lines = append(lines, logs...)
+	for len(lines) < height-footerLines {
+		lines = append(lines, "")
+	}
+
+	// Show our keybindings, or the message acknowledging the last key pressed.
+	footer := snapshot.message
+	if footer == "" && snapshot.keysEnabled {
+		footer = "[p] pause/resume new call sequences | [r] write reports | [q] stop"
+	}
+	lines = append(lines, footer)
+
+	// Clip our lines to our screen.
+	if len(lines) > height {
+		lines = lines[:maxInt(height, 0)]
+	}
+	for i, line := range lines {
+		lines[i] = truncateColorizedText(line, width)
+	}
+	return lines
+}
+
+// statusScreenPanelTitle renders the title of a panel on a statusScreen of the provided width.
+// Returns the rendered title.
+func statusScreenPanelTitle(title string, width int) string {
+	return fmt.Sprintf("── %v %v", colors.Colorize(title, colors.Bold), strings.Repeat("─", maxInt(width-len(title)-4, 0)))
+}
+
+// sparkline renders the provided values as a sparkline, scaled between their minimum and maximum.
+// Returns the rendered sparkline.
+func sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	minimum, maximum := values[0], values[0]
+	for _, value := range values {
+		minimum, maximum = minInt(minimum, value), maxInt(maximum, value)
+	}
+	characters := make([]rune, len(values))
+	for i, value := range values {
+		index := 0
+		if maximum > minimum {
+			index = (value - minimum) * (len(sparklineCharacters) - 1) / (maximum - minimum)
+		}
+		characters[i] = sparklineCharacters[index]
+	}
+	return string(characters)
+}
+
+// truncateColorizedText truncates the provided text, which may contain color codes, to the provided amount of
+// visible characters. If the text is truncated, the colored spans which remain are colored again with
+// colors.Colorize, so the truncated text never leaves a color applied and respects the color mode.
+// Returns the truncated text.
+func truncateColorizedText(text string, width int) string {
+	if utf8.RuneCountInString(colors.Strip(text)) <= width {
+		return text
+	}
+	var b, span strings.Builder
+	var color colors.Color
+	writeSpan := func() {
+		if span.Len() > 0 {
+			if color == "" {
+				b.WriteString(span.String())
+			} else {
+				b.WriteString(colors.Colorize(span.String(), color))
+			}
+		}
+		span.Reset()
+	}
+	visible := 0
+	for i := 0; i < len(text) && visible < width; {
+		// Color codes end the current span and change the color of the next, without counting as visible characters.
+		if text[i] == '\x1b' {
+			end := strings.IndexByte(text[i:], 'm')
+			if end >= 0 {
+				writeSpan()
+				if code := colors.Color(text[i : i+end+1]); code == colors.Reset {
+					color = ""
+				} else {
+					color += code
+				}
+				i += end + 1
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		span.WriteRune(r)
+		i += size
+		visible++
+	}
+	writeSpan()
+	return b.String()
+}
+
+// minInt returns the smaller of the provided integers.
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// maxInt returns the larger of the provided integers.
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// statusScreenLogs is an io.Writer which retains the most recent lines written to it, without colors, so they can be
+// shown in the log pane of a statusScreen.
+type statusScreenLogs struct {
+	// history describes the retained lines, oldest first.
+	history []string
+
+	// maxLines describes the maximum amount of lines retained.
+	maxLines int
+
+	// partial describes a line which was written without its line ending yet.
+	partial string
+
+	// lock provides thread-synchronization, as logs are written from many goroutines.
+	lock sync.Mutex
+}
+
+// newStatusScreenLogs creates a statusScreenLogs which retains up to the provided amount of lines.
+// Returns the new statusScreenLogs.
+func newStatusScreenLogs(maxLines int) *statusScreenLogs {
+	return &statusScreenLogs{history: make([]string, 0), maxLines: maxLines}
+}
+
+// Write retains the lines of the provided text. A line without a line ending is retained once it is completed.
+// Returns the length of the provided text.
+func (l *statusScreenLogs) Write(p []byte) (int, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	lines := strings.Split(l.partial+colors.Strip(string(p)), "\n")
+	l.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		l.history = append(l.history, strings.TrimRight(line, "\r"))
+	}
+	if len(l.history) > l.maxLines {
+		l.history = append([]string{}, l.history[len(l.history)-l.maxLines:]...)
+	}
+	return len(p), nil
+}
+
+// lines returns the retained lines, oldest first.
+func (l *statusScreenLogs) lines() []string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return append([]string{}, l.history...)
+}
@@ -0,0 +1,9 @@
+package fuzzing
+
+import "golang.org/x/sys/unix"
+
+// ioctlReadTermios and ioctlWriteTermios describe the requests which read and write the mode of a terminal.
+const (
+	ioctlReadTermios  = unix.TCGETS
+	ioctlWriteTermios = unix.TCSETS
+)
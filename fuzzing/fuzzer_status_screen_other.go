@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+
+package fuzzing
+
+import (
+	"errors"
+	"os"
+)
+
+// terminalSize obtains the size of the provided terminal. The size of terminals is not obtained on this platform.
+// Returns a width and height of 80x24 characters.
+func terminalSize(terminal *os.File) (int, int) {
+	return 80, 24
+}
+
+// enableTerminalKeyInput switches the provided terminal to deliver individual key presses. This is not supported on
+// this platform, so the status screen is shown without keybindings.
+// Returns an error, as the terminal could not be switched.
+func enableTerminalKeyInput(terminal *os.File) (func(), error) {
+	return nil, errors.New("reading individual key presses is not supported on this platform")
+}
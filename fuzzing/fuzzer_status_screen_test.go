@@ -0,0 +1,155 @@
+package fuzzing
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/logging/colors"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStatusScreenRender ensures the status screen renders the campaign summary, its test and worker panels and the
+// most recent logs, clipped to the size of the screen.
+func TestStatusScreenRender(t *testing.T) {
+	snapshot := &statusScreenSnapshot{
+		status: &fuzzerStatus{
+			CallsTested:         12000,
+			CallsPerSecond:      400,
+			SequencesTested:     120,
+			CorpusSize:          7,
+			CoveredInstructions: 350,
+		},
+		elapsed:               30 * time.Second,
+		averageCallsPerSecond: 400,
+		coverageHistory:       []int{100, 200, 350},
+		tests: []statusScreenTest{
+			{name: "Property Test: TestContract.property_a()", status: TestCaseStatusRunning},
+			{name: "Optimization Test: TestContract.optimize_b()", status: TestCaseStatusRunning, best: "42"},
+			{name: "Property Test: TestContract.property_c()", status: TestCaseStatusFailed},
+		},
+		workers: []statusScreenWorker{
+			{callsPerSecond: 250, sequencesTested: 70, resets: 1},
+			{callsPerSecond: 150, sequencesTested: 50},
+		},
+		paused:      true,
+		keysEnabled: true,
+		logs:        []string{"first log", "second log", "third log"},
+	}
+	lines := snapshot.render(100, 16)
+	output := colors.Strip(strings.Join(lines, "\n"))
+
+	assert.Len(t, lines, 16)
+	assert.Contains(t, output, "elapsed: 30s | paused")
+	assert.Contains(t, output, "calls: 12000 (400/sec, avg 400/sec) | seq: 120")
+	assert.Contains(t, output, "coverage: 350 instructions, 0 edges ▁▃█")
+	assert.Contains(t, output, "tests: 2 open, 1 failed, 0 passed")
+	assert.Contains(t, output, "worker 0: 250 calls/sec | seq: 70 | resets: 1")
+	assert.Contains(t, output, "[p] pause/resume new call sequences")
+
+	// Failed tests should be shown first, and optimization tests with their best value.
+	assert.Less(t, strings.Index(output, "property_c"), strings.Index(output, "property_a"))
+	assert.Contains(t, output, "optimize_b() (best: 42)")
+
+	// The most recent logs should fill the remaining lines.
+	assert.Contains(t, output, "third log")
+
+	// A smaller screen should clip lines to its width and height, dropping the oldest logs first.
+	lines = snapshot.render(30, 12)
+	assert.Len(t, lines, 12)
+	for _, line := range lines {
+		assert.LessOrEqual(t, len([]rune(colors.Strip(line))), 30)
+	}
+	output = colors.Strip(strings.Join(lines, "\n"))
+	assert.NotContains(t, output, "first log")
+}
+
+// TestSparkline ensures sparklines are scaled between the minimum and maximum of their values.
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", sparkline(nil))
+	assert.Equal(t, "▁▁▁", sparkline([]int{5, 5, 5}))
+	assert.Equal(t, "▁▄█", sparkline([]int{0, 50, 100}))
+}
+
+// TestTruncateColorizedText ensures truncated text keeps the colors of its remaining spans, without leaving a color
+// applied, and has no colors applied when colors are disabled.
+func TestTruncateColorizedText(t *testing.T) {
+	defer colors.SetMode(colors.ModeAuto)
+
+	colors.SetMode(colors.ModeAlways)
+	text := "ab" + colors.Colorize("cdef", colors.Red) + "gh"
+	assert.Equal(t, text, truncateColorizedText(text, 8))
+	assert.Equal(t, "ab"+colors.Colorize("c", colors.Red), truncateColorizedText(text, 3))
+	assert.Equal(t, "a", truncateColorizedText(text, 1))
+	assert.Equal(t, colors.Colorize("b", colors.Bold+colors.Red), truncateColorizedText(colors.Colorize(colors.Colorize("bold", colors.Red), colors.Bold), 1))
+
+	colors.SetMode(colors.ModeNever)
+	assert.Equal(t, "abc", truncateColorizedText(text, 3))
+}
+
+// TestStatusScreenLogs ensures the status screen's log pane retains the most recent complete lines written to it,
+// without colors.
+func TestStatusScreenLogs(t *testing.T) {
+	logs := newStatusScreenLogs(2)
+	_, _ = logs.Write([]byte("first\nsec"))
+	assert.Equal(t, []string{"first"}, logs.lines())
+	_, _ = logs.Write([]byte("ond\n" + colors.Colorize("third", colors.Red) + "\r\n"))
+	assert.Equal(t, []string{"second", "third"}, logs.lines())
+}
+
+// TestGenerationPause ensures workers waiting while generation is paused are released once it is resumed, or once the
+// fuzzing operation is cancelled.
+func TestGenerationPause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := &Fuzzer{ctx: ctx}
+
+	// Waiting while generation is not paused should not block.
+	f.waitWhileGenerationPaused()
+
+	// Waiting while generation is paused should block until it is resumed.
+	f.PauseGeneration()
+	assert.True(t, f.GenerationPaused())
+	released := make(chan struct{})
+	go func() {
+		f.waitWhileGenerationPaused()
+		close(released)
+	}()
+	select {
+	case <-released:
+		t.Fatal("worker was released while generation is paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	f.ResumeGeneration()
+	assert.False(t, f.GenerationPaused())
+	<-released
+
+	// Cancelling the fuzzing operation should release workers too.
+	f.PauseGeneration()
+	cancel()
+	f.waitWhileGenerationPaused()
+}
+
+// TestStatusScreenLogRedirection ensures logs are shown in the status screen's log pane while it is shown, and are
+// written to its terminal along with the logger being restored once it is closed.
+func TestStatusScreenLogRedirection(t *testing.T) {
+	output, err := os.CreateTemp(t.TempDir(), "screen")
+	assert.NoError(t, err)
+	defer output.Close()
+
+	previousLogger := logging.GlobalLogger
+	screen := newStatusScreen(&Fuzzer{}, output, nil)
+	screen.start()
+	logging.GlobalLogger.Info().Msg("logged while shown")
+	assert.Equal(t, []string{"logged while shown"}, screen.logs.lines())
+	screen.close()
+	screen.close()
+	assert.Same(t, previousLogger, logging.GlobalLogger)
+
+	written, err := os.ReadFile(output.Name())
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(written), "\x1b[?25h\x1b[?1049llogged while shown\n"))
+}
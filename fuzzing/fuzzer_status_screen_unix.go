@@ -0,0 +1,40 @@
+//go:build linux || darwin
+
+package fuzzing
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalSize obtains the size of the provided terminal.
+// Returns the width and height of the terminal in characters, or 80x24 if its size could not be obtained.
+func terminalSize(terminal *os.File) (int, int) {
+	size, err := unix.IoctlGetWinsize(int(terminal.Fd()), unix.TIOCGWINSZ)
+	if err != nil || size.Col == 0 || size.Row == 0 {
+		return 80, 24
+	}
+	return int(size.Col), int(size.Row)
+}
+
+// enableTerminalKeyInput switches the provided terminal to deliver individual key presses without echoing them,
+// while signals such as interrupts are still raised.
+// Returns a function which restores the previous mode of the terminal, or an error if it could not be switched.
+func enableTerminalKeyInput(terminal *os.File) (func(), error) {
+	fd := int(terminal.Fd())
+	previous, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
+	if err != nil {
+		return nil, err
+	}
+	termios := *previous
+	termios.Lflag &^= unix.ICANON | unix.ECHO
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err = unix.IoctlSetTermios(fd, ioctlWriteTermios, &termios); err != nil {
+		return nil, err
+	}
+	return func() {
+		_ = unix.IoctlSetTermios(fd, ioctlWriteTermios, previous)
+	}, nil
+}
@@ -0,0 +1,220 @@
+package fuzzing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/crytic/medusa/logging"
+)
+
+// StopReason describes the reason a fuzzing campaign stopped.
+type StopReason string
+
+const (
+	// StopReasonNone indicates the campaign has not stopped.
+	StopReasonNone StopReason = ""
+
+	// StopReasonInterrupted indicates the campaign was stopped through Fuzzer.Stop, e.g. on a keyboard interrupt.
+	StopReasonInterrupted StopReason = "interrupted"
+
+	// StopReasonTimeout indicates the campaign ran for its configured timeout.
+	StopReasonTimeout StopReason = "timeout"
+
+	// StopReasonCallLimit indicates the campaign tested its configured call (or test) limit.
+	StopReasonCallLimit StopReason = "callLimit"
+
+	// StopReasonSequenceLimit indicates the campaign tested its configured sequence limit.
+	StopReasonSequenceLimit StopReason = "sequenceLimit"
+
+	// StopReasonNoNewCoverage indicates the campaign found no new coverage for its configured stagnation duration.
+	StopReasonNoNewCoverage StopReason = "noNewCoverage"
+
+	// StopReasonFailedTest indicates the campaign was configured to stop on the first failed test, and one failed.
+	StopReasonFailedTest StopReason = "failedTest"
+
+	// StopReasonRecompiled indicates the campaign was stopped in watch mode, as the contracts it fuzzed were
+	// recompiled.
+	StopReasonRecompiled StopReason = "recompiled"
+
+	// StopReasonError indicates the campaign stopped because it encountered an error.
+	StopReasonError StopReason = "error"
+)
+
+// Description obtains a human-readable description of the StopReason, for use in the exit summary.
+func (r StopReason) Description() string {
+	switch r {
+	case StopReasonInterrupted:
+		return "the campaign was interrupted"
+	case StopReasonTimeout:
+		return "the timeout elapsed"
+	case StopReasonCallLimit:
+		return "the call limit was reached"
+	case StopReasonSequenceLimit:
+		return "the sequence limit was reached"
+	case StopReasonNoNewCoverage:
+		return "no new coverage was found for the configured duration"
+	case StopReasonFailedTest:
+		return "a test failed"
+	case StopReasonRecompiled:
+		return "the contracts were recompiled"
+	case StopReasonError:
+		return "an error was encountered"
+	default:
+		return "the campaign ended"
+	}
+}
+
+// stopConditionCheckInterval describes how often the stopConditionCoordinator checks whether a limit of the campaign
+// was reached.
+const stopConditionCheckInterval = 100 * time.Millisecond
+
+// stopConditionCoordinator watches the counters of a Fuzzer's workers and the coverage they find, stopping the
+// campaign once it reaches one of its configured limits or its coverage stagnates.
+type stopConditionCoordinator struct {
+	// fuzzer describes the Fuzzer whose campaign is being watched.
+	fuzzer *Fuzzer
+
+	// lastCoverageIncrease describes the time at which coverage last increased, or the campaign began fuzzing if it
+	// has not increased since.
+	lastCoverageIncrease time.Time
+
+	// lastCoverageIncreaseLock provides thread-synchronization to avoid race conditions when workers update
+	// lastCoverageIncrease.
+	lastCoverageIncreaseLock sync.Mutex
+}
+
+// attachStopConditionCoordinator creates a stopConditionCoordinator and subscribes it to the coverage increases of
+// workers created by the provided Fuzzer.
+// Returns the coordinator created.
+func attachStopConditionCoordinator(fuzzer *Fuzzer) *stopConditionCoordinator {
+	c := &stopConditionCoordinator{
+		fuzzer: fuzzer,
+	}
+	fuzzer.Events.WorkerCreated.Subscribe(c.onWorkerCreated)
+	return c
+}
+
+// onWorkerCreated is the event handler triggered when a FuzzerWorker is created by the Fuzzer. It subscribes to the
+// coverage increases found by the worker.
+func (c *stopConditionCoordinator) onWorkerCreated(event FuzzerWorkerCreatedEvent) error {
+	event.Worker.Events.CoverageIncreased.Subscribe(c.onWorkerCoverageIncreased)
+	return nil
+}
+
+// onWorkerCoverageIncreased is the event handler triggered when a FuzzerWorker increases coverage. It records the time
+// coverage increased, so stagnation is measured from it.
+func (c *stopConditionCoordinator) onWorkerCoverageIncreased(event FuzzerWorkerCoverageIncreasedEvent) error {
+	c.lastCoverageIncreaseLock.Lock()
+	c.lastCoverageIncrease = time.Now()
+	c.lastCoverageIncreaseLock.Unlock()
+	return nil
+}
+
+// start begins watching the Fuzzer's campaign for its stop conditions, until its ctx signals a stopped operation.
+// This should be called as the campaign begins fuzzing, as coverage stagnation is measured from it.
+func (c *stopConditionCoordinator) start() {
+	c.lastCoverageIncreaseLock.Lock()
+	c.lastCoverageIncrease = time.Now()
+	c.lastCoverageIncreaseLock.Unlock()
+	go c.run()
+}
+
+// run checks the Fuzzer's campaign for its stop conditions every stopConditionCheckInterval until its ctx signals a
+// stopped operation, stopping the campaign for the first condition which is met.
+func (c *stopConditionCoordinator) run() {
+	// A malformed duration is rejected by config validation, so we can safely ignore its error here.
+	fuzzingConfig := c.fuzzer.config.Fuzzing
+	stagnationDuration, _ := fuzzingConfig.NoNewCoverageStopDuration()
+
+	ticker := time.NewTicker(stopConditionCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.fuzzer.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Check our call and sequence limits.
+		callsTested := c.fuzzer.metrics.CallsTested()
+		for _, callLimit := range []uint64{fuzzingConfig.TestLimit, fuzzingConfig.CallLimit} {
+			if callLimit > 0 && (!callsTested.IsUint64() || callsTested.Uint64() >= callLimit) {
+				logging.GlobalLogger.Info().Uint64("limit", callLimit).Msgf("Call limit of %d reached, halting now ...", callLimit)
+				c.fuzzer.stop(StopReasonCallLimit)
+				return
+			}
+		}
+		sequencesTested := c.fuzzer.metrics.SequencesTested()
+		if sequenceLimit := fuzzingConfig.SequenceLimit; sequenceLimit > 0 && (!sequencesTested.IsUint64() || sequencesTested.Uint64() >= sequenceLimit) {
+			logging.GlobalLogger.Info().Uint64("limit", sequenceLimit).Msgf("Sequence limit of %d reached, halting now ...", sequenceLimit)
+			c.fuzzer.stop(StopReasonSequenceLimit)
+			return
+		}
+
+		// Check whether our coverage stagnated.
+		if stagnationDuration > 0 {
+			c.lastCoverageIncreaseLock.Lock()
+			sinceCoverageIncrease := time.Since(c.lastCoverageIncrease)
+			c.lastCoverageIncreaseLock.Unlock()
+			if sinceCoverageIncrease >= stagnationDuration {
+				logging.GlobalLogger.Info().Str("duration", stagnationDuration.String()).Msgf("No new coverage found for %v, halting now ...", stagnationDuration)
+				c.fuzzer.stop(StopReasonNoNewCoverage)
+				return
+			}
+		}
+	}
+}
+
+// StopReason returns the reason the current (or most recent) fuzzing campaign stopped, or StopReasonNone if it has
+// not stopped.
+func (f *Fuzzer) StopReason() StopReason {
+	f.stopReasonLock.Lock()
+	defer f.stopReasonLock.Unlock()
+	return f.stopReason
+}
+
+// stop records the provided reason as the reason the campaign stopped, unless it already stopped for another reason,
+// then stops it.
+func (f *Fuzzer) stop(reason StopReason) {
+	f.stopReasonLock.Lock()
+	if f.stopReason == StopReasonNone && (f.ctx == nil || f.ctx.Err() == nil) {
+		f.stopReason = reason
+	}
+	f.stopReasonLock.Unlock()
+
+	// Call the cancel function on our running context to stop all working goroutines
+	if f.ctxCancelFunc != nil {
+		f.ctxCancelFunc()
+	}
+}
+
+// forceStop stops the campaign immediately, without letting workers finish the call sequence they are testing or
+// shrinking.
+func (f *Fuzzer) forceStop() {
+	if f.forceStopCtxCancelFunc != nil {
+		f.forceStopCtxCancelFunc()
+	}
+	if f.ctxCancelFunc != nil {
+		f.ctxCancelFunc()
+	}
+}
+
+// recordFinalStopReason records the reason the campaign stopped once its workers have exited, if no reason was
+// recorded when it was stopped. This is the case when it encountered an error, its timeout elapsed, or the context it
+// was started with was cancelled.
+func (f *Fuzzer) recordFinalStopReason(err error) {
+	f.stopReasonLock.Lock()
+	defer f.stopReasonLock.Unlock()
+	if f.stopReason != StopReasonNone {
+		return
+	}
+	if err != nil {
+		f.stopReason = StopReasonError
+	} else if errors.Is(f.ctx.Err(), context.DeadlineExceeded) {
+		f.stopReason = StopReasonTimeout
+	} else if f.ctx.Err() != nil {
+		f.stopReason = StopReasonInterrupted
+	}
+}
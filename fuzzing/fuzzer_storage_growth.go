@@ -0,0 +1,349 @@
+package fuzzing
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/storagetracer"
+	"github.com/crytic/medusa/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/maps"
+)
+
+// storageGrowthContractsReported describes the maximum amount of contracts reported when the campaign ends, as those
+// whose storage grew the most.
+const storageGrowthContractsReported = 10
+
+// ContractStorageGrowth describes how a contract's storage and code grew across the call sequences of a campaign.
+type ContractStorageGrowth struct {
+	// Address describes the address of the contract.
+	Address common.Address `json:"address"`
+
+	// Contract describes the name of the contract, if it was resolved.
+	Contract string `json:"contract,omitempty"`
+
+	// SlotsWritten describes the total amount of storage writes to the contract across all tested call sequences.
+	SlotsWritten uint64 `json:"slotsWritten"`
+
+	// MaxNetNewSlots describes the largest net amount of storage slots a single call sequence populated in the
+	// contract.
+	MaxNetNewSlots int64 `json:"maxNetNewSlots"`
+
+	// MaxCodeSize describes the largest code size, in bytes, of the contract when a call sequence created it. This is
+	// zero if no tested call sequence created it (e.g. it was deployed during chain setup).
+	MaxCodeSize int `json:"maxCodeSize"`
+}
+
+// StorageGrowthSequence describes a call sequence which populated many new contract storage slots.
+type StorageGrowthSequence struct {
+	// NetNewSlots describes the net amount of storage slots the call sequence populated, across all contracts.
+	NetNewSlots int64 `json:"netNewSlots"`
+
+	// NetNewSlotsByContract describes the net amount of storage slots the call sequence populated in each contract
+	// whose storage grew, keyed by contract name (or address, if the name was not resolved).
+	NetNewSlotsByContract map[string]int64 `json:"netNewSlotsByContract"`
+
+	// CallSequence describes the call sequence.
+	CallSequence calls.CallSequence `json:"callSequence"`
+}
+
+// storageGrowthSequence describes a StorageGrowthSequence recorded by a storageGrowthTracker, along with its hash.
+type storageGrowthSequence struct {
+	// hash describes the canonical hash of the call sequence, used to de-duplicate the sequences recorded.
+	hash common.Hash
+
+	// sequence describes the recorded call sequence and its growth.
+	sequence StorageGrowthSequence
+}
+
+// storageGrowthTracker records how the storage and code of each contract grew in the call sequences a worker tested,
+// along with the sequences which grew it the most. It provides thread-synchronization, as recorded growth is read by
+// the Fuzzer while workers record it.
+type storageGrowthTracker struct {
+	// contracts describes the growth recorded for each contract.
+	contracts map[common.Address]*ContractStorageGrowth
+
+	// sequences describes the call sequences which populated the most storage slots, most slots first.
+	sequences []storageGrowthSequence
+
+	// lock provides thread-synchronization to avoid race conditions when accessing recorded growth.
+	lock sync.Mutex
+}
+
+// newStorageGrowthTracker returns a new storageGrowthTracker with no recorded growth.
+func newStorageGrowthTracker() *storageGrowthTracker {
+	return &storageGrowthTracker{
+		contracts: make(map[common.Address]*ContractStorageGrowth),
+		sequences: make([]storageGrowthSequence, 0),
+	}
+}
+
+// recordSequence records the growth of each contract in the provided executed call sequence, keyed by contract address,
+// where contractNames resolves the names of the contracts. The call sequence is retained if it is among the
+// topSequences call sequences which populated the most storage slots.
+func (t *storageGrowthTracker) recordSequence(callSequence calls.CallSequence, growth map[common.Address]*storagetracer.StorageGrowth, contractNames map[common.Address]string, topSequences int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Record the growth of each contract.
+	netNewSlots := int64(0)
+	for address, contractGrowth := range growth {
+		contract, ok := t.contracts[address]
+		if !ok {
+			contract = &ContractStorageGrowth{Address: address, Contract: contractNames[address]}
+			t.contracts[address] = contract
+		}
+		contract.SlotsWritten += contractGrowth.SlotsWritten
+		if contractGrowth.NetNewSlots > contract.MaxNetNewSlots {
+			contract.MaxNetNewSlots = contractGrowth.NetNewSlots
+		}
+		if contractGrowth.CodeSize > contract.MaxCodeSize {
+			contract.MaxCodeSize = contractGrowth.CodeSize
+		}
+		netNewSlots += contractGrowth.NetNewSlots
+	}
+
+	// If the call sequence did not populate more slots than those we retained, we do not retain it.
+	if netNewSlots <= 0 || topSequences <= 0 {
+		return
+	}
+	if len(t.sequences) >= topSequences && netNewSlots <= t.sequences[len(t.sequences)-1].sequence.NetNewSlots {
+		return
+	}
+	hash, err := callSequence.CanonicalHash()
+	if err != nil {
+		return
+	}
+	for _, existing := range t.sequences {
+		if existing.hash == hash {
+			return
+		}
+	}
+
+	// Retain a copy of the call sequence without its execution results, so its chain is not retained with it.
+	clonedSequence, err := callSequence.Clone()
+	if err != nil {
+		return
+	}
+	for _, element := range clonedSequence {
+		element.ChainReference = nil
+		element.ExecutionTrace = nil
+	}
+	netNewSlotsByContract := make(map[string]int64)
+	for address, contractGrowth := range growth {
+		if contractGrowth.NetNewSlots > 0 {
+			netNewSlotsByContract[storageGrowthContractName(address, contractNames[address])] += contractGrowth.NetNewSlots
+		}
+	}
+	t.sequences = append(t.sequences, storageGrowthSequence{
+		hash: hash,
+		sequence: StorageGrowthSequence{
+			NetNewSlots:           netNewSlots,
+			NetNewSlotsByContract: netNewSlotsByContract,
+			CallSequence:          clonedSequence,
+		},
+	})
+	sort.SliceStable(t.sequences, func(i, j int) bool {
+		return t.sequences[i].sequence.NetNewSlots > t.sequences[j].sequence.NetNewSlots
+	})
+	if len(t.sequences) > topSequences {
+		t.sequences = t.sequences[:topSequences]
+	}
+}
+
+// storageGrowthContractName returns the provided contract name, or the provided address if the name is empty.
+func storageGrowthContractName(address common.Address, contractName string) string {
+	if contractName == "" {
+		return address.String()
+	}
+	return contractName
+}
+
+// recordStorageGrowth records the storage growth of each contract in the provided executed call sequence, as recorded
+// by the storagetracer.StorageGrowthTracer attached to the worker's chain. This must be called before the chain is
+// reverted, so the contracts the sequence deployed can be resolved.
+func (fw *FuzzerWorker) recordStorageGrowth(callSequence calls.CallSequence) {
+	// Sum the growth of each contract across the calls of the sequence.
+	growth := make(map[common.Address]*storagetracer.StorageGrowth)
+	contractNames := make(map[common.Address]string)
+	for _, element := range callSequence {
+		if element.ChainReference == nil {
+			continue
+		}
+		for address, callGrowth := range storagetracer.GetStorageGrowthTracerResults(element.ChainReference.MessageResults()) {
+			sequenceGrowth, ok := growth[address]
+			if !ok {
+				sequenceGrowth = &storagetracer.StorageGrowth{}
+				growth[address] = sequenceGrowth
+				if contract := fw.DeployedContract(address); contract != nil {
+					contractNames[address] = contract.Name()
+				}
+			}
+			sequenceGrowth.SlotsWritten += callGrowth.SlotsWritten
+			sequenceGrowth.NetNewSlots += callGrowth.NetNewSlots
+			if callGrowth.CodeSize > sequenceGrowth.CodeSize {
+				sequenceGrowth.CodeSize = callGrowth.CodeSize
+			}
+		}
+	}
+	fw.workerMetrics().storageGrowth.recordSequence(callSequence, growth, contractNames, fw.fuzzer.config.Fuzzing.StorageGrowth.TopSequences)
+}
+
+// ContractStorageGrowth summarizes how the storage and code of each contract grew across the call sequences the
+// fuzzer tested, if storage growth is recorded. The growth recorded by each worker is merged when this is called.
+// Contracts are sorted by largest net growth in a single call sequence first.
+// Returns the growth of each contract, or nil if no campaign has been started.
+func (f *Fuzzer) ContractStorageGrowth() []ContractStorageGrowth {
+	// If we have not started a campaign, we have nothing to summarize.
+	if f.metrics == nil {
+		return nil
+	}
+
+	// Merge the growth recorded by each worker.
+	merged := make(map[common.Address]*ContractStorageGrowth)
+	for _, workerMetrics := range f.metrics.workerMetrics {
+		workerMetrics.storageGrowth.lock.Lock()
+		for address, contract := range workerMetrics.storageGrowth.contracts {
+			mergedContract, ok := merged[address]
+			if !ok {
+				mergedContract = &ContractStorageGrowth{Address: address}
+				merged[address] = mergedContract
+			}
+			if mergedContract.Contract == "" {
+				mergedContract.Contract = contract.Contract
+			}
+			mergedContract.SlotsWritten += contract.SlotsWritten
+			if contract.MaxNetNewSlots > mergedContract.MaxNetNewSlots {
+				mergedContract.MaxNetNewSlots = contract.MaxNetNewSlots
+			}
+			if contract.MaxCodeSize > mergedContract.MaxCodeSize {
+				mergedContract.MaxCodeSize = contract.MaxCodeSize
+			}
+		}
+		workerMetrics.storageGrowth.lock.Unlock()
+	}
+
+	// Sort the contracts by largest growth first, then by address.
+	contracts := make([]ContractStorageGrowth, 0, len(merged))
+	for _, contract := range merged {
+		contracts = append(contracts, *contract)
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		if contracts[i].MaxNetNewSlots != contracts[j].MaxNetNewSlots {
+			return contracts[i].MaxNetNewSlots > contracts[j].MaxNetNewSlots
+		}
+		if contracts[i].MaxCodeSize != contracts[j].MaxCodeSize {
+			return contracts[i].MaxCodeSize > contracts[j].MaxCodeSize
+		}
+		return bytes.Compare(contracts[i].Address.Bytes(), contracts[j].Address.Bytes()) < 0
+	})
+	return contracts
+}
+
+// StorageGrowthSequences returns the call sequences tested by the fuzzer which populated the most new storage slots,
+// most slots first, if storage growth is recorded. At most the configured amount of top sequences is returned.
+// Returns the call sequences, or nil if no campaign has been started.
+func (f *Fuzzer) StorageGrowthSequences() []StorageGrowthSequence {
+	// If we have not started a campaign, we have nothing to summarize.
+	if f.metrics == nil {
+		return nil
+	}
+
+	// Merge the call sequences retained by each worker, as the same call sequence may be tested by several.
+	merged := make([]storageGrowthSequence, 0)
+	hashes := make(map[common.Hash]struct{})
+	for _, workerMetrics := range f.metrics.workerMetrics {
+		workerMetrics.storageGrowth.lock.Lock()
+		for _, sequence := range workerMetrics.storageGrowth.sequences {
+			if _, ok := hashes[sequence.hash]; !ok {
+				hashes[sequence.hash] = struct{}{}
+				merged = append(merged, sequence)
+			}
+		}
+		workerMetrics.storageGrowth.lock.Unlock()
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].sequence.NetNewSlots > merged[j].sequence.NetNewSlots
+	})
+	if topSequences := f.config.Fuzzing.StorageGrowth.TopSequences; len(merged) > topSequences {
+		merged = merged[:topSequences]
+	}
+	sequences := make([]StorageGrowthSequence, 0, len(merged))
+	for _, sequence := range merged {
+		sequences = append(sequences, sequence.sequence)
+	}
+	return sequences
+}
+
+// writeStorageGrowthSequences stores the call sequences which populated the most new storage slots in the corpus
+// directory, if storage growth is recorded.
+// Returns an error if one occurs.
+func (f *Fuzzer) writeStorageGrowthSequences() error {
+	if !f.config.Fuzzing.StorageGrowth.Enabled || f.corpus == nil {
+		return nil
+	}
+	sequences := f.StorageGrowthSequences()
+	callSequences := make([]calls.CallSequence, 0, len(sequences))
+	for _, sequence := range sequences {
+		callSequences = append(callSequences, sequence.CallSequence)
+	}
+	err := f.corpus.WriteStorageGrowthCallSequences(callSequences)
+	if err != nil {
+		return fmt.Errorf("failed to write storage growth call sequences: %v", err)
+	}
+	return nil
+}
+
+// printStorageGrowthReport logs a table of the contracts whose storage grew the most, followed by the call sequences
+// which populated the most new storage slots, if storage growth is recorded. Nothing is logged if no contract's
+// storage was written to.
+func (f *Fuzzer) printStorageGrowthReport() {
+	if !f.config.Fuzzing.StorageGrowth.Enabled {
+		return
+	}
+	contracts := f.ContractStorageGrowth()
+	if len(contracts) == 0 {
+		return
+	}
+	if len(contracts) > storageGrowthContractsReported {
+		contracts = contracts[:storageGrowthContractsReported]
+	}
+	sequences := f.StorageGrowthSequences()
+
+	// In the JSON log format, the growth is logged as structured data rather than a table.
+	if logging.GlobalLogger.Format() == logging.LogFormatJSON {
+		logging.GlobalLogger.Info().Interface("contracts", contracts).Interface("sequences", sequences).Msg("Contract storage growth")
+		return
+	}
+
+	var report strings.Builder
+	writer := tabwriter.NewWriter(&report, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "CONTRACT\tADDRESS\tSLOTS WRITTEN\tMAX NET NEW SLOTS\tMAX CODE SIZE\n")
+	for _, contract := range contracts {
+		fmt.Fprintf(writer, "%v\t%v\t%d\t%d\t%d\n", storageGrowthContractName(contract.Address, contract.Contract), contract.Address, contract.SlotsWritten, contract.MaxNetNewSlots, contract.MaxCodeSize)
+	}
+	_ = writer.Flush()
+
+	// Describe the call sequences which grew storage the most, and where they were stored.
+	if len(sequences) > 0 {
+		report.WriteString("\nCall sequences which populated the most new storage slots:\n")
+		for i, sequence := range sequences {
+			contractNames := maps.Keys(sequence.NetNewSlotsByContract)
+			sort.Strings(contractNames)
+			contractGrowth := make([]string, 0, len(contractNames))
+			for _, contractName := range contractNames {
+				contractGrowth = append(contractGrowth, fmt.Sprintf("%v: %d", contractName, sequence.NetNewSlotsByContract[contractName]))
+			}
+			fmt.Fprintf(&report, "%d) %d new slots in %d calls (%v)\n", i+1, sequence.NetNewSlots, len(sequence.CallSequence), strings.Join(contractGrowth, ", "))
+		}
+		if f.corpus != nil && f.corpus.StorageGrowthCallSequencesDirectory() != "" {
+			fmt.Fprintf(&report, "These call sequences were stored in %v", f.corpus.StorageGrowthCallSequencesDirectory())
+		}
+	}
+	logging.GlobalLogger.Info().Msgf("Contract storage growth:\n%s", strings.TrimRight(report.String(), "\n"))
+}
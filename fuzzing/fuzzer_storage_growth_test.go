@@ -0,0 +1,68 @@
+package fuzzing
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/storagetracer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// storageGrowthTestSequence creates a call sequence with a single call, which is distinct for each provided value sent.
+func storageGrowthTestSequence(value int64) calls.CallSequence {
+	to := common.BytesToAddress([]byte("target"))
+	method := abi.NewMethod("grow", "grow", abi.Function, "", false, false, nil, nil)
+	data := &calls.CallMessageDataAbiValues{Method: &method, InputValues: []any{}}
+	call := calls.NewCallMessageWithAbiValueData(common.BytesToAddress([]byte("sender")), &to, 0, big.NewInt(value), 100_000, big.NewInt(1), big.NewInt(1), big.NewInt(0), data)
+	return calls.CallSequence{calls.NewCallSequenceElement(nil, call, 1, 1)}
+}
+
+// TestStorageGrowth ensures the storage growth recorded by each worker is merged, and that only the call sequences
+// which populated the most storage slots are retained, without duplicates.
+func TestStorageGrowth(t *testing.T) {
+	f := &Fuzzer{metrics: newFuzzerMetrics(2, nil, nil)}
+	f.config.Fuzzing.StorageGrowth.TopSequences = 2
+	token := common.BytesToAddress([]byte("token"))
+	registry := common.BytesToAddress([]byte("registry"))
+	contractNames := map[common.Address]string{token: "Token"}
+	record := func(workerIndex int, callSequence calls.CallSequence, growth map[common.Address]*storagetracer.StorageGrowth) {
+		f.metrics.workerMetrics[workerIndex].storageGrowth.recordSequence(callSequence, growth, contractNames, f.config.Fuzzing.StorageGrowth.TopSequences)
+	}
+
+	// Record sequences which grew the storage of each contract by varying amounts across both workers.
+	record(0, storageGrowthTestSequence(0), map[common.Address]*storagetracer.StorageGrowth{
+		token: {SlotsWritten: 4, NetNewSlots: 2},
+	})
+	record(0, storageGrowthTestSequence(1), map[common.Address]*storagetracer.StorageGrowth{
+		token:    {SlotsWritten: 10, NetNewSlots: 6},
+		registry: {SlotsWritten: 3, NetNewSlots: 3, CodeSize: 120},
+	})
+	record(0, storageGrowthTestSequence(2), map[common.Address]*storagetracer.StorageGrowth{
+		token: {SlotsWritten: 8, NetNewSlots: -8},
+	})
+	record(1, storageGrowthTestSequence(1), map[common.Address]*storagetracer.StorageGrowth{
+		token:    {SlotsWritten: 10, NetNewSlots: 6},
+		registry: {SlotsWritten: 3, NetNewSlots: 3, CodeSize: 120},
+	})
+	record(1, storageGrowthTestSequence(3), map[common.Address]*storagetracer.StorageGrowth{
+		token: {SlotsWritten: 5, NetNewSlots: 5},
+	})
+
+	// The growth of each contract should be merged across workers.
+	contracts := f.ContractStorageGrowth()
+	assert.EqualValues(t, []ContractStorageGrowth{
+		{Address: token, Contract: "Token", SlotsWritten: 37, MaxNetNewSlots: 6},
+		{Address: registry, SlotsWritten: 6, MaxNetNewSlots: 3, MaxCodeSize: 120},
+	}, contracts)
+
+	// The sequence tested by both workers should only be retained once, followed by the next which grew storage most.
+	sequences := f.StorageGrowthSequences()
+	assert.Len(t, sequences, 2)
+	assert.EqualValues(t, 9, sequences[0].NetNewSlots)
+	assert.EqualValues(t, map[string]int64{"Token": 6, registry.String(): 3}, sequences[0].NetNewSlotsByContract)
+	assert.EqualValues(t, 5, sequences[1].NetNewSlots)
+	assert.EqualValues(t, 3, sequences[1].CallSequence[0].Call.MsgValue.Int64())
+}
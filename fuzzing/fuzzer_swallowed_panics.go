@@ -0,0 +1,66 @@
+package fuzzing
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/panictracer"
+)
+
+// describeSwallowedPanic obtains a text-based printable description of a panic raised by an inner call and caught,
+// identifying the contract and instruction which raised it, along with the source location of the instruction if it
+// can be resolved. This must be called before the worker's chain is reverted, so contracts deployed by the call
+// sequence being described can be resolved.
+// Returns a string describing the swallowed panic.
+func (fw *FuzzerWorker) describeSwallowedPanic(swallowedPanic *panictracer.SwallowedPanic) string {
+	// Resolve the contract which raised the panic. Panics raised while creating a contract are raised by its init
+	// code, which is not yet deployed.
+	frameDescription := fmt.Sprintf("contract %v", swallowedPanic.Address)
+	location := fmt.Sprintf("pc %d", swallowedPanic.PC)
+	if swallowedPanic.Create {
+		frameDescription = fmt.Sprintf("the constructor of the contract created at %v", swallowedPanic.Address)
+	} else if contract := fw.DeployedContract(swallowedPanic.Address); contract != nil {
+		frameDescription = fmt.Sprintf("contract %v (%v)", contract.Name(), swallowedPanic.Address)
+		if sourceLocation := fw.fuzzer.sourceLocation(contract, swallowedPanic.PC); sourceLocation != "" {
+			location = fmt.Sprintf("%v, %v", location, sourceLocation)
+		}
+	}
+	return fmt.Sprintf("%v in an inner call to %v at %v, which was caught", describePanicCode(swallowedPanic.PanicCode), frameDescription, location)
+}
+
+// sourceLocation obtains the source location of the instruction at the provided program counter in the runtime
+// bytecode of the provided contract, as a source file path and line number (e.g. "contracts/Token.sol:42").
+// Returns the source location, or only its source file path if its line could not be read, or an empty string if it
+// could not be resolved.
+func (f *Fuzzer) sourceLocation(contract *fuzzerTypes.Contract, pc uint64) string {
+	// Resolve the source map element of the instruction.
+	compiledContract := contract.CompiledContract()
+	sourceMap, err := compilationTypes.ParseSourceMap(compiledContract.SrcMapsRuntime)
+	if err != nil {
+		return ""
+	}
+	element := sourceMap.ElementAtPC(compiledContract.RuntimeBytecode, pc)
+	if element == nil || element.SourceUnitID < 0 {
+		return ""
+	}
+
+	// Source unit identifiers are only unique within a compilation, so we resolve them in the contract's.
+	for _, compilation := range f.compilations {
+		if _, ok := compilation.Sources[contract.SourcePath()]; !ok {
+			continue
+		}
+		sourcePath, ok := compilation.GetSourcePath(element.SourceUnitID)
+		if !ok {
+			return ""
+		}
+		contents, err := os.ReadFile(sourcePath)
+		if err != nil || element.Offset > len(contents) {
+			return sourcePath
+		}
+		return fmt.Sprintf("%v:%d", sourcePath, bytes.Count(contents[:element.Offset], []byte("\n"))+1)
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package fuzzing
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/panictracer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDescribeSwallowedPanic ensures swallowed panics are described by the contract which raised them, along with the
+// program counter and source location of the instruction which raised them.
+func TestDescribeSwallowedPanic(t *testing.T) {
+	// Create a source file, and a contract whose REVERT instruction (at pc 4) maps to its third line.
+	sourcePath := filepath.Join(t.TempDir(), "Target.sol")
+	source := "contract Target {\n    function f() public {\n        assert(false);\n    }\n}\n"
+	err := os.WriteFile(sourcePath, []byte(source), 0644)
+	assert.NoError(t, err)
+	compiledContract := compilationTypes.CompiledContract{
+		RuntimeBytecode: []byte{byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.REVERT)},
+		SrcMapsRuntime:  "0:70:0:-;;52:13",
+	}
+	compilation := compilationTypes.NewCompilation()
+	compilation.Sources[sourcePath] = compilationTypes.CompiledSource{
+		Ast:       map[string]any{"src": "0:70:0"},
+		Contracts: map[string]compilationTypes.CompiledContract{"Target": compiledContract},
+	}
+	contract := fuzzerTypes.NewContract("Target", sourcePath, &compiledContract, nil)
+
+	address := common.HexToAddress("0x1234")
+	fw := &FuzzerWorker{
+		fuzzer:            &Fuzzer{compilations: []compilationTypes.Compilation{*compilation}},
+		deployedContracts: map[common.Address]*fuzzerTypes.Contract{address: contract},
+	}
+
+	// The panic should be described by its contract and source location.
+	description := fw.describeSwallowedPanic(&panictracer.SwallowedPanic{Address: address, PC: 4, Depth: 1, PanicCode: big.NewInt(1)})
+	assert.EqualValues(t, "assertion failed in an inner call to contract Target (0x0000000000000000000000000000000000001234) at pc 4, "+sourcePath+":3, which was caught", description)
+
+	// Panics raised by unknown contracts, or instructions without a source location, are described without them.
+	unknownAddress := common.HexToAddress("0x5678")
+	description = fw.describeSwallowedPanic(&panictracer.SwallowedPanic{Address: unknownAddress, PC: 7, Depth: 2, PanicCode: big.NewInt(0x12)})
+	assert.EqualValues(t, "panic: division or modulo by zero (code: 0x12) in an inner call to contract 0x0000000000000000000000000000000000005678 at pc 7, which was caught", description)
+	assert.Empty(t, fw.fuzzer.sourceLocation(contract, 1))
+}
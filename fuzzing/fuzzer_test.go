@@ -1,13 +1,22 @@
 package fuzzing
 
 import (
+	"context"
+	"fmt"
 	"github.com/crytic/medusa/chain"
+	compilationTypes "github.com/crytic/medusa/compilation/types"
 	"github.com/crytic/medusa/events"
 	"github.com/crytic/medusa/fuzzing/calls"
 	"github.com/crytic/medusa/fuzzing/valuegeneration"
 	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"math/big"
 	"math/rand"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/crytic/medusa/fuzzing/config"
 	"github.com/stretchr/testify/assert"
@@ -55,6 +64,81 @@ func TestFuzzerHooks(t *testing.T) {
 	})
 }
 
+// constantIntegerValueGenerator is a valuegeneration.ValueGenerator used for testing, which always generates and
+// mutates integers to the same constant value.
+type constantIntegerValueGenerator struct {
+	// value describes the constant integer value to generate.
+	value *big.Int
+
+	// RandomValueGenerator is included to inherit from the random generator
+	*valuegeneration.RandomValueGenerator
+}
+
+// GenerateInteger returns the constant integer value for the generator.
+func (g *constantIntegerValueGenerator) GenerateInteger(signed bool, bitLength int) *big.Int {
+	return new(big.Int).Set(g.value)
+}
+
+// MutateInteger returns the constant integer value for the generator.
+func (g *constantIntegerValueGenerator) MutateInteger(i *big.Int, signed bool, bitLength int) *big.Int {
+	return new(big.Int).Set(g.value)
+}
+
+// TestFuzzerHooksCustomValueGenerator runs tests to ensure that a custom value generator can be supplied to the fuzzer
+// through its hooks, and that it is used to generate every transaction.
+func TestFuzzerHooksCustomValueGenerator(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/assertions/assert_even_number.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 1_000
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Replace our value generator with one which only generates even numbers, so assertions never fail.
+			expectedValue := big.NewInt(2)
+			var valueGeneratorCreated bool
+			f.fuzzer.Hooks.NewValueGeneratorFunc = func(fuzzer *Fuzzer, valueSet *valuegeneration.ValueSet, randomProvider *rand.Rand) (valuegeneration.ValueGenerator, error) {
+				valueGeneratorCreated = true
+				return &constantIntegerValueGenerator{
+					value: expectedValue,
+					RandomValueGenerator: valuegeneration.NewRandomValueGenerator(&valuegeneration.RandomValueGeneratorConfig{
+						GenerateRandomArrayMinSize:  0,
+						GenerateRandomArrayMaxSize:  100,
+						GenerateRandomBytesMinSize:  0,
+						GenerateRandomBytesMaxSize:  100,
+						GenerateRandomStringMinSize: 0,
+						GenerateRandomStringMaxSize: 100,
+					}, randomProvider),
+				}, nil
+			}
+
+			// Verify every call we send uses our generated value.
+			var callsVerified int
+			f.fuzzer.Hooks.CallSequenceTestFuncs = append(f.fuzzer.Hooks.CallSequenceTestFuncs, func(worker *FuzzerWorker, callSequence calls.CallSequence) ([]ShrinkCallSequenceRequest, error) {
+				lastCall := callSequence[len(callSequence)-1].Call
+				if assert.NotNil(t, lastCall.MsgDataAbiValues) {
+					for _, inputValue := range lastCall.MsgDataAbiValues.InputValues {
+						assert.EqualValues(t, expectedValue, inputValue)
+					}
+					callsVerified++
+				}
+				return make([]ShrinkCallSequenceRequest, 0), nil
+			})
+
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Our custom generator should've been used for every call, so no assertion should have failed.
+			assert.True(t, valueGeneratorCreated, "could not hook value generator func")
+			assert.Greater(t, callsVerified, 0, "no calls were verified")
+			assertFailedTestsExpected(f, false)
+		},
+	})
+}
+
 // TestAssertionsBasicSolving runs tests to ensure that assertion testing behaves as expected.
 func TestAssertionsBasicSolving(t *testing.T) {
 	filePaths := []string{
@@ -81,349 +165,1413 @@ func TestAssertionsBasicSolving(t *testing.T) {
 	}
 }
 
-// TestAssertionsNotRequire runs a test to ensure require and revert statements are not mistaken for assert statements.
-// It runs tests against a contract which immediately makes these statements and expects to find no errors before
-// timing out.
-func TestAssertionsNotRequire(t *testing.T) {
+// TestAssertionsShrinkCallArguments runs a test to ensure the arguments of the call failing an assertion test are
+// simplified to the smallest values which still fail it.
+func TestAssertionsShrinkCallArguments(t *testing.T) {
 	runFuzzerTest(t, &fuzzerSolcFileTest{
-		filePath: "testdata/contracts/assertions/assert_not_require.sol",
+		filePath: "testdata/contracts/assertions/assert_shrink_call_arguments.sol",
 		configUpdates: func(config *config.ProjectConfig) {
 			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
-			config.Fuzzing.TestLimit = 500
 			config.Fuzzing.Testing.PropertyTesting.Enabled = false
 			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			config.Fuzzing.Testing.ShrinkCallArguments = true
 		},
 		method: func(f *fuzzerTestContext) {
 			// Start the fuzzer
 			err := f.fuzzer.Start()
 			assert.NoError(t, err)
 
-			// Check for failed assertion tests. We expect none.
-			assertFailedTestsExpected(f, false)
+			// Check for failed assertion tests.
+			assertFailedTestsExpected(f, true)
+
+			// The failing call's arguments should be shrunk to the boundary of the failing condition.
+			failingSequence := *f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)[0].CallSequence()
+			assert.Len(t, failingSequence, 1)
+			inputValues := failingSequence[0].Call.MsgDataAbiValues.InputValues
+			assert.EqualValues(t, big.NewInt(1001), inputValues[0])
+			assert.Len(t, inputValues[1], 2)
 		},
 	})
 }
 
-// TestAssertionsAndProperties runs a test to property testing and assertion testing can both run in parallel.
-// This test does not stop on first failure and expects a failure from each after timeout.
-func TestAssertionsAndProperties(t *testing.T) {
+// TestAssertionsShrinkLimit runs a test to ensure a failing call sequence is still reported when shrinking is
+// stopped early by the shrink limit.
+func TestAssertionsShrinkLimit(t *testing.T) {
 	runFuzzerTest(t, &fuzzerSolcFileTest{
-		filePath: "testdata/contracts/assertions/assert_and_property_test.sol",
+		filePath: "testdata/contracts/assertions/assert_shrink_call_arguments.sol",
 		configUpdates: func(config *config.ProjectConfig) {
 			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
-			config.Fuzzing.TestLimit = 500
-			config.Fuzzing.Testing.StopOnFailedTest = false
-			config.Fuzzing.Testing.PropertyTesting.Enabled = true
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
 			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			config.Fuzzing.Testing.ShrinkCallArguments = true
+			config.Fuzzing.Testing.ShrinkLimit = 1
 		},
 		method: func(f *fuzzerTestContext) {
 			// Start the fuzzer
 			err := f.fuzzer.Start()
 			assert.NoError(t, err)
 
-			// Check for failed assertion tests. We expect none.
-			assert.EqualValues(f.t, 2, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)), "Expected one failure from a property test, and one failure from an assertion test.")
+			// Check for failed assertion tests, which should still report the sequence found, with its arguments
+			// left unshrunk as the only attempt permitted was spent trying to remove the failing call.
+			assertFailedTestsExpected(f, true)
+			failingSequence := *f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)[0].CallSequence()
+			assert.NotEmpty(t, failingSequence)
+			inputValues := failingSequence[len(failingSequence)-1].Call.MsgDataAbiValues.InputValues
+			assert.NotEqualValues(t, big.NewInt(1001), inputValues[0])
 		},
 	})
 }
 
-// TestChainBehaviour runs tests to ensure the chain behaves as expected.
-func TestChainBehaviour(t *testing.T) {
-	// Run a test to simulate out of gas errors to make sure its handled well by the Chain and does not panic.
+// TestAssertionsNotRequire runs a test to ensure require and revert statements are not mistaken for assert statements.
+// It runs tests against a contract which immediately makes these statements and expects to find no errors before
+// timing out.
+func TestAssertionsNotRequire(t *testing.T) {
 	runFuzzerTest(t, &fuzzerSolcFileTest{
-		filePath: "testdata/contracts/chain/tx_out_of_gas.sol",
+		filePath: "testdata/contracts/assertions/assert_not_require.sol",
 		configUpdates: func(config *config.ProjectConfig) {
 			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
-			config.Fuzzing.Workers = 1
-			config.Fuzzing.TestLimit = uint64(config.Fuzzing.CallSequenceLength) // we just need a few oog txs to test
-			config.Fuzzing.Timeout = 10                                          // to be safe, we set a 10s timeout
-			config.Fuzzing.TransactionGasLimit = 500000                          // we set this low, so contract execution runs out of gas earlier.
+			config.Fuzzing.TestLimit = 500
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
 		},
 		method: func(f *fuzzerTestContext) {
 			// Start the fuzzer
 			err := f.fuzzer.Start()
 			assert.NoError(t, err)
 
-			// Assert that we should not have failures.
+			// Check for failed assertion tests. We expect none.
 			assertFailedTestsExpected(f, false)
 		},
 	})
 }
 
-// TestCheatCodes runs tests to ensure that vm extensions ("cheat codes") are working as intended.
-func TestCheatCodes(t *testing.T) {
-	filePaths := []string{
-		"testdata/contracts/cheat_codes/utils/addr.sol",
-		"testdata/contracts/cheat_codes/utils/to_string.sol",
-		"testdata/contracts/cheat_codes/utils/sign.sol",
-		"testdata/contracts/cheat_codes/utils/parse.sol",
-		"testdata/contracts/cheat_codes/vm/coinbase.sol",
-		"testdata/contracts/cheat_codes/vm/chain_id.sol",
-		"testdata/contracts/cheat_codes/vm/deal.sol",
-		"testdata/contracts/cheat_codes/vm/difficulty.sol",
-		"testdata/contracts/cheat_codes/vm/etch.sol",
-		"testdata/contracts/cheat_codes/vm/fee.sol",
-		"testdata/contracts/cheat_codes/vm/prank.sol",
-		"testdata/contracts/cheat_codes/vm/roll.sol",
-		"testdata/contracts/cheat_codes/vm/store_load.sol",
-		"testdata/contracts/cheat_codes/vm/warp.sol",
-	}
+// TestAssertionsPanicCodes runs a test to ensure only the panic codes configured as failures are reported as failing
+// assertion tests, with the decoded panic reported in their results.
+func TestAssertionsPanicCodes(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/assertions/assert_panic_codes.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 1_000
+			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			config.Fuzzing.Testing.AssertionTesting.PanicCodeConfig.FailOnAssertion = false
+			config.Fuzzing.Testing.AssertionTesting.PanicCodeConfig.FailOnArithmeticUnderflow = false
+			config.Fuzzing.Testing.AssertionTesting.PanicCodeConfig.FailOnDivideByZero = true
+			config.Fuzzing.Testing.AssertionTesting.PanicCodeConfig.FailOnOutOfBoundsArrayAccess = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
 
-	// FFI test will fail on Windows because "echo" is a shell command, not a system command, so we diverge these
-	// tests.
-	if utils.IsWindowsEnvironment() {
-		filePaths = append(filePaths,
-			"testdata/contracts/cheat_codes/utils/ffi_windows.sol",
-		)
-	} else {
-		filePaths = append(filePaths,
-			"testdata/contracts/cheat_codes/utils/ffi_unix.sol",
-		)
-	}
+			// Only the division and array access panics should have failed their tests.
+			failedTestCases := f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)
+			failedTestNames := make([]string, 0)
+			for _, testCase := range failedTestCases {
+				failedTestNames = append(failedTestNames, testCase.Name())
+			}
+			assert.ElementsMatch(t, []string{
+				"Assertion Test: TestContract.failDivision(uint256)",
+				"Assertion Test: TestContract.failArrayAccess(uint256)",
+			}, failedTestNames)
+
+			// The decoded panic should be reported for each failure.
+			for _, testCase := range failedTestCases {
+				if strings.Contains(testCase.Name(), "failDivision") {
+					assert.Contains(t, testCase.Message(), "division or modulo by zero")
+				} else {
+					assert.Contains(t, testCase.Message(), "out-of-bounds array access")
+				}
+			}
+		},
+	})
+}
 
-	for _, filePath := range filePaths {
+// TestAssertionsSwallowed runs a test to ensure assertions failed by inner calls and caught by the calling contract
+// only fail assertion tests when swallowed panics are configured to, reporting the contract and source location which
+// failed the assertion.
+func TestAssertionsSwallowed(t *testing.T) {
+	for _, failOnSwallowedPanics := range []bool{true, false} {
 		runFuzzerTest(t, &fuzzerSolcFileTest{
-			filePath: filePath,
+			filePath: "testdata/contracts/assertions/assert_swallowed.sol",
 			configUpdates: func(config *config.ProjectConfig) {
 				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
-
-				// some tests require full sequence + revert to test fully
-				config.Fuzzing.Workers = 3
-				config.Fuzzing.TestLimit = uint64(config.Fuzzing.CallSequenceLength*config.Fuzzing.Workers) * 3
-
-				// enable assertion testing only
+				config.Fuzzing.TestLimit = 1_000
 				config.Fuzzing.Testing.PropertyTesting.Enabled = false
 				config.Fuzzing.Testing.AssertionTesting.Enabled = true
-				config.Fuzzing.TestChainConfig.CheatCodeConfig.EnableFFI = true
+				config.Fuzzing.Testing.AssertionTesting.FailOnSwallowedPanics = failOnSwallowedPanics
 			},
 			method: func(f *fuzzerTestContext) {
 				// Start the fuzzer
 				err := f.fuzzer.Start()
 				assert.NoError(t, err)
 
-				// Check for failed assertion tests.
-				assertFailedTestsExpected(f, false)
+				// Our assertion should only fail if swallowed panics are failures.
+				assertFailedTestsExpected(f, failOnSwallowedPanics)
+
+				// The failure should identify the inner contract and source line which failed the assertion.
+				for _, testCase := range f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed) {
+					assert.Contains(t, testCase.Message(), "assertion failed in an inner call to contract InnerContract")
+					assert.Contains(t, testCase.Message(), "assert_swallowed.sol:6")
+				}
 			},
 		})
 	}
 }
 
-// TestDeploymentsInnerDeployments runs tests to ensure dynamically deployed contracts are detected by the Fuzzer and
-// their properties are tested appropriately.
-func TestDeploymentsInnerDeployments(t *testing.T) {
-	// These contracts provide functions to deploy inner contracts which have properties that will produce a failure.
+// TestAssertionsExpectRevertSolving runs tests to ensure methods expected to revert are reported as failing when they
+// do not revert, or revert with an error other than the one expected.
+func TestAssertionsExpectRevertSolving(t *testing.T) {
 	filePaths := []string{
-		"testdata/contracts/deployments/inner_deployment.sol",
-		"testdata/contracts/deployments/inner_inner_deployment.sol",
+		"testdata/contracts/assertions/assert_expect_revert_missing.sol",
+		"testdata/contracts/assertions/assert_expect_revert_wrong_error.sol",
+		"testdata/contracts/assertions/assert_expect_revert_view.sol",
 	}
 	for _, filePath := range filePaths {
 		runFuzzerTest(t, &fuzzerSolcFileTest{
 			filePath: filePath,
 			configUpdates: func(config *config.ProjectConfig) {
-				config.Fuzzing.DeploymentOrder = []string{"InnerDeploymentFactory"}
-				config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
-				config.Fuzzing.Testing.StopOnFailedContractMatching = true
-				config.Fuzzing.Testing.TestAllContracts = true // test dynamically deployed contracts
+				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+				config.Fuzzing.Testing.PropertyTesting.Enabled = false
+				config.Fuzzing.Testing.AssertionTesting.Enabled = true
 			},
 			method: func(f *fuzzerTestContext) {
 				// Start the fuzzer
 				err := f.fuzzer.Start()
 				assert.NoError(t, err)
 
-				// Check for any failed tests and verify coverage was captured
+				// Check for failed assertion tests, and verify the expected error is reported.
 				assertFailedTestsExpected(f, true)
-				assertCorpusCallSequencesCollected(f, true)
+				for _, testCase := range f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed) {
+					assert.Contains(t, testCase.Message(), "InsufficientBalance(uint256,uint256)")
+				}
 			},
 		})
 	}
+}
 
-	// This contract deploys an inner contract upon construction, which contains properties that will produce a failure.
+// TestAssertionsExpectRevertSatisfied runs a test to ensure methods expected to revert are not reported as failing
+// when they always revert as expected.
+func TestAssertionsExpectRevertSatisfied(t *testing.T) {
 	runFuzzerTest(t, &fuzzerSolcFileTest{
-		filePath: "testdata/contracts/deployments/inner_deployment_on_construction.sol",
+		filePath: "testdata/contracts/assertions/assert_expect_revert_satisfied.sol",
 		configUpdates: func(config *config.ProjectConfig) {
-			config.Fuzzing.DeploymentOrder = []string{"InnerDeploymentFactory"}
-			config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
-			config.Fuzzing.Testing.StopOnFailedContractMatching = true
-			config.Fuzzing.Testing.TestAllContracts = true // test dynamically deployed contracts
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 500
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
 		},
 		method: func(f *fuzzerTestContext) {
 			// Start the fuzzer
 			err := f.fuzzer.Start()
 			assert.NoError(t, err)
 
-			// Check to see if there are any failures
+			// Check for failed assertion tests. We expect none.
+			assertFailedTestsExpected(f, false)
+		},
+	})
+}
+
+// TestAssertionsExpectEmitUnmet runs a test to ensure calls which do not meet event emissions expected through cheat
+// codes are reported as failing assertion tests, describing the expected and emitted events.
+func TestAssertionsExpectEmitUnmet(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/assertions/assert_expect_emit_unmet.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for failed assertion tests, and verify the unmet expectation is reported.
 			assertFailedTestsExpected(f, true)
+			for _, testCase := range f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed) {
+				assert.Contains(t, testCase.Message(), "expected event was not emitted")
+			}
 		},
 	})
 }
 
-// TestDeploymentsInternalLibrary runs a test to ensure internal libraries behave correctly.
-func TestDeploymentsInternalLibrary(t *testing.T) {
+// TestAssertionsAndProperties runs a test to property testing and assertion testing can both run in parallel.
+// This test does not stop on first failure and expects a failure from each after timeout.
+func TestAssertionsAndProperties(t *testing.T) {
 	runFuzzerTest(t, &fuzzerSolcFileTest{
-		filePath: "testdata/contracts/deployments/internal_library.sol",
+		filePath: "testdata/contracts/assertions/assert_and_property_test.sol",
 		configUpdates: func(config *config.ProjectConfig) {
-			config.Fuzzing.DeploymentOrder = []string{"TestInternalLibrary"}
-			config.Fuzzing.TestLimit = 100 // this test should expose a failure quickly.
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 500
+			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.Testing.PropertyTesting.Enabled = true
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
 		},
 		method: func(f *fuzzerTestContext) {
 			// Start the fuzzer
 			err := f.fuzzer.Start()
 			assert.NoError(t, err)
 
-			// Check for any failed tests and verify coverage was captured
-			assertFailedTestsExpected(f, false)
-			assertCorpusCallSequencesCollected(f, true)
+			// Check for failed assertion tests. We expect none.
+			assert.EqualValues(f.t, 2, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)), "Expected one failure from a property test, and one failure from an assertion test.")
 		},
 	})
 }
 
-// TestDeploymentsInnerDeployments runs a test to ensure dynamically deployed contracts are detected by the Fuzzer and
-// their properties are tested appropriately.
-func TestDeploymentsSelfDestruct(t *testing.T) {
-	// These contracts provide functions to deploy inner contracts which have properties that will produce a failure.
-	filePaths := []string{
-		"testdata/contracts/deployments/selfdestruct_init.sol",
-		"testdata/contracts/deployments/selfdestruct_runtime.sol",
+// TestFailedTestBehaviors runs tests to ensure failed tests either stop being tested after their first failure, or
+// continue to be tested with their distinct failures counted.
+func TestFailedTestBehaviors(t *testing.T) {
+	// Define our expected distinct failure counts for each behavior.
+	expectedDistinctFailures := map[config.FailedTestBehavior]int{
+		config.FailedTestBehaviorDisable:     1,
+		config.FailedTestBehaviorDeduplicate: 2,
 	}
-	for _, filePath := range filePaths {
+	for failedTestBehavior, expectedFailures := range expectedDistinctFailures {
+		failedTestBehavior, expectedFailures := failedTestBehavior, expectedFailures
 		runFuzzerTest(t, &fuzzerSolcFileTest{
-			filePath: filePath,
+			filePath: "testdata/contracts/failures/distinct_failures.sol",
 			configUpdates: func(config *config.ProjectConfig) {
-				config.Fuzzing.DeploymentOrder = []string{"InnerDeploymentFactory"}
-				config.Fuzzing.TestLimit = 500 // this test should expose a failure quickly.
+				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+				config.Fuzzing.TestLimit = 5_000
+				config.Fuzzing.Testing.StopOnFailedTest = false
+				config.Fuzzing.Testing.FailedTestBehavior = failedTestBehavior
+				config.Fuzzing.Testing.PropertyTesting.Enabled = true
 			},
 			method: func(f *fuzzerTestContext) {
-				// Subscribe to any mined block events globally. When receiving them, check contract changes for a
-				// self-destruct.
-				selfDestructCount := 0
-				events.SubscribeAny(func(event chain.PendingBlockCommittedEvent) error {
-					for _, messageResults := range event.Block.MessageResults {
-						for _, contractDeploymentChange := range messageResults.ContractDeploymentChanges {
-							if contractDeploymentChange.SelfDestructed {
-								selfDestructCount++
-							}
-						}
-					}
-					return nil
-				})
-
 				// Start the fuzzer
 				err := f.fuzzer.Start()
 				assert.NoError(t, err)
 
-				// When it's done, we should've had at least one self-destruction.
-				assert.Greater(t, selfDestructCount, 0, "no SELFDESTRUCT operations were detected, when they should have been.")
+				// The property test should have failed, with each of its distinct failures recorded if it continued
+				// to be tested.
+				failedTestCases := f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)
+				if assert.EqualValues(t, 1, len(failedTestCases)) {
+					if expectedFailures == 1 {
+						assert.EqualValues(t, 1, f.fuzzer.DistinctTestCaseFailures(failedTestCases[0]))
+					} else {
+						assert.GreaterOrEqual(t, f.fuzzer.DistinctTestCaseFailures(failedTestCases[0]), expectedFailures)
+					}
+				}
+			},
+		})
+	}
+}
+
+// TestNeverRevertTests runs a test to ensure methods which must never revert fail their tests if they revert for any
+// reason, with the decoded revert reason reported in their results.
+func TestNeverRevertTests(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/never_revert/never_revert.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 5_000
+			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.NeverRevertTesting.Enabled = true
+			config.Fuzzing.Testing.NeverRevertTesting.Methods = []string{"TestContract.previewWithdraw(uint256)"}
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Each method which must never revert should have a test, and only those which can revert should fail.
+			assert.EqualValues(t, 3, len(f.fuzzer.TestCases()))
+			failedTestCases := f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)
+			assert.EqualValues(t, 2, len(failedTestCases))
+			for _, testCase := range failedTestCases {
+				if strings.Contains(testCase.Name(), "previewWithdraw") {
+					assert.Contains(t, testCase.Message(), "division or modulo by zero")
+				} else {
+					assert.Contains(t, testCase.Name(), "neverRevert_bounded")
+					assert.Contains(t, testCase.Message(), "value too large")
+				}
+			}
+		},
+	})
+}
+
+// TestInvariantPatterns runs a test to ensure view functions matching an invariant pattern are tested as property
+// tests, failing when they return false or revert.
+func TestInvariantPatterns(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/invariants/invariant_view_function.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 10_000
+			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.Testing.PropertyTesting.Enabled = true
+			config.Fuzzing.Testing.PropertyTesting.TestPrefixes = []string{}
+			config.Fuzzing.Testing.PropertyTesting.InvariantPatterns = []string{"^invariant_"}
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Both invariants should have been discovered and failed.
+			assert.EqualValues(t, 2, len(f.fuzzer.TestCases()))
+			assert.EqualValues(t, 2, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)), "Expected the false-returning and reverting invariants to fail")
+		},
+	})
+}
+
+// TestDifferentialTesting runs a test to ensure calls to a subject contract are mirrored to its reference contract,
+// keeping their states in sync, and that diverging return values fail the differential test.
+func TestDifferentialTesting(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/differential/diverging_implementations.sol",
+		configUpdates: func(projectConfig *config.ProjectConfig) {
+			projectConfig.Fuzzing.DeploymentOrder = []string{"ReferenceImplementation", "SubjectImplementation"}
+			projectConfig.Fuzzing.Testing.PropertyTesting.Enabled = false
+			projectConfig.Fuzzing.Testing.DifferentialTesting.Enabled = true
+			projectConfig.Fuzzing.Testing.DifferentialTesting.ContractPairs = []config.DifferentialContractPair{
+				{Subject: "SubjectImplementation", Reference: "ReferenceImplementation"},
+			}
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for failed differential tests.
+			assertFailedTestsExpected(f, true)
+
+			// Every call to the subject in the failing sequence should be mirrored to the reference.
+			testCase := f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)[0]
+			callSequence := *testCase.CallSequence()
+			assert.EqualValues(t, 0, len(callSequence)%2)
+			for i := 0; i+1 < len(callSequence); i += 2 {
+				assert.EqualValues(t, "SubjectImplementation", callSequence[i].Contract.Name())
+				assert.EqualValues(t, "ReferenceImplementation", callSequence[i+1].Contract.Name())
+				assert.EqualValues(t, callSequence[i].Call.Data(), callSequence[i+1].Call.Data())
+			}
+		},
+	})
+}
+
+// TestOptimizationTests runs a test to ensure optimization tests track the best value found for each of several
+// targets concurrently, whether maximizing or minimizing them, and persist the call sequence which reached it.
+func TestOptimizationTests(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/optimizations/optimize_max_min.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 10_000
+			config.Fuzzing.CorpusDirectory = "corpus"
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Each optimization test should have found its optimum value, and passed.
+			assert.EqualValues(t, 2, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusPassed)))
+			for _, testCase := range f.fuzzer.TestCases() {
+				optimizationTestCase, ok := testCase.(*OptimizationTestCase)
+				if assert.True(t, ok) {
+					expectedValue := big.NewInt(100)
+					if optimizationTestCase.Minimize() {
+						expectedValue = big.NewInt(-100)
+					}
+					assert.EqualValues(t, expectedValue, optimizationTestCase.Value())
+					assert.NotNil(t, optimizationTestCase.CallSequence())
+				}
+			}
+
+			// The call sequence reaching each best value should be stored in the corpus.
+			matches, err := filepath.Glob(filepath.Join(f.fuzzer.corpus.OptimizationCallSequencesDirectory(), "*.json"))
+			assert.NoError(t, err)
+			assert.EqualValues(t, 2, len(matches))
+		},
+	})
+}
+
+// TestGasOptimizationTests runs a test to ensure gas targets are tested as optimization tests, finding calls to the
+// target method which use the most gas.
+func TestGasOptimizationTests(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/optimizations/optimize_gas.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 10_000
+			config.Fuzzing.CorpusDirectory = "corpus"
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = true
+			config.Fuzzing.Testing.OptimizationTesting.GasTargets = []string{"TestContract.work"}
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// The gas optimization test should have passed, with its call sequence ending in a call to the target
+			// method which performs the most iterations.
+			testCases := f.fuzzer.TestCasesWithStatus(TestCaseStatusPassed)
+			assert.EqualValues(t, 1, len(testCases))
+			optimizationTestCase, ok := testCases[0].(*OptimizationTestCase)
+			if assert.True(t, ok) && assert.True(t, optimizationTestCase.GasTarget()) {
+				assert.NotNil(t, optimizationTestCase.Value())
+				callSequence := *optimizationTestCase.CallSequence()
+				lastCall := callSequence[len(callSequence)-1]
+				assert.EqualValues(t, "work", lastCall.Call.MsgDataAbiValues.Method.Name)
+				assert.GreaterOrEqual(t, lastCall.Call.MsgDataAbiValues.InputValues[0].(uint8), uint8(50))
+			}
+
+			// The call sequence using the most gas should be stored in the corpus.
+			matches, err := filepath.Glob(filepath.Join(f.fuzzer.corpus.OptimizationCallSequencesDirectory(), "*.json"))
+			assert.NoError(t, err)
+			assert.EqualValues(t, 1, len(matches))
+		},
+	})
+}
+
+// TestChainBehaviour runs tests to ensure the chain behaves as expected.
+func TestChainBehaviour(t *testing.T) {
+	// Run a test to simulate out of gas errors to make sure its handled well by the Chain and does not panic.
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/chain/tx_out_of_gas.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.Workers = 1
+			config.Fuzzing.TestLimit = uint64(config.Fuzzing.CallSequenceLength) // we just need a few oog txs to test
+			config.Fuzzing.Timeout = 10                                          // to be safe, we set a 10s timeout
+			config.Fuzzing.TransactionGasLimit = 500000                          // we set this low, so contract execution runs out of gas earlier.
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Assert that we should not have failures.
+			assertFailedTestsExpected(f, false)
+		},
+	})
+}
+
+// TestDynamicFeeTransactions runs a test to ensure calls are sent as EIP-1559 transactions which pay the base fee of
+// their block and a tip when dynamic fee transactions are enabled, and as legacy transactions otherwise.
+func TestDynamicFeeTransactions(t *testing.T) {
+	for _, dynamicFeeTransactions := range []bool{true, false} {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: "testdata/contracts/chain/dynamic_fee_transactions.sol",
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+				config.Fuzzing.TestLimit = 1_000
+				config.Fuzzing.TestChainConfig.BaseFeeConfig.Dynamic = true
+				config.Fuzzing.TransactionFees.DynamicFeeTransactions = dynamicFeeTransactions
+				config.Fuzzing.TransactionFees.MinMaxPriorityFeePerGas = 1
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Our properties should fail only if calls pay fees.
+				assertFailedTestsExpected(f, dynamicFeeTransactions)
+			},
+		})
+	}
+}
+
+// TestStorageGrowthSequences runs a test to ensure the call sequences which populate the most storage slots are
+// recorded and stored in the corpus when storage growth is recorded.
+func TestStorageGrowthSequences(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/chain/storage_growth.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 1_000
+			config.Fuzzing.CorpusDirectory = "corpus"
+			config.Fuzzing.StorageGrowth.Enabled = true
+			config.Fuzzing.StorageGrowth.TopSequences = 3
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// The contract's storage should have grown, and the sequences which grew it the most should be recorded,
+			// most slots first.
+			contracts := f.fuzzer.ContractStorageGrowth()
+			if assert.NotEmpty(t, contracts) {
+				assert.EqualValues(t, "TestContract", contracts[0].Contract)
+				assert.Greater(t, contracts[0].MaxNetNewSlots, int64(1))
+			}
+			sequences := f.fuzzer.StorageGrowthSequences()
+			assert.EqualValues(t, 3, len(sequences))
+			for i := 1; i < len(sequences); i++ {
+				assert.GreaterOrEqual(t, sequences[i-1].NetNewSlots, sequences[i].NetNewSlots)
+			}
+
+			// The call sequences should be stored in the corpus.
+			matches, err := filepath.Glob(filepath.Join(f.fuzzer.corpus.StorageGrowthCallSequencesDirectory(), "*.json"))
+			assert.NoError(t, err)
+			assert.EqualValues(t, 3, len(matches))
+		},
+	})
+}
+
+// TestCheatCodes runs tests to ensure that vm extensions ("cheat codes") are working as intended.
+func TestCheatCodes(t *testing.T) {
+	filePaths := []string{
+		"testdata/contracts/cheat_codes/utils/addr.sol",
+		"testdata/contracts/cheat_codes/utils/to_string.sol",
+		"testdata/contracts/cheat_codes/utils/sign.sol",
+		"testdata/contracts/cheat_codes/utils/signer_keys.sol",
+		"testdata/contracts/cheat_codes/utils/parse.sol",
+		"testdata/contracts/cheat_codes/utils/env.sol",
+		"testdata/contracts/cheat_codes/vm/coinbase.sol",
+		"testdata/contracts/cheat_codes/vm/chain_id.sol",
+		"testdata/contracts/cheat_codes/vm/deal.sol",
+		"testdata/contracts/cheat_codes/vm/difficulty.sol",
+		"testdata/contracts/cheat_codes/vm/prevrandao.sol",
+		"testdata/contracts/cheat_codes/vm/etch.sol",
+		"testdata/contracts/cheat_codes/vm/fee.sol",
+		"testdata/contracts/cheat_codes/vm/mock_call.sol",
+		"testdata/contracts/cheat_codes/vm/expect_emit.sol",
+		"testdata/contracts/cheat_codes/vm/prank.sol",
+		"testdata/contracts/cheat_codes/vm/roll.sol",
+		"testdata/contracts/cheat_codes/vm/snapshot.sol",
+		"testdata/contracts/cheat_codes/vm/store_load.sol",
+		"testdata/contracts/cheat_codes/vm/warp.sol",
+	}
+
+	// FFI test will fail on Windows because "echo" is a shell command, not a system command, so we diverge these
+	// tests.
+	if utils.IsWindowsEnvironment() {
+		filePaths = append(filePaths,
+			"testdata/contracts/cheat_codes/utils/ffi_windows.sol",
+		)
+	} else {
+		filePaths = append(filePaths,
+			"testdata/contracts/cheat_codes/utils/ffi_unix.sol",
+		)
+	}
+
+	for _, filePath := range filePaths {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: filePath,
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+
+				// some tests require full sequence + revert to test fully
+				config.Fuzzing.Workers = 3
+				config.Fuzzing.TestLimit = uint64(config.Fuzzing.CallSequenceLength*config.Fuzzing.Workers) * 3
+
+				// enable assertion testing only
+				config.Fuzzing.Testing.PropertyTesting.Enabled = false
+				config.Fuzzing.Testing.AssertionTesting.Enabled = true
+				config.Fuzzing.TestChainConfig.CheatCodeConfig.EnableFFI = true
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Check for failed assertion tests.
+				assertFailedTestsExpected(f, false)
+			},
+		})
+	}
+}
+
+// TestCheatCodesDealStoreProperties runs a test to ensure balances and storage set through cheat codes during
+// deployment persist across call sequences, while those set during a call sequence are reverted after it.
+func TestCheatCodesDealStoreProperties(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/cheat_codes/vm/deal_store_property.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 5_000
+			config.Fuzzing.Testing.PropertyTesting.Enabled = true
+			config.Fuzzing.Testing.AssertionTesting.Enabled = false
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for failed property tests. We expect none.
+			assertFailedTestsExpected(f, false)
+		},
+	})
+}
+
+// TestDeploymentsInnerDeployments runs tests to ensure dynamically deployed contracts are detected by the Fuzzer and
+// their properties are tested appropriately.
+func TestDeploymentsInnerDeployments(t *testing.T) {
+	// These contracts provide functions to deploy inner contracts which have properties that will produce a failure.
+	filePaths := []string{
+		"testdata/contracts/deployments/inner_deployment.sol",
+		"testdata/contracts/deployments/inner_inner_deployment.sol",
+	}
+	for _, filePath := range filePaths {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: filePath,
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"InnerDeploymentFactory"}
+				config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
+				config.Fuzzing.Testing.StopOnFailedContractMatching = true
+				config.Fuzzing.Testing.TestAllContracts = true // test dynamically deployed contracts
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Check for any failed tests and verify coverage was captured
+				assertFailedTestsExpected(f, true)
+				assertCorpusCallSequencesCollected(f, true)
+			},
+		})
+	}
+
+	// This contract deploys an inner contract upon construction, which contains properties that will produce a failure.
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/inner_deployment_on_construction.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"InnerDeploymentFactory"}
+			config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
+			config.Fuzzing.Testing.StopOnFailedContractMatching = true
+			config.Fuzzing.Testing.TestAllContracts = true // test dynamically deployed contracts
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check to see if there are any failures
+			assertFailedTestsExpected(f, true)
+		},
+	})
+}
+
+// TestDeploymentsEtchedDeployments runs a test to ensure code etched through cheat codes during deployment is matched
+// to its contract definition and tested.
+func TestDeploymentsEtchedDeployments(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/etched_deployment_on_construction.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"EtchedDeploymentFactory"}
+			config.Fuzzing.TestLimit = 1_000               // this test should expose a failure quickly.
+			config.Fuzzing.Testing.TestAllContracts = true // test dynamically deployed contracts
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check to see if there are any failures
+			assertFailedTestsExpected(f, true)
+		},
+	})
+}
+
+// TestDeploymentsImmutableDeployments runs tests to ensure contracts with immutable variables, deployed with CREATE2
+// or etched without an init code, are matched to their contract definition and tested.
+func TestDeploymentsImmutableDeployments(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/immutable_create2_deployment.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"ImmutableDeploymentFactory"}
+			config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
+			config.Fuzzing.Testing.StopOnFailedContractMatching = true
+			config.Fuzzing.Testing.TestAllContracts = true // test dynamically deployed contracts
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for any failed tests and verify coverage was captured
+			assertFailedTestsExpected(f, true)
+			assertCorpusCallSequencesCollected(f, true)
+		},
+	})
+}
+
+// TestDeploymentsCreate2Deployments runs a test to ensure contracts configured to be deployed through CREATE2 are
+// deployed through their factory, or the deterministic deployment proxy if none is specified.
+func TestDeploymentsCreate2Deployments(t *testing.T) {
+	factories := []string{"Create2Factory", ""}
+	for _, factory := range factories {
+		create2Deployments := map[string]config.Create2DeploymentConfig{
+			"Create2Deployment": {Salt: "0x01", Factory: factory},
+		}
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: "testdata/contracts/deployments/create2_deployment.sol",
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"Create2Factory", "Create2Deployment"}
+				config.Fuzzing.Create2Deployments = create2Deployments
+				config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Check for any failed tests and verify coverage was captured
+				assertFailedTestsExpected(f, true)
+				assertCorpusCallSequencesCollected(f, true)
+			},
+		})
+	}
+}
+
+// TestDeploymentsCreate2AddressPrediction runs a test to ensure the addresses of contracts created through CREATE2
+// during fuzzing are provided as arguments in later call sequences, before the contracts are created again.
+func TestDeploymentsCreate2AddressPrediction(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/create2_address_prediction.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"Create2AddressPrediction"}
+			config.Fuzzing.TestLimit = 10_000 // this test should expose a failure quickly.
+			config.Fuzzing.CallSequenceLength = 10
+			// Our address should only be learned through CREATE2, as it is returned or compared by no call.
+			config.Fuzzing.ValueSetSeeding.RuntimeValues = false
+			config.Fuzzing.ValueSetSeeding.ComparisonValues = false
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for any failed tests and verify coverage was captured
+			assertFailedTestsExpected(f, true)
+			assertCorpusCallSequencesCollected(f, true)
+		},
+	})
+}
+
+// TestDeploymentsProxyImplementations runs a test to ensure the methods of the implementations of proxies are called
+// through them, whether the implementation is stored in the proxy's EIP-1967 implementation slot, or configured.
+func TestDeploymentsProxyImplementations(t *testing.T) {
+	proxyImplementations := map[string]map[string]string{
+		"EIP1967Proxy": {},
+		"CustomProxy":  {"CustomProxy": "FlagImplementation"},
+	}
+	for proxyName, configuredImplementations := range proxyImplementations {
+		proxyName, configuredImplementations := proxyName, configuredImplementations
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: "testdata/contracts/deployments/proxy_implementation.sol",
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"FlagImplementation", proxyName}
+				config.Fuzzing.ConstructorArgs = map[string]map[string]any{
+					proxyName: {"_implementation": "DeployedContract:FlagImplementation"},
+				}
+				config.Fuzzing.ProxyImplementations = configuredImplementations
+				config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Check for any failed tests and verify coverage was captured
+				assertFailedTestsExpected(f, true)
+				assertCorpusCallSequencesCollected(f, true)
+			},
+		})
+	}
+}
+
+// TestDeploymentsInternalLibrary runs a test to ensure internal libraries behave correctly.
+func TestDeploymentsInternalLibrary(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/internal_library.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestInternalLibrary"}
+			config.Fuzzing.TestLimit = 100 // this test should expose a failure quickly.
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for any failed tests and verify coverage was captured
+			assertFailedTestsExpected(f, false)
+			assertCorpusCallSequencesCollected(f, true)
+		},
+	})
+}
+
+// TestDeploymentsExternalLibrary runs a test to ensure external libraries are deployed and linked before the contracts
+// which reference them, including libraries which reference other libraries, and are not called directly.
+func TestDeploymentsExternalLibrary(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/external_library.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestExternalLibrary"}
+			config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Our libraries should have been deployed in the order they reference each other.
+			libraryNames := make([]string, 0)
+			for _, library := range f.fuzzer.libraryContracts {
+				libraryNames = append(libraryNames, library.Name())
+			}
+			assert.EqualValues(t, []string{"MathLibrary", "WrappedMathLibrary"}, libraryNames)
+
+			// Calls to our library should succeed, without producing incorrect results.
+			assertFailedTestsExpected(f, true)
+			for _, testCase := range f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed) {
+				assert.Contains(t, testCase.Name(), "fuzz_library_called")
+			}
+		},
+	})
+}
+
+// TestDeploymentsInnerDeployments runs a test to ensure dynamically deployed contracts are detected by the Fuzzer and
+// their properties are tested appropriately.
+func TestDeploymentsSelfDestruct(t *testing.T) {
+	// These contracts provide functions to deploy inner contracts which have properties that will produce a failure.
+	filePaths := []string{
+		"testdata/contracts/deployments/selfdestruct_init.sol",
+		"testdata/contracts/deployments/selfdestruct_runtime.sol",
+	}
+	for _, filePath := range filePaths {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: filePath,
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"InnerDeploymentFactory"}
+				config.Fuzzing.TestLimit = 500 // this test should expose a failure quickly.
+			},
+			method: func(f *fuzzerTestContext) {
+				// Subscribe to any mined block events globally. When receiving them, check contract changes for a
+				// self-destruct.
+				selfDestructCount := 0
+				events.SubscribeAny(func(event chain.PendingBlockCommittedEvent) error {
+					for _, messageResults := range event.Block.MessageResults {
+						for _, contractDeploymentChange := range messageResults.ContractDeploymentChanges {
+							if contractDeploymentChange.SelfDestructed {
+								selfDestructCount++
+							}
+						}
+					}
+					return nil
+				})
+
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// When it's done, we should've had at least one self-destruction.
+				assert.Greater(t, selfDestructCount, 0, "no SELFDESTRUCT operations were detected, when they should have been.")
+			},
+		})
+	}
+}
+
+// TestExecutionTraces runs tests to ensure that execution traces capture information
+// regarding assertion failures, revert reasons, etc.
+func TestExecutionTraces(t *testing.T) {
+	expectedMessagesPerTest := map[string][]string{
+		"testdata/contracts/execution_tracing/call_and_deployment_args.sol": {"Hello from deployment args!", "Hello from call args!"},
+		"testdata/contracts/execution_tracing/cheatcodes.sol":               {"StdCheats.toString(true)"},
+		"testdata/contracts/execution_tracing/console_log.sol":              {"[console.log] Hello from console.log!", "[console.log] x is "},
+		"testdata/contracts/execution_tracing/event_emission.sol":           {"TestEvent", "TestIndexedEvent", "TestMixedEvent", "Hello from event args!", "Hello from library event args!"},
+		"testdata/contracts/execution_tracing/proxy_call.sol":               {"TestContract -> InnerDeploymentContract.setXY", "Hello from proxy call args!"},
+		"testdata/contracts/execution_tracing/revert_custom_error.sol":      {"CustomError", "Hello from a custom error!"},
+		"testdata/contracts/execution_tracing/revert_panic.sol":             {"[panic: arithmetic underflow or overflow (code: 0x11)]"},
+		"testdata/contracts/execution_tracing/revert_reasons.sol":           {"RevertingContract was called and reverted."},
+		"testdata/contracts/execution_tracing/self_destruct.sol":            {"[selfdestruct]", "[assertion failed]"},
+	}
+	for filePath, expectedTraceMessages := range expectedMessagesPerTest {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: filePath,
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+				config.Fuzzing.Testing.PropertyTesting.Enabled = false
+				config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Check for failed assertion tests.
+				failedTestCase := f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)
+				assert.NotEmpty(t, failedTestCase, "expected to have failed test cases")
+
+				// Obtain our first failed test case, get the message, and verify it contains our assertion failed.
+				failingSequence := *failedTestCase[0].CallSequence()
+				assert.NotEmpty(t, failingSequence, "expected to have calls in the call sequence failing an assertion test")
+
+				// Obtain the last call
+				lastCall := failingSequence[len(failingSequence)-1]
+				assert.NotNilf(t, lastCall.ExecutionTrace, "expected to have an execution trace attached to call sequence for this test")
+
+				// Get the execution trace message
+				executionTraceMsg := lastCall.ExecutionTrace.String()
+
+				// Verify it contains all expected strings
+				for _, expectedTraceMessage := range expectedTraceMessages {
+					assert.Contains(t, executionTraceMsg, expectedTraceMessage)
+				}
+			},
+		})
+	}
+}
+
+// TestExecutionTracesStorageWrites runs a test to ensure that execution traces record storage writes, with the values
+// written, when the trace verbosity is configured to include them.
+func TestExecutionTracesStorageWrites(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/execution_tracing/storage_writes.sol",
+		configUpdates: func(projectConfig *config.ProjectConfig) {
+			projectConfig.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			projectConfig.Fuzzing.Testing.PropertyTesting.Enabled = false
+			projectConfig.Fuzzing.Testing.AssertionTesting.Enabled = true
+			projectConfig.Fuzzing.Testing.TraceVerbosity = config.TraceVerbosityStorageWrites
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for failed assertion tests.
+			failedTestCase := f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)
+			assert.NotEmpty(t, failedTestCase, "expected to have failed test cases")
+			failingSequence := *failedTestCase[0].CallSequence()
+			assert.NotEmpty(t, failingSequence, "expected to have calls in the call sequence failing an assertion test")
+
+			// Verify the trace of the last call records the write of the failing value to the first storage slot.
+			lastCall := failingSequence[len(failingSequence)-1]
+			assert.NotNilf(t, lastCall.ExecutionTrace, "expected to have an execution trace attached to call sequence for this test")
+			executionTraceMsg := lastCall.ExecutionTrace.String()
+			assert.Contains(t, executionTraceMsg, "[storage write] slot="+common.Hash{}.String())
+			assert.Contains(t, executionTraceMsg, "value="+common.BigToHash(big.NewInt(7)).String())
+		},
+	})
+}
+
+// TestTestingScope runs tests to ensure dynamically deployed contracts are tested when the "test all contracts"
+// config option is specified. It also runs the fuzzer without the option enabled to ensure they are not tested.
+func TestTestingScope(t *testing.T) {
+	for _, testingAllContracts := range []bool{false, true} {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: "testdata/contracts/deployments/testing_scope.sol",
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+				config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
+				config.Fuzzing.Testing.TestAllContracts = testingAllContracts
+				config.Fuzzing.Testing.StopOnFailedTest = false
+				config.Fuzzing.Testing.AssertionTesting.Enabled = true
+				config.Fuzzing.Testing.PropertyTesting.Enabled = true
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Define our expected failure count
+				var expectedFailureCount int
+				if testingAllContracts {
+					expectedFailureCount = 4
+				} else {
+					expectedFailureCount = 2
+				}
+
+				// Check for any failed tests and verify coverage was captured
+				assert.EqualValues(t, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)), expectedFailureCount)
+			},
+		})
+	}
+}
+
+// TestDeploymentsWithArgs runs tests to ensure contracts deployed with config provided constructor arguments are
+// deployed as expected. It expects all properties should fail (indicating values provided were set accordingly).
+func TestDeploymentsWithArgs(t *testing.T) {
+	// This contract deploys a contract with specific constructor arguments. Property tests will fail if they are
+	// set correctly.
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/deployment_with_args.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"DeploymentWithArgs", "Dependent"}
+			config.Fuzzing.ConstructorArgs = map[string]map[string]any{
+				"DeploymentWithArgs": {
+					"_x": "123456789",
+					"_y": "0x5465",
+					"_z": map[string]any{
+						"a": "0x4d2",
+						"b": "0x54657374206465706c6f796d656e74207769746820617267756d656e7473",
+					},
+				},
+				"Dependent": {
+					"_deployed": "DeployedContract:DeploymentWithArgs",
+				},
+			}
+			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.TestLimit = 500 // this test should expose a failure quickly.
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check to see if there are any failures
+			assert.EqualValues(t, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)), 4)
+		},
+	})
+}
+
+// TestDeploymentsWithReferences runs a test to ensure constructor arguments can reference contracts deployed earlier
+// in the deployment order, including references nested within arrays and structs, and that references to contracts
+// which have not been deployed yet are reported as errors.
+func TestDeploymentsWithReferences(t *testing.T) {
+	constructorArgs := map[string]map[string]any{
+		"Pool": {
+			"_token":   "DeployedContract:Token",
+			"_oracles": []any{"DeployedContract:Oracle", "DeployedContract:Token"},
+			"_config": map[string]any{
+				"token":   "DeployedContract:Token",
+				"oracles": []any{"DeployedContract:Oracle"},
+			},
+		},
+	}
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/deployment_with_references.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"Token", "Oracle", "Pool"}
+			config.Fuzzing.ConstructorArgs = constructorArgs
+			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.TestLimit = 500 // this test should expose a failure quickly.
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check to see if there are any failures
+			assert.EqualValues(t, 3, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)))
+		},
+	})
+
+	// Deploying a contract before the contracts it references should fail.
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/deployment_with_references.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"Token", "Pool", "Oracle"}
+			config.Fuzzing.ConstructorArgs = constructorArgs
+			config.Fuzzing.TestLimit = 500
+		},
+		method: func(f *fuzzerTestContext) {
+			err := f.fuzzer.Start()
+			assert.ErrorContains(t, err, "not deployed before it")
+		},
+	})
+}
+
+// TestDeploymentsWithSetupContract runs a test to ensure contracts deployed and configured by a setup contract are
+// discovered and tested, and that the deployment order can be used to limit which of them are tested.
+func TestDeploymentsWithSetupContract(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/setup_contract.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.SetupContract = "Setup"
+			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.TestLimit = 500 // this test should expose a failure quickly.
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Every contract created during setup should be tested, and the vault properties should fail.
+			assert.ElementsMatch(t, []string{"Setup", "Token", "Vault"}, f.fuzzer.config.Fuzzing.DeploymentOrder)
+			assert.EqualValues(t, 2, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)))
+		},
+	})
+
+	// Limit testing to the contracts in the deployment order.
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/setup_contract.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.SetupContract = "Setup"
+			config.Fuzzing.DeploymentOrder = []string{"Setup", "Token"}
+			config.Fuzzing.TestLimit = 500
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// The vault is not tested, so there should be no failures.
+			assert.EqualValues(t, 0, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)))
+		},
+	})
+}
+
+// TestMethodFiltering runs tests to ensure function signature filters restrict the methods the fuzzer calls, and
+// that filters which leave no methods to call are reported as errors.
+func TestMethodFiltering(t *testing.T) {
+	filterTests := []struct {
+		include        []string
+		exclude        []string
+		failedTests    int
+		expectStartErr bool
+	}{
+		// Exclude methods by contract-qualified signature and by regular expression.
+		{exclude: []string{"TestContract.unlock()", `TestContract\.admin_.*`}, failedTests: 1},
+		// Include methods by signature.
+		{include: []string{"unlock()", "setValue(uint256)"}, failedTests: 2},
+		// Include no existing methods.
+		{include: []string{"missing()"}, expectStartErr: true},
+	}
+	for _, filterTest := range filterTests {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: "testdata/contracts/method_filtering/method_filtering.sol",
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+				config.Fuzzing.IncludeFunctionSignatures = filterTest.include
+				config.Fuzzing.ExcludeFunctionSignatures = filterTest.exclude
+				config.Fuzzing.Testing.StopOnFailedTest = false
+				config.Fuzzing.TestLimit = 1_000
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				if filterTest.expectStartErr {
+					assert.Error(t, err)
+					return
+				}
+				assert.NoError(t, err)
+
+				// Check that only properties broken by the methods we may call failed
+				assert.EqualValues(t, filterTest.failedTests, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)))
+			},
+		})
+	}
+}
+
+// TestSenderRoles runs tests to ensure senders can be configured with starting balances and roles, that methods
+// restricted to certain roles are only called by senders with them, and that roles are reported alongside senders.
+func TestSenderRoles(t *testing.T) {
+	senders := []config.SenderConfig{
+		{Address: "0x40000", Role: "admin"},
+		{Address: "0x50000", Balance: "1234", Role: "attacker"},
+	}
+	for _, allowedRole := range []string{"attacker", "admin"} {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: "testdata/contracts/senders/sender_roles.sol",
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+				config.Fuzzing.Senders = senders
+				config.Fuzzing.MethodRoles = map[string][]string{"privileged()": {allowedRole}}
+				config.Fuzzing.Testing.StopOnFailedTest = false
+				config.Fuzzing.TestLimit = 1_000
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// The attacker balance property should always fail, while the privileged method should only be called
+				// by the admin if it is allowed to.
+				failedTestCases := f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)
+				if allowedRole == "admin" {
+					assert.EqualValues(t, 2, len(failedTestCases))
+					for _, testCase := range failedTestCases {
+						if strings.Contains(testCase.Name(), "fuzz_adminNeverCalledPrivileged") {
+							assert.Contains(t, testCase.Message(), "(admin)")
+						}
+					}
+				} else {
+					assert.EqualValues(t, 1, len(failedTestCases))
+				}
+			},
+		})
+	}
+}
+
+// TestAgentContracts runs a test to ensure calls can be routed through agent contracts, so callbacks made to the
+// sender of a call can re-enter the contract which made them.
+func TestAgentContracts(t *testing.T) {
+	for _, agentContracts := range [][]string{{"Agent"}, {}} {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: "testdata/contracts/agents/reentrancy_agent.sol",
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"Bank"}
+				config.Fuzzing.AgentContracts = agentContracts
+				config.Fuzzing.TestLimit = 1_000
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// The bank should only be re-entered if calls may be routed through the agent, and the failing call
+				// should be reported as the call to the bank made by the agent.
+				assertFailedTestsExpected(f, len(agentContracts) > 0)
+				for _, testCase := range f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed) {
+					assert.Contains(t, testCase.Message(), "Bank.withdraw()")
+					assert.Contains(t, testCase.Message(), "via agent")
+				}
 			},
 		})
 	}
 }
 
-// TestExecutionTraces runs tests to ensure that execution traces capture information
-// regarding assertion failures, revert reasons, etc.
-func TestExecutionTraces(t *testing.T) {
-	expectedMessagesPerTest := map[string][]string{
-		"testdata/contracts/execution_tracing/call_and_deployment_args.sol": {"Hello from deployment args!", "Hello from call args!"},
-		"testdata/contracts/execution_tracing/cheatcodes.sol":               {"StdCheats.toString(true)"},
-		"testdata/contracts/execution_tracing/event_emission.sol":           {"TestEvent", "TestIndexedEvent", "TestMixedEvent", "Hello from event args!", "Hello from library event args!"},
-		"testdata/contracts/execution_tracing/proxy_call.sol":               {"TestContract -> InnerDeploymentContract.setXY", "Hello from proxy call args!"},
-		"testdata/contracts/execution_tracing/revert_custom_error.sol":      {"CustomError", "Hello from a custom error!"},
-		"testdata/contracts/execution_tracing/revert_reasons.sol":           {"RevertingContract was called and reverted."},
-		"testdata/contracts/execution_tracing/self_destruct.sol":            {"[selfdestruct]", "[assertion failed]"},
-	}
-	for filePath, expectedTraceMessages := range expectedMessagesPerTest {
+// TestAssertionsOutOfGas runs a test to ensure assertion failures caused by calls running out of gas with fuzzed gas
+// limits are only reported when running out of gas is configured to be treated as a failure.
+func TestAssertionsOutOfGas(t *testing.T) {
+	for _, treatOOGAsFailure := range []bool{true, false} {
 		runFuzzerTest(t, &fuzzerSolcFileTest{
-			filePath: filePath,
+			filePath: "testdata/contracts/assertions/assert_out_of_gas.sol",
 			configUpdates: func(config *config.ProjectConfig) {
 				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+				config.Fuzzing.FuzzTransactionGasLimits = true
+				config.Fuzzing.Testing.TreatOOGAsFailure = treatOOGAsFailure
 				config.Fuzzing.Testing.PropertyTesting.Enabled = false
 				config.Fuzzing.Testing.AssertionTesting.Enabled = true
+				config.Fuzzing.TestLimit = 1_000
 			},
 			method: func(f *fuzzerTestContext) {
 				// Start the fuzzer
 				err := f.fuzzer.Start()
 				assert.NoError(t, err)
 
-				// Check for failed assertion tests.
-				failedTestCase := f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)
-				assert.NotEmpty(t, failedTestCase, "expected to have failed test cases")
-
-				// Obtain our first failed test case, get the message, and verify it contains our assertion failed.
-				failingSequence := *failedTestCase[0].CallSequence()
-				assert.NotEmpty(t, failingSequence, "expected to have calls in the call sequence failing an assertion test")
-
-				// Obtain the last call
-				lastCall := failingSequence[len(failingSequence)-1]
-				assert.NotNilf(t, lastCall.ExecutionTrace, "expected to have an execution trace attached to call sequence for this test")
-
-				// Get the execution trace message
-				executionTraceMsg := lastCall.ExecutionTrace.String()
-
-				// Verify it contains all expected strings
-				for _, expectedTraceMessage := range expectedTraceMessages {
-					assert.Contains(t, executionTraceMsg, expectedTraceMessage)
-				}
+				// Calls should have run out of gas either way, but only be reported if configured to.
+				assert.Positive(t, f.fuzzer.metrics.CallsOutOfGas().Sign())
+				assertFailedTestsExpected(f, treatOOGAsFailure)
 			},
 		})
 	}
 }
 
-// TestTestingScope runs tests to ensure dynamically deployed contracts are tested when the "test all contracts"
-// config option is specified. It also runs the fuzzer without the option enabled to ensure they are not tested.
-func TestTestingScope(t *testing.T) {
-	for _, testingAllContracts := range []bool{false, true} {
+// TestStopConditions runs a test to ensure campaigns stop once they reach their call or sequence limit, or find no new
+// coverage for the configured duration, reporting the condition which stopped them.
+func TestStopConditions(t *testing.T) {
+	tests := []struct {
+		configUpdates      func(config *config.ProjectConfig)
+		expectedStopReason StopReason
+	}{
+		{
+			configUpdates:      func(config *config.ProjectConfig) { config.Fuzzing.CallLimit = 500 },
+			expectedStopReason: StopReasonCallLimit,
+		},
+		{
+			configUpdates:      func(config *config.ProjectConfig) { config.Fuzzing.SequenceLimit = 20 },
+			expectedStopReason: StopReasonSequenceLimit,
+		},
+		{
+			configUpdates:      func(config *config.ProjectConfig) { config.Fuzzing.StopAfterNoNewCoverage = "1s" },
+			expectedStopReason: StopReasonNoNewCoverage,
+		},
+		{
+			configUpdates:      func(config *config.ProjectConfig) { config.Fuzzing.Timeout = 1 },
+			expectedStopReason: StopReasonTimeout,
+		},
+	}
+	for _, test := range tests {
 		runFuzzerTest(t, &fuzzerSolcFileTest{
-			filePath: "testdata/contracts/deployments/testing_scope.sol",
+			filePath: "testdata/contracts/assertions/assert_not_require.sol",
 			configUpdates: func(config *config.ProjectConfig) {
 				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
-				config.Fuzzing.TestLimit = 1_000 // this test should expose a failure quickly.
-				config.Fuzzing.Testing.TestAllContracts = testingAllContracts
-				config.Fuzzing.Testing.StopOnFailedTest = false
+				config.Fuzzing.CallSequenceLength = 10
+				config.Fuzzing.Testing.PropertyTesting.Enabled = false
 				config.Fuzzing.Testing.AssertionTesting.Enabled = true
-				config.Fuzzing.Testing.PropertyTesting.Enabled = true
+				test.configUpdates(config)
 			},
 			method: func(f *fuzzerTestContext) {
 				// Start the fuzzer
 				err := f.fuzzer.Start()
 				assert.NoError(t, err)
 
-				// Define our expected failure count
-				var expectedFailureCount int
-				if testingAllContracts {
-					expectedFailureCount = 4
-				} else {
-					expectedFailureCount = 2
+				// Check the campaign stopped for the expected reason, once it reached the limit stopping it.
+				assert.EqualValues(t, test.expectedStopReason, f.fuzzer.StopReason())
+				switch test.expectedStopReason {
+				case StopReasonCallLimit:
+					assert.GreaterOrEqual(t, f.fuzzer.metrics.CallsTested().Uint64(), f.fuzzer.config.Fuzzing.CallLimit)
+				case StopReasonSequenceLimit:
+					assert.GreaterOrEqual(t, f.fuzzer.metrics.SequencesTested().Uint64(), f.fuzzer.config.Fuzzing.SequenceLimit)
 				}
-
-				// Check for any failed tests and verify coverage was captured
-				assert.EqualValues(t, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)), expectedFailureCount)
 			},
 		})
 	}
 }
 
-// TestDeploymentsWithArgs runs tests to ensure contracts deployed with config provided constructor arguments are
-// deployed as expected. It expects all properties should fail (indicating values provided were set accordingly).
-func TestDeploymentsWithArgs(t *testing.T) {
-	// This contract deploys a contract with specific constructor arguments. Property tests will fail if they are
-	// set correctly.
+// TestGracefulStopShrinksFailures runs a test to ensure a campaign which is stopped gracefully, by cancelling the
+// context it was started with while a failing call sequence is being shrunk, finishes shrinking and reporting it, and
+// writes its corpus before returning.
+func TestGracefulStopShrinksFailures(t *testing.T) {
 	runFuzzerTest(t, &fuzzerSolcFileTest{
-		filePath: "testdata/contracts/deployments/deployment_with_args.sol",
+		filePath: "testdata/contracts/assertions/assert_shrink_call_arguments.sol",
 		configUpdates: func(config *config.ProjectConfig) {
-			config.Fuzzing.DeploymentOrder = []string{"DeploymentWithArgs", "Dependent"}
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.CorpusDirectory = "corpus"
+			config.Fuzzing.TestLimit = 100_000
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Cancel our context as soon as a worker begins shrinking a failing call sequence.
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				for ctx.Err() == nil {
+					f.fuzzer.unshrunkCallSequencesLock.Lock()
+					shrinking := len(f.fuzzer.unshrunkCallSequences) > 0
+					f.fuzzer.unshrunkCallSequencesLock.Unlock()
+					if shrinking {
+						cancel()
+						return
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}()
+
+			// Start the fuzzer, which should stop once our context is cancelled.
+			err := f.fuzzer.StartWithContext(ctx)
+			assert.NoError(t, err)
+			assert.EqualValues(t, StopReasonInterrupted, f.fuzzer.StopReason())
+
+			// The failure should have been shrunk down to the single call which fails.
+			assertFailedTestsExpected(f, true)
+			for _, testCase := range f.fuzzer.TestCases() {
+				if testCase.Status() == TestCaseStatusFailed {
+					assert.NotNil(t, testCase.CallSequence())
+					assert.Len(t, *testCase.CallSequence(), 1)
+				}
+			}
+
+			// Our corpus should have been written, without leaving any partially written files behind.
+			assert.FileExists(t, f.fuzzer.corpus.VersionFilePath())
+			matches, err := filepath.Glob(filepath.Join(f.fuzzer.corpus.CallSequencesDirectory(), ".tmp-*"))
+			assert.NoError(t, err)
+			assert.Empty(t, matches)
+		},
+	})
+}
+
+// TestDeploymentsWithFuzzedArgs runs a test to ensure contracts can be deployed with constructor arguments generated
+// by the fuzzer alongside config-provided ones, that the generated values are reported with failures, and that they
+// can be replayed by a later campaign.
+func TestDeploymentsWithFuzzedArgs(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/deployments/deployment_with_fuzzed_args.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"DeploymentWithFuzzedArgs"}
 			config.Fuzzing.ConstructorArgs = map[string]map[string]any{
-				"DeploymentWithArgs": {
-					"_x": "123456789",
-					"_y": "0x5465",
-					"_z": map[string]any{
-						"a": "0x4d2",
-						"b": "0x54657374206465706c6f796d656e74207769746820617267756d656e7473",
-					},
-				},
-				"Dependent": {
-					"_deployed": "DeployedContract:DeploymentWithArgs",
+				"DeploymentWithFuzzedArgs": {
+					"_owner": "0x0000000000000000000000000000000000001234",
 				},
 			}
-			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.FuzzedConstructorArgs = map[string][]string{
+				"DeploymentWithFuzzedArgs": {"_fee"},
+			}
+			config.Fuzzing.CorpusDirectory = "corpus"
 			config.Fuzzing.TestLimit = 500 // this test should expose a failure quickly.
 		},
 		method: func(f *fuzzerTestContext) {
@@ -431,8 +1579,27 @@ func TestDeploymentsWithArgs(t *testing.T) {
 			err := f.fuzzer.Start()
 			assert.NoError(t, err)
 
-			// Check to see if there are any failures
-			assert.EqualValues(t, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)), 4)
+			// Only the fuzzed argument should have been generated.
+			fuzzedArgs := f.fuzzer.FuzzedConstructorArgs()["DeploymentWithFuzzedArgs"]
+			assert.Contains(t, fuzzedArgs, "_fee")
+			assert.NotContains(t, fuzzedArgs, "_owner")
+
+			// The config-provided argument should have been used, failing the property test, and the generated
+			// argument should be reported with the failure.
+			failedTestCases := f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)
+			if assert.EqualValues(t, 1, len(failedTestCases)) {
+				assert.Contains(t, f.fuzzer.testCaseResultMessage(failedTestCases[0]), "_fee")
+			}
+
+			// The generated argument should have been recorded in the corpus directory, and should be replayed by a
+			// campaign with another seed if configured to.
+			assert.FileExists(t, f.fuzzer.corpus.ConstructorArgsFilePath())
+			seed := f.fuzzer.seed + 1
+			f.fuzzer.config.Fuzzing.Seed = &seed
+			f.fuzzer.config.Fuzzing.ReplayFuzzedConstructorArgs = true
+			err = f.fuzzer.Start()
+			assert.NoError(t, err)
+			assert.EqualValues(t, fuzzedArgs, f.fuzzer.FuzzedConstructorArgs()["DeploymentWithFuzzedArgs"])
 		},
 	})
 }
@@ -457,6 +1624,73 @@ func TestValueGenerationGenerateAllTypes(t *testing.T) {
 	})
 }
 
+// TestValueGenerationEnumArguments runs a test to ensure enum arguments are generally generated within the range of
+// their members, as determined from the compilation AST, including the elements of enum array arguments.
+func TestValueGenerationEnumArguments(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/value_generation/enum_arguments.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 5_000
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+		},
+		method: func(f *fuzzerTestContext) {
+			// Define the enum bounds we expect for the inputs of each method.
+			colorBounds := &compilationTypes.EnumBounds{MemberCount: 3}
+			sizeBounds := &compilationTypes.EnumBounds{MemberCount: 5}
+			expectedEnumBounds := map[string][]*compilationTypes.EnumBounds{
+				"setColor":        {colorBounds},
+				"setSize":         {sizeBounds},
+				"setColorAndSize": {colorBounds, nil, sizeBounds},
+				"setColors":       {{Elem: colorBounds}},
+			}
+
+			// Track how many enum arguments we saw, and how many were out of range.
+			var enumArguments, outOfRangeEnumArguments int
+			f.fuzzer.Hooks.CallSequenceTestFuncs = append(f.fuzzer.Hooks.CallSequenceTestFuncs, func(worker *FuzzerWorker, callSequence calls.CallSequence) ([]ShrinkCallSequenceRequest, error) {
+				lastElement := callSequence[len(callSequence)-1]
+				if lastElement.Call.MsgDataAbiValues == nil {
+					return make([]ShrinkCallSequenceRequest, 0), nil
+				}
+
+				// Verify our contract resolved the enum bounds for this method.
+				method := lastElement.Call.MsgDataAbiValues.Method
+				expectedBounds := expectedEnumBounds[method.Name]
+				assert.EqualValues(t, expectedBounds, lastElement.Contract.MethodInputEnumBounds(method))
+
+				// Count our enum arguments, including the elements of enum arrays, and check their ranges.
+				for i, inputValue := range lastElement.Call.MsgDataAbiValues.InputValues {
+					if expectedBounds[i] == nil {
+						continue
+					}
+					var enumValues []uint8
+					memberCount := expectedBounds[i].MemberCount
+					if elemBounds := expectedBounds[i].Elem; elemBounds != nil {
+						enumValues, memberCount = inputValue.([]uint8), elemBounds.MemberCount
+					} else {
+						enumValues = []uint8{inputValue.(uint8)}
+					}
+					for _, enumValue := range enumValues {
+						enumArguments++
+						if int(enumValue) >= memberCount {
+							outOfRangeEnumArguments++
+						}
+					}
+				}
+				return make([]ShrinkCallSequenceRequest, 0), nil
+			})
+
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Most enum arguments should have been within range.
+			assert.Greater(t, enumArguments, 0, "no enum arguments were verified")
+			assert.Less(t, outOfRangeEnumArguments, enumArguments/4, "too many out-of-range enum arguments were generated")
+		},
+	})
+}
+
 // TestValueGenerationSolving runs a series of tests to test the value generator can solve expected problems.
 func TestValueGenerationSolving(t *testing.T) {
 	// TODO: match_ints_xy is slower than match_uints_xy in the value generator because AST doesn't retain negative
@@ -470,6 +1704,10 @@ func TestValueGenerationSolving(t *testing.T) {
 		//"testdata/contracts/value_generation/match_ints_xy.sol",
 		"testdata/contracts/value_generation/match_uints_xy.sol",
 		"testdata/contracts/value_generation/match_payable_xy.sol",
+		"testdata/contracts/value_generation/match_signature_vrs.sol",
+		"testdata/contracts/value_generation/match_signature_bytes.sol",
+		"testdata/contracts/value_generation/match_timestamp_deadline.sol",
+		"testdata/contracts/value_generation/match_strings_self_copy.sol",
 	}
 	for _, filePath := range filePaths {
 		runFuzzerTest(t, &fuzzerSolcFileTest{
@@ -490,6 +1728,50 @@ func TestValueGenerationSolving(t *testing.T) {
 	}
 }
 
+// TestValueGenerationComparisonValues ensures the fuzzer can open a check against a magic value computed at runtime
+// within a small test limit when comparison values are enabled, and that it cannot do so when they are disabled.
+func TestValueGenerationComparisonValues(t *testing.T) {
+	for _, comparisonValues := range []bool{true, false} {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: "testdata/contracts/value_generation/match_comparison_magic_value.sol",
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+				config.Fuzzing.TestLimit = 10_000
+				config.Fuzzing.ValueSetSeeding.ComparisonValues = comparisonValues
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Check the magic value was only found if comparison values were enabled
+				assertFailedTestsExpected(f, comparisonValues)
+			},
+		})
+	}
+}
+
+// TestValueGenerationPayableBalances runs a test to ensure payable calls are sent values derived from the balance of
+// their sender, and round amounts of ether which arbitrary integers would rarely match.
+func TestValueGenerationPayableBalances(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/value_generation/match_payable_balance.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.TestLimit = 10_000
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Both the round amount and full balance properties should fail
+			assert.EqualValues(t, 2, len(f.fuzzer.TestCasesWithStatus(TestCaseStatusFailed)))
+		},
+	})
+}
+
 // TestVMCorrectness runs tests to ensure block properties are reported consistently within the EVM, as it's configured
 // by the chain.TestChain.
 func TestVMCorrectness(t *testing.T) {
@@ -599,6 +1881,206 @@ func TestCorpusReplayability(t *testing.T) {
 	})
 }
 
+// TestReplayFailureFile ensures a failure file is written for a failed assertion test, and that replaying it on a
+// freshly deployed chain reproduces the failure with execution traces attached.
+func TestReplayFailureFile(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/assertions/assert_immediate.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+			assertFailedTestsExpected(f, true)
+
+			// Obtain the failure file written for our failed test.
+			filePaths, err := filepath.Glob(filepath.Join(f.fuzzer.config.Fuzzing.Testing.FailuresDirectory, "*.json"))
+			assert.NoError(t, err)
+			assert.Len(t, filePaths, 1)
+			failure, err := ReadFailureFile(filePaths[0])
+			assert.NoError(t, err)
+			assert.EqualValues(t, "assertion", failure.TestKind)
+			assert.EqualValues(t, "TestContract", failure.Contract)
+			assert.NotEmpty(t, failure.CallSequence)
+
+			// Replay our failure, which should reproduce it with execution traces attached.
+			replay, err := f.fuzzer.ReplayFailureFile(filePaths[0])
+			assert.NoError(t, err)
+			assert.True(t, replay.Reproduced)
+			assert.Empty(t, replay.DeploymentMismatches)
+			assert.Len(t, replay.CallSequence, len(failure.CallSequence))
+			for _, element := range replay.CallSequence {
+				assert.NotNil(t, element.ExecutionTrace)
+			}
+		},
+	})
+}
+
+// TestFuzzerCampaignEvents ensures the campaign-level events are published with their payloads, that the corpus
+// entry added for new coverage precedes the coverage event in the same worker, and that the campaign finished event is
+// published once, after every other event.
+func TestFuzzerCampaignEvents(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/assertions/assert_immediate.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.Workers = 2
+			config.Fuzzing.TestLimit = 500
+			config.Fuzzing.WorkerResetLimit = 10
+			config.Fuzzing.Testing.StopOnFailedTest = false
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Record the order of our events, as handlers may be called concurrently by different workers.
+			var eventsLock sync.Mutex
+			var recordedEvents []any
+			record := func(event any) error {
+				eventsLock.Lock()
+				defer eventsLock.Unlock()
+				recordedEvents = append(recordedEvents, event)
+				return nil
+			}
+			f.fuzzer.Events.CoverageFound.Subscribe(func(event FuzzerCoverageFoundEvent) error { return record(event) })
+			f.fuzzer.Events.CorpusEntryAdded.Subscribe(func(event FuzzerCorpusEntryAddedEvent) error { return record(event) })
+			f.fuzzer.Events.TestCaseFailed.Subscribe(func(event FuzzerTestCaseFailedEvent) error {
+				// Handlers may query the fuzzer's test cases without deadlocking.
+				assert.NotEmpty(t, event.Fuzzer.TestCases())
+				return record(event)
+			})
+			f.fuzzer.Events.WorkerReset.Subscribe(func(event FuzzerWorkerResetEvent) error { return record(event) })
+			f.fuzzer.Events.CampaignFinished.Subscribe(func(event FuzzerCampaignFinishedEvent) error { return record(event) })
+
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+			assertFailedTestsExpected(f, true)
+
+			// Tally our events, verifying each coverage event follows a corpus entry added by the same worker.
+			var coverageFound, corpusEntriesAdded, testCasesFailed, workerResets, campaignsFinished int
+			lastCorpusEntryWorker := make(map[int]bool)
+			for i, event := range recordedEvents {
+				switch event := event.(type) {
+				case FuzzerCoverageFoundEvent:
+					coverageFound++
+					assert.NotEmpty(t, event.CallSequence)
+					assert.True(t, lastCorpusEntryWorker[event.Worker.WorkerIndex()], "expected a corpus entry to be added before coverage was reported")
+					lastCorpusEntryWorker[event.Worker.WorkerIndex()] = false
+				case FuzzerCorpusEntryAddedEvent:
+					corpusEntriesAdded++
+					assert.NotNil(t, event.Metadata)
+					if event.Metadata.WorkerIndex != nil {
+						lastCorpusEntryWorker[*event.Metadata.WorkerIndex] = true
+					}
+				case FuzzerTestCaseFailedEvent:
+					testCasesFailed++
+					assert.EqualValues(t, TestCaseStatusFailed, event.TestCase.Status())
+					assert.NotNil(t, event.CallSequence)
+				case FuzzerWorkerResetEvent:
+					workerResets++
+					assert.EqualValues(t, WorkerResetCauseSequenceLimit, event.Cause)
+				case FuzzerCampaignFinishedEvent:
+					campaignsFinished++
+					assert.EqualValues(t, len(recordedEvents)-1, i, "expected the campaign finished event to be the last event")
+					assert.EqualValues(t, StopReasonCallLimit, event.StopReason)
+					assert.NoError(t, event.Err)
+					assert.NotEmpty(t, event.TestCases)
+				}
+			}
+			assert.Greater(t, coverageFound, 0)
+			assert.EqualValues(t, f.fuzzer.corpus.CallSequenceCount(), corpusEntriesAdded)
+			assert.EqualValues(t, 1, testCasesFailed)
+			assert.Greater(t, workerResets, 0)
+			assert.EqualValues(t, 1, campaignsFinished)
+		},
+	})
+}
+
+// TestCheckpointResume ensures a campaign resumed from the checkpoint of a previous one carries over its metrics and
+// restores its failed test cases by replaying the call sequences which failed them.
+func TestCheckpointResume(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/assertions/assert_immediate.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.DeploymentOrder = []string{"TestContract"}
+			config.Fuzzing.CorpusDirectory = "corpus"
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer, which should fail the test and checkpoint the failure.
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+			assertFailedTestsExpected(f, true)
+			callsTested := f.fuzzer.metrics.CallsTested().Uint64()
+			assert.FileExists(t, f.fuzzer.corpus.CheckpointFilePath())
+
+			// Resume the campaign. The failure should be restored, and our metrics should continue from those of the
+			// previous campaign.
+			f.fuzzer.config.Fuzzing.Resume = true
+			err = f.fuzzer.Start()
+			assert.NoError(t, err)
+			assertFailedTestsExpected(f, true)
+			assert.Greater(t, f.fuzzer.metrics.CallsTested().Uint64(), callsTested)
+		},
+	})
+}
+
+// TestFuzzerSeedReproducibility ensures that two fuzzing campaigns with the same seed and a single worker generate
+// identical call sequences.
+func TestFuzzerSeedReproducibility(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/value_generation/generate_all_types.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			seed := int64(1337)
+			config.Fuzzing.DeploymentOrder = []string{"GenerateAllTypes"}
+			config.Fuzzing.Workers = 1
+			config.Fuzzing.Seed = &seed
+			config.Fuzzing.TestLimit = 0
+			config.Fuzzing.CallSequenceLength = 10
+		},
+		method: func(f *fuzzerTestContext) {
+			// Record a description of each of the first calls tested, then stop the fuzzer.
+			const recordedCallCount = 500
+			var recordedCalls []string
+			f.fuzzer.Hooks.CallSequenceTestFuncs = append(f.fuzzer.Hooks.CallSequenceTestFuncs, func(worker *FuzzerWorker, callSequence calls.CallSequence) ([]ShrinkCallSequenceRequest, error) {
+				if len(recordedCalls) < recordedCallCount {
+					lastElement := callSequence[len(callSequence)-1]
+					recordedCalls = append(recordedCalls, fmt.Sprintf("%v %v %v %v %v",
+						len(callSequence),
+						lastElement.Call.MsgFrom,
+						lastElement.Call.MsgValue,
+						lastElement.BlockNumberDelay,
+						lastElement.Call.MsgData,
+					))
+					if len(recordedCalls) == recordedCallCount {
+						worker.Fuzzer().Stop()
+					}
+				}
+				return make([]ShrinkCallSequenceRequest, 0), nil
+			})
+
+			// Run the fuzzer twice, recording the calls each time.
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+			firstRunCalls := recordedCalls
+			assert.EqualValues(t, recordedCallCount, len(firstRunCalls))
+
+			recordedCalls = nil
+			err = f.fuzzer.Start()
+			assert.NoError(t, err)
+			assert.EqualValues(t, int64(1337), f.fuzzer.Seed())
+
+			// Verify both runs generated the same calls.
+			assert.EqualValues(t, firstRunCalls, recordedCalls)
+		},
+	})
+}
+
 // TestDeploymentOrderWithCoverage will ensure that changing the deployment order does not lead to the same coverage
 // This is also proof that changing the order changes the addresses of the contracts leading to the coverage not being
 // useful.
@@ -0,0 +1,391 @@
+package fuzzing
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crytic/medusa/fuzzing/config"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/logging/colors"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// testCaseResult describes the result of a TestCase, as written to test result outputs.
+type testCaseResult struct {
+	// testCase describes the test case the result is for.
+	testCase TestCase
+
+	// kind describes the kind of test, e.g. "property" or "assertion".
+	kind string
+
+	// contractName describes the name of the contract which defines the test, if known.
+	contractName string
+
+	// message describes the result message of the test case, without colors. For failed test cases, this includes
+	// the (shrunken) call sequence which failed the test.
+	message string
+
+	// location describes the source location of the function which defines the test, or nil if it is unknown.
+	location *testCaseSourceLocation
+}
+
+// testCaseSourceLocation describes the lines of a source file a test is defined at.
+type testCaseSourceLocation struct {
+	// path describes the path of the source file, relative to the working directory if possible.
+	path string
+
+	// startLine describes the first line of the test's definition, starting from one.
+	startLine int
+
+	// endLine describes the last line of the test's definition, starting from one.
+	endLine int
+}
+
+// testCaseResults obtains the results of every registered TestCase, for writing to test result outputs.
+// Returns the test case results.
+func (f *Fuzzer) testCaseResults() []testCaseResult {
+	testCases := f.TestCases()
+	results := make([]testCaseResult, 0, len(testCases))
+	for _, testCase := range testCases {
+		result := testCaseResult{
+			testCase: testCase,
+			kind:     testCaseKind(testCase),
+			message:  colors.Strip(strings.TrimSpace(f.testCaseResultMessage(testCase))),
+		}
+
+		// Resolve the contract method which defines the test, if any.
+		var (
+			targetContract *fuzzerTypes.Contract
+			targetMethod   abi.Method
+		)
+		switch t := testCase.(type) {
+		case *PropertyTestCase:
+			targetContract, targetMethod = t.targetContract, t.targetMethod
+		case *AssertionTestCase:
+			targetContract, targetMethod = t.targetContract, t.targetMethod
+		case *OptimizationTestCase:
+			targetContract, targetMethod = t.targetContract, t.targetMethod
+		case *NeverRevertTestCase:
+			targetContract, targetMethod = t.targetContract, t.targetMethod
+		case *DifferentialTestCase:
+			result.contractName = t.subjectContract.Name()
+		}
+		if targetContract != nil {
+			result.contractName = targetContract.Name()
+			result.location = f.methodSourceLocation(targetContract, targetMethod)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// testCaseKind obtains the kind of the provided TestCase, e.g. "property" or "assertion".
+// Returns the kind of the test case, or "test" if it is not a known kind of test case.
+func testCaseKind(testCase TestCase) string {
+	switch testCase.(type) {
+	case *PropertyTestCase:
+		return "property"
+	case *AssertionTestCase:
+		return "assertion"
+	case *OptimizationTestCase:
+		return "optimization"
+	case *NeverRevertTestCase:
+		return "never-revert"
+	case *DifferentialTestCase:
+		return "differential"
+	}
+	return "test"
+}
+
+// methodSourceLocation resolves the source location of the function definition which implements the provided method
+// of the provided contract, through the ASTs of our compilations.
+// Returns the source location, or nil if it could not be resolved.
+func (f *Fuzzer) methodSourceLocation(contract *fuzzerTypes.Contract, method abi.Method) *testCaseSourceLocation {
+	for _, compilation := range f.compilations {
+		if _, ok := compilation.Sources[contract.SourcePath()].Contracts[contract.Name()]; !ok {
+			continue
+		}
+		sourceRange, ok := compilation.GetFunctionSourceRanges(contract.SourcePath(), contract.Name())[fmt.Sprintf("%x", method.ID)]
+		if !ok {
+			return nil
+		}
+		sourcePath, ok := compilation.GetSourcePath(sourceRange.SourceUnitID)
+		if !ok {
+			return nil
+		}
+		contents, err := os.ReadFile(sourcePath)
+		if err != nil || sourceRange.Offset+sourceRange.Length > len(contents) {
+			return nil
+		}
+
+		// Source URIs should be relative, so results resolve in any checkout of the project.
+		if absolutePath, err := filepath.Abs(sourcePath); err == nil {
+			if workingDirectory, err := os.Getwd(); err == nil {
+				if relativePath, err := filepath.Rel(workingDirectory, absolutePath); err == nil && !strings.HasPrefix(relativePath, "..") {
+					sourcePath = relativePath
+				}
+			}
+		}
+		startLine := bytes.Count(contents[:sourceRange.Offset], []byte("\n")) + 1
+		return &testCaseSourceLocation{
+			path:      filepath.ToSlash(sourcePath),
+			startLine: startLine,
+			endLine:   startLine + bytes.Count(contents[sourceRange.Offset:sourceRange.Offset+sourceRange.Length], []byte("\n")),
+		}
+	}
+	return nil
+}
+
+// writeTestResultOutputs writes the results of every registered TestCase to each of the configured test result
+// outputs.
+// Returns an error if one occurs.
+func (f *Fuzzer) writeTestResultOutputs() error {
+	resultOutputs := f.config.Fuzzing.Testing.ResultOutputs
+	if len(resultOutputs) == 0 {
+		return nil
+	}
+	results := f.testCaseResults()
+	for _, resultOutput := range resultOutputs {
+		var err error
+		switch resultOutput.Format {
+		case config.TestResultOutputFormatJUnit:
+			err = writeJUnitTestResults(results, resultOutput.Path)
+		case config.TestResultOutputFormatSARIF:
+			err = writeSARIFTestResults(results, resultOutput.Path)
+		default:
+			err = fmt.Errorf("unknown test result output format '%v'", resultOutput.Format)
+		}
+		if err != nil {
+			return fmt.Errorf("could not write %v test results to '%v': %v", resultOutput.Format, resultOutput.Path, err)
+		}
+		logging.GlobalLogger.Info().Str("format", string(resultOutput.Format)).Str("path", resultOutput.Path).Msgf("%v test results written to '%v'", resultOutput.Format, resultOutput.Path)
+	}
+	return nil
+}
+
+// writeTestResultFile writes the provided data to the provided file path, creating its parent directory if needed.
+// Returns an error if one occurs.
+func writeTestResultFile(filePath string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// junitTestSuites describes the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite describes a suite of test cases in a JUnit XML report.
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase describes a test case in a JUnit XML report. Test cases without a failure or skipped element passed.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+// junitFailure describes the failure of a test case in a JUnit XML report.
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// junitSkipped describes a test case in a JUnit XML report which did not conclude.
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnitTestResults writes the provided test case results to the provided file path as a JUnit XML report, with
+// a test suite for each kind of test. Test cases which did not conclude (e.g. as the campaign was interrupted) are
+// reported as skipped.
+// Returns an error if one occurs.
+func writeJUnitTestResults(results []testCaseResult, filePath string) error {
+	report := junitTestSuites{Name: "medusa", TestSuites: make([]junitTestSuite, 0)}
+	suiteIndexes := make(map[string]int)
+	for _, result := range results {
+		suiteIndex, ok := suiteIndexes[result.kind]
+		if !ok {
+			suiteIndex = len(report.TestSuites)
+			suiteIndexes[result.kind] = suiteIndex
+			report.TestSuites = append(report.TestSuites, junitTestSuite{Name: result.kind, TestCases: make([]junitTestCase, 0)})
+		}
+		suite := &report.TestSuites[suiteIndex]
+
+		testCase := junitTestCase{Name: strings.TrimSpace(result.testCase.Name()), ClassName: result.contractName}
+		if result.location != nil {
+			testCase.File, testCase.Line = result.location.path, result.location.startLine
+		}
+		switch result.testCase.Status() {
+		case TestCaseStatusPassed:
+		case TestCaseStatusFailed:
+			testCase.Failure = &junitFailure{
+				Message:  strings.SplitN(result.message, "\n", 2)[0],
+				Type:     result.kind,
+				Contents: result.message,
+			}
+			suite.Failures++
+			report.Failures++
+		default:
+			testCase.Skipped = &junitSkipped{Message: fmt.Sprintf("test did not conclude (%v)", result.testCase.Status())}
+			suite.Skipped++
+			report.Skipped++
+		}
+		suite.Tests++
+		report.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	b, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeTestResultFile(filePath, append([]byte(xml.Header), append(b, '\n')...))
+}
+
+// sarifLog describes the root object of a SARIF log.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun describes a single run of an analysis tool in a SARIF log.
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes the analysis tool which produced a SARIF log.
+type sarifTool struct {
+	Driver sarifToolDriver `json:"driver"`
+}
+
+// sarifToolDriver describes the component of the analysis tool which produced a SARIF log, and its rules.
+type sarifToolDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+// sarifRule describes a rule results of a SARIF log can be reported for.
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+// sarifMessage describes a message in a SARIF log.
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifResult describes a result reported by the analysis tool in a SARIF log.
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+// sarifLocation describes the location of a result in a SARIF log.
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+// sarifPhysicalLocation describes a region of a source file in a SARIF log.
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+// sarifArtifactLocation describes the URI of a source file in a SARIF log.
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifRegion describes the lines of a region of a source file in a SARIF log.
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+// writeSARIFTestResults writes the failed test cases of the provided test case results to the provided file path as
+// a SARIF log, with each failure located at the function which defines the test.
+// Returns an error if one occurs.
+func writeSARIFTestResults(results []testCaseResult, filePath string) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifToolDriver{
+				Name:           "medusa",
+				InformationURI: "https://github.com/crytic/medusa",
+				Rules:          make([]sarifRule, 0),
+			},
+		},
+		Results: make([]sarifResult, 0),
+	}
+	ruleIDs := make(map[string]struct{})
+	for _, result := range results {
+		if result.testCase.Status() != TestCaseStatusFailed {
+			continue
+		}
+
+		// Each kind of test is reported under its own rule.
+		ruleID := fmt.Sprintf("%v-test-failure", result.kind)
+		if _, ok := ruleIDs[ruleID]; !ok {
+			ruleIDs[ruleID] = struct{}{}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               ruleID,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("A %v test failed", result.kind)},
+			})
+		}
+
+		sarifResult := sarifResult{
+			RuleID:  ruleID,
+			Level:   "error",
+			Message: sarifMessage{Text: result.message},
+		}
+		if result.location != nil {
+			sarifResult.Locations = []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: result.location.path},
+					Region:           sarifRegion{StartLine: result.location.startLine, EndLine: result.location.endLine},
+				},
+			}}
+		}
+		run.Results = append(run.Results, sarifResult)
+	}
+
+	b, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeTestResultFile(filePath, b)
+}
@@ -0,0 +1,108 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/stretchr/testify/assert"
+)
+
+// testResultStubTestCase is a TestCase with a fixed name, status and call sequence, used to test result outputs.
+type testResultStubTestCase struct {
+	name         string
+	status       TestCaseStatus
+	callSequence *calls.CallSequence
+}
+
+func (t *testResultStubTestCase) Status() TestCaseStatus            { return t.status }
+func (t *testResultStubTestCase) CallSequence() *calls.CallSequence { return t.callSequence }
+func (t *testResultStubTestCase) Name() string                      { return t.name }
+func (t *testResultStubTestCase) Message() string                   { return "" }
+func (t *testResultStubTestCase) ID() string                        { return t.name }
+
+// testResultStubResults returns test case results with one passed, one failed and one unconcluded test.
+func testResultStubResults() []testCaseResult {
+	return []testCaseResult{
+		{
+			testCase:     &testResultStubTestCase{name: "Property Test: TestContract.fuzz_passes()", status: TestCaseStatusPassed},
+			kind:         "property",
+			contractName: "TestContract",
+		},
+		{
+			testCase:     &testResultStubTestCase{name: "Property Test: TestContract.fuzz_fails()", status: TestCaseStatusFailed},
+			kind:         "property",
+			contractName: "TestContract",
+			message:      "Test \"TestContract.fuzz_fails()\" failed after the following call sequence:\n1) TestContract.set(1)",
+			location:     &testCaseSourceLocation{path: "contracts/TestContract.sol", startLine: 10, endLine: 12},
+		},
+		{
+			testCase:     &testResultStubTestCase{name: "Assertion Test: TestContract.set(uint256)", status: TestCaseStatusRunning},
+			kind:         "assertion",
+			contractName: "TestContract",
+		},
+	}
+}
+
+// TestWriteJUnitTestResults ensures test case results are written as a JUnit XML report, with failed tests reported
+// as failures carrying their call sequence and unconcluded tests reported as skipped.
+func TestWriteJUnitTestResults(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "results", "junit.xml")
+	assert.NoError(t, writeJUnitTestResults(testResultStubResults(), filePath))
+
+	b, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	var report junitTestSuites
+	assert.NoError(t, xml.Unmarshal(b, &report))
+
+	assert.EqualValues(t, 3, report.Tests)
+	assert.EqualValues(t, 1, report.Failures)
+	assert.EqualValues(t, 1, report.Skipped)
+	assert.Len(t, report.TestSuites, 2)
+	assert.EqualValues(t, "property", report.TestSuites[0].Name)
+	assert.Len(t, report.TestSuites[0].TestCases, 2)
+
+	passed, failed := report.TestSuites[0].TestCases[0], report.TestSuites[0].TestCases[1]
+	assert.Nil(t, passed.Failure)
+	assert.Nil(t, passed.Skipped)
+	assert.EqualValues(t, "TestContract", failed.ClassName)
+	assert.EqualValues(t, 10, failed.Line)
+	if assert.NotNil(t, failed.Failure) {
+		assert.EqualValues(t, "Test \"TestContract.fuzz_fails()\" failed after the following call sequence:", failed.Failure.Message)
+		assert.Contains(t, failed.Failure.Contents, "1) TestContract.set(1)")
+	}
+	assert.NotNil(t, report.TestSuites[1].TestCases[0].Skipped)
+}
+
+// TestWriteSARIFTestResults ensures only failed test cases are written as results of a SARIF log, located at the
+// function which defines the test.
+func TestWriteSARIFTestResults(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "results.sarif")
+	assert.NoError(t, writeSARIFTestResults(testResultStubResults(), filePath))
+
+	b, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	var log sarifLog
+	assert.NoError(t, json.Unmarshal(b, &log))
+
+	assert.EqualValues(t, "2.1.0", log.Version)
+	if assert.Len(t, log.Runs, 1) {
+		run := log.Runs[0]
+		assert.EqualValues(t, "medusa", run.Tool.Driver.Name)
+		assert.Len(t, run.Tool.Driver.Rules, 1)
+		if assert.Len(t, run.Results, 1) {
+			result := run.Results[0]
+			assert.EqualValues(t, "property-test-failure", result.RuleID)
+			assert.EqualValues(t, "error", result.Level)
+			if assert.Len(t, result.Locations, 1) {
+				location := result.Locations[0].PhysicalLocation
+				assert.EqualValues(t, "contracts/TestContract.sol", location.ArtifactLocation.URI)
+				assert.EqualValues(t, 10, location.Region.StartLine)
+				assert.EqualValues(t, 12, location.Region.EndLine)
+			}
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package fuzzing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/logging"
+)
+
+// watchPollInterval describes how often the sources of the compilation target are checked for changes in watch mode.
+const watchPollInterval = time.Second
+
+// watchedSourceExtensions describes the extensions of the source files which are watched for changes in watch mode.
+var watchedSourceExtensions = []string{".sol", ".vy"}
+
+// watchedSourceFile describes the state of a source file watched for changes, as observed when it was last checked.
+type watchedSourceFile struct {
+	// modTime describes the time the file was last modified.
+	modTime time.Time
+
+	// size describes the size of the file, in bytes.
+	size int64
+}
+
+// watchedSourceFiles describes the state of every source file of a compilation target watched for changes, keyed by
+// path.
+type watchedSourceFiles map[string]watchedSourceFile
+
+// equals checks whether the provided watched source files are in the same state as these.
+func (w watchedSourceFiles) equals(other watchedSourceFiles) bool {
+	if len(w) != len(other) {
+		return false
+	}
+	for path, file := range w {
+		otherFile, ok := other[path]
+		if !ok || !file.modTime.Equal(otherFile.modTime) || file.size != otherFile.size {
+			return false
+		}
+	}
+	return true
+}
+
+// readWatchedSourceFiles reads the state of every source file under the provided compilation target path. If the
+// target is a file, every source file in its directory is read, so changes to the files it imports are observed.
+// Hidden directories, node_modules and the provided excluded directories are skipped.
+// Returns the state of each source file, or an error if one occurs.
+func readWatchedSourceFiles(target string, excludedDirectories []string) (watchedSourceFiles, error) {
+	if target == "" {
+		target = "."
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		target = filepath.Dir(target)
+	}
+
+	// Resolve our excluded directories, so they can be compared with the directories we walk.
+	excluded := make(map[string]struct{}, len(excludedDirectories))
+	for _, directory := range excludedDirectories {
+		if directory == "" {
+			continue
+		}
+		if absDirectory, err := filepath.Abs(directory); err == nil {
+			excluded[absDirectory] = struct{}{}
+		}
+	}
+
+	files := make(watchedSourceFiles)
+	err = filepath.WalkDir(target, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != target && (strings.HasPrefix(name, ".") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			if absPath, err := filepath.Abs(path); err == nil {
+				if _, ok := excluded[absPath]; ok {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		for _, extension := range watchedSourceExtensions {
+			if filepath.Ext(path) == extension {
+				info, err := entry.Info()
+				if err != nil {
+					return err
+				}
+				files[path] = watchedSourceFile{modTime: info.ModTime(), size: info.Size()}
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// incompatibleContractChanges compares the provided contract definitions with those they were recompiled into,
+// describing every change which prevents corpus call sequences calling them from being replayed: contracts which no
+// longer exist, and methods which were removed or whose inputs changed.
+// Returns a description of each incompatible change.
+func incompatibleContractChanges(previous fuzzerTypes.Contracts, recompiled fuzzerTypes.Contracts) []string {
+	recompiledContracts := make(map[string]*fuzzerTypes.Contract, len(recompiled))
+	for _, contract := range recompiled {
+		recompiledContracts[contract.Name()] = contract
+	}
+
+	changes := make([]string, 0)
+	for _, contract := range previous {
+		recompiledContract, ok := recompiledContracts[contract.Name()]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("contract '%v' was removed", contract.Name()))
+			continue
+		}
+
+		// Methods are resolved by name, then their inputs decoded, so either changing invalidates calls to them.
+		methodNames := make([]string, 0, len(contract.CompiledContract().Abi.Methods))
+		for methodName := range contract.CompiledContract().Abi.Methods {
+			methodNames = append(methodNames, methodName)
+		}
+		sort.Strings(methodNames)
+		for _, methodName := range methodNames {
+			method := contract.CompiledContract().Abi.Methods[methodName]
+			recompiledMethod, ok := recompiledContract.CompiledContract().Abi.Methods[methodName]
+			if !ok {
+				changes = append(changes, fmt.Sprintf("method '%v.%v' was removed", contract.Name(), method.Sig))
+			} else if recompiledMethod.Sig != method.Sig {
+				changes = append(changes, fmt.Sprintf("method '%v.%v' changed to '%v.%v'", contract.Name(), method.Sig, contract.Name(), recompiledMethod.Sig))
+			}
+		}
+	}
+	return changes
+}
+
+// Watch begins a fuzzing operation like StartWithContext, but also watches the sources of the compilation target for
+// changes. When they change, the target is recompiled while fuzzing continues. If it compiles, the campaign is
+// stopped and a new one is started against the recompiled contracts on fresh chains, carrying over the corpus and
+// the values learned so far. If a campaign stops for any other reason, the next change starts a new one. This
+// operation does not return until an error is encountered or the provided context is cancelled.
+// Returns an error if one is encountered.
+func (f *Fuzzer) Watch(ctx context.Context) error {
+	if f.config.Compilation == nil {
+		return errors.New("watch mode requires a compilation config to recompile targets with")
+	}
+
+	// Read the initial state of our sources, so we can tell when they change.
+	sourceFiles, err := f.readWatchedSourceFiles()
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Start a campaign, recompiling our target while it runs if its sources change.
+		campaignCtx, cancelCampaign := context.WithCancel(ctx)
+		recompiled := make(chan []compilationTypes.Compilation, 1)
+		go f.watchCompilationTarget(campaignCtx, cancelCampaign, &sourceFiles, recompiled)
+		err = f.StartWithContext(campaignCtx)
+		if err != nil {
+			cancelCampaign()
+			return err
+		}
+
+		// If the campaign stopped for another reason, wait for our target to be recompiled before starting another,
+		// unless we were cancelled.
+		var compilations []compilationTypes.Compilation
+		select {
+		case compilations = <-recompiled:
+		default:
+			if ctx.Err() == nil {
+				logging.GlobalLogger.Info().Msg("Waiting for changes to the compilation target ...")
+			}
+			select {
+			case compilations = <-recompiled:
+			case <-ctx.Done():
+			}
+		}
+		cancelCampaign()
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Target our recompiled contracts in the next campaign.
+		err = f.reloadCompilationTargets(compilations)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readWatchedSourceFiles reads the state of every source file of the compilation target, excluding the corpus
+// directory.
+// Returns the state of each source file, or an error if one occurs.
+func (f *Fuzzer) readWatchedSourceFiles() (watchedSourceFiles, error) {
+	platformConfig, err := f.config.Compilation.GetPlatformConfig()
+	if err != nil {
+		return nil, err
+	}
+	return readWatchedSourceFiles(platformConfig.GetTarget(), []string{f.config.Fuzzing.CorpusDirectory})
+}
+
+// watchCompilationTarget checks the sources of the compilation target for changes every watchPollInterval, until the
+// provided context is cancelled. When they change, the target is recompiled. If it compiles, the campaign is stopped
+// and its compilations are sent over the provided channel. Otherwise, the error is logged and the sources are watched
+// for another change. The provided source files are updated with the state of the sources as they are checked.
+func (f *Fuzzer) watchCompilationTarget(ctx context.Context, cancelCampaign context.CancelFunc, sourceFiles *watchedSourceFiles, recompiled chan<- []compilationTypes.Compilation) {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Check whether our sources changed since we last checked them.
+		currentSourceFiles, err := f.readWatchedSourceFiles()
+		if err != nil {
+			logging.GlobalLogger.Warn().Err(err).Msgf("Failed to check the compilation target for changes: %v", err)
+			continue
+		}
+		if currentSourceFiles.equals(*sourceFiles) {
+			continue
+		}
+		*sourceFiles = currentSourceFiles
+
+		// Recompile our target. If it does not compile, we keep fuzzing the contracts we have.
+		logging.GlobalLogger.Info().Str("platform", f.config.Compilation.Platform).Msgf("Changes to the compilation target detected, recompiling targets (platform '%s') ...", f.config.Compilation.Platform)
+		compilations, compilationOutput, err := (*f.config.Compilation).Compile()
+		if err != nil {
+			logging.GlobalLogger.Error().Err(err).Msgf("Failed to recompile targets, continuing to fuzz the previous compilation: %v", err)
+			continue
+		}
+		if compilationOutput = strings.TrimRight(compilationOutput, "\n"); compilationOutput != "" {
+			logging.GlobalLogger.Info().Msg(compilationOutput)
+		}
+
+		// Stop our campaign so a new one is started against our recompiled contracts.
+		logging.GlobalLogger.Info().Msg("Targets recompiled, restarting the campaign ...")
+		f.stop(StopReasonRecompiled)
+		cancelCampaign()
+		recompiled <- compilations
+		return
+	}
+}
+
+// reloadCompilationTargets replaces the contracts the Fuzzer targets with those of the provided compilations, so the
+// next campaign started fuzzes them. The values learned by the previous campaign are carried over to it, as is its
+// corpus, which is detached from the previous contracts so it is replayed against the new ones. Call sequences which
+// call contracts or methods incompatible with the new contracts are disabled as stale when it is replayed.
+// Returns an error if one occurs.
+func (f *Fuzzer) reloadCompilationTargets(compilations []compilationTypes.Compilation) error {
+	// Replace our contract definitions, seeding the new ones into the values we learned.
+	previousContractDefinitions := f.contractDefinitions
+	if f.learnedValueSet != nil {
+		f.baseValueSet = f.learnedValueSet.Clone()
+	}
+	f.contractDefinitions = make(fuzzerTypes.Contracts, 0)
+	f.compilations = nil
+	f.AddCompilationTargets(compilations)
+	f.unmatchedContractCodeHashes = nil
+
+	// Report any changes to the contracts which prevent our corpus from being replayed in full.
+	for _, change := range incompatibleContractChanges(previousContractDefinitions, f.contractDefinitions) {
+		logging.GlobalLogger.Warn().Msgf("Recompiled contracts are incompatible with the previous ones: %v, corpus call sequences using it will be disabled as stale", change)
+	}
+
+	// Carry over our corpus to the next campaign. Its checkpoint no longer applies to our contracts, so it must not
+	// be resumed from.
+	if f.corpus != nil {
+		err := f.corpus.DetachCallSequences()
+		if err != nil {
+			return err
+		}
+		f.retainedCorpus = f.corpus
+	}
+	f.config.Fuzzing.Resume = false
+	return nil
+}
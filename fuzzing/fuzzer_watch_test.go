@@ -0,0 +1,87 @@
+package fuzzing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReadWatchedSourceFiles ensures changes to the sources of a compilation target are detected in watch mode, while
+// changes to other files, hidden directories and excluded directories are not.
+func TestReadWatchedSourceFiles(t *testing.T) {
+	directory := t.TempDir()
+	writeFile := func(path string, data string) {
+		path = filepath.Join(directory, path)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(data), 0644))
+	}
+	writeFile("contracts/Target.sol", "contract Target {}")
+	writeFile("contracts/Library.vy", "# library")
+	target := filepath.Join(directory, "contracts", "Target.sol")
+	corpusDirectory := filepath.Join(directory, "contracts", "corpus")
+
+	// Targeting a file should watch every source in its directory.
+	initial, err := readWatchedSourceFiles(target, []string{corpusDirectory})
+	assert.NoError(t, err)
+	assert.Len(t, initial, 2)
+
+	// Changes to files which are not sources, or which reside in hidden or excluded directories, should be ignored.
+	writeFile("contracts/crytic-export/combined_solc.json", "{}")
+	writeFile("contracts/.cache/Cached.sol", "contract Cached {}")
+	writeFile("contracts/corpus/Corpus.sol", "contract Corpus {}")
+	unchanged, err := readWatchedSourceFiles(target, []string{corpusDirectory})
+	assert.NoError(t, err)
+	assert.True(t, initial.equals(unchanged))
+
+	// Modifying or adding a source should be detected.
+	future := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(target, future, future))
+	modified, err := readWatchedSourceFiles(target, []string{corpusDirectory})
+	assert.NoError(t, err)
+	assert.False(t, initial.equals(modified))
+	writeFile("contracts/nested/Added.sol", "contract Added {}")
+	added, err := readWatchedSourceFiles(filepath.Dir(target), []string{corpusDirectory})
+	assert.NoError(t, err)
+	assert.False(t, modified.equals(added))
+	assert.Len(t, added, 3)
+}
+
+// TestIncompatibleContractChanges ensures removed contracts and methods, and methods whose inputs changed, are
+// reported as incompatible when contracts are recompiled in watch mode, while added methods are not.
+func TestIncompatibleContractChanges(t *testing.T) {
+	newContract := func(name string, abiJSON string) *fuzzerTypes.Contract {
+		contractAbi, err := abi.JSON(strings.NewReader(abiJSON))
+		assert.NoError(t, err)
+		return fuzzerTypes.NewContract(name, "", &compilationTypes.CompiledContract{Abi: contractAbi}, nil)
+	}
+	previous := fuzzerTypes.Contracts{
+		newContract("Target", `[
+			{"type":"function","name":"kept","inputs":[],"outputs":[]},
+			{"type":"function","name":"removed","inputs":[],"outputs":[]},
+			{"type":"function","name":"changed","inputs":[{"name":"x","type":"uint256"}],"outputs":[]}
+		]`),
+		newContract("Removed", `[]`),
+	}
+	recompiled := fuzzerTypes.Contracts{
+		newContract("Target", `[
+			{"type":"function","name":"kept","inputs":[],"outputs":[]},
+			{"type":"function","name":"added","inputs":[],"outputs":[]},
+			{"type":"function","name":"changed","inputs":[{"name":"x","type":"uint128"}],"outputs":[]}
+		]`),
+	}
+
+	changes := incompatibleContractChanges(previous, recompiled)
+	assert.EqualValues(t, []string{
+		"method 'Target.changed(uint256)' changed to 'Target.changed(uint128)'",
+		"method 'Target.removed()' was removed",
+		"contract 'Removed' was removed",
+	}, changes)
+	assert.Empty(t, incompatibleContractChanges(previous, previous))
+}
@@ -1,17 +1,28 @@
 package fuzzing
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/crytic/medusa/chain"
 	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/comparisontracer"
+	"github.com/crytic/medusa/fuzzing/config"
 	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/corpus"
 	"github.com/crytic/medusa/fuzzing/coverage"
+	"github.com/crytic/medusa/fuzzing/create2tracer"
+	"github.com/crytic/medusa/fuzzing/gastracer"
+	"github.com/crytic/medusa/fuzzing/panictracer"
+	"github.com/crytic/medusa/fuzzing/storagetracer"
 	"github.com/crytic/medusa/fuzzing/valuegeneration"
+	"github.com/crytic/medusa/logging"
 	"github.com/crytic/medusa/utils"
 	"github.com/ethereum/go-ethereum/common"
 	"golang.org/x/exp/maps"
 	"math/big"
 	"math/rand"
+	"sort"
+	"time"
 )
 
 // FuzzerWorker describes a single thread worker utilizing its own go-ethereum test node to run property tests against
@@ -26,6 +37,9 @@ type FuzzerWorker struct {
 	chain *chain.TestChain
 	// coverageTracer describes the tracer used to collect coverage maps during fuzzing campaigns.
 	coverageTracer *coverage.CoverageTracer
+	// comparisonTracer describes the tracer used to collect the operands of comparison instructions, if comparison
+	// values are enabled.
+	comparisonTracer *comparisontracer.ComparisonTracer
 
 	// testingBaseBlockNumber refers to the block number at which all contracts for testing have been deployed, prior
 	// to any fuzzing activity. This block number is reverted to after testing each call sequence to reset state.
@@ -37,6 +51,17 @@ type FuzzerWorker struct {
 	// (non-read-only). A sequence of calls is generated by the FuzzerWorker, targeting stateChangingMethods
 	// before executing tests.
 	stateChangingMethods []fuzzerTypes.DeployedContractMethod
+	// stateChangingMethodWeights describes the configured weight of each method in stateChangingMethods, at the same
+	// index.
+	stateChangingMethodWeights []uint64
+	// proxyResolver resolves the implementations of deployed proxy contracts.
+	proxyResolver *fuzzerTypes.ProxyImplementationResolver
+	// proxyImplementations describes the contract definitions of the implementations of deployed proxy contracts,
+	// keyed by the address of the proxy. The methods of each implementation are called through its proxy.
+	proxyImplementations map[common.Address]*fuzzerTypes.Contract
+	// agents describes the addresses of deployed agent contracts which calls may be routed through, sorted so they
+	// are selected reproducibly.
+	agents []common.Address
 
 	// randomProvider provides random data as inputs to decisions throughout the worker.
 	randomProvider *rand.Rand
@@ -46,6 +71,17 @@ type FuzzerWorker struct {
 	// valueSet defines a set derived from Fuzzer.BaseValueSet which is further populated with runtime values by the
 	// FuzzerWorker. It is the value set shared with the underlying valueGenerator.
 	valueSet *valuegeneration.ValueSet
+	// runtimeValueRemovers describes functions which remove values learned at runtime from the valueSet, in the order
+	// the values were learned. This is used to evict the oldest learned values once the configured bound is exceeded.
+	runtimeValueRemovers []func()
+	// create2Addresses describes the addresses of contracts created through CREATE2 which were learned into the
+	// valueSet. They are kept in the valueSet when the contracts are removed from the chain, as they are created at the
+	// same addresses again.
+	create2Addresses map[common.Address]struct{}
+
+	// resetCause describes the resource limit the worker reached, causing it to exit so it is recreated, or an empty
+	// string if it has not exited for this reason.
+	resetCause WorkerResetCause
 
 	// Events describes the event system for the FuzzerWorker.
 	Events FuzzerWorkerEvents
@@ -70,9 +106,14 @@ func newFuzzerWorker(fuzzer *Fuzzer, workerIndex int, randomProvider *rand.Rand)
 		fuzzer:               fuzzer,
 		deployedContracts:    make(map[common.Address]*fuzzerTypes.Contract),
 		stateChangingMethods: make([]fuzzerTypes.DeployedContractMethod, 0),
+		proxyResolver:        fuzzerTypes.NewProxyImplementationResolver(fuzzer.contractDefinitions, fuzzer.config.Fuzzing.ProxyImplementations),
+		proxyImplementations: make(map[common.Address]*fuzzerTypes.Contract),
 		coverageTracer:       nil,
+		comparisonTracer:     nil,
 		randomProvider:       randomProvider,
 		valueSet:             valueSet,
+		runtimeValueRemovers: make([]func(), 0),
+		create2Addresses:     make(map[common.Address]struct{}),
 	}
 	worker.sequenceGenerator = NewCallSequenceGenerator(worker, callSequenceGenConfig)
 
@@ -114,6 +155,14 @@ func (fw *FuzzerWorker) DeployedContract(address common.Address) *fuzzerTypes.Co
 	return nil
 }
 
+// deployedContractsStateHash calculates a hash of the current state of the contracts deployed on the worker's chain,
+// used to deduplicate corpus call sequences by the state they reach. The accounts sending calls are not included, as
+// their balances differ with the gas each call sequence used.
+// Returns the state hash, or an error if one occurs.
+func (fw *FuzzerWorker) deployedContractsStateHash() (common.Hash, error) {
+	return fw.chain.StateHash(maps.Keys(fw.deployedContracts))
+}
+
 // ValueSet obtains the value set used to power the value generator for this worker.
 func (fw *FuzzerWorker) ValueSet() *valuegeneration.ValueSet {
 	return fw.valueSet
@@ -128,7 +177,21 @@ func (fw *FuzzerWorker) ValueGenerator() valuegeneration.ValueGenerator {
 // should have in the corpus' weighted random chooser.
 func (fw *FuzzerWorker) getNewCorpusCallSequenceWeight() *big.Int {
 	// Return our weight, ensuring it is non-zero.
-	return new(big.Int).Add(fw.workerMetrics().sequencesTested, big.NewInt(1))
+	return new(big.Int).SetUint64(fw.workerMetrics().sequencesTested.load() + 1)
+}
+
+// newCorpusCallSequenceMetadata creates metadata describing a call sequence produced by this worker with the provided
+// origin, for use when adding it to the corpus.
+func (fw *FuzzerWorker) newCorpusCallSequenceMetadata(origin corpus.CallSequenceOrigin) *corpus.CallSequenceMetadata {
+	workerIndex := fw.workerIndex
+	metadata := &corpus.CallSequenceMetadata{
+		WorkerIndex: &workerIndex,
+		Origin:      origin,
+	}
+	if len(fw.fuzzer.fuzzedConstructorArgs) > 0 {
+		metadata.ConstructorArgs = fw.fuzzer.fuzzedConstructorArgs
+	}
+	return metadata
 }
 
 // onChainContractDeploymentAddedEvent is the event callback used when the chain detects a new contract deployment.
@@ -136,6 +199,9 @@ func (fw *FuzzerWorker) getNewCorpusCallSequenceWeight() *big.Int {
 func (fw *FuzzerWorker) onChainContractDeploymentAddedEvent(event chain.ContractDeploymentsAddedEvent) error {
 	// Add the contract address to our value set so our generator can use it in calls.
 	fw.valueSet.AddAddress(event.Contract.Address)
+	if valueGenerator, ok := fw.ValueGenerator().(valuegeneration.ContractAwareValueGenerator); ok {
+		valueGenerator.AddDeployedContractAddress(event.Contract.Address)
+	}
 
 	// Try to match it to a known contract definition
 	matchedDefinition := fw.fuzzer.contractDefinitions.MatchBytecode(event.Contract.InitBytecode, event.Contract.RuntimeBytecode)
@@ -144,6 +210,7 @@ func (fw *FuzzerWorker) onChainContractDeploymentAddedEvent(event chain.Contract
 		if fw.fuzzer.config.Fuzzing.Testing.StopOnFailedContractMatching {
 			return fmt.Errorf("could not match bytecode of a deployed contract to any contract definition known to the fuzzer")
 		} else {
+			fw.fuzzer.reportUnmatchedContract(event.Contract.Address, event.Contract.RuntimeBytecode)
 			return nil
 		}
 	}
@@ -151,7 +218,8 @@ func (fw *FuzzerWorker) onChainContractDeploymentAddedEvent(event chain.Contract
 	// Set our deployed contract address in our deployed contract lookup, so we can reference it later.
 	fw.deployedContracts[event.Contract.Address] = matchedDefinition
 
-	// Update our state changing methods
+	// Update our proxy implementations and state changing methods
+	fw.updateProxyImplementations(event.Chain)
 	fw.updateStateChangingMethods()
 
 	// Emit an event indicating the worker detected a new contract deployment on its chain.
@@ -169,8 +237,14 @@ func (fw *FuzzerWorker) onChainContractDeploymentAddedEvent(event chain.Contract
 // onChainContractDeploymentRemovedEvent is the event callback used when the chain detects removal of a previously
 // deployed contract. It updates the list of deployed contracts the worker should use for fuzz testing.
 func (fw *FuzzerWorker) onChainContractDeploymentRemovedEvent(event chain.ContractDeploymentsRemovedEvent) error {
-	// Remove the contract address from our value set so our generator doesn't use it any longer
-	fw.valueSet.RemoveAddress(event.Contract.Address)
+	// Remove the contract address from our value set so our generator doesn't use it any longer, unless it was created
+	// through CREATE2, as it may be created at the same address again.
+	if _, ok := fw.create2Addresses[event.Contract.Address]; !ok {
+		fw.valueSet.RemoveAddress(event.Contract.Address)
+	}
+	if valueGenerator, ok := fw.ValueGenerator().(valuegeneration.ContractAwareValueGenerator); ok {
+		valueGenerator.RemoveDeployedContractAddress(event.Contract.Address)
+	}
 
 	// Obtain our contract definition for this address. If we didn't record this contract deployment in the first place,
 	// there is nothing to remove, so we exit early.
@@ -182,7 +256,8 @@ func (fw *FuzzerWorker) onChainContractDeploymentRemovedEvent(event chain.Contra
 	// Remove the contract from our deployed contracts mapping the worker maintains.
 	delete(fw.deployedContracts, event.Contract.Address)
 
-	// Update our state changing methods
+	// Update our proxy implementations and state changing methods
+	fw.updateProxyImplementations(event.Chain)
 	fw.updateStateChangingMethods()
 
 	// Emit an event indicating the worker detected the removal of a previously deployed contract on its chain.
@@ -200,21 +275,87 @@ func (fw *FuzzerWorker) onChainContractDeploymentRemovedEvent(event chain.Contra
 // updateStateChangingMethods updates the list of state changing methods used by the worker by re-evaluating them
 // from the deployedContracts lookup.
 func (fw *FuzzerWorker) updateStateChangingMethods() {
-	// Clear our list of state changing methods
+	// Clear our list of state changing methods and agents
 	fw.stateChangingMethods = make([]fuzzerTypes.DeployedContractMethod, 0)
+	fw.agents = make([]common.Address, 0)
 
 	// Loop through each deployed contract
 	for contractAddress, contractDefinition := range fw.deployedContracts {
+		// Libraries are only called through the contracts which reference them.
+		if fw.fuzzer.isLibraryContract(contractDefinition) {
+			continue
+		}
+
+		// Agents may be called directly, but their method which forwards calls is only used to route calls.
+		isAgent := fw.fuzzer.isAgentContract(contractDefinition)
+		if isAgent {
+			fw.agents = append(fw.agents, contractAddress)
+		}
+
 		// If we deployed the contract, also enumerate property tests and state changing methods.
 		for _, method := range contractDefinition.CompiledContract().Abi.Methods {
-			if !method.IsConstant() {
-				// Any non-constant method should be tracked as a state changing method.
+			if isAgent && isAgentExecuteMethod(method.Sig) {
+				continue
+			}
+			if fw.fuzzer.isCallableMethod(method) && fw.fuzzer.methodFilter.allows(contractDefinition, method) {
+				// Any non-constant method should be tracked as a state changing method, unless it is filtered out.
+				// Constant methods expected to revert are also called, so their assertion test cases are executed.
 				fw.stateChangingMethods = append(fw.stateChangingMethods, fuzzerTypes.DeployedContractMethod{Address: contractAddress, Contract: contractDefinition, Method: method})
 			}
 		}
+
+		// If the contract is a proxy, its implementation's methods are called through it, unless the proxy itself
+		// handles calls to them.
+		implementation, isProxy := fw.proxyImplementations[contractAddress]
+		if !isProxy {
+			continue
+		}
+		for _, method := range implementation.CompiledContract().Abi.Methods {
+			if _, err := contractDefinition.CompiledContract().Abi.MethodById(method.ID); err == nil {
+				continue
+			}
+			if fw.fuzzer.isCallableMethod(method) && fw.fuzzer.methodFilter.allows(implementation, method) {
+				fw.stateChangingMethods = append(fw.stateChangingMethods, fuzzerTypes.DeployedContractMethod{Address: contractAddress, Contract: implementation, Method: method})
+			}
+		}
+	}
+
+	// Sort our methods, as the maps we enumerated have no defined order, and random method selection should be
+	// reproducible given the same random seed.
+	sort.Slice(fw.stateChangingMethods, func(i, j int) bool {
+		addressComparison := bytes.Compare(fw.stateChangingMethods[i].Address[:], fw.stateChangingMethods[j].Address[:])
+		if addressComparison != 0 {
+			return addressComparison < 0
+		}
+		return fw.stateChangingMethods[i].Method.Sig < fw.stateChangingMethods[j].Method.Sig
+	})
+	sort.Slice(fw.agents, func(i, j int) bool {
+		return bytes.Compare(fw.agents[i][:], fw.agents[j][:]) < 0
+	})
+
+	// Determine the configured weight of each method, used when selecting methods to call.
+	fw.stateChangingMethodWeights = make([]uint64, len(fw.stateChangingMethods))
+	for i := range fw.stateChangingMethods {
+		fw.stateChangingMethodWeights[i] = configuredMethodWeight(fw.fuzzer.config.Fuzzing, &fw.stateChangingMethods[i])
 	}
 }
 
+// updateProxyImplementations re-resolves the implementations of the deployed contracts which are proxies, from the
+// state of the provided chain, as proxies may be upgraded to new implementations.
+// Returns a boolean indicating whether any implementation changed.
+func (fw *FuzzerWorker) updateProxyImplementations(testChain *chain.TestChain) bool {
+	proxyImplementations := make(map[common.Address]*fuzzerTypes.Contract)
+	for contractAddress, contractDefinition := range fw.deployedContracts {
+		implementation := fw.proxyResolver.Resolve(testChain.State(), contractAddress, contractDefinition, fw.deployedContracts)
+		if implementation != nil {
+			proxyImplementations[contractAddress] = implementation
+		}
+	}
+	changed := !maps.Equal(proxyImplementations, fw.proxyImplementations)
+	fw.proxyImplementations = proxyImplementations
+	return changed
+}
+
 // testCallSequence tests a call message sequence against the underlying FuzzerWorker's Chain and calls every
 // CallSequenceTestFunc registered with the parent Fuzzer to update any test results. If any call message in the
 // sequence is nil, a call message will be created in its place, targeting a state changing method of a contract
@@ -229,6 +370,12 @@ func (fw *FuzzerWorker) testCallSequence() (calls.CallSequence, []ShrinkCallSequ
 		}
 	}()
 
+	// Our chain was reverted after the last sequence, which may have upgraded proxies, so we re-resolve their
+	// implementations.
+	if fw.updateProxyImplementations(fw.chain) {
+		fw.updateStateChangingMethods()
+	}
+
 	// Initialize a new sequence within our sequence generator.
 	var isNewSequence bool
 	isNewSequence, err = fw.sequenceGenerator.InitializeNextSequence()
@@ -239,9 +386,30 @@ func (fw *FuzzerWorker) testCallSequence() (calls.CallSequence, []ShrinkCallSequ
 	// Define our shrink requests we'll collect during execution.
 	shrinkCallSequenceRequests := make([]ShrinkCallSequenceRequest, 0)
 
-	// Our "fetch next call" method will generate new calls as needed, if we are generating a new sequence.
+	// Our "fetch next call" method will generate new calls as needed, if we are generating a new sequence. Any calls
+	// mirroring a fetched call are executed after it, before fetching the next.
+	var pendingMirrorElements []*calls.CallSequenceElement
 	fetchElementFunc := func(currentIndex int) (*calls.CallSequenceElement, error) {
-		return fw.sequenceGenerator.PopSequenceElement()
+		if len(pendingMirrorElements) > 0 {
+			mirrorElement := pendingMirrorElements[0]
+			pendingMirrorElements = pendingMirrorElements[1:]
+			mirrorElement.Call.FillFromTestChainProperties(fw.chain)
+			return mirrorElement, nil
+		}
+		for {
+			element, err := fw.sequenceGenerator.PopSequenceElement()
+			if element == nil || err != nil {
+				return element, err
+			}
+			mirrorElements, execute, err := fw.mirrorCallSequenceElement(element)
+			if err != nil {
+				return nil, err
+			}
+			if execute {
+				pendingMirrorElements = mirrorElements
+				return element, nil
+			}
+		}
 	}
 
 	// Our "post execution check function" method will check coverage and call all testing functions. If one returns a
@@ -250,11 +418,47 @@ func (fw *FuzzerWorker) testCallSequence() (calls.CallSequence, []ShrinkCallSequ
 	executionCheckFunc := func(currentlyExecutedSequence calls.CallSequence) (bool, error) {
 		// Check for updates to coverage and corpus.
 		// If we detect coverage changes, add this sequence with weight as 1 + sequences tested (to avoid zero weights)
-		err := fw.fuzzer.corpus.AddCallSequenceIfCoverageChanged(currentlyExecutedSequence, fw.getNewCorpusCallSequenceWeight(), true)
+		coverageIncreased, err := fw.fuzzer.corpus.AddCallSequenceIfCoverageChangedWithStateHash(currentlyExecutedSequence, fw.getNewCorpusCallSequenceWeight(), fw.sequenceGenerator.corpusCallSequenceMetadata(), true, fw.deployedContractsStateHash)
 		if err != nil {
 			return true, err
 		}
 
+		// If coverage increased, attribute it to any arguments mutated in the last call, so they are mutated more
+		// often in future iterations, and notify any subscribers.
+		if coverageIncreased {
+			attributedCount := fw.sequenceGenerator.recordCoverageIncrease(currentlyExecutedSequence[len(currentlyExecutedSequence)-1])
+			fw.workerMetrics().productiveArgumentMutations.add(uint64(attributedCount))
+			err = fw.Events.CoverageIncreased.Publish(FuzzerWorkerCoverageIncreasedEvent{
+				Worker:       fw,
+				CallSequence: currentlyExecutedSequence,
+			})
+			if err != nil {
+				return true, fmt.Errorf("error returned by an event handler when a worker emitted an event indicating coverage increased: %v", err)
+			}
+			err = fw.fuzzer.Events.CoverageFound.Publish(FuzzerCoverageFoundEvent{
+				Worker:       fw,
+				CallSequence: currentlyExecutedSequence,
+			})
+			if err != nil {
+				return true, fmt.Errorf("error returned by an event handler when the fuzzer emitted an event indicating coverage increased: %v", err)
+			}
+		}
+
+		// Learn any values returned or emitted by the last call, or compared against during it, so they may be used in
+		// later calls.
+		if fw.fuzzer.config.Fuzzing.ValueSetSeeding.RuntimeValues {
+			fw.learnValuesFromCallResults(currentlyExecutedSequence[len(currentlyExecutedSequence)-1])
+		}
+		if fw.fuzzer.config.Fuzzing.ValueSetSeeding.ComparisonValues {
+			fw.learnValuesFromComparisons(currentlyExecutedSequence[len(currentlyExecutedSequence)-1])
+		}
+		fw.learnCreate2Addresses(currentlyExecutedSequence[len(currentlyExecutedSequence)-1])
+
+		// If the last call upgraded a proxy, its new implementation's methods should be called through it.
+		if fw.updateProxyImplementations(fw.chain) {
+			fw.updateStateChangingMethods()
+		}
+
 		// Loop through each test function, signal our worker tested a call, and collect any requests to shrink
 		// this call sequence.
 		for _, callSequenceTestFunc := range fw.fuzzer.Hooks.CallSequenceTestFuncs {
@@ -266,10 +470,18 @@ func (fw *FuzzerWorker) testCallSequence() (calls.CallSequence, []ShrinkCallSequ
 		}
 
 		// Update our metrics
-		fw.workerMetrics().callsTested.Add(fw.workerMetrics().callsTested, big.NewInt(1))
+		fw.workerMetrics().callsTested.add(1)
+		if callRanOutOfGas(currentlyExecutedSequence[len(currentlyExecutedSequence)-1]) {
+			fw.workerMetrics().callsOutOfGas.add(1)
+		}
+		fw.workerMetrics().methodCalls.recordCall(currentlyExecutedSequence[len(currentlyExecutedSequence)-1])
+		if fw.fuzzer.config.Fuzzing.MethodSelection == config.MethodSelectionAdaptive {
+			fw.workerMetrics().methodSelection.recordCall(currentlyExecutedSequence[len(currentlyExecutedSequence)-1], coverageIncreased)
+		}
 
-		// If our fuzzer context is done, exit out immediately without results.
-		if utils.CheckContextDone(fw.fuzzer.ctx) {
+		// If our fuzzer was forced to stop, exit out immediately without results. If it was stopped gracefully, we
+		// finish testing our call sequence, so any failures it finds are still shrunk and reported.
+		if utils.CheckContextDone(fw.fuzzer.forceStopCtx) {
 			return true, nil
 		}
 
@@ -277,19 +489,25 @@ func (fw *FuzzerWorker) testCallSequence() (calls.CallSequence, []ShrinkCallSequ
 		return len(shrinkCallSequenceRequests) > 0, nil
 	}
 
-	// Execute our call sequence.
+	// Execute our call sequence, then report its outcome to the corpus if it was pending resolution.
 	testedCallSequence, err := calls.ExecuteCallSequenceIteratively(fw.chain, fetchElementFunc, executionCheckFunc)
+	fw.sequenceGenerator.completePendingCorpusSequence()
 
 	// If we encountered an error, report it.
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// If our fuzzer context is done, exit out immediately without results.
-	if utils.CheckContextDone(fw.fuzzer.ctx) {
+	// If our fuzzer was forced to stop, exit out immediately without results.
+	if utils.CheckContextDone(fw.fuzzer.forceStopCtx) {
 		return nil, nil, nil
 	}
 
+	// Record the storage growth of the call sequence before our chain is reverted.
+	if fw.fuzzer.config.Fuzzing.StorageGrowth.Enabled {
+		fw.recordStorageGrowth(testedCallSequence)
+	}
+
 	// If this was not a new call sequence, indicate not to save the shrunken result to the corpus again.
 	if !isNewSequence {
 		for _, shrinkRequest := range shrinkCallSequenceRequests {
@@ -301,8 +519,37 @@ func (fw *FuzzerWorker) testCallSequence() (calls.CallSequence, []ShrinkCallSequ
 	return testedCallSequence, shrinkCallSequenceRequests, nil
 }
 
+// mirrorCallSequenceElement calls every FuzzerHooks.CallMirrorFuncs function with the provided call sequence element,
+// which is about to be executed.
+// Returns the elements to execute after the provided element, a boolean indicating whether the provided element
+// should be executed, or an error if one occurs.
+func (fw *FuzzerWorker) mirrorCallSequenceElement(element *calls.CallSequenceElement) ([]*calls.CallSequenceElement, bool, error) {
+	mirrorElements := make([]*calls.CallSequenceElement, 0)
+	for _, callMirrorFunc := range fw.fuzzer.Hooks.CallMirrorFuncs {
+		newMirrorElements, execute, err := callMirrorFunc(fw, element)
+		if err != nil {
+			return nil, false, err
+		}
+		if !execute {
+			return nil, false, nil
+		}
+		mirrorElements = append(mirrorElements, newMirrorElements...)
+	}
+	return mirrorElements, true, nil
+}
+
+// callSequenceDataLength obtains the total length of the call data of each call in the provided call sequence.
+func callSequenceDataLength(callSequence calls.CallSequence) int {
+	length := 0
+	for _, element := range callSequence {
+		length += len(element.Call.Data())
+	}
+	return length
+}
+
 // shrinkCallSequence takes a provided call sequence and attempts to shrink it by looking for redundant
-// calls which can be removed that continue to satisfy the provided shrink verifier.
+// calls which can be removed that continue to satisfy the provided shrink verifier. Shrinking stops early once the
+// configured shrink limit or timeout is reached, in which case the smallest call sequence found so far is used.
 // Returns a call sequence that was optimized to include as little calls as possible to trigger the
 // expected conditions, or an error if one occurred.
 func (fw *FuzzerWorker) shrinkCallSequence(callSequence calls.CallSequence, shrinkRequest ShrinkCallSequenceRequest) (calls.CallSequence, error) {
@@ -318,97 +565,174 @@ func (fw *FuzzerWorker) shrinkCallSequence(callSequence calls.CallSequence, shri
 	// Define a variable to track our most optimized sequence across all optimization iterations.
 	optimizedSequence := callSequence
 
-	for i := 0; i < len(optimizedSequence); {
-		// Recreate our current optimized sequence without the item at this index
-		possibleShrunkSequence, err := optimizedSequence.Clone()
-		if err != nil {
-			return nil, err
+	// Track how many candidate sequences we executed and when we started, so we can bound our shrinking efforts. If
+	// the fuzzer is stopped gracefully, we also track when we noticed, so we only continue for the stop shrink timeout.
+	testingConfig := fw.fuzzer.config.Fuzzing.Testing
+	shrinkStartTime := time.Now()
+	shrinkAttempts := uint64(0)
+	var stopNoticedTime time.Time
+	shrinkBudgetExhausted := func() bool {
+		if testingConfig.ShrinkLimit > 0 && shrinkAttempts >= testingConfig.ShrinkLimit {
+			return true
 		}
-		possibleShrunkSequence = append(possibleShrunkSequence[:i], possibleShrunkSequence[i+1:]...)
-
-		// Our "fetch next call method" method will simply fetch and fix the call message in case any fields are not correct due to shrinking.
-		fetchElementFunc := func(currentIndex int) (*calls.CallSequenceElement, error) {
-			// If we are at the end of our sequence, return nil indicating we should stop executing.
-			if currentIndex >= len(possibleShrunkSequence) {
-				return nil, nil
+		if utils.CheckContextDone(fw.fuzzer.ctx) {
+			if stopNoticedTime.IsZero() {
+				stopNoticedTime = time.Now()
+			}
+			if time.Since(stopNoticedTime) >= time.Duration(testingConfig.StopShrinkTimeout)*time.Second {
+				return true
 			}
-
-			possibleShrunkSequence[currentIndex].Call.FillFromTestChainProperties(fw.chain)
-			return possibleShrunkSequence[currentIndex], nil
 		}
+		return testingConfig.ShrinkTimeout > 0 && time.Since(shrinkStartTime) >= time.Duration(testingConfig.ShrinkTimeout)*time.Second
+	}
 
-		// Our "post-execution check" method will check coverage and call all testing functions. If one returns a
-		// request for a shrunk call sequence, we exit our call sequence execution immediately to go fulfill the shrink
-		// request.
-		executionCheckFunc := func(currentlyExecutedSequence calls.CallSequence) (bool, error) {
-			// Check for updates to coverage and corpus (using only the section of the sequence we tested so far).
-			// If we detect coverage changes, add this sequence.
-			err := fw.fuzzer.corpus.AddCallSequenceIfCoverageChanged(currentlyExecutedSequence, fw.getNewCorpusCallSequenceWeight(), true)
+	// First, we try to remove each call from the sequence. Candidates which each remove a different call are tested
+	// in batches, spread across workers.
+	for i := 0; i < len(optimizedSequence) && !shrinkBudgetExhausted(); {
+		// Create a batch of candidates, each removing a different call from our current optimized sequence, starting
+		// at this index. We create as many as there are workers, without exceeding our remaining shrink limit.
+		batchSize := uint64(fw.fuzzer.config.Fuzzing.Workers)
+		if testingConfig.ShrinkLimit > 0 && testingConfig.ShrinkLimit-shrinkAttempts < batchSize {
+			batchSize = testingConfig.ShrinkLimit - shrinkAttempts
+		}
+		possibleShrunkSequences := make([]calls.CallSequence, 0, batchSize)
+		for j := i; j < len(optimizedSequence) && uint64(len(possibleShrunkSequences)) < batchSize; j++ {
+			// Recreate our current optimized sequence without the item at this index
+			possibleShrunkSequence, err := optimizedSequence.Clone()
 			if err != nil {
-				return true, err
-			}
-
-			// If our fuzzer context is done, exit out immediately without results.
-			if utils.CheckContextDone(fw.fuzzer.ctx) {
-				return true, nil
+				return nil, err
 			}
-
-			return false, nil
+			possibleShrunkSequences = append(possibleShrunkSequences, append(possibleShrunkSequence[:j], possibleShrunkSequence[j+1:]...))
 		}
 
-		// Execute our call sequence.
-		testedPossibleShrunkSequence, err := calls.ExecuteCallSequenceIteratively(fw.chain, fetchElementFunc, executionCheckFunc)
+		// Test our candidates, exiting early if our fuzzer context is done.
+		results, err := fw.testShrinkCandidates(possibleShrunkSequences, &shrinkRequest)
 		if err != nil {
 			return nil, err
 		}
-
-		// If our fuzzer context is done, exit out immediately without results.
-		if utils.CheckContextDone(fw.fuzzer.ctx) {
+		shrinkAttempts += uint64(len(possibleShrunkSequences))
+		if utils.CheckContextDone(fw.fuzzer.forceStopCtx) {
 			return nil, nil
 		}
 
-		// Check if our verifier signalled that we met our conditions
-		validShrunkSequence := false
-		if len(testedPossibleShrunkSequence) > 0 {
-			validShrunkSequence, err = shrinkRequest.VerifierFunction(fw, testedPossibleShrunkSequence)
-			if err != nil {
-				return nil, err
+		// Each candidate was derived from the same optimized sequence, so we can only use one of them. We use the
+		// earliest removal which satisfied our verifier and continue from its index, as the removals following it
+		// must be tested again against the new optimized sequence. If none did, we move past the whole batch.
+		removedIndex := -1
+		for k, result := range results {
+			if result.valid {
+				removedIndex = k
+				break
 			}
 		}
+		if removedIndex >= 0 {
+			optimizedSequence = results[removedIndex].testedCallSequence
+			i += removedIndex
+		} else {
+			i += len(results)
+		}
+	}
+
+	// Next, if enabled, we try to simplify the ABI argument values of each remaining call.
+	if testingConfig.ShrinkCallArguments {
+		for i := 0; i < len(optimizedSequence) && !shrinkBudgetExhausted(); i++ {
+			// Calls with raw call data have no arguments we can simplify.
+			abiValues := optimizedSequence[i].Call.MsgDataAbiValues
+			if abiValues == nil || abiValues.Method == nil {
+				continue
+			}
+
+			for j := 0; j < len(abiValues.Method.Inputs) && !shrinkBudgetExhausted(); j++ {
+				// Shrink the argument, testing each candidate value in a copy of our optimized sequence. Any candidate
+				// which satisfies our verifier sets our optimized sequence, so it holds the simplest value found.
+				_, err := valuegeneration.ShrinkAbiValue(&abiValues.Method.Inputs[j].Type, abiValues.InputValues[j], fw.fuzzer.senders, func(candidate any) (bool, error) {
+					if utils.CheckContextDone(fw.fuzzer.forceStopCtx) || shrinkBudgetExhausted() {
+						return false, nil
+					}
+					possibleShrunkSequence, err := optimizedSequence.Clone()
+					if err != nil {
+						return false, err
+					}
+					possibleShrunkSequence[i].Call.MsgDataAbiValues.InputValues[j] = candidate
+
+					// Test the candidate, using it as our optimized sequence if it satisfies our verifier.
+					shrinkAttempts++
+					testedPossibleShrunkSequence, validShrunkSequence, err := fw.testShrinkCandidate(possibleShrunkSequence, &shrinkRequest)
+					if validShrunkSequence {
+						optimizedSequence = testedPossibleShrunkSequence
+					}
+					return validShrunkSequence, err
+				})
+				if err != nil {
+					return nil, err
+				}
+				if utils.CheckContextDone(fw.fuzzer.forceStopCtx) {
+					return nil, nil
+				}
+
+				// Our optimized sequence may have been replaced, so we obtain its values again.
+				abiValues = optimizedSequence[i].Call.MsgDataAbiValues
+			}
+		}
+	}
+
+	// Report how much our shrinking reduced the call sequence, and whether it was stopped early.
+	shrinkCompletion := "completed"
+	if shrinkBudgetExhausted() {
+		shrinkCompletion = "stopped early after reaching the shrink limit or timeout, or as the fuzzer stopped"
+	}
+	shrinkDuration := time.Since(shrinkStartTime).Round(time.Millisecond)
+	logging.GlobalLogger.Info().
+		Int("worker", fw.workerIndex).
+		Int("sequenceLength", len(callSequence)).
+		Int("shrunkSequenceLength", len(optimizedSequence)).
+		Uint64("attempts", shrinkAttempts).
+		Dur("duration", shrinkDuration).
+		Bool("stoppedEarly", shrinkBudgetExhausted()).
+		Msgf(
+			"Shrinking %v: eliminated %d of %d calls and %d of %d call data bytes after %d attempts in %v",
+			shrinkCompletion,
+			len(callSequence)-len(optimizedSequence), len(callSequence),
+			callSequenceDataLength(callSequence)-callSequenceDataLength(optimizedSequence), callSequenceDataLength(callSequence),
+			shrinkAttempts, shrinkDuration,
+		)
+
+	// We have a finalized call sequence, re-execute it, so our current chain state is representative of post-execution.
+	_, err = calls.ExecuteCallSequence(fw.chain, optimizedSequence)
+	if err != nil {
+		return nil, err
+	}
 
-		// After testing the sequence, we'll want to rollback changes to reset our testing state.
+	// As candidates may have been tested by other workers, verify the sequence reproduces our conditions when executed
+	// serially on this worker. If it does not, we fall back to the original sequence.
+	validShrunkSequence, err := shrinkRequest.VerifierFunction(fw, optimizedSequence)
+	if err != nil {
+		return nil, err
+	}
+	if !validShrunkSequence {
 		if err = fw.chain.RevertToBlockNumber(fw.testingBaseBlockNumber); err != nil {
 			return nil, err
 		}
-
-		// If this current sequence satisfied our conditions, set it as our optimized sequence.
-		if validShrunkSequence {
-			optimizedSequence = testedPossibleShrunkSequence
-		} else {
-			// We didn't remove an item at this index, so we'll iterate to the next one.
-			i++
+		optimizedSequence = callSequence
+		_, err = calls.ExecuteCallSequence(fw.chain, optimizedSequence)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	// If the shrink request wanted the sequence recorded in the corpus, do so now.
 	if shrinkRequest.RecordResultInCorpus {
-		err = fw.fuzzer.corpus.AddCallSequence(optimizedSequence, fw.getNewCorpusCallSequenceWeight(), true)
+		err = fw.fuzzer.corpus.AddCallSequence(optimizedSequence, fw.getNewCorpusCallSequenceWeight(), fw.newCorpusCallSequenceMetadata(corpus.CallSequenceOriginShrink), true)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// We have a finalized call sequence, re-execute it, so our current chain state is representative of post-execution.
-	_, err = calls.ExecuteCallSequence(fw.chain, optimizedSequence)
-	if err != nil {
-		return nil, err
-	}
-
 	// Shrinking is complete. If our config specified we want all result sequences to have execution traces attached,
 	// attach them now to each element in the sequence. Otherwise, call sequences will only have traces that the
 	// test providers choose to attach themselves.
 	if fw.fuzzer.config.Fuzzing.Testing.TraceAll {
-		err = optimizedSequence.AttachExecutionTraces(fw.chain, fw.fuzzer.contractDefinitions)
+		err = optimizedSequence.AttachExecutionTraces(fw.chain, fw.fuzzer.contractDefinitions, fw.fuzzer.traceStorageWrites())
 		if err != nil {
 			return nil, err
 		}
@@ -423,6 +747,19 @@ func (fw *FuzzerWorker) shrinkCallSequence(callSequence calls.CallSequence, shri
 	return optimizedSequence, err
 }
 
+// WorkerResetCause describes the reason a FuzzerWorker was destroyed so it could be recreated with a fresh chain.
+type WorkerResetCause string
+
+const (
+	// WorkerResetCauseSequenceLimit indicates the worker tested the call sequences allowed by the
+	// FuzzingConfig.WorkerResetLimit.
+	WorkerResetCauseSequenceLimit WorkerResetCause = "sequenceLimit"
+
+	// WorkerResetCauseMemoryLimit indicates the database of the worker's chain grew beyond the
+	// FuzzingConfig.WorkerMemoryLimit.
+	WorkerResetCauseMemoryLimit WorkerResetCause = "memoryLimit"
+)
+
 // run takes a base Chain in a setup state ready for testing, clones it, and begins executing fuzzed transaction calls
 // and asserting properties are upheld. This runs until Fuzzer.ctx cancels the operation.
 // Returns a boolean indicating whether Fuzzer.ctx has indicated we cancel the operation, and an error if one occurred.
@@ -448,8 +785,36 @@ func (fw *FuzzerWorker) run(baseTestChain *chain.TestChain) (bool, error) {
 		// If we have coverage-guided fuzzing enabled, create a tracer to collect coverage and connect it to the chain.
 		if fw.fuzzer.config.Fuzzing.CoverageEnabled {
 			fw.coverageTracer = coverage.NewCoverageTracer()
+			fw.coverageTracer.SetEdgeCoverageEnabled(fw.fuzzer.config.Fuzzing.CoverageFeedback.UsesEdgeCoverage())
+			fw.coverageTracer.SetHitCountsEnabled(fw.fuzzer.config.Fuzzing.CoverageHitCounts)
 			initializedChain.AddTracer(fw.coverageTracer, true, false)
 		}
+
+		// If we have comparison values enabled, create a tracer to collect comparison operands and connect it to the
+		// chain.
+		if fw.fuzzer.config.Fuzzing.ValueSetSeeding.ComparisonValues {
+			fw.comparisonTracer = comparisontracer.NewComparisonTracer()
+			initializedChain.AddTracer(fw.comparisonTracer, true, false)
+		}
+
+		// Compute the address of every contract created through CREATE2, so they may be provided in later calls.
+		initializedChain.AddTracer(create2tracer.NewCreate2Tracer(), true, false)
+
+		// If we fuzz transaction gas limits, record whether calls run out of gas, so failures caused by the gas limits
+		// we provide can be distinguished from others.
+		if fw.fuzzer.config.Fuzzing.FuzzTransactionGasLimits {
+			initializedChain.AddTracer(gastracer.NewOutOfGasTracer(), true, false)
+		}
+
+		// If swallowed panics fail assertion tests, record the panics raised by inner calls which were caught.
+		if fw.fuzzer.config.Fuzzing.Testing.AssertionTesting.Enabled && fw.fuzzer.config.Fuzzing.Testing.AssertionTesting.FailOnSwallowedPanics {
+			initializedChain.AddTracer(panictracer.NewSwallowedPanicTracer(), true, false)
+		}
+
+		// If we record storage growth, count the storage slots each call writes to and populates in each contract.
+		if fw.fuzzer.config.Fuzzing.StorageGrowth.Enabled {
+			initializedChain.AddTracer(storagetracer.NewStorageGrowthTracer(), true, false)
+		}
 		return nil
 	})
 
@@ -468,18 +833,21 @@ func (fw *FuzzerWorker) run(baseTestChain *chain.TestChain) (bool, error) {
 	}
 
 	// Increase our generation metric as we successfully generated a test node
-	fw.workerMetrics().workerStartupCount.Add(fw.workerMetrics().workerStartupCount, big.NewInt(1))
+	fw.workerMetrics().workerStartupCount.add(1)
 
 	// Save the current block number as all contracts have been deployed at this point, and we'll want to revert
 	// to this state between testing.
 	fw.testingBaseBlockNumber = fw.chain.HeadBlockNumber()
 
-	// Enter the main fuzzing loop, restricting our memory database size based on our config variable.
-	// When the limit is reached, we exit this method gracefully, which will cause the fuzzing to recreate
+	// Enter the main fuzzing loop, restricting our memory database size based on our config variables.
+	// When a limit is reached, we exit this method gracefully, which will cause the fuzzing to recreate
 	// this worker with a fresh memory database.
+	memoryLimit := uint64(fw.fuzzer.config.Fuzzing.WorkerMemoryLimit) * 1024 * 1024
 	sequencesTested := 0
 	for sequencesTested <= fw.fuzzer.config.Fuzzing.WorkerResetLimit {
-		// If our context signalled to close the operation, exit our testing loop accordingly, otherwise continue.
+		// If our context signalled to close the operation, exit our testing loop accordingly, otherwise continue. If
+		// we were paused from testing new call sequences, wait until we are resumed first.
+		fw.fuzzer.waitWhileGenerationPaused()
 		if utils.CheckContextDone(fw.fuzzer.ctx) {
 			return true, nil
 		}
@@ -492,19 +860,29 @@ func (fw *FuzzerWorker) run(baseTestChain *chain.TestChain) (bool, error) {
 			return false, fmt.Errorf("error returned by an event handler when a worker emitted an event indicating testing of a new call sequence is starting: %v", err)
 		}
 
-		// Test a new sequence
+		// Test any candidates other workers queued while shrinking call sequences, then test a new sequence.
+		fw.helpShrinkCallSequences()
 		callSequence, shrinkVerifiers, err := fw.testCallSequence()
 		if err != nil {
 			return false, err
 		}
 
-		// If we have any requests to shrink call sequences, do so now.
+		// If we have any requests to shrink call sequences, do so now. Until they are shrunk, our call sequence is
+		// recorded in checkpoints, so its failures are not lost if the campaign stops.
+		if len(shrinkVerifiers) > 0 {
+			unshrunkCallSequence, err := callSequence.Clone()
+			if err != nil {
+				return false, err
+			}
+			fw.fuzzer.setUnshrunkCallSequence(fw.workerIndex, unshrunkCallSequence)
+		}
 		for _, shrinkVerifier := range shrinkVerifiers {
 			_, err = fw.shrinkCallSequence(callSequence, shrinkVerifier)
 			if err != nil {
 				return false, err
 			}
 		}
+		fw.fuzzer.setUnshrunkCallSequence(fw.workerIndex, nil)
 
 		// Emit an event indicating the worker is about to test a new call sequence.
 		err = fw.Events.CallSequenceTested.Publish(FuzzerWorkerCallSequenceTestedEvent{
@@ -515,10 +893,19 @@ func (fw *FuzzerWorker) run(baseTestChain *chain.TestChain) (bool, error) {
 		}
 
 		// Update our sequences tested metrics
-		fw.workerMetrics().sequencesTested.Add(fw.workerMetrics().sequencesTested, big.NewInt(1))
+		fw.workerMetrics().sequencesTested.add(1)
 		sequencesTested++
+
+		// If our chain's database grew beyond our memory limit, exit so this worker is regenerated with a fresh one.
+		if memoryLimit > 0 && fw.chain.DatabaseSize() >= memoryLimit {
+			fw.workerMetrics().workerResets[WorkerResetCauseMemoryLimit].add(1)
+			fw.resetCause = WorkerResetCauseMemoryLimit
+			return false, nil
+		}
 	}
 
 	// We have not cancelled fuzzing operations, but this worker exited, signalling for it to be regenerated.
+	fw.workerMetrics().workerResets[WorkerResetCauseSequenceLimit].add(1)
+	fw.resetCause = WorkerResetCauseSequenceLimit
 	return false, nil
 }
@@ -0,0 +1,113 @@
+package fuzzing
+
+import (
+	"math/big"
+	"math/rand"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/config"
+)
+
+const (
+	// secondsPerBlock describes the time between blocks assumed when deriving a block number delay from a block
+	// timestamp delay.
+	secondsPerBlock = 12
+
+	// secondsPerMinute, secondsPerHour and secondsPerDay describe the bounds of the timestamp delays generated for
+	// each kind of block delay.
+	secondsPerMinute = 60
+	secondsPerHour   = 60 * secondsPerMinute
+	secondsPerDay    = 24 * secondsPerHour
+
+	// maxDaysBlockTimestampDelay describes the exclusive upper bound of timestamp delays measured in days.
+	maxDaysBlockTimestampDelay = 30 * secondsPerDay
+)
+
+// generateBlockDelays generates the block number and timestamp delays between a call and the call before it, selecting
+// a kind of delay with a likelihood proportional to its weight in the provided config.
+// Returns the block number delay and block timestamp delay.
+func generateBlockDelays(randomProvider *rand.Rand, fuzzingConfig config.FuzzingConfig) (uint64, uint64) {
+	// Select the kind of delay to generate.
+	weights := fuzzingConfig.BlockDelayWeights
+	position := uint64(randomProvider.Int63n(int64(weights.Total())))
+	kind := 0
+	for i, weight := range []uint64{weights.None, weights.One, weights.Minutes, weights.Hours, weights.Days, weights.Max, weights.Random} {
+		if position < weight {
+			kind = i
+			break
+		}
+		position -= weight
+	}
+
+	// Generate our timestamp delay, deriving the block number delay from it where it is not otherwise defined.
+	var timestampDelay uint64
+	switch kind {
+	case 0:
+		return 0, 0
+	case 1:
+		return capBlockDelays(fuzzingConfig, 1, 1)
+	case 2:
+		timestampDelay = randomUint64InRange(randomProvider, secondsPerMinute, secondsPerHour)
+	case 3:
+		timestampDelay = randomUint64InRange(randomProvider, secondsPerHour, secondsPerDay)
+	case 4:
+		timestampDelay = randomUint64InRange(randomProvider, secondsPerDay, maxDaysBlockTimestampDelay)
+	case 5:
+		return capBlockDelays(fuzzingConfig, fuzzingConfig.MaxBlockNumberDelay, fuzzingConfig.MaxBlockTimestampDelay)
+	default:
+		numberDelay := randomProvider.Uint64() % (fuzzingConfig.MaxBlockNumberDelay + 1)
+		timestampDelay = randomProvider.Uint64() % (fuzzingConfig.MaxBlockTimestampDelay + 1)
+		return capBlockDelays(fuzzingConfig, numberDelay, timestampDelay)
+	}
+	numberDelay := timestampDelay / secondsPerBlock
+	if numberDelay == 0 {
+		numberDelay = 1
+	}
+	return capBlockDelays(fuzzingConfig, numberDelay, timestampDelay)
+}
+
+// capBlockDelays caps the provided block number and timestamp delays to their maximums in the provided config. As
+// each block must have a unique timestamp, the block number delay is also capped to the timestamp delay.
+// Returns the capped block number delay and block timestamp delay.
+func capBlockDelays(fuzzingConfig config.FuzzingConfig, numberDelay uint64, timestampDelay uint64) (uint64, uint64) {
+	if numberDelay > fuzzingConfig.MaxBlockNumberDelay {
+		numberDelay = fuzzingConfig.MaxBlockNumberDelay
+	}
+	if timestampDelay > fuzzingConfig.MaxBlockTimestampDelay {
+		timestampDelay = fuzzingConfig.MaxBlockTimestampDelay
+	}
+	if numberDelay > timestampDelay {
+		numberDelay = timestampDelay
+	}
+	return numberDelay, timestampDelay
+}
+
+// randomUint64InRange generates a random integer which is at least min, but less than max.
+func randomUint64InRange(randomProvider *rand.Rand, min uint64, max uint64) uint64 {
+	return min + uint64(randomProvider.Int63n(int64(max-min)))
+}
+
+// mutateBlockDelays mutates the block number and timestamp delays of the provided call sequence element with a
+// probability of CallSequenceGeneratorConfig.MutateBlockDelayProbability. The delays are either generated anew, or
+// mutated like any other integer and wrapped within their maximum configured values.
+func (g *CallSequenceGenerator) mutateBlockDelays(element *calls.CallSequenceElement) {
+	// Determine whether we should mutate the delays at all.
+	randomProvider := g.worker.randomProvider
+	if randomProvider.Float32() >= g.config.MutateBlockDelayProbability {
+		return
+	}
+
+	// Either generate new delays, or mutate the existing ones.
+	fuzzingConfig := g.worker.fuzzer.config.Fuzzing
+	if randomProvider.Intn(2) == 0 {
+		element.BlockNumberDelay, element.BlockTimestampDelay = generateBlockDelays(randomProvider, fuzzingConfig)
+		return
+	}
+	numberDelay := g.config.ValueGenerator.MutateInteger(new(big.Int).SetUint64(element.BlockNumberDelay), false, 64).Uint64()
+	timestampDelay := g.config.ValueGenerator.MutateInteger(new(big.Int).SetUint64(element.BlockTimestampDelay), false, 64).Uint64()
+	element.BlockNumberDelay, element.BlockTimestampDelay = capBlockDelays(
+		fuzzingConfig,
+		numberDelay%(fuzzingConfig.MaxBlockNumberDelay+1),
+		timestampDelay%(fuzzingConfig.MaxBlockTimestampDelay+1),
+	)
+}
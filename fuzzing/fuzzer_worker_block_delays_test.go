@@ -0,0 +1,53 @@
+package fuzzing
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGenerateBlockDelays ensures block delays are generated within the bounds of the kind of delay selected, and
+// within the maximum configured delays.
+func TestGenerateBlockDelays(t *testing.T) {
+	randomProvider := rand.New(rand.NewSource(0))
+	fuzzingConfig := config.FuzzingConfig{MaxBlockNumberDelay: 60480, MaxBlockTimestampDelay: 604800}
+
+	// Each kind of delay should only produce delays within its bounds.
+	bounds := []struct {
+		weights                    config.BlockDelayWeightsConfig
+		minTimestamp, maxTimestamp uint64
+	}{
+		{config.BlockDelayWeightsConfig{None: 1}, 0, 0},
+		{config.BlockDelayWeightsConfig{One: 1}, 1, 1},
+		{config.BlockDelayWeightsConfig{Minutes: 1}, secondsPerMinute, secondsPerHour - 1},
+		{config.BlockDelayWeightsConfig{Hours: 1}, secondsPerHour, secondsPerDay - 1},
+		{config.BlockDelayWeightsConfig{Days: 1}, secondsPerDay, fuzzingConfig.MaxBlockTimestampDelay},
+		{config.BlockDelayWeightsConfig{Max: 1}, fuzzingConfig.MaxBlockTimestampDelay, fuzzingConfig.MaxBlockTimestampDelay},
+		{config.BlockDelayWeightsConfig{Random: 1}, 0, fuzzingConfig.MaxBlockTimestampDelay},
+	}
+	for _, bound := range bounds {
+		fuzzingConfig.BlockDelayWeights = bound.weights
+		for i := 0; i < 100; i++ {
+			numberDelay, timestampDelay := generateBlockDelays(randomProvider, fuzzingConfig)
+			assert.GreaterOrEqual(t, timestampDelay, bound.minTimestamp)
+			assert.LessOrEqual(t, timestampDelay, bound.maxTimestamp)
+			assert.LessOrEqual(t, numberDelay, fuzzingConfig.MaxBlockNumberDelay)
+			assert.LessOrEqual(t, numberDelay, timestampDelay)
+			if timestampDelay > 0 && bound.weights.Random == 0 {
+				assert.Greater(t, numberDelay, uint64(0))
+			}
+		}
+	}
+
+	// Delays should be capped to their configured maximums, with block numbers never advancing faster than
+	// timestamps.
+	fuzzingConfig.MaxBlockNumberDelay, fuzzingConfig.MaxBlockTimestampDelay = 5, 100
+	numberDelay, timestampDelay := capBlockDelays(fuzzingConfig, 10, 1000)
+	assert.EqualValues(t, 5, numberDelay)
+	assert.EqualValues(t, 100, timestampDelay)
+	numberDelay, timestampDelay = capBlockDelays(fuzzingConfig, 5, 3)
+	assert.EqualValues(t, 3, numberDelay)
+	assert.EqualValues(t, 3, timestampDelay)
+}
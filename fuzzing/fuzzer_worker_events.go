@@ -3,6 +3,7 @@ package fuzzing
 import (
 	"github.com/crytic/medusa/chain"
 	"github.com/crytic/medusa/events"
+	"github.com/crytic/medusa/fuzzing/calls"
 	"github.com/crytic/medusa/fuzzing/contracts"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -31,6 +32,10 @@ type FuzzerWorkerEvents struct {
 	// CallSequenceTested emits events when the FuzzerWorker has finished generating and testing a
 	// new call sequence.
 	CallSequenceTested events.EventEmitter[FuzzerWorkerCallSequenceTestedEvent]
+
+	// CoverageIncreased emits events when a call sequence executed by the FuzzerWorker increased the coverage of the
+	// campaign and was added to the corpus.
+	CoverageIncreased events.EventEmitter[FuzzerWorkerCoverageIncreasedEvent]
 }
 
 // FuzzerWorkerContractAddedEvent describes an event where a fuzzing.FuzzerWorker detects a newly deployed contract in
@@ -94,3 +99,13 @@ type FuzzerWorkerCallSequenceTestedEvent struct {
 	// Worker represents the instance of the fuzzing.FuzzerWorker for which the event occurred.
 	Worker *FuzzerWorker
 }
+
+// FuzzerWorkerCoverageIncreasedEvent describes an event where a call sequence executed by a fuzzing.FuzzerWorker
+// increased the coverage of the campaign.
+type FuzzerWorkerCoverageIncreasedEvent struct {
+	// Worker represents the instance of the fuzzing.FuzzerWorker for which the event occurred.
+	Worker *FuzzerWorker
+
+	// CallSequence describes the call sequence, executed up to the call which increased coverage.
+	CallSequence calls.CallSequence
+}
@@ -0,0 +1,75 @@
+package fuzzing
+
+import (
+	"math/big"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/gastracer"
+	"github.com/ethereum/go-ethereum/core"
+)
+
+// interestingGasLimits describes gas limits which generated calls are given, or which are mutated to derive a gas
+// limit, when transaction gas limits are fuzzed: the cost of a plain transfer, and amounts which commonly bound the
+// gas forwarded to other contracts. The transaction and block gas limits are drawn from alongside them.
+var interestingGasLimits = []uint64{21_000, 100_000, 1_000_000}
+
+// generateGasLimit generates the gas limit for the provided call. If the FuzzingConfig does not enable transaction gas
+// limit fuzzing, this is always the TransactionGasLimit. Otherwise, it is often still the TransactionGasLimit, but may
+// be an interesting gas limit or the block gas limit, any of which may be mutated. The result is at least the
+// intrinsic gas of the call, so it can be executed, and at most the block gas limit.
+// Returns the generated gas limit.
+func (g *CallSequenceGenerator) generateGasLimit(msg *calls.CallMessage) uint64 {
+	// If we do not fuzz gas limits, or we decide against it for this call, use our transaction gas limit.
+	fuzzingConfig := g.worker.fuzzer.config.Fuzzing
+	randomProvider := g.worker.randomProvider
+	if !fuzzingConfig.FuzzTransactionGasLimits || randomProvider.Intn(2) == 0 {
+		return fuzzingConfig.TransactionGasLimit
+	}
+
+	// Select a gas limit from our interesting values, and possibly mutate it.
+	gasLimits := append(append([]uint64{}, interestingGasLimits...), fuzzingConfig.TransactionGasLimit, fuzzingConfig.BlockGasLimit)
+	gasLimit := gasLimits[randomProvider.Intn(len(gasLimits))]
+	if randomProvider.Intn(2) == 0 {
+		gasLimit = g.config.ValueGenerator.MutateInteger(new(big.Int).SetUint64(gasLimit), false, 64).Uint64()
+	}
+
+	// Bound our gas limit by the gas the call requires before execution, and by the gas a block can provide.
+	intrinsicGas, err := core.IntrinsicGas(msg.ExecutableMessage().Data(), nil, false, true, true, false)
+	if err == nil && gasLimit < intrinsicGas {
+		gasLimit = intrinsicGas
+	}
+	if gasLimit > fuzzingConfig.BlockGasLimit {
+		gasLimit = fuzzingConfig.BlockGasLimit
+	}
+	return gasLimit
+}
+
+// mutateGasLimit generates a new gas limit for the call of the provided call sequence element with a probability of
+// CallSequenceGeneratorConfig.MutateGasLimitProbability, if the FuzzingConfig enables transaction gas limit fuzzing.
+func (g *CallSequenceGenerator) mutateGasLimit(element *calls.CallSequenceElement) {
+	if !g.worker.fuzzer.config.Fuzzing.FuzzTransactionGasLimits || g.worker.randomProvider.Float32() >= g.config.MutateGasLimitProbability {
+		return
+	}
+	element.Call.MsgGas = g.generateGasLimit(element.Call)
+}
+
+// callRanOutOfGas indicates whether the call of the provided executed call sequence element failed because it ran out
+// of gas. This is the case if its top-level call frame ran out of gas, or if any of its call frames did, as recorded by
+// a gastracer.OutOfGasTracer when attached (it is when the FuzzingConfig enables transaction gas limit fuzzing).
+func callRanOutOfGas(element *calls.CallSequenceElement) bool {
+	// If our call was not executed or did not fail, it did not run out of gas.
+	if element.ChainReference == nil {
+		return false
+	}
+	messageResults := element.ChainReference.MessageResults()
+	if !messageResults.ExecutionResult.Failed() {
+		return false
+	}
+
+	// Check both our top-level error and any call frames which ran out of gas below it.
+	if gastracer.IsOutOfGasError(messageResults.ExecutionResult.Err) {
+		return true
+	}
+	outOfGas, _ := gastracer.GetOutOfGasTracerResults(messageResults)
+	return outOfGas
+}
@@ -0,0 +1,226 @@
+package fuzzing
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/config"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+)
+
+const (
+	// methodWeightScale describes the factor configured method weights are scaled by when methods are selected
+	// adaptively, so fractional adjustments to them are preserved.
+	methodWeightScale = 100
+
+	// methodSelectionDecay describes the factor the recorded call outcomes of a method decay by each time it is
+	// called, so adaptive weights reflect the outcomes of its recent calls.
+	methodSelectionDecay = 0.99
+
+	// methodSelectionMaxCoverageBoost describes the maximum factor the weight of a method is boosted by when its
+	// recent calls increased coverage.
+	methodSelectionMaxCoverageBoost = 10.0
+
+	// methodSelectionMinRecentCalls describes the amount of recent calls to a method (after decay) which must be
+	// recorded before its weight is reduced for reverting.
+	methodSelectionMinRecentCalls = 20.0
+
+	// methodSelectionRevertThreshold describes the fraction of recent calls to a method which must revert for its
+	// weight to be reduced.
+	methodSelectionRevertThreshold = 0.95
+
+	// methodSelectionRevertPenalty describes the factor the weight of a method is reduced by when its recent calls
+	// almost always revert.
+	methodSelectionRevertPenalty = 0.1
+)
+
+// methodSelectionStats describes the recent outcomes of the calls to a single method, and its latest weight.
+type methodSelectionStats struct {
+	// recentCalls describes the decayed amount of calls to the method.
+	recentCalls float64
+
+	// recentReverts describes the decayed amount of calls to the method which reverted or otherwise failed.
+	recentReverts float64
+
+	// recentCoverageIncreases describes the decayed amount of calls to the method which increased coverage.
+	recentCoverageIncreases float64
+
+	// weight describes the weight the method was last selected with.
+	weight uint64
+}
+
+// methodSelectionTracker records the recent outcomes of calls for each method, from which adaptive method weights are
+// derived, along with the weight each method was last selected with. It provides thread-synchronization, as weights
+// are read by the Fuzzer while workers update them.
+type methodSelectionTracker struct {
+	// stats describes the recent call outcomes and latest weight of each method.
+	stats map[methodCallMetricsKey]*methodSelectionStats
+
+	// lock provides thread-synchronization to avoid race conditions when accessing stats.
+	lock sync.Mutex
+}
+
+// newMethodSelectionTracker creates a methodSelectionTracker with no recorded call outcomes.
+func newMethodSelectionTracker() *methodSelectionTracker {
+	return &methodSelectionTracker{stats: make(map[methodCallMetricsKey]*methodSelectionStats)}
+}
+
+// getOrCreateStats obtains the stats for the provided method, creating them if they do not yet exist. The lock must
+// be held by the caller.
+func (t *methodSelectionTracker) getOrCreateStats(key methodCallMetricsKey) *methodSelectionStats {
+	stats, ok := t.stats[key]
+	if !ok {
+		stats = &methodSelectionStats{}
+		t.stats[key] = stats
+	}
+	return stats
+}
+
+// recordCall records the outcome of the provided executed call sequence element, and whether it increased coverage,
+// if the method it called can be resolved.
+func (t *methodSelectionTracker) recordCall(element *calls.CallSequenceElement, coverageIncreased bool) {
+	// If the element was not executed or did not call a known method, there is nothing to record.
+	if element.ChainReference == nil {
+		return
+	}
+	method, err := element.Method()
+	if err != nil || method == nil {
+		return
+	}
+	executionResult := element.ChainReference.MessageResults().ExecutionResult
+	reverted := executionResult == nil || executionResult.Failed()
+
+	// Decay the previous outcomes of calls to this method and record this one.
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	stats := t.getOrCreateStats(methodCallMetricsKey{contractName: element.Contract.Name(), methodSignature: method.Sig})
+	stats.recentCalls = stats.recentCalls*methodSelectionDecay + 1
+	stats.recentReverts *= methodSelectionDecay
+	if reverted {
+		stats.recentReverts++
+	}
+	stats.recentCoverageIncreases *= methodSelectionDecay
+	if coverageIncreased {
+		stats.recentCoverageIncreases++
+	}
+}
+
+// weight computes the weight to select the provided method with, given its configured weight. If adapt is true, the
+// configured weight is adjusted by the recent outcomes of calls to the method. The computed weight is recorded as the
+// latest weight of the method.
+// Returns the weight to select the method with.
+func (t *methodSelectionTracker) weight(method *fuzzerTypes.DeployedContractMethod, configuredWeight uint64, adapt bool) uint64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	stats := t.getOrCreateStats(methodCallMetricsKey{contractName: method.Contract.Name(), methodSignature: method.Method.Sig})
+
+	// Without adaptation, or for methods which should never be selected, the configured weight is used as is.
+	if !adapt || configuredWeight == 0 {
+		stats.weight = configuredWeight
+		return stats.weight
+	}
+
+	// Boost methods which recently increased coverage, and penalize those which almost always revert.
+	adjustment := math.Min(1+stats.recentCoverageIncreases, methodSelectionMaxCoverageBoost)
+	if stats.recentCalls >= methodSelectionMinRecentCalls && stats.recentReverts/stats.recentCalls > methodSelectionRevertThreshold {
+		adjustment *= methodSelectionRevertPenalty
+	}
+	stats.weight = uint64(math.Max(1, float64(configuredWeight)*methodWeightScale*adjustment))
+	return stats.weight
+}
+
+// MethodWeight describes the weight a method is selected with when generating new calls.
+type MethodWeight struct {
+	// Contract describes the name of the contract the method belongs to.
+	Contract string `json:"contract"`
+
+	// Method describes the signature of the method.
+	Method string `json:"method"`
+
+	// Weight describes the weight the method was last selected with, averaged across workers.
+	Weight float64 `json:"weight"`
+}
+
+// MethodWeights returns the weight each method was last selected with when generating new calls, averaged across
+// workers, sorted by contract name and method signature. Weights are only recorded if methods are not selected
+// uniformly.
+func (m *FuzzerMetrics) MethodWeights() []MethodWeight {
+	// Sum the weights of each method across workers.
+	sums := make(map[methodCallMetricsKey]float64)
+	counts := make(map[methodCallMetricsKey]int)
+	for _, workerMetrics := range m.workerMetrics {
+		workerMetrics.methodSelection.lock.Lock()
+		for key, stats := range workerMetrics.methodSelection.stats {
+			sums[key] += float64(stats.weight)
+			counts[key]++
+		}
+		workerMetrics.methodSelection.lock.Unlock()
+	}
+
+	methodWeights := make([]MethodWeight, 0, len(sums))
+	for key, sum := range sums {
+		methodWeights = append(methodWeights, MethodWeight{
+			Contract: key.contractName,
+			Method:   key.methodSignature,
+			Weight:   sum / float64(counts[key]),
+		})
+	}
+	sort.Slice(methodWeights, func(i, j int) bool {
+		if methodWeights[i].Contract != methodWeights[j].Contract {
+			return methodWeights[i].Contract < methodWeights[j].Contract
+		}
+		return methodWeights[i].Method < methodWeights[j].Method
+	})
+	return methodWeights
+}
+
+// configuredMethodWeight obtains the weight configured for the provided method, or 1 if none was configured. A weight
+// configured for the contract-qualified method signature takes precedence over one configured for the signature alone.
+func configuredMethodWeight(fuzzingConfig config.FuzzingConfig, method *fuzzerTypes.DeployedContractMethod) uint64 {
+	if weight, ok := fuzzingConfig.MethodWeights[method.Contract.Name()+"."+method.Method.Sig]; ok {
+		return weight
+	}
+	if weight, ok := fuzzingConfig.MethodWeights[method.Method.Sig]; ok {
+		return weight
+	}
+	return 1
+}
+
+// selectStateChangingMethod selects the state changing method the next generated call should target, as described
+// by the FuzzingConfig MethodSelection option.
+// Returns the selected method, or an error if one occurs.
+func (fw *FuzzerWorker) selectStateChangingMethod() (*fuzzerTypes.DeployedContractMethod, error) {
+	// Verify we have state changing methods to call
+	if len(fw.stateChangingMethods) == 0 {
+		return nil, fmt.Errorf("cannot generate fuzzed tx as there are no state changing methods to call")
+	}
+
+	// If methods are selected uniformly, any method is as likely as another.
+	methodSelection := fw.fuzzer.config.Fuzzing.MethodSelection
+	if methodSelection == config.MethodSelectionUniform {
+		return &fw.stateChangingMethods[fw.randomProvider.Intn(len(fw.stateChangingMethods))], nil
+	}
+
+	// Otherwise, we select a method with a likelihood proportional to its weight.
+	adapt := methodSelection == config.MethodSelectionAdaptive
+	weights := make([]uint64, len(fw.stateChangingMethods))
+	totalWeight := uint64(0)
+	for i := range fw.stateChangingMethods {
+		weights[i] = fw.workerMetrics().methodSelection.weight(&fw.stateChangingMethods[i], fw.stateChangingMethodWeights[i], adapt)
+		totalWeight += weights[i]
+	}
+	if totalWeight == 0 {
+		return nil, fmt.Errorf("cannot generate fuzzed tx as all state changing methods have a weight of zero")
+	}
+	position := uint64(fw.randomProvider.Int63n(int64(totalWeight)))
+	for i, weight := range weights {
+		if position < weight {
+			return &fw.stateChangingMethods[i], nil
+		}
+		position -= weight
+	}
+	return nil, fmt.Errorf("could not select a weighted state changing method, selected position does not exist")
+}
@@ -0,0 +1,50 @@
+package fuzzing
+
+import (
+	"testing"
+
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/fuzzing/config"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMethodSelectionWeights ensures configured method weights are resolved by signature, and that adaptive weights
+// are boosted for methods which recently increased coverage and reduced for methods which almost always revert.
+func TestMethodSelectionWeights(t *testing.T) {
+	contract := fuzzerTypes.NewContract("Target", "", &compilationTypes.CompiledContract{}, nil)
+	newMethod := func(name string) *fuzzerTypes.DeployedContractMethod {
+		return &fuzzerTypes.DeployedContractMethod{Contract: contract, Method: abi.NewMethod(name, name, abi.Function, "", false, false, nil, nil)}
+	}
+	productive, reverting, idle := newMethod("productive"), newMethod("reverting"), newMethod("idle")
+
+	// Weights configured for contract-qualified signatures take precedence over those for signatures alone.
+	fuzzingConfig := config.FuzzingConfig{MethodWeights: map[string]uint64{"productive()": 2, "Target.productive()": 5, "idle()": 0}}
+	assert.EqualValues(t, 5, configuredMethodWeight(fuzzingConfig, productive))
+	assert.EqualValues(t, 0, configuredMethodWeight(fuzzingConfig, idle))
+	assert.EqualValues(t, 1, configuredMethodWeight(fuzzingConfig, reverting))
+
+	// Record recent call outcomes for our methods.
+	tracker := newMethodSelectionTracker()
+	tracker.stats[methodCallMetricsKey{contractName: "Target", methodSignature: "productive()"}] = &methodSelectionStats{recentCalls: 50, recentCoverageIncreases: 2}
+	tracker.stats[methodCallMetricsKey{contractName: "Target", methodSignature: "reverting()"}] = &methodSelectionStats{recentCalls: 50, recentReverts: 49}
+
+	// Without adaptation, configured weights are used as is.
+	assert.EqualValues(t, 5, tracker.weight(productive, 5, false))
+	assert.EqualValues(t, 1, tracker.weight(reverting, 1, false))
+
+	// With adaptation, weights are scaled and adjusted by recent call outcomes, but methods with no weight are never
+	// selected.
+	assert.EqualValues(t, 5*methodWeightScale*3, tracker.weight(productive, 5, true))
+	assert.EqualValues(t, methodWeightScale*methodSelectionRevertPenalty, tracker.weight(reverting, 1, true))
+	assert.EqualValues(t, 0, tracker.weight(idle, 0, true))
+
+	// The latest weight of each method is recorded, so it can be reported.
+	metrics := &FuzzerMetrics{workerMetrics: []fuzzerWorkerMetrics{{methodSelection: tracker}}}
+	assert.EqualValues(t, []MethodWeight{
+		{Contract: "Target", Method: "idle()", Weight: 0},
+		{Contract: "Target", Method: "productive()", Weight: 5 * methodWeightScale * 3},
+		{Contract: "Target", Method: "reverting()", Weight: methodWeightScale * methodSelectionRevertPenalty},
+	}, metrics.MethodWeights())
+}
@@ -0,0 +1,218 @@
+package fuzzing
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/comparisontracer"
+	"github.com/crytic/medusa/fuzzing/create2tracer"
+	"github.com/crytic/medusa/utils/reflectionutils"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/slices"
+)
+
+// learnValuesFromCallResults decodes the return data and event logs produced by the provided call sequence element
+// using the ABIs of known contract definitions, and adds any decoded values to the worker's value set.
+func (fw *FuzzerWorker) learnValuesFromCallResults(element *calls.CallSequenceElement) {
+	// If the element was not executed, there are no results to learn from.
+	if element.ChainReference == nil {
+		return
+	}
+	messageResults := element.ChainReference.MessageResults()
+
+	// Decode the return data of the call, if it succeeded and we can resolve the method called.
+	if messageResults.ExecutionResult != nil && !messageResults.ExecutionResult.Failed() {
+		method, err := element.Method()
+		if err == nil && method != nil && len(method.Outputs) > 0 {
+			if returnValues, err := method.Outputs.Unpack(messageResults.ExecutionResult.ReturnData); err == nil {
+				for _, returnValue := range returnValues {
+					fw.learnValue(returnValue)
+				}
+			}
+		}
+	}
+
+	// Decode any event logs emitted by contracts we know the definitions of.
+	if messageResults.Receipt == nil {
+		return
+	}
+	for _, log := range messageResults.Receipt.Logs {
+		// Indexed event arguments are stored as topics, which we learn as raw words. The first topic is the event
+		// identifier, so it is skipped.
+		for i := 1; i < len(log.Topics); i++ {
+			fw.learnValue(new(big.Int).SetBytes(log.Topics[i].Bytes()))
+			if common.BytesToAddress(log.Topics[i].Bytes()).Hash() == log.Topics[i] {
+				fw.learnValue(common.BytesToAddress(log.Topics[i].Bytes()))
+			}
+		}
+
+		// Resolve the event definition to decode its non-indexed arguments.
+		contractDefinition, ok := fw.deployedContracts[log.Address]
+		if !ok || len(log.Topics) == 0 {
+			continue
+		}
+
+		// Events emitted by proxies may be declared by their implementation instead.
+		event, err := contractDefinition.CompiledContract().Abi.EventByID(log.Topics[0])
+		if implementation, isProxy := fw.proxyImplementations[log.Address]; isProxy && (err != nil || event == nil) {
+			event, err = implementation.CompiledContract().Abi.EventByID(log.Topics[0])
+		}
+		if err != nil || event == nil {
+			continue
+		}
+		if eventValues, err := event.Inputs.Unpack(log.Data); err == nil {
+			for _, eventValue := range eventValues {
+				fw.learnValue(eventValue)
+			}
+		}
+	}
+}
+
+// learnValuesFromComparisons adds the operands of comparison instructions recorded by the worker's comparison tracer
+// while executing the provided call sequence element to the worker's value set, so that values a contract compares
+// its inputs against may be provided in later calls. The recorded operands are removed from the element's results.
+func (fw *FuzzerWorker) learnValuesFromComparisons(element *calls.CallSequenceElement) {
+	// If the element was not executed, there are no results to learn from.
+	if element.ChainReference == nil {
+		return
+	}
+	messageResults := element.ChainReference.MessageResults()
+	operands := comparisontracer.GetComparisonTracerResults(messageResults)
+	comparisontracer.RemoveComparisonTracerResults(messageResults)
+
+	for _, operand := range operands {
+		// Every operand is learned as an unsigned integer. Operands of signed comparisons with their sign bit set are
+		// also learned as negative integers.
+		value := operand.Value.ToBig()
+		fw.learnValue(value)
+		if operand.Signed && operand.Value.Sign() < 0 {
+			fw.learnValue(new(big.Int).Sub(value, new(big.Int).Lsh(big.NewInt(1), 256)))
+		}
+
+		// Words which are left-aligned (e.g. bytes4 selectors or bytes16 magic values) are learned as byte sequences
+		// of their significant length.
+		word := operand.Value.Bytes32()
+		if significantLength := len(bytes.TrimRight(word[:], "\x00")); significantLength < len(word) {
+			fw.learnValue(word[:significantLength])
+		}
+
+		// Words which fit in an address, but are too large to be a typical integer, are learned as addresses.
+		if bitLen := operand.Value.BitLen(); bitLen > 64 && bitLen <= 160 {
+			fw.learnValue(common.BytesToAddress(word[:]))
+		}
+	}
+}
+
+// learnCreate2Addresses adds the addresses of contracts created through CREATE2, recorded by the worker's CREATE2
+// tracer while executing the provided call sequence element, to the worker's value set. As CREATE2 addresses do not
+// depend on the state of the chain, this allows calls to reference these contracts before they are created in later
+// call sequences, or after their creation failed. The recorded addresses are removed from the element's results.
+func (fw *FuzzerWorker) learnCreate2Addresses(element *calls.CallSequenceElement) {
+	// If the element was not executed, there are no results to learn from.
+	if element.ChainReference == nil {
+		return
+	}
+	messageResults := element.ChainReference.MessageResults()
+	addresses := create2tracer.GetCreate2TracerResults(messageResults)
+	create2tracer.RemoveCreate2TracerResults(messageResults)
+
+	// Addresses of contracts created by this call were already added to our value set when they were deployed, but are
+	// still learned, so they are kept in the value set once the contracts are removed.
+	createdAddresses := make(map[common.Address]struct{})
+	for _, deploymentChange := range messageResults.ContractDeploymentChanges {
+		if deploymentChange.Creation {
+			createdAddresses[deploymentChange.Contract.Address] = struct{}{}
+		}
+	}
+
+	for _, address := range addresses {
+		// Addresses which were already learned, or which exist in the value set for other reasons, are ignored, so
+		// that values seeded prior to fuzzing are never evicted.
+		if _, ok := fw.create2Addresses[address]; ok {
+			continue
+		}
+		if _, ok := createdAddresses[address]; !ok {
+			if fw.valueSet.ContainsAddress(address) {
+				continue
+			}
+			fw.valueSet.AddAddress(address)
+		}
+		fw.create2Addresses[address] = struct{}{}
+		address := address
+		fw.addRuntimeValueRemover(func() {
+			delete(fw.create2Addresses, address)
+			fw.valueSet.RemoveAddress(address)
+		})
+	}
+}
+
+// learnValue adds a decoded ABI value to the worker's value set. Arrays, slices, and structs are walked recursively
+// to learn each underlying value. Values which already exist in the value set are ignored, so that values seeded
+// prior to fuzzing are never evicted.
+func (fw *FuzzerWorker) learnValue(value any) {
+	// Switch on our value type to determine how to add it to the value set.
+	switch v := value.(type) {
+	case common.Address:
+		if !fw.valueSet.ContainsAddress(v) {
+			fw.valueSet.AddAddress(v)
+			fw.addRuntimeValueRemover(func() { fw.valueSet.RemoveAddress(v) })
+		}
+		return
+	case *big.Int:
+		if !fw.valueSet.ContainsInteger(v) {
+			fw.valueSet.AddInteger(v)
+			fw.addRuntimeValueRemover(func() { fw.valueSet.RemoveInteger(v) })
+		}
+		return
+	case string:
+		if !fw.valueSet.ContainsString(v) {
+			fw.valueSet.AddString(v)
+			fw.addRuntimeValueRemover(func() { fw.valueSet.RemoveString(v) })
+		}
+		return
+	case []byte:
+		if !fw.valueSet.ContainsBytes(v) {
+			b := slices.Clone(v)
+			fw.valueSet.AddBytes(b)
+			fw.addRuntimeValueRemover(func() { fw.valueSet.RemoveBytes(b) })
+		}
+		return
+	}
+
+	// Handle remaining types through reflection.
+	reflectedValue := reflect.ValueOf(value)
+	switch reflectedValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fw.learnValue(big.NewInt(reflectedValue.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fw.learnValue(new(big.Int).SetUint64(reflectedValue.Uint()))
+	case reflect.Array:
+		// Fixed-size byte arrays are learned as bytes, other arrays are walked.
+		if reflectedValue.Type().Elem().Kind() == reflect.Uint8 {
+			fw.learnValue(reflectionutils.ArrayToSlice(reflectedValue).([]byte))
+			return
+		}
+		fallthrough
+	case reflect.Slice:
+		for _, element := range reflectionutils.GetReflectedArrayValues(reflectedValue) {
+			fw.learnValue(element)
+		}
+	case reflect.Struct:
+		for i := 0; i < reflectedValue.NumField(); i++ {
+			fw.learnValue(reflectionutils.GetField(reflectedValue.Field(i)))
+		}
+	}
+}
+
+// addRuntimeValueRemover records a function which removes a newly learned value from the worker's value set. If the
+// amount of learned values exceeds the configured bound, the oldest learned values are evicted.
+func (fw *FuzzerWorker) addRuntimeValueRemover(remover func()) {
+	fw.runtimeValueRemovers = append(fw.runtimeValueRemovers, remover)
+	maxRuntimeValues := fw.fuzzer.config.Fuzzing.ValueSetSeeding.MaxRuntimeValues
+	for len(fw.runtimeValueRemovers) > maxRuntimeValues {
+		fw.runtimeValueRemovers[0]()
+		fw.runtimeValueRemovers = fw.runtimeValueRemovers[1:]
+	}
+}
@@ -0,0 +1,106 @@
+package fuzzing
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/crytic/medusa/chain"
+	chainConfig "github.com/crytic/medusa/chain/config"
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/config"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/valuegeneration"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRuntimeValuesTestWorker creates a FuzzerWorker with an empty value set, which retains up to the provided amount
+// of values learned at runtime.
+func newRuntimeValuesTestWorker(maxRuntimeValues int) *FuzzerWorker {
+	fuzzer := &Fuzzer{}
+	fuzzer.config.Fuzzing.ValueSetSeeding = config.ValueSetSeedingConfig{RuntimeValues: true, MaxRuntimeValues: maxRuntimeValues}
+	return &FuzzerWorker{
+		fuzzer:               fuzzer,
+		valueSet:             valuegeneration.NewValueSet(),
+		runtimeValueRemovers: make([]func(), 0),
+		deployedContracts:    make(map[common.Address]*fuzzerTypes.Contract),
+		proxyImplementations: make(map[common.Address]*fuzzerTypes.Contract),
+	}
+}
+
+// TestLearnValuesFromCallResults ensures the values returned by a call and emitted in its event logs are added to a
+// worker's value set.
+func TestLearnValuesFromCallResults(t *testing.T) {
+	// Define a contract which emits an event with an indexed and a non-indexed value, then returns a value.
+	contractAbi, err := abi.JSON(strings.NewReader(`[
+		{"type": "function", "name": "get", "inputs": [], "outputs": [{"name": "", "type": "uint256"}], "stateMutability": "nonpayable"},
+		{"type": "event", "name": "Seen", "inputs": [{"name": "a", "type": "uint256", "indexed": true}, {"name": "b", "type": "uint256", "indexed": false}], "anonymous": false}
+	]`))
+	assert.NoError(t, err)
+	bytecode := []byte{byte(vm.PUSH1), 0x55, byte(vm.PUSH1), 0x00, byte(vm.MSTORE), byte(vm.PUSH1), 0x66, byte(vm.PUSH32)}
+	bytecode = append(bytecode, contractAbi.Events["Seen"].ID.Bytes()...)
+	bytecode = append(bytecode, byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.LOG2))
+	bytecode = append(bytecode, byte(vm.PUSH2), 0x12, 0x34, byte(vm.PUSH1), 0x00, byte(vm.MSTORE))
+	bytecode = append(bytecode, byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.RETURN))
+	contract := fuzzerTypes.NewContract("TestContract", "", &compilationTypes.CompiledContract{Abi: contractAbi, RuntimeBytecode: bytecode}, nil)
+	sender := common.HexToAddress("0x10000")
+	contractAddress := common.HexToAddress("0x20000")
+
+	// Create our chain with the contract deployed.
+	testChainConfig, err := chainConfig.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChain, err := chain.NewTestChain(core.GenesisAlloc{
+		sender:          {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		contractAddress: {Code: bytecode, Balance: big.NewInt(0)},
+	}, testChainConfig)
+	assert.NoError(t, err)
+
+	// Execute a call to the contract.
+	call := calls.NewCallMessage(sender, &contractAddress, 0, big.NewInt(0), 0, nil, nil, nil, contractAbi.Methods["get"].ID)
+	call.FillFromTestChainProperties(testChain)
+	element := calls.NewCallSequenceElement(contract, call, 1, 1)
+	_, err = calls.ExecuteCallSequence(testChain, calls.CallSequence{element})
+	assert.NoError(t, err)
+
+	// The return value, the indexed event value, and the non-indexed event value should be learned. The non-indexed
+	// value is only learned if the contract definition of the emitter is known.
+	worker := newRuntimeValuesTestWorker(100)
+	worker.learnValuesFromCallResults(element)
+	assert.True(t, worker.valueSet.ContainsInteger(big.NewInt(0x1234)))
+	assert.True(t, worker.valueSet.ContainsInteger(big.NewInt(0x66)))
+	assert.False(t, worker.valueSet.ContainsInteger(big.NewInt(0x55)))
+	worker.deployedContracts[contractAddress] = contract
+	worker.learnValuesFromCallResults(element)
+	assert.True(t, worker.valueSet.ContainsInteger(big.NewInt(0x55)))
+}
+
+// TestLearnValueBounds ensures values learned at runtime are walked through nested types, that values which already
+// existed in the value set are never evicted, and that the oldest learned values are evicted once the configured
+// bound is exceeded.
+func TestLearnValueBounds(t *testing.T) {
+	worker := newRuntimeValuesTestWorker(3)
+	worker.valueSet.AddInteger(big.NewInt(1))
+
+	// Nested values should each be learned, while existing values are ignored.
+	worker.learnValue([]any{big.NewInt(1), uint8(2), [2]byte{0xAA, 0xBB}, "value"})
+	assert.True(t, worker.valueSet.ContainsInteger(big.NewInt(2)))
+	assert.True(t, worker.valueSet.ContainsBytes([]byte{0xAA, 0xBB}))
+	assert.True(t, worker.valueSet.ContainsString("value"))
+	assert.Len(t, worker.runtimeValueRemovers, 3)
+
+	// Exceeding the bound should evict the oldest learned values, but not values which existed beforehand.
+	worker.learnValue(common.HexToAddress("0x1"))
+	worker.learnValue(big.NewInt(3))
+	assert.Len(t, worker.runtimeValueRemovers, 3)
+	assert.False(t, worker.valueSet.ContainsInteger(big.NewInt(2)))
+	assert.False(t, worker.valueSet.ContainsBytes([]byte{0xAA, 0xBB}))
+	assert.True(t, worker.valueSet.ContainsString("value"))
+	assert.True(t, worker.valueSet.ContainsAddress(common.HexToAddress("0x1")))
+	assert.True(t, worker.valueSet.ContainsInteger(big.NewInt(3)))
+	assert.True(t, worker.valueSet.ContainsInteger(big.NewInt(1)))
+}
@@ -2,13 +2,24 @@ package fuzzing
 
 import (
 	"fmt"
+	compilationTypes "github.com/crytic/medusa/compilation/types"
 	"github.com/crytic/medusa/fuzzing/calls"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/corpus"
 	"github.com/crytic/medusa/fuzzing/valuegeneration"
 	"github.com/crytic/medusa/utils"
 	"github.com/crytic/medusa/utils/randomutils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/slices"
 	"math/big"
+	"sync"
 )
 
+// argumentMutationFeedbackScale describes the scale applied to an argument's score in a corpus mutation history when
+// determining its weight for targeted argument mutation. Every argument has a base weight of one.
+const argumentMutationFeedbackScale = 10.0
+
 // CallSequenceGenerator generates call sequences iteratively per element, for use in fuzzing campaigns. It is attached
 // to a FuzzerWorker and uses its runtime context
 type CallSequenceGenerator struct {
@@ -35,6 +46,40 @@ type CallSequenceGenerator struct {
 	// mutationStrategyChooser is a weighted random selector of functions that prepare the CallSequenceGenerator with
 	// a baseSequence derived from corpus entries.
 	mutationStrategyChooser *randomutils.WeightedRandomChooser[CallSequenceGeneratorMutationStrategy]
+
+	// corpusElementOrigins describes the corpus entry each element in the baseSequence was cloned from, and the
+	// arguments mutated in it, so that coverage increases can be attributed to the mutated arguments.
+	corpusElementOrigins map[*calls.CallSequenceElement]*corpusElementOrigin
+
+	// metadata describes the provenance of the sequence being generated, recorded if it is added to the corpus.
+	metadata *corpus.CallSequenceMetadata
+
+	// pendingCorpusSequence describes the corpus call sequence the baseSequence was obtained from, if it is pending
+	// resolution (see corpus.Corpus.UnexecutedCallSequence), in which case its elements are resolved as they are
+	// fetched by PopSequenceElement.
+	pendingCorpusSequence *calls.CallSequence
+
+	// pendingCorpusSequenceError describes the error encountered resolving an element of the pendingCorpusSequence,
+	// or nil if none was encountered.
+	pendingCorpusSequenceError error
+
+	// unexecutedCorpusSequence describes whether the baseSequence holds the elements of an unexecuted corpus call
+	// sequence (see corpus.Corpus.UnexecutedCallSequence), which are shared with the corpus. If so, its elements are
+	// cloned before they are modified by PopSequenceElement, so the corpus entry remains unchanged.
+	unexecutedCorpusSequence bool
+}
+
+// corpusElementOrigin describes the corpus call sequence a call sequence element was cloned from, and the arguments
+// which were mutated in it.
+type corpusElementOrigin struct {
+	// mutationHistory describes the mutation history of the corpus call sequence the element was cloned from.
+	mutationHistory *corpus.CallSequenceMutationHistory
+
+	// elementIndex describes the index of the element within the corpus call sequence it was cloned from.
+	elementIndex int
+
+	// mutatedArguments describes the indexes of the ABI arguments which were mutated in the element.
+	mutatedArguments []int
 }
 
 // CallSequenceGeneratorConfig defines the configuration for a CallSequenceGenerator to be created and used by a
@@ -84,6 +129,36 @@ type CallSequenceGeneratorConfig struct {
 	// number of calls from each.
 	RandomMutatedInterleaveAtRandomWeight uint64
 
+	// TargetedArgumentMutationProbability defines the probability that the CallSequenceGenerator should mutate only a
+	// single argument of a corpus call, rather than all of them. The argument is selected with a bias towards those
+	// whose mutation previously produced new coverage. A value of zero disables this feedback.
+	TargetedArgumentMutationProbability float32
+
+	// GenerateTimestampArgumentProbability defines the probability that the CallSequenceGenerator should generate an
+	// unsigned integer argument named like a timestamp (e.g. "deadline") near the current block timestamp, or one
+	// named like a duration (e.g. "lockPeriod") as a small length of time, rather than as an arbitrary integer.
+	GenerateTimestampArgumentProbability float32
+
+	// TimestampArgumentWindow defines the maximum distance in seconds from the current block timestamp of a value
+	// generated for an argument named like a timestamp.
+	TimestampArgumentWindow uint64
+
+	// DurationArgumentMax defines the maximum value in seconds generated for an argument named like a duration.
+	DurationArgumentMax uint64
+
+	// CopyCallArgumentProbability defines the probability that the CallSequenceGenerator should copy the value of one
+	// argument of a generated or mutated call into another argument of a compatible type within the same call. Integer
+	// arguments are copied this way too, rather than through an integer mutation strategy.
+	CopyCallArgumentProbability float32
+
+	// MutateBlockDelayProbability defines the probability that the CallSequenceGenerator should mutate the block
+	// number and timestamp delays of a corpus call, rather than retaining those it was recorded with.
+	MutateBlockDelayProbability float32
+
+	// MutateGasLimitProbability defines the probability that the CallSequenceGenerator should generate a new gas
+	// limit for a corpus call, if the FuzzingConfig enables transaction gas limit fuzzing.
+	MutateGasLimitProbability float32
+
 	// ValueGenerator defines the value provider to use when generating or mutating call sequences. This is used both
 	// for ABI call data generation, and generation of additional values such as the "value" field of a
 	// transaction/call.
@@ -114,7 +189,7 @@ func NewCallSequenceGenerator(worker *FuzzerWorker, config *CallSequenceGenerato
 	generator := &CallSequenceGenerator{
 		worker:                  worker,
 		config:                  config,
-		mutationStrategyChooser: randomutils.NewWeightedRandomChooser[CallSequenceGeneratorMutationStrategy](),
+		mutationStrategyChooser: randomutils.NewWeightedRandomChooserWithRand[CallSequenceGeneratorMutationStrategy](worker.randomProvider, &sync.Mutex{}),
 	}
 
 	generator.mutationStrategyChooser.AddChoices(
@@ -188,12 +263,26 @@ func (g *CallSequenceGenerator) InitializeNextSequence() (bool, error) {
 	g.baseSequence = make(calls.CallSequence, g.worker.fuzzer.config.Fuzzing.CallSequenceLength)
 	g.fetchIndex = 0
 	g.prefetchModifyCallFunc = nil
+	g.corpusElementOrigins = make(map[*calls.CallSequenceElement]*corpusElementOrigin)
+	g.metadata = g.worker.newCorpusCallSequenceMetadata(corpus.CallSequenceOriginGeneration)
+	g.pendingCorpusSequence = nil
+	g.pendingCorpusSequenceError = nil
+	g.unexecutedCorpusSequence = false
 
 	// Check if there are any previously une-xecuted corpus call sequences. If there are, the fuzzer should execute
-	// those first.
-	unexecutedSequence := g.worker.fuzzer.corpus.UnexecutedCallSequence()
+	// those first. Those pending resolution are resolved as their elements are fetched, after which their parent is
+	// recorded.
+	unexecutedSequence, pendingResolution := g.worker.fuzzer.corpus.UnexecutedCallSequence()
 	if unexecutedSequence != nil {
-		g.baseSequence = *unexecutedSequence
+		g.baseSequence = slices.Clone(*unexecutedSequence)
+		g.unexecutedCorpusSequence = true
+		if pendingResolution {
+			g.pendingCorpusSequence = unexecutedSequence
+			return false, nil
+		}
+		if err := g.recordCorpusParent(g.baseSequence); err != nil {
+			return false, err
+		}
 		return false, nil
 	}
 
@@ -246,6 +335,34 @@ func (g *CallSequenceGenerator) PopSequenceElement() (*calls.CallSequenceElement
 			return nil, err
 		}
 	} else {
+		// If the element is from a corpus call sequence pending resolution, we resolve it against the contracts
+		// deployed on our chain. If it cannot be resolved, the sequence is no longer applicable, so we stop executing
+		// it. Once every element is resolved, we can record the sequence as the parent of the one being executed.
+		if g.pendingCorpusSequence != nil {
+			g.pendingCorpusSequenceError = g.resolvePendingCorpusElement(element)
+			if g.pendingCorpusSequenceError != nil {
+				return nil, nil
+			}
+			if g.fetchIndex == len(g.baseSequence)-1 {
+				if err = g.recordCorpusParent(*g.pendingCorpusSequence); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// If the element is shared with the corpus, we clone it before modifying it, so the corpus entry is not
+		// altered for its later replays, or for other workers.
+		if g.unexecutedCorpusSequence {
+			element, err = element.Clone()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// We have an element derived from the corpus, so we fix up any fields which may no longer be valid in the
+		// context of this sequence (e.g. it was spliced together from different sequences).
+		g.fixupCorpusElement(element)
+
 		// We have an element, if our generator set a post-call modify for this function, execute it now to modify
 		// our call prior to return. This allows mutations to be applied on a per-call time frame, rather than
 		// per-sequence, making use of the most recent runtime data.
@@ -263,62 +380,352 @@ func (g *CallSequenceGenerator) PopSequenceElement() (*calls.CallSequenceElement
 	return element, nil
 }
 
+// resolvePendingCorpusElement resolves the contract targeted by the provided element of a corpus call sequence pending
+// resolution, and the method its ABI values call, against the contracts deployed on the worker's chain. If the method
+// is not declared by the contract, it may be declared by the implementation of a proxy, which it is called through.
+// Returns an error if the element could not be resolved.
+func (g *CallSequenceGenerator) resolvePendingCorpusElement(element *calls.CallSequenceElement) error {
+	// If we are deploying a contract rather than calling one, there is nothing to resolve.
+	if element.Call == nil || element.Call.MsgTo == nil {
+		return nil
+	}
+
+	// Resolve the contract the call is targeting.
+	contract, ok := g.worker.deployedContracts[*element.Call.MsgTo]
+	if !ok {
+		return fmt.Errorf("contract at address '%v' could not be resolved", element.Call.MsgTo.String())
+	}
+	element.Contract = contract
+
+	// If our element uses ABI values to produce call data, resolve its method.
+	callAbiValues := element.Call.MsgDataAbiValues
+	if callAbiValues == nil {
+		return nil
+	}
+	err := callAbiValues.Resolve(contract.CompiledContract().Abi)
+	if err != nil {
+		implementation, isProxy := g.worker.proxyImplementations[*element.Call.MsgTo]
+		if !isProxy || callAbiValues.Resolve(implementation.CompiledContract().Abi) != nil {
+			return err
+		}
+		element.Contract = implementation
+	}
+	return nil
+}
+
+// completePendingCorpusSequence reports the outcome of executing the baseSequence to the corpus, if it was obtained
+// from a corpus call sequence pending resolution. It was resolved if every one of its elements was fetched and
+// resolved. This must be called after the sequence is executed.
+func (g *CallSequenceGenerator) completePendingCorpusSequence() {
+	if g.pendingCorpusSequence == nil {
+		return
+	}
+	resolved := g.pendingCorpusSequenceError == nil && g.fetchIndex >= len(g.baseSequence)
+	g.worker.fuzzer.corpus.ResolvePendingCallSequence(g.pendingCorpusSequence, resolved, g.pendingCorpusSequenceError)
+	g.pendingCorpusSequence = nil
+}
+
+// fixupCorpusElement updates a call sequence element derived from the corpus so that it is valid to execute at the
+// current position of the sequence being generated. Elements taken from different corpus sequences (or a corpus
+// loaded from a previous campaign) may carry senders which are no longer configured (or may not call the method),
+// agents which are no longer deployed, or nonces which do not match the current chain state.
+func (g *CallSequenceGenerator) fixupCorpusElement(element *calls.CallSequenceElement) {
+	// If this element has no call, there is nothing to fix up.
+	if element.Call == nil {
+		return
+	}
+
+	// If the sender is not one of our configured senders which may call the method, replace it with one that is.
+	senders := g.worker.fuzzer.senders
+	if method, err := element.Method(); err == nil && method != nil {
+		senders = g.worker.fuzzer.methodSenders(element.Contract, *method)
+	}
+	if len(senders) > 0 && !slices.Contains(senders, element.Call.MsgFrom) {
+		element.Call.MsgFrom = senders[g.worker.randomProvider.Intn(len(senders))]
+	}
+
+	// If the call is routed through an agent which is no longer deployed, send it directly instead.
+	if element.Call.MsgAgent != nil && !slices.Contains(g.worker.agents, *element.Call.MsgAgent) {
+		element.Call.MsgAgent = nil
+	}
+
+	// Update the nonce (and any other missing fields) from our current chain state.
+	element.Call.FillFromTestChainProperties(g.worker.chain)
+}
+
+// randomCorpusSequence obtains a weighted random call sequence from the corpus, recording the origin of each of its
+// elements so that coverage increases from later mutations can be attributed to the corpus entry. The mutation history
+// of the corpus entry decays each time it is selected.
+// Returns the cloned corpus call sequence, or an error if one occurs.
+func (g *CallSequenceGenerator) randomCorpusSequence() (calls.CallSequence, error) {
+	corpusSequence, mutationHistory, err := g.worker.fuzzer.corpus.RandomCallSequenceWithMutationHistory()
+	if err == nil && corpusSequence != nil {
+		err = g.recordCorpusParent(corpusSequence)
+	}
+	if err != nil || mutationHistory == nil {
+		return corpusSequence, err
+	}
+
+	mutationHistory.Decay()
+	for i, element := range corpusSequence {
+		g.corpusElementOrigins[element] = &corpusElementOrigin{
+			mutationHistory: mutationHistory,
+			elementIndex:    i,
+		}
+	}
+	return corpusSequence, nil
+}
+
+// recordCorpusParent records that the sequence being generated is derived from the provided corpus call sequence, prior
+// to any mutation. The first corpus call sequence recorded is considered the parent, while recording another indicates
+// the sequence is spliced from both.
+// Returns an error if one occurs.
+func (g *CallSequenceGenerator) recordCorpusParent(corpusSequence calls.CallSequence) error {
+	if g.metadata.ParentHash != nil {
+		g.metadata.Origin = corpus.CallSequenceOriginSplice
+		return nil
+	}
+	parentHash, err := corpusSequence.CanonicalHash()
+	if err != nil {
+		return err
+	}
+	g.metadata.ParentHash = &parentHash
+	g.metadata.Origin = corpus.CallSequenceOriginMutation
+	return nil
+}
+
+// corpusCallSequenceMetadata obtains metadata describing the provenance of the sequence being generated, for use when
+// adding it to the corpus.
+func (g *CallSequenceGenerator) corpusCallSequenceMetadata() *corpus.CallSequenceMetadata {
+	return g.metadata
+}
+
+// selectArgumentsToMutate determines which ABI arguments of the provided corpus derived call sequence element should
+// be mutated. With a probability of TargetedArgumentMutationProbability, a single argument is selected, weighted by its
+// score in the mutation history of the corpus entry the element was cloned from. Otherwise, all arguments are
+// selected. The selection is recorded so coverage increases can later be attributed to it.
+// Returns the indexes of the arguments to mutate.
+func (g *CallSequenceGenerator) selectArgumentsToMutate(element *calls.CallSequenceElement, argumentCount int) []int {
+	// By default, we mutate every argument.
+	argumentIndexes := make([]int, argumentCount)
+	for i := 0; i < argumentCount; i++ {
+		argumentIndexes[i] = i
+	}
+
+	// If we don't know where this element came from, we have no feedback to act on.
+	origin, ok := g.corpusElementOrigins[element]
+	if !ok {
+		return argumentIndexes
+	}
+
+	// Determine whether we should target a single argument.
+	if argumentCount > 1 && g.worker.randomProvider.Float32() < g.config.TargetedArgumentMutationProbability {
+		// Obtain the weight of each argument, so those which previously produced new coverage are favored.
+		weights := make([]float64, argumentCount)
+		totalWeight := 0.0
+		for i := 0; i < argumentCount; i++ {
+			weights[i] = 1.0 + origin.mutationHistory.ArgumentScore(origin.elementIndex, i)*argumentMutationFeedbackScale
+			totalWeight += weights[i]
+		}
+
+		// Select our argument.
+		selectedPosition := g.worker.randomProvider.Float64() * totalWeight
+		selectedIndex := argumentCount - 1
+		for i, weight := range weights {
+			if selectedPosition < weight {
+				selectedIndex = i
+				break
+			}
+			selectedPosition -= weight
+		}
+		argumentIndexes = []int{selectedIndex}
+
+		// Update our metrics
+		g.worker.workerMetrics().targetedArgumentMutations.add(1)
+	}
+
+	origin.mutatedArguments = argumentIndexes
+	return argumentIndexes
+}
+
+// recordCoverageIncrease attributes a coverage increase produced by executing the provided call sequence element to
+// the arguments mutated in it, increasing their scores in the mutation history of the corpus entry the element was
+// cloned from.
+// Returns the number of mutated arguments the coverage increase was attributed to.
+func (g *CallSequenceGenerator) recordCoverageIncrease(element *calls.CallSequenceElement) int {
+	origin, ok := g.corpusElementOrigins[element]
+	if !ok {
+		return 0
+	}
+	for _, argumentIndex := range origin.mutatedArguments {
+		origin.mutationHistory.RecordProductiveMutation(origin.elementIndex, argumentIndex)
+	}
+	return len(origin.mutatedArguments)
+}
+
+// applyCallArgumentsModifyFuncs calls every FuzzerHooks.CallArgumentsModifyFuncs function with the argument values
+// of a generated or mutated call to the provided method, of the provided contract definition if it is known.
+// Returns an error if one occurs.
+func (g *CallSequenceGenerator) applyCallArgumentsModifyFuncs(contract *fuzzerTypes.Contract, method *abi.Method, values []any) error {
+	for _, callArgumentsModifyFunc := range g.worker.fuzzer.Hooks.CallArgumentsModifyFuncs {
+		err := callArgumentsModifyFunc(g.worker, contract, method, values)
+		if err != nil {
+			return fmt.Errorf("error when modifying call arguments: %v", err)
+		}
+	}
+	return nil
+}
+
+// generateTimestampArgument generates a value for the provided method input argument if it is identified as a
+// timestamp or duration by valuegeneration.MatchTimestampArgument. Timestamps are generated within
+// TimestampArgumentWindow of the current block timestamp, while durations are generated up to DurationArgumentMax.
+// Values are only generated with a probability of GenerateTimestampArgumentProbability, so arbitrary values (e.g. far
+// future timestamps which may overflow) are still tested.
+// Returns the generated value, or nil if the argument should be generated or mutated as usual.
+func (g *CallSequenceGenerator) generateTimestampArgument(input *abi.Argument) any {
+	// Determine if this argument holds a time-related value, and if we should generate one for it.
+	kind := valuegeneration.MatchTimestampArgument(input)
+	if kind == valuegeneration.TimestampArgumentKindNone || g.worker.randomProvider.Float32() >= g.config.GenerateTimestampArgumentProbability {
+		return nil
+	}
+
+	// Generate our value.
+	value := new(big.Int)
+	if kind == valuegeneration.TimestampArgumentKindTimestamp {
+		// Offset the current block timestamp by a random amount within our window, in either direction.
+		window := new(big.Int).SetUint64(g.config.TimestampArgumentWindow)
+		offset := new(big.Int).Rand(g.worker.randomProvider, new(big.Int).Add(new(big.Int).Lsh(window, 1), big.NewInt(1)))
+		value.SetUint64(g.worker.chain.Head().Header.Time)
+		value.Add(value, offset.Sub(offset, window))
+		if value.Sign() < 0 {
+			value.SetUint64(0)
+		}
+	} else {
+		value.Rand(g.worker.randomProvider, new(big.Int).Add(new(big.Int).SetUint64(g.config.DurationArgumentMax), big.NewInt(1)))
+	}
+	return valuegeneration.IntegerToAbiValue(value, &input.Type)
+}
+
+// generateCallValue generates the value in wei to send with the provided call to the provided method from the provided
+// sender, once its gas limit and fees are set. For payable methods, the value is either 0, 1 wei, a round amount of ether, the sender's
+// full balance (less the gas it may spend), its balance plus one wei (to test the call being rejected), or an arbitrary
+// integer. Non-payable methods are sent 1 wei with a probability of FuzzingConfig.NonPayableValueProbability (to test
+// that they revert), and no value otherwise.
+// Returns the generated value.
+func (g *CallSequenceGenerator) generateCallValue(sender common.Address, method *abi.Method, msg *calls.CallMessage) *big.Int {
+	// Non-payable methods should only occasionally be sent value, to test that they revert.
+	if !method.IsPayable() {
+		if g.worker.randomProvider.Float64() < g.worker.fuzzer.config.Fuzzing.NonPayableValueProbability {
+			return big.NewInt(1)
+		}
+		return big.NewInt(0)
+	}
+
+	// Determine the balance the sender could spend on value, after paying for the gas its call may use. Legacy calls
+	// are sent with a gas price of one wei, while EIP-1559 calls must afford their fee cap for each unit of gas.
+	gasCost := new(big.Int).SetUint64(msg.MsgGas)
+	if msg.IsDynamicFee() {
+		gasCost.Mul(gasCost, msg.MsgGasFeeCap)
+	}
+	balance := new(big.Int).Sub(g.worker.chain.State().GetBalance(sender), gasCost)
+	if balance.Sign() < 0 {
+		balance.SetUint64(0)
+	}
+
+	// Select a value from our distribution. Values which exceed our balance are capped to it, except for the one
+	// intended to exceed it.
+	var value *big.Int
+	switch choice := g.worker.randomProvider.Intn(100); {
+	case choice < 15:
+		return big.NewInt(0)
+	case choice < 30:
+		value = big.NewInt(1)
+	case choice < 50:
+		value = new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(18+g.worker.randomProvider.Intn(4))), nil)
+	case choice < 65:
+		return balance
+	case choice < 67:
+		return balance.Add(balance, big.NewInt(1))
+	default:
+		value = g.config.ValueGenerator.GenerateInteger(false, 64)
+	}
+	if value.Cmp(balance) > 0 {
+		return balance
+	}
+	return value
+}
+
 // generateNewElement generates a new call sequence element which targets a state changing method in a contract
 // deployed to the CallSequenceGenerator's parent FuzzerWorker chain, with fuzzed call data.
 // Returns the call sequence element, or an error if one was encountered.
 func (g *CallSequenceGenerator) generateNewElement() (*calls.CallSequenceElement, error) {
-	// Verify we have state changing methods to call
-	if len(g.worker.stateChangingMethods) == 0 {
-		return nil, fmt.Errorf("cannot generate fuzzed tx as there are no state changing methods to call")
+	// Select a random method and sender
+	selectedMethod, err := g.worker.selectStateChangingMethod()
+	if err != nil {
+		return nil, err
+	}
+	senders := g.worker.fuzzer.methodSenders(selectedMethod.Contract, selectedMethod.Method)
+	if len(senders) == 0 {
+		return nil, fmt.Errorf("cannot generate fuzzed tx as no sender may call %s.%s", selectedMethod.Contract.Name(), selectedMethod.Method.Sig)
 	}
+	selectedSender := senders[g.worker.randomProvider.Intn(len(senders))]
 
-	// Select a random method and sender
-	selectedMethod := &g.worker.stateChangingMethods[g.worker.randomProvider.Intn(len(g.worker.stateChangingMethods))]
-	selectedSender := g.worker.fuzzer.senders[g.worker.randomProvider.Intn(len(g.worker.fuzzer.senders))]
+	// Inform our value generator of the contract we're generating inputs for, if it supports it.
+	if valueGenerator, ok := g.config.ValueGenerator.(valuegeneration.ContractAwareValueGenerator); ok {
+		valueGenerator.SetTargetContractAddress(&selectedMethod.Address)
+	}
 
 	// Generate fuzzed parameters for the function call
+	enumBounds := selectedMethod.Contract.MethodInputEnumBounds(&selectedMethod.Method)
+	sizeLimits := selectedMethod.Contract.MethodInputSizeLimits(&selectedMethod.Method)
 	args := make([]any, len(selectedMethod.Method.Inputs))
 	for i := 0; i < len(args); i++ {
-		// Create our fuzzed parameters.
+		// Create our fuzzed parameters. Enums, including those nested within arrays or structs, are generated within
+		// the range of their members.
 		input := selectedMethod.Method.Inputs[i]
-		args[i] = valuegeneration.GenerateAbiValue(g.config.ValueGenerator, &input.Type)
+		// Arguments named like timestamps or durations may be generated near the current block timestamp.
+		if enumBounds != nil && enumBounds[i] != nil {
+			args[i] = valuegeneration.GenerateEnumBoundedAbiValue(g.config.ValueGenerator, &input.Type, enumBounds[i])
+		} else if timestampValue := g.generateTimestampArgument(&input); timestampValue != nil {
+			args[i] = timestampValue
+		} else {
+			args[i] = valuegeneration.GenerateAbiValue(g.config.ValueGenerator, &input.Type)
+		}
 	}
 
-	// If this is a payable function, generate value to send
-	var value *big.Int
-	value = big.NewInt(0)
-	if selectedMethod.Method.StateMutability == "payable" {
-		value = g.config.ValueGenerator.GenerateInteger(false, 64)
+	// Apply any modifications which operate on all of our arguments together.
+	err = g.applyCallArgumentsModifyFuncs(selectedMethod.Contract, &selectedMethod.Method, args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Arguments with a size bounded by the contract's language are kept within their bound.
+	for i, sizeLimit := range sizeLimits {
+		args[i] = valuegeneration.LimitAbiValueSize(&selectedMethod.Method.Inputs[i].Type, args[i], sizeLimit)
 	}
 
+	// If our arguments appear to contain a signature over a hash, try to make it a valid one.
+	valuegeneration.ApplySignatureArguments(g.config.ValueGenerator, selectedMethod.Method.Inputs, args)
+
 	// Create our message using the provided parameters.
 	// We fill out some fields and populate the rest from our TestChain properties.
-	// TODO: We likely want to make gasPrice fluctuate within some sensible range here.
-	msg := calls.NewCallMessageWithAbiValueData(selectedSender, &selectedMethod.Address, 0, value, g.worker.fuzzer.config.Fuzzing.TransactionGasLimit, nil, nil, nil, &calls.CallMessageDataAbiValues{
+	msg := calls.NewCallMessageWithAbiValueData(selectedSender, &selectedMethod.Address, 0, big.NewInt(0), g.worker.fuzzer.config.Fuzzing.TransactionGasLimit, nil, nil, nil, &calls.CallMessageDataAbiValues{
 		Method:      &selectedMethod.Method,
 		InputValues: args,
 	})
+
+	// Our call may be routed through an agent contract, so the agent calls the target rather than our sender.
+	msg.MsgAgent = g.worker.selectAgent(selectedMethod.Address)
+
+	// Select the gas limit and fees for our call, then the value to send, considering whether the method is payable
+	// and what our sender can afford after paying for that gas.
+	msg.MsgGas = g.generateGasLimit(msg)
+	g.generateTransactionFees(msg)
+	msg.MsgValue = g.generateCallValue(selectedSender, &selectedMethod.Method, msg)
 	msg.FillFromTestChainProperties(g.worker.chain)
 
 	// Determine our delay values for this element
-	blockNumberDelay := uint64(0)
-	blockTimestampDelay := uint64(0)
-	if g.worker.fuzzer.config.Fuzzing.MaxBlockNumberDelay > 0 {
-		blockNumberDelay = g.config.ValueGenerator.GenerateInteger(false, 64).Uint64() % (g.worker.fuzzer.config.Fuzzing.MaxBlockNumberDelay + 1)
-	}
-	if g.worker.fuzzer.config.Fuzzing.MaxBlockTimestampDelay > 0 {
-		blockTimestampDelay = g.config.ValueGenerator.GenerateInteger(false, 64).Uint64() % (g.worker.fuzzer.config.Fuzzing.MaxBlockTimestampDelay + 1)
-	}
-
-	// For each block we jump, we need a unique time stamp for chain semantics, so if our block number jump is too small,
-	// while our timestamp jump is larger, we cap it.
-	if blockNumberDelay > blockTimestampDelay {
-		if blockTimestampDelay == 0 {
-			blockNumberDelay = 0
-		} else {
-			blockNumberDelay %= blockTimestampDelay
-		}
-	}
+	blockNumberDelay, blockTimestampDelay := generateBlockDelays(g.worker.randomProvider, g.worker.fuzzer.config.Fuzzing)
 
 	// Return our call sequence element.
 	return calls.NewCallSequenceElement(selectedMethod.Contract, msg, blockNumberDelay, blockTimestampDelay), nil
@@ -329,7 +736,7 @@ func (g *CallSequenceGenerator) generateNewElement() (*calls.CallSequenceElement
 // Returns an error if one occurs.
 func callSeqGenFuncCorpusHead(sequenceGenerator *CallSequenceGenerator, sequence calls.CallSequence) error {
 	// Obtain a call sequence from the corpus
-	corpusSequence, err := sequenceGenerator.worker.fuzzer.corpus.RandomCallSequence()
+	corpusSequence, err := sequenceGenerator.randomCorpusSequence()
 	if err != nil {
 		return fmt.Errorf("could not obtain corpus call sequence for tail mutation: %v", err)
 	}
@@ -346,7 +753,7 @@ func callSeqGenFuncCorpusHead(sequenceGenerator *CallSequenceGenerator, sequence
 // Returns an error if one occurs.
 func callSeqGenFuncCorpusTail(sequenceGenerator *CallSequenceGenerator, sequence calls.CallSequence) error {
 	// Obtain a call sequence from the corpus
-	corpusSequence, err := sequenceGenerator.worker.fuzzer.corpus.RandomCallSequence()
+	corpusSequence, err := sequenceGenerator.randomCorpusSequence()
 	if err != nil {
 		return fmt.Errorf("could not obtain corpus call sequence for tail mutation: %v", err)
 	}
@@ -365,28 +772,30 @@ func callSeqGenFuncCorpusTail(sequenceGenerator *CallSequenceGenerator, sequence
 // Returns an error if one occurs.
 func callSeqGenFuncSpliceAtRandom(sequenceGenerator *CallSequenceGenerator, sequence calls.CallSequence) error {
 	// Obtain two corpus call sequence entries
-	headSequence, err := sequenceGenerator.worker.fuzzer.corpus.RandomCallSequence()
+	headSequence, err := sequenceGenerator.randomCorpusSequence()
 	if err != nil {
 		return fmt.Errorf("could not obtain head corpus call sequence for splice-at-random corpus mutation: %v", err)
 	}
-	tailSequence, err := sequenceGenerator.worker.fuzzer.corpus.RandomCallSequence()
+	tailSequence, err := sequenceGenerator.randomCorpusSequence()
 	if err != nil {
 		return fmt.Errorf("could not obtain tail corpus call sequence for splice-at-random corpus mutation: %v", err)
 	}
 
-	// Determine a random position to slice off the head of the call sequence.
+	// Determine a random cut point in the first sequence, taking everything prior to it as our head.
 	maxLength := utils.Min(len(sequence), len(headSequence))
 	headSequenceLength := sequenceGenerator.worker.randomProvider.Intn(maxLength) + 1
 
 	// Copy the head of the first corpus sequence to our destination sequence.
 	copy(sequence, headSequence[:headSequenceLength])
 
-	// Determine a random position to slice off the tail of the call sequence.
-	maxLength = utils.Min(len(sequence)-headSequenceLength, len(tailSequence))
-	tailSequenceLength := sequenceGenerator.worker.randomProvider.Intn(maxLength + 1)
+	// Determine a random cut point in the second sequence, taking everything after it as our tail. If the tail
+	// exceeds the space remaining in our destination sequence, it is truncated so we respect the configured call
+	// sequence length.
+	tailSequenceStart := sequenceGenerator.worker.randomProvider.Intn(len(tailSequence) + 1)
+	tailSequenceLength := utils.Min(len(sequence)-headSequenceLength, len(tailSequence)-tailSequenceStart)
 
 	// Copy the tail of the second corpus sequence to our destination sequence (after the head sequence portion).
-	copy(sequence[headSequenceLength:], tailSequence[len(tailSequence)-tailSequenceLength:])
+	copy(sequence[headSequenceLength:], tailSequence[tailSequenceStart:tailSequenceStart+tailSequenceLength])
 
 	return nil
 }
@@ -397,11 +806,11 @@ func callSeqGenFuncSpliceAtRandom(sequenceGenerator *CallSequenceGenerator, sequ
 // Returns an error if one occurs.
 func callSeqGenFuncInterleaveAtRandom(sequenceGenerator *CallSequenceGenerator, sequence calls.CallSequence) error {
 	// Obtain two corpus call sequence entries
-	firstSequence, err := sequenceGenerator.worker.fuzzer.corpus.RandomCallSequence()
+	firstSequence, err := sequenceGenerator.randomCorpusSequence()
 	if err != nil {
 		return fmt.Errorf("could not obtain first corpus call sequence for interleave-at-random corpus mutation: %v", err)
 	}
-	secondSequence, err := sequenceGenerator.worker.fuzzer.corpus.RandomCallSequence()
+	secondSequence, err := sequenceGenerator.randomCorpusSequence()
 	if err != nil {
 		return fmt.Errorf("could not obtain second corpus call sequence for interleave-at-random corpus mutation: %v", err)
 	}
@@ -437,19 +846,94 @@ func callSeqGenFuncInterleaveAtRandom(sequenceGenerator *CallSequenceGenerator,
 // to a call sequence element, prior to it being fetched.
 // Returns an error if one occurs.
 func prefetchModifyCallFuncMutate(sequenceGenerator *CallSequenceGenerator, element *calls.CallSequenceElement) error {
+	// If this element has no call, exit early.
+	if element.Call == nil {
+		return nil
+	}
+
+	// Mutate the delays between this call and the last, so time-dependent logic is exercised at different points.
+	sequenceGenerator.mutateBlockDelays(element)
+
+	// Mutate the gas limit of this call, so its behavior when starved of gas is exercised.
+	sequenceGenerator.mutateGasLimit(element)
+
 	// If this element has no ABI value based call data, exit early.
-	if element.Call == nil || element.Call.MsgDataAbiValues == nil {
+	if element.Call.MsgDataAbiValues == nil {
 		return nil
 	}
 
-	// Loop for each input value and mutate it
+	// Inform our value generator of the contract we're mutating inputs for, if it supports it.
+	if valueGenerator, ok := sequenceGenerator.config.ValueGenerator.(valuegeneration.ContractAwareValueGenerator); ok {
+		valueGenerator.SetTargetContractAddress(element.Call.MsgTo)
+	}
+
 	abiValuesMsgData := element.Call.MsgDataAbiValues
-	for i := 0; i < len(abiValuesMsgData.InputValues); i++ {
-		mutatedInput, err := valuegeneration.MutateAbiValue(sequenceGenerator.config.ValueGenerator, &abiValuesMsgData.Method.Inputs[i].Type, abiValuesMsgData.InputValues[i])
+
+	// Obtain the enum bounds and size limits for our method's inputs, if any.
+	var enumBounds []*compilationTypes.EnumBounds
+	var sizeLimits []int
+	if element.Contract != nil {
+		enumBounds = element.Contract.MethodInputEnumBounds(abiValuesMsgData.Method)
+		sizeLimits = element.Contract.MethodInputSizeLimits(abiValuesMsgData.Method)
+	}
+
+	// Loop for each input value selected for mutation and mutate it
+	for _, i := range sequenceGenerator.selectArgumentsToMutate(element, len(abiValuesMsgData.InputValues)) {
+		// Enums, including those nested within arrays or structs, are mutated so that they generally remain within
+		// the range of their members.
+		inputType := &abiValuesMsgData.Method.Inputs[i].Type
+		if enumBounds != nil && enumBounds[i] != nil {
+			mutatedInput, err := valuegeneration.MutateEnumBoundedAbiValue(sequenceGenerator.config.ValueGenerator, inputType, abiValuesMsgData.InputValues[i], enumBounds[i])
+			if err != nil {
+				return fmt.Errorf("error when mutating call sequence input argument: %v", err)
+			}
+			abiValuesMsgData.InputValues[i] = mutatedInput
+			continue
+		}
+
+		// Arguments named like timestamps or durations may be regenerated near the current block timestamp.
+		if timestampValue := sequenceGenerator.generateTimestampArgument(&abiValuesMsgData.Method.Inputs[i]); timestampValue != nil {
+			abiValuesMsgData.InputValues[i] = timestampValue
+			continue
+		}
+
+		mutatedInput, err := valuegeneration.MutateAbiValue(sequenceGenerator.config.ValueGenerator, inputType, abiValuesMsgData.InputValues[i])
 		if err != nil {
 			return fmt.Errorf("error when mutating call sequence input argument: %v", err)
 		}
 		abiValuesMsgData.InputValues[i] = mutatedInput
 	}
+
+	// Apply any modifications which operate on all of our arguments together.
+	err := sequenceGenerator.applyCallArgumentsModifyFuncs(element.Contract, abiValuesMsgData.Method, abiValuesMsgData.InputValues)
+	if err != nil {
+		return err
+	}
+
+	// Arguments with a size bounded by the contract's language are kept within their bound.
+	for i, sizeLimit := range sizeLimits {
+		abiValuesMsgData.InputValues[i] = valuegeneration.LimitAbiValueSize(&abiValuesMsgData.Method.Inputs[i].Type, abiValuesMsgData.InputValues[i], sizeLimit)
+	}
+
+	// If our arguments appear to contain a signature over a hash, it was likely invalidated by our mutations, so we
+	// try to make it a valid one again.
+	valuegeneration.ApplySignatureArguments(sequenceGenerator.config.ValueGenerator, abiValuesMsgData.Method.Inputs, abiValuesMsgData.InputValues)
+	return nil
+}
+
+// callArgumentsModifyFuncCopyArgument is a CallArgumentsModifyFunc which, with a probability of the
+// CallSequenceGeneratorConfig.CopyCallArgumentProbability, copies the value of one argument into another argument of
+// a compatible type within the same call. Enum arguments and arguments with a size limit are kept within their bounds.
+// Returns an error if one occurs.
+func callArgumentsModifyFuncCopyArgument(worker *FuzzerWorker, contract *fuzzerTypes.Contract, method *abi.Method, values []any) error {
+	if worker.randomProvider.Float32() < worker.sequenceGenerator.config.CopyCallArgumentProbability {
+		var enumBounds []*compilationTypes.EnumBounds
+		var sizeLimits []int
+		if contract != nil {
+			enumBounds = contract.MethodInputEnumBounds(method)
+			sizeLimits = contract.MethodInputSizeLimits(method)
+		}
+		valuegeneration.CopyCallArgumentValue(worker.randomProvider, method.Inputs, values, enumBounds, sizeLimits)
+	}
 	return nil
 }
@@ -0,0 +1,85 @@
+package fuzzing
+
+import (
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/crytic/medusa/chain"
+	chainConfig "github.com/crytic/medusa/chain/config"
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/fuzzing/calls"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCallSequenceGeneratorUnexecutedCorpusSequence ensures the elements of an unexecuted corpus call sequence are
+// fixed up for the worker executing them (e.g. given a configured sender) without altering the corpus entry itself.
+func TestCallSequenceGeneratorUnexecutedCorpusSequence(t *testing.T) {
+	// Define a contract with a single method, deployed to our chain.
+	contractAbi, err := abi.JSON(strings.NewReader(`[{"type": "function", "name": "set", "inputs": [{"name": "value", "type": "uint256"}], "outputs": [], "stateMutability": "nonpayable"}]`))
+	assert.NoError(t, err)
+	bytecode := []byte{byte(vm.STOP)}
+	contract := fuzzerTypes.NewContract("TestContract", "", &compilationTypes.CompiledContract{Abi: contractAbi, RuntimeBytecode: bytecode}, nil)
+	contractDefinitions := fuzzerTypes.Contracts{contract}
+	sender := common.HexToAddress("0x10000")
+	unknownSender := common.HexToAddress("0x90000")
+	contractAddress := common.HexToAddress("0x20000")
+	testChainConfig, err := chainConfig.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChain, err := chain.NewTestChain(core.GenesisAlloc{
+		sender:          {Balance: new(big.Int).Lsh(big.NewInt(1), 128)},
+		contractAddress: {Code: bytecode, Balance: big.NewInt(0)},
+	}, testChainConfig)
+	assert.NoError(t, err)
+
+	// Persist a corpus call sequence sent by a sender which is no longer configured, along with coverage maps, so it
+	// is queued for execution, pending resolution, when the corpus is loaded again.
+	corpusDirectory := t.TempDir()
+	originalCorpus, err := corpus.NewCorpus(corpusDirectory)
+	assert.NoError(t, err)
+	assert.NoError(t, originalCorpus.Initialize(testChain, contractDefinitions))
+	method := contractAbi.Methods["set"]
+	call := calls.NewCallMessageWithAbiValueData(unknownSender, &contractAddress, 0, big.NewInt(0), 0, nil, nil, nil, &calls.CallMessageDataAbiValues{
+		Method:      &method,
+		InputValues: []any{big.NewInt(5)},
+	})
+	call.FillFromTestChainProperties(testChain)
+	assert.NoError(t, originalCorpus.AddCallSequence(calls.CallSequence{calls.NewCallSequenceElement(contract, call, 1, 1)}, nil, nil, true))
+	assert.NoError(t, originalCorpus.WriteCoverageMaps())
+
+	// Load the corpus and create a worker which executes its unexecuted call sequence.
+	loadedCorpus, err := corpus.NewCorpus(corpusDirectory)
+	assert.NoError(t, err)
+	assert.NoError(t, loadedCorpus.Initialize(testChain, contractDefinitions))
+	fuzzer := &Fuzzer{corpus: loadedCorpus, senders: []common.Address{sender}}
+	fuzzer.config.Fuzzing.CallSequenceLength = 1
+	worker := &FuzzerWorker{
+		fuzzer:            fuzzer,
+		chain:             testChain,
+		randomProvider:    rand.New(rand.NewSource(0)),
+		deployedContracts: map[common.Address]*fuzzerTypes.Contract{contractAddress: contract},
+	}
+	worker.sequenceGenerator = NewCallSequenceGenerator(worker, &CallSequenceGeneratorConfig{})
+	isNewSequence, err := worker.sequenceGenerator.InitializeNextSequence()
+	assert.NoError(t, err)
+	assert.False(t, isNewSequence)
+	storedSequence := worker.sequenceGenerator.baseSequence
+	storedElement := storedSequence[0]
+
+	// The element fetched should be sent by a configured sender, while the corpus entry keeps its original sender.
+	element, err := worker.sequenceGenerator.PopSequenceElement()
+	assert.NoError(t, err)
+	assert.NotNil(t, element)
+	assert.EqualValues(t, sender, element.Call.MsgFrom)
+	assert.NotSame(t, storedElement, element)
+	assert.EqualValues(t, unknownSender, storedElement.Call.MsgFrom)
+	assert.Same(t, storedElement, (*worker.sequenceGenerator.pendingCorpusSequence)[0])
+	assert.Same(t, contract, storedElement.Contract)
+}
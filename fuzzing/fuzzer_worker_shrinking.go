@@ -0,0 +1,185 @@
+package fuzzing
+
+import (
+	"time"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/crytic/medusa/utils"
+)
+
+// shrinkCandidate describes a candidate call sequence produced by a FuzzerWorker while shrinking a call sequence. It
+// may be tested by any FuzzerWorker against its own chain, on behalf of the worker shrinking the call sequence.
+type shrinkCandidate struct {
+	// callSequence describes the candidate call sequence to test.
+	callSequence calls.CallSequence
+
+	// shrinkRequest describes the request the call sequence is being shrunk for, whose verifier the candidate must
+	// satisfy.
+	shrinkRequest *ShrinkCallSequenceRequest
+
+	// result describes the channel the result of testing the candidate is sent to. It is buffered, so the worker
+	// testing the candidate never blocks on sending it.
+	result chan shrinkCandidateResult
+}
+
+// shrinkCandidateResult describes the result of testing a shrinkCandidate.
+type shrinkCandidateResult struct {
+	// testedCallSequence describes the call sequence which was executed when testing the candidate.
+	testedCallSequence calls.CallSequence
+
+	// valid indicates whether the candidate satisfied the verifier of its shrink request.
+	valid bool
+
+	// err describes an error which occurred while testing the candidate, if any.
+	err error
+}
+
+// newShrinkCandidate creates a shrinkCandidate to test the provided call sequence against the provided shrink request.
+func newShrinkCandidate(callSequence calls.CallSequence, shrinkRequest *ShrinkCallSequenceRequest) *shrinkCandidate {
+	return &shrinkCandidate{
+		callSequence:  callSequence,
+		shrinkRequest: shrinkRequest,
+		result:        make(chan shrinkCandidateResult, 1),
+	}
+}
+
+// testShrinkCandidate executes the provided candidate call sequence on the worker's chain and checks whether it
+// satisfies the verifier of the provided shrink request. The chain is reverted to the testing base block afterwards.
+// Returns the executed call sequence, a boolean indicating whether it satisfied the verifier, or an error if one
+// occurred.
+func (fw *FuzzerWorker) testShrinkCandidate(possibleShrunkSequence calls.CallSequence, shrinkRequest *ShrinkCallSequenceRequest) (calls.CallSequence, bool, error) {
+	// Record that we tested a candidate, and how long it took, so shrink throughput can be reported.
+	startTime := time.Now()
+	defer func() {
+		metrics := fw.workerMetrics()
+		metrics.shrinkCandidatesTested.add(1)
+		metrics.shrinkDuration.add(uint64(time.Since(startTime)))
+	}()
+
+	// Our "fetch next call method" method will simply fetch and fix the call message in case any fields are not correct due to shrinking.
+	fetchElementFunc := func(currentIndex int) (*calls.CallSequenceElement, error) {
+		// If we are at the end of our sequence, return nil indicating we should stop executing.
+		if currentIndex >= len(possibleShrunkSequence) {
+			return nil, nil
+		}
+
+		possibleShrunkSequence[currentIndex].Call.FillFromTestChainProperties(fw.chain)
+		return possibleShrunkSequence[currentIndex], nil
+	}
+
+	// Our "post-execution check" method will check coverage and call all testing functions. If one returns a
+	// request for a shrunk call sequence, we exit our call sequence execution immediately to go fulfill the shrink
+	// request.
+	executionCheckFunc := func(currentlyExecutedSequence calls.CallSequence) (bool, error) {
+		// Check for updates to coverage and corpus (using only the section of the sequence we tested so far).
+		// If we detect coverage changes, add this sequence.
+		_, err := fw.fuzzer.corpus.AddCallSequenceIfCoverageChanged(currentlyExecutedSequence, fw.getNewCorpusCallSequenceWeight(), fw.newCorpusCallSequenceMetadata(corpus.CallSequenceOriginShrink), true)
+		if err != nil {
+			return true, err
+		}
+
+		// If our fuzzer was forced to stop, exit out immediately without results.
+		if utils.CheckContextDone(fw.fuzzer.forceStopCtx) {
+			return true, nil
+		}
+
+		return false, nil
+	}
+
+	// Execute our call sequence.
+	testedPossibleShrunkSequence, err := calls.ExecuteCallSequenceIteratively(fw.chain, fetchElementFunc, executionCheckFunc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// If our fuzzer was forced to stop, exit out immediately without results.
+	if utils.CheckContextDone(fw.fuzzer.forceStopCtx) {
+		return nil, false, nil
+	}
+
+	// Check if our verifier signalled that we met our conditions
+	validShrunkSequence := false
+	if len(testedPossibleShrunkSequence) > 0 {
+		validShrunkSequence, err = shrinkRequest.VerifierFunction(fw, testedPossibleShrunkSequence)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// After testing the sequence, we'll want to rollback changes to reset our testing state.
+	if err = fw.chain.RevertToBlockNumber(fw.testingBaseBlockNumber); err != nil {
+		return nil, false, err
+	}
+	return testedPossibleShrunkSequence, validShrunkSequence, nil
+}
+
+// processShrinkCandidate tests the provided shrinkCandidate on the worker's chain and sends its result.
+func (fw *FuzzerWorker) processShrinkCandidate(candidate *shrinkCandidate) {
+	testedCallSequence, valid, err := fw.testShrinkCandidate(candidate.callSequence, candidate.shrinkRequest)
+	candidate.result <- shrinkCandidateResult{
+		testedCallSequence: testedCallSequence,
+		valid:              valid,
+		err:                err,
+	}
+}
+
+// helpShrinkCallSequences tests any shrinkCandidate which other workers have queued while shrinking call sequences,
+// returning once no more are queued. This is called between testing call sequences, when the worker's chain is at
+// its testing base block.
+func (fw *FuzzerWorker) helpShrinkCallSequences() {
+	for {
+		select {
+		case candidate := <-fw.fuzzer.shrinkCandidates:
+			fw.processShrinkCandidate(candidate)
+		default:
+			return
+		}
+	}
+}
+
+// testShrinkCandidates tests the provided candidate call sequences against the provided shrink request. Candidates
+// are queued for other workers to test between their own call sequences, while this worker tests any that remain
+// queued itself.
+// Returns the results of testing each candidate, in the order the candidates were provided, or an error if one
+// occurred.
+func (fw *FuzzerWorker) testShrinkCandidates(callSequences []calls.CallSequence, shrinkRequest *ShrinkCallSequenceRequest) ([]shrinkCandidateResult, error) {
+	// Queue every candidate but the first for other workers, keeping any which do not fit in the queue for ourselves.
+	candidates := make([]*shrinkCandidate, len(callSequences))
+	localCandidates := make([]*shrinkCandidate, 0, len(callSequences))
+	for i, callSequence := range callSequences {
+		candidates[i] = newShrinkCandidate(callSequence, shrinkRequest)
+		if i == 0 {
+			localCandidates = append(localCandidates, candidates[i])
+			continue
+		}
+		select {
+		case fw.fuzzer.shrinkCandidates <- candidates[i]:
+		default:
+			localCandidates = append(localCandidates, candidates[i])
+		}
+	}
+
+	// Test the candidates we kept.
+	for _, candidate := range localCandidates {
+		fw.processShrinkCandidate(candidate)
+	}
+
+	// Collect our results. While we wait on candidates still queued or being tested by other workers, we test queued
+	// candidates ourselves, so candidates never wait on a worker which is busy (e.g. shrinking a sequence of its own).
+	results := make([]shrinkCandidateResult, len(candidates))
+	for i, candidate := range candidates {
+		for waiting := true; waiting; {
+			select {
+			case results[i] = <-candidate.result:
+				waiting = false
+			case queuedCandidate := <-fw.fuzzer.shrinkCandidates:
+				fw.processShrinkCandidate(queuedCandidate)
+			}
+		}
+		if results[i].err != nil {
+			return nil, results[i].err
+		}
+	}
+	return results, nil
+}
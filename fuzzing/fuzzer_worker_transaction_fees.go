@@ -0,0 +1,32 @@
+package fuzzing
+
+import (
+	"math/big"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+)
+
+// generateTransactionFees sets the fee caps of the provided call. If the FuzzingConfig does not enable dynamic fee
+// transactions, the call is left to be sent as a legacy transaction, which does not pay a base fee. Otherwise, its
+// maxFeePerGas and maxPriorityFeePerGas are drawn from their configured ranges, with the latter capped to the former,
+// so the call is sent as an EIP-1559 transaction.
+func (g *CallSequenceGenerator) generateTransactionFees(msg *calls.CallMessage) {
+	transactionFees := g.worker.fuzzer.config.Fuzzing.TransactionFees
+	if !transactionFees.DynamicFeeTransactions {
+		return
+	}
+	msg.MsgGasFeeCap = g.randomUint64InRange(transactionFees.MinMaxFeePerGas, transactionFees.MaxMaxFeePerGas)
+	msg.MsgGasTipCap = g.randomUint64InRange(transactionFees.MinMaxPriorityFeePerGas, transactionFees.MaxMaxPriorityFeePerGas)
+	if msg.MsgGasTipCap.Cmp(msg.MsgGasFeeCap) > 0 {
+		msg.MsgGasTipCap.Set(msg.MsgGasFeeCap)
+	}
+}
+
+// randomUint64InRange draws an integer uniformly from the provided inclusive range, which must not be empty.
+// Returns the drawn integer.
+func (g *CallSequenceGenerator) randomUint64InRange(min uint64, max uint64) *big.Int {
+	rangeSize := new(big.Int).SetUint64(max - min)
+	rangeSize.Add(rangeSize, big.NewInt(1))
+	value := new(big.Int).Rand(g.worker.randomProvider, rangeSize)
+	return value.Add(value, new(big.Int).SetUint64(min))
+}
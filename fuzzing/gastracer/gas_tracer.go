@@ -0,0 +1,90 @@
+package gastracer
+
+import (
+	"math/big"
+
+	"github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// gasTracerResultsKey describes the key to use when storing tracer results in call message results, or when querying
+// them.
+const gasTracerResultsKey = "GasTracerResults"
+
+// GetGasTracerResults obtains the execution gas recorded by a GasTracer from message results.
+// Returns the execution gas, or false if no gas was recorded by a tracer (e.g. GasTracer was not attached during this
+// message execution).
+func GetGasTracerResults(messageResults *types.MessageResults) (uint64, bool) {
+	// Try to obtain the results the tracer should've stored.
+	if genericResult, ok := messageResults.AdditionalResults[gasTracerResultsKey]; ok {
+		if castedResult, ok := genericResult.(uint64); ok {
+			return castedResult, true
+		}
+	}
+
+	// If we could not obtain them, report that.
+	return 0, false
+}
+
+// GasTracer implements vm.EVMLogger to record the gas consumed by the execution of a transaction. The gas recorded is
+// that used by the top-level call frame, which excludes the intrinsic cost of the transaction (the base cost of 21000
+// and the cost of its calldata). Gas refunds are not subtracted from it, as they are capped relative to the total gas
+// used by the transaction, and would otherwise cause the same execution to be measured differently depending on its
+// calldata.
+type GasTracer struct {
+	// gasUsed describes the gas used by the top-level call frame of the current transaction.
+	gasUsed uint64
+}
+
+// NewGasTracer returns a new GasTracer.
+func NewGasTracer() *GasTracer {
+	tracer := &GasTracer{}
+	tracer.CaptureTxStart(0)
+	return tracer
+}
+
+// CaptureTxStart is called upon the start of transaction execution, as defined by vm.EVMLogger.
+func (t *GasTracer) CaptureTxStart(gasLimit uint64) {
+	// Reset our capture state
+	t.gasUsed = 0
+}
+
+// CaptureTxEnd is called upon the end of transaction execution, as defined by vm.EVMLogger.
+func (t *GasTracer) CaptureTxEnd(restGas uint64) {
+}
+
+// CaptureStart initializes the tracing operation for the top of a call frame, as defined by vm.EVMLogger.
+func (t *GasTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureEnd is called after a call to finalize tracing completes for the top of a call frame, as defined by vm.EVMLogger.
+func (t *GasTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	// The top-level call frame is provided the gas remaining after the intrinsic cost was deducted, so the gas it
+	// used reflects execution alone.
+	t.gasUsed = gasUsed
+}
+
+// CaptureEnter is called upon entering of the call frame, as defined by vm.EVMLogger.
+func (t *GasTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit is called upon exiting of the call frame, as defined by vm.EVMLogger.
+func (t *GasTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureState records data from an EVM state update, as defined by vm.EVMLogger.
+func (t *GasTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, vmErr error) {
+}
+
+// CaptureFault records an execution fault, as defined by vm.EVMLogger.
+func (t *GasTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureTxEndSetAdditionalResults can be used to set additional results captured from execution tracing. If this
+// tracer is used during transaction execution (block creation), the results can later be queried from the block.
+// This method will only be called on the added tracer if it implements the extended TestChainTracer interface.
+func (t *GasTracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
+	// Store our tracer results.
+	results.AdditionalResults[gasTracerResultsKey] = t.gasUsed
+}
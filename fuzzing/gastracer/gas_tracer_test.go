@@ -0,0 +1,45 @@
+package gastracer
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGasTracerExecutionGas ensures the gas tracer records the gas used by the execution of the top-level call frame.
+func TestGasTracerExecutionGas(t *testing.T) {
+	// PUSH1 (3) + PUSH1 (3) + ADD (3) + POP (2) + STOP (0)
+	code := []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x02, byte(vm.ADD), byte(vm.POP), byte(vm.STOP)}
+
+	tracer := NewGasTracer()
+	_, _, err := runtime.Execute(code, nil, &runtime.Config{EVMConfig: vm.Config{Debug: true, Tracer: tracer}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, tracer.gasUsed)
+
+	// Starting a new transaction should reset the recorded gas.
+	tracer.CaptureTxStart(0)
+	assert.Zero(t, tracer.gasUsed)
+}
+
+// TestOutOfGasTracer ensures the out of gas tracer records whether execution ran out of gas.
+func TestOutOfGasTracer(t *testing.T) {
+	// PUSH1 (3) + PUSH1 (3) + ADD (3) + POP (2) + STOP (0)
+	code := []byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x02, byte(vm.ADD), byte(vm.POP), byte(vm.STOP)}
+
+	// Providing enough gas should not record an out of gas error.
+	tracer := NewOutOfGasTracer()
+	_, _, err := runtime.Execute(code, nil, &runtime.Config{GasLimit: 11, EVMConfig: vm.Config{Debug: true, Tracer: tracer}})
+	assert.NoError(t, err)
+	assert.False(t, tracer.outOfGas)
+
+	// Providing too little gas should.
+	_, _, err = runtime.Execute(code, nil, &runtime.Config{GasLimit: 10, EVMConfig: vm.Config{Debug: true, Tracer: tracer}})
+	assert.ErrorIs(t, err, vm.ErrOutOfGas)
+	assert.True(t, tracer.outOfGas)
+
+	// Starting a new transaction should reset the recorded result.
+	tracer.CaptureTxStart(0)
+	assert.False(t, tracer.outOfGas)
+}
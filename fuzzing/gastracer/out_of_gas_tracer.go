@@ -0,0 +1,94 @@
+package gastracer
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// outOfGasTracerResultsKey describes the key to use when storing tracer results in call message results, or when
+// querying them.
+const outOfGasTracerResultsKey = "OutOfGasTracerResults"
+
+// GetOutOfGasTracerResults obtains whether any call frame ran out of gas, as recorded by an OutOfGasTracer, from
+// message results.
+// Returns whether a call frame ran out of gas, and a boolean indicating whether results were recorded by a tracer (e.g.
+// false if OutOfGasTracer was not attached during this message execution).
+func GetOutOfGasTracerResults(messageResults *types.MessageResults) (bool, bool) {
+	// Try to obtain the results the tracer should've stored.
+	if genericResult, ok := messageResults.AdditionalResults[outOfGasTracerResultsKey]; ok {
+		if castedResult, ok := genericResult.(bool); ok {
+			return castedResult, true
+		}
+	}
+
+	// If we could not obtain them, report that.
+	return false, false
+}
+
+// IsOutOfGasError indicates whether the provided error describes a call frame running out of gas.
+func IsOutOfGasError(err error) bool {
+	return errors.Is(err, vm.ErrOutOfGas) || errors.Is(err, vm.ErrCodeStoreOutOfGas)
+}
+
+// OutOfGasTracer implements vm.EVMLogger to record whether any call frame of a transaction ran out of gas. A call
+// frame running out of gas does not necessarily fail the transaction, as its caller may recover from it, so this
+// allows such failures to be distinguished from failures of the contract logic which followed them.
+type OutOfGasTracer struct {
+	// outOfGas describes whether any call frame of the current transaction ran out of gas.
+	outOfGas bool
+}
+
+// NewOutOfGasTracer returns a new OutOfGasTracer.
+func NewOutOfGasTracer() *OutOfGasTracer {
+	tracer := &OutOfGasTracer{}
+	tracer.CaptureTxStart(0)
+	return tracer
+}
+
+// CaptureTxStart is called upon the start of transaction execution, as defined by vm.EVMLogger.
+func (t *OutOfGasTracer) CaptureTxStart(gasLimit uint64) {
+	// Reset our capture state
+	t.outOfGas = false
+}
+
+// CaptureTxEnd is called upon the end of transaction execution, as defined by vm.EVMLogger.
+func (t *OutOfGasTracer) CaptureTxEnd(restGas uint64) {
+}
+
+// CaptureStart initializes the tracing operation for the top of a call frame, as defined by vm.EVMLogger.
+func (t *OutOfGasTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureEnd is called after a call to finalize tracing completes for the top of a call frame, as defined by vm.EVMLogger.
+func (t *OutOfGasTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	t.outOfGas = t.outOfGas || IsOutOfGasError(err)
+}
+
+// CaptureEnter is called upon entering of the call frame, as defined by vm.EVMLogger.
+func (t *OutOfGasTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+// CaptureExit is called upon exiting of the call frame, as defined by vm.EVMLogger.
+func (t *OutOfGasTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	t.outOfGas = t.outOfGas || IsOutOfGasError(err)
+}
+
+// CaptureState records data from an EVM state update, as defined by vm.EVMLogger.
+func (t *OutOfGasTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, vmErr error) {
+}
+
+// CaptureFault records an execution fault, as defined by vm.EVMLogger.
+func (t *OutOfGasTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureTxEndSetAdditionalResults can be used to set additional results captured from execution tracing. If this
+// tracer is used during transaction execution (block creation), the results can later be queried from the block.
+// This method will only be called on the added tracer if it implements the extended TestChainTracer interface.
+func (t *OutOfGasTracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
+	// Store our tracer results.
+	results.AdditionalResults[outOfGasTracerResultsKey] = t.outOfGas
+}
@@ -0,0 +1,184 @@
+package panictracer
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/crytic/medusa/chain/types"
+	"github.com/crytic/medusa/compilation/abiutils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// swallowedPanicTracerResultsKey describes the key to use when storing tracer results in call message results, or
+// when querying them.
+const swallowedPanicTracerResultsKey = "SwallowedPanicTracerResults"
+
+// SwallowedPanic describes a Solidity panic (e.g. a failed assertion) raised by an inner call frame of a transaction,
+// which did not propagate to the end of the transaction, as a calling frame caught it (e.g. with a try/catch
+// statement).
+type SwallowedPanic struct {
+	// Address describes the address of the contract whose code raised the panic, which is the code address for
+	// delegated calls.
+	Address common.Address
+
+	// Create describes whether the panic was raised by the init code of a contract being created at Address, rather
+	// than by its runtime code.
+	Create bool
+
+	// PC describes the program counter of the instruction which raised the panic.
+	PC uint64
+
+	// Depth describes the depth of the call frame which raised the panic, where the top-level call frame has a depth
+	// of zero.
+	Depth int
+
+	// PanicCode describes the Solidity panic code raised. Failed assertions in compilations prior to Solidity 0.8.0
+	// execute an invalid opcode, which is treated as abiutils.PanicCodeAssertFailed.
+	PanicCode *big.Int
+}
+
+// GetSwallowedPanicTracerResults obtains the panics raised by inner call frames which did not propagate to the end of
+// the transaction, stored by a SwallowedPanicTracer from message results. This is nil if no panics were recorded by a
+// tracer (e.g. SwallowedPanicTracer was not attached during this message execution).
+func GetSwallowedPanicTracerResults(messageResults *types.MessageResults) []SwallowedPanic {
+	// Try to obtain the results the tracer should've stored.
+	if genericResult, ok := messageResults.AdditionalResults[swallowedPanicTracerResultsKey]; ok {
+		if castedResult, ok := genericResult.([]SwallowedPanic); ok {
+			return castedResult
+		}
+	}
+
+	// If we could not obtain them, return nil.
+	return nil
+}
+
+// swallowedPanicTracerFrame describes a call frame executing while a SwallowedPanicTracer is tracing.
+type swallowedPanicTracerFrame struct {
+	// address describes the address of the contract whose code the frame executes.
+	address common.Address
+
+	// create describes whether the frame executes the init code of a contract being created.
+	create bool
+
+	// pc describes the program counter of the last instruction the frame executed.
+	pc uint64
+
+	// childPanicIndex describes the index of the recorded panic which the last child frame to panic exited with, or
+	// -1 if no child frame has panicked.
+	childPanicIndex int
+
+	// childPanicOutput describes the return data the last child frame to panic exited with.
+	childPanicOutput []byte
+}
+
+// SwallowedPanicTracer implements vm.EVMLogger to record the Solidity panics raised by the inner call frames of a
+// transaction which did not propagate to the end of it. A panic a frame propagates by reverting with the same return
+// data as the frame which raised it is attributed to the frame which raised it.
+type SwallowedPanicTracer struct {
+	// frames describes the call frames currently executing, with the current call frame last.
+	frames []*swallowedPanicTracerFrame
+
+	// panics describes the panics raised by inner call frames in the current transaction.
+	panics []SwallowedPanic
+}
+
+// NewSwallowedPanicTracer returns a new SwallowedPanicTracer.
+func NewSwallowedPanicTracer() *SwallowedPanicTracer {
+	tracer := &SwallowedPanicTracer{}
+	tracer.CaptureTxStart(0)
+	return tracer
+}
+
+// CaptureTxStart is called upon the start of transaction execution, as defined by vm.EVMLogger.
+func (t *SwallowedPanicTracer) CaptureTxStart(gasLimit uint64) {
+	// Reset our capture state
+	t.frames = make([]*swallowedPanicTracerFrame, 0)
+	t.panics = nil
+}
+
+// CaptureTxEnd is called upon the end of transaction execution, as defined by vm.EVMLogger.
+func (t *SwallowedPanicTracer) CaptureTxEnd(restGas uint64) {
+}
+
+// CaptureStart initializes the tracing operation for the top of a call frame, as defined by vm.EVMLogger.
+func (t *SwallowedPanicTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames, &swallowedPanicTracerFrame{address: to, create: create, childPanicIndex: -1})
+}
+
+// CaptureEnd is called after a call to finalize tracing completes for the top of a call frame, as defined by vm.EVMLogger.
+func (t *SwallowedPanicTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	if len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	// If the transaction itself ended with a panic propagated from an inner frame, that panic was not swallowed.
+	if frame.childPanicIndex >= 0 && abiutils.GetSolidityPanicCode(err, output, false) != nil && bytes.Equal(output, frame.childPanicOutput) {
+		t.panics = append(t.panics[:frame.childPanicIndex], t.panics[frame.childPanicIndex+1:]...)
+	}
+}
+
+// CaptureEnter is called upon entering of the call frame, as defined by vm.EVMLogger.
+func (t *SwallowedPanicTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames, &swallowedPanicTracerFrame{address: to, create: typ == vm.CREATE || typ == vm.CREATE2, childPanicIndex: -1})
+}
+
+// CaptureExit is called upon exiting of the call frame, as defined by vm.EVMLogger.
+func (t *SwallowedPanicTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(t.frames) < 2 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	// If the frame did not exit with a panic, there is nothing to record.
+	panicCode := abiutils.GetSolidityPanicCode(err, output, true)
+	if panicCode == nil {
+		return
+	}
+
+	// If the frame reverted with the same panic as its last child frame to panic, it propagated it, so we attribute
+	// it to the frame which raised it. Otherwise, this frame raised it.
+	panicIndex := frame.childPanicIndex
+	if panicIndex < 0 || err != vm.ErrExecutionReverted || !bytes.Equal(output, frame.childPanicOutput) {
+		panicIndex = len(t.panics)
+		t.panics = append(t.panics, SwallowedPanic{
+			Address:   frame.address,
+			Create:    frame.create,
+			PC:        frame.pc,
+			Depth:     len(t.frames),
+			PanicCode: panicCode,
+		})
+	}
+
+	// Record the panic with our parent frame, in case it propagates it.
+	parent := t.frames[len(t.frames)-1]
+	parent.childPanicIndex = panicIndex
+	parent.childPanicOutput = output
+}
+
+// CaptureState records data from an EVM state update, as defined by vm.EVMLogger.
+func (t *SwallowedPanicTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, vmErr error) {
+	// Record the last instruction executed by the current frame, which is the one raising a panic if it exits with
+	// one.
+	if len(t.frames) > 0 {
+		t.frames[len(t.frames)-1].pc = pc
+	}
+}
+
+// CaptureFault records an execution fault, as defined by vm.EVMLogger.
+func (t *SwallowedPanicTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if len(t.frames) > 0 {
+		t.frames[len(t.frames)-1].pc = pc
+	}
+}
+
+// CaptureTxEndSetAdditionalResults can be used to set additional results captured from execution tracing. If this
+// tracer is used during transaction execution (block creation), the results can later be queried from the block.
+// This method will only be called on the added tracer if it implements the extended TestChainTracer interface.
+func (t *SwallowedPanicTracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
+	// Store our tracer results.
+	results.AdditionalResults[swallowedPanicTracerResultsKey] = t.panics
+}
@@ -0,0 +1,85 @@
+package panictracer
+
+import (
+	"testing"
+
+	"github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+// panickingContractCode reverts with a Solidity Panic(0x01), as a failed assertion does. The REVERT instruction is at
+// program counter 20.
+var panickingContractCode = []byte{
+	byte(vm.PUSH4), 0x4e, 0x48, 0x7b, 0x71, byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0x00, byte(vm.MSTORE),
+	byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x04, byte(vm.MSTORE),
+	byte(vm.PUSH1), 0x24, byte(vm.PUSH1), 0x00, byte(vm.REVERT),
+}
+
+// callerCode returns code which calls the contract at the provided address, then either stops, or reverts with the
+// return data of the call if propagate is true.
+func callerCode(callee common.Address, propagate bool) []byte {
+	code := []byte{
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00,
+		byte(vm.PUSH20),
+	}
+	code = append(code, callee.Bytes()...)
+	code = append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.POP))
+	if propagate {
+		code = append(code,
+			byte(vm.RETURNDATASIZE), byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.RETURNDATACOPY),
+			byte(vm.RETURNDATASIZE), byte(vm.PUSH1), 0x00, byte(vm.REVERT),
+		)
+	}
+	return append(code, byte(vm.STOP))
+}
+
+// executeWithSwallowedPanicTracer executes the provided code with a SwallowedPanicTracer attached, after setting the
+// code of the provided callee.
+// Returns the panics recorded by the tracer.
+func executeWithSwallowedPanicTracer(t *testing.T, code []byte, callee common.Address, calleeCode []byte) []SwallowedPanic {
+	stateDB, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	assert.NoError(t, err)
+	stateDB.SetCode(callee, calleeCode)
+
+	tracer := NewSwallowedPanicTracer()
+	_, _, _ = runtime.Execute(code, nil, &runtime.Config{State: stateDB, EVMConfig: vm.Config{Debug: true, Tracer: tracer}})
+	results := &types.MessageResults{AdditionalResults: make(map[string]any)}
+	tracer.CaptureTxEndSetAdditionalResults(results)
+	return GetSwallowedPanicTracerResults(results)
+}
+
+// TestSwallowedPanicTracer ensures panics raised by inner call frames are recorded with the frame which raised them
+// when they are caught, and not when they propagate to the end of the transaction.
+func TestSwallowedPanicTracer(t *testing.T) {
+	callee := common.HexToAddress("0xbb")
+
+	// A caller which ignores the failure of its call swallows the panic.
+	panics := executeWithSwallowedPanicTracer(t, callerCode(callee, false), callee, panickingContractCode)
+	if assert.Len(t, panics, 1) {
+		assert.EqualValues(t, callee, panics[0].Address)
+		assert.EqualValues(t, 20, panics[0].PC)
+		assert.EqualValues(t, 1, panics[0].Depth)
+		assert.EqualValues(t, 1, panics[0].PanicCode.Uint64())
+		assert.False(t, panics[0].Create)
+	}
+
+	// A caller which reverts with the return data of its call propagates the panic to the end of the transaction.
+	panics = executeWithSwallowedPanicTracer(t, callerCode(callee, true), callee, panickingContractCode)
+	assert.Empty(t, panics)
+
+	// Failed assertions of older Solidity versions execute an invalid opcode, which are recorded as assertions.
+	panics = executeWithSwallowedPanicTracer(t, callerCode(callee, false), callee, []byte{byte(vm.PUSH1), 0x00, byte(vm.INVALID)})
+	if assert.Len(t, panics, 1) {
+		assert.EqualValues(t, 2, panics[0].PC)
+		assert.EqualValues(t, 1, panics[0].PanicCode.Uint64())
+	}
+
+	// Calls which succeed, or revert without a panic, record nothing.
+	panics = executeWithSwallowedPanicTracer(t, callerCode(callee, false), callee, []byte{byte(vm.PUSH1), 0x00, byte(vm.DUP1), byte(vm.REVERT)})
+	assert.Empty(t, panics)
+}
@@ -0,0 +1,376 @@
+package reproducers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FoundryDeployment describes a contract deployment which a Foundry reproducer performs during setup, so contracts
+// are deployed at the same addresses they were while fuzzing.
+type FoundryDeployment struct {
+	// Deployer describes the address which deployed the contract.
+	Deployer common.Address
+
+	// Nonce describes the nonce of the deployer when deploying the contract.
+	Nonce uint64
+
+	// Address describes the address the contract was deployed at.
+	Address common.Address
+
+	// Contract describes the compiled contract which was deployed, or nil if it could not be matched to one.
+	Contract *contracts.Contract
+
+	// Data describes the init bytecode (including any constructor arguments) used to deploy the contract.
+	Data []byte
+}
+
+// FoundryReproducer describes a failing call sequence to be converted into a self-contained Foundry test.
+type FoundryReproducer struct {
+	// Name describes the name of the test which failed, used to name the reproducer.
+	Name string
+
+	// Deployments describes the contract deployments performed during setup, in order.
+	Deployments []FoundryDeployment
+
+	// CallSequence describes the failing call sequence to replay.
+	CallSequence calls.CallSequence
+
+	// PropertyTest describes the property test which fails at the end of the call sequence, or nil if the last call
+	// in the call sequence fails an assertion instead.
+	PropertyTest *contracts.DeployedContractMethod
+
+	// PropertyTestSender describes the address the property test is called from.
+	PropertyTestSender common.Address
+
+	// ExpectAssertionFailure describes whether the last call in the call sequence is expected to fail an assertion
+	// (revert with the panic described by AssertionFailurePanicCode). This is ignored if PropertyTest is set.
+	ExpectAssertionFailure bool
+
+	// AssertionFailurePanicCode describes the Solidity panic code the last call in the call sequence is expected to
+	// revert with if ExpectAssertionFailure is set, or nil to expect the panic code of a failed assertion.
+	AssertionFailurePanicCode *big.Int
+
+	// ExpectLastCallSuccess describes whether the last call in the call sequence is expected to succeed, for methods
+	// which fail by not reverting when expected to. This is ignored if PropertyTest or ExpectAssertionFailure is set.
+	ExpectLastCallSuccess bool
+
+	// UnexpectedRevertError describes the custom error the last call in the call sequence was expected to revert
+	// with, for methods which fail by not reverting with it. The last call is expected to either succeed, or revert
+	// with other data. This is ignored if PropertyTest, ExpectAssertionFailure or ExpectLastCallSuccess is set.
+	UnexpectedRevertError *abi.Error
+}
+
+// expectsRevertDataMismatch indicates whether the reproducer asserts the last call in the call sequence did not revert
+// with the UnexpectedRevertError.
+func (r *FoundryReproducer) expectsRevertDataMismatch() bool {
+	return r.PropertyTest == nil && !r.ExpectAssertionFailure && !r.ExpectLastCallSuccess && r.UnexpectedRevertError != nil
+}
+
+// nonIdentifierCharacters matches characters which may not be used in Solidity identifiers.
+var nonIdentifierCharacters = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// ContractName obtains the name of the Foundry test contract generated for the reproducer.
+func (r *FoundryReproducer) ContractName() string {
+	name := strings.Trim(nonIdentifierCharacters.ReplaceAllString(r.Name, "_"), "_")
+	return "Reproducer_" + name
+}
+
+// Generate generates the source of a self-contained Foundry test which replays the call sequence, pranking the
+// sender of each call and warping/rolling to the block timestamp and number it was executed in, then asserts the
+// failure it reproduces.
+// Returns the Solidity source of the test, or an error if one occurs.
+func (r *FoundryReproducer) Generate() (string, error) {
+	var source bytes.Buffer
+
+	// Write our header and import the source of every contract we deploy.
+	source.WriteString("// SPDX-License-Identifier: UNLICENSED\n")
+	source.WriteString("pragma solidity ^0.8.0;\n\n")
+	source.WriteString("import \"forge-std/Test.sol\";\n")
+	imported := make(map[string]bool)
+	for _, deployment := range r.Deployments {
+		if deployment.Contract != nil && deployment.Contract.SourcePath() != "" && !imported[deployment.Contract.SourcePath()] {
+			imported[deployment.Contract.SourcePath()] = true
+			source.WriteString(fmt.Sprintf("import \"%v\";\n", deployment.Contract.SourcePath()))
+		}
+	}
+	source.WriteString("\n")
+	source.WriteString(fmt.Sprintf("// Reproduces the failure of %v, found by medusa.\n", r.Name))
+	source.WriteString(fmt.Sprintf("contract %v is Test {\n", r.ContractName()))
+
+	// Write our setup, deploying our contracts with the same deployers and nonces, so their addresses match.
+	source.WriteString("    function setUp() public {\n")
+	for _, deployment := range r.Deployments {
+		initCode := fmt.Sprintf("hex\"%x\"", deployment.Data)
+		if deployment.Contract != nil && bytes.HasPrefix(deployment.Data, deployment.Contract.CompiledContract().InitBytecode) {
+			initCode = fmt.Sprintf("abi.encodePacked(type(%v).creationCode, hex\"%x\")", deployment.Contract.Name(), deployment.Data[len(deployment.Contract.CompiledContract().InitBytecode):])
+		}
+		source.WriteString(fmt.Sprintf("        vm.setNonce(%v, %d);\n", deployment.Deployer.Hex(), deployment.Nonce))
+		source.WriteString(fmt.Sprintf("        vm.prank(%v);\n", deployment.Deployer.Hex()))
+		source.WriteString(fmt.Sprintf("        assertEq(_deploy(%v), %v);\n", initCode, deployment.Address.Hex()))
+	}
+	source.WriteString("    }\n\n")
+
+	// Write our test, replaying each call.
+	source.WriteString("    function test_reproduce() public {\n")
+	source.WriteString("        bool success;\n")
+	lastCallResult := ""
+	if r.expectsRevertDataMismatch() {
+		source.WriteString("        bytes memory lastCallResult;\n")
+		lastCallResult = "lastCallResult"
+	}
+	dealt := make(map[common.Address]bool)
+	var blockNumber, blockTimestamp uint64
+	for i, element := range r.CallSequence {
+		// Fund our sender so it can pay any value sent.
+		sender := element.Call.MsgFrom
+		if !dealt[sender] {
+			dealt[sender] = true
+			source.WriteString(fmt.Sprintf("        vm.deal(%v, type(uint128).max);\n", sender.Hex()))
+		}
+
+		// Move to the block of the call, if it was recorded, otherwise apply the delay it describes.
+		if element.ChainReference != nil {
+			header := element.ChainReference.Block.Header
+			if header.Number.Uint64() != blockNumber {
+				blockNumber = header.Number.Uint64()
+				source.WriteString(fmt.Sprintf("        vm.roll(%d);\n", blockNumber))
+			}
+			if header.Time != blockTimestamp {
+				blockTimestamp = header.Time
+				source.WriteString(fmt.Sprintf("        vm.warp(%d);\n", blockTimestamp))
+			}
+		} else {
+			if element.BlockNumberDelay > 0 {
+				source.WriteString(fmt.Sprintf("        vm.roll(block.number + %d);\n", element.BlockNumberDelay))
+			}
+			if element.BlockTimestampDelay > 0 {
+				source.WriteString(fmt.Sprintf("        vm.warp(block.timestamp + %d);\n", element.BlockTimestampDelay))
+			}
+		}
+
+		// Encode our call data, preferring readable Solidity literals for our arguments.
+		callData, err := r.encodeCallData(&source, element, i)
+		if err != nil {
+			return "", err
+		}
+
+		// If this is our last call and it is expected to fail an assertion, expect it to revert with its panic code.
+		// If its revert data is asserted instead, we capture it.
+		returnData := ""
+		if i == len(r.CallSequence)-1 {
+			if r.PropertyTest == nil && r.ExpectAssertionFailure {
+				panicCode := big.NewInt(1)
+				if r.AssertionFailurePanicCode != nil {
+					panicCode = r.AssertionFailurePanicCode
+				}
+				source.WriteString(fmt.Sprintf("        vm.expectRevert(abi.encodeWithSignature(\"Panic(uint256)\", uint256(0x%02x)));\n", panicCode))
+			}
+			returnData = lastCallResult
+		}
+
+		// Make our call.
+		value := ""
+		if element.Call.MsgValue != nil && element.Call.MsgValue.Sign() > 0 {
+			value = fmt.Sprintf("{value: %v}", element.Call.MsgValue.String())
+		}
+		if element.Call.MsgTo == nil {
+			return "", fmt.Errorf("call %d deploys a contract, which reproducers do not support", i)
+		}
+		source.WriteString(fmt.Sprintf("        vm.prank(%v);\n", sender.Hex()))
+		if element.Call.MsgAgent != nil {
+			// Calls routed through an agent contract are forwarded to their target by the agent.
+			forwardedValue := big.NewInt(0)
+			if element.Call.MsgValue != nil {
+				forwardedValue = element.Call.MsgValue
+			}
+			callData = fmt.Sprintf("abi.encodeWithSignature(\"%v\", %v, %v, %v)", calls.AgentExecuteMethodSignature, element.Call.MsgTo.Hex(), callData, forwardedValue.String())
+			source.WriteString(fmt.Sprintf("        (success, %v) = %v.call%v(%v);\n", returnData, element.Call.MsgAgent.Hex(), value, callData))
+			continue
+		}
+		source.WriteString(fmt.Sprintf("        (success, %v) = %v.call%v(%v);\n", returnData, element.Call.MsgTo.Hex(), value, callData))
+	}
+
+	// Assert the failure we reproduce.
+	if r.PropertyTest == nil && !r.ExpectAssertionFailure && r.ExpectLastCallSuccess && len(r.CallSequence) > 0 {
+		source.WriteString("        assertTrue(success, \"expected the last call not to revert\");\n")
+	}
+	if r.expectsRevertDataMismatch() && len(r.CallSequence) > 0 {
+		source.WriteString(fmt.Sprintf("        assertTrue(success || _selector(lastCallResult) != bytes4(0x%x), \"expected the last call not to revert with error %v\");\n", r.UnexpectedRevertError.ID.Bytes()[:4], r.UnexpectedRevertError.Sig))
+	}
+	if r.PropertyTest != nil {
+		source.WriteString("        bytes memory propertyTestResult;\n")
+		source.WriteString(fmt.Sprintf("        vm.prank(%v);\n", r.PropertyTestSender.Hex()))
+		source.WriteString(fmt.Sprintf("        (success, propertyTestResult) = %v.call(abi.encodeWithSignature(\"%v\"));\n", r.PropertyTest.Address.Hex(), r.PropertyTest.Method.Sig))
+		source.WriteString(fmt.Sprintf("        assertTrue(!success || !abi.decode(propertyTestResult, (bool)), \"expected property test %v to fail\");\n", r.PropertyTest.Method.Sig))
+	}
+	source.WriteString("    }\n\n")
+
+	// Write our deployment helper.
+	source.WriteString("    function _deploy(bytes memory initCode) internal returns (address deployed) {\n")
+	source.WriteString("        assembly {\n")
+	source.WriteString("            deployed := create(0, add(initCode, 0x20), mload(initCode))\n")
+	source.WriteString("        }\n")
+	source.WriteString("        require(deployed != address(0), \"deployment failed\");\n")
+	source.WriteString("    }\n")
+
+	// Write our helper obtaining the selector of revert data, if we assert it.
+	if r.expectsRevertDataMismatch() {
+		source.WriteString("\n    function _selector(bytes memory data) internal pure returns (bytes4) {\n")
+		source.WriteString("        if (data.length < 4) {\n")
+		source.WriteString("            return bytes4(0);\n")
+		source.WriteString("        }\n")
+		source.WriteString("        return bytes4(data[0]) | (bytes4(data[1]) >> 8) | (bytes4(data[2]) >> 16) | (bytes4(data[3]) >> 24);\n")
+		source.WriteString("    }\n")
+	}
+	source.WriteString("}\n")
+	return source.String(), nil
+}
+
+// encodeCallData obtains a Solidity expression encoding the call data of the provided call sequence element. Method
+// calls are encoded with their arguments as Solidity literals, declaring any dynamic arrays as local variables in the
+// provided source. Call data which cannot be expressed this way is encoded as raw bytes.
+// Returns the Solidity expression encoding the call data, or an error if one occurs.
+func (r *FoundryReproducer) encodeCallData(source *bytes.Buffer, element *calls.CallSequenceElement, callIndex int) (string, error) {
+	abiValues := element.Call.MsgDataAbiValues
+	if abiValues != nil && abiValues.Method != nil {
+		encoder := &solidityLiteralEncoder{declarations: &bytes.Buffer{}, variablePrefix: fmt.Sprintf("call%dArg", callIndex)}
+		arguments := make([]string, 0, len(abiValues.InputValues)+1)
+		arguments = append(arguments, fmt.Sprintf("\"%v\"", abiValues.Method.Sig))
+		encoded := true
+		for i, input := range abiValues.Method.Inputs {
+			literal, err := encoder.encode(&input.Type, abiValues.InputValues[i])
+			if err != nil {
+				encoded = false
+				break
+			}
+			arguments = append(arguments, literal)
+		}
+		if encoded {
+			source.Write(encoder.declarations.Bytes())
+			return fmt.Sprintf("abi.encodeWithSignature(%v)", strings.Join(arguments, ", ")), nil
+		}
+	}
+
+	// Fall back to our raw call data.
+	data := element.Call.MsgData
+	if abiValues != nil {
+		var err error
+		data, err = abiValues.Pack()
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("hex\"%x\"", data), nil
+}
+
+// solidityLiteralEncoder encodes ABI values as Solidity expressions.
+type solidityLiteralEncoder struct {
+	// declarations describes the statements declaring local variables used by the encoded expressions, which must
+	// precede them.
+	declarations *bytes.Buffer
+
+	// variablePrefix describes the prefix of the names of declared local variables.
+	variablePrefix string
+
+	// variableCount describes the count of local variables declared so far.
+	variableCount int
+}
+
+// encode encodes the provided value of the provided ABI type as a Solidity expression of that type. Dynamic arrays are
+// declared as local variables, as Solidity array literals are statically sized.
+// Returns the Solidity expression, or an error if the value cannot be expressed as one.
+func (e *solidityLiteralEncoder) encode(inputType *abi.Type, value any) (string, error) {
+	switch inputType.T {
+	case abi.UintTy, abi.IntTy:
+		n, ok := new(big.Int).SetString(fmt.Sprintf("%v", value), 10)
+		if !ok {
+			return "", fmt.Errorf("invalid integer value")
+		}
+		return fmt.Sprintf("%v(%v)", inputType.String(), n.String()), nil
+	case abi.AddressTy:
+		address, ok := value.(common.Address)
+		if !ok {
+			return "", fmt.Errorf("invalid address value")
+		}
+		return fmt.Sprintf("address(%v)", address.Hex()), nil
+	case abi.BoolTy:
+		b, ok := value.(bool)
+		if !ok {
+			return "", fmt.Errorf("invalid bool value")
+		}
+		return fmt.Sprintf("%v", b), nil
+	case abi.StringTy:
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("invalid string value")
+		}
+		// Printable strings are emitted as string literals, others as hex literals.
+		if utf8.ValidString(s) && !strings.ContainsAny(s, "\"\\\n\r\t") && strings.IndexFunc(s, func(r rune) bool { return r < 0x20 || r > 0x7e }) < 0 {
+			return fmt.Sprintf("string(\"%v\")", s), nil
+		}
+		return fmt.Sprintf("string(hex\"%x\")", s), nil
+	case abi.BytesTy:
+		b, ok := value.([]byte)
+		if !ok {
+			return "", fmt.Errorf("invalid bytes value")
+		}
+		return fmt.Sprintf("bytes(hex\"%x\")", b), nil
+	case abi.FixedBytesTy:
+		reflectedValue := reflect.ValueOf(value)
+		if reflectedValue.Kind() != reflect.Array {
+			return "", fmt.Errorf("invalid fixed bytes value")
+		}
+		b := make([]byte, reflectedValue.Len())
+		reflect.Copy(reflect.ValueOf(b), reflectedValue)
+		return fmt.Sprintf("%v(hex\"%v\")", inputType.String(), hex.EncodeToString(b)), nil
+	case abi.ArrayTy:
+		// Static arrays are emitted as array literals.
+		reflectedValue := reflect.ValueOf(value)
+		if reflectedValue.Kind() != reflect.Array {
+			return "", fmt.Errorf("invalid array value")
+		}
+		elements := make([]string, reflectedValue.Len())
+		for i := 0; i < reflectedValue.Len(); i++ {
+			element, err := e.encode(inputType.Elem, reflectedValue.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			elements[i] = element
+		}
+		return fmt.Sprintf("[%v]", strings.Join(elements, ", ")), nil
+	case abi.SliceTy:
+		// Dynamic arrays are declared as local variables, assigning each element.
+		reflectedValue := reflect.ValueOf(value)
+		if reflectedValue.Kind() != reflect.Slice {
+			return "", fmt.Errorf("invalid array value")
+		}
+		elements := make([]string, reflectedValue.Len())
+		for i := 0; i < reflectedValue.Len(); i++ {
+			element, err := e.encode(inputType.Elem, reflectedValue.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			elements[i] = element
+		}
+		variableName := fmt.Sprintf("%v%d", e.variablePrefix, e.variableCount)
+		e.variableCount++
+		e.declarations.WriteString(fmt.Sprintf("        %v memory %v = new %v(%d);\n", inputType.String(), variableName, inputType.String(), len(elements)))
+		for i, element := range elements {
+			e.declarations.WriteString(fmt.Sprintf("        %v[%d] = %v;\n", variableName, i, element))
+		}
+		return variableName, nil
+	default:
+		return "", fmt.Errorf("values of type %v cannot be expressed as Solidity literals", inputType.String())
+	}
+}
@@ -0,0 +1,112 @@
+package reproducers
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFoundryReproducerGenerate ensures Foundry reproducers prank senders, apply block delays, encode arguments as
+// valid Solidity literals (including dynamic bytes and nested arrays) and assert the failure they reproduce.
+func TestFoundryReproducerGenerate(t *testing.T) {
+	// newType creates an ABI type from the provided type name, expecting no error.
+	newType := func(typeName string) abi.Type {
+		abiType, err := abi.NewType(typeName, "", nil)
+		assert.NoError(t, err)
+		return abiType
+	}
+
+	// Create a method taking a variety of argument types, and a call to it.
+	method := abi.NewMethod("f", "f", abi.Function, "", false, false, abi.Arguments{
+		{Name: "a", Type: newType("uint8")},
+		{Name: "b", Type: newType("int256")},
+		{Name: "c", Type: newType("bytes")},
+		{Name: "d", Type: newType("string")},
+		{Name: "e", Type: newType("uint256[][]")},
+		{Name: "f", Type: newType("bytes2[2]")},
+	}, nil)
+	sender := common.HexToAddress("0x10000")
+	target := common.HexToAddress("0xA647ff3c36cFab592509E13860ab8c4F28781a66")
+	callData := &calls.CallMessageDataAbiValues{
+		Method: &method,
+		InputValues: []any{
+			uint8(7),
+			big.NewInt(-5),
+			[]byte{0xde, 0xad},
+			"hello",
+			[][]*big.Int{{big.NewInt(1), big.NewInt(2)}, {}},
+			[2][2]byte{{0x01, 0x02}, {0x03, 0x04}},
+		},
+	}
+	call := calls.NewCallMessageWithAbiValueData(sender, &target, 0, big.NewInt(1), 0, nil, nil, nil, callData)
+	propertyTest := abi.NewMethod("property_x", "property_x", abi.Function, "", false, false, nil, abi.Arguments{{Name: "", Type: newType("bool")}})
+
+	// Generate a reproducer for a property test failing after the call.
+	reproducer := &FoundryReproducer{
+		Name: "C.property_x",
+		Deployments: []FoundryDeployment{
+			{Deployer: common.HexToAddress("0x30000"), Nonce: 0, Address: target, Data: []byte{0x60, 0x80}},
+		},
+		CallSequence:       calls.CallSequence{calls.NewCallSequenceElement(nil, call, 2, 10)},
+		PropertyTest:       &contracts.DeployedContractMethod{Address: target, Method: propertyTest},
+		PropertyTestSender: sender,
+	}
+	source, err := reproducer.Generate()
+	assert.NoError(t, err)
+
+	// Verify our deployment, sender funding, delays and call were generated.
+	assert.Contains(t, source, "contract Reproducer_C_property_x is Test {")
+	assert.Contains(t, source, "assertEq(_deploy(hex\"6080\"), 0xA647ff3c36cFab592509E13860ab8c4F28781a66);")
+	assert.Contains(t, source, "vm.roll(block.number + 2);")
+	assert.Contains(t, source, "vm.warp(block.timestamp + 10);")
+	assert.Contains(t, source, "vm.prank(0x0000000000000000000000000000000000010000);")
+	assert.Contains(t, source, "        uint256[] memory call0Arg0 = new uint256[](2);\n        call0Arg0[0] = uint256(1);\n        call0Arg0[1] = uint256(2);\n")
+	assert.Contains(t, source, "        uint256[] memory call0Arg1 = new uint256[](0);\n")
+	assert.Contains(t, source, "        uint256[][] memory call0Arg2 = new uint256[][](2);\n        call0Arg2[0] = call0Arg0;\n        call0Arg2[1] = call0Arg1;\n")
+	assert.Contains(t, source, "(success, ) = 0xA647ff3c36cFab592509E13860ab8c4F28781a66.call{value: 1}(abi.encodeWithSignature(\"f(uint8,int256,bytes,string,uint256[][],bytes2[2])\", uint8(7), int256(-5), bytes(hex\"dead\"), string(\"hello\"), call0Arg2, [bytes2(hex\"0102\"), bytes2(hex\"0304\")]));")
+	assert.Contains(t, source, "abi.encodeWithSignature(\"property_x()\")")
+	assert.Contains(t, source, "assertTrue(!success || !abi.decode(propertyTestResult, (bool))")
+
+	// Generate a reproducer for an assertion failure instead, and verify the panic is expected on the last call.
+	reproducer.PropertyTest = nil
+	reproducer.ExpectAssertionFailure = true
+	source, err = reproducer.Generate()
+	assert.NoError(t, err)
+	assert.Contains(t, source, "vm.expectRevert(abi.encodeWithSignature(\"Panic(uint256)\", uint256(0x01)));\n        vm.prank(")
+	assert.NotContains(t, source, "propertyTestResult")
+
+	// Expect the panic code the assertion failure was observed with, if it is not that of a failed assertion.
+	reproducer.AssertionFailurePanicCode = big.NewInt(0x11)
+	source, err = reproducer.Generate()
+	assert.NoError(t, err)
+	assert.Contains(t, source, "vm.expectRevert(abi.encodeWithSignature(\"Panic(uint256)\", uint256(0x11)));\n        vm.prank(")
+
+	// Generate a reproducer for a method which did not revert with the error it was expected to, and verify the
+	// revert data of the last call is asserted not to carry its selector.
+	reproducer.ExpectAssertionFailure = false
+	reproducer.AssertionFailurePanicCode = nil
+	unexpectedRevertError := abi.NewError("InsufficientBalance", abi.Arguments{{Name: "available", Type: newType("uint256")}})
+	reproducer.UnexpectedRevertError = &unexpectedRevertError
+	source, err = reproducer.Generate()
+	assert.NoError(t, err)
+	assert.NotContains(t, source, "vm.expectRevert(")
+	assert.Contains(t, source, "(success, lastCallResult) = 0xA647ff3c36cFab592509E13860ab8c4F28781a66.call{value: 1}(")
+	assert.Contains(t, source, fmt.Sprintf("assertTrue(success || _selector(lastCallResult) != bytes4(0x%x), \"expected the last call not to revert with error InsufficientBalance(uint256)\");", unexpectedRevertError.ID.Bytes()[:4]))
+	assert.Contains(t, source, "function _selector(bytes memory data) internal pure returns (bytes4) {")
+	reproducer.UnexpectedRevertError = nil
+	reproducer.ExpectAssertionFailure = true
+
+	// Route the call through an agent contract, and verify the agent is called to forward it to its target.
+	agent := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	call.MsgAgent = &agent
+	source, err = reproducer.Generate()
+	assert.NoError(t, err)
+	assert.Contains(t, source, "(success, ) = 0x5FbDB2315678afecb367f032d93F642f64180aa3.call{value: 1}(abi.encodeWithSignature(\"execute(address,bytes,uint256)\", 0xA647ff3c36cFab592509E13860ab8c4F28781a66, abi.encodeWithSignature(\"f(uint8,int256,bytes,string,uint256[][],bytes2[2])\"")
+	assert.Contains(t, source, "[bytes2(hex\"0102\"), bytes2(hex\"0304\")]), 1));")
+}
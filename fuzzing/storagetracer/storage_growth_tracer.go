@@ -0,0 +1,177 @@
+package storagetracer
+
+import (
+	"math/big"
+
+	"github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// storageGrowthTracerResultsKey describes the key to use when storing tracer results in call message results, or when
+// querying them.
+const storageGrowthTracerResultsKey = "StorageGrowthTracerResults"
+
+// StorageGrowth describes how the state of a single contract grew during the execution of a transaction.
+type StorageGrowth struct {
+	// SlotsWritten describes the amount of SSTORE instructions which wrote to the contract's storage, including those
+	// in call frames which later reverted.
+	SlotsWritten uint64
+
+	// NetNewSlots describes the amount of the contract's storage slots which were zero before the transaction and
+	// non-zero after it, less the amount which were non-zero before it and zero after it. This is negative if the
+	// transaction cleared more slots than it populated.
+	NetNewSlots int64
+
+	// CodeSize describes the size of the contract's code after the transaction, if the transaction created the
+	// contract. It is zero otherwise, or if the creation failed.
+	CodeSize int
+}
+
+// GetStorageGrowthTracerResults obtains the storage growth of each contract recorded by a StorageGrowthTracer from
+// message results. This is nil if no growth was recorded by a tracer (e.g. StorageGrowthTracer was not attached during
+// this message execution).
+func GetStorageGrowthTracerResults(messageResults *types.MessageResults) map[common.Address]*StorageGrowth {
+	// Try to obtain the results the tracer should've stored.
+	if genericResult, ok := messageResults.AdditionalResults[storageGrowthTracerResultsKey]; ok {
+		if castedResult, ok := genericResult.(map[common.Address]*StorageGrowth); ok {
+			return castedResult
+		}
+	}
+
+	// If we could not obtain them, return nil.
+	return nil
+}
+
+// StorageGrowthTracer implements vm.EVMLogger to count the storage slots each contract's storage is written to during
+// a transaction, and how many of those slots were newly populated (or cleared) once it ended, along with the code size
+// of any contracts it created. Only the first value of each written slot is read, so the tracer remains lightweight.
+type StorageGrowthTracer struct {
+	// env describes the EVM executing the current transaction, used to read the state once it ends.
+	env *vm.EVM
+
+	// growth describes the storage growth recorded for each contract written to or created in the current
+	// transaction.
+	growth map[common.Address]*StorageGrowth
+
+	// originalValues describes the value each written storage slot held before it was first written to in the current
+	// transaction, for each contract.
+	originalValues map[common.Address]map[common.Hash]common.Hash
+
+	// createdContracts describes the addresses of the contracts created in the current transaction.
+	createdContracts map[common.Address]struct{}
+}
+
+// NewStorageGrowthTracer returns a new StorageGrowthTracer.
+func NewStorageGrowthTracer() *StorageGrowthTracer {
+	tracer := &StorageGrowthTracer{}
+	tracer.CaptureTxStart(0)
+	return tracer
+}
+
+// contractGrowth obtains the storage growth recorded for the provided contract address, creating it if it does not
+// exist.
+func (t *StorageGrowthTracer) contractGrowth(address common.Address) *StorageGrowth {
+	growth, ok := t.growth[address]
+	if !ok {
+		growth = &StorageGrowth{}
+		t.growth[address] = growth
+	}
+	return growth
+}
+
+// CaptureTxStart is called upon the start of transaction execution, as defined by vm.EVMLogger.
+func (t *StorageGrowthTracer) CaptureTxStart(gasLimit uint64) {
+	// Reset our capture state
+	t.env = nil
+	t.growth = make(map[common.Address]*StorageGrowth)
+	t.originalValues = make(map[common.Address]map[common.Hash]common.Hash)
+	t.createdContracts = make(map[common.Address]struct{})
+}
+
+// CaptureTxEnd is called upon the end of transaction execution, as defined by vm.EVMLogger.
+func (t *StorageGrowthTracer) CaptureTxEnd(restGas uint64) {
+}
+
+// CaptureStart initializes the tracing operation for the top of a call frame, as defined by vm.EVMLogger.
+func (t *StorageGrowthTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	if create {
+		t.createdContracts[to] = struct{}{}
+	}
+}
+
+// CaptureEnd is called after a call to finalize tracing completes for the top of a call frame, as defined by vm.EVMLogger.
+func (t *StorageGrowthTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureEnter is called upon entering of the call frame, as defined by vm.EVMLogger.
+func (t *StorageGrowthTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if typ == vm.CREATE || typ == vm.CREATE2 {
+		t.createdContracts[to] = struct{}{}
+	}
+}
+
+// CaptureExit is called upon exiting of the call frame, as defined by vm.EVMLogger.
+func (t *StorageGrowthTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+}
+
+// CaptureState records data from an EVM state update, as defined by vm.EVMLogger.
+func (t *StorageGrowthTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, vmErr error) {
+	// We only record SSTORE instructions which are about to execute.
+	if op != vm.SSTORE || vmErr != nil || len(scope.Stack.Data()) < 1 || t.env == nil {
+		return
+	}
+
+	// Count the write against the contract whose storage is written, which is the caller for delegated calls.
+	address := scope.Contract.Address()
+	t.contractGrowth(address).SlotsWritten++
+
+	// If this is the first write to the slot in this transaction, record the value it held before, as this
+	// instruction has not yet executed.
+	slot := common.Hash(scope.Stack.Back(0).Bytes32())
+	contractOriginalValues, ok := t.originalValues[address]
+	if !ok {
+		contractOriginalValues = make(map[common.Hash]common.Hash)
+		t.originalValues[address] = contractOriginalValues
+	}
+	if _, ok := contractOriginalValues[slot]; !ok {
+		contractOriginalValues[slot] = t.env.StateDB.GetState(address, slot)
+	}
+}
+
+// CaptureFault records an execution fault, as defined by vm.EVMLogger.
+func (t *StorageGrowthTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureTxEndSetAdditionalResults can be used to set additional results captured from execution tracing. If this
+// tracer is used during transaction execution (block creation), the results can later be queried from the block.
+// This method will only be called on the added tracer if it implements the extended TestChainTracer interface.
+func (t *StorageGrowthTracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
+	// Compare the final value of each written slot against its original value. Writes in reverted call frames were
+	// undone, so they leave the slot as it was.
+	if t.env != nil {
+		for address, contractOriginalValues := range t.originalValues {
+			growth := t.contractGrowth(address)
+			for slot, originalValue := range contractOriginalValues {
+				populatedBefore := originalValue != (common.Hash{})
+				populatedAfter := t.env.StateDB.GetState(address, slot) != (common.Hash{})
+				if !populatedBefore && populatedAfter {
+					growth.NetNewSlots++
+				} else if populatedBefore && !populatedAfter {
+					growth.NetNewSlots--
+				}
+			}
+		}
+
+		// Record the code size of each contract created, skipping those whose creation failed.
+		for address := range t.createdContracts {
+			if codeSize := t.env.StateDB.GetCodeSize(address); codeSize > 0 {
+				t.contractGrowth(address).CodeSize = codeSize
+			}
+		}
+	}
+
+	// Store our tracer results.
+	results.AdditionalResults[storageGrowthTracerResultsKey] = t.growth
+}
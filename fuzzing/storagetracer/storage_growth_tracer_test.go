@@ -0,0 +1,99 @@
+package storagetracer
+
+import (
+	"testing"
+
+	"github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/stretchr/testify/assert"
+)
+
+// callWithStorageGrowthTracer sets the code of the provided contract in the provided state and calls it with a
+// StorageGrowthTracer attached, retaining the contract's storage from previous calls.
+// Returns the storage growth recorded by the tracer.
+func callWithStorageGrowthTracer(t *testing.T, stateDB *state.StateDB, contract common.Address, code []byte) map[common.Address]*StorageGrowth {
+	stateDB.SetCode(contract, code)
+	tracer := NewStorageGrowthTracer()
+	_, _, err := runtime.Call(contract, nil, &runtime.Config{State: stateDB, EVMConfig: vm.Config{Debug: true, Tracer: tracer}})
+	assert.NoError(t, err)
+	results := &types.MessageResults{AdditionalResults: make(map[string]any)}
+	tracer.CaptureTxEndSetAdditionalResults(results)
+	return GetStorageGrowthTracerResults(results)
+}
+
+// TestStorageGrowthTracer ensures the storage growth tracer counts storage writes and the net amount of storage slots
+// populated in each transaction.
+func TestStorageGrowthTracer(t *testing.T) {
+	stateDB, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	assert.NoError(t, err)
+	contract := common.BytesToAddress([]byte("contract"))
+
+	// Write slot 0 twice and slot 1 once, populating two new slots.
+	code := []byte{
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x02, byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x01, byte(vm.SSTORE),
+		byte(vm.STOP),
+	}
+	growth := callWithStorageGrowthTracer(t, stateDB, contract, code)
+	assert.Len(t, growth, 1)
+	assert.EqualValues(t, 3, growth[contract].SlotsWritten)
+	assert.EqualValues(t, 2, growth[contract].NetNewSlots)
+	assert.Zero(t, growth[contract].CodeSize)
+
+	// Overwrite slot 0, clear slot 1, and populate and then clear slot 2, which is a net loss of one slot.
+	code = []byte{
+		byte(vm.PUSH1), 0x03, byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x01, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x02, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x02, byte(vm.SSTORE),
+		byte(vm.STOP),
+	}
+	growth = callWithStorageGrowthTracer(t, stateDB, contract, code)
+	assert.EqualValues(t, 4, growth[contract].SlotsWritten)
+	assert.EqualValues(t, -1, growth[contract].NetNewSlots)
+}
+
+// TestStorageGrowthTracerRevert ensures storage writes which are reverted are counted, but do not populate slots.
+func TestStorageGrowthTracerRevert(t *testing.T) {
+	stateDB, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	assert.NoError(t, err)
+	contract := common.BytesToAddress([]byte("contract"))
+
+	code := []byte{
+		byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x00, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0x00, byte(vm.DUP1), byte(vm.REVERT),
+	}
+	tracer := NewStorageGrowthTracer()
+	_, _, err = runtime.Execute(code, nil, &runtime.Config{State: stateDB, EVMConfig: vm.Config{Debug: true, Tracer: tracer}})
+	assert.ErrorIs(t, err, vm.ErrExecutionReverted)
+	results := &types.MessageResults{AdditionalResults: make(map[string]any)}
+	tracer.CaptureTxEndSetAdditionalResults(results)
+	growth := GetStorageGrowthTracerResults(results)
+	assert.EqualValues(t, 1, growth[contract].SlotsWritten)
+	assert.Zero(t, growth[contract].NetNewSlots)
+}
+
+// TestStorageGrowthTracerCodeSize ensures the storage growth tracer records the code size of created contracts.
+func TestStorageGrowthTracerCodeSize(t *testing.T) {
+	// Return three bytes of (zeroed) memory as the runtime code of the created contract.
+	initCode := []byte{byte(vm.PUSH1), 0x03, byte(vm.PUSH1), 0x00, byte(vm.RETURN)}
+
+	tracer := NewStorageGrowthTracer()
+	cfg := &runtime.Config{EVMConfig: vm.Config{Debug: true, Tracer: tracer}}
+	_, address, _, err := runtime.Create(initCode, cfg)
+	assert.NoError(t, err)
+	results := &types.MessageResults{AdditionalResults: make(map[string]any)}
+	tracer.CaptureTxEndSetAdditionalResults(results)
+	growth := GetStorageGrowthTracerResults(results)
+	assert.Len(t, growth, 1)
+	assert.EqualValues(t, 3, growth[address].CodeSize)
+
+	// Starting a new transaction should reset the recorded growth.
+	tracer.CaptureTxStart(0)
+	assert.Empty(t, tracer.growth)
+}
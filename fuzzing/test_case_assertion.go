@@ -2,6 +2,7 @@ package fuzzing
 
 import (
 	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/crytic/medusa/fuzzing/calls"
@@ -15,6 +16,21 @@ type AssertionTestCase struct {
 	targetContract *fuzzerTypes.Contract
 	targetMethod   abi.Method
 	callSequence   *calls.CallSequence
+
+	// expectRevert indicates whether the target method is expected to revert whenever it is called, rather than
+	// simply not fail an assertion.
+	expectRevert bool
+	// expectedRevertError describes the custom error the target method is expected to revert with, or nil if any
+	// revert is expected.
+	expectedRevertError *abi.Error
+	// failureReason describes the result of the final call in the call sequence which failed the test.
+	failureReason string
+	// failurePanicCode describes the Solidity panic code the final call in the call sequence which failed the test
+	// reverted with, or nil if it did not revert with one.
+	failurePanicCode *big.Int
+	// expectedEmitFailures describes the event emissions expected through cheat codes which the final call in the
+	// call sequence which failed the test did not meet.
+	expectedEmitFailures []string
 }
 
 // Status describes the TestCaseStatus used to define the current state of the test.
@@ -37,10 +53,37 @@ func (t *AssertionTestCase) Name() string {
 func (t *AssertionTestCase) Message() string {
 	// If the test failed, return a failure message.
 	if t.Status() == TestCaseStatusFailed {
+		// If expected events were not emitted, describe each expectation against the events which were emitted.
+		if len(t.expectedEmitFailures) > 0 {
+			return fmt.Sprintf(
+				"Test for method \"%s.%s\" failed after the following call sequence did not emit expected events:\n%s\n%s",
+				t.targetContract.Name(),
+				t.targetMethod.Sig,
+				strings.Join(t.expectedEmitFailures, "\n"),
+				t.CallSequence().String(),
+			)
+		}
+
+		// If the method was expected to revert, describe the revert we expected and the result we obtained instead.
+		if t.expectRevert {
+			expectedRevert := "a revert"
+			if t.expectedRevertError != nil {
+				expectedRevert = fmt.Sprintf("a revert with error %s", t.expectedRevertError.Sig)
+			}
+			return fmt.Sprintf(
+				"Test for method \"%s.%s\" failed after the following call sequence did not result in %s (result: %s):\n%s",
+				t.targetContract.Name(),
+				t.targetMethod.Sig,
+				expectedRevert,
+				t.failureReason,
+				t.CallSequence().String(),
+			)
+		}
 		return fmt.Sprintf(
-			"Test for method \"%s.%s\" failed after the following call sequence resulted in an assertion:\n%s",
+			"Test for method \"%s.%s\" failed after the following call sequence resulted in an assertion (result: %s):\n%s",
 			t.targetContract.Name(),
 			t.targetMethod.Sig,
+			t.failureReason,
 			t.CallSequence().String(),
 		)
 	}
@@ -1,13 +1,24 @@
 package fuzzing
 
 import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
 	"github.com/crytic/medusa/compilation/abiutils"
 	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/crytic/medusa/fuzzing/panictracer"
+	"github.com/crytic/medusa/fuzzing/valuegeneration"
 	"golang.org/x/exp/slices"
-	"sync"
 
 	"github.com/crytic/medusa/fuzzing/contracts"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
 )
 
 // AssertionTestCaseProvider is am AssertionTestCase provider which spawns test cases for every contract method and
@@ -46,8 +57,137 @@ func attachAssertionTestCaseProvider(fuzzer *Fuzzer) *AssertionTestCaseProvider
 // isTestableMethod checks whether the method is configured by the attached fuzzer to be a target of assertion testing.
 // Returns true if this target should be tested, false otherwise.
 func (t *AssertionTestCaseProvider) isTestableMethod(method abi.Method) bool {
-	// Only test constant methods (pure/view) if we are configured to.
-	return !method.IsConstant() || t.fuzzer.config.Fuzzing.Testing.AssertionTesting.TestViewMethods
+	// Only test constant methods (pure/view) if we are configured to, or they are expected to revert.
+	return !method.IsConstant() || t.fuzzer.config.Fuzzing.Testing.AssertionTesting.TestViewMethods || t.isExpectRevertMethod(method)
+}
+
+// isExpectRevertMethod checks whether the method is expected to revert whenever it is called, given the method name
+// prefixes the attached fuzzer is configured with.
+// Returns true if the method is expected to revert, false otherwise.
+func (t *AssertionTestCaseProvider) isExpectRevertMethod(method abi.Method) bool {
+	return t.expectRevertMethodSuffix(method) != nil
+}
+
+// expectRevertMethodSuffix obtains the remainder of the method name following the expect revert prefix it matched.
+// Returns the remainder of the method name, or nil if the method name does not match an expect revert prefix.
+func (t *AssertionTestCaseProvider) expectRevertMethodSuffix(method abi.Method) *string {
+	return expectRevertMethodSuffix(t.fuzzer.config.Fuzzing.Testing.AssertionTesting, method)
+}
+
+// expectRevertMethodSuffix obtains the remainder of the method name following the expect revert prefix of the provided
+// assertion testing config that it matched.
+// Returns the remainder of the method name, or nil if the method name does not match an expect revert prefix.
+func expectRevertMethodSuffix(assertionTestingConfig config.AssertionTestingConfig, method abi.Method) *string {
+	for _, prefix := range assertionTestingConfig.ExpectRevertPrefixes {
+		if prefix != "" && strings.HasPrefix(method.Name, prefix) {
+			suffix := strings.TrimPrefix(method.Name, prefix)
+			return &suffix
+		}
+	}
+	return nil
+}
+
+// expectedRevertError obtains the custom error in the contract ABI which the provided expect revert method is
+// expected to revert with. This is the error with the longest name that the remainder of the method name (following
+// its expect revert prefix) begins with, e.g. "medusa_revert_Unauthorized_withdraw" expects "Unauthorized".
+// Returns the expected custom error, or nil if any revert is expected.
+func (t *AssertionTestCaseProvider) expectedRevertError(contract *contracts.Contract, method abi.Method) *abi.Error {
+	// If this is not an expect revert method, there is no error to expect.
+	suffix := t.expectRevertMethodSuffix(method)
+	if suffix == nil {
+		return nil
+	}
+
+	// Find the error with the longest matching name.
+	var expectedError *abi.Error
+	for _, abiError := range contract.CompiledContract().Abi.Errors {
+		if strings.HasPrefix(*suffix, abiError.Name) && (expectedError == nil || len(abiError.Name) > len(expectedError.Name)) {
+			// Make a local copy to avoid taking a pointer of a loop variable.
+			abiError := abiError
+			expectedError = &abiError
+		}
+	}
+	return expectedError
+}
+
+// checkExpectedRevert checks whether the provided execution result is a revert which satisfies the provided test
+// case, which expects its target method to revert.
+// Returns a boolean indicating whether the expected revert occurred.
+func checkExpectedRevert(testCase *AssertionTestCase, executionResult *core.ExecutionResult) bool {
+	// If no error occurred, the call did not revert.
+	if executionResult.Err == nil {
+		return false
+	}
+
+	// Failed assertions are never satisfactory reverts.
+	panicCode := abiutils.GetSolidityPanicCode(executionResult.Err, executionResult.ReturnData, true)
+	if panicCode != nil && panicCode.Uint64() == abiutils.PanicCodeAssertFailed {
+		return false
+	}
+
+	// If we expect a specific error, the revert data must carry its selector.
+	if testCase.expectedRevertError != nil {
+		return executionResult.Err == vm.ErrExecutionReverted && len(executionResult.ReturnData) >= 4 &&
+			bytes.Equal(executionResult.ReturnData[:4], testCase.expectedRevertError.ID.Bytes()[:4])
+	}
+	return true
+}
+
+// describeExecutionResult obtains a text-based printable description of the result of a call to the provided
+// contract, decoding any revert reason it can. Custom errors not declared by the contract are resolved from the
+// provided custom errors.
+// Returns a string describing the execution result.
+func describeExecutionResult(contract *contracts.Contract, customErrors abiutils.SolidityCustomErrors, executionResult *core.ExecutionResult) string {
+	// If the call succeeded, there is no revert reason to decode.
+	if executionResult.Err == nil {
+		return "call did not revert"
+	}
+
+	// Try to resolve a panic code.
+	panicCode := abiutils.GetSolidityPanicCode(executionResult.Err, executionResult.ReturnData, true)
+	if panicCode != nil {
+		return describePanicCode(panicCode)
+	}
+
+	// Try to resolve an error string.
+	errorMessage := abiutils.GetSolidityRevertErrorString(executionResult.Err, executionResult.ReturnData)
+	if errorMessage != nil {
+		return fmt.Sprintf("revert ('%v')", *errorMessage)
+	}
+
+	// Try to resolve a custom error.
+	var contractAbi *abi.ABI
+	if contract != nil {
+		contractAbi = &contract.CompiledContract().Abi
+	}
+	matchedCustomError, unpackedCustomErrorArgs := abiutils.GetSolidityCustomRevertError(contractAbi, executionResult.Err, executionResult.ReturnData)
+	if matchedCustomError == nil {
+		matchedCustomError, unpackedCustomErrorArgs = customErrors.GetSolidityCustomRevertError(executionResult.Err, executionResult.ReturnData)
+	}
+	if matchedCustomError != nil {
+		customErrorArgsDisplayText, err := valuegeneration.EncodeABIArgumentsToString(matchedCustomError.Inputs, unpackedCustomErrorArgs)
+		if err == nil {
+			return fmt.Sprintf("revert (error: %v(%v))", matchedCustomError.Name, customErrorArgsDisplayText)
+		}
+	}
+
+	// Otherwise describe the revert by its raw data, or the VM error if this was not a revert.
+	if executionResult.Err == vm.ErrExecutionReverted {
+		if len(executionResult.ReturnData) == 0 {
+			return "revert"
+		}
+		return fmt.Sprintf("revert (return_data=%v)", hex.EncodeToString(executionResult.ReturnData))
+	}
+	return fmt.Sprintf("vm error ('%v')", executionResult.Err.Error())
+}
+
+// describePanicCode obtains a text-based printable description of the provided Solidity panic code.
+// Returns a string describing the panic.
+func describePanicCode(panicCode *big.Int) string {
+	if panicCode.IsUint64() && panicCode.Uint64() == abiutils.PanicCodeAssertFailed {
+		return "assertion failed"
+	}
+	return fmt.Sprintf("panic: %v (code: 0x%x)", abiutils.GetSolidityPanicCodeDescription(panicCode), panicCode)
 }
 
 // checkAssertionFailures checks the results of the last call for assertion failures.
@@ -65,24 +205,119 @@ func (t *AssertionTestCaseProvider) checkAssertionFailures(callSequence calls.Ca
 		return nil, false, err
 	}
 	methodId := contracts.GetContractMethodID(lastCall.Contract, lastCallMethod)
+	lastExecutionResult := lastCall.ChainReference.MessageResults().ExecutionResult
+
+	// If the call did not meet the event emissions expected through cheat codes, the test fails.
+	if len(lastCall.ChainReference.MessageResults().ExpectedEmitFailures) > 0 {
+		return &methodId, true, nil
+	}
+
+	// If the method is expected to revert, the test fails if the call did not revert as expected.
+	t.testCasesLock.Lock()
+	testCase, testCaseExists := t.testCases[methodId]
+	t.testCasesLock.Unlock()
+	if testCaseExists && testCase.expectRevert {
+		return &methodId, !checkExpectedRevert(testCase, lastExecutionResult), nil
+	}
+
+	// If the call ran out of gas, the failure may be due to the gas limit rather than the contract logic, so it is
+	// only treated as an assertion failure if we are configured to.
+	if callRanOutOfGas(lastCall) {
+		return &methodId, t.fuzzer.config.Fuzzing.Testing.TreatOOGAsFailure, nil
+	}
 
 	// Check if we encountered an assertion error.
-	// Try to unpack our error and return data for a panic code and verify it matches the "assert failed" panic code.
-	// Solidity >0.8.0 introduced asserts failing as reverts but with special return data. But we indicate we also
-	// want to be backwards compatible with older Solidity which simply hit an invalid opcode and did not actually
+	// Try to unpack our error and return data for a panic code and verify it is one we are configured to treat as a
+	// failure. Solidity >0.8.0 introduced asserts failing as reverts but with special return data. But we indicate we
+	// also want to be backwards compatible with older Solidity which simply hit an invalid opcode and did not actually
 	// have a panic code.
-	lastExecutionResult := lastCall.ChainReference.MessageResults().ExecutionResult
 	panicCode := abiutils.GetSolidityPanicCode(lastExecutionResult.Err, lastExecutionResult.ReturnData, true)
-	encounteredAssertionFailure := panicCode != nil && panicCode.Uint64() == abiutils.PanicCodeAssertFailed
+	encounteredAssertionFailure := panicCode != nil && t.isFailurePanicCode(panicCode)
+
+	// If the call did not fail an assertion itself, an inner call it made may have failed one which was caught.
+	if !encounteredAssertionFailure {
+		encounteredAssertionFailure = t.swallowedFailurePanic(lastCall) != nil
+	}
 
 	return &methodId, encounteredAssertionFailure, nil
 }
 
+// swallowedFailurePanic obtains the first panic treated as an assertion failure which an inner call made by the
+// provided executed call raised and a calling frame caught, if swallowed panics are configured to fail tests and the
+// call did not fail an assertion itself.
+// Returns the swallowed panic, or nil if there is none.
+func (t *AssertionTestCaseProvider) swallowedFailurePanic(element *calls.CallSequenceElement) *panictracer.SwallowedPanic {
+	if !t.fuzzer.config.Fuzzing.Testing.AssertionTesting.FailOnSwallowedPanics {
+		return nil
+	}
+
+	// If the call failed an assertion itself, its failure is reported instead.
+	executionResult := element.ChainReference.MessageResults().ExecutionResult
+	panicCode := abiutils.GetSolidityPanicCode(executionResult.Err, executionResult.ReturnData, true)
+	if panicCode != nil && t.isFailurePanicCode(panicCode) {
+		return nil
+	}
+
+	swallowedPanics := panictracer.GetSwallowedPanicTracerResults(element.ChainReference.MessageResults())
+	for i := range swallowedPanics {
+		if t.isFailurePanicCode(swallowedPanics[i].PanicCode) {
+			return &swallowedPanics[i]
+		}
+	}
+	return nil
+}
+
+// isFailurePanicCode checks whether the provided Solidity panic code is configured to be treated as an assertion
+// failure.
+// Returns true if the panic code is a failure, false otherwise.
+func (t *AssertionTestCaseProvider) isFailurePanicCode(panicCode *big.Int) bool {
+	// Panic codes not known to us are never failures.
+	if !panicCode.IsUint64() {
+		return false
+	}
+
+	panicCodeConfig := t.fuzzer.config.Fuzzing.Testing.AssertionTesting.PanicCodeConfig
+	switch panicCode.Uint64() {
+	case abiutils.PanicCodeCompilerInserted:
+		return panicCodeConfig.FailOnCompilerInsertedPanic
+	case abiutils.PanicCodeAssertFailed:
+		return panicCodeConfig.FailOnAssertion
+	case abiutils.PanicCodeArithmeticUnderOverflow:
+		return panicCodeConfig.FailOnArithmeticUnderflow
+	case abiutils.PanicCodeDivideByZero:
+		return panicCodeConfig.FailOnDivideByZero
+	case abiutils.PanicCodeEnumTypeConversionOutOfBounds:
+		return panicCodeConfig.FailOnEnumTypeConversionOutOfBounds
+	case abiutils.PanicCodeIncorrectStorageAccess:
+		return panicCodeConfig.FailOnIncorrectStorageAccess
+	case abiutils.PanicCodePopEmptyArray:
+		return panicCodeConfig.FailOnPopEmptyArray
+	case abiutils.PanicCodeOutOfBoundsArrayAccess:
+		return panicCodeConfig.FailOnOutOfBoundsArrayAccess
+	case abiutils.PanicCodeAllocateTooMuchMemory:
+		return panicCodeConfig.FailOnAllocateTooMuchMemory
+	case abiutils.PanicCodeCallUninitializedVariable:
+		return panicCodeConfig.FailOnCallUninitializedVariable
+	}
+	return false
+}
+
 // onFuzzerStarting is the event handler triggered when the Fuzzer is starting a fuzzing campaign. It creates test cases
 // in a "not started" state for every method to test discovered in the contract definitions known to the Fuzzer.
 func (t *AssertionTestCaseProvider) onFuzzerStarting(event FuzzerStartingEvent) error {
-	// Reset our state
+	// Reset our state and register our test cases with the fuzzer
+	for _, testCase := range t.resetTestCases() {
+		t.fuzzer.RegisterTestCase(testCase)
+	}
+	return nil
+}
+
+// resetTestCases replaces the test cases tracked by the provider with new ones in a "not started" state, for every
+// method to test discovered in the contract definitions known to the Fuzzer.
+// Returns the test cases created, in the order they were created.
+func (t *AssertionTestCaseProvider) resetTestCases() []*AssertionTestCase {
 	t.testCases = make(map[contracts.ContractMethodID]*AssertionTestCase)
+	testCases := make([]*AssertionTestCase, 0)
 
 	// Create a test case for every test method.
 	for _, contract := range t.fuzzer.ContractDefinitions() {
@@ -103,19 +338,21 @@ func (t *AssertionTestCaseProvider) onFuzzerStarting(event FuzzerStartingEvent)
 
 			// Create our test case
 			testCase := &AssertionTestCase{
-				status:         TestCaseStatusNotStarted,
-				targetContract: contract,
-				targetMethod:   method,
-				callSequence:   nil,
+				status:              TestCaseStatusNotStarted,
+				targetContract:      contract,
+				targetMethod:        method,
+				callSequence:        nil,
+				expectRevert:        t.isExpectRevertMethod(method),
+				expectedRevertError: t.expectedRevertError(contract, method),
 			}
 
-			// Add to our test cases and register them with the fuzzer
+			// Add to our test cases
 			methodId := contracts.GetContractMethodID(contract, &method)
 			t.testCases[methodId] = testCase
-			t.fuzzer.RegisterTestCase(testCase)
+			testCases = append(testCases, testCase)
 		}
 	}
-	return nil
+	return testCases
 }
 
 // onFuzzerStarting is the event handler triggered when the Fuzzer is stopping the fuzzing campaign and all workers
@@ -190,8 +427,8 @@ func (t *AssertionTestCaseProvider) callSequencePostCallTest(worker *FuzzerWorke
 		return shrinkRequests, nil
 	}
 
-	// If the test case already failed, skip it
-	if testCase.Status() == TestCaseStatusFailed {
+	// If the test case already failed, skip it, unless failed test cases continue to be tested
+	if testCase.Status() == TestCaseStatusFailed && !t.fuzzer.retestFailedTestCases() {
 		return shrinkRequests, nil
 	}
 
@@ -211,17 +448,35 @@ func (t *AssertionTestCaseProvider) callSequencePostCallTest(worker *FuzzerWorke
 				return shrunkSeqTestFailed && *methodId == *shrunkSeqMethodId, nil
 			},
 			FinishedCallback: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) error {
-				// When we're finished shrinking, attach an execution trace to the last call
+				// When we're finished shrinking, attach an execution trace to the last call and describe its result.
+				var (
+					failureReason        string
+					failurePanicCode     *big.Int
+					expectedEmitFailures []string
+				)
 				if len(shrunkenCallSequence) > 0 {
-					err = shrunkenCallSequence[len(shrunkenCallSequence)-1].AttachExecutionTrace(worker.chain, worker.fuzzer.contractDefinitions)
+					lastCall := shrunkenCallSequence[len(shrunkenCallSequence)-1]
+					err = lastCall.AttachExecutionTrace(worker.chain, worker.fuzzer.contractDefinitions, worker.fuzzer.traceStorageWrites())
 					if err != nil {
 						return err
 					}
+					lastExecutionResult := lastCall.ChainReference.MessageResults().ExecutionResult
+					failureReason = describeExecutionResult(lastCall.Contract, worker.fuzzer.contractDefinitions.CustomErrors(), lastExecutionResult)
+					failurePanicCode = abiutils.GetSolidityPanicCode(lastExecutionResult.Err, lastExecutionResult.ReturnData, false)
+					if swallowedPanic := t.swallowedFailurePanic(lastCall); swallowedPanic != nil {
+						failureReason = worker.describeSwallowedPanic(swallowedPanic)
+					}
+					expectedEmitFailures = lastCall.ChainReference.MessageResults().ExpectedEmitFailures
 				}
 
 				// Update our test state and report it finalized.
+				t.testCasesLock.Lock()
+				testCase.failureReason = failureReason
+				testCase.failurePanicCode = failurePanicCode
+				testCase.expectedEmitFailures = expectedEmitFailures
 				testCase.status = TestCaseStatusFailed
 				testCase.callSequence = &shrunkenCallSequence
+				t.testCasesLock.Unlock()
 				worker.Fuzzer().ReportTestCaseFinished(testCase)
 				return nil
 			},
@@ -0,0 +1,59 @@
+package fuzzing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// DifferentialTestCase describes a test being run by a DifferentialTestCaseProvider.
+type DifferentialTestCase struct {
+	status            TestCaseStatus
+	subjectContract   *fuzzerTypes.Contract
+	referenceContract *fuzzerTypes.Contract
+	callSequence      *calls.CallSequence
+	divergedMethod    *abi.Method
+	subjectResult     string
+	referenceResult   string
+}
+
+// Status describes the TestCaseStatus used to define the current state of the test.
+func (t *DifferentialTestCase) Status() TestCaseStatus {
+	return t.status
+}
+
+// CallSequence describes the types.CallSequence of calls sent to the EVM which resulted in this TestCase result.
+// This should be nil if the result is not related to the CallSequence.
+func (t *DifferentialTestCase) CallSequence() *calls.CallSequence {
+	return t.callSequence
+}
+
+// Name describes the name of the test case.
+func (t *DifferentialTestCase) Name() string {
+	return fmt.Sprintf("Differential Test: %s vs %s", t.subjectContract.Name(), t.referenceContract.Name())
+}
+
+// Message obtains a text-based printable message which describes the test result.
+func (t *DifferentialTestCase) Message() string {
+	// If the test failed, return a failure message.
+	if t.Status() == TestCaseStatusFailed {
+		return fmt.Sprintf(
+			"Differential test \"%s\" vs \"%s\" diverged on %s: the subject %s, while the reference %s, after the following call sequence:\n%s",
+			t.subjectContract.Name(),
+			t.referenceContract.Name(),
+			t.divergedMethod.Sig,
+			t.subjectResult,
+			t.referenceResult,
+			t.CallSequence().String(),
+		)
+	}
+	return ""
+}
+
+// ID obtains a unique identifier for a test result.
+func (t *DifferentialTestCase) ID() string {
+	return strings.Replace(fmt.Sprintf("DIFFERENTIAL-%s-%s", t.subjectContract.Name(), t.referenceContract.Name()), "_", "-", -1)
+}
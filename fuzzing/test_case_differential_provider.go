@@ -0,0 +1,412 @@
+package fuzzing
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+)
+
+// DifferentialTestCaseProvider is a provider for differential tests.
+// Differential tests compare a subject contract against a reference contract with an identical ABI, as specified by a
+// config.FuzzingConfig. Every call the fuzzer makes to the subject contract is mirrored to the reference contract
+// immediately after, so the state of both contracts remains in sync. If the two calls differ in their revert status
+// or ABI-decoded return values, the test signals a failed status. If no divergence is found before the fuzzing
+// campaign ends, the test signals a passed status.
+type DifferentialTestCaseProvider struct {
+	// fuzzer describes the Fuzzer which this provider is attached to.
+	fuzzer *Fuzzer
+
+	// testCases is a list of differential test cases, one for each configured contract pair.
+	testCases []*DifferentialTestCase
+
+	// workerStates is a slice where each element stores state for a given worker index.
+	workerStates []differentialTestCaseProviderWorkerState
+}
+
+// differentialTestCaseProviderWorkerState represents the state for an individual worker maintained by
+// DifferentialTestCaseProvider.
+type differentialTestCaseProviderWorkerState struct {
+	// pairAddresses describes the deployed addresses of the contracts compared by each test case, at the same index
+	// as the test case.
+	pairAddresses []differentialContractPairAddresses
+
+	// pairAddressesLock is used for thread-synchronization when updating pairAddresses
+	pairAddressesLock sync.Mutex
+}
+
+// differentialContractPairAddresses describes the deployed addresses of the contracts compared by a
+// DifferentialTestCase. Either address is nil if the respective contract is not deployed.
+type differentialContractPairAddresses struct {
+	subject   *common.Address
+	reference *common.Address
+}
+
+// attachDifferentialTestCaseProvider attaches a new DifferentialTestCaseProvider to the Fuzzer and returns it.
+func attachDifferentialTestCaseProvider(fuzzer *Fuzzer) *DifferentialTestCaseProvider {
+	// Create a test case provider
+	t := &DifferentialTestCaseProvider{
+		fuzzer: fuzzer,
+	}
+
+	// Subscribe the provider to relevant events the fuzzer emits.
+	fuzzer.Events.FuzzerStarting.Subscribe(t.onFuzzerStarting)
+	fuzzer.Events.FuzzerStopping.Subscribe(t.onFuzzerStopping)
+	fuzzer.Events.WorkerCreated.Subscribe(t.onWorkerCreated)
+
+	// Add the provider's call mirror and call sequence test functions to the fuzzer.
+	fuzzer.Hooks.CallMirrorFuncs = append(fuzzer.Hooks.CallMirrorFuncs, t.callMirror)
+	fuzzer.Hooks.CallSequenceTestFuncs = append(fuzzer.Hooks.CallSequenceTestFuncs, t.callSequencePostCallTest)
+	return t
+}
+
+// verifyIdenticalABIs verifies the provided subject and reference contracts define the same methods, with the same
+// outputs, so every call to the subject can be mirrored to the reference and their results compared.
+// Returns an error if the ABIs differ.
+func verifyIdenticalABIs(subject *contracts.Contract, reference *contracts.Contract) error {
+	subjectMethods := subject.CompiledContract().Abi.Methods
+	referenceMethods := reference.CompiledContract().Abi.Methods
+	if len(subjectMethods) != len(referenceMethods) {
+		return fmt.Errorf("differential test contracts '%v' and '%v' do not have identical ABIs, as they define %d and %d methods respectively", subject.Name(), reference.Name(), len(subjectMethods), len(referenceMethods))
+	}
+	for name, subjectMethod := range subjectMethods {
+		referenceMethod, ok := referenceMethods[name]
+		if !ok || referenceMethod.Sig != subjectMethod.Sig || !reflect.DeepEqual(referenceMethod.Outputs.NonIndexed(), subjectMethod.Outputs.NonIndexed()) {
+			return fmt.Errorf("differential test contracts '%v' and '%v' do not have identical ABIs, as they differ in method '%v'", subject.Name(), reference.Name(), subjectMethod.Sig)
+		}
+	}
+	return nil
+}
+
+// onFuzzerStarting is the event handler triggered when the Fuzzer is starting a fuzzing campaign. It creates test cases
+// in a "not started" state for every contract pair in the differential testing config, verifying both contracts are
+// known to the Fuzzer and have identical ABIs.
+func (t *DifferentialTestCaseProvider) onFuzzerStarting(event FuzzerStartingEvent) error {
+	// Reset our state
+	t.testCases = make([]*DifferentialTestCase, 0)
+	t.workerStates = make([]differentialTestCaseProviderWorkerState, t.fuzzer.Config().Fuzzing.Workers)
+
+	// Resolve the contract definitions for a contract name.
+	contractDefinition := func(name string) (*contracts.Contract, error) {
+		for _, contract := range t.fuzzer.ContractDefinitions() {
+			if contract.Name() == name {
+				return contract, nil
+			}
+		}
+		return nil, fmt.Errorf("differential test contract '%v' could not be found in the compiled contracts", name)
+	}
+
+	// Create a test case for every contract pair.
+	for _, pair := range t.fuzzer.config.Fuzzing.Testing.DifferentialTesting.ContractPairs {
+		subjectContract, err := contractDefinition(pair.Subject)
+		if err != nil {
+			return err
+		}
+		referenceContract, err := contractDefinition(pair.Reference)
+		if err != nil {
+			return err
+		}
+		err = verifyIdenticalABIs(subjectContract, referenceContract)
+		if err != nil {
+			return err
+		}
+
+		// Create our differential test case and register it with the fuzzer.
+		differentialTestCase := &DifferentialTestCase{
+			status:            TestCaseStatusNotStarted,
+			subjectContract:   subjectContract,
+			referenceContract: referenceContract,
+		}
+		t.testCases = append(t.testCases, differentialTestCase)
+		t.fuzzer.RegisterTestCase(differentialTestCase)
+	}
+	return nil
+}
+
+// onFuzzerStopping is the event handler triggered when the Fuzzer is stopping the fuzzing campaign and all workers
+// have been destroyed. It clears state tracked for each FuzzerWorker and sets test cases in "running" states to
+// "passed".
+func (t *DifferentialTestCaseProvider) onFuzzerStopping(event FuzzerStoppingEvent) error {
+	// Clear our worker states
+	t.workerStates = nil
+
+	// Loop through each test case and set any tests with a running status to a passed status.
+	for _, testCase := range t.testCases {
+		if testCase.status == TestCaseStatusRunning {
+			testCase.status = TestCaseStatusPassed
+		}
+	}
+	return nil
+}
+
+// onWorkerCreated is the event handler triggered when a FuzzerWorker is created by the Fuzzer. It ensures state tracked
+// for that worker index is refreshed and subscribes to relevant worker events.
+func (t *DifferentialTestCaseProvider) onWorkerCreated(event FuzzerWorkerCreatedEvent) error {
+	// Create a new state for this worker.
+	t.workerStates[event.Worker.WorkerIndex()] = differentialTestCaseProviderWorkerState{
+		pairAddresses:     make([]differentialContractPairAddresses, len(t.testCases)),
+		pairAddressesLock: sync.Mutex{},
+	}
+
+	// Subscribe to relevant worker events.
+	event.Worker.Events.ContractAdded.Subscribe(t.onWorkerDeployedContractAdded)
+	event.Worker.Events.ContractDeleted.Subscribe(t.onWorkerDeployedContractDeleted)
+	return nil
+}
+
+// onWorkerDeployedContractAdded is the event handler triggered when a FuzzerWorker detects a new contract deployment
+// on its underlying chain. It tracks the addresses of any subject or reference contracts deployed. Once both
+// contracts of a pair are deployed, its test case is put into a "running" state, as calls to the subject can now be
+// mirrored to the reference.
+func (t *DifferentialTestCaseProvider) onWorkerDeployedContractAdded(event FuzzerWorkerContractAddedEvent) error {
+	// If we don't have a contract definition, it cannot be a contract we compare.
+	if event.ContractDefinition == nil {
+		return nil
+	}
+
+	workerState := &t.workerStates[event.Worker.WorkerIndex()]
+	workerState.pairAddressesLock.Lock()
+	defer workerState.pairAddressesLock.Unlock()
+	for i, testCase := range t.testCases {
+		address := event.ContractAddress
+		if event.ContractDefinition == testCase.subjectContract {
+			workerState.pairAddresses[i].subject = &address
+		} else if event.ContractDefinition == testCase.referenceContract {
+			workerState.pairAddresses[i].reference = &address
+		} else {
+			continue
+		}
+		if workerState.pairAddresses[i].subject != nil && workerState.pairAddresses[i].reference != nil && testCase.Status() == TestCaseStatusNotStarted {
+			testCase.status = TestCaseStatusRunning
+		}
+	}
+	return nil
+}
+
+// onWorkerDeployedContractDeleted is the event handler triggered when a FuzzerWorker detects that a previously
+// deployed contract no longer exists on its underlying chain. It stops tracking the address of any subject or
+// reference contract which was deleted.
+func (t *DifferentialTestCaseProvider) onWorkerDeployedContractDeleted(event FuzzerWorkerContractDeletedEvent) error {
+	workerState := &t.workerStates[event.Worker.WorkerIndex()]
+	workerState.pairAddressesLock.Lock()
+	defer workerState.pairAddressesLock.Unlock()
+	for i := range workerState.pairAddresses {
+		addresses := &workerState.pairAddresses[i]
+		if addresses.subject != nil && *addresses.subject == event.ContractAddress {
+			addresses.subject = nil
+		}
+		if addresses.reference != nil && *addresses.reference == event.ContractAddress {
+			addresses.reference = nil
+		}
+	}
+	return nil
+}
+
+// workerPairAddresses obtains the deployed addresses of the contracts compared by the test case at the provided index
+// on the provided worker's chain.
+func (t *DifferentialTestCaseProvider) workerPairAddresses(worker *FuzzerWorker, index int) differentialContractPairAddresses {
+	workerState := &t.workerStates[worker.WorkerIndex()]
+	workerState.pairAddressesLock.Lock()
+	defer workerState.pairAddressesLock.Unlock()
+	return workerState.pairAddresses[index]
+}
+
+// callMirror is a CallMirrorFunc which mirrors every call to a subject contract with the same call to its reference
+// contract. Calls made to a reference contract directly (including mirrors recorded in corpus call sequences) are
+// discarded, as the reference contract should only ever receive the calls its subject does.
+func (t *DifferentialTestCaseProvider) callMirror(worker *FuzzerWorker, element *calls.CallSequenceElement) ([]*calls.CallSequenceElement, bool, error) {
+	if element.Call.MsgTo == nil {
+		return nil, true, nil
+	}
+	for i, testCase := range t.testCases {
+		addresses := t.workerPairAddresses(worker, i)
+		if addresses.subject == nil || addresses.reference == nil {
+			continue
+		}
+		if *element.Call.MsgTo == *addresses.reference {
+			return nil, false, nil
+		}
+		if *element.Call.MsgTo == *addresses.subject {
+			mirrorCall, err := element.Call.Clone()
+			if err != nil {
+				return nil, false, err
+			}
+			mirrorCall.MsgTo = addresses.reference
+			return []*calls.CallSequenceElement{calls.NewCallSequenceElement(testCase.referenceContract, mirrorCall, 0, 0)}, true, nil
+		}
+	}
+	return nil, true, nil
+}
+
+// isMirroredCall indicates whether the provided reference element mirrors the provided subject element, sending the
+// same call to the reference contract as was sent to the subject contract.
+func isMirroredCall(subjectElement *calls.CallSequenceElement, referenceElement *calls.CallSequenceElement, addresses differentialContractPairAddresses) bool {
+	if addresses.subject == nil || addresses.reference == nil || subjectElement.Call.MsgTo == nil || referenceElement.Call.MsgTo == nil {
+		return false
+	}
+	return *subjectElement.Call.MsgTo == *addresses.subject &&
+		*referenceElement.Call.MsgTo == *addresses.reference &&
+		subjectElement.Call.MsgFrom == referenceElement.Call.MsgFrom &&
+		subjectElement.Call.MsgValue.Cmp(referenceElement.Call.MsgValue) == 0 &&
+		bytes.Equal(subjectElement.Call.Data(), referenceElement.Call.Data())
+}
+
+// mirroredCallsIntact indicates whether every call to the subject contract in the provided call sequence is followed
+// by its mirror to the reference contract, and every call to the reference contract is such a mirror. Shrinking may
+// remove or alter one call of a pair, which would desynchronize the contracts, so such call sequences are rejected.
+func mirroredCallsIntact(callSequence calls.CallSequence, addresses differentialContractPairAddresses) bool {
+	for i := 0; i < len(callSequence); i++ {
+		to := callSequence[i].Call.MsgTo
+		if to == nil {
+			continue
+		}
+		if *to == *addresses.subject {
+			if i+1 >= len(callSequence) || !isMirroredCall(callSequence[i], callSequence[i+1], addresses) {
+				return false
+			}
+			i++
+		} else if *to == *addresses.reference {
+			return false
+		}
+	}
+	return true
+}
+
+// describeDifferentialCallResult describes the result of a call compared by a differential test in text, e.g.
+// "returned [1 true]".
+func describeDifferentialCallResult(result *core.ExecutionResult, values []any, decoded bool) string {
+	if result.Failed() {
+		return "reverted"
+	}
+	if decoded {
+		return fmt.Sprintf("returned %v", values)
+	}
+	return fmt.Sprintf("returned 0x%x", result.Return())
+}
+
+// compareMirroredCalls compares the results of the provided executed subject and reference elements. Their return
+// values are compared after ABI-decoding, if the method called could be resolved and both values could be decoded.
+// Returns a boolean indicating whether the results diverged, and descriptions of the subject and reference results.
+func compareMirroredCalls(subjectElement *calls.CallSequenceElement, referenceElement *calls.CallSequenceElement) (bool, string, string) {
+	subjectResult := subjectElement.ChainReference.MessageResults().ExecutionResult
+	referenceResult := referenceElement.ChainReference.MessageResults().ExecutionResult
+
+	// Decode the return values of both calls, if we can.
+	var (
+		subjectValues, referenceValues []any
+		decoded                        bool
+	)
+	if method, err := subjectElement.Method(); err == nil && method != nil && !subjectResult.Failed() && !referenceResult.Failed() {
+		var subjectErr, referenceErr error
+		subjectValues, subjectErr = method.Outputs.Unpack(subjectResult.Return())
+		referenceValues, referenceErr = method.Outputs.Unpack(referenceResult.Return())
+		decoded = subjectErr == nil && referenceErr == nil
+	}
+
+	// Compare revert statuses, then return values.
+	var diverged bool
+	if subjectResult.Failed() || referenceResult.Failed() {
+		diverged = subjectResult.Failed() != referenceResult.Failed()
+	} else if decoded {
+		diverged = !reflect.DeepEqual(subjectValues, referenceValues)
+	} else {
+		diverged = !bytes.Equal(subjectResult.Return(), referenceResult.Return())
+	}
+	return diverged, describeDifferentialCallResult(subjectResult, subjectValues, decoded), describeDifferentialCallResult(referenceResult, referenceValues, decoded)
+}
+
+// findDivergence finds the first pair of mirrored calls in the provided executed call sequence whose results diverged.
+// Returns the index of the subject call of the pair, or -1 if no pair diverged.
+func findDivergence(callSequence calls.CallSequence, addresses differentialContractPairAddresses) int {
+	for i := 0; i+1 < len(callSequence); i++ {
+		if isMirroredCall(callSequence[i], callSequence[i+1], addresses) {
+			if diverged, _, _ := compareMirroredCalls(callSequence[i], callSequence[i+1]); diverged {
+				return i
+			}
+			i++
+		}
+	}
+	return -1
+}
+
+// callSequencePostCallTest provides is a CallSequenceTestFunc that performs post-call testing logic for the attached
+// Fuzzer and any underlying FuzzerWorker. It is called after every call made in a call sequence. If the last call
+// mirrored a call to a subject contract, it checks whether the results of both calls diverged.
+func (t *DifferentialTestCaseProvider) callSequencePostCallTest(worker *FuzzerWorker, callSequence calls.CallSequence) ([]ShrinkCallSequenceRequest, error) {
+	// Create a list of shrink call sequence verifiers, which we populate for each failed differential test we want a
+	// call sequence shrunk for.
+	shrinkRequests := make([]ShrinkCallSequenceRequest, 0)
+
+	// We can only compare results once a call was mirrored.
+	if len(callSequence) < 2 {
+		return shrinkRequests, nil
+	}
+	subjectElement, referenceElement := callSequence[len(callSequence)-2], callSequence[len(callSequence)-1]
+
+	for i, testCase := range t.testCases {
+		// If the test case already failed (and failed test cases are not tested further), or the last call does not
+		// mirror a call for it, skip it
+		testCase := testCase
+		index := i
+		if (testCase.Status() == TestCaseStatusFailed && !t.fuzzer.retestFailedTestCases()) || !isMirroredCall(subjectElement, referenceElement, t.workerPairAddresses(worker, index)) {
+			continue
+		}
+		if diverged, _, _ := compareMirroredCalls(subjectElement, referenceElement); !diverged {
+			continue
+		}
+
+		// The results diverged, so we create a request to shrink this call sequence, ensuring shrunken sequences keep
+		// each call mirrored and still diverge.
+		shrinkRequest := ShrinkCallSequenceRequest{
+			VerifierFunction: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) (bool, error) {
+				addresses := t.workerPairAddresses(worker, index)
+				if addresses.subject == nil || addresses.reference == nil {
+					// If the contracts aren't available, this shrunk sequence likely messed up deployment, so we
+					// report it as an invalid solution.
+					return false, nil
+				}
+				return mirroredCallsIntact(shrunkenCallSequence, addresses) && findDivergence(shrunkenCallSequence, addresses) >= 0, nil
+			},
+			FinishedCallback: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) error {
+				// Find the calls which diverged in our final shrunken sequence and attach execution traces to them.
+				divergedIndex := findDivergence(shrunkenCallSequence, t.workerPairAddresses(worker, index))
+				if divergedIndex < 0 {
+					return fmt.Errorf("differential test provider did not find diverging calls in final shrunken sequence")
+				}
+				divergedElements := shrunkenCallSequence[divergedIndex : divergedIndex+2]
+				err := divergedElements.AttachExecutionTraces(worker.chain, worker.fuzzer.contractDefinitions, worker.fuzzer.traceStorageWrites())
+				if err != nil {
+					return err
+				}
+
+				// Update our test state and report it finalized.
+				_, subjectResult, referenceResult := compareMirroredCalls(divergedElements[0], divergedElements[1])
+				divergedMethod, err := divergedElements[0].Method()
+				if err != nil {
+					return err
+				}
+				if divergedMethod == nil {
+					divergedMethod = &abi.Method{Sig: "<unknown method>"}
+				}
+				testCase.status = TestCaseStatusFailed
+				testCase.callSequence = &shrunkenCallSequence
+				testCase.divergedMethod = divergedMethod
+				testCase.subjectResult = subjectResult
+				testCase.referenceResult = referenceResult
+				worker.Fuzzer().ReportTestCaseFinished(testCase)
+				return nil
+			},
+			RecordResultInCorpus: true,
+		}
+
+		// Add our shrink request to our list.
+		shrinkRequests = append(shrinkRequests, shrinkRequest)
+	}
+	return shrinkRequests, nil
+}
@@ -0,0 +1,57 @@
+package fuzzing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// NeverRevertTestCase describes a test being run by a NeverRevertTestCaseProvider.
+type NeverRevertTestCase struct {
+	status         TestCaseStatus
+	targetContract *fuzzerTypes.Contract
+	targetMethod   abi.Method
+	callSequence   *calls.CallSequence
+
+	// revertReason describes the result of the final call in the call sequence which failed the test.
+	revertReason string
+}
+
+// Status describes the TestCaseStatus used to define the current state of the test.
+func (t *NeverRevertTestCase) Status() TestCaseStatus {
+	return t.status
+}
+
+// CallSequence describes the types.CallSequence of calls sent to the EVM which resulted in this TestCase result.
+// This should be nil if the result is not related to the CallSequence.
+func (t *NeverRevertTestCase) CallSequence() *calls.CallSequence {
+	return t.callSequence
+}
+
+// Name describes the name of the test case.
+func (t *NeverRevertTestCase) Name() string {
+	return fmt.Sprintf("Never Revert Test: %s.%s", t.targetContract.Name(), t.targetMethod.Sig)
+}
+
+// Message obtains a text-based printable message which describes the test result.
+func (t *NeverRevertTestCase) Message() string {
+	// If the test failed, return a failure message.
+	if t.Status() == TestCaseStatusFailed {
+		return fmt.Sprintf(
+			"Test for method \"%s.%s\" failed after the following call sequence resulted in a revert (result: %s):\n%s",
+			t.targetContract.Name(),
+			t.targetMethod.Sig,
+			t.revertReason,
+			t.CallSequence().String(),
+		)
+	}
+	return ""
+}
+
+// ID obtains a unique identifier for a test result.
+func (t *NeverRevertTestCase) ID() string {
+	return strings.Replace(fmt.Sprintf("NEVER-REVERT-%s-%s", t.targetContract.Name(), t.targetMethod.Sig), "_", "-", -1)
+}
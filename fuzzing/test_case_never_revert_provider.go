@@ -0,0 +1,234 @@
+package fuzzing
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"golang.org/x/exp/slices"
+)
+
+// NeverRevertTestCaseProvider is a NeverRevertTestCase provider which spawns test cases for every contract method
+// which must never revert, as specified by a config.FuzzingConfig through method name prefixes or signatures. Unlike
+// assertion tests, which only fail on panics, any revert of such a method fails its test, including those caused by
+// running out of gas.
+type NeverRevertTestCaseProvider struct {
+	// fuzzer describes the Fuzzer which this provider is attached to.
+	fuzzer *Fuzzer
+
+	// testCases is a map of contract-method IDs to never revert test cases.
+	testCases map[contracts.ContractMethodID]*NeverRevertTestCase
+
+	// testCasesLock is used for thread-synchronization when updating testCases
+	testCasesLock sync.Mutex
+}
+
+// attachNeverRevertTestCaseProvider attaches a new NeverRevertTestCaseProvider to the Fuzzer and returns it.
+func attachNeverRevertTestCaseProvider(fuzzer *Fuzzer) *NeverRevertTestCaseProvider {
+	// Create a test case provider
+	t := &NeverRevertTestCaseProvider{
+		fuzzer: fuzzer,
+	}
+
+	// Subscribe the provider to relevant events the fuzzer emits.
+	fuzzer.Events.FuzzerStarting.Subscribe(t.onFuzzerStarting)
+	fuzzer.Events.FuzzerStopping.Subscribe(t.onFuzzerStopping)
+	fuzzer.Events.WorkerCreated.Subscribe(t.onWorkerCreated)
+
+	// Add the provider's call sequence test function to the fuzzer.
+	fuzzer.Hooks.CallSequenceTestFuncs = append(fuzzer.Hooks.CallSequenceTestFuncs, t.callSequencePostCallTest)
+	return t
+}
+
+// isNeverRevertMethod checks whether the method of the provided contract must never revert, given the method name
+// prefixes and signatures the attached fuzzer is configured with.
+// Returns true if the method must never revert, false otherwise.
+func (t *NeverRevertTestCaseProvider) isNeverRevertMethod(contract *contracts.Contract, method abi.Method) bool {
+	testingConfig := t.fuzzer.config.Fuzzing.Testing.NeverRevertTesting
+	for _, prefix := range testingConfig.TestPrefixes {
+		if prefix != "" && strings.HasPrefix(method.Name, prefix) {
+			return true
+		}
+	}
+	for _, signature := range testingConfig.Methods {
+		if signature == method.Sig || signature == contract.Name()+"."+method.Sig {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNeverRevertFailure checks whether the last call of the provided call sequence targeted a method which must
+// never revert, and reverted.
+// Returns the test case for the method targeted, if any, and a boolean indicating whether the call reverted.
+func (t *NeverRevertTestCaseProvider) checkNeverRevertFailure(callSequence calls.CallSequence) (*NeverRevertTestCase, bool) {
+	// If we have an empty call sequence, we cannot have a revert
+	if len(callSequence) == 0 {
+		return nil, false
+	}
+
+	// Obtain the contract and method from the last call made in our sequence
+	lastCall := callSequence[len(callSequence)-1]
+	lastCallMethod, err := lastCall.Method()
+	if err != nil || lastCallMethod == nil {
+		return nil, false
+	}
+
+	// Obtain the test case for this method, if we're testing it.
+	t.testCasesLock.Lock()
+	testCase, testCaseExists := t.testCases[contracts.GetContractMethodID(lastCall.Contract, lastCallMethod)]
+	t.testCasesLock.Unlock()
+	if !testCaseExists {
+		return nil, false
+	}
+
+	// Calls sending value to non-payable methods are expected to revert, so they do not fail the test.
+	if !lastCallMethod.IsPayable() && lastCall.Call.MsgValue != nil && lastCall.Call.MsgValue.Sign() > 0 {
+		return nil, false
+	}
+
+	// Calls we gave less than our transaction gas limit may run out of gas because of it rather than the method
+	// itself, so they only fail the test if we are configured to treat running out of gas as a failure.
+	testingConfig := t.fuzzer.config.Fuzzing.Testing
+	if !testingConfig.TreatOOGAsFailure && lastCall.Call.MsgGas < t.fuzzer.config.Fuzzing.TransactionGasLimit && callRanOutOfGas(lastCall) {
+		return nil, false
+	}
+
+	// Any other error, whether a revert or another VM error such as running out of gas, fails the test.
+	return testCase, lastCall.ChainReference.MessageResults().ExecutionResult.Failed()
+}
+
+// onFuzzerStarting is the event handler triggered when the Fuzzer is starting a fuzzing campaign. It creates test cases
+// in a "not started" state for every method which must never revert, discovered in the contract definitions known to
+// the Fuzzer.
+func (t *NeverRevertTestCaseProvider) onFuzzerStarting(event FuzzerStartingEvent) error {
+	// Reset our state
+	t.testCases = make(map[contracts.ContractMethodID]*NeverRevertTestCase)
+
+	// Create a test case for every method which must never revert.
+	for _, contract := range t.fuzzer.ContractDefinitions() {
+		// If we're not testing all contracts, verify the current contract is one we specified in our deployment order.
+		if !t.fuzzer.config.Fuzzing.Testing.TestAllContracts && !slices.Contains(t.fuzzer.config.Fuzzing.DeploymentOrder, contract.Name()) {
+			continue
+		}
+
+		for _, method := range contract.CompiledContract().Abi.Methods {
+			// Verify this method must never revert
+			if !t.isNeverRevertMethod(contract, method) {
+				continue
+			}
+
+			// Create local variables to avoid pointer types in the loop being overridden.
+			contract := contract
+			method := method
+
+			// Create our test case
+			testCase := &NeverRevertTestCase{
+				status:         TestCaseStatusNotStarted,
+				targetContract: contract,
+				targetMethod:   method,
+				callSequence:   nil,
+			}
+
+			// Add to our test cases and register them with the fuzzer
+			methodId := contracts.GetContractMethodID(contract, &method)
+			t.testCases[methodId] = testCase
+			t.fuzzer.RegisterTestCase(testCase)
+		}
+	}
+	return nil
+}
+
+// onFuzzerStopping is the event handler triggered when the Fuzzer is stopping the fuzzing campaign and all workers
+// have been destroyed. It sets test cases in "running" states to "passed".
+func (t *NeverRevertTestCaseProvider) onFuzzerStopping(event FuzzerStoppingEvent) error {
+	// Loop through each test case and set any tests with a running status to a passed status.
+	for _, testCase := range t.testCases {
+		if testCase.status == TestCaseStatusRunning {
+			testCase.status = TestCaseStatusPassed
+		}
+	}
+	return nil
+}
+
+// onWorkerCreated is the event handler triggered when a FuzzerWorker is created by the Fuzzer. It subscribes to
+// relevant worker events.
+func (t *NeverRevertTestCaseProvider) onWorkerCreated(event FuzzerWorkerCreatedEvent) error {
+	// Subscribe to relevant worker events.
+	event.Worker.Events.ContractAdded.Subscribe(t.onWorkerDeployedContractAdded)
+	return nil
+}
+
+// onWorkerDeployedContractAdded is the event handler triggered when a FuzzerWorker detects a new contract deployment
+// on its underlying chain. Any test cases for methods of the deployed contract which are in a "not started" state are
+// put into a "running" state, as they are now potentially reachable for testing.
+func (t *NeverRevertTestCaseProvider) onWorkerDeployedContractAdded(event FuzzerWorkerContractAddedEvent) error {
+	// If we don't have a contract definition, we can't run tests against the contract.
+	if event.ContractDefinition == nil {
+		return nil
+	}
+
+	// Loop through all methods and find ones for which we have tests
+	for _, method := range event.ContractDefinition.CompiledContract().Abi.Methods {
+		// Obtain an identifier for this pair
+		methodId := contracts.GetContractMethodID(event.ContractDefinition, &method)
+
+		// If we have any tests in a not-started state, we can signal a running state now.
+		t.testCasesLock.Lock()
+		testCase, testCaseExists := t.testCases[methodId]
+		t.testCasesLock.Unlock()
+		if testCaseExists && testCase.Status() == TestCaseStatusNotStarted {
+			testCase.status = TestCaseStatusRunning
+		}
+	}
+	return nil
+}
+
+// callSequencePostCallTest provides is a CallSequenceTestFunc that performs post-call testing logic for the attached
+// Fuzzer and any underlying FuzzerWorker. It is called after every call made in a call sequence. It checks whether
+// the last call the Fuzzer made targeted a method which must never revert, and reverted.
+func (t *NeverRevertTestCaseProvider) callSequencePostCallTest(worker *FuzzerWorker, callSequence calls.CallSequence) ([]ShrinkCallSequenceRequest, error) {
+	// Create a list of shrink call sequence verifiers, which we populate if the last call failed a test.
+	shrinkRequests := make([]ShrinkCallSequenceRequest, 0)
+
+	// Check whether the last call targeted a method which must never revert, and reverted.
+	testCase, testFailed := t.checkNeverRevertFailure(callSequence)
+	if testCase == nil || !testFailed {
+		return shrinkRequests, nil
+	}
+
+	// If the test case already failed, skip it, unless failed test cases continue to be tested
+	if testCase.Status() == TestCaseStatusFailed && !t.fuzzer.retestFailedTestCases() {
+		return shrinkRequests, nil
+	}
+
+	// We failed a test, so we provide a shrink verifier which ensures each shrunken sequence ends in a reverting call
+	// to the same method.
+	shrinkRequest := ShrinkCallSequenceRequest{
+		VerifierFunction: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) (bool, error) {
+			shrunkenTestCase, shrunkenTestFailed := t.checkNeverRevertFailure(shrunkenCallSequence)
+			return shrunkenTestFailed && shrunkenTestCase == testCase, nil
+		},
+		FinishedCallback: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) error {
+			// When we're finished shrinking, attach an execution trace to the last call and record its revert reason.
+			if len(shrunkenCallSequence) > 0 {
+				lastCall := shrunkenCallSequence[len(shrunkenCallSequence)-1]
+				err := lastCall.AttachExecutionTrace(worker.chain, worker.fuzzer.contractDefinitions, worker.fuzzer.traceStorageWrites())
+				if err != nil {
+					return err
+				}
+				testCase.revertReason = describeExecutionResult(lastCall.Contract, worker.fuzzer.contractDefinitions.CustomErrors(), lastCall.ChainReference.MessageResults().ExecutionResult)
+			}
+
+			// Update our test state and report it finalized.
+			testCase.status = TestCaseStatusFailed
+			testCase.callSequence = &shrunkenCallSequence
+			worker.Fuzzer().ReportTestCaseFinished(testCase)
+			return nil
+		},
+		RecordResultInCorpus: true,
+	}
+	return append(shrinkRequests, shrinkRequest), nil
+}
@@ -0,0 +1,133 @@
+package fuzzing
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/executiontracer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OptimizationTestCase describes a test being run by an OptimizationTestCaseProvider.
+type OptimizationTestCase struct {
+	status                TestCaseStatus
+	targetContract        *fuzzerTypes.Contract
+	targetMethod          abi.Method
+	targetAddress         common.Address
+	minimize              bool
+	gasTarget             bool
+	value                 *big.Int
+	callSequence          *calls.CallSequence
+	optimizationTestTrace *executiontracer.ExecutionTrace
+	valueLock             sync.Mutex
+}
+
+// Status describes the TestCaseStatus used to define the current state of the test.
+func (t *OptimizationTestCase) Status() TestCaseStatus {
+	return t.status
+}
+
+// CallSequence describes the types.CallSequence of calls sent to the EVM which resulted in this TestCase result.
+// This should be nil if the result is not related to the CallSequence.
+func (t *OptimizationTestCase) CallSequence() *calls.CallSequence {
+	t.valueLock.Lock()
+	defer t.valueLock.Unlock()
+	return t.callSequence
+}
+
+// Name describes the name of the test case.
+func (t *OptimizationTestCase) Name() string {
+	if t.gasTarget {
+		return fmt.Sprintf("Gas Optimization Test: %s.%s", t.targetContract.Name(), t.targetMethod.Sig)
+	}
+	return fmt.Sprintf("Optimization Test: %s.%s", t.targetContract.Name(), t.targetMethod.Sig)
+}
+
+// Message obtains a text-based printable message which describes the test result.
+func (t *OptimizationTestCase) Message() string {
+	t.valueLock.Lock()
+	defer t.valueLock.Unlock()
+
+	// If no value was obtained, there is nothing to report.
+	if t.value == nil || t.callSequence == nil {
+		return ""
+	}
+	if t.gasTarget {
+		return fmt.Sprintf(
+			"Gas optimization test \"%s.%s\" resulted in the %s gas used %v with the following call sequence:\n%s",
+			t.targetContract.Name(),
+			t.targetMethod.Sig,
+			t.objective(),
+			t.value,
+			t.callSequence.String(),
+		)
+	}
+	msg := fmt.Sprintf(
+		"Optimization test \"%s.%s\" resulted in the %s value %v with the following call sequence:\n%s",
+		t.targetContract.Name(),
+		t.targetMethod.Sig,
+		t.objective(),
+		t.value,
+		t.callSequence.String(),
+	)
+	// If an execution trace is attached then add it to the message
+	if t.optimizationTestTrace != nil {
+		msg += fmt.Sprintf("\nOptimization test execution trace:\n%s", t.optimizationTestTrace.String())
+	}
+	return msg
+}
+
+// ID obtains a unique identifier for a test result.
+func (t *OptimizationTestCase) ID() string {
+	if t.gasTarget {
+		return strings.Replace(fmt.Sprintf("OPTIMIZATION-GAS-%s-%s", t.targetContract.Name(), t.targetMethod.Sig), "_", "-", -1)
+	}
+	return strings.Replace(fmt.Sprintf("OPTIMIZATION-%s-%s", t.targetContract.Name(), t.targetMethod.Sig), "_", "-", -1)
+}
+
+// Minimize indicates whether the test seeks the minimum value its method returns, rather than the maximum.
+func (t *OptimizationTestCase) Minimize() bool {
+	return t.minimize
+}
+
+// GasTarget indicates whether the test seeks the maximum gas used by calls to its method, rather than a value
+// returned by it.
+func (t *OptimizationTestCase) GasTarget() bool {
+	return t.gasTarget
+}
+
+// Value obtains the best value the test's method was found to return (or for gas targets, the most gas a call to it
+// was found to use), or nil if it has not obtained a value.
+func (t *OptimizationTestCase) Value() *big.Int {
+	t.valueLock.Lock()
+	defer t.valueLock.Unlock()
+	if t.value == nil {
+		return nil
+	}
+	return new(big.Int).Set(t.value)
+}
+
+// isImprovement indicates whether the provided value improves upon the provided best value for this test. Any value
+// improves upon a nil best value.
+func (t *OptimizationTestCase) isImprovement(value *big.Int, best *big.Int) bool {
+	if best == nil {
+		return true
+	}
+	if t.minimize {
+		return value.Cmp(best) < 0
+	}
+	return value.Cmp(best) > 0
+}
+
+// objective describes the value the test seeks in text, e.g. "maximum".
+func (t *OptimizationTestCase) objective() string {
+	if t.minimize {
+		return "minimum"
+	}
+	return "maximum"
+}
@@ -0,0 +1,582 @@
+package fuzzing
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/corpus"
+	"github.com/crytic/medusa/fuzzing/executiontracer"
+	"github.com/crytic/medusa/fuzzing/gastracer"
+	"github.com/crytic/medusa/logging"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"golang.org/x/exp/slices"
+)
+
+// OptimizationTestCaseProvider is a provider for on-chain optimization tests.
+// Optimization tests are represented as publicly-accessible view functions which have a name prefix specified by a
+// config.FuzzingConfig. They take no input arguments and return an integer, which the fuzzer seeks to maximize, or to
+// minimize if the function matches a minimization prefix. Each test tracks the best value found and the call sequence
+// which reached it. Methods configured as gas targets are also tested, with the gas used by calls to them being
+// maximized. Optimization tests never fail; once the fuzzing campaign ends, they signal a passed status.
+type OptimizationTestCaseProvider struct {
+	// fuzzer describes the Fuzzer which this provider is attached to.
+	fuzzer *Fuzzer
+
+	// testCases is a map of contract-method IDs to optimization test cases.
+	testCases map[contracts.ContractMethodID]*OptimizationTestCase
+
+	// gasTestCases is a map of contract-method IDs to optimization test cases for gas targets.
+	gasTestCases map[contracts.ContractMethodID]*OptimizationTestCase
+
+	// testCasesLock is used for thread-synchronization when updating testCases
+	testCasesLock sync.Mutex
+
+	// workerStates is a slice where each element stores state for a given worker index.
+	workerStates []optimizationTestCaseProviderWorkerState
+}
+
+// optimizationTestCaseProviderWorkerState represents the state for an individual worker maintained by
+// OptimizationTestCaseProvider.
+type optimizationTestCaseProviderWorkerState struct {
+	// optimizationTestMethods a mapping from contract-method ID to deployed contract-method descriptors.
+	// Each deployed contract-method represents an optimization test method to call for evaluation.
+	optimizationTestMethods map[contracts.ContractMethodID]contracts.DeployedContractMethod
+
+	// optimizationTestMethodsLock is used for thread-synchronization when updating optimizationTestMethods
+	optimizationTestMethodsLock sync.Mutex
+}
+
+// attachOptimizationTestCaseProvider attaches a new OptimizationTestCaseProvider to the Fuzzer and returns it.
+func attachOptimizationTestCaseProvider(fuzzer *Fuzzer) *OptimizationTestCaseProvider {
+	// Create a test case provider
+	t := &OptimizationTestCaseProvider{
+		fuzzer: fuzzer,
+	}
+
+	// Subscribe the provider to relevant events the fuzzer emits.
+	fuzzer.Events.FuzzerStarting.Subscribe(t.onFuzzerStarting)
+	fuzzer.Events.FuzzerStopping.Subscribe(t.onFuzzerStopping)
+	fuzzer.Events.WorkerCreated.Subscribe(t.onWorkerCreated)
+
+	// Add the provider's call sequence test function to the fuzzer.
+	fuzzer.Hooks.CallSequenceTestFuncs = append(fuzzer.Hooks.CallSequenceTestFuncs, t.callSequencePostCallTest)
+	return t
+}
+
+// isOptimizationTest checks whether the method is an optimization test given potential naming prefixes it must
+// conform to and its underlying input/output arguments.
+// Returns a boolean indicating whether the method is an optimization test, and another indicating whether its value
+// should be minimized rather than maximized.
+func (t *OptimizationTestCaseProvider) isOptimizationTest(method abi.Method) (bool, bool) {
+	// Optimization tests take no arguments and return a single integer.
+	if len(method.Inputs) != 0 || len(method.Outputs) != 1 {
+		return false, false
+	}
+	if outputType := method.Outputs[0].Type.T; outputType != abi.IntTy && outputType != abi.UintTy {
+		return false, false
+	}
+
+	// Minimization prefixes take precedence, as they may be more specific forms of maximization prefixes.
+	testingConfig := t.fuzzer.Config().Fuzzing.Testing.OptimizationTesting
+	for _, prefix := range testingConfig.MinimizeTestPrefixes {
+		if strings.HasPrefix(method.Name, prefix) {
+			return true, true
+		}
+	}
+	for _, prefix := range testingConfig.TestPrefixes {
+		if strings.HasPrefix(method.Name, prefix) {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// optimizationTestValue converts a decoded integer return value of an optimization test method to a big.Int.
+// Returns the converted value, or false if the value is not an integer.
+func optimizationTestValue(value any) (*big.Int, bool) {
+	if bigIntValue, ok := value.(*big.Int); ok {
+		return bigIntValue, true
+	}
+	reflectedValue := reflect.ValueOf(value)
+	switch reflectedValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(reflectedValue.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(reflectedValue.Uint()), true
+	}
+	return nil, false
+}
+
+// runOptimizationTest executes a given optimization test method to obtain the value it returns. This is used to
+// facilitate testing of optimization test methods after every call the Fuzzer makes when testing call sequences.
+// A boolean indicating whether an execution trace should be captured and returned is provided to the method.
+// Returns the value the optimization test method returned (or nil if it reverted), an optional execution trace for
+// the optimization test call, or an error if one occurred.
+func (t *OptimizationTestCaseProvider) runOptimizationTest(worker *FuzzerWorker, optimizationTestMethod *contracts.DeployedContractMethod, trace bool) (*big.Int, *executiontracer.ExecutionTrace, error) {
+	// Generate our ABI input data for the call. In this case, optimization test methods take no arguments, so the
+	// variadic argument list here is empty.
+	data, err := optimizationTestMethod.Contract.CompiledContract().Abi.Pack(optimizationTestMethod.Method.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create a call targeting our optimization test method
+	msg := calls.NewCallMessage(worker.Fuzzer().senders[0], &optimizationTestMethod.Address, 0, big.NewInt(0), worker.fuzzer.config.Fuzzing.TransactionGasLimit, nil, nil, nil, data)
+	msg.FillFromTestChainProperties(worker.chain)
+
+	// Execute the call. If we are tracing, we attach an execution tracer and obtain the result.
+	var executionResult *core.ExecutionResult
+	var executionTrace *executiontracer.ExecutionTrace
+	if trace {
+		executionTracer := executiontracer.NewExecutionTracer(worker.fuzzer.contractDefinitions, worker.chain.CheatCodeContracts(), worker.fuzzer.traceStorageWrites())
+		executionResult, err = worker.Chain().CallContract(msg, nil, executionTracer)
+		executionTrace = executionTracer.Trace()
+	} else {
+		executionResult, err = worker.Chain().CallContract(msg, nil)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to call optimization test method: %v", err)
+	}
+
+	// If our optimization test method call failed, it provides no value to consider.
+	if executionResult.Failed() {
+		return nil, nil, nil
+	}
+
+	// Decode our ABI outputs
+	retVals, err := optimizationTestMethod.Method.Outputs.Unpack(executionResult.Return())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode optimization test method return value: %v", err)
+	}
+
+	// We should have one return value.
+	if len(retVals) != 1 {
+		return nil, nil, fmt.Errorf("detected an unexpected number of return values from optimization test '%s'", optimizationTestMethod.Method.Name)
+	}
+
+	// The one return value should be an integer
+	value, ok := optimizationTestValue(retVals[0])
+	if !ok {
+		return nil, nil, fmt.Errorf("failed to parse optimization test method value from return value '%s'", optimizationTestMethod.Method.Name)
+	}
+	return value, executionTrace, nil
+}
+
+// onFuzzerStarting is the event handler triggered when the Fuzzer is starting a fuzzing campaign. It creates test cases
+// in a "not started" state for every optimization test method discovered in the contract definitions known to the
+// Fuzzer.
+func (t *OptimizationTestCaseProvider) onFuzzerStarting(event FuzzerStartingEvent) error {
+	// Reset our state
+	t.testCases = make(map[contracts.ContractMethodID]*OptimizationTestCase)
+	t.gasTestCases = make(map[contracts.ContractMethodID]*OptimizationTestCase)
+	t.workerStates = make([]optimizationTestCaseProviderWorkerState, t.fuzzer.Config().Fuzzing.Workers)
+
+	// Create a test case for every optimization test method.
+	for _, contract := range t.fuzzer.ContractDefinitions() {
+		// If we're not testing all contracts, verify the current contract is one we specified in our deployment order.
+		if !t.fuzzer.config.Fuzzing.Testing.TestAllContracts && !slices.Contains(t.fuzzer.config.Fuzzing.DeploymentOrder, contract.Name()) {
+			continue
+		}
+
+		for _, method := range contract.CompiledContract().Abi.Methods {
+			// Verify this method is an optimization test method
+			isOptimizationTest, minimize := t.isOptimizationTest(method)
+			if !isOptimizationTest {
+				continue
+			}
+
+			// Create local variables to avoid pointer types in the loop being overridden.
+			contract := contract
+			method := method
+
+			// Create our optimization test case
+			optimizationTestCase := &OptimizationTestCase{
+				status:         TestCaseStatusNotStarted,
+				targetContract: contract,
+				targetMethod:   method,
+				minimize:       minimize,
+			}
+
+			// Add to our test cases and register them with the fuzzer
+			methodId := contracts.GetContractMethodID(contract, &method)
+			t.testCases[methodId] = optimizationTestCase
+			t.fuzzer.RegisterTestCase(optimizationTestCase)
+		}
+	}
+
+	// Create a test case for every gas target.
+	for _, gasTarget := range t.fuzzer.config.Fuzzing.Testing.OptimizationTesting.GasTargets {
+		contract, method := t.resolveGasTarget(gasTarget)
+		if contract == nil {
+			return fmt.Errorf("optimization gas target '%s' does not match a method of any known contract", gasTarget)
+		}
+
+		// Create our gas optimization test case, skipping any duplicate gas targets.
+		methodId := contracts.GetContractMethodID(contract, method)
+		if _, exists := t.gasTestCases[methodId]; exists {
+			continue
+		}
+		gasTestCase := &OptimizationTestCase{
+			status:         TestCaseStatusNotStarted,
+			targetContract: contract,
+			targetMethod:   *method,
+			gasTarget:      true,
+		}
+		t.gasTestCases[methodId] = gasTestCase
+		t.fuzzer.RegisterTestCase(gasTestCase)
+	}
+	return nil
+}
+
+// resolveGasTarget resolves a gas target of the form "ContractName.methodName" or "ContractName.methodSignature" to
+// a contract definition known to the Fuzzer and a method within it.
+// Returns the contract and method, or nil values if the gas target could not be resolved.
+func (t *OptimizationTestCaseProvider) resolveGasTarget(gasTarget string) (*contracts.Contract, *abi.Method) {
+	contractName, methodName, _ := strings.Cut(gasTarget, ".")
+	for _, contract := range t.fuzzer.ContractDefinitions() {
+		if contract.Name() != contractName {
+			continue
+		}
+		for _, method := range contract.CompiledContract().Abi.Methods {
+			if method.Name == methodName || method.Sig == methodName {
+				method := method
+				return contract, &method
+			}
+		}
+	}
+	return nil, nil
+}
+
+// onFuzzerStopping is the event handler triggered when the Fuzzer is stopping the fuzzing campaign and all workers
+// have been destroyed. It clears state tracked for each FuzzerWorker and sets test cases in "running" states to
+// "passed".
+func (t *OptimizationTestCaseProvider) onFuzzerStopping(event FuzzerStoppingEvent) error {
+	// Clear our optimization test methods
+	t.workerStates = nil
+
+	// Loop through each test case and set any tests with a running status to a passed status.
+	for _, testCase := range t.testCases {
+		if testCase.status == TestCaseStatusRunning {
+			testCase.status = TestCaseStatusPassed
+		}
+	}
+	for _, testCase := range t.gasTestCases {
+		if testCase.status == TestCaseStatusRunning {
+			testCase.status = TestCaseStatusPassed
+		}
+	}
+	return nil
+}
+
+// onWorkerCreated is the event handler triggered when a FuzzerWorker is created by the Fuzzer. It ensures state tracked
+// for that worker index is refreshed and subscribes to relevant worker events.
+func (t *OptimizationTestCaseProvider) onWorkerCreated(event FuzzerWorkerCreatedEvent) error {
+	// Create a new state for this worker.
+	t.workerStates[event.Worker.WorkerIndex()] = optimizationTestCaseProviderWorkerState{
+		optimizationTestMethods:     make(map[contracts.ContractMethodID]contracts.DeployedContractMethod),
+		optimizationTestMethodsLock: sync.Mutex{},
+	}
+
+	// Subscribe to relevant worker events.
+	event.Worker.Events.ContractAdded.Subscribe(t.onWorkerDeployedContractAdded)
+	event.Worker.Events.ContractDeleted.Subscribe(t.onWorkerDeployedContractDeleted)
+	if len(t.gasTestCases) > 0 {
+		event.Worker.Events.FuzzerWorkerChainCreated.Subscribe(t.onWorkerChainCreated)
+	}
+	return nil
+}
+
+// onWorkerChainCreated is the event handler triggered when a FuzzerWorker has created its chain, prior to chain
+// setup. It attaches a tracer to the chain which measures the gas used by each transaction, so gas targets can be
+// evaluated.
+func (t *OptimizationTestCaseProvider) onWorkerChainCreated(event FuzzerWorkerChainCreatedEvent) error {
+	event.Chain.AddTracer(gastracer.NewGasTracer(), true, false)
+	return nil
+}
+
+// onWorkerDeployedContractAdded is the event handler triggered when a FuzzerWorker detects a new contract deployment
+// on its underlying chain. It ensures any optimization test methods which the deployed contract contains are tracked
+// by the provider for testing. Any test cases previously made for these methods which are in a "not started" state
+// are put into a "running" state, as they are now potentially reachable for testing.
+func (t *OptimizationTestCaseProvider) onWorkerDeployedContractAdded(event FuzzerWorkerContractAddedEvent) error {
+	// If we don't have a contract definition, we can't run optimization tests against the contract.
+	if event.ContractDefinition == nil {
+		return nil
+	}
+
+	// Loop through all methods and find ones for which we have tests
+	for _, method := range event.ContractDefinition.CompiledContract().Abi.Methods {
+		// Obtain an identifier for this pair
+		methodId := contracts.GetContractMethodID(event.ContractDefinition, &method)
+
+		// If we have a gas test case targeting this contract/method, it is now reachable, so we can signal a running
+		// state if it has not started.
+		t.testCasesLock.Lock()
+		gasTestCase, gasTestCaseExists := t.gasTestCases[methodId]
+		if gasTestCaseExists && gasTestCase.Status() == TestCaseStatusNotStarted {
+			gasTestCase.status = TestCaseStatusRunning
+		}
+
+		// If we have a test case targeting this contract/method, track this deployed method in our map for this
+		// worker. If the test is in a not-started state, we can signal a running state now.
+		optimizationTestCase, optimizationTestCaseExists := t.testCases[methodId]
+		t.testCasesLock.Unlock()
+
+		if optimizationTestCaseExists {
+			if optimizationTestCase.Status() == TestCaseStatusNotStarted {
+				optimizationTestCase.status = TestCaseStatusRunning
+			}
+
+			// Create our optimization test method reference.
+			workerState := &t.workerStates[event.Worker.WorkerIndex()]
+			workerState.optimizationTestMethodsLock.Lock()
+			workerState.optimizationTestMethods[methodId] = contracts.DeployedContractMethod{
+				Address:  event.ContractAddress,
+				Contract: event.ContractDefinition,
+				Method:   method,
+			}
+			workerState.optimizationTestMethodsLock.Unlock()
+		}
+	}
+	return nil
+}
+
+// onWorkerDeployedContractDeleted is the event handler triggered when a FuzzerWorker detects that a previously
+// deployed contract no longer exists on its underlying chain. It ensures any optimization test methods which the
+// deployed contract contained are no longer tracked by the provider for testing.
+func (t *OptimizationTestCaseProvider) onWorkerDeployedContractDeleted(event FuzzerWorkerContractDeletedEvent) error {
+	// If we don't have a contract definition, there's nothing to do.
+	if event.ContractDefinition == nil {
+		return nil
+	}
+
+	// Loop through all methods and find ones for which we have tests
+	for _, method := range event.ContractDefinition.CompiledContract().Abi.Methods {
+		// Obtain an identifier for this pair
+		methodId := contracts.GetContractMethodID(event.ContractDefinition, &method)
+
+		// If this identifier is in our test cases map, then we remove it from our optimization test method lookup
+		// for this worker index.
+		t.testCasesLock.Lock()
+		_, isOptimizationTestMethod := t.testCases[methodId]
+		t.testCasesLock.Unlock()
+
+		if isOptimizationTestMethod {
+			// Delete our optimization test method reference.
+			workerState := &t.workerStates[event.Worker.WorkerIndex()]
+			workerState.optimizationTestMethodsLock.Lock()
+			delete(workerState.optimizationTestMethods, methodId)
+			workerState.optimizationTestMethodsLock.Unlock()
+		}
+	}
+	return nil
+}
+
+// logOptimizationTestImprovement logs that the provided optimization test reached a new best value, alongside the
+// difference from the previous best value, if it had one.
+func logOptimizationTestImprovement(worker *FuzzerWorker, testCase *OptimizationTestCase, value *big.Int, previousValue *big.Int, callSequence calls.CallSequence) {
+	name := strings.TrimSpace(testCase.Name())
+	event := logging.GlobalLogger.Info().
+		Str("testCase", name).
+		Str("testCaseId", testCase.ID()).
+		Str("value", value.String()).
+		Int("worker", worker.WorkerIndex()).
+		Int("sequenceLength", len(callSequence))
+	if previousValue == nil {
+		event.Msgf("[%s] found initial %s value %v", name, testCase.objective(), value)
+		return
+	}
+	delta := new(big.Int).Sub(value, previousValue)
+	deltaText := delta.String()
+	if delta.Sign() > 0 {
+		deltaText = "+" + deltaText
+	}
+	event.Str("previousValue", previousValue.String()).Str("delta", delta.String()).
+		Msgf("[%s] improved %s value to %v (%s)", name, testCase.objective(), value, deltaText)
+}
+
+// callSequencePostCallTest provides is a CallSequenceTestFunc that performs post-call testing logic for the attached
+// Fuzzer and any underlying FuzzerWorker. It is called after every call made in a call sequence. It checks whether
+// any optimization test method returns a value better than the best value found for it so far, requesting the call
+// sequence be shrunk if so.
+func (t *OptimizationTestCaseProvider) callSequencePostCallTest(worker *FuzzerWorker, callSequence calls.CallSequence) ([]ShrinkCallSequenceRequest, error) {
+	// Create a list of shrink call sequence verifiers, which we populate for each optimization test we improved upon.
+	shrinkRequests := make([]ShrinkCallSequenceRequest, 0)
+
+	// Obtain the test provider state for this worker
+	workerState := &t.workerStates[worker.WorkerIndex()]
+
+	// Loop through all optimization test methods and test them.
+	for optimizationTestMethodId, workerOptimizationTestMethod := range workerState.optimizationTestMethods {
+		// Obtain the test case for this optimization test method
+		t.testCasesLock.Lock()
+		testCase := t.testCases[optimizationTestMethodId]
+		t.testCasesLock.Unlock()
+
+		// Test our optimization test method (create a local copy to avoid loop overwriting the method)
+		workerOptimizationTestMethod := workerOptimizationTestMethod
+		value, _, err := t.runOptimizationTest(worker, &workerOptimizationTestMethod, false)
+		if err != nil {
+			return nil, err
+		}
+
+		// If we did not improve upon the best value, there is nothing to do.
+		if value == nil || !testCase.isImprovement(value, testCase.Value()) {
+			continue
+		}
+
+		// We improved upon the best value, so we provide a shrink verifier which ensures each shrunken sequence
+		// reaches a value at least as good.
+		shrinkRequest := ShrinkCallSequenceRequest{
+			VerifierFunction: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) (bool, error) {
+				// First verify the contract to optimization test is still deployed to call upon.
+				_, optimizationTestContractDeployed := worker.deployedContracts[workerOptimizationTestMethod.Address]
+				if !optimizationTestContractDeployed {
+					// If the contract isn't available, this shrunk sequence likely messed up deployment, so we
+					// report it as an invalid solution.
+					return false, nil
+				}
+
+				// Then the shrink verifier ensures the shrunken sequence reaches the value we found, or a better one.
+				shrunkenValue, _, err := t.runOptimizationTest(worker, &workerOptimizationTestMethod, false)
+				if err != nil || shrunkenValue == nil {
+					return false, err
+				}
+				return shrunkenValue.Cmp(value) == 0 || testCase.isImprovement(shrunkenValue, value), nil
+			},
+			FinishedCallback: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) error {
+				// When we're finished shrinking, attach an execution trace to the last call
+				if len(shrunkenCallSequence) > 0 {
+					err := shrunkenCallSequence[len(shrunkenCallSequence)-1].AttachExecutionTrace(worker.chain, worker.fuzzer.contractDefinitions, worker.fuzzer.traceStorageWrites())
+					if err != nil {
+						return err
+					}
+				}
+
+				// Execute the optimization test a final time, this time obtaining an execution trace
+				shrunkenValue, executionTrace, err := t.runOptimizationTest(worker, &workerOptimizationTestMethod, true)
+				if err != nil {
+					return err
+				}
+				if shrunkenValue == nil {
+					return fmt.Errorf("optimization test provider did not obtain a value on final shrunken sequence")
+				}
+
+				return t.recordOptimizationTestImprovement(worker, testCase, shrunkenValue, workerOptimizationTestMethod.Address, shrunkenCallSequence, executionTrace)
+			},
+			RecordResultInCorpus: true,
+		}
+
+		// Add our shrink request to our list.
+		shrinkRequests = append(shrinkRequests, shrinkRequest)
+	}
+
+	// Test whether the last call made targets a gas target and used more gas than the maximum found for it so far.
+	if gasShrinkRequest := t.gasTargetShrinkRequest(callSequence); gasShrinkRequest != nil {
+		shrinkRequests = append(shrinkRequests, *gasShrinkRequest)
+	}
+	return shrinkRequests, nil
+}
+
+// gasTargetShrinkRequest creates a request to shrink the provided call sequence if its last call targets a gas target
+// and used more gas for execution than the maximum found for that target so far. The shrunken call sequence must end
+// with a call to the same target which uses at least as much gas.
+// Returns the shrink request, or nil if the call sequence does not improve upon a gas target.
+func (t *OptimizationTestCaseProvider) gasTargetShrinkRequest(callSequence calls.CallSequence) *ShrinkCallSequenceRequest {
+	// Obtain the gas used by the last call, if it targets a gas target.
+	if len(callSequence) == 0 {
+		return nil
+	}
+	testCase, gasUsed := t.gasTargetCallGasUsed(callSequence[len(callSequence)-1])
+	if testCase == nil || !testCase.isImprovement(gasUsed, testCase.Value()) {
+		return nil
+	}
+
+	return &ShrinkCallSequenceRequest{
+		VerifierFunction: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) (bool, error) {
+			// The shrunken sequence must still end with a call to our target which uses at least as much gas.
+			shrunkenTestCase, shrunkenGasUsed := t.gasTargetCallGasUsed(shrunkenCallSequence[len(shrunkenCallSequence)-1])
+			if shrunkenTestCase != testCase {
+				return false, nil
+			}
+			return shrunkenGasUsed.Cmp(gasUsed) >= 0, nil
+		},
+		FinishedCallback: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) error {
+			// When we're finished shrinking, obtain the gas used by the final call and attach an execution trace to it.
+			lastCall := shrunkenCallSequence[len(shrunkenCallSequence)-1]
+			shrunkenTestCase, shrunkenGasUsed := t.gasTargetCallGasUsed(lastCall)
+			if shrunkenTestCase != testCase {
+				return fmt.Errorf("optimization test provider did not obtain the gas used by the final call of the shrunken sequence")
+			}
+			err := lastCall.AttachExecutionTrace(worker.chain, worker.fuzzer.contractDefinitions, worker.fuzzer.traceStorageWrites())
+			if err != nil {
+				return err
+			}
+			return t.recordOptimizationTestImprovement(worker, testCase, shrunkenGasUsed, *lastCall.Call.To(), shrunkenCallSequence, nil)
+		},
+		RecordResultInCorpus: true,
+	}
+}
+
+// gasTargetCallGasUsed obtains the gas test case targeted by the provided executed call, along with the gas that call
+// used for execution, excluding its intrinsic cost.
+// Returns the test case and gas used, or nil values if the call does not target a gas target or did not succeed.
+func (t *OptimizationTestCaseProvider) gasTargetCallGasUsed(callSequenceElement *calls.CallSequenceElement) (*OptimizationTestCase, *big.Int) {
+	// Calls which were not executed or which target unknown methods cannot target a gas target.
+	if callSequenceElement.ChainReference == nil {
+		return nil, nil
+	}
+	method, err := callSequenceElement.Method()
+	if err != nil || method == nil {
+		return nil, nil
+	}
+
+	// Obtain the test case for the targeted method.
+	t.testCasesLock.Lock()
+	testCase, exists := t.gasTestCases[contracts.GetContractMethodID(callSequenceElement.Contract, method)]
+	t.testCasesLock.Unlock()
+	if !exists {
+		return nil, nil
+	}
+
+	// Calls which failed do not count towards a gas target, as the gas they used is not meaningful (e.g. a call which
+	// ran out of gas used all gas provided to it).
+	messageResults := callSequenceElement.ChainReference.MessageResults()
+	if messageResults.ExecutionResult.Failed() {
+		return nil, nil
+	}
+	gasUsed, ok := gastracer.GetGasTracerResults(messageResults)
+	if !ok {
+		return nil, nil
+	}
+	return testCase, new(big.Int).SetUint64(gasUsed)
+}
+
+// recordOptimizationTestImprovement updates the provided optimization test with a value reached by a shrunken call
+// sequence, if it still improves upon the test's best value, as another worker may have found a better value while
+// the sequence was shrunk. The improvement is logged and the call sequence is persisted as the best for the test, so
+// subsequent runs start from it.
+// Returns an error if one occurred.
+func (t *OptimizationTestCaseProvider) recordOptimizationTestImprovement(worker *FuzzerWorker, testCase *OptimizationTestCase, value *big.Int, targetAddress common.Address, callSequence calls.CallSequence, executionTrace *executiontracer.ExecutionTrace) error {
+	testCase.valueLock.Lock()
+	previousValue := testCase.value
+	if !testCase.isImprovement(value, previousValue) {
+		testCase.valueLock.Unlock()
+		return nil
+	}
+	testCase.value = value
+	testCase.targetAddress = targetAddress
+	testCase.callSequence = &callSequence
+	testCase.optimizationTestTrace = executionTrace
+	testCase.valueLock.Unlock()
+	logOptimizationTestImprovement(worker, testCase, value, previousValue, callSequence)
+
+	return worker.fuzzer.corpus.WriteOptimizationCallSequence(testCase.ID(), callSequence, worker.newCorpusCallSequenceMetadata(corpus.CallSequenceOriginShrink))
+}
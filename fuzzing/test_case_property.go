@@ -6,6 +6,7 @@ import (
 	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
 	"github.com/crytic/medusa/fuzzing/executiontracer"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 	"strings"
 )
 
@@ -14,6 +15,7 @@ type PropertyTestCase struct {
 	status            TestCaseStatus
 	targetContract    *fuzzerTypes.Contract
 	targetMethod      abi.Method
+	targetAddress     common.Address
 	callSequence      *calls.CallSequence
 	propertyTestTrace *executiontracer.ExecutionTrace
 }
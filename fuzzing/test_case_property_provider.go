@@ -2,6 +2,7 @@ package fuzzing
 
 import (
 	"fmt"
+	"github.com/crytic/medusa/chain"
 	"github.com/crytic/medusa/fuzzing/calls"
 	"github.com/crytic/medusa/fuzzing/contracts"
 	"github.com/crytic/medusa/fuzzing/executiontracer"
@@ -9,13 +10,15 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"golang.org/x/exp/slices"
 	"math/big"
+	"regexp"
 	"strings"
 	"sync"
 )
 
 // PropertyTestCaseProvider is a provider for on-chain property tests.
-// Property tests are represented as publicly-accessible view functions which have a name prefix specified by a
-// config.FuzzingConfig. They take no input arguments and return a boolean indicating whether the test passed.
+// Property tests are represented as publicly-accessible view functions which have a name prefix, or a name matching an
+// invariant pattern, specified by a config.FuzzingConfig. They take no input arguments and return a boolean
+// indicating whether the test passed.
 // If a call to any on-chain property test returns false, the test signals a failed status. If no failure is found
 // before the fuzzing campaign ends, the test signals a passed status.
 type PropertyTestCaseProvider struct {
@@ -62,15 +65,26 @@ func attachPropertyTestCaseProvider(fuzzer *Fuzzer) *PropertyTestCaseProvider {
 	return t
 }
 
-// isPropertyTest check whether the method is a property test given potential naming prefixes it must conform to
-// and its underlying input/output arguments.
+// isPropertyTest check whether the method is a property test given potential naming prefixes or invariant patterns
+// it must conform to and its underlying input/output arguments.
 func (t *PropertyTestCaseProvider) isPropertyTest(method abi.Method) bool {
+	// Property tests take no arguments and return a single boolean.
+	if len(method.Inputs) != 0 || len(method.Outputs) != 1 || method.Outputs[0].Type.T != abi.BoolTy {
+		return false
+	}
+
 	// Loop through all enabled prefixes to find a match
 	for _, prefix := range t.fuzzer.Config().Fuzzing.Testing.PropertyTesting.TestPrefixes {
 		if strings.HasPrefix(method.Name, prefix) {
-			if len(method.Inputs) == 0 && len(method.Outputs) == 1 && method.Outputs[0].Type.T == abi.BoolTy {
-				return true
-			}
+			return true
+		}
+	}
+
+	// Loop through all invariant patterns to find a match. These were validated with the project config, so any which
+	// fail to compile are skipped.
+	for _, pattern := range t.fuzzer.Config().Fuzzing.Testing.PropertyTesting.InvariantPatterns {
+		if matched, err := regexp.MatchString(pattern, method.Name); err == nil && matched {
+			return true
 		}
 	}
 	return false
@@ -82,6 +96,15 @@ func (t *PropertyTestCaseProvider) isPropertyTest(method abi.Method) bool {
 // Returns a boolean indicating if the property test failed, an optional execution trace for the property test call,
 // or an error if one occurred.
 func (t *PropertyTestCaseProvider) checkPropertyTestFailed(worker *FuzzerWorker, propertyTestMethod *contracts.DeployedContractMethod, trace bool) (bool, *executiontracer.ExecutionTrace, error) {
+	return t.checkPropertyTestFailedOnChain(worker.chain, propertyTestMethod, trace)
+}
+
+// checkPropertyTestFailedOnChain executes a given property test method on the provided test chain to see if it returns
+// a failed status. A boolean indicating whether an execution trace should be captured and returned is provided to the
+// method.
+// Returns a boolean indicating if the property test failed, an optional execution trace for the property test call,
+// or an error if one occurred.
+func (t *PropertyTestCaseProvider) checkPropertyTestFailedOnChain(testChain *chain.TestChain, propertyTestMethod *contracts.DeployedContractMethod, trace bool) (bool, *executiontracer.ExecutionTrace, error) {
 	// Generate our ABI input data for the call. In this case, property test methods take no arguments, so the
 	// variadic argument list here is empty.
 	data, err := propertyTestMethod.Contract.CompiledContract().Abi.Pack(propertyTestMethod.Method.Name)
@@ -90,19 +113,18 @@ func (t *PropertyTestCaseProvider) checkPropertyTestFailed(worker *FuzzerWorker,
 	}
 
 	// Create a call targeting our property test method
-	// TODO: Determine if we should use `Senders[0]` or have a separate funded account for the assertions.
-	msg := calls.NewCallMessage(worker.Fuzzer().senders[0], &propertyTestMethod.Address, 0, big.NewInt(0), worker.fuzzer.config.Fuzzing.TransactionGasLimit, nil, nil, nil, data)
-	msg.FillFromTestChainProperties(worker.chain)
+	msg := calls.NewCallMessage(t.fuzzer.propertyTestSender(), &propertyTestMethod.Address, 0, big.NewInt(0), t.fuzzer.config.Fuzzing.TransactionGasLimit, nil, nil, nil, data)
+	msg.FillFromTestChainProperties(testChain)
 
 	// Execute the call. If we are tracing, we attach an execution tracer and obtain the result.
 	var executionResult *core.ExecutionResult
 	var executionTrace *executiontracer.ExecutionTrace
 	if trace {
-		executionTracer := executiontracer.NewExecutionTracer(worker.fuzzer.contractDefinitions, worker.chain.CheatCodeContracts())
-		executionResult, err = worker.Chain().CallContract(msg, nil, executionTracer)
+		executionTracer := executiontracer.NewExecutionTracer(t.fuzzer.contractDefinitions, testChain.CheatCodeContracts(), t.fuzzer.traceStorageWrites())
+		executionResult, err = testChain.CallContract(msg, nil, executionTracer)
 		executionTrace = executionTracer.Trace()
 	} else {
-		executionResult, err = worker.Chain().CallContract(msg, nil)
+		executionResult, err = testChain.CallContract(msg, nil)
 	}
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to call property test method: %v", err)
@@ -137,9 +159,20 @@ func (t *PropertyTestCaseProvider) checkPropertyTestFailed(worker *FuzzerWorker,
 // onFuzzerStarting is the event handler triggered when the Fuzzer is starting a fuzzing campaign. It creates test cases
 // in a "not started" state for every property test method discovered in the contract definitions known to the Fuzzer.
 func (t *PropertyTestCaseProvider) onFuzzerStarting(event FuzzerStartingEvent) error {
-	// Reset our state
-	t.testCases = make(map[contracts.ContractMethodID]*PropertyTestCase)
+	// Reset our state and register our test cases with the fuzzer
 	t.workerStates = make([]propertyTestCaseProviderWorkerState, t.fuzzer.Config().Fuzzing.Workers)
+	for _, propertyTestCase := range t.resetTestCases() {
+		t.fuzzer.RegisterTestCase(propertyTestCase)
+	}
+	return nil
+}
+
+// resetTestCases replaces the test cases tracked by the provider with new ones in a "not started" state, for every
+// property test method discovered in the contract definitions known to the Fuzzer.
+// Returns the test cases created, in the order they were created.
+func (t *PropertyTestCaseProvider) resetTestCases() []*PropertyTestCase {
+	t.testCases = make(map[contracts.ContractMethodID]*PropertyTestCase)
+	testCases := make([]*PropertyTestCase, 0)
 
 	// Create a test case for every property test method.
 	for _, contract := range t.fuzzer.ContractDefinitions() {
@@ -166,13 +199,13 @@ func (t *PropertyTestCaseProvider) onFuzzerStarting(event FuzzerStartingEvent) e
 				callSequence:   nil,
 			}
 
-			// Add to our test cases and register them with the fuzzer
+			// Add to our test cases
 			methodId := contracts.GetContractMethodID(contract, &method)
 			t.testCases[methodId] = propertyTestCase
-			t.fuzzer.RegisterTestCase(propertyTestCase)
+			testCases = append(testCases, propertyTestCase)
 		}
 	}
-	return nil
+	return testCases
 }
 
 // onFuzzerStarting is the event handler triggered when the Fuzzer is stopping the fuzzing campaign and all workers
@@ -296,8 +329,8 @@ func (t *PropertyTestCaseProvider) callSequencePostCallTest(worker *FuzzerWorker
 		testCase := t.testCases[propertyTestMethodId]
 		t.testCasesLock.Unlock()
 
-		// If the test case already failed, skip it
-		if testCase.Status() == TestCaseStatusFailed {
+		// If the test case already failed, skip it, unless failed test cases continue to be tested
+		if testCase.Status() == TestCaseStatusFailed && !t.fuzzer.retestFailedTestCases() {
 			continue
 		}
 
@@ -330,7 +363,7 @@ func (t *PropertyTestCaseProvider) callSequencePostCallTest(worker *FuzzerWorker
 				FinishedCallback: func(worker *FuzzerWorker, shrunkenCallSequence calls.CallSequence) error {
 					// When we're finished shrinking, attach an execution trace to the last call
 					if len(shrunkenCallSequence) > 0 {
-						err = shrunkenCallSequence[len(shrunkenCallSequence)-1].AttachExecutionTrace(worker.chain, worker.fuzzer.contractDefinitions)
+						err = shrunkenCallSequence[len(shrunkenCallSequence)-1].AttachExecutionTrace(worker.chain, worker.fuzzer.contractDefinitions, worker.fuzzer.traceStorageWrites())
 						if err != nil {
 							return err
 						}
@@ -347,6 +380,7 @@ func (t *PropertyTestCaseProvider) callSequencePostCallTest(worker *FuzzerWorker
 
 					// Update our test state and report it finalized.
 					testCase.status = TestCaseStatusFailed
+					testCase.targetAddress = workerPropertyTestMethod.Address
 					testCase.callSequence = &shrunkenCallSequence
 					testCase.propertyTestTrace = executionTrace
 					worker.Fuzzer().ReportTestCaseFinished(testCase)
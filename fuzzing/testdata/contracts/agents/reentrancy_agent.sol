@@ -0,0 +1,42 @@
+// This contract is used to test calls routed through agent contracts. The bank pays out withdrawals to its caller
+// before updating its state, which is a property violation only a contract sender can reach by re-entering it.
+contract Bank {
+    bool withdrawing;
+    bool reentered;
+
+    function withdraw() public {
+        if (withdrawing) {
+            reentered = true;
+        }
+        withdrawing = true;
+        (bool success, ) = msg.sender.call("");
+        require(success);
+        withdrawing = false;
+    }
+
+    function fuzz_never_reentered() public view returns (bool) {
+        return !reentered;
+    }
+}
+
+// This agent forwards calls routed through it, and re-enters the bank when it is paid out.
+contract Agent {
+    bool entered;
+
+    function execute(address target, bytes calldata data, uint256 value) external payable {
+        (bool success, bytes memory returnData) = target.call{value: value}(data);
+        if (!success) {
+            assembly {
+                revert(add(returnData, 32), mload(returnData))
+            }
+        }
+    }
+
+    fallback() external payable {
+        if (!entered) {
+            entered = true;
+            Bank(msg.sender).withdraw();
+            entered = false;
+        }
+    }
+}
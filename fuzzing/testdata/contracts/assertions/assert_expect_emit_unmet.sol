@@ -0,0 +1,28 @@
+// This contract expects an event emission which is not met for some inputs, so the assertion test should fail.
+interface CheatCodes {
+    function expectEmit(bool, bool, bool, bool) external;
+}
+
+contract Token {
+    event Transfer(address indexed from, address indexed to, uint256 amount);
+
+    function transfer(address to, uint256 amount) public {
+        // Transfers with an amount of zero do not emit an event.
+        if (amount > 0) {
+            emit Transfer(msg.sender, to, amount);
+        }
+    }
+}
+
+contract TestContract {
+    event Transfer(address indexed from, address indexed to, uint256 amount);
+
+    Token token = new Token();
+
+    function transfer(uint256 amount) public {
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+        cheats.expectEmit(true, true, true, true);
+        emit Transfer(address(this), address(1), amount);
+        token.transfer(address(1), amount);
+    }
+}
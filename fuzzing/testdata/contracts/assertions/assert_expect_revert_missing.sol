@@ -0,0 +1,18 @@
+// This contract verifies the fuzzer reports a failure when a method expected to revert completes without reverting.
+contract TestContract {
+    error InsufficientBalance(uint256 available, uint256 required);
+
+    uint256 balance = 100;
+
+    function withdraw(uint256 amount) public {
+        if (amount > balance) {
+            revert InsufficientBalance(balance, amount);
+        }
+        balance -= amount;
+    }
+
+    function medusa_revert_InsufficientBalance_withdraw(uint256 amount) public {
+        // This should fail, as withdrawing an amount within the balance does not revert.
+        withdraw(amount);
+    }
+}
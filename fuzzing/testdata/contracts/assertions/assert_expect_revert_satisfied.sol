@@ -0,0 +1,24 @@
+// This contract verifies the fuzzer does not report a failure when methods expected to revert always revert as
+// expected.
+contract TestContract {
+    error InsufficientBalance(uint256 available, uint256 required);
+
+    uint256 balance = 100;
+
+    function withdraw(uint256 amount) public {
+        if (amount > balance) {
+            revert InsufficientBalance(balance, amount);
+        }
+        balance -= amount;
+    }
+
+    function medusa_revert_InsufficientBalance_withdraw(uint256 amount) public {
+        // This should not fail, as the amount always exceeds the balance.
+        withdraw(balance + 1 + (amount % 1000));
+    }
+
+    function medusa_revert_require(uint256 amount) public {
+        // This should not fail, as any revert is expected.
+        require(false, "always reverts");
+    }
+}
@@ -0,0 +1,14 @@
+// This contract verifies the fuzzer calls view methods expected to revert, reporting a failure when they complete
+// without reverting.
+contract TestContract {
+    error InsufficientBalance(uint256 available, uint256 required);
+
+    uint256 balance = 100;
+
+    function medusa_revert_InsufficientBalance_check(uint256 amount) public view {
+        // This should fail, as checking an amount within the balance does not revert.
+        if (amount > balance) {
+            revert InsufficientBalance(balance, amount);
+        }
+    }
+}
@@ -0,0 +1,15 @@
+// This contract verifies the fuzzer reports a failure when a method expected to revert with a given error reverts
+// with a different one.
+contract TestContract {
+    error InsufficientBalance(uint256 available, uint256 required);
+    error Unauthorized(address caller);
+
+    function withdraw(uint256 amount) public {
+        revert Unauthorized(msg.sender);
+    }
+
+    function medusa_revert_InsufficientBalance_withdraw(uint256 amount) public {
+        // This should fail, as the revert uses a different error than expected.
+        withdraw(amount);
+    }
+}
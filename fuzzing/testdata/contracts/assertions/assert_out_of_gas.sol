@@ -0,0 +1,18 @@
+// This contract ensures the fuzzer does not report assertion failures caused by a call running out of gas, unless it
+// is configured to treat running out of gas as a failure.
+contract TestContract {
+    function work() external returns (uint256 sum) {
+        // Consume more gas than some of the gas limits the fuzzer may give a call.
+        for (uint256 i = 0; i < 2000; i++) {
+            sum += i;
+        }
+    }
+
+    function callWork() public {
+        // ASSERTION: this only fails if the call to work runs out of gas.
+        try this.work() {
+        } catch {
+            assert(false);
+        }
+    }
+}
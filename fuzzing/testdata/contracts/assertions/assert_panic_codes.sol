@@ -0,0 +1,25 @@
+// This contract ensures the fuzzer only reports panics it is configured to treat as assertion failures.
+contract TestContract {
+    uint[] values;
+
+    function failAssert(uint value) public {
+        assert(false);
+    }
+
+    function failArithmetic(uint value) public {
+        // This results in an arithmetic overflow panic for any value greater than zero.
+        uint x = type(uint).max;
+        x += value + 1;
+    }
+
+    function failDivision(uint value) public {
+        // This results in a division by zero panic.
+        uint zero = 0;
+        value = value / zero;
+    }
+
+    function failArrayAccess(uint value) public {
+        // This results in an out-of-bounds array access panic, as the array is empty.
+        value = values[value];
+    }
+}
@@ -0,0 +1,6 @@
+// This contract ensures the fuzzer shrinks the arguments of calls failing a test towards their simplest values.
+contract TestContract {
+    function failOnLargeValues(uint x, bytes memory data) public {
+        assert(x <= 1000 || data.length < 2);
+    }
+}
@@ -0,0 +1,20 @@
+// This contract ensures assertions failed by inner calls and caught by a try/catch statement are only reported when
+// swallowed panics are configured to fail tests.
+contract InnerContract {
+    function check(uint value) public pure {
+        // ASSERTION: Always fails on odd values, but is caught by the caller.
+        assert(value % 2 == 0);
+    }
+}
+
+contract TestContract {
+    InnerContract inner;
+
+    constructor() {
+        inner = new InnerContract();
+    }
+
+    function callInner(uint value) public {
+        try inner.check(value) {} catch {}
+    }
+}
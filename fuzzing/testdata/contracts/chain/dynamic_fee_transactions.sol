@@ -0,0 +1,21 @@
+// This contract verifies calls are sent as EIP-1559 transactions paying the block's base fee and a tip when dynamic fee
+// transactions are enabled, and as legacy transactions which do not pay the base fee otherwise.
+contract TestContract {
+    bool paidBaseFee;
+    bool paidTip;
+
+    function pay() public {
+        paidBaseFee = paidBaseFee || tx.gasprice >= block.basefee;
+        paidTip = paidTip || tx.gasprice > block.basefee;
+    }
+
+    function fuzz_never_pay_base_fee() public view returns (bool) {
+        // ASSERTION: calls should never pay the base fee, which fails if dynamic fee transactions are enabled.
+        return !paidBaseFee;
+    }
+
+    function fuzz_never_pay_tip() public view returns (bool) {
+        // ASSERTION: calls should never pay a tip, which fails if dynamic fee transactions are enabled.
+        return !paidTip;
+    }
+}
@@ -0,0 +1,16 @@
+// This contract verifies the storage slots populated by call sequences are recorded, by offering a method which grows
+// storage without bound, and one which only overwrites a single slot.
+contract TestContract {
+    uint256[] entries;
+    uint256 counter;
+
+    function push(uint8 count) public {
+        for (uint8 i = 0; i < count % 16; i++) {
+            entries.push(i + 1);
+        }
+    }
+
+    function increment() public {
+        counter++;
+    }
+}
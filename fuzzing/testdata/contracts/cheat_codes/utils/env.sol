@@ -0,0 +1,27 @@
+// This test ensures that environment variables can be read with cheat codes, falling back to defaults if missing
+interface CheatCodes {
+    function envOr(string calldata, uint256) external returns (uint256);
+    function envOr(string calldata, address) external returns (address);
+    function envOr(string calldata, string calldata, bool[] calldata) external returns (bool[] memory);
+    function envUint(string calldata) external returns (uint256);
+}
+
+contract TestContract {
+    function test(uint256 x, address y) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Verify defaults are returned for missing variables.
+        assert(cheats.envOr("MEDUSA_MISSING_ENV_VARIABLE", x) == x);
+        assert(cheats.envOr("MEDUSA_MISSING_ENV_VARIABLE", y) == y);
+        bool[] memory defaults = new bool[](2);
+        defaults[1] = true;
+        bool[] memory values = cheats.envOr("MEDUSA_MISSING_ENV_VARIABLE", ",", defaults);
+        assert(values.length == 2 && !values[0] && values[1]);
+
+        // Verify reading a missing variable without a default reverts.
+        try cheats.envUint("MEDUSA_MISSING_ENV_VARIABLE") {
+            assert(false);
+        } catch {}
+    }
+}
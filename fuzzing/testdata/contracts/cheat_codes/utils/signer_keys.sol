@@ -0,0 +1,21 @@
+// This test ensures that the fuzzer's signer keys can be obtained with cheat codes, and used to sign as their accounts
+interface CheatCodes {
+    function signerKeys() external returns (uint256[] memory);
+    function addr(uint256) external returns (address);
+    function sign(uint256, bytes32) external returns (uint8, bytes32, bytes32);
+}
+
+contract TestContract {
+    function test(bytes32 digest) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Obtain our signer keys and verify each produces signatures recovering to its account.
+        uint256[] memory keys = cheats.signerKeys();
+        assert(keys.length > 0);
+        for (uint256 i = 0; i < keys.length; i++) {
+            (uint8 v, bytes32 r, bytes32 s) = cheats.sign(keys[i], digest);
+            assert(ecrecover(digest, v, r, s) == cheats.addr(keys[i]));
+        }
+    }
+}
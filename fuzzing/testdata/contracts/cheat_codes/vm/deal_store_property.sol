@@ -0,0 +1,45 @@
+// This test ensures that balances and storage set with cheat codes during deployment persist across call sequences,
+// while those set during a call sequence are reverted before the next one.
+interface CheatCodes {
+    function deal(address, uint256) external;
+    function store(address, bytes32, bytes32) external;
+    function load(address, bytes32) external view returns (bytes32);
+}
+
+contract TestContract {
+    // Obtain our cheat code contract reference.
+    CheatCodes constant cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    // The first sender used by the fuzzer, and the balance we fund it with.
+    address constant SENDER = address(0x10000);
+    uint256 constant FUNDS = 1e30;
+
+    // Storage slot 0, which is set through cheat codes.
+    uint256 secret;
+
+    // Indicates whether the current call sequence changed the values set during deployment.
+    bool spent;
+
+    constructor() {
+        // Fund our sender with an exact balance, and store our secret directly.
+        cheats.deal(SENDER, FUNDS);
+        cheats.store(address(this), bytes32(uint256(0)), bytes32(uint256(5)));
+    }
+
+    function spendAll() public {
+        // Drain our sender and clear our secret. These changes should not outlive this call sequence.
+        cheats.deal(SENDER, 0);
+        cheats.store(address(this), bytes32(uint256(0)), bytes32(0));
+        spent = true;
+    }
+
+    function fuzz_sender_funded() public view returns (bool) {
+        // The sender should hold the funds dealt to it, less any gas fees it paid in this call sequence.
+        return spent || (SENDER.balance <= FUNDS && SENDER.balance > FUNDS - 1 ether);
+    }
+
+    function fuzz_secret_stored() public view returns (bool) {
+        // Our secret should be readable both directly and through cheat codes.
+        return spent || (secret == 5 && uint256(cheats.load(address(this), bytes32(uint256(0)))) == 5);
+    }
+}
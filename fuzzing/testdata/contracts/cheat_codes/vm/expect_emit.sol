@@ -0,0 +1,40 @@
+// This test ensures that expected event emissions can be set with cheat codes and are met in order
+interface CheatCodes {
+    function expectEmit() external;
+    function expectEmit(bool, bool, bool, bool) external;
+    function expectEmit(bool, bool, bool, bool, address) external;
+}
+
+contract Token {
+    event Transfer(address indexed from, address indexed to, uint256 amount);
+    event Approval(address indexed owner, address indexed spender, uint256 amount);
+
+    function transfer(address to, uint256 amount) public {
+        emit Approval(msg.sender, to, amount);
+        emit Transfer(msg.sender, to, amount);
+    }
+}
+
+contract TestContract {
+    event Transfer(address indexed from, address indexed to, uint256 amount);
+    event Approval(address indexed owner, address indexed spender, uint256 amount);
+
+    Token token = new Token();
+
+    function test(address to, uint256 amount) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Expect a transfer with all topics and data checked, from our token.
+        cheats.expectEmit(true, true, true, true, address(token));
+        emit Transfer(address(this), to, amount);
+        token.transfer(to, amount);
+
+        // Expect an approval followed by a transfer, ignoring the recipient and amount of the transfer.
+        cheats.expectEmit();
+        emit Approval(address(this), to, amount);
+        cheats.expectEmit(true, false, false, false);
+        emit Transfer(address(this), address(0), 0);
+        token.transfer(to, amount);
+    }
+}
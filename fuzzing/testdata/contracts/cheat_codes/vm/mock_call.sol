@@ -0,0 +1,36 @@
+// This test ensures that calls can be mocked with cheat codes
+interface CheatCodes {
+    function mockCall(address, bytes calldata, bytes calldata) external;
+    function mockCall(address, uint256, bytes calldata, bytes calldata) external;
+    function clearMockedCalls() external;
+    function deal(address, uint256) external;
+}
+
+contract Oracle {
+    function price() public payable returns (uint256) {
+        return 1;
+    }
+}
+
+contract TestContract {
+    Oracle oracle = new Oracle();
+
+    function test(uint256 x) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Mock our oracle price and verify it is returned.
+        cheats.mockCall(address(oracle), abi.encodeWithSelector(Oracle.price.selector), abi.encode(x));
+        assert(oracle.price() == x);
+
+        // Mock our oracle price for a specific call value and verify it is preferred.
+        cheats.deal(address(this), 1);
+        cheats.mockCall(address(oracle), 1, abi.encodeWithSelector(Oracle.price.selector), abi.encode(~x));
+        assert(oracle.price{value: 1}() == ~x);
+        assert(oracle.price() == x);
+
+        // Clear our mocks and verify the original price is returned.
+        cheats.clearMockedCalls();
+        assert(oracle.price() == 1);
+    }
+}
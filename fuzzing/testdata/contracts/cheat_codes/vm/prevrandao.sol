@@ -0,0 +1,17 @@
+// This test ensures that the block's random value can be set with cheat codes
+interface CheatCodes {
+    function prevrandao(bytes32) external;
+}
+
+contract TestContract {
+    function test(bytes32 x) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Change value and verify. Since the merge, block.difficulty returns the block's random value.
+        cheats.prevrandao(x);
+        assert(block.difficulty == uint256(x));
+        cheats.prevrandao(bytes32(uint256(7)));
+        assert(block.difficulty == 7);
+    }
+}
@@ -1,4 +1,5 @@
-// This test ensures that the block number can be set with cheat codes
+// This test ensures that the block number can be set with cheat codes, but not to or before the parent block's, or
+// beyond the range of block numbers
 interface CheatCodes {
     function roll(uint256) external;
 }
@@ -7,11 +8,26 @@ contract TestContract {
     function test(uint256 x) public {
         // Obtain our cheat code contract reference.
         CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+        uint256 original = block.number;
 
-        // Change value and verify.
-        cheats.roll(x);
-        assert(block.number == x);
-        cheats.roll(7);
-        assert(block.number == 7);
+        // Change value and verify, if it does not precede the current block and is within range.
+        if (x >= original && x <= type(uint64).max) {
+            cheats.roll(x);
+            assert(block.number == x);
+        }
+        cheats.roll(original + 7);
+        assert(block.number == original + 7);
+
+        // Verify we cannot roll back to the genesis block, or beyond the range of block numbers.
+        try cheats.roll(0) {
+            assert(false);
+        } catch {
+            assert(block.number == original + 7);
+        }
+        try cheats.roll(uint256(type(uint64).max) + 1) {
+            assert(false);
+        } catch {
+            assert(block.number == original + 7);
+        }
     }
 }
@@ -0,0 +1,46 @@
+// This test ensures that state and block context can be snapshotted and reverted to with cheat codes
+interface CheatCodes {
+    function snapshot() external returns (uint256);
+    function revertTo(uint256) external returns (bool);
+    function warp(uint64) external;
+    function roll(uint256) external;
+}
+
+contract TestContract {
+    uint x = 1;
+
+    function test(uint64 timestamp) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Take a snapshot, change our state and block context, then revert and verify it was restored.
+        uint originalTimestamp = block.timestamp;
+        uint originalNumber = block.number;
+        uint snapshotId = cheats.snapshot();
+        x = 2;
+        cheats.warp(uint64(originalTimestamp) + 1 + timestamp % 1000);
+        cheats.roll(originalNumber + 100);
+        assert(x == 2);
+        assert(cheats.revertTo(snapshotId));
+        assert(x == 1);
+        assert(block.timestamp == originalTimestamp);
+        assert(block.number == originalNumber);
+
+        // Verify we can revert to the same snapshot again.
+        x = 3;
+        assert(cheats.revertTo(snapshotId));
+        assert(x == 1);
+
+        // Verify snapshots taken after the one reverted to are invalidated.
+        uint laterSnapshotId = cheats.snapshot();
+        assert(cheats.revertTo(snapshotId));
+        try cheats.revertTo(laterSnapshotId) {
+            assert(false);
+        } catch {}
+
+        // Verify unknown snapshot IDs revert.
+        try cheats.revertTo(laterSnapshotId + 1000) {
+            assert(false);
+        } catch {}
+    }
+}
@@ -1,4 +1,4 @@
-// This test ensures that the block timestamp can be set with cheat codes
+// This test ensures that the block timestamp can be set with cheat codes, but not to or before the parent block's
 interface CheatCodes {
     function warp(uint64) external;
 }
@@ -7,13 +7,23 @@ contract TestContract {
     function test(uint64 x) public {
         // Obtain our cheat code contract reference.
         CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+        uint64 original = uint64(block.timestamp);
 
-        // Change value and verify.
-        cheats.warp(x);
-        assert(block.timestamp == x);
-        cheats.warp(7);
-        assert(block.timestamp == 7);
-        cheats.warp(9);
-        assert(block.timestamp == 9);
+        // Change value and verify, if it does not precede the current block.
+        if (x >= original) {
+            cheats.warp(x);
+            assert(block.timestamp == x);
+        }
+        cheats.warp(original + 7);
+        assert(block.timestamp == original + 7);
+        cheats.warp(original + 9);
+        assert(block.timestamp == original + 9);
+
+        // Verify we cannot warp back to the genesis block's timestamp.
+        try cheats.warp(0) {
+            assert(false);
+        } catch {
+            assert(block.timestamp == original + 9);
+        }
     }
 }
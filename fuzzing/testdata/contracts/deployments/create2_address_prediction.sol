@@ -0,0 +1,30 @@
+// Create2AddressPrediction deploys Create2Child through CREATE2, and fails its test if it is provided the address the
+// child is deployed at before the child exists. As the chain is reverted after each call sequence, this verifies the
+// fuzzer learns the addresses of contracts created through CREATE2, and provides them in later call sequences.
+contract Create2Child {
+}
+
+contract Create2AddressPrediction {
+    bool providedBeforeCreation;
+
+    function deploy() public {
+        new Create2Child{salt: bytes32(uint256(1))}();
+    }
+
+    function provide(address target) public {
+        bytes32 initCodeHash = keccak256(type(Create2Child).creationCode);
+        address predicted = address(uint160(uint256(keccak256(abi.encodePacked(bytes1(0xff), address(this), bytes32(uint256(1)), initCodeHash)))));
+        uint size;
+        assembly {
+            size := extcodesize(target)
+        }
+        if (target == predicted && size == 0) {
+            providedBeforeCreation = true;
+        }
+    }
+
+    function fuzz_provided_before_creation() public view returns (bool) {
+        // ASSERTION: Fail if we were provided the address of our child before it was created.
+        return !providedBeforeCreation;
+    }
+}
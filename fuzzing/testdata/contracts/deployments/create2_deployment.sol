@@ -0,0 +1,38 @@
+// Create2Factory deploys the init bytecode following the salt in its call data through CREATE2, as the deterministic
+// deployment proxy does. Create2Deployment is deployed through it, and fails its test if it was deployed by a
+// contract, verifying the fuzzer deployed it through CREATE2 rather than from its deployer directly.
+contract Create2Factory {
+    fallback() external {
+        assembly {
+            let size := sub(calldatasize(), 32)
+            calldatacopy(0, 32, size)
+            let deployed := create2(0, 0, size, calldataload(0))
+            if iszero(deployed) {
+                revert(0, 0)
+            }
+        }
+    }
+}
+
+contract Create2Deployment {
+    address deployer;
+
+    constructor() public {
+        deployer = msg.sender;
+    }
+
+    function dummyFunction(uint x) public {
+        // This exists so the fuzzer knows there are state changing methods to target, instead of quitting early.
+        x = 7;
+    }
+
+    function fuzz_deployed_by_contract() public view returns (bool) {
+        // ASSERTION: Fail if we were deployed by a contract.
+        uint size;
+        address _deployer = deployer;
+        assembly {
+            size := extcodesize(_deployer)
+        }
+        return size == 0;
+    }
+}
@@ -0,0 +1,20 @@
+// This contract is used to test deployment of contracts with constructor arguments generated by the fuzzer.
+contract DeploymentWithFuzzedArgs {
+    uint24 fee;
+    address owner;
+
+    constructor(uint24 _fee, address _owner) {
+        fee = _fee;
+        owner = _owner;
+    }
+
+    function fuzz_checkOwner() public returns (bool) {
+        // This fails, indicating the config-provided owner was set.
+        return owner != 0x0000000000000000000000000000000000001234;
+    }
+
+    function dummyFunction(uint a) public {
+        // This exists so the fuzzer knows there are state changing methods to target, instead of quitting early.
+        a = 8;
+    }
+}
@@ -0,0 +1,52 @@
+// This contract is used to test deployment of contracts whose constructor arguments reference previously deployed
+// contracts, including references nested within arrays and structs.
+contract Token {
+    function dummyFunction(uint a) public {
+        // This exists so the fuzzer knows there are state changing methods to target, instead of quitting early.
+        a = 8;
+    }
+}
+
+contract Oracle {
+    function dummyFunction(uint a) public {
+        // This exists so the fuzzer knows there are state changing methods to target, instead of quitting early.
+        a = 8;
+    }
+}
+
+contract Pool {
+    struct Config {
+        address token;
+        address[] oracles;
+    }
+
+    address token;
+    address[2] oracles;
+    Config config;
+
+    constructor(address _token, address[2] memory _oracles, Config memory _config) {
+        token = _token;
+        oracles = _oracles;
+        config = _config;
+    }
+
+    function fuzz_checkToken() public returns (bool) {
+        // This should fail if the token reference was resolved.
+        return token.code.length == 0;
+    }
+
+    function fuzz_checkOracles() public returns (bool) {
+        // This should fail if the oracle references within the array were resolved.
+        return oracles[0].code.length == 0 || oracles[1] != token;
+    }
+
+    function fuzz_checkConfig() public returns (bool) {
+        // This should fail if the references within the struct were resolved.
+        return config.token != token || config.oracles.length != 1 || config.oracles[0] != oracles[0];
+    }
+
+    function dummyFunction(uint a) public {
+        // This exists so the fuzzer knows there are state changing methods to target, instead of quitting early.
+        a = 8;
+    }
+}
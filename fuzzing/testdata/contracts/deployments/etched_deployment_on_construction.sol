@@ -0,0 +1,31 @@
+// EtchedDeploymentFactory etches the code of an EtchedDeployment at a fixed address on construction and verifies the
+// fuzzer can match its bytecode and fail the test appropriately.
+interface CheatCodes {
+    function etch(address, bytes calldata) external;
+}
+
+contract EtchedDeployment {
+    function dummyFunction(uint x) public {
+        // This exists so the fuzzer knows there are state changing methods to target, instead of quitting early.
+        x = 7;
+    }
+
+    function fuzz_etched_deployment() public view returns (bool) {
+        // ASSERTION: Fail immediately.
+        return false;
+    }
+}
+
+contract EtchedDeploymentFactory {
+    address a = address(0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48);
+
+    constructor() public {
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+        cheats.etch(a, type(EtchedDeployment).runtimeCode);
+    }
+
+    function dummyFunction(uint x) public {
+        // This exists so the fuzzer knows there are state changing methods to target, instead of quitting early.
+        x = 8;
+    }
+}
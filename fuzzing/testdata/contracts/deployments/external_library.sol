@@ -0,0 +1,36 @@
+// This contract ensures the fuzzer deploys and links external libraries, including libraries which reference other
+// libraries.
+library MathLibrary {
+    function double(uint x) external pure returns (uint) {
+        return x * 2;
+    }
+}
+
+library WrappedMathLibrary {
+    function quadruple(uint x) external pure returns (uint) {
+        return MathLibrary.double(MathLibrary.double(x));
+    }
+}
+
+contract TestExternalLibrary {
+    bool libraryCalled;
+    bool failedTest;
+
+    function testQuadruple(uint x) public {
+        require(x < 1000);
+        if (WrappedMathLibrary.quadruple(x) != x * 4) {
+            failedTest = true;
+        }
+        libraryCalled = true;
+    }
+
+    function fuzz_library_linking_broken() public view returns (bool) {
+        // ASSERTION: We should always be able to compute correctly.
+        return !failedTest;
+    }
+
+    function fuzz_library_called() public view returns (bool) {
+        // ASSERTION: Calls to our library should succeed once it is linked, so this should fail.
+        return !libraryCalled;
+    }
+}
@@ -0,0 +1,42 @@
+// ImmutableDeploymentFactory deploys ImmutableDeployment, which stores immutable variables, with CREATE2 when a method
+// is called after deployment, and etches a copy of its deployed code on construction. This verifies the fuzzer can
+// match the bytecode of contracts whose immutable values were substituted into their runtime code, and fail the test
+// appropriately.
+interface CheatCodes {
+    function etch(address, bytes calldata) external;
+}
+
+contract ImmutableDeployment {
+    uint256 public immutable value;
+    address public immutable factory;
+
+    constructor(uint256 _value) public {
+        value = _value;
+        factory = msg.sender;
+    }
+
+    function dummyFunction(uint x) public {
+        // This exists so the fuzzer knows there are state changing methods to target, instead of quitting early.
+        x = value;
+    }
+
+    function fuzz_immutable_deployment() public view returns (bool) {
+        // ASSERTION: Fail immediately.
+        return false;
+    }
+}
+
+contract ImmutableDeploymentFactory {
+    address etched = address(0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48);
+
+    constructor() public {
+        // Etch the runtime code of a deployment, so it can only be matched by its runtime code.
+        ImmutableDeployment deployment = new ImmutableDeployment(7);
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+        cheats.etch(etched, address(deployment).code);
+    }
+
+    function deployImmutable(bytes32 salt, uint256 _value) public returns (address) {
+        return address(new ImmutableDeployment{salt: salt}(_value));
+    }
+}
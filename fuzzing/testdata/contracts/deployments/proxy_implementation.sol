@@ -0,0 +1,67 @@
+// FlagImplementation is called through proxies, which fail their test once the flag stored in their storage is set.
+// EIP1967Proxy stores its implementation in its EIP-1967 implementation slot, while CustomProxy requires its
+// implementation to be configured. This verifies the fuzzer calls the methods of a proxy's implementation through it.
+contract FlagImplementation {
+    bool flagged;
+
+    function setFlag(uint x) public {
+        if (x == 7) {
+            flagged = true;
+        }
+    }
+}
+
+contract EIP1967Proxy {
+    bool flagged;
+
+    constructor(address _implementation) public {
+        assembly {
+            sstore(0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc, _implementation)
+        }
+    }
+
+    fallback() external payable {
+        assembly {
+            let implementation := sload(0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc)
+            calldatacopy(0, 0, calldatasize())
+            let success := delegatecall(gas(), implementation, 0, calldatasize(), 0, 0)
+            returndatacopy(0, 0, returndatasize())
+            if iszero(success) {
+                revert(0, returndatasize())
+            }
+            return(0, returndatasize())
+        }
+    }
+
+    function fuzz_not_flagged() public view returns (bool) {
+        // ASSERTION: Fail once our implementation set our flag.
+        return !flagged;
+    }
+}
+
+contract CustomProxy {
+    bool flagged;
+    address implementation;
+
+    constructor(address _implementation) public {
+        implementation = _implementation;
+    }
+
+    fallback() external payable {
+        address _implementation = implementation;
+        assembly {
+            calldatacopy(0, 0, calldatasize())
+            let success := delegatecall(gas(), _implementation, 0, calldatasize(), 0, 0)
+            returndatacopy(0, 0, returndatasize())
+            if iszero(success) {
+                revert(0, returndatasize())
+            }
+            return(0, returndatasize())
+        }
+    }
+
+    function fuzz_not_flagged() public view returns (bool) {
+        // ASSERTION: Fail once our implementation set our flag.
+        return !flagged;
+    }
+}
@@ -0,0 +1,48 @@
+// This contract is used to test deployment through a setup contract, which deploys and wires the contracts to test.
+contract Token {
+    mapping(address => uint) public balanceOf;
+
+    function mint(address to, uint amount) public {
+        balanceOf[to] += amount;
+    }
+}
+
+contract Vault {
+    Token token;
+    bool initialized;
+
+    function initialize(Token _token) public {
+        token = _token;
+        initialized = true;
+    }
+
+    function fuzz_checkInitialized() public returns (bool) {
+        // This should fail if the setup contract initialized the vault.
+        return !initialized;
+    }
+
+    function fuzz_checkMinted() public returns (bool) {
+        // This should fail if the setup contract minted tokens to the vault.
+        return token.balanceOf(address(this)) == 0;
+    }
+}
+
+contract Setup {
+    Token token;
+    Vault vault;
+
+    constructor() {
+        token = new Token();
+    }
+
+    function setUp() public {
+        vault = new Vault();
+        vault.initialize(token);
+        token.mint(address(vault), 1000);
+    }
+
+    function fuzz_checkSetup() public returns (bool) {
+        // This should never fail, and only exists so the setup contract has a property to test.
+        return true;
+    }
+}
@@ -0,0 +1,33 @@
+// This contract ensures the fuzzer mirrors state changing calls between a subject and reference implementation, and
+// detects when their results diverge.
+contract ReferenceImplementation {
+    uint256 total;
+
+    function add(uint256 value) public {
+        if (value < 1_000_000) {
+            total += value;
+        }
+    }
+
+    function doubled() public view returns (uint256) {
+        return total * 2;
+    }
+}
+
+contract SubjectImplementation {
+    uint256 total;
+
+    function add(uint256 value) public {
+        if (value < 1_000_000) {
+            total += value;
+        }
+    }
+
+    function doubled() public view returns (uint256) {
+        // BUG: The optimized implementation diverges once the total is large enough.
+        if (total > 5_000) {
+            return total << 2;
+        }
+        return total << 1;
+    }
+}
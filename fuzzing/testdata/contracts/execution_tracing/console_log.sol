@@ -0,0 +1,32 @@
+// This contract logs messages through console.log before failing an assertion, so the messages should be shown inline
+// in the execution trace of the failing call.
+library console {
+    address constant CONSOLE_ADDRESS = address(0x000000000000000000636F6e736F6c652e6c6f67);
+
+    function _sendLogPayload(bytes memory payload) private view {
+        address consoleAddress = CONSOLE_ADDRESS;
+        assembly {
+            let payloadStart := add(payload, 32)
+            let payloadLength := mload(payload)
+            let r := staticcall(gas(), consoleAddress, payloadStart, payloadLength, 0, 0)
+        }
+    }
+
+    function log(string memory p0) internal view {
+        _sendLogPayload(abi.encodeWithSignature("log(string)", p0));
+    }
+
+    function log(string memory p0, uint256 p1) internal view {
+        _sendLogPayload(abi.encodeWithSignature("log(string,uint256)", p0, p1));
+    }
+}
+
+contract TestContract {
+    function testConsoleLogAndFail(uint256 x) public {
+        console.log("Hello from console.log!");
+        console.log("x is %d", x);
+
+        // Fail test immediately.
+        assert(false);
+    }
+}
@@ -0,0 +1,24 @@
+// This contract ensures the fuzzer's execution tracing can describe panics other than assertion failures
+
+// Define the contract to panic on arithmetic overflow.
+contract OverflowContract {
+    function addToMax(uint8 x) public returns (uint8) {
+        uint8 max = type(uint8).max;
+        return max + x;
+    }
+}
+
+contract TestContract {
+    OverflowContract oc;
+    constructor() {
+        oc = new OverflowContract();
+    }
+
+    function assertIfPanicEncountered(uint8 value) public {
+        try oc.addToMax(value) {
+            return;
+        } catch Panic(uint /*code*/) {
+            assert(false);
+        }
+    }
+}
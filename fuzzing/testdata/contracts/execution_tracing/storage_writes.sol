@@ -0,0 +1,9 @@
+// This contract ensures the fuzzer's execution tracing can record storage writes when configured to.
+contract TestContract {
+    uint x;
+
+    function storeAndAssert(uint value) public {
+        x = value;
+        assert(value != 7);
+    }
+}
@@ -0,0 +1,18 @@
+// This contract ensures the fuzzer can find distinct failures of a property test which continues to be tested after
+// it fails. The property fails once either flag is set, which is reached by two distinct call sequences.
+contract TestContract {
+    bool a;
+    bool b;
+
+    function setA() public {
+        a = true;
+    }
+
+    function setB() public {
+        b = true;
+    }
+
+    function fuzz_neither_set() public view returns (bool) {
+        return !a && !b;
+    }
+}
@@ -0,0 +1,23 @@
+// This contract ensures the fuzzer tests Foundry-style invariant functions after every call, without a property test
+// prefix, failing them when they return false or revert.
+contract TestContract {
+    uint256 x;
+    uint256 y;
+
+    function setX(uint256 value) public {
+        x = value;
+    }
+
+    function setY(uint256 value) public {
+        y = value;
+    }
+
+    function invariant_xBelowLimit() external view returns (bool) {
+        return x < 1000;
+    }
+
+    function invariant_yDoesNotRevert() external view returns (bool) {
+        require(y < 1000);
+        return true;
+    }
+}
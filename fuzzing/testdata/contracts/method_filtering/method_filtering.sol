@@ -0,0 +1,35 @@
+// This contract is used to test that function signature filters restrict the methods the fuzzer calls. Each method
+// breaks a property if it is called.
+contract TestContract {
+    bool unlocked;
+    bool adminCalled;
+    bool valueSet;
+
+    function unlock() public {
+        unlocked = true;
+    }
+
+    function admin_setOwner(address owner) public {
+        adminCalled = true;
+    }
+
+    function admin_pause() public {
+        adminCalled = true;
+    }
+
+    function setValue(uint value) public {
+        valueSet = true;
+    }
+
+    function fuzz_neverUnlocked() public view returns (bool) {
+        return !unlocked;
+    }
+
+    function fuzz_neverAdminCalled() public view returns (bool) {
+        return !adminCalled;
+    }
+
+    function fuzz_neverValueSet() public view returns (bool) {
+        return !valueSet;
+    }
+}
@@ -0,0 +1,23 @@
+// This contract ensures the fuzzer reports any revert of a method which must never revert, whether it is selected by
+// name prefix or by signature.
+contract TestContract {
+    uint256 shares = 100;
+
+    function neverRevert_bounded(uint256 value) public pure returns (uint256) {
+        // This reverts with a reason string for large values.
+        require(value <= 1000, "value too large");
+        return value;
+    }
+
+    function neverRevert_safe(uint256 value) public pure returns (uint256) {
+        // This never reverts.
+        unchecked {
+            return value + 1;
+        }
+    }
+
+    function previewWithdraw(uint256 assets) public view returns (uint256) {
+        // This results in a division by zero panic when no assets are provided.
+        return shares / assets;
+    }
+}
@@ -0,0 +1,18 @@
+// This contract ensures the fuzzer can maximize the gas used by calls to a gas optimization target.
+contract TestContract {
+    uint256 counter;
+
+    function work(uint8 iterations) public {
+        // The number of iterations is bounded, so the most gas is used by any call with at least 50 iterations.
+        if (iterations > 50) {
+            iterations = 50;
+        }
+        for (uint8 i = 0; i < iterations; i++) {
+            counter += i;
+        }
+    }
+
+    function reset() public {
+        counter = 0;
+    }
+}
@@ -0,0 +1,19 @@
+// This contract ensures the fuzzer can maximize and minimize the values returned by optimization tests concurrently.
+contract TestContract {
+    int256 x;
+
+    function set(int256 value) public {
+        // Only values within a bounded range are accepted, so the optimum values are known.
+        if (value >= -100 && value <= 100) {
+            x = value;
+        }
+    }
+
+    function optimize_x() public view returns (int256) {
+        return x;
+    }
+
+    function optimize_min_x() public view returns (int256) {
+        return x;
+    }
+}
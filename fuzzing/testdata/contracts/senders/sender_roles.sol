@@ -0,0 +1,20 @@
+// This contract is used to test senders configured with starting balances and roles. The admin breaks a property if
+// it calls the privileged method, so the property only fails if the admin is allowed to call it.
+contract TestContract {
+    bool adminCalledPrivileged;
+
+    function privileged() public {
+        if (msg.sender == address(0x40000)) {
+            adminCalledPrivileged = true;
+        }
+    }
+
+    function fuzz_adminNeverCalledPrivileged() public view returns (bool) {
+        return !adminCalledPrivileged;
+    }
+
+    function fuzz_attackerBalance() public view returns (bool) {
+        // This should fail if the attacker was funded with its configured balance.
+        return address(0x50000).balance != 1234;
+    }
+}
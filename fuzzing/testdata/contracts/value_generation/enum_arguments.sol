@@ -0,0 +1,27 @@
+// This contract verifies the fuzzer provides enum arguments which are generally within the range of the enum members.
+contract TestContract {
+    enum Color { Red, Green, Blue }
+    enum Size { Small, Medium, Large, ExtraLarge, Huge }
+
+    Color color;
+    Size size;
+
+    function setColor(Color value) public {
+        color = value;
+    }
+
+    function setSize(Size value) public {
+        size = value;
+    }
+
+    function setColorAndSize(Color colorValue, uint8 notAnEnum, Size sizeValue) public {
+        color = colorValue;
+        size = sizeValue;
+    }
+
+    function setColors(Color[] calldata values) public {
+        if (values.length > 0) {
+            color = values[0];
+        }
+    }
+}
@@ -0,0 +1,21 @@
+// This contract verifies the fuzzer can provide a magic value which is computed at runtime, and therefore cannot be
+// found in the contract's bytecode or AST, by learning the operands of the comparison which guards it.
+contract TestContract {
+    uint256 magic;
+    bool opened;
+
+    constructor() {
+        magic = uint256(keccak256(abi.encodePacked(address(this), "magic")));
+    }
+
+    function openGate(uint256 value) public {
+        if (value == magic) {
+            opened = true;
+        }
+    }
+
+    function fuzz_gate_never_opened() public view returns (bool) {
+        // ASSERTION: the gate should never be opened
+        return !opened;
+    }
+}
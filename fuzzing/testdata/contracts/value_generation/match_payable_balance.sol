@@ -0,0 +1,25 @@
+// This contract verifies the fuzzer sends payable calls with round amounts of ether, as well as with the full balance
+// of the sender (such that it has nothing left to spend on gas).
+contract TestContract {
+    bool paidRoundAmount;
+    bool paidFullBalance;
+
+    function pay() public payable {
+        if (msg.value == 100 ether) {
+            paidRoundAmount = true;
+        }
+        if (msg.value > 0 && msg.sender.balance == 0) {
+            paidFullBalance = true;
+        }
+    }
+
+    function fuzz_never_pay_round_amount() public view returns (bool) {
+        // ASSERTION: the amount paid should never be exactly 100 ether.
+        return !paidRoundAmount;
+    }
+
+    function fuzz_never_pay_full_balance() public view returns (bool) {
+        // ASSERTION: the sender should never pay its full balance.
+        return !paidFullBalance;
+    }
+}
@@ -0,0 +1,32 @@
+// This contract verifies the fuzzer can provide a valid 65-byte encoded signature over a raw hash.
+contract TestContract {
+    // The address of the default signer private key 0x1.
+    address constant signer = 0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf;
+
+    bool verified;
+
+    function verify(bytes32 messageHash, bytes memory signature) public {
+        if (signature.length != 65) {
+            return;
+        }
+
+        // Split our signature into its components.
+        bytes32 r;
+        bytes32 s;
+        uint8 v;
+        assembly {
+            r := mload(add(signature, 32))
+            s := mload(add(signature, 64))
+            v := byte(0, mload(add(signature, 96)))
+        }
+
+        if (ecrecover(messageHash, v, r, s) == signer) {
+            verified = true;
+        }
+    }
+
+    function fuzz_never_verified() public view returns (bool) {
+        // ASSERTION: we should never see a valid signature from our signer.
+        return !verified;
+    }
+}
@@ -0,0 +1,19 @@
+// This contract verifies the fuzzer can provide a valid (v, r, s) signature over an EIP-191 signed message hash.
+contract TestContract {
+    // The address of the default signer private key 0x1.
+    address constant signer = 0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf;
+
+    bool verified;
+
+    function verify(bytes32 hash, uint8 v, bytes32 r, bytes32 s) public {
+        bytes32 digest = keccak256(abi.encodePacked("\x19Ethereum Signed Message:\n32", hash));
+        if (ecrecover(digest, v, r, s) == signer) {
+            verified = true;
+        }
+    }
+
+    function fuzz_never_verified() public view returns (bool) {
+        // ASSERTION: we should never see a valid signature from our signer.
+        return !verified;
+    }
+}
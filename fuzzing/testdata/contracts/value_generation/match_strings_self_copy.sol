@@ -0,0 +1,15 @@
+// This contract verifies the fuzzer can provide the same long value for multiple arguments of the same call.
+contract TestContract {
+    bool matched;
+
+    function setPair(string memory a, uint256 n, string memory b) public {
+        if (bytes(a).length >= 20 && keccak256(bytes(a)) == keccak256(bytes(b))) {
+            matched = true;
+        }
+    }
+
+    function fuzz_never_matching_strings() public view returns (bool) {
+        // ASSERTION: a and b should never be the same long string.
+        return !matched;
+    }
+}
@@ -0,0 +1,22 @@
+// This contract verifies the fuzzer can provide timestamp and duration arguments near the current block timestamp.
+contract TestContract {
+    bool deadlineHit;
+    bool durationHit;
+
+    function setDeadline(uint256 deadline) public {
+        if (deadline > block.timestamp && deadline <= block.timestamp + 1 hours) {
+            deadlineHit = true;
+        }
+    }
+
+    function setLockDuration(uint64 lockDuration) public {
+        if (lockDuration >= 1 days && lockDuration <= 7 days) {
+            durationHit = true;
+        }
+    }
+
+    function fuzz_never_near_timestamps() public view returns (bool) {
+        // ASSERTION: we should never see a deadline within the next hour, and a duration between one and seven days.
+        return !(deadlineHit && durationHit);
+    }
+}
@@ -7,16 +7,27 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/crytic/medusa/compilation/types"
 	"github.com/crytic/medusa/utils/reflectionutils"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/slices"
 )
 
 // addressJSONContractNameOverridePrefix defines a string prefix which is to be followed by a contract name. The
 // contract address will be resolved by searching the deployed contracts for a contract with this name.
 const addressJSONContractNameOverridePrefix = "DeployedContract:"
 
+// stringJSONHexEncodedPrefix defines a string prefix which is followed by hex-encoded string content. Strings which
+// are not valid UTF-8 would otherwise be corrupted when serialized as JSON, so they are encoded in this form instead.
+const stringJSONHexEncodedPrefix = "hex:"
+
+// enumOutOfRangeProbability defines the probability that an enum argument is given a value outside the range of its
+// members, so the revert path of the implicit bounds check is still tested.
+const enumOutOfRangeProbability = 0.05
+
 // GenerateAbiValue generates a value of the provided abi.Type using the provided ValueGenerator.
 // The generated value is returned.
 func GenerateAbiValue(generator ValueGenerator, inputType *abi.Type) any {
@@ -88,11 +99,16 @@ func GenerateAbiValue(generator ValueGenerator, inputType *abi.Type) any {
 			reflectionutils.SetField(field, fieldValue)
 		}
 		return st.Interface()
+	case abi.FunctionTy:
+		// Function types are represented as a 24-byte array, composed of a 20-byte address and a 4-byte selector.
+		// We generate each part independently, so generators can use known contract addresses and selectors.
+		return encodeFunctionTypeValue(generator.GenerateAddress(), generator.GenerateFixedBytes(4))
 	default:
 		// Unexpected types will result in a panic as we should support these values as soon as possible:
 		// - Mappings cannot be used in public/external methods and must reference storage, so we shouldn't ever
 		//	 see cases of it unless Solidity was updated in the future.
-		// - FixedPoint types are currently unsupported.
+		// - FixedPoint types are currently unsupported. ABI entries using them are skipped when contract ABIs are
+		//	 parsed, so they are never provided.
 		panic(fmt.Sprintf("attempt to generate function argument of unsupported type: '%s'", inputType.String()))
 	}
 }
@@ -208,26 +224,21 @@ func MutateAbiValue(generator ValueGenerator, inputType *abi.Type, value any) (a
 
 		// Mutate our array structure first
 		mutatedValues := generator.MutateArray(reflectionutils.GetReflectedArrayValues(array), true)
+		if len(mutatedValues) != inputType.Size {
+			return nil, fmt.Errorf("could not mutate array input as the mutated value returned was not of the correct length. expected %v, got %v", inputType.Size, len(mutatedValues))
+		}
 
-		// Create a new array of the appropriate size
-		array = reflect.New(reflect.ArrayOf(array.Len(), array.Type().Elem())).Elem()
+		// Create a new array of the exact type expected for our ABI type. Values loaded from elsewhere (e.g. the corpus)
+		// may have element types which differ from it, which would later fail to pack.
+		array = reflect.New(inputType.GetType()).Elem()
 
 		// Next mutate each element in the array.
 		for i := 0; i < array.Len(); i++ {
-			// Obtain the element's reflected value to access its getter/setters
-			reflectedElement := array.Index(i)
-
-			// If any item is nil, we generate a new element in its place instead. Otherwise, we mutate the existing value.
-			if mutatedValues[i] == nil {
-				generatedElement := GenerateAbiValue(generator, inputType.Elem)
-				reflectedElement.Set(reflect.ValueOf(generatedElement))
-			} else {
-				mutatedElement, err := MutateAbiValue(generator, inputType.Elem, mutatedValues[i])
-				if err != nil {
-					return nil, fmt.Errorf("could not mutate array input as the value generator encountered an error: %v", err)
-				}
-				reflectedElement.Set(reflect.ValueOf(mutatedElement))
+			element, err := mutateAbiArrayElement(generator, inputType.Elem, mutatedValues[i], array.Type().Elem())
+			if err != nil {
+				return nil, fmt.Errorf("could not mutate array input as the value generator encountered an error: %v", err)
 			}
+			array.Index(i).Set(element)
 		}
 
 		return array.Interface(), nil
@@ -239,25 +250,16 @@ func MutateAbiValue(generator ValueGenerator, inputType *abi.Type, value any) (a
 		// Mutate our slice structure first
 		mutatedValues := generator.MutateArray(reflectionutils.GetReflectedArrayValues(slice), false)
 
-		// Create a new slice of the appropriate size
-		slice = reflect.MakeSlice(reflect.SliceOf(slice.Type().Elem()), len(mutatedValues), len(mutatedValues))
+		// Create a new slice of the appropriate size, of the exact type expected for our ABI type.
+		slice = reflect.MakeSlice(inputType.GetType(), len(mutatedValues), len(mutatedValues))
 
 		// Next mutate each element in the slice.
 		for i := 0; i < slice.Len(); i++ {
-			// Obtain the element's reflected value to access its getter/setters
-			reflectedElement := slice.Index(i)
-
-			// If any item is nil, we generate a new element in its place instead. Otherwise, we mutate the existing value.
-			if mutatedValues[i] == nil {
-				generatedElement := GenerateAbiValue(generator, inputType.Elem)
-				reflectedElement.Set(reflect.ValueOf(generatedElement))
-			} else {
-				mutatedElement, err := MutateAbiValue(generator, inputType.Elem, mutatedValues[i])
-				if err != nil {
-					return nil, fmt.Errorf("could not mutate slice input as the value generator encountered an error: %v", err)
-				}
-				reflectedElement.Set(reflect.ValueOf(mutatedElement))
+			element, err := mutateAbiArrayElement(generator, inputType.Elem, mutatedValues[i], slice.Type().Elem())
+			if err != nil {
+				return nil, fmt.Errorf("could not mutate slice input as the value generator encountered an error: %v", err)
 			}
+			slice.Index(i).Set(element)
 		}
 		return slice.Interface(), nil
 	case abi.TupleTy:
@@ -271,14 +273,178 @@ func MutateAbiValue(generator ValueGenerator, inputType *abi.Type, value any) (a
 			if err != nil {
 				return nil, fmt.Errorf("could not mutate struct/tuple input as the value generator encountered an error: %v", err)
 			}
-			reflectionutils.SetField(field, mutatedValue)
+			convertedValue, err := reflectionutils.ConvertReflectedValue(mutatedValue, field.Type())
+			if err != nil {
+				return nil, fmt.Errorf("could not mutate struct/tuple input as the mutated field could not be converted: %v", err)
+			}
+			reflectionutils.SetField(field, convertedValue.Interface())
 		}
 		return tuple.Interface(), nil
+	case abi.FunctionTy:
+		// Split our function type into its address and selector, mutate each independently, and rejoin them.
+		v, ok := value.([24]byte)
+		if !ok {
+			return nil, fmt.Errorf("could not mutate function input as the value provided is not a 24-byte array")
+		}
+		address, selector := decodeFunctionTypeValue(v)
+		mutatedSelector := generator.MutateFixedBytes(selector)
+		if len(mutatedSelector) != 4 {
+			return nil, fmt.Errorf("could not mutate function input as the mutated selector returned was not of the correct length. expected 4, got %v", len(mutatedSelector))
+		}
+		return encodeFunctionTypeValue(generator.MutateAddress(address), mutatedSelector), nil
 	default:
 		return nil, fmt.Errorf("could not mutate argument, type is unsupported: %v", inputType)
 	}
 }
 
+// mutateAbiArrayElement mutates an element of an array or slice of the provided element abi.Type, converting the
+// result to the provided element reflect.Type of its parent, so types do not drift through nested arrays and slices.
+// If the element is nil (e.g. it was inserted by a structural mutation), a new element is generated in its place.
+// Returns the reflected element, or an error if one occurs.
+func mutateAbiArrayElement(generator ValueGenerator, elementType *abi.Type, value any, elementReflectedType reflect.Type) (reflect.Value, error) {
+	// If the item is nil, we generate a new element in its place instead. Otherwise, we mutate the existing value.
+	var element any
+	if value == nil {
+		element = GenerateAbiValue(generator, elementType)
+	} else {
+		var err error
+		element, err = MutateAbiValue(generator, elementType, value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return reflectionutils.ConvertReflectedValue(element, elementReflectedType)
+}
+
+// GenerateEnumAbiValue generates a value for an enum argument with the provided number of members. Enums are encoded
+// as uint8 in the ABI. Values within the range of the enum are generated with high probability, while values outside
+// of it are still occasionally generated to exercise the revert path of the implicit bounds check.
+// The generated value is returned.
+func GenerateEnumAbiValue(generator ValueGenerator, memberCount int) uint8 {
+	// If we have no valid range, or have decided to test the out-of-range case, generate any uint8.
+	if memberCount <= 0 || memberCount > 256 || generator.RandomProvider().Float32() < enumOutOfRangeProbability {
+		return uint8(generator.GenerateInteger(false, 8).Uint64())
+	}
+	return uint8(generator.RandomProvider().Intn(memberCount))
+}
+
+// MutateEnumAbiValue mutates a value for an enum argument with the provided number of members. If the mutated value
+// falls outside the range of the enum, it is wrapped back into range with high probability.
+// Returns the mutated value.
+func MutateEnumAbiValue(generator ValueGenerator, memberCount int, value uint8) uint8 {
+	// Mutate our value as we would any uint8, then wrap it back into range.
+	mutated := uint8(generator.MutateInteger(new(big.Int).SetUint64(uint64(value)), false, 8).Uint64())
+	return wrapEnumAbiValue(generator, memberCount, mutated)
+}
+
+// wrapEnumAbiValue wraps a value for an enum argument with the provided number of members back into the range of the
+// enum if it falls outside of it, unless we decide to test the out-of-range case.
+// Returns the wrapped value.
+func wrapEnumAbiValue(generator ValueGenerator, memberCount int, value uint8) uint8 {
+	if memberCount > 0 && memberCount <= 256 && int(value) >= memberCount {
+		if generator.RandomProvider().Float32() >= enumOutOfRangeProbability {
+			value = uint8(int(value) % memberCount)
+		}
+	}
+	return value
+}
+
+// GenerateEnumBoundedAbiValue generates a value of the provided abi.Type using the provided ValueGenerator, where the
+// enums within the type, described by the provided bounds, are generally kept within the range of their members. This
+// includes enums nested within arrays or structs. If the bounds are nil, this is equivalent to GenerateAbiValue.
+// The generated value is returned.
+func GenerateEnumBoundedAbiValue(generator ValueGenerator, inputType *abi.Type, bounds *types.EnumBounds) any {
+	if bounds != nil && bounds.MemberCount > 0 && isEnumAbiType(inputType) {
+		return GenerateEnumAbiValue(generator, bounds.MemberCount)
+	}
+	return wrapEnumAbiValues(generator, inputType, GenerateAbiValue(generator, inputType), bounds)
+}
+
+// MutateEnumBoundedAbiValue mutates a value of the provided abi.Type using the provided ValueGenerator, where the
+// enums within the type, described by the provided bounds, are generally kept within the range of their members. This
+// includes enums nested within arrays or structs. If the bounds are nil, this is equivalent to MutateAbiValue.
+// Returns the mutated value, or an error if one occurs.
+func MutateEnumBoundedAbiValue(generator ValueGenerator, inputType *abi.Type, value any, bounds *types.EnumBounds) (any, error) {
+	if enumValue, ok := value.(uint8); ok && bounds != nil && bounds.MemberCount > 0 && isEnumAbiType(inputType) {
+		return MutateEnumAbiValue(generator, bounds.MemberCount, enumValue), nil
+	}
+	mutatedValue, err := MutateAbiValue(generator, inputType, value)
+	if err != nil {
+		return nil, err
+	}
+	return wrapEnumAbiValues(generator, inputType, mutatedValue, bounds), nil
+}
+
+// wrapEnumAbiValues walks a value of the provided abi.Type alongside the provided bounds describing the enums within
+// the type, wrapping each enum value found back into the range of its members (see wrapEnumAbiValue). Arrays, slices,
+// and structs are copied rather than altered in place.
+// Returns the value with its enum values wrapped.
+func wrapEnumAbiValues(generator ValueGenerator, inputType *abi.Type, value any, bounds *types.EnumBounds) any {
+	if bounds == nil || value == nil {
+		return value
+	}
+	switch inputType.T {
+	case abi.UintTy:
+		if enumValue, ok := value.(uint8); ok && bounds.MemberCount > 0 {
+			return wrapEnumAbiValue(generator, bounds.MemberCount, enumValue)
+		}
+	case abi.ArrayTy, abi.SliceTy:
+		if bounds.Elem == nil {
+			return value
+		}
+		array := reflectionutils.CopyReflectedType(reflect.ValueOf(value))
+		for i := 0; i < array.Len(); i++ {
+			element := wrapEnumAbiValues(generator, inputType.Elem, array.Index(i).Interface(), bounds.Elem)
+			array.Index(i).Set(reflect.ValueOf(element))
+		}
+		return array.Interface()
+	case abi.TupleTy:
+		if len(bounds.TupleElems) != len(inputType.TupleElems) {
+			return value
+		}
+		tuple := reflectionutils.CopyReflectedType(reflect.ValueOf(value))
+		for i := 0; i < len(inputType.TupleElems); i++ {
+			field := tuple.Field(i)
+			fieldValue := wrapEnumAbiValues(generator, inputType.TupleElems[i], reflectionutils.GetField(field), bounds.TupleElems[i])
+			reflectionutils.SetField(field, fieldValue)
+		}
+		return tuple.Interface()
+	}
+	return value
+}
+
+// isEnumAbiType indicates whether the provided abi.Type could represent an enum, which are encoded as uint8 in the
+// ABI.
+func isEnumAbiType(inputType *abi.Type) bool {
+	return inputType.T == abi.UintTy && inputType.Size == 8
+}
+
+// LimitAbiValueSize truncates a value of the provided abi.Type so its size does not exceed the provided limit, for
+// languages such as Vyper which bound the length of bytes and string arguments, and the element count of dynamic
+// array arguments, without describing these bounds in the ABI. Values of other types, or which do not exceed the
+// limit, are returned unchanged, as are all values if the limit is not positive.
+// Returns the value, truncated if it exceeded the limit.
+func LimitAbiValueSize(inputType *abi.Type, value any, limit int) any {
+	if limit <= 0 {
+		return value
+	}
+	switch inputType.T {
+	case abi.BytesTy:
+		if b, ok := value.([]byte); ok && len(b) > limit {
+			return b[:limit]
+		}
+	case abi.StringTy:
+		if s, ok := value.(string); ok && len(s) > limit {
+			return s[:limit]
+		}
+	case abi.SliceTy:
+		if reflectedValue := reflect.ValueOf(value); reflectedValue.Kind() == reflect.Slice && reflectedValue.Len() > limit {
+			return reflectedValue.Slice(0, limit).Interface()
+		}
+	}
+	return value
+}
+
 // EncodeJSONArgumentsToMap encodes provided go-ethereum ABI packable input values into a generic JSON type values
 // (e.g. []any, map[string]any, etc).
 // Returns the encoded values, or an error if one occurs.
@@ -519,6 +685,14 @@ func encodeABIArgumentToString(inputType *abi.Type, value any) (string, error) {
 		// Join the tuple string elements and close them in braces.
 		str := "{" + strings.Join(tupleData, ", ") + "}"
 		return str, nil
+	case abi.FunctionTy:
+		// Prepare a function type. Return as a string in the format `address.selector`.
+		v, ok := value.([24]byte)
+		if !ok {
+			return "", fmt.Errorf("could not encode function as the value provided is not of the correct type")
+		}
+		address, selector := decodeFunctionTypeValue(v)
+		return fmt.Sprintf("%v.0x%v", strings.ToLower(address.String()), hex.EncodeToString(selector)), nil
 	default:
 		return "", fmt.Errorf("could not encode argument as string, type is unsupported: %v", inputType)
 	}
@@ -612,6 +786,12 @@ func encodeJSONArgument(inputType *abi.Type, value any) (any, error) {
 		if !ok {
 			return nil, fmt.Errorf("could not encode string as the value provided is not of the correct type")
 		}
+
+		// If our string is not valid UTF-8, it cannot be represented in JSON without corruption, so we hex encode it.
+		// Strings which happen to start with our prefix are also encoded, so they can be decoded unambiguously.
+		if !utf8.ValidString(str) || strings.HasPrefix(str, stringJSONHexEncodedPrefix) {
+			return stringJSONHexEncodedPrefix + hex.EncodeToString([]byte(str)), nil
+		}
 		return str, nil
 	case abi.BytesTy:
 		b, ok := value.([]byte)
@@ -661,6 +841,12 @@ func encodeJSONArgument(inputType *abi.Type, value any) (any, error) {
 			tupleData[inputType.TupleRawNames[i]] = fieldData
 		}
 		return tupleData, nil
+	case abi.FunctionTy:
+		v, ok := value.([24]byte)
+		if !ok {
+			return nil, fmt.Errorf("could not encode function as the value provided is not of the correct type")
+		}
+		return hex.EncodeToString(v[:]), nil
 	default:
 		return nil, fmt.Errorf("could not encode argument, type is unsupported: %v", inputType)
 	}
@@ -715,6 +901,34 @@ func DecodeJSONArgumentsFromSlice(inputs abi.Arguments, values []any, deployedCo
 	return decodedArgs, nil
 }
 
+// DeployedContractReferences obtains the names of all deployed contracts referenced (e.g. "DeployedContract:MyToken")
+// by the provided JSON value, including any nested within arrays or objects. The value provided must be a generic
+// JSON type (e.g. []any, map[string]any, etc), as supplied to DecodeJSONArgumentsFromMap.
+// Returns the referenced contract names in sorted order, without duplicates.
+func DeployedContractReferences(value any) []string {
+	names := make([]string, 0)
+	var collect func(value any)
+	collect = func(value any) {
+		switch v := value.(type) {
+		case string:
+			if _, contractName, found := strings.Cut(v, addressJSONContractNameOverridePrefix); found && !slices.Contains(names, contractName) {
+				names = append(names, contractName)
+			}
+		case []any:
+			for _, e := range v {
+				collect(e)
+			}
+		case map[string]any:
+			for _, e := range v {
+				collect(e)
+			}
+		}
+	}
+	collect(value)
+	slices.Sort(names)
+	return names
+}
+
 // decodeJSONArgument decodes JSON value into a provided value of a given type, or returns an error of one occurs.
 // The value provided must be a generic JSON type (e.g. []any, map[string]any, etc) which will be transformed into
 // a go-ethereum ABI packable value.
@@ -794,6 +1008,15 @@ func decodeJSONArgument(inputType *abi.Type, value any, deployedContractAddr map
 		if !ok {
 			return nil, fmt.Errorf("invalid string value")
 		}
+
+		// If our string was hex encoded to preserve non-UTF-8 content, decode it.
+		if strings.HasPrefix(str, stringJSONHexEncodedPrefix) {
+			decodedBytes, err := hex.DecodeString(strings.TrimPrefix(str, stringJSONHexEncodedPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("invalid hex-encoded string value: %v", err)
+			}
+			str = string(decodedBytes)
+		}
 		v = str
 	case abi.BytesTy:
 		str, ok := value.(string)
@@ -876,12 +1099,30 @@ func decodeJSONArgument(inputType *abi.Type, value any, deployedContractAddr map
 				return nil, fmt.Errorf("value for struct field %s not provided", fieldName)
 			}
 			eleValue, err := decodeJSONArgument(eleType, fieldValue, deployedContractAddr)
-			if !ok {
+			if err != nil {
 				return nil, fmt.Errorf("can not parse struct field %s, error: %s", fieldName, err)
 			}
 			reflectionutils.SetField(field, eleValue)
 		}
 		v = st.Interface()
+	case abi.FunctionTy:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("function value should be added as string in JSON")
+		}
+		if len(str) >= 2 && str[0] == '0' && (str[1] == 'x' || str[1] == 'X') {
+			str = str[2:]
+		}
+		decodedBytes, err := hex.DecodeString(str)
+		if err != nil {
+			return nil, err
+		}
+		if len(decodedBytes) != 24 {
+			return nil, fmt.Errorf("invalid number of bytes for function value %v", len(decodedBytes))
+		}
+		var function [24]byte
+		copy(function[:], decodedBytes)
+		v = function
 	default:
 		err := fmt.Errorf("argument type is not supported: %v", inputType)
 		return nil, err
@@ -889,3 +1130,17 @@ func decodeJSONArgument(inputType *abi.Type, value any, deployedContractAddr map
 
 	return v, nil
 }
+
+// encodeFunctionTypeValue joins an address and a 4-byte function selector into the 24-byte array go-ethereum uses to
+// represent an ABI function type.
+func encodeFunctionTypeValue(address common.Address, selector []byte) [24]byte {
+	var function [24]byte
+	copy(function[:common.AddressLength], address.Bytes())
+	copy(function[common.AddressLength:], selector)
+	return function
+}
+
+// decodeFunctionTypeValue splits a 24-byte ABI function type value into its address and 4-byte function selector.
+func decodeFunctionTypeValue(function [24]byte) (common.Address, []byte) {
+	return common.BytesToAddress(function[:common.AddressLength]), slices.Clone(function[common.AddressLength:])
+}
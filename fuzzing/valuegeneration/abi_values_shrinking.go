@@ -0,0 +1,179 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/slices"
+)
+
+// AbiValueShrinkTestFunc tests whether a candidate value for an ABI argument still satisfies the conditions the
+// argument's value is being shrunk for (e.g. it still triggers a failing test).
+// Returns a boolean indicating whether the candidate satisfies the conditions, or an error if one occurs.
+type AbiValueShrinkTestFunc func(candidate any) (bool, error)
+
+// ShrinkAbiValue searches for a simpler value of the provided abi.Type than the provided value, which satisfies the
+// provided test function. Integers are shrunk towards zero, bytes, strings and dynamic arrays towards fewer elements,
+// booleans towards false, fixed bytes towards zero, and addresses towards the provided addresses (e.g. the sender
+// addresses), preferring those provided first. Other types are not shrunk. Values which take a range (integers and
+// lengths) are searched by bisection, assuming values closer to the original are more likely to satisfy the test.
+// Returns the simplest value found to satisfy the test, or the provided value if no simpler value did. If an error
+// occurs, it is returned alongside the simplest value found prior to it.
+func ShrinkAbiValue(inputType *abi.Type, value any, addresses []common.Address, test AbiValueShrinkTestFunc) (any, error) {
+	switch inputType.T {
+	case abi.UintTy, abi.IntTy:
+		// Obtain our integer, then shrink its magnitude while preserving its sign.
+		i := abiIntegerValueToBigInt(value)
+		if i == nil || i.Sign() == 0 {
+			return value, nil
+		}
+		sign := big.NewInt(int64(i.Sign()))
+		magnitude, err := shrinkRange(new(big.Int).Abs(i), func(candidate *big.Int) (bool, error) {
+			return test(IntegerToAbiValue(new(big.Int).Mul(candidate, sign), inputType))
+		})
+		return IntegerToAbiValue(magnitude.Mul(magnitude, sign), inputType), err
+	case abi.BytesTy:
+		b, ok := value.([]byte)
+		if !ok || len(b) == 0 {
+			return value, nil
+		}
+		length, err := shrinkLength(len(b), func(candidate int) (bool, error) {
+			return test(slices.Clone(b[:candidate]))
+		})
+		return slices.Clone(b[:length]), err
+	case abi.StringTy:
+		s, ok := value.(string)
+		if !ok || len(s) == 0 {
+			return value, nil
+		}
+		length, err := shrinkLength(len(s), func(candidate int) (bool, error) {
+			return test(s[:candidate])
+		})
+		return s[:length], err
+	case abi.SliceTy:
+		reflectedSlice := reflect.ValueOf(value)
+		if reflectedSlice.Kind() != reflect.Slice || reflectedSlice.Len() == 0 {
+			return value, nil
+		}
+		length, err := shrinkLength(reflectedSlice.Len(), func(candidate int) (bool, error) {
+			return test(cloneReflectedSlicePrefix(reflectedSlice, candidate))
+		})
+		return cloneReflectedSlicePrefix(reflectedSlice, length), err
+	case abi.BoolTy:
+		if b, ok := value.(bool); !ok || !b {
+			return value, nil
+		}
+		satisfied, err := test(false)
+		if err != nil || !satisfied {
+			return value, err
+		}
+		return false, nil
+	case abi.FixedBytesTy:
+		reflectedValue := reflect.ValueOf(value)
+		if !reflectedValue.IsValid() || reflectedValue.IsZero() {
+			return value, nil
+		}
+		zero := reflect.Zero(reflectedValue.Type()).Interface()
+		satisfied, err := test(zero)
+		if err != nil || !satisfied {
+			return value, err
+		}
+		return zero, nil
+	case abi.AddressTy:
+		address, ok := value.(common.Address)
+		if !ok {
+			return value, nil
+		}
+		// Try each preferred address which precedes our current one, in order.
+		for _, candidate := range addresses {
+			if candidate == address {
+				break
+			}
+			satisfied, err := test(candidate)
+			if err != nil {
+				return value, err
+			}
+			if satisfied {
+				return candidate, nil
+			}
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// shrinkRange bisects the range between zero (inclusive) and the provided positive integer (exclusive) for the
+// smallest integer which satisfies the provided test function, trying zero and one first.
+// Returns the smallest integer found to satisfy the test, or the provided integer if none did. If an error occurs, it
+// is returned alongside the smallest integer found prior to it.
+func shrinkRange(i *big.Int, test func(candidate *big.Int) (bool, error)) (*big.Int, error) {
+	// Try the simplest values first, as they are the most common minimal values.
+	for _, candidate := range []*big.Int{big.NewInt(0), big.NewInt(1)} {
+		if candidate.Cmp(i) >= 0 {
+			return i, nil
+		}
+		satisfied, err := test(candidate)
+		if err != nil {
+			return i, err
+		}
+		if satisfied {
+			return candidate, nil
+		}
+	}
+
+	// Bisect between the largest value known not to satisfy the test and the smallest value known to.
+	low, high := big.NewInt(1), new(big.Int).Set(i)
+	one := big.NewInt(1)
+	for new(big.Int).Sub(high, low).Cmp(one) > 0 {
+		mid := new(big.Int).Add(low, high)
+		mid.Rsh(mid, 1)
+		satisfied, err := test(mid)
+		if err != nil {
+			return high, err
+		}
+		if satisfied {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return high, nil
+}
+
+// shrinkLength bisects the lengths between zero (inclusive) and the provided positive length (exclusive) for the
+// shortest length which satisfies the provided test function.
+// Returns the shortest length found to satisfy the test, or the provided length if none did. If an error occurs, it
+// is returned alongside the shortest length found prior to it.
+func shrinkLength(length int, test func(candidate int) (bool, error)) (int, error) {
+	shrunk, err := shrinkRange(big.NewInt(int64(length)), func(candidate *big.Int) (bool, error) {
+		return test(int(candidate.Int64()))
+	})
+	return int(shrunk.Int64()), err
+}
+
+// cloneReflectedSlicePrefix creates a copy of the first elements of the provided reflected slice.
+// Returns the copied slice with the provided length.
+func cloneReflectedSlicePrefix(reflectedSlice reflect.Value, length int) any {
+	prefix := reflect.MakeSlice(reflectedSlice.Type(), length, length)
+	reflect.Copy(prefix, reflectedSlice.Slice(0, length))
+	return prefix.Interface()
+}
+
+// abiIntegerValueToBigInt converts the provided integer ABI value into a big.Int.
+// Returns the converted integer, or nil if the value is not an integer ABI value.
+func abiIntegerValueToBigInt(value any) *big.Int {
+	if i, ok := value.(*big.Int); ok {
+		return new(big.Int).Set(i)
+	}
+	reflectedValue := reflect.ValueOf(value)
+	switch reflectedValue.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(reflectedValue.Uint())
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(reflectedValue.Int())
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestShrinkAbiValue ensures values are shrunk to the simplest value satisfying the test, and are left unchanged when
+// no simpler value does.
+func TestShrinkAbiValue(t *testing.T) {
+	// shrink shrinks the provided value of the provided type with the provided test, expecting no error.
+	shrink := func(typeName string, value any, addresses []common.Address, test AbiValueShrinkTestFunc) any {
+		inputType, err := abi.NewType(typeName, "", nil)
+		assert.NoError(t, err)
+		shrunk, err := ShrinkAbiValue(&inputType, value, addresses, test)
+		assert.NoError(t, err)
+		return shrunk
+	}
+
+	// Integers should be shrunk to the boundary of the condition they satisfy, preserving their sign and type.
+	largeValue, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	shrunk := shrink("uint256", largeValue, nil, func(candidate any) (bool, error) {
+		return candidate.(*big.Int).Cmp(big.NewInt(1000)) > 0, nil
+	})
+	assert.EqualValues(t, big.NewInt(1001), shrunk)
+	shrunk = shrink("uint8", uint8(200), nil, func(candidate any) (bool, error) { return true, nil })
+	assert.EqualValues(t, uint8(0), shrunk)
+	shrunk = shrink("int64", int64(-5000), nil, func(candidate any) (bool, error) { return candidate.(int64) < -7, nil })
+	assert.EqualValues(t, int64(-8), shrunk)
+	shrunk = shrink("uint256", big.NewInt(77), nil, func(candidate any) (bool, error) { return candidate.(*big.Int).Int64() == 77, nil })
+	assert.EqualValues(t, big.NewInt(77), shrunk)
+
+	// Bytes, strings and slices should be shrunk to the shortest prefix satisfying the test.
+	shrunk = shrink("bytes", []byte{1, 2, 3, 4, 5, 6, 7, 8}, nil, func(candidate any) (bool, error) { return len(candidate.([]byte)) >= 3, nil })
+	assert.EqualValues(t, []byte{1, 2, 3}, shrunk)
+	shrunk = shrink("string", "hello world", nil, func(candidate any) (bool, error) { return true, nil })
+	assert.EqualValues(t, "", shrunk)
+	shrunk = shrink("uint8[]", []uint8{9, 8, 7, 6}, nil, func(candidate any) (bool, error) { return len(candidate.([]uint8)) > 1, nil })
+	assert.EqualValues(t, []uint8{9, 8}, shrunk)
+
+	// Booleans and fixed bytes should be shrunk to their zero values.
+	shrunk = shrink("bool", true, nil, func(candidate any) (bool, error) { return true, nil })
+	assert.EqualValues(t, false, shrunk)
+	shrunk = shrink("bytes4", [4]byte{1, 2, 3, 4}, nil, func(candidate any) (bool, error) { return true, nil })
+	assert.EqualValues(t, [4]byte{}, shrunk)
+
+	// Addresses should be shrunk to the first preferred address which satisfies the test.
+	addresses := []common.Address{common.HexToAddress("0x10000"), common.HexToAddress("0x20000")}
+	shrunk = shrink("address", common.HexToAddress("0xdeadbeef"), addresses, func(candidate any) (bool, error) {
+		return candidate.(common.Address) != addresses[0], nil
+	})
+	assert.EqualValues(t, addresses[1], shrunk)
+	shrunk = shrink("address", addresses[0], addresses, func(candidate any) (bool, error) { return true, nil })
+	assert.EqualValues(t, addresses[0], shrunk)
+}
@@ -1,13 +1,16 @@
 package valuegeneration
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/crytic/medusa/compilation/types"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -67,6 +70,19 @@ func getTestABIArguments() abi.Arguments {
 			},
 			Indexed: false,
 		},
+		{
+			Name: "testFunction",
+			Type: abi.Type{
+				Elem:          nil,
+				Size:          24,
+				T:             abi.FunctionTy,
+				TupleRawName:  "",
+				TupleElems:    nil,
+				TupleRawNames: nil,
+				TupleType:     nil,
+			},
+			Indexed: false,
+		},
 	}
 
 	// Append all fixed byte sizes
@@ -195,7 +211,53 @@ func getTestABIArguments() abi.Arguments {
 		}
 	}
 
-	// TODO: Add tuple argument.
+	// Add tuple (struct) arguments, including tuples nested in slices and slices nested in tuples.
+	args = append(args, getTestABITupleArguments()...)
+	return args
+}
+
+// getTestABITupleArguments obtains ABI tuple (struct) arguments for use in testing ABI value related methods.
+func getTestABITupleArguments() abi.Arguments {
+	// Define the components of a simple struct type, akin to `(address,uint256,bytes)`.
+	orderComponents := []abi.ArgumentMarshaling{
+		{Name: "owner", Type: "address"},
+		{Name: "amount", Type: "uint256"},
+		{Name: "data", Type: "bytes"},
+	}
+
+	// Define the components of a struct which contains slices, arrays, and another struct.
+	nestedComponents := []abi.ArgumentMarshaling{
+		{Name: "id", Type: "uint8"},
+		{Name: "values", Type: "int256[]"},
+		{Name: "tags", Type: "bytes4[3]"},
+		{Name: "order", Type: "tuple", Components: orderComponents},
+		{Name: "orders", Type: "tuple[]", Components: orderComponents},
+	}
+
+	// Construct all tuple types to test.
+	typeDefinitions := []struct {
+		name       string
+		typeString string
+		components []abi.ArgumentMarshaling
+	}{
+		{"testTuple", "tuple", orderComponents},
+		{"testTupleSlice", "tuple[]", orderComponents},
+		{"testTupleArray", "tuple[2]", orderComponents},
+		{"testNestedTuple", "tuple", nestedComponents},
+		{"testNestedTupleSlice", "tuple[][]", nestedComponents},
+	}
+	args := make(abi.Arguments, 0)
+	for _, typeDefinition := range typeDefinitions {
+		tupleType, err := abi.NewType(typeDefinition.typeString, "", typeDefinition.components)
+		if err != nil {
+			panic(fmt.Sprintf("failed to create tuple type for test: %v", err))
+		}
+		args = append(args, abi.Argument{
+			Name:    typeDefinition.name,
+			Type:    tupleType,
+			Indexed: false,
+		})
+	}
 	return args
 }
 
@@ -223,6 +285,10 @@ func TestABIRoundtripEncodingAllTypes(t *testing.T) {
 			// Generate a value for this argument
 			value := GenerateAbiValue(valueGenerator, &arg.Type)
 
+			// Ensure the generated value can be ABI packed for its argument type.
+			_, err := abi.Arguments{arg}.Pack(value)
+			assert.NoError(t, err)
+
 			// Encode the generated value for this argument
 			encodedValue, err := encodeJSONArgument(&arg.Type, value)
 			assert.NoError(t, err)
@@ -289,12 +355,37 @@ func TestABIGenerationAndMutation(t *testing.T) {
 			mutatedValue, err := MutateAbiValue(valueGenerator, &arg.Type, value)
 			assert.NoError(t, err)
 
-			// Verify the types of the value and mutated value are the same
+			// Verify the types of the value and mutated value are the same, and the mutated value can be ABI packed.
 			assert.EqualValues(t, reflect.ValueOf(value).Type().String(), reflect.ValueOf(mutatedValue).Type().String())
+			_, err = abi.Arguments{arg}.Pack(mutatedValue)
+			assert.NoError(t, err, "mutated value could not be packed for '%v'", arg.Name)
+
+			// Mutate the value again after loosening the types of its nested arrays and slices, as may occur for
+			// values sourced from elsewhere, and verify the mutated value has the exact type expected.
+			mutatedValue, err = MutateAbiValue(valueGenerator, &arg.Type, loosenAbiArrayValueTypes(&arg.Type, value))
+			assert.NoError(t, err)
+			assert.EqualValues(t, arg.Type.GetType(), reflect.TypeOf(mutatedValue), "mutated value type drifted for '%v'", arg.Name)
+			_, err = abi.Arguments{arg}.Pack(mutatedValue)
+			assert.NoError(t, err, "mutated value could not be packed for '%v'", arg.Name)
 		}
 	}
 }
 
+// loosenAbiArrayValueTypes converts any nested arrays and slices within the provided ABI value of the provided type
+// into slices of type []any, so that they no longer match the types expected for the ABI type.
+// Returns the converted value.
+func loosenAbiArrayValueTypes(inputType *abi.Type, value any) any {
+	if inputType.T != abi.ArrayTy && inputType.T != abi.SliceTy {
+		return value
+	}
+	reflectedValue := reflect.ValueOf(value)
+	loosenedValue := make([]any, reflectedValue.Len())
+	for i := 0; i < len(loosenedValue); i++ {
+		loosenedValue[i] = loosenAbiArrayValueTypes(inputType.Elem, reflectedValue.Index(i).Interface())
+	}
+	return loosenedValue
+}
+
 // TestEncodeABIArgumentToString runs tests to ensure that  a provided go-ethereum ABI packable input value of a given
 // type is encoded to string in the specific format, depending on the input's type.
 func TestEncodeABIArgumentToString(t *testing.T) {
@@ -324,3 +415,206 @@ func TestEncodeABIArgumentToString(t *testing.T) {
 		}
 	}
 }
+
+// TestEnumAbiValueGeneration runs tests to ensure that values generated and mutated for enum arguments fall within the
+// range of the enum's members with high probability, while still occasionally producing out-of-range values.
+func TestEnumAbiValueGeneration(t *testing.T) {
+	// Create a value generator
+	valueGenerator := NewRandomValueGenerator(&RandomValueGeneratorConfig{
+		GenerateRandomArrayMinSize:  0,
+		GenerateRandomArrayMaxSize:  100,
+		GenerateRandomBytesMinSize:  0,
+		GenerateRandomBytesMaxSize:  100,
+		GenerateRandomStringMinSize: 0,
+		GenerateRandomStringMaxSize: 100,
+	}, rand.New(rand.NewSource(time.Now().UnixNano())))
+
+	// Test enums of various sizes.
+	const iterations = 10_000
+	for _, memberCount := range []int{1, 3, 7, 256} {
+		// Generate and mutate values, tracking how many fell outside the range of the enum.
+		outOfRange := 0
+		for i := 0; i < iterations; i++ {
+			value := GenerateEnumAbiValue(valueGenerator, memberCount)
+			if int(value) >= memberCount {
+				outOfRange++
+			}
+			value = MutateEnumAbiValue(valueGenerator, memberCount, value)
+			if int(value) >= memberCount {
+				outOfRange++
+			}
+		}
+
+		// Most values should be within range, but for enums which do not span the entire uint8 range, we expect
+		// some out-of-range values too.
+		assert.Less(t, outOfRange, iterations/5, "too many out-of-range values generated for enum with %v members", memberCount)
+		if memberCount < 256 {
+			assert.Greater(t, outOfRange, 0, "no out-of-range values generated for enum with %v members", memberCount)
+		}
+	}
+}
+
+// TestNestedEnumAbiValueGeneration runs tests to ensure that values generated and mutated for arguments containing
+// enums nested within arrays and structs keep those enums within the range of their members with high probability,
+// while leaving the other values they contain unconstrained.
+func TestNestedEnumAbiValueGeneration(t *testing.T) {
+	// Create a value generator
+	valueGenerator := NewRandomValueGenerator(&RandomValueGeneratorConfig{
+		GenerateRandomArrayMinSize:  1,
+		GenerateRandomArrayMaxSize:  10,
+		GenerateRandomBytesMinSize:  0,
+		GenerateRandomBytesMaxSize:  100,
+		GenerateRandomStringMinSize: 0,
+		GenerateRandomStringMaxSize: 100,
+	}, rand.New(rand.NewSource(time.Now().UnixNano())))
+
+	// Define an enum array type, and a struct type with an enum and a plain uint8 field, alongside their enum bounds.
+	enumArrayType, err := abi.NewType("uint8[]", "", nil)
+	assert.NoError(t, err)
+	enumArrayBounds := &types.EnumBounds{Elem: &types.EnumBounds{MemberCount: 3}}
+	structType, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "kind", Type: "uint8"},
+		{Name: "amount", Type: "uint8"},
+	})
+	assert.NoError(t, err)
+	structBounds := &types.EnumBounds{TupleElems: []*types.EnumBounds{{MemberCount: 3}, nil}}
+
+	// Generate and mutate values, tracking how many enums fell outside the range of the enum.
+	const iterations = 2_000
+	enumValues, outOfRange, plainOutOfRange := 0, 0, 0
+	for i := 0; i < iterations; i++ {
+		arrayValue := GenerateEnumBoundedAbiValue(valueGenerator, &enumArrayType, enumArrayBounds)
+		arrayValue, err = MutateEnumBoundedAbiValue(valueGenerator, &enumArrayType, arrayValue, enumArrayBounds)
+		assert.NoError(t, err)
+		for _, kind := range arrayValue.([]uint8) {
+			enumValues++
+			if kind >= 3 {
+				outOfRange++
+			}
+		}
+
+		structValue := GenerateEnumBoundedAbiValue(valueGenerator, &structType, structBounds)
+		structValue, err = MutateEnumBoundedAbiValue(valueGenerator, &structType, structValue, structBounds)
+		assert.NoError(t, err)
+		fields := reflect.ValueOf(structValue)
+		enumValues++
+		if fields.Field(0).Uint() >= 3 {
+			outOfRange++
+		}
+		if fields.Field(1).Uint() >= 3 {
+			plainOutOfRange++
+		}
+
+		// The values should remain packable.
+		_, err = abi.Arguments{{Type: enumArrayType}, {Type: structType}}.Pack(arrayValue, structValue)
+		assert.NoError(t, err)
+	}
+
+	// Most enums should be within range, while fields which are not enums should not be constrained.
+	assert.Less(t, outOfRange, enumValues/5, "too many out-of-range values generated for nested enums")
+	assert.Greater(t, plainOutOfRange, iterations/2, "values which are not enums were constrained")
+}
+
+// TestLimitAbiValueSize runs tests to ensure that bytes, string and dynamic array values generated for arguments with
+// a bounded size are truncated to their bound, while values of other types are left unchanged.
+func TestLimitAbiValueSize(t *testing.T) {
+	// Create a value generator which generates values exceeding our bound.
+	valueGenerator := NewRandomValueGenerator(&RandomValueGeneratorConfig{
+		GenerateRandomArrayMinSize:  20,
+		GenerateRandomArrayMaxSize:  100,
+		GenerateRandomBytesMinSize:  20,
+		GenerateRandomBytesMaxSize:  100,
+		GenerateRandomStringMinSize: 20,
+		GenerateRandomStringMaxSize: 100,
+	}, rand.New(rand.NewSource(time.Now().UnixNano())))
+
+	// Generate values of each bounded type and ensure they are truncated to our bound.
+	const limit = 10
+	for _, typeStr := range []string{"bytes", "string", "uint256[]", "string[]"} {
+		inputType, err := abi.NewType(typeStr, "", nil)
+		assert.NoError(t, err)
+		value := GenerateAbiValue(valueGenerator, &inputType)
+		limitedValue := LimitAbiValueSize(&inputType, value, limit)
+		assert.EqualValues(t, limit, reflect.ValueOf(limitedValue).Len(), typeStr)
+		assert.EqualValues(t, reflect.TypeOf(value), reflect.TypeOf(limitedValue), typeStr)
+
+		// Values are unchanged without a bound.
+		assert.EqualValues(t, value, LimitAbiValueSize(&inputType, value, 0), typeStr)
+	}
+
+	// Fixed-size values are never truncated.
+	inputType, err := abi.NewType("uint256[20]", "", nil)
+	assert.NoError(t, err)
+	value := GenerateAbiValue(valueGenerator, &inputType)
+	assert.EqualValues(t, value, LimitAbiValueSize(&inputType, value, limit))
+}
+
+// TestJSONRoundtripEncodingNonUTF8Strings runs tests to ensure that strings which are not valid UTF-8 (or which could
+// be confused with an encoded string) survive JSON serialization without corruption.
+func TestJSONRoundtripEncodingNonUTF8Strings(t *testing.T) {
+	// Define our string type and the values to test.
+	stringType, err := abi.NewType("string", "", nil)
+	assert.NoError(t, err)
+	values := []string{"", "plain", "\xff\xfe\x00", "valid \u4e2d then invalid \xc0\x80", stringJSONHexEncodedPrefix + "abcd"}
+
+	for _, value := range values {
+		// Encode our value and serialize it to JSON.
+		encodedValue, err := encodeJSONArgument(&stringType, value)
+		assert.NoError(t, err)
+		b, err := json.Marshal(encodedValue)
+		assert.NoError(t, err)
+
+		// Deserialize our JSON and decode our value.
+		var deserializedValue any
+		err = json.Unmarshal(b, &deserializedValue)
+		assert.NoError(t, err)
+		decodedValue, err := decodeJSONArgument(&stringType, deserializedValue, nil)
+		assert.NoError(t, err)
+
+		// Verify the value is unchanged.
+		assert.EqualValues(t, value, decodedValue)
+	}
+}
+
+// TestJSONDecodingDeployedContractReferences runs tests to ensure deployed contract references are resolved to their
+// addresses when decoding JSON arguments, including references nested within arrays and tuples.
+func TestJSONDecodingDeployedContractReferences(t *testing.T) {
+	// Define a constructor argument which nests addresses within a tuple and an array.
+	tupleType, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "token", Type: "address"},
+		{Name: "oracles", Type: "address[]"},
+	})
+	assert.NoError(t, err)
+	inputs := abi.Arguments{{Name: "_config", Type: tupleType}}
+	values := map[string]any{
+		"_config": map[string]any{
+			"token":   "DeployedContract:Token",
+			"oracles": []any{"DeployedContract:OracleB", "DeployedContract:OracleA", "DeployedContract:Token"},
+		},
+	}
+
+	// Verify the referenced contract names are collected without duplicates.
+	assert.EqualValues(t, []string{"OracleA", "OracleB", "Token"}, DeployedContractReferences(values))
+
+	// Decode our value and verify every reference was resolved.
+	deployedContractAddr := map[string]common.Address{
+		"Token":   common.HexToAddress("0x1111"),
+		"OracleA": common.HexToAddress("0x2222"),
+		"OracleB": common.HexToAddress("0x3333"),
+	}
+	decoded, err := DecodeJSONArgumentsFromMap(inputs, values, deployedContractAddr)
+	assert.NoError(t, err)
+	reencoded, err := EncodeJSONArgumentsToMap(inputs, decoded)
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[string]any{
+		"_config": map[string]any{
+			"token":   deployedContractAddr["Token"].String(),
+			"oracles": []any{deployedContractAddr["OracleB"].String(), deployedContractAddr["OracleA"].String(), deployedContractAddr["Token"].String()},
+		},
+	}, reencoded)
+
+	// Verify decoding fails if a referenced contract was not deployed.
+	delete(deployedContractAddr, "OracleA")
+	_, err = DecodeJSONArgumentsFromMap(inputs, values, deployedContractAddr)
+	assert.Error(t, err)
+}
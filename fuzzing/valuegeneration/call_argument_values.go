@@ -0,0 +1,131 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"math/rand"
+	"reflect"
+
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/crytic/medusa/utils/reflectionutils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"golang.org/x/exp/slices"
+)
+
+// callArgumentTypesCompatible indicates whether a value of the provided source abi.Type can be copied into an
+// argument of the provided target abi.Type. Values can be copied between arguments of identical types, or between
+// integer arguments of any size or signedness, in which case they are constrained to the target type.
+func callArgumentTypesCompatible(source *abi.Type, target *abi.Type) bool {
+	if source.String() == target.String() {
+		return true
+	}
+	isInteger := func(t *abi.Type) bool { return t.T == abi.UintTy || t.T == abi.IntTy }
+	return isInteger(source) && isInteger(target)
+}
+
+// callArgumentEnumValueInRange indicates whether the provided call argument value is an integer within the range of an
+// enum with the provided member count.
+func callArgumentEnumValueInRange(value any, memberCount int) bool {
+	b := integerFromValue(value)
+	return b != nil && b.Sign() >= 0 && b.Cmp(big.NewInt(int64(memberCount))) < 0
+}
+
+// callArgumentEnumBoundsCompatible indicates whether the provided value of a source argument, with the provided enum
+// bounds, can be copied into a target argument with the provided enum bounds without placing enums out of range.
+// Values can be copied into enum arguments if they are within range of the enum's members, and into arguments with
+// enums nested within arrays or structs if the source argument's enums are described by identical bounds.
+func callArgumentEnumBoundsCompatible(value any, sourceBounds *types.EnumBounds, targetBounds *types.EnumBounds) bool {
+	if targetBounds == nil {
+		return true
+	}
+	if targetBounds.MemberCount > 0 {
+		return callArgumentEnumValueInRange(value, targetBounds.MemberCount)
+	}
+	return reflect.DeepEqual(sourceBounds, targetBounds)
+}
+
+// CopyCallArgumentValue copies the value of a randomly selected argument of a call into another randomly selected
+// argument of the same call with a compatible type (see callArgumentTypesCompatible). This allows a call such as
+// `transferFrom(from, to, amount)` to be provided with `from == to`, which is unlikely to occur when each value is
+// generated independently. The enums within arguments (see types.EnumBounds) and the size limits of arguments (see
+// LimitAbiValueSize) may be provided, indexed by argument, with nil, zero, or a nil slice indicating there is none.
+// Enum arguments are only provided values within the range of their members, arguments with nested enums are only
+// provided values of arguments with identical enums, and size limited arguments are provided values truncated to
+// their limit, so copies remain within the bounds values would otherwise be generated within.
+// Returns a boolean indicating whether a value was copied, which is false if no two arguments have compatible types.
+func CopyCallArgumentValue(randomProvider *rand.Rand, inputs abi.Arguments, values []any, enumBounds []*types.EnumBounds, sizeLimits []int) bool {
+	// Collect every pair of arguments a value can be copied between.
+	if len(inputs) != len(values) {
+		return false
+	}
+	type argumentPair struct{ source, target int }
+	pairs := make([]argumentPair, 0)
+	for source := range inputs {
+		for target := range inputs {
+			if source == target || values[source] == nil || !callArgumentTypesCompatible(&inputs[source].Type, &inputs[target].Type) {
+				continue
+			}
+			if len(enumBounds) == len(inputs) && !callArgumentEnumBoundsCompatible(values[source], enumBounds[source], enumBounds[target]) {
+				continue
+			}
+			pairs = append(pairs, argumentPair{source, target})
+		}
+	}
+	if len(pairs) == 0 {
+		return false
+	}
+
+	// Select a random pair and copy the value, converting it to the target type and truncating it to its size limit.
+	pair := pairs[randomProvider.Intn(len(pairs))]
+	if !copyCallArgumentValue(values, pair.source, pair.target, &inputs[pair.source].Type, &inputs[pair.target].Type) {
+		return false
+	}
+	if pair.target < len(sizeLimits) && sizeLimits[pair.target] > 0 {
+		values[pair.target] = LimitAbiValueSize(&inputs[pair.target].Type, values[pair.target], sizeLimits[pair.target])
+	}
+	return true
+}
+
+// copyCallArgumentValue copies the value of the source argument of a call into the target argument, converting it to
+// the target argument's type, which must be compatible with the source's (see callArgumentTypesCompatible).
+// Returns a boolean indicating whether the value was copied.
+func copyCallArgumentValue(values []any, source int, target int, sourceType *abi.Type, targetType *abi.Type) bool {
+	if sourceType.String() != targetType.String() {
+		values[target] = IntegerToAbiValue(integerFromValue(values[source]), targetType)
+		return true
+	}
+
+	// Byte slices are copied so the arguments do not share a backing array, as mutations may alter them in place.
+	// Other values are replaced, rather than altered in place, when they are mutated.
+	if b, ok := values[source].([]byte); ok {
+		values[target] = slices.Clone(b)
+		return true
+	}
+	convertedValue, err := reflectionutils.ConvertReflectedValue(values[source], targetType.GetType())
+	if err != nil {
+		return false
+	}
+	values[target] = convertedValue.Interface()
+	return true
+}
+
+// integerFromValue obtains the integer represented by the provided call argument value, which may be a *big.Int or a
+// native Go integer type.
+// Returns the integer, or nil if the value is not an integer.
+func integerFromValue(value any) *big.Int {
+	if b, ok := value.(*big.Int); ok {
+		if b == nil {
+			return nil
+		}
+		return new(big.Int).Set(b)
+	}
+
+	reflectedValue := reflect.ValueOf(value)
+	switch reflectedValue.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(reflectedValue.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(reflectedValue.Uint())
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/crytic/medusa/compilation/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCopyCallArgumentValue ensures values are only copied between arguments of compatible types, and are converted
+// to the type of the target argument.
+func TestCopyCallArgumentValue(t *testing.T) {
+	randomProvider := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// Arguments of identical types should have values copied between them.
+	inputs := getTestNamedArguments(t, "from", "address", "to", "address", "data", "bytes")
+	for i := 0; i < 20; i++ {
+		values := []any{common.HexToAddress("0x1"), common.HexToAddress("0x2"), []byte{1, 2, 3}}
+		assert.True(t, CopyCallArgumentValue(randomProvider, inputs, values, nil, nil))
+		assert.EqualValues(t, values[0], values[1])
+		assert.EqualValues(t, []byte{1, 2, 3}, values[2])
+	}
+
+	// Integer arguments should have values copied between them, constrained to the type of the target argument.
+	inputs = getTestNamedArguments(t, "amount", "uint256", "small", "uint8", "flag", "bool")
+	for i := 0; i < 20; i++ {
+		values := []any{big.NewInt(0x1234), uint8(7), true}
+		assert.True(t, CopyCallArgumentValue(randomProvider, inputs, values, nil, nil))
+		if values[1] == uint8(0x34) {
+			assert.EqualValues(t, big.NewInt(0x1234), values[0])
+		} else {
+			assert.EqualValues(t, big.NewInt(7), values[0])
+			assert.EqualValues(t, uint8(7), values[1])
+		}
+		assert.EqualValues(t, true, values[2])
+
+		// The copied values should remain packable.
+		_, err := inputs.Pack(values...)
+		assert.NoError(t, err)
+	}
+
+	// Arguments without compatible types should not be modified.
+	inputs = getTestNamedArguments(t, "to", "address", "amount", "uint256", "data", "bytes")
+	values := []any{common.HexToAddress("0x1"), big.NewInt(1), []byte{1}}
+	assert.False(t, CopyCallArgumentValue(randomProvider, inputs, values, nil, nil))
+	assert.EqualValues(t, []any{common.HexToAddress("0x1"), big.NewInt(1), []byte{1}}, values)
+	assert.False(t, CopyCallArgumentValue(randomProvider, abi.Arguments{}, []any{}, nil, nil))
+}
+
+// TestCopyCallArgumentValueBounds ensures values are only copied into enum arguments if they are within the range of
+// the enum's members, and are truncated to the size limit of the argument they are copied into.
+func TestCopyCallArgumentValueBounds(t *testing.T) {
+	randomProvider := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	// Values outside the range of an enum should never be copied into it.
+	inputs := getTestNamedArguments(t, "amount", "uint256", "kind", "uint8")
+	enumBounds := []*types.EnumBounds{nil, {MemberCount: 3}}
+	for i := 0; i < 20; i++ {
+		values := []any{big.NewInt(7), uint8(1)}
+		assert.True(t, CopyCallArgumentValue(randomProvider, inputs, values, enumBounds, nil))
+		assert.EqualValues(t, []any{big.NewInt(1), uint8(1)}, values)
+	}
+
+	// Values within the range of an enum may be copied into it.
+	copiedIntoEnum := false
+	for i := 0; i < 100; i++ {
+		values := []any{big.NewInt(2), uint8(1)}
+		assert.True(t, CopyCallArgumentValue(randomProvider, inputs, values, enumBounds, nil))
+		copiedIntoEnum = copiedIntoEnum || values[1] == uint8(2)
+	}
+	assert.True(t, copiedIntoEnum)
+
+	// No value is copied if every value is out of range of the enum it could be copied into.
+	inputs = getTestNamedArguments(t, "first", "uint8", "second", "uint8")
+	values := []any{uint8(5), uint8(7)}
+	assert.False(t, CopyCallArgumentValue(randomProvider, inputs, values, []*types.EnumBounds{{MemberCount: 3}, {MemberCount: 3}}, nil))
+	assert.EqualValues(t, []any{uint8(5), uint8(7)}, values)
+
+	// Values should only be copied into enum array arguments from arguments with identical enums.
+	inputs = getTestNamedArguments(t, "kinds", "uint8[]", "amounts", "uint8[]")
+	enumBounds = []*types.EnumBounds{{Elem: &types.EnumBounds{MemberCount: 3}}, nil}
+	for i := 0; i < 20; i++ {
+		values = []any{[]uint8{0, 2}, []uint8{9, 200}}
+		assert.True(t, CopyCallArgumentValue(randomProvider, inputs, values, enumBounds, nil))
+		assert.EqualValues(t, []any{[]uint8{0, 2}, []uint8{0, 2}}, values)
+	}
+
+	// Values copied into a size limited argument should be truncated to its limit.
+	inputs = getTestNamedArguments(t, "long", "bytes", "short", "bytes")
+	for i := 0; i < 20; i++ {
+		values = []any{[]byte{1, 2, 3, 4, 5, 6}, []byte{1, 2}}
+		assert.True(t, CopyCallArgumentValue(randomProvider, inputs, values, nil, []int{0, 2}))
+		assert.Len(t, values[1], 2)
+	}
+}
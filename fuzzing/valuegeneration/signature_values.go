@@ -0,0 +1,169 @@
+package valuegeneration
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignatureValueGenerator is an optional interface implemented by a ValueGenerator which can produce valid ECDSA
+// signatures over generated hashes, so that signature verification (e.g. ecrecover) in a contract may succeed.
+type SignatureValueGenerator interface {
+	// GenerateSignature produces a 65-byte ECDSA signature over the provided hash, in [R || S || V] form where V is 27
+	// or 28. The hash may be signed directly, or as an EIP-191 signed message.
+	// Returns the signature, or nil if no valid signature should be produced, in which case the caller should keep
+	// its randomly generated values.
+	GenerateSignature(hash [32]byte) []byte
+}
+
+// SignatureArguments describes the indexes of method input arguments which were identified as a signature over a
+// hash. Indexes which were not identified are -1.
+type SignatureArguments struct {
+	// HashIndex describes the index of the bytes32 argument containing the signed hash.
+	HashIndex int
+
+	// VIndex describes the index of the uint8 argument containing the recovery identifier of the signature.
+	VIndex int
+	// RIndex describes the index of the bytes32 argument containing the R value of the signature.
+	RIndex int
+	// SIndex describes the index of the bytes32 argument containing the S value of the signature.
+	SIndex int
+
+	// SignatureIndex describes the index of the bytes argument containing the encoded 65-byte signature.
+	SignatureIndex int
+}
+
+// signatureHashArgumentNames describes substrings which identify a bytes32 argument as a signed hash by its name.
+var signatureHashArgumentNames = []string{"hash", "digest", "msg", "message"}
+
+// normalizeSignatureArgumentName obtains a lowercase argument name with any leading or trailing underscores removed,
+// for use in signature argument matching.
+func normalizeSignatureArgumentName(name string) string {
+	return strings.ToLower(strings.Trim(name, "_"))
+}
+
+// MatchSignatureArguments uses a heuristic on the names and types of the provided method input arguments to identify
+// a signature over a hash. A signature is identified as either uint8 v, bytes32 r and bytes32 s arguments, or a bytes
+// argument whose name contains "sig". The signed hash is identified as a bytes32 argument whose name suggests it is a
+// hash, or the only other bytes32 argument if none does.
+// Returns the identified SignatureArguments, or nil if no signature and hash could be identified.
+func MatchSignatureArguments(inputs abi.Arguments) *SignatureArguments {
+	matched := &SignatureArguments{HashIndex: -1, VIndex: -1, RIndex: -1, SIndex: -1, SignatureIndex: -1}
+
+	// Identify our signature arguments, collecting any other bytes32 arguments which may be our hash.
+	hashCandidates := make([]int, 0)
+	for i, input := range inputs {
+		name := normalizeSignatureArgumentName(input.Name)
+		switch {
+		case input.Type.T == abi.UintTy && input.Type.Size == 8 && name == "v":
+			matched.VIndex = i
+		case input.Type.T == abi.FixedBytesTy && input.Type.Size == 32 && name == "r":
+			matched.RIndex = i
+		case input.Type.T == abi.FixedBytesTy && input.Type.Size == 32 && name == "s":
+			matched.SIndex = i
+		case input.Type.T == abi.BytesTy && strings.Contains(name, "sig"):
+			matched.SignatureIndex = i
+		case input.Type.T == abi.FixedBytesTy && input.Type.Size == 32:
+			hashCandidates = append(hashCandidates, i)
+		}
+	}
+
+	// Only accept complete signature representations.
+	hasVRS := matched.VIndex >= 0 && matched.RIndex >= 0 && matched.SIndex >= 0
+	if !hasVRS {
+		matched.VIndex, matched.RIndex, matched.SIndex = -1, -1, -1
+	}
+	if !hasVRS && matched.SignatureIndex < 0 {
+		return nil
+	}
+
+	// Identify our hash argument, preferring one named like a hash.
+	for _, candidate := range hashCandidates {
+		name := normalizeSignatureArgumentName(inputs[candidate].Name)
+		for _, hashName := range signatureHashArgumentNames {
+			if strings.Contains(name, hashName) {
+				matched.HashIndex = candidate
+				break
+			}
+		}
+		if matched.HashIndex >= 0 {
+			break
+		}
+	}
+	if matched.HashIndex < 0 && len(hashCandidates) == 1 {
+		matched.HashIndex = hashCandidates[0]
+	}
+	if matched.HashIndex < 0 {
+		return nil
+	}
+	return matched
+}
+
+// ApplySignatureArguments replaces the signature arguments identified by MatchSignatureArguments in the provided
+// input values with a valid signature over the hash argument, if the provided generator is a
+// SignatureValueGenerator which chooses to produce one.
+// Returns a boolean indicating whether a valid signature was applied.
+func ApplySignatureArguments(generator ValueGenerator, inputs abi.Arguments, values []any) bool {
+	// Verify our generator supports signing, and our method has signature arguments.
+	signatureGenerator, ok := generator.(SignatureValueGenerator)
+	if !ok || len(values) != len(inputs) {
+		return false
+	}
+	matched := MatchSignatureArguments(inputs)
+	if matched == nil {
+		return false
+	}
+
+	// Obtain our hash and sign it.
+	hash, ok := values[matched.HashIndex].([32]byte)
+	if !ok {
+		return false
+	}
+	signature := signatureGenerator.GenerateSignature(hash)
+	if len(signature) != crypto.SignatureLength {
+		return false
+	}
+
+	// Set our signature arguments.
+	if matched.VIndex >= 0 {
+		var r, s [32]byte
+		copy(r[:], signature[:32])
+		copy(s[:], signature[32:64])
+		values[matched.VIndex] = signature[64]
+		values[matched.RIndex] = r
+		values[matched.SIndex] = s
+	}
+	if matched.SignatureIndex >= 0 {
+		values[matched.SignatureIndex] = signature
+	}
+	return true
+}
+
+// GenerateSignature produces a 65-byte ECDSA signature over the provided hash using a random signer key, in
+// [R || S || V] form where V is 27 or 28. The hash is signed either directly, or as an EIP-191 signed message.
+// Returns the signature, or nil if no signer keys were provided, or the generator opted to fall back to random
+// values (see MutatingValueGeneratorConfig.GenerateValidSignatureProbability).
+func (g *MutatingValueGenerator) GenerateSignature(hash [32]byte) []byte {
+	// If we have no keys, or randomly decided not to, we do not produce a signature.
+	if len(g.signerKeys) == 0 || g.randomProvider.Float32() >= g.config.GenerateValidSignatureProbability {
+		return nil
+	}
+
+	// Determine the digest we will sign.
+	digest := hash[:]
+	if g.randomProvider.Intn(2) == 0 {
+		digest = accounts.TextHash(hash[:])
+	}
+
+	// Sign our digest with a random signer key.
+	signature, err := crypto.Sign(digest, g.signerKeys[g.randomProvider.Intn(len(g.signerKeys))])
+	if err != nil {
+		return nil
+	}
+
+	// Convert our recovery identifier to the form expected by ecrecover.
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature
+}
@@ -0,0 +1,96 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// getTestNamedArguments creates abi.Arguments with the provided (alternating) names and type strings for testing.
+func getTestNamedArguments(t *testing.T, namesAndTypes ...string) abi.Arguments {
+	arguments := make(abi.Arguments, 0)
+	for i := 0; i < len(namesAndTypes); i += 2 {
+		argumentType, err := abi.NewType(namesAndTypes[i+1], "", nil)
+		assert.NoError(t, err)
+		arguments = append(arguments, abi.Argument{Name: namesAndTypes[i], Type: argumentType})
+	}
+	return arguments
+}
+
+// TestMatchSignatureArguments ensures signature and hash arguments are identified by their names and types.
+func TestMatchSignatureArguments(t *testing.T) {
+	// A v, r, s signature alongside a hash-like argument should be identified.
+	matched := MatchSignatureArguments(getTestNamedArguments(t, "amount", "uint256", "_hash", "bytes32", "v", "uint8", "r", "bytes32", "s", "bytes32"))
+	assert.EqualValues(t, &SignatureArguments{HashIndex: 1, VIndex: 2, RIndex: 3, SIndex: 4, SignatureIndex: -1}, matched)
+
+	// A bytes signature alongside the only other bytes32 argument should be identified.
+	matched = MatchSignatureArguments(getTestNamedArguments(t, "data", "bytes32", "signature", "bytes"))
+	assert.EqualValues(t, &SignatureArguments{HashIndex: 0, VIndex: -1, RIndex: -1, SIndex: -1, SignatureIndex: 1}, matched)
+
+	// A hash-like name should be preferred when there are multiple bytes32 arguments.
+	matched = MatchSignatureArguments(getTestNamedArguments(t, "salt", "bytes32", "messageDigest", "bytes32", "sig", "bytes"))
+	assert.EqualValues(t, 1, matched.HashIndex)
+
+	// Incomplete or ambiguous signatures should not be identified.
+	assert.Nil(t, MatchSignatureArguments(getTestNamedArguments(t, "hash", "bytes32", "v", "uint8", "r", "bytes32")))
+	assert.Nil(t, MatchSignatureArguments(getTestNamedArguments(t, "a", "bytes32", "b", "bytes32", "sig", "bytes")))
+	assert.Nil(t, MatchSignatureArguments(getTestNamedArguments(t, "data", "bytes", "amount", "uint256")))
+}
+
+// TestApplySignatureArguments ensures signatures applied to call arguments recover to a signer key, both for v, r, s
+// and bytes signature arguments.
+func TestApplySignatureArguments(t *testing.T) {
+	// Create a value generator which always signs.
+	signerKeys, err := utils.HexStringsToPrivateKeys([]string{"0x1", "0x2"})
+	assert.NoError(t, err)
+	signerAddresses := []common.Address{crypto.PubkeyToAddress(signerKeys[0].PublicKey), crypto.PubkeyToAddress(signerKeys[1].PublicKey)}
+	config := getTestMutatingValueGeneratorConfig()
+	config.GenerateValidSignatureProbability = 1
+	valueGenerator := NewMutatingValueGenerator(config, NewValueSet(), rand.New(rand.NewSource(time.Now().UnixNano())))
+	valueGenerator.SetSignerKeys(signerKeys)
+
+	// recoverSigner recovers the signer of a signature over the hash, either directly or as an EIP-191 signed message.
+	recoverSigner := func(hash [32]byte, signature []byte) common.Address {
+		assert.Len(t, signature, 65)
+		assert.Contains(t, []byte{27, 28}, signature[64])
+		normalizedSignature := append([]byte{}, signature...)
+		normalizedSignature[64] -= 27
+		for _, digest := range [][]byte{hash[:], accounts.TextHash(hash[:])} {
+			publicKey, err := crypto.SigToPub(digest, normalizedSignature)
+			if err == nil && (crypto.PubkeyToAddress(*publicKey) == signerAddresses[0] || crypto.PubkeyToAddress(*publicKey) == signerAddresses[1]) {
+				return crypto.PubkeyToAddress(*publicKey)
+			}
+		}
+		return common.Address{}
+	}
+
+	vrsArguments := getTestNamedArguments(t, "hash", "bytes32", "v", "uint8", "r", "bytes32", "s", "bytes32")
+	bytesArguments := getTestNamedArguments(t, "amount", "uint256", "hash", "bytes32", "signature", "bytes")
+	for i := 0; i < 100; i++ {
+		// Apply a v, r, s signature and verify it.
+		values := []any{GenerateAbiValue(valueGenerator, &vrsArguments[0].Type), uint8(0), [32]byte{}, [32]byte{}}
+		assert.True(t, ApplySignatureArguments(valueGenerator, vrsArguments, values))
+		r, s := values[2].([32]byte), values[3].([32]byte)
+		signature := append(append(r[:], s[:]...), values[1].(uint8))
+		assert.NotEqualValues(t, common.Address{}, recoverSigner(values[0].([32]byte), signature))
+
+		// Apply a bytes signature and verify it.
+		values = []any{big.NewInt(1), GenerateAbiValue(valueGenerator, &bytesArguments[1].Type), []byte{}}
+		assert.True(t, ApplySignatureArguments(valueGenerator, bytesArguments, values))
+		assert.NotEqualValues(t, common.Address{}, recoverSigner(values[1].([32]byte), values[2].([]byte)))
+	}
+
+	// A generator without signer keys should leave values untouched.
+	valueGenerator.SetSignerKeys(nil)
+	values := []any{[32]byte{1}, uint8(0), [32]byte{}, [32]byte{}}
+	assert.False(t, ApplySignatureArguments(valueGenerator, vrsArguments, values))
+	assert.EqualValues(t, uint8(0), values[1])
+}
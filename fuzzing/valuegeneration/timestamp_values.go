@@ -0,0 +1,90 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// TimestampArgumentKind describes the kind of time-related value an ABI argument was identified as holding.
+type TimestampArgumentKind int
+
+const (
+	// TimestampArgumentKindNone describes an argument which was not identified as holding a time-related value.
+	TimestampArgumentKindNone TimestampArgumentKind = iota
+	// TimestampArgumentKindTimestamp describes an argument which holds a point in time, such as a deadline, which is
+	// generally only meaningful when it is near the current block timestamp.
+	TimestampArgumentKindTimestamp
+	// TimestampArgumentKindDuration describes an argument which holds a length of time, such as a lock period, which
+	// is generally only meaningful when it is small.
+	TimestampArgumentKindDuration
+)
+
+// timestampArgumentNames describes substrings which identify an unsigned integer argument as a timestamp by its name.
+var timestampArgumentNames = []string{"deadline", "expir", "timestamp", "until", "validafter", "validbefore", "starttime", "endtime", "unlocktime", "releasetime"}
+
+// durationArgumentNames describes substrings which identify an unsigned integer argument as a duration by its name.
+var durationArgumentNames = []string{"duration", "period", "interval", "delay", "timeout", "cooldown", "lockup"}
+
+// MatchTimestampArgument uses a heuristic on the name and type of the provided method input argument to identify
+// whether it holds a timestamp or a duration. Only unsigned integer arguments are considered.
+// Returns the TimestampArgumentKind identified for the argument.
+func MatchTimestampArgument(input *abi.Argument) TimestampArgumentKind {
+	if input.Type.T != abi.UintTy {
+		return TimestampArgumentKindNone
+	}
+
+	// Normalize our name so that different naming conventions (e.g. "valid_after", "validAfter") match alike.
+	name := strings.ToLower(strings.ReplaceAll(input.Name, "_", ""))
+	for _, timestampName := range timestampArgumentNames {
+		if strings.Contains(name, timestampName) {
+			return TimestampArgumentKindTimestamp
+		}
+	}
+	for _, durationName := range durationArgumentNames {
+		if strings.Contains(name, durationName) {
+			return TimestampArgumentKindDuration
+		}
+	}
+	return TimestampArgumentKindNone
+}
+
+// IntegerToAbiValue converts the provided integer into the Go type used to represent a value of the provided
+// integer ABI type, constraining it to the type's bit length.
+// Returns the converted value, or nil if the ABI type is not an integer type.
+func IntegerToAbiValue(i *big.Int, inputType *abi.Type) any {
+	switch inputType.T {
+	case abi.UintTy:
+		b := utils.ConstrainIntegerToBitLength(i, false, inputType.Size)
+		switch inputType.Size {
+		case 64:
+			return b.Uint64()
+		case 32:
+			return uint32(b.Uint64())
+		case 16:
+			return uint16(b.Uint64())
+		case 8:
+			return uint8(b.Uint64())
+		default:
+			return b
+		}
+	case abi.IntTy:
+		b := utils.ConstrainIntegerToBitLength(i, true, inputType.Size)
+		switch inputType.Size {
+		case 64:
+			return b.Int64()
+		case 32:
+			return int32(b.Int64())
+		case 16:
+			return int16(b.Int64())
+		case 8:
+			return int8(b.Int64())
+		default:
+			return b
+		}
+	default:
+		return nil
+	}
+}
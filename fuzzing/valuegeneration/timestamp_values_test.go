@@ -0,0 +1,64 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMatchTimestampArgument ensures unsigned integer arguments are identified as timestamps or durations by their
+// names, regardless of naming convention.
+func TestMatchTimestampArgument(t *testing.T) {
+	uint256Type, err := abi.NewType("uint256", "", nil)
+	assert.NoError(t, err)
+	uint64Type, err := abi.NewType("uint64", "", nil)
+	assert.NoError(t, err)
+	int256Type, err := abi.NewType("int256", "", nil)
+	assert.NoError(t, err)
+
+	expectedKinds := map[string]TimestampArgumentKind{
+		"deadline":     TimestampArgumentKindTimestamp,
+		"_expiry":      TimestampArgumentKindTimestamp,
+		"expiresAt":    TimestampArgumentKindTimestamp,
+		"valid_after":  TimestampArgumentKindTimestamp,
+		"START_TIME":   TimestampArgumentKindTimestamp,
+		"duration":     TimestampArgumentKindDuration,
+		"lockPeriod":   TimestampArgumentKindDuration,
+		"cooldown_len": TimestampArgumentKindDuration,
+		"amount":       TimestampArgumentKindNone,
+		"times":        TimestampArgumentKindNone,
+		"":             TimestampArgumentKindNone,
+	}
+	for name, expectedKind := range expectedKinds {
+		assert.EqualValues(t, expectedKind, MatchTimestampArgument(&abi.Argument{Name: name, Type: uint256Type}), name)
+		assert.EqualValues(t, expectedKind, MatchTimestampArgument(&abi.Argument{Name: name, Type: uint64Type}), name)
+		assert.EqualValues(t, TimestampArgumentKindNone, MatchTimestampArgument(&abi.Argument{Name: name, Type: int256Type}), name)
+	}
+}
+
+// TestIntegerToAbiValue ensures integers are converted to the Go types expected for their ABI types, and constrained
+// to their bit lengths.
+func TestIntegerToAbiValue(t *testing.T) {
+	expectedValues := map[string]any{
+		"uint8":   uint8(0x34),
+		"uint16":  uint16(0x1234),
+		"uint32":  uint32(0x1234),
+		"uint64":  uint64(0x1234),
+		"uint256": big.NewInt(0x1234),
+		"int8":    int8(0x34),
+		"int64":   int64(0x1234),
+		"int256":  big.NewInt(0x1234),
+	}
+	for typeName, expectedValue := range expectedValues {
+		inputType, err := abi.NewType(typeName, "", nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, expectedValue, IntegerToAbiValue(big.NewInt(0x1234), &inputType), typeName)
+	}
+
+	// Non-integer types should not be converted.
+	boolType, err := abi.NewType("bool", "", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, IntegerToAbiValue(big.NewInt(1), &boolType))
+}
@@ -7,7 +7,8 @@ import (
 )
 
 // ValueGenerator represents an interface for a provider used to generate function inputs and call arguments for use
-// in fuzzing campaigns.
+// in fuzzing campaigns. This interface is considered stable, so custom implementations can be supplied to the fuzzer
+// through its NewValueGeneratorFunc hook without depending on the internals of the provided generators.
 type ValueGenerator interface {
 	// RandomProvider returns the internal random provider used for value generation.
 	RandomProvider() *rand.Rand
@@ -49,3 +50,18 @@ type ValueGenerator interface {
 	// MutateInteger takes an integer input and returns a mutated value based off the input.
 	MutateInteger(i *big.Int, signed bool, bitLength int) *big.Int
 }
+
+// ContractAwareValueGenerator represents an optional interface which a ValueGenerator may implement to be informed of
+// the contracts deployed in a fuzzing campaign, and of the contract a call is currently being generated for. This
+// allows such addresses to be prioritized when generating address inputs.
+type ContractAwareValueGenerator interface {
+	ValueGenerator
+
+	// AddDeployedContractAddress informs the generator of the address of a newly deployed contract.
+	AddDeployedContractAddress(address common.Address)
+	// RemoveDeployedContractAddress informs the generator that the contract at the provided address was removed.
+	RemoveDeployedContractAddress(address common.Address)
+	// SetTargetContractAddress informs the generator of the address of the contract which inputs are currently being
+	// generated for. A nil address indicates there is no target contract.
+	SetTargetContractAddress(address *common.Address)
+}
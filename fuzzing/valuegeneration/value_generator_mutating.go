@@ -1,7 +1,9 @@
 package valuegeneration
 
 import (
+	"crypto/ecdsa"
 	"github.com/crytic/medusa/utils"
+	"github.com/crytic/medusa/utils/randomutils"
 	"github.com/ethereum/go-ethereum/common"
 	"golang.org/x/exp/slices"
 	"math/big"
@@ -18,10 +20,51 @@ type MutatingValueGenerator struct {
 	// operations.
 	valueSet *ValueSet
 
+	// senderAddresses describes the addresses used to send transactions in the fuzzing campaign, which are used as
+	// special addresses in address generation.
+	senderAddresses []common.Address
+
+	// deployedContractAddresses describes the addresses of contracts deployed in the fuzzing campaign, which are used
+	// as special addresses in address generation.
+	deployedContractAddresses []common.Address
+
+	// targetContractAddress describes the address of the contract which inputs are currently being generated for, if
+	// any. It is used as a special address in address generation.
+	targetContractAddress *common.Address
+
+	// signerKeys describes the private keys used to produce valid signatures for signature arguments.
+	signerKeys []*ecdsa.PrivateKey
+
+	// integerMutationChooser is a weighted random selector of integer mutation strategies, used when generating or
+	// mutating integers.
+	integerMutationChooser *randomutils.WeightedRandomChooser[integerMutationStrategy]
+
+	// bytesMutationChooser is a weighted random selector of dynamic-sized byte array mutation methods, used in each
+	// bytes mutation round.
+	bytesMutationChooser *randomutils.WeightedRandomChooser[bytesMutationMethod]
+
+	// stringMutationChooser is a weighted random selector of string mutation methods, used in each string mutation
+	// round.
+	stringMutationChooser *randomutils.WeightedRandomChooser[stringMutationMethod]
+
 	// RandomValueGenerator is included to inherit from the random generator
 	*RandomValueGenerator
 }
 
+// precompileAddresses describes the addresses of the precompiled contracts, which are used as special addresses in
+// address generation.
+var precompileAddresses = []common.Address{
+	common.BytesToAddress([]byte{0x01}),
+	common.BytesToAddress([]byte{0x02}),
+	common.BytesToAddress([]byte{0x03}),
+	common.BytesToAddress([]byte{0x04}),
+	common.BytesToAddress([]byte{0x05}),
+	common.BytesToAddress([]byte{0x06}),
+	common.BytesToAddress([]byte{0x07}),
+	common.BytesToAddress([]byte{0x08}),
+	common.BytesToAddress([]byte{0x09}),
+}
+
 // MutatingValueGeneratorConfig defines the operating parameters for a MutatingValueGenerator.
 type MutatingValueGeneratorConfig struct {
 	// MinMutationRounds describes the minimum amount of mutations which should occur when generating a value.
@@ -37,6 +80,24 @@ type MutatingValueGeneratorConfig struct {
 	// entirely random, rather than selected from the ValueSet provided by MutatingValueGenerator.SetValueSet. Value
 	// range is [0.0, 1.0].
 	GenerateRandomAddressBias float32
+	// GenerateSpecialAddressBias defines the probability in which an address generated by the value generator is
+	// selected from a pool of special addresses (such as the zero address, precompiles, senders, or deployed
+	// contracts), chosen using the SpecialAddress*Weight fields. Value range is [0.0, 1.0].
+	GenerateSpecialAddressBias float32
+	// SpecialAddressZeroWeight defines the weight of the zero address being selected from the special address pool.
+	SpecialAddressZeroWeight uint64
+	// SpecialAddressPrecompileWeight defines the weight of a precompile address (0x01 to 0x09) being selected from the
+	// special address pool.
+	SpecialAddressPrecompileWeight uint64
+	// SpecialAddressSenderWeight defines the weight of a sender address being selected from the special address pool.
+	SpecialAddressSenderWeight uint64
+	// SpecialAddressDeployedContractWeight defines the weight of a deployed contract address being selected from the
+	// special address pool.
+	SpecialAddressDeployedContractWeight uint64
+	// SpecialAddressTargetContractWeight defines the weight of the address of the contract being called being selected
+	// from the special address pool.
+	SpecialAddressTargetContractWeight uint64
+
 	// GenerateRandomIntegerBias defines the probability in which an integer generated by the value generator is
 	// entirely random, rather than mutated. Value range is [0.0, 1.0].
 	GenerateRandomIntegerBias float32
@@ -62,6 +123,9 @@ type MutatingValueGeneratorConfig struct {
 	// MutateBytesGenerateNewBias defines the probability that when an existing dynamic-sized byte array will be
 	// mutated, it is done so by being replaced with a newly generated one instead. Value range is [0.0, 1.0].
 	MutateBytesGenerateNewBias float32
+	// BytesMutationWeights defines the weights of each dynamic-sized byte array mutation method applied in a
+	// mutation round. If nil, DefaultBytesMutationWeights is used.
+	BytesMutationWeights *BytesMutationWeights
 	// MutateFixedBytesProbability defines the probability in which an existing fixed-sized byte array value will be
 	// mutated by the value generator. Value range is [0.0, 1.0].
 	MutateFixedBytesProbability float32
@@ -71,12 +135,23 @@ type MutatingValueGeneratorConfig struct {
 	// MutateStringGenerateNewBias defines the probability that when an existing string will be mutated,
 	// it is done so by being replaced with a newly generated one instead. Value range is [0.0, 1.0].
 	MutateStringGenerateNewBias float32
+	// StringMutationWeights defines the weights of each string mutation method applied in a mutation round. If nil,
+	// DefaultStringMutationWeights is used.
+	StringMutationWeights *StringMutationWeights
 	// MutateIntegerProbability defines the probability in which an existing integer value will be mutated by
 	// the value generator. Value range is [0.0, 1.0].
 	MutateIntegerProbability float32
 	// MutateIntegerGenerateNewBias defines the probability that when an existing integer will be mutated,
 	// it is done so by being replaced with a newly generated one instead. Value range is [0.0, 1.0].
 	MutateIntegerGenerateNewBias float32
+	// IntegerMutationWeights defines the weights of each integer mutation strategy used when generating or mutating
+	// integers. If nil, DefaultIntegerMutationWeights is used.
+	IntegerMutationWeights *IntegerMutationWeights
+
+	// GenerateValidSignatureProbability defines the probability in which arguments identified as a signature are
+	// replaced with a valid signature over the hash argument, rather than left as randomly generated values. Value
+	// range is [0.0, 1.0].
+	GenerateValidSignatureProbability float32
 
 	// RandomValueGeneratorConfig is adhered to in this structure, to power the underlying RandomValueGenerator.
 	*RandomValueGeneratorConfig
@@ -90,6 +165,9 @@ func NewMutatingValueGenerator(config *MutatingValueGeneratorConfig, valueSet *V
 		valueSet:             valueSet,
 		RandomValueGenerator: NewRandomValueGenerator(config.RandomValueGeneratorConfig, randomProvider),
 	}
+	generator.integerMutationChooser = newIntegerMutationChooser(generator, config.IntegerMutationWeights)
+	generator.bytesMutationChooser = newBytesMutationChooser(generator, config.BytesMutationWeights)
+	generator.stringMutationChooser = newStringMutationChooser(generator, config.StringMutationWeights)
 
 	// Ensure some initial values this mutator will depend on for basic mutations to the set.
 	generator.valueSet.AddInteger(big.NewInt(0))
@@ -151,6 +229,22 @@ func (g *MutatingValueGenerator) mutateIntegerInternal(i *big.Int, signed bool,
 		return g.RandomValueGenerator.GenerateInteger(signed, bitLength)
 	}
 
+	// Select an integer mutation strategy. If all strategies were disabled, we fall back to arithmetic mutations.
+	mutationStrategy, err := g.integerMutationChooser.Choose()
+	if err != nil {
+		return integerMutationArithmetic(g, i, signed, bitLength)
+	}
+
+	// Apply our strategy and correct value boundaries (underflow/overflow)
+	min, max := utils.GetIntegerConstraints(signed, bitLength)
+	return utils.ConstrainIntegerToBounds((*mutationStrategy)(g, i, signed, bitLength), min, max)
+}
+
+// integerMutationArithmetic is an integer mutation strategy which applies a random number of arithmetic operations
+// to an integer input, using values from the ValueSet as operands. If a nil input is provided, a value from the
+// ValueSet is used as the starting point for mutation.
+// Returns the mutated integer.
+func integerMutationArithmetic(g *MutatingValueGenerator, i *big.Int, signed bool, bitLength int) *big.Int {
 	// Calculate our integer bounds
 	min, max := utils.GetIntegerConstraints(signed, bitLength)
 
@@ -186,63 +280,6 @@ func (g *MutatingValueGenerator) mutateIntegerInternal(i *big.Int, signed bool,
 	return input
 }
 
-// bytesMutationMethods define methods which take an initial bytes and a set of inputs to transform the input. The
-// transformed input is returned. This is used in a loop to mutate byte slices.
-var bytesMutationMethods = []func(*MutatingValueGenerator, []byte, ...[]byte) []byte{
-	// Replace a random index with a random byte
-	func(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
-		// Generate a random byte and replace an existing byte in our array with it. If our array has no bytes, we add
-		// it.
-		randomByteValue := byte(g.randomProvider.Intn(256))
-		if len(b) > 0 {
-			b[g.randomProvider.Intn(len(b))] = randomByteValue
-		} else {
-			b = append(b, randomByteValue)
-		}
-		return b
-	},
-	// Flip a random bit in it.
-	func(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
-		// If we have bytes in our array, flip a random bit in a random byte. Otherwise, we add a random byte.
-		if len(b) > 0 {
-			i := g.randomProvider.Intn(len(b))
-			b[i] = b[i] ^ (1 << (g.randomProvider.Intn(8)))
-		} else {
-			b = append(b, byte(g.randomProvider.Intn(256)))
-		}
-		return b
-	},
-	// Add a random byte at a random position
-	func(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
-		// Generate a random byte to insert
-		by := byte(g.randomProvider.Intn(256))
-
-		// If our provided byte array has no bytes, simply return a new array with this byte.
-		if len(b) == 0 {
-			return []byte{by}
-		}
-
-		// Determine the index to insert our byte into and insert it accordingly. We add +1 here as we allow appending
-		// to the end here.
-		i := g.randomProvider.Intn(len(b) + 1)
-		if i >= len(b) {
-			return append(b, by)
-		} else {
-			return append(b[:i], append([]byte{by}, b[i:]...)...)
-		}
-	},
-	// Remove a random byte
-	func(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
-		// If we have no bytes to remove, do nothing.
-		if len(b) == 0 {
-			return b
-		}
-
-		i := g.randomProvider.Intn(len(b))
-		return append(b[:i], b[i+1:]...)
-	},
-}
-
 // mutateBytesInternal takes a byte array and returns either a random new byte array, or a mutated value based off the
 // input.
 // If a nil input is provided, this method uses an existing base value set value as the starting point for mutation.
@@ -265,70 +302,17 @@ func (g *MutatingValueGenerator) mutateBytesInternal(b []byte) []byte {
 
 	// Mutate the data for our desired number of rounds
 	for i := 0; i < mutationCount; i++ {
-		input = bytesMutationMethods[g.randomProvider.Intn(len(bytesMutationMethods))](g, input, inputs...)
+		mutationMethod, err := g.bytesMutationChooser.Choose()
+		if err != nil {
+			// All bytes mutation methods were disabled, so we return our input as-is.
+			return input
+		}
+		input = (*mutationMethod)(g, input, inputs...)
 	}
 
 	return input
 }
 
-// stringMutationMethods define methods which take an initial string and a set of inputs to transform the input. The
-// transformed input is returned. This is used in a loop to mutate strings.
-var stringMutationMethods = []func(*MutatingValueGenerator, string, ...string) string{
-	// Replace a random index with a random character
-	func(g *MutatingValueGenerator, s string, inputs ...string) string {
-		// Generate a random rune
-		randomRune := rune(32 + g.randomProvider.Intn(95))
-
-		// If the string is empty, we can simply return a new string with just the rune in it.
-		r := []rune(s)
-		if len(r) == 0 {
-			return string(randomRune)
-		}
-
-		// Otherwise, we replace a rune in it and return it.
-		r[g.randomProvider.Intn(len(r))] = randomRune
-		return string(r)
-	},
-	// Flip a random bit
-	func(g *MutatingValueGenerator, s string, inputs ...string) string {
-		// If the string is empty, simply return a new one with a randomly added character.
-		r := []rune(s)
-		if len(r) == 0 {
-			return string(rune(32 + g.randomProvider.Int()%95))
-		}
-
-		// Otherwise, flip a random bit in it and return it.
-		i := g.randomProvider.Intn(len(r))
-		r[i] = r[i] ^ (1 << (g.randomProvider.Int() % 8))
-		return string(r)
-	},
-	// Insert a random character at a random position
-	func(g *MutatingValueGenerator, s string, inputs ...string) string {
-		// Create a random character.
-		c := string(rune(32 + g.randomProvider.Intn(95)))
-
-		// If we have an empty string, simply return it
-		if len(s) == 0 {
-			return c
-		}
-
-		// Otherwise we insert it into a random position in the string.
-		i := g.randomProvider.Intn(len(s))
-		return s[:i] + c + s[i+1:]
-	},
-	// Remove a random character
-	func(g *MutatingValueGenerator, s string, inputs ...string) string {
-		// If we have no characters to remove, do nothing
-		if len(s) == 0 {
-			return s
-		}
-
-		// Otherwise, remove a random character.
-		i := g.randomProvider.Intn(len(s))
-		return s[:i] + s[i+1:]
-	},
-}
-
 // mutateStringInternal takes a string and returns either a random new string, or a mutated value based off the input.
 // If a nil input is provided, this method uses an existing base value set value as the starting point for mutation.
 func (g *MutatingValueGenerator) mutateStringInternal(s *string) string {
@@ -350,14 +334,106 @@ func (g *MutatingValueGenerator) mutateStringInternal(s *string) string {
 
 	// Mutate the data for our desired number of rounds
 	for i := 0; i < mutationCount; i++ {
-		input = stringMutationMethods[g.randomProvider.Intn(len(stringMutationMethods))](g, input, inputs...)
+		mutationMethod, err := g.stringMutationChooser.Choose()
+		if err != nil {
+			// All string mutation methods were disabled, so we return our input as-is.
+			return input
+		}
+		input = (*mutationMethod)(g, input, inputs...)
 	}
 
 	return input
 }
 
-// GenerateAddress obtains an existing address from its underlying value set or generates a random one.
+// SetSenderAddresses sets the addresses used to send transactions in the fuzzing campaign, which are used as special
+// addresses in address generation.
+func (g *MutatingValueGenerator) SetSenderAddresses(addresses []common.Address) {
+	g.senderAddresses = slices.Clone(addresses)
+}
+
+// SetSignerKeys sets the private keys used to produce valid signatures for signature arguments.
+func (g *MutatingValueGenerator) SetSignerKeys(keys []*ecdsa.PrivateKey) {
+	g.signerKeys = slices.Clone(keys)
+}
+
+// AddDeployedContractAddress adds the address of a deployed contract, which is used as a special address in address
+// generation.
+func (g *MutatingValueGenerator) AddDeployedContractAddress(address common.Address) {
+	if !slices.Contains(g.deployedContractAddresses, address) {
+		g.deployedContractAddresses = append(g.deployedContractAddresses, address)
+	}
+}
+
+// RemoveDeployedContractAddress removes the address of a previously deployed contract, so it is no longer used as a
+// special address in address generation.
+func (g *MutatingValueGenerator) RemoveDeployedContractAddress(address common.Address) {
+	if i := slices.Index(g.deployedContractAddresses, address); i >= 0 {
+		g.deployedContractAddresses = slices.Delete(g.deployedContractAddresses, i, i+1)
+	}
+}
+
+// SetTargetContractAddress sets the address of the contract which inputs are currently being generated for, which is
+// used as a special address in address generation. A nil address indicates there is no target contract.
+func (g *MutatingValueGenerator) SetTargetContractAddress(address *common.Address) {
+	g.targetContractAddress = address
+}
+
+// generateSpecialAddress selects an address from the pool of special addresses, using the weights of each category
+// of special address provided by the config. Categories which have no addresses are not considered.
+// Returns the selected address, and a boolean indicating whether an address could be selected.
+func (g *MutatingValueGenerator) generateSpecialAddress() (common.Address, bool) {
+	// Determine the candidates for each category of special address.
+	var targetContractAddresses []common.Address
+	if g.targetContractAddress != nil {
+		targetContractAddresses = []common.Address{*g.targetContractAddress}
+	}
+	categories := []struct {
+		addresses []common.Address
+		weight    uint64
+	}{
+		{[]common.Address{{}}, g.config.SpecialAddressZeroWeight},
+		{precompileAddresses, g.config.SpecialAddressPrecompileWeight},
+		{g.senderAddresses, g.config.SpecialAddressSenderWeight},
+		{g.deployedContractAddresses, g.config.SpecialAddressDeployedContractWeight},
+		{targetContractAddresses, g.config.SpecialAddressTargetContractWeight},
+	}
+
+	// Determine the total weight of all categories with candidates.
+	totalWeight := uint64(0)
+	for _, category := range categories {
+		if len(category.addresses) > 0 {
+			totalWeight += category.weight
+		}
+	}
+	if totalWeight == 0 {
+		return common.Address{}, false
+	}
+
+	// Select a category by weight, then a random address from it.
+	selectedWeight := uint64(g.randomProvider.Int63n(int64(totalWeight)))
+	for _, category := range categories {
+		if len(category.addresses) == 0 {
+			continue
+		}
+		if selectedWeight < category.weight {
+			return category.addresses[g.randomProvider.Intn(len(category.addresses))], true
+		}
+		selectedWeight -= category.weight
+	}
+	return common.Address{}, false
+}
+
+// GenerateAddress obtains an existing address from its underlying value set or generates a random one. Addresses may
+// also be selected from a pool of special addresses, such as the zero address, precompiles, senders, or deployed
+// contracts.
 func (g *MutatingValueGenerator) GenerateAddress() common.Address {
+	// If our bias directs us to, select a special address.
+	if g.randomProvider.Float32() < g.config.GenerateSpecialAddressBias {
+		if address, ok := g.generateSpecialAddress(); ok {
+			return address
+		}
+	}
+
 	// If our bias directs us to, use the random generator instead
 	randomGeneratorDecision := g.randomProvider.Float32()
 	if randomGeneratorDecision < g.config.GenerateRandomAddressBias {
@@ -385,17 +461,89 @@ func (g *MutatingValueGenerator) MutateAddress(addr common.Address) common.Addre
 	return addr
 }
 
+// sliceStructureMutationMethods define methods which take a dynamic-sized array and transform its structure (e.g.
+// its length or the order of its elements). Elements which are nil in the returned array will be generated new by the
+// caller. This is used in a loop to mutate the structure of slices.
+var sliceStructureMutationMethods = []func(*MutatingValueGenerator, []any) []any{
+	// Insert a new element at a random position
+	func(g *MutatingValueGenerator, value []any) []any {
+		// If we are at our maximum size, do nothing.
+		if len(value) >= g.config.GenerateRandomArrayMaxSize {
+			return value
+		}
+
+		// Insert a nil element, which signals a new element should be generated in its place.
+		i := g.randomProvider.Intn(len(value) + 1)
+		return slices.Insert(value, i, nil)
+	},
+	// Remove a random element
+	func(g *MutatingValueGenerator, value []any) []any {
+		// If we have no elements to remove, do nothing.
+		if len(value) == 0 {
+			return value
+		}
+
+		i := g.randomProvider.Intn(len(value))
+		return slices.Delete(value, i, i+1)
+	},
+	// Duplicate a random element
+	func(g *MutatingValueGenerator, value []any) []any {
+		// If we have no elements to duplicate, or we are at our maximum size, do nothing.
+		if len(value) == 0 || len(value) >= g.config.GenerateRandomArrayMaxSize {
+			return value
+		}
+
+		// Insert a copy of the element directly after itself.
+		i := g.randomProvider.Intn(len(value))
+		return slices.Insert(value, i+1, value[i])
+	},
+	// Swap two random elements
+	arrayStructureMutationSwap,
+	// Truncate to an empty array
+	func(g *MutatingValueGenerator, value []any) []any {
+		return value[:0]
+	},
+}
+
+// fixedArrayStructureMutationMethods define methods which take a fixed-sized array and transform its structure
+// without altering its length. This is used in a loop to mutate the structure of fixed-sized arrays.
+var fixedArrayStructureMutationMethods = []func(*MutatingValueGenerator, []any) []any{
+	// Swap two random elements
+	arrayStructureMutationSwap,
+}
+
+// arrayStructureMutationSwap swaps two random elements of an array. Returns the mutated array.
+func arrayStructureMutationSwap(g *MutatingValueGenerator, value []any) []any {
+	// If we have less than two elements, there is nothing to swap.
+	if len(value) < 2 {
+		return value
+	}
+
+	i, j := g.randomProvider.Intn(len(value)), g.randomProvider.Intn(len(value))
+	value[i], value[j] = value[j], value[i]
+	return value
+}
+
 // MutateArray takes a dynamic or fixed sized array as input, and returns a mutated value based off of the input.
+// Dynamic-sized arrays may have elements inserted, removed, duplicated, swapped, or be truncated to empty, while
+// fixed-sized arrays may only have elements swapped.
 // Returns the mutated value. If any element of the returned array is nil, the value generator will be called upon
 // to generate it new.
 func (g *MutatingValueGenerator) MutateArray(value []any, fixedLength bool) []any {
 	// Determine whether to perform mutations against this input or just return it as-is.
 	randomGeneratorDecision := g.randomProvider.Float32()
 	if randomGeneratorDecision < g.config.MutateArrayStructureProbability {
-		// Determine how many mutations we'll apply
+		// Determine which structural mutations we can apply.
+		mutationMethods := sliceStructureMutationMethods
+		if fixedLength {
+			mutationMethods = fixedArrayStructureMutationMethods
+		}
+
+		// Determine how many mutations we'll apply, and apply them to a copy of our input.
+		value = slices.Clone(value)
 		_, mutationCount := g.getMutationParams(1)
 		for i := 0; i < mutationCount; i++ {
-			// TODO: Apply array structure mutations (swap, insert, delete)
+			value = mutationMethods[g.randomProvider.Intn(len(mutationMethods))](g, value)
 		}
 		return value
 	}
@@ -433,6 +581,35 @@ func (g *MutatingValueGenerator) MutateBytes(b []byte) []byte {
 	return b
 }
 
+// GenerateFixedBytes generates a fixed-sized byte array to use when populating inputs. If the underlying value set
+// contains byte sequences of the requested length, or known function selectors when four bytes are requested, one
+// may be returned instead of a random value.
+func (g *MutatingValueGenerator) GenerateFixedBytes(length int) []byte {
+	// If our bias directs us to, use the random generator instead
+	randomGeneratorDecision := g.randomProvider.Float32()
+	if randomGeneratorDecision < g.config.GenerateRandomBytesBias {
+		return g.RandomValueGenerator.GenerateFixedBytes(length)
+	}
+
+	// Collect all byte sequences in our value set which match our requested length, including function selectors if
+	// a selector's length was requested.
+	inputs := make([][]byte, 0)
+	for _, input := range g.valueSet.Bytes() {
+		if len(input) == length {
+			inputs = append(inputs, input)
+		}
+	}
+	if length == 4 {
+		inputs = append(inputs, g.valueSet.Selectors()...)
+	}
+
+	// If we have no candidates, generate a random value instead. Otherwise, return a copy of a random candidate.
+	if len(inputs) == 0 {
+		return g.RandomValueGenerator.GenerateFixedBytes(length)
+	}
+	return slices.Clone(inputs[g.randomProvider.Intn(len(inputs))])
+}
+
 // MutateFixedBytes takes a fixed-sized byte array input and returns a mutated value based off the input.
 func (g *MutatingValueGenerator) MutateFixedBytes(b []byte) []byte {
 	// Determine whether to perform mutations against this input or just return it as-is.
@@ -0,0 +1,278 @@
+package valuegeneration
+
+import (
+	"encoding/binary"
+	"math/big"
+	"sync"
+
+	"github.com/crytic/medusa/utils/randomutils"
+)
+
+// BytesMutationWeights defines the weights of each dynamic-sized byte array mutation method used by a
+// MutatingValueGenerator. In each mutation round, a mutation method is selected using these weights. A weight of zero
+// disables the mutation method. The mutation methods are modeled on AFL's havoc stage.
+type BytesMutationWeights struct {
+	// ReplaceByteWeight defines the weight of replacing a random byte with a random value.
+	ReplaceByteWeight uint64
+	// FlipBitWeight defines the weight of flipping a random bit.
+	FlipBitWeight uint64
+	// FlipByteWeight defines the weight of flipping all bits of a random byte.
+	FlipByteWeight uint64
+	// InsertByteWeight defines the weight of inserting a random byte at a random position.
+	InsertByteWeight uint64
+	// RemoveByteWeight defines the weight of removing a random byte.
+	RemoveByteWeight uint64
+	// ArithmeticWeight defines the weight of adding or subtracting a small value to a random 1, 2, 4, or 8 byte chunk,
+	// interpreted as a big-endian integer.
+	ArithmeticWeight uint64
+	// DeleteBlockWeight defines the weight of deleting a random block of bytes.
+	DeleteBlockWeight uint64
+	// DuplicateBlockWeight defines the weight of duplicating a random block of bytes in place.
+	DuplicateBlockWeight uint64
+	// OverwriteInterestingWeight defines the weight of overwriting a random 1, 2, 4, or 8 byte chunk with an
+	// interesting big-endian constant (e.g. 0x00, 0xFF, 0x7F, or integer boundaries).
+	OverwriteInterestingWeight uint64
+}
+
+// DefaultBytesMutationWeights returns the BytesMutationWeights used by a MutatingValueGenerator if none are provided
+// by its config.
+func DefaultBytesMutationWeights() *BytesMutationWeights {
+	return &BytesMutationWeights{
+		ReplaceByteWeight:          100,
+		FlipBitWeight:              100,
+		FlipByteWeight:             50,
+		InsertByteWeight:           100,
+		RemoveByteWeight:           100,
+		ArithmeticWeight:           50,
+		DeleteBlockWeight:          25,
+		DuplicateBlockWeight:       25,
+		OverwriteInterestingWeight: 50,
+	}
+}
+
+// bytesMutationMethod defines a method which takes an initial byte slice and a set of inputs to transform the input.
+// The transformed input is returned.
+type bytesMutationMethod func(*MutatingValueGenerator, []byte, ...[]byte) []byte
+
+// bytesMutationMaxArithmetic defines the largest value added to or subtracted from a chunk of bytes by the arithmetic
+// bytes mutation.
+const bytesMutationMaxArithmetic = 35
+
+// interestingBytesChunkSizes defines the sizes of chunks of bytes which arithmetic and interesting value overwrite
+// mutations operate on.
+var interestingBytesChunkSizes = []int{1, 2, 4, 8}
+
+// interestingIntegers defines integer values which are commonly at the boundaries of edge cases. These are written
+// as big-endian integers of different sizes when overwriting chunks of bytes.
+var interestingIntegers = []int64{
+	// 8-bit values
+	-128, -1, 0, 1, 16, 32, 64, 100, 127,
+	// 16-bit values
+	-32768, -129, 128, 255, 256, 512, 1000, 1024, 4096, 32767,
+	// 32-bit values
+	-2147483648, -100663046, -32769, 32768, 65535, 65536, 100663045, 2147483647,
+	// 64-bit values
+	-9223372036854775808, 4294967295, 4294967296, 9223372036854775807,
+}
+
+// newBytesMutationChooser creates a weighted random chooser of bytes mutation methods using the provided weights.
+// If no weights are provided, DefaultBytesMutationWeights is used.
+func newBytesMutationChooser(g *MutatingValueGenerator, weights *BytesMutationWeights) *randomutils.WeightedRandomChooser[bytesMutationMethod] {
+	if weights == nil {
+		weights = DefaultBytesMutationWeights()
+	}
+
+	chooser := randomutils.NewWeightedRandomChooserWithRand[bytesMutationMethod](g.randomProvider, &sync.Mutex{})
+	chooser.AddChoices(
+		randomutils.NewWeightedRandomChoice[bytesMutationMethod](bytesMutationReplaceByte, new(big.Int).SetUint64(weights.ReplaceByteWeight)),
+		randomutils.NewWeightedRandomChoice[bytesMutationMethod](bytesMutationFlipBit, new(big.Int).SetUint64(weights.FlipBitWeight)),
+		randomutils.NewWeightedRandomChoice[bytesMutationMethod](bytesMutationFlipByte, new(big.Int).SetUint64(weights.FlipByteWeight)),
+		randomutils.NewWeightedRandomChoice[bytesMutationMethod](bytesMutationInsertByte, new(big.Int).SetUint64(weights.InsertByteWeight)),
+		randomutils.NewWeightedRandomChoice[bytesMutationMethod](bytesMutationRemoveByte, new(big.Int).SetUint64(weights.RemoveByteWeight)),
+		randomutils.NewWeightedRandomChoice[bytesMutationMethod](bytesMutationArithmetic, new(big.Int).SetUint64(weights.ArithmeticWeight)),
+		randomutils.NewWeightedRandomChoice[bytesMutationMethod](bytesMutationDeleteBlock, new(big.Int).SetUint64(weights.DeleteBlockWeight)),
+		randomutils.NewWeightedRandomChoice[bytesMutationMethod](bytesMutationDuplicateBlock, new(big.Int).SetUint64(weights.DuplicateBlockWeight)),
+		randomutils.NewWeightedRandomChoice[bytesMutationMethod](bytesMutationOverwriteInteresting, new(big.Int).SetUint64(weights.OverwriteInterestingWeight)),
+	)
+	return chooser
+}
+
+// randomBytesChunk selects a random chunk of bytes of one of the interestingBytesChunkSizes, which fits within a byte
+// slice of the provided length.
+// Returns the start and end (exclusive) index of the chunk, or a boolean indicating no chunk could fit.
+func (g *MutatingValueGenerator) randomBytesChunk(length int) (int, int, bool) {
+	// Determine which chunk sizes fit in our byte slice.
+	chunkSizeCount := 0
+	for chunkSizeCount < len(interestingBytesChunkSizes) && interestingBytesChunkSizes[chunkSizeCount] <= length {
+		chunkSizeCount++
+	}
+	if chunkSizeCount == 0 {
+		return 0, 0, false
+	}
+
+	// Select a chunk size and position.
+	chunkSize := interestingBytesChunkSizes[g.randomProvider.Intn(chunkSizeCount)]
+	start := g.randomProvider.Intn(length - chunkSize + 1)
+	return start, start + chunkSize, true
+}
+
+// bytesMutationReplaceByte replaces a random index with a random byte.
+func bytesMutationReplaceByte(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
+	// Generate a random byte and replace an existing byte in our array with it. If our array has no bytes, we add
+	// it.
+	randomByteValue := byte(g.randomProvider.Intn(256))
+	if len(b) > 0 {
+		b[g.randomProvider.Intn(len(b))] = randomByteValue
+	} else {
+		b = append(b, randomByteValue)
+	}
+	return b
+}
+
+// bytesMutationFlipBit flips a random bit.
+func bytesMutationFlipBit(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
+	// If we have bytes in our array, flip a random bit in a random byte. Otherwise, we add a random byte.
+	if len(b) > 0 {
+		i := g.randomProvider.Intn(len(b))
+		b[i] = b[i] ^ (1 << (g.randomProvider.Intn(8)))
+	} else {
+		b = append(b, byte(g.randomProvider.Intn(256)))
+	}
+	return b
+}
+
+// bytesMutationFlipByte flips all bits of a random byte.
+func bytesMutationFlipByte(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
+	if len(b) > 0 {
+		i := g.randomProvider.Intn(len(b))
+		b[i] = ^b[i]
+	}
+	return b
+}
+
+// bytesMutationInsertByte adds a random byte at a random position, if doing so does not exceed the maximum
+// configured size.
+func bytesMutationInsertByte(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
+	// If we are at our maximum size, do nothing.
+	if len(b) >= g.config.GenerateRandomBytesMaxSize {
+		return b
+	}
+
+	// Generate a random byte to insert
+	by := byte(g.randomProvider.Intn(256))
+
+	// If our provided byte array has no bytes, simply return a new array with this byte.
+	if len(b) == 0 {
+		return []byte{by}
+	}
+
+	// Determine the index to insert our byte into and insert it accordingly. We add +1 here as we allow appending
+	// to the end here.
+	i := g.randomProvider.Intn(len(b) + 1)
+	if i >= len(b) {
+		return append(b, by)
+	} else {
+		return append(b[:i], append([]byte{by}, b[i:]...)...)
+	}
+}
+
+// bytesMutationRemoveByte removes a random byte.
+func bytesMutationRemoveByte(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
+	// If we have no bytes to remove, do nothing.
+	if len(b) == 0 {
+		return b
+	}
+
+	i := g.randomProvider.Intn(len(b))
+	return append(b[:i], b[i+1:]...)
+}
+
+// bytesMutationArithmetic adds or subtracts a small value to a random 1, 2, 4, or 8 byte chunk, interpreted as a
+// big-endian integer. The result wraps around on overflow.
+func bytesMutationArithmetic(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
+	// Select our chunk. If none fit, do nothing.
+	start, end, ok := g.randomBytesChunk(len(b))
+	if !ok {
+		return b
+	}
+
+	// Determine the value to add (or subtract).
+	delta := uint64(g.randomProvider.Intn(bytesMutationMaxArithmetic) + 1)
+	if g.randomProvider.Intn(2) == 0 {
+		delta = -delta
+	}
+
+	// Read our chunk as a big-endian integer, apply our delta, and write it back.
+	chunk := b[start:end]
+	switch len(chunk) {
+	case 1:
+		chunk[0] += byte(delta)
+	case 2:
+		binary.BigEndian.PutUint16(chunk, binary.BigEndian.Uint16(chunk)+uint16(delta))
+	case 4:
+		binary.BigEndian.PutUint32(chunk, binary.BigEndian.Uint32(chunk)+uint32(delta))
+	case 8:
+		binary.BigEndian.PutUint64(chunk, binary.BigEndian.Uint64(chunk)+delta)
+	}
+	return b
+}
+
+// bytesMutationDeleteBlock deletes a random block of bytes.
+func bytesMutationDeleteBlock(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
+	// If we have no bytes to remove, do nothing.
+	if len(b) == 0 {
+		return b
+	}
+
+	start := g.randomProvider.Intn(len(b))
+	end := start + g.randomProvider.Intn(len(b)-start) + 1
+	return append(b[:start], b[end:]...)
+}
+
+// bytesMutationDuplicateBlock duplicates a random block of bytes in place. The block is truncated so the result does
+// not exceed the maximum configured size.
+func bytesMutationDuplicateBlock(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
+	// If we have no bytes to duplicate, or we are at our maximum size, do nothing.
+	space := g.config.GenerateRandomBytesMaxSize - len(b)
+	if len(b) == 0 || space <= 0 {
+		return b
+	}
+
+	// Select our block, truncating it to fit in the remaining space.
+	start := g.randomProvider.Intn(len(b))
+	end := start + g.randomProvider.Intn(len(b)-start) + 1
+	if end-start > space {
+		end = start + space
+	}
+
+	// Insert a copy of the block directly after itself.
+	result := make([]byte, 0, len(b)+(end-start))
+	result = append(result, b[:end]...)
+	result = append(result, b[start:end]...)
+	return append(result, b[end:]...)
+}
+
+// bytesMutationOverwriteInteresting overwrites a random 1, 2, 4, or 8 byte chunk with an interesting big-endian
+// constant.
+func bytesMutationOverwriteInteresting(g *MutatingValueGenerator, b []byte, inputs ...[]byte) []byte {
+	// Select our chunk. If none fit, do nothing.
+	start, end, ok := g.randomBytesChunk(len(b))
+	if !ok {
+		return b
+	}
+
+	// Write an interesting value to our chunk, truncated to its size.
+	value := uint64(interestingIntegers[g.randomProvider.Intn(len(interestingIntegers))])
+	chunk := b[start:end]
+	switch len(chunk) {
+	case 1:
+		chunk[0] = byte(value)
+	case 2:
+		binary.BigEndian.PutUint16(chunk, uint16(value))
+	case 4:
+		binary.BigEndian.PutUint32(chunk, uint32(value))
+	case 8:
+		binary.BigEndian.PutUint64(chunk, value)
+	}
+	return b
+}
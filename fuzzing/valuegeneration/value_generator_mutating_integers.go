@@ -0,0 +1,131 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/crytic/medusa/utils"
+	"github.com/crytic/medusa/utils/randomutils"
+)
+
+// IntegerMutationWeights defines the weights of each integer mutation strategy used by a MutatingValueGenerator when
+// generating or mutating integers. A weight of zero disables the mutation strategy. Values are not copied from other
+// arguments of the same call by these strategies, but for every argument type by CopyCallArgumentValue, with the
+// probability configured by FuzzingConfig.CopyCallArgumentProbability.
+type IntegerMutationWeights struct {
+	// ArithmeticWeight defines the weight of applying random arithmetic operations to the input, using values in the
+	// ValueSet as operands.
+	ArithmeticWeight uint64
+	// BoundaryWeight defines the weight of producing a boundary value for the integer type: its min, min+1, max,
+	// max-1, 0, or 1.
+	BoundaryWeight uint64
+	// PowerOfTwoWeight defines the weight of producing a power of two (or a value adjacent to one).
+	PowerOfTwoWeight uint64
+	// DictionaryNeighborWeight defines the weight of producing a value adjacent to an integer in the ValueSet
+	// (+/-1, +/-2, *2, /2).
+	DictionaryNeighborWeight uint64
+}
+
+// DefaultIntegerMutationWeights returns the IntegerMutationWeights used by a MutatingValueGenerator if none are
+// provided by its config.
+func DefaultIntegerMutationWeights() *IntegerMutationWeights {
+	return &IntegerMutationWeights{
+		ArithmeticWeight:         100,
+		BoundaryWeight:           20,
+		PowerOfTwoWeight:         10,
+		DictionaryNeighborWeight: 20,
+	}
+}
+
+// integerMutationStrategy defines a method which takes an optional integer input (nil if a value is being generated
+// anew) and the properties of the integer type, and returns a new integer. The result is constrained to the bounds of
+// the integer type by the caller.
+type integerMutationStrategy func(g *MutatingValueGenerator, i *big.Int, signed bool, bitLength int) *big.Int
+
+// newIntegerMutationChooser creates a weighted random chooser of integer mutation strategies using the provided
+// weights. If no weights are provided, DefaultIntegerMutationWeights is used.
+func newIntegerMutationChooser(g *MutatingValueGenerator, weights *IntegerMutationWeights) *randomutils.WeightedRandomChooser[integerMutationStrategy] {
+	if weights == nil {
+		weights = DefaultIntegerMutationWeights()
+	}
+
+	chooser := randomutils.NewWeightedRandomChooserWithRand[integerMutationStrategy](g.randomProvider, &sync.Mutex{})
+	chooser.AddChoices(
+		randomutils.NewWeightedRandomChoice[integerMutationStrategy](integerMutationArithmetic, new(big.Int).SetUint64(weights.ArithmeticWeight)),
+		randomutils.NewWeightedRandomChoice[integerMutationStrategy](integerMutationBoundary, new(big.Int).SetUint64(weights.BoundaryWeight)),
+		randomutils.NewWeightedRandomChoice[integerMutationStrategy](integerMutationPowerOfTwo, new(big.Int).SetUint64(weights.PowerOfTwoWeight)),
+		randomutils.NewWeightedRandomChoice[integerMutationStrategy](integerMutationDictionaryNeighbor, new(big.Int).SetUint64(weights.DictionaryNeighborWeight)),
+	)
+	return chooser
+}
+
+// integerBoundaryValues returns the boundary values for an integer type with the provided properties: its min,
+// min+1, max, max-1, 0, and 1.
+func integerBoundaryValues(signed bool, bitLength int) []*big.Int {
+	min, max := utils.GetIntegerConstraints(signed, bitLength)
+	return []*big.Int{
+		min,
+		new(big.Int).Add(min, big.NewInt(1)),
+		max,
+		new(big.Int).Sub(max, big.NewInt(1)),
+		big.NewInt(0),
+		big.NewInt(1),
+	}
+}
+
+// integerMutationBoundary is an integer mutation strategy which produces a boundary value for the integer type.
+// Returns the boundary value.
+func integerMutationBoundary(g *MutatingValueGenerator, i *big.Int, signed bool, bitLength int) *big.Int {
+	boundaryValues := integerBoundaryValues(signed, bitLength)
+	return boundaryValues[g.randomProvider.Intn(len(boundaryValues))]
+}
+
+// integerMutationPowerOfTwo is an integer mutation strategy which produces a power of two that fits within the
+// integer type, or a value adjacent to one. For signed integers, the value may also be negated.
+// Returns the produced value.
+func integerMutationPowerOfTwo(g *MutatingValueGenerator, i *big.Int, signed bool, bitLength int) *big.Int {
+	// Determine the largest exponent which fits in our integer type.
+	maxExponent := bitLength - 1
+	if signed {
+		maxExponent--
+	}
+
+	// Create our power of two, then offset it by -1, 0, or +1.
+	value := new(big.Int).Lsh(big.NewInt(1), uint(g.randomProvider.Intn(maxExponent+1)))
+	value.Add(value, big.NewInt(int64(g.randomProvider.Intn(3)-1)))
+	if signed && g.randomProvider.Intn(2) == 0 {
+		value.Neg(value)
+	}
+	return value
+}
+
+// integerMutationDictionaryNeighbor is an integer mutation strategy which produces a value adjacent to an integer in
+// the ValueSet, by adding or subtracting one or two, doubling, or halving it. If the ValueSet is empty, the input is
+// used instead.
+// Returns the produced value.
+func integerMutationDictionaryNeighbor(g *MutatingValueGenerator, i *big.Int, signed bool, bitLength int) *big.Int {
+	// Select our base value.
+	integers := g.valueSet.Integers()
+	base := new(big.Int)
+	if len(integers) > 0 {
+		base.Set(integers[g.randomProvider.Intn(len(integers))])
+	} else if i != nil {
+		base.Set(i)
+	}
+
+	// Apply a random neighboring operation.
+	switch g.randomProvider.Intn(6) {
+	case 0:
+		return base.Add(base, big.NewInt(1))
+	case 1:
+		return base.Sub(base, big.NewInt(1))
+	case 2:
+		return base.Add(base, big.NewInt(2))
+	case 3:
+		return base.Sub(base, big.NewInt(2))
+	case 4:
+		return base.Mul(base, big.NewInt(2))
+	default:
+		return base.Quo(base, big.NewInt(2))
+	}
+}
@@ -0,0 +1,262 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/crytic/medusa/utils/randomutils"
+)
+
+// StringMutationWeights defines the weights of each string mutation method used by a MutatingValueGenerator. In each
+// mutation round, a mutation method is selected using these weights. A weight of zero disables the mutation method.
+type StringMutationWeights struct {
+	// ReplaceCharacterWeight defines the weight of replacing a random character with a random printable character.
+	ReplaceCharacterWeight uint64
+	// FlipBitWeight defines the weight of flipping a random bit in a random character.
+	FlipBitWeight uint64
+	// InsertCharacterWeight defines the weight of inserting a random printable character at a random position.
+	InsertCharacterWeight uint64
+	// RemoveCharacterWeight defines the weight of removing a random byte.
+	RemoveCharacterWeight uint64
+	// DuplicateBlockWeight defines the weight of duplicating a random block of the string in place.
+	DuplicateBlockWeight uint64
+	// DeleteBlockWeight defines the weight of deleting a random block of the string.
+	DeleteBlockWeight uint64
+	// FlipCaseWeight defines the weight of flipping the case of the letters in a random block of the string.
+	FlipCaseWeight uint64
+	// InsertUnicodeWeight defines the weight of inserting a multi-byte (or invalid) UTF-8 sequence at a random
+	// position.
+	InsertUnicodeWeight uint64
+	// EmptyStringWeight defines the weight of replacing the string with an empty string.
+	EmptyStringWeight uint64
+	// LongStringWeight defines the weight of extending the string up to the maximum configured string size.
+	LongStringWeight uint64
+	// InsertDangerousSubstringWeight defines the weight of inserting a known-dangerous substring (e.g. null bytes,
+	// format specifiers, or JSON metacharacters) at a random position.
+	InsertDangerousSubstringWeight uint64
+}
+
+// DefaultStringMutationWeights returns the StringMutationWeights used by a MutatingValueGenerator if none are provided
+// by its config.
+func DefaultStringMutationWeights() *StringMutationWeights {
+	return &StringMutationWeights{
+		ReplaceCharacterWeight:         100,
+		FlipBitWeight:                  100,
+		InsertCharacterWeight:          100,
+		RemoveCharacterWeight:          100,
+		DuplicateBlockWeight:           50,
+		DeleteBlockWeight:              50,
+		FlipCaseWeight:                 50,
+		InsertUnicodeWeight:            50,
+		EmptyStringWeight:              10,
+		LongStringWeight:               10,
+		InsertDangerousSubstringWeight: 50,
+	}
+}
+
+// stringMutationMethod defines a method which takes an initial string and a set of inputs to transform the input.
+// The transformed input is returned.
+type stringMutationMethod func(*MutatingValueGenerator, string, ...string) string
+
+// interestingUnicodeSequences describes multi-byte and invalid UTF-8 sequences which are inserted into strings by
+// the InsertUnicode string mutation.
+var interestingUnicodeSequences = []string{
+	"\u00e9",       // two-byte sequence (e with acute accent)
+	"\u4e2d",       // three-byte sequence (CJK ideograph)
+	"\U0001F600",   // four-byte sequence (emoji)
+	"\u200b",       // zero-width space
+	"\u202e",       // right-to-left override
+	"\ufeff",       // byte order mark
+	"\ufffd",       // replacement character
+	"\xc0\x80",     // overlong encoding of a null byte
+	"\xed\xa0\x80", // encoded surrogate half
+	"\xff",         // invalid byte
+	"\xe4\xb8",     // truncated three-byte sequence
+}
+
+// dangerousSubstrings describes substrings which commonly trigger bugs in string handling code, which are inserted
+// into strings by the InsertDangerousSubstring string mutation.
+var dangerousSubstrings = []string{
+	"\x00",
+	"%s",
+	"%n",
+	"%x",
+	"%%",
+	"\"",
+	"'",
+	"\\",
+	"{",
+	"}",
+	"[",
+	"]",
+	":",
+	",",
+	"\n",
+	"\r\n",
+	"<",
+	">",
+	"&",
+}
+
+// newStringMutationChooser creates a weighted random chooser of string mutation methods using the provided weights.
+// If no weights are provided, DefaultStringMutationWeights is used.
+func newStringMutationChooser(g *MutatingValueGenerator, weights *StringMutationWeights) *randomutils.WeightedRandomChooser[stringMutationMethod] {
+	if weights == nil {
+		weights = DefaultStringMutationWeights()
+	}
+
+	chooser := randomutils.NewWeightedRandomChooserWithRand[stringMutationMethod](g.randomProvider, &sync.Mutex{})
+	chooser.AddChoices(
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationReplaceCharacter, new(big.Int).SetUint64(weights.ReplaceCharacterWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationFlipBit, new(big.Int).SetUint64(weights.FlipBitWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationInsertCharacter, new(big.Int).SetUint64(weights.InsertCharacterWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationRemoveCharacter, new(big.Int).SetUint64(weights.RemoveCharacterWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationDuplicateBlock, new(big.Int).SetUint64(weights.DuplicateBlockWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationDeleteBlock, new(big.Int).SetUint64(weights.DeleteBlockWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationFlipCase, new(big.Int).SetUint64(weights.FlipCaseWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationInsertUnicode, new(big.Int).SetUint64(weights.InsertUnicodeWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationEmptyString, new(big.Int).SetUint64(weights.EmptyStringWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationLongString, new(big.Int).SetUint64(weights.LongStringWeight)),
+		randomutils.NewWeightedRandomChoice[stringMutationMethod](stringMutationInsertDangerousSubstring, new(big.Int).SetUint64(weights.InsertDangerousSubstringWeight)),
+	)
+	return chooser
+}
+
+// randomStringBlock selects a random, non-empty block within a string of the provided length.
+// Returns the start and end (exclusive) index of the block.
+func (g *MutatingValueGenerator) randomStringBlock(length int) (int, int) {
+	start := g.randomProvider.Intn(length)
+	end := start + g.randomProvider.Intn(length-start) + 1
+	return start, end
+}
+
+// insertString inserts the provided substring into a string at a random position (including the end of the string).
+func (g *MutatingValueGenerator) insertString(s string, substring string) string {
+	i := g.randomProvider.Intn(len(s) + 1)
+	return s[:i] + substring + s[i:]
+}
+
+// stringMutationReplaceCharacter replaces a random index with a random character.
+func stringMutationReplaceCharacter(g *MutatingValueGenerator, s string, inputs ...string) string {
+	// Generate a random rune
+	randomRune := rune(32 + g.randomProvider.Intn(95))
+
+	// If the string is empty, we can simply return a new string with just the rune in it.
+	r := []rune(s)
+	if len(r) == 0 {
+		return string(randomRune)
+	}
+
+	// Otherwise, we replace a rune in it and return it.
+	r[g.randomProvider.Intn(len(r))] = randomRune
+	return string(r)
+}
+
+// stringMutationFlipBit flips a random bit in a random character.
+func stringMutationFlipBit(g *MutatingValueGenerator, s string, inputs ...string) string {
+	// If the string is empty, simply return a new one with a randomly added character.
+	r := []rune(s)
+	if len(r) == 0 {
+		return string(rune(32 + g.randomProvider.Int()%95))
+	}
+
+	// Otherwise, flip a random bit in it and return it.
+	i := g.randomProvider.Intn(len(r))
+	r[i] = r[i] ^ (1 << (g.randomProvider.Int() % 8))
+	return string(r)
+}
+
+// stringMutationInsertCharacter inserts a random character at a random position.
+func stringMutationInsertCharacter(g *MutatingValueGenerator, s string, inputs ...string) string {
+	// Create a random character and insert it into a random position in the string.
+	c := string(rune(32 + g.randomProvider.Intn(95)))
+	return g.insertString(s, c)
+}
+
+// stringMutationRemoveCharacter removes a random character.
+func stringMutationRemoveCharacter(g *MutatingValueGenerator, s string, inputs ...string) string {
+	// If we have no characters to remove, do nothing
+	if len(s) == 0 {
+		return s
+	}
+
+	// Otherwise, remove a random character.
+	i := g.randomProvider.Intn(len(s))
+	return s[:i] + s[i+1:]
+}
+
+// stringMutationDuplicateBlock duplicates a random block of the string in place.
+func stringMutationDuplicateBlock(g *MutatingValueGenerator, s string, inputs ...string) string {
+	// If we have nothing to duplicate, do nothing.
+	if len(s) == 0 {
+		return s
+	}
+
+	// Insert a copy of the block directly after itself.
+	start, end := g.randomStringBlock(len(s))
+	return s[:end] + s[start:end] + s[end:]
+}
+
+// stringMutationDeleteBlock deletes a random block of the string.
+func stringMutationDeleteBlock(g *MutatingValueGenerator, s string, inputs ...string) string {
+	// If we have nothing to delete, do nothing.
+	if len(s) == 0 {
+		return s
+	}
+
+	start, end := g.randomStringBlock(len(s))
+	return s[:start] + s[end:]
+}
+
+// stringMutationFlipCase flips the case of all ASCII letters in a random block of the string.
+func stringMutationFlipCase(g *MutatingValueGenerator, s string, inputs ...string) string {
+	// If we have nothing to flip, do nothing.
+	if len(s) == 0 {
+		return s
+	}
+
+	// We operate on bytes and only touch ASCII letters, so any non-UTF8 content is left intact.
+	b := []byte(s)
+	start, end := g.randomStringBlock(len(b))
+	for i := start; i < end; i++ {
+		if (b[i] >= 'a' && b[i] <= 'z') || (b[i] >= 'A' && b[i] <= 'Z') {
+			b[i] ^= 0x20
+		}
+	}
+	return string(b)
+}
+
+// stringMutationInsertUnicode inserts a multi-byte or invalid UTF-8 sequence at a random position.
+func stringMutationInsertUnicode(g *MutatingValueGenerator, s string, inputs ...string) string {
+	return g.insertString(s, interestingUnicodeSequences[g.randomProvider.Intn(len(interestingUnicodeSequences))])
+}
+
+// stringMutationEmptyString replaces the string with an empty string.
+func stringMutationEmptyString(g *MutatingValueGenerator, s string, inputs ...string) string {
+	return ""
+}
+
+// stringMutationLongString extends the string by repeating its content, up to a random length no larger than the
+// maximum configured string size.
+func stringMutationLongString(g *MutatingValueGenerator, s string, inputs ...string) string {
+	// If we're already at our maximum size, do nothing.
+	maxSize := g.config.GenerateRandomStringMaxSize
+	if len(s) >= maxSize {
+		return s
+	}
+
+	// If we have no content to repeat, use a random character.
+	if len(s) == 0 {
+		s = string(rune(32 + g.randomProvider.Intn(95)))
+	}
+
+	// Repeat our content until we reach our target length, then truncate to it.
+	targetLength := len(s) + g.randomProvider.Intn(maxSize-len(s)+1)
+	return strings.Repeat(s, targetLength/len(s)+1)[:targetLength]
+}
+
+// stringMutationInsertDangerousSubstring inserts a known-dangerous substring at a random position.
+func stringMutationInsertDangerousSubstring(g *MutatingValueGenerator, s string, inputs ...string) string {
+	return g.insertString(s, dangerousSubstrings[g.randomProvider.Intn(len(dangerousSubstrings))])
+}
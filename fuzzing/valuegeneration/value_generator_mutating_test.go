@@ -0,0 +1,398 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
+)
+
+// getTestMutatingValueGeneratorConfig obtains a MutatingValueGeneratorConfig for use in testing the
+// MutatingValueGenerator.
+func getTestMutatingValueGeneratorConfig() *MutatingValueGeneratorConfig {
+	return &MutatingValueGeneratorConfig{
+		MinMutationRounds:                    0,
+		MaxMutationRounds:                    1,
+		GenerateRandomAddressBias:            0.5,
+		GenerateSpecialAddressBias:           0.5,
+		SpecialAddressZeroWeight:             1,
+		SpecialAddressPrecompileWeight:       1,
+		SpecialAddressSenderWeight:           1,
+		SpecialAddressDeployedContractWeight: 1,
+		SpecialAddressTargetContractWeight:   1,
+		GenerateRandomIntegerBias:            0.5,
+		GenerateRandomStringBias:             0.5,
+		GenerateRandomBytesBias:              0.5,
+		MutateAddressProbability:             0.8,
+		MutateArrayStructureProbability:      0.8,
+		MutateBoolProbability:                0.8,
+		MutateBytesProbability:               0.8,
+		MutateBytesGenerateNewBias:           0.45,
+		MutateFixedBytesProbability:          0.8,
+		MutateStringProbability:              0.8,
+		MutateStringGenerateNewBias:          0.7,
+		MutateIntegerProbability:             0.8,
+		MutateIntegerGenerateNewBias:         0.5,
+		RandomValueGeneratorConfig: &RandomValueGeneratorConfig{
+			GenerateRandomArrayMinSize:  0,
+			GenerateRandomArrayMaxSize:  100,
+			GenerateRandomBytesMinSize:  0,
+			GenerateRandomBytesMaxSize:  100,
+			GenerateRandomStringMinSize: 0,
+			GenerateRandomStringMaxSize: 100,
+		},
+	}
+}
+
+// TestMutatingValueGeneratorSpecialAddresses runs tests to ensure that all special addresses (the zero address,
+// precompiles, senders, deployed contracts, and the target contract) are generated by the MutatingValueGenerator.
+func TestMutatingValueGeneratorSpecialAddresses(t *testing.T) {
+	// Create a value generator
+	valueGenerator := NewMutatingValueGenerator(getTestMutatingValueGeneratorConfig(), NewValueSet(), rand.New(rand.NewSource(time.Now().UnixNano())))
+
+	// Define our special addresses.
+	senders := []common.Address{common.HexToAddress("0x10000"), common.HexToAddress("0x20000")}
+	deployedContracts := []common.Address{common.HexToAddress("0xA0000"), common.HexToAddress("0xB0000")}
+	removedContract := common.HexToAddress("0xC0000")
+	targetContract := common.HexToAddress("0xD0000")
+
+	// Inform our generator of the addresses.
+	valueGenerator.SetSenderAddresses(senders)
+	for _, deployedContract := range deployedContracts {
+		valueGenerator.AddDeployedContractAddress(deployedContract)
+	}
+	valueGenerator.AddDeployedContractAddress(removedContract)
+	valueGenerator.RemoveDeployedContractAddress(removedContract)
+	valueGenerator.SetTargetContractAddress(&targetContract)
+
+	// Collect the addresses we expect to see.
+	expectedAddresses := []common.Address{{}, targetContract}
+	expectedAddresses = append(expectedAddresses, precompileAddresses...)
+	expectedAddresses = append(expectedAddresses, senders...)
+	expectedAddresses = append(expectedAddresses, deployedContracts...)
+
+	// Generate many addresses and record which were seen.
+	generatedAddresses := make(map[common.Address]bool)
+	for i := 0; i < 10_000; i++ {
+		generatedAddresses[valueGenerator.GenerateAddress()] = true
+	}
+
+	// Verify every special address was generated, and our removed contract was not.
+	for _, expectedAddress := range expectedAddresses {
+		assert.True(t, generatedAddresses[expectedAddress], "special address %v was never generated", expectedAddress.String())
+	}
+	assert.False(t, generatedAddresses[removedContract], "removed contract address was generated")
+}
+
+// TestMutatingValueGeneratorStringMutations runs tests to ensure each string mutation method behaves as expected.
+func TestMutatingValueGeneratorStringMutations(t *testing.T) {
+	// Create a value generator
+	config := getTestMutatingValueGeneratorConfig()
+	valueGenerator := NewMutatingValueGenerator(config, NewValueSet(), rand.New(rand.NewSource(time.Now().UnixNano())))
+
+	// Define our inputs, including ones which are empty or contain non-UTF-8 content.
+	inputs := []string{"", "a", "Hello World", "\xff\xfe invalid \xc0", "中文"}
+	for _, input := range inputs {
+		for i := 0; i < 100; i++ {
+			// Test mutations which should alter the length of the string in a predictable way.
+			assert.Len(t, stringMutationInsertCharacter(valueGenerator, input), len(input)+1)
+			assert.LessOrEqual(t, len(stringMutationRemoveCharacter(valueGenerator, input)), len(input))
+			assert.GreaterOrEqual(t, len(stringMutationDuplicateBlock(valueGenerator, input)), len(input))
+			assert.LessOrEqual(t, len(stringMutationDeleteBlock(valueGenerator, input)), len(input))
+			assert.Len(t, stringMutationFlipCase(valueGenerator, input), len(input))
+			assert.Empty(t, stringMutationEmptyString(valueGenerator, input))
+
+			// Long strings should never exceed our configured maximum size.
+			longString := stringMutationLongString(valueGenerator, input)
+			assert.GreaterOrEqual(t, len(longString), len(input))
+			assert.LessOrEqual(t, len(longString), config.GenerateRandomStringMaxSize)
+
+			// Insertion of special sequences should retain the original content around it.
+			assert.Greater(t, len(stringMutationInsertUnicode(valueGenerator, input)), len(input))
+			assert.Greater(t, len(stringMutationInsertDangerousSubstring(valueGenerator, input)), len(input))
+		}
+	}
+
+}
+
+// TestMutatingValueGeneratorStringMutationWeights runs tests to ensure string mutation methods are selected according
+// to the configured weights.
+func TestMutatingValueGeneratorStringMutationWeights(t *testing.T) {
+	// Create a value generator which only ever applies the empty string mutation.
+	config := getTestMutatingValueGeneratorConfig()
+	config.GenerateRandomStringBias = 0
+	config.StringMutationWeights = &StringMutationWeights{EmptyStringWeight: 1}
+	valueSet := NewValueSet()
+	valueSet.AddString("base value")
+	valueGenerator := NewMutatingValueGenerator(config, valueSet, rand.New(rand.NewSource(time.Now().UnixNano())))
+
+	// Every mutation should either leave our input as-is (no mutation rounds), or empty it.
+	input := "Hello World"
+	for i := 0; i < 100; i++ {
+		mutated := valueGenerator.mutateStringInternal(&input)
+		assert.Contains(t, []string{"", input}, mutated)
+	}
+
+	// Create a value generator with all string mutations disabled, which should leave inputs unchanged.
+	config.StringMutationWeights = &StringMutationWeights{}
+	valueGenerator = NewMutatingValueGenerator(config, valueSet, rand.New(rand.NewSource(time.Now().UnixNano())))
+	for i := 0; i < 100; i++ {
+		assert.EqualValues(t, input, valueGenerator.mutateStringInternal(&input))
+	}
+}
+
+// TestMutatingValueGeneratorBytesMutations runs tests to ensure each dynamic-sized byte array mutation method behaves
+// as expected, and that mutations which grow the input do not exceed the configured maximum size.
+func TestMutatingValueGeneratorBytesMutations(t *testing.T) {
+	// Create a value generator
+	config := getTestMutatingValueGeneratorConfig()
+	valueGenerator := NewMutatingValueGenerator(config, NewValueSet(), rand.New(rand.NewSource(time.Now().UnixNano())))
+	maxSize := config.GenerateRandomBytesMaxSize
+
+	// Define our inputs, including ones which are empty or at the maximum size.
+	inputs := [][]byte{{}, {0x01}, {0x01, 0x02, 0x03}, make([]byte, 32), make([]byte, maxSize)}
+	for _, input := range inputs {
+		for i := 0; i < 100; i++ {
+			// Mutations which preserve length.
+			if len(input) > 0 {
+				assert.Len(t, bytesMutationReplaceByte(valueGenerator, slices.Clone(input)), len(input))
+				assert.Len(t, bytesMutationFlipBit(valueGenerator, slices.Clone(input)), len(input))
+			}
+			assert.Len(t, bytesMutationFlipByte(valueGenerator, slices.Clone(input)), len(input))
+			assert.Len(t, bytesMutationArithmetic(valueGenerator, slices.Clone(input)), len(input))
+			assert.Len(t, bytesMutationOverwriteInteresting(valueGenerator, slices.Clone(input)), len(input))
+
+			// Mutations which shrink the input.
+			assert.LessOrEqual(t, len(bytesMutationRemoveByte(valueGenerator, slices.Clone(input))), len(input))
+			assert.LessOrEqual(t, len(bytesMutationDeleteBlock(valueGenerator, slices.Clone(input))), len(input))
+
+			// Mutations which grow the input must stay within our configured bounds.
+			for _, mutated := range [][]byte{
+				bytesMutationInsertByte(valueGenerator, slices.Clone(input)),
+				bytesMutationDuplicateBlock(valueGenerator, slices.Clone(input)),
+			} {
+				assert.GreaterOrEqual(t, len(mutated), len(input))
+				assert.LessOrEqual(t, len(mutated), maxSize)
+			}
+		}
+	}
+
+	// Flipping a byte twice in a single byte input should restore it.
+	b := []byte{0x5A}
+	assert.EqualValues(t, []byte{0xA5}, bytesMutationFlipByte(valueGenerator, b))
+	assert.EqualValues(t, []byte{0x5A}, bytesMutationFlipByte(valueGenerator, b))
+
+	// Overwriting a single byte input with interesting values should produce the interesting byte values.
+	expectedBytes := map[byte]bool{0x00: false, 0xFF: false, 0x7F: false, 0x80: false}
+	for i := 0; i < 1000; i++ {
+		mutated := bytesMutationOverwriteInteresting(valueGenerator, []byte{0x42})
+		if _, ok := expectedBytes[mutated[0]]; ok {
+			expectedBytes[mutated[0]] = true
+		}
+	}
+	for expectedByte, seen := range expectedBytes {
+		assert.True(t, seen, "interesting byte value %x was never written", expectedByte)
+	}
+
+	// Arithmetic on a single byte input should never change it by more than our maximum arithmetic delta.
+	for i := 0; i < 1000; i++ {
+		mutated := bytesMutationArithmetic(valueGenerator, []byte{0x80})
+		delta := int(mutated[0]) - 0x80
+		assert.NotZero(t, delta)
+		assert.LessOrEqual(t, delta, bytesMutationMaxArithmetic)
+		assert.GreaterOrEqual(t, delta, -bytesMutationMaxArithmetic)
+	}
+}
+
+// TestMutatingValueGeneratorSelectors ensures function selectors in the value set are only provided for four-byte
+// values, such as bytes4 arguments and the selectors of function type arguments, and not for other byte sequences.
+func TestMutatingValueGeneratorSelectors(t *testing.T) {
+	// Create a value generator whose value set only contains a function selector.
+	selector := []byte{0xa9, 0x05, 0x9c, 0xbb}
+	valueSet := NewValueSet()
+	valueSet.AddSelector(selector)
+	valueGenerator := NewMutatingValueGenerator(getTestMutatingValueGeneratorConfig(), valueSet, rand.New(rand.NewSource(time.Now().UnixNano())))
+	assert.Empty(t, valueSet.Bytes())
+
+	// The selector should be generated for four-byte values only.
+	generatedSelector := false
+	for i := 0; i < 100; i++ {
+		generatedSelector = generatedSelector || slices.Equal(valueGenerator.GenerateFixedBytes(4), selector)
+		assert.NotEqualValues(t, selector, valueGenerator.GenerateBytes())
+	}
+	assert.True(t, generatedSelector)
+}
+
+// TestMutatingValueGeneratorArrayStructureMutations runs tests to ensure dynamic-sized arrays have their structure
+// (length and order) mutated, while fixed-sized arrays retain their length and elements.
+func TestMutatingValueGeneratorArrayStructureMutations(t *testing.T) {
+	// Create a value generator which always mutates array structure.
+	config := getTestMutatingValueGeneratorConfig()
+	config.MutateArrayStructureProbability = 1
+	config.MaxMutationRounds = 3
+	valueGenerator := NewMutatingValueGenerator(config, NewValueSet(), rand.New(rand.NewSource(time.Now().UnixNano())))
+
+	// Define our input array.
+	input := []any{1, 2, 3, 4, 5}
+
+	// Mutate dynamic-sized arrays, tracking the structural changes we observe.
+	var sawEmpty, sawShorter, sawLonger, sawNewElement, sawReordered bool
+	for i := 0; i < 1000; i++ {
+		mutated := valueGenerator.MutateArray(slices.Clone(input), false)
+		assert.LessOrEqual(t, len(mutated), config.GenerateRandomArrayMaxSize)
+
+		sawEmpty = sawEmpty || len(mutated) == 0
+		sawShorter = sawShorter || (len(mutated) > 0 && len(mutated) < len(input))
+		sawLonger = sawLonger || len(mutated) > len(input)
+		sawNewElement = sawNewElement || slices.IndexFunc(mutated, func(element any) bool { return element == nil }) >= 0
+		sawReordered = sawReordered || (len(mutated) == len(input) && slices.IndexFunc(mutated, func(element any) bool { return element == nil }) < 0 && !reflect.DeepEqual(mutated, input))
+	}
+	assert.True(t, sawEmpty, "dynamic-sized array was never truncated to empty")
+	assert.True(t, sawShorter, "dynamic-sized array never had elements removed")
+	assert.True(t, sawLonger, "dynamic-sized array never had elements inserted or duplicated")
+	assert.True(t, sawNewElement, "dynamic-sized array never had a new element inserted")
+	assert.True(t, sawReordered, "dynamic-sized array was never reordered")
+
+	// Mutate fixed-sized arrays, which should only ever be reordered.
+	sawReordered = false
+	for i := 0; i < 1000; i++ {
+		mutated := valueGenerator.MutateArray(slices.Clone(input), true)
+		assert.Len(t, mutated, len(input))
+		for _, element := range input {
+			assert.Contains(t, mutated, element)
+		}
+		sawReordered = sawReordered || !reflect.DeepEqual(mutated, input)
+	}
+	assert.True(t, sawReordered, "fixed-sized array was never reordered")
+
+	// Our original input should never be modified.
+	assert.EqualValues(t, []any{1, 2, 3, 4, 5}, input)
+}
+
+// TestMutatingValueGeneratorIntegerMutationWeights runs a statistical test to ensure integer boundary values are
+// generated with (at least) the bias described by the configured integer mutation weights, and that other integer
+// mutation strategies produce values as expected.
+func TestMutatingValueGeneratorIntegerMutationWeights(t *testing.T) {
+	// Create a value generator which chooses boundary values a quarter of the time.
+	config := getTestMutatingValueGeneratorConfig()
+	config.GenerateRandomIntegerBias = 0
+	config.IntegerMutationWeights = &IntegerMutationWeights{
+		ArithmeticWeight: 3,
+		BoundaryWeight:   1,
+	}
+	valueSet := NewValueSet()
+	valueSet.AddInteger(big.NewInt(12345))
+	valueGenerator := NewMutatingValueGenerator(config, valueSet, rand.New(rand.NewSource(time.Now().UnixNano())))
+
+	// Generate many uint256 values, counting how many fall on a boundary.
+	const iterations = 10_000
+	boundaryValues := integerBoundaryValues(false, 256)
+	boundaryCount := 0
+	for i := 0; i < iterations; i++ {
+		value := valueGenerator.GenerateInteger(false, 256)
+		for _, boundaryValue := range boundaryValues {
+			if value.Cmp(boundaryValue) == 0 {
+				boundaryCount++
+				break
+			}
+		}
+	}
+
+	// Boundary values should appear with at least the configured bias (with some tolerance). Arithmetic mutations
+	// may also produce boundary values, so we only check a lower bound.
+	assert.GreaterOrEqual(t, float64(boundaryCount)/iterations, 0.25*0.9)
+
+	// Test our other strategies in isolation.
+	strategyTests := []struct {
+		weights *IntegerMutationWeights
+		check   func(value *big.Int) bool
+	}{
+		{
+			// Powers of two (or adjacent values) should be within one of a power of two.
+			weights: &IntegerMutationWeights{PowerOfTwoWeight: 1},
+			check: func(value *big.Int) bool {
+				for _, offset := range []int64{-1, 0, 1} {
+					v := new(big.Int).Abs(new(big.Int).Add(value, big.NewInt(offset)))
+					if v.Sign() > 0 && new(big.Int).And(v, new(big.Int).Sub(v, big.NewInt(1))).Sign() == 0 {
+						return true
+					}
+				}
+				return value.Sign() == 0
+			},
+		},
+		{
+			// Dictionary neighbors should be derived from an integer in our ValueSet.
+			weights: &IntegerMutationWeights{DictionaryNeighborWeight: 1},
+			check: func(value *big.Int) bool {
+				for _, base := range valueSet.Integers() {
+					neighbors := []*big.Int{
+						new(big.Int).Add(base, big.NewInt(1)),
+						new(big.Int).Sub(base, big.NewInt(1)),
+						new(big.Int).Add(base, big.NewInt(2)),
+						new(big.Int).Sub(base, big.NewInt(2)),
+						new(big.Int).Mul(base, big.NewInt(2)),
+						new(big.Int).Quo(base, big.NewInt(2)),
+					}
+					for _, neighbor := range neighbors {
+						if value.Cmp(neighbor) == 0 {
+							return true
+						}
+					}
+				}
+				return false
+			},
+		},
+	}
+	for _, strategyTest := range strategyTests {
+		config.IntegerMutationWeights = strategyTest.weights
+		valueGenerator = NewMutatingValueGenerator(config, valueSet, rand.New(rand.NewSource(time.Now().UnixNano())))
+		for i := 0; i < 1000; i++ {
+			value := valueGenerator.GenerateInteger(true, 256)
+			assert.True(t, strategyTest.check(value), "unexpected value generated: %v", value)
+		}
+	}
+}
+
+// TestMutatingValueGeneratorSeedDeterminism ensures that two MutatingValueGenerator instances created with the same
+// seed generate the same values, even if their value sets were populated in a different order.
+func TestMutatingValueGeneratorSeedDeterminism(t *testing.T) {
+	// Create the values to populate our value sets with.
+	integers := make([]*big.Int, 0)
+	addresses := make([]common.Address, 0)
+	strings := make([]string, 0)
+	for i := 0; i < 50; i++ {
+		integers = append(integers, big.NewInt(int64(i*1000)))
+		addresses = append(addresses, common.BigToAddress(big.NewInt(int64(i+100))))
+		strings = append(strings, string(rune('a'+i%26))+"value")
+	}
+
+	// Create a generator whose value set is populated in the provided order.
+	createGenerator := func(reversed bool) *MutatingValueGenerator {
+		valueSet := NewValueSet()
+		for i := range integers {
+			index := i
+			if reversed {
+				index = len(integers) - 1 - i
+			}
+			valueSet.AddInteger(integers[index])
+			valueSet.AddAddress(addresses[index])
+			valueSet.AddString(strings[index])
+		}
+		return NewMutatingValueGenerator(getTestMutatingValueGeneratorConfig(), valueSet, rand.New(rand.NewSource(42)))
+	}
+	first := createGenerator(false)
+	second := createGenerator(true)
+
+	// Verify both generators produce the same values.
+	for i := 0; i < 1000; i++ {
+		assert.EqualValues(t, first.GenerateInteger(false, 256), second.GenerateInteger(false, 256))
+		assert.EqualValues(t, first.GenerateAddress(), second.GenerateAddress())
+		assert.EqualValues(t, first.GenerateString(), second.GenerateString())
+		assert.EqualValues(t, first.MutateInteger(big.NewInt(7), true, 128), second.MutateInteger(big.NewInt(7), true, 128))
+	}
+}
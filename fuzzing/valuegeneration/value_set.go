@@ -4,22 +4,28 @@ import (
 	"encoding/hex"
 	"hash"
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"golang.org/x/crypto/sha3"
-	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
-// ValueSet represents potential values of significance within the source code to be used in fuzz tests.
+// ValueSet represents potential values of significance within the source code to be used in fuzz tests. Values of
+// each type are kept in a deterministic order, so that random selections from the ValueSet are reproducible given the
+// same random seed, regardless of the order values were added in.
 type ValueSet struct {
-	// addresses represents a set of common.Address to use in fuzz tests. A mapping is used to avoid duplicates.
-	addresses map[common.Address]any
-	// integers represents a set of integers to use in fuzz tests. A mapping is used to avoid duplicates.
-	integers map[string]*big.Int
-	// strings represents a set of strings to use in fuzz tests. A mapping is used to avoid duplicates.
-	strings map[string]any
-	// bytes represents a set of bytes to use in fuzz tests. A mapping is used to avoid duplicates.
-	bytes map[string][]byte
+	// addresses represents a set of common.Address to use in fuzz tests.
+	addresses *sortedValueList[common.Address]
+	// integers represents a set of integers to use in fuzz tests.
+	integers *sortedValueList[*big.Int]
+	// strings represents a set of strings to use in fuzz tests.
+	strings *sortedValueList[string]
+	// bytes represents a set of bytes to use in fuzz tests.
+	bytes *sortedValueList[[]byte]
+	// selectors represents a set of function selectors to use in fuzz tests. They are kept apart from bytes, so that
+	// they are only provided for four-byte and function type values.
+	selectors *sortedValueList[[]byte]
 	// hashProvider represents a hash provider used to create keys for some data.
 	hashProvider hash.Hash
 }
@@ -27,10 +33,11 @@ type ValueSet struct {
 // NewValueSet initializes a new ValueSet object for use with a Fuzzer.
 func NewValueSet() *ValueSet {
 	baseValueSet := &ValueSet{
-		addresses:    make(map[common.Address]any, 0),
-		integers:     make(map[string]*big.Int, 0),
-		strings:      make(map[string]any, 0),
-		bytes:        make(map[string][]byte, 0),
+		addresses:    newSortedValueList[common.Address](),
+		integers:     newSortedValueList[*big.Int](),
+		strings:      newSortedValueList[string](),
+		bytes:        newSortedValueList[[]byte](),
+		selectors:    newSortedValueList[[]byte](),
 		hashProvider: sha3.NewLegacyKeccak256(),
 	}
 	return baseValueSet
@@ -39,10 +46,11 @@ func NewValueSet() *ValueSet {
 // Clone creates a copy of the current ValueSet.
 func (vs *ValueSet) Clone() *ValueSet {
 	baseValueSet := &ValueSet{
-		addresses:    maps.Clone(vs.addresses),
-		integers:     maps.Clone(vs.integers),
-		strings:      maps.Clone(vs.strings),
-		bytes:        maps.Clone(vs.bytes),
+		addresses:    vs.addresses.clone(),
+		integers:     vs.integers.clone(),
+		strings:      vs.strings.clone(),
+		bytes:        vs.bytes.clone(),
+		selectors:    vs.selectors.clone(),
 		hashProvider: sha3.NewLegacyKeccak256(),
 	}
 	return baseValueSet
@@ -50,95 +58,242 @@ func (vs *ValueSet) Clone() *ValueSet {
 
 // Addresses returns a list of addresses contained within the set.
 func (vs *ValueSet) Addresses() []common.Address {
-	res := make([]common.Address, len(vs.addresses))
-	count := 0
-	for k := range vs.addresses {
-		res[count] = k
-		count++
-	}
-	return res
+	return vs.addresses.list()
 }
 
 // AddAddress adds an address item to the ValueSet.
 func (vs *ValueSet) AddAddress(a common.Address) {
-	vs.addresses[a] = nil
+	vs.addresses.add(string(a.Bytes()), a)
+}
+
+// ContainsAddress checks whether an address item exists within the ValueSet.
+func (vs *ValueSet) ContainsAddress(a common.Address) bool {
+	return vs.addresses.contains(string(a.Bytes()))
 }
 
 // RemoveAddress removes an address item from the ValueSet.
 func (vs *ValueSet) RemoveAddress(a common.Address) {
-	delete(vs.addresses, a)
+	vs.addresses.remove(string(a.Bytes()))
 }
 
 // Integers returns a list of integers contained within the set.
 func (vs *ValueSet) Integers() []*big.Int {
-	res := make([]*big.Int, len(vs.integers))
-	count := 0
-	for _, v := range vs.integers {
-		res[count] = v
-		count++
-	}
-	return res
+	return vs.integers.list()
 }
 
 // AddInteger adds an integer item to the ValueSet.
 func (vs *ValueSet) AddInteger(b *big.Int) {
-	vs.integers[b.String()] = b
+	vs.integers.add(b.String(), b)
+}
+
+// ContainsInteger checks whether an integer item exists within the ValueSet.
+func (vs *ValueSet) ContainsInteger(b *big.Int) bool {
+	return vs.integers.contains(b.String())
 }
 
 // RemoveInteger removes an integer item from the ValueSet.
 func (vs *ValueSet) RemoveInteger(b *big.Int) {
-	delete(vs.integers, b.String())
+	vs.integers.remove(b.String())
 }
 
 // Strings returns a list of strings contained within the set.
 func (vs *ValueSet) Strings() []string {
-	res := make([]string, len(vs.strings))
-	count := 0
-	for k := range vs.strings {
-		res[count] = k
-		count++
-	}
-	return res
+	return vs.strings.list()
 }
 
 // AddString adds a string item to the ValueSet.
 func (vs *ValueSet) AddString(s string) {
-	vs.strings[s] = nil
+	vs.strings.add(s, s)
+}
+
+// ContainsString checks whether a string item exists within the ValueSet.
+func (vs *ValueSet) ContainsString(s string) bool {
+	return vs.strings.contains(s)
 }
 
 // RemoveString removes a string item from the ValueSet.
 func (vs *ValueSet) RemoveString(s string) {
-	delete(vs.strings, s)
+	vs.strings.remove(s)
 }
 
 // Bytes returns a list of bytes contained within the set.
 func (vs *ValueSet) Bytes() [][]byte {
-	res := make([][]byte, len(vs.bytes))
-	count := 0
-	for _, v := range vs.bytes {
-		res[count] = v
-		count++
-	}
-	return res
+	return vs.bytes.list()
 }
 
-// AddBytes adds a byte sequence to the ValueSet.
-func (vs *ValueSet) AddBytes(b []byte) {
+// bytesKey calculates the key used to store a byte sequence in the ValueSet.
+func (vs *ValueSet) bytesKey(b []byte) string {
 	// Calculate hash and reset our hash provider
 	vs.hashProvider.Write(b)
 	hashStr := hex.EncodeToString(vs.hashProvider.Sum(nil))
 	vs.hashProvider.Reset()
+	return hashStr
+}
 
-	// Add our hash to our "set" (map)
-	vs.bytes[hashStr] = b
+// AddBytes adds a byte sequence to the ValueSet.
+func (vs *ValueSet) AddBytes(b []byte) {
+	vs.bytes.add(vs.bytesKey(b), b)
+}
+
+// ContainsBytes checks whether a byte sequence item exists within the ValueSet.
+func (vs *ValueSet) ContainsBytes(b []byte) bool {
+	return vs.bytes.contains(vs.bytesKey(b))
 }
 
 // RemoveBytes removes a byte sequence item from the ValueSet.
 func (vs *ValueSet) RemoveBytes(b []byte) {
-	// Calculate hash and reset our hash provider
-	vs.hashProvider.Write(b)
-	hashStr := hex.EncodeToString(vs.hashProvider.Sum(nil))
-	vs.hashProvider.Reset()
+	vs.bytes.remove(vs.bytesKey(b))
+}
+
+// Selectors returns a list of function selectors contained within the set.
+func (vs *ValueSet) Selectors() [][]byte {
+	return vs.selectors.list()
+}
+
+// AddSelector adds a four-byte function selector to the ValueSet. Selectors are not provided as byte sequences
+// through Bytes.
+func (vs *ValueSet) AddSelector(selector []byte) {
+	vs.selectors.add(hex.EncodeToString(selector), slices.Clone(selector))
+}
+
+// ContainsSelector checks whether a function selector exists within the ValueSet.
+func (vs *ValueSet) ContainsSelector(selector []byte) bool {
+	return vs.selectors.contains(hex.EncodeToString(selector))
+}
+
+// RemoveSelector removes a function selector from the ValueSet.
+func (vs *ValueSet) RemoveSelector(selector []byte) {
+	vs.selectors.remove(hex.EncodeToString(selector))
+}
+
+// EvictOldest removes the least recently added values of each type from the ValueSet, until at most maxValuesPerType
+// values of each type remain.
+func (vs *ValueSet) EvictOldest(maxValuesPerType int) {
+	vs.addresses.evictOldest(maxValuesPerType)
+	vs.integers.evictOldest(maxValuesPerType)
+	vs.strings.evictOldest(maxValuesPerType)
+	vs.bytes.evictOldest(maxValuesPerType)
+}
+
+// sortedValueList describes a set of values, each identified by a unique key. Values are kept sorted by their keys,
+// so the order in which they are listed does not depend on the order they were added in. The order values were last
+// added in is still tracked, so the least recently added values may be evicted.
+type sortedValueList[T any] struct {
+	// keys describes the sorted keys of every value in the list.
+	keys []string
+	// values describes the values in the list, where each index corresponds to the same index in keys.
+	values []T
+	// additions describes when each value in the list was last added, where each index corresponds to the same index
+	// in keys. Greater numbers indicate more recent additions.
+	additions []uint64
+	// additionCount describes the amount of additions made to the list, used to number the next addition.
+	additionCount uint64
+}
+
+// newSortedValueList creates a new, empty sortedValueList.
+func newSortedValueList[T any]() *sortedValueList[T] {
+	return &sortedValueList[T]{
+		keys:      make([]string, 0),
+		values:    make([]T, 0),
+		additions: make([]uint64, 0),
+	}
+}
+
+// clone creates a copy of the sortedValueList.
+func (l *sortedValueList[T]) clone() *sortedValueList[T] {
+	return &sortedValueList[T]{
+		keys:          slices.Clone(l.keys),
+		values:        slices.Clone(l.values),
+		additions:     slices.Clone(l.additions),
+		additionCount: l.additionCount,
+	}
+}
+
+// list returns a copy of the values in the sortedValueList.
+func (l *sortedValueList[T]) list() []T {
+	return slices.Clone(l.values)
+}
+
+// listByAddition returns a copy of the values in the sortedValueList, ordered from the least to the most recently
+// added.
+func (l *sortedValueList[T]) listByAddition() []T {
+	indexes := l.indexesByAddition()
+	values := make([]T, len(indexes))
+	for i, index := range indexes {
+		values[i] = l.values[index]
+	}
+	return values
+}
 
-	delete(vs.bytes, hashStr)
+// indexesByAddition returns the indexes of the values in the sortedValueList, ordered from the least to the most
+// recently added value.
+func (l *sortedValueList[T]) indexesByAddition() []int {
+	indexes := make([]int, len(l.values))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return l.additions[indexes[i]] < l.additions[indexes[j]]
+	})
+	return indexes
+}
+
+// len returns the count of values in the sortedValueList.
+func (l *sortedValueList[T]) len() int {
+	return len(l.values)
+}
+
+// add adds a value with the provided key to the sortedValueList, replacing any existing value with the same key. The
+// value is marked as the most recently added one.
+func (l *sortedValueList[T]) add(key string, value T) {
+	l.additionCount++
+	index, exists := slices.BinarySearch(l.keys, key)
+	if exists {
+		l.values[index] = value
+		l.additions[index] = l.additionCount
+		return
+	}
+	l.keys = slices.Insert(l.keys, index, key)
+	l.values = slices.Insert(l.values, index, value)
+	l.additions = slices.Insert(l.additions, index, l.additionCount)
+}
+
+// contains checks whether a value with the provided key exists within the sortedValueList.
+func (l *sortedValueList[T]) contains(key string) bool {
+	_, exists := slices.BinarySearch(l.keys, key)
+	return exists
+}
+
+// remove removes the value with the provided key from the sortedValueList, if it exists.
+func (l *sortedValueList[T]) remove(key string) {
+	index, exists := slices.BinarySearch(l.keys, key)
+	if exists {
+		l.keys = slices.Delete(l.keys, index, index+1)
+		l.values = slices.Delete(l.values, index, index+1)
+		l.additions = slices.Delete(l.additions, index, index+1)
+	}
+}
+
+// evictOldest removes the least recently added values from the sortedValueList, until at most maxValues remain.
+func (l *sortedValueList[T]) evictOldest(maxValues int) {
+	if maxValues < 0 || len(l.values) <= maxValues {
+		return
+	}
+
+	// Determine which values were added least recently, and keep the rest in their sorted order.
+	evicted := make(map[int]bool)
+	for _, index := range l.indexesByAddition()[:len(l.values)-maxValues] {
+		evicted[index] = true
+	}
+	keys := make([]string, 0, maxValues)
+	values := make([]T, 0, maxValues)
+	additions := make([]uint64, 0, maxValues)
+	for i := range l.values {
+		if !evicted[i] {
+			keys = append(keys, l.keys[i])
+			values = append(values, l.values[i])
+			additions = append(additions, l.additions[i])
+		}
+	}
+	l.keys, l.values, l.additions = keys, values, additions
 }
@@ -38,6 +38,14 @@ func (vs *ValueSet) SeedFromAst(ast any) {
 			} else if literalKind == "string" {
 				vs.AddString(literalValue)
 			}
+		} else if obtainedNodeType && strings.EqualFold(nodeType, "EnumDefinition") {
+			// Seed ValueSet with the bounds of the enum: its largest valid value, and the first invalid one.
+			members, obtainedMembers := node["members"].([]any)
+			if !obtainedMembers || len(members) == 0 {
+				return // fail silently to continue walking
+			}
+			vs.AddInteger(big.NewInt(int64(len(members) - 1)))
+			vs.AddInteger(big.NewInt(int64(len(members))))
 		}
 	})
 }
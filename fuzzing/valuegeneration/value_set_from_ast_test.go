@@ -0,0 +1,35 @@
+package valuegeneration
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSeedFromAstEnumBounds ensures the bounds of enums declared in an AST are seeded into a ValueSet: the largest
+// valid value of each enum, and the first invalid one.
+func TestSeedFromAstEnumBounds(t *testing.T) {
+	ast := map[string]any{
+		"id":       1,
+		"nodeType": "SourceUnit",
+		"nodes": []any{
+			map[string]any{
+				"id":       2,
+				"nodeType": "EnumDefinition",
+				"members":  []any{map[string]any{"name": "A"}, map[string]any{"name": "B"}, map[string]any{"name": "C"}},
+			},
+			map[string]any{
+				"id":       3,
+				"nodeType": "EnumDefinition",
+				"members":  []any{},
+			},
+		},
+	}
+
+	vs := NewValueSet()
+	vs.SeedFromAst(ast)
+	assert.True(t, vs.ContainsInteger(big.NewInt(2)))
+	assert.True(t, vs.ContainsInteger(big.NewInt(3)))
+	assert.Len(t, vs.Integers(), 2)
+}
@@ -0,0 +1,59 @@
+package valuegeneration
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"math/big"
+)
+
+// SeedFromBytecode allows a ValueSet to be seeded from the constants pushed onto the stack by the provided EVM
+// bytecode (e.g. PUSH1 to PUSH32 operands). Each type of value extracted can be enabled or disabled independently.
+// Operands which are immediately used as jump destinations are ignored, as they are not meaningful values.
+func (vs *ValueSet) SeedFromBytecode(bytecode []byte, seedIntegers bool, seedAddresses bool, seedBytes bool) {
+	// If we aren't seeding any values, there is no work to do.
+	if !seedIntegers && !seedAddresses && !seedBytes {
+		return
+	}
+
+	// Walk every instruction in our bytecode.
+	for pc := 0; pc < len(bytecode); pc++ {
+		// If this isn't a push instruction, skip it.
+		op := vm.OpCode(bytecode[pc])
+		if op < vm.PUSH1 || op > vm.PUSH32 {
+			continue
+		}
+
+		// Determine the operand size and ensure it is within bounds (e.g. it could be truncated by metadata).
+		operandSize := int(op-vm.PUSH1) + 1
+		operandStart := pc + 1
+		operandEnd := operandStart + operandSize
+		if operandEnd > len(bytecode) {
+			return
+		}
+		operand := bytecode[operandStart:operandEnd]
+
+		// Advance our program counter past the operand.
+		pc = operandEnd - 1
+
+		// If the next instruction uses this operand as a jump destination, skip it.
+		if operandEnd < len(bytecode) {
+			nextOp := vm.OpCode(bytecode[operandEnd])
+			if nextOp == vm.JUMP || nextOp == vm.JUMPI {
+				continue
+			}
+		}
+
+		// Seed our ValueSet with the operand.
+		if seedIntegers {
+			b := new(big.Int).SetBytes(operand)
+			vs.AddInteger(b)
+			vs.AddInteger(new(big.Int).Neg(b))
+		}
+		if seedAddresses && operandSize == common.AddressLength {
+			vs.AddAddress(common.BytesToAddress(operand))
+		}
+		if seedBytes && operandSize == common.HashLength {
+			vs.AddBytes(common.CopyBytes(operand))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package valuegeneration
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSeedFromBytecode ensures PUSH operands in bytecode are seeded into a ValueSet as the types of values enabled,
+// while jump destinations and operands truncated by the end of the bytecode are ignored.
+func TestSeedFromBytecode(t *testing.T) {
+	address := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	word := bytes.Repeat([]byte{0xAB}, common.HashLength)
+	bytecode := []byte{byte(vm.PUSH2), 0x12, 0x34, byte(vm.POP)}
+	bytecode = append(append(bytecode, byte(vm.PUSH20)), address.Bytes()...)
+	bytecode = append(append(bytecode, byte(vm.PUSH32)), word...)
+	bytecode = append(bytecode, byte(vm.PUSH1), 0x77, byte(vm.JUMP))
+	bytecode = append(bytecode, byte(vm.PUSH4), 0x99, 0x99)
+
+	// Every type of value should be seeded when enabled.
+	vs := NewValueSet()
+	vs.SeedFromBytecode(bytecode, true, true, true)
+	assert.True(t, vs.ContainsInteger(big.NewInt(0x1234)))
+	assert.True(t, vs.ContainsInteger(big.NewInt(-0x1234)))
+	assert.True(t, vs.ContainsInteger(new(big.Int).SetBytes(address.Bytes())))
+	assert.True(t, vs.ContainsAddress(address))
+	assert.True(t, vs.ContainsBytes(word))
+	assert.Len(t, vs.Addresses(), 1)
+	assert.Len(t, vs.Bytes(), 1)
+
+	// Jump destinations and truncated operands should be ignored.
+	assert.False(t, vs.ContainsInteger(big.NewInt(0x77)))
+	assert.False(t, vs.ContainsInteger(big.NewInt(0x9999)))
+	assert.Len(t, vs.Integers(), 6)
+
+	// Only the types of values enabled should be seeded.
+	vs = NewValueSet()
+	vs.SeedFromBytecode(bytecode, false, true, false)
+	assert.Empty(t, vs.Integers())
+	assert.Empty(t, vs.Bytes())
+	assert.True(t, vs.ContainsAddress(address))
+	vs = NewValueSet()
+	vs.SeedFromBytecode(bytecode, false, false, false)
+	assert.Empty(t, vs.Integers())
+	assert.Empty(t, vs.Addresses())
+	assert.Empty(t, vs.Bytes())
+}
@@ -0,0 +1,121 @@
+package valuegeneration
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// valueSetJSONVersion describes the version of the format used to serialize a ValueSet to JSON. It should be
+// incremented whenever the format changes in a way which is not backwards compatible.
+const valueSetJSONVersion = 1
+
+// valueSetJSON describes the JSON representation of a ValueSet. Values of each type are stored in separately typed
+// lists, so they are not mis-categorized when they are loaded.
+type valueSetJSON struct {
+	// Version describes the version of the format the ValueSet was serialized with.
+	Version int `json:"version"`
+
+	// Addresses describes the hex-encoded addresses in the ValueSet.
+	Addresses []common.Address `json:"addresses"`
+
+	// Integers describes the base-10 encoded integers in the ValueSet.
+	Integers []string `json:"integers"`
+
+	// Strings describes the hex-encoded strings in the ValueSet. Strings are hex-encoded as they may not be valid
+	// UTF-8, and would otherwise be corrupted by JSON serialization.
+	Strings []string `json:"strings"`
+
+	// Bytes describes the hex-encoded byte sequences in the ValueSet.
+	Bytes []hexutil.Bytes `json:"bytes"`
+}
+
+// MarshalJSON provides custom JSON marshalling for the ValueSet, recording each type of value separately along with
+// a format version. Values are recorded from the least to the most recently added, so this order is kept when they
+// are loaded again.
+// Returns the JSON encoded data, or an error if one occurs.
+func (vs *ValueSet) MarshalJSON() ([]byte, error) {
+	// Create our JSON representation.
+	out := valueSetJSON{
+		Version:   valueSetJSONVersion,
+		Addresses: vs.addresses.listByAddition(),
+		Integers:  make([]string, 0, vs.integers.len()),
+		Strings:   make([]string, 0, vs.strings.len()),
+		Bytes:     make([]hexutil.Bytes, 0, vs.bytes.len()),
+	}
+	for _, i := range vs.integers.listByAddition() {
+		out.Integers = append(out.Integers, i.String())
+	}
+	for _, s := range vs.strings.listByAddition() {
+		out.Strings = append(out.Strings, hex.EncodeToString([]byte(s)))
+	}
+	for _, b := range vs.bytes.listByAddition() {
+		out.Bytes = append(out.Bytes, b)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON provides custom JSON unmarshalling for the ValueSet. Values are added to any existing values in the
+// ValueSet, in the order they were recorded.
+// Returns an error if the data could not be parsed, or was serialized with an unsupported format version.
+func (vs *ValueSet) UnmarshalJSON(b []byte) error {
+	// Ensure our ValueSet is initialized, in case it was not created with NewValueSet.
+	if vs.addresses == nil {
+		*vs = *NewValueSet()
+	}
+
+	// Unmarshal our JSON representation.
+	var in valueSetJSON
+	err := json.Unmarshal(b, &in)
+	if err != nil {
+		return err
+	}
+
+	// Verify we support the version of the format the data was serialized with.
+	if in.Version != valueSetJSONVersion {
+		return fmt.Errorf("value set was serialized with unsupported format version %v (expected %v)", in.Version, valueSetJSONVersion)
+	}
+
+	// Add all of our values.
+	for _, address := range in.Addresses {
+		vs.AddAddress(address)
+	}
+	for _, integerStr := range in.Integers {
+		integer, ok := new(big.Int).SetString(integerStr, 10)
+		if !ok {
+			return fmt.Errorf("value set contained an invalid integer: %v", integerStr)
+		}
+		vs.AddInteger(integer)
+	}
+	for _, hexStr := range in.Strings {
+		s, err := hex.DecodeString(hexStr)
+		if err != nil {
+			return fmt.Errorf("value set contained an invalid hex-encoded string: %v", err)
+		}
+		vs.AddString(string(s))
+	}
+	for _, bytes := range in.Bytes {
+		vs.AddBytes(bytes)
+	}
+	return nil
+}
+
+// Merge adds all values from the provided ValueSet to this one, in the order they were added to the provided ValueSet.
+func (vs *ValueSet) Merge(other *ValueSet) {
+	for _, address := range other.addresses.listByAddition() {
+		vs.AddAddress(address)
+	}
+	for _, integer := range other.integers.listByAddition() {
+		vs.AddInteger(integer)
+	}
+	for _, s := range other.strings.listByAddition() {
+		vs.AddString(s)
+	}
+	for _, b := range other.bytes.listByAddition() {
+		vs.AddBytes(b)
+	}
+}
@@ -0,0 +1,107 @@
+package colors
+
+import (
+	"os"
+	"regexp"
+)
+
+// Color describes an ANSI escape code used to color text printed to a terminal.
+type Color string
+
+const (
+	// Reset describes the escape code which resets any previously applied color or style.
+	Reset Color = "\x1b[0m"
+
+	// Bold describes the escape code which renders text in bold.
+	Bold Color = "\x1b[1m"
+
+	// Red describes the escape code which renders text in red.
+	Red Color = "\x1b[31m"
+
+	// Green describes the escape code which renders text in green.
+	Green Color = "\x1b[32m"
+
+	// Yellow describes the escape code which renders text in yellow.
+	Yellow Color = "\x1b[33m"
+
+	// Blue describes the escape code which renders text in blue.
+	Blue Color = "\x1b[34m"
+
+	// Magenta describes the escape code which renders text in magenta.
+	Magenta Color = "\x1b[35m"
+
+	// Cyan describes the escape code which renders text in cyan.
+	Cyan Color = "\x1b[36m"
+)
+
+// Mode describes when Colorize applies colors.
+type Mode string
+
+const (
+	// ModeAuto indicates colors are applied only if standard output is a terminal and the NO_COLOR environment
+	// variable is not set, so text written to files or pipes remains plain.
+	ModeAuto Mode = "auto"
+
+	// ModeAlways indicates colors are always applied.
+	ModeAlways Mode = "always"
+
+	// ModeNever indicates colors are never applied.
+	ModeNever Mode = "never"
+)
+
+// IsValid indicates whether the Mode is one of the known color modes.
+func (m Mode) IsValid() bool {
+	return m == ModeAuto || m == ModeAlways || m == ModeNever
+}
+
+// enabled describes whether Colorize applies colors, as determined by the Mode last set with SetMode.
+var enabled = autoEnabled()
+
+// SetMode sets when Colorize applies colors. Unknown modes are treated as ModeAuto.
+func SetMode(mode Mode) {
+	switch mode {
+	case ModeAlways:
+		enabled = true
+	case ModeNever:
+		enabled = false
+	default:
+		enabled = autoEnabled()
+	}
+}
+
+// Enabled indicates whether Colorize currently applies colors.
+func Enabled() bool {
+	return enabled
+}
+
+// autoEnabled indicates whether colors should be applied under ModeAuto.
+func autoEnabled() bool {
+	return StdoutIsTerminal() && os.Getenv("NO_COLOR") == ""
+}
+
+// StdoutIsTerminal indicates whether standard output refers to a terminal (character device).
+func StdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Colorize wraps the provided text in the provided color, followed by a reset. If colors are not Enabled, the text
+// is returned unchanged. All colored output should be produced with this function, so it respects the Mode.
+func Colorize(text string, color Color) string {
+	if !enabled {
+		return text
+	}
+	return string(color) + text + string(Reset)
+}
+
+// colorCodePattern matches the escape codes Colorize wraps text in.
+var colorCodePattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// Strip removes any color escape codes from the provided text, such as those applied by Colorize while colors were
+// Enabled. This should be used when writing text which may have been colored to files.
+func Strip(text string) string {
+	return colorCodePattern.ReplaceAllString(text, "")
+}
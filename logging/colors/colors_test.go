@@ -0,0 +1,26 @@
+package colors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestColorModes ensures Colorize only applies colors when the color mode enables them, and that NO_COLOR disables
+// them in the automatic mode.
+func TestColorModes(t *testing.T) {
+	defer SetMode(ModeAuto)
+
+	SetMode(ModeAlways)
+	assert.True(t, Enabled())
+	assert.EqualValues(t, string(Red)+"text"+string(Reset), Colorize("text", Red))
+
+	SetMode(ModeNever)
+	assert.False(t, Enabled())
+	assert.EqualValues(t, "text", Colorize("text", Red))
+
+	t.Setenv("NO_COLOR", "1")
+	SetMode(ModeAuto)
+	assert.False(t, Enabled())
+	assert.EqualValues(t, "text", Colorize("text", Red))
+}
@@ -0,0 +1,174 @@
+package logging
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/crytic/medusa/logging/colors"
+	"github.com/rs/zerolog"
+)
+
+// LogFormat describes the format log events are written in.
+type LogFormat string
+
+const (
+	// LogFormatText indicates log events are written as their plain messages, one or more lines per event, for
+	// interactive use. Messages of events at levels other than info are prefixed with their level (e.g. "[WARN] ").
+	LogFormatText LogFormat = "text"
+
+	// LogFormatJSON indicates log events are written as one JSON object per line, holding the level, timestamp,
+	// message and any structured fields of the event.
+	LogFormatJSON LogFormat = "json"
+)
+
+// IsValid indicates whether the LogFormat is one of the known log formats.
+func (f LogFormat) IsValid() bool {
+	return f == LogFormatText || f == LogFormatJSON
+}
+
+// GlobalLogger describes the Logger used to log events throughout medusa. It logs in the text format to standard
+// output until it is replaced (e.g. with SetGlobalLogger).
+var GlobalLogger = NewLogger(LogFormatText, os.Stdout)
+
+// SetGlobalLogger replaces GlobalLogger with a new Logger which writes events in the provided format to standard
+// output, including debug events if debug is true. If the JSON format is used, colors are disabled, as escape codes
+// have no meaning to log processors.
+func SetGlobalLogger(format LogFormat, debug bool) {
+	if format == LogFormatJSON {
+		colors.SetMode(colors.ModeNever)
+	}
+	GlobalLogger = NewLogger(format, os.Stdout)
+	GlobalLogger.SetDebug(debug)
+}
+
+// Logger writes log events in a LogFormat. Events are built with zerolog, so structured fields can be attached to
+// them; these are only written in the JSON format, while the text format writes only the message of each event,
+// prefixed with its level if it is not an info event.
+// Debug events are discarded unless enabled with SetDebug.
+type Logger struct {
+	// format describes the format events are written in.
+	format LogFormat
+
+	// logger describes the underlying zerolog logger events are built and written with.
+	logger zerolog.Logger
+}
+
+// NewLogger creates a Logger which writes events in the provided format to the provided writer.
+// Returns the new Logger.
+func NewLogger(format LogFormat, writer io.Writer) *Logger {
+	if format == LogFormatJSON {
+		return &Logger{
+			format: format,
+			logger: zerolog.New(&jsonWriter{writer: writer}).With().Timestamp().Logger().Level(zerolog.InfoLevel),
+		}
+	}
+	return &Logger{
+		format: LogFormatText,
+		logger: zerolog.New(&textWriter{writer: writer}).Level(zerolog.InfoLevel),
+	}
+}
+
+// SetDebug sets whether the Logger writes debug events, rather than discarding them.
+func (l *Logger) SetDebug(enabled bool) {
+	if enabled {
+		l.logger = l.logger.Level(zerolog.DebugLevel)
+	} else {
+		l.logger = l.logger.Level(zerolog.InfoLevel)
+	}
+}
+
+// WithWriter creates a Logger which writes events to the provided writer, in the same format as this Logger, and
+// including debug events if this Logger does.
+// Returns the new Logger.
+func (l *Logger) WithWriter(writer io.Writer) *Logger {
+	logger := NewLogger(l.format, writer)
+	logger.SetDebug(l.logger.GetLevel() == zerolog.DebugLevel)
+	return logger
+}
+
+// Format returns the format the Logger writes events in.
+func (l *Logger) Format() LogFormat {
+	return l.format
+}
+
+// Debug starts a new event at the debug level, which is only written if debug events are enabled. Fields may be
+// attached before it is written with Msg or Msgf.
+func (l *Logger) Debug() *zerolog.Event {
+	return l.logger.Debug()
+}
+
+// Info starts a new event at the info level. Fields may be attached before it is written with Msg or Msgf.
+func (l *Logger) Info() *zerolog.Event {
+	return l.logger.Info()
+}
+
+// Warn starts a new event at the warn level. Fields may be attached before it is written with Msg or Msgf.
+func (l *Logger) Warn() *zerolog.Event {
+	return l.logger.Warn()
+}
+
+// Error starts a new event at the error level. Fields may be attached before it is written with Msg or Msgf.
+func (l *Logger) Error() *zerolog.Event {
+	return l.logger.Error()
+}
+
+// Break writes an empty line to visually separate events in the text format. It does nothing in the JSON format.
+func (l *Logger) Break() {
+	if l.format == LogFormatText {
+		l.logger.Log().Msg("")
+	}
+}
+
+// textWriter is an io.Writer which receives the JSON-encoded events of a zerolog logger and writes only their
+// messages, each followed by a new line. Messages of events at levels other than info are prefixed with their level,
+// so warnings and errors remain distinguishable.
+type textWriter struct {
+	// writer describes the underlying writer messages are written to.
+	writer io.Writer
+}
+
+// Write writes the message of the provided JSON-encoded event to the underlying writer, prefixed with its level if
+// it is neither an info event nor an event without a level (such as those written by Logger.Break).
+// Returns the length of the provided event, or an error if one occurs.
+func (w *textWriter) Write(p []byte) (int, error) {
+	var event struct {
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}
+	err := json.Unmarshal(p, &event)
+	if err != nil {
+		return 0, err
+	}
+	message := event.Message
+	if event.Level != "" && event.Level != zerolog.InfoLevel.String() {
+		message = "[" + strings.ToUpper(event.Level) + "] " + message
+	}
+	_, err = io.WriteString(w.writer, message+"\n")
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// escapedColorCodePattern matches JSON-escaped ANSI color escape codes, as produced by the colors package.
+var escapedColorCodePattern = regexp.MustCompile(`\\u001b\[[0-9;]*m`)
+
+// jsonWriter is an io.Writer which receives the JSON-encoded events of a zerolog logger and writes them with any
+// color escape codes removed, so colored text never leaks into JSON output.
+type jsonWriter struct {
+	// writer describes the underlying writer events are written to.
+	writer io.Writer
+}
+
+// Write writes the provided JSON-encoded event to the underlying writer, removing any color escape codes from it.
+// Returns the length of the provided event, or an error if one occurs.
+func (w *jsonWriter) Write(p []byte) (int, error) {
+	_, err := w.writer.Write(escapedColorCodePattern.ReplaceAll(p, nil))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
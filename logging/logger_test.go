@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/crytic/medusa/logging/colors"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoggerFormats ensures the text format writes only the messages of events, prefixed with their level unless they
+// are info events, while the JSON format writes one object per event holding its level, timestamp, message and
+// structured fields, without any color escape codes.
+func TestLoggerFormats(t *testing.T) {
+	// Log the same events in both formats.
+	logEvents := func(logger *Logger) {
+		logger.Info().Int("worker", 3).Msg("first")
+		logger.Break()
+		logger.Error().Str("testCase", "fuzz_test").Interface("callSequence", []map[string]int{{"blockNumberDelay": 1}}).
+			Msg(string(colors.Red) + "[FAILED] fuzz_test" + string(colors.Reset))
+	}
+	var text strings.Builder
+	logEvents(NewLogger(LogFormatText, &text))
+	var jsonOutput strings.Builder
+	logEvents(NewLogger(LogFormatJSON, &jsonOutput))
+
+	// The text format preserves messages verbatim, separated by breaks, prefixing those which are not info events.
+	assert.EqualValues(t, "first\n\n[ERROR] "+string(colors.Red)+"[FAILED] fuzz_test"+string(colors.Reset)+"\n", text.String())
+	text.Reset()
+	textLogger := NewLogger(LogFormatText, &text)
+	textLogger.SetDebug(true)
+	textLogger.Warn().Str("file", "corpus.json").Msg("corpus item disabled")
+	textLogger.Debug().Msg("console output")
+	assert.EqualValues(t, "[WARN] corpus item disabled\n[DEBUG] console output\n", text.String())
+
+	// The JSON format writes an object per event, omitting breaks.
+	lines := strings.Split(strings.TrimSpace(jsonOutput.String()), "\n")
+	assert.Len(t, lines, 2)
+	var events []map[string]any
+	for _, line := range lines {
+		var event map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		assert.Contains(t, event, "time")
+		events = append(events, event)
+	}
+	assert.EqualValues(t, "info", events[0]["level"])
+	assert.EqualValues(t, "first", events[0]["message"])
+	assert.EqualValues(t, 3, events[0]["worker"])
+	assert.EqualValues(t, "error", events[1]["level"])
+	assert.EqualValues(t, "[FAILED] fuzz_test", events[1]["message"])
+	assert.EqualValues(t, "fuzz_test", events[1]["testCase"])
+	assert.EqualValues(t, []any{map[string]any{"blockNumberDelay": float64(1)}}, events[1]["callSequence"])
+	assert.NotContains(t, jsonOutput.String(), "\\u001b")
+}
+
+// TestLoggerDebugEvents ensures debug events are discarded unless they are enabled.
+func TestLoggerDebugEvents(t *testing.T) {
+	var output strings.Builder
+	logger := NewLogger(LogFormatText, &output)
+	logger.Debug().Msg("hidden")
+	logger.SetDebug(true)
+	logger.Debug().Msg("shown")
+	logger.SetDebug(false)
+	logger.Debug().Msg("hidden again")
+	logger.Info().Msg("info")
+	assert.EqualValues(t, "[DEBUG] shown\ninfo\n", output.String())
+}
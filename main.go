@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"github.com/crytic/medusa/cmd"
+	"github.com/crytic/medusa/logging"
 	"os"
 )
 
@@ -12,8 +13,11 @@ func main() {
 
 	// Print any error we encountered
 	if err != nil {
-		// TODO: Replace this when we have an appropriate logger in place.
-		fmt.Printf("ERROR:\n%s", err.Error())
+		if logging.GlobalLogger.Format() == logging.LogFormatJSON {
+			logging.GlobalLogger.Error().Err(err).Msg("medusa exited with an error")
+		} else {
+			fmt.Printf("ERROR:\n%s", err.Error())
+		}
 		os.Exit(1)
 	}
 }
@@ -52,6 +52,38 @@ func CopyFile(sourcePath string, targetPath string) error {
 	return os.Chmod(targetPath, sourceInfo.Mode())
 }
 
+// WriteFileAtomic writes the provided data to the file at the provided path with the provided permissions. The data is
+// written to a temporary file in the same directory which is then renamed, so the file is never left partially
+// written (e.g. if the process exits while writing it).
+// Returns an error if one occurs.
+func WriteFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	// Create our temporary file.
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), ".tmp-"+filepath.Base(filePath)+"-*")
+	if err != nil {
+		return err
+	}
+	tempFilePath := tempFile.Name()
+
+	// Write our data.
+	err = tempFile.Chmod(perm)
+	if err == nil {
+		_, err = tempFile.Write(data)
+	}
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	// Move our temporary file into place, or remove it if we failed to write it.
+	if err == nil {
+		err = os.Rename(tempFilePath, filePath)
+	}
+	if err != nil {
+		_ = os.Remove(tempFilePath)
+		return err
+	}
+	return nil
+}
+
 // GetFileNameWithoutExtension obtains a filename without the extension. This does not contain any preceding directory
 // paths.
 func GetFileNameWithoutExtension(filePath string) string {
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// HexStringToPrivateKey converts a hex string (with or without the "0x" prefix) to an ECDSA private key on the
+// secp256k1 curve. Returns the parsed private key, or an error if one occurs during conversion.
+func HexStringToPrivateKey(privateKeyHexString string) (*ecdsa.PrivateKey, error) {
+	// Remove the 0x prefix and pad the hex string to the length of a private key.
+	trimmedString := strings.TrimPrefix(privateKeyHexString, "0x")
+	if len(trimmedString) < 64 {
+		trimmedString = strings.Repeat("0", 64-len(trimmedString)) + trimmedString
+	}
+	return crypto.HexToECDSA(trimmedString)
+}
+
+// HexStringsToPrivateKeys converts hex strings (with or without the "0x" prefix) to ECDSA private keys on the
+// secp256k1 curve. Returns the parsed private keys, or an error if one occurs during conversion.
+func HexStringsToPrivateKeys(privateKeyHexStrings []string) ([]*ecdsa.PrivateKey, error) {
+	// Create our array of private keys
+	privateKeys := make([]*ecdsa.PrivateKey, 0)
+
+	// Convert all hex strings to private keys
+	for _, privateKeyHexString := range privateKeyHexStrings {
+		privateKey, err := HexStringToPrivateKey(privateKeyHexString)
+		if err != nil {
+			return nil, err
+		}
+		privateKeys = append(privateKeys, privateKey)
+	}
+	return privateKeys, nil
+}
@@ -5,8 +5,21 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
-// MessageToTransaction derives a types.Transaction from a types.Message.
+// MessageToTransaction derives a types.Transaction from a types.Message. Messages with a non-zero gas fee cap are
+// derived as EIP-1559 (dynamic fee) transactions, while others are derived as legacy transactions.
 func MessageToTransaction(msg core.Message) *types.Transaction {
+	if msg.GasFeeCap() != nil && msg.GasFeeCap().Sign() > 0 {
+		return types.NewTx(&types.DynamicFeeTx{
+			Nonce:      msg.Nonce(),
+			GasTipCap:  msg.GasTipCap(),
+			GasFeeCap:  msg.GasFeeCap(),
+			Gas:        msg.Gas(),
+			To:         msg.To(),
+			Value:      msg.Value(),
+			Data:       msg.Data(),
+			AccessList: msg.AccessList(),
+		})
+	}
 	return types.NewTx(&types.LegacyTx{
 		Nonce:    msg.Nonce(),
 		GasPrice: msg.GasPrice(),
@@ -20,3 +20,15 @@ func ForkRandomProvider(randomProvider *rand.Rand) *rand.Rand {
 	forkSeed := int64(binary.LittleEndian.Uint64(b))
 	return rand.New(rand.NewSource(forkSeed))
 }
+
+// DeriveSeed deterministically derives a child seed from a parent seed and an index, such that each index obtains a
+// distinct, well-distributed seed. This can be used to create random providers for go routines which are reproducible
+// given the same parent seed, regardless of the order in which they are created.
+// Returns the derived seed.
+func DeriveSeed(seed int64, index int) int64 {
+	// We mix the seed and index using the SplitMix64 finalizer.
+	z := uint64(seed) + (uint64(index)+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return int64(z ^ (z >> 31))
+}
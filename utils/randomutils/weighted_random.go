@@ -27,6 +27,11 @@ func NewWeightedRandomChoice[T any](data T, weight *big.Int) *WeightedRandomChoi
 	}
 }
 
+// Weight returns a copy of the weight of this WeightedRandomChoice.
+func (c *WeightedRandomChoice[T]) Weight() *big.Int {
+	return new(big.Int).Set(c.weight)
+}
+
 // WeightedRandomChooser takes a series of WeightedRandomChoice objects which wrap underlying data, and returns one
 // of the weighted options randomly.
 type WeightedRandomChooser[T any] struct {
@@ -78,6 +83,18 @@ func (c *WeightedRandomChooser[T]) AddChoices(choices ...*WeightedRandomChoice[T
 	c.choices = append(c.choices, choices...)
 }
 
+// SetChoiceWeight updates the weight of a choice which was previously added to the WeightedRandomChooser.
+func (c *WeightedRandomChooser[T]) SetChoiceWeight(choice *WeightedRandomChoice[T], weight *big.Int) {
+	// Acquire our lock during the duration of this method.
+	c.randomProviderLock.Lock()
+	defer c.randomProviderLock.Unlock()
+
+	// Replace the choice's weight in our total weight, then update the choice.
+	c.totalWeight = new(big.Int).Sub(c.totalWeight, choice.weight)
+	c.totalWeight = new(big.Int).Add(c.totalWeight, weight)
+	choice.weight = new(big.Int).Set(weight)
+}
+
 // Choose selects a random weighted item from the WeightedRandomChooser, or returns an error if one occurs.
 func (c *WeightedRandomChooser[T]) Choose() (*T, error) {
 	// If we have no choices or 0 total weight, return nil.
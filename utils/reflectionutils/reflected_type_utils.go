@@ -95,3 +95,70 @@ func SetReflectedArrayValues(reflectedArray reflect.Value, values []any) error {
 	}
 	panic("failed to set reflected array values, type not supported")
 }
+
+// ConvertReflectedValue converts a value into the provided type. Arrays, slices and structs are converted recursively,
+// element by element, so that values whose nested element types differ from, but are convertible to, those of the
+// provided type can be converted (e.g. a value decoded into differently declared struct types).
+// Returns the converted reflected value, or an error if the value could not be converted.
+func ConvertReflectedValue(value any, targetType reflect.Type) (reflect.Value, error) {
+	// If we weren't provided a value, we cannot determine how to convert it.
+	if value == nil {
+		return reflect.Value{}, fmt.Errorf("failed to convert nil value to type %v", targetType)
+	}
+
+	// If our value is already of the correct type, or of a directly convertible type, we can return it immediately.
+	reflectedValue := reflect.ValueOf(value)
+	if reflectedValue.Type() == targetType {
+		return reflectedValue, nil
+	}
+	if reflectedValue.Type().ConvertibleTo(targetType) && reflectedValue.Kind() != reflect.Slice {
+		return reflectedValue.Convert(targetType), nil
+	}
+
+	// Otherwise, we convert composite types element by element.
+	switch targetType.Kind() {
+	case reflect.Slice:
+		fallthrough
+	case reflect.Array:
+		if reflectedValue.Kind() != reflect.Slice && reflectedValue.Kind() != reflect.Array {
+			return reflect.Value{}, fmt.Errorf("failed to convert value of type %v to type %v", reflectedValue.Type(), targetType)
+		}
+
+		// Create our resulting array or slice.
+		var result reflect.Value
+		if targetType.Kind() == reflect.Array {
+			if reflectedValue.Len() != targetType.Len() {
+				return reflect.Value{}, fmt.Errorf("failed to convert value of length %v to array type %v", reflectedValue.Len(), targetType)
+			}
+			result = reflect.New(targetType).Elem()
+		} else {
+			result = reflect.MakeSlice(targetType, reflectedValue.Len(), reflectedValue.Len())
+		}
+
+		// Convert each element.
+		for i := 0; i < reflectedValue.Len(); i++ {
+			convertedElement, err := ConvertReflectedValue(GetField(reflectedValue.Index(i)), targetType.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			result.Index(i).Set(convertedElement)
+		}
+		return result, nil
+	case reflect.Struct:
+		if reflectedValue.Kind() != reflect.Struct || reflectedValue.NumField() != targetType.NumField() {
+			return reflect.Value{}, fmt.Errorf("failed to convert value of type %v to type %v", reflectedValue.Type(), targetType)
+		}
+
+		// Convert each field.
+		result := reflect.New(targetType).Elem()
+		for i := 0; i < targetType.NumField(); i++ {
+			convertedField, err := ConvertReflectedValue(GetField(reflectedValue.Field(i)), targetType.Field(i).Type)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			SetField(result.Field(i), convertedField.Interface())
+		}
+		return result, nil
+	}
+	return reflect.Value{}, fmt.Errorf("failed to convert value of type %v to type %v", reflectedValue.Type(), targetType)
+}